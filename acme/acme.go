@@ -0,0 +1,399 @@
+// Package acme obtains and renews the certificate of the DoT/DoH listeners via ACME (RFC 8555), e.g. from Let's Encrypt.
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/sirupsen/logrus"
+	acmeclient "golang.org/x/crypto/acme"
+)
+
+const (
+	checkInterval = 12 * time.Hour
+	retryInterval = time.Hour
+
+	challengePath = "/.well-known/acme-challenge/"
+
+	accountKeyFile = "account.key"
+	certFile       = "cert.pem"
+	keyFile        = "key.pem"
+)
+
+var errNoChallenge = errors.New("no supported challenge offered")
+
+func logger() *logrus.Entry {
+	return log.PrefixedLog("acme")
+}
+
+// Manager provides the certificate for the TLS listeners and renews it before it expires.
+// Until a certificate was obtained, a self-signed certificate is used.
+type Manager struct {
+	cfg config.ACME
+	dns dnsProvider
+
+	cert     atomic.Pointer[tls.Certificate]
+	fallback tls.Certificate
+
+	// key authorizations of the pending http-01 challenges by token
+	tokens sync.Map
+}
+
+// New loads the stored certificate, returns nil if ACME is disabled
+func New(cfg config.ACME) (*Manager, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil //nolint:nilnil
+	}
+
+	if !cfg.AcceptTOS {
+		return nil, errors.New("acme.acceptTOS must be true to create the account")
+	}
+
+	if err := os.MkdirAll(cfg.Storage, 0o700); err != nil { //nolint:mnd
+		return nil, fmt.Errorf("can't create ACME storage: %w", err)
+	}
+
+	m := &Manager{cfg: cfg}
+
+	if cfg.Challenge == config.ACMEChallengeDns01 {
+		provider, err := newDNSProvider(cfg.DNS01)
+		if err != nil {
+			return nil, err
+		}
+
+		m.dns = provider
+	}
+
+	fallback, err := util.TLSGenerateSelfSignedCert(cfg.Domains)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate self-signed certificate: %w", err)
+	}
+
+	m.fallback = fallback
+
+	cert, err := tls.LoadX509KeyPair(m.path(certFile), m.path(keyFile))
+
+	switch {
+	case err == nil:
+		m.cert.Store(&cert)
+
+	case errors.Is(err, os.ErrNotExist):
+		logger().Info("no certificate obtained yet, using self-signed certificate")
+
+	default:
+		return nil, fmt.Errorf("can't load stored certificate: %w", err)
+	}
+
+	return m, nil
+}
+
+// GetCertificate is the `tls.Config.GetCertificate` of the TLS listeners
+func (m *Manager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cert := m.cert.Load(); cert != nil {
+		return cert, nil
+	}
+
+	return &m.fallback, nil
+}
+
+// HTTPHandler answers the http-01 challenges and passes all other requests to `fallback`
+func (m *Manager) HTTPHandler(fallback http.Handler) http.Handler {
+	if m == nil {
+		return fallback
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.URL.Path, challengePath)
+		if !ok {
+			fallback.ServeHTTP(w, r)
+
+			return
+		}
+
+		keyAuth, ok := m.tokens.Load(token)
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(keyAuth.(string)))
+	})
+}
+
+// Start obtains a certificate if there is none and renews it before it expires, until ctx is done
+func (m *Manager) Start(ctx context.Context) {
+	go func() {
+		for {
+			wait := checkInterval
+
+			if m.needsRenewal(time.Now()) {
+				if err := m.obtain(ctx); err != nil {
+					logger().Errorf("can't obtain certificate, retrying in %s: %s", retryInterval, err)
+
+					wait = retryInterval
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// needsRenewal returns true if there is no certificate for the configured domains valid for `renewBefore`
+func (m *Manager) needsRenewal(now time.Time) bool {
+	cert := m.cert.Load()
+	if cert == nil {
+		return true
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return true
+	}
+
+	names := slices.Clone(leaf.DNSNames)
+	domains := slices.Clone(m.cfg.Domains)
+
+	slices.Sort(names)
+	slices.Sort(domains)
+
+	if !slices.Equal(names, domains) {
+		return true
+	}
+
+	return now.Add(m.cfg.RenewBefore.ToDuration()).After(leaf.NotAfter)
+}
+
+// obtain orders a new certificate and stores it
+func (m *Manager) obtain(ctx context.Context) error {
+	logger().Infof("obtaining certificate for %s", strings.Join(m.cfg.Domains, ", "))
+
+	accountKey, err := m.loadOrCreateKey(accountKeyFile)
+	if err != nil {
+		return err
+	}
+
+	client := &acmeclient.Client{Key: accountKey, DirectoryURL: m.cfg.Directory, UserAgent: "blocky"}
+
+	account := &acmeclient.Account{}
+	if m.cfg.Email != "" {
+		account.Contact = []string{"mailto:" + m.cfg.Email}
+	}
+
+	if _, err := client.Register(ctx, account, acmeclient.AcceptTOS); err != nil &&
+		!errors.Is(err, acmeclient.ErrAccountAlreadyExists) {
+		return fmt.Errorf("can't register account: %w", err)
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acmeclient.DomainIDs(m.cfg.Domains...))
+	if err != nil {
+		return fmt.Errorf("can't create order: %w", err)
+	}
+
+	for _, url := range order.AuthzURLs {
+		if err := m.authorize(ctx, client, url); err != nil {
+			return err
+		}
+	}
+
+	if _, err := client.WaitOrder(ctx, order.URI); err != nil {
+		return fmt.Errorf("order failed: %w", err)
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: m.cfg.Domains}, certKey)
+	if err != nil {
+		return err
+	}
+
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return fmt.Errorf("can't finalize order: %w", err)
+	}
+
+	cert, err := m.store(chain, certKey)
+	if err != nil {
+		return err
+	}
+
+	m.cert.Store(cert)
+
+	logger().Infof("obtained certificate valid until %s", cert.Leaf.NotAfter)
+
+	return nil
+}
+
+// authorize completes a challenge of the authorization
+func (m *Manager) authorize(ctx context.Context, client *acmeclient.Client, url string) error {
+	authz, err := client.GetAuthorization(ctx, url)
+	if err != nil {
+		return fmt.Errorf("can't get authorization: %w", err)
+	}
+
+	if authz.Status == acmeclient.StatusValid {
+		return nil
+	}
+
+	domain := authz.Identifier.Value
+
+	idx := slices.IndexFunc(authz.Challenges, func(c *acmeclient.Challenge) bool {
+		return c.Type == m.cfg.Challenge.String()
+	})
+	if idx < 0 {
+		return fmt.Errorf("%s: %w: %s", domain, errNoChallenge, m.cfg.Challenge)
+	}
+
+	challenge := authz.Challenges[idx]
+
+	switch m.cfg.Challenge {
+	case config.ACMEChallengeHttp01:
+		keyAuth, err := client.HTTP01ChallengeResponse(challenge.Token)
+		if err != nil {
+			return err
+		}
+
+		m.tokens.Store(challenge.Token, keyAuth)
+		defer m.tokens.Delete(challenge.Token)
+
+	case config.ACMEChallengeDns01:
+		value, err := client.DNS01ChallengeRecord(challenge.Token)
+		if err != nil {
+			return err
+		}
+
+		// the challenge of a wildcard domain is the one of its base domain
+		fqdn := "_acme-challenge." + strings.TrimPrefix(domain, "*.") + "."
+
+		if err := m.dns.present(ctx, fqdn, value); err != nil {
+			return fmt.Errorf("can't publish TXT record %s: %w", fqdn, err)
+		}
+
+		defer func() {
+			if err := m.dns.cleanup(context.WithoutCancel(ctx), fqdn, value); err != nil {
+				logger().Warnf("can't remove TXT record %s: %s", fqdn, err)
+			}
+		}()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.cfg.DNS01.PropagationDelay.ToDuration()):
+		}
+	}
+
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("%s: can't accept challenge: %w", domain, err)
+	}
+
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("%s: authorization failed: %w", domain, err)
+	}
+
+	return nil
+}
+
+func (m *Manager) path(name string) string {
+	return filepath.Join(m.cfg.Storage, name)
+}
+
+// loadOrCreateKey returns the stored key, a new one is created and stored if there is none
+func (m *Manager) loadOrCreateKey(name string) (crypto.Signer, error) {
+	data, err := os.ReadFile(m.path(name))
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s: no PEM data", name)
+		}
+
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+	if err := util.WriteFileAtomic(m.path(name), keyPEM, 0o600); err != nil { //nolint:mnd
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// store writes the certificate chain and its key and returns the certificate
+func (m *Manager) store(chain [][]byte, key *ecdsa.PrivateKey) (*tls.Certificate, error) {
+	var certPEM []byte
+
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	// the key first, the certificate is useless without it
+	if err := util.WriteFileAtomic(m.path(keyFile), keyPEM, 0o600); err != nil { //nolint:mnd
+		return nil, err
+	}
+
+	if err := util.WriteFileAtomic(m.path(certFile), certPEM, 0o600); err != nil { //nolint:mnd
+		return nil, err
+	}
+
+	return &cert, nil
+}
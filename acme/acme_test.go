@@ -0,0 +1,179 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manager", func() {
+	var (
+		cfg config.ACME
+		sut *Manager
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = config.WithDefaults[config.ACME]()
+		Expect(err).Should(Succeed())
+
+		cfg.Domains = []string{"dns.example.com"}
+		cfg.AcceptTOS = true
+		cfg.Storage = GinkgoT().TempDir()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = New(cfg)
+		Expect(err).Should(Succeed())
+	})
+
+	// storeCert stores a self-signed certificate as if it was obtained from the CA
+	storeCert := func(domains ...string) {
+		cert, err := util.TLSGenerateSelfSignedCert(domains)
+		Expect(err).Should(Succeed())
+
+		stored, err := sut.store(cert.Certificate, cert.PrivateKey.(*ecdsa.PrivateKey))
+		Expect(err).Should(Succeed())
+
+		sut.cert.Store(stored)
+	}
+
+	Describe("New", func() {
+		It("should return nil if disabled", func() {
+			cfg.Domains = nil
+
+			m, err := New(cfg)
+			Expect(err).Should(Succeed())
+			Expect(m).Should(BeNil())
+		})
+
+		It("should fail if the terms of service are not accepted", func() {
+			cfg.AcceptTOS = false
+
+			_, err := New(cfg)
+			Expect(err).Should(MatchError(ContainSubstring("acceptTOS")))
+		})
+
+		It("should fail without dns-01 provider configuration", func() {
+			cfg.Challenge = config.ACMEChallengeDns01
+
+			_, err := New(cfg)
+			Expect(err).Should(MatchError(ContainSubstring("nameserver is required")))
+		})
+
+		It("should load the stored certificate", func() {
+			storeCert("dns.example.com")
+
+			m, err := New(cfg)
+			Expect(err).Should(Succeed())
+			Expect(m.cert.Load().Certificate).Should(Equal(sut.cert.Load().Certificate))
+		})
+
+		It("should fail on an invalid stored certificate", func() {
+			Expect(os.WriteFile(sut.path(certFile), []byte("invalid"), 0o600)).Should(Succeed())
+			Expect(os.WriteFile(sut.path(keyFile), []byte("invalid"), 0o600)).Should(Succeed())
+
+			_, err := New(cfg)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("GetCertificate", func() {
+		It("should use the self-signed certificate until a certificate was obtained", func() {
+			cert, err := sut.GetCertificate(nil)
+			Expect(err).Should(Succeed())
+			Expect(cert.Leaf.DNSNames).Should(Equal([]string{"dns.example.com"}))
+			Expect(cert.Leaf.Subject.Organization).Should(Equal([]string{"Blocky"}))
+
+			storeCert("dns.example.com")
+
+			cert, err = sut.GetCertificate(nil)
+			Expect(err).Should(Succeed())
+			Expect(cert).Should(BeIdenticalTo(sut.cert.Load()))
+		})
+	})
+
+	Describe("needsRenewal", func() {
+		It("should be true without certificate", func() {
+			Expect(sut.needsRenewal(time.Now())).Should(BeTrue())
+		})
+
+		It("should be true if the domains changed", func() {
+			storeCert("other.example.com")
+
+			Expect(sut.needsRenewal(time.Now())).Should(BeTrue())
+		})
+
+		It("should be true shortly before the expiry", func() {
+			storeCert("dns.example.com")
+
+			notAfter := sut.cert.Load().Leaf.NotAfter
+
+			Expect(sut.needsRenewal(time.Now())).Should(BeFalse())
+			Expect(sut.needsRenewal(notAfter.Add(-time.Hour))).Should(BeTrue())
+		})
+	})
+
+	Describe("HTTPHandler", func() {
+		var fallback http.Handler
+
+		BeforeEach(func() {
+			fallback = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+		})
+
+		get := func(handler http.Handler, path string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+			return rec
+		}
+
+		It("should answer pending challenges", func() {
+			sut.tokens.Store("token", "token.thumbprint")
+
+			rec := get(sut.HTTPHandler(fallback), challengePath+"token")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+
+			body, err := io.ReadAll(rec.Body)
+			Expect(err).Should(Succeed())
+			Expect(string(body)).Should(Equal("token.thumbprint"))
+
+			Expect(get(sut.HTTPHandler(fallback), challengePath+"unknown").Code).Should(Equal(http.StatusNotFound))
+		})
+
+		It("should pass other requests to the fallback", func() {
+			Expect(get(sut.HTTPHandler(fallback), "/api/blocking/status").Code).Should(Equal(http.StatusTeapot))
+		})
+
+		It("should return the fallback if disabled", func() {
+			var m *Manager
+
+			Expect(get(m.HTTPHandler(fallback), challengePath+"token").Code).Should(Equal(http.StatusTeapot))
+		})
+	})
+
+	Describe("loadOrCreateKey", func() {
+		It("should create the key once", func() {
+			key, err := sut.loadOrCreateKey(accountKeyFile)
+			Expect(err).Should(Succeed())
+
+			loaded, err := sut.loadOrCreateKey(accountKeyFile)
+			Expect(err).Should(Succeed())
+			Expect(loaded).Should(Equal(key))
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	challengeTTL  = 60
+	tsigFudge     = 300
+	updateTimeout = 10 * time.Second
+)
+
+// dnsProvider publishes the TXT records of the dns-01 challenge
+type dnsProvider interface {
+	present(ctx context.Context, fqdn, value string) error
+	cleanup(ctx context.Context, fqdn, value string) error
+}
+
+func newDNSProvider(cfg config.ACMEDNS01) (dnsProvider, error) {
+	switch cfg.Provider {
+	case config.ACMEDNSProviderRfc2136:
+		if cfg.Nameserver == "" {
+			return nil, errors.New("acme.dns01.nameserver is required for the rfc2136 provider")
+		}
+
+		nameserver := cfg.Nameserver
+		if _, _, err := net.SplitHostPort(nameserver); err != nil {
+			nameserver = net.JoinHostPort(nameserver, "53")
+		}
+
+		return &rfc2136Provider{cfg: cfg, nameserver: nameserver}, nil
+
+	case config.ACMEDNSProviderExec:
+		if cfg.Command == "" {
+			return nil, errors.New("acme.dns01.command is required for the exec provider")
+		}
+
+		return &execProvider{command: cfg.Command}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported dns01 provider: %s", cfg.Provider)
+}
+
+// rfc2136Provider publishes the records via dynamic updates (RFC 2136), optionally signed with TSIG
+type rfc2136Provider struct {
+	cfg        config.ACMEDNS01
+	nameserver string
+}
+
+func (p *rfc2136Provider) present(ctx context.Context, fqdn, value string) error {
+	return p.update(ctx, fqdn, value, (*dns.Msg).Insert)
+}
+
+func (p *rfc2136Provider) cleanup(ctx context.Context, fqdn, value string) error {
+	return p.update(ctx, fqdn, value, (*dns.Msg).Remove)
+}
+
+func (p *rfc2136Provider) update(ctx context.Context, fqdn, value string, op func(*dns.Msg, []dns.RR)) error {
+	zone, err := p.zone(ctx, fqdn)
+	if err != nil {
+		return err
+	}
+
+	rr := &dns.TXT{
+		Hdr: dns.RR_Header{Name: fqdn, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: challengeTTL},
+		Txt: []string{value},
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(zone)
+	op(msg, []dns.RR{rr})
+
+	resp, err := p.exchange(ctx, msg)
+	if err != nil {
+		return err
+	}
+
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("update of zone %s failed: %s", zone, dns.RcodeToString[resp.Rcode])
+	}
+
+	return nil
+}
+
+// zone returns the configured zone or the zone of `fqdn` according to the SOA record
+func (p *rfc2136Provider) zone(ctx context.Context, fqdn string) (string, error) {
+	if p.cfg.Zone != "" {
+		return dns.Fqdn(p.cfg.Zone), nil
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(fqdn, dns.TypeSOA)
+
+	resp, err := p.exchange(ctx, msg)
+	if err != nil {
+		return "", fmt.Errorf("can't find zone of %s: %w", fqdn, err)
+	}
+
+	// the SOA is in the answer for the apex and in the authority section below it
+	for _, rr := range append(resp.Answer, resp.Ns...) {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa.Hdr.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("can't find zone of %s: no SOA record", fqdn)
+}
+
+func (p *rfc2136Provider) exchange(ctx context.Context, msg *dns.Msg) (*dns.Msg, error) {
+	client := &dns.Client{Net: "tcp", Timeout: updateTimeout}
+
+	if p.cfg.TSIGKey != "" {
+		key := dns.Fqdn(p.cfg.TSIGKey)
+
+		client.TsigSecret = map[string]string{key: p.cfg.TSIGSecret}
+		msg.SetTsig(key, dns.Fqdn(p.cfg.TSIGAlgorithm), tsigFudge, time.Now().Unix())
+	}
+
+	resp, _, err := client.ExchangeContext(ctx, msg, p.nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("can't reach %s: %w", p.nameserver, err)
+	}
+
+	return resp, nil
+}
+
+// execProvider publishes the records with an external command, e.g. a script calling the API of the DNS hoster
+type execProvider struct {
+	command string
+}
+
+func (p *execProvider) present(ctx context.Context, fqdn, value string) error {
+	return p.run(ctx, "present", fqdn, value)
+}
+
+func (p *execProvider) cleanup(ctx context.Context, fqdn, value string) error {
+	return p.run(ctx, "cleanup", fqdn, value)
+}
+
+func (p *execProvider) run(ctx context.Context, action, fqdn, value string) error {
+	out, err := exec.CommandContext(ctx, p.command, action, fqdn, value).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w: %s", p.command, action, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
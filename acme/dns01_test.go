@@ -0,0 +1,187 @@
+package acme
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/dnsupdate"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNS providers", func() {
+	var cfg config.ACMEDNS01
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = config.WithDefaults[config.ACMEDNS01]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("rfc2136", func() {
+		const (
+			keyName = "acme-key."
+			secret  = "c2VjcmV0LXNlY3JldC1zZWNyZXQ="
+		)
+
+		var (
+			mu      sync.Mutex
+			updates []*dns.Msg
+		)
+
+		BeforeEach(func() {
+			updates = nil
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).Should(Succeed())
+
+			handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+				resp := new(dns.Msg)
+				resp.SetReply(req)
+
+				switch {
+				case req.Opcode == dns.OpcodeUpdate:
+					if w.TsigStatus() != nil {
+						resp.Rcode = dns.RcodeRefused
+					}
+
+					mu.Lock()
+					updates = append(updates, req)
+					mu.Unlock()
+
+				case req.Question[0].Qtype == dns.TypeSOA:
+					soa, err := dns.NewRR("example.com. 300 IN SOA ns.example.com. admin.example.com. 1 3600 600 86400 60")
+					Expect(err).Should(Succeed())
+
+					resp.Ns = []dns.RR{soa}
+				}
+
+				if tsig := req.IsTsig(); tsig != nil {
+					resp.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+				}
+
+				_ = w.WriteMsg(resp)
+			})
+
+			started := make(chan struct{})
+			server := &dns.Server{
+				Listener:          listener,
+				Handler:           handler,
+				TsigSecret:        map[string]string{keyName: secret},
+				MsgAcceptFunc:     dnsupdate.AcceptMsg,
+				NotifyStartedFunc: func() { close(started) },
+			}
+
+			go func() {
+				defer GinkgoRecover()
+
+				_ = server.ActivateAndServe()
+			}()
+
+			DeferCleanup(server.Shutdown)
+			Eventually(started).Should(BeClosed())
+
+			cfg.Nameserver = listener.Addr().String()
+			cfg.TSIGKey = "acme-key"
+			cfg.TSIGSecret = secret
+		})
+
+		It("should insert and remove the record in the zone of the domain", func() {
+			sut, err := newDNSProvider(cfg)
+			Expect(err).Should(Succeed())
+
+			Expect(sut.present(context.Background(), "_acme-challenge.dns.example.com.", "value")).Should(Succeed())
+			Expect(sut.cleanup(context.Background(), "_acme-challenge.dns.example.com.", "value")).Should(Succeed())
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			Expect(updates).Should(HaveLen(2))
+
+			Expect(updates[0].Question[0].Name).Should(Equal("example.com."))
+			Expect(updates[0].Ns).Should(HaveLen(1))
+			Expect(updates[0].Ns[0].(*dns.TXT).Txt).Should(Equal([]string{"value"}))
+			Expect(updates[0].Ns[0].Header().Class).Should(Equal(uint16(dns.ClassINET)))
+
+			// removal of a single record uses class NONE
+			Expect(updates[1].Ns[0].Header().Class).Should(Equal(uint16(dns.ClassNONE)))
+		})
+
+		It("should use the configured zone", func() {
+			cfg.Zone = "dns.example.com"
+
+			sut, err := newDNSProvider(cfg)
+			Expect(err).Should(Succeed())
+
+			Expect(sut.present(context.Background(), "_acme-challenge.dns.example.com.", "value")).Should(Succeed())
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			Expect(updates[0].Question[0].Name).Should(Equal("dns.example.com."))
+		})
+
+		It("should fail if the update is refused", func() {
+			cfg.TSIGSecret = "d3Jvbmc="
+
+			sut, err := newDNSProvider(cfg)
+			Expect(err).Should(Succeed())
+
+			err = sut.present(context.Background(), "_acme-challenge.dns.example.com.", "value")
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should fail without nameserver", func() {
+			cfg.Nameserver = ""
+
+			_, err := newDNSProvider(cfg)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("exec", func() {
+		var out string
+
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			out = filepath.Join(dir, "calls")
+
+			script := filepath.Join(dir, "hook.sh")
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" >> "+out+"\n"), 0o700)).Should(Succeed())
+
+			cfg.Provider = config.ACMEDNSProviderExec
+			cfg.Command = script
+		})
+
+		It("should call the command", func() {
+			sut, err := newDNSProvider(cfg)
+			Expect(err).Should(Succeed())
+
+			Expect(sut.present(context.Background(), "_acme-challenge.dns.example.com.", "value")).Should(Succeed())
+			Expect(sut.cleanup(context.Background(), "_acme-challenge.dns.example.com.", "value")).Should(Succeed())
+
+			Expect(os.ReadFile(out)).Should(BeEquivalentTo(
+				"present _acme-challenge.dns.example.com. value\n" +
+					"cleanup _acme-challenge.dns.example.com. value\n",
+			))
+		})
+
+		It("should fail if the command fails", func() {
+			cfg.Command = "false"
+
+			sut, err := newDNSProvider(cfg)
+			Expect(err).Should(Succeed())
+
+			Expect(sut.present(context.Background(), "_acme-challenge.dns.example.com.", "value")).
+				Should(MatchError(ContainSubstring("false present failed")))
+		})
+	})
+})
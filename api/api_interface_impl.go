@@ -6,19 +6,27 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/0xERR0R/blocky/lists/formats"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/querylog"
+	"github.com/0xERR0R/blocky/stats"
 	"github.com/0xERR0R/blocky/util"
 	"github.com/go-chi/chi/v5"
 	"github.com/miekg/dns"
 )
 
+const defaultRecentQueries = 100
+
 type httpReqCtxKey struct{}
 
 // BlockingStatus represents the current blocking status
@@ -31,11 +39,24 @@ type BlockingStatus struct {
 	AutoEnableInSec int
 }
 
+// BlockingScheduleStatus represents the schedule state of a blocking group
+type BlockingScheduleStatus struct {
+	// Group name
+	Group string
+	// True if the group is currently blocked according to its schedule
+	Active bool
+	// Time of the next schedule change, zero if the state never changes
+	NextChange time.Time
+}
+
 // BlockingControl interface to control the blocking status
 type BlockingControl interface {
 	EnableBlocking(ctx context.Context)
 	DisableBlocking(ctx context.Context, duration time.Duration, disableGroups []string) error
+	// AllowDomain allows the domain and its subdomains for all clients for the duration
+	AllowDomain(ctx context.Context, domain string, duration time.Duration) error
 	BlockingStatus() BlockingStatus
+	BlockingSchedule() []BlockingScheduleStatus
 }
 
 // ListRefresher interface to control the list refresh
@@ -43,14 +64,128 @@ type ListRefresher interface {
 	RefreshLists() error
 }
 
+// ListExporter provides the local allow/denylist rules
+type ListExporter interface {
+	// LocalListRules returns the inline and local file rules of the groups, or of all groups if empty
+	LocalListRules(ctx context.Context, groups []string) ([]formats.Rule, error)
+}
+
 type Querier interface {
 	Query(
 		ctx context.Context, serverHost string, clientIP net.IP, question string, qType dns.Type,
 	) (*model.Response, error)
+	// TraceQuery resolves the query in dry-run mode and returns how it was resolved
+	TraceQuery(
+		ctx context.Context, serverHost string, clientIP net.IP, question string, qType dns.Type,
+	) (*model.Response, *model.Trace, error)
+}
+
+// CacheEntry is a cached DNS response
+type CacheEntry struct {
+	// Queried domain name
+	Name string
+	// Query type
+	Type dns.Type
+	// EDNS Client Subnet the entry is specific to, empty if it is used for all clients
+	Subnet string
+	// Remaining time until the entry expires
+	TTL time.Duration
+	// Cached response
+	Response *dns.Msg
+}
+
+// CacheTTLBucket counts the cache lookups of entries with a TTL in [MinTTL, MaxTTL)
+type CacheTTLBucket struct {
+	MinTTL time.Duration
+	// Upper bound of the bucket, 0 if it is unbounded
+	MaxTTL time.Duration
+	Hits   uint64
+	Misses uint64
+}
+
+// CacheStats describes the content and the efficiency of the DNS response cache
+type CacheStats struct {
+	// Number of cached responses
+	Entries int
+	// Estimated size of the cached keys and responses in bytes
+	MemoryBytes int
+	// Hits and misses per TTL bucket
+	Buckets []CacheTTLBucket
 }
 
+// CacheControl interface to inspect and control the DNS response cache
 type CacheControl interface {
 	FlushCaches(ctx context.Context)
+	// CacheEntries returns the entries whose domain contains name, all entries if name is empty
+	CacheEntries(name string) []CacheEntry
+	// RemoveCacheEntries removes the entries of the domain and its subdomains and returns their number
+	RemoveCacheEntries(ctx context.Context, name string) int
+	CacheStats() CacheStats
+}
+
+// UpstreamStatus is the health and rolling stats of an upstream in a group
+type UpstreamStatus struct {
+	// Name of the upstream group
+	Group string
+	// Upstream as configured
+	Upstream string
+	// False if the upstream failed the health checks and is out of rotation
+	Healthy bool
+	// Share of failed queries (0 - 1) of the latest queries
+	ErrorRate float64
+	// Average duration of the latest successful queries
+	AverageLatency time.Duration
+	// Time of the latest health check, zero if health checks are disabled
+	LastCheck time.Time
+}
+
+// UpstreamStatusProvider provides the status of all upstreams
+type UpstreamStatusProvider interface {
+	UpstreamStatus() []UpstreamStatus
+}
+
+// Snapshot describes a snapshot of the configuration and runtime state
+type Snapshot struct {
+	// Name of the snapshot
+	Name string
+	// Time the snapshot was taken
+	Created time.Time
+	// Why the snapshot was taken
+	Reason string
+	// Hash of the configuration in the snapshot
+	ConfigHash string
+}
+
+// SnapshotManager lists and restores snapshots
+type SnapshotManager interface {
+	Snapshots() ([]Snapshot, error)
+	// Rollback restores the snapshot, the error wraps `fs.ErrNotExist` if there is no snapshot with that name
+	Rollback(ctx context.Context, name string) error
+}
+
+// InfoProvider provides information about the running instance
+type InfoProvider interface {
+	// ConfigHash returns the hash of the loaded configuration
+	ConfigHash() string
+	// Configuration returns the effective configuration as it is logged on start, without secrets
+	Configuration() string
+}
+
+// QueryLogProvider provides the latest queries
+type QueryLogProvider interface {
+	// RecentQueries returns up to limit of the latest query log entries, newest first
+	RecentQueries(limit int) []*querylog.LogEntry
+	// SubscribeQueries returns a channel receiving new query log entries, buffering up to size entries,
+	// and a function ending the subscription
+	SubscribeQueries(size int) (<-chan *querylog.LogEntry, func())
+}
+
+// StatsProvider provides the rolling query statistics
+type StatsProvider interface {
+	// StatsOverview returns the counters of the latest period
+	StatsOverview(ctx context.Context, period time.Duration) (stats.Overview, error)
+	// TopStats returns up to limit domains or clients with the most queries of the latest period
+	TopStats(ctx context.Context, period time.Duration, kind stats.Kind, limit int) ([]stats.Count, error)
 }
 
 func RegisterOpenAPIEndpoints(router chi.Router, impl StrictServerInterface) {
@@ -71,19 +206,37 @@ type OpenAPIInterfaceImpl struct {
 	control      BlockingControl
 	querier      Querier
 	refresher    ListRefresher
+	exporter     ListExporter
 	cacheControl CacheControl
+	info         InfoProvider
+	upstreams    UpstreamStatusProvider
+	snapshots    SnapshotManager
+	queryLog     QueryLogProvider
+	stats        StatsProvider
 }
 
 func NewOpenAPIInterfaceImpl(control BlockingControl,
 	querier Querier,
 	refresher ListRefresher,
+	exporter ListExporter,
 	cacheControl CacheControl,
+	info InfoProvider,
+	upstreams UpstreamStatusProvider,
+	snapshots SnapshotManager,
+	queryLog QueryLogProvider,
+	stats StatsProvider,
 ) *OpenAPIInterfaceImpl {
 	return &OpenAPIInterfaceImpl{
 		control:      control,
 		querier:      querier,
 		refresher:    refresher,
+		exporter:     exporter,
 		cacheControl: cacheControl,
+		info:         info,
+		upstreams:    upstreams,
+		snapshots:    snapshots,
+		queryLog:     queryLog,
+		stats:        stats,
 	}
 }
 
@@ -116,6 +269,22 @@ func (i *OpenAPIInterfaceImpl) DisableBlocking(ctx context.Context,
 	return DisableBlocking200Response{}, nil
 }
 
+func (i *OpenAPIInterfaceImpl) AllowDomain(ctx context.Context,
+	request AllowDomainRequestObject,
+) (AllowDomainResponseObject, error) {
+	duration, err := time.ParseDuration(request.Params.Duration)
+	if err != nil {
+		return AllowDomain400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	err = i.control.AllowDomain(ctx, request.Params.Domain, duration)
+	if err != nil {
+		return AllowDomain400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	return AllowDomain200Response{}, nil
+}
+
 func (i *OpenAPIInterfaceImpl) EnableBlocking(ctx context.Context, _ EnableBlockingRequestObject,
 ) (EnableBlockingResponseObject, error) {
 	i.control.EnableBlocking(ctx)
@@ -142,6 +311,28 @@ func (i *OpenAPIInterfaceImpl) BlockingStatus(_ context.Context, _ BlockingStatu
 	return BlockingStatus200JSONResponse(result), nil
 }
 
+func (i *OpenAPIInterfaceImpl) BlockingSchedule(_ context.Context, _ BlockingScheduleRequestObject,
+) (BlockingScheduleResponseObject, error) {
+	schedule := i.control.BlockingSchedule()
+
+	result := make(BlockingSchedule200JSONResponse, 0, len(schedule))
+
+	for _, s := range schedule {
+		status := ApiBlockingScheduleStatus{
+			Group:  s.Group,
+			Active: s.Active,
+		}
+
+		if !s.NextChange.IsZero() {
+			status.NextChange = &s.NextChange
+		}
+
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
 func (i *OpenAPIInterfaceImpl) ListRefresh(_ context.Context,
 	_ ListRefreshRequestObject,
 ) (ListRefreshResponseObject, error) {
@@ -153,21 +344,78 @@ func (i *OpenAPIInterfaceImpl) ListRefresh(_ context.Context,
 	return ListRefresh200Response{}, nil
 }
 
+func (i *OpenAPIInterfaceImpl) ListExport(ctx context.Context,
+	request ListExportRequestObject,
+) (ListExportResponseObject, error) {
+	format, err := formats.ParseFormat(string(request.Params.Format))
+	if err != nil {
+		return ListExport400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	if request.Params.Type == nil && format != formats.FormatAdguard {
+		return ListExport400TextResponse(fmt.Sprintf("format '%s' needs a type", format)), nil
+	}
+
+	var groups []string
+	if request.Params.Groups != nil && len(*request.Params.Groups) > 0 {
+		groups = strings.Split(*request.Params.Groups, ",")
+	}
+
+	rules, err := i.exporter.LocalListRules(ctx, groups)
+	if err != nil {
+		return ListExport400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	if request.Params.Type != nil {
+		allow := *request.Params.Type == ListTypeAllow
+
+		rules = slices.DeleteFunc(rules, func(rule formats.Rule) bool {
+			return rule.Allow != allow
+		})
+	}
+
+	var sb strings.Builder
+
+	if err := formats.Write(&sb, format, rules); err != nil {
+		return nil, err
+	}
+
+	return ListExport200TextResponse(sb.String()), nil
+}
+
+func (i *OpenAPIInterfaceImpl) ListImport(_ context.Context,
+	request ListImportRequestObject,
+) (ListImportResponseObject, error) {
+	format, err := formats.ParseFormat(string(request.Params.Format))
+	if err != nil {
+		return ListImport400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	allow := request.Params.Type != nil && *request.Params.Type == ListTypeAllow
+
+	var body string
+	if request.Body != nil {
+		body = *request.Body
+	}
+
+	var sb strings.Builder
+
+	if err := formats.Import(&sb, strings.NewReader(body), format, allow); err != nil {
+		return ListImport400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	return ListImport200TextResponse(sb.String()), nil
+}
+
 func (i *OpenAPIInterfaceImpl) Query(ctx context.Context, request QueryRequestObject) (QueryResponseObject, error) {
 	qType := dns.Type(dns.StringToType[request.Body.Type])
 	if qType == dns.Type(dns.TypeNone) {
 		return Query400TextResponse(fmt.Sprintf("unknown query type '%s'", request.Body.Type)), nil
 	}
 
-	var (
-		serverHost string
-		clientIP   net.IP
-	)
-
-	httpReq, ok := ctx.Value(httpReqCtxKey{}).(*http.Request)
-	if ok {
-		serverHost = httpReq.Host
-		clientIP = util.HTTPClientIP(httpReq)
+	serverHost, clientIP, err := queryClient(ctx, request.Body.Client)
+	if err != nil {
+		return Query400TextResponse(err.Error()), nil
 	}
 
 	resp, err := i.querier.Query(ctx, serverHost, clientIP, dns.Fqdn(request.Body.Query), qType)
@@ -183,6 +431,75 @@ func (i *OpenAPIInterfaceImpl) Query(ctx context.Context, request QueryRequestOb
 	}), nil
 }
 
+func (i *OpenAPIInterfaceImpl) QueryTrace(
+	ctx context.Context, request QueryTraceRequestObject,
+) (QueryTraceResponseObject, error) {
+	typeName := "A"
+	if request.Params.Type != nil && *request.Params.Type != "" {
+		typeName = strings.ToUpper(*request.Params.Type)
+	}
+
+	qType := dns.Type(dns.StringToType[typeName])
+	if qType == dns.Type(dns.TypeNone) {
+		return QueryTrace400TextResponse(fmt.Sprintf("unknown query type '%s'", log.EscapeInput(typeName))), nil
+	}
+
+	if request.Params.Name == "" {
+		return QueryTrace400TextResponse("name is empty"), nil
+	}
+
+	serverHost, clientIP, err := queryClient(ctx, request.Params.Client)
+	if err != nil {
+		return QueryTrace400TextResponse(err.Error()), nil
+	}
+
+	resp, trace, err := i.querier.TraceQuery(ctx, serverHost, clientIP, dns.Fqdn(request.Params.Name), qType)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]ApiTraceStep, 0, len(trace.Steps))
+	for _, step := range trace.Steps {
+		steps = append(steps, ApiTraceStep{Resolver: step.Resolver, Message: step.Message})
+	}
+
+	lists := trace.Lists
+	if lists == nil {
+		lists = []string{}
+	}
+
+	return QueryTrace200JSONResponse(ApiQueryTrace{
+		Reason:            resp.Reason,
+		ResponseType:      resp.RType.String(),
+		Response:          util.AnswerToString(resp.Res.Answer),
+		ReturnCode:        dns.RcodeToString[resp.Res.Rcode],
+		Cache:             trace.Cache,
+		Lists:             lists,
+		UpstreamGroup:     trace.UpstreamGroup,
+		AuthenticatedData: resp.Res.AuthenticatedData,
+		Steps:             steps,
+	}), nil
+}
+
+// queryClient returns the host of the API request and the client IP to query for:
+// the given one, or the one of the API request
+func queryClient(ctx context.Context, client *string) (serverHost string, clientIP net.IP, err error) {
+	httpReq, ok := ctx.Value(httpReqCtxKey{}).(*http.Request)
+	if ok {
+		serverHost = httpReq.Host
+		clientIP = util.HTTPClientIP(httpReq)
+	}
+
+	if client != nil && *client != "" {
+		clientIP = net.ParseIP(*client)
+		if clientIP == nil {
+			return "", nil, fmt.Errorf("invalid client IP '%s'", log.EscapeInput(*client))
+		}
+	}
+
+	return serverHost, clientIP, nil
+}
+
 func (i *OpenAPIInterfaceImpl) CacheFlush(ctx context.Context,
 	_ CacheFlushRequestObject,
 ) (CacheFlushResponseObject, error) {
@@ -190,3 +507,197 @@ func (i *OpenAPIInterfaceImpl) CacheFlush(ctx context.Context,
 
 	return CacheFlush200Response{}, nil
 }
+
+func (i *OpenAPIInterfaceImpl) CacheEntries(_ context.Context,
+	request CacheEntriesRequestObject,
+) (CacheEntriesResponseObject, error) {
+	var name string
+
+	if request.Params.Name != nil {
+		name = *request.Params.Name
+	}
+
+	entries := i.cacheControl.CacheEntries(name)
+
+	result := make(CacheEntries200JSONResponse, 0, len(entries))
+
+	for _, e := range entries {
+		entry := ApiCacheEntry{
+			Name:       e.Name,
+			Type:       e.Type.String(),
+			TtlSec:     int(e.TTL.Seconds()),
+			Response:   util.AnswerToString(e.Response.Answer),
+			ReturnCode: dns.RcodeToString[e.Response.Rcode],
+		}
+
+		if e.Subnet != "" {
+			entry.Subnet = &e.Subnet
+		}
+
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
+
+func (i *OpenAPIInterfaceImpl) CacheEvict(ctx context.Context,
+	request CacheEvictRequestObject,
+) (CacheEvictResponseObject, error) {
+	removed := i.cacheControl.RemoveCacheEntries(ctx, request.Name)
+
+	return CacheEvict200JSONResponse(ApiCacheEvictResult{Removed: removed}), nil
+}
+
+func (i *OpenAPIInterfaceImpl) CacheStats(_ context.Context,
+	_ CacheStatsRequestObject,
+) (CacheStatsResponseObject, error) {
+	stats := i.cacheControl.CacheStats()
+
+	buckets := make([]ApiCacheTTLBucket, 0, len(stats.Buckets))
+
+	for _, b := range stats.Buckets {
+		bucket := ApiCacheTTLBucket{
+			MinTtlSec: int(b.MinTTL.Seconds()),
+			Hits:      int(b.Hits),
+			Misses:    int(b.Misses),
+		}
+
+		if b.MaxTTL > 0 {
+			maxTTL := int(b.MaxTTL.Seconds())
+			bucket.MaxTtlSec = &maxTTL
+		}
+
+		if lookups := b.Hits + b.Misses; lookups > 0 {
+			bucket.HitRatio = float32(float64(b.Hits) / float64(lookups))
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return CacheStats200JSONResponse(ApiCacheStats{
+		Entries:     stats.Entries,
+		MemoryBytes: stats.MemoryBytes,
+		Buckets:     buckets,
+	}), nil
+}
+
+func (i *OpenAPIInterfaceImpl) Info(_ context.Context, _ InfoRequestObject) (InfoResponseObject, error) {
+	buildInfo := util.GetBuildInfo()
+
+	return Info200JSONResponse(ApiInfo{
+		Version:      buildInfo.Version,
+		BuildTime:    buildInfo.BuildTime,
+		Architecture: buildInfo.Architecture,
+		GoVersion:    buildInfo.GoVersion,
+		BuildTags:    buildInfo.BuildTags,
+		ConfigHash:   i.info.ConfigHash(),
+	}), nil
+}
+
+func (i *OpenAPIInterfaceImpl) Configuration(_ context.Context,
+	_ ConfigurationRequestObject,
+) (ConfigurationResponseObject, error) {
+	return Configuration200TextResponse(i.info.Configuration()), nil
+}
+
+func (i *OpenAPIInterfaceImpl) RecentQueries(_ context.Context,
+	request RecentQueriesRequestObject,
+) (RecentQueriesResponseObject, error) {
+	limit := defaultRecentQueries
+
+	if request.Params.Limit != nil && *request.Params.Limit > 0 {
+		limit = *request.Params.Limit
+	}
+
+	entries := i.queryLog.RecentQueries(limit)
+
+	result := make(RecentQueries200JSONResponse, 0, len(entries))
+
+	for _, e := range entries {
+		result = append(result, toAPIQueryLogEntry(e))
+	}
+
+	return result, nil
+}
+
+func toAPIQueryLogEntry(e *querylog.LogEntry) ApiQueryLogEntry {
+	var categories *[]string
+	if len(e.Categories) != 0 {
+		categories = &e.Categories
+	}
+
+	return ApiQueryLogEntry{
+		Time:         e.Start,
+		ClientIP:     e.ClientIP,
+		ClientNames:  e.ClientNames,
+		DurationMs:   int(e.DurationMs),
+		Reason:       e.ResponseReason,
+		Categories:   categories,
+		ResponseType: e.ResponseType,
+		ResponseCode: e.ResponseCode,
+		Question:     e.QuestionName,
+		QuestionType: e.QuestionType,
+		Answer:       e.Answer,
+	}
+}
+
+func (i *OpenAPIInterfaceImpl) UpstreamStatus(_ context.Context,
+	_ UpstreamStatusRequestObject,
+) (UpstreamStatusResponseObject, error) {
+	upstreams := i.upstreams.UpstreamStatus()
+
+	result := make(UpstreamStatus200JSONResponse, 0, len(upstreams))
+
+	for _, u := range upstreams {
+		status := ApiUpstreamStatus{
+			Group:            u.Group,
+			Upstream:         u.Upstream,
+			Healthy:          u.Healthy,
+			ErrorRate:        float32(u.ErrorRate),
+			AverageLatencyMs: float32(float64(u.AverageLatency) / float64(time.Millisecond)),
+		}
+
+		if !u.LastCheck.IsZero() {
+			status.LastCheck = &u.LastCheck
+		}
+
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+func (i *OpenAPIInterfaceImpl) Snapshots(_ context.Context, _ SnapshotsRequestObject) (SnapshotsResponseObject, error) {
+	snapshots, err := i.snapshots.Snapshots()
+	if err != nil {
+		return Snapshots500TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	result := make(Snapshots200JSONResponse, 0, len(snapshots))
+
+	for _, s := range snapshots {
+		result = append(result, ApiSnapshot{
+			Name:       s.Name,
+			Created:    s.Created,
+			Reason:     s.Reason,
+			ConfigHash: s.ConfigHash,
+		})
+	}
+
+	return result, nil
+}
+
+func (i *OpenAPIInterfaceImpl) SnapshotRollback(ctx context.Context,
+	request SnapshotRollbackRequestObject,
+) (SnapshotRollbackResponseObject, error) {
+	err := i.snapshots.Rollback(ctx, request.Name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return SnapshotRollback404TextResponse(log.EscapeInput(err.Error())), nil
+		}
+
+		return SnapshotRollback400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	return SnapshotRollback200Response{}, nil
+}
@@ -3,11 +3,16 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io/fs"
 	"net"
 	"net/http"
 	"time"
 
+	"github.com/0xERR0R/blocky/lists/formats"
 	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/querylog"
+	"github.com/0xERR0R/blocky/stats"
 	"github.com/0xERR0R/blocky/util"
 	"github.com/go-chi/chi/v5"
 	"github.com/miekg/dns"
@@ -26,6 +31,10 @@ type ListRefreshMock struct {
 	mock.Mock
 }
 
+type ListExporterMock struct {
+	mock.Mock
+}
+
 type QuerierMock struct {
 	mock.Mock
 }
@@ -34,12 +43,43 @@ type CacheControlMock struct {
 	mock.Mock
 }
 
+type InfoProviderMock struct {
+	mock.Mock
+}
+
+type UpstreamStatusProviderMock struct {
+	mock.Mock
+}
+
+type SnapshotManagerMock struct {
+	mock.Mock
+}
+
+type QueryLogProviderMock struct {
+	mock.Mock
+}
+
+type StatsProviderMock struct {
+	mock.Mock
+}
+
 func (m *ListRefreshMock) RefreshLists() error {
 	args := m.Called()
 
 	return args.Error(0)
 }
 
+func (m *ListExporterMock) LocalListRules(_ context.Context, groups []string) ([]formats.Rule, error) {
+	args := m.Called(groups)
+
+	err := args.Error(1)
+	if err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).([]formats.Rule), nil
+}
+
 func (m *BlockingControlMock) EnableBlocking(_ context.Context) {
 	_ = m.Called()
 }
@@ -50,12 +90,24 @@ func (m *BlockingControlMock) DisableBlocking(_ context.Context, t time.Duration
 	return args.Error(0)
 }
 
+func (m *BlockingControlMock) AllowDomain(_ context.Context, domain string, t time.Duration) error {
+	args := m.Called(domain, t)
+
+	return args.Error(0)
+}
+
 func (m *BlockingControlMock) BlockingStatus() BlockingStatus {
 	args := m.Called()
 
 	return args.Get(0).(BlockingStatus)
 }
 
+func (m *BlockingControlMock) BlockingSchedule() []BlockingScheduleStatus {
+	args := m.Called()
+
+	return args.Get(0).([]BlockingScheduleStatus)
+}
+
 func (m *QuerierMock) Query(
 	ctx context.Context, serverHost string, clientIP net.IP, question string, qType dns.Type,
 ) (*model.Response, error) {
@@ -69,16 +121,113 @@ func (m *QuerierMock) Query(
 	return args.Get(0).(*model.Response), nil
 }
 
+func (m *QuerierMock) TraceQuery(
+	ctx context.Context, serverHost string, clientIP net.IP, question string, qType dns.Type,
+) (*model.Response, *model.Trace, error) {
+	args := m.Called(ctx, serverHost, clientIP, question, qType)
+
+	err := args.Error(2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return args.Get(0).(*model.Response), args.Get(1).(*model.Trace), nil
+}
+
 func (m *CacheControlMock) FlushCaches(ctx context.Context) {
 	_ = m.Called(ctx)
 }
 
+func (m *CacheControlMock) CacheEntries(name string) []CacheEntry {
+	args := m.Called(name)
+
+	return args.Get(0).([]CacheEntry)
+}
+
+func (m *CacheControlMock) RemoveCacheEntries(_ context.Context, name string) int {
+	args := m.Called(name)
+
+	return args.Int(0)
+}
+
+func (m *CacheControlMock) CacheStats() CacheStats {
+	args := m.Called()
+
+	return args.Get(0).(CacheStats)
+}
+
+func (m *InfoProviderMock) ConfigHash() string {
+	args := m.Called()
+
+	return args.String(0)
+}
+
+func (m *InfoProviderMock) Configuration() string {
+	args := m.Called()
+
+	return args.String(0)
+}
+
+func (m *QueryLogProviderMock) RecentQueries(limit int) []*querylog.LogEntry {
+	args := m.Called(limit)
+
+	return args.Get(0).([]*querylog.LogEntry)
+}
+
+func (m *QueryLogProviderMock) SubscribeQueries(size int) (<-chan *querylog.LogEntry, func()) {
+	args := m.Called(size)
+
+	return args.Get(0).(<-chan *querylog.LogEntry), args.Get(1).(func())
+}
+
+func (m *StatsProviderMock) StatsOverview(_ context.Context, period time.Duration) (stats.Overview, error) {
+	args := m.Called(period)
+
+	return args.Get(0).(stats.Overview), args.Error(1)
+}
+
+func (m *StatsProviderMock) TopStats(_ context.Context,
+	period time.Duration, kind stats.Kind, limit int,
+) ([]stats.Count, error) {
+	args := m.Called(period, kind, limit)
+
+	return args.Get(0).([]stats.Count), args.Error(1)
+}
+
+func (m *UpstreamStatusProviderMock) UpstreamStatus() []UpstreamStatus {
+	args := m.Called()
+
+	return args.Get(0).([]UpstreamStatus)
+}
+
+func (m *SnapshotManagerMock) Snapshots() ([]Snapshot, error) {
+	args := m.Called()
+
+	err := args.Error(1)
+	if err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).([]Snapshot), nil
+}
+
+func (m *SnapshotManagerMock) Rollback(_ context.Context, name string) error {
+	args := m.Called(name)
+
+	return args.Error(0)
+}
+
 var _ = Describe("API implementation tests", func() {
 	var (
 		blockingControlMock *BlockingControlMock
 		querierMock         *QuerierMock
 		listRefreshMock     *ListRefreshMock
+		listExporterMock    *ListExporterMock
 		cacheControlMock    *CacheControlMock
+		infoProviderMock    *InfoProviderMock
+		upstreamsMock       *UpstreamStatusProviderMock
+		snapshotsMock       *SnapshotManagerMock
+		queryLogMock        *QueryLogProviderMock
 		sut                 *OpenAPIInterfaceImpl
 
 		ctx      context.Context
@@ -92,14 +241,27 @@ var _ = Describe("API implementation tests", func() {
 		blockingControlMock = &BlockingControlMock{}
 		querierMock = &QuerierMock{}
 		listRefreshMock = &ListRefreshMock{}
+		listExporterMock = &ListExporterMock{}
 		cacheControlMock = &CacheControlMock{}
-		sut = NewOpenAPIInterfaceImpl(blockingControlMock, querierMock, listRefreshMock, cacheControlMock)
+		infoProviderMock = &InfoProviderMock{}
+		upstreamsMock = &UpstreamStatusProviderMock{}
+		snapshotsMock = &SnapshotManagerMock{}
+		queryLogMock = &QueryLogProviderMock{}
+		sut = NewOpenAPIInterfaceImpl(
+			blockingControlMock, querierMock, listRefreshMock, listExporterMock, cacheControlMock, infoProviderMock,
+			upstreamsMock, snapshotsMock, queryLogMock, nil,
+		)
 	})
 
 	AfterEach(func() {
 		blockingControlMock.AssertExpectations(GinkgoT())
 		querierMock.AssertExpectations(GinkgoT())
 		listRefreshMock.AssertExpectations(GinkgoT())
+		listExporterMock.AssertExpectations(GinkgoT())
+		infoProviderMock.AssertExpectations(GinkgoT())
+		upstreamsMock.AssertExpectations(GinkgoT())
+		snapshotsMock.AssertExpectations(GinkgoT())
+		queryLogMock.AssertExpectations(GinkgoT())
 	})
 
 	Describe("RegisterOpenAPIEndpoints", func() {
@@ -178,6 +340,32 @@ var _ = Describe("API implementation tests", func() {
 				Expect(err).Should(MatchError(expectedErr))
 			})
 
+			It("should use the client of the request", func() {
+				expectedErr := errors.New("test")
+				querierMock.On("Query", ctx, "", net.ParseIP("192.168.178.20"), "example.com.", A).Return(nil, expectedErr)
+
+				client := "192.168.178.20"
+
+				_, err := sut.Query(ctx, QueryRequestObject{
+					Body: &ApiQueryRequest{
+						Query: "example.com", Type: "A", Client: &client,
+					},
+				})
+				Expect(err).Should(MatchError(expectedErr))
+			})
+
+			It("should return 400 on an invalid client", func() {
+				client := "not-an-ip"
+
+				resp, err := sut.Query(ctx, QueryRequestObject{
+					Body: &ApiQueryRequest{
+						Query: "example.com", Type: "A", Client: &client,
+					},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(Query400TextResponse("invalid client IP 'not-an-ip'")))
+			})
+
 			It("should return 400 on wrong parameter", func() {
 				resp, err := sut.Query(ctx, QueryRequestObject{
 					Body: &ApiQueryRequest{
@@ -191,6 +379,96 @@ var _ = Describe("API implementation tests", func() {
 				Expect(resp).Should(Equal(Query400TextResponse("unknown query type 'WRONGTYPE'")))
 			})
 		})
+
+		When("QueryTrace is called", func() {
+			It("should return the trace", func() {
+				queryResponse, err := util.NewMsgWithAnswer("example.com.", 123, AAAA, "::")
+				Expect(err).Should(Succeed())
+
+				client := "192.168.178.20"
+				qType := "aaaa"
+
+				querierMock.On("TraceQuery", ctx, "", net.ParseIP(client), "example.com.", AAAA).Return(
+					&model.Response{Res: queryResponse, RType: model.ResponseTypeBLOCKED, Reason: "BLOCKED (ads)"},
+					&model.Trace{
+						Steps: []model.TraceStep{{Resolver: "blocking", Message: "blocked: BLOCKED (ads)"}},
+						Lists: []string{"ads"},
+					},
+					nil)
+
+				resp, err := sut.QueryTrace(ctx, QueryTraceRequestObject{
+					Params: QueryTraceParams{Name: "example.com", Type: &qType, Client: &client},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(QueryTrace200JSONResponse(ApiQueryTrace{
+					Reason:       "BLOCKED (ads)",
+					Response:     "AAAA (::)",
+					ResponseType: "BLOCKED",
+					ReturnCode:   "NOERROR",
+					Lists:        []string{"ads"},
+					Steps:        []ApiTraceStep{{Resolver: "blocking", Message: "blocked: BLOCKED (ads)"}},
+				})))
+			})
+
+			It("should trace an A query by default", func() {
+				queryResponse, err := util.NewMsgWithAnswer("example.com.", 123, A, "192.0.2.1")
+				Expect(err).Should(Succeed())
+
+				queryResponse.AuthenticatedData = true
+
+				querierMock.On("TraceQuery", ctx, "", net.IP(nil), "example.com.", A).Return(
+					&model.Response{Res: queryResponse, Reason: "RESOLVED (default)"},
+					&model.Trace{Cache: "MISS", UpstreamGroup: "default"},
+					nil)
+
+				resp, err := sut.QueryTrace(ctx, QueryTraceRequestObject{
+					Params: QueryTraceParams{Name: "example.com"},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(QueryTrace200JSONResponse(ApiQueryTrace{
+					Reason:            "RESOLVED (default)",
+					Response:          "A (192.0.2.1)",
+					ResponseType:      "RESOLVED",
+					ReturnCode:        "NOERROR",
+					Cache:             "MISS",
+					Lists:             []string{},
+					UpstreamGroup:     "default",
+					AuthenticatedData: true,
+					Steps:             []ApiTraceStep{},
+				})))
+			})
+
+			It("should return 400 on wrong parameters", func() {
+				wrongType := "WRONGTYPE"
+				client := "not-an-ip"
+
+				resp, err := sut.QueryTrace(ctx, QueryTraceRequestObject{
+					Params: QueryTraceParams{Name: "example.com", Type: &wrongType},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(QueryTrace400TextResponse("unknown query type 'WRONGTYPE'")))
+
+				resp, err = sut.QueryTrace(ctx, QueryTraceRequestObject{
+					Params: QueryTraceParams{Name: "example.com", Client: &client},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(QueryTrace400TextResponse("invalid client IP 'not-an-ip'")))
+
+				resp, err = sut.QueryTrace(ctx, QueryTraceRequestObject{})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(QueryTrace400TextResponse("name is empty")))
+			})
+
+			It("should fail if the query fails", func() {
+				expectedErr := errors.New("test")
+				querierMock.On("TraceQuery", ctx, "", net.IP(nil), "example.com.", A).Return(nil, nil, expectedErr)
+
+				_, err := sut.QueryTrace(ctx, QueryTraceRequestObject{
+					Params: QueryTraceParams{Name: "example.com"},
+				})
+				Expect(err).Should(MatchError(expectedErr))
+			})
+		})
 	})
 
 	Describe("Lists API", func() {
@@ -214,6 +492,84 @@ var _ = Describe("API implementation tests", func() {
 				Expect(resp).Should(Equal(ListRefresh500TextResponse("failed")))
 			})
 		})
+
+		When("List export is called", func() {
+			var rules []formats.Rule
+
+			BeforeEach(func() {
+				rules = []formats.Rule{
+					{Kind: formats.RuleKindWildcard, Value: "ads.com"},
+					{Allow: true, Kind: formats.RuleKindExact, Value: "good.com"},
+				}
+			})
+
+			It("should export both types in the AdGuard format", func() {
+				groups := "ads,kids"
+				listExporterMock.On("LocalListRules", []string{"ads", "kids"}).Return(rules, nil)
+
+				resp, err := sut.ListExport(ctx, ListExportRequestObject{
+					Params: ListExportParams{Format: ListFormatAdguard, Groups: &groups},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(ListExport200TextResponse("||ads.com^\n@@|good.com^\n")))
+			})
+
+			It("should only export rules of the type", func() {
+				listType := ListTypeAllow
+				listExporterMock.On("LocalListRules", []string(nil)).Return(rules, nil)
+
+				resp, err := sut.ListExport(ctx, ListExportRequestObject{
+					Params: ListExportParams{Format: ListFormatPihole, Type: &listType},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(ListExport200TextResponse("good.com\n")))
+			})
+
+			It("should return 400 without type for formats without allow rules", func() {
+				resp, err := sut.ListExport(ctx, ListExportRequestObject{
+					Params: ListExportParams{Format: ListFormatBlocky},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(ListExport400TextResponse("format 'blocky' needs a type")))
+			})
+
+			It("should return 400 on unknown group", func() {
+				listType := ListTypeDeny
+				listExporterMock.On("LocalListRules", []string(nil)).Return(nil, errors.New("group 'x' is unknown"))
+
+				resp, err := sut.ListExport(ctx, ListExportRequestObject{
+					Params: ListExportParams{Format: ListFormatBlocky, Type: &listType},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(ListExport400TextResponse("group 'x' is unknown")))
+			})
+		})
+
+		When("List import is called", func() {
+			It("should convert the rules of the type to the blocky format", func() {
+				listType := ListTypeAllow
+				body := "! comment\n||good.com^\n@@||better.com^\n||ads.com^$third-party\n"
+
+				resp, err := sut.ListImport(ctx, ListImportRequestObject{
+					Params: ListImportParams{Format: ListFormatAdguard, Type: &listType},
+					Body:   &body,
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(ListImport200TextResponse(
+					"# skipped line 4 '||ads.com^$third-party': unsupported rule\n" +
+						"# skipped rule of the other type: *.good.com\n" +
+						"*.better.com\n",
+				)))
+			})
+
+			It("should return 400 on unknown format", func() {
+				resp, err := sut.ListImport(ctx, ListImportRequestObject{
+					Params: ListImportParams{Format: "hosts"},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(BeAssignableToTypeOf(ListImport400TextResponse("")))
+			})
+		})
 	})
 
 	Describe("Control blocking status via API", func() {
@@ -273,6 +629,35 @@ var _ = Describe("API implementation tests", func() {
 				Expect(resp).Should(Equal(DisableBlocking400TextResponse("time: unknown unit \"sds\" in duration \"4sds\"")))
 			})
 		})
+		When("Allow domain is called", func() {
+			It("should return 200 on success", func() {
+				blockingControlMock.On("AllowDomain", "example.com", 5*time.Minute).Return(nil)
+
+				resp, err := sut.AllowDomain(ctx, AllowDomainRequestObject{
+					Params: AllowDomainParams{Domain: "example.com", Duration: "5m"},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(AllowDomain200Response{}))
+			})
+
+			It("should return 400 on failure", func() {
+				blockingControlMock.On("AllowDomain", "", 5*time.Minute).Return(errors.New("domain is empty"))
+
+				resp, err := sut.AllowDomain(ctx, AllowDomainRequestObject{
+					Params: AllowDomainParams{Duration: "5m"},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(AllowDomain400TextResponse("domain is empty")))
+			})
+
+			It("should return 400 on wrong duration parameter", func() {
+				resp, err := sut.AllowDomain(ctx, AllowDomainRequestObject{
+					Params: AllowDomainParams{Domain: "example.com", Duration: "5"},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(AllowDomain400TextResponse("time: missing unit in duration \"5\"")))
+			})
+		})
 		When("Enable blocking is called", func() {
 			It("should return 200 on success", func() {
 				blockingControlMock.On("EnableBlocking").Return()
@@ -302,6 +687,29 @@ var _ = Describe("API implementation tests", func() {
 				Expect(resp200.AutoEnableInSec).Should(HaveValue(BeNumerically("==", 47)))
 			})
 		})
+
+		When("Blocking schedule is called", func() {
+			It("should return 200 and the state of all scheduled groups", func() {
+				nextChange := time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC)
+
+				blockingControlMock.On("BlockingSchedule").Return([]BlockingScheduleStatus{
+					{Group: "gr1", Active: true, NextChange: nextChange},
+					{Group: "gr2"},
+				})
+
+				resp, err := sut.BlockingSchedule(ctx, BlockingScheduleRequestObject{})
+				Expect(err).Should(Succeed())
+				var resp200 BlockingSchedule200JSONResponse
+				Expect(resp).Should(BeAssignableToTypeOf(resp200))
+				resp200 = resp.(BlockingSchedule200JSONResponse)
+				Expect(resp200).Should(HaveLen(2))
+				Expect(resp200[0].Group).Should(Equal("gr1"))
+				Expect(resp200[0].Active).Should(BeTrue())
+				Expect(resp200[0].NextChange).Should(HaveValue(Equal(nextChange)))
+				Expect(resp200[1].Active).Should(BeFalse())
+				Expect(resp200[1].NextChange).Should(BeNil())
+			})
+		})
 	})
 
 	Describe("Cache API", func() {
@@ -314,5 +722,271 @@ var _ = Describe("API implementation tests", func() {
 				Expect(resp).Should(BeAssignableToTypeOf(resp200))
 			})
 		})
+
+		When("Cache entries are searched", func() {
+			It("should return the matching entries", func() {
+				msg, err := util.NewMsgWithAnswer("example.com.", 300, A, "1.2.3.4")
+				Expect(err).Should(Succeed())
+
+				cacheControlMock.On("CacheEntries", "example").Return([]CacheEntry{
+					{Name: "example.com", Type: A, TTL: 250 * time.Second, Response: msg},
+					{Name: "example.com", Type: A, Subnet: "10.0.0.0/24", TTL: time.Minute, Response: msg},
+				})
+
+				name := "example"
+				resp, err := sut.CacheEntries(ctx, CacheEntriesRequestObject{
+					Params: CacheEntriesParams{Name: &name},
+				})
+				Expect(err).Should(Succeed())
+
+				var resp200 CacheEntries200JSONResponse
+				Expect(resp).Should(BeAssignableToTypeOf(resp200))
+				resp200 = resp.(CacheEntries200JSONResponse)
+				Expect(resp200).Should(HaveLen(2))
+				Expect(resp200[0].Name).Should(Equal("example.com"))
+				Expect(resp200[0].Type).Should(Equal("A"))
+				Expect(resp200[0].TtlSec).Should(Equal(250))
+				Expect(resp200[0].Response).Should(Equal("A (1.2.3.4)"))
+				Expect(resp200[0].ReturnCode).Should(Equal("NOERROR"))
+				Expect(resp200[0].Subnet).Should(BeNil())
+				Expect(resp200[1].Subnet).Should(HaveValue(Equal("10.0.0.0/24")))
+			})
+
+			It("should return all entries without name", func() {
+				cacheControlMock.On("CacheEntries", "").Return([]CacheEntry{})
+
+				resp, err := sut.CacheEntries(ctx, CacheEntriesRequestObject{})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(BeEquivalentTo(CacheEntries200JSONResponse{}))
+			})
+		})
+
+		When("Cache entries are evicted", func() {
+			It("should return the number of removed entries", func() {
+				cacheControlMock.On("RemoveCacheEntries", "example.com").Return(3)
+
+				resp, err := sut.CacheEvict(ctx, CacheEvictRequestObject{Name: "example.com"})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(CacheEvict200JSONResponse{Removed: 3}))
+			})
+		})
+
+		When("Cache stats are called", func() {
+			It("should return the stats with the hit ratio", func() {
+				cacheControlMock.On("CacheStats").Return(CacheStats{
+					Entries:     2,
+					MemoryBytes: 100,
+					Buckets: []CacheTTLBucket{
+						{MinTTL: 0, MaxTTL: time.Minute, Hits: 3, Misses: 1},
+						{MinTTL: time.Minute},
+					},
+				})
+
+				resp, err := sut.CacheStats(ctx, CacheStatsRequestObject{})
+				Expect(err).Should(Succeed())
+
+				var resp200 CacheStats200JSONResponse
+				Expect(resp).Should(BeAssignableToTypeOf(resp200))
+				resp200 = resp.(CacheStats200JSONResponse)
+				Expect(resp200.Entries).Should(Equal(2))
+				Expect(resp200.MemoryBytes).Should(Equal(100))
+				Expect(resp200.Buckets).Should(HaveLen(2))
+				Expect(resp200.Buckets[0].MaxTtlSec).Should(HaveValue(Equal(60)))
+				Expect(resp200.Buckets[0].HitRatio).Should(BeNumerically("~", 0.75))
+				Expect(resp200.Buckets[1].MinTtlSec).Should(Equal(60))
+				Expect(resp200.Buckets[1].MaxTtlSec).Should(BeNil())
+				Expect(resp200.Buckets[1].HitRatio).Should(BeZero())
+			})
+		})
+	})
+
+	Describe("Info API", func() {
+		When("Info is called", func() {
+			It("should return the build information and config hash", func() {
+				infoProviderMock.On("ConfigHash").Return("abc123")
+
+				resp, err := sut.Info(ctx, InfoRequestObject{})
+				Expect(err).Should(Succeed())
+
+				var resp200 Info200JSONResponse
+				Expect(resp).Should(BeAssignableToTypeOf(resp200))
+				resp200 = resp.(Info200JSONResponse)
+				Expect(resp200.Version).Should(Equal(util.Version))
+				Expect(resp200.BuildTime).Should(Equal(util.BuildTime))
+				Expect(resp200.GoVersion).ShouldNot(BeEmpty())
+				Expect(resp200.BuildTags).ShouldNot(BeNil())
+				Expect(resp200.ConfigHash).Should(Equal("abc123"))
+			})
+		})
+	})
+
+	Describe("Configuration API", func() {
+		When("Configuration is called", func() {
+			It("should return the configuration", func() {
+				infoProviderMock.On("Configuration").Return("current configuration:\n")
+
+				resp, err := sut.Configuration(ctx, ConfigurationRequestObject{})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(Configuration200TextResponse("current configuration:\n")))
+			})
+		})
+	})
+
+	Describe("Recent queries API", func() {
+		var entries []*querylog.LogEntry
+
+		BeforeEach(func() {
+			entries = []*querylog.LogEntry{
+				{
+					Start:          time.Now(),
+					ClientIP:       "192.168.178.25",
+					ClientNames:    []string{"client1"},
+					DurationMs:     12,
+					ResponseReason: "BLOCKED (ads)",
+					Categories:     []string{"ads"},
+					ResponseType:   "BLOCKED",
+					ResponseCode:   "NOERROR",
+					QuestionName:   "example.com",
+					QuestionType:   "A",
+					Answer:         "A (0.0.0.0)",
+				},
+			}
+		})
+
+		When("no limit is passed", func() {
+			It("should return the default number of queries", func() {
+				queryLogMock.On("RecentQueries", defaultRecentQueries).Return(entries)
+
+				resp, err := sut.RecentQueries(ctx, RecentQueriesRequestObject{})
+				Expect(err).Should(Succeed())
+
+				var resp200 RecentQueries200JSONResponse
+				Expect(resp).Should(BeAssignableToTypeOf(resp200))
+				resp200 = resp.(RecentQueries200JSONResponse)
+				Expect(resp200).Should(HaveLen(1))
+
+				Expect(resp200[0].Time).Should(Equal(entries[0].Start))
+				Expect(resp200[0].ClientIP).Should(Equal("192.168.178.25"))
+				Expect(resp200[0].ClientNames).Should(Equal([]string{"client1"}))
+				Expect(resp200[0].DurationMs).Should(Equal(12))
+				Expect(resp200[0].Reason).Should(Equal("BLOCKED (ads)"))
+				Expect(resp200[0].Categories).Should(HaveValue(Equal([]string{"ads"})))
+				Expect(resp200[0].ResponseType).Should(Equal("BLOCKED"))
+				Expect(resp200[0].ResponseCode).Should(Equal("NOERROR"))
+				Expect(resp200[0].Question).Should(Equal("example.com"))
+				Expect(resp200[0].QuestionType).Should(Equal("A"))
+				Expect(resp200[0].Answer).Should(Equal("A (0.0.0.0)"))
+			})
+		})
+
+		When("a limit is passed", func() {
+			It("should pass it to the provider", func() {
+				queryLogMock.On("RecentQueries", 10).Return(entries)
+
+				limit := 10
+				resp, err := sut.RecentQueries(ctx, RecentQueriesRequestObject{
+					Params: RecentQueriesParams{Limit: &limit},
+				})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("Upstream status API", func() {
+		When("UpstreamStatus is called", func() {
+			It("should return the status of all upstreams", func() {
+				lastCheck := time.Now()
+
+				upstreamsMock.On("UpstreamStatus").Return([]UpstreamStatus{
+					{
+						Group:          "default",
+						Upstream:       "tcp+udp:1.1.1.1:53",
+						Healthy:        true,
+						ErrorRate:      0.25,
+						AverageLatency: 1500 * time.Microsecond,
+						LastCheck:      lastCheck,
+					},
+					{
+						Group:    "default",
+						Upstream: "tcp+udp:9.9.9.9:53",
+					},
+				})
+
+				resp, err := sut.UpstreamStatus(ctx, UpstreamStatusRequestObject{})
+				Expect(err).Should(Succeed())
+
+				var resp200 UpstreamStatus200JSONResponse
+				Expect(resp).Should(BeAssignableToTypeOf(resp200))
+				resp200 = resp.(UpstreamStatus200JSONResponse)
+				Expect(resp200).Should(HaveLen(2))
+
+				Expect(resp200[0].Group).Should(Equal("default"))
+				Expect(resp200[0].Upstream).Should(Equal("tcp+udp:1.1.1.1:53"))
+				Expect(resp200[0].Healthy).Should(BeTrue())
+				Expect(resp200[0].ErrorRate).Should(BeNumerically("~", 0.25))
+				Expect(resp200[0].AverageLatencyMs).Should(BeNumerically("~", 1.5))
+				Expect(resp200[0].LastCheck).Should(HaveValue(Equal(lastCheck)))
+
+				Expect(resp200[1].Healthy).Should(BeFalse())
+				Expect(resp200[1].LastCheck).Should(BeNil())
+			})
+		})
+	})
+
+	Describe("Snapshot API", func() {
+		When("Snapshots is called", func() {
+			It("should return all snapshots", func() {
+				created := time.Now()
+
+				snapshotsMock.On("Snapshots").Return([]Snapshot{
+					{Name: "20240501-102030-start", Created: created, Reason: "start", ConfigHash: "abc"},
+				}, nil)
+
+				resp, err := sut.Snapshots(ctx, SnapshotsRequestObject{})
+				Expect(err).Should(Succeed())
+
+				var resp200 Snapshots200JSONResponse
+				Expect(resp).Should(BeAssignableToTypeOf(resp200))
+				resp200 = resp.(Snapshots200JSONResponse)
+				Expect(resp200).Should(Equal(Snapshots200JSONResponse{
+					{Name: "20240501-102030-start", Created: created, Reason: "start", ConfigHash: "abc"},
+				}))
+			})
+
+			It("should return 500 on error", func() {
+				snapshotsMock.On("Snapshots").Return(nil, errors.New("boom"))
+
+				resp, err := sut.Snapshots(ctx, SnapshotsRequestObject{})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(Snapshots500TextResponse("boom")))
+			})
+		})
+
+		When("SnapshotRollback is called", func() {
+			It("should roll back to the snapshot", func() {
+				snapshotsMock.On("Rollback", "20240501-102030-start").Return(nil)
+
+				resp, err := sut.SnapshotRollback(ctx, SnapshotRollbackRequestObject{Name: "20240501-102030-start"})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(BeAssignableToTypeOf(SnapshotRollback200Response{}))
+			})
+
+			It("should return 404 for unknown snapshots", func() {
+				snapshotsMock.On("Rollback", "unknown").Return(fmt.Errorf("can't read snapshot: %w", fs.ErrNotExist))
+
+				resp, err := sut.SnapshotRollback(ctx, SnapshotRollbackRequestObject{Name: "unknown"})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(BeAssignableToTypeOf(SnapshotRollback404TextResponse("")))
+			})
+
+			It("should return 400 if the snapshot can't be restored", func() {
+				snapshotsMock.On("Rollback", "x").Return(errors.New("snapshots are disabled"))
+
+				resp, err := sut.SnapshotRollback(ctx, SnapshotRollbackRequestObject{Name: "x"})
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(Equal(SnapshotRollback400TextResponse("snapshots are disabled")))
+			})
+		})
 	})
 })
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/0xERR0R/blocky/api/admin"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/miekg/dns"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCInterfaceImpl implements the gRPC admin API with the same providers as the REST API
+type GRPCInterfaceImpl struct {
+	admin.UnimplementedAdminServiceServer
+
+	control      BlockingControl
+	querier      Querier
+	refresher    ListRefresher
+	cacheControl CacheControl
+}
+
+func NewGRPCInterfaceImpl(control BlockingControl,
+	querier Querier,
+	refresher ListRefresher,
+	cacheControl CacheControl,
+) *GRPCInterfaceImpl {
+	return &GRPCInterfaceImpl{
+		control:      control,
+		querier:      querier,
+		refresher:    refresher,
+		cacheControl: cacheControl,
+	}
+}
+
+func (i *GRPCInterfaceImpl) EnableBlocking(ctx context.Context,
+	_ *admin.EnableBlockingRequest,
+) (*admin.BlockingStatus, error) {
+	i.control.EnableBlocking(ctx)
+
+	return i.blockingStatus(), nil
+}
+
+func (i *GRPCInterfaceImpl) DisableBlocking(ctx context.Context,
+	request *admin.DisableBlockingRequest,
+) (*admin.BlockingStatus, error) {
+	var (
+		duration time.Duration
+		err      error
+	)
+
+	if request.GetDuration() != "" {
+		duration, err = time.ParseDuration(request.GetDuration())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, log.EscapeInput(err.Error()))
+		}
+	}
+
+	err = i.control.DisableBlocking(ctx, duration, request.GetGroups())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, log.EscapeInput(err.Error()))
+	}
+
+	return i.blockingStatus(), nil
+}
+
+func (i *GRPCInterfaceImpl) GetBlockingStatus(context.Context,
+	*admin.GetBlockingStatusRequest,
+) (*admin.BlockingStatus, error) {
+	return i.blockingStatus(), nil
+}
+
+func (i *GRPCInterfaceImpl) WatchBlockingStatus(_ *admin.WatchBlockingStatusRequest,
+	stream admin.AdminService_WatchBlockingStatusServer,
+) error {
+	changed := make(chan struct{}, 1)
+
+	onChange := func(bool) {
+		// the stream sends the latest status anyway, so changes while sending can be merged
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	if err := evt.Bus().Subscribe(evt.BlockingEnabledEvent, onChange); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	defer evt.Bus().Unsubscribe(evt.BlockingEnabledEvent, onChange) //nolint:errcheck
+
+	for {
+		if err := stream.Send(i.blockingStatus()); err != nil {
+			return err
+		}
+
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (i *GRPCInterfaceImpl) blockingStatus() *admin.BlockingStatus {
+	blStatus := i.control.BlockingStatus()
+
+	return &admin.BlockingStatus{
+		Enabled:         blStatus.Enabled,
+		DisabledGroups:  blStatus.DisabledGroups,
+		AutoEnableInSec: int32(blStatus.AutoEnableInSec), //nolint:gosec
+	}
+}
+
+func (i *GRPCInterfaceImpl) RefreshLists(context.Context,
+	*admin.RefreshListsRequest,
+) (*admin.RefreshListsResponse, error) {
+	if err := i.refresher.RefreshLists(); err != nil {
+		return nil, status.Error(codes.Internal, log.EscapeInput(err.Error()))
+	}
+
+	return &admin.RefreshListsResponse{}, nil
+}
+
+func (i *GRPCInterfaceImpl) Query(ctx context.Context, request *admin.QueryRequest) (*admin.QueryResponse, error) {
+	typeName := request.GetType()
+	if typeName == "" {
+		typeName = dns.TypeToString[dns.TypeA]
+	}
+
+	qType := dns.Type(dns.StringToType[typeName])
+	if qType == dns.Type(dns.TypeNone) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown query type '%s'", log.EscapeInput(typeName))
+	}
+
+	var (
+		serverHost string
+		clientIP   net.IP
+	)
+
+	if authority := metadata.ValueFromIncomingContext(ctx, ":authority"); len(authority) != 0 {
+		serverHost = authority[0]
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if addr, ok := p.Addr.(*net.TCPAddr); ok {
+			clientIP = addr.IP
+		}
+	}
+
+	if request.GetClient() != "" {
+		clientIP = net.ParseIP(request.GetClient())
+		if clientIP == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid client IP '%s'", log.EscapeInput(request.GetClient()))
+		}
+	}
+
+	resp, err := i.querier.Query(ctx, serverHost, clientIP, dns.Fqdn(request.GetQuery()), qType)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "query failed: %s", err)
+	}
+
+	return &admin.QueryResponse{
+		Reason:       resp.Reason,
+		ResponseType: resp.RType.String(),
+		Response:     util.AnswerToString(resp.Res.Answer),
+		ReturnCode:   dns.RcodeToString[resp.Res.Rcode],
+	}, nil
+}
+
+func (i *GRPCInterfaceImpl) FlushCache(ctx context.Context,
+	_ *admin.FlushCacheRequest,
+) (*admin.FlushCacheResponse, error) {
+	i.cacheControl.FlushCaches(ctx)
+
+	return &admin.FlushCacheResponse{}, nil
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/0xERR0R/blocky/api/admin"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("gRPC API implementation tests", func() {
+	var (
+		blockingControlMock *BlockingControlMock
+		querierMock         *QuerierMock
+		listRefreshMock     *ListRefreshMock
+		cacheControlMock    *CacheControlMock
+
+		client admin.AdminServiceClient
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		var cancelFn context.CancelFunc
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		blockingControlMock = &BlockingControlMock{}
+		querierMock = &QuerierMock{}
+		listRefreshMock = &ListRefreshMock{}
+		cacheControlMock = &CacheControlMock{}
+
+		srv := grpc.NewServer()
+		admin.RegisterAdminServiceServer(srv,
+			NewGRPCInterfaceImpl(blockingControlMock, querierMock, listRefreshMock, cacheControlMock))
+
+		listener := bufconn.Listen(1024 * 1024)
+
+		go func() { _ = srv.Serve(listener) }()
+
+		DeferCleanup(srv.Stop)
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).Should(Succeed())
+		DeferCleanup(conn.Close)
+
+		client = admin.NewAdminServiceClient(conn)
+	})
+
+	AfterEach(func() {
+		blockingControlMock.AssertExpectations(GinkgoT())
+		querierMock.AssertExpectations(GinkgoT())
+		listRefreshMock.AssertExpectations(GinkgoT())
+		cacheControlMock.AssertExpectations(GinkgoT())
+	})
+
+	Describe("Blocking", func() {
+		It("should enable blocking and return the status", func() {
+			blockingControlMock.On("EnableBlocking").Return()
+			blockingControlMock.On("BlockingStatus").Return(BlockingStatus{Enabled: true})
+
+			resp, err := client.EnableBlocking(ctx, &admin.EnableBlockingRequest{})
+			Expect(err).Should(Succeed())
+			Expect(resp.GetEnabled()).Should(BeTrue())
+		})
+
+		It("should disable blocking of groups for a duration", func() {
+			blockingControlMock.On("DisableBlocking", 5*time.Minute, []string{"ads", "kids"}).Return(nil)
+			blockingControlMock.On("BlockingStatus").Return(BlockingStatus{
+				Enabled:         false,
+				DisabledGroups:  []string{"ads", "kids"},
+				AutoEnableInSec: 300,
+			})
+
+			resp, err := client.DisableBlocking(ctx, &admin.DisableBlockingRequest{
+				Duration: "5m",
+				Groups:   []string{"ads", "kids"},
+			})
+			Expect(err).Should(Succeed())
+			Expect(resp.GetEnabled()).Should(BeFalse())
+			Expect(resp.GetDisabledGroups()).Should(Equal([]string{"ads", "kids"}))
+			Expect(resp.GetAutoEnableInSec()).Should(BeNumerically("==", 300))
+		})
+
+		It("should reject an invalid duration", func() {
+			_, err := client.DisableBlocking(ctx, &admin.DisableBlockingRequest{Duration: "5 minutes"})
+			Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+		})
+
+		It("should return the error of the blocking control", func() {
+			blockingControlMock.On("DisableBlocking", time.Duration(0), []string(nil)).
+				Return(errors.New("group 'unknown' does not exist"))
+
+			_, err := client.DisableBlocking(ctx, &admin.DisableBlockingRequest{})
+			Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+			Expect(err).Should(MatchError(ContainSubstring("group 'unknown' does not exist")))
+		})
+
+		It("should stream the status on changes", func() {
+			blockingControlMock.On("BlockingStatus").Return(BlockingStatus{Enabled: true}).Once()
+			blockingControlMock.On("BlockingStatus").Return(BlockingStatus{Enabled: false})
+
+			stream, err := client.WatchBlockingStatus(ctx, &admin.WatchBlockingStatusRequest{})
+			Expect(err).Should(Succeed())
+
+			first, err := stream.Recv()
+			Expect(err).Should(Succeed())
+			Expect(first.GetEnabled()).Should(BeTrue())
+
+			evt.Bus().Publish(evt.BlockingEnabledEvent, false)
+
+			second, err := stream.Recv()
+			Expect(err).Should(Succeed())
+			Expect(second.GetEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("RefreshLists", func() {
+		It("should refresh the lists", func() {
+			listRefreshMock.On("RefreshLists").Return(nil)
+
+			_, err := client.RefreshLists(ctx, &admin.RefreshListsRequest{})
+			Expect(err).Should(Succeed())
+		})
+
+		It("should return an error if the refresh fails", func() {
+			listRefreshMock.On("RefreshLists").Return(errors.New("failed"))
+
+			_, err := client.RefreshLists(ctx, &admin.RefreshListsRequest{})
+			Expect(status.Code(err)).Should(Equal(codes.Internal))
+		})
+	})
+
+	Describe("Query", func() {
+		It("should resolve the query", func() {
+			queryResponse, err := util.NewMsgWithAnswer("example.com.", 123, A, "1.2.3.4")
+			Expect(err).Should(Succeed())
+
+			querierMock.On("Query", mock.Anything, mock.Anything, net.ParseIP("192.168.178.20"), "example.com.", A).
+				Return(&model.Response{Res: queryResponse, Reason: "reason"}, nil)
+
+			resp, err := client.Query(ctx, &admin.QueryRequest{Query: "example.com", Client: "192.168.178.20"})
+			Expect(err).Should(Succeed())
+			Expect(resp.GetReason()).Should(Equal("reason"))
+			Expect(resp.GetResponse()).Should(Equal("A (1.2.3.4)"))
+			Expect(resp.GetResponseType()).Should(Equal("RESOLVED"))
+			Expect(resp.GetReturnCode()).Should(Equal("NOERROR"))
+		})
+
+		It("should reject an unknown query type", func() {
+			_, err := client.Query(ctx, &admin.QueryRequest{Query: "example.com", Type: "WRONGTYPE"})
+			Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+		})
+
+		It("should reject an invalid client", func() {
+			_, err := client.Query(ctx, &admin.QueryRequest{Query: "example.com", Client: "not-an-ip"})
+			Expect(status.Code(err)).Should(Equal(codes.InvalidArgument))
+		})
+	})
+
+	Describe("FlushCache", func() {
+		It("should flush the cache", func() {
+			cacheControlMock.On("FlushCaches", mock.Anything).Return()
+
+			_, err := client.FlushCache(ctx, &admin.FlushCacheRequest{})
+			Expect(err).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/querylog"
+)
+
+const (
+	queryStreamBufferSize = 100
+	queryStreamKeepAlive  = 15 * time.Second
+)
+
+func (i *OpenAPIInterfaceImpl) QueryStream(ctx context.Context,
+	request QueryStreamRequestObject,
+) (QueryStreamResponseObject, error) {
+	return &queryStreamResponse{
+		ctx:      ctx,
+		queryLog: i.queryLog,
+		filter:   newQueryStreamFilter(request.Params),
+	}, nil
+}
+
+// queryStreamFilter selects the entries of the query stream, empty fields match all entries
+type queryStreamFilter struct {
+	client        string
+	domain        string
+	responseTypes []string
+}
+
+func newQueryStreamFilter(params QueryStreamParams) queryStreamFilter {
+	var res queryStreamFilter
+
+	if params.Client != nil {
+		res.client = strings.ToLower(strings.TrimSpace(*params.Client))
+	}
+
+	if params.Domain != nil {
+		res.domain = strings.ToLower(strings.TrimSpace(*params.Domain))
+	}
+
+	if params.ResponseType != nil {
+		for _, t := range strings.Split(*params.ResponseType, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				res.responseTypes = append(res.responseTypes, t)
+			}
+		}
+	}
+
+	return res
+}
+
+func (f *queryStreamFilter) matches(e *querylog.LogEntry) bool {
+	if f.domain != "" && !strings.Contains(strings.ToLower(e.QuestionName), f.domain) {
+		return false
+	}
+
+	if f.client != "" && !f.matchesClient(e) {
+		return false
+	}
+
+	if len(f.responseTypes) == 0 {
+		return true
+	}
+
+	for _, t := range f.responseTypes {
+		if strings.EqualFold(t, e.ResponseType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *queryStreamFilter) matchesClient(e *querylog.LogEntry) bool {
+	if strings.Contains(e.ClientIP, f.client) {
+		return true
+	}
+
+	for _, name := range e.ClientNames {
+		if strings.Contains(strings.ToLower(name), f.client) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// queryStreamResponse sends the new query log entries as Server-Sent Events until the request is done
+type queryStreamResponse struct {
+	ctx      context.Context
+	queryLog QueryLogProvider
+	filter   queryStreamFilter
+}
+
+func (r *queryStreamResponse) VisitQueryStreamResponse(w http.ResponseWriter) error {
+	rc := http.NewResponseController(w)
+
+	// the stream runs longer than the write timeout of the server
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	entries, cancel := r.queryLog.SubscribeQueries(queryStreamBufferSize)
+	defer cancel()
+
+	w.Header().Set("content-type", "text/event-stream")
+	w.Header().Set("cache-control", "no-cache")
+	// disable response buffering of nginx
+	w.Header().Set("x-accel-buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(queryStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		// errors mean that the client is gone, the request context ends then
+		_ = rc.Flush()
+
+		select {
+		case <-r.ctx.Done():
+			return nil
+
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+
+		case e, ok := <-entries:
+			if !ok {
+				return nil
+			}
+
+			if !r.filter.matches(e) {
+				continue
+			}
+
+			data, err := json.Marshal(toAPIQueryLogEntry(e))
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+	}
+}
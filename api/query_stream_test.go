@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/0xERR0R/blocky/querylog"
+	"github.com/go-chi/chi/v5"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Query stream", func() {
+	Describe("QueryStream endpoint", func() {
+		var (
+			queryLogMock *QueryLogProviderMock
+			entries      chan *querylog.LogEntry
+			canceled     chan struct{}
+			baseURL      string
+		)
+
+		BeforeEach(func() {
+			entries = make(chan *querylog.LogEntry, 10)
+			canceled = make(chan struct{})
+
+			queryLogMock = &QueryLogProviderMock{}
+			queryLogMock.On("SubscribeQueries", queryStreamBufferSize).
+				Return((<-chan *querylog.LogEntry)(entries), func() { close(canceled) })
+
+			rtr := chi.NewRouter()
+			RegisterOpenAPIEndpoints(rtr, NewOpenAPIInterfaceImpl(nil, nil, nil, nil, nil, nil, nil, nil, queryLogMock, nil))
+
+			srv := httptest.NewServer(rtr)
+			DeferCleanup(srv.Close)
+
+			baseURL = srv.URL
+		})
+
+		stream := func(query string) (*http.Response, *bufio.Reader) {
+			ctx, cancel := context.WithCancel(context.Background())
+			DeferCleanup(cancel)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/queries/stream"+query, nil)
+			Expect(err).Should(Succeed())
+
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).Should(Succeed())
+			DeferCleanup(resp.Body.Close)
+
+			return resp, bufio.NewReader(resp.Body)
+		}
+
+		nextEvent := func(reader *bufio.Reader) ApiQueryLogEntry {
+			line, err := reader.ReadString('\n')
+			Expect(err).Should(Succeed())
+			Expect(line).Should(HavePrefix("data: "))
+
+			var entry ApiQueryLogEntry
+			Expect(json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &entry)).Should(Succeed())
+
+			empty, err := reader.ReadString('\n')
+			Expect(err).Should(Succeed())
+			Expect(empty).Should(Equal("\n"))
+
+			return entry
+		}
+
+		It("should send the matching entries as events", func() {
+			resp, reader := stream("?responseType=BLOCKED")
+			Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+			Expect(resp.Header.Get("content-type")).Should(Equal("text/event-stream"))
+
+			entries <- &querylog.LogEntry{QuestionName: "allowed.com", ResponseType: "RESOLVED"}
+			entries <- &querylog.LogEntry{
+				QuestionName: "blocked.com", ResponseType: "BLOCKED", ClientIP: "192.168.178.20",
+				ClientNames: []string{"laptop"}, DurationMs: 3,
+			}
+
+			entry := nextEvent(reader)
+			Expect(entry.Question).Should(Equal("blocked.com"))
+			Expect(entry.ClientIP).Should(Equal("192.168.178.20"))
+			Expect(entry.ClientNames).Should(Equal([]string{"laptop"}))
+			Expect(entry.DurationMs).Should(Equal(3))
+		})
+
+		It("should end the subscription when the client disconnects", func() {
+			resp, _ := stream("")
+			Expect(resp.Body.Close()).Should(Succeed())
+
+			Eventually(canceled).Should(BeClosed())
+		})
+	})
+
+	DescribeTable("filter",
+		func(params QueryStreamParams, entry querylog.LogEntry, expected bool) {
+			filter := newQueryStreamFilter(params)
+
+			Expect(filter.matches(&entry)).Should(Equal(expected))
+		},
+		Entry("without filter", QueryStreamParams{}, querylog.LogEntry{}, true),
+		Entry("domain contained", QueryStreamParams{Domain: ptrOf("Example")},
+			querylog.LogEntry{QuestionName: "www.example.com"}, true),
+		Entry("domain not contained", QueryStreamParams{Domain: ptrOf("example")},
+			querylog.LogEntry{QuestionName: "www.blocky.com"}, false),
+		Entry("client IP", QueryStreamParams{Client: ptrOf("192.168.178.")},
+			querylog.LogEntry{ClientIP: "192.168.178.20"}, true),
+		Entry("client name", QueryStreamParams{Client: ptrOf("laptop")},
+			querylog.LogEntry{ClientIP: "192.168.178.20", ClientNames: []string{"Laptop-Anna"}}, true),
+		Entry("other client", QueryStreamParams{Client: ptrOf("laptop")},
+			querylog.LogEntry{ClientIP: "192.168.178.20", ClientNames: []string{"phone"}}, false),
+		Entry("one of the response types", QueryStreamParams{ResponseType: ptrOf("blocked, CACHED")},
+			querylog.LogEntry{ResponseType: "CACHED"}, true),
+		Entry("other response type", QueryStreamParams{ResponseType: ptrOf("BLOCKED")},
+			querylog.LogEntry{ResponseType: "RESOLVED"}, false),
+	)
+})
+
+func ptrOf[T any](v T) *T {
+	return &v
+}
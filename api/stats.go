@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/stats"
+)
+
+const (
+	defaultStatsPeriod = 24 * time.Hour
+	defaultStatsLimit  = 10
+)
+
+func (i *OpenAPIInterfaceImpl) StatsOverview(ctx context.Context,
+	request StatsOverviewRequestObject,
+) (StatsOverviewResponseObject, error) {
+	period, err := statsPeriod(request.Params.Period)
+	if err != nil {
+		return StatsOverview400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	overview, err := i.stats.StatsOverview(ctx, period)
+	if err != nil {
+		if errors.Is(err, stats.ErrDisabled) {
+			return StatsOverview404TextResponse(err.Error()), nil
+		}
+
+		return nil, err
+	}
+
+	timeline := make([]ApiStatsCounters, 0, len(overview.Timeline))
+
+	for _, t := range overview.Timeline {
+		timeline = append(timeline, ApiStatsCounters{
+			Time:    t.Time,
+			Total:   int(t.Total),
+			Blocked: int(t.Blocked),
+			Cached:  int(t.Cached),
+		})
+	}
+
+	return StatsOverview200JSONResponse{
+		From:         overview.From,
+		Total:        int(overview.Total),
+		Blocked:      int(overview.Blocked),
+		Cached:       int(overview.Cached),
+		BlockedRatio: float32(overview.BlockedRatio()),
+		Clients:      overview.Clients,
+		Domains:      overview.Domains,
+		Timeline:     timeline,
+	}, nil
+}
+
+func (i *OpenAPIInterfaceImpl) StatsTopDomains(ctx context.Context,
+	request StatsTopDomainsRequestObject,
+) (StatsTopDomainsResponseObject, error) {
+	period, err := statsPeriod(request.Params.Period)
+	if err != nil {
+		return StatsTopDomains400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	kind := stats.KindDomains
+
+	if request.Params.Blocked != nil && *request.Params.Blocked {
+		kind = stats.KindBlockedDomains
+	}
+
+	counts, err := i.stats.TopStats(ctx, period, kind, statsLimit(request.Params.Limit))
+	if err != nil {
+		if errors.Is(err, stats.ErrDisabled) {
+			return StatsTopDomains404TextResponse(err.Error()), nil
+		}
+
+		return nil, err
+	}
+
+	return StatsTopDomains200JSONResponse(toAPIStatsCounts(counts)), nil
+}
+
+func (i *OpenAPIInterfaceImpl) StatsTopClients(ctx context.Context,
+	request StatsTopClientsRequestObject,
+) (StatsTopClientsResponseObject, error) {
+	period, err := statsPeriod(request.Params.Period)
+	if err != nil {
+		return StatsTopClients400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	counts, err := i.stats.TopStats(ctx, period, stats.KindClients, statsLimit(request.Params.Limit))
+	if err != nil {
+		if errors.Is(err, stats.ErrDisabled) {
+			return StatsTopClients404TextResponse(err.Error()), nil
+		}
+
+		return nil, err
+	}
+
+	return StatsTopClients200JSONResponse(toAPIStatsCounts(counts)), nil
+}
+
+func (i *OpenAPIInterfaceImpl) StatsTopCategories(ctx context.Context,
+	request StatsTopCategoriesRequestObject,
+) (StatsTopCategoriesResponseObject, error) {
+	period, err := statsPeriod(request.Params.Period)
+	if err != nil {
+		return StatsTopCategories400TextResponse(log.EscapeInput(err.Error())), nil
+	}
+
+	counts, err := i.stats.TopStats(ctx, period, stats.KindBlockedCategories, statsLimit(request.Params.Limit))
+	if err != nil {
+		if errors.Is(err, stats.ErrDisabled) {
+			return StatsTopCategories404TextResponse(err.Error()), nil
+		}
+
+		return nil, err
+	}
+
+	return StatsTopCategories200JSONResponse(toAPIStatsCounts(counts)), nil
+}
+
+func statsPeriod(param *string) (time.Duration, error) {
+	if param == nil {
+		return defaultStatsPeriod, nil
+	}
+
+	period, err := time.ParseDuration(*param)
+	if err != nil {
+		return 0, fmt.Errorf("invalid period: %w", err)
+	}
+
+	if period <= 0 {
+		return 0, fmt.Errorf("invalid period '%s': must be positive", *param)
+	}
+
+	return period, nil
+}
+
+func statsLimit(param *int) int {
+	if param != nil && *param > 0 {
+		return *param
+	}
+
+	return defaultStatsLimit
+}
+
+func toAPIStatsCounts(counts []stats.Count) []ApiStatsCount {
+	res := make([]ApiStatsCount, 0, len(counts))
+
+	for _, c := range counts {
+		res = append(res, ApiStatsCount{Name: c.Key, Count: int(c.Count)})
+	}
+
+	return res
+}
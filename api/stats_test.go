@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xERR0R/blocky/stats"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Statistics endpoints", func() {
+	var (
+		statsMock *StatsProviderMock
+		sut       *OpenAPIInterfaceImpl
+	)
+
+	BeforeEach(func() {
+		statsMock = &StatsProviderMock{}
+		sut = NewOpenAPIInterfaceImpl(nil, nil, nil, nil, nil, nil, nil, nil, nil, statsMock)
+	})
+
+	AfterEach(func() {
+		statsMock.AssertExpectations(GinkgoT())
+	})
+
+	Describe("StatsOverview", func() {
+		It("should return the overview of the default period", func(ctx context.Context) {
+			from := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+			statsMock.On("StatsOverview", 24*time.Hour).Return(stats.Overview{
+				Counters: stats.Counters{Total: 4, Blocked: 1, Cached: 2},
+				From:     from,
+				Clients:  2,
+				Domains:  3,
+				Timeline: []stats.TimeCounters{
+					{Counters: stats.Counters{Total: 4, Blocked: 1, Cached: 2}, Time: from},
+				},
+			}, nil)
+
+			resp, err := sut.StatsOverview(ctx, StatsOverviewRequestObject{})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(Equal(StatsOverview200JSONResponse{
+				From:         from,
+				Total:        4,
+				Blocked:      1,
+				Cached:       2,
+				BlockedRatio: 0.25,
+				Clients:      2,
+				Domains:      3,
+				Timeline:     []ApiStatsCounters{{Time: from, Total: 4, Blocked: 1, Cached: 2}},
+			}))
+		})
+
+		It("should return 400 for an invalid period", func(ctx context.Context) {
+			resp, err := sut.StatsOverview(ctx, StatsOverviewRequestObject{
+				Params: StatsOverviewParams{Period: ptrOf("yesterday")},
+			})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(BeAssignableToTypeOf(StatsOverview400TextResponse("")))
+
+			resp, err = sut.StatsOverview(ctx, StatsOverviewRequestObject{
+				Params: StatsOverviewParams{Period: ptrOf("-1h")},
+			})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(BeAssignableToTypeOf(StatsOverview400TextResponse("")))
+		})
+
+		It("should return 404 if statistics are disabled", func(ctx context.Context) {
+			statsMock.On("StatsOverview", time.Hour).Return(stats.Overview{}, stats.ErrDisabled)
+
+			resp, err := sut.StatsOverview(ctx, StatsOverviewRequestObject{
+				Params: StatsOverviewParams{Period: ptrOf("1h")},
+			})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(BeAssignableToTypeOf(StatsOverview404TextResponse("")))
+		})
+
+		It("should pass other errors", func(ctx context.Context) {
+			statsMock.On("StatsOverview", 24*time.Hour).Return(stats.Overview{}, errors.New("redis down"))
+
+			_, err := sut.StatsOverview(ctx, StatsOverviewRequestObject{})
+			Expect(err).Should(MatchError("redis down"))
+		})
+	})
+
+	Describe("StatsTopDomains", func() {
+		It("should return the top domains", func(ctx context.Context) {
+			statsMock.On("TopStats", 24*time.Hour, stats.KindDomains, 10).
+				Return([]stats.Count{{Key: "example.com", Count: 3}}, nil)
+
+			resp, err := sut.StatsTopDomains(ctx, StatsTopDomainsRequestObject{})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(Equal(StatsTopDomains200JSONResponse{{Name: "example.com", Count: 3}}))
+		})
+
+		It("should return the top blocked domains with the limit", func(ctx context.Context) {
+			statsMock.On("TopStats", time.Hour, stats.KindBlockedDomains, 5).Return([]stats.Count{}, nil)
+
+			resp, err := sut.StatsTopDomains(ctx, StatsTopDomainsRequestObject{
+				Params: StatsTopDomainsParams{Period: ptrOf("1h"), Limit: ptrOf(5), Blocked: ptrOf(true)},
+			})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(Equal(StatsTopDomains200JSONResponse{}))
+		})
+
+		It("should return 404 if statistics are disabled", func(ctx context.Context) {
+			statsMock.On("TopStats", 24*time.Hour, stats.KindDomains, 10).Return([]stats.Count(nil), stats.ErrDisabled)
+
+			resp, err := sut.StatsTopDomains(ctx, StatsTopDomainsRequestObject{})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(BeAssignableToTypeOf(StatsTopDomains404TextResponse("")))
+		})
+	})
+
+	Describe("StatsTopClients", func() {
+		It("should return the top clients", func(ctx context.Context) {
+			statsMock.On("TopStats", 24*time.Hour, stats.KindClients, 10).
+				Return([]stats.Count{{Key: "laptop", Count: 7}, {Key: "192.168.178.20", Count: 1}}, nil)
+
+			resp, err := sut.StatsTopClients(ctx, StatsTopClientsRequestObject{
+				Params: StatsTopClientsParams{Limit: ptrOf(0)},
+			})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(Equal(StatsTopClients200JSONResponse{
+				{Name: "laptop", Count: 7},
+				{Name: "192.168.178.20", Count: 1},
+			}))
+		})
+
+		It("should return 400 for an invalid period", func(ctx context.Context) {
+			resp, err := sut.StatsTopClients(ctx, StatsTopClientsRequestObject{
+				Params: StatsTopClientsParams{Period: ptrOf("7d")},
+			})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(BeAssignableToTypeOf(StatsTopClients400TextResponse("")))
+		})
+	})
+
+	Describe("StatsTopCategories", func() {
+		It("should return the top categories of blocked queries", func(ctx context.Context) {
+			statsMock.On("TopStats", time.Hour, stats.KindBlockedCategories, 10).
+				Return([]stats.Count{{Key: "ads", Count: 7}, {Key: "tracking", Count: 1}}, nil)
+
+			resp, err := sut.StatsTopCategories(ctx, StatsTopCategoriesRequestObject{
+				Params: StatsTopCategoriesParams{Period: ptrOf("1h")},
+			})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(Equal(StatsTopCategories200JSONResponse{
+				{Name: "ads", Count: 7},
+				{Name: "tracking", Count: 1},
+			}))
+		})
+
+		It("should return 404 if statistics are disabled", func(ctx context.Context) {
+			statsMock.On("TopStats", 24*time.Hour, stats.KindBlockedCategories, 10).
+				Return([]stats.Count(nil), stats.ErrDisabled)
+
+			resp, err := sut.StatsTopCategories(ctx, StatsTopCategoriesRequestObject{})
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(BeAssignableToTypeOf(StatsTopCategories404TextResponse("")))
+		})
+	})
+})
@@ -12,6 +12,13 @@ type ExpiringCache[T any] interface {
 	// TotalCount returns the total count of valid (not expired) elements
 	TotalCount() int
 
+	// Entries calls fn for each valid (not expired) entry with its remained TTL until fn returns false.
+	// Entries are not marked as used, so the LRU order doesn't change.
+	Entries(fn func(key string, val *T, ttl time.Duration) bool)
+
+	// Remove removes the entry with the passed key, if it is cached
+	Remove(key string)
+
 	// Clear removes all cache entries
 	Clear()
 }
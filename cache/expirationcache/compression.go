@@ -0,0 +1,162 @@
+package expirationcache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+)
+
+// Codec serializes cache values, so idle elements can be stored compressed (see `Options.CompressIdleAfter`):
+// they are S2 (snappy compatible) compressed during the periodic cleanup, and decompressed again on their next access.
+// Size estimates the memory of a value for `Options.MaxMemory`.
+type Codec[T any] interface {
+	Encode(val *T) []byte
+	Decode(data []byte) (*T, error)
+	Size(val *T) int
+}
+
+// BytesCodec is the Codec for caches of byte slices
+type BytesCodec struct{}
+
+// Encode implements `Codec`.
+func (BytesCodec) Encode(val *[]byte) []byte {
+	return *val
+}
+
+// Decode implements `Codec`.
+func (BytesCodec) Decode(data []byte) (*[]byte, error) {
+	return &data, nil
+}
+
+// Size implements `Codec`.
+func (BytesCodec) Size(val *[]byte) int {
+	return len(*val)
+}
+
+// bytesCodecFor returns the `BytesCodec` if T is []byte, nil otherwise
+func bytesCodecFor[T any]() Codec[T] {
+	codec, _ := any(BytesCodec{}).(Codec[T])
+
+	return codec
+}
+
+// access returns the value of el, decompressing it if needed, and marks el as used
+func (e *ExpiringLRUCache[T]) access(el *element[T]) (*T, error) {
+	el.lock.Lock()
+	defer el.lock.Unlock()
+
+	el.lastAccessMs = time.Now().UnixMilli()
+
+	if el.val != nil || el.compressed == nil {
+		return el.val, nil
+	}
+
+	data, err := s2.Decode(nil, el.compressed)
+	if err != nil {
+		return nil, fmt.Errorf("can't decompress cache entry: %w", err)
+	}
+
+	val, err := e.codec.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode cache entry: %w", err)
+	}
+
+	el.val = val
+	el.compressed = nil
+
+	e.resized(el)
+
+	return val, nil
+}
+
+// peek returns the value of el like `access`, but a compressed element stays compressed and isn't marked as used
+func (e *ExpiringLRUCache[T]) peek(el *element[T]) (*T, error) {
+	el.lock.Lock()
+	compressed, val := el.compressed, el.val
+	el.lock.Unlock()
+
+	if val != nil || compressed == nil {
+		return val, nil
+	}
+
+	data, err := s2.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("can't decompress cache entry: %w", err)
+	}
+
+	return e.codec.Decode(data)
+}
+
+// compressIdle compresses all elements which were not accessed for compressIdleAfter
+func (e *ExpiringLRUCache[T]) compressIdle() {
+	if e.compressIdleAfter <= 0 {
+		return
+	}
+
+	idleSinceMs := time.Now().Add(-e.compressIdleAfter).UnixMilli()
+
+	for _, k := range e.lru.Keys() {
+		if v, ok := e.lru.Peek(k); ok {
+			e.compress(v.(*element[T]), idleSinceMs)
+		}
+	}
+}
+
+func (e *ExpiringLRUCache[T]) compress(el *element[T], idleSinceMs int64) {
+	el.lock.Lock()
+	defer el.lock.Unlock()
+
+	if el.val == nil || el.lastAccessMs > idleSinceMs {
+		return
+	}
+
+	data := e.codec.Encode(el.val)
+
+	compressed := s2.Encode(nil, data)
+	if len(compressed) >= len(data) {
+		// not worth it, e.g. for tiny values
+		return
+	}
+
+	el.compressed = compressed
+	el.val = nil
+
+	e.resized(el)
+}
+
+// prefetchCodec stores the prefetch flag of a cacheValue in front of the encoded element
+type prefetchCodec[T any] struct {
+	inner Codec[T]
+}
+
+// Encode implements `Codec`.
+func (c prefetchCodec[T]) Encode(val *cacheValue[T]) []byte {
+	var flag byte
+
+	if val.prefetch {
+		flag = 1
+	}
+
+	return append([]byte{flag}, c.inner.Encode(val.element)...)
+}
+
+// Decode implements `Codec`.
+func (c prefetchCodec[T]) Decode(data []byte) (*cacheValue[T], error) {
+	if len(data) == 0 {
+		return nil, errors.New("missing prefetch flag")
+	}
+
+	element, err := c.inner.Decode(data[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &cacheValue[T]{element: element, prefetch: data[0] == 1}, nil
+}
+
+// Size implements `Codec`.
+func (c prefetchCodec[T]) Size(val *cacheValue[T]) int {
+	return c.inner.Size(val.element)
+}
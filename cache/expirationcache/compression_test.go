@@ -0,0 +1,153 @@
+package expirationcache
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Idle compression", func() {
+	var (
+		ctx      context.Context
+		cancelFn context.CancelFunc
+
+		value []byte
+	)
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		value = bytes.Repeat([]byte("example.com."), 20)
+	})
+
+	peek := func(l *lru.Cache, key string) (val *[]byte, compressed []byte) {
+		v, ok := l.Peek(key)
+		Expect(ok).Should(BeTrue())
+
+		el := v.(*element[[]byte])
+
+		return el.val, el.compressed
+	}
+
+	When("an entry is idle", func() {
+		It("should be compressed and decompressed on access", func() {
+			cache := NewCache[[]byte](ctx, Options{CompressIdleAfter: time.Millisecond})
+
+			cache.Put("key", &value, time.Minute)
+
+			time.Sleep(2 * time.Millisecond)
+			cache.compressIdle()
+
+			val, compressed := peek(cache.lru, "key")
+			Expect(val).Should(BeNil())
+			Expect(len(compressed)).Should(BeNumerically("<", len(value)))
+
+			res, ttl := cache.Get("key")
+			Expect(res).Should(HaveValue(Equal(value)))
+			Expect(ttl).Should(BeNumerically(">", 0))
+
+			val, compressed = peek(cache.lru, "key")
+			Expect(val).Should(HaveValue(Equal(value)))
+			Expect(compressed).Should(BeNil())
+		})
+	})
+
+	When("the entries are listed", func() {
+		It("should return the value of idle entries without decompressing them", func() {
+			cache := NewCache[[]byte](ctx, Options{CompressIdleAfter: time.Millisecond})
+
+			cache.Put("key", &value, time.Minute)
+
+			time.Sleep(2 * time.Millisecond)
+			cache.compressIdle()
+
+			cache.Entries(func(key string, val *[]byte, _ time.Duration) bool {
+				Expect(key).Should(Equal("key"))
+				Expect(val).Should(HaveValue(Equal(value)))
+
+				return true
+			})
+
+			val, compressed := peek(cache.lru, "key")
+			Expect(val).Should(BeNil())
+			Expect(compressed).ShouldNot(BeNil())
+		})
+	})
+
+	When("an entry was accessed recently", func() {
+		It("should not be compressed", func() {
+			cache := NewCache[[]byte](ctx, Options{CompressIdleAfter: time.Hour})
+
+			cache.Put("key", &value, time.Minute)
+			cache.compressIdle()
+
+			val, compressed := peek(cache.lru, "key")
+			Expect(val).Should(HaveValue(Equal(value)))
+			Expect(compressed).Should(BeNil())
+		})
+	})
+
+	When("compression doesn't reduce the size", func() {
+		It("should keep the entry uncompressed", func() {
+			cache := NewCache[[]byte](ctx, Options{CompressIdleAfter: time.Millisecond})
+
+			tiny := []byte{1}
+			cache.Put("key", &tiny, time.Minute)
+
+			time.Sleep(2 * time.Millisecond)
+			cache.compressIdle()
+
+			val, compressed := peek(cache.lru, "key")
+			Expect(val).Should(HaveValue(Equal(tiny)))
+			Expect(compressed).Should(BeNil())
+		})
+	})
+
+	When("compression is not enabled", func() {
+		It("should not compress idle entries", func() {
+			cache := NewCache[[]byte](ctx, Options{})
+
+			cache.Put("key", &value, time.Minute)
+
+			time.Sleep(2 * time.Millisecond)
+			cache.compressIdle()
+
+			val, _ := peek(cache.lru, "key")
+			Expect(val).Should(HaveValue(Equal(value)))
+		})
+	})
+
+	When("the elements have no codec", func() {
+		It("should not compress them", func() {
+			cache := NewCache[string](ctx, Options{CompressIdleAfter: time.Millisecond})
+
+			Expect(cache.compressIdleAfter).Should(BeZero())
+			Expect(cache.codec).Should(BeNil())
+		})
+	})
+
+	Describe("Prefetching cache", func() {
+		It("should keep the prefetch flag", func() {
+			cache := NewPrefetchingCache(ctx, PrefetchingOptions[[]byte]{
+				Options: Options{CompressIdleAfter: time.Millisecond},
+				ReloadFn: func(context.Context, string) (*[]byte, time.Duration) {
+					return &value, time.Minute
+				},
+			})
+
+			cache.cache.Put("key", &cacheValue[[]byte]{element: &value, prefetch: true}, time.Minute)
+
+			time.Sleep(2 * time.Millisecond)
+			cache.cache.compressIdle()
+
+			res, _ := cache.cache.Get("key")
+			Expect(res.prefetch).Should(BeTrue())
+			Expect(res.element).Should(HaveValue(Equal(value)))
+		})
+	})
+})
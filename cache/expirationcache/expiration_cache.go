@@ -2,6 +2,8 @@ package expirationcache
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -13,8 +15,15 @@ const (
 )
 
 type element[T any] struct {
-	val            *T
 	expiresEpochMs int64
+	ttl            time.Duration // TTL the element was stored with
+
+	lock         sync.Mutex
+	val          *T
+	compressed   []byte // set instead of val while the element is idle, see `Options.CompressIdleAfter`
+	lastAccessMs int64
+	size         int64 // accounted size of the value, see `Options.MaxMemory`
+	removed      bool
 }
 
 type ExpiringLRUCache[T any] struct {
@@ -24,6 +33,18 @@ type ExpiringLRUCache[T any] struct {
 	onCacheMiss     OnCacheMissCallback
 	onAfterPut      OnAfterPutCallback
 	lru             *lru.Cache
+	maxSize         int
+
+	expirationConcurrency int
+
+	compressIdleAfter time.Duration
+	codec             Codec[T]
+
+	putLock   sync.Mutex
+	maxMemory int64
+	sizeFn    func(val *T) int
+	memory    atomic.Int64
+	sketch    *frequencySketch // nil unless TinyLFU is enabled
 }
 
 type Options struct {
@@ -32,6 +53,22 @@ type Options struct {
 	OnAfterPutFn    OnAfterPutCallback
 	CleanupInterval time.Duration
 	MaxSize         uint
+
+	// ExpirationConcurrency is the max number of concurrent OnExpirationCallback calls, default 1
+	ExpirationConcurrency int
+
+	// TinyLFU only adds a new element to a full cache, if it was requested more often recently
+	// than the least recently used element, which would be removed for it
+	TinyLFU bool
+
+	// CompressIdleAfter enables the compression of elements which were not accessed for this duration,
+	// only caches of []byte support it. See `Codec`.
+	CompressIdleAfter time.Duration
+
+	// MaxMemory limits the estimated memory usage in bytes: the least recently used elements are removed
+	// until the cache fits. Compressed elements are counted with their compressed size.
+	// Only caches of []byte support it.
+	MaxMemory int64
 }
 
 // OnExpirationCallback will be called just before an element gets expired and will
@@ -55,7 +92,14 @@ func NewCache[T any](ctx context.Context, options Options) *ExpiringLRUCache[T]
 func NewCacheWithOnExpired[T any](ctx context.Context, options Options,
 	onExpirationFn OnExpirationCallback[T],
 ) *ExpiringLRUCache[T] {
-	l, _ := lru.New(defaultSize)
+	return newCache(ctx, options, onExpirationFn, bytesCodecFor[T]())
+}
+
+// newCache creates the cache, codec serializes the elements for the compression and the memory limit.
+// It may be nil if the element type doesn't support them.
+func newCache[T any](ctx context.Context, options Options,
+	onExpirationFn OnExpirationCallback[T], codec Codec[T],
+) *ExpiringLRUCache[T] {
 	c := &ExpiringLRUCache[T]{
 		cleanUpInterval: defaultCleanUpInterval,
 		preExpirationFn: func(ctx context.Context, key string) (val *T, ttl time.Duration) {
@@ -63,7 +107,9 @@ func NewCacheWithOnExpired[T any](ctx context.Context, options Options,
 		},
 		onCacheHit:  func(key string) {},
 		onCacheMiss: func(key string) {},
-		lru:         l,
+		maxSize:     defaultSize,
+
+		expirationConcurrency: max(options.ExpirationConcurrency, 1),
 	}
 
 	if options.CleanupInterval > 0 {
@@ -71,8 +117,15 @@ func NewCacheWithOnExpired[T any](ctx context.Context, options Options,
 	}
 
 	if options.MaxSize > 0 {
-		l, _ := lru.New(int(options.MaxSize))
-		c.lru = l
+		c.maxSize = int(options.MaxSize)
+	}
+
+	c.lru, _ = lru.NewWithEvict(c.maxSize, func(key, value interface{}) {
+		c.removed(key.(string), value.(*element[T]))
+	})
+
+	if options.TinyLFU {
+		c.sketch = newFrequencySketch(c.maxSize)
 	}
 
 	if options.OnAfterPutFn != nil {
@@ -91,6 +144,16 @@ func NewCacheWithOnExpired[T any](ctx context.Context, options Options,
 		c.preExpirationFn = onExpirationFn
 	}
 
+	if options.CompressIdleAfter > 0 && codec != nil {
+		c.compressIdleAfter = options.CompressIdleAfter
+		c.codec = codec
+	}
+
+	if options.MaxMemory > 0 && codec != nil {
+		c.maxMemory = options.MaxMemory
+		c.sizeFn = codec.Size
+	}
+
 	go periodicCleanup(ctx, c)
 
 	return c
@@ -104,6 +167,7 @@ func periodicCleanup[T any](ctx context.Context, c *ExpiringLRUCache[T]) {
 		select {
 		case <-ticker.C:
 			c.cleanUp()
+			c.compressIdle()
 		case <-ctx.Done():
 			return
 		}
@@ -123,17 +187,41 @@ func (e *ExpiringLRUCache[T]) cleanUp() {
 	}
 
 	if len(expiredKeys) > 0 {
-		var keysToDelete []string
+		var (
+			keysToDelete []string
+			lock         sync.Mutex
+			wg           sync.WaitGroup
+		)
+
+		// the callbacks can take a while (e.g. prefetching), so they may run concurrently
+		sem := make(chan struct{}, e.expirationConcurrency)
 
 		for _, key := range expiredKeys {
-			newVal, newTTL := e.preExpirationFn(context.Background(), key)
-			if newVal != nil {
-				e.Put(key, newVal, newTTL)
-			} else {
+			sem <- struct{}{}
+
+			wg.Add(1)
+
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				newVal, newTTL := e.preExpirationFn(context.Background(), key)
+				if newVal != nil {
+					e.Put(key, newVal, newTTL)
+
+					return
+				}
+
+				lock.Lock()
 				keysToDelete = append(keysToDelete, key)
-			}
+				lock.Unlock()
+			}()
 		}
 
+		wg.Wait()
+
 		for _, key := range keysToDelete {
 			e.lru.Remove(key)
 		}
@@ -148,24 +236,74 @@ func (e *ExpiringLRUCache[T]) Put(key string, val *T, ttl time.Duration) {
 
 	expiresEpochMs := time.Now().UnixMilli() + ttl.Milliseconds()
 
-	// add new item
-	e.lru.Add(key, &element[T]{
+	el := &element[T]{
 		val:            val,
 		expiresEpochMs: expiresEpochMs,
-	})
+		ttl:            ttl,
+		lastAccessMs:   time.Now().UnixMilli(),
+	}
+
+	e.putLock.Lock()
+
+	old, replaced := e.lru.Peek(key)
+
+	if !replaced && !e.admit(key, val) {
+		e.putLock.Unlock()
+
+		return
+	}
+
+	// add new item
+	e.added(key, el)
+	e.lru.Add(key, el)
+
+	if replaced {
+		// the LRU doesn't call the eviction callback for replaced values
+		e.removed(key, old.(*element[T]))
+	}
+
+	e.shrink()
+
+	e.putLock.Unlock()
 
 	if e.onAfterPut != nil {
 		e.onAfterPut(e.lru.Len())
 	}
 }
 
+// admit returns false if TinyLFU rejects a new element because it is requested less often than the element it replaces
+func (e *ExpiringLRUCache[T]) admit(key string, val *T) bool {
+	if e.sketch == nil || !e.isFull(key, val) {
+		return true
+	}
+
+	victim, _, ok := e.lru.GetOldest()
+	if !ok {
+		return true
+	}
+
+	return e.sketch.estimate(key) > e.sketch.estimate(victim.(string))
+}
+
 func (e *ExpiringLRUCache[T]) Get(key string) (val *T, ttl time.Duration) {
+	if e.sketch != nil {
+		e.sketch.increment(key)
+	}
+
 	el, found := e.lru.Get(key)
 
 	if found {
-		e.onCacheHit(key)
+		el := el.(*element[T])
 
-		return el.(*element[T]).val, calculateRemainTTL(el.(*element[T]).expiresEpochMs)
+		val, err := e.access(el)
+		if err == nil {
+			e.onCacheHit(key)
+
+			return val, calculateRemainTTL(el.expiresEpochMs)
+		}
+
+		// can't happen unless the codec is broken: handle it like a miss
+		e.lru.Remove(key)
 	}
 
 	e.onCacheMiss(key)
@@ -173,6 +311,15 @@ func (e *ExpiringLRUCache[T]) Get(key string) (val *T, ttl time.Duration) {
 	return nil, 0
 }
 
+// storedTTL returns the TTL the element was stored with, 0 if it isn't cached
+func (e *ExpiringLRUCache[T]) storedTTL(key string) time.Duration {
+	if el, found := e.lru.Peek(key); found {
+		return el.(*element[T]).ttl
+	}
+
+	return 0
+}
+
 func isExpired[T any](el *element[T]) bool {
 	return el.expiresEpochMs > 0 && time.Now().UnixMilli() > el.expiresEpochMs
 }
@@ -189,6 +336,33 @@ func (e *ExpiringLRUCache[T]) TotalCount() (count int) {
 	return e.lru.Len()
 }
 
+func (e *ExpiringLRUCache[T]) Entries(fn func(key string, val *T, ttl time.Duration) bool) {
+	for _, k := range e.lru.Keys() {
+		v, ok := e.lru.Peek(k)
+		if !ok {
+			continue
+		}
+
+		el := v.(*element[T])
+		if isExpired(el) {
+			continue
+		}
+
+		val, err := e.peek(el)
+		if err != nil {
+			continue
+		}
+
+		if !fn(k.(string), val, calculateRemainTTL(el.expiresEpochMs)) {
+			return
+		}
+	}
+}
+
+func (e *ExpiringLRUCache[T]) Remove(key string) {
+	e.lru.Remove(key)
+}
+
 func (e *ExpiringLRUCache[T]) Clear() {
 	e.lru.Purge()
 }
@@ -100,6 +100,55 @@ var _ = Describe("Expiration cache", func() {
 			})
 		})
 	})
+	Describe("Entries and Remove", func() {
+		When("the cache has entries", func() {
+			It("should iterate over the valid entries", func() {
+				cache := NewCache[string](ctx, Options{})
+				v1, v2 := "v1", "v2"
+				cache.Put("key1", &v1, time.Minute)
+				cache.Put("key2", &v2, time.Millisecond)
+
+				time.Sleep(2 * time.Millisecond)
+
+				entries := map[string]string{}
+				cache.Entries(func(key string, val *string, ttl time.Duration) bool {
+					Expect(ttl).Should(BeNumerically(">", 0))
+					entries[key] = *val
+
+					return true
+				})
+
+				Expect(entries).Should(Equal(map[string]string{"key1": "v1"}))
+			})
+			It("should stop if the function returns false", func() {
+				cache := NewCache[string](ctx, Options{})
+				v := "v"
+				cache.Put("key1", &v, time.Minute)
+				cache.Put("key2", &v, time.Minute)
+
+				calls := 0
+				cache.Entries(func(string, *string, time.Duration) bool {
+					calls++
+
+					return false
+				})
+
+				Expect(calls).Should(Equal(1))
+			})
+			It("should remove an entry", func() {
+				cache := NewCache[string](ctx, Options{})
+				v := "v"
+				cache.Put("key1", &v, time.Minute)
+				cache.Put("key2", &v, time.Minute)
+
+				cache.Remove("key1")
+				cache.Remove("unknown")
+
+				Expect(cache.TotalCount()).Should(Equal(1))
+				Expect(cache.Get("key1")).Should(BeNil())
+			})
+		})
+	})
 	Describe("Hook functions", func() {
 		When("Hook functions are defined", func() {
 			It("should call each hook function", func() {
@@ -0,0 +1,83 @@
+package expirationcache
+
+// elementOverhead is the estimated size of an element and its LRU bookkeeping without key and value
+const elementOverhead = 150
+
+// MemoryUsage returns the estimated memory usage in bytes, 0 if the memory is not limited
+func (e *ExpiringLRUCache[T]) MemoryUsage() int64 {
+	return e.memory.Load()
+}
+
+// valueSize returns the size of the value of el, el.lock must be held
+func (e *ExpiringLRUCache[T]) valueSize(el *element[T]) int64 {
+	if el.val == nil {
+		return int64(len(el.compressed))
+	}
+
+	return int64(e.sizeFn(el.val))
+}
+
+// added accounts the memory of a new element
+func (e *ExpiringLRUCache[T]) added(key string, el *element[T]) {
+	if e.sizeFn == nil {
+		return
+	}
+
+	el.lock.Lock()
+	defer el.lock.Unlock()
+
+	el.size = e.valueSize(el)
+	e.memory.Add(elementOverhead + int64(len(key)) + el.size)
+}
+
+// removed releases the memory of a removed or replaced element
+func (e *ExpiringLRUCache[T]) removed(key string, el *element[T]) {
+	if e.sizeFn == nil {
+		return
+	}
+
+	el.lock.Lock()
+	defer el.lock.Unlock()
+
+	if el.removed {
+		return
+	}
+
+	el.removed = true
+	e.memory.Add(-(elementOverhead + int64(len(key)) + el.size))
+}
+
+// resized updates the memory usage after el was (de)compressed, el.lock must be held
+func (e *ExpiringLRUCache[T]) resized(el *element[T]) {
+	if e.sizeFn == nil || el.removed {
+		return
+	}
+
+	size := e.valueSize(el)
+	e.memory.Add(size - el.size)
+	el.size = size
+}
+
+// isFull returns true if an element with the key can't be added without removing another one
+func (e *ExpiringLRUCache[T]) isFull(key string, val *T) bool {
+	if e.lru.Len() >= e.maxSize {
+		return true
+	}
+
+	if e.maxMemory <= 0 {
+		return false
+	}
+
+	return e.memory.Load()+elementOverhead+int64(len(key)+e.sizeFn(val)) > e.maxMemory
+}
+
+// shrink removes the least recently used elements until the cache fits into the memory limit
+func (e *ExpiringLRUCache[T]) shrink() {
+	if e.maxMemory <= 0 {
+		return
+	}
+
+	for e.memory.Load() > e.maxMemory && e.lru.Len() > 0 {
+		e.lru.RemoveOldest()
+	}
+}
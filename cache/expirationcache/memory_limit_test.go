@@ -0,0 +1,111 @@
+package expirationcache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Memory limit", func() {
+	var (
+		ctx      context.Context
+		cancelFn context.CancelFunc
+		cache    *ExpiringLRUCache[[]byte]
+	)
+
+	const valueSize = 100
+
+	// the size of an element with a 4 bytes key
+	const elementSize = elementOverhead + 4 + valueSize
+
+	newValue := func() *[]byte {
+		val := bytes.Repeat([]byte("a"), valueSize)
+
+		return &val
+	}
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		cache = NewCache[[]byte](ctx, Options{MaxMemory: 3 * elementSize})
+	})
+
+	It("should track the memory usage", func() {
+		cache.Put("key1", newValue(), time.Minute)
+		Expect(cache.MemoryUsage()).Should(BeEquivalentTo(elementSize))
+
+		// replacing the value doesn't change the size
+		cache.Put("key1", newValue(), time.Minute)
+		Expect(cache.MemoryUsage()).Should(BeEquivalentTo(elementSize))
+
+		cache.Put("key2", newValue(), time.Minute)
+		Expect(cache.MemoryUsage()).Should(BeEquivalentTo(2 * elementSize))
+
+		cache.Remove("key1")
+		Expect(cache.MemoryUsage()).Should(BeEquivalentTo(elementSize))
+
+		cache.Clear()
+		Expect(cache.MemoryUsage()).Should(BeZero())
+	})
+
+	It("should remove the least recently used elements if the limit is reached", func() {
+		for i := range 3 {
+			cache.Put(fmt.Sprintf("key%d", i), newValue(), time.Minute)
+		}
+
+		cache.Get("key0")
+
+		cache.Put("key3", newValue(), time.Minute)
+
+		Expect(cache.TotalCount()).Should(Equal(3))
+		Expect(cache.MemoryUsage()).Should(BeEquivalentTo(3 * elementSize))
+		val, _ := cache.Get("key1")
+		Expect(val).Should(BeNil())
+
+		val, _ = cache.Get("key0")
+		Expect(val).ShouldNot(BeNil())
+	})
+
+	It("should count compressed elements with their compressed size", func() {
+		cache = NewCache[[]byte](ctx, Options{CompressIdleAfter: time.Millisecond, MaxMemory: 3 * elementSize})
+
+		cache.Put("key1", newValue(), time.Minute)
+
+		time.Sleep(2 * time.Millisecond)
+		cache.compressIdle()
+
+		Expect(cache.MemoryUsage()).Should(BeNumerically("<", elementSize))
+
+		cache.Get("key1")
+		Expect(cache.MemoryUsage()).Should(BeEquivalentTo(elementSize))
+	})
+
+	When("the memory is not limited", func() {
+		It("should not track the memory usage", func() {
+			cache := NewCache[[]byte](ctx, Options{})
+			cache.Put("key1", newValue(), time.Minute)
+
+			Expect(cache.MemoryUsage()).Should(BeZero())
+		})
+	})
+
+	When("the cache is prefetching", func() {
+		It("should limit the memory", func() {
+			cache := NewPrefetchingCache[[]byte](ctx, PrefetchingOptions[[]byte]{
+				Options: Options{MaxMemory: 2 * elementSize},
+			})
+
+			for i := range 3 {
+				cache.Put(fmt.Sprintf("key%d", i), newValue(), time.Minute)
+			}
+
+			Expect(cache.TotalCount()).Should(Equal(2))
+			Expect(cache.MemoryUsage()).Should(BeEquivalentTo(2 * elementSize))
+		})
+	})
+})
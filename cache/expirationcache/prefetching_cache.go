@@ -7,11 +7,12 @@ import (
 )
 
 type PrefetchingExpiringLRUCache[T any] struct {
-	cache                   ExpiringCache[cacheValue[T]]
+	cache                   *ExpiringLRUCache[cacheValue[T]]
 	prefetchingNameCache    ExpiringCache[atomic.Uint32]
 	reloadFn                ReloadEntryFn[T]
 	prefetchThreshold       int
 	prefetchExpires         time.Duration
+	shouldPrefetchFn        ShouldPrefetchFn
 	onPrefetchEntryReloaded OnEntryReloadedCallback
 	onPrefetchCacheHit      OnCacheHitCallback
 }
@@ -27,12 +28,17 @@ type OnEntryReloadedCallback func(key string)
 // ReloadEntryFn reloads a prefetched entry by key
 type ReloadEntryFn[T any] func(ctx context.Context, key string) (*T, time.Duration)
 
+// ShouldPrefetchFn decides if an expired entry is prefetched, it receives the number of queries in the prefetch
+// window and the TTL the entry was stored with. If set, it replaces the PrefetchThreshold check.
+type ShouldPrefetchFn func(key string, queryCount int, ttl time.Duration) bool
+
 type PrefetchingOptions[T any] struct {
 	Options
 	ReloadFn                ReloadEntryFn[T]
 	PrefetchThreshold       int
 	PrefetchExpires         time.Duration
 	PrefetchMaxItemsCount   int
+	ShouldPrefetchFn        ShouldPrefetchFn
 	OnPrefetchAfterPut      OnAfterPutCallback
 	OnPrefetchEntryReloaded OnEntryReloadedCallback
 	OnPrefetchCacheHit      OnCacheHitCallback
@@ -50,30 +56,40 @@ func NewPrefetchingCache[T any](ctx context.Context, options PrefetchingOptions[
 		prefetchExpires:         options.PrefetchExpires,
 		prefetchThreshold:       options.PrefetchThreshold,
 		reloadFn:                options.ReloadFn,
+		shouldPrefetchFn:        options.ShouldPrefetchFn,
 		onPrefetchEntryReloaded: options.OnPrefetchEntryReloaded,
 		onPrefetchCacheHit:      options.OnPrefetchCacheHit,
 	}
 
-	pc.cache = NewCacheWithOnExpired[cacheValue[T]](ctx, options.Options, pc.onExpired)
+	var codec Codec[cacheValue[T]]
+	if inner := bytesCodecFor[T](); inner != nil {
+		codec = prefetchCodec[T]{inner}
+	}
+
+	pc.cache = newCache[cacheValue[T]](ctx, options.Options, pc.onExpired, codec)
 
 	return pc
 }
 
 // check if a cache entry should be prefetched: was queried > threshold in the time window
-func (e *PrefetchingExpiringLRUCache[T]) shouldPrefetch(cacheKey string) bool {
-	if e.prefetchThreshold == 0 {
-		return true
+func (e *PrefetchingExpiringLRUCache[T]) shouldPrefetch(cacheKey string, ttl time.Duration) bool {
+	var queryCount int
+
+	if cnt, _ := e.prefetchingNameCache.Get(cacheKey); cnt != nil {
+		queryCount = int(cnt.Load())
 	}
 
-	cnt, _ := e.prefetchingNameCache.Get(cacheKey)
+	if e.shouldPrefetchFn != nil {
+		return e.shouldPrefetchFn(cacheKey, queryCount, ttl)
+	}
 
-	return cnt != nil && int64(cnt.Load()) > int64(e.prefetchThreshold)
+	return e.prefetchThreshold == 0 || queryCount > e.prefetchThreshold
 }
 
 func (e *PrefetchingExpiringLRUCache[T]) onExpired(
 	ctx context.Context, cacheKey string,
 ) (val *cacheValue[T], ttl time.Duration) {
-	if e.shouldPrefetch(cacheKey) {
+	if e.shouldPrefetch(cacheKey, e.cache.storedTTL(cacheKey)) {
 		loadedVal, ttl := e.reloadFn(ctx, cacheKey)
 		if loadedVal != nil {
 			if e.onPrefetchEntryReloaded != nil {
@@ -124,6 +140,24 @@ func (e *PrefetchingExpiringLRUCache[T]) TotalCount() int {
 	return e.cache.TotalCount()
 }
 
+// Entries calls fn for each valid (not expired) entry with its remained TTL until fn returns false
+func (e *PrefetchingExpiringLRUCache[T]) Entries(fn func(key string, val *T, ttl time.Duration) bool) {
+	e.cache.Entries(func(key string, val *cacheValue[T], ttl time.Duration) bool {
+		return fn(key, val.element, ttl)
+	})
+}
+
+// Remove removes the entry with the passed key, if it is cached
+func (e *PrefetchingExpiringLRUCache[T]) Remove(key string) {
+	e.cache.Remove(key)
+	e.prefetchingNameCache.Remove(key)
+}
+
+// MemoryUsage returns the estimated memory usage in bytes, 0 if the memory is not limited
+func (e *PrefetchingExpiringLRUCache[T]) MemoryUsage() int64 {
+	return e.cache.MemoryUsage()
+}
+
 // Clear removes all cache entries
 func (e *PrefetchingExpiringLRUCache[T]) Clear() {
 	e.cache.Clear()
@@ -2,6 +2,8 @@ package expirationcache
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -45,6 +47,27 @@ var _ = Describe("Prefetching expiration cache", func() {
 
 				Expect(cache.TotalCount()).Should(Equal(0))
 			})
+
+			It("Should list and remove entries", func() {
+				cache := NewPrefetchingCache[string](ctx, PrefetchingOptions[string]{})
+				v := "v1"
+				cache.Put("key1", &v, time.Minute)
+				cache.Get("key1")
+
+				var keys []string
+				cache.Entries(func(key string, val *string, _ time.Duration) bool {
+					Expect(val).Should(HaveValue(Equal("v1")))
+					keys = append(keys, key)
+
+					return true
+				})
+				Expect(keys).Should(Equal([]string{"key1"}))
+
+				cache.Remove("key1")
+
+				Expect(cache.TotalCount()).Should(Equal(0))
+				Expect(cache.prefetchingNameCache.TotalCount()).Should(Equal(0))
+			})
 		})
 		Context("Prefetching", func() {
 			It("Should prefetch element", func() {
@@ -108,6 +131,110 @@ var _ = Describe("Prefetching expiration cache", func() {
 					}, "5s", "500ms").Should(Succeed())
 				})
 			})
+			It("Should ask ShouldPrefetchFn with the query count and TTL", func() {
+				type call struct {
+					key        string
+					queryCount int
+					ttl        time.Duration
+				}
+
+				calls := make(chan call, 10)
+
+				cache := NewPrefetchingCache[string](ctx, PrefetchingOptions[string]{
+					Options: Options{
+						CleanupInterval: 100 * time.Millisecond,
+					},
+					PrefetchThreshold: 100,
+					PrefetchExpires:   time.Minute,
+					ShouldPrefetchFn: func(key string, queryCount int, ttl time.Duration) bool {
+						calls <- call{key, queryCount, ttl}
+
+						return true
+					},
+					ReloadFn: func(ctx context.Context, cacheKey string) (*string, time.Duration) {
+						v := "v2"
+
+						return &v, time.Minute
+					},
+				})
+
+				v := "v1"
+				cache.Put("key1", &v, 50*time.Millisecond)
+				cache.Get("key1")
+
+				Eventually(calls).Should(Receive(Equal(call{"key1", 1, 50 * time.Millisecond})))
+
+				Eventually(func() *string {
+					val, _ := cache.Get("key1")
+
+					return val
+				}).Should(HaveValue(Equal("v2")))
+			})
+			It("Should not prefetch if ShouldPrefetchFn returns false", func() {
+				cache := NewPrefetchingCache[string](ctx, PrefetchingOptions[string]{
+					Options: Options{
+						CleanupInterval: 100 * time.Millisecond,
+					},
+					ShouldPrefetchFn: func(string, int, time.Duration) bool {
+						return false
+					},
+					ReloadFn: func(ctx context.Context, cacheKey string) (*string, time.Duration) {
+						Fail("should not reload")
+
+						return nil, 0
+					},
+				})
+
+				v := "v1"
+				cache.Put("key1", &v, 50*time.Millisecond)
+
+				Eventually(func() int {
+					return cache.cache.lru.Len()
+				}, "5s").Should(BeZero())
+			})
+			It("Should reload entries concurrently", func() {
+				const count = 4
+
+				var running, maxRunning atomic.Int32
+
+				cache := NewPrefetchingCache[string](ctx, PrefetchingOptions[string]{
+					Options: Options{
+						CleanupInterval:       100 * time.Millisecond,
+						ExpirationConcurrency: 2,
+					},
+					ReloadFn: func(ctx context.Context, cacheKey string) (*string, time.Duration) {
+						n := running.Add(1)
+						defer running.Add(-1)
+
+						for {
+							old := maxRunning.Load()
+							if n <= old || maxRunning.CompareAndSwap(old, n) {
+								break
+							}
+						}
+
+						time.Sleep(50 * time.Millisecond)
+
+						v := "v2"
+
+						return &v, time.Minute
+					},
+				})
+
+				for i := range count {
+					v := "v1"
+					cache.Put(fmt.Sprintf("key%d", i), &v, 10*time.Millisecond)
+				}
+
+				Eventually(func(g Gomega) {
+					for i := range count {
+						val, _ := cache.Get(fmt.Sprintf("key%d", i))
+						g.Expect(val).Should(HaveValue(Equal("v2")))
+					}
+				}, "5s").Should(Succeed())
+
+				Expect(maxRunning.Load()).Should(BeEquivalentTo(2))
+			})
 			It("With default config (threshold = 0) should always prefetch", func() {
 				cache := NewPrefetchingCache[string](ctx, PrefetchingOptions[string]{
 					Options: Options{
@@ -0,0 +1,99 @@
+package expirationcache
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"sync"
+)
+
+const (
+	sketchDepth      = 4
+	sketchMinWidth   = 64
+	sketchMaxCount   = 15
+	sketchResetRatio = 10
+)
+
+// frequencySketch is a count-min sketch which estimates how often keys were accessed recently.
+// All counters are halved after a number of increments, so old accesses lose their weight (TinyLFU aging).
+type frequencySketch struct {
+	lock sync.Mutex
+
+	seed      maphash.Seed
+	counters  [sketchDepth][]uint8
+	mask      uint64
+	additions int
+	resetAt   int
+}
+
+func newFrequencySketch(size int) *frequencySketch {
+	width := uint64(1) << bits.Len(uint(max(size, sketchMinWidth)-1))
+
+	s := &frequencySketch{
+		seed:    maphash.MakeSeed(),
+		mask:    width - 1,
+		resetAt: sketchResetRatio * int(width),
+	}
+
+	for i := range s.counters {
+		s.counters[i] = make([]uint8, width)
+	}
+
+	return s
+}
+
+// indexes returns the counter index of the key in each row
+func (s *frequencySketch) indexes(key string) (res [sketchDepth]uint64) {
+	h := maphash.String(s.seed, key)
+	h1, h2 := h, (h>>32)|1
+
+	for i := range res {
+		res[i] = (h1 + uint64(i)*h2) & s.mask
+	}
+
+	return res
+}
+
+// increment records an access of the key
+func (s *frequencySketch) increment(key string) {
+	idx := s.indexes(key)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, j := range idx {
+		if s.counters[i][j] < sketchMaxCount {
+			s.counters[i][j]++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+// estimate returns the estimated number of recent accesses of the key
+func (s *frequencySketch) estimate(key string) uint8 {
+	idx := s.indexes(key)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	res := uint8(sketchMaxCount)
+	for i, j := range idx {
+		res = min(res, s.counters[i][j])
+	}
+
+	return res
+}
+
+// reset halves all counters, s.lock must be held
+func (s *frequencySketch) reset() {
+	for i := range s.counters {
+		for j := range s.counters[i] {
+			s.counters[i][j] /= 2
+		}
+	}
+
+	s.additions /= 2
+}
@@ -0,0 +1,117 @@
+package expirationcache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TinyLFU", func() {
+	Describe("frequency sketch", func() {
+		It("should estimate the access count", func() {
+			sketch := newFrequencySketch(100)
+
+			for range 3 {
+				sketch.increment("key1")
+			}
+
+			sketch.increment("key2")
+
+			Expect(sketch.estimate("key1")).Should(BeNumerically(">=", 3))
+			Expect(sketch.estimate("key2")).Should(BeNumerically(">=", 1))
+			Expect(sketch.estimate("key1")).Should(BeNumerically(">", sketch.estimate("key2")))
+		})
+
+		It("should not overflow", func() {
+			sketch := newFrequencySketch(100)
+
+			for range 100 {
+				sketch.increment("key")
+			}
+
+			Expect(sketch.estimate("key")).Should(BeNumerically("==", sketchMaxCount))
+		})
+
+		It("should age the counters", func() {
+			sketch := newFrequencySketch(1)
+
+			for range sketch.resetAt {
+				sketch.increment("key")
+			}
+
+			Expect(sketch.estimate("key")).Should(BeNumerically("==", 7))
+		})
+	})
+
+	Describe("admission", func() {
+		var (
+			ctx      context.Context
+			cancelFn context.CancelFunc
+			cache    *ExpiringLRUCache[string]
+		)
+
+		get := func(key string) *string {
+			val, _ := cache.Get(key)
+
+			return val
+		}
+
+		BeforeEach(func() {
+			ctx, cancelFn = context.WithCancel(context.Background())
+			DeferCleanup(cancelFn)
+
+			cache = NewCache[string](ctx, Options{MaxSize: 2, TinyLFU: true})
+
+			for i := range 2 {
+				key := fmt.Sprintf("key%d", i)
+				v := key
+
+				cache.Get(key)
+				cache.Get(key)
+				cache.Put(key, &v, time.Minute)
+			}
+		})
+
+		When("a new entry is requested less often than the least recently used entry", func() {
+			It("should not be added", func() {
+				v := "new"
+
+				cache.Get("new")
+				cache.Put("new", &v, time.Minute)
+
+				Expect(cache.TotalCount()).Should(Equal(2))
+				Expect(get("new")).Should(BeNil())
+				Expect(get("key0")).Should(HaveValue(Equal("key0")))
+			})
+		})
+
+		When("a new entry is requested more often than the least recently used entry", func() {
+			It("should replace it", func() {
+				v := "new"
+
+				for range 5 {
+					cache.Get("new")
+				}
+
+				cache.Put("new", &v, time.Minute)
+
+				Expect(cache.TotalCount()).Should(Equal(2))
+				Expect(get("new")).Should(HaveValue(Equal("new")))
+				Expect(get("key0")).Should(BeNil())
+			})
+		})
+
+		When("an entry is updated", func() {
+			It("should always be replaced", func() {
+				v := "updated"
+
+				cache.Put("key0", &v, time.Minute)
+
+				Expect(get("key0")).Should(HaveValue(Equal("updated")))
+			})
+		})
+	})
+})
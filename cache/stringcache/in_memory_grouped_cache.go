@@ -31,6 +31,13 @@ func NewInMemoryGroupedWildcardCache() *InMemoryGroupedCache {
 	}
 }
 
+func NewInMemoryGroupedCIDRCache() *InMemoryGroupedCache {
+	return &InMemoryGroupedCache{
+		caches:    make(map[string]stringCache),
+		factoryFn: newCIDRCacheFactory,
+	}
+}
+
 func (c *InMemoryGroupedCache) ElementCount(group string) int {
 	c.lock.RLock()
 	cache, found := c.caches[group]
@@ -1,6 +1,7 @@
 package stringcache
 
 import (
+	"net/netip"
 	"regexp"
 	"sort"
 	"strings"
@@ -253,3 +254,88 @@ func normalizeWildcard(domain string) string {
 
 	return domain
 }
+
+// cidrCache matches IP addresses against networks, grouped by prefix length:
+// a lookup masks the address once per length instead of checking every network
+type cidrCache struct {
+	networks map[int]map[netip.Prefix]struct{}
+	cnt      int
+}
+
+func (cache cidrCache) elementCount() int {
+	return cache.cnt
+}
+
+func (cache cidrCache) contains(searchString string) bool {
+	addr, err := netip.ParseAddr(searchString)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for bits, networks := range cache.networks {
+		if bits > addr.BitLen() {
+			continue
+		}
+
+		network, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+
+		if _, found := networks[network]; found {
+			log.PrefixedLog("cidr_cache").Debugf("network '%s' matched with '%s'", network, searchString)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+type cidrCacheFactory struct {
+	cache cidrCache
+}
+
+func newCIDRCacheFactory() cacheFactory {
+	return &cidrCacheFactory{
+		cache: cidrCache{networks: make(map[int]map[netip.Prefix]struct{})},
+	}
+}
+
+func (r *cidrCacheFactory) addEntry(entry string) bool {
+	if !strings.Contains(entry, "/") {
+		return false
+	}
+
+	network, err := netip.ParsePrefix(entry)
+	if err != nil {
+		return false
+	}
+
+	network = network.Masked()
+
+	networks, found := r.cache.networks[network.Bits()]
+	if !found {
+		networks = make(map[netip.Prefix]struct{})
+		r.cache.networks[network.Bits()] = networks
+	}
+
+	networks[network] = struct{}{}
+	r.cache.cnt++
+
+	return true
+}
+
+func (r *cidrCacheFactory) count() int {
+	return r.cache.cnt
+}
+
+func (r *cidrCacheFactory) create() stringCache {
+	if r.cache.cnt == 0 {
+		return nil
+	}
+
+	return r.cache
+}
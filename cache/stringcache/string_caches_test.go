@@ -170,4 +170,50 @@ var _ = Describe("Caches", func() {
 			})
 		})
 	})
+
+	Describe("CIDR StringCache", func() {
+		It("should not return a cache when empty", func() {
+			Expect(newCIDRCacheFactory().create()).Should(BeNil())
+		})
+
+		It("should not handle other entries", func() {
+			factory := newCIDRCacheFactory()
+
+			Expect(factory.addEntry("example.com")).Should(BeFalse())
+			Expect(factory.addEntry("1.2.3.4")).Should(BeFalse())
+			Expect(factory.addEntry("/regex/")).Should(BeFalse())
+			Expect(factory.addEntry("1.2.3.0/33")).Should(BeFalse())
+
+			Expect(factory.create()).Should(BeNil())
+		})
+
+		When("cache was created", func() {
+			BeforeEach(func() {
+				factory = newCIDRCacheFactory()
+
+				Expect(factory.addEntry("10.0.0.0/8")).Should(BeTrue())
+				Expect(factory.addEntry("192.168.178.1/24")).Should(BeTrue())
+				Expect(factory.addEntry("2001:db8::/32")).Should(BeTrue())
+
+				cache = factory.create()
+			})
+
+			It("should match addresses in the networks", func() {
+				Expect(cache.contains("10.1.2.3")).Should(BeTrue())
+				Expect(cache.contains("192.168.178.200")).Should(BeTrue())
+				Expect(cache.contains("2001:db8::1")).Should(BeTrue())
+				Expect(cache.contains("::ffff:10.0.0.1")).Should(BeTrue())
+
+				Expect(cache.contains("11.0.0.1")).Should(BeFalse())
+				Expect(cache.contains("192.168.179.1")).Should(BeFalse())
+				Expect(cache.contains("2001:db9::1")).Should(BeFalse())
+				Expect(cache.contains("example.com")).Should(BeFalse())
+			})
+
+			It("should return correct element count", func() {
+				Expect(factory.count()).Should(Equal(3))
+				Expect(cache.elementCount()).Should(Equal(3))
+			})
+		})
+	})
 })
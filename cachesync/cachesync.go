@@ -0,0 +1,90 @@
+// Package cachesync defines how blocky instances share cache entries and the blocking state with each other
+package cachesync
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+)
+
+const (
+	// DefaultTTL is used for shared entries without answer or with a TTL of 0
+	DefaultTTL = 1 * time.Second
+
+	cacheReason = "EXTERNAL_CACHE"
+)
+
+// CacheMessage is a cache entry received from another instance or from the shared store
+type CacheMessage struct {
+	Key      string
+	Response *model.Response
+}
+
+// EnabledMessage is a blocking state change of another instance
+type EnabledMessage struct {
+	State    bool          `json:"s"`
+	Duration time.Duration `json:"d,omitempty"`
+	Groups   []string      `json:"g,omitempty"`
+}
+
+// Client is a backend which synchronizes the instances
+type Client interface {
+	// PublishCache sends a new cache entry to the other instances and stores it, if the backend has a store
+	PublishCache(key string, message *dns.Msg)
+
+	// PublishEnabled sends a blocking state change to the other instances
+	PublishEnabled(ctx context.Context, state *EnabledMessage)
+
+	// LoadCache sends the stored cache entries to the cache channel
+	LoadCache(ctx context.Context)
+
+	// CacheMessages returns the channel of the received cache entries
+	CacheMessages() <-chan *CacheMessage
+
+	// EnabledMessages returns the channel of the received blocking state changes
+	EnabledMessages() <-chan *EnabledMessage
+
+	// Ping checks that the backend is reachable
+	Ping(ctx context.Context) error
+}
+
+// NewCacheMessage unpacks a shared DNS message, a TTL above 0 replaces the TTL of the answer records
+func NewCacheMessage(key string, packed []byte, ttl time.Duration) (*CacheMessage, error) {
+	msg := new(dns.Msg)
+
+	if err := msg.Unpack(packed); err != nil {
+		return nil, err
+	}
+
+	if ttl > 0 {
+		for _, a := range msg.Answer {
+			a.Header().Ttl = uint32(ttl.Seconds())
+		}
+	}
+
+	return &CacheMessage{
+		Key: key,
+		Response: &model.Response{
+			RType:  model.ResponseTypeCACHED,
+			Reason: cacheReason,
+			Res:    msg,
+		},
+	}, nil
+}
+
+// TTL returns the minimal TTL of the answer records or DefaultTTL if it is 0
+func TTL(msg *dns.Msg) time.Duration {
+	ttl := uint32(math.MaxInt32)
+	for _, a := range msg.Answer {
+		ttl = min(ttl, a.Header().Ttl)
+	}
+
+	if ttl == 0 {
+		return DefaultTTL
+	}
+
+	return time.Duration(ttl) * time.Second
+}
@@ -0,0 +1,18 @@
+package cachesync
+
+import (
+	"testing"
+
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func init() {
+	log.Silence()
+}
+
+func TestCacheSync(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cache Sync Suite")
+}
@@ -0,0 +1,66 @@
+package cachesync
+
+import (
+	"time"
+
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache sync", func() {
+	newMsg := func(ttl uint) *dns.Msg {
+		msg, err := util.NewMsgWithAnswer("example.com.", ttl, dns.Type(dns.TypeA), "1.1.1.1")
+		Expect(err).Should(Succeed())
+
+		return msg
+	}
+
+	Describe("NewCacheMessage", func() {
+		var packed []byte
+
+		BeforeEach(func() {
+			var err error
+
+			packed, err = newMsg(300).Pack()
+			Expect(err).Should(Succeed())
+		})
+
+		It("should create a cached response", func() {
+			msg, err := NewCacheMessage("key", packed, 0)
+			Expect(err).Should(Succeed())
+
+			Expect(msg.Key).Should(Equal("key"))
+			Expect(msg.Response.RType).Should(Equal(model.ResponseTypeCACHED))
+			Expect(msg.Response.Reason).Should(Equal("EXTERNAL_CACHE"))
+			Expect(msg.Response.Res.Answer[0].Header().Ttl).Should(BeNumerically("==", 300))
+		})
+
+		It("should replace the TTL", func() {
+			msg, err := NewCacheMessage("key", packed, 20*time.Second)
+			Expect(err).Should(Succeed())
+
+			Expect(msg.Response.Res.Answer[0].Header().Ttl).Should(BeNumerically("==", 20))
+		})
+
+		It("should fail on invalid messages", func() {
+			_, err := NewCacheMessage("key", []byte("invalid"), 0)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("TTL", func() {
+		It("should return the minimal TTL", func() {
+			msg := newMsg(300)
+			msg.Answer = append(msg.Answer, newMsg(100).Answer...)
+
+			Expect(TTL(msg)).Should(Equal(100 * time.Second))
+		})
+
+		It("should return the default TTL for a TTL of 0", func() {
+			Expect(TTL(newMsg(0))).Should(Equal(DefaultTTL))
+		})
+	})
+})
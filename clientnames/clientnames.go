@@ -0,0 +1,241 @@
+// Package clientnames provides client names and groups from external sources, like the device names of a network
+// controller, fetched from a HTTP endpoint or returned by a script
+package clientnames
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+)
+
+// maxResponseSize is the maximum size of the response of a URL source
+const maxResponseSize = 4 * 1024 * 1024
+
+// Client is the entry of an IP address in a source
+type Client struct {
+	Name string `json:"name"`
+	// Groups are additional client names, which can be used for the client groups of blocking and upstreams
+	Groups []string `json:"groups"`
+}
+
+// Names returns the name, if known, and the groups of the client
+func (c Client) Names() []string {
+	names := make([]string, 0, 1+len(c.Groups))
+
+	if c.Name != "" {
+		names = append(names, c.Name)
+	}
+
+	return append(names, c.Groups...)
+}
+
+// Store holds the clients of all sources and refreshes them periodically
+type Store struct {
+	cfg    config.ExternalClientNames
+	client *http.Client
+
+	lock    sync.RWMutex
+	clients map[string]Client // by IP
+
+	sources []map[string]Client
+}
+
+// NewStore creates a new store for the configured sources, `client` is used for the URLs
+func NewStore(cfg config.ExternalClientNames, client *http.Client) *Store {
+	return &Store{
+		cfg:    cfg,
+		client: client,
+
+		clients: make(map[string]Client),
+		sources: make([]map[string]Client, len(cfg.Sources)),
+	}
+}
+
+// Start loads the clients and refreshes them in the background, until `ctx` is done.
+// `onChange` is called each time the clients changed after the initial load.
+func (s *Store) Start(ctx context.Context, onChange func()) {
+	s.refresh(ctx)
+
+	go func() {
+		ticker := time.NewTicker(s.cfg.RefreshPeriod.ToDuration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if s.refresh(ctx) && onChange != nil {
+					onChange()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Lookup returns the client with the IP
+func (s *Store) Lookup(ip net.IP) (Client, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	client, ok := s.clients[ip.String()]
+
+	return client, ok
+}
+
+// Count returns the number of clients
+func (s *Store) Count() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.clients)
+}
+
+// refresh reloads the sources and returns true if the clients changed
+func (s *Store) refresh(ctx context.Context) bool {
+	logger := log.PrefixedLog("client_names")
+
+	for i, source := range s.cfg.Sources {
+		clients, err := s.load(ctx, source)
+		if err != nil {
+			logger.WithField("source", source).WithError(err).Warn("can't load client names, keeping the previous ones")
+
+			continue
+		}
+
+		s.sources[i] = clients
+	}
+
+	clients := make(map[string]Client)
+
+	// later sources take precedence
+	for _, source := range s.sources {
+		maps.Copy(clients, source)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if maps.EqualFunc(s.clients, clients, clientEqual) {
+		return false
+	}
+
+	s.clients = clients
+
+	logger.Debugf("loaded %d clients", len(clients))
+
+	return true
+}
+
+func (s *Store) load(ctx context.Context, source config.ExternalClientNamesSource) (map[string]Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout.ToDuration())
+	defer cancel()
+
+	var (
+		data []byte
+		err  error
+	)
+
+	if source.URL != "" {
+		data, err = s.fetch(ctx, source.URL)
+	} else {
+		data, err = run(ctx, source.Command)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+func (s *Store) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status code %d", resp.StatusCode)
+	}
+
+	// read one byte more than allowed to detect oversized responses
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxResponseSize {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxResponseSize)
+	}
+
+	return data, nil
+}
+
+func run(ctx context.Context, command string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, command)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+// Parse parses the JSON object of IP addresses to clients, e.g. `{"192.168.1.10": {"name": "tv", "groups": ["iot"]}}`.
+// Entries without name and groups are skipped.
+func Parse(data []byte) (map[string]Client, error) {
+	var entries map[string]Client
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid client names: %w", err)
+	}
+
+	result := make(map[string]Client, len(entries))
+
+	for key, client := range entries {
+		ip := net.ParseIP(strings.TrimSpace(key))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid client IP: %s", key)
+		}
+
+		client.Name = strings.TrimSpace(client.Name)
+		client.Groups = slices.DeleteFunc(client.Groups, func(group string) bool { return strings.TrimSpace(group) == "" })
+
+		if client.Name == "" && len(client.Groups) == 0 {
+			continue
+		}
+
+		result[ip.String()] = client
+	}
+
+	return result, nil
+}
+
+func clientEqual(a, b Client) bool {
+	return a.Name == b.Name && slices.Equal(a.Groups, b.Groups)
+}
@@ -0,0 +1,160 @@
+package clientnames
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Store", func() {
+	var (
+		ctx      context.Context
+		sut      *Store
+		cfg      config.ExternalClientNames
+		server   *httptest.Server
+		response atomic.Value
+		changes  atomic.Int32
+	)
+
+	lookup := func(ip string) Client {
+		client, ok := sut.Lookup(net.ParseIP(ip))
+		ExpectWithOffset(1, ok).Should(BeTrue())
+
+		return client
+	}
+
+	BeforeEach(func() {
+		var cancelFn context.CancelFunc
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		response.Store(`{"192.168.1.10": {"name": "tv", "groups": ["iot"]}, "192.168.1.20": {"name": "laptop"}}`)
+		changes.Store(0)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			str, _ := response.Load().(string)
+			if str == "" {
+				w.WriteHeader(http.StatusInternalServerError)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(str))
+		}))
+		DeferCleanup(server.Close)
+
+		script := filepath.Join(GinkgoT().TempDir(), "clients.sh")
+		Expect(os.WriteFile(script, []byte("#!/bin/sh\necho '{\"192.168.1.20\": {\"groups\": [\"kids\"]}}'\n"), 0o700)).
+			Should(Succeed())
+
+		cfg = config.ExternalClientNames{
+			Sources: []config.ExternalClientNamesSource{
+				{URL: server.URL},
+				{Command: script},
+			},
+			RefreshPeriod: config.Duration(10 * time.Millisecond),
+			Timeout:       config.Duration(time.Second),
+		}
+	})
+
+	JustBeforeEach(func() {
+		sut = NewStore(cfg, server.Client())
+		sut.Start(ctx, func() { changes.Add(1) })
+	})
+
+	It("loads the clients of all sources", func() {
+		Expect(sut.Count()).Should(Equal(2))
+
+		Expect(lookup("192.168.1.10")).Should(Equal(Client{Name: "tv", Groups: []string{"iot"}}))
+
+		// later sources take precedence
+		Expect(lookup("192.168.1.20")).Should(Equal(Client{Groups: []string{"kids"}}))
+
+		_, ok := sut.Lookup(net.ParseIP("192.168.1.30"))
+		Expect(ok).Should(BeFalse())
+	})
+
+	It("refreshes the clients", func() {
+		Consistently(changes.Load, "50ms").Should(BeZero())
+
+		response.Store(`{"192.168.1.30": {"name": "phone"}}`)
+
+		Eventually(changes.Load).Should(BeEquivalentTo(1))
+
+		Expect(lookup("192.168.1.30")).Should(HaveField("Name", "phone"))
+
+		_, ok := sut.Lookup(net.ParseIP("192.168.1.10"))
+		Expect(ok).Should(BeFalse())
+	})
+
+	It("keeps the clients of sources which fail", func() {
+		response.Store("")
+
+		Consistently(changes.Load, "50ms").Should(BeZero())
+		Expect(lookup("192.168.1.10")).Should(HaveField("Name", "tv"))
+	})
+
+	It("fails on too large responses", func() {
+		response.Store(`{"192.168.1.30": {"name": "phone"}}` + strings.Repeat(" ", maxResponseSize))
+
+		_, err := sut.fetch(ctx, server.URL)
+		Expect(err).Should(MatchError(ContainSubstring("larger than")))
+
+		Consistently(changes.Load, "50ms").Should(BeZero())
+		Expect(lookup("192.168.1.10")).Should(HaveField("Name", "tv"))
+	})
+
+	When("the command fails", func() {
+		BeforeEach(func() {
+			cfg.Sources[1].Command = filepath.Join(GinkgoT().TempDir(), "missing.sh")
+		})
+
+		It("uses the other sources", func() {
+			Expect(lookup("192.168.1.20")).Should(HaveField("Name", "laptop"))
+		})
+	})
+})
+
+var _ = Describe("Client", func() {
+	It("has the name and the groups as names", func() {
+		Expect(Client{Name: "tv", Groups: []string{"iot", "media"}}.Names()).
+			Should(Equal([]string{"tv", "iot", "media"}))
+		Expect(Client{Groups: []string{"iot"}}.Names()).Should(Equal([]string{"iot"}))
+		Expect(Client{}.Names()).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("Parse", func() {
+	It("parses the clients by IP", func() {
+		clients, err := Parse([]byte(`{
+			"192.168.1.10": {"name": " tv ", "groups": ["iot", ""]},
+			"2001:db8:0::1": {"name": "laptop"},
+			"192.168.1.30": {}
+		}`))
+		Expect(err).Should(Succeed())
+
+		Expect(clients).Should(Equal(map[string]Client{
+			"192.168.1.10": {Name: "tv", Groups: []string{"iot"}},
+			"2001:db8::1":  {Name: "laptop"},
+		}))
+	})
+
+	It("fails on invalid data", func() {
+		_, err := Parse([]byte(`["192.168.1.10"]`))
+		Expect(err).Should(MatchError(ContainSubstring("invalid client names")))
+
+		_, err = Parse([]byte(`{"tv": {"name": "tv"}}`))
+		Expect(err).Should(MatchError(ContainSubstring("invalid client IP")))
+	})
+})
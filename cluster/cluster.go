@@ -0,0 +1,351 @@
+// Package cluster synchronizes the blocking state and the lists of multiple instances over their HTTP listeners
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/cachesync"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/lists"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	chanCap = 1000
+
+	// a peer is alive if it answered one of the latest heartbeats
+	missedHeartbeats = 3
+
+	authorizationHeader = "Authorization"
+)
+
+// Status is the state of an instance, returned by the status endpoint
+type Status struct {
+	Name       string `json:"name"`
+	Leader     string `json:"leader"`
+	ConfigHash string `json:"configHash"`
+}
+
+// peer is another instance of the cluster
+type peer struct {
+	url        string
+	name       string
+	configHash string
+	lastSeen   time.Time
+	// state of the latest heartbeat, to log changes
+	alive bool
+}
+
+// Node is the instance in the cluster, it implements `cachesync.Client` and `lists.GroupSharing`
+type Node struct {
+	cfg        *config.Cluster
+	configHash string
+	client     *http.Client
+	listClient *http.Client
+	l          *logrus.Entry
+
+	lock  sync.RWMutex
+	peers []*peer
+	// gzip compressed entries loaded by this instance per list type and group
+	lists map[string][]byte
+
+	enabledChannel chan *cachesync.EnabledMessage
+	cacheChannel   chan *cachesync.CacheMessage
+}
+
+// New creates the node and sends the first heartbeat, so the leader is known before the lists are loaded.
+// The heartbeats stop when the context is done.
+func New(ctx context.Context, cfg *config.Cluster, configHash string) *Node {
+	if cfg == nil || !cfg.IsEnabled() {
+		return nil
+	}
+
+	n := &Node{
+		cfg:            cfg,
+		configHash:     configHash,
+		client:         &http.Client{Timeout: cfg.HeartbeatInterval.ToDuration()},
+		listClient:     &http.Client{},
+		l:              log.PrefixedLog("cluster"),
+		lists:          make(map[string][]byte),
+		enabledChannel: make(chan *cachesync.EnabledMessage, chanCap),
+		cacheChannel:   make(chan *cachesync.CacheMessage),
+	}
+
+	for _, u := range cfg.Peers {
+		n.peers = append(n.peers, &peer{url: u})
+	}
+
+	n.heartbeat(ctx)
+
+	go n.heartbeats(ctx)
+
+	return n
+}
+
+func (n *Node) heartbeats(ctx context.Context) {
+	ticker := time.NewTicker(n.cfg.HeartbeatInterval.ToDuration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n.heartbeat(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// heartbeat requests the status of all peers
+func (n *Node) heartbeat(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, p := range n.peers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			n.checkPeer(ctx, p)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (n *Node) checkPeer(ctx context.Context, p *peer) {
+	var status Status
+
+	err := n.request(ctx, http.MethodGet, p.url+PathPrefix+pathStatus, nil, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(&status)
+	})
+
+	now := time.Now()
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if err != nil {
+		if p.alive && !n.isAlive(p, now) {
+			p.alive = false
+
+			n.l.WithField("peer", p.url).Warn("peer left the cluster: ", err)
+		}
+
+		return
+	}
+
+	if !p.alive {
+		n.l.WithField("peer", p.url).Infof("peer '%s' joined the cluster", status.Name)
+	}
+
+	if status.Name == n.cfg.Name {
+		n.l.WithField("peer", p.url).Warnf("peer has the same name '%s' as this instance", status.Name)
+	}
+
+	// client groups aren't shared: they are defined by the configuration of each instance and matched with its own
+	// client lookups, so only a different configuration is reported
+	if status.ConfigHash != p.configHash && status.ConfigHash != n.configHash {
+		n.l.WithField("peer", p.url).Warn("peer runs a different configuration, the unshared client groups may differ")
+	}
+
+	p.name = status.Name
+	p.configHash = status.ConfigHash
+	p.lastSeen = now
+	p.alive = true
+}
+
+// isAlive returns true if the peer answered one of the latest heartbeats, the lock must be held
+func (n *Node) isAlive(p *peer, now time.Time) bool {
+	return !p.lastSeen.IsZero() && now.Sub(p.lastSeen) < missedHeartbeats*n.cfg.HeartbeatInterval.ToDuration()
+}
+
+// alivePeers returns the peers which answered one of the latest heartbeats
+func (n *Node) alivePeers() []peer {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	now := time.Now()
+	res := make([]peer, 0, len(n.peers))
+
+	for _, p := range n.peers {
+		if n.isAlive(p, now) {
+			res = append(res, *p)
+		}
+	}
+
+	return res
+}
+
+// leader returns the alive instance with the smallest name, nil if it is this instance
+func (n *Node) leader() *peer {
+	var res *peer
+
+	for _, p := range n.alivePeers() {
+		if p.name < n.cfg.Name && (res == nil || p.name < res.name) {
+			res = &p
+		}
+	}
+
+	return res
+}
+
+// Status returns the state of this instance
+func (n *Node) Status() Status {
+	leader := n.cfg.Name
+	if p := n.leader(); p != nil {
+		leader = p.name
+	}
+
+	return Status{
+		Name:       n.cfg.Name,
+		Leader:     leader,
+		ConfigHash: n.configHash,
+	}
+}
+
+// IsLeader implements `lists.GroupSharing`
+func (n *Node) IsLeader() bool {
+	return n.leader() == nil
+}
+
+// Load implements `lists.GroupSharing`
+func (n *Node) Load(ctx context.Context, listType lists.ListCacheType, group string) (io.ReadCloser, error) {
+	leader := n.leader()
+	if leader == nil {
+		return nil, fmt.Errorf("this instance is the leader")
+	}
+
+	req, err := n.newRequest(ctx, http.MethodGet, leader.url+listPath(listType, group), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// without the heartbeat timeout, which applies to reading the body too and lists can be large
+	resp, err := n.listClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("leader '%s' returned status %s", leader.name, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// Store implements `lists.GroupSharing`
+func (n *Node) Store(listType lists.ListCacheType, group string, entries []byte) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.lists[listKey(listType, group)] = entries
+}
+
+func (n *Node) storedList(listType lists.ListCacheType, group string) ([]byte, bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	entries, ok := n.lists[listKey(listType, group)]
+
+	return entries, ok
+}
+
+// PublishEnabled implements `cachesync.Client`, the state is sent to all alive peers
+func (n *Node) PublishEnabled(ctx context.Context, state *cachesync.EnabledMessage) {
+	body, err := json.Marshal(state)
+	if err != nil {
+		n.l.Error("can't marshal blocking state: ", err)
+
+		return
+	}
+
+	// the state is sent in the background, after a request which changed it is done
+	ctx = context.WithoutCancel(ctx)
+
+	for _, p := range n.alivePeers() {
+		go func() {
+			err := n.request(ctx, http.MethodPost, p.url+PathPrefix+pathBlocking, body, nil)
+			if err != nil {
+				n.l.WithField("peer", p.url).Warn("can't send blocking state: ", err)
+			}
+		}()
+	}
+}
+
+// PublishCache implements `cachesync.Client`, cache entries are not shared in a cluster
+func (n *Node) PublishCache(string, *dns.Msg) {}
+
+// LoadCache implements `cachesync.Client`, cache entries are not shared in a cluster
+func (n *Node) LoadCache(context.Context) {}
+
+// CacheMessages implements `cachesync.Client`
+func (n *Node) CacheMessages() <-chan *cachesync.CacheMessage {
+	return n.cacheChannel
+}
+
+// EnabledMessages implements `cachesync.Client`
+func (n *Node) EnabledMessages() <-chan *cachesync.EnabledMessage {
+	return n.enabledChannel
+}
+
+// Ping implements `cachesync.Client`, a node without reachable peers still works on its own
+func (n *Node) Ping(context.Context) error {
+	return nil
+}
+
+func (n *Node) newRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(authorizationHeader, "Bearer "+n.cfg.Secret)
+
+	return req, nil
+}
+
+// request sends the request to a peer and passes the body of a successful response to read, if set
+func (n *Node) request(ctx context.Context, method, url string, body []byte, read func(io.Reader) error) error {
+	req, err := n.newRequest(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+
+	if read == nil {
+		return nil
+	}
+
+	return read(resp.Body)
+}
+
+func listKey(listType lists.ListCacheType, group string) string {
+	return listType.String() + ":" + group
+}
+
+func listPath(listType lists.ListCacheType, group string) string {
+	return fmt.Sprintf("%s%s/%s/%s", PathPrefix, pathLists, listType, url.PathEscape(group))
+}
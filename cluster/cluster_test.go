@@ -0,0 +1,165 @@
+package cluster
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/cachesync"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/lists"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node", func() {
+	var (
+		ctx      context.Context
+		cancelFn context.CancelFunc
+
+		// handlers of the test servers, set when the nodes are created
+		handlers *sync.Map
+		urls     map[string]string
+	)
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		handlers = new(sync.Map)
+		urls = make(map[string]string)
+
+		for _, name := range []string{"a", "b", "c"} {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handler, ok := handlers.Load(name)
+				if !ok {
+					http.Error(w, "not started", http.StatusServiceUnavailable)
+
+					return
+				}
+
+				http.StripPrefix(PathPrefix, handler.(http.Handler)).ServeHTTP(w, r)
+			}))
+			DeferCleanup(server.Close)
+
+			urls[name] = server.URL
+		}
+	})
+
+	newNodeWithInterval := func(name string, interval time.Duration, peers ...string) *Node {
+		cfg := config.Cluster{
+			Name:              name,
+			Secret:            "secret",
+			HeartbeatInterval: config.Duration(interval),
+		}
+
+		for _, p := range peers {
+			cfg.Peers = append(cfg.Peers, urls[p])
+		}
+
+		return New(ctx, &cfg, "hash")
+	}
+
+	newNode := func(name string, peers ...string) *Node {
+		return newNodeWithInterval(name, time.Hour, peers...)
+	}
+
+	It("should be nil if disabled", func() {
+		Expect(New(ctx, &config.Cluster{}, "hash")).Should(BeNil())
+	})
+
+	Describe("leader", func() {
+		It("should be the alive instance with the smallest name", func() {
+			b := newNode("b", "a", "c")
+			handlers.Store("b", b.Handler())
+
+			Expect(b.IsLeader()).Should(BeTrue())
+
+			a := newNode("a", "b", "c")
+			handlers.Store("a", a.Handler())
+
+			Expect(a.IsLeader()).Should(BeTrue())
+			Expect(a.Status()).Should(Equal(Status{Name: "a", Leader: "a", ConfigHash: "hash"}))
+
+			b.heartbeat(ctx)
+
+			Expect(b.IsLeader()).Should(BeFalse())
+			Expect(b.Status().Leader).Should(Equal("a"))
+		})
+
+		It("should change if the leader doesn't answer", func() {
+			a := newNode("a", "b")
+			handlers.Store("a", a.Handler())
+
+			b := newNodeWithInterval("b", 50*time.Millisecond, "a")
+
+			Expect(b.IsLeader()).Should(BeFalse())
+
+			handlers.Delete("a")
+
+			Eventually(func() bool {
+				b.heartbeat(ctx)
+
+				return b.IsLeader()
+			}).Should(BeTrue())
+		})
+	})
+
+	Describe("lists", func() {
+		It("should load the lists stored by the leader", func() {
+			a := newNode("a", "b")
+			handlers.Store("a", a.Handler())
+
+			b := newNode("b", "a")
+
+			_, err := b.Load(ctx, lists.ListCacheTypeDenylist, "ads")
+			Expect(err).Should(MatchError(ContainSubstring("404")))
+
+			a.Store(lists.ListCacheTypeDenylist, "ads & trackers", []byte("entries"))
+
+			r, err := b.Load(ctx, lists.ListCacheTypeDenylist, "ads & trackers")
+			Expect(err).Should(Succeed())
+
+			DeferCleanup(r.Close)
+
+			Expect(io.ReadAll(r)).Should(BeEquivalentTo("entries"))
+		})
+
+		It("should not load from itself", func() {
+			a := newNode("a", "b")
+
+			_, err := a.Load(ctx, lists.ListCacheTypeDenylist, "ads")
+			Expect(err).Should(MatchError(ContainSubstring("leader")))
+		})
+	})
+
+	Describe("blocking state", func() {
+		It("should be sent to the alive peers", func() {
+			a := newNode("a", "b")
+			handlers.Store("a", a.Handler())
+
+			b := newNode("b", "a")
+			handlers.Store("b", b.Handler())
+
+			a.heartbeat(ctx)
+
+			state := &cachesync.EnabledMessage{State: false, Duration: time.Minute, Groups: []string{"ads"}}
+			a.PublishEnabled(ctx, state)
+
+			Eventually(b.EnabledMessages()).Should(Receive(Equal(state)))
+		})
+	})
+
+	It("should not share the cache", func() {
+		a := newNode("a", "b")
+		a.PublishCache("key", nil)
+		a.LoadCache(ctx)
+
+		Expect(a.CacheMessages()).ShouldNot(Receive())
+		Expect(a.Ping(ctx)).Should(Succeed())
+	})
+})
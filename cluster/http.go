@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/0xERR0R/blocky/cachesync"
+	"github.com/0xERR0R/blocky/lists"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// PathPrefix is the path of the cluster endpoints on the HTTP listeners
+	PathPrefix = "/cluster"
+
+	pathStatus   = "/status"
+	pathBlocking = "/blocking"
+	pathLists    = "/lists"
+)
+
+// Handler returns the endpoints of the peers, to be mounted on `PathPrefix`:
+// the status for the heartbeats via `GET /status`, blocking state changes via `POST /blocking`
+// and the lists loaded by this instance via `GET /lists/{type}/{group}`
+func (n *Node) Handler() http.Handler {
+	router := chi.NewRouter()
+
+	router.Use(n.authenticate)
+
+	router.Get(pathStatus, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		_ = json.NewEncoder(w).Encode(n.Status())
+	})
+
+	router.Post(pathBlocking, func(w http.ResponseWriter, r *http.Request) {
+		var state cachesync.EnabledMessage
+		if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+			http.Error(w, "invalid blocking state: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		select {
+		case n.enabledChannel <- &state:
+		default:
+			http.Error(w, "too many blocking state changes", http.StatusServiceUnavailable)
+
+			return
+		}
+	})
+
+	router.Get(pathLists+"/{type}/{group}", func(w http.ResponseWriter, r *http.Request) {
+		listType, err := lists.ParseListCacheType(chi.URLParam(r, "type"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		group, err := url.PathUnescape(chi.URLParam(r, "group"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		entries, ok := n.storedList(listType, group)
+		if !ok {
+			http.Error(w, "list is not loaded", http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/gzip")
+
+		_, _ = w.Write(entries)
+	})
+
+	return router
+}
+
+// authenticate rejects requests without the shared secret
+func (n *Node) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get(authorizationHeader), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(n.cfg.Secret)) != 1 {
+			http.Error(w, "invalid cluster secret", http.StatusUnauthorized)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
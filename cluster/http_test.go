@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/0xERR0R/blocky/cachesync"
+	"github.com/0xERR0R/blocky/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var sut http.Handler
+
+	BeforeEach(func() {
+		node := &Node{
+			cfg:            &config.Cluster{Name: "a", Secret: "secret"},
+			lists:          make(map[string][]byte),
+			enabledChannel: make(chan *cachesync.EnabledMessage, 1),
+		}
+
+		sut = node.Handler()
+	})
+
+	serve := func(method, path, secret, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if secret != "" {
+			req.Header.Set(authorizationHeader, "Bearer "+secret)
+		}
+
+		rec := httptest.NewRecorder()
+		sut.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	It("should reject requests without the secret", func() {
+		Expect(serve(http.MethodGet, pathStatus, "", "").Code).Should(Equal(http.StatusUnauthorized))
+		Expect(serve(http.MethodGet, pathStatus, "wrong", "").Code).Should(Equal(http.StatusUnauthorized))
+		Expect(serve(http.MethodGet, pathStatus, "secret", "").Code).Should(Equal(http.StatusOK))
+	})
+
+	It("should reject invalid requests", func() {
+		Expect(serve(http.MethodPost, pathBlocking, "secret", "{").Code).Should(Equal(http.StatusBadRequest))
+		Expect(serve(http.MethodGet, pathLists+"/unknown/ads", "secret", "").Code).Should(Equal(http.StatusBadRequest))
+	})
+
+	It("should reject blocking state changes if the queue is full", func() {
+		Expect(serve(http.MethodPost, pathBlocking, "secret", `{"s":true}`).Code).Should(Equal(http.StatusOK))
+		Expect(serve(http.MethodPost, pathBlocking, "secret", `{"s":true}`).Code).
+			Should(Equal(http.StatusServiceUnavailable))
+	})
+})
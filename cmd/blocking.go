@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/0xERR0R/blocky/api"
 	"github.com/0xERR0R/blocky/log"
@@ -37,6 +38,15 @@ func newBlockingCommand() *cobra.Command {
 	disableCommand.Flags().StringArrayP("groups", "g", []string{}, "blocking groups to disable")
 	c.AddCommand(disableCommand)
 
+	allowCommand := &cobra.Command{
+		Use:   "allow <domain>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Allow a domain and its subdomains for certain duration",
+		RunE:  allowDomain,
+	}
+	allowCommand.Flags().DurationP("duration", "d", 15*time.Minute, "duration of the allowance")
+	c.AddCommand(allowCommand)
+
 	c.AddCommand(&cobra.Command{
 		Use:   "status",
 		Args:  cobra.NoArgs,
@@ -44,11 +54,18 @@ func newBlockingCommand() *cobra.Command {
 		RunE:  statusBlocking,
 	})
 
+	c.AddCommand(&cobra.Command{
+		Use:   "schedule",
+		Args:  cobra.NoArgs,
+		Short: "Print the schedule state of all scheduled blocking groups",
+		RunE:  scheduleBlocking,
+	})
+
 	return c
 }
 
 func enableBlocking(_ *cobra.Command, _ []string) error {
-	client, err := api.NewClientWithResponses(apiURL())
+	client, err := newAPIClient()
 	if err != nil {
 		return fmt.Errorf("can't create client: %w", err)
 	}
@@ -68,7 +85,7 @@ func disableBlocking(cmd *cobra.Command, _ []string) error {
 	durationString := duration.String()
 	groupsString := strings.Join(groups, ",")
 
-	client, err := api.NewClientWithResponses(apiURL())
+	client, err := newAPIClient()
 	if err != nil {
 		return fmt.Errorf("can't create client: %w", err)
 	}
@@ -84,8 +101,27 @@ func disableBlocking(cmd *cobra.Command, _ []string) error {
 	return printOkOrError(resp, string(resp.Body))
 }
 
+func allowDomain(cmd *cobra.Command, args []string) error {
+	duration, _ := cmd.Flags().GetDuration("duration")
+
+	client, err := newAPIClient()
+	if err != nil {
+		return fmt.Errorf("can't create client: %w", err)
+	}
+
+	resp, err := client.AllowDomainWithResponse(context.Background(), &api.AllowDomainParams{
+		Domain:   args[0],
+		Duration: duration.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("can't execute %w", err)
+	}
+
+	return printOkOrError(resp, string(resp.Body))
+}
+
 func statusBlocking(_ *cobra.Command, _ []string) error {
-	client, err := api.NewClientWithResponses(apiURL())
+	client, err := newAPIClient()
 	if err != nil {
 		return fmt.Errorf("can't create client: %w", err)
 	}
@@ -121,3 +157,40 @@ func statusBlocking(_ *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+func scheduleBlocking(_ *cobra.Command, _ []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return fmt.Errorf("can't create client: %w", err)
+	}
+
+	resp, err := client.BlockingScheduleWithResponse(context.Background())
+	if err != nil {
+		return fmt.Errorf("can't execute %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("response NOK, Status: %s", resp.Status())
+	}
+
+	if resp.JSON200 == nil || len(*resp.JSON200) == 0 {
+		log.Log().Info("no scheduled groups")
+
+		return nil
+	}
+
+	for _, s := range *resp.JSON200 {
+		state := "inactive"
+		if s.Active {
+			state = "active"
+		}
+
+		if s.NextChange == nil {
+			log.Log().Infof("group '%s': %s", s.Group, state)
+		} else {
+			log.Log().Infof("group '%s': %s until %s", s.Group, state, s.NextChange.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
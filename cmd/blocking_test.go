@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/sirupsen/logrus/hooks/test"
 
@@ -92,6 +93,39 @@ var _ = Describe("Blocking command", func() {
 			})
 		})
 	})
+	Describe("allow domain", func() {
+		When("allow domain is called via REST", func() {
+			var query url.Values
+
+			BeforeEach(func() {
+				mockFn = func(w http.ResponseWriter, r *http.Request) {
+					query = r.URL.Query()
+				}
+			})
+
+			It("should allow the domain for the duration", func() {
+				allowCommand, _, err := newBlockingCommand().Find([]string{"allow"})
+				Expect(err).Should(Succeed())
+
+				Expect(allowDomain(allowCommand, []string{"example.com"})).Should(Succeed())
+				Expect(loggerHook.LastEntry().Message).Should(Equal("OK"))
+				Expect(query.Get("domain")).Should(Equal("example.com"))
+				Expect(query.Get("duration")).Should(Equal("15m0s"))
+			})
+		})
+		When("Server returns bad request", func() {
+			BeforeEach(func() {
+				mockFn = func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			})
+			It("Should end with error", func() {
+				err := allowDomain(newBlockingCommand(), []string{"example.com"})
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("400 Bad Request"))
+			})
+		})
+	})
 	Describe("status blocking", func() {
 		When("status blocking is called via REST and blocking is enabled", func() {
 			BeforeEach(func() {
@@ -162,6 +196,42 @@ var _ = Describe("Blocking command", func() {
 			})
 		})
 	})
+	Describe("schedule blocking", func() {
+		When("schedule blocking is called via REST", func() {
+			BeforeEach(func() {
+				mockFn = func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Add("Content-Type", "application/json")
+					nextChange := time.Date(2024, time.January, 1, 16, 0, 0, 0, time.UTC)
+					response, err := json.Marshal([]api.ApiBlockingScheduleStatus{
+						{Group: "ads", Active: false},
+						{Group: "social", Active: true, NextChange: &nextChange},
+					})
+					Expect(err).Should(Succeed())
+
+					_, err = w.Write(response)
+					Expect(err).Should(Succeed())
+				}
+			})
+			It("should show the schedule state of all groups", func() {
+				Expect(scheduleBlocking(newBlockingCommand(), []string{})).Should(Succeed())
+				Expect(loggerHook.Entries).Should(HaveLen(2))
+				Expect(loggerHook.Entries[0].Message).Should(Equal("group 'ads': inactive"))
+				Expect(loggerHook.Entries[1].Message).Should(Equal("group 'social': active until 2024-01-01T16:00:00Z"))
+			})
+		})
+		When("Server returns internal error", func() {
+			BeforeEach(func() {
+				mockFn = func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			})
+			It("Should end with error", func() {
+				err := scheduleBlocking(newBlockingCommand(), []string{})
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("500 Internal Server Error"))
+			})
+		})
+	})
 })
 
 func testHTTPAPIServer(fn func(w http.ResponseWriter, _ *http.Request)) *httptest.Server {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/0xERR0R/blocky/api"
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +25,7 @@ func newCacheCommand() *cobra.Command {
 }
 
 func flushCache(_ *cobra.Command, _ []string) error {
-	client, err := api.NewClientWithResponses(apiURL())
+	client, err := newAPIClient()
 	if err != nil {
 		return fmt.Errorf("can't create client: %w", err)
 	}
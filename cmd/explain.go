@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/miekg/dns"
+	"github.com/spf13/cobra"
+)
+
+// handledBy describes which part of the resolver chain returns responses of each type
+//
+//nolint:gochecknoglobals
+var handledBy = map[string]string{
+	"RESOLVED":    "upstream resolver",
+	"CACHED":      "cache",
+	"BLOCKED":     "blocking (deny list)",
+	"CONDITIONAL": "conditional upstream",
+	"CUSTOMDNS":   "custom DNS",
+	"HOSTSFILE":   "hosts file",
+	"FILTERED":    "query type filtering",
+	"NOTFQDN":     "FQDN only filter",
+	"SPECIAL":     "special use domain",
+}
+
+func newExplainCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:               "explain <domain>",
+		Args:              cobra.ExactArgs(1),
+		Short:             "shows which rule, list or upstream handles a query (dry run)",
+		RunE:              explain,
+		PersistentPreRunE: initConfigPreRun,
+	}
+
+	c.Flags().StringP("type", "t", "A", "query type (A, AAAA, ...)")
+	c.Flags().String("client", "", "IP address of the simulated client")
+
+	return c
+}
+
+func explain(cmd *cobra.Command, args []string) error {
+	typeFlag, _ := cmd.Flags().GetString("type")
+	if dns.StringToType[typeFlag] == dns.TypeNone {
+		return fmt.Errorf("unknown query type '%s'", typeFlag)
+	}
+
+	params := api.QueryTraceParams{
+		Name: args[0],
+		Type: &typeFlag,
+	}
+
+	clientFlag, _ := cmd.Flags().GetString("client")
+	if clientFlag != "" {
+		if net.ParseIP(clientFlag) == nil {
+			return fmt.Errorf("invalid client IP '%s'", clientFlag)
+		}
+
+		params.Client = &clientFlag
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return fmt.Errorf("can't create client: %w", err)
+	}
+
+	// the trace is a dry run: the query isn't cached, logged or counted
+	resp, err := client.QueryTraceWithResponse(context.Background(), &params)
+	if err != nil {
+		return fmt.Errorf("can't execute %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("response NOK, %s %s", resp.Status(), string(resp.Body))
+	}
+
+	trace := resp.JSON200
+
+	handler, ok := handledBy[trace.ResponseType]
+	if !ok {
+		handler = trace.ResponseType
+	}
+
+	if clientFlag != "" {
+		log.Log().Infof("Query '%s' (%s) from client %s:", params.Name, typeFlag, clientFlag)
+	} else {
+		log.Log().Infof("Query '%s' (%s):", params.Name, typeFlag)
+	}
+
+	log.Log().Infof("\thandled by:     %20s", handler)
+	log.Log().Infof("\tmatched rule:   %20s", trace.Reason)
+	log.Log().Infof("\tlists:          %20s", strings.Join(trace.Lists, ","))
+	log.Log().Infof("\tupstream group: %20s", trace.UpstreamGroup)
+	log.Log().Infof("\tcache:          %20s", trace.Cache)
+	log.Log().Infof("\tresponse type:  %20s", trace.ResponseType)
+	log.Log().Infof("\tresponse:       %20s", trace.Response)
+	log.Log().Infof("\treturn code:    %20s", trace.ReturnCode)
+
+	if len(trace.Steps) != 0 {
+		log.Log().Info("\tdecisions:")
+
+		for _, step := range trace.Steps {
+			log.Log().Infof("\t\t%-20s %s", step.Resolver+":", step.Message)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Explain command", func() {
+	var (
+		ts         *httptest.Server
+		mockFn     func(w http.ResponseWriter, _ *http.Request)
+		loggerHook *test.Hook
+		query      url.Values
+	)
+	JustBeforeEach(func() {
+		ts = testHTTPAPIServer(mockFn)
+	})
+	JustAfterEach(func() {
+		ts.Close()
+	})
+	BeforeEach(func() {
+		query = nil
+		mockFn = func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).Should(Equal(http.MethodGet))
+			Expect(r.URL.Path).Should(Equal("/api/query/trace"))
+			query = r.URL.Query()
+
+			w.Header().Add("Content-Type", "application/json")
+			response, err := json.Marshal(api.ApiQueryTrace{
+				Reason:       "BLOCKED (ads)",
+				ResponseType: "BLOCKED",
+				Response:     "A (0.0.0.0)",
+				ReturnCode:   "NOERROR",
+				Lists:        []string{"ads"},
+				Steps:        []api.ApiTraceStep{{Resolver: "blocking", Message: "blocked: BLOCKED (ads)"}},
+			})
+			Expect(err).Should(Succeed())
+
+			_, err = w.Write(response)
+			Expect(err).Should(Succeed())
+		}
+		loggerHook = test.NewGlobal()
+		log.Log().AddHook(loggerHook)
+	})
+	AfterEach(func() {
+		loggerHook.Reset()
+	})
+
+	When("explain is called", func() {
+		It("should trace the query and print its handler", func() {
+			Expect(explain(newExplainCommand(), []string{"ads.com"})).Should(Succeed())
+
+			Expect(query.Get("name")).Should(Equal("ads.com"))
+			Expect(query.Get("type")).Should(Equal("A"))
+			Expect(query.Has("client")).Should(BeFalse())
+			Expect(loggerHook.Entries).Should(ContainElement(
+				HaveField("Message", ContainSubstring("blocking (deny list)"))))
+			Expect(loggerHook.Entries).Should(ContainElement(
+				HaveField("Message", SatisfyAll(ContainSubstring("matched rule"), ContainSubstring("BLOCKED (ads)")))))
+			Expect(loggerHook.Entries).Should(ContainElement(
+				HaveField("Message", SatisfyAll(ContainSubstring("lists"), ContainSubstring("ads")))))
+			Expect(loggerHook.Entries).Should(ContainElement(
+				HaveField("Message", ContainSubstring("blocked: BLOCKED (ads)"))))
+		})
+	})
+
+	When("a client is passed", func() {
+		It("should send it with the query", func() {
+			command := newExplainCommand()
+			Expect(command.Flags().Set("client", "192.168.178.10")).Should(Succeed())
+
+			Expect(explain(command, []string{"ads.com"})).Should(Succeed())
+
+			Expect(query.Get("client")).Should(Equal("192.168.178.10"))
+		})
+	})
+
+	When("the client is invalid", func() {
+		It("should end with error", func() {
+			command := newExplainCommand()
+			Expect(command.Flags().Set("client", "invalid")).Should(Succeed())
+
+			err := explain(command, []string{"ads.com"})
+			Expect(err).Should(MatchError(ContainSubstring("invalid client IP 'invalid'")))
+		})
+	})
+
+	When("Type is wrong", func() {
+		It("should end with error", func() {
+			command := newExplainCommand()
+			command.SetArgs([]string{"--type", "X", "google.de"})
+			err := command.Execute()
+			Expect(err).Should(MatchError(ContainSubstring("unknown query type 'X'")))
+		})
+	})
+
+	When("Server returns 500", func() {
+		BeforeEach(func() {
+			mockFn = func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})
+		It("should end with error", func() {
+			err := explain(newExplainCommand(), []string{"google.de"})
+			Expect(err).Should(MatchError(ContainSubstring("500 Internal Server Error")))
+		})
+	})
+})
@@ -11,6 +11,7 @@ import (
 const (
 	defaultDNSPort   = 53
 	defaultIPAddress = "127.0.0.1"
+	healthCheckName  = "healthcheck.blocky"
 )
 
 func NewHealthcheckCommand() *cobra.Command {
@@ -22,6 +23,8 @@ func NewHealthcheckCommand() *cobra.Command {
 
 	c.Flags().Uint16P("port", "p", defaultDNSPort, "blocky port")
 	c.Flags().StringP("bindip", "b", defaultIPAddress, "blocky host binding ip address")
+	c.Flags().StringP("name", "n", healthCheckName,
+		"domain to resolve, e.g. example.com to check the resolution through the upstreams")
 
 	return c
 }
@@ -30,13 +33,17 @@ func healthcheck(cmd *cobra.Command, args []string) error {
 	_ = args
 	port, _ := cmd.Flags().GetUint16("port")
 	bindIP, _ := cmd.Flags().GetString("bindip")
+	name, _ := cmd.Flags().GetString("name")
 
 	c := new(dns.Client)
 	c.Net = "tcp"
 	m := new(dns.Msg)
-	m.SetQuestion("healthcheck.blocky.", dns.TypeA)
+	m.SetQuestion(dns.Fqdn(name), dns.TypeA)
 
-	_, _, err := c.Exchange(m, net.JoinHostPort(bindIP, fmt.Sprintf("%d", port)))
+	resp, _, err := c.Exchange(m, net.JoinHostPort(bindIP, fmt.Sprintf("%d", port)))
+	if err == nil && resp.Rcode != dns.RcodeSuccess {
+		err = fmt.Errorf("can't resolve %s: %s", name, dns.RcodeToString[resp.Rcode])
+	}
 
 	if err == nil {
 		fmt.Println("OK")
@@ -48,6 +48,30 @@ var _ = Describe("Healthcheck command", func() {
 				return c.Execute()
 			}, "1s").Should(Succeed())
 		})
+
+		It("should resolve the name", func() {
+			ip := "127.0.0.1"
+			hostPort := helpertest.GetHostPort(ip, 65101)
+			port := helpertest.GetStringPort(65101)
+			srv := createMockServer(hostPort)
+			go func() {
+				defer GinkgoRecover()
+				err := srv.ListenAndServe()
+				Expect(err).Should(Succeed())
+			}()
+
+			Eventually(func() error {
+				c := NewHealthcheckCommand()
+				c.SetArgs([]string{"-p", port, "-b", ip, "-n", "example.com"})
+
+				return c.Execute()
+			}, "1s").Should(Succeed())
+
+			c := NewHealthcheckCommand()
+			c.SetArgs([]string{"-p", port, "-b", ip, "-n", "unknown.com"})
+
+			Expect(c.Execute()).Should(MatchError(ContainSubstring("can't resolve unknown.com: SERVFAIL")))
+		})
 	})
 })
 
@@ -70,6 +94,18 @@ func createMockServer(hostPort string) *dns.Server {
 		err := w.WriteMsg(resp)
 		Expect(err).Should(Succeed())
 	})
+	th.HandleFunc("example.com", func(w dns.ResponseWriter, request *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(request)
+
+		Expect(w.WriteMsg(resp)).Should(Succeed())
+	})
+	th.HandleFunc("unknown.com", func(w dns.ResponseWriter, request *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetRcode(request, dns.RcodeServerFailure)
+
+		Expect(w.WriteMsg(resp)).Should(Succeed())
+	})
 
 	DeferCleanup(res.Shutdown)
 
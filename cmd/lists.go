@@ -3,8 +3,13 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
 
 	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/lists/formats"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +21,7 @@ func NewListsCommand() *cobra.Command {
 		PersistentPreRunE: initConfigPreRun,
 	}
 
-	c.AddCommand(newRefreshCommand())
+	c.AddCommand(newRefreshCommand(), newExportCommand(), newImportCommand())
 
 	return c
 }
@@ -29,8 +34,39 @@ func newRefreshCommand() *cobra.Command {
 	}
 }
 
+func newExportCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "export",
+		Args:  cobra.NoArgs,
+		Short: "Print the inline and local file rules of the allow/denylists in the given format",
+		RunE:  exportList,
+	}
+
+	c.Flags().StringP("format", "f", formats.FormatAdguard.String(),
+		fmt.Sprintf("format of the rules [%s]", strings.Join(formats.FormatNames(), ", ")))
+	c.Flags().StringP("type", "t", "", "type of the rules to export [deny, allow], empty for both (AdGuard only)")
+	c.Flags().StringArrayP("groups", "g", []string{}, "groups to export, all groups if empty")
+
+	return c
+}
+
+func newImportCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "import [file]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Convert rules of the given format (from the file or stdin) to the blocky list format",
+		RunE:  importList,
+	}
+
+	c.Flags().StringP("format", "f", formats.FormatAdguard.String(),
+		fmt.Sprintf("format of the rules [%s]", strings.Join(formats.FormatNames(), ", ")))
+	c.Flags().StringP("type", "t", string(api.ListTypeDeny), "type of the rules to import [deny, allow]")
+
+	return c
+}
+
 func refreshList(_ *cobra.Command, _ []string) error {
-	client, err := api.NewClientWithResponses(apiURL())
+	client, err := newAPIClient()
 	if err != nil {
 		return fmt.Errorf("can't create client: %w", err)
 	}
@@ -42,3 +78,67 @@ func refreshList(_ *cobra.Command, _ []string) error {
 
 	return printOkOrError(resp, string(resp.Body))
 }
+
+func exportList(cmd *cobra.Command, _ []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	listType, _ := cmd.Flags().GetString("type")
+	groups, _ := cmd.Flags().GetStringArray("groups")
+
+	params := api.ListExportParams{Format: api.ApiListFormat(format)}
+
+	if listType != "" {
+		t := api.ApiListType(listType)
+		params.Type = &t
+	}
+
+	if len(groups) > 0 {
+		groupsString := strings.Join(groups, ",")
+		params.Groups = &groupsString
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return fmt.Errorf("can't create client: %w", err)
+	}
+
+	resp, err := client.ListExportWithResponse(context.Background(), &params)
+	if err != nil {
+		return fmt.Errorf("can't execute %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("response NOK, %s %s", resp.Status(), string(resp.Body))
+	}
+
+	_, err = cmd.OutOrStdout().Write(resp.Body)
+
+	return err
+}
+
+func importList(cmd *cobra.Command, args []string) error {
+	formatName, _ := cmd.Flags().GetString("format")
+	listType, _ := cmd.Flags().GetString("type")
+
+	format, err := formats.ParseFormat(formatName)
+	if err != nil {
+		return err
+	}
+
+	if listType != string(api.ListTypeDeny) && listType != string(api.ListTypeAllow) {
+		return fmt.Errorf("unknown type '%s'", listType)
+	}
+
+	var r io.Reader = cmd.InOrStdin()
+
+	if len(args) == 1 {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	return formats.Import(cmd.OutOrStdout(), r, format, listType == string(api.ListTypeAllow))
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/0xERR0R/blocky/log"
 	"github.com/sirupsen/logrus/hooks/test"
@@ -74,4 +78,99 @@ var _ = Describe("Lists command", func() {
 			})
 		})
 	})
+	Describe("Call list export command", func() {
+		var out *bytes.Buffer
+
+		BeforeEach(func() {
+			out = &bytes.Buffer{}
+			c = NewListsCommand()
+			c.SetOut(out)
+		})
+		When("list export is executed", func() {
+			BeforeEach(func() {
+				c.SetArgs([]string{"export", "--format", "pihole", "--type", "allow", "-g", "kids", "-g", "ads"})
+				mockFn = func(w http.ResponseWriter, r *http.Request) {
+					Expect(r.URL.Path).Should(Equal("/api/lists/export"))
+					Expect(r.URL.Query().Get("format")).Should(Equal("pihole"))
+					Expect(r.URL.Query().Get("type")).Should(Equal("allow"))
+					Expect(r.URL.Query().Get("groups")).Should(Equal("kids,ads"))
+
+					_, _ = w.Write([]byte("good.com\n"))
+				}
+			})
+			It("should print the rules", func() {
+				err = c.Execute()
+				Expect(err).Should(Succeed())
+
+				Expect(out.String()).Should(Equal("good.com\n"))
+			})
+		})
+		When("Server returns 400", func() {
+			BeforeEach(func() {
+				c.SetArgs([]string{"export", "--format", "blocky"})
+				mockFn = func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte("format 'blocky' needs a type"))
+				}
+			})
+			It("should end with error", func() {
+				err = c.Execute()
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).Should(ContainSubstring("needs a type"))
+			})
+		})
+	})
+	Describe("Call list import command", func() {
+		var out *bytes.Buffer
+
+		BeforeEach(func() {
+			out = &bytes.Buffer{}
+			c = NewListsCommand()
+			c.SetOut(out)
+		})
+		When("rules are read from stdin", func() {
+			BeforeEach(func() {
+				c.SetIn(strings.NewReader("! comment\n||ads.com^\n"))
+				c.SetArgs([]string{"import", "--format", "adguard"})
+			})
+			It("should print the rules in the blocky format", func() {
+				err = c.Execute()
+				Expect(err).Should(Succeed())
+
+				Expect(out.String()).Should(Equal("*.ads.com\n"))
+			})
+		})
+		When("rules are read from a file", func() {
+			BeforeEach(func() {
+				file := filepath.Join(GinkgoT().TempDir(), "regex.list")
+				Expect(os.WriteFile(file, []byte(`(\.|^)ads\.com$`), 0o600)).Should(Succeed())
+
+				c.SetArgs([]string{"import", "-f", "pihole", "-t", "allow", file})
+			})
+			It("should print the rules in the blocky format", func() {
+				err = c.Execute()
+				Expect(err).Should(Succeed())
+
+				Expect(out.String()).Should(Equal("*.ads.com\n"))
+			})
+		})
+		When("format is unknown", func() {
+			BeforeEach(func() {
+				c.SetArgs([]string{"import", "--format", "hosts"})
+			})
+			It("should end with error", func() {
+				err = c.Execute()
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+		When("type is unknown", func() {
+			BeforeEach(func() {
+				c.SetArgs([]string{"import", "--type", "block"})
+			})
+			It("should end with error", func() {
+				err = c.Execute()
+				Expect(err).Should(MatchError("unknown type 'block'"))
+			})
+		})
+	})
 })
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/0xERR0R/blocky/api"
 	"github.com/0xERR0R/blocky/log"
@@ -22,6 +23,7 @@ func NewQueryCommand() *cobra.Command {
 	}
 
 	c.Flags().StringP("type", "t", "A", "query type (A, AAAA, ...)")
+	c.Flags().Bool("trace", false, "show how the query is resolved without caching or logging it")
 
 	return c
 }
@@ -34,11 +36,15 @@ func query(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unknown query type '%s'", typeFlag)
 	}
 
-	client, err := api.NewClientWithResponses(apiURL())
+	client, err := newAPIClient()
 	if err != nil {
 		return fmt.Errorf("can't create client: %w", err)
 	}
 
+	if trace, _ := cmd.Flags().GetBool("trace"); trace {
+		return queryTrace(client, args[0], typeFlag)
+	}
+
 	req := api.ApiQueryRequest{
 		Query: args[0],
 		Type:  typeFlag,
@@ -61,3 +67,34 @@ func query(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func queryTrace(client *api.ClientWithResponses, domain, qType string) error {
+	resp, err := client.QueryTraceWithResponse(context.Background(), &api.QueryTraceParams{
+		Name: domain,
+		Type: &qType,
+	})
+	if err != nil {
+		return fmt.Errorf("can't execute %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("response NOK, %s %s", resp.Status(), string(resp.Body))
+	}
+
+	log.Log().Infof("Query trace for '%s' (%s):", domain, qType)
+
+	for _, step := range resp.JSON200.Steps {
+		log.Log().Infof("\t%-20s %s", step.Resolver+":", step.Message)
+	}
+
+	log.Log().Infof("\tcache:          %20s", resp.JSON200.Cache)
+	log.Log().Infof("\tlists:          %20s", strings.Join(resp.JSON200.Lists, ","))
+	log.Log().Infof("\tupstream group: %20s", resp.JSON200.UpstreamGroup)
+	log.Log().Infof("\tauthenticated:  %20t", resp.JSON200.AuthenticatedData)
+	log.Log().Infof("\treason:         %20s", resp.JSON200.Reason)
+	log.Log().Infof("\tresponse type:  %20s", resp.JSON200.ResponseType)
+	log.Log().Infof("\tresponse:       %20s", resp.JSON200.Response)
+	log.Log().Infof("\treturn code:    %20s", resp.JSON200.ReturnCode)
+
+	return nil
+}
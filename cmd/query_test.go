@@ -70,6 +70,44 @@ var _ = Describe("Blocking command", func() {
 				Expect(loggerHook.LastEntry().Message).Should(ContainSubstring("NOERROR"))
 			})
 		})
+		When("query command is called with trace", func() {
+			var path string
+
+			BeforeEach(func() {
+				mockFn = func(w http.ResponseWriter, r *http.Request) {
+					path = r.URL.Path + "?" + r.URL.RawQuery
+
+					w.Header().Add("Content-Type", "application/json")
+					response, err := json.Marshal(api.ApiQueryTrace{
+						Reason:       "BLOCKED (ads)",
+						ResponseType: "BLOCKED",
+						Response:     "A (0.0.0.0)",
+						ReturnCode:   "NOERROR",
+						Lists:        []string{"ads"},
+						Steps:        []api.ApiTraceStep{{Resolver: "blocking", Message: "blocked: BLOCKED (ads)"}},
+					})
+					Expect(err).Should(Succeed())
+
+					_, err = w.Write(response)
+					Expect(err).Should(Succeed())
+				}
+			})
+			It("should print the trace", func() {
+				command := NewQueryCommand()
+				Expect(command.Flags().Set("trace", "true")).Should(Succeed())
+
+				Expect(query(command, []string{"ads.example.com"})).Should(Succeed())
+				Expect(path).Should(Equal("/api/query/trace?name=ads.example.com&type=A"))
+
+				var messages []string
+				for _, entry := range loggerHook.AllEntries() {
+					messages = append(messages, entry.Message)
+				}
+
+				Expect(messages).Should(ContainElement(ContainSubstring("blocked: BLOCKED (ads)")))
+				Expect(loggerHook.LastEntry().Message).Should(ContainSubstring("NOERROR"))
+			})
+		})
 		When("Server returns 500", func() {
 			BeforeEach(func() {
 				mockFn = func(w http.ResponseWriter, _ *http.Request) {
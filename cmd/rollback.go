@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/spf13/cobra"
+)
+
+func newRollbackCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rollback [snapshot]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Roll back to a snapshot of the configuration and runtime state, lists the snapshots without argument",
+		Long: `Roll back to a snapshot of the configuration and runtime state.
+
+The runtime state (e.g. disabled blocking) is restored immediately, the configuration is written to
+the configuration file and applied on the next start. Without argument, all snapshots are listed.`,
+		PersistentPreRunE: initConfigPreRun,
+		RunE:              rollback,
+	}
+}
+
+func rollback(_ *cobra.Command, args []string) error {
+	client, err := newAPIClient()
+	if err != nil {
+		return fmt.Errorf("can't create client: %w", err)
+	}
+
+	if len(args) == 0 {
+		return listSnapshots(client)
+	}
+
+	resp, err := client.SnapshotRollbackWithResponse(context.Background(), args[0])
+	if err != nil {
+		return fmt.Errorf("can't execute %w", err)
+	}
+
+	return printOkOrError(resp, string(resp.Body))
+}
+
+func listSnapshots(client *api.ClientWithResponses) error {
+	resp, err := client.SnapshotsWithResponse(context.Background())
+	if err != nil {
+		return fmt.Errorf("can't execute %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("response NOK, %s %s", resp.Status(), string(resp.Body))
+	}
+
+	if resp.JSON200 == nil || len(*resp.JSON200) == 0 {
+		log.Log().Info("no snapshots")
+
+		return nil
+	}
+
+	for _, s := range *resp.JSON200 {
+		log.Log().Infof("%s: %s snapshot of %s, config %.12s", s.Name, s.Reason, s.Created.Format(time.RFC3339), s.ConfigHash)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/log"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rollback command", func() {
+	var (
+		ts         *httptest.Server
+		mockFn     func(w http.ResponseWriter, _ *http.Request)
+		loggerHook *test.Hook
+	)
+	JustBeforeEach(func() {
+		ts = testHTTPAPIServer(mockFn)
+	})
+	JustAfterEach(func() {
+		ts.Close()
+	})
+	BeforeEach(func() {
+		mockFn = func(w http.ResponseWriter, _ *http.Request) {}
+		loggerHook = test.NewGlobal()
+		log.Log().AddHook(loggerHook)
+	})
+	AfterEach(func() {
+		loggerHook.Reset()
+	})
+	When("no snapshot is given", func() {
+		BeforeEach(func() {
+			mockFn = func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).Should(Equal("/api/snapshots"))
+
+				w.Header().Add("Content-Type", "application/json")
+				response, err := json.Marshal([]api.ApiSnapshot{
+					{
+						Name:       "20240501-102030-start",
+						Created:    time.Date(2024, time.May, 1, 10, 20, 30, 0, time.UTC),
+						Reason:     "start",
+						ConfigHash: "0123456789abcdef",
+					},
+				})
+				Expect(err).Should(Succeed())
+
+				_, err = w.Write(response)
+				Expect(err).Should(Succeed())
+			}
+		})
+		It("should list the snapshots", func() {
+			Expect(rollback(newRollbackCommand(), []string{})).Should(Succeed())
+			Expect(loggerHook.LastEntry().Message).Should(Equal(
+				"20240501-102030-start: start snapshot of 2024-05-01T10:20:30Z, config 0123456789ab",
+			))
+		})
+	})
+	When("there are no snapshots", func() {
+		BeforeEach(func() {
+			mockFn = func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Add("Content-Type", "application/json")
+				_, err := w.Write([]byte("[]"))
+				Expect(err).Should(Succeed())
+			}
+		})
+		It("should say so", func() {
+			Expect(rollback(newRollbackCommand(), []string{})).Should(Succeed())
+			Expect(loggerHook.LastEntry().Message).Should(Equal("no snapshots"))
+		})
+	})
+	When("a snapshot is given", func() {
+		BeforeEach(func() {
+			mockFn = func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).Should(Equal(http.MethodPost))
+				Expect(r.URL.Path).Should(Equal("/api/snapshots/20240501-102030-start/rollback"))
+			}
+		})
+		It("should roll back to it", func() {
+			Expect(rollback(newRollbackCommand(), []string{"20240501-102030-start"})).Should(Succeed())
+			Expect(loggerHook.LastEntry().Message).Should(Equal("OK"))
+		})
+	})
+	When("the snapshot does not exist", func() {
+		BeforeEach(func() {
+			mockFn = func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		It("should end with error", func() {
+			err := rollback(newRollbackCommand(), []string{"unknown"})
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).Should(ContainSubstring("404 Not Found"))
+		})
+	})
+	When("Server returns internal error", func() {
+		BeforeEach(func() {
+			mockFn = func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})
+		It("should end with error", func() {
+			err := rollback(newRollbackCommand(), []string{})
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).Should(ContainSubstring("500 Internal Server Error"))
+		})
+	})
+})
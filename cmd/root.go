@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/0xERR0R/blocky/api"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/spf13/cobra"
@@ -18,6 +20,7 @@ var (
 	configPath string
 	apiHost    string
 	apiPort    uint16
+	apiToken   string
 )
 
 const (
@@ -26,6 +29,7 @@ const (
 	defaultConfigPath   = "./config.yml"
 	configFileEnvVar    = "BLOCKY_CONFIG_FILE"
 	configFileEnvVarOld = "CONFIG_FILE"
+	apiTokenEnvVar      = "BLOCKY_API_TOKEN"
 )
 
 // NewRootCommand creates a new root cli command instance
@@ -47,6 +51,7 @@ Complete documentation is available at https://github.com/0xERR0R/blocky`,
 	c.PersistentFlags().StringVarP(&configPath, "config", "c", defaultConfigPath, "path to config file or folder")
 	c.PersistentFlags().StringVar(&apiHost, "apiHost", defaultHost, "host of blocky (API). Default overridden by config and CLI.") //nolint:lll
 	c.PersistentFlags().Uint16Var(&apiPort, "apiPort", defaultPort, "port of blocky (API). Default overridden by config and CLI.") //nolint:lll
+	c.PersistentFlags().StringVar(&apiToken, "apiToken", "", "bearer token for the API, default from "+apiTokenEnvVar)
 
 	c.AddCommand(newRefreshCommand(),
 		NewQueryCommand(),
@@ -56,7 +61,9 @@ Complete documentation is available at https://github.com/0xERR0R/blocky`,
 		NewListsCommand(),
 		NewHealthcheckCommand(),
 		newCacheCommand(),
-		NewValidateCommand())
+		NewValidateCommand(),
+		newExplainCommand(),
+		newRollbackCommand())
 
 	return c
 }
@@ -65,11 +72,28 @@ func apiURL() string {
 	return fmt.Sprintf("http://%s%s", net.JoinHostPort(apiHost, strconv.Itoa(int(apiPort))), "/api")
 }
 
+// newAPIClient creates an API client which authenticates with the API token, if set
+func newAPIClient() (*api.ClientWithResponses, error) {
+	token := apiToken
+	if token == "" {
+		token = os.Getenv(apiTokenEnvVar)
+	}
+
+	return api.NewClientWithResponses(apiURL(), api.WithRequestEditorFn(func(_ context.Context, req *http.Request) error {
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		return nil
+	}))
+}
+
 func initConfigPreRun(cmd *cobra.Command, args []string) error {
 	return initConfig()
 }
 
-func initConfig() error {
+// resolveConfigPath uses the config path from the environment if it wasn't set with a flag
+func resolveConfigPath() {
 	if configPath == defaultConfigPath {
 		val, present := os.LookupEnv(configFileEnvVar)
 		if present {
@@ -81,10 +105,21 @@ func initConfig() error {
 			}
 		}
 	}
+}
+
+func initConfig() error {
+	_, err := loadConfig()
+
+	return err
+}
+
+// loadConfig loads the configuration, configures the logger and the API address
+func loadConfig() (*config.Config, error) {
+	resolveConfigPath()
 
 	cfg, err := config.LoadConfig(configPath, false)
 	if err != nil {
-		return fmt.Errorf("unable to load configuration file '%s': %w", configPath, err)
+		return nil, fmt.Errorf("unable to load configuration file '%s': %w", configPath, err)
 	}
 
 	log.Configure(&cfg.Log)
@@ -98,13 +133,13 @@ func initConfig() error {
 
 		port, err := config.ConvertPort(split[lastIdx])
 		if err != nil {
-			return fmt.Errorf("can't convert port '%s' to number (1 - 65535): %w", split[lastIdx], err)
+			return nil, fmt.Errorf("can't convert port '%s' to number (1 - 65535): %w", split[lastIdx], err)
 		}
 
 		apiPort = port
 	}
 
-	return nil
+	return cfg, nil
 }
 
 // Execute starts the command
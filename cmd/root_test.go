@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"io"
+	"net/http"
 	"os"
 
 	"github.com/0xERR0R/blocky/log"
@@ -67,4 +69,53 @@ var _ = Describe("root command", func() {
 			Expect(configPath).Should(Equal(tmpFile.Path))
 		})
 	})
+
+	Describe("API client", func() {
+		var authorization chan string
+
+		BeforeEach(func() {
+			authorization = make(chan string, 1)
+
+			ts := testHTTPAPIServer(func(w http.ResponseWriter, r *http.Request) {
+				authorization <- r.Header.Get("Authorization")
+
+				w.WriteHeader(http.StatusOK)
+			})
+			DeferCleanup(ts.Close)
+
+			apiToken = ""
+			DeferCleanup(func() { apiToken = "" })
+		})
+
+		send := func() {
+			client, err := newAPIClient()
+			Expect(err).Should(Succeed())
+
+			_, err = client.CacheFlushWithResponse(context.Background())
+			Expect(err).Should(Succeed())
+		}
+
+		It("should not authenticate without token", func() {
+			send()
+
+			Expect(authorization).Should(Receive(BeEmpty()))
+		})
+
+		It("should send the token of the flag", func() {
+			apiToken = "flag-token"
+
+			send()
+
+			Expect(authorization).Should(Receive(Equal("Bearer flag-token")))
+		})
+
+		It("should send the token of the env var", func() {
+			os.Setenv(apiTokenEnvVar, "env-token")
+			DeferCleanup(func() { os.Unsetenv(apiTokenEnvVar) })
+
+			send()
+
+			Expect(authorization).Should(Receive(Equal("Bearer env-token")))
+		})
+	})
 })
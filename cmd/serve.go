@@ -13,6 +13,7 @@ import (
 	"github.com/0xERR0R/blocky/server"
 	"github.com/0xERR0R/blocky/util"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -35,8 +36,6 @@ func newServeCommand() *cobra.Command {
 }
 
 func startServer(_ *cobra.Command, _ []string) error {
-	printBanner()
-
 	cfg, err := config.LoadConfig(configPath, isConfigMandatory)
 	if err != nil {
 		return fmt.Errorf("unable to load configuration: %w", err)
@@ -44,6 +43,8 @@ func startServer(_ *cobra.Command, _ []string) error {
 
 	log.Configure(&cfg.Log)
 
+	printBanner(cfg)
+
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 
 	ctx, cancelFn := context.WithCancel(context.Background())
@@ -81,20 +82,16 @@ func startServer(_ *cobra.Command, _ []string) error {
 	return terminationErr
 }
 
-func printBanner() {
-	log.Log().Info("_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/")
-	log.Log().Info("_/                                                              _/")
-	log.Log().Info("_/                                                              _/")
-	log.Log().Info("_/       _/        _/                      _/                   _/")
-	log.Log().Info("_/      _/_/_/    _/    _/_/      _/_/_/  _/  _/    _/    _/    _/")
-	log.Log().Info("_/     _/    _/  _/  _/    _/  _/        _/_/      _/    _/     _/")
-	log.Log().Info("_/    _/    _/  _/  _/    _/  _/        _/  _/    _/    _/      _/")
-	log.Log().Info("_/   _/_/_/    _/    _/_/      _/_/_/  _/    _/    _/_/_/       _/")
-	log.Log().Info("_/                                                    _/        _/")
-	log.Log().Info("_/                                               _/_/           _/")
-	log.Log().Info("_/                                                              _/")
-	log.Log().Info("_/                                                              _/")
-	log.Log().Infof("_/  Version: %-18s Build time: %-18s  _/", util.Version, util.BuildTime)
-	log.Log().Info("_/                                                              _/")
-	log.Log().Info("_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/_/")
+// printBanner logs the build information as structured fields, so it is machine-readable in JSON logs
+func printBanner(cfg *config.Config) {
+	info := util.GetBuildInfo()
+
+	log.Log().WithFields(logrus.Fields{
+		"version":      info.Version,
+		"build_time":   info.BuildTime,
+		"architecture": info.Architecture,
+		"go_version":   info.GoVersion,
+		"build_tags":   info.BuildTags,
+		"config_hash":  cfg.Hash,
+	}).Info("starting blocky")
 }
@@ -1,25 +1,35 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"slices"
 
+	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/log"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
 )
 
 // NewValidateCommand creates new command instance
 func NewValidateCommand() *cobra.Command {
-	return &cobra.Command{
+	c := &cobra.Command{
 		Use:   "validate",
 		Args:  cobra.NoArgs,
 		Short: "Validates the configuration",
 		RunE:  validateConfiguration,
 	}
+
+	c.Flags().Bool("online", false, "checks that the list and hosts file sources can be loaded")
+
+	return c
 }
 
-func validateConfiguration(_ *cobra.Command, _ []string) error {
+func validateConfiguration(cmd *cobra.Command, _ []string) error {
 	log.Log().Infof("Validating configuration file: %s", configPath)
 
 	_, err := os.Stat(configPath)
@@ -27,12 +37,90 @@ func validateConfiguration(_ *cobra.Command, _ []string) error {
 		return errors.New("configuration path does not exist")
 	}
 
-	err = initConfig()
+	cfg, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
+	if online, _ := cmd.Flags().GetBool("online"); online {
+		if err := checkSources(cmd.Context(), cfg); err != nil {
+			return err
+		}
+	}
+
 	log.Log().Info("Configuration is valid")
 
 	return nil
 }
+
+// checkSources checks that all HTTP sources can be downloaded and all file sources exist
+func checkSources(ctx context.Context, cfg *config.Config) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	client := &http.Client{Timeout: cfg.Blocking.Loading.Downloads.Timeout.ToDuration()}
+
+	var errs []error
+
+	check := func(kind string, sources []config.BytesSource) {
+		for _, source := range sources {
+			if err := checkSource(ctx, client, source); err != nil {
+				log.Log().Errorf("%s: %s: %s", kind, source, err)
+
+				errs = append(errs, fmt.Errorf("%s %s: %w", kind, source, err))
+
+				continue
+			}
+
+			log.Log().Debugf("%s: %s is reachable", kind, source)
+		}
+	}
+
+	for _, lists := range []struct {
+		kind   string
+		groups map[string][]config.BytesSource
+	}{
+		{"denylist", cfg.Blocking.Denylists},
+		{"allowlist", cfg.Blocking.Allowlists},
+	} {
+		groups := maps.Keys(lists.groups)
+		slices.Sort(groups)
+
+		for _, group := range groups {
+			check(lists.kind+" "+group, lists.groups[group])
+		}
+	}
+
+	check("hosts file", cfg.HostsFile.Sources)
+
+	return errors.Join(errs...)
+}
+
+func checkSource(ctx context.Context, client *http.Client, source config.BytesSource) error {
+	switch source.Type {
+	case config.BytesSourceTypeHttp:
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.From, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		resp.Body.Close()
+
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("got status %s", resp.Status)
+		}
+
+	case config.BytesSourceTypeFile:
+		if _, err := os.Stat(source.From); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
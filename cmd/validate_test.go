@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"net/http"
+	"net/http/httptest"
+
 	"github.com/0xERR0R/blocky/helpertest"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -49,4 +52,56 @@ var _ = Describe("Validate command", func() {
 			Expect(c.Execute()).Should(HaveOccurred())
 		})
 	})
+
+	When("Validate is called with --online", func() {
+		var ts *httptest.Server
+
+		BeforeEach(func() {
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/list.txt" {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			DeferCleanup(ts.Close)
+		})
+
+		It("should succeed if all sources can be loaded", func() {
+			listFile := tmpDir.CreateStringFile("list.txt", "ads.com")
+			cfgFile := tmpDir.CreateStringFile("config.yaml",
+				"upstreams:",
+				"  groups:",
+				"    default:",
+				"      - 1.1.1.1",
+				"blocking:",
+				"  denylists:",
+				"    ads:",
+				"      - "+ts.URL+"/list.txt",
+				"      - "+listFile.Path)
+
+			c := NewRootCommand()
+			c.SetArgs([]string{"validate", "--online", "--config", cfgFile.Path})
+
+			Expect(c.Execute()).Should(Succeed())
+		})
+
+		It("should fail if a source can't be loaded", func() {
+			cfgFile := tmpDir.CreateStringFile("config.yaml",
+				"upstreams:",
+				"  groups:",
+				"    default:",
+				"      - 1.1.1.1",
+				"blocking:",
+				"  denylists:",
+				"    ads:",
+				"      - "+ts.URL+"/missing.txt",
+				"      - /does/not/exist.txt")
+
+			c := NewRootCommand()
+			c.SetArgs([]string{"validate", "--online", "--config", cfgFile.Path})
+
+			err := c.Execute()
+			Expect(err).Should(MatchError(ContainSubstring("404 Not Found")))
+			Expect(err).Should(MatchError(ContainSubstring("/does/not/exist.txt")))
+		})
+	})
 })
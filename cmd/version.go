@@ -1,25 +1,70 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 
+	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/util"
 	"github.com/spf13/cobra"
 )
 
+// versionInfo is the JSON output of the version command
+type versionInfo struct {
+	util.BuildInfo
+
+	// empty if there is no configuration
+	ConfigHash string `json:"configHash,omitempty"`
+}
+
 // NewVersionCommand creates new command instance
 func NewVersionCommand() *cobra.Command {
-	return &cobra.Command{
+	c := &cobra.Command{
 		Use:   "version",
 		Args:  cobra.NoArgs,
 		Short: "Print the version number of blocky",
-		Run:   printVersion,
+		RunE:  printVersion,
 	}
+
+	c.Flags().Bool("json", false, "print the build information and config hash as JSON")
+
+	return c
 }
 
-func printVersion(_ *cobra.Command, _ []string) {
+func printVersion(cmd *cobra.Command, _ []string) error {
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printVersionJSON(cmd)
+	}
+
 	fmt.Println("blocky")
 	fmt.Printf("Version: %s\n", util.Version)
 	fmt.Printf("Build time: %s\n", util.BuildTime)
 	fmt.Printf("Architecture: %s\n", util.Architecture)
+
+	return nil
+}
+
+func printVersionJSON(cmd *cobra.Command) error {
+	resolveConfigPath()
+
+	info := versionInfo{BuildInfo: util.GetBuildInfo()}
+
+	hash, err := config.HashConfig(configPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	info.ConfigHash = hash
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(info)
 }
@@ -1,6 +1,12 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/util"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -14,4 +20,57 @@ var _ = Describe("Version command", func() {
 			Expect(err).Should(Succeed())
 		})
 	})
+
+	When("Version command is called with --json", func() {
+		var (
+			tmpDir *helpertest.TmpFolder
+			out    *bytes.Buffer
+		)
+
+		BeforeEach(func() {
+			tmpDir = helpertest.NewTmpFolder("config")
+			out = new(bytes.Buffer)
+		})
+
+		It("should print the build info and config hash", func() {
+			cfgFile := tmpDir.CreateStringFile("config.yaml",
+				"upstreams:",
+				"  groups:",
+				"    default:",
+				"      - 1.1.1.1")
+
+			c := NewRootCommand()
+			c.SetOut(out)
+			c.SetArgs([]string{"version", "--json", "--config", cfgFile.Path})
+			Expect(c.Execute()).Should(Succeed())
+
+			var info map[string]any
+			Expect(json.Unmarshal(out.Bytes(), &info)).Should(Succeed())
+
+			Expect(info).Should(HaveKeyWithValue("version", util.Version))
+			Expect(info).Should(HaveKeyWithValue("buildTime", util.BuildTime))
+			Expect(info).Should(HaveKeyWithValue("goVersion", Not(BeEmpty())))
+			Expect(info).Should(HaveKey("buildTags"))
+			Expect(info).Should(HaveKeyWithValue("configHash", Equal(must(config.HashConfig(cfgFile.Path)))))
+		})
+
+		It("should omit the config hash without configuration", func() {
+			c := NewRootCommand()
+			c.SetOut(out)
+			c.SetArgs([]string{"version", "--json", "--config", "/notexisting/path.yaml"})
+			Expect(c.Execute()).Should(Succeed())
+
+			var info map[string]any
+			Expect(json.Unmarshal(out.Bytes(), &info)).Should(Succeed())
+
+			Expect(info).Should(HaveKey("version"))
+			Expect(info).ShouldNot(HaveKey("configHash"))
+		})
+	})
 })
+
+func must[T any](val T, err error) T {
+	Expect(err).Should(Succeed())
+
+	return val
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ACME configures the automatic management of the DoT/DoH certificate via ACME (RFC 8555), e.g. with Let's Encrypt
+type ACME struct {
+	// Domains of the certificate
+	Domains []string `yaml:"domains"`
+	// Contact of the account, e.g. for expiry notices of the CA
+	Email string `yaml:"email"`
+	// Agree to the terms of service of the CA, required to create the account
+	AcceptTOS bool `yaml:"acceptTOS" default:"false"`
+	// Directory URL of the CA
+	Directory string `yaml:"directory" default:"https://acme-v02.api.letsencrypt.org/directory"`
+	// Directory to store the account key and the certificate in
+	Storage   string        `yaml:"storage" default:"acme"`
+	Challenge ACMEChallenge `yaml:"challenge" default:"http-01"`
+	// Time before the expiry of the certificate it is renewed
+	RenewBefore Duration  `yaml:"renewBefore" default:"720h"`
+	DNS01       ACMEDNS01 `yaml:"dns01"`
+}
+
+// ACMEDNS01 configures how the TXT records of the dns-01 challenge are published
+type ACMEDNS01 struct {
+	Provider ACMEDNSProvider `yaml:"provider" default:"rfc2136"`
+
+	// rfc2136: server accepting the updates and the TSIG key to sign them
+	Nameserver string `yaml:"nameserver"`
+	// rfc2136: zone of the records, found via SOA query to the nameserver if empty
+	Zone          string `yaml:"zone"`
+	TSIGKey       string `yaml:"tsigKey"`
+	TSIGSecret    string `yaml:"tsigSecret"`
+	TSIGAlgorithm string `yaml:"tsigAlgorithm" default:"hmac-sha256."`
+
+	// exec: called with the arguments `present|cleanup <fqdn> <value>`
+	Command string `yaml:"command"`
+
+	// Time to wait after publishing the record, before the CA checks it
+	PropagationDelay Duration `yaml:"propagationDelay" default:"30s"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *ACME) IsEnabled() bool {
+	return len(c.Domains) > 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ACME) LogConfig(logger *logrus.Entry) {
+	logger.Infof("domains     = %s", strings.Join(c.Domains, ", "))
+	logger.Infof("directory   = %s", c.Directory)
+	logger.Infof("storage     = %s", c.Storage)
+	logger.Infof("challenge   = %s", c.Challenge)
+	logger.Infof("renewBefore = %s", c.RenewBefore)
+
+	if c.Challenge != ACMEChallengeDns01 {
+		return
+	}
+
+	logger.Infof("dns01:")
+	logger.Infof("  provider         = %s", c.DNS01.Provider)
+
+	switch c.DNS01.Provider {
+	case ACMEDNSProviderRfc2136:
+		logger.Infof("  nameserver       = %s", c.DNS01.Nameserver)
+		logger.Infof("  tsigKey          = %s", c.DNS01.TSIGKey)
+	case ACMEDNSProviderExec:
+		logger.Infof("  command          = %s", c.DNS01.Command)
+	}
+
+	logger.Infof("  propagationDelay = %s", c.DNS01.PropagationDelay)
+}
+
+func (c *ACME) validate(logger *logrus.Entry, cfg *Config) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	domains := make([]string, 0, len(c.Domains))
+
+	for _, domain := range c.Domains {
+		domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+		if domain == "" {
+			continue
+		}
+
+		if strings.HasPrefix(domain, "*.") && c.Challenge != ACMEChallengeDns01 {
+			logger.Warnf("acme.domains: wildcard domain %s requires the dns-01 challenge", domain)
+		}
+
+		domains = append(domains, domain)
+	}
+
+	c.Domains = domains
+
+	defaults := mustDefault[ACME]()
+
+	if !c.RenewBefore.IsAboveZero() {
+		logger.Warnf("acme.renewBefore <= 0, setting to %s", defaults.RenewBefore)
+		c.RenewBefore = defaults.RenewBefore
+	}
+
+	if !c.AcceptTOS {
+		logger.Warn("acme.acceptTOS must be true to create the account")
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		logger.Warn("certFile and keyFile are ignored, the certificate is managed via ACME")
+	}
+
+	switch c.Challenge {
+	case ACMEChallengeHttp01:
+		if len(cfg.Ports.HTTP) == 0 {
+			logger.Warn("acme: the http-01 challenge requires ports.http, reachable on port 80")
+		}
+
+	case ACMEChallengeDns01:
+		if c.DNS01.Provider == ACMEDNSProviderRfc2136 && c.DNS01.Nameserver == "" {
+			logger.Warn("acme.dns01.nameserver is required for the rfc2136 provider")
+		}
+
+		if c.DNS01.Provider == ACMEDNSProviderExec && c.DNS01.Command == "" {
+			logger.Warn("acme.dns01.command is required for the exec provider")
+		}
+	}
+}
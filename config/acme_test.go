@@ -0,0 +1,129 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACMEConfig", func() {
+	var (
+		cfg     ACME
+		rootCfg Config
+	)
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[ACME]()
+		Expect(err).Should(Succeed())
+
+		cfg.Domains = []string{"dns.example.com"}
+		cfg.AcceptTOS = true
+
+		rootCfg, err = WithDefaults[Config]()
+		Expect(err).Should(Succeed())
+
+		rootCfg.Ports.HTTP = ListenConfig{"80"}
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			cfg, err := WithDefaults[ACME]()
+			Expect(err).Should(Succeed())
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		It("should be true with domains", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"domains     = dns.example.com",
+				"directory   = https://acme-v02.api.letsencrypt.org/directory",
+				"challenge   = http-01",
+			))
+			Expect(hook.Messages).ShouldNot(ContainElement("dns01:"))
+		})
+
+		It("should log the dns-01 provider", func() {
+			cfg.Challenge = ACMEChallengeDns01
+			cfg.DNS01.Nameserver = "192.168.178.1"
+			cfg.DNS01.TSIGKey = "acme-key"
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"dns01:",
+				"  provider         = rfc2136",
+				"  nameserver       = 192.168.178.1",
+				"  tsigKey          = acme-key",
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should accept a valid configuration", func() {
+			cfg.validate(logger, &rootCfg)
+
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+
+		It("should normalize the domains and fix invalid values", func() {
+			cfg.Domains = []string{" DNS.Example.com. ", ""}
+			cfg.RenewBefore = Duration(-time.Hour)
+
+			cfg.validate(logger, &rootCfg)
+
+			Expect(cfg.Domains).Should(Equal([]string{"dns.example.com"}))
+			Expect(cfg.RenewBefore).Should(Equal(Duration(720 * time.Hour)))
+			Expect(hook.Calls).Should(HaveLen(1))
+		})
+
+		It("should warn about configurations which can't work", func() {
+			cfg.Domains = []string{"*.example.com"}
+			cfg.AcceptTOS = false
+			rootCfg.CertFile = "cert.pem"
+			rootCfg.Ports.HTTP = nil
+
+			cfg.validate(logger, &rootCfg)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("requires the dns-01 challenge"),
+				ContainSubstring("acceptTOS must be true"),
+				ContainSubstring("certFile and keyFile are ignored"),
+				ContainSubstring("requires ports.http"),
+			))
+		})
+
+		It("should warn about missing provider settings", func() {
+			cfg.Challenge = ACMEChallengeDns01
+
+			cfg.validate(logger, &rootCfg)
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("nameserver is required")))
+
+			cfg.DNS01.Provider = ACMEDNSProviderExec
+
+			cfg.validate(logger, &rootCfg)
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("command is required")))
+		})
+
+		It("should do nothing if disabled", func() {
+			cfg.Domains = nil
+			cfg.AcceptTOS = false
+
+			cfg.validate(logger, &rootCfg)
+
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/sirupsen/logrus"
+)
+
+// API configures protections for the HTTP(S) listeners serving the REST API and DoH
+type API struct {
+	RateLimit RateLimit `yaml:"rateLimit"`
+	// TrustedProxies are the reverse proxies whose `X-Forwarded-For` header is used to rate limit the clients
+	TrustedProxies        []string `yaml:"trustedProxies"`
+	MaxBodySize           int64    `yaml:"maxBodySize" default:"0"`
+	MaxConcurrentRequests uint     `yaml:"maxConcurrentRequests" default:"0"`
+	Auth                  APIAuth  `yaml:"auth"`
+}
+
+// RateLimit configures a token bucket: `rate` requests per second with bursts of up to `burst` requests
+type RateLimit struct {
+	Rate  uint `yaml:"rate" default:"0"`
+	Burst uint `yaml:"burst" default:"0"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *RateLimit) IsEnabled() bool {
+	return c.Rate > 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *RateLimit) LogConfig(logger *logrus.Entry) {
+	logger.Infof("rate  = %d/s", c.Rate)
+	logger.Infof("burst = %d", c.EffectiveBurst())
+}
+
+// EffectiveBurst returns the configured burst, but at least the rate
+func (c *RateLimit) EffectiveBurst() uint {
+	if c.Burst < c.Rate {
+		return c.Rate
+	}
+
+	return c.Burst
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *API) IsEnabled() bool {
+	return c.RateLimit.IsEnabled() || c.MaxBodySize > 0 || c.MaxConcurrentRequests > 0 || c.Auth.IsEnabled()
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *API) LogConfig(logger *logrus.Entry) {
+	if c.RateLimit.IsEnabled() {
+		logger.Info("rateLimit (per client):")
+		log.WithIndent(logger, "  ", c.RateLimit.LogConfig)
+	} else {
+		logger.Debug("rateLimit: disabled")
+	}
+
+	if len(c.TrustedProxies) != 0 {
+		logger.Infof("trustedProxies = %s", strings.Join(c.TrustedProxies, ", "))
+	}
+
+	if c.MaxBodySize > 0 {
+		logger.Infof("maxBodySize = %d bytes", c.MaxBodySize)
+	}
+
+	if c.MaxConcurrentRequests > 0 {
+		logger.Infof("maxConcurrentRequests = %d", c.MaxConcurrentRequests)
+	}
+
+	if c.Auth.IsEnabled() {
+		logger.Info("auth:")
+		log.WithIndent(logger, "  ", c.Auth.LogConfig)
+	} else {
+		logger.Debug("auth: disabled")
+	}
+}
+
+func (c *API) validate(logger *logrus.Entry) {
+	trusted := c.TrustedProxies[:0]
+
+	for _, value := range c.TrustedProxies {
+		if _, err := ParseClientPrefix(value); err != nil {
+			logger.Warnf("api.trustedProxies: ignoring %s", err)
+
+			continue
+		}
+
+		trusted = append(trusted, value)
+	}
+
+	c.TrustedProxies = trusted
+
+	c.Auth.validate(logger)
+}
+
+// TrustedPrefixes returns the trusted proxies, invalid values are ignored
+func (c *API) TrustedPrefixes() []netip.Prefix {
+	res := make([]netip.Prefix, 0, len(c.TrustedProxies))
+
+	for _, value := range c.TrustedProxies {
+		if prefix, err := ParseClientPrefix(value); err == nil {
+			res = append(res, prefix)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"slices"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// APIAuth configures the authentication of the REST API clients
+type APIAuth struct {
+	Tokens    []APIToken       `yaml:"tokens"`
+	Users     []APIUser        `yaml:"users"`
+	OIDC      OIDC             `yaml:"oidc"`
+	Endpoints APIEndpointRoles `yaml:"endpoints"`
+}
+
+// APIToken is a static bearer token, its role is readOnly if not set
+type APIToken struct {
+	Name  string  `yaml:"name"`
+	Token string  `yaml:"token"`
+	Role  APIRole `yaml:"role"`
+}
+
+// APIUser is a user for HTTP basic authentication, the password can be a bcrypt hash.
+// The role is readOnly if not set.
+type APIUser struct {
+	Username string  `yaml:"username"`
+	Password string  `yaml:"password"`
+	Role     APIRole `yaml:"role"`
+}
+
+// OIDC validates bearer tokens (JWTs) issued by an OpenID Connect provider
+type OIDC struct {
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// RoleClaim is the claim containing the role names of the user
+	RoleClaim string `yaml:"roleClaim" default:"roles"`
+	// Roles maps the values of the role claim to API roles
+	Roles map[string]APIRole `yaml:"roles"`
+}
+
+// APIEndpointRoles are the minimal roles required per endpoint class
+type APIEndpointRoles struct {
+	// Read are the endpoints returning the state, e.g. the blocking status or the cache statistics
+	Read APIRole `yaml:"read" default:"readOnly"`
+	// Query is the endpoint resolving DNS queries
+	Query APIRole `yaml:"query" default:"readOnly"`
+	// Control are the endpoints changing the state, e.g. disabling blocking, and the Go profiler
+	Control APIRole `yaml:"control" default:"admin"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *APIAuth) IsEnabled() bool {
+	return len(c.Tokens) != 0 || len(c.Users) != 0 || c.OIDC.IsEnabled()
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *APIAuth) LogConfig(logger *logrus.Entry) {
+	if len(c.Tokens) != 0 {
+		logger.Info("tokens:")
+
+		for _, token := range c.Tokens {
+			logger.Infof("  %s = %s", token.Name, token.Role)
+		}
+	}
+
+	if len(c.Users) != 0 {
+		logger.Info("users:")
+
+		for _, user := range c.Users {
+			logger.Infof("  %s = %s", user.Username, user.Role)
+		}
+	}
+
+	if c.OIDC.IsEnabled() {
+		logger.Info("oidc:")
+		log.WithIndent(logger, "  ", c.OIDC.LogConfig)
+	}
+
+	logger.Info("endpoints:")
+	logger.Infof("  read    = %s", c.Endpoints.Read)
+	logger.Infof("  query   = %s", c.Endpoints.Query)
+	logger.Infof("  control = %s", c.Endpoints.Control)
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *OIDC) IsEnabled() bool {
+	return c.Issuer != ""
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *OIDC) LogConfig(logger *logrus.Entry) {
+	logger.Infof("issuer    = %s", c.Issuer)
+	logger.Infof("audience  = %s", c.Audience)
+	logger.Infof("roleClaim = %s", c.RoleClaim)
+
+	values := maps.Keys(c.Roles)
+	slices.Sort(values)
+
+	for _, value := range values {
+		logger.Infof("  %s = %s", value, c.Roles[value])
+	}
+}
+
+func (c *APIAuth) validate(logger *logrus.Entry) {
+	tokens := make([]APIToken, 0, len(c.Tokens))
+
+	for i, token := range c.Tokens {
+		if token.Token == "" {
+			logger.Warnf("api.auth.tokens[%d]: ignoring token without value", i)
+
+			continue
+		}
+
+		if token.Name == "" {
+			token.Name = "unnamed"
+		}
+
+		if token.Role == APIRoleNone {
+			token.Role = APIRoleReadOnly
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	c.Tokens = tokens
+
+	users := make([]APIUser, 0, len(c.Users))
+
+	for i, user := range c.Users {
+		if user.Username == "" || user.Password == "" {
+			logger.Warnf("api.auth.users[%d]: ignoring user without name or password", i)
+
+			continue
+		}
+
+		if user.Role == APIRoleNone {
+			user.Role = APIRoleReadOnly
+		}
+
+		users = append(users, user)
+	}
+
+	c.Users = users
+
+	if c.OIDC.IsEnabled() && c.OIDC.Audience == "" {
+		logger.Warn("api.auth.oidc: no audience configured, tokens of all clients of the issuer are accepted")
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"github.com/creasty/defaults"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("APIConfig", func() {
+	var cfg API
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		cfg = API{
+			RateLimit:             RateLimit{Rate: 10, Burst: 20},
+			MaxBodySize:           1024,
+			MaxConcurrentRequests: 5,
+		}
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			cfg := API{}
+			Expect(defaults.Set(&cfg)).Should(Succeed())
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("any limit is set", func() {
+			It("should be true", func() {
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+
+				Expect((&API{MaxBodySize: 1}).IsEnabled()).Should(BeTrue())
+				Expect((&API{MaxConcurrentRequests: 1}).IsEnabled()).Should(BeTrue())
+				Expect((&API{RateLimit: RateLimit{Rate: 1}}).IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("rate  = 10/s"),
+				ContainSubstring("burst = 20"),
+				ContainSubstring("maxBodySize = 1024"),
+				ContainSubstring("maxConcurrentRequests = 5"),
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should ignore invalid trusted proxies", func() {
+			cfg.TrustedProxies = []string{"10.0.0.0/8", "proxy", "192.168.178.1"}
+
+			cfg.validate(logger)
+
+			Expect(cfg.TrustedProxies).Should(Equal([]string{"10.0.0.0/8", "192.168.178.1"}))
+			Expect(cfg.TrustedPrefixes()).Should(HaveLen(2))
+			Expect(hook.Messages).Should(ConsistOf(ContainSubstring("api.trustedProxies: ignoring")))
+		})
+	})
+
+	Describe("Auth", func() {
+		var auth APIAuth
+
+		BeforeEach(func() {
+			var err error
+
+			auth, err = WithDefaults[APIAuth]()
+			Expect(err).Should(Succeed())
+
+			auth.Tokens = []APIToken{{Name: "ci", Token: "secret-token", Role: APIRoleAdmin}}
+			auth.Users = []APIUser{{Username: "admin", Password: "secret-password"}}
+			auth.OIDC = OIDC{
+				Issuer:    "https://sso.example.com",
+				Audience:  "blocky",
+				RoleClaim: "groups",
+				Roles:     map[string]APIRole{"dns-admins": APIRoleAdmin},
+			}
+		})
+
+		It("should have defaults for the endpoint classes", func() {
+			Expect(auth.Endpoints.Read).Should(Equal(APIRoleReadOnly))
+			Expect(auth.Endpoints.Query).Should(Equal(APIRoleReadOnly))
+			Expect(auth.Endpoints.Control).Should(Equal(APIRoleAdmin))
+		})
+
+		It("should enable the API config", func() {
+			Expect((&API{Auth: auth}).IsEnabled()).Should(BeTrue())
+			Expect((&API{Auth: APIAuth{OIDC: OIDC{Issuer: "https://sso.example.com"}}}).IsEnabled()).Should(BeTrue())
+		})
+
+		It("should log the configuration without secrets", func() {
+			auth.validate(logger)
+			auth.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("ci = admin"),
+				ContainSubstring("admin = readOnly"),
+				ContainSubstring("issuer    = https://sso.example.com"),
+				ContainSubstring("dns-admins = admin"),
+				ContainSubstring("control = admin"),
+			))
+			Expect(hook.Messages).ShouldNot(ContainElement(ContainSubstring("secret")))
+		})
+
+		Describe("validate", func() {
+			It("should default the role to readOnly", func() {
+				auth.validate(logger)
+
+				Expect(auth.Users[0].Role).Should(Equal(APIRoleReadOnly))
+				Expect(auth.Tokens[0].Role).Should(Equal(APIRoleAdmin))
+			})
+
+			It("should ignore incomplete credentials", func() {
+				auth.Tokens = append(auth.Tokens, APIToken{Name: "empty"})
+				auth.Users = append(auth.Users, APIUser{Username: "nopassword"})
+
+				auth.validate(logger)
+
+				Expect(auth.Tokens).Should(HaveLen(1))
+				Expect(auth.Users).Should(HaveLen(1))
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("ignoring token without value"),
+					ContainSubstring("ignoring user without name or password"),
+				))
+			})
+
+			It("should warn if OIDC has no audience", func() {
+				auth.OIDC.Audience = ""
+
+				auth.validate(logger)
+
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("no audience configured")))
+			})
+		})
+	})
+
+	Describe("RateLimit", func() {
+		It("should use the rate as minimum burst", func() {
+			Expect((&RateLimit{Rate: 10, Burst: 2}).EffectiveBurst()).Should(BeEquivalentTo(10))
+			Expect((&RateLimit{Rate: 10, Burst: 20}).EffectiveBurst()).Should(BeEquivalentTo(20))
+		})
+	})
+})
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"strings"
+
 	. "github.com/0xERR0R/blocky/config/migration"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/sirupsen/logrus"
@@ -8,12 +10,20 @@ import (
 
 // Blocking configuration for query blocking
 type Blocking struct {
-	Denylists         map[string][]BytesSource `yaml:"denylists"`
-	Allowlists        map[string][]BytesSource `yaml:"allowlists"`
-	ClientGroupsBlock map[string][]string      `yaml:"clientGroupsBlock"`
-	BlockType         string                   `yaml:"blockType" default:"ZEROIP"`
-	BlockTTL          Duration                 `yaml:"blockTTL" default:"6h"`
-	Loading           SourceLoading            `yaml:"loading"`
+	Denylists         map[string][]BytesSource    `yaml:"denylists"`
+	Allowlists        map[string][]BytesSource    `yaml:"allowlists"`
+	ClientGroupsBlock map[string][]string         `yaml:"clientGroupsBlock"`
+	BlockType         string                      `yaml:"blockType" default:"ZEROIP"`
+	BlockTypes        map[string]string           `yaml:"blockTypes"`
+	ClientBlockTypes  map[string]string           `yaml:"clientBlockTypes"`
+	BlockTTL          Duration                    `yaml:"blockTTL" default:"6h"`
+	Loading           SourceLoading               `yaml:"loading"`
+	Schedules         map[string]BlockingSchedule `yaml:"schedules"`
+	StripECH          []string                    `yaml:"stripECH"`
+	// Categories of the denylist groups, e.g. ads, tracking, malware or adult
+	Categories map[string]string `yaml:"categories"`
+	// Feeds are fast-refreshing denylist groups, loaded separately from the denylists
+	Feeds map[string]BlockingFeed `yaml:"feeds"`
 
 	// Deprecated options
 	Deprecated struct {
@@ -64,13 +74,51 @@ func (c *Blocking) LogConfig(logger *logrus.Entry) {
 
 	logger.Infof("blockType = %s", c.BlockType)
 
+	if len(c.BlockTypes) != 0 {
+		logger.Info("blockTypes:")
+
+		for group, blockType := range c.BlockTypes {
+			logger.Infof("  %s = %s", group, blockType)
+		}
+	}
+
+	if len(c.ClientBlockTypes) != 0 {
+		logger.Info("clientBlockTypes:")
+
+		for client, blockType := range c.ClientBlockTypes {
+			logger.Infof("  %s = %s", client, blockType)
+		}
+	}
+
 	if c.BlockType != "NXDOMAIN" {
 		logger.Infof("blockTTL = %s", c.BlockTTL)
 	}
 
+	if len(c.StripECH) != 0 {
+		logger.Infof("stripECH = %v", c.StripECH)
+	}
+
+	if len(c.Categories) != 0 {
+		logger.Info("categories:")
+
+		for group, category := range c.Categories {
+			logger.Infof("  %s = %s", group, category)
+		}
+	}
+
 	logger.Info("loading:")
 	log.WithIndent(logger, "  ", c.Loading.LogConfig)
 
+	if len(c.Schedules) != 0 {
+		logger.Info("schedules:")
+		log.WithIndent(logger, "  ", func(logger *logrus.Entry) {
+			for group, schedule := range c.Schedules {
+				logger.Infof("%s:", group)
+				log.WithIndent(logger, "  ", schedule.LogConfig)
+			}
+		})
+	}
+
 	logger.Info("denylists:")
 	log.WithIndent(logger, "  ", func(logger *logrus.Entry) {
 		c.logListGroups(logger, c.Denylists)
@@ -80,6 +128,102 @@ func (c *Blocking) LogConfig(logger *logrus.Entry) {
 	log.WithIndent(logger, "  ", func(logger *logrus.Entry) {
 		c.logListGroups(logger, c.Allowlists)
 	})
+
+	if len(c.Feeds) != 0 {
+		logger.Info("feeds:")
+		log.WithIndent(logger, "  ", func(logger *logrus.Entry) {
+			for name, feed := range c.Feeds {
+				logger.Infof("%s:", name)
+				log.WithIndent(logger, "  ", feed.LogConfig)
+			}
+		})
+	}
+}
+
+// IsDenylistGroup returns true if the group is a denylist or a feed
+func (c *Blocking) IsDenylistGroup(group string) bool {
+	_, isDenylist := c.Denylists[group]
+	_, isFeed := c.Feeds[group]
+
+	return isDenylist || isFeed
+}
+
+func (c *Blocking) validate(logger *logrus.Entry) {
+	for name, feed := range c.Feeds {
+		if _, isDenylist := c.Denylists[name]; isDenylist {
+			logger.Warnf("blocking.feeds: feed '%s' is also defined in denylists, ignoring the feed", name)
+			delete(c.Feeds, name)
+
+			continue
+		}
+
+		if !feed.IsEnabled() {
+			logger.Warnf("blocking.feeds: feed '%s' has no sources, ignoring it", name)
+			delete(c.Feeds, name)
+
+			continue
+		}
+
+		feed.validate(logger, name)
+		c.Feeds[name] = feed
+	}
+
+	for group := range c.BlockTypes {
+		if !c.IsDenylistGroup(group) {
+			logger.Warnf("blocking.blockTypes: group '%s' is not defined in denylists or feeds", group)
+		}
+	}
+
+	for group, category := range c.Categories {
+		if !c.IsDenylistGroup(group) {
+			logger.Warnf("blocking.categories: group '%s' is not defined in denylists or feeds", group)
+		}
+
+		if category == "" {
+			logger.Warnf("blocking.categories: group '%s' has an empty category, ignoring it", group)
+			delete(c.Categories, group)
+		}
+	}
+
+	clients := c.clientIdentifiers()
+
+	for key := range c.ClientBlockTypes {
+		for _, client := range splitClientIdentifiers(key) {
+			if _, isClientGroup := clients[client]; !isClientGroup {
+				logger.Warnf("blocking.clientBlockTypes: client '%s' is not defined in clientGroupsBlock", client)
+			}
+		}
+	}
+
+	for group, schedule := range c.Schedules {
+		_, isAllowlist := c.Allowlists[group]
+
+		if !c.IsDenylistGroup(group) && !isAllowlist {
+			logger.Warnf("blocking.schedules: group '%s' is not defined in denylists, feeds or allowlists", group)
+		}
+
+		if len(schedule.Windows) == 0 {
+			logger.Warnf("blocking.schedules: group '%s' has no windows and will never be blocked", group)
+		}
+	}
+}
+
+// clientIdentifiers returns the client identifiers of clientGroupsBlock
+func (c *Blocking) clientIdentifiers() map[string]struct{} {
+	res := make(map[string]struct{}, len(c.ClientGroupsBlock))
+
+	for key := range c.ClientGroupsBlock {
+		for _, client := range splitClientIdentifiers(key) {
+			res[client] = struct{}{}
+		}
+	}
+
+	return res
+}
+
+// splitClientIdentifiers returns the lowercased identifiers of a comma separated key of clientGroupsBlock
+func splitClientIdentifiers(key string) []string {
+	return strings.Split(strings.ToLower(key), ",")
 }
 
 func (c *Blocking) logListGroups(logger *logrus.Entry, listGroups map[string][]BytesSource) {
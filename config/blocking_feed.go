@@ -0,0 +1,69 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// BlockingFeed configuration of a high-churn threat-intel feed, like newly registered or phishing domains.
+// Feeds are loaded separately from the denylists and refreshed much more often.
+type BlockingFeed struct {
+	Sources       []BytesSource `yaml:"sources"`
+	RefreshPeriod Duration      `yaml:"refreshPeriod" default:"5m"`
+	// TTL of the blocked answers, short so clients query again once a domain left the feed
+	BlockTTL Duration `yaml:"blockTTL" default:"1m"`
+	// MaxAge after the last successful refresh, before the feed is reported as stale
+	MaxAge Duration `yaml:"maxAge" default:"30m"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *BlockingFeed) IsEnabled() bool {
+	return len(c.Sources) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *BlockingFeed) LogConfig(logger *logrus.Entry) {
+	logger.Infof("refreshPeriod = %s", c.RefreshPeriod)
+	logger.Infof("blockTTL = %s", c.BlockTTL)
+	logger.Infof("maxAge = %s", c.MaxAge)
+	logger.Info("sources:")
+
+	for _, source := range c.Sources {
+		logger.Infof("  - %s", source)
+	}
+}
+
+// Loading returns the loading configuration of the feed, based on the one of the lists
+func (c *BlockingFeed) Loading(lists SourceLoading) SourceLoading {
+	lists.RefreshPeriod = c.RefreshPeriod
+	// a feed which can't be loaded must not delay the start
+	lists.Strategy = InitStrategyFast
+
+	return lists
+}
+
+// validate applies the defaults to the unset durations, the values of a map don't get them when loaded
+func (c *BlockingFeed) validate(logger *logrus.Entry, name string) {
+	def := mustDefault[BlockingFeed]()
+
+	if c.RefreshPeriod < 0 {
+		logger.Warnf("blocking.feeds.%s.refreshPeriod < 0, setting to %s", name, def.RefreshPeriod)
+	}
+
+	if c.RefreshPeriod <= 0 {
+		c.RefreshPeriod = def.RefreshPeriod
+	}
+
+	if c.BlockTTL <= 0 {
+		c.BlockTTL = def.BlockTTL
+	}
+
+	if c.MaxAge == 0 {
+		c.MaxAge = max(def.MaxAge, 3*c.RefreshPeriod)
+	}
+
+	if c.MaxAge < c.RefreshPeriod {
+		logger.Warnf("blocking.feeds.%s.maxAge < refreshPeriod, setting to %s", name, 3*c.RefreshPeriod)
+
+		c.MaxAge = 3 * c.RefreshPeriod
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BlockingFeedConfig", func() {
+	var cfg BlockingFeed
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[BlockingFeed]()
+		Expect(err).Should(Succeed())
+
+		cfg.Sources = NewBytesSources("https://example.com/nrd.txt")
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false without sources", func() {
+			cfg.Sources = nil
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		It("should be true with sources", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"refreshPeriod = 5 minutes",
+				"blockTTL = 1 minute",
+				"maxAge = 30 minutes",
+				"  - https://example.com/nrd.txt",
+			))
+		})
+	})
+
+	Describe("Loading", func() {
+		It("should use the refresh period of the feed and not delay the start", func() {
+			lists, err := WithDefaults[SourceLoading]()
+			Expect(err).Should(Succeed())
+
+			loading := cfg.Loading(lists)
+
+			Expect(loading.RefreshPeriod).Should(Equal(cfg.RefreshPeriod))
+			Expect(loading.Strategy).Should(Equal(InitStrategyFast))
+			Expect(loading.Downloads).Should(Equal(lists.Downloads))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should apply the defaults to unset values", func() {
+			cfg = BlockingFeed{RefreshPeriod: Duration(20 * time.Minute)}
+
+			cfg.validate(logger, "nrd")
+
+			Expect(hook.Calls).Should(BeEmpty())
+			Expect(cfg.BlockTTL).Should(Equal(Duration(time.Minute)))
+			Expect(cfg.MaxAge).Should(Equal(Duration(time.Hour)))
+		})
+
+		It("should fix invalid values", func() {
+			cfg.RefreshPeriod = -1
+			cfg.MaxAge = Duration(time.Minute)
+
+			cfg.validate(logger, "nrd")
+
+			Expect(cfg.RefreshPeriod).Should(Equal(Duration(5 * time.Minute)))
+			Expect(cfg.MaxAge).Should(Equal(Duration(15 * time.Minute)))
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("blocking.feeds.nrd.refreshPeriod < 0"),
+				ContainSubstring("blocking.feeds.nrd.maxAge < refreshPeriod"),
+			))
+		})
+	})
+})
@@ -59,6 +59,103 @@ var _ = Describe("BlockingConfig", func() {
 			Expect(hook.Messages[0]).Should(Equal("clientGroupsBlock:"))
 			Expect(hook.Messages).Should(ContainElement(Equal("blockType = ZEROIP")))
 		})
+
+		It("should log domains with stripped ech", func() {
+			cfg.StripECH = []string{"example.com"}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElement(Equal("stripECH = [example.com]")))
+		})
+
+		It("should log the block types of groups and clients", func() {
+			cfg.BlockTypes = map[string]string{"gr1": "NXDOMAIN"}
+			cfg.ClientBlockTypes = map[string]string{"default": "192.168.178.2"}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"blockTypes:", "  gr1 = NXDOMAIN", "clientBlockTypes:", "  default = 192.168.178.2"))
+		})
+
+		It("should log the categories of groups", func() {
+			cfg.Categories = map[string]string{"gr1": "ads"}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements("categories:", "  gr1 = ads"))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should warn about schedules of unknown groups", func() {
+			cfg.Schedules = map[string]BlockingSchedule{
+				"unknown": {Windows: []ScheduleWindow{{}}},
+				"gr1":     {},
+			}
+
+			cfg.validate(logger)
+
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("group 'unknown' is not defined"),
+				ContainSubstring("group 'gr1' has no windows"),
+			))
+		})
+
+		It("should warn about block types of unknown groups and clients", func() {
+			cfg.BlockTypes = map[string]string{"unknown": "NXDOMAIN", "gr1": "NXDOMAIN"}
+			cfg.ClientBlockTypes = map[string]string{"laptop*": "NODATA", "default": "REFUSED"}
+
+			cfg.validate(logger)
+
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("group 'unknown' is not defined in denylists"),
+				ContainSubstring("client 'laptop*' is not defined in clientGroupsBlock"),
+			))
+		})
+
+		It("should match the groups with their case and the clients like clientGroupsBlock", func() {
+			cfg.Denylists["socialMedia"] = NewBytesSources("/a/file/path")
+			cfg.ClientGroupsBlock["Laptop,tablet"] = []string{"socialMedia"}
+			cfg.BlockTypes = map[string]string{"socialMedia": "NXDOMAIN", "socialmedia": "NXDOMAIN"}
+			cfg.ClientBlockTypes = map[string]string{"laptop": "NODATA", "TABLET,default": "REFUSED"}
+
+			cfg.validate(logger)
+
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("group 'socialmedia' is not defined in denylists"),
+			))
+		})
+
+		It("should warn about categories of unknown groups and ignore empty ones", func() {
+			cfg.Categories = map[string]string{"unknown": "ads", "gr1": ""}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Categories).Should(Equal(map[string]string{"unknown": "ads"}))
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("group 'unknown' is not defined in denylists"),
+				ContainSubstring("group 'gr1' has an empty category"),
+			))
+		})
+
+		It("should validate the feeds", func() {
+			cfg.Feeds = map[string]BlockingFeed{
+				"nrd":   {Sources: NewBytesSources("https://example.com/nrd.txt")},
+				"gr1":   {Sources: NewBytesSources("https://example.com/gr1.txt")},
+				"empty": {},
+			}
+			cfg.BlockTypes = map[string]string{"nrd": "NXDOMAIN"}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Feeds).Should(HaveLen(1))
+			Expect(cfg.Feeds["nrd"].RefreshPeriod).Should(Equal(Duration(5 * time.Minute)))
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("feed 'gr1' is also defined in denylists"),
+				ContainSubstring("feed 'empty' has no sources"),
+			))
+		})
 	})
 
 	Describe("migrate", func() {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -8,14 +9,42 @@ import (
 
 // Caching configuration for domain caching
 type Caching struct {
-	MinCachingTime        Duration `yaml:"minTime"`
-	MaxCachingTime        Duration `yaml:"maxTime"`
-	CacheTimeNegative     Duration `yaml:"cacheTimeNegative" default:"30m"`
-	MaxItemsCount         int      `yaml:"maxItemsCount"`
-	Prefetching           bool     `yaml:"prefetching"`
-	PrefetchExpires       Duration `yaml:"prefetchExpires" default:"2h"`
-	PrefetchThreshold     int      `yaml:"prefetchThreshold" default:"5"`
-	PrefetchMaxItemsCount int      `yaml:"prefetchMaxItemsCount"`
+	MinCachingTime        Duration            `yaml:"minTime"`
+	MaxCachingTime        Duration            `yaml:"maxTime"`
+	CacheTimeNegative     Duration            `yaml:"cacheTimeNegative" default:"30m"`
+	NXDomain              NegativeCaching     `yaml:"nxdomain"`
+	NoData                NegativeCaching     `yaml:"nodata"`
+	ServFailTime          Duration            `yaml:"servfailTime"`
+	MaxItemsCount         int                 `yaml:"maxItemsCount"`
+	MaxMemory             int64               `yaml:"maxMemory"`
+	EvictionPolicy        CacheEvictionPolicy `yaml:"evictionPolicy" default:"lru"`
+	Prefetching           bool                `yaml:"prefetching"`
+	PrefetchExpires       Duration            `yaml:"prefetchExpires" default:"2h"`
+	PrefetchThreshold     int                 `yaml:"prefetchThreshold" default:"5"`
+	PrefetchMaxItemsCount int                 `yaml:"prefetchMaxItemsCount"`
+	PrefetchMaxConcurrent int                 `yaml:"prefetchMaxConcurrent" default:"1"`
+	PrefetchMinTTL        Duration            `yaml:"prefetchMinTTL"`
+	PrefetchExclude       []string            `yaml:"prefetchExclude"`
+	PrefetchDomains       []PrefetchDomain    `yaml:"prefetchDomains"`
+	CompressIdleAfter     Duration            `yaml:"compressIdleAfter"`
+	TTLRewrite            TTLRewrite          `yaml:"ttlRewrite"`
+}
+
+// NegativeCaching limits how long a kind of negative response is cached,
+// 0 means no lower limit and `cacheTimeNegative` as upper limit
+type NegativeCaching struct {
+	MinTime Duration `yaml:"minTime"`
+	MaxTime Duration `yaml:"maxTime"`
+}
+
+// maxServFailTime is the longest time a SERVFAIL response may be cached (RFC 2308 section 7.1)
+const maxServFailTime = Duration(5 * time.Minute)
+
+// PrefetchDomain overrides the prefetch settings for the domains and their subdomains, unset values use the global ones
+type PrefetchDomain struct {
+	Domains   []string  `yaml:"domains"`
+	Threshold *int      `yaml:"threshold"`
+	MinTTL    *Duration `yaml:"minTTL"`
 }
 
 // IsEnabled implements `config.Configurable`.
@@ -29,14 +58,140 @@ func (c *Caching) LogConfig(logger *logrus.Entry) {
 	logger.Infof("maxTime = %s", c.MaxCachingTime)
 	logger.Infof("cacheTimeNegative = %s", c.CacheTimeNegative)
 
+	c.NXDomain.logConfig(logger, "nxdomain")
+	c.NoData.logConfig(logger, "nodata")
+
+	if c.ServFailTime.IsAboveZero() {
+		logger.Infof("servfailTime = %s", c.ServFailTime)
+	}
+
+	if c.MaxItemsCount > 0 {
+		logger.Infof("maxItemsCount = %d", c.MaxItemsCount)
+	}
+
+	if c.MaxMemory > 0 {
+		logger.Infof("maxMemory = %d bytes", c.MaxMemory)
+	}
+
+	logger.Infof("evictionPolicy = %s", c.EvictionPolicy)
+
 	if c.Prefetching {
 		logger.Infof("prefetching:")
-		logger.Infof("  expires   = %s", c.PrefetchExpires)
-		logger.Infof("  threshold = %d", c.PrefetchThreshold)
-		logger.Infof("  maxItems  = %d", c.PrefetchMaxItemsCount)
+		logger.Infof("  expires       = %s", c.PrefetchExpires)
+		logger.Infof("  threshold     = %d", c.PrefetchThreshold)
+		logger.Infof("  maxItems      = %d", c.PrefetchMaxItemsCount)
+		logger.Infof("  maxConcurrent = %d", c.PrefetchMaxConcurrent)
+
+		if c.PrefetchMinTTL.IsAboveZero() {
+			logger.Infof("  minTTL        = %s", c.PrefetchMinTTL)
+		}
+
+		if len(c.PrefetchExclude) != 0 {
+			logger.Infof("  exclude       = %s", strings.Join(c.PrefetchExclude, ", "))
+		}
+
+		for _, domain := range c.PrefetchDomains {
+			domain.logConfig(logger)
+		}
 	} else {
 		logger.Debug("prefetching: disabled")
 	}
+
+	if c.CompressIdleAfter.IsAboveZero() {
+		logger.Infof("compressIdleAfter = %s", c.CompressIdleAfter)
+	}
+}
+
+func (c *NegativeCaching) logConfig(logger *logrus.Entry, name string) {
+	if c.MinTime.IsAboveZero() {
+		logger.Infof("%s.minTime = %s", name, c.MinTime)
+	}
+
+	if c.MaxTime.IsAboveZero() {
+		logger.Infof("%s.maxTime = %s", name, c.MaxTime)
+	}
+}
+
+func (c *PrefetchDomain) logConfig(logger *logrus.Entry) {
+	logger.Infof("  %s:", strings.Join(c.Domains, ", "))
+
+	if c.Threshold != nil {
+		logger.Infof("    threshold = %d", *c.Threshold)
+	}
+
+	if c.MinTTL != nil {
+		logger.Infof("    minTTL    = %s", *c.MinTTL)
+	}
+}
+
+func (c *Caching) validate(logger *logrus.Entry) {
+	if c.PrefetchMaxConcurrent < 1 {
+		logger.Warnf("caching.prefetchMaxConcurrent %d is less than 1, setting to 1", c.PrefetchMaxConcurrent)
+
+		c.PrefetchMaxConcurrent = 1
+	}
+
+	if c.ServFailTime > maxServFailTime {
+		logger.Warnf("caching.servfailTime %s is longer than allowed by RFC 2308, setting to %s",
+			c.ServFailTime, maxServFailTime)
+
+		c.ServFailTime = maxServFailTime
+	}
+
+	c.NXDomain.validate(logger, "nxdomain")
+	c.NoData.validate(logger, "nodata")
+	c.TTLRewrite.validate(logger)
+
+	if c.MaxMemory < 0 {
+		logger.Warnf("caching.maxMemory %d is negative, disabling the memory limit", c.MaxMemory)
+
+		c.MaxMemory = 0
+	}
+
+	c.PrefetchExclude = normalizeDomains(c.PrefetchExclude)
+
+	domains := make([]PrefetchDomain, 0, len(c.PrefetchDomains))
+
+	for _, domain := range c.PrefetchDomains {
+		domain.Domains = normalizeDomains(domain.Domains)
+
+		if len(domain.Domains) == 0 {
+			logger.Warn("caching.prefetchDomains contains an entry without domains, ignoring it")
+
+			continue
+		}
+
+		domains = append(domains, domain)
+	}
+
+	c.PrefetchDomains = domains
+}
+
+func (c *NegativeCaching) validate(logger *logrus.Entry, name string) {
+	if c.MaxTime.IsAboveZero() && c.MinTime > c.MaxTime {
+		logger.Warnf("caching.%s.minTime %s is greater than maxTime %s, setting to %s",
+			name, c.MinTime, c.MaxTime, c.MaxTime)
+
+		c.MinTime = c.MaxTime
+	}
+}
+
+// normalizeDomains returns the lower case domains without trailing dot, empty entries are removed
+func normalizeDomains(domains []string) []string {
+	result := make([]string, 0, len(domains))
+
+	for _, domain := range domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			result = append(result, domain)
+		}
+	}
+
+	return result
+}
+
+// normalizeDomain returns the lower case domain without trailing dot
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
 }
 
 func (c *Caching) EnablePrefetch() {
@@ -49,4 +204,7 @@ func (c *Caching) EnablePrefetch() {
 
 	c.Prefetching = true
 	c.PrefetchThreshold = 0
+	c.PrefetchMinTTL = 0
+	c.PrefetchExclude = nil
+	c.PrefetchDomains = nil
 }
@@ -70,6 +70,135 @@ var _ = Describe("CachingConfig", func() {
 				Expect(hook.Messages).Should(ContainElement(ContainSubstring("prefetching:")))
 			})
 		})
+
+		When("prefetch domains are configured", func() {
+			BeforeEach(func() {
+				threshold := 2
+
+				cfg = Caching{
+					Prefetching:     true,
+					PrefetchMinTTL:  Duration(time.Minute),
+					PrefetchExclude: []string{"cdn.net"},
+					PrefetchDomains: []PrefetchDomain{{Domains: []string{"example.com"}, Threshold: &threshold}},
+				}
+			})
+
+			It("should log them", func() {
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(SatisfyAll(
+					ContainElement("  minTTL        = 1 minute"),
+					ContainElement("  exclude       = cdn.net"),
+					ContainElement("  example.com:"),
+					ContainElement("    threshold = 2"),
+				))
+			})
+		})
+
+		When("idle compression is enabled", func() {
+			BeforeEach(func() {
+				cfg = Caching{
+					CompressIdleAfter: Duration(time.Hour),
+				}
+			})
+
+			It("should log the idle time", func() {
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElement("compressIdleAfter = 1 hour"))
+			})
+		})
+
+		When("negative caching limits are set", func() {
+			BeforeEach(func() {
+				cfg = Caching{
+					NXDomain:     NegativeCaching{MaxTime: Duration(time.Minute)},
+					NoData:       NegativeCaching{MinTime: Duration(time.Hour)},
+					ServFailTime: Duration(time.Minute),
+				}
+			})
+
+			It("should log them", func() {
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(SatisfyAll(
+					ContainElement("nxdomain.maxTime = 1 minute"),
+					ContainElement("nodata.minTime = 1 hour"),
+					ContainElement("servfailTime = 1 minute"),
+				))
+			})
+		})
+
+		When("the cache size is limited", func() {
+			BeforeEach(func() {
+				cfg = Caching{
+					MaxItemsCount:  1000,
+					MaxMemory:      1 << 20,
+					EvictionPolicy: CacheEvictionPolicyTinylfu,
+				}
+			})
+
+			It("should log the limits and the eviction policy", func() {
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(SatisfyAll(
+					ContainElement("maxItemsCount = 1000"),
+					ContainElement("maxMemory = 1048576 bytes"),
+					ContainElement("evictionPolicy = tinylfu"),
+				))
+			})
+		})
+	})
+
+	Describe("validate", func() {
+		It("should normalize the domains and remove entries without domains", func() {
+			cfg.PrefetchExclude = []string{"CDN.net.", " "}
+			cfg.PrefetchDomains = []PrefetchDomain{
+				{Domains: []string{"Example.com."}},
+				{Domains: []string{""}},
+			}
+
+			cfg.validate(logger)
+
+			Expect(cfg.PrefetchExclude).Should(Equal([]string{"cdn.net"}))
+			Expect(cfg.PrefetchDomains).Should(HaveLen(1))
+			Expect(cfg.PrefetchDomains[0].Domains).Should(Equal([]string{"example.com"}))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("without domains")))
+		})
+
+		It("should use at least one concurrent prefetch", func() {
+			cfg.PrefetchMaxConcurrent = 0
+
+			cfg.validate(logger)
+
+			Expect(cfg.PrefetchMaxConcurrent).Should(Equal(1))
+		})
+
+		It("should limit the SERVFAIL caching time", func() {
+			cfg.ServFailTime = Duration(time.Hour)
+
+			cfg.validate(logger)
+
+			Expect(cfg.ServFailTime).Should(Equal(Duration(5 * time.Minute)))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("RFC 2308")))
+		})
+
+		It("should fix a negative caching min time above the max time", func() {
+			cfg.NXDomain = NegativeCaching{MinTime: Duration(time.Hour), MaxTime: Duration(time.Minute)}
+
+			cfg.validate(logger)
+
+			Expect(cfg.NXDomain.MinTime).Should(Equal(Duration(time.Minute)))
+		})
+
+		It("should disable a negative memory limit", func() {
+			cfg.MaxMemory = -1
+
+			cfg.validate(logger)
+
+			Expect(cfg.MaxMemory).Should(BeZero())
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("caching.maxMemory -1 is negative")))
+		})
 	})
 
 	Describe("EnablePrefetch", func() {
@@ -85,6 +214,18 @@ var _ = Describe("CachingConfig", func() {
 				Expect(cfg.PrefetchThreshold).Should(Equal(0))
 				Expect(cfg.MaxCachingTime).Should(BeZero())
 			})
+
+			It("should prefetch all domains", func() {
+				cfg.PrefetchMinTTL = Duration(time.Minute)
+				cfg.PrefetchExclude = []string{"cdn.net"}
+				cfg.PrefetchDomains = []PrefetchDomain{{Domains: []string{"example.com"}}}
+
+				cfg.EnablePrefetch()
+
+				Expect(cfg.PrefetchMinTTL).Should(BeZero())
+				Expect(cfg.PrefetchExclude).Should(BeEmpty())
+				Expect(cfg.PrefetchDomains).Should(BeEmpty())
+			})
 		})
 	})
 })
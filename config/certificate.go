@@ -0,0 +1,38 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Certificate configures how the certificate of the DoT and DoH listeners from certFile and keyFile is served
+type Certificate struct {
+	// CheckPeriod is the period to check certFile and keyFile for changes, 0 disables the reload
+	CheckPeriod Duration `yaml:"checkPeriod" default:"1m"`
+	// OCSPStapling fetches the OCSP response of the certificate from the CA and sends it in the TLS handshake
+	OCSPStapling bool `yaml:"ocspStapling" default:"false"`
+	// ExpiryWarning is the time before the expiry of the certificate from which a warning is logged and an event fired
+	// once a day, 0 disables the warning
+	ExpiryWarning Duration `yaml:"expiryWarning" default:"336h"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Certificate) IsEnabled() bool {
+	return c.CheckPeriod.IsAboveZero() || c.OCSPStapling
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Certificate) LogConfig(logger *logrus.Entry) {
+	if c.CheckPeriod.IsAboveZero() {
+		logger.Infof("checkPeriod   = %s", c.CheckPeriod)
+	} else {
+		logger.Info("checkPeriod   = disabled")
+	}
+
+	logger.Infof("ocspStapling  = %t", c.OCSPStapling)
+
+	if c.ExpiryWarning.IsAboveZero() {
+		logger.Infof("expiryWarning = %s", c.ExpiryWarning)
+	} else {
+		logger.Info("expiryWarning = disabled")
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CertificateConfig", func() {
+	var cfg Certificate
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[Certificate]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should check the files by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+
+		It("should be false without reload and OCSP stapling", func() {
+			cfg.CheckPeriod = 0
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"checkPeriod   = 1 minute",
+				"ocspStapling  = false",
+				"expiryWarning = 2 weeks",
+			}))
+		})
+
+		It("should log disabled reload and expiry warning", func() {
+			cfg.CheckPeriod = 0
+			cfg.OCSPStapling = true
+			cfg.ExpiryWarning = 0
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"checkPeriod   = disabled",
+				"ocspStapling  = true",
+				"expiryWarning = disabled",
+			}))
+		})
+	})
+})
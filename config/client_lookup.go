@@ -3,6 +3,7 @@ package config
 import (
 	"net"
 
+	"github.com/0xERR0R/blocky/log"
 	"github.com/sirupsen/logrus"
 )
 
@@ -11,11 +12,37 @@ type ClientLookup struct {
 	ClientnameIPMapping map[string][]net.IP `yaml:"clients"`
 	Upstream            Upstream            `yaml:"upstream"`
 	SingleNameOrder     []uint              `yaml:"singleNameOrder"`
+	Leases              DHCPLeases          `yaml:"leases"`
+	External            ExternalClientNames `yaml:"external"`
+	MACLookup           bool                `yaml:"macLookup" default:"false"`
+	Cache               ClientLookupCache   `yaml:"cache"`
+	// MaxWait is how long a query waits for the names of an unknown client, afterwards it uses the IP as name
+	// while the lookup continues in the background
+	MaxWait Duration `yaml:"maxWait" default:"100ms"`
+}
+
+// ClientLookupCache configures the caching of the resolved client names
+type ClientLookupCache struct {
+	MaxItemsCount uint `yaml:"maxItemsCount" default:"10000"`
+	// Bounds of the TTL of rDNS results, names from the mapping or DHCP leases are cached for the maximum
+	MinTime Duration `yaml:"minTime" default:"5m"`
+	MaxTime Duration `yaml:"maxTime" default:"1h"`
+	// How long failed lookups and IPs without name are cached
+	NegativeTime Duration `yaml:"negativeTime" default:"5m"`
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ClientLookupCache) LogConfig(logger *logrus.Entry) {
+	logger.Infof("maxItemsCount = %d", c.MaxItemsCount)
+	logger.Infof("minTime = %s", c.MinTime)
+	logger.Infof("maxTime = %s", c.MaxTime)
+	logger.Infof("negativeTime = %s", c.NegativeTime)
 }
 
 // IsEnabled implements `config.Configurable`.
 func (c *ClientLookup) IsEnabled() bool {
-	return !c.Upstream.IsDefault() || len(c.ClientnameIPMapping) != 0
+	return !c.Upstream.IsDefault() || len(c.ClientnameIPMapping) != 0 || c.Leases.IsEnabled() ||
+		c.External.IsEnabled() || c.MACLookup
 }
 
 // LogConfig implements `config.Configurable`.
@@ -25,6 +52,11 @@ func (c *ClientLookup) LogConfig(logger *logrus.Entry) {
 	}
 
 	logger.Infof("singleNameOrder = %v", c.SingleNameOrder)
+	logger.Infof("macLookup = %t", c.MACLookup)
+	logger.Infof("maxWait = %s", c.MaxWait)
+
+	logger.Info("cache:")
+	log.WithIndent(logger, "  ", c.Cache.LogConfig)
 
 	if len(c.ClientnameIPMapping) > 0 {
 		logger.Infof("client IP mapping:")
@@ -33,4 +65,24 @@ func (c *ClientLookup) LogConfig(logger *logrus.Entry) {
 			logger.Infof("  %s = %s", k, v)
 		}
 	}
+
+	if c.Leases.IsEnabled() {
+		logger.Info("leases:")
+		log.WithIndent(logger, "  ", c.Leases.LogConfig)
+	}
+
+	if c.External.IsEnabled() {
+		logger.Info("external:")
+		log.WithIndent(logger, "  ", c.External.LogConfig)
+	}
+}
+
+func (c *ClientLookup) validate(logger *logrus.Entry) {
+	c.Leases.validate(logger)
+	c.External.validate(logger)
+
+	if c.Cache.MaxTime < c.Cache.MinTime {
+		logger.Warnf("clientLookup.cache.maxTime < minTime, setting to %s", c.Cache.MinTime)
+		c.Cache.MaxTime = c.Cache.MinTime
+	}
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"net"
+	"time"
 
 	"github.com/creasty/defaults"
 	. "github.com/onsi/ginkgo/v2"
@@ -42,6 +43,22 @@ var _ = Describe("ClientLookupConfig", func() {
 					Expect(cfg.IsEnabled()).Should(BeTrue())
 				})
 
+				By("leases", func() {
+					cfg := ClientLookup{
+						Leases: DHCPLeases{
+							Sources: []DHCPLeaseSource{{Format: DHCPLeaseFormatDnsmasq, Source: "dnsmasq.leases"}},
+						},
+					}
+
+					Expect(cfg.IsEnabled()).Should(BeTrue())
+				})
+
+				By("MAC lookup", func() {
+					cfg := ClientLookup{MACLookup: true}
+
+					Expect(cfg.IsEnabled()).Should(BeTrue())
+				})
+
 				By("mapping", func() {
 					cfg := ClientLookup{
 						ClientnameIPMapping: map[string][]net.IP{
@@ -60,7 +77,50 @@ var _ = Describe("ClientLookupConfig", func() {
 			cfg.LogConfig(logger)
 
 			Expect(hook.Calls).ShouldNot(BeEmpty())
-			Expect(hook.Messages).Should(ContainElement(ContainSubstring("client IP mapping:")))
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("client IP mapping:"),
+				"macLookup = false",
+			))
+		})
+
+		It("should log leases", func() {
+			cfg.Leases.Sources = []DHCPLeaseSource{{Format: DHCPLeaseFormatDnsmasq, Source: "dnsmasq.leases"}}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements("leases:", ContainSubstring("- dnsmasq: dnsmasq.leases")))
+		})
+	})
+
+	Describe("Cache", func() {
+		It("should have defaults", func() {
+			cfg = ClientLookup{}
+			Expect(defaults.Set(&cfg)).Should(Succeed())
+
+			Expect(cfg.MaxWait).Should(Equal(Duration(100 * time.Millisecond)))
+			Expect(cfg.Cache).Should(Equal(ClientLookupCache{
+				MaxItemsCount: 10_000,
+				MinTime:       Duration(5 * time.Minute),
+				MaxTime:       Duration(time.Hour),
+				NegativeTime:  Duration(5 * time.Minute),
+			}))
+		})
+
+		It("should be logged", func() {
+			cfg.Cache = ClientLookupCache{MaxItemsCount: 5, MaxTime: Duration(time.Hour)}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements("cache:", "maxItemsCount = 5", "maxTime = 1 hour"))
+		})
+
+		It("should fix a maximum time lower than the minimum", func() {
+			cfg.Cache = ClientLookupCache{MinTime: Duration(time.Hour), MaxTime: Duration(time.Minute)}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Cache.MaxTime).Should(Equal(Duration(time.Hour)))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("maxTime < minTime")))
 		})
 	})
 })
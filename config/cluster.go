@@ -0,0 +1,85 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Cluster configures the synchronization with other blocky instances over their HTTP listeners
+type Cluster struct {
+	// Name of the instance in the cluster, the instance with the smallest name is the leader
+	Name string `yaml:"name"`
+	// Base URLs of the HTTP listeners of the other instances
+	Peers []string `yaml:"peers"`
+	// Shared secret to authenticate the instances
+	Secret            string   `yaml:"secret"`
+	HeartbeatInterval Duration `yaml:"heartbeatInterval" default:"5s"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Cluster) IsEnabled() bool {
+	return len(c.Peers) > 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Cluster) LogConfig(logger *logrus.Entry) {
+	logger.Info("name: ", c.Name)
+	logger.Info("secret: ", secretObfuscator)
+	logger.Info("heartbeatInterval: ", c.HeartbeatInterval)
+	logger.Info("peers:")
+
+	for _, peer := range c.Peers {
+		logger.Infof("  - %s", peer)
+	}
+}
+
+func (c *Cluster) validate(logger *logrus.Entry, redis *Redis, nats *NATS) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	if redis.IsEnabled() || nats.IsEnabled() {
+		logger.Warn("cluster and redis or nats are both configured, only redis or nats is used")
+
+		c.Peers = nil
+
+		return
+	}
+
+	if c.Secret == "" {
+		logger.Warn("cluster.secret is empty, cluster is disabled")
+
+		c.Peers = nil
+
+		return
+	}
+
+	peers := c.Peers[:0]
+
+	for _, peer := range c.Peers {
+		if u, err := url.Parse(peer); err != nil || u.Host == "" {
+			logger.Warnf("cluster: '%s' is not a valid URL, ignoring peer", peer)
+
+			continue
+		}
+
+		peers = append(peers, strings.TrimSuffix(peer, "/"))
+	}
+
+	c.Peers = peers
+
+	if c.Name == "" {
+		c.Name, _ = os.Hostname()
+	}
+
+	if !c.HeartbeatInterval.IsAboveZero() {
+		def := mustDefault[Cluster]()
+
+		logger.Warnf("cluster.heartbeatInterval is not above zero, setting to %s", def.HeartbeatInterval)
+
+		c.HeartbeatInterval = def.HeartbeatInterval
+	}
+}
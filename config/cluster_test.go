@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/creasty/defaults"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cluster", func() {
+	var (
+		c   Cluster
+		err error
+	)
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		c = Cluster{}
+		err = defaults.Set(&c)
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		When("all fields are default", func() {
+			It("should be disabled", func() {
+				Expect(c.IsEnabled()).Should(BeFalse())
+			})
+		})
+
+		When("peers are set", func() {
+			BeforeEach(func() {
+				c.Peers = []string{"http://blocky-2:4000"}
+			})
+
+			It("should be enabled", func() {
+				Expect(c.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		BeforeEach(func() {
+			logger, hook = log.NewMockEntry()
+			c.Name = "blocky-1"
+			c.Peers = []string{"http://blocky-2:4000"}
+			c.Secret = "secret-value"
+		})
+
+		It("should log the values without the secret", func() {
+			c.LogConfig(logger)
+
+			Expect(hook.Messages).Should(
+				SatisfyAll(
+					ContainElement(ContainSubstring("name: blocky-1")),
+					ContainElement(ContainSubstring("heartbeatInterval: 5 seconds")),
+					ContainElement(ContainSubstring("http://blocky-2:4000"))))
+			Expect(hook.Messages).ShouldNot(ContainElement(ContainSubstring("secret-value")))
+		})
+	})
+
+	Describe("validate", func() {
+		BeforeEach(func() {
+			logger, hook = log.NewMockEntry()
+			c.Peers = []string{"http://blocky-2:4000/", "no url"}
+			c.Secret = "secret"
+		})
+
+		It("should drop invalid peers and default the name", func() {
+			c.validate(logger, &Redis{}, &NATS{})
+
+			Expect(c.Peers).Should(Equal([]string{"http://blocky-2:4000"}))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("'no url' is not a valid URL")))
+
+			hostname, _ := os.Hostname()
+			Expect(c.Name).Should(Equal(hostname))
+		})
+
+		When("the secret is empty", func() {
+			It("should disable the cluster", func() {
+				c.Secret = ""
+				c.validate(logger, &Redis{}, &NATS{})
+
+				Expect(c.IsEnabled()).Should(BeFalse())
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("cluster.secret is empty")))
+			})
+		})
+
+		When("redis or NATS is configured too", func() {
+			It("should disable the cluster", func() {
+				c.validate(logger, &Redis{}, &NATS{URL: "nats://localhost:4222"})
+
+				Expect(c.IsEnabled()).Should(BeFalse())
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("only redis or nats is used")))
+			})
+		})
+
+		When("the heartbeat interval is not above zero", func() {
+			It("should use the default", func() {
+				c.HeartbeatInterval = 0
+				c.validate(logger, &Redis{}, &NATS{})
+
+				Expect(c.HeartbeatInterval).Should(Equal(Duration(5 * time.Second)))
+			})
+		})
+	})
+})
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Compatibility configures how queries using unusual parts of the DNS protocol are handled
+type Compatibility struct {
+	// Queries with more than one question
+	MultipleQuestions CompatibilityAction `yaml:"multipleQuestions" default:"forward"`
+	// EDNS options blocky doesn't know
+	UnknownEDNSOptions CompatibilityAction `yaml:"unknownEdnsOptions" default:"forward"`
+	// Query classes blocky doesn't know, there is nothing to strip
+	UnknownClasses CompatibilityAction `yaml:"unknownClasses" default:"forward"`
+}
+
+func (c *Compatibility) validate(logger *logrus.Entry) {
+	if c.UnknownClasses == CompatibilityActionStrip {
+		logger.Warnf(
+			"compatibility.unknownClasses = %s is not supported, using %s", c.UnknownClasses, CompatibilityActionRefuse,
+		)
+
+		c.UnknownClasses = CompatibilityActionRefuse
+	}
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Compatibility) IsEnabled() bool {
+	return c.MultipleQuestions != CompatibilityActionForward ||
+		c.UnknownEDNSOptions != CompatibilityActionForward ||
+		c.UnknownClasses != CompatibilityActionForward
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Compatibility) LogConfig(logger *logrus.Entry) {
+	logger.Infof("multipleQuestions  = %s", c.MultipleQuestions)
+	logger.Infof("unknownEdnsOptions = %s", c.UnknownEDNSOptions)
+	logger.Infof("unknownClasses     = %s", c.UnknownClasses)
+}
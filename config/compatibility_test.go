@@ -0,0 +1,70 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompatibilityConfig", func() {
+	var cfg Compatibility
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[Compatibility]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("one action is not forward", func() {
+			It("should be true", func() {
+				cfg.UnknownEDNSOptions = CompatibilityActionStrip
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.MultipleQuestions = CompatibilityActionRefuse
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("multipleQuestions  = refuse"),
+				ContainSubstring("unknownEdnsOptions = forward"),
+				ContainSubstring("unknownClasses     = forward"),
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should replace strip for unknown classes", func() {
+			cfg.UnknownClasses = CompatibilityActionStrip
+
+			cfg.validate(logger)
+
+			Expect(cfg.UnknownClasses).Should(Equal(CompatibilityActionRefuse))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("not supported")))
+		})
+
+		It("should keep supported actions", func() {
+			cfg.UnknownClasses = CompatibilityActionRefuse
+			cfg.MultipleQuestions = CompatibilityActionStrip
+
+			cfg.validate(logger)
+
+			Expect(cfg.UnknownClasses).Should(Equal(CompatibilityActionRefuse))
+			Expect(cfg.MultipleQuestions).Should(Equal(CompatibilityActionStrip))
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+	})
+})
@@ -4,45 +4,133 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/0xERR0R/blocky/log"
 	"github.com/sirupsen/logrus"
 )
 
+// ConditionalListPrefix marks a mapping key as reference to a list of `conditional.lists`
+const ConditionalListPrefix = "list:"
+
 // ConditionalUpstream conditional upstream configuration
 type ConditionalUpstream struct {
 	RewriterConfig `yaml:",inline"`
 	Mapping        ConditionalUpstreamMapping `yaml:"mapping"`
+	Lists          map[string][]BytesSource   `yaml:"lists"`
+	Loading        SourceLoading              `yaml:"loading"`
+	// HealthCheck of the conditional upstreams, `upstreams.healthCheck` is used if the interval is 0
+	HealthCheck UpstreamHealthCheck `yaml:"healthCheck"`
+	// ResolvConf forwards the search domains of the system to its name servers
+	ResolvConf ConditionalResolvConf `yaml:"resolvConf"`
+}
+
+// ConditionalResolvConf configures the mappings created from a resolv.conf file, for example written by a DHCP client
+type ConditionalResolvConf struct {
+	// Path of the file, empty disables the mappings
+	File string `yaml:"file"`
+	// Time between the checks of the file for changes, 0 reads it only on start
+	CheckPeriod Duration `yaml:"checkPeriod" default:"1m"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *ConditionalResolvConf) IsEnabled() bool {
+	return c.File != ""
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ConditionalResolvConf) LogConfig(logger *logrus.Entry) {
+	logger.Info("file: ", c.File)
+	logger.Info("checkPeriod: ", c.CheckPeriod)
 }
 
 // ConditionalUpstreamMapping mapping for conditional configuration
 type ConditionalUpstreamMapping struct {
 	Upstreams map[string][]Upstream
+	// Strategies of the mappings with an explicit strategy, the others use the parallel_best strategy
+	Strategies map[string]UpstreamStrategy
+}
+
+// conditionalMappingEntry is a comma separated list of upstreams or an object with the upstreams and a strategy
+type conditionalMappingEntry struct {
+	Upstreams []string          `yaml:"upstreams"`
+	Strategy  *UpstreamStrategy `yaml:"strategy"`
 }
 
 // IsEnabled implements `config.Configurable`.
 func (c *ConditionalUpstream) IsEnabled() bool {
-	return len(c.Mapping.Upstreams) != 0
+	return len(c.Mapping.Upstreams) != 0 || c.ResolvConf.IsEnabled()
 }
 
 // LogConfig implements `config.Configurable`.
 func (c *ConditionalUpstream) LogConfig(logger *logrus.Entry) {
 	for key, val := range c.Mapping.Upstreams {
-		logger.Infof("%s = %v", key, val)
+		if strategy, ok := c.Mapping.Strategies[key]; ok {
+			logger.Infof("%s = %v (%s)", key, val, strategy)
+		} else {
+			logger.Infof("%s = %v", key, val)
+		}
+	}
+
+	if c.HealthCheck.IsEnabled() {
+		logger.Info("healthCheck:")
+		log.WithIndent(logger, "  ", c.HealthCheck.LogConfig)
+	}
+
+	if c.ResolvConf.IsEnabled() {
+		logger.Info("resolvConf:")
+		log.WithIndent(logger, "  ", c.ResolvConf.LogConfig)
+	}
+
+	if len(c.Lists) != 0 {
+		logger.Info("loading:")
+		log.WithIndent(logger, "  ", c.Loading.LogConfig)
+
+		logger.Info("lists:")
+
+		for name, sources := range c.Lists {
+			logger.Infof("  %s: %d sources", name, len(sources))
+		}
 	}
 }
 
+func (c *ConditionalUpstream) validate(logger *logrus.Entry) {
+	if c.HealthCheck.IsEnabled() && c.HealthCheck.FailureThreshold == 0 {
+		def := mustDefault[UpstreamHealthCheck]()
+
+		logger.Warnf("conditional.healthCheck.failureThreshold = 0, setting to %d", def.FailureThreshold)
+
+		c.HealthCheck.FailureThreshold = def.FailureThreshold
+	}
+
+	for name := range c.Lists {
+		if _, ok := c.Mapping.Upstreams[ConditionalListPrefix+name]; !ok {
+			logger.Warnf("conditional.lists: %s is not used in the mapping, use '%s%s' as key", name, ConditionalListPrefix, name)
+		}
+	}
+}
+
+// ListName returns the name of the list referenced by the mapping key, false if the key is a domain or wildcard
+func (c *ConditionalUpstream) ListName(key string) (string, bool) {
+	return strings.CutPrefix(key, ConditionalListPrefix)
+}
+
 // UnmarshalYAML implements `yaml.Unmarshaler`.
 func (c *ConditionalUpstreamMapping) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	var input map[string]string
+	var input map[string]conditionalMappingEntry
 	if err := unmarshal(&input); err != nil {
 		return err
 	}
 
 	result := make(map[string][]Upstream, len(input))
+	strategies := make(map[string]UpstreamStrategy)
 
 	for k, v := range input {
+		if len(v.Upstreams) == 0 {
+			return fmt.Errorf("mapping '%s' has no upstreams", k)
+		}
+
 		var upstreams []Upstream
 
-		for _, part := range strings.Split(v, ",") {
+		for _, part := range v.Upstreams {
 			upstream, err := ParseUpstream(strings.TrimSpace(part))
 			if err != nil {
 				return fmt.Errorf("can't convert upstream '%s': %w", strings.TrimSpace(part), err)
@@ -52,9 +140,28 @@ func (c *ConditionalUpstreamMapping) UnmarshalYAML(unmarshal func(interface{}) e
 		}
 
 		result[k] = upstreams
+
+		if v.Strategy != nil {
+			strategies[k] = *v.Strategy
+		}
 	}
 
 	c.Upstreams = result
+	c.Strategies = strategies
 
 	return nil
 }
+
+// UnmarshalYAML implements `yaml.Unmarshaler`.
+func (c *conditionalMappingEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var upstreams string
+	if err := unmarshal(&upstreams); err == nil {
+		c.Upstreams = strings.Split(upstreams, ",")
+
+		return nil
+	}
+
+	type plain conditionalMappingEntry
+
+	return unmarshal((*plain)(c))
+}
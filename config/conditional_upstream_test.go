@@ -2,10 +2,12 @@ package config
 
 import (
 	"errors"
+	"time"
 
 	"github.com/creasty/defaults"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
 )
 
 var _ = Describe("ConditionalUpstreamConfig", func() {
@@ -39,6 +41,14 @@ var _ = Describe("ConditionalUpstreamConfig", func() {
 			})
 		})
 
+		When("a resolv.conf is set", func() {
+			It("should be true", func() {
+				cfg := ConditionalUpstream{ResolvConf: ConditionalResolvConf{File: "/etc/resolv.conf"}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+
 		When("disabled", func() {
 			It("should be false", func() {
 				cfg := ConditionalUpstream{
@@ -59,20 +69,123 @@ var _ = Describe("ConditionalUpstreamConfig", func() {
 		})
 	})
 
+	Describe("strategies and health checks", func() {
+		BeforeEach(func() {
+			cfg.Mapping.Strategies = map[string]UpstreamStrategy{"fritz.box": UpstreamStrategyStrict}
+			cfg.HealthCheck = UpstreamHealthCheck{Interval: Duration(time.Minute), Name: "fritz.box"}
+		})
+
+		It("should be logged", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("fritz.box = [tcp+udp:fbTest:0] (strict)"),
+				ContainSubstring("healthCheck:"),
+			))
+		})
+
+		It("should default the failure threshold", func() {
+			cfg.validate(logger)
+
+			Expect(cfg.HealthCheck.FailureThreshold).Should(BeEquivalentTo(3))
+		})
+	})
+
+	Describe("resolvConf", func() {
+		It("should be logged", func() {
+			cfg.ResolvConf = ConditionalResolvConf{File: "/etc/resolv.conf", CheckPeriod: Duration(time.Minute)}
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("resolvConf:"),
+				ContainSubstring("file: /etc/resolv.conf"),
+				ContainSubstring("checkPeriod: 1 minute"),
+			))
+		})
+	})
+
+	Describe("Lists", func() {
+		BeforeEach(func() {
+			cfg.Lists = map[string][]BytesSource{
+				"china":  NewBytesSources("https://example.com/china.txt"),
+				"unused": NewBytesSources("https://example.com/unused.txt"),
+			}
+			cfg.Mapping.Upstreams["list:china"] = []Upstream{{Net: NetProtocolTcpUdp, Host: "chinaTest"}}
+		})
+
+		It("should be logged", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("lists:"),
+				ContainSubstring("china: 1 sources"),
+			))
+		})
+
+		It("should warn about unused lists", func() {
+			cfg.validate(logger)
+
+			Expect(hook.Messages).Should(ConsistOf(ContainSubstring("unused is not used in the mapping")))
+		})
+
+		It("should tell list references from domains", func() {
+			name, isList := cfg.ListName("list:china")
+			Expect(isList).Should(BeTrue())
+			Expect(name).Should(Equal("china"))
+
+			_, isList = cfg.ListName("fritz.box")
+			Expect(isList).Should(BeFalse())
+		})
+	})
+
 	Describe("UnmarshalYAML", func() {
 		It("Should parse config as map", func() {
 			c := &ConditionalUpstreamMapping{}
-			err := c.UnmarshalYAML(func(i interface{}) error {
-				*i.(*map[string]string) = map[string]string{"key": "1.2.3.4"}
-
-				return nil
-			})
+			err := yaml.Unmarshal([]byte(`key: 1.2.3.4`), c)
 			Expect(err).Should(Succeed())
 			Expect(c.Upstreams).Should(HaveLen(1))
 			Expect(c.Upstreams["key"]).Should(HaveLen(1))
 			Expect(c.Upstreams["key"][0]).Should(Equal(Upstream{
 				Net: NetProtocolTcpUdp, Host: "1.2.3.4", Port: 53,
 			}))
+			Expect(c.Strategies).Should(BeEmpty())
+		})
+
+		It("should parse upstreams with a strategy", func() {
+			c := &ConditionalUpstreamMapping{}
+			err := yaml.Unmarshal([]byte(`
+corp.example.com:
+  upstreams:
+    - 10.0.0.1
+    - 10.0.0.2
+  strategy: strict
+fritz.box: 192.168.178.1, 192.168.178.2`), c)
+			Expect(err).Should(Succeed())
+			Expect(c.Upstreams["corp.example.com"]).Should(Equal([]Upstream{
+				{Net: NetProtocolTcpUdp, Host: "10.0.0.1", Port: 53},
+				{Net: NetProtocolTcpUdp, Host: "10.0.0.2", Port: 53},
+			}))
+			Expect(c.Upstreams["fritz.box"]).Should(HaveLen(2))
+			Expect(c.Strategies).Should(Equal(map[string]UpstreamStrategy{
+				"corp.example.com": UpstreamStrategyStrict,
+			}))
+		})
+
+		It("should fail without upstreams", func() {
+			c := &ConditionalUpstreamMapping{}
+			err := yaml.Unmarshal([]byte(`
+corp.example.com:
+  strategy: strict`), c)
+			Expect(err).Should(MatchError(ContainSubstring("mapping 'corp.example.com' has no upstreams")))
+		})
+
+		It("should fail with an unknown strategy", func() {
+			c := &ConditionalUpstreamMapping{}
+			err := yaml.Unmarshal([]byte(`
+corp.example.com:
+  upstreams: [10.0.0.1]
+  strategy: unknown`), c)
+			Expect(err).Should(HaveOccurred())
 		})
 
 		It("should fail if wrong YAML format", func() {
@@ -3,6 +3,7 @@ package config
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -45,6 +46,7 @@ type Configurable interface {
 // tcp+udp // TCP and UDP protocols
 // tcp-tls // TCP-TLS protocol
 // https // HTTPS protocol
+// unix // Unix domain socket, DNS over stream framing
 // )
 type NetProtocol uint16
 
@@ -101,6 +103,15 @@ func (v *TLSVersion) validate(logger *logrus.Entry) {
 	}
 }
 
+// TLSClientAuth is the policy of a TLS listener for client certificates. ENUM(
+// none             // don't request a client certificate
+// request          // request a client certificate, but don't require it
+// require          // require any client certificate
+// verifyIfGiven    // verify the client certificate if one is sent
+// requireAndVerify // require a valid client certificate
+// )
+type TLSClientAuth uint8 // values MUST match `tls.ClientAuthType`
+
 // QueryLogType type of the query log ENUM(
 // console // use logger as fallback
 // none // no logging
@@ -109,6 +120,7 @@ func (v *TLSVersion) validate(logger *logrus.Entry) {
 // csv // CSV file per day
 // csv-client // CSV file per day and client
 // timescale // Timescale database
+// jsonl // gzip compressed JSON lines files, rotated per hour or day
 // )
 type QueryLogType int16
 
@@ -147,12 +159,123 @@ func (s InitStrategy) Do(ctx context.Context, init func(context.Context) error,
 	return nil
 }
 
+// CompatibilityAction is how unusual queries are handled ENUM(
+// forward // forward the query verbatim
+// strip   // remove the unusual part and resolve the rest
+// refuse  // answer without resolving
+// )
+type CompatibilityAction uint8
+
+// RateLimitAction is how queries exceeding a rate limit are handled ENUM(
+// refuse // answer with REFUSED
+// drop   // don't answer
+// slip   // answer every n-th query over UDP truncated, so real clients retry over TCP, and drop the others
+// )
+type RateLimitAction uint8
+
+// ProxyProtocolListener is a kind of listener which can accept PROXY protocol headers ENUM(
+// dns   // DNS over TCP
+// tls   // DNS over TLS
+// http  // HTTP, e.g. DoH without TLS
+// https // HTTPS, e.g. DoH
+// )
+type ProxyProtocolListener uint8
+
+// ACMEChallenge is how the control of the domains is proven to the ACME CA ENUM(
+// http-01 // serve a token via HTTP on port 80
+// dns-01  // publish a TXT record, required for wildcard domains
+// )
+type ACMEChallenge uint8
+
+// ACMEDNSProvider publishes the TXT records of the dns-01 challenge ENUM(
+// rfc2136 // dynamic DNS update (RFC 2136) with TSIG
+// exec    // run a command
+// )
+type ACMEDNSProvider uint8
+
+// DHCPLeaseFormat format of a DHCP lease source ENUM(
+// dnsmasq // dnsmasq lease file
+// isc     // ISC DHCP dhcpd.leases file
+// kea     // Kea memfile CSV lease file
+// kea-api // Kea control agent API
+// )
+type DHCPLeaseFormat uint8
+
+// CacheEvictionPolicy decides which entries are kept in a full cache ENUM(
+// lru     // remove the least recently used entry
+// tinylfu // remove the least recently used entry, unless the new entry is requested less often
+// )
+type CacheEvictionPolicy uint8
+
+// RebindAction is how answers with private addresses are handled ENUM(
+// nxdomain // answer with NXDOMAIN
+// drop     // remove the records with private addresses from the answer
+// )
+type RebindAction uint8
+
+// NewDomainAction is how queries for newly observed or registered domains are handled ENUM(
+// log   // log the domain and resolve the query
+// block // answer with NXDOMAIN
+// )
+type NewDomainAction uint8
+
+// TunnelingAction is how queries of a client to a zone suspected of DNS tunneling are handled ENUM(
+// log       // log and count the suspected tunnel
+// ratelimit // answer the queries exceeding the rate limit with REFUSED
+// block     // answer all queries with REFUSED
+// )
+type TunnelingAction uint8
+
+// APIRole is the role of an API client, each endpoint class requires a minimal role ENUM(
+// none     // anonymous clients, only for endpoint classes
+// readOnly // can read the state
+// admin    // can also change the state
+// )
+type APIRole uint8
+
 // QueryLogField data field to be logged
-// ENUM(clientIP,clientName,responseReason,responseAnswer,question,duration)
+// ENUM(clientIP,clientName,responseReason,responseAnswer,question,duration,answerGeo,edns)
 type QueryLogField string
 
+// QueryLogIPAnonymization is how client IPs are anonymized in the query log ENUM(
+// none     // log the IP as it is
+// truncate // log the network of the IP only
+// hash     // log a salted hash of the IP
+// )
+type QueryLogIPAnonymization uint8
+
+// EventsType is the message broker the events are published to ENUM(
+// none      // no publishing
+// mqtt      // MQTT broker
+// kafkaRest // Kafka REST proxy, not a Kafka broker
+// )
+type EventsType uint8
+
+// EventType is a kind of published event
+// ENUM(queryBlocked,listRefreshed,listDownloadFailed,upstreamHealthChanged,blockingChanged)
+type EventType string
+
+// WebhookFormat is the payload format of a webhook ENUM(
+// json     // generic JSON
+// slack    // Slack incoming webhook
+// discord  // Discord webhook
+// ntfy     // ntfy topic
+// pushover // Pushover message API
+// )
+type WebhookFormat uint8
+
+// WebhookEvent is an operational event which triggers the webhooks
+// ENUM(listDownloadFailed,upstreamsDown,upstreamsRecovered,certificateExpiring)
+type WebhookEvent string
+
+// QueryLogRotation is the period covered by a query log archive file ENUM(
+// hourly
+// daily
+// )
+type QueryLogRotation uint8
+
 // UpstreamStrategy data field to be logged
-// ENUM(parallel_best,strict,random)
+// ENUM(parallel_best,strict,random,weighted,fastest)
 type UpstreamStrategy uint8
 
 //nolint:gochecknoglobals
@@ -232,11 +355,19 @@ type Config struct {
 	QueryLog         QueryLog            `yaml:"queryLog"`
 	Prometheus       Metrics             `yaml:"prometheus"`
 	Redis            Redis               `yaml:"redis"`
+	NATS             NATS                `yaml:"nats"`
+	Cluster          Cluster             `yaml:"cluster"`
+	Events           Events              `yaml:"events"`
+	Webhooks         Webhooks            `yaml:"webhooks"`
+	Sinkhole         Sinkhole            `yaml:"sinkhole"`
 	Log              log.Config          `yaml:"log"`
 	Ports            Ports               `yaml:"ports"`
 	MinTLSServeVer   TLSVersion          `yaml:"minTlsServeVersion" default:"1.2"`
 	CertFile         string              `yaml:"certFile"`
 	KeyFile          string              `yaml:"keyFile"`
+	Certificate      Certificate         `yaml:"certificate"`
+	DoH              DoH                 `yaml:"doh"`
+	ACME             ACME                `yaml:"acme"`
 	BootstrapDNS     BootstrapDNS        `yaml:"bootstrapDns"`
 	HostsFile        HostsFile           `yaml:"hostsFile"`
 	FQDNOnly         FQDNOnly            `yaml:"fqdnOnly"`
@@ -244,6 +375,32 @@ type Config struct {
 	EDE              EDE                 `yaml:"ede"`
 	ECS              ECS                 `yaml:"ecs"`
 	SUDN             SUDN                `yaml:"specialUseDomains"`
+	API              API                 `yaml:"api"`
+	TLS              TLS                 `yaml:"tls"`
+	Compatibility    Compatibility       `yaml:"compatibility"`
+	Snapshots        Snapshots           `yaml:"snapshots"`
+	Watchdog         Watchdog            `yaml:"watchdog"`
+	DNS64            DNS64               `yaml:"dns64"`
+	ResponseRewrite  ResponseRewrite     `yaml:"responseRewrite"`
+	Mirror           Mirror              `yaml:"mirror"`
+	MDNS             MDNS                `yaml:"mdns"`
+	GeoIP            GeoIP               `yaml:"geoIP"`
+	Stats            Stats               `yaml:"stats"`
+	RateLimit        DNSRateLimit        `yaml:"rateLimit"`
+	RRL              RRL                 `yaml:"rrl"`
+	Cookies          DNSCookies          `yaml:"cookies"`
+	ProxyProtocol    ProxyProtocol       `yaml:"proxyProtocol"`
+	NewDomains       NewDomains          `yaml:"newDomains"`
+	Tunneling        Tunneling           `yaml:"tunneling"`
+
+	// Hash is the SHA-256 of the configuration data, to tell which configuration an instance runs
+	Hash string `yaml:"-"`
+
+	// Path the configuration was loaded from, empty if it was not loaded from a file
+	Path string `yaml:"-"`
+
+	// Data is the configuration as it was loaded, to take snapshots of it
+	Data []byte `yaml:"-"`
 
 	// Deprecated options
 	Deprecated struct {
@@ -268,6 +425,36 @@ type Ports struct {
 	HTTP  ListenConfig `yaml:"http"`
 	HTTPS ListenConfig `yaml:"https"`
 	TLS   ListenConfig `yaml:"tls"`
+
+	// Port(s) of the gRPC admin API
+	GRPC ListenConfig `yaml:"grpc"`
+
+	// Paths of Unix domain sockets to serve DNS on (stream framing, like TCP)
+	Unix []string `yaml:"unix"`
+
+	// Serve HTTP/3 (QUIC) on the UDP side of the HTTPS ports
+	HTTP3 bool `yaml:"http3" default:"false"`
+
+	// Maximum number of queries processed concurrently per TCP/DoT connection, 0 or 1 disables pipelining
+	TCPPipelining uint `yaml:"tcpPipelining" default:"16"`
+
+	// Maximum number of open TCP/DoT connections, further connections are closed. 0 for no limit
+	TCPMaxConnections uint `yaml:"tcpMaxConnections" default:"0"`
+
+	// Maximum number of queries per TCP/DoT connection, the connection is closed afterwards. 0 for no limit
+	TCPMaxQueries uint `yaml:"tcpMaxQueries" default:"128"`
+
+	// Time an idle TCP/DoT connection is kept open
+	TCPIdleTimeout Duration `yaml:"tcpIdleTimeout" default:"8s"`
+
+	// Announce the idle timeout to clients using the EDNS TCP keepalive option (RFC 7828)
+	TCPKeepalive bool `yaml:"tcpKeepalive" default:"true"`
+
+	// Bind the TCP and UDP listeners with SO_REUSEPORT, so a new process can take over while the old one drains
+	ReusePort bool `yaml:"reusePort" default:"false"`
+
+	// Time to finish the in-flight queries and requests on shutdown
+	DrainTimeout Duration `yaml:"drainTimeout" default:"10s"`
 }
 
 func (c *Ports) LogConfig(logger *logrus.Entry) {
@@ -275,6 +462,43 @@ func (c *Ports) LogConfig(logger *logrus.Entry) {
 	logger.Infof("TLS   = %s", c.TLS)
 	logger.Infof("HTTP  = %s", c.HTTP)
 	logger.Infof("HTTPS = %s", c.HTTPS)
+
+	if c.HTTP3 {
+		logger.Infof("HTTP3 = %s", c.HTTPS)
+	}
+
+	if len(c.GRPC) != 0 {
+		logger.Infof("gRPC  = %s", c.GRPC)
+	}
+
+	if len(c.Unix) != 0 {
+		logger.Infof("Unix  = %s", c.Unix)
+	}
+
+	logger.Debugf("TCP pipelining       = %d", c.TCPPipelining)
+	logger.Debugf("TCP max connections  = %d", c.TCPMaxConnections)
+	logger.Debugf("TCP max queries      = %d", c.TCPMaxQueries)
+	logger.Debugf("TCP idle timeout     = %s", c.TCPIdleTimeout)
+	logger.Debugf("TCP keepalive option = %t", c.TCPKeepalive)
+
+	if c.ReusePort {
+		logger.Info("reusePort = true")
+	}
+
+	logger.Debugf("drain timeout        = %s", c.DrainTimeout)
+}
+
+func (c *Ports) validate(logger *logrus.Entry) {
+	if c.HTTP3 && len(c.HTTPS) == 0 {
+		logger.Warn("ports.http3 has no effect without ports.https")
+	}
+
+	if !c.TCPIdleTimeout.IsAboveZero() {
+		defaults := mustDefault[Ports]()
+
+		logger.Warnf("ports.tcpIdleTimeout <= 0, setting to %s", defaults.TCPIdleTimeout)
+		c.TCPIdleTimeout = defaults.TCPIdleTimeout
+	}
 }
 
 // split in two types to avoid infinite recursion. See `BootstrapDNS.UnmarshalYAML`.
@@ -323,10 +547,69 @@ func (c *toEnable) LogConfig(logger *logrus.Entry) {
 
 type Init struct {
 	Strategy InitStrategy `yaml:"strategy" default:"blocking"`
+	Retry    InitRetry    `yaml:"retry"`
 }
 
 func (c *Init) LogConfig(logger *logrus.Entry) {
 	logger.Debugf("strategy = %s", c.Strategy)
+
+	if c.Retry.IsEnabled() && c.Strategy != InitStrategyFailOnError {
+		logger.Debugf("retry = %s - %s", c.Retry.MinDelay, c.Retry.MaxDelay)
+	}
+}
+
+// Do runs init with the strategy.
+// If it fails, blocky keeps running and retries are enabled, it is retried in the background with exponential backoff
+// until it succeeds.
+func (c *Init) Do(ctx context.Context, init func(context.Context) error, logErr func(error)) error {
+	if !c.Retry.IsEnabled() || c.Strategy == InitStrategyFailOnError {
+		return c.Strategy.Do(ctx, init, logErr)
+	}
+
+	return c.Strategy.Do(ctx, init, func(err error) {
+		logErr(err)
+
+		go c.Retry.retry(ctx, init, logErr)
+	})
+}
+
+// InitRetry configures the backoff of the retries of a failed initialization
+type InitRetry struct {
+	// Delay before the first retry, doubled after each failed one. 0 disables the retries
+	MinDelay Duration `yaml:"minDelay" default:"0"`
+	// Maximum delay between two retries
+	MaxDelay Duration `yaml:"maxDelay" default:"5m"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *InitRetry) IsEnabled() bool {
+	return c.MinDelay.IsAboveZero()
+}
+
+func (c *InitRetry) retry(ctx context.Context, init func(context.Context) error, logErr func(error)) {
+	init = recoverToError(init, func(panicVal any) error {
+		return fmt.Errorf("panic during initialization: %v", panicVal)
+	})
+
+	delay := c.MinDelay.ToDuration()
+	maxDelay := max(c.MaxDelay.ToDuration(), delay)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		err := init(ctx)
+		if err == nil {
+			return
+		}
+
+		delay = min(2*delay, maxDelay)
+
+		logErr(fmt.Errorf("retry failed, retrying in %s: %w", delay, err))
+	}
 }
 
 type SourceLoading struct {
@@ -336,6 +619,8 @@ type SourceLoading struct {
 	MaxErrorsPerSource int        `yaml:"maxErrorsPerSource" default:"5"`
 	RefreshPeriod      Duration   `yaml:"refreshPeriod" default:"4h"`
 	Downloads          Downloader `yaml:"downloads"`
+	// Interval to check the local files of the lists for changes, 0 disables the checks
+	CheckPeriod Duration `yaml:"checkPeriod" default:"1m"`
 }
 
 func (c *SourceLoading) LogConfig(logger *logrus.Entry) {
@@ -349,14 +634,20 @@ func (c *SourceLoading) LogConfig(logger *logrus.Entry) {
 		logger.Debug("refresh = disabled")
 	}
 
+	if c.CheckPeriod.IsAboveZero() {
+		logger.Debugf("checkPeriod = %s", c.CheckPeriod)
+	}
+
 	logger.Info("downloads:")
 	log.WithIndent(logger, "  ", c.Downloads.LogConfig)
 }
 
+// StartPeriodicRefresh runs load with the init strategy, then refresh every `RefreshPeriod`.
+// Only load is retried: it is called again after a failure, so it can reload only what failed.
 func (c *SourceLoading) StartPeriodicRefresh(
-	ctx context.Context, refresh func(context.Context) error, logErr func(error),
+	ctx context.Context, load, refresh func(context.Context) error, logErr func(error),
 ) error {
-	err := c.Strategy.Do(ctx, refresh, logErr)
+	err := c.Init.Do(ctx, load, logErr)
 	if err != nil {
 		return err
 	}
@@ -411,12 +702,36 @@ type Downloader struct {
 	WriteTimeout      Duration `yaml:"writeTimeout" default:"20s"`
 	Attempts          uint     `yaml:"attempts" default:"3"`
 	Cooldown          Duration `yaml:"cooldown" default:"500ms"`
+	// If above cooldown, the cooldown doubles after each failed attempt up to this value
+	MaxCooldown Duration `yaml:"maxCooldown" default:"0"`
+	// Limit of the download speed of all downloads together in KiB/s, 0 is unlimited
+	MaxBandwidth uint `yaml:"maxBandwidth" default:"0"`
+	// Mirror URLs of sources, tried in order if the download from the source fails
+	Mirrors map[string][]string `yaml:"mirrors"`
+	// Directory to keep the last successful download of each source in, used if all mirrors fail
+	CacheDir string `yaml:"cacheDir"`
 }
 
 func (c *Downloader) LogConfig(logger *logrus.Entry) {
 	logger.Infof("timeout = %s", c.Timeout)
 	logger.Infof("attempts = %d", c.Attempts)
 	logger.Debugf("cooldown = %s", c.Cooldown)
+
+	if c.MaxCooldown > c.Cooldown {
+		logger.Debugf("maxCooldown = %s", c.MaxCooldown)
+	}
+
+	if c.MaxBandwidth > 0 {
+		logger.Infof("maxBandwidth = %d KiB/s", c.MaxBandwidth)
+	}
+
+	if c.CacheDir != "" {
+		logger.Infof("cacheDir = %s", c.CacheDir)
+	}
+
+	for source, mirrors := range c.Mirrors {
+		logger.Infof("mirrors of %s = %s", source, strings.Join(mirrors, ", "))
+	}
 }
 
 func WithDefaults[T any]() (T, error) {
@@ -457,7 +772,7 @@ func loadConfig(logger *logrus.Entry, path string, mandatory bool) (rCfg *Config
 		}
 	}()
 
-	fs, err := os.Stat(path)
+	data, prettyPath, err := readConfig(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) && !mandatory {
 			// config file does not exist
@@ -465,31 +780,11 @@ func loadConfig(logger *logrus.Entry, path string, mandatory bool) (rCfg *Config
 			return &cfg, nil
 		}
 
-		return nil, fmt.Errorf("can't read config file(s): %w", err)
-	}
-
-	var (
-		data       []byte
-		prettyPath string
-	)
-
-	if fs.IsDir() {
-		prettyPath = filepath.Join(path, "*")
-
-		data, err = readFromDir(path, data)
-		if err != nil {
-			return nil, fmt.Errorf("can't read config files: %w", err)
-		}
-	} else {
-		prettyPath = path
-
-		data, err = os.ReadFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("can't read config file: %w", err)
-		}
+		return nil, err
 	}
 
 	cfg.CustomDNS.Zone.configPath = prettyPath
+	cfg.Path = path
 
 	err = unmarshalConfig(logger, data, &cfg)
 	if err != nil {
@@ -499,43 +794,55 @@ func loadConfig(logger *logrus.Entry, path string, mandatory bool) (rCfg *Config
 	return &cfg, nil
 }
 
-func readFromDir(path string, data []byte) ([]byte, error) {
-	err := filepath.WalkDir(path, func(filePath string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if path == filePath {
-			return nil
-		}
+// readConfig reads the configuration data from the file or all YAML files in the directory at path.
+// The files of a directory and included files are merged.
+func readConfig(path string) (data []byte, prettyPath string, err error) {
+	fs, err := os.Stat(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("can't read config file(s): %w", err)
+	}
 
-		// Ignore non YAML files
-		if !strings.HasSuffix(filePath, ".yml") && !strings.HasSuffix(filePath, ".yaml") {
-			return nil
+	if fs.IsDir() {
+		files, err := configFilesInDir(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("can't read config files: %w", err)
 		}
 
-		isRegular, err := isRegularFile(filePath)
+		data, err = mergeConfigFiles(files)
 		if err != nil {
-			return err
+			return nil, "", fmt.Errorf("can't read config files: %w", err)
 		}
 
-		// Ignore non regular files (directories, sockets, etc.)
-		if !isRegular {
-			return nil
-		}
+		return data, filepath.Join(path, "*"), nil
+	}
+
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("can't read config file: %w", err)
+	}
 
-		fileData, err := os.ReadFile(filePath)
+	if HasIncludes(data) {
+		data, err = mergeConfigFiles([]string{path})
 		if err != nil {
-			return err
+			return nil, "", fmt.Errorf("can't read config file: %w", err)
 		}
+	}
 
-		data = append(data, []byte("\n")...)
-		data = append(data, fileData...)
+	return data, path, nil
+}
 
-		return nil
-	})
+// HashConfig returns the hash of the configuration at path, it is the same as `Config.Hash` once loaded
+func HashConfig(path string) (string, error) {
+	data, _, err := readConfig(path)
+	if err != nil {
+		return "", err
+	}
+
+	return hashConfigData(data), nil
+}
 
-	return data, err
+func hashConfigData(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
 }
 
 // isRegularFile follows symlinks, so the result is `true` for a symlink to a regular file.
@@ -551,7 +858,13 @@ func isRegularFile(path string) (bool, error) {
 }
 
 func unmarshalConfig(logger *logrus.Entry, data []byte, cfg *Config) error {
-	err := yaml.UnmarshalStrict(data, cfg)
+	// the data is stored without the values of environment variables and secret files, to not leak them
+	interpolated, err := interpolate(data)
+	if err != nil {
+		return fmt.Errorf("can't interpolate config values: %w", err)
+	}
+
+	err = yaml.UnmarshalStrict(interpolated, cfg)
 	if err != nil {
 		return fmt.Errorf("wrong file structure: %w", err)
 	}
@@ -563,6 +876,9 @@ func unmarshalConfig(logger *logrus.Entry, data []byte, cfg *Config) error {
 
 	cfg.validate(logger)
 
+	cfg.Hash = hashConfigData(data)
+	cfg.Data = data
+
 	return nil
 }
 
@@ -600,8 +916,46 @@ func (cfg *Config) migrate(logger *logrus.Entry) bool {
 }
 
 func (cfg *Config) validate(logger *logrus.Entry) {
+	cfg.Ports.validate(logger)
+	cfg.API.validate(logger)
 	cfg.MinTLSServeVer.validate(logger)
 	cfg.Upstreams.validate(logger)
+	cfg.TLS.validate(logger)
+	cfg.ACME.validate(logger, cfg)
+	cfg.DoH.validate(logger)
+	cfg.Blocking.validate(logger)
+	cfg.Caching.validate(logger)
+	cfg.ClientLookup.validate(logger)
+	cfg.Compatibility.validate(logger)
+	cfg.CustomDNS.validate(logger, &cfg.API.Auth)
+	cfg.Conditional.validate(logger)
+	cfg.Watchdog.validate(logger)
+	cfg.Filtering.RebindProtection.validate(logger)
+	cfg.DNS64.validate(logger)
+	cfg.ResponseRewrite.validate(logger)
+	cfg.Mirror.validate(logger)
+	cfg.MDNS.validate(logger)
+	cfg.NATS.validate(logger, &cfg.Redis)
+	cfg.Cluster.validate(logger, &cfg.Redis, &cfg.NATS)
+	cfg.Events.validate(logger)
+	cfg.Webhooks.validate(logger)
+	cfg.Sinkhole.validate(logger, &cfg.Blocking)
+	cfg.Stats.validate(logger, &cfg.Redis)
+	cfg.RateLimit.validate(logger)
+	cfg.RRL.validate(logger)
+	cfg.Cookies.validate(logger)
+	cfg.ProxyProtocol.validate(logger)
+	cfg.QueryLog.Anonymize.validate(logger)
+	cfg.NewDomains.validate(logger)
+	cfg.Tunneling.validate(logger)
+
+	cfg.Upstreams.TLS = cfg.TLS.ForUpstreams()
+	cfg.Upstreams.ECSUpstreams = cfg.ECS.Upstreams
+	cfg.Upstreams.ProxyProtocolUpstreams = cfg.ProxyProtocol.Upstreams
+	cfg.Redis.TLS = cfg.TLS.ForRedis()
+	cfg.NATS.TLS = cfg.TLS.ForNATS()
+	cfg.Events.TLS = cfg.TLS.ForEvents()
+	cfg.QueryLog.TLS = cfg.TLS.ForDatabase()
 }
 
 // ConvertPort converts string representation into a valid port (0 - 65535)
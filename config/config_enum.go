@@ -11,6 +11,699 @@ import (
 	"strings"
 )
 
+const (
+	// ACMEChallengeHttp01 is a ACMEChallenge of type Http-01.
+	// serve a token via HTTP on port 80
+	ACMEChallengeHttp01 ACMEChallenge = iota
+	// ACMEChallengeDns01 is a ACMEChallenge of type Dns-01.
+	// publish a TXT record, required for wildcard domains
+	ACMEChallengeDns01
+)
+
+var ErrInvalidACMEChallenge = fmt.Errorf("not a valid ACMEChallenge, try [%s]", strings.Join(_ACMEChallengeNames, ", "))
+
+const _ACMEChallengeName = "http-01dns-01"
+
+var _ACMEChallengeNames = []string{
+	_ACMEChallengeName[0:7],
+	_ACMEChallengeName[7:13],
+}
+
+// ACMEChallengeNames returns a list of possible string values of ACMEChallenge.
+func ACMEChallengeNames() []string {
+	tmp := make([]string, len(_ACMEChallengeNames))
+	copy(tmp, _ACMEChallengeNames)
+	return tmp
+}
+
+// ACMEChallengeValues returns a list of the values for ACMEChallenge
+func ACMEChallengeValues() []ACMEChallenge {
+	return []ACMEChallenge{
+		ACMEChallengeHttp01,
+		ACMEChallengeDns01,
+	}
+}
+
+var _ACMEChallengeMap = map[ACMEChallenge]string{
+	ACMEChallengeHttp01: _ACMEChallengeName[0:7],
+	ACMEChallengeDns01:  _ACMEChallengeName[7:13],
+}
+
+// String implements the Stringer interface.
+func (x ACMEChallenge) String() string {
+	if str, ok := _ACMEChallengeMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("ACMEChallenge(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x ACMEChallenge) IsValid() bool {
+	_, ok := _ACMEChallengeMap[x]
+	return ok
+}
+
+var _ACMEChallengeValue = map[string]ACMEChallenge{
+	_ACMEChallengeName[0:7]:  ACMEChallengeHttp01,
+	_ACMEChallengeName[7:13]: ACMEChallengeDns01,
+}
+
+// ParseACMEChallenge attempts to convert a string to a ACMEChallenge.
+func ParseACMEChallenge(name string) (ACMEChallenge, error) {
+	if x, ok := _ACMEChallengeValue[name]; ok {
+		return x, nil
+	}
+	return ACMEChallenge(0), fmt.Errorf("%s is %w", name, ErrInvalidACMEChallenge)
+}
+
+// MarshalText implements the text marshaller method.
+func (x ACMEChallenge) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *ACMEChallenge) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseACMEChallenge(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// ACMEDNSProviderRfc2136 is a ACMEDNSProvider of type Rfc2136.
+	// dynamic DNS update (RFC 2136) with TSIG
+	ACMEDNSProviderRfc2136 ACMEDNSProvider = iota
+	// ACMEDNSProviderExec is a ACMEDNSProvider of type Exec.
+	// run a command
+	ACMEDNSProviderExec
+)
+
+var ErrInvalidACMEDNSProvider = fmt.Errorf("not a valid ACMEDNSProvider, try [%s]", strings.Join(_ACMEDNSProviderNames, ", "))
+
+const _ACMEDNSProviderName = "rfc2136exec"
+
+var _ACMEDNSProviderNames = []string{
+	_ACMEDNSProviderName[0:7],
+	_ACMEDNSProviderName[7:11],
+}
+
+// ACMEDNSProviderNames returns a list of possible string values of ACMEDNSProvider.
+func ACMEDNSProviderNames() []string {
+	tmp := make([]string, len(_ACMEDNSProviderNames))
+	copy(tmp, _ACMEDNSProviderNames)
+	return tmp
+}
+
+// ACMEDNSProviderValues returns a list of the values for ACMEDNSProvider
+func ACMEDNSProviderValues() []ACMEDNSProvider {
+	return []ACMEDNSProvider{
+		ACMEDNSProviderRfc2136,
+		ACMEDNSProviderExec,
+	}
+}
+
+var _ACMEDNSProviderMap = map[ACMEDNSProvider]string{
+	ACMEDNSProviderRfc2136: _ACMEDNSProviderName[0:7],
+	ACMEDNSProviderExec:    _ACMEDNSProviderName[7:11],
+}
+
+// String implements the Stringer interface.
+func (x ACMEDNSProvider) String() string {
+	if str, ok := _ACMEDNSProviderMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("ACMEDNSProvider(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x ACMEDNSProvider) IsValid() bool {
+	_, ok := _ACMEDNSProviderMap[x]
+	return ok
+}
+
+var _ACMEDNSProviderValue = map[string]ACMEDNSProvider{
+	_ACMEDNSProviderName[0:7]:  ACMEDNSProviderRfc2136,
+	_ACMEDNSProviderName[7:11]: ACMEDNSProviderExec,
+}
+
+// ParseACMEDNSProvider attempts to convert a string to a ACMEDNSProvider.
+func ParseACMEDNSProvider(name string) (ACMEDNSProvider, error) {
+	if x, ok := _ACMEDNSProviderValue[name]; ok {
+		return x, nil
+	}
+	return ACMEDNSProvider(0), fmt.Errorf("%s is %w", name, ErrInvalidACMEDNSProvider)
+}
+
+// MarshalText implements the text marshaller method.
+func (x ACMEDNSProvider) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *ACMEDNSProvider) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseACMEDNSProvider(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// APIRoleNone is a APIRole of type None.
+	// anonymous clients, only for endpoint classes
+	APIRoleNone APIRole = iota
+	// APIRoleReadOnly is a APIRole of type ReadOnly.
+	// can read the state
+	APIRoleReadOnly
+	// APIRoleAdmin is a APIRole of type Admin.
+	// can also change the state
+	APIRoleAdmin
+)
+
+var ErrInvalidAPIRole = fmt.Errorf("not a valid APIRole, try [%s]", strings.Join(_APIRoleNames, ", "))
+
+const _APIRoleName = "nonereadOnlyadmin"
+
+var _APIRoleNames = []string{
+	_APIRoleName[0:4],
+	_APIRoleName[4:12],
+	_APIRoleName[12:17],
+}
+
+// APIRoleNames returns a list of possible string values of APIRole.
+func APIRoleNames() []string {
+	tmp := make([]string, len(_APIRoleNames))
+	copy(tmp, _APIRoleNames)
+	return tmp
+}
+
+// APIRoleValues returns a list of the values for APIRole
+func APIRoleValues() []APIRole {
+	return []APIRole{
+		APIRoleNone,
+		APIRoleReadOnly,
+		APIRoleAdmin,
+	}
+}
+
+var _APIRoleMap = map[APIRole]string{
+	APIRoleNone:     _APIRoleName[0:4],
+	APIRoleReadOnly: _APIRoleName[4:12],
+	APIRoleAdmin:    _APIRoleName[12:17],
+}
+
+// String implements the Stringer interface.
+func (x APIRole) String() string {
+	if str, ok := _APIRoleMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("APIRole(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x APIRole) IsValid() bool {
+	_, ok := _APIRoleMap[x]
+	return ok
+}
+
+var _APIRoleValue = map[string]APIRole{
+	_APIRoleName[0:4]:   APIRoleNone,
+	_APIRoleName[4:12]:  APIRoleReadOnly,
+	_APIRoleName[12:17]: APIRoleAdmin,
+}
+
+// ParseAPIRole attempts to convert a string to a APIRole.
+func ParseAPIRole(name string) (APIRole, error) {
+	if x, ok := _APIRoleValue[name]; ok {
+		return x, nil
+	}
+	return APIRole(0), fmt.Errorf("%s is %w", name, ErrInvalidAPIRole)
+}
+
+// MarshalText implements the text marshaller method.
+func (x APIRole) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *APIRole) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseAPIRole(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// CacheEvictionPolicyLru is a CacheEvictionPolicy of type Lru.
+	// remove the least recently used entry
+	CacheEvictionPolicyLru CacheEvictionPolicy = iota
+	// CacheEvictionPolicyTinylfu is a CacheEvictionPolicy of type Tinylfu.
+	// remove the least recently used entry, unless the new entry is requested less often
+	CacheEvictionPolicyTinylfu
+)
+
+var ErrInvalidCacheEvictionPolicy = fmt.Errorf("not a valid CacheEvictionPolicy, try [%s]", strings.Join(_CacheEvictionPolicyNames, ", "))
+
+const _CacheEvictionPolicyName = "lrutinylfu"
+
+var _CacheEvictionPolicyNames = []string{
+	_CacheEvictionPolicyName[0:3],
+	_CacheEvictionPolicyName[3:10],
+}
+
+// CacheEvictionPolicyNames returns a list of possible string values of CacheEvictionPolicy.
+func CacheEvictionPolicyNames() []string {
+	tmp := make([]string, len(_CacheEvictionPolicyNames))
+	copy(tmp, _CacheEvictionPolicyNames)
+	return tmp
+}
+
+// CacheEvictionPolicyValues returns a list of the values for CacheEvictionPolicy
+func CacheEvictionPolicyValues() []CacheEvictionPolicy {
+	return []CacheEvictionPolicy{
+		CacheEvictionPolicyLru,
+		CacheEvictionPolicyTinylfu,
+	}
+}
+
+var _CacheEvictionPolicyMap = map[CacheEvictionPolicy]string{
+	CacheEvictionPolicyLru:     _CacheEvictionPolicyName[0:3],
+	CacheEvictionPolicyTinylfu: _CacheEvictionPolicyName[3:10],
+}
+
+// String implements the Stringer interface.
+func (x CacheEvictionPolicy) String() string {
+	if str, ok := _CacheEvictionPolicyMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("CacheEvictionPolicy(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x CacheEvictionPolicy) IsValid() bool {
+	_, ok := _CacheEvictionPolicyMap[x]
+	return ok
+}
+
+var _CacheEvictionPolicyValue = map[string]CacheEvictionPolicy{
+	_CacheEvictionPolicyName[0:3]:  CacheEvictionPolicyLru,
+	_CacheEvictionPolicyName[3:10]: CacheEvictionPolicyTinylfu,
+}
+
+// ParseCacheEvictionPolicy attempts to convert a string to a CacheEvictionPolicy.
+func ParseCacheEvictionPolicy(name string) (CacheEvictionPolicy, error) {
+	if x, ok := _CacheEvictionPolicyValue[name]; ok {
+		return x, nil
+	}
+	return CacheEvictionPolicy(0), fmt.Errorf("%s is %w", name, ErrInvalidCacheEvictionPolicy)
+}
+
+// MarshalText implements the text marshaller method.
+func (x CacheEvictionPolicy) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *CacheEvictionPolicy) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseCacheEvictionPolicy(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// CompatibilityActionForward is a CompatibilityAction of type Forward.
+	// forward the query verbatim
+	CompatibilityActionForward CompatibilityAction = iota
+	// CompatibilityActionStrip is a CompatibilityAction of type Strip.
+	// remove the unusual part and resolve the rest
+	CompatibilityActionStrip
+	// CompatibilityActionRefuse is a CompatibilityAction of type Refuse.
+	// answer without resolving
+	CompatibilityActionRefuse
+)
+
+var ErrInvalidCompatibilityAction = fmt.Errorf("not a valid CompatibilityAction, try [%s]", strings.Join(_CompatibilityActionNames, ", "))
+
+const _CompatibilityActionName = "forwardstriprefuse"
+
+var _CompatibilityActionNames = []string{
+	_CompatibilityActionName[0:7],
+	_CompatibilityActionName[7:12],
+	_CompatibilityActionName[12:18],
+}
+
+// CompatibilityActionNames returns a list of possible string values of CompatibilityAction.
+func CompatibilityActionNames() []string {
+	tmp := make([]string, len(_CompatibilityActionNames))
+	copy(tmp, _CompatibilityActionNames)
+	return tmp
+}
+
+// CompatibilityActionValues returns a list of the values for CompatibilityAction
+func CompatibilityActionValues() []CompatibilityAction {
+	return []CompatibilityAction{
+		CompatibilityActionForward,
+		CompatibilityActionStrip,
+		CompatibilityActionRefuse,
+	}
+}
+
+var _CompatibilityActionMap = map[CompatibilityAction]string{
+	CompatibilityActionForward: _CompatibilityActionName[0:7],
+	CompatibilityActionStrip:   _CompatibilityActionName[7:12],
+	CompatibilityActionRefuse:  _CompatibilityActionName[12:18],
+}
+
+// String implements the Stringer interface.
+func (x CompatibilityAction) String() string {
+	if str, ok := _CompatibilityActionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("CompatibilityAction(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x CompatibilityAction) IsValid() bool {
+	_, ok := _CompatibilityActionMap[x]
+	return ok
+}
+
+var _CompatibilityActionValue = map[string]CompatibilityAction{
+	_CompatibilityActionName[0:7]:   CompatibilityActionForward,
+	_CompatibilityActionName[7:12]:  CompatibilityActionStrip,
+	_CompatibilityActionName[12:18]: CompatibilityActionRefuse,
+}
+
+// ParseCompatibilityAction attempts to convert a string to a CompatibilityAction.
+func ParseCompatibilityAction(name string) (CompatibilityAction, error) {
+	if x, ok := _CompatibilityActionValue[name]; ok {
+		return x, nil
+	}
+	return CompatibilityAction(0), fmt.Errorf("%s is %w", name, ErrInvalidCompatibilityAction)
+}
+
+// MarshalText implements the text marshaller method.
+func (x CompatibilityAction) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *CompatibilityAction) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseCompatibilityAction(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// DHCPLeaseFormatDnsmasq is a DHCPLeaseFormat of type Dnsmasq.
+	// dnsmasq lease file
+	DHCPLeaseFormatDnsmasq DHCPLeaseFormat = iota
+	// DHCPLeaseFormatIsc is a DHCPLeaseFormat of type Isc.
+	// ISC DHCP dhcpd.leases file
+	DHCPLeaseFormatIsc
+	// DHCPLeaseFormatKea is a DHCPLeaseFormat of type Kea.
+	// Kea memfile CSV lease file
+	DHCPLeaseFormatKea
+	// DHCPLeaseFormatKeaApi is a DHCPLeaseFormat of type Kea-Api.
+	// Kea control agent API
+	DHCPLeaseFormatKeaApi
+)
+
+var ErrInvalidDHCPLeaseFormat = fmt.Errorf("not a valid DHCPLeaseFormat, try [%s]", strings.Join(_DHCPLeaseFormatNames, ", "))
+
+const _DHCPLeaseFormatName = "dnsmasqisckeakea-api"
+
+var _DHCPLeaseFormatNames = []string{
+	_DHCPLeaseFormatName[0:7],
+	_DHCPLeaseFormatName[7:10],
+	_DHCPLeaseFormatName[10:13],
+	_DHCPLeaseFormatName[13:20],
+}
+
+// DHCPLeaseFormatNames returns a list of possible string values of DHCPLeaseFormat.
+func DHCPLeaseFormatNames() []string {
+	tmp := make([]string, len(_DHCPLeaseFormatNames))
+	copy(tmp, _DHCPLeaseFormatNames)
+	return tmp
+}
+
+// DHCPLeaseFormatValues returns a list of the values for DHCPLeaseFormat
+func DHCPLeaseFormatValues() []DHCPLeaseFormat {
+	return []DHCPLeaseFormat{
+		DHCPLeaseFormatDnsmasq,
+		DHCPLeaseFormatIsc,
+		DHCPLeaseFormatKea,
+		DHCPLeaseFormatKeaApi,
+	}
+}
+
+var _DHCPLeaseFormatMap = map[DHCPLeaseFormat]string{
+	DHCPLeaseFormatDnsmasq: _DHCPLeaseFormatName[0:7],
+	DHCPLeaseFormatIsc:     _DHCPLeaseFormatName[7:10],
+	DHCPLeaseFormatKea:     _DHCPLeaseFormatName[10:13],
+	DHCPLeaseFormatKeaApi:  _DHCPLeaseFormatName[13:20],
+}
+
+// String implements the Stringer interface.
+func (x DHCPLeaseFormat) String() string {
+	if str, ok := _DHCPLeaseFormatMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("DHCPLeaseFormat(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x DHCPLeaseFormat) IsValid() bool {
+	_, ok := _DHCPLeaseFormatMap[x]
+	return ok
+}
+
+var _DHCPLeaseFormatValue = map[string]DHCPLeaseFormat{
+	_DHCPLeaseFormatName[0:7]:   DHCPLeaseFormatDnsmasq,
+	_DHCPLeaseFormatName[7:10]:  DHCPLeaseFormatIsc,
+	_DHCPLeaseFormatName[10:13]: DHCPLeaseFormatKea,
+	_DHCPLeaseFormatName[13:20]: DHCPLeaseFormatKeaApi,
+}
+
+// ParseDHCPLeaseFormat attempts to convert a string to a DHCPLeaseFormat.
+func ParseDHCPLeaseFormat(name string) (DHCPLeaseFormat, error) {
+	if x, ok := _DHCPLeaseFormatValue[name]; ok {
+		return x, nil
+	}
+	return DHCPLeaseFormat(0), fmt.Errorf("%s is %w", name, ErrInvalidDHCPLeaseFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x DHCPLeaseFormat) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *DHCPLeaseFormat) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseDHCPLeaseFormat(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// EventTypeQueryBlocked is a EventType of type queryBlocked.
+	EventTypeQueryBlocked EventType = "queryBlocked"
+	// EventTypeListRefreshed is a EventType of type listRefreshed.
+	EventTypeListRefreshed EventType = "listRefreshed"
+	// EventTypeListDownloadFailed is a EventType of type listDownloadFailed.
+	EventTypeListDownloadFailed EventType = "listDownloadFailed"
+	// EventTypeUpstreamHealthChanged is a EventType of type upstreamHealthChanged.
+	EventTypeUpstreamHealthChanged EventType = "upstreamHealthChanged"
+	// EventTypeBlockingChanged is a EventType of type blockingChanged.
+	EventTypeBlockingChanged EventType = "blockingChanged"
+)
+
+var ErrInvalidEventType = fmt.Errorf("not a valid EventType, try [%s]", strings.Join(_EventTypeNames, ", "))
+
+var _EventTypeNames = []string{
+	string(EventTypeQueryBlocked),
+	string(EventTypeListRefreshed),
+	string(EventTypeListDownloadFailed),
+	string(EventTypeUpstreamHealthChanged),
+	string(EventTypeBlockingChanged),
+}
+
+// EventTypeNames returns a list of possible string values of EventType.
+func EventTypeNames() []string {
+	tmp := make([]string, len(_EventTypeNames))
+	copy(tmp, _EventTypeNames)
+	return tmp
+}
+
+// EventTypeValues returns a list of the values for EventType
+func EventTypeValues() []EventType {
+	return []EventType{
+		EventTypeQueryBlocked,
+		EventTypeListRefreshed,
+		EventTypeListDownloadFailed,
+		EventTypeUpstreamHealthChanged,
+		EventTypeBlockingChanged,
+	}
+}
+
+// String implements the Stringer interface.
+func (x EventType) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x EventType) IsValid() bool {
+	_, err := ParseEventType(string(x))
+	return err == nil
+}
+
+var _EventTypeValue = map[string]EventType{
+	"queryBlocked":          EventTypeQueryBlocked,
+	"listRefreshed":         EventTypeListRefreshed,
+	"listDownloadFailed":    EventTypeListDownloadFailed,
+	"upstreamHealthChanged": EventTypeUpstreamHealthChanged,
+	"blockingChanged":       EventTypeBlockingChanged,
+}
+
+// ParseEventType attempts to convert a string to a EventType.
+func ParseEventType(name string) (EventType, error) {
+	if x, ok := _EventTypeValue[name]; ok {
+		return x, nil
+	}
+	return EventType(""), fmt.Errorf("%s is %w", name, ErrInvalidEventType)
+}
+
+// MarshalText implements the text marshaller method.
+func (x EventType) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *EventType) UnmarshalText(text []byte) error {
+	tmp, err := ParseEventType(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// EventsTypeNone is a EventsType of type None.
+	// no publishing
+	EventsTypeNone EventsType = iota
+	// EventsTypeMqtt is a EventsType of type Mqtt.
+	// MQTT broker
+	EventsTypeMqtt
+	// EventsTypeKafkaRest is a EventsType of type KafkaRest.
+	// Kafka REST proxy, not a Kafka broker
+	EventsTypeKafkaRest
+)
+
+var ErrInvalidEventsType = fmt.Errorf("not a valid EventsType, try [%s]", strings.Join(_EventsTypeNames, ", "))
+
+const _EventsTypeName = "nonemqttkafkaRest"
+
+var _EventsTypeNames = []string{
+	_EventsTypeName[0:4],
+	_EventsTypeName[4:8],
+	_EventsTypeName[8:17],
+}
+
+// EventsTypeNames returns a list of possible string values of EventsType.
+func EventsTypeNames() []string {
+	tmp := make([]string, len(_EventsTypeNames))
+	copy(tmp, _EventsTypeNames)
+	return tmp
+}
+
+// EventsTypeValues returns a list of the values for EventsType
+func EventsTypeValues() []EventsType {
+	return []EventsType{
+		EventsTypeNone,
+		EventsTypeMqtt,
+		EventsTypeKafkaRest,
+	}
+}
+
+var _EventsTypeMap = map[EventsType]string{
+	EventsTypeNone:      _EventsTypeName[0:4],
+	EventsTypeMqtt:      _EventsTypeName[4:8],
+	EventsTypeKafkaRest: _EventsTypeName[8:17],
+}
+
+// String implements the Stringer interface.
+func (x EventsType) String() string {
+	if str, ok := _EventsTypeMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("EventsType(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x EventsType) IsValid() bool {
+	_, ok := _EventsTypeMap[x]
+	return ok
+}
+
+var _EventsTypeValue = map[string]EventsType{
+	_EventsTypeName[0:4]:  EventsTypeNone,
+	_EventsTypeName[4:8]:  EventsTypeMqtt,
+	_EventsTypeName[8:17]: EventsTypeKafkaRest,
+}
+
+// ParseEventsType attempts to convert a string to a EventsType.
+func ParseEventsType(name string) (EventsType, error) {
+	if x, ok := _EventsTypeValue[name]; ok {
+		return x, nil
+	}
+	return EventsType(0), fmt.Errorf("%s is %w", name, ErrInvalidEventsType)
+}
+
+// MarshalText implements the text marshaller method.
+func (x EventsType) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *EventsType) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseEventsType(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
 const (
 	// IPVersionDual is a IPVersion of type Dual.
 	// IPv4 and IPv6
@@ -23,76 +716,627 @@ const (
 	IPVersionV6
 )
 
-var ErrInvalidIPVersion = fmt.Errorf("not a valid IPVersion, try [%s]", strings.Join(_IPVersionNames, ", "))
+var ErrInvalidIPVersion = fmt.Errorf("not a valid IPVersion, try [%s]", strings.Join(_IPVersionNames, ", "))
+
+const _IPVersionName = "dualv4v6"
+
+var _IPVersionNames = []string{
+	_IPVersionName[0:4],
+	_IPVersionName[4:6],
+	_IPVersionName[6:8],
+}
+
+// IPVersionNames returns a list of possible string values of IPVersion.
+func IPVersionNames() []string {
+	tmp := make([]string, len(_IPVersionNames))
+	copy(tmp, _IPVersionNames)
+	return tmp
+}
+
+// IPVersionValues returns a list of the values for IPVersion
+func IPVersionValues() []IPVersion {
+	return []IPVersion{
+		IPVersionDual,
+		IPVersionV4,
+		IPVersionV6,
+	}
+}
+
+var _IPVersionMap = map[IPVersion]string{
+	IPVersionDual: _IPVersionName[0:4],
+	IPVersionV4:   _IPVersionName[4:6],
+	IPVersionV6:   _IPVersionName[6:8],
+}
+
+// String implements the Stringer interface.
+func (x IPVersion) String() string {
+	if str, ok := _IPVersionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("IPVersion(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x IPVersion) IsValid() bool {
+	_, ok := _IPVersionMap[x]
+	return ok
+}
+
+var _IPVersionValue = map[string]IPVersion{
+	_IPVersionName[0:4]: IPVersionDual,
+	_IPVersionName[4:6]: IPVersionV4,
+	_IPVersionName[6:8]: IPVersionV6,
+}
+
+// ParseIPVersion attempts to convert a string to a IPVersion.
+func ParseIPVersion(name string) (IPVersion, error) {
+	if x, ok := _IPVersionValue[name]; ok {
+		return x, nil
+	}
+	return IPVersion(0), fmt.Errorf("%s is %w", name, ErrInvalidIPVersion)
+}
+
+// MarshalText implements the text marshaller method.
+func (x IPVersion) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *IPVersion) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseIPVersion(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// InitStrategyBlocking is a InitStrategy of type Blocking.
+	// synchronously download blocking lists on startup
+	InitStrategyBlocking InitStrategy = iota
+	// InitStrategyFailOnError is a InitStrategy of type FailOnError.
+	// synchronously download blocking lists on startup and shutdown on error
+	InitStrategyFailOnError
+	// InitStrategyFast is a InitStrategy of type Fast.
+	// asyncronously download blocking lists on startup
+	InitStrategyFast
+)
+
+var ErrInvalidInitStrategy = fmt.Errorf("not a valid InitStrategy, try [%s]", strings.Join(_InitStrategyNames, ", "))
+
+const _InitStrategyName = "blockingfailOnErrorfast"
+
+var _InitStrategyNames = []string{
+	_InitStrategyName[0:8],
+	_InitStrategyName[8:19],
+	_InitStrategyName[19:23],
+}
+
+// InitStrategyNames returns a list of possible string values of InitStrategy.
+func InitStrategyNames() []string {
+	tmp := make([]string, len(_InitStrategyNames))
+	copy(tmp, _InitStrategyNames)
+	return tmp
+}
+
+// InitStrategyValues returns a list of the values for InitStrategy
+func InitStrategyValues() []InitStrategy {
+	return []InitStrategy{
+		InitStrategyBlocking,
+		InitStrategyFailOnError,
+		InitStrategyFast,
+	}
+}
+
+var _InitStrategyMap = map[InitStrategy]string{
+	InitStrategyBlocking:    _InitStrategyName[0:8],
+	InitStrategyFailOnError: _InitStrategyName[8:19],
+	InitStrategyFast:        _InitStrategyName[19:23],
+}
+
+// String implements the Stringer interface.
+func (x InitStrategy) String() string {
+	if str, ok := _InitStrategyMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("InitStrategy(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x InitStrategy) IsValid() bool {
+	_, ok := _InitStrategyMap[x]
+	return ok
+}
+
+var _InitStrategyValue = map[string]InitStrategy{
+	_InitStrategyName[0:8]:   InitStrategyBlocking,
+	_InitStrategyName[8:19]:  InitStrategyFailOnError,
+	_InitStrategyName[19:23]: InitStrategyFast,
+}
+
+// ParseInitStrategy attempts to convert a string to a InitStrategy.
+func ParseInitStrategy(name string) (InitStrategy, error) {
+	if x, ok := _InitStrategyValue[name]; ok {
+		return x, nil
+	}
+	return InitStrategy(0), fmt.Errorf("%s is %w", name, ErrInvalidInitStrategy)
+}
+
+// MarshalText implements the text marshaller method.
+func (x InitStrategy) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *InitStrategy) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseInitStrategy(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// NetProtocolTcpUdp is a NetProtocol of type Tcp+Udp.
+	// TCP and UDP protocols
+	NetProtocolTcpUdp NetProtocol = iota
+	// NetProtocolTcpTls is a NetProtocol of type Tcp-Tls.
+	// TCP-TLS protocol
+	NetProtocolTcpTls
+	// NetProtocolHttps is a NetProtocol of type Https.
+	// HTTPS protocol
+	NetProtocolHttps
+	// NetProtocolUnix is a NetProtocol of type Unix.
+	// Unix domain socket, DNS over stream framing
+	NetProtocolUnix
+)
+
+var ErrInvalidNetProtocol = fmt.Errorf("not a valid NetProtocol, try [%s]", strings.Join(_NetProtocolNames, ", "))
+
+const _NetProtocolName = "tcp+udptcp-tlshttpsunix"
+
+var _NetProtocolNames = []string{
+	_NetProtocolName[0:7],
+	_NetProtocolName[7:14],
+	_NetProtocolName[14:19],
+	_NetProtocolName[19:23],
+}
+
+// NetProtocolNames returns a list of possible string values of NetProtocol.
+func NetProtocolNames() []string {
+	tmp := make([]string, len(_NetProtocolNames))
+	copy(tmp, _NetProtocolNames)
+	return tmp
+}
+
+// NetProtocolValues returns a list of the values for NetProtocol
+func NetProtocolValues() []NetProtocol {
+	return []NetProtocol{
+		NetProtocolTcpUdp,
+		NetProtocolTcpTls,
+		NetProtocolHttps,
+		NetProtocolUnix,
+	}
+}
+
+var _NetProtocolMap = map[NetProtocol]string{
+	NetProtocolTcpUdp: _NetProtocolName[0:7],
+	NetProtocolTcpTls: _NetProtocolName[7:14],
+	NetProtocolHttps:  _NetProtocolName[14:19],
+	NetProtocolUnix:   _NetProtocolName[19:23],
+}
+
+// String implements the Stringer interface.
+func (x NetProtocol) String() string {
+	if str, ok := _NetProtocolMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("NetProtocol(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x NetProtocol) IsValid() bool {
+	_, ok := _NetProtocolMap[x]
+	return ok
+}
+
+var _NetProtocolValue = map[string]NetProtocol{
+	_NetProtocolName[0:7]:   NetProtocolTcpUdp,
+	_NetProtocolName[7:14]:  NetProtocolTcpTls,
+	_NetProtocolName[14:19]: NetProtocolHttps,
+	_NetProtocolName[19:23]: NetProtocolUnix,
+}
+
+// ParseNetProtocol attempts to convert a string to a NetProtocol.
+func ParseNetProtocol(name string) (NetProtocol, error) {
+	if x, ok := _NetProtocolValue[name]; ok {
+		return x, nil
+	}
+	return NetProtocol(0), fmt.Errorf("%s is %w", name, ErrInvalidNetProtocol)
+}
+
+// MarshalText implements the text marshaller method.
+func (x NetProtocol) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *NetProtocol) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseNetProtocol(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// NewDomainActionLog is a NewDomainAction of type Log.
+	// log the domain and resolve the query
+	NewDomainActionLog NewDomainAction = iota
+	// NewDomainActionBlock is a NewDomainAction of type Block.
+	// answer with NXDOMAIN
+	NewDomainActionBlock
+)
+
+var ErrInvalidNewDomainAction = fmt.Errorf("not a valid NewDomainAction, try [%s]", strings.Join(_NewDomainActionNames, ", "))
+
+const _NewDomainActionName = "logblock"
+
+var _NewDomainActionNames = []string{
+	_NewDomainActionName[0:3],
+	_NewDomainActionName[3:8],
+}
+
+// NewDomainActionNames returns a list of possible string values of NewDomainAction.
+func NewDomainActionNames() []string {
+	tmp := make([]string, len(_NewDomainActionNames))
+	copy(tmp, _NewDomainActionNames)
+	return tmp
+}
+
+// NewDomainActionValues returns a list of the values for NewDomainAction
+func NewDomainActionValues() []NewDomainAction {
+	return []NewDomainAction{
+		NewDomainActionLog,
+		NewDomainActionBlock,
+	}
+}
+
+var _NewDomainActionMap = map[NewDomainAction]string{
+	NewDomainActionLog:   _NewDomainActionName[0:3],
+	NewDomainActionBlock: _NewDomainActionName[3:8],
+}
+
+// String implements the Stringer interface.
+func (x NewDomainAction) String() string {
+	if str, ok := _NewDomainActionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("NewDomainAction(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x NewDomainAction) IsValid() bool {
+	_, ok := _NewDomainActionMap[x]
+	return ok
+}
+
+var _NewDomainActionValue = map[string]NewDomainAction{
+	_NewDomainActionName[0:3]: NewDomainActionLog,
+	_NewDomainActionName[3:8]: NewDomainActionBlock,
+}
+
+// ParseNewDomainAction attempts to convert a string to a NewDomainAction.
+func ParseNewDomainAction(name string) (NewDomainAction, error) {
+	if x, ok := _NewDomainActionValue[name]; ok {
+		return x, nil
+	}
+	return NewDomainAction(0), fmt.Errorf("%s is %w", name, ErrInvalidNewDomainAction)
+}
+
+// MarshalText implements the text marshaller method.
+func (x NewDomainAction) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *NewDomainAction) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseNewDomainAction(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// ProxyProtocolListenerDns is a ProxyProtocolListener of type Dns.
+	// DNS over TCP
+	ProxyProtocolListenerDns ProxyProtocolListener = iota
+	// ProxyProtocolListenerTls is a ProxyProtocolListener of type Tls.
+	// DNS over TLS
+	ProxyProtocolListenerTls
+	// ProxyProtocolListenerHttp is a ProxyProtocolListener of type Http.
+	// HTTP, e.g. DoH without TLS
+	ProxyProtocolListenerHttp
+	// ProxyProtocolListenerHttps is a ProxyProtocolListener of type Https.
+	// HTTPS, e.g. DoH
+	ProxyProtocolListenerHttps
+)
+
+var ErrInvalidProxyProtocolListener = fmt.Errorf("not a valid ProxyProtocolListener, try [%s]", strings.Join(_ProxyProtocolListenerNames, ", "))
+
+const _ProxyProtocolListenerName = "dnstlshttphttps"
+
+var _ProxyProtocolListenerNames = []string{
+	_ProxyProtocolListenerName[0:3],
+	_ProxyProtocolListenerName[3:6],
+	_ProxyProtocolListenerName[6:10],
+	_ProxyProtocolListenerName[10:15],
+}
+
+// ProxyProtocolListenerNames returns a list of possible string values of ProxyProtocolListener.
+func ProxyProtocolListenerNames() []string {
+	tmp := make([]string, len(_ProxyProtocolListenerNames))
+	copy(tmp, _ProxyProtocolListenerNames)
+	return tmp
+}
+
+// ProxyProtocolListenerValues returns a list of the values for ProxyProtocolListener
+func ProxyProtocolListenerValues() []ProxyProtocolListener {
+	return []ProxyProtocolListener{
+		ProxyProtocolListenerDns,
+		ProxyProtocolListenerTls,
+		ProxyProtocolListenerHttp,
+		ProxyProtocolListenerHttps,
+	}
+}
+
+var _ProxyProtocolListenerMap = map[ProxyProtocolListener]string{
+	ProxyProtocolListenerDns:   _ProxyProtocolListenerName[0:3],
+	ProxyProtocolListenerTls:   _ProxyProtocolListenerName[3:6],
+	ProxyProtocolListenerHttp:  _ProxyProtocolListenerName[6:10],
+	ProxyProtocolListenerHttps: _ProxyProtocolListenerName[10:15],
+}
+
+// String implements the Stringer interface.
+func (x ProxyProtocolListener) String() string {
+	if str, ok := _ProxyProtocolListenerMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("ProxyProtocolListener(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x ProxyProtocolListener) IsValid() bool {
+	_, ok := _ProxyProtocolListenerMap[x]
+	return ok
+}
+
+var _ProxyProtocolListenerValue = map[string]ProxyProtocolListener{
+	_ProxyProtocolListenerName[0:3]:   ProxyProtocolListenerDns,
+	_ProxyProtocolListenerName[3:6]:   ProxyProtocolListenerTls,
+	_ProxyProtocolListenerName[6:10]:  ProxyProtocolListenerHttp,
+	_ProxyProtocolListenerName[10:15]: ProxyProtocolListenerHttps,
+}
+
+// ParseProxyProtocolListener attempts to convert a string to a ProxyProtocolListener.
+func ParseProxyProtocolListener(name string) (ProxyProtocolListener, error) {
+	if x, ok := _ProxyProtocolListenerValue[name]; ok {
+		return x, nil
+	}
+	return ProxyProtocolListener(0), fmt.Errorf("%s is %w", name, ErrInvalidProxyProtocolListener)
+}
+
+// MarshalText implements the text marshaller method.
+func (x ProxyProtocolListener) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *ProxyProtocolListener) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseProxyProtocolListener(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// QueryLogFieldClientIP is a QueryLogField of type clientIP.
+	QueryLogFieldClientIP QueryLogField = "clientIP"
+	// QueryLogFieldClientName is a QueryLogField of type clientName.
+	QueryLogFieldClientName QueryLogField = "clientName"
+	// QueryLogFieldResponseReason is a QueryLogField of type responseReason.
+	QueryLogFieldResponseReason QueryLogField = "responseReason"
+	// QueryLogFieldResponseAnswer is a QueryLogField of type responseAnswer.
+	QueryLogFieldResponseAnswer QueryLogField = "responseAnswer"
+	// QueryLogFieldQuestion is a QueryLogField of type question.
+	QueryLogFieldQuestion QueryLogField = "question"
+	// QueryLogFieldDuration is a QueryLogField of type duration.
+	QueryLogFieldDuration QueryLogField = "duration"
+	// QueryLogFieldAnswerGeo is a QueryLogField of type answerGeo.
+	QueryLogFieldAnswerGeo QueryLogField = "answerGeo"
+	// QueryLogFieldEdns is a QueryLogField of type edns.
+	QueryLogFieldEdns QueryLogField = "edns"
+)
+
+var ErrInvalidQueryLogField = fmt.Errorf("not a valid QueryLogField, try [%s]", strings.Join(_QueryLogFieldNames, ", "))
+
+var _QueryLogFieldNames = []string{
+	string(QueryLogFieldClientIP),
+	string(QueryLogFieldClientName),
+	string(QueryLogFieldResponseReason),
+	string(QueryLogFieldResponseAnswer),
+	string(QueryLogFieldQuestion),
+	string(QueryLogFieldDuration),
+	string(QueryLogFieldAnswerGeo),
+	string(QueryLogFieldEdns),
+}
+
+// QueryLogFieldNames returns a list of possible string values of QueryLogField.
+func QueryLogFieldNames() []string {
+	tmp := make([]string, len(_QueryLogFieldNames))
+	copy(tmp, _QueryLogFieldNames)
+	return tmp
+}
+
+// QueryLogFieldValues returns a list of the values for QueryLogField
+func QueryLogFieldValues() []QueryLogField {
+	return []QueryLogField{
+		QueryLogFieldClientIP,
+		QueryLogFieldClientName,
+		QueryLogFieldResponseReason,
+		QueryLogFieldResponseAnswer,
+		QueryLogFieldQuestion,
+		QueryLogFieldDuration,
+		QueryLogFieldAnswerGeo,
+		QueryLogFieldEdns,
+	}
+}
+
+// String implements the Stringer interface.
+func (x QueryLogField) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x QueryLogField) IsValid() bool {
+	_, err := ParseQueryLogField(string(x))
+	return err == nil
+}
+
+var _QueryLogFieldValue = map[string]QueryLogField{
+	"clientIP":       QueryLogFieldClientIP,
+	"clientName":     QueryLogFieldClientName,
+	"responseReason": QueryLogFieldResponseReason,
+	"responseAnswer": QueryLogFieldResponseAnswer,
+	"question":       QueryLogFieldQuestion,
+	"duration":       QueryLogFieldDuration,
+	"answerGeo":      QueryLogFieldAnswerGeo,
+	"edns":           QueryLogFieldEdns,
+}
+
+// ParseQueryLogField attempts to convert a string to a QueryLogField.
+func ParseQueryLogField(name string) (QueryLogField, error) {
+	if x, ok := _QueryLogFieldValue[name]; ok {
+		return x, nil
+	}
+	return QueryLogField(""), fmt.Errorf("%s is %w", name, ErrInvalidQueryLogField)
+}
+
+// MarshalText implements the text marshaller method.
+func (x QueryLogField) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *QueryLogField) UnmarshalText(text []byte) error {
+	tmp, err := ParseQueryLogField(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// QueryLogIPAnonymizationNone is a QueryLogIPAnonymization of type None.
+	// log the IP as it is
+	QueryLogIPAnonymizationNone QueryLogIPAnonymization = iota
+	// QueryLogIPAnonymizationTruncate is a QueryLogIPAnonymization of type Truncate.
+	// log the network of the IP only
+	QueryLogIPAnonymizationTruncate
+	// QueryLogIPAnonymizationHash is a QueryLogIPAnonymization of type Hash.
+	// log a salted hash of the IP
+	QueryLogIPAnonymizationHash
+)
+
+var ErrInvalidQueryLogIPAnonymization = fmt.Errorf("not a valid QueryLogIPAnonymization, try [%s]", strings.Join(_QueryLogIPAnonymizationNames, ", "))
 
-const _IPVersionName = "dualv4v6"
+const _QueryLogIPAnonymizationName = "nonetruncatehash"
 
-var _IPVersionNames = []string{
-	_IPVersionName[0:4],
-	_IPVersionName[4:6],
-	_IPVersionName[6:8],
+var _QueryLogIPAnonymizationNames = []string{
+	_QueryLogIPAnonymizationName[0:4],
+	_QueryLogIPAnonymizationName[4:12],
+	_QueryLogIPAnonymizationName[12:16],
 }
 
-// IPVersionNames returns a list of possible string values of IPVersion.
-func IPVersionNames() []string {
-	tmp := make([]string, len(_IPVersionNames))
-	copy(tmp, _IPVersionNames)
+// QueryLogIPAnonymizationNames returns a list of possible string values of QueryLogIPAnonymization.
+func QueryLogIPAnonymizationNames() []string {
+	tmp := make([]string, len(_QueryLogIPAnonymizationNames))
+	copy(tmp, _QueryLogIPAnonymizationNames)
 	return tmp
 }
 
-// IPVersionValues returns a list of the values for IPVersion
-func IPVersionValues() []IPVersion {
-	return []IPVersion{
-		IPVersionDual,
-		IPVersionV4,
-		IPVersionV6,
+// QueryLogIPAnonymizationValues returns a list of the values for QueryLogIPAnonymization
+func QueryLogIPAnonymizationValues() []QueryLogIPAnonymization {
+	return []QueryLogIPAnonymization{
+		QueryLogIPAnonymizationNone,
+		QueryLogIPAnonymizationTruncate,
+		QueryLogIPAnonymizationHash,
 	}
 }
 
-var _IPVersionMap = map[IPVersion]string{
-	IPVersionDual: _IPVersionName[0:4],
-	IPVersionV4:   _IPVersionName[4:6],
-	IPVersionV6:   _IPVersionName[6:8],
+var _QueryLogIPAnonymizationMap = map[QueryLogIPAnonymization]string{
+	QueryLogIPAnonymizationNone:     _QueryLogIPAnonymizationName[0:4],
+	QueryLogIPAnonymizationTruncate: _QueryLogIPAnonymizationName[4:12],
+	QueryLogIPAnonymizationHash:     _QueryLogIPAnonymizationName[12:16],
 }
 
 // String implements the Stringer interface.
-func (x IPVersion) String() string {
-	if str, ok := _IPVersionMap[x]; ok {
+func (x QueryLogIPAnonymization) String() string {
+	if str, ok := _QueryLogIPAnonymizationMap[x]; ok {
 		return str
 	}
-	return fmt.Sprintf("IPVersion(%d)", x)
+	return fmt.Sprintf("QueryLogIPAnonymization(%d)", x)
 }
 
 // IsValid provides a quick way to determine if the typed value is
 // part of the allowed enumerated values
-func (x IPVersion) IsValid() bool {
-	_, ok := _IPVersionMap[x]
+func (x QueryLogIPAnonymization) IsValid() bool {
+	_, ok := _QueryLogIPAnonymizationMap[x]
 	return ok
 }
 
-var _IPVersionValue = map[string]IPVersion{
-	_IPVersionName[0:4]: IPVersionDual,
-	_IPVersionName[4:6]: IPVersionV4,
-	_IPVersionName[6:8]: IPVersionV6,
+var _QueryLogIPAnonymizationValue = map[string]QueryLogIPAnonymization{
+	_QueryLogIPAnonymizationName[0:4]:   QueryLogIPAnonymizationNone,
+	_QueryLogIPAnonymizationName[4:12]:  QueryLogIPAnonymizationTruncate,
+	_QueryLogIPAnonymizationName[12:16]: QueryLogIPAnonymizationHash,
 }
 
-// ParseIPVersion attempts to convert a string to a IPVersion.
-func ParseIPVersion(name string) (IPVersion, error) {
-	if x, ok := _IPVersionValue[name]; ok {
+// ParseQueryLogIPAnonymization attempts to convert a string to a QueryLogIPAnonymization.
+func ParseQueryLogIPAnonymization(name string) (QueryLogIPAnonymization, error) {
+	if x, ok := _QueryLogIPAnonymizationValue[name]; ok {
 		return x, nil
 	}
-	return IPVersion(0), fmt.Errorf("%s is %w", name, ErrInvalidIPVersion)
+	return QueryLogIPAnonymization(0), fmt.Errorf("%s is %w", name, ErrInvalidQueryLogIPAnonymization)
 }
 
 // MarshalText implements the text marshaller method.
-func (x IPVersion) MarshalText() ([]byte, error) {
+func (x QueryLogIPAnonymization) MarshalText() ([]byte, error) {
 	return []byte(x.String()), nil
 }
 
 // UnmarshalText implements the text unmarshaller method.
-func (x *IPVersion) UnmarshalText(text []byte) error {
+func (x *QueryLogIPAnonymization) UnmarshalText(text []byte) error {
 	name := string(text)
-	tmp, err := ParseIPVersion(name)
+	tmp, err := ParseQueryLogIPAnonymization(name)
 	if err != nil {
 		return err
 	}
@@ -101,87 +1345,78 @@ func (x *IPVersion) UnmarshalText(text []byte) error {
 }
 
 const (
-	// InitStrategyBlocking is a InitStrategy of type Blocking.
-	// synchronously download blocking lists on startup
-	InitStrategyBlocking InitStrategy = iota
-	// InitStrategyFailOnError is a InitStrategy of type FailOnError.
-	// synchronously download blocking lists on startup and shutdown on error
-	InitStrategyFailOnError
-	// InitStrategyFast is a InitStrategy of type Fast.
-	// asyncronously download blocking lists on startup
-	InitStrategyFast
+	// QueryLogRotationHourly is a QueryLogRotation of type Hourly.
+	QueryLogRotationHourly QueryLogRotation = iota
+	// QueryLogRotationDaily is a QueryLogRotation of type Daily.
+	QueryLogRotationDaily
 )
 
-var ErrInvalidInitStrategy = fmt.Errorf("not a valid InitStrategy, try [%s]", strings.Join(_InitStrategyNames, ", "))
+var ErrInvalidQueryLogRotation = fmt.Errorf("not a valid QueryLogRotation, try [%s]", strings.Join(_QueryLogRotationNames, ", "))
 
-const _InitStrategyName = "blockingfailOnErrorfast"
+const _QueryLogRotationName = "hourlydaily"
 
-var _InitStrategyNames = []string{
-	_InitStrategyName[0:8],
-	_InitStrategyName[8:19],
-	_InitStrategyName[19:23],
+var _QueryLogRotationNames = []string{
+	_QueryLogRotationName[0:6],
+	_QueryLogRotationName[6:11],
 }
 
-// InitStrategyNames returns a list of possible string values of InitStrategy.
-func InitStrategyNames() []string {
-	tmp := make([]string, len(_InitStrategyNames))
-	copy(tmp, _InitStrategyNames)
+// QueryLogRotationNames returns a list of possible string values of QueryLogRotation.
+func QueryLogRotationNames() []string {
+	tmp := make([]string, len(_QueryLogRotationNames))
+	copy(tmp, _QueryLogRotationNames)
 	return tmp
 }
 
-// InitStrategyValues returns a list of the values for InitStrategy
-func InitStrategyValues() []InitStrategy {
-	return []InitStrategy{
-		InitStrategyBlocking,
-		InitStrategyFailOnError,
-		InitStrategyFast,
+// QueryLogRotationValues returns a list of the values for QueryLogRotation
+func QueryLogRotationValues() []QueryLogRotation {
+	return []QueryLogRotation{
+		QueryLogRotationHourly,
+		QueryLogRotationDaily,
 	}
 }
 
-var _InitStrategyMap = map[InitStrategy]string{
-	InitStrategyBlocking:    _InitStrategyName[0:8],
-	InitStrategyFailOnError: _InitStrategyName[8:19],
-	InitStrategyFast:        _InitStrategyName[19:23],
+var _QueryLogRotationMap = map[QueryLogRotation]string{
+	QueryLogRotationHourly: _QueryLogRotationName[0:6],
+	QueryLogRotationDaily:  _QueryLogRotationName[6:11],
 }
 
 // String implements the Stringer interface.
-func (x InitStrategy) String() string {
-	if str, ok := _InitStrategyMap[x]; ok {
+func (x QueryLogRotation) String() string {
+	if str, ok := _QueryLogRotationMap[x]; ok {
 		return str
 	}
-	return fmt.Sprintf("InitStrategy(%d)", x)
+	return fmt.Sprintf("QueryLogRotation(%d)", x)
 }
 
 // IsValid provides a quick way to determine if the typed value is
 // part of the allowed enumerated values
-func (x InitStrategy) IsValid() bool {
-	_, ok := _InitStrategyMap[x]
+func (x QueryLogRotation) IsValid() bool {
+	_, ok := _QueryLogRotationMap[x]
 	return ok
 }
 
-var _InitStrategyValue = map[string]InitStrategy{
-	_InitStrategyName[0:8]:   InitStrategyBlocking,
-	_InitStrategyName[8:19]:  InitStrategyFailOnError,
-	_InitStrategyName[19:23]: InitStrategyFast,
+var _QueryLogRotationValue = map[string]QueryLogRotation{
+	_QueryLogRotationName[0:6]:  QueryLogRotationHourly,
+	_QueryLogRotationName[6:11]: QueryLogRotationDaily,
 }
 
-// ParseInitStrategy attempts to convert a string to a InitStrategy.
-func ParseInitStrategy(name string) (InitStrategy, error) {
-	if x, ok := _InitStrategyValue[name]; ok {
+// ParseQueryLogRotation attempts to convert a string to a QueryLogRotation.
+func ParseQueryLogRotation(name string) (QueryLogRotation, error) {
+	if x, ok := _QueryLogRotationValue[name]; ok {
 		return x, nil
 	}
-	return InitStrategy(0), fmt.Errorf("%s is %w", name, ErrInvalidInitStrategy)
+	return QueryLogRotation(0), fmt.Errorf("%s is %w", name, ErrInvalidQueryLogRotation)
 }
 
 // MarshalText implements the text marshaller method.
-func (x InitStrategy) MarshalText() ([]byte, error) {
+func (x QueryLogRotation) MarshalText() ([]byte, error) {
 	return []byte(x.String()), nil
 }
 
 // UnmarshalText implements the text unmarshaller method.
-func (x *InitStrategy) UnmarshalText(text []byte) error {
+func (x *QueryLogRotation) UnmarshalText(text []byte) error {
 	name := string(text)
-	tmp, err := ParseInitStrategy(name)
+	tmp, err := ParseQueryLogRotation(name)
 	if err != nil {
 		return err
 	}
@@ -190,87 +1425,122 @@ func (x *InitStrategy) UnmarshalText(text []byte) error {
 }
 
 const (
-	// NetProtocolTcpUdp is a NetProtocol of type Tcp+Udp.
-	// TCP and UDP protocols
-	NetProtocolTcpUdp NetProtocol = iota
-	// NetProtocolTcpTls is a NetProtocol of type Tcp-Tls.
-	// TCP-TLS protocol
-	NetProtocolTcpTls
-	// NetProtocolHttps is a NetProtocol of type Https.
-	// HTTPS protocol
-	NetProtocolHttps
+	// QueryLogTypeConsole is a QueryLogType of type Console.
+	// use logger as fallback
+	QueryLogTypeConsole QueryLogType = iota
+	// QueryLogTypeNone is a QueryLogType of type None.
+	// no logging
+	QueryLogTypeNone
+	// QueryLogTypeMysql is a QueryLogType of type Mysql.
+	// MySQL or MariaDB database
+	QueryLogTypeMysql
+	// QueryLogTypePostgresql is a QueryLogType of type Postgresql.
+	// PostgreSQL database
+	QueryLogTypePostgresql
+	// QueryLogTypeCsv is a QueryLogType of type Csv.
+	// CSV file per day
+	QueryLogTypeCsv
+	// QueryLogTypeCsvClient is a QueryLogType of type Csv-Client.
+	// CSV file per day and client
+	QueryLogTypeCsvClient
+	// QueryLogTypeTimescale is a QueryLogType of type Timescale.
+	// Timescale database
+	QueryLogTypeTimescale
+	// QueryLogTypeJsonl is a QueryLogType of type Jsonl.
+	// gzip compressed JSON lines files, rotated per hour or day
+	QueryLogTypeJsonl
 )
 
-var ErrInvalidNetProtocol = fmt.Errorf("not a valid NetProtocol, try [%s]", strings.Join(_NetProtocolNames, ", "))
+var ErrInvalidQueryLogType = fmt.Errorf("not a valid QueryLogType, try [%s]", strings.Join(_QueryLogTypeNames, ", "))
 
-const _NetProtocolName = "tcp+udptcp-tlshttps"
+const _QueryLogTypeName = "consolenonemysqlpostgresqlcsvcsv-clienttimescalejsonl"
 
-var _NetProtocolNames = []string{
-	_NetProtocolName[0:7],
-	_NetProtocolName[7:14],
-	_NetProtocolName[14:19],
+var _QueryLogTypeNames = []string{
+	_QueryLogTypeName[0:7],
+	_QueryLogTypeName[7:11],
+	_QueryLogTypeName[11:16],
+	_QueryLogTypeName[16:26],
+	_QueryLogTypeName[26:29],
+	_QueryLogTypeName[29:39],
+	_QueryLogTypeName[39:48],
+	_QueryLogTypeName[48:53],
 }
 
-// NetProtocolNames returns a list of possible string values of NetProtocol.
-func NetProtocolNames() []string {
-	tmp := make([]string, len(_NetProtocolNames))
-	copy(tmp, _NetProtocolNames)
+// QueryLogTypeNames returns a list of possible string values of QueryLogType.
+func QueryLogTypeNames() []string {
+	tmp := make([]string, len(_QueryLogTypeNames))
+	copy(tmp, _QueryLogTypeNames)
 	return tmp
 }
 
-// NetProtocolValues returns a list of the values for NetProtocol
-func NetProtocolValues() []NetProtocol {
-	return []NetProtocol{
-		NetProtocolTcpUdp,
-		NetProtocolTcpTls,
-		NetProtocolHttps,
+// QueryLogTypeValues returns a list of the values for QueryLogType
+func QueryLogTypeValues() []QueryLogType {
+	return []QueryLogType{
+		QueryLogTypeConsole,
+		QueryLogTypeNone,
+		QueryLogTypeMysql,
+		QueryLogTypePostgresql,
+		QueryLogTypeCsv,
+		QueryLogTypeCsvClient,
+		QueryLogTypeTimescale,
+		QueryLogTypeJsonl,
 	}
 }
 
-var _NetProtocolMap = map[NetProtocol]string{
-	NetProtocolTcpUdp: _NetProtocolName[0:7],
-	NetProtocolTcpTls: _NetProtocolName[7:14],
-	NetProtocolHttps:  _NetProtocolName[14:19],
+var _QueryLogTypeMap = map[QueryLogType]string{
+	QueryLogTypeConsole:    _QueryLogTypeName[0:7],
+	QueryLogTypeNone:       _QueryLogTypeName[7:11],
+	QueryLogTypeMysql:      _QueryLogTypeName[11:16],
+	QueryLogTypePostgresql: _QueryLogTypeName[16:26],
+	QueryLogTypeCsv:        _QueryLogTypeName[26:29],
+	QueryLogTypeCsvClient:  _QueryLogTypeName[29:39],
+	QueryLogTypeTimescale:  _QueryLogTypeName[39:48],
+	QueryLogTypeJsonl:      _QueryLogTypeName[48:53],
 }
 
 // String implements the Stringer interface.
-func (x NetProtocol) String() string {
-	if str, ok := _NetProtocolMap[x]; ok {
+func (x QueryLogType) String() string {
+	if str, ok := _QueryLogTypeMap[x]; ok {
 		return str
 	}
-	return fmt.Sprintf("NetProtocol(%d)", x)
+	return fmt.Sprintf("QueryLogType(%d)", x)
 }
 
 // IsValid provides a quick way to determine if the typed value is
 // part of the allowed enumerated values
-func (x NetProtocol) IsValid() bool {
-	_, ok := _NetProtocolMap[x]
+func (x QueryLogType) IsValid() bool {
+	_, ok := _QueryLogTypeMap[x]
 	return ok
 }
 
-var _NetProtocolValue = map[string]NetProtocol{
-	_NetProtocolName[0:7]:   NetProtocolTcpUdp,
-	_NetProtocolName[7:14]:  NetProtocolTcpTls,
-	_NetProtocolName[14:19]: NetProtocolHttps,
+var _QueryLogTypeValue = map[string]QueryLogType{
+	_QueryLogTypeName[0:7]:   QueryLogTypeConsole,
+	_QueryLogTypeName[7:11]:  QueryLogTypeNone,
+	_QueryLogTypeName[11:16]: QueryLogTypeMysql,
+	_QueryLogTypeName[16:26]: QueryLogTypePostgresql,
+	_QueryLogTypeName[26:29]: QueryLogTypeCsv,
+	_QueryLogTypeName[29:39]: QueryLogTypeCsvClient,
+	_QueryLogTypeName[39:48]: QueryLogTypeTimescale,
+	_QueryLogTypeName[48:53]: QueryLogTypeJsonl,
 }
 
-// ParseNetProtocol attempts to convert a string to a NetProtocol.
-func ParseNetProtocol(name string) (NetProtocol, error) {
-	if x, ok := _NetProtocolValue[name]; ok {
+// ParseQueryLogType attempts to convert a string to a QueryLogType.
+func ParseQueryLogType(name string) (QueryLogType, error) {
+	if x, ok := _QueryLogTypeValue[name]; ok {
 		return x, nil
 	}
-	return NetProtocol(0), fmt.Errorf("%s is %w", name, ErrInvalidNetProtocol)
+	return QueryLogType(0), fmt.Errorf("%s is %w", name, ErrInvalidQueryLogType)
 }
 
 // MarshalText implements the text marshaller method.
-func (x NetProtocol) MarshalText() ([]byte, error) {
+func (x QueryLogType) MarshalText() ([]byte, error) {
 	return []byte(x.String()), nil
 }
 
 // UnmarshalText implements the text unmarshaller method.
-func (x *NetProtocol) UnmarshalText(text []byte) error {
+func (x *QueryLogType) UnmarshalText(text []byte) error {
 	name := string(text)
-	tmp, err := ParseNetProtocol(name)
+	tmp, err := ParseQueryLogType(name)
 	if err != nil {
 		return err
 	}
@@ -279,87 +1549,169 @@ func (x *NetProtocol) UnmarshalText(text []byte) error {
 }
 
 const (
-	// QueryLogFieldClientIP is a QueryLogField of type clientIP.
-	QueryLogFieldClientIP QueryLogField = "clientIP"
-	// QueryLogFieldClientName is a QueryLogField of type clientName.
-	QueryLogFieldClientName QueryLogField = "clientName"
-	// QueryLogFieldResponseReason is a QueryLogField of type responseReason.
-	QueryLogFieldResponseReason QueryLogField = "responseReason"
-	// QueryLogFieldResponseAnswer is a QueryLogField of type responseAnswer.
-	QueryLogFieldResponseAnswer QueryLogField = "responseAnswer"
-	// QueryLogFieldQuestion is a QueryLogField of type question.
-	QueryLogFieldQuestion QueryLogField = "question"
-	// QueryLogFieldDuration is a QueryLogField of type duration.
-	QueryLogFieldDuration QueryLogField = "duration"
+	// RateLimitActionRefuse is a RateLimitAction of type Refuse.
+	// answer with REFUSED
+	RateLimitActionRefuse RateLimitAction = iota
+	// RateLimitActionDrop is a RateLimitAction of type Drop.
+	// don't answer
+	RateLimitActionDrop
+	// RateLimitActionSlip is a RateLimitAction of type Slip.
+	// answer every n-th query over UDP truncated, so real clients retry over TCP, and drop the others
+	RateLimitActionSlip
+)
+
+var ErrInvalidRateLimitAction = fmt.Errorf("not a valid RateLimitAction, try [%s]", strings.Join(_RateLimitActionNames, ", "))
+
+const _RateLimitActionName = "refusedropslip"
+
+var _RateLimitActionNames = []string{
+	_RateLimitActionName[0:6],
+	_RateLimitActionName[6:10],
+	_RateLimitActionName[10:14],
+}
+
+// RateLimitActionNames returns a list of possible string values of RateLimitAction.
+func RateLimitActionNames() []string {
+	tmp := make([]string, len(_RateLimitActionNames))
+	copy(tmp, _RateLimitActionNames)
+	return tmp
+}
+
+// RateLimitActionValues returns a list of the values for RateLimitAction
+func RateLimitActionValues() []RateLimitAction {
+	return []RateLimitAction{
+		RateLimitActionRefuse,
+		RateLimitActionDrop,
+		RateLimitActionSlip,
+	}
+}
+
+var _RateLimitActionMap = map[RateLimitAction]string{
+	RateLimitActionRefuse: _RateLimitActionName[0:6],
+	RateLimitActionDrop:   _RateLimitActionName[6:10],
+	RateLimitActionSlip:   _RateLimitActionName[10:14],
+}
+
+// String implements the Stringer interface.
+func (x RateLimitAction) String() string {
+	if str, ok := _RateLimitActionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("RateLimitAction(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x RateLimitAction) IsValid() bool {
+	_, ok := _RateLimitActionMap[x]
+	return ok
+}
+
+var _RateLimitActionValue = map[string]RateLimitAction{
+	_RateLimitActionName[0:6]:   RateLimitActionRefuse,
+	_RateLimitActionName[6:10]:  RateLimitActionDrop,
+	_RateLimitActionName[10:14]: RateLimitActionSlip,
+}
+
+// ParseRateLimitAction attempts to convert a string to a RateLimitAction.
+func ParseRateLimitAction(name string) (RateLimitAction, error) {
+	if x, ok := _RateLimitActionValue[name]; ok {
+		return x, nil
+	}
+	return RateLimitAction(0), fmt.Errorf("%s is %w", name, ErrInvalidRateLimitAction)
+}
+
+// MarshalText implements the text marshaller method.
+func (x RateLimitAction) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *RateLimitAction) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseRateLimitAction(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// RebindActionNxdomain is a RebindAction of type Nxdomain.
+	// answer with NXDOMAIN
+	RebindActionNxdomain RebindAction = iota
+	// RebindActionDrop is a RebindAction of type Drop.
+	// remove the records with private addresses from the answer
+	RebindActionDrop
 )
 
-var ErrInvalidQueryLogField = fmt.Errorf("not a valid QueryLogField, try [%s]", strings.Join(_QueryLogFieldNames, ", "))
+var ErrInvalidRebindAction = fmt.Errorf("not a valid RebindAction, try [%s]", strings.Join(_RebindActionNames, ", "))
 
-var _QueryLogFieldNames = []string{
-	string(QueryLogFieldClientIP),
-	string(QueryLogFieldClientName),
-	string(QueryLogFieldResponseReason),
-	string(QueryLogFieldResponseAnswer),
-	string(QueryLogFieldQuestion),
-	string(QueryLogFieldDuration),
+const _RebindActionName = "nxdomaindrop"
+
+var _RebindActionNames = []string{
+	_RebindActionName[0:8],
+	_RebindActionName[8:12],
 }
 
-// QueryLogFieldNames returns a list of possible string values of QueryLogField.
-func QueryLogFieldNames() []string {
-	tmp := make([]string, len(_QueryLogFieldNames))
-	copy(tmp, _QueryLogFieldNames)
+// RebindActionNames returns a list of possible string values of RebindAction.
+func RebindActionNames() []string {
+	tmp := make([]string, len(_RebindActionNames))
+	copy(tmp, _RebindActionNames)
 	return tmp
 }
 
-// QueryLogFieldValues returns a list of the values for QueryLogField
-func QueryLogFieldValues() []QueryLogField {
-	return []QueryLogField{
-		QueryLogFieldClientIP,
-		QueryLogFieldClientName,
-		QueryLogFieldResponseReason,
-		QueryLogFieldResponseAnswer,
-		QueryLogFieldQuestion,
-		QueryLogFieldDuration,
+// RebindActionValues returns a list of the values for RebindAction
+func RebindActionValues() []RebindAction {
+	return []RebindAction{
+		RebindActionNxdomain,
+		RebindActionDrop,
 	}
 }
 
+var _RebindActionMap = map[RebindAction]string{
+	RebindActionNxdomain: _RebindActionName[0:8],
+	RebindActionDrop:     _RebindActionName[8:12],
+}
+
 // String implements the Stringer interface.
-func (x QueryLogField) String() string {
-	return string(x)
+func (x RebindAction) String() string {
+	if str, ok := _RebindActionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("RebindAction(%d)", x)
 }
 
 // IsValid provides a quick way to determine if the typed value is
 // part of the allowed enumerated values
-func (x QueryLogField) IsValid() bool {
-	_, err := ParseQueryLogField(string(x))
-	return err == nil
+func (x RebindAction) IsValid() bool {
+	_, ok := _RebindActionMap[x]
+	return ok
 }
 
-var _QueryLogFieldValue = map[string]QueryLogField{
-	"clientIP":       QueryLogFieldClientIP,
-	"clientName":     QueryLogFieldClientName,
-	"responseReason": QueryLogFieldResponseReason,
-	"responseAnswer": QueryLogFieldResponseAnswer,
-	"question":       QueryLogFieldQuestion,
-	"duration":       QueryLogFieldDuration,
+var _RebindActionValue = map[string]RebindAction{
+	_RebindActionName[0:8]:  RebindActionNxdomain,
+	_RebindActionName[8:12]: RebindActionDrop,
 }
 
-// ParseQueryLogField attempts to convert a string to a QueryLogField.
-func ParseQueryLogField(name string) (QueryLogField, error) {
-	if x, ok := _QueryLogFieldValue[name]; ok {
+// ParseRebindAction attempts to convert a string to a RebindAction.
+func ParseRebindAction(name string) (RebindAction, error) {
+	if x, ok := _RebindActionValue[name]; ok {
 		return x, nil
 	}
-	return QueryLogField(""), fmt.Errorf("%s is %w", name, ErrInvalidQueryLogField)
+	return RebindAction(0), fmt.Errorf("%s is %w", name, ErrInvalidRebindAction)
 }
 
 // MarshalText implements the text marshaller method.
-func (x QueryLogField) MarshalText() ([]byte, error) {
-	return []byte(string(x)), nil
+func (x RebindAction) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
 }
 
 // UnmarshalText implements the text unmarshaller method.
-func (x *QueryLogField) UnmarshalText(text []byte) error {
-	tmp, err := ParseQueryLogField(string(text))
+func (x *RebindAction) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseRebindAction(name)
 	if err != nil {
 		return err
 	}
@@ -368,115 +1720,101 @@ func (x *QueryLogField) UnmarshalText(text []byte) error {
 }
 
 const (
-	// QueryLogTypeConsole is a QueryLogType of type Console.
-	// use logger as fallback
-	QueryLogTypeConsole QueryLogType = iota
-	// QueryLogTypeNone is a QueryLogType of type None.
-	// no logging
-	QueryLogTypeNone
-	// QueryLogTypeMysql is a QueryLogType of type Mysql.
-	// MySQL or MariaDB database
-	QueryLogTypeMysql
-	// QueryLogTypePostgresql is a QueryLogType of type Postgresql.
-	// PostgreSQL database
-	QueryLogTypePostgresql
-	// QueryLogTypeCsv is a QueryLogType of type Csv.
-	// CSV file per day
-	QueryLogTypeCsv
-	// QueryLogTypeCsvClient is a QueryLogType of type Csv-Client.
-	// CSV file per day and client
-	QueryLogTypeCsvClient
-	// QueryLogTypeTimescale is a QueryLogType of type Timescale.
-	// Timescale database
-	QueryLogTypeTimescale
+	// TLSClientAuthNone is a TLSClientAuth of type None.
+	// don't request a client certificate
+	TLSClientAuthNone TLSClientAuth = iota
+	// TLSClientAuthRequest is a TLSClientAuth of type Request.
+	// request a client certificate, but don't require it
+	TLSClientAuthRequest
+	// TLSClientAuthRequire is a TLSClientAuth of type Require.
+	// require any client certificate
+	TLSClientAuthRequire
+	// TLSClientAuthVerifyIfGiven is a TLSClientAuth of type VerifyIfGiven.
+	// verify the client certificate if one is sent
+	TLSClientAuthVerifyIfGiven
+	// TLSClientAuthRequireAndVerify is a TLSClientAuth of type RequireAndVerify.
+	// require a valid client certificate
+	TLSClientAuthRequireAndVerify
 )
 
-var ErrInvalidQueryLogType = fmt.Errorf("not a valid QueryLogType, try [%s]", strings.Join(_QueryLogTypeNames, ", "))
+var ErrInvalidTLSClientAuth = fmt.Errorf("not a valid TLSClientAuth, try [%s]", strings.Join(_TLSClientAuthNames, ", "))
 
-const _QueryLogTypeName = "consolenonemysqlpostgresqlcsvcsv-clienttimescale"
+const _TLSClientAuthName = "nonerequestrequireverifyIfGivenrequireAndVerify"
 
-var _QueryLogTypeNames = []string{
-	_QueryLogTypeName[0:7],
-	_QueryLogTypeName[7:11],
-	_QueryLogTypeName[11:16],
-	_QueryLogTypeName[16:26],
-	_QueryLogTypeName[26:29],
-	_QueryLogTypeName[29:39],
-	_QueryLogTypeName[39:48],
+var _TLSClientAuthNames = []string{
+	_TLSClientAuthName[0:4],
+	_TLSClientAuthName[4:11],
+	_TLSClientAuthName[11:18],
+	_TLSClientAuthName[18:31],
+	_TLSClientAuthName[31:47],
 }
 
-// QueryLogTypeNames returns a list of possible string values of QueryLogType.
-func QueryLogTypeNames() []string {
-	tmp := make([]string, len(_QueryLogTypeNames))
-	copy(tmp, _QueryLogTypeNames)
+// TLSClientAuthNames returns a list of possible string values of TLSClientAuth.
+func TLSClientAuthNames() []string {
+	tmp := make([]string, len(_TLSClientAuthNames))
+	copy(tmp, _TLSClientAuthNames)
 	return tmp
 }
 
-// QueryLogTypeValues returns a list of the values for QueryLogType
-func QueryLogTypeValues() []QueryLogType {
-	return []QueryLogType{
-		QueryLogTypeConsole,
-		QueryLogTypeNone,
-		QueryLogTypeMysql,
-		QueryLogTypePostgresql,
-		QueryLogTypeCsv,
-		QueryLogTypeCsvClient,
-		QueryLogTypeTimescale,
+// TLSClientAuthValues returns a list of the values for TLSClientAuth
+func TLSClientAuthValues() []TLSClientAuth {
+	return []TLSClientAuth{
+		TLSClientAuthNone,
+		TLSClientAuthRequest,
+		TLSClientAuthRequire,
+		TLSClientAuthVerifyIfGiven,
+		TLSClientAuthRequireAndVerify,
 	}
 }
 
-var _QueryLogTypeMap = map[QueryLogType]string{
-	QueryLogTypeConsole:    _QueryLogTypeName[0:7],
-	QueryLogTypeNone:       _QueryLogTypeName[7:11],
-	QueryLogTypeMysql:      _QueryLogTypeName[11:16],
-	QueryLogTypePostgresql: _QueryLogTypeName[16:26],
-	QueryLogTypeCsv:        _QueryLogTypeName[26:29],
-	QueryLogTypeCsvClient:  _QueryLogTypeName[29:39],
-	QueryLogTypeTimescale:  _QueryLogTypeName[39:48],
+var _TLSClientAuthMap = map[TLSClientAuth]string{
+	TLSClientAuthNone:             _TLSClientAuthName[0:4],
+	TLSClientAuthRequest:          _TLSClientAuthName[4:11],
+	TLSClientAuthRequire:          _TLSClientAuthName[11:18],
+	TLSClientAuthVerifyIfGiven:    _TLSClientAuthName[18:31],
+	TLSClientAuthRequireAndVerify: _TLSClientAuthName[31:47],
 }
 
 // String implements the Stringer interface.
-func (x QueryLogType) String() string {
-	if str, ok := _QueryLogTypeMap[x]; ok {
+func (x TLSClientAuth) String() string {
+	if str, ok := _TLSClientAuthMap[x]; ok {
 		return str
 	}
-	return fmt.Sprintf("QueryLogType(%d)", x)
+	return fmt.Sprintf("TLSClientAuth(%d)", x)
 }
 
 // IsValid provides a quick way to determine if the typed value is
 // part of the allowed enumerated values
-func (x QueryLogType) IsValid() bool {
-	_, ok := _QueryLogTypeMap[x]
+func (x TLSClientAuth) IsValid() bool {
+	_, ok := _TLSClientAuthMap[x]
 	return ok
 }
 
-var _QueryLogTypeValue = map[string]QueryLogType{
-	_QueryLogTypeName[0:7]:   QueryLogTypeConsole,
-	_QueryLogTypeName[7:11]:  QueryLogTypeNone,
-	_QueryLogTypeName[11:16]: QueryLogTypeMysql,
-	_QueryLogTypeName[16:26]: QueryLogTypePostgresql,
-	_QueryLogTypeName[26:29]: QueryLogTypeCsv,
-	_QueryLogTypeName[29:39]: QueryLogTypeCsvClient,
-	_QueryLogTypeName[39:48]: QueryLogTypeTimescale,
+var _TLSClientAuthValue = map[string]TLSClientAuth{
+	_TLSClientAuthName[0:4]:   TLSClientAuthNone,
+	_TLSClientAuthName[4:11]:  TLSClientAuthRequest,
+	_TLSClientAuthName[11:18]: TLSClientAuthRequire,
+	_TLSClientAuthName[18:31]: TLSClientAuthVerifyIfGiven,
+	_TLSClientAuthName[31:47]: TLSClientAuthRequireAndVerify,
 }
 
-// ParseQueryLogType attempts to convert a string to a QueryLogType.
-func ParseQueryLogType(name string) (QueryLogType, error) {
-	if x, ok := _QueryLogTypeValue[name]; ok {
+// ParseTLSClientAuth attempts to convert a string to a TLSClientAuth.
+func ParseTLSClientAuth(name string) (TLSClientAuth, error) {
+	if x, ok := _TLSClientAuthValue[name]; ok {
 		return x, nil
 	}
-	return QueryLogType(0), fmt.Errorf("%s is %w", name, ErrInvalidQueryLogType)
+	return TLSClientAuth(0), fmt.Errorf("%s is %w", name, ErrInvalidTLSClientAuth)
 }
 
 // MarshalText implements the text marshaller method.
-func (x QueryLogType) MarshalText() ([]byte, error) {
+func (x TLSClientAuth) MarshalText() ([]byte, error) {
 	return []byte(x.String()), nil
 }
 
 // UnmarshalText implements the text unmarshaller method.
-func (x *QueryLogType) UnmarshalText(text []byte) error {
+func (x *TLSClientAuth) UnmarshalText(text []byte) error {
 	name := string(text)
-	tmp, err := ParseQueryLogType(name)
+	tmp, err := ParseTLSClientAuth(name)
 	if err != nil {
 		return err
 	}
@@ -576,6 +1914,95 @@ func (x *TLSVersion) UnmarshalText(text []byte) error {
 	return nil
 }
 
+const (
+	// TunnelingActionLog is a TunnelingAction of type Log.
+	// log and count the suspected tunnel
+	TunnelingActionLog TunnelingAction = iota
+	// TunnelingActionRatelimit is a TunnelingAction of type Ratelimit.
+	// answer the queries exceeding the rate limit with REFUSED
+	TunnelingActionRatelimit
+	// TunnelingActionBlock is a TunnelingAction of type Block.
+	// answer all queries with REFUSED
+	TunnelingActionBlock
+)
+
+var ErrInvalidTunnelingAction = fmt.Errorf("not a valid TunnelingAction, try [%s]", strings.Join(_TunnelingActionNames, ", "))
+
+const _TunnelingActionName = "logratelimitblock"
+
+var _TunnelingActionNames = []string{
+	_TunnelingActionName[0:3],
+	_TunnelingActionName[3:12],
+	_TunnelingActionName[12:17],
+}
+
+// TunnelingActionNames returns a list of possible string values of TunnelingAction.
+func TunnelingActionNames() []string {
+	tmp := make([]string, len(_TunnelingActionNames))
+	copy(tmp, _TunnelingActionNames)
+	return tmp
+}
+
+// TunnelingActionValues returns a list of the values for TunnelingAction
+func TunnelingActionValues() []TunnelingAction {
+	return []TunnelingAction{
+		TunnelingActionLog,
+		TunnelingActionRatelimit,
+		TunnelingActionBlock,
+	}
+}
+
+var _TunnelingActionMap = map[TunnelingAction]string{
+	TunnelingActionLog:       _TunnelingActionName[0:3],
+	TunnelingActionRatelimit: _TunnelingActionName[3:12],
+	TunnelingActionBlock:     _TunnelingActionName[12:17],
+}
+
+// String implements the Stringer interface.
+func (x TunnelingAction) String() string {
+	if str, ok := _TunnelingActionMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("TunnelingAction(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x TunnelingAction) IsValid() bool {
+	_, ok := _TunnelingActionMap[x]
+	return ok
+}
+
+var _TunnelingActionValue = map[string]TunnelingAction{
+	_TunnelingActionName[0:3]:   TunnelingActionLog,
+	_TunnelingActionName[3:12]:  TunnelingActionRatelimit,
+	_TunnelingActionName[12:17]: TunnelingActionBlock,
+}
+
+// ParseTunnelingAction attempts to convert a string to a TunnelingAction.
+func ParseTunnelingAction(name string) (TunnelingAction, error) {
+	if x, ok := _TunnelingActionValue[name]; ok {
+		return x, nil
+	}
+	return TunnelingAction(0), fmt.Errorf("%s is %w", name, ErrInvalidTunnelingAction)
+}
+
+// MarshalText implements the text marshaller method.
+func (x TunnelingAction) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *TunnelingAction) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseTunnelingAction(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
 const (
 	// UpstreamStrategyParallelBest is a UpstreamStrategy of type Parallel_best.
 	UpstreamStrategyParallelBest UpstreamStrategy = iota
@@ -583,16 +2010,22 @@ const (
 	UpstreamStrategyStrict
 	// UpstreamStrategyRandom is a UpstreamStrategy of type Random.
 	UpstreamStrategyRandom
+	// UpstreamStrategyWeighted is a UpstreamStrategy of type Weighted.
+	UpstreamStrategyWeighted
+	// UpstreamStrategyFastest is a UpstreamStrategy of type Fastest.
+	UpstreamStrategyFastest
 )
 
 var ErrInvalidUpstreamStrategy = fmt.Errorf("not a valid UpstreamStrategy, try [%s]", strings.Join(_UpstreamStrategyNames, ", "))
 
-const _UpstreamStrategyName = "parallel_beststrictrandom"
+const _UpstreamStrategyName = "parallel_beststrictrandomweightedfastest"
 
 var _UpstreamStrategyNames = []string{
 	_UpstreamStrategyName[0:13],
 	_UpstreamStrategyName[13:19],
 	_UpstreamStrategyName[19:25],
+	_UpstreamStrategyName[25:33],
+	_UpstreamStrategyName[33:40],
 }
 
 // UpstreamStrategyNames returns a list of possible string values of UpstreamStrategy.
@@ -608,6 +2041,8 @@ func UpstreamStrategyValues() []UpstreamStrategy {
 		UpstreamStrategyParallelBest,
 		UpstreamStrategyStrict,
 		UpstreamStrategyRandom,
+		UpstreamStrategyWeighted,
+		UpstreamStrategyFastest,
 	}
 }
 
@@ -615,6 +2050,8 @@ var _UpstreamStrategyMap = map[UpstreamStrategy]string{
 	UpstreamStrategyParallelBest: _UpstreamStrategyName[0:13],
 	UpstreamStrategyStrict:       _UpstreamStrategyName[13:19],
 	UpstreamStrategyRandom:       _UpstreamStrategyName[19:25],
+	UpstreamStrategyWeighted:     _UpstreamStrategyName[25:33],
+	UpstreamStrategyFastest:      _UpstreamStrategyName[33:40],
 }
 
 // String implements the Stringer interface.
@@ -636,6 +2073,8 @@ var _UpstreamStrategyValue = map[string]UpstreamStrategy{
 	_UpstreamStrategyName[0:13]:  UpstreamStrategyParallelBest,
 	_UpstreamStrategyName[13:19]: UpstreamStrategyStrict,
 	_UpstreamStrategyName[19:25]: UpstreamStrategyRandom,
+	_UpstreamStrategyName[25:33]: UpstreamStrategyWeighted,
+	_UpstreamStrategyName[33:40]: UpstreamStrategyFastest,
 }
 
 // ParseUpstreamStrategy attempts to convert a string to a UpstreamStrategy.
@@ -661,3 +2100,185 @@ func (x *UpstreamStrategy) UnmarshalText(text []byte) error {
 	*x = tmp
 	return nil
 }
+
+const (
+	// WebhookEventListDownloadFailed is a WebhookEvent of type listDownloadFailed.
+	WebhookEventListDownloadFailed WebhookEvent = "listDownloadFailed"
+	// WebhookEventUpstreamsDown is a WebhookEvent of type upstreamsDown.
+	WebhookEventUpstreamsDown WebhookEvent = "upstreamsDown"
+	// WebhookEventUpstreamsRecovered is a WebhookEvent of type upstreamsRecovered.
+	WebhookEventUpstreamsRecovered WebhookEvent = "upstreamsRecovered"
+	// WebhookEventCertificateExpiring is a WebhookEvent of type certificateExpiring.
+	WebhookEventCertificateExpiring WebhookEvent = "certificateExpiring"
+)
+
+var ErrInvalidWebhookEvent = fmt.Errorf("not a valid WebhookEvent, try [%s]", strings.Join(_WebhookEventNames, ", "))
+
+var _WebhookEventNames = []string{
+	string(WebhookEventListDownloadFailed),
+	string(WebhookEventUpstreamsDown),
+	string(WebhookEventUpstreamsRecovered),
+	string(WebhookEventCertificateExpiring),
+}
+
+// WebhookEventNames returns a list of possible string values of WebhookEvent.
+func WebhookEventNames() []string {
+	tmp := make([]string, len(_WebhookEventNames))
+	copy(tmp, _WebhookEventNames)
+	return tmp
+}
+
+// WebhookEventValues returns a list of the values for WebhookEvent
+func WebhookEventValues() []WebhookEvent {
+	return []WebhookEvent{
+		WebhookEventListDownloadFailed,
+		WebhookEventUpstreamsDown,
+		WebhookEventUpstreamsRecovered,
+		WebhookEventCertificateExpiring,
+	}
+}
+
+// String implements the Stringer interface.
+func (x WebhookEvent) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x WebhookEvent) IsValid() bool {
+	_, err := ParseWebhookEvent(string(x))
+	return err == nil
+}
+
+var _WebhookEventValue = map[string]WebhookEvent{
+	"listDownloadFailed":  WebhookEventListDownloadFailed,
+	"upstreamsDown":       WebhookEventUpstreamsDown,
+	"upstreamsRecovered":  WebhookEventUpstreamsRecovered,
+	"certificateExpiring": WebhookEventCertificateExpiring,
+}
+
+// ParseWebhookEvent attempts to convert a string to a WebhookEvent.
+func ParseWebhookEvent(name string) (WebhookEvent, error) {
+	if x, ok := _WebhookEventValue[name]; ok {
+		return x, nil
+	}
+	return WebhookEvent(""), fmt.Errorf("%s is %w", name, ErrInvalidWebhookEvent)
+}
+
+// MarshalText implements the text marshaller method.
+func (x WebhookEvent) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *WebhookEvent) UnmarshalText(text []byte) error {
+	tmp, err := ParseWebhookEvent(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+const (
+	// WebhookFormatJson is a WebhookFormat of type Json.
+	// generic JSON
+	WebhookFormatJson WebhookFormat = iota
+	// WebhookFormatSlack is a WebhookFormat of type Slack.
+	// Slack incoming webhook
+	WebhookFormatSlack
+	// WebhookFormatDiscord is a WebhookFormat of type Discord.
+	// Discord webhook
+	WebhookFormatDiscord
+	// WebhookFormatNtfy is a WebhookFormat of type Ntfy.
+	// ntfy topic
+	WebhookFormatNtfy
+	// WebhookFormatPushover is a WebhookFormat of type Pushover.
+	// Pushover message API
+	WebhookFormatPushover
+)
+
+var ErrInvalidWebhookFormat = fmt.Errorf("not a valid WebhookFormat, try [%s]", strings.Join(_WebhookFormatNames, ", "))
+
+const _WebhookFormatName = "jsonslackdiscordntfypushover"
+
+var _WebhookFormatNames = []string{
+	_WebhookFormatName[0:4],
+	_WebhookFormatName[4:9],
+	_WebhookFormatName[9:16],
+	_WebhookFormatName[16:20],
+	_WebhookFormatName[20:28],
+}
+
+// WebhookFormatNames returns a list of possible string values of WebhookFormat.
+func WebhookFormatNames() []string {
+	tmp := make([]string, len(_WebhookFormatNames))
+	copy(tmp, _WebhookFormatNames)
+	return tmp
+}
+
+// WebhookFormatValues returns a list of the values for WebhookFormat
+func WebhookFormatValues() []WebhookFormat {
+	return []WebhookFormat{
+		WebhookFormatJson,
+		WebhookFormatSlack,
+		WebhookFormatDiscord,
+		WebhookFormatNtfy,
+		WebhookFormatPushover,
+	}
+}
+
+var _WebhookFormatMap = map[WebhookFormat]string{
+	WebhookFormatJson:     _WebhookFormatName[0:4],
+	WebhookFormatSlack:    _WebhookFormatName[4:9],
+	WebhookFormatDiscord:  _WebhookFormatName[9:16],
+	WebhookFormatNtfy:     _WebhookFormatName[16:20],
+	WebhookFormatPushover: _WebhookFormatName[20:28],
+}
+
+// String implements the Stringer interface.
+func (x WebhookFormat) String() string {
+	if str, ok := _WebhookFormatMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("WebhookFormat(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x WebhookFormat) IsValid() bool {
+	_, ok := _WebhookFormatMap[x]
+	return ok
+}
+
+var _WebhookFormatValue = map[string]WebhookFormat{
+	_WebhookFormatName[0:4]:   WebhookFormatJson,
+	_WebhookFormatName[4:9]:   WebhookFormatSlack,
+	_WebhookFormatName[9:16]:  WebhookFormatDiscord,
+	_WebhookFormatName[16:20]: WebhookFormatNtfy,
+	_WebhookFormatName[20:28]: WebhookFormatPushover,
+}
+
+// ParseWebhookFormat attempts to convert a string to a WebhookFormat.
+func ParseWebhookFormat(name string) (WebhookFormat, error) {
+	if x, ok := _WebhookFormatValue[name]; ok {
+		return x, nil
+	}
+	return WebhookFormat(0), fmt.Errorf("%s is %w", name, ErrInvalidWebhookFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x WebhookFormat) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *WebhookFormat) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseWebhookFormat(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -163,6 +163,19 @@ var _ = Describe("Config", func() {
 
 				defaultTestFileConfig(c)
 			})
+
+			It("should set the hash of the config data", func() {
+				confFile := writeConfigYml(tmpDir)
+
+				c, err = LoadConfig(confFile.Path, true)
+				Expect(err).Should(Succeed())
+
+				Expect(c.Hash).Should(HaveLen(64))
+				Expect(HashConfig(confFile.Path)).Should(Equal(c.Hash))
+
+				other := tmpDir.CreateStringFile("other.yml", "upstreams:\n  groups:\n    default:\n      - 1.1.1.1")
+				Expect(HashConfig(other.Path)).ShouldNot(Equal(c.Hash))
+			})
 		})
 		When("Test config file contains a zone file with $INCLUDE", func() {
 			When("The config path is set to the config file", func() {
@@ -521,6 +534,22 @@ bootstrapDns:
 			"https://dns.google:888/dns-query",
 			Upstream{Net: NetProtocolHttps, Host: "dns.google", Port: 888, Path: "/dns-query"},
 			false),
+		Entry("DoH over HTTP/3",
+			"h3://dns.google/dns-query",
+			Upstream{Net: NetProtocolHttps, Host: "dns.google", Port: 443, Path: "/dns-query", HTTP3: true},
+			false),
+		Entry("unix socket",
+			"unix:/run/dns.sock",
+			Upstream{Net: NetProtocolUnix, Path: "/run/dns.sock"},
+			false),
+		Entry("unix socket URL",
+			"unix:///run/dns.sock",
+			Upstream{Net: NetProtocolUnix, Path: "/run/dns.sock"},
+			false),
+		Entry("unix socket with relative path",
+			"unix:dns.sock",
+			Upstream{},
+			true),
 		Entry("empty",
 			"",
 			Upstream{Net: 0},
@@ -627,6 +656,14 @@ bootstrapDns:
 			Upstream{Net: NetProtocolHttps, Host: "localhost", Port: 888, Path: "/dns-query"},
 			"https://localhost:888/dns-query",
 		),
+		Entry("https over HTTP/3",
+			Upstream{Net: NetProtocolHttps, Host: "localhost", Port: 443, Path: "/dns-query", HTTP3: true},
+			"h3://localhost/dns-query",
+		),
+		Entry("unix socket",
+			Upstream{Net: NetProtocolUnix, Path: "/run/dns.sock"},
+			"unix:/run/dns.sock",
+		),
 		Entry("tcp+udp IPv4 with port",
 			Upstream{Net: NetProtocolTcpUdp, Host: "127.0.0.1", Port: 531},
 			"tcp+udp:127.0.0.1:531",
@@ -841,6 +878,106 @@ bootstrapDns:
 		})
 	})
 
+	Describe("Init", func() {
+		var (
+			sut      Init
+			ctx      context.Context
+			cancelFn context.CancelFunc
+		)
+
+		BeforeEach(func() {
+			sut = Init{
+				Strategy: InitStrategyFast,
+				Retry:    InitRetry{MinDelay: Duration(time.Millisecond), MaxDelay: Duration(4 * time.Millisecond)},
+			}
+
+			ctx, cancelFn = context.WithCancel(context.Background())
+			DeferCleanup(cancelFn)
+		})
+
+		It("retries in the background until it succeeds", func() {
+			var calls atomic.Int32
+
+			errs := make(chan error, 10)
+
+			err := sut.Do(ctx, func(context.Context) error {
+				if calls.Add(1) < 4 {
+					return errors.New("not yet")
+				}
+
+				return nil
+			}, func(err error) {
+				errs <- err
+			})
+
+			Expect(err).Should(Succeed())
+			Eventually(calls.Load, "100ms").Should(BeNumerically("==", 4))
+			Consistently(calls.Load, "20ms").Should(BeNumerically("==", 4))
+
+			Expect(errs).Should(HaveLen(3))
+			Expect(<-errs).Should(MatchError("not yet"))
+			Expect(<-errs).Should(MatchError(ContainSubstring("retrying in 2ms")))
+			Expect(<-errs).Should(MatchError(ContainSubstring("retrying in 4ms")))
+		})
+
+		It("stops retrying when the context is done", func() {
+			var calls atomic.Int32
+
+			err := sut.Do(ctx, func(context.Context) error {
+				calls.Add(1)
+
+				return errors.New("fail")
+			}, func(error) {})
+
+			Expect(err).Should(Succeed())
+			Eventually(calls.Load, "100ms").Should(BeNumerically(">", 1))
+
+			cancelFn()
+
+			time.Sleep(10 * time.Millisecond)
+
+			count := calls.Load()
+			Consistently(calls.Load, "20ms").Should(Equal(count))
+		})
+
+		It("doesn't retry with the failOnError strategy", func() {
+			sut.Strategy = InitStrategyFailOnError
+
+			var calls atomic.Int32
+
+			err := sut.Do(ctx, func(context.Context) error {
+				calls.Add(1)
+
+				return errors.New("fail")
+			}, func(error) {})
+
+			Expect(err).Should(HaveOccurred())
+			Consistently(calls.Load, "20ms").Should(BeNumerically("==", 1))
+		})
+
+		It("doesn't retry if disabled", func() {
+			sut.Retry.MinDelay = 0
+
+			var calls atomic.Int32
+
+			err := sut.Do(ctx, func(context.Context) error {
+				calls.Add(1)
+
+				return errors.New("fail")
+			}, func(error) {})
+
+			Expect(err).Should(Succeed())
+			Consistently(calls.Load, "20ms").Should(BeNumerically("<=", 1))
+		})
+
+		It("should not be retried by default", func() {
+			cfg, err := WithDefaults[Init]()
+			Expect(err).Should(Succeed())
+
+			Expect(cfg.Retry.IsEnabled()).Should(BeFalse())
+		})
+	})
+
 	Describe("BootstrapDNSConfig", func() {
 		It("is not enabled when empty", func() {
 			var sut BootstrapDNS
@@ -882,9 +1019,11 @@ bootstrapDns:
 
 			panicMsg := "panic value"
 
-			err := sut.StartPeriodicRefresh(ctx, func(context.Context) error {
+			load := func(context.Context) error {
 				panic(panicMsg)
-			}, func(err error) {
+			}
+
+			err := sut.StartPeriodicRefresh(ctx, load, load, func(err error) {
 				Expect(err).Should(MatchError(ContainSubstring(panicMsg)))
 			})
 
@@ -902,7 +1041,7 @@ bootstrapDns:
 
 			var call atomic.Int32
 
-			err := sut.StartPeriodicRefresh(ctx, func(context.Context) error {
+			refresh := func(context.Context) error {
 				call := call.Add(1)
 				calls <- call
 
@@ -911,7 +1050,9 @@ bootstrapDns:
 				}
 
 				return nil
-			}, func(err error) {
+			}
+
+			err := sut.StartPeriodicRefresh(ctx, refresh, refresh, func(err error) {
 				defer GinkgoRecover()
 
 				Expect(err).Should(MatchError(ContainSubstring(panicMsg)))
@@ -923,6 +1064,34 @@ bootstrapDns:
 			Eventually(calls, "50ms").Should(Receive(Equal(int32(2))))
 			Eventually(calls, "50ms").Should(Receive(Equal(int32(3))))
 		})
+
+		It("only retries load", func() {
+			sut := SourceLoading{
+				Init: Init{
+					Strategy: InitStrategyFast,
+					Retry:    InitRetry{MinDelay: Duration(time.Millisecond), MaxDelay: Duration(time.Millisecond)},
+				},
+				RefreshPeriod: Duration(time.Hour),
+			}
+
+			var loads, refreshes atomic.Int32
+
+			err := sut.StartPeriodicRefresh(ctx, func(context.Context) error {
+				if loads.Add(1) < 3 {
+					return errors.New("fail")
+				}
+
+				return nil
+			}, func(context.Context) error {
+				refreshes.Add(1)
+
+				return nil
+			}, func(error) {})
+
+			Expect(err).Should(Succeed())
+			Eventually(loads.Load, "100ms").Should(BeNumerically("==", 3))
+			Consistently(refreshes.Load, "20ms").Should(BeZero())
+		})
 	})
 
 	Describe("WithDefaults", func() {
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"net/url"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ContainerDNS configuration of the custom DNS records of Docker or Podman containers
+type ContainerDNS struct {
+	Enable bool `yaml:"enable" default:"false"`
+	// Endpoint of the Docker API: unix socket, TCP or HTTP(S) URL
+	Endpoint string `yaml:"endpoint" default:"unix:///var/run/docker.sock"`
+	// Label holding the domains of a container, `<label>.ip` overrides its IP addresses
+	Label string `yaml:"label" default:"blocky.dns"`
+	// Network whose IP addresses are used, all networks if empty
+	Network string `yaml:"network"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *ContainerDNS) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ContainerDNS) LogConfig(logger *logrus.Entry) {
+	logger.Infof("endpoint = %s", c.Endpoint)
+	logger.Infof("label    = %s", c.Label)
+
+	if c.Network != "" {
+		logger.Infof("network  = %s", c.Network)
+	}
+}
+
+func (c *ContainerDNS) validate(logger *logrus.Entry) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	def := mustDefault[ContainerDNS]()
+
+	if u, err := url.Parse(c.Endpoint); err != nil || !slices.Contains([]string{"unix", "tcp", "http", "https"}, u.Scheme) {
+		logger.Warnf("customDNS.containers.endpoint '%s' is not a unix, tcp or http(s) URL, setting to %s",
+			c.Endpoint, def.Endpoint)
+
+		c.Endpoint = def.Endpoint
+	}
+
+	if c.Label == "" {
+		logger.Warnf("customDNS.containers.label is empty, setting to %s", def.Label)
+
+		c.Label = def.Label
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContainerDNSConfig", func() {
+	var cfg ContainerDNS
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[ContainerDNS]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("enabled", func() {
+			It("should be true", func() {
+				cfg.Enable = true
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.Network = "frontend"
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"endpoint = unix:///var/run/docker.sock",
+				"label    = blocky.dns",
+				"network  = frontend",
+			}))
+		})
+	})
+
+	Describe("validate", func() {
+		BeforeEach(func() {
+			cfg.Enable = true
+		})
+
+		It("should accept valid endpoints", func() {
+			for _, endpoint := range []string{"unix:///run/podman/podman.sock", "tcp://docker:2375", "https://docker:2376"} {
+				cfg.Endpoint = endpoint
+
+				cfg.validate(logger)
+
+				Expect(cfg.Endpoint).Should(Equal(endpoint))
+			}
+
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+
+		It("should fix invalid values", func() {
+			cfg.Endpoint = "/var/run/docker.sock"
+			cfg.Label = ""
+
+			cfg.validate(logger)
+
+			Expect(cfg.Endpoint).Should(Equal("unix:///var/run/docker.sock"))
+			Expect(cfg.Label).Should(Equal("blocky.dns"))
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("is not a unix, tcp or http(s) URL"),
+				ContainSubstring("customDNS.containers.label is empty"),
+			))
+		})
+
+		It("should do nothing when disabled", func() {
+			cfg.Enable = false
+			cfg.Label = ""
+
+			cfg.validate(logger)
+
+			Expect(cfg.Label).Should(BeEmpty())
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+	})
+})
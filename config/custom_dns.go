@@ -3,19 +3,29 @@ package config
 import (
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 
+	"github.com/0xERR0R/blocky/log"
 	"github.com/miekg/dns"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 )
 
 // CustomDNS custom DNS configuration
 type CustomDNS struct {
 	RewriterConfig      `yaml:",inline"`
-	CustomTTL           Duration         `yaml:"customTTL" default:"1h"`
-	Mapping             CustomDNSMapping `yaml:"mapping"`
-	Zone                ZoneFileDNS      `yaml:"zone" default:""`
-	FilterUnmappedTypes bool             `yaml:"filterUnmappedTypes" default:"true"`
+	CustomTTL           Duration                 `yaml:"customTTL" default:"1h"`
+	Mapping             CustomDNSMapping         `yaml:"mapping"`
+	Zone                ZoneFileDNS              `yaml:"zone" default:""`
+	ZoneFiles           ZoneFiles                `yaml:"zoneFiles"`
+	ReverseRanges       []ReverseRange           `yaml:"reverseRanges"`
+	Views               map[string]CustomDNSView `yaml:"views"`
+	FilterUnmappedTypes bool                     `yaml:"filterUnmappedTypes" default:"true"`
+	SelfHostnames       []string                 `yaml:"selfHostnames"`
+	Containers          ContainerDNS             `yaml:"containers"`
+	ExternalDNS         ExternalDNS              `yaml:"externalDNS"`
+	DynamicUpdates      DynamicUpdates           `yaml:"dynamicUpdates"`
 }
 
 type (
@@ -66,12 +76,15 @@ func (z *ZoneFileDNS) UnmarshalYAML(unmarshal func(interface{}) error) error {
 }
 
 func (c *CustomDNSEntries) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var parts []string
+
 	var input string
-	if err := unmarshal(&input); err != nil {
+	if err := unmarshal(&input); err == nil {
+		parts = splitCustomDNSEntries(input)
+	} else if listErr := unmarshal(&parts); listErr != nil {
 		return err
 	}
 
-	parts := strings.Split(input, ",")
 	result := make(CustomDNSEntries, len(parts))
 
 	for i, part := range parts {
@@ -88,9 +101,62 @@ func (c *CustomDNSEntries) UnmarshalYAML(unmarshal func(interface{}) error) erro
 	return nil
 }
 
+// splitCustomDNSEntries splits comma separated IP addresses, a record can contain commas and is not split
+func splitCustomDNSEntries(input string) []string {
+	if isRecordEntry(input) {
+		return []string{input}
+	}
+
+	return strings.Split(input, ",")
+}
+
+// isRecordEntry returns if the entry is a record with type and data like "MX 10 mail.example.com"
+func isRecordEntry(entry string) bool {
+	fields := strings.Fields(entry)
+	if len(fields) < 2 { //nolint:mnd
+		return false
+	}
+
+	_, ok := dns.StringToType[strings.ToUpper(fields[0])]
+
+	return ok
+}
+
 // IsEnabled implements `config.Configurable`.
 func (c *CustomDNS) IsEnabled() bool {
-	return len(c.Mapping) != 0
+	return len(c.Mapping) != 0 || len(c.SelfHostnames) != 0 || len(c.ReverseRanges) != 0 || len(c.Views) != 0 ||
+		c.ZoneFiles.IsEnabled() || c.Containers.IsEnabled() || c.ExternalDNS.IsEnabled() ||
+		c.DynamicUpdates.IsEnabled()
+}
+
+func (c *CustomDNS) validate(logger *logrus.Entry, auth *APIAuth) {
+	c.ZoneFiles.validate(logger)
+	c.ReverseRanges = validateReverseRanges(logger, c.ReverseRanges)
+	validateCustomDNSViews(logger, c.Views)
+	c.Containers.validate(logger)
+	c.ExternalDNS.validate(logger, auth)
+	c.DynamicUpdates.validate(logger)
+
+	for _, name := range c.SelfHostnames {
+		if !c.hasMapping(name) {
+			logger.Warnf("customDNS.selfHostnames: %s has no mapping, queries for it will get an empty answer", name)
+		}
+	}
+}
+
+// hasMapping returns if the domain or one of its parents is mapped
+func (c *CustomDNS) hasMapping(domain string) bool {
+	domain = strings.ToLower(dns.Fqdn(domain))
+
+	for _, mapping := range []CustomDNSMapping{c.Mapping, c.Zone.RRs} {
+		for key := range mapping {
+			if dns.IsSubDomain(strings.ToLower(dns.Fqdn(key)), domain) {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 // LogConfig implements `config.Configurable`.
@@ -98,17 +164,73 @@ func (c *CustomDNS) LogConfig(logger *logrus.Entry) {
 	logger.Debugf("TTL = %s", c.CustomTTL)
 	logger.Debugf("filterUnmappedTypes = %t", c.FilterUnmappedTypes)
 
+	if len(c.SelfHostnames) != 0 {
+		logger.Infof("selfHostnames = %s", strings.Join(c.SelfHostnames, ", "))
+	}
+
 	logger.Info("mapping:")
 
 	for key, val := range c.Mapping {
 		logger.Infof("  %s = %s", key, val)
 	}
+
+	if c.ZoneFiles.IsEnabled() {
+		logger.Info("zoneFiles:")
+		log.WithIndent(logger, "  ", c.ZoneFiles.LogConfig)
+	}
+
+	if len(c.Views) != 0 {
+		logger.Info("views:")
+
+		names := maps.Keys(c.Views)
+		slices.Sort(names)
+
+		for _, name := range names {
+			view := c.Views[name]
+
+			logger.Infof("  %s:", name)
+			log.WithIndent(logger, "    ", view.LogConfig)
+		}
+	}
+
+	if len(c.ReverseRanges) != 0 {
+		logger.Info("reverseRanges:")
+
+		for _, r := range c.ReverseRanges {
+			log.WithIndent(logger, "  ", r.LogConfig)
+		}
+	}
+
+	if c.Containers.IsEnabled() {
+		logger.Info("containers:")
+		log.WithIndent(logger, "  ", c.Containers.LogConfig)
+	}
+
+	if c.ExternalDNS.IsEnabled() {
+		logger.Info("externalDNS:")
+		log.WithIndent(logger, "  ", c.ExternalDNS.LogConfig)
+	}
+
+	if c.DynamicUpdates.IsEnabled() {
+		logger.Info("dynamicUpdates:")
+		log.WithIndent(logger, "  ", c.DynamicUpdates.LogConfig)
+	}
 }
 
-func configToRR(ipStr string) (dns.RR, error) {
-	ip := net.ParseIP(ipStr)
+func configToRR(entry string) (dns.RR, error) {
+	if isRecordEntry(entry) {
+		// the name is set when answering, the TTL is `customTTL`
+		rr, err := dns.NewRR(". " + entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid record '%s': %w", entry, err)
+		}
+
+		return rr, nil
+	}
+
+	ip := net.ParseIP(entry)
 	if ip == nil {
-		return nil, fmt.Errorf("invalid IP address '%s'", ipStr)
+		return nil, fmt.Errorf("invalid IP address '%s'", entry)
 	}
 
 	if ip.To4() != nil {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/netip"
 	"strings"
 
 	. "github.com/0xERR0R/blocky/helpertest"
@@ -46,6 +47,48 @@ var _ = Describe("CustomDNSConfig", func() {
 			})
 		})
 
+		When("only own hostnames are configured", func() {
+			It("should be true", func() {
+				cfg := CustomDNS{SelfHostnames: []string{"dns.example.com"}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+
+		When("only containers are enabled", func() {
+			It("should be true", func() {
+				cfg := CustomDNS{Containers: ContainerDNS{Enable: true}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+
+		When("only ExternalDNS is enabled", func() {
+			It("should be true", func() {
+				cfg := CustomDNS{ExternalDNS: ExternalDNS{Enable: true}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+
+		When("only reverse ranges are configured", func() {
+			It("should be true", func() {
+				cfg := CustomDNS{ReverseRanges: []ReverseRange{
+					{Prefix: netip.MustParsePrefix("192.168.178.0/24"), Domain: "dhcp.lan"},
+				}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+
+		When("only views are configured", func() {
+			It("should be true", func() {
+				cfg := CustomDNS{Views: map[string]CustomDNSView{"vpn": {Clients: []string{"10.8.0.0/24"}}}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+
 		When("disabled", func() {
 			It("should be false", func() {
 				cfg := CustomDNS{}
@@ -55,6 +98,16 @@ var _ = Describe("CustomDNSConfig", func() {
 		})
 	})
 
+	Describe("validate", func() {
+		It("should warn about own hostnames without mapping", func() {
+			cfg.SelfHostnames = []string{"sub.custom.domain", "dns.example.com"}
+
+			cfg.validate(logger, &APIAuth{})
+
+			Expect(hook.Messages).Should(ConsistOf(ContainSubstring("dns.example.com has no mapping")))
+		})
+	})
+
 	Describe("LogConfig", func() {
 		It("should log configuration", func() {
 			cfg.LogConfig(logger)
@@ -66,6 +119,46 @@ var _ = Describe("CustomDNSConfig", func() {
 				ContainSubstring("multiple.ips = "),
 			))
 		})
+
+		When("views are configured", func() {
+			It("should log them", func() {
+				cfg.Views = map[string]CustomDNSView{"vpn": {Clients: []string{"10.8.0.0/24"}}}
+
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					"views:",
+					"  vpn:",
+					"clients = 10.8.0.0/24",
+				))
+			})
+		})
+
+		When("containers are enabled", func() {
+			It("should log their configuration", func() {
+				cfg.Containers = ContainerDNS{Enable: true, Endpoint: "tcp://docker:2375", Label: "blocky.dns"}
+
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					"containers:",
+					ContainSubstring("endpoint = tcp://docker:2375"),
+				))
+			})
+		})
+
+		When("ExternalDNS is enabled", func() {
+			It("should log its configuration", func() {
+				cfg.ExternalDNS = ExternalDNS{Enable: true, Domains: []string{"k8s.lan"}}
+
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					"externalDNS:",
+					ContainSubstring("domains = k8s.lan"),
+				))
+			})
+		})
 	})
 
 	Describe("CustomDNSEntries UnmarshalYAML", func() {
@@ -112,6 +205,57 @@ var _ = Describe("CustomDNSConfig", func() {
 			Expect(c[2].(*dns.A).A).Should(Equal(net.ParseIP("3.4.5.6")))
 		})
 
+		It("Should parse a record with type", func() {
+			c := CustomDNSEntries{}
+			err := c.UnmarshalYAML(func(i interface{}) error {
+				*i.(*string) = `TXT "v=spf1 mx -all" "a, b"`
+
+				return nil
+			})
+			Expect(err).Should(Succeed())
+			Expect(c).Should(HaveLen(1))
+
+			Expect(c[0].(*dns.TXT).Txt).Should(Equal([]string{"v=spf1 mx -all", "a, b"}))
+		})
+
+		It("Should parse a list of addresses and records", func() {
+			c := CustomDNSEntries{}
+			err := c.UnmarshalYAML(func(i interface{}) error {
+				list, ok := i.(*[]string)
+				if !ok {
+					return errors.New("not a string")
+				}
+
+				*list = []string{
+					"192.168.178.2",
+					"MX 10 mail.home.lan",
+					"srv 0 5 5060 sip.home.lan.",
+					`CAA 0 issue "letsencrypt.org"`,
+					"HTTPS 1 . alpn=h2,h3",
+				}
+
+				return nil
+			})
+			Expect(err).Should(Succeed())
+			Expect(c).Should(HaveLen(5))
+
+			Expect(c[0].(*dns.A).A).Should(Equal(net.ParseIP("192.168.178.2")))
+			Expect(c[1].(*dns.MX).Mx).Should(Equal("mail.home.lan."))
+			Expect(c[2].(*dns.SRV).Port).Should(BeNumerically("==", 5060))
+			Expect(c[3].(*dns.CAA).Value).Should(Equal("letsencrypt.org"))
+			Expect(c[4].(*dns.HTTPS).String()).Should(HaveSuffix(`1 . alpn="h2,h3"`))
+		})
+
+		It("should fail if a record is invalid", func() {
+			c := CustomDNSEntries{}
+			err := c.UnmarshalYAML(func(i interface{}) error {
+				*i.(*string) = "MX mail.home.lan"
+
+				return nil
+			})
+			Expect(err).Should(MatchError(ContainSubstring("invalid record 'MX mail.home.lan'")))
+		})
+
 		It("should fail if wrong YAML format", func() {
 			c := &CustomDNSEntries{}
 			err := c.UnmarshalYAML(func(i interface{}) error {
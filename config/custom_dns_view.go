@@ -0,0 +1,35 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CustomDNSView answers the clients of the view with its own records for the domains of its mapping
+type CustomDNSView struct {
+	// Clients of the view: client names (wildcards supported), IP addresses, CIDRs, MAC addresses or OUIs
+	Clients []string         `yaml:"clients"`
+	Mapping CustomDNSMapping `yaml:"mapping"`
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *CustomDNSView) LogConfig(logger *logrus.Entry) {
+	logger.Infof("clients = %s", strings.Join(c.Clients, ", "))
+	logger.Info("mapping:")
+
+	for key, val := range c.Mapping {
+		logger.Infof("  %s = %s", key, val)
+	}
+}
+
+// validateCustomDNSViews removes views without clients, they can't match any client
+func validateCustomDNSViews(logger *logrus.Entry, views map[string]CustomDNSView) {
+	for name, view := range views {
+		if len(view.Clients) == 0 {
+			logger.Warnf("customDNS.views: view %s has no clients, ignoring it", name)
+
+			delete(views, name)
+		}
+	}
+}
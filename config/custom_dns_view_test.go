@@ -0,0 +1,51 @@
+package config
+
+import (
+	"net"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CustomDNSViewConfig", func() {
+	var cfg CustomDNSView
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		cfg = CustomDNSView{
+			Clients: []string{"192.168.178.0/24", "laptop*"},
+			Mapping: CustomDNSMapping{
+				"nas.home.lan": {&dns.A{A: net.ParseIP("192.168.178.10")}},
+			},
+		}
+	})
+
+	Describe("LogConfig", func() {
+		It("should log the clients and the mapping", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"clients = 192.168.178.0/24, laptop*",
+				"mapping:",
+				ContainSubstring("nas.home.lan = "),
+			))
+		})
+	})
+
+	Describe("validateCustomDNSViews", func() {
+		It("should remove views without clients", func() {
+			views := map[string]CustomDNSView{
+				"internal": cfg,
+				"empty":    {Mapping: cfg.Mapping},
+			}
+
+			validateCustomDNSViews(logger, views)
+
+			Expect(views).Should(HaveKey("internal"))
+			Expect(views).ShouldNot(HaveKey("empty"))
+			Expect(hook.Messages).Should(ConsistOf(ContainSubstring("view empty has no clients")))
+		})
+	})
+})
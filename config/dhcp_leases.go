@@ -0,0 +1,72 @@
+package config
+
+import (
+	"net/url"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DHCPLeases configuration of the DHCP leases used to resolve client names
+type DHCPLeases struct {
+	Sources       []DHCPLeaseSource `yaml:"sources"`
+	CheckInterval Duration          `yaml:"checkInterval" default:"10s"`
+}
+
+// DHCPLeaseSource is a lease file or the Kea control agent
+type DHCPLeaseSource struct {
+	Format DHCPLeaseFormat `yaml:"format"`
+	// Path of the lease file or URL of the Kea control agent
+	Source string `yaml:"source"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DHCPLeases) IsEnabled() bool {
+	return len(c.Sources) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DHCPLeases) LogConfig(logger *logrus.Entry) {
+	logger.Infof("checkInterval = %s", c.CheckInterval)
+	logger.Info("sources:")
+
+	for _, source := range c.Sources {
+		logger.Infof("  - %s: %s", source.Format, source.Source)
+	}
+}
+
+func (c *DHCPLeases) validate(logger *logrus.Entry) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	if c.CheckInterval <= 0 {
+		def := mustDefault[DHCPLeases]().CheckInterval
+
+		logger.Warnf("clientLookup.leases.checkInterval <= 0, setting to %s", def)
+
+		c.CheckInterval = def
+	}
+
+	sources := c.Sources[:0]
+
+	for _, source := range c.Sources {
+		if source.Source == "" {
+			logger.Warnf("clientLookup.leases: %s source without path, ignoring it", source.Format)
+
+			continue
+		}
+
+		if source.Format == DHCPLeaseFormatKeaApi {
+			if u, err := url.Parse(source.Source); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				logger.Warnf("clientLookup.leases: '%s' is not a HTTP(S) URL of the Kea control agent, ignoring it",
+					source.Source)
+
+				continue
+			}
+		}
+
+		sources = append(sources, source)
+	}
+
+	c.Sources = sources
+}
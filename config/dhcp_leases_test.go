@@ -0,0 +1,83 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DHCPLeasesConfig", func() {
+	var cfg DHCPLeases
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[DHCPLeases]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("enabled", func() {
+			It("should be true", func() {
+				cfg.Sources = []DHCPLeaseSource{{Format: DHCPLeaseFormatDnsmasq, Source: "/var/lib/misc/dnsmasq.leases"}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.Sources = []DHCPLeaseSource{{Format: DHCPLeaseFormatKeaApi, Source: "http://kea:8000"}}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"checkInterval = 10 seconds",
+				"  - kea-api: http://kea:8000",
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should accept valid sources", func() {
+			cfg.Sources = []DHCPLeaseSource{
+				{Format: DHCPLeaseFormatIsc, Source: "/var/lib/dhcp/dhcpd.leases"},
+				{Format: DHCPLeaseFormatKeaApi, Source: "https://kea:8000"},
+			}
+
+			cfg.validate(logger)
+
+			Expect(hook.Calls).Should(BeEmpty())
+			Expect(cfg.Sources).Should(HaveLen(2))
+		})
+
+		It("should ignore invalid sources", func() {
+			cfg.Sources = []DHCPLeaseSource{
+				{Format: DHCPLeaseFormatKea},
+				{Format: DHCPLeaseFormatKeaApi, Source: "/var/lib/kea/kea-leases4.csv"},
+				{Format: DHCPLeaseFormatDnsmasq, Source: "/var/lib/misc/dnsmasq.leases"},
+			}
+			cfg.CheckInterval = 0
+
+			cfg.validate(logger)
+
+			Expect(cfg.Sources).Should(Equal([]DHCPLeaseSource{
+				{Format: DHCPLeaseFormatDnsmasq, Source: "/var/lib/misc/dnsmasq.leases"},
+			}))
+			Expect(cfg.CheckInterval).Should(Equal(Duration(10 * time.Second)))
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("kea source without path"),
+				ContainSubstring("is not a HTTP(S) URL"),
+				ContainSubstring("clientLookup.leases.checkInterval <= 0"),
+			))
+		})
+	})
+})
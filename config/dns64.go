@@ -0,0 +1,54 @@
+package config
+
+import (
+	"net/netip"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DNS64 configures the synthesis of AAAA records from A records for IPv6-only clients behind NAT64 (RFC 6147)
+type DNS64 struct {
+	Enable bool `yaml:"enable" default:"false"`
+	// NAT64 prefix to embed the IPv4 addresses in (RFC 6052)
+	Prefix netip.Prefix `yaml:"prefix" default:"64:ff9b::/96"`
+	// AAAA records in these IPv6 networks count as missing, A records in these IPv4 networks are not synthesized
+	Exclude []netip.Prefix `yaml:"exclude" default:"[\"::ffff:0:0/96\"]"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DNS64) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DNS64) LogConfig(logger *logrus.Entry) {
+	logger.Infof("prefix = %s", c.Prefix)
+
+	if len(c.Exclude) != 0 {
+		logger.Info("exclude:")
+
+		for _, prefix := range c.Exclude {
+			logger.Infof("  - %s", prefix)
+		}
+	}
+}
+
+func (c *DNS64) validate(logger *logrus.Entry) {
+	// prefix lengths of RFC 6052, section 2.2
+	validLengths := []int{32, 40, 48, 56, 64, 96} //nolint:mnd
+
+	if !c.IsEnabled() {
+		return
+	}
+
+	if !c.Prefix.Addr().Is6() || c.Prefix.Addr().Is4In6() || !slices.Contains(validLengths, c.Prefix.Bits()) {
+		defaults := mustDefault[DNS64]()
+
+		logger.Warnf(
+			"dns64.prefix must be an IPv6 prefix with a length of %v, setting to %s",
+			validLengths, defaults.Prefix,
+		)
+		c.Prefix = defaults.Prefix
+	}
+}
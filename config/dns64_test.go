@@ -0,0 +1,86 @@
+package config
+
+import (
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("DNS64Config", func() {
+	var cfg DNS64
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[DNS64]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("defaults", func() {
+		It("should use the well-known prefix and exclude IPv4-mapped addresses", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+			Expect(cfg.Prefix.String()).Should(Equal("64:ff9b::/96"))
+			Expect(cfg.Exclude).Should(HaveLen(1))
+			Expect(cfg.Exclude[0].String()).Should(Equal("::ffff:0.0.0.0/96"))
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("prefix = 64:ff9b::/96"),
+				ContainSubstring("- ::ffff:0.0.0.0/96"),
+			))
+		})
+	})
+
+	Describe("UnmarshalYAML", func() {
+		It("should parse the prefix and exclusions", func() {
+			Expect(yaml.UnmarshalStrict([]byte(`
+enable: true
+prefix: 2001:db8:64::/64
+exclude:
+  - 10.0.0.0/8
+`), &cfg)).Should(Succeed())
+
+			Expect(cfg.Prefix.String()).Should(Equal("2001:db8:64::/64"))
+			Expect(cfg.Exclude).Should(HaveLen(1))
+			Expect(cfg.Exclude[0].String()).Should(Equal("10.0.0.0/8"))
+		})
+
+		It("should fail for an invalid prefix", func() {
+			Expect(yaml.UnmarshalStrict([]byte("prefix: 64:ff9b::"), &cfg)).ShouldNot(Succeed())
+		})
+	})
+
+	Describe("validate", func() {
+		BeforeEach(func() {
+			cfg.Enable = true
+		})
+
+		It("should accept the defaults", func() {
+			cfg.validate(logger)
+
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+
+		DescribeTable("should reset invalid prefixes to the default",
+			func(prefix string) {
+				cfg.Prefix = netip.MustParsePrefix(prefix)
+
+				cfg.validate(logger)
+
+				Expect(cfg.Prefix.String()).Should(Equal("64:ff9b::/96"))
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("dns64.prefix must be")))
+			},
+			Entry("IPv4", "10.0.0.0/8"),
+			Entry("invalid length", "2001:db8::/80"),
+		)
+	})
+})
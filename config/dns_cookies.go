@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/hex"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DNSCookieSecretLength is the length in bytes of the secret of the server cookies
+const DNSCookieSecretLength = 16
+
+// DNSCookies configures DNS Cookies (RFC 7873) on the DNS listeners: a client returning a valid server cookie
+// can't have a spoofed source address
+type DNSCookies struct {
+	Enable bool `yaml:"enable" default:"false"`
+	// Secret of the server cookies, hex encoded, shared by the instances behind the same address. Random if empty.
+	Secret string `yaml:"secret"`
+	// RateLimitFactor multiplies the rate limits of clients with a valid server cookie
+	RateLimitFactor uint `yaml:"rateLimitFactor" default:"4"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DNSCookies) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DNSCookies) LogConfig(logger *logrus.Entry) {
+	if c.Secret != "" {
+		logger.Info("secret: ", secretObfuscator)
+	} else {
+		logger.Info("secret: random")
+	}
+
+	logger.Infof("rateLimitFactor = %d", c.RateLimitFactor)
+}
+
+// SecretBytes returns the decoded secret, nil if it is random
+func (c *DNSCookies) SecretBytes() []byte {
+	secret, err := hex.DecodeString(c.Secret)
+	if err != nil || len(secret) != DNSCookieSecretLength {
+		return nil
+	}
+
+	return secret
+}
+
+func (c *DNSCookies) validate(logger *logrus.Entry) {
+	if c.Secret != "" && c.SecretBytes() == nil {
+		logger.Warnf("cookies.secret must be %d hex encoded bytes, using a random secret", DNSCookieSecretLength)
+
+		c.Secret = ""
+	}
+
+	if c.RateLimitFactor == 0 {
+		logger.Warn("cookies.rateLimitFactor is 0, setting to 1")
+
+		c.RateLimitFactor = 1
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNSCookiesConfig", func() {
+	var cfg DNSCookies
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[DNSCookies]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("enabled", func() {
+			It("should be true", func() {
+				cfg.Enable = true
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"secret: random",
+				"rateLimitFactor = 4",
+			}))
+		})
+
+		It("should hide the secret", func() {
+			cfg.Secret = "e5e973e5a6b2a43f48e7dc849e37bfcf"
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElement("secret: " + secretObfuscator))
+			Expect(hook.Messages).ShouldNot(ContainElement(ContainSubstring(cfg.Secret)))
+		})
+	})
+
+	Describe("SecretBytes", func() {
+		It("should decode the secret", func() {
+			cfg.Secret = "e5e973e5a6b2a43f48e7dc849e37bfcf"
+
+			Expect(cfg.SecretBytes()).Should(HaveLen(DNSCookieSecretLength))
+		})
+
+		It("should return nil for a random secret", func() {
+			Expect(cfg.SecretBytes()).Should(BeNil())
+		})
+	})
+
+	Describe("validate", func() {
+		It("should fix invalid values", func() {
+			cfg.Secret = "e5e973"
+			cfg.RateLimitFactor = 0
+
+			cfg.validate(logger)
+
+			Expect(cfg.Secret).Should(BeEmpty())
+			Expect(cfg.RateLimitFactor).Should(BeEquivalentTo(1))
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("cookies.secret must be 16 hex encoded bytes"),
+				ContainSubstring("cookies.rateLimitFactor is 0"),
+			))
+		})
+	})
+})
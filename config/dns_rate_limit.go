@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/sirupsen/logrus"
+)
+
+// DNSRateLimit limits the queries of each client on the DNS listeners
+type DNSRateLimit struct {
+	// Limits of all clients without own limits
+	DNSClientRateLimit `yaml:",inline"`
+
+	Action RateLimitAction `yaml:"action" default:"refuse"`
+	// With action slip, every n-th limited query over UDP is answered truncated, 0 drops all
+	Slip uint `yaml:"slip" default:"2"`
+	// Limits per client IP or CIDR, replacing the default limits
+	Clients map[string]DNSClientRateLimit `yaml:"clients"`
+}
+
+// DNSClientRateLimit are the limits of a client, disabled limits don't limit the client
+type DNSClientRateLimit struct {
+	// All queries
+	RateLimit `yaml:",inline"`
+	// Queries answered with NXDOMAIN
+	NXDomain RateLimit `yaml:"nxdomain"`
+	// Queries of type ANY
+	Any RateLimit `yaml:"any"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DNSClientRateLimit) IsEnabled() bool {
+	return c.RateLimit.IsEnabled() || c.NXDomain.IsEnabled() || c.Any.IsEnabled()
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DNSClientRateLimit) LogConfig(logger *logrus.Entry) {
+	if !c.IsEnabled() {
+		logger.Info("unlimited")
+
+		return
+	}
+
+	logLimit := func(name string, limit *RateLimit) {
+		if limit.IsEnabled() {
+			logger.Infof("%-8s = %d/s, burst %d", name, limit.Rate, limit.EffectiveBurst())
+		}
+	}
+
+	logLimit("queries", &c.RateLimit)
+	logLimit("nxdomain", &c.NXDomain)
+	logLimit("any", &c.Any)
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DNSRateLimit) IsEnabled() bool {
+	if c.DNSClientRateLimit.IsEnabled() {
+		return true
+	}
+
+	for _, limit := range c.Clients {
+		if limit.IsEnabled() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DNSRateLimit) LogConfig(logger *logrus.Entry) {
+	logger.Infof("action = %s", c.Action)
+
+	if c.Action == RateLimitActionSlip {
+		logger.Infof("slip   = %d", c.Slip)
+	}
+
+	logger.Info("default:")
+	log.WithIndent(logger, "  ", c.DNSClientRateLimit.LogConfig)
+
+	keys := make([]string, 0, len(c.Clients))
+	for key := range c.Clients {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		limit := c.Clients[key]
+
+		logger.Infof("%s:", key)
+		log.WithIndent(logger, "  ", limit.LogConfig)
+	}
+}
+
+func (c *DNSRateLimit) validate(logger *logrus.Entry) {
+	for key := range c.Clients {
+		if _, err := ParseClientPrefix(key); err != nil {
+			logger.Warnf("rateLimit.clients: ignoring %s", err)
+			delete(c.Clients, key)
+		}
+	}
+}
+
+// ParseClientPrefix parses a client IP or CIDR, an IP is a prefix of the full address length
+func ParseClientPrefix(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid client CIDR '%s': %w", value, err)
+		}
+
+		return prefix.Masked(), nil
+	}
+
+	ip, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid client IP '%s': %w", value, err)
+	}
+
+	return netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()), nil
+}
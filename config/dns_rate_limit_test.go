@@ -0,0 +1,115 @@
+package config
+
+import (
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNSRateLimitConfig", func() {
+	var cfg DNSRateLimit
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[DNSRateLimit]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("the default limit is enabled", func() {
+			It("should be true", func() {
+				cfg.NXDomain.Rate = 10
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+
+		When("only a client limit is enabled", func() {
+			It("should be true", func() {
+				cfg.Clients = map[string]DNSClientRateLimit{"10.0.0.0/8": {Any: RateLimit{Rate: 1}}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.Rate = 100
+			cfg.Burst = 200
+			cfg.Any.Rate = 1
+			cfg.Action = RateLimitActionSlip
+			cfg.Clients = map[string]DNSClientRateLimit{"192.168.178.1": {}}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"action = slip",
+				"slip   = 2",
+				"default:",
+				"queries  = 100/s, burst 200",
+				"any      = 1/s, burst 1",
+				"192.168.178.1:",
+				"unlimited",
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should remove invalid clients", func() {
+			cfg.Clients = map[string]DNSClientRateLimit{
+				"10.0.0.0/8": {},
+				"laptop":     {},
+			}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Clients).Should(HaveKey("10.0.0.0/8"))
+			Expect(cfg.Clients).ShouldNot(HaveKey("laptop"))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("invalid client IP 'laptop'")))
+		})
+	})
+
+	Describe("unmarshal", func() {
+		It("should read the inline limits", func() {
+			var cfg Config
+
+			Expect(unmarshalConfig(logger, []byte(`
+rateLimit:
+  rate: 50
+  nxdomain:
+    rate: 5
+  action: drop
+  clients:
+    192.168.178.0/24:
+      rate: 500
+      burst: 1000
+`), &cfg)).Should(Succeed())
+
+			Expect(cfg.RateLimit.Rate).Should(BeEquivalentTo(50))
+			Expect(cfg.RateLimit.NXDomain.Rate).Should(BeEquivalentTo(5))
+			Expect(cfg.RateLimit.Action).Should(Equal(RateLimitActionDrop))
+			Expect(cfg.RateLimit.Clients).Should(HaveKeyWithValue("192.168.178.0/24", DNSClientRateLimit{
+				RateLimit: RateLimit{Rate: 500, Burst: 1000},
+			}))
+		})
+	})
+
+	DescribeTable("ParseClientPrefix",
+		func(value string, expected netip.Prefix) {
+			Expect(ParseClientPrefix(value)).Should(Equal(expected))
+		},
+		Entry("IPv4", "192.168.178.3", netip.MustParsePrefix("192.168.178.3/32")),
+		Entry("IPv4 CIDR", "192.168.178.3/24", netip.MustParsePrefix("192.168.178.0/24")),
+		Entry("IPv6", "2001:db8::1", netip.MustParsePrefix("2001:db8::1/128")),
+		Entry("IPv6 CIDR", "2001:db8::/32", netip.MustParsePrefix("2001:db8::/32")),
+	)
+})
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// minDNSMessageSize is the size of the DNS header, no message is smaller
+const minDNSMessageSize = 12
+
+// DoH configures the DNS-over-HTTPS endpoints (RFC 8484) of the HTTP(S) listeners
+type DoH struct {
+	// Path of the endpoint, a client name can be appended as additional segment
+	Path string `yaml:"path" default:"/dns-query"`
+	// Paths of additional endpoints mapped to the client name of their requests, e.g. to select client groups
+	Paths map[string]string `yaml:"paths"`
+	// Maximum size of the DNS message of a request
+	MaxMessageSize uint16 `yaml:"maxMessageSize" default:"512"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DoH) IsEnabled() bool {
+	return c.Path != ""
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DoH) LogConfig(logger *logrus.Entry) {
+	logger.Infof("path           = %s", c.Path)
+	logger.Infof("maxMessageSize = %d bytes", c.MaxMessageSize)
+
+	if len(c.Paths) == 0 {
+		return
+	}
+
+	paths := maps.Keys(c.Paths)
+	slices.Sort(paths)
+
+	logger.Info("paths:")
+
+	for _, path := range paths {
+		logger.Infof("  %s = %s", path, c.Paths[path])
+	}
+}
+
+func (c *DoH) validate(logger *logrus.Entry) {
+	defaults := mustDefault[DoH]()
+
+	c.Path = normalizeHTTPPath(c.Path)
+	if c.Path == "" {
+		logger.Warnf("doh.path is empty, setting to %s", defaults.Path)
+		c.Path = defaults.Path
+	}
+
+	paths := make(map[string]string, len(c.Paths))
+
+	for path, clientName := range c.Paths {
+		path = normalizeHTTPPath(path)
+		clientName = strings.TrimSpace(clientName)
+
+		switch {
+		case path == "" || clientName == "":
+			logger.Warnf("doh.paths: path '%s' needs a path and a client name, ignoring it", path)
+
+			continue
+
+		case path == c.Path || strings.HasPrefix(path, c.Path+"/"):
+			logger.Warnf("doh.paths: path '%s' is part of doh.path, ignoring it", path)
+
+			continue
+		}
+
+		paths[path] = clientName
+	}
+
+	c.Paths = paths
+
+	if c.MaxMessageSize < minDNSMessageSize {
+		logger.Warnf("doh.maxMessageSize < %d, setting to %d", minDNSMessageSize, defaults.MaxMessageSize)
+		c.MaxMessageSize = defaults.MaxMessageSize
+	}
+}
+
+// normalizeHTTPPath returns the path with a leading and without trailing slash, empty for the root
+func normalizeHTTPPath(path string) string {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" {
+		return ""
+	}
+
+	return "/" + path
+}
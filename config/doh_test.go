@@ -0,0 +1,71 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DoHConfig", func() {
+	var cfg DoH
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[DoH]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be true by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.Paths = map[string]string{"/kids": "kids", "/adults": "adults"}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"path           = /dns-query",
+				"maxMessageSize = 512 bytes",
+				"paths:",
+				"  /adults = adults",
+				"  /kids = kids",
+			}))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should normalize the paths", func() {
+			cfg.Path = "doh/"
+			cfg.Paths = map[string]string{"kids/": " kids "}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Path).Should(Equal("/doh"))
+			Expect(cfg.Paths).Should(Equal(map[string]string{"/kids": "kids"}))
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+
+		It("should fix invalid values", func() {
+			cfg.Path = "/"
+			cfg.MaxMessageSize = 10
+			cfg.Paths = map[string]string{
+				"/kids":           "",
+				"/dns-query/kids": "kids",
+				"/adults":         "adults",
+			}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Path).Should(Equal("/dns-query"))
+			Expect(cfg.MaxMessageSize).Should(BeEquivalentTo(512))
+			Expect(cfg.Paths).Should(Equal(map[string]string{"/adults": "adults"}))
+			Expect(hook.Calls).Should(HaveLen(4))
+		})
+	})
+})
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// DynamicUpdates configures the zones accepting DNS UPDATE messages (RFC 2136) for the custom DNS resolver
+type DynamicUpdates struct {
+	// Zones accepting updates
+	Zones []string `yaml:"zones"`
+	// TSIG keys by name as base64 secrets, updates must be signed with one of them
+	Keys map[string]string `yaml:"keys"`
+	// File to persist the records in, they are kept in memory only if empty
+	File string `yaml:"file"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *DynamicUpdates) IsEnabled() bool {
+	return len(c.Zones) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *DynamicUpdates) LogConfig(logger *logrus.Entry) {
+	keys := maps.Keys(c.Keys)
+	slices.Sort(keys)
+
+	logger.Infof("zones = %s", strings.Join(c.Zones, ", "))
+	logger.Infof("keys  = %s", strings.Join(keys, ", "))
+
+	if c.File == "" {
+		logger.Info("file  = none, records are lost on restart")
+	} else {
+		logger.Infof("file  = %s", c.File)
+	}
+}
+
+func (c *DynamicUpdates) validate(logger *logrus.Entry) {
+	zones := make([]string, 0, len(c.Zones))
+
+	for _, zone := range c.Zones {
+		zone = strings.ToLower(strings.Trim(strings.TrimSpace(zone), "."))
+		if zone == "" {
+			logger.Warn("customDNS.dynamicUpdates.zones contains an empty zone, ignoring it")
+
+			continue
+		}
+
+		zones = append(zones, zone)
+	}
+
+	c.Zones = zones
+
+	keys := make(map[string]string, len(c.Keys))
+
+	for name, secret := range c.Keys {
+		if _, err := base64.StdEncoding.DecodeString(secret); err != nil {
+			logger.Warnf("customDNS.dynamicUpdates.keys: secret of %s is not valid base64, ignoring it", name)
+
+			continue
+		}
+
+		keys[strings.ToLower(strings.TrimSuffix(name, "."))] = secret
+	}
+
+	c.Keys = keys
+
+	if c.IsEnabled() && len(c.Keys) == 0 {
+		logger.Warn("customDNS.dynamicUpdates has no keys, all updates will be refused")
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DynamicUpdatesConfig", func() {
+	var cfg DynamicUpdates
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		cfg = DynamicUpdates{
+			Zones: []string{"home.lan"},
+			Keys:  map[string]string{"dhcp-key": "c2VjcmV0"},
+		}
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be true with zones", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+
+		It("should be false without zones", func() {
+			cfg.Zones = nil
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should not log the secrets", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"zones = home.lan",
+				"keys  = dhcp-key",
+				"file  = none, records are lost on restart",
+			}))
+		})
+
+		It("should log the file", func() {
+			cfg.File = "/var/lib/blocky/updates.zone"
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElement("file  = /var/lib/blocky/updates.zone"))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should normalize the zones and key names", func() {
+			cfg.Zones = []string{"Home.Lan.", " "}
+			cfg.Keys = map[string]string{"DHCP-key.": "c2VjcmV0"}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Zones).Should(Equal([]string{"home.lan"}))
+			Expect(cfg.Keys).Should(Equal(map[string]string{"dhcp-key": "c2VjcmV0"}))
+			Expect(hook.Messages).Should(ConsistOf(ContainSubstring("contains an empty zone")))
+		})
+
+		It("should ignore invalid secrets", func() {
+			cfg.Keys = map[string]string{"dhcp-key": "not base64!"}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Keys).Should(BeEmpty())
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("secret of dhcp-key is not valid base64"),
+				ContainSubstring("has no keys, all updates will be refused"),
+			))
+		})
+	})
+})
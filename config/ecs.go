@@ -48,6 +48,9 @@ type ECS struct {
 	Forward     bool      `yaml:"forward" default:"false"`
 	IPv4Mask    ECSv4Mask `yaml:"ipv4Mask" default:"0"`
 	IPv6Mask    ECSv6Mask `yaml:"ipv6Mask" default:"0"`
+
+	// Upstreams receiving the ECS option, it is removed for all others. Empty means all upstreams.
+	Upstreams []Upstream `yaml:"upstreams"`
 }
 
 // IsEnabled returns true if the ECS resolver is enabled
@@ -61,6 +64,14 @@ func (c *ECS) LogConfig(logger *logrus.Entry) {
 	logger.Infof("Forward       = %t", c.Forward)
 	logger.Infof("IPv4 netmask  = %d", c.IPv4Mask)
 	logger.Infof("IPv6 netmask  = %d", c.IPv6Mask)
+
+	if len(c.Upstreams) != 0 {
+		logger.Info("Upstreams:")
+
+		for _, upstream := range c.Upstreams {
+			logger.Infof("  - %s", upstream)
+		}
+	}
 }
 
 // unmarshalInternal unmarshals the subnet mask from the given text and checks if the value is valid
@@ -83,6 +83,21 @@ var _ = Describe("ECS", func() {
 				ContainSubstring("IPv6 netmask"),
 			))
 		})
+
+		When("upstreams are selected", func() {
+			BeforeEach(func() {
+				c.Upstreams = []Upstream{{Net: NetProtocolTcpUdp, Host: "1.1.1.1", Port: 53}}
+			})
+
+			It("should log the upstreams", func() {
+				c.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("Upstreams:"),
+					ContainSubstring("1.1.1.1"),
+				))
+			})
+		})
 	})
 
 	Describe("Parse", func() {
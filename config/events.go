@@ -0,0 +1,84 @@
+package config
+
+import (
+	"net/url"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Events configures the publishing of events to a message broker, e.g. for home automation or SIEM pipelines
+type Events struct {
+	Type EventsType `yaml:"type" default:"none"`
+	// mqtt: URL of the broker (tcp://, mqtt://, ssl:// or mqtts://), kafkaRest: URL of the Kafka REST proxy
+	URL      string `yaml:"url"`
+	Username string `yaml:"username" default:""`
+	Password string `yaml:"password" default:""`
+	// mqtt: prefix of the topic per event type, kafkaRest: topic of all events
+	Topic    string      `yaml:"topic" default:"blocky"`
+	Events   []EventType `yaml:"events"`
+	ClientID string      `yaml:"clientID" default:"blocky"`
+	// Maximal number of events waiting to be published, further events are dropped
+	QueueSize uint `yaml:"queueSize" default:"1000"`
+
+	// TLS is set from the global `tls` config if a `tls.events` section exists
+	TLS *TLSPolicy `yaml:"-"`
+}
+
+// SetDefaults implements `defaults.Setter`.
+func (c *Events) SetDefaults() {
+	c.Events = EventTypeValues()
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Events) IsEnabled() bool {
+	return c.Type != EventsTypeNone
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Events) LogConfig(logger *logrus.Entry) {
+	logger.Infof("type: %s", c.Type)
+	logger.Infof("url: %s", c.URL)
+
+	if c.Username != "" {
+		logger.Infof("username: %s", c.Username)
+		logger.Infof("password: %s", secretObfuscator)
+	}
+
+	logger.Infof("topic: %s", c.Topic)
+	logger.Infof("events: %s", c.Events)
+
+	if c.Type == EventsTypeMqtt {
+		logger.Infof("clientID: %s", c.ClientID)
+	}
+
+	logger.Debugf("queueSize: %d", c.QueueSize)
+	logger.Infof("tls: %t", c.TLS != nil)
+}
+
+func (c *Events) validate(logger *logrus.Entry) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	if _, err := url.Parse(c.URL); c.URL == "" || err != nil {
+		logger.Warnf("events.url '%s' is not a valid URL, events are not published", c.URL)
+
+		c.Type = EventsTypeNone
+
+		return
+	}
+
+	def := mustDefault[Events]()
+
+	if c.Topic == "" {
+		logger.Warnf("events.topic is empty, setting to %s", def.Topic)
+
+		c.Topic = def.Topic
+	}
+
+	if c.QueueSize == 0 {
+		logger.Warnf("events.queueSize is 0, setting to %d", def.QueueSize)
+
+		c.QueueSize = def.QueueSize
+	}
+}
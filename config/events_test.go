@@ -0,0 +1,82 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Events", func() {
+	var cfg Events
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		cfg = mustDefault[Events]()
+		cfg.Type = EventsTypeMqtt
+		cfg.URL = "tcp://localhost:1883"
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			cfg := mustDefault[Events]()
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("a broker is configured", func() {
+			It("should be true", func() {
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("SetDefaults", func() {
+		It("should publish all events", func() {
+			Expect(cfg.Events).Should(Equal(EventTypeValues()))
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log the configuration without the password", func() {
+			cfg.Username = "user"
+			cfg.Password = "secret"
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("type: mqtt"),
+				ContainSubstring("url: tcp://localhost:1883"),
+				ContainSubstring("topic: blocky"),
+				ContainSubstring("clientID: blocky"),
+			))
+			Expect(hook.Messages).ShouldNot(ContainElement(ContainSubstring("secret")))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should disable publishing without URL", func() {
+			cfg.URL = ""
+
+			cfg.validate(logger)
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("is not a valid URL")))
+		})
+
+		It("should set the defaults of empty values", func() {
+			cfg.Topic = ""
+			cfg.QueueSize = 0
+
+			cfg.validate(logger)
+
+			Expect(cfg.Topic).Should(Equal("blocky"))
+			Expect(cfg.QueueSize).Should(BeNumerically("==", 1000))
+		})
+
+		It("should keep valid values", func() {
+			cfg.validate(logger)
+
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+	})
+})
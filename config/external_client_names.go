@@ -0,0 +1,89 @@
+package config
+
+import (
+	"net/url"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExternalClientNames configuration of the external sources of client names, like a network controller
+type ExternalClientNames struct {
+	Sources       []ExternalClientNamesSource `yaml:"sources"`
+	RefreshPeriod Duration                    `yaml:"refreshPeriod" default:"5m"`
+	// Timeout of one fetch or script run
+	Timeout Duration `yaml:"timeout" default:"10s"`
+}
+
+// ExternalClientNamesSource returns the JSON mapping of client IPs to names and groups,
+// either from a HTTP(S) URL or from the output of a command
+type ExternalClientNamesSource struct {
+	URL     string `yaml:"url"`
+	Command string `yaml:"command"`
+}
+
+// String returns the URL or the command of the source
+func (s ExternalClientNamesSource) String() string {
+	if s.URL != "" {
+		return s.URL
+	}
+
+	return s.Command
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *ExternalClientNames) IsEnabled() bool {
+	return len(c.Sources) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ExternalClientNames) LogConfig(logger *logrus.Entry) {
+	logger.Infof("refreshPeriod = %s", c.RefreshPeriod)
+	logger.Infof("timeout = %s", c.Timeout)
+	logger.Info("sources:")
+
+	for _, source := range c.Sources {
+		logger.Infof("  - %s", source)
+	}
+}
+
+func (c *ExternalClientNames) validate(logger *logrus.Entry) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	def := mustDefault[ExternalClientNames]()
+
+	if c.RefreshPeriod <= 0 {
+		logger.Warnf("clientLookup.external.refreshPeriod <= 0, setting to %s", def.RefreshPeriod)
+
+		c.RefreshPeriod = def.RefreshPeriod
+	}
+
+	if c.Timeout <= 0 {
+		logger.Warnf("clientLookup.external.timeout <= 0, setting to %s", def.Timeout)
+
+		c.Timeout = def.Timeout
+	}
+
+	sources := c.Sources[:0]
+
+	for _, source := range c.Sources {
+		if (source.URL == "") == (source.Command == "") {
+			logger.Warn("clientLookup.external: a source needs either url or command, ignoring it")
+
+			continue
+		}
+
+		if source.URL != "" {
+			if u, err := url.Parse(source.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				logger.Warnf("clientLookup.external: '%s' is not a HTTP(S) URL, ignoring it", source.URL)
+
+				continue
+			}
+		}
+
+		sources = append(sources, source)
+	}
+
+	c.Sources = sources
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExternalClientNamesConfig", func() {
+	var cfg ExternalClientNames
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[ExternalClientNames]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("enabled", func() {
+			It("should be true", func() {
+				cfg.Sources = []ExternalClientNamesSource{{URL: "http://controller/clients.json"}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.Sources = []ExternalClientNamesSource{
+				{URL: "http://controller/clients.json"},
+				{Command: "/usr/local/bin/clients.sh"},
+			}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"refreshPeriod = 5 minutes",
+				"timeout = 10 seconds",
+				"  - http://controller/clients.json",
+				"  - /usr/local/bin/clients.sh",
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should accept valid sources", func() {
+			cfg.Sources = []ExternalClientNamesSource{
+				{URL: "https://controller/clients.json"},
+				{Command: "/usr/local/bin/clients.sh"},
+			}
+
+			cfg.validate(logger)
+
+			Expect(hook.Calls).Should(BeEmpty())
+			Expect(cfg.Sources).Should(HaveLen(2))
+		})
+
+		It("should ignore invalid sources", func() {
+			cfg.Sources = []ExternalClientNamesSource{
+				{},
+				{URL: "http://controller/clients.json", Command: "/usr/local/bin/clients.sh"},
+				{URL: "/etc/clients.json"},
+				{Command: "/usr/local/bin/clients.sh"},
+			}
+			cfg.RefreshPeriod = 0
+			cfg.Timeout = -1
+
+			cfg.validate(logger)
+
+			Expect(cfg.Sources).Should(Equal([]ExternalClientNamesSource{{Command: "/usr/local/bin/clients.sh"}}))
+			Expect(cfg.RefreshPeriod).Should(Equal(Duration(5 * time.Minute)))
+			Expect(cfg.Timeout).Should(Equal(Duration(10 * time.Second)))
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("needs either url or command"),
+				ContainSubstring("is not a HTTP(S) URL"),
+				ContainSubstring("clientLookup.external.refreshPeriod <= 0"),
+				ContainSubstring("clientLookup.external.timeout <= 0"),
+			))
+		})
+	})
+})
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExternalDNS configuration of the webhook provider for Kubernetes ExternalDNS
+type ExternalDNS struct {
+	Enable bool `yaml:"enable" default:"false"`
+	// Domains ExternalDNS may manage records in, required
+	Domains []string `yaml:"domains"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *ExternalDNS) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ExternalDNS) LogConfig(logger *logrus.Entry) {
+	logger.Infof("domains = %s", strings.Join(c.Domains, ", "))
+}
+
+// validate normalizes the domains and disables the webhook if it would be unrestricted:
+// it needs at least one domain and the authentication of the control endpoints
+func (c *ExternalDNS) validate(logger *logrus.Entry, auth *APIAuth) {
+	domains := make([]string, 0, len(c.Domains))
+
+	for _, domain := range c.Domains {
+		domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+		if domain == "" {
+			logger.Warn("customDNS.externalDNS.domains contains an empty domain, ignoring it")
+
+			continue
+		}
+
+		domains = append(domains, domain)
+	}
+
+	c.Domains = domains
+
+	if !c.Enable {
+		return
+	}
+
+	switch {
+	case len(c.Domains) == 0:
+		logger.Warn("customDNS.externalDNS.domains is empty, disabling the webhook")
+
+		c.Enable = false
+
+	case !auth.IsEnabled() || auth.Endpoints.Control == APIRoleNone:
+		logger.Warn("customDNS.externalDNS requires the authentication of the control endpoints (api.auth), " +
+			"disabling the webhook")
+
+		c.Enable = false
+	}
+}
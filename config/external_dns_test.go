@@ -0,0 +1,102 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExternalDNSConfig", func() {
+	var cfg ExternalDNS
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[ExternalDNS]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("enabled", func() {
+			It("should be true", func() {
+				cfg.Enable = true
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log the domains", func() {
+			cfg.Domains = []string{"k8s.lan", "example.com"}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{"domains = k8s.lan, example.com"}))
+		})
+	})
+
+	Describe("validate", func() {
+		var auth APIAuth
+
+		BeforeEach(func() {
+			var err error
+
+			auth, err = WithDefaults[APIAuth]()
+			Expect(err).Should(Succeed())
+
+			auth.Tokens = []APIToken{{Name: "externaldns", Token: "secret", Role: APIRoleAdmin}}
+
+			cfg.Enable = true
+			cfg.Domains = []string{"k8s.lan"}
+		})
+
+		It("should normalize the domains", func() {
+			cfg.Domains = []string{"K8s.lan.", " ", "example.com"}
+
+			cfg.validate(logger, &auth)
+
+			Expect(cfg.Domains).Should(Equal([]string{"k8s.lan", "example.com"}))
+			Expect(cfg.Enable).Should(BeTrue())
+			Expect(hook.Messages).Should(ConsistOf(ContainSubstring("contains an empty domain")))
+		})
+
+		When("no domain is configured", func() {
+			It("should disable the webhook", func() {
+				cfg.Domains = []string{"."}
+
+				cfg.validate(logger, &auth)
+
+				Expect(cfg.Enable).Should(BeFalse())
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("domains is empty")))
+			})
+		})
+
+		When("the API has no authentication", func() {
+			It("should disable the webhook", func() {
+				auth.Tokens = nil
+
+				cfg.validate(logger, &auth)
+
+				Expect(cfg.Enable).Should(BeFalse())
+				Expect(hook.Messages).Should(ConsistOf(ContainSubstring("requires the authentication")))
+			})
+		})
+
+		When("the control endpoints are public", func() {
+			It("should disable the webhook", func() {
+				auth.Endpoints.Control = APIRoleNone
+
+				cfg.validate(logger, &auth)
+
+				Expect(cfg.Enable).Should(BeFalse())
+				Expect(hook.Messages).Should(ConsistOf(ContainSubstring("requires the authentication")))
+			})
+		})
+	})
+})
@@ -1,23 +1,89 @@
 package config
 
 import (
+	"slices"
+	"strings"
+
+	"github.com/0xERR0R/blocky/log"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 )
 
 type Filtering struct {
 	QueryTypes QTypeSet `yaml:"queryTypes"`
+	// SuppressAAAA answers AAAA queries with NODATA if the domain has A records
+	SuppressAAAA bool `yaml:"suppressAAAA" default:"false"`
+	// ClientGroups replaces the filtering for clients matching the key (client name, IP, CIDR or MAC address)
+	ClientGroups     map[string]FilteringGroup `yaml:"clientGroups"`
+	RebindProtection RebindProtection          `yaml:"rebindProtection"`
+}
+
+// FilteringGroup is the query filtering of a client group
+type FilteringGroup struct {
+	QueryTypes   QTypeSet `yaml:"queryTypes"`
+	SuppressAAAA bool     `yaml:"suppressAAAA"`
+}
+
+// RebindProtection configures the DNS rebinding protection: upstream answers for public domains must not
+// contain private, loopback or link-local addresses
+type RebindProtection struct {
+	Enable bool         `yaml:"enable" default:"false"`
+	Action RebindAction `yaml:"action" default:"nxdomain"`
+	// Allowlist contains domains (and their subdomains) which may resolve to private addresses, e.g. for split DNS
+	Allowlist []string `yaml:"allowlist"`
 }
 
 // IsEnabled implements `config.Configurable`.
 func (c *Filtering) IsEnabled() bool {
-	return len(c.QueryTypes) != 0
+	return len(c.QueryTypes) != 0 || c.SuppressAAAA || len(c.ClientGroups) != 0
 }
 
 // LogConfig implements `config.Configurable`.
 func (c *Filtering) LogConfig(logger *logrus.Entry) {
+	FilteringGroup{QueryTypes: c.QueryTypes, SuppressAAAA: c.SuppressAAAA}.logConfig(logger)
+
+	if len(c.ClientGroups) == 0 {
+		return
+	}
+
+	logger.Info("client groups:")
+
+	clients := maps.Keys(c.ClientGroups)
+	slices.Sort(clients)
+
+	for _, client := range clients {
+		logger.Infof("  %s:", client)
+
+		log.WithIndent(logger, "    ", c.ClientGroups[client].logConfig)
+	}
+}
+
+func (c FilteringGroup) logConfig(logger *logrus.Entry) {
 	logger.Info("query types:")
 
 	for qType := range c.QueryTypes {
 		logger.Infof("  - %s", qType)
 	}
+
+	if c.SuppressAAAA {
+		logger.Info("suppressAAAA = true")
+	}
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *RebindProtection) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *RebindProtection) LogConfig(logger *logrus.Entry) {
+	logger.Infof("action = %s", c.Action)
+
+	if len(c.Allowlist) != 0 {
+		logger.Infof("allowlist = %s", strings.Join(c.Allowlist, ", "))
+	}
+}
+
+func (c *RebindProtection) validate(_ *logrus.Entry) {
+	c.Allowlist = normalizeDomains(c.Allowlist)
 }
@@ -54,4 +54,74 @@ var _ = Describe("FilteringConfig", func() {
 			))
 		})
 	})
+
+	Describe("client groups", func() {
+		BeforeEach(func() {
+			cfg = Filtering{
+				ClientGroups: map[string]FilteringGroup{
+					"iot*": {QueryTypes: NewQTypeSet(AAAA), SuppressAAAA: true},
+				},
+			}
+		})
+
+		It("should be enabled", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+
+		It("should log the groups", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"client groups:",
+				"  iot*:",
+				"  - AAAA",
+				"suppressAAAA = true",
+			))
+		})
+	})
+
+	When("AAAA suppression is enabled", func() {
+		It("should be enabled", func() {
+			cfg := Filtering{SuppressAAAA: true}
+
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("RebindProtection", func() {
+		var rebindCfg RebindProtection
+
+		BeforeEach(func() {
+			rebindCfg = RebindProtection{
+				Enable:    true,
+				Action:    RebindActionDrop,
+				Allowlist: []string{"plex.direct"},
+			}
+		})
+
+		It("should be disabled by default", func() {
+			cfg := RebindProtection{}
+			Expect(defaults.Set(&cfg)).Should(Succeed())
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+			Expect(cfg.Action).Should(Equal(RebindActionNxdomain))
+		})
+
+		It("should log configuration", func() {
+			rebindCfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"action = drop",
+				"allowlist = plex.direct",
+			))
+		})
+
+		It("should normalize the allowlist", func() {
+			rebindCfg.Allowlist = []string{" Plex.Direct. ", ""}
+
+			rebindCfg.validate(logger)
+
+			Expect(rebindCfg.Allowlist).Should(Equal([]string{"plex.direct"}))
+		})
+	})
 })
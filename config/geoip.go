@@ -0,0 +1,37 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// GeoIP configures the IP databases (MaxMind or IPinfo MMDB files) used to look up information about answer IPs
+type GeoIP struct {
+	// ASNDatabase is the path of a MMDB file with the autonomous system numbers of networks
+	ASNDatabase string `yaml:"asnDatabase"`
+	// CountryDatabase is the path of a MMDB file with the countries of networks, can be the same as ASNDatabase
+	CountryDatabase string `yaml:"countryDatabase"`
+	// ReloadInterval is how often the files are checked for changes, 0 disables reloading
+	ReloadInterval Duration `yaml:"reloadInterval" default:"1m"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *GeoIP) IsEnabled() bool {
+	return c.ASNDatabase != "" || c.CountryDatabase != ""
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *GeoIP) LogConfig(logger *logrus.Entry) {
+	if c.ASNDatabase != "" {
+		logger.Infof("asnDatabase = %s", c.ASNDatabase)
+	}
+
+	if c.CountryDatabase != "" {
+		logger.Infof("countryDatabase = %s", c.CountryDatabase)
+	}
+
+	if c.ReloadInterval > 0 {
+		logger.Infof("reloadInterval = %s", c.ReloadInterval)
+	} else {
+		logger.Info("reloadInterval = disabled")
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GeoIP", func() {
+	var cfg GeoIP
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		cfg = GeoIP{
+			ASNDatabase:     "/var/lib/GeoLite2-ASN.mmdb",
+			CountryDatabase: "/var/lib/GeoLite2-Country.mmdb",
+			ReloadInterval:  Duration(time.Minute),
+		}
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be true with a database", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+
+			cfg.ASNDatabase = ""
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+
+		It("should be false by default", func() {
+			cfg := GeoIP{}
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log the databases", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"asnDatabase = /var/lib/GeoLite2-ASN.mmdb",
+				"countryDatabase = /var/lib/GeoLite2-Country.mmdb",
+				"reloadInterval = 1 minute",
+			))
+		})
+
+		It("should log disabled reloading", func() {
+			cfg.ReloadInterval = 0
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElement("reloadInterval = disabled"))
+		})
+	})
+})
@@ -0,0 +1,300 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeKey is the top level key of the files to include, it isn't part of `Config`
+const includeKey = "include"
+
+// HasIncludes returns if the config data includes other files
+func HasIncludes(data []byte) bool {
+	var doc struct {
+		Include interface{} `yaml:"include"`
+	}
+
+	// invalid data is reported when unmarshalling the config
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+
+	return doc.Include != nil
+}
+
+// mergeConfigFiles merges the files and the files they include and returns the result as YAML.
+//
+// Maps are merged, lists are appended and other values are replaced by the value of the later file.
+// Included files are merged before the file including them, so the file's own values take precedence.
+// The files are merged in the node tree, so the scalars keep their text and tags.
+func mergeConfigFiles(paths []string) ([]byte, error) {
+	m := configMerger{result: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}}
+
+	for _, path := range paths {
+		if err := m.mergeFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return yaml.Marshal(m.result)
+}
+
+type configMerger struct {
+	result *yaml.Node
+	// files currently being merged, to detect include cycles
+	stack []string
+}
+
+func (m *configMerger) mergeFile(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(m.stack, path) {
+		return fmt.Errorf("include cycle: %s", strings.Join(append(m.stack, path), " -> "))
+	}
+
+	m.stack = append(m.stack, path)
+	defer func() { m.stack = m.stack[:len(m.stack)-1] }()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	doc, err := parseConfigFile(data)
+	if err != nil {
+		return fmt.Errorf("wrong file structure in %s: %w", path, err)
+	}
+
+	includes, err := includePatterns(removeKey(doc, includeKey))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, pattern := range includes {
+		if err := m.mergeIncluded(filepath.Dir(path), pattern); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	mergeYAML(m.result, doc)
+
+	return nil
+}
+
+// parseConfigFile returns the top level mapping of the file, with the aliases replaced by the values they refer to,
+// so they stay valid when the values are merged into the other files
+func parseConfigFile(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		// empty file
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	root := resolveAliases(doc.Content[0])
+
+	if root.Kind == yaml.ScalarNode && root.ShortTag() == "!!null" {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: the config must be a map", root.Line)
+	}
+
+	return root, nil
+}
+
+// resolveAliases returns a copy of the node without anchors in which the aliases are replaced by copies of their values
+func resolveAliases(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return resolveAliases(node.Alias)
+	}
+
+	res := *node
+	res.Anchor = ""
+
+	if len(node.Content) > 0 {
+		res.Content = make([]*yaml.Node, len(node.Content))
+
+		for i, item := range node.Content {
+			res.Content[i] = resolveAliases(item)
+		}
+	}
+
+	return &res
+}
+
+// indexOfKey returns the index of the key in the content of a mapping or -1
+func indexOfKey(content []*yaml.Node, key string) int {
+	// keys and values alternate
+	for i := 0; i+1 < len(content); i += 2 {
+		if content[i].Kind == yaml.ScalarNode && content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// removeKey removes the key from the mapping and returns its value
+func removeKey(mapping *yaml.Node, key string) *yaml.Node {
+	i := indexOfKey(mapping.Content, key)
+	if i < 0 {
+		return nil
+	}
+
+	value := mapping.Content[i+1]
+	mapping.Content = slices.Delete(mapping.Content, i, i+2)
+
+	return value
+}
+
+// mergeIncluded merges the files matching the pattern, all YAML files of a matching directory are merged
+func (m *configMerger) mergeIncluded(dir, pattern string) error {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid include '%s': %w", pattern, err)
+	}
+
+	if len(matches) == 0 && !hasGlobMeta(pattern) {
+		return fmt.Errorf("can't include '%s': %w", pattern, os.ErrNotExist)
+	}
+
+	// Glob returns the matches in lexical order
+	for _, match := range matches {
+		stat, err := os.Stat(match)
+		if err != nil {
+			return err
+		}
+
+		files := []string{match}
+
+		if stat.IsDir() {
+			files, err = configFilesInDir(match)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, file := range files {
+			if err := m.mergeFile(file); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// includePatterns returns the value of the include key, a single pattern or a list of patterns
+func includePatterns(node *yaml.Node) ([]string, error) {
+	if node == nil || node.ShortTag() == "!!null" {
+		return nil, nil
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []string{node.Value}, nil
+
+	case yaml.SequenceNode:
+		result := make([]string, 0, len(node.Content))
+
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("invalid include in line %d, must be a path", item.Line)
+			}
+
+			result = append(result, item.Value)
+		}
+
+		return result, nil
+	}
+
+	return nil, errors.New("include must be a path or a list of paths")
+}
+
+// mergeYAML merges the mapping src into dst: maps are merged, lists are appended and other values are replaced.
+// Only keys of earlier files are merged, so duplicate keys of the same file are kept and reported as such.
+func mergeYAML(dst, src *yaml.Node) {
+	existing := len(dst.Content)
+
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		j := indexOfKey(dst.Content[:existing], key.Value)
+		if j < 0 {
+			dst.Content = append(dst.Content, key, value)
+
+			continue
+		}
+
+		current := dst.Content[j+1]
+
+		switch {
+		case value.Kind == yaml.MappingNode && current.Kind == yaml.MappingNode:
+			mergeYAML(current, value)
+
+		case value.Kind == yaml.SequenceNode && current.Kind == yaml.SequenceNode:
+			current.Content = append(current.Content, value.Content...)
+
+		default:
+			dst.Content[j+1] = value
+		}
+	}
+}
+
+// configFilesInDir returns the YAML files in the directory and its subdirectories in lexical order
+func configFilesInDir(path string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(path, func(filePath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == filePath {
+			return nil
+		}
+
+		// Ignore non YAML files
+		if !strings.HasSuffix(filePath, ".yml") && !strings.HasSuffix(filePath, ".yaml") {
+			return nil
+		}
+
+		isRegular, err := isRegularFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		// Ignore non regular files (directories, sockets, etc.)
+		if !isRegular {
+			return nil
+		}
+
+		files = append(files, filePath)
+
+		return nil
+	})
+
+	return files, err
+}
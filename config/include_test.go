@@ -0,0 +1,174 @@
+package config
+
+import (
+	"github.com/0xERR0R/blocky/helpertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config includes", func() {
+	var tmpDir *helpertest.TmpFolder
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		tmpDir = helpertest.NewTmpFolder("config")
+	})
+
+	writeMainConfig := func(lines ...string) *helpertest.TmpFile {
+		return tmpDir.CreateStringFile("config.yml", append([]string{
+			"upstreams:",
+			"  groups:",
+			"    default:",
+			"      - 1.1.1.1",
+		}, lines...)...)
+	}
+
+	When("the config includes files", func() {
+		BeforeEach(func() {
+			confD := tmpDir.CreateSubFolder("config.d")
+			confD.CreateStringFile("10-dns.yml",
+				"customDNS:",
+				"  mapping:",
+				"    printer.lan: 192.168.178.3",
+				"upstreams:",
+				"  groups:",
+				"    default:",
+				"      - 9.9.9.9",
+				"log:",
+				"  level: warn",
+			)
+			confD.CreateStringFile("20-dns.yaml",
+				"customDNS:",
+				"  mapping:",
+				"    nas.lan: 192.168.178.4",
+			)
+			confD.CreateStringFile("ignored.txt", "not: yaml")
+		})
+
+		It("should merge them", func() {
+			cfgFile := writeMainConfig(
+				"include:",
+				"  - config.d/*.yml",
+				"  - config.d/*.yaml",
+				"log:",
+				"  level: debug",
+			)
+
+			cfg, err := LoadConfig(cfgFile.Path, true)
+			Expect(err).Should(Succeed())
+
+			Expect(cfg.CustomDNS.Mapping).Should(HaveKey("printer.lan"))
+			Expect(cfg.CustomDNS.Mapping).Should(HaveKey("nas.lan"))
+			// lists are appended
+			Expect(cfg.Upstreams.Groups["default"]).Should(HaveLen(2))
+			// the own values take precedence over included ones
+			Expect(cfg.Log.Level.String()).Should(Equal("debug"))
+		})
+
+		It("should merge the YAML files of an included directory", func() {
+			cfgFile := writeMainConfig("include: config.d")
+
+			cfg, err := LoadConfig(cfgFile.Path, true)
+			Expect(err).Should(Succeed())
+
+			Expect(cfg.CustomDNS.Mapping).Should(HaveLen(2))
+			Expect(cfg.Log.Level.String()).Should(Equal("warning"))
+		})
+
+		It("should keep the text of the scalars of included files", func() {
+			tmpDir.CreateStringFile("config.d/30-clients.yml",
+				"clientLookup:",
+				"  clients:",
+				"    0123:",
+				"      - 192.168.178.10",
+				"customDNS:",
+				"  mapping:",
+				"    yes.lan: 192.168.178.5",
+				"redis:",
+				"  password: 0123",
+				"  username: yes",
+			)
+			cfgFile := writeMainConfig("include: config.d")
+
+			cfg, err := LoadConfig(cfgFile.Path, true)
+			Expect(err).Should(Succeed())
+
+			Expect(cfg.ClientLookup.ClientnameIPMapping).Should(HaveKey("0123"))
+			Expect(cfg.CustomDNS.Mapping).Should(HaveKey("yes.lan"))
+			Expect(cfg.Redis.Password).Should(Equal("0123"))
+			Expect(cfg.Redis.Username).Should(Equal("yes"))
+		})
+
+		It("should change the hash if an included file changes", func() {
+			cfgFile := writeMainConfig("include: config.d")
+
+			hash, err := HashConfig(cfgFile.Path)
+			Expect(err).Should(Succeed())
+
+			tmpDir.CreateStringFile("config.d/30-dns.yml",
+				"customDNS:",
+				"  mapping:",
+				"    tv.lan: 192.168.178.5",
+			)
+
+			Expect(HashConfig(cfgFile.Path)).ShouldNot(Equal(hash))
+		})
+	})
+
+	When("the config is a directory", func() {
+		It("should merge the fragments", func() {
+			writeMainConfig(
+				"customDNS:",
+				"  mapping:",
+				"    printer.lan: 192.168.178.3",
+			)
+			tmpDir.CreateStringFile("dns.yml",
+				"customDNS:",
+				"  mapping:",
+				"    nas.lan: 192.168.178.4",
+			)
+
+			cfg, err := LoadConfig(tmpDir.Path, true)
+			Expect(err).Should(Succeed())
+
+			Expect(cfg.CustomDNS.Mapping).Should(HaveLen(2))
+		})
+	})
+
+	When("an included file does not exist", func() {
+		It("should fail", func() {
+			cfgFile := writeMainConfig("include: missing.yml")
+
+			_, err := LoadConfig(cfgFile.Path, true)
+			Expect(err).Should(MatchError(ContainSubstring("can't include")))
+		})
+
+		It("should ignore patterns without match", func() {
+			cfgFile := writeMainConfig("include: config.d/*.yml")
+
+			_, err := LoadConfig(cfgFile.Path, true)
+			Expect(err).Should(Succeed())
+		})
+	})
+
+	When("files include each other", func() {
+		It("should fail", func() {
+			tmpDir.CreateStringFile("other.yml", "include: config.yml")
+			cfgFile := writeMainConfig("include: other.yml")
+
+			_, err := LoadConfig(cfgFile.Path, true)
+			Expect(err).Should(MatchError(ContainSubstring("include cycle")))
+		})
+	})
+
+	When("the include is invalid", func() {
+		It("should fail", func() {
+			cfgFile := writeMainConfig("include:", "  key: value")
+
+			_, err := LoadConfig(cfgFile.Path, true)
+			Expect(err).Should(MatchError(ContainSubstring("include must be a path or a list of paths")))
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// interpolationRegex matches `${NAME}`, `${NAME:-default}`, `${file:/path}` and the escaped form `$${...}`.
+// Other forms like the `${0,3,d}` modifiers of `$GENERATE` in zones are kept as they are.
+var interpolationRegex = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+const secretFilePrefix = "file:"
+
+// interpolate replaces environment variables and secret files in the string values of the config data.
+// The values are replaced in the node tree, so the order, duplicates and comments of the keys are kept.
+func interpolate(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte("${")) {
+		return data, nil
+	}
+
+	var doc yaml.Node
+
+	// invalid data is reported when unmarshalling the config
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return data, nil //nolint:nilerr
+	}
+
+	if err := interpolateNode(&doc); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(&doc)
+}
+
+func interpolateNode(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" {
+			return interpolateScalar(node)
+		}
+
+	case yaml.MappingNode:
+		// keys and values alternate
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolateNode(node.Content[i]); err != nil {
+				return fmt.Errorf("%s: %w", node.Content[i-1].Value, err)
+			}
+		}
+
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := interpolateNode(item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// interpolateScalar replaces the placeholders in the value of the node and sets the tag of the result
+func interpolateScalar(node *yaml.Node) error {
+	result, err := interpolateString(node.Value)
+	if err != nil {
+		return err
+	}
+
+	switch v := result.(type) {
+	case int:
+		node.Value, node.Tag, node.Style = strconv.Itoa(v), "!!int", 0
+
+	case bool:
+		node.Value, node.Tag, node.Style = strconv.FormatBool(v), "!!bool", 0
+
+	case string:
+		node.SetString(v)
+	}
+
+	return nil
+}
+
+// interpolateString replaces the placeholders in the string.
+// A value consisting of a single placeholder with a number or boolean is returned as such, like unquoted YAML.
+func interpolateString(value string) (interface{}, error) {
+	var errs []error
+
+	result := interpolationRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+
+		replacement, err := resolvePlaceholder(match[2 : len(match)-1])
+		if err != nil {
+			errs = append(errs, err)
+
+			return match
+		}
+
+		return replacement
+	})
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if interpolationRegex.FindString(value) != value || strings.HasPrefix(value, "$$") {
+		return result, nil
+	}
+
+	if i, err := strconv.Atoi(result); err == nil && strconv.Itoa(i) == result {
+		return i, nil
+	}
+
+	if b, err := strconv.ParseBool(result); err == nil && (result == "true" || result == "false") {
+		return b, nil
+	}
+
+	return result, nil
+}
+
+// resolvePlaceholder returns the value of an environment variable or the content of a secret file
+func resolvePlaceholder(expr string) (string, error) {
+	if path, ok := strings.CutPrefix(expr, secretFilePrefix); ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("can't read secret file: %w", err)
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	name, def, hasDefault := strings.Cut(expr, ":-")
+
+	if !envNameRegex.MatchString(name) {
+		// not a placeholder, keep the text
+		return "${" + expr + "}", nil
+	}
+
+	if value, ok := os.LookupEnv(name); ok {
+		return value, nil
+	}
+
+	if hasDefault {
+		return def, nil
+	}
+
+	return "", fmt.Errorf("environment variable %s is not set", name)
+}
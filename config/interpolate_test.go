@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+
+	"github.com/0xERR0R/blocky/helpertest"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config interpolation", func() {
+	suiteBeforeEach()
+
+	setEnv := func(name, value string) {
+		Expect(os.Setenv(name, value)).Should(Succeed())
+		DeferCleanup(os.Unsetenv, name)
+	}
+
+	Describe("interpolateString", func() {
+		It("should replace environment variables", func() {
+			setEnv("BLOCKY_TEST_HOST", "redis")
+
+			Expect(interpolateString("${BLOCKY_TEST_HOST}:6379")).Should(Equal("redis:6379"))
+		})
+
+		It("should use the default of unset variables", func() {
+			Expect(interpolateString("${BLOCKY_TEST_UNSET:-localhost}")).Should(Equal("localhost"))
+		})
+
+		It("should fail for unset variables without default", func() {
+			_, err := interpolateString("${BLOCKY_TEST_UNSET}")
+			Expect(err).Should(MatchError("environment variable BLOCKY_TEST_UNSET is not set"))
+		})
+
+		It("should read secret files", func() {
+			secret := helpertest.NewTmpFolder("secrets").CreateStringFile("redis", "s3cr3t: #1")
+
+			Expect(interpolateString("${file:" + secret.Path + "}")).Should(Equal("s3cr3t: #1"))
+		})
+
+		It("should fail if a secret file does not exist", func() {
+			_, err := interpolateString("${file:/does/not/exist}")
+			Expect(err).Should(MatchError(ContainSubstring("can't read secret file")))
+		})
+
+		It("should keep escaped placeholders and other expressions", func() {
+			Expect(interpolateString("$${BLOCKY_TEST_UNSET}")).Should(Equal("${BLOCKY_TEST_UNSET}"))
+			Expect(interpolateString("host-${0,3,d}")).Should(Equal("host-${0,3,d}"))
+		})
+
+		It("should return numbers and booleans of single placeholders", func() {
+			setEnv("BLOCKY_TEST_NUMBER", "53")
+			setEnv("BLOCKY_TEST_BOOL", "true")
+
+			Expect(interpolateString("${BLOCKY_TEST_NUMBER}")).Should(Equal(53))
+			Expect(interpolateString("${BLOCKY_TEST_BOOL}")).Should(Equal(true))
+			Expect(interpolateString("port ${BLOCKY_TEST_NUMBER}")).Should(Equal("port 53"))
+		})
+	})
+
+	Describe("interpolate", func() {
+		It("should keep the order of the keys and the duplicates", func() {
+			setEnv("BLOCKY_TEST_HOST", "redis")
+
+			result, err := interpolate([]byte("b: ${BLOCKY_TEST_HOST}\na: 1\nb: 2\n"))
+			Expect(err).Should(Succeed())
+			Expect(string(result)).Should(Equal("b: redis\na: 1\nb: 2\n"))
+		})
+
+		It("should quote values which aren't plain YAML strings", func() {
+			for _, value := range []string{"s3cr3t: #1", "yes", "1.5", "- item", "line1\nline2", "null"} {
+				setEnv("BLOCKY_TEST_VALUE", value)
+
+				result, err := interpolate([]byte("value: ${BLOCKY_TEST_VALUE}\n"))
+				Expect(err).Should(Succeed())
+
+				var parsed map[string]string
+
+				Expect(yaml.UnmarshalStrict(result, &parsed)).Should(Succeed())
+				Expect(parsed).Should(HaveKeyWithValue("value", value))
+			}
+		})
+	})
+
+	Describe("unmarshalConfig", func() {
+		It("should use the interpolated values but keep the data", func() {
+			setEnv("BLOCKY_TEST_PASSWORD", "s3cr3t: #1")
+			setEnv("BLOCKY_TEST_DB", "2")
+
+			data := []byte(`
+redis:
+  address: ${BLOCKY_TEST_REDIS:-redis:6379}
+  password: ${BLOCKY_TEST_PASSWORD}
+  database: ${BLOCKY_TEST_DB}
+`)
+
+			var cfg Config
+			Expect(unmarshalConfig(logger, data, &cfg)).Should(Succeed())
+
+			Expect(cfg.Redis.Address).Should(Equal("redis:6379"))
+			Expect(cfg.Redis.Password).Should(Equal("s3cr3t: #1"))
+			Expect(cfg.Redis.Database).Should(Equal(2))
+			Expect(cfg.Data).Should(Equal(data))
+		})
+
+		It("should fail on duplicate keys", func() {
+			setEnv("BLOCKY_TEST_HOST", "redis")
+
+			var cfg Config
+
+			err := unmarshalConfig(logger, []byte("redis:\n  address: ${BLOCKY_TEST_HOST}\n  address: other\n"), &cfg)
+			Expect(err).Should(MatchError(ContainSubstring("already set")))
+		})
+
+		It("should fail if a variable is not set", func() {
+			var cfg Config
+
+			err := unmarshalConfig(logger, []byte("redis:\n  password: ${BLOCKY_TEST_UNSET}"), &cfg)
+			Expect(err).Should(MatchError(ContainSubstring("redis: password: environment variable BLOCKY_TEST_UNSET")))
+		})
+	})
+})
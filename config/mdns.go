@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MDNS configures the resolution of link-local names with multicast DNS (RFC 6762) and LLMNR (RFC 4795)
+type MDNS struct {
+	Enable bool `yaml:"enable" default:"false"`
+	// LLMNR also resolves single-label names with LLMNR
+	LLMNR bool `yaml:"llmnr" default:"false"`
+	// Interfaces to send the queries on, all multicast capable ones if empty
+	Interfaces []string `yaml:"interfaces"`
+	// Timeout to wait for a response
+	Timeout Duration `yaml:"timeout" default:"1s"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *MDNS) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *MDNS) LogConfig(logger *logrus.Entry) {
+	logger.Infof("llmnr      = %t", c.LLMNR)
+
+	if len(c.Interfaces) == 0 {
+		logger.Info("interfaces = all")
+	} else {
+		logger.Infof("interfaces = %s", strings.Join(c.Interfaces, ", "))
+	}
+
+	logger.Infof("timeout    = %s", c.Timeout)
+}
+
+func (c *MDNS) validate(logger *logrus.Entry) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	if c.Timeout <= 0 {
+		def := mustDefault[MDNS]()
+
+		logger.Warnf("mdns.timeout must be positive, setting to %s", def.Timeout)
+
+		c.Timeout = def.Timeout
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MDNSConfig", func() {
+	var cfg MDNS
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[MDNS]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("enabled", func() {
+			It("should be true", func() {
+				cfg.Enable = true
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log all interfaces by default", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"llmnr      = false",
+				"interfaces = all",
+				"timeout    = 1 second",
+			}))
+		})
+
+		It("should log the interfaces", func() {
+			cfg.Interfaces = []string{"eth0", "wlan0"}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElement("interfaces = eth0, wlan0"))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should reset an invalid timeout", func() {
+			cfg.Enable = true
+			cfg.Timeout = 0
+
+			cfg.validate(logger)
+
+			Expect(cfg.Timeout).Should(Equal(Duration(time.Second)))
+			Expect(hook.Messages).Should(ConsistOf(ContainSubstring("mdns.timeout must be positive")))
+		})
+	})
+})
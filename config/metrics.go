@@ -6,6 +6,14 @@ import "github.com/sirupsen/logrus"
 type Metrics struct {
 	Enable bool   `yaml:"enable" default:"false"`
 	Path   string `yaml:"path" default:"/metrics"`
+
+	// Detailed metrics, disabled by default since they create one time series per label value
+	PerClient   bool `yaml:"perClient" default:"false"`
+	PerUpstream bool `yaml:"perUpstream" default:"false"`
+	PerGroup    bool `yaml:"perGroup" default:"false"`
+
+	// Cardinality guard: maximum number of distinct label values per detailed metric, 0 is unlimited
+	MaxLabelValues uint `yaml:"maxLabelValues" default:"100"`
 }
 
 // IsEnabled implements `config.Configurable`.
@@ -16,4 +24,11 @@ func (c *Metrics) IsEnabled() bool {
 // LogConfig implements `config.Configurable`.
 func (c *Metrics) LogConfig(logger *logrus.Entry) {
 	logger.Infof("url path: %s", c.Path)
+
+	if c.PerClient || c.PerUpstream || c.PerGroup {
+		logger.Infof("perClient = %t", c.PerClient)
+		logger.Infof("perUpstream = %t", c.PerUpstream)
+		logger.Infof("perGroup = %t", c.PerGroup)
+		logger.Infof("maxLabelValues = %d", c.MaxLabelValues)
+	}
 }
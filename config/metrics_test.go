@@ -48,5 +48,18 @@ var _ = Describe("MetricsConfig", func() {
 			Expect(hook.Calls).Should(HaveLen(1))
 			Expect(hook.Messages).Should(ContainElement(ContainSubstring("url path: /custom/path")))
 		})
+
+		It("should log detailed metrics settings", func() {
+			cfg.PerClient = true
+			cfg.MaxLabelValues = 50
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"perClient = true",
+				"perUpstream = false",
+				"maxLabelValues = 50",
+			))
+		})
 	})
 })
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Mirror configures the mirroring of queries to a shadow upstream, to compare its responses with the ones of blocky
+type Mirror struct {
+	// Upstream receiving the mirrored queries, for example another DNS provider or a second blocky instance
+	Upstream Upstream `yaml:"upstream"`
+	// Percentage of the queries to mirror
+	Percentage uint `yaml:"percentage" default:"10"`
+	// MaxInFlight limits the mirrored queries waiting for a response, further queries are not mirrored
+	MaxInFlight uint `yaml:"maxInFlight" default:"100"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Mirror) IsEnabled() bool {
+	return !c.Upstream.IsDefault() && c.Percentage > 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Mirror) LogConfig(logger *logrus.Entry) {
+	logger.Infof("upstream    = %s", c.Upstream)
+	logger.Infof("percentage  = %d%%", c.Percentage)
+	logger.Infof("maxInFlight = %d", c.MaxInFlight)
+}
+
+func (c *Mirror) validate(logger *logrus.Entry) {
+	const maxPercentage = 100
+
+	if !c.IsEnabled() {
+		return
+	}
+
+	if c.Percentage > maxPercentage {
+		logger.Warnf("mirror.percentage %d is greater than 100, setting to 100", c.Percentage)
+
+		c.Percentage = maxPercentage
+	}
+
+	if c.MaxInFlight == 0 {
+		def := mustDefault[Mirror]()
+
+		logger.Warnf("mirror.maxInFlight is 0, setting to %d", def.MaxInFlight)
+
+		c.MaxInFlight = def.MaxInFlight
+	}
+}
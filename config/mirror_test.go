@@ -0,0 +1,76 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MirrorConfig", func() {
+	var cfg Mirror
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[Mirror]()
+		Expect(err).Should(Succeed())
+
+		cfg.Upstream = Upstream{Net: NetProtocolTcpUdp, Host: "1.1.1.1", Port: 53}
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be true with an upstream", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+
+		When("no upstream is configured", func() {
+			It("should be false", func() {
+				cfg.Upstream = Upstream{}
+
+				Expect(cfg.IsEnabled()).Should(BeFalse())
+			})
+		})
+
+		When("the percentage is 0", func() {
+			It("should be false", func() {
+				cfg.Percentage = 0
+
+				Expect(cfg.IsEnabled()).Should(BeFalse())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("upstream"),
+				"percentage  = 10%",
+				"maxInFlight = 100",
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should limit the percentage to 100", func() {
+			cfg.Percentage = 150
+
+			cfg.validate(logger)
+
+			Expect(cfg.Percentage).Should(BeNumerically("==", 100))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("mirror.percentage 150")))
+		})
+
+		It("should use the default when maxInFlight is 0", func() {
+			cfg.MaxInFlight = 0
+
+			cfg.validate(logger)
+
+			Expect(cfg.MaxInFlight).Should(BeNumerically("==", 100))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("mirror.maxInFlight is 0")))
+		})
+	})
+})
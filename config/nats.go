@@ -0,0 +1,70 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// NATS configuration for the NATS connection, an alternative to redis for multiple instances
+type NATS struct {
+	URL                string   `yaml:"url"`
+	Username           string   `yaml:"username" default:""`
+	Password           string   `yaml:"password" default:""`
+	Token              string   `yaml:"token" default:""`
+	Subject            string   `yaml:"subject" default:"blocky.sync"`
+	Bucket             string   `yaml:"bucket" default:""`
+	BucketMaxAge       Duration `yaml:"bucketMaxAge" default:"1h"`
+	Required           bool     `yaml:"required" default:"false"`
+	ConnectionAttempts int      `yaml:"connectionAttempts" default:"3"`
+	ConnectionCooldown Duration `yaml:"connectionCooldown" default:"1s"`
+
+	// TLS is set from the global `tls` config if a `tls.nats` section exists, nil means no TLS
+	TLS *TLSPolicy `yaml:"-"`
+}
+
+// IsEnabled implements `config.Configurable`
+func (c *NATS) IsEnabled() bool {
+	return c.URL != ""
+}
+
+// LogConfig implements `config.Configurable`
+func (c *NATS) LogConfig(logger *logrus.Entry) {
+	logger.Info("url: ", c.URL)
+	logger.Info("username: ", c.Username)
+	logger.Info("password: ", secretObfuscator)
+	logger.Info("token: ", secretObfuscator)
+	logger.Info("subject: ", c.Subject)
+
+	if c.Bucket != "" {
+		logger.Info("bucket: ", c.Bucket)
+		logger.Info("bucketMaxAge: ", c.BucketMaxAge)
+	} else {
+		logger.Info("bucket: disabled")
+	}
+
+	logger.Info("required: ", c.Required)
+	logger.Info("connectionAttempts: ", c.ConnectionAttempts)
+	logger.Info("connectionCooldown: ", c.ConnectionCooldown)
+	logger.Info("tls: ", c.TLS != nil)
+}
+
+func (c *NATS) validate(logger *logrus.Entry, redis *Redis) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	if redis.IsEnabled() {
+		logger.Warn("nats and redis are both configured, only redis is used")
+
+		c.URL = ""
+
+		return
+	}
+
+	if c.Subject == "" {
+		def := mustDefault[NATS]()
+
+		logger.Warnf("nats.subject is empty, setting to %s", def.Subject)
+
+		c.Subject = def.Subject
+	}
+}
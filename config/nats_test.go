@@ -0,0 +1,106 @@
+package config
+
+import (
+	"github.com/0xERR0R/blocky/log"
+	"github.com/creasty/defaults"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NATS", func() {
+	var (
+		c   NATS
+		err error
+	)
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		err = defaults.Set(&c)
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		When("all fields are default", func() {
+			It("should be disabled", func() {
+				Expect(c.IsEnabled()).Should(BeFalse())
+			})
+		})
+
+		When("URL is set", func() {
+			BeforeEach(func() {
+				c.URL = "nats://localhost:4222"
+			})
+
+			It("should be enabled", func() {
+				Expect(c.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		BeforeEach(func() {
+			logger, hook = log.NewMockEntry()
+			c.URL = "nats://localhost:4222"
+		})
+
+		It("should log the values", func() {
+			c.LogConfig(logger)
+
+			Expect(hook.Messages).Should(
+				SatisfyAll(
+					ContainElement(ContainSubstring("url: nats://localhost:4222")),
+					ContainElement(ContainSubstring("subject: blocky.sync")),
+					ContainElement(ContainSubstring("bucket: disabled"))))
+		})
+
+		When("a bucket is set", func() {
+			It("should log the bucket", func() {
+				c.Bucket = "blocky"
+				c.LogConfig(logger)
+
+				Expect(hook.Messages).Should(
+					SatisfyAll(
+						ContainElement(ContainSubstring("bucket: blocky")),
+						ContainElement(ContainSubstring("bucketMaxAge: 1 hour"))))
+			})
+		})
+
+		const secretValue = "secret-value"
+
+		It("should not log the password and token", func() {
+			c.Password = secretValue
+			c.Token = secretValue
+			c.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+			Expect(hook.Messages).ShouldNot(ContainElement(ContainSubstring(secretValue)))
+		})
+	})
+
+	Describe("validate", func() {
+		BeforeEach(func() {
+			logger, hook = log.NewMockEntry()
+			c.URL = "nats://localhost:4222"
+		})
+
+		When("redis is configured too", func() {
+			It("should disable NATS", func() {
+				c.validate(logger, &Redis{Address: "localhost:6379"})
+
+				Expect(c.IsEnabled()).Should(BeFalse())
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("only redis is used")))
+			})
+		})
+
+		When("the subject is empty", func() {
+			It("should use the default", func() {
+				c.Subject = ""
+				c.validate(logger, &Redis{})
+
+				Expect(c.IsEnabled()).Should(BeTrue())
+				Expect(c.Subject).Should(Equal("blocky.sync"))
+			})
+		})
+	})
+})
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/sirupsen/logrus"
+)
+
+// NewDomains configures the detection of newly observed and newly registered domains, a common control against
+// phishing. Domains are compared by their registrable part, e.g. `example.co.uk` for `www.example.co.uk`.
+type NewDomains struct {
+	Action NewDomainAction `yaml:"action" default:"log"`
+	// FirstSeen flags domains which were never queried before on this instance
+	FirstSeen FirstSeenDomains `yaml:"firstSeen"`
+	// Registered flags domains registered recently, looked up with RDAP
+	Registered RegisteredDomains `yaml:"registered"`
+	// Allowlist contains domains (and their subdomains) which are never flagged
+	Allowlist []string `yaml:"allowlist"`
+}
+
+// FirstSeenDomains configures the detection of domains never queried before
+type FirstSeenDomains struct {
+	Enable bool `yaml:"enable" default:"false"`
+	// LearningPeriod after the first start in which domains are only recorded, to not flag all domains in use
+	LearningPeriod Duration `yaml:"learningPeriod" default:"24h"`
+	// FlagPeriod after the first query of a domain, during which it is flagged
+	FlagPeriod Duration `yaml:"flagPeriod" default:"1h"`
+	// Path of the file recording the seen domains across restarts, optional
+	Path string `yaml:"path"`
+}
+
+// RegisteredDomains configures the detection of domains registered within MaxAge
+type RegisteredDomains struct {
+	// MaxAge of the registration of flagged domains, 0 disables the lookups
+	MaxAge Duration `yaml:"maxAge" default:"0"`
+	// RDAPURL is the RDAP endpoint the domain is appended to
+	RDAPURL string `yaml:"rdapURL" default:"https://rdap.org/domain/"`
+	// Timeout of one RDAP lookup
+	Timeout Duration `yaml:"timeout" default:"5s"`
+	// MaxWait for the lookup before the query is resolved, the lookup continues in the background
+	MaxWait Duration `yaml:"maxWait" default:"500ms"`
+	// CacheTime of the looked up registration dates
+	CacheTime Duration `yaml:"cacheTime" default:"24h"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *NewDomains) IsEnabled() bool {
+	return c.FirstSeen.Enable || c.Registered.IsEnabled()
+}
+
+// IsEnabled returns true if the registration dates are looked up
+func (c *RegisteredDomains) IsEnabled() bool {
+	return c.MaxAge.IsAboveZero()
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *NewDomains) LogConfig(logger *logrus.Entry) {
+	logger.Infof("action = %s", c.Action)
+
+	if c.FirstSeen.Enable {
+		logger.Info("firstSeen:")
+		log.WithIndent(logger, "  ", c.FirstSeen.logConfig)
+	}
+
+	if c.Registered.IsEnabled() {
+		logger.Info("registered:")
+		log.WithIndent(logger, "  ", c.Registered.logConfig)
+	}
+
+	if len(c.Allowlist) != 0 {
+		logger.Infof("allowlist = %s", strings.Join(c.Allowlist, ", "))
+	}
+}
+
+func (c *FirstSeenDomains) logConfig(logger *logrus.Entry) {
+	logger.Infof("learningPeriod = %s", c.LearningPeriod)
+	logger.Infof("flagPeriod = %s", c.FlagPeriod)
+
+	if c.Path != "" {
+		logger.Infof("path = %s", c.Path)
+	}
+}
+
+func (c *RegisteredDomains) logConfig(logger *logrus.Entry) {
+	logger.Infof("maxAge = %s", c.MaxAge)
+	logger.Infof("rdapURL = %s", c.RDAPURL)
+	logger.Infof("timeout = %s", c.Timeout)
+	logger.Infof("maxWait = %s", c.MaxWait)
+	logger.Infof("cacheTime = %s", c.CacheTime)
+}
+
+func (c *NewDomains) validate(logger *logrus.Entry) {
+	c.Allowlist = normalizeDomains(c.Allowlist)
+
+	if !c.Registered.IsEnabled() {
+		return
+	}
+
+	if u, err := url.Parse(c.Registered.RDAPURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		def := mustDefault[RegisteredDomains]()
+
+		logger.Warnf("newDomains.registered.rdapURL '%s' is not a HTTP(S) URL, setting to %s",
+			c.Registered.RDAPURL, def.RDAPURL)
+
+		c.Registered.RDAPURL = def.RDAPURL
+	}
+
+	if !strings.HasSuffix(c.Registered.RDAPURL, "/") {
+		c.Registered.RDAPURL += "/"
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewDomainsConfig", func() {
+	var cfg NewDomains
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[NewDomains]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		It("should be true with first seen detection", func() {
+			cfg.FirstSeen.Enable = true
+
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+
+		It("should be true with a max registration age", func() {
+			cfg.Registered.MaxAge = Duration(30 * 24 * time.Hour)
+
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.FirstSeen.Enable = true
+			cfg.FirstSeen.Path = "/var/lib/blocky/seen.txt"
+			cfg.Registered.MaxAge = Duration(24 * time.Hour)
+			cfg.Allowlist = []string{"example.com"}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"action = log",
+				"firstSeen:",
+				"learningPeriod = 1 day",
+				"path = /var/lib/blocky/seen.txt",
+				"registered:",
+				"maxAge = 1 day",
+				"rdapURL = https://rdap.org/domain/",
+				"allowlist = example.com",
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should normalize the allowlist", func() {
+			cfg.Allowlist = []string{"Example.COM."}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Allowlist).Should(Equal([]string{"example.com"}))
+		})
+
+		It("should fix the RDAP URL", func() {
+			cfg.Registered.MaxAge = Duration(24 * time.Hour)
+			cfg.Registered.RDAPURL = "https://rdap.example.com/domain"
+
+			cfg.validate(logger)
+
+			Expect(cfg.Registered.RDAPURL).Should(Equal("https://rdap.example.com/domain/"))
+			Expect(hook.Calls).Should(BeEmpty())
+
+			cfg.Registered.RDAPURL = "rdap.example.com"
+
+			cfg.validate(logger)
+
+			Expect(cfg.Registered.RDAPURL).Should(Equal("https://rdap.org/domain/"))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("is not a HTTP(S) URL")))
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProxyProtocol configures the HAProxy PROXY protocol, so blocky behind a TCP load balancer sees the client IPs
+type ProxyProtocol struct {
+	// Listeners accepting a PROXY protocol header from the trusted proxies
+	Listeners []ProxyProtocolListener `yaml:"listeners"`
+	// IPs or CIDRs of the proxies, connections of other sources are used without header
+	TrustedProxies []string `yaml:"trustedProxies"`
+	// Time a trusted proxy has to send the header after connecting
+	HeaderTimeout Duration `yaml:"headerTimeout" default:"5s"`
+	// TCP and DoT upstreams receiving a PROXY protocol header with the client IP
+	Upstreams []Upstream `yaml:"upstreams"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *ProxyProtocol) IsEnabled() bool {
+	return len(c.Listeners) > 0 || len(c.Upstreams) > 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ProxyProtocol) LogConfig(logger *logrus.Entry) {
+	if len(c.Listeners) > 0 {
+		listeners := make([]string, 0, len(c.Listeners))
+		for _, listener := range c.Listeners {
+			listeners = append(listeners, listener.String())
+		}
+
+		logger.Infof("listeners      = %s", strings.Join(listeners, ", "))
+		logger.Infof("trustedProxies = %s", strings.Join(c.TrustedProxies, ", "))
+		logger.Infof("headerTimeout  = %s", c.HeaderTimeout)
+	}
+
+	if len(c.Upstreams) > 0 {
+		logger.Info("upstreams:")
+
+		for _, upstream := range c.Upstreams {
+			logger.Infof("  - %s", upstream)
+		}
+	}
+}
+
+// Accepts returns true if the listener accepts PROXY protocol headers
+func (c *ProxyProtocol) Accepts(listener ProxyProtocolListener) bool {
+	return slices.Contains(c.Listeners, listener)
+}
+
+func (c *ProxyProtocol) validate(logger *logrus.Entry) {
+	if !c.HeaderTimeout.IsAboveZero() {
+		defaults := mustDefault[ProxyProtocol]()
+
+		logger.Warnf("proxyProtocol.headerTimeout <= 0, setting to %s", defaults.HeaderTimeout)
+		c.HeaderTimeout = defaults.HeaderTimeout
+	}
+
+	trusted := c.TrustedProxies[:0]
+
+	for _, value := range c.TrustedProxies {
+		if _, err := ParseClientPrefix(value); err != nil {
+			logger.Warnf("proxyProtocol.trustedProxies: ignoring %s", err)
+
+			continue
+		}
+
+		trusted = append(trusted, value)
+	}
+
+	c.TrustedProxies = trusted
+
+	if len(c.Listeners) > 0 && len(c.TrustedProxies) == 0 {
+		logger.Warn("proxyProtocol.listeners have no effect without proxyProtocol.trustedProxies")
+	}
+
+	upstreams := c.Upstreams[:0]
+
+	for _, upstream := range c.Upstreams {
+		if upstream.Net != NetProtocolTcpTls && upstream.Net != NetProtocolTcpUdp {
+			logger.Warnf("proxyProtocol.upstreams: ignoring %s, only TCP and DoT upstreams are supported", upstream)
+
+			continue
+		}
+
+		upstreams = append(upstreams, upstream)
+	}
+
+	c.Upstreams = upstreams
+}
+
+// TrustedPrefixes returns the parsed trusted proxies
+func (c *ProxyProtocol) TrustedPrefixes() ([]netip.Prefix, error) {
+	res := make([]netip.Prefix, 0, len(c.TrustedProxies))
+
+	for _, value := range c.TrustedProxies {
+		prefix, err := ParseClientPrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("proxyProtocol.trustedProxies: %w", err)
+		}
+
+		res = append(res, prefix)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"net/netip"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProxyProtocolConfig", func() {
+	var cfg ProxyProtocol
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[ProxyProtocol]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("upstreams are set", func() {
+			It("should be true", func() {
+				cfg.Upstreams = []Upstream{{Net: NetProtocolTcpTls, Host: "dns.example.com", Port: 853}}
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.Listeners = []ProxyProtocolListener{ProxyProtocolListenerDns, ProxyProtocolListenerHttps}
+			cfg.TrustedProxies = []string{"10.0.0.1", "10.0.1.0/24"}
+			cfg.Upstreams = []Upstream{{Net: NetProtocolTcpTls, Host: "dns.example.com", Port: 853}}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"listeners      = dns, https",
+				"trustedProxies = 10.0.0.1, 10.0.1.0/24",
+				"headerTimeout  = 5 seconds",
+				"upstreams:",
+				"  - tcp-tls:dns.example.com",
+			))
+		})
+	})
+
+	Describe("Accepts", func() {
+		It("should be true for the configured listeners", func() {
+			cfg.Listeners = []ProxyProtocolListener{ProxyProtocolListenerTls}
+
+			Expect(cfg.Accepts(ProxyProtocolListenerTls)).Should(BeTrue())
+			Expect(cfg.Accepts(ProxyProtocolListenerDns)).Should(BeFalse())
+		})
+	})
+
+	Describe("validate", func() {
+		It("should fix invalid values", func() {
+			cfg.Listeners = []ProxyProtocolListener{ProxyProtocolListenerDns}
+			cfg.HeaderTimeout = Duration(-time.Second)
+			cfg.TrustedProxies = []string{"10.0.0.1", "haproxy"}
+			cfg.Upstreams = []Upstream{
+				{Net: NetProtocolTcpTls, Host: "dns.example.com", Port: 853},
+				{Net: NetProtocolHttps, Host: "dns.example.com", Port: 443},
+			}
+
+			cfg.validate(logger)
+
+			Expect(cfg.HeaderTimeout).Should(Equal(Duration(5 * time.Second)))
+			Expect(cfg.TrustedProxies).Should(Equal([]string{"10.0.0.1"}))
+			Expect(cfg.Upstreams).Should(HaveLen(1))
+			Expect(hook.Calls).Should(HaveLen(3))
+		})
+
+		It("should warn about listeners without trusted proxies", func() {
+			cfg.Listeners = []ProxyProtocolListener{ProxyProtocolListenerDns}
+
+			cfg.validate(logger)
+
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("no effect")))
+		})
+	})
+
+	Describe("TrustedPrefixes", func() {
+		It("should parse the proxies", func() {
+			cfg.TrustedProxies = []string{"10.0.0.0/8", "192.168.178.1"}
+
+			Expect(cfg.TrustedPrefixes()).Should(Equal([]netip.Prefix{
+				netip.MustParsePrefix("10.0.0.0/8"),
+				netip.MustParsePrefix("192.168.178.1/32"),
+			}))
+		})
+
+		It("should fail on invalid proxies", func() {
+			cfg.TrustedProxies = []string{"haproxy"}
+
+			_, err := cfg.TrustedPrefixes()
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
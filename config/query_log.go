@@ -10,20 +10,93 @@ import (
 
 // QueryLog configuration for the query logging
 type QueryLog struct {
-	Target           string          `yaml:"target"`
-	Type             QueryLogType    `yaml:"type"`
-	LogRetentionDays uint64          `yaml:"logRetentionDays"`
-	CreationAttempts int             `yaml:"creationAttempts" default:"3"`
-	CreationCooldown Duration        `yaml:"creationCooldown" default:"2s"`
-	Fields           []QueryLogField `yaml:"fields"`
-	FlushInterval    Duration        `yaml:"flushInterval" default:"30s"`
-	Ignore           QueryLogIgnore  `yaml:"ignore"`
+	Target           string            `yaml:"target"`
+	Type             QueryLogType      `yaml:"type"`
+	LogRetentionDays uint64            `yaml:"logRetentionDays"`
+	CreationAttempts int               `yaml:"creationAttempts" default:"3"`
+	CreationCooldown Duration          `yaml:"creationCooldown" default:"2s"`
+	Fields           []QueryLogField   `yaml:"fields"`
+	FlushInterval    Duration          `yaml:"flushInterval" default:"30s"`
+	Ignore           QueryLogIgnore    `yaml:"ignore"`
+	Anonymize        QueryLogAnonymize `yaml:"anonymize"`
+	Archive          QueryLogArchive   `yaml:"archive"`
+
+	// TLS is set from the global `tls` config if a `tls.database` section exists
+	TLS *TLSPolicy `yaml:"-"`
 }
 
 type QueryLogIgnore struct {
 	SUDN bool `yaml:"sudn" default:"false"`
 }
 
+// QueryLogArchive configures the files of the jsonl query log
+type QueryLogArchive struct {
+	Rotation QueryLogRotation `yaml:"rotation" default:"daily"`
+	// Size in MiB of the compressed data after which a new file is started, 0 is unlimited
+	MaxFileSize uint `yaml:"maxFileSize" default:"100"`
+}
+
+// QueryLogAnonymize configures the anonymization of the clients in the query log
+type QueryLogAnonymize struct {
+	ClientIP QueryLogIPAnonymization `yaml:"clientIP" default:"none"`
+	// Prefix lengths of the networks kept by the truncation
+	IPv4Prefix uint8 `yaml:"ipv4Prefix" default:"24"`
+	IPv6Prefix uint8 `yaml:"ipv6Prefix" default:"48"`
+	// Interval after which the salt of the hashes changes, 0 keeps it until blocky restarts
+	SaltRotation Duration `yaml:"saltRotation" default:"24h"`
+	// Log a salted hash instead of the client names
+	HashClientNames bool `yaml:"hashClientNames" default:"false"`
+	// Log neither client IP nor client names
+	DropClient bool `yaml:"dropClient" default:"false"`
+	// Clients (name with wildcards, IP, CIDR or MAC address) logged without anonymization
+	Exempt []string `yaml:"exempt"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *QueryLogAnonymize) IsEnabled() bool {
+	return c.ClientIP != QueryLogIPAnonymizationNone || c.HashClientNames || c.DropClient
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *QueryLogAnonymize) LogConfig(logger *logrus.Entry) {
+	if c.DropClient {
+		logger.Info("dropClient = true")
+	} else {
+		logger.Infof("clientIP        = %s", c.ClientIP)
+
+		if c.ClientIP == QueryLogIPAnonymizationTruncate {
+			logger.Infof("prefixes        = /%d, /%d", c.IPv4Prefix, c.IPv6Prefix)
+		}
+
+		logger.Infof("hashClientNames = %t", c.HashClientNames)
+
+		if c.ClientIP == QueryLogIPAnonymizationHash || c.HashClientNames {
+			logger.Infof("saltRotation    = %s", c.SaltRotation)
+		}
+	}
+
+	if len(c.Exempt) != 0 {
+		logger.Infof("exempt          = %s", strings.Join(c.Exempt, ", "))
+	}
+}
+
+func (c *QueryLogAnonymize) validate(logger *logrus.Entry) {
+	const (
+		maxIPv4Prefix = 32
+		maxIPv6Prefix = 128
+	)
+
+	if c.IPv4Prefix > maxIPv4Prefix {
+		logger.Warnf("queryLog.anonymize.ipv4Prefix > %d, setting to %d", maxIPv4Prefix, maxIPv4Prefix)
+		c.IPv4Prefix = maxIPv4Prefix
+	}
+
+	if c.IPv6Prefix > maxIPv6Prefix {
+		logger.Warnf("queryLog.anonymize.ipv6Prefix > %d, setting to %d", maxIPv6Prefix, maxIPv6Prefix)
+		c.IPv6Prefix = maxIPv6Prefix
+	}
+}
+
 // SetDefaults implements `defaults.Setter`.
 func (c *QueryLog) SetDefaults() {
 	// Since the default depends on the enum values, set it dynamically
@@ -54,6 +127,19 @@ func (c *QueryLog) LogConfig(logger *logrus.Entry) {
 	log.WithIndent(logger, "  ", func(e *logrus.Entry) {
 		logger.Infof("sudn: %t", c.Ignore.SUDN)
 	})
+
+	if c.Type == QueryLogTypeJsonl {
+		logger.Info("archive:")
+		log.WithIndent(logger, "  ", func(e *logrus.Entry) {
+			e.Infof("rotation: %s", c.Archive.Rotation)
+			e.Infof("maxFileSize: %d MiB", c.Archive.MaxFileSize)
+		})
+	}
+
+	if c.Anonymize.IsEnabled() {
+		logger.Info("anonymize:")
+		log.WithIndent(logger, "  ", c.Anonymize.LogConfig)
+	}
 }
 
 func (c *QueryLog) censoredTarget() string {
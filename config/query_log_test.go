@@ -48,6 +48,71 @@ var _ = Describe("QueryLogConfig", func() {
 		})
 	})
 
+	Describe("Anonymize", func() {
+		It("should be disabled by default", func() {
+			cfg, err := WithDefaults[QueryLog]()
+			Expect(err).Should(Succeed())
+
+			Expect(cfg.Anonymize.IsEnabled()).Should(BeFalse())
+		})
+
+		It("should be logged if enabled", func() {
+			cfg.Anonymize = QueryLogAnonymize{
+				ClientIP:     QueryLogIPAnonymizationHash,
+				SaltRotation: Duration(time.Hour),
+				Exempt:       []string{"admin"},
+			}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("anonymize:"),
+				ContainSubstring("clientIP        = hash"),
+				ContainSubstring("saltRotation    = 1 hour"),
+				ContainSubstring("exempt          = admin"),
+			))
+		})
+
+		It("should fix invalid prefix lengths", func() {
+			cfg.Anonymize = QueryLogAnonymize{IPv4Prefix: 33, IPv6Prefix: 129}
+
+			cfg.Anonymize.validate(logger)
+
+			Expect(cfg.Anonymize.IPv4Prefix).Should(BeNumerically("==", 32))
+			Expect(cfg.Anonymize.IPv6Prefix).Should(BeNumerically("==", 128))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("ipv4Prefix > 32")))
+		})
+	})
+
+	Describe("Archive", func() {
+		It("should rotate daily by default", func() {
+			cfg, err := WithDefaults[QueryLog]()
+			Expect(err).Should(Succeed())
+
+			Expect(cfg.Archive.Rotation).Should(Equal(QueryLogRotationDaily))
+			Expect(cfg.Archive.MaxFileSize).Should(BeNumerically("==", 100))
+		})
+
+		It("should be logged for the jsonl type", func() {
+			cfg.Type = QueryLogTypeJsonl
+			cfg.Archive = QueryLogArchive{Rotation: QueryLogRotationHourly, MaxFileSize: 10}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("archive:"),
+				ContainSubstring("rotation: hourly"),
+				ContainSubstring("maxFileSize: 10 MiB"),
+			))
+		})
+
+		It("should not be logged for other types", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).ShouldNot(ContainElement(ContainSubstring("archive:")))
+		})
+	})
+
 	Describe("LogConfig", func() {
 		It("should log configuration", func() {
 			cfg.LogConfig(logger)
@@ -16,6 +16,9 @@ type Redis struct {
 	SentinelUsername   string   `yaml:"sentinelUsername" default:""`
 	SentinelPassword   string   `yaml:"sentinelPassword" default:""`
 	SentinelAddresses  []string `yaml:"sentinelAddresses"`
+
+	// TLS is set from the global `tls` config if a `tls.redis` section exists, nil means no TLS
+	TLS *TLSPolicy `yaml:"-"`
 }
 
 // IsEnabled implements `config.Configurable`
@@ -35,6 +38,7 @@ func (c *Redis) LogConfig(logger *logrus.Entry) {
 	logger.Info("required: ", c.Required)
 	logger.Info("connectionAttempts: ", c.ConnectionAttempts)
 	logger.Info("connectionCooldown: ", c.ConnectionCooldown)
+	logger.Info("tls: ", c.TLS != nil)
 
 	if len(c.SentinelAddresses) > 0 {
 		logger.Info("sentinel:")
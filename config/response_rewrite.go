@@ -0,0 +1,123 @@
+package config
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// ResponseRewrite configures the rewriting of answers, unlike `rewrite` which rewrites the query names
+type ResponseRewrite struct {
+	Rules []ResponseRewriteRule `yaml:"rules"`
+}
+
+// ResponseRewriteRule rewrites the answers of queries for the domains and their subdomains, of all queries if empty
+type ResponseRewriteRule struct {
+	Domains []string `yaml:"domains"`
+	// Addresses replaces A and AAAA records in a network with an address
+	Addresses []AddressRewrite `yaml:"addresses"`
+	// CNAMEs replaces CNAME targets ending with a domain by another domain
+	CNAMEs map[string]string `yaml:"cnames"`
+	// StripTypes removes the records of these types from the answer
+	StripTypes QTypeSet `yaml:"stripTypes"`
+}
+
+// AddressRewrite replaces the addresses in the network `from` by the address `to`
+type AddressRewrite struct {
+	From netip.Prefix `yaml:"from"`
+	To   netip.Addr   `yaml:"to"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *ResponseRewrite) IsEnabled() bool {
+	return len(c.Rules) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ResponseRewrite) LogConfig(logger *logrus.Entry) {
+	for _, rule := range c.Rules {
+		rule.logConfig(logger)
+	}
+}
+
+func (c *ResponseRewriteRule) logConfig(logger *logrus.Entry) {
+	if len(c.Domains) == 0 {
+		logger.Info("all domains:")
+	} else {
+		logger.Infof("%s:", strings.Join(c.Domains, ", "))
+	}
+
+	for _, address := range c.Addresses {
+		logger.Infof("  %s -> %s", address.From, address.To)
+	}
+
+	cnames := maps.Keys(c.CNAMEs)
+	slices.Sort(cnames)
+
+	for _, cname := range cnames {
+		logger.Infof("  CNAME %s -> %s", cname, c.CNAMEs[cname])
+	}
+
+	if len(c.StripTypes) != 0 {
+		types := make([]string, 0, len(c.StripTypes))
+		for qType := range c.StripTypes {
+			types = append(types, qType.String())
+		}
+
+		slices.Sort(types)
+
+		logger.Infof("  strip = %s", strings.Join(types, ", "))
+	}
+}
+
+func (c *ResponseRewrite) validate(logger *logrus.Entry) {
+	rules := make([]ResponseRewriteRule, 0, len(c.Rules))
+
+	for i, rule := range c.Rules {
+		rule.Domains = normalizeDomains(rule.Domains)
+
+		addresses := make([]AddressRewrite, 0, len(rule.Addresses))
+
+		for _, address := range rule.Addresses {
+			if !address.From.IsValid() || !address.To.IsValid() || address.From.Addr().Is4() != address.To.Is4() {
+				logger.Warnf("responseRewrite.rules[%d]: ignoring address rewrite %s -> %s, "+
+					"both must be valid and of the same IP version", i, address.From, address.To)
+
+				continue
+			}
+
+			address.From = address.From.Masked()
+			addresses = append(addresses, address)
+		}
+
+		rule.Addresses = addresses
+
+		cnames := make(map[string]string, len(rule.CNAMEs))
+
+		for from, to := range rule.CNAMEs {
+			from, to = normalizeDomain(from), normalizeDomain(to)
+			if from == "" || to == "" {
+				logger.Warnf("responseRewrite.rules[%d]: ignoring CNAME rewrite with empty domain", i)
+
+				continue
+			}
+
+			cnames[from] = to
+		}
+
+		rule.CNAMEs = cnames
+
+		if len(rule.Addresses) == 0 && len(rule.CNAMEs) == 0 && len(rule.StripTypes) == 0 {
+			logger.Warnf("responseRewrite.rules[%d] doesn't rewrite anything, ignoring it", i)
+
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	c.Rules = rules
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"net/netip"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("ResponseRewrite", func() {
+	var cfg ResponseRewrite
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		Expect(yaml.UnmarshalStrict([]byte(`
+rules:
+  - domains:
+      - Example.com.
+    addresses:
+      - from: 10.0.0.0/8
+        to: 192.168.1.1
+    cnames:
+      cdn.old.net: cdn.new.net.
+    stripTypes:
+      - AAAA
+      - HTTPS
+`), &cfg)).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be true with rules", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+
+		It("should be false without rules", func() {
+			Expect(new(ResponseRewrite).IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log the rules", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"Example.com.:",
+				"  10.0.0.0/8 -> 192.168.1.1",
+				"  CNAME cdn.old.net -> cdn.new.net.",
+				"  strip = AAAA, HTTPS",
+			))
+		})
+
+		It("should log rules for all domains", func() {
+			cfg.Rules[0].Domains = nil
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElement("all domains:"))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should normalize the domains", func() {
+			cfg.validate(logger)
+
+			Expect(cfg.Rules).Should(HaveLen(1))
+			Expect(cfg.Rules[0].Domains).Should(Equal([]string{"example.com"}))
+			Expect(cfg.Rules[0].CNAMEs).Should(Equal(map[string]string{"cdn.old.net": "cdn.new.net"}))
+			Expect(cfg.Rules[0].StripTypes.Contains(dns.Type(dns.TypeAAAA))).Should(BeTrue())
+		})
+
+		It("should ignore address rewrites with different IP versions", func() {
+			cfg.Rules[0].Addresses = append(cfg.Rules[0].Addresses, AddressRewrite{
+				From: netip.MustParsePrefix("10.0.0.0/8"),
+				To:   netip.MustParseAddr("fd00::1"),
+			})
+
+			cfg.validate(logger)
+
+			Expect(cfg.Rules[0].Addresses).Should(HaveLen(1))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("same IP version")))
+		})
+
+		It("should remove rules which don't rewrite anything", func() {
+			cfg.Rules = append(cfg.Rules, ResponseRewriteRule{Domains: []string{"example.org"}})
+
+			cfg.validate(logger)
+
+			Expect(cfg.Rules).Should(HaveLen(1))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("rules[1] doesn't rewrite anything")))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReverseRange generates names for the addresses of a prefix without custom DNS record, for example of a DHCP range
+type ReverseRange struct {
+	Prefix netip.Prefix `yaml:"prefix"`
+	// Domain of the generated names, the first label is the address with dashes: 192-168-178-20.dhcp.lan
+	Domain string `yaml:"domain"`
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ReverseRange) LogConfig(logger *logrus.Entry) {
+	logger.Infof("%s = %s", c.Prefix, c.Domain)
+}
+
+// validateReverseRanges returns the ranges with a valid prefix and domain
+func validateReverseRanges(logger *logrus.Entry, ranges []ReverseRange) []ReverseRange {
+	result := make([]ReverseRange, 0, len(ranges))
+
+	for _, r := range ranges {
+		if !r.Prefix.IsValid() {
+			logger.Warn("customDNS.reverseRanges: range without prefix, ignoring it")
+
+			continue
+		}
+
+		r.Domain = strings.ToLower(strings.Trim(strings.TrimSpace(r.Domain), "."))
+		if r.Domain == "" {
+			logger.Warnf("customDNS.reverseRanges: %s has no domain, ignoring it", r.Prefix)
+
+			continue
+		}
+
+		r.Prefix = r.Prefix.Masked()
+
+		result = append(result, r)
+	}
+
+	return result
+}
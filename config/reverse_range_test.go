@@ -0,0 +1,48 @@
+package config
+
+import (
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReverseRangeConfig", func() {
+	suiteBeforeEach()
+
+	Describe("LogConfig", func() {
+		It("should log the prefix and domain", func() {
+			cfg := ReverseRange{Prefix: netip.MustParsePrefix("192.168.178.0/24"), Domain: "dhcp.lan"}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{"192.168.178.0/24 = dhcp.lan"}))
+		})
+	})
+
+	Describe("validateReverseRanges", func() {
+		It("should normalize the ranges", func() {
+			ranges := validateReverseRanges(logger, []ReverseRange{
+				{Prefix: netip.MustParsePrefix("192.168.178.20/24"), Domain: "DHCP.lan."},
+			})
+
+			Expect(ranges).Should(Equal([]ReverseRange{
+				{Prefix: netip.MustParsePrefix("192.168.178.0/24"), Domain: "dhcp.lan"},
+			}))
+			Expect(hook.Messages).Should(BeEmpty())
+		})
+
+		It("should ignore ranges without prefix or domain", func() {
+			ranges := validateReverseRanges(logger, []ReverseRange{
+				{Domain: "dhcp.lan"},
+				{Prefix: netip.MustParsePrefix("fd00::/64")},
+			})
+
+			Expect(ranges).Should(BeEmpty())
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("range without prefix"),
+				ContainSubstring("fd00::/64 has no domain"),
+			))
+		})
+	})
+})
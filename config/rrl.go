@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RRL configures the response rate limiting for UDP, like in BIND, to not be useful as amplification reflector
+type RRL struct {
+	// Identical responses per second and client prefix
+	ResponsesPerSecond uint `yaml:"responsesPerSecond" default:"0"`
+	// NXDOMAIN responses per second and client prefix, default: responsesPerSecond
+	NXDomainsPerSecond uint `yaml:"nxdomainsPerSecond" default:"0"`
+	// Error responses per second and client prefix, default: responsesPerSecond
+	ErrorsPerSecond uint `yaml:"errorsPerSecond" default:"0"`
+	// Time span the rates are averaged over, a client stays limited up to this long
+	Window Duration `yaml:"window" default:"15s"`
+	// Every n-th limited response is sent truncated, 0 drops all
+	Slip             uint `yaml:"slip" default:"2"`
+	IPv4PrefixLength uint `yaml:"ipv4PrefixLength" default:"24"`
+	IPv6PrefixLength uint `yaml:"ipv6PrefixLength" default:"56"`
+	// Maximum number of tracked responses, the least recently used one is dropped while the table is full
+	MaxTableSize uint `yaml:"maxTableSize" default:"50000"`
+	// Client IPs or CIDRs which are never limited
+	Exempt []string `yaml:"exempt"`
+	// Only log and count limited responses, without limiting them
+	LogOnly bool `yaml:"logOnly" default:"false"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *RRL) IsEnabled() bool {
+	return c.ResponsesPerSecond > 0 || c.NXDomainsPerSecond > 0 || c.ErrorsPerSecond > 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *RRL) LogConfig(logger *logrus.Entry) {
+	logger.Infof("responsesPerSecond = %d", c.ResponsesPerSecond)
+	logger.Infof("nxdomainsPerSecond = %d", c.NXDomainsPerSecond)
+	logger.Infof("errorsPerSecond    = %d", c.ErrorsPerSecond)
+	logger.Infof("window             = %s", c.Window)
+	logger.Infof("slip               = %d", c.Slip)
+	logger.Infof("prefix lengths     = /%d, /%d", c.IPv4PrefixLength, c.IPv6PrefixLength)
+	logger.Infof("maxTableSize       = %d", c.MaxTableSize)
+
+	if len(c.Exempt) > 0 {
+		logger.Infof("exempt             = %s", strings.Join(c.Exempt, ", "))
+	}
+
+	if c.LogOnly {
+		logger.Info("logOnly            = true")
+	}
+}
+
+func (c *RRL) validate(logger *logrus.Entry) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	defaults := mustDefault[RRL]()
+
+	if c.NXDomainsPerSecond == 0 {
+		c.NXDomainsPerSecond = c.ResponsesPerSecond
+	}
+
+	if c.ErrorsPerSecond == 0 {
+		c.ErrorsPerSecond = c.ResponsesPerSecond
+	}
+
+	if !c.Window.IsAboveZero() {
+		logger.Warnf("rrl.window <= 0, setting to %s", defaults.Window)
+		c.Window = defaults.Window
+	}
+
+	if c.MaxTableSize == 0 {
+		logger.Warnf("rrl.maxTableSize == 0, setting to %d", defaults.MaxTableSize)
+		c.MaxTableSize = defaults.MaxTableSize
+	}
+
+	const (
+		ipv4Bits = 32
+		ipv6Bits = 128
+	)
+
+	if c.IPv4PrefixLength > ipv4Bits {
+		logger.Warnf("rrl.ipv4PrefixLength > %d, setting to %d", ipv4Bits, defaults.IPv4PrefixLength)
+		c.IPv4PrefixLength = defaults.IPv4PrefixLength
+	}
+
+	if c.IPv6PrefixLength > ipv6Bits {
+		logger.Warnf("rrl.ipv6PrefixLength > %d, setting to %d", ipv6Bits, defaults.IPv6PrefixLength)
+		c.IPv6PrefixLength = defaults.IPv6PrefixLength
+	}
+
+	exempt := c.Exempt[:0]
+
+	for _, value := range c.Exempt {
+		if _, err := ParseClientPrefix(value); err != nil {
+			logger.Warnf("rrl.exempt: ignoring %s", err)
+
+			continue
+		}
+
+		exempt = append(exempt, value)
+	}
+
+	c.Exempt = exempt
+}
+
+// ExemptPrefixes returns the parsed exempt clients
+func (c *RRL) ExemptPrefixes() ([]netip.Prefix, error) {
+	res := make([]netip.Prefix, 0, len(c.Exempt))
+
+	for _, value := range c.Exempt {
+		prefix, err := ParseClientPrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("rrl.exempt: %w", err)
+		}
+
+		res = append(res, prefix)
+	}
+
+	return res, nil
+}
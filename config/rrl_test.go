@@ -0,0 +1,105 @@
+package config
+
+import (
+	"net/netip"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RRLConfig", func() {
+	var cfg RRL
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[RRL]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("a rate is set", func() {
+			It("should be true", func() {
+				cfg.ErrorsPerSecond = 5
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.ResponsesPerSecond = 5
+			cfg.Exempt = []string{"10.0.0.0/8", "192.168.0.0/16"}
+			cfg.LogOnly = true
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"responsesPerSecond = 5",
+				"window             = 15 seconds",
+				"prefix lengths     = /24, /56",
+				"exempt             = 10.0.0.0/8, 192.168.0.0/16",
+				"logOnly            = true",
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		BeforeEach(func() {
+			cfg.ResponsesPerSecond = 5
+		})
+
+		It("should use the response rate for NXDOMAIN and errors", func() {
+			cfg.ErrorsPerSecond = 1
+
+			cfg.validate(logger)
+
+			Expect(cfg.NXDomainsPerSecond).Should(BeEquivalentTo(5))
+			Expect(cfg.ErrorsPerSecond).Should(BeEquivalentTo(1))
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+
+		It("should fix invalid values", func() {
+			cfg.Window = Duration(-time.Second)
+			cfg.IPv4PrefixLength = 33
+			cfg.IPv6PrefixLength = 129
+			cfg.MaxTableSize = 0
+			cfg.Exempt = []string{"10.0.0.0/8", "laptop"}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Window).Should(Equal(Duration(15 * time.Second)))
+			Expect(cfg.IPv4PrefixLength).Should(BeEquivalentTo(24))
+			Expect(cfg.IPv6PrefixLength).Should(BeEquivalentTo(56))
+			Expect(cfg.MaxTableSize).Should(BeEquivalentTo(50000))
+			Expect(cfg.Exempt).Should(Equal([]string{"10.0.0.0/8"}))
+			Expect(hook.Calls).Should(HaveLen(5))
+		})
+	})
+
+	Describe("ExemptPrefixes", func() {
+		It("should parse the clients", func() {
+			cfg.Exempt = []string{"10.0.0.0/8", "192.168.178.1"}
+
+			Expect(cfg.ExemptPrefixes()).Should(Equal([]netip.Prefix{
+				netip.MustParsePrefix("10.0.0.0/8"),
+				netip.MustParsePrefix("192.168.178.1/32"),
+			}))
+		})
+
+		It("should fail on invalid clients", func() {
+			cfg.Exempt = []string{"laptop"}
+
+			_, err := cfg.ExemptPrefixes()
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
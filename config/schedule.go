@@ -0,0 +1,257 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	minutesPerHour = 60
+	minutesPerDay  = 24 * minutesPerHour
+	daysPerWeek    = 7
+)
+
+// BlockingSchedule restricts the blocking of a group to time windows
+type BlockingSchedule struct {
+	Timezone Timezone         `yaml:"timezone"`
+	Windows  []ScheduleWindow `yaml:"windows"`
+}
+
+// ScheduleWindow is a daily time range on the given days.
+//
+// If `to` is before `from`, the window ends on the next day.
+type ScheduleWindow struct {
+	Days Weekdays  `yaml:"days"`
+	From TimeOfDay `yaml:"from"`
+	To   TimeOfDay `yaml:"to"`
+}
+
+// IsActive returns true if t is inside one of the schedule's windows
+func (s *BlockingSchedule) IsActive(t time.Time) bool {
+	t = t.In(s.Timezone.Location())
+	minute := TimeOfDay(t.Hour()*minutesPerHour + t.Minute())
+	yesterday := t.AddDate(0, 0, -1).Weekday()
+
+	for _, w := range s.Windows {
+		if w.From < w.To {
+			if w.Days.Contains(t.Weekday()) && minute >= w.From && minute < w.To {
+				return true
+			}
+
+			continue
+		}
+
+		// window spans midnight, or the whole day if from == to
+		if w.Days.Contains(t.Weekday()) && minute >= w.From {
+			return true
+		}
+
+		if w.Days.Contains(yesterday) && minute < w.To {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextChange returns the next time after t at which IsActive changes.
+//
+// The zero time is returned if the state never changes.
+func (s *BlockingSchedule) NextChange(t time.Time) time.Time {
+	loc := s.Timezone.Location()
+	t = t.In(loc)
+	current := s.IsActive(t)
+
+	var candidates []time.Time
+
+	// one extra day for windows spanning midnight
+	for offset := 0; offset <= daysPerWeek+1; offset++ {
+		day := t.AddDate(0, 0, offset)
+
+		for _, w := range s.Windows {
+			for _, tod := range []TimeOfDay{w.From, w.To} {
+				candidate := time.Date(day.Year(), day.Month(), day.Day(), tod.Hour(), tod.Minute(), 0, 0, loc)
+
+				if candidate.After(t) {
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b time.Time) int { return a.Compare(b) })
+
+	for _, candidate := range candidates {
+		if s.IsActive(candidate) != current {
+			return candidate
+		}
+	}
+
+	return time.Time{}
+}
+
+// LogConfig implements `config.Configurable`.
+func (s *BlockingSchedule) LogConfig(logger *logrus.Entry) {
+	logger.Infof("timezone = %s", s.Timezone)
+
+	for _, w := range s.Windows {
+		logger.Infof("- %s %s-%s", w.Days, w.From, w.To)
+	}
+}
+
+// Timezone is a time zone from the IANA database, e.g. "Europe/Berlin". Defaults to the local time zone.
+type Timezone struct {
+	loc *time.Location
+}
+
+// Location returns the time.Location of the time zone
+func (z Timezone) Location() *time.Location {
+	if z.loc == nil {
+		return time.Local
+	}
+
+	return z.loc
+}
+
+// String implements `fmt.Stringer`.
+func (z Timezone) String() string {
+	return z.Location().String()
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (z *Timezone) UnmarshalText(data []byte) error {
+	loc, err := time.LoadLocation(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid timezone '%s': %w", data, err)
+	}
+
+	z.loc = loc
+
+	return nil
+}
+
+// TimeOfDay is the number of minutes since midnight, configured as "HH:MM"
+type TimeOfDay uint16
+
+// Hour returns the hour part
+func (t TimeOfDay) Hour() int {
+	return int(t) / minutesPerHour
+}
+
+// Minute returns the minute part
+func (t TimeOfDay) Minute() int {
+	return int(t) % minutesPerHour
+}
+
+// String implements `fmt.Stringer`.
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", t.Hour(), t.Minute())
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (t *TimeOfDay) UnmarshalText(data []byte) error {
+	var hour, minute int
+
+	_, err := fmt.Sscanf(string(data), "%d:%d", &hour, &minute)
+	if err != nil || hour < 0 || minute < 0 || minute >= minutesPerHour ||
+		hour*minutesPerHour+minute > minutesPerDay {
+		return fmt.Errorf("invalid time of day '%s', expected HH:MM", data)
+	}
+
+	*t = TimeOfDay(hour*minutesPerHour + minute)
+
+	return nil
+}
+
+// Weekdays is a set of days, configured as a list of days ("mon") or ranges ("mon-fri").
+// An empty set contains all days.
+type Weekdays []time.Weekday
+
+// Contains returns true if d is in the set
+func (w Weekdays) Contains(d time.Weekday) bool {
+	return len(w) == 0 || slices.Contains(w, d)
+}
+
+// String implements `fmt.Stringer`.
+func (w Weekdays) String() string {
+	if len(w) == 0 {
+		return "daily"
+	}
+
+	names := make([]string, 0, len(w))
+
+	for _, d := range w {
+		names = append(names, d.String()[:3])
+	}
+
+	return strings.Join(names, ",")
+}
+
+// UnmarshalYAML implements `yaml.Unmarshaler`.
+func (w *Weekdays) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var entries []string
+
+	if err := unmarshal(&entries); err != nil {
+		var single string
+
+		if err := unmarshal(&single); err != nil {
+			return err
+		}
+
+		entries = strings.Split(single, ",")
+	}
+
+	res := make(Weekdays, 0, daysPerWeek)
+
+	for _, entry := range entries {
+		from, to, isRange := strings.Cut(entry, "-")
+
+		first, err := parseWeekday(from)
+		if err != nil {
+			return err
+		}
+
+		last := first
+
+		if isRange {
+			last, err = parseWeekday(to)
+			if err != nil {
+				return err
+			}
+		}
+
+		for d := first; ; d = (d + 1) % daysPerWeek {
+			if !slices.Contains(res, d) {
+				res = append(res, d)
+			}
+
+			if d == last {
+				break
+			}
+		}
+	}
+
+	*w = res
+
+	return nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	const minLen = 3
+
+	if len(s) >= minLen {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.HasPrefix(strings.ToLower(d.String()), s) {
+				return d, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("invalid weekday '%s'", s)
+}
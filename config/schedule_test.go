@@ -0,0 +1,131 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("BlockingSchedule", func() {
+	var (
+		cfg BlockingSchedule
+		loc *time.Location
+	)
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		loc, err = time.LoadLocation("Europe/Berlin")
+		Expect(err).Should(Succeed())
+
+		data := `
+timezone: Europe/Berlin
+windows:
+  - days: mon-fri
+    from: 08:00
+    to: 16:00
+  - days: [sat, sunday]
+    from: 22:00
+    to: 02:00
+`
+		cfg = BlockingSchedule{}
+		Expect(yaml.UnmarshalStrict([]byte(data), &cfg)).Should(Succeed())
+	})
+
+	at := func(day, hour, minute int) time.Time {
+		// 2024-01-01 is a monday
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, loc)
+	}
+
+	Describe("UnmarshalYAML", func() {
+		It("should parse the schedule", func() {
+			Expect(cfg.Timezone.String()).Should(Equal("Europe/Berlin"))
+			Expect(cfg.Windows).Should(HaveLen(2))
+			Expect(cfg.Windows[0].Days).Should(Equal(Weekdays{
+				time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+			}))
+			Expect(cfg.Windows[0].From).Should(Equal(TimeOfDay(8 * 60)))
+			Expect(cfg.Windows[1].Days).Should(Equal(Weekdays{time.Saturday, time.Sunday}))
+		})
+
+		It("should support ranges across the end of the week", func() {
+			var days Weekdays
+			Expect(yaml.UnmarshalStrict([]byte(`fri-mon`), &days)).Should(Succeed())
+			Expect(days).Should(Equal(Weekdays{time.Friday, time.Saturday, time.Sunday, time.Monday}))
+		})
+
+		It("should reject invalid values", func() {
+			var days Weekdays
+			Expect(yaml.UnmarshalStrict([]byte(`[mo]`), &days)).Should(MatchError(ContainSubstring("invalid weekday")))
+
+			var tod TimeOfDay
+			Expect(yaml.UnmarshalStrict([]byte(`"25:00"`), &tod)).Should(MatchError(ContainSubstring("invalid time")))
+			Expect(yaml.UnmarshalStrict([]byte(`"10:60"`), &tod)).Should(HaveOccurred())
+
+			var tz Timezone
+			Expect(yaml.UnmarshalStrict([]byte(`Mars/Olympus`), &tz)).Should(MatchError(ContainSubstring("invalid timezone")))
+		})
+	})
+
+	Describe("IsActive", func() {
+		It("should match daytime windows", func() {
+			Expect(cfg.IsActive(at(1, 7, 59))).Should(BeFalse())
+			Expect(cfg.IsActive(at(1, 8, 0))).Should(BeTrue())
+			Expect(cfg.IsActive(at(5, 15, 59))).Should(BeTrue())
+			Expect(cfg.IsActive(at(5, 16, 0))).Should(BeFalse())
+			Expect(cfg.IsActive(at(6, 10, 0))).Should(BeFalse())
+		})
+
+		It("should match windows spanning midnight", func() {
+			Expect(cfg.IsActive(at(6, 23, 0))).Should(BeTrue())
+			Expect(cfg.IsActive(at(7, 1, 0))).Should(BeTrue())
+			// sunday night window ends monday morning
+			Expect(cfg.IsActive(at(8, 1, 59))).Should(BeTrue())
+			Expect(cfg.IsActive(at(8, 2, 0))).Should(BeFalse())
+			// friday night is not in the window
+			Expect(cfg.IsActive(at(6, 1, 0))).Should(BeFalse())
+		})
+
+		It("should evaluate in the configured time zone", func() {
+			Expect(cfg.IsActive(at(1, 8, 30).UTC())).Should(BeTrue())
+		})
+
+		It("should match the whole day if every day is included", func() {
+			cfg.Windows = []ScheduleWindow{{}}
+
+			Expect(cfg.IsActive(at(3, 0, 0))).Should(BeTrue())
+			Expect(cfg.IsActive(at(3, 23, 59))).Should(BeTrue())
+		})
+	})
+
+	Describe("NextChange", func() {
+		It("should return the next boundary", func() {
+			Expect(cfg.NextChange(at(1, 7, 0))).Should(Equal(at(1, 8, 0)))
+			Expect(cfg.NextChange(at(1, 8, 0))).Should(Equal(at(1, 16, 0)))
+			Expect(cfg.NextChange(at(5, 17, 0))).Should(Equal(at(6, 22, 0)))
+			Expect(cfg.NextChange(at(6, 23, 0))).Should(Equal(at(7, 2, 0)))
+		})
+
+		It("should return the zero time if the state never changes", func() {
+			cfg.Windows = nil
+
+			Expect(cfg.NextChange(at(1, 0, 0))).Should(BeZero())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log the windows", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"timezone = Europe/Berlin",
+				"- Mon,Tue,Wed,Thu,Fri 08:00-16:00",
+				"- Sat,Sun 22:00-02:00",
+			))
+		})
+	})
+})
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"net"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sinkhole serves a block page for HTTP(S) requests sent to the blocking IP
+type Sinkhole struct {
+	HTTP  ListenConfig `yaml:"http"`
+	HTTPS ListenConfig `yaml:"https"`
+	// Go html/template file of the page, the built-in page is used if empty
+	Template string `yaml:"template"`
+	// Duration a domain is allowed for with the button of the page, the button is hidden if 0
+	AllowDuration Duration `yaml:"allowDuration" default:"0"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Sinkhole) IsEnabled() bool {
+	return len(c.HTTP) != 0 || len(c.HTTPS) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Sinkhole) LogConfig(logger *logrus.Entry) {
+	if len(c.HTTP) != 0 {
+		logger.Infof("http = %v", c.HTTP)
+	}
+
+	if len(c.HTTPS) != 0 {
+		logger.Infof("https = %v", c.HTTPS)
+	}
+
+	if c.Template != "" {
+		logger.Infof("template = %s", c.Template)
+	} else {
+		logger.Info("template = built-in")
+	}
+
+	if c.AllowDuration.IsAboveZero() {
+		logger.Infof("allowDuration = %s", c.AllowDuration)
+	} else {
+		logger.Info("allowDuration = disabled")
+	}
+}
+
+func (c *Sinkhole) validate(logger *logrus.Entry, blocking *Blocking) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	if c.Template != "" {
+		if _, err := os.Stat(c.Template); err != nil {
+			logger.Warnf("sinkhole.template: can't read '%s', using the built-in page: %v", c.Template, err)
+
+			c.Template = ""
+		}
+	}
+
+	if !hasIPBlockType(blocking) {
+		logger.Warn("sinkhole: no block type is an IP address, blocked domains don't resolve to the sinkhole")
+	}
+}
+
+// hasIPBlockType returns true if a block type of the blocking configuration contains an IP address
+func hasIPBlockType(blocking *Blocking) bool {
+	isIPs := func(blockType string) bool {
+		for _, part := range strings.Split(blockType, ",") {
+			if net.ParseIP(strings.TrimSpace(part)) != nil {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if isIPs(blocking.BlockType) {
+		return true
+	}
+
+	for _, blockTypes := range []map[string]string{blocking.BlockTypes, blocking.ClientBlockTypes} {
+		for _, blockType := range blockTypes {
+			if isIPs(blockType) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
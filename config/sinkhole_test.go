@@ -0,0 +1,74 @@
+package config
+
+import (
+	"time"
+
+	"github.com/creasty/defaults"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sinkhole", func() {
+	var c Sinkhole
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		c = Sinkhole{}
+		Expect(defaults.Set(&c)).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(c.IsEnabled()).Should(BeFalse())
+		})
+
+		It("should be true with a listener", func() {
+			c.HTTPS = ListenConfig{":443"}
+
+			Expect(c.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log the listeners and the allow duration", func() {
+			c.HTTP = ListenConfig{"192.168.178.10:80"}
+			c.AllowDuration = Duration(15 * time.Minute)
+
+			c.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"http = [192.168.178.10:80]",
+				"template = built-in",
+				"allowDuration = 15 minutes",
+			}))
+		})
+	})
+
+	Describe("validate", func() {
+		BeforeEach(func() {
+			c.HTTP = ListenConfig{":80"}
+		})
+
+		It("should warn if no block type is an IP", func() {
+			c.validate(logger, &Blocking{BlockType: "zeroIP", BlockTypes: map[string]string{"ads": "nxDomain"}})
+
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("no block type is an IP address")))
+		})
+
+		It("should accept an IP block type of a group", func() {
+			c.validate(logger, &Blocking{BlockType: "zeroIP", BlockTypes: map[string]string{"ads": "192.168.178.10"}})
+
+			Expect(hook.Messages).Should(BeEmpty())
+		})
+
+		It("should use the built-in page if the template doesn't exist", func() {
+			c.Template = "/does/not/exist.html"
+
+			c.validate(logger, &Blocking{BlockType: "192.168.178.10"})
+
+			Expect(c.Template).Should(BeEmpty())
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("can't read '/does/not/exist.html'")))
+		})
+	})
+})
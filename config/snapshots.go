@@ -0,0 +1,27 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Snapshots configures the snapshots of the configuration and runtime state, to roll back to
+type Snapshots struct {
+	// Directory to store the snapshots in, empty disables snapshots
+	Directory string `yaml:"directory"`
+	// Interval between scheduled snapshots, 0 only takes snapshots on start and before a rollback
+	Interval Duration `yaml:"interval" default:"24h"`
+	// Number of snapshots to keep, 0 keeps all
+	Keep uint `yaml:"keep" default:"7"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Snapshots) IsEnabled() bool {
+	return c.Directory != ""
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Snapshots) LogConfig(logger *logrus.Entry) {
+	logger.Infof("directory = %s", c.Directory)
+	logger.Infof("interval  = %s", c.Interval)
+	logger.Infof("keep      = %d", c.Keep)
+}
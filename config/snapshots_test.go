@@ -0,0 +1,48 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SnapshotsConfig", func() {
+	var cfg Snapshots
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[Snapshots]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("a directory is configured", func() {
+			It("should be true", func() {
+				cfg.Directory = "/var/lib/blocky/snapshots"
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.Directory = "/var/lib/blocky/snapshots"
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("directory = /var/lib/blocky/snapshots"),
+				ContainSubstring("interval  = 1 day"),
+				ContainSubstring("keep      = 7"),
+			))
+		})
+	})
+})
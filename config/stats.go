@@ -0,0 +1,54 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Stats configures the rolling query statistics of the API
+type Stats struct {
+	Enable bool `yaml:"enable" default:"false"`
+	// Time span the statistics are kept for
+	Retention Duration `yaml:"retention" default:"24h"`
+	// Size of the time buckets the queries are counted in, the smallest period of the statistics
+	Resolution Duration `yaml:"resolution" default:"10m"`
+	// Maximum number of distinct domains and clients counted per time bucket
+	MaxKeys uint `yaml:"maxKeys" default:"10000"`
+	// Store the statistics in redis, so they are shared by all instances and survive restarts
+	Redis bool `yaml:"redis" default:"false"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Stats) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Stats) LogConfig(logger *logrus.Entry) {
+	logger.Infof("retention  = %s", c.Retention)
+	logger.Infof("resolution = %s", c.Resolution)
+	logger.Infof("maxKeys    = %d", c.MaxKeys)
+	logger.Infof("redis      = %t", c.Redis)
+}
+
+func (c *Stats) validate(logger *logrus.Entry, redis *Redis) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	defaults := mustDefault[Stats]()
+
+	if !c.Resolution.IsAboveZero() {
+		logger.Warnf("stats.resolution <= 0, setting to %s", defaults.Resolution)
+		c.Resolution = defaults.Resolution
+	}
+
+	if c.Retention.ToDuration() < c.Resolution.ToDuration() {
+		logger.Warnf("stats.retention < stats.resolution, setting to %s", c.Resolution)
+		c.Retention = c.Resolution
+	}
+
+	if c.Redis && !redis.IsEnabled() {
+		logger.Warn("stats.redis has no effect without redis configuration, statistics are kept in memory")
+		c.Redis = false
+	}
+}
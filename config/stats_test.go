@@ -0,0 +1,89 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StatsConfig", func() {
+	var cfg Stats
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[Stats]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("enabled", func() {
+			It("should be true", func() {
+				cfg.Enable = true
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("retention  = 1 day"),
+				ContainSubstring("resolution = 10 minutes"),
+				ContainSubstring("maxKeys    = 10000"),
+				ContainSubstring("redis      = false"),
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		BeforeEach(func() {
+			cfg.Enable = true
+		})
+
+		It("should accept the defaults", func() {
+			cfg.validate(logger, &Redis{})
+
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+
+		It("should fix invalid durations", func() {
+			cfg.Resolution = 0
+			cfg.Retention = Duration(time.Minute)
+
+			cfg.validate(logger, &Redis{})
+
+			Expect(cfg.Resolution).Should(Equal(Duration(10 * time.Minute)))
+			Expect(cfg.Retention).Should(Equal(cfg.Resolution))
+			Expect(hook.Calls).Should(HaveLen(2))
+		})
+
+		It("should disable redis without redis configuration", func() {
+			cfg.Redis = true
+
+			cfg.validate(logger, &Redis{})
+
+			Expect(cfg.Redis).Should(BeFalse())
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("stats.redis has no effect")))
+		})
+
+		It("should keep redis with redis configuration", func() {
+			cfg.Redis = true
+
+			cfg.validate(logger, &Redis{Address: "localhost:6379"})
+
+			Expect(cfg.Redis).Should(BeTrue())
+		})
+	})
+})
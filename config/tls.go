@@ -0,0 +1,367 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/sirupsen/logrus"
+)
+
+// TLS is the TLS policy shared by all TLS surfaces, with optional overrides per surface
+type TLS struct {
+	TLSPolicy `yaml:",inline"`
+
+	DoT       *TLSPolicy `yaml:"dot"`
+	DoH       *TLSPolicy `yaml:"doh"`
+	Upstreams *TLSPolicy `yaml:"upstreams"`
+	Redis     *TLSPolicy `yaml:"redis"`
+	NATS      *TLSPolicy `yaml:"nats"`
+	Database  *TLSPolicy `yaml:"database"`
+	Events    *TLSPolicy `yaml:"events"`
+}
+
+// TLSPolicy configures the TLS settings of one surface
+//
+// Settings that are not set use Go's secure defaults.
+type TLSPolicy struct {
+	MinVersion   TLSVersion       `yaml:"minVersion"`
+	CipherSuites []TLSCipherSuite `yaml:"cipherSuites"`
+	Curves       []TLSCurve       `yaml:"curves"`
+
+	// Listeners only: client certificate policy, verified using CAFile
+	ClientAuth TLSClientAuth `yaml:"clientAuth"`
+
+	// Listeners: CA for client certificates; clients: CA for server certificates (default: system pool)
+	CAFile string `yaml:"caFile"`
+
+	// Clients only: certificate presented to the server for mutual TLS
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *TLS) IsEnabled() bool {
+	return c.TLSPolicy.IsEnabled() ||
+		c.DoT != nil || c.DoH != nil || c.Upstreams != nil || c.Redis != nil || c.NATS != nil || c.Database != nil ||
+		c.Events != nil
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *TLS) LogConfig(logger *logrus.Entry) {
+	if c.TLSPolicy.IsEnabled() {
+		c.TLSPolicy.LogConfig(logger)
+	}
+
+	logOverride := func(name string, policy *TLSPolicy) {
+		if policy == nil {
+			return
+		}
+
+		logger.Infof("%s:", name)
+		log.WithIndent(logger, "  ", policy.LogConfig)
+	}
+
+	logOverride("dot", c.DoT)
+	logOverride("doh", c.DoH)
+	logOverride("upstreams", c.Upstreams)
+	logOverride("redis", c.Redis)
+	logOverride("nats", c.NATS)
+	logOverride("database", c.Database)
+	logOverride("events", c.Events)
+}
+
+// ForDoT returns the effective policy of the DoT listener
+func (c *TLS) ForDoT() TLSPolicy { return c.TLSPolicy.merge(c.DoT) }
+
+// ForDoH returns the effective policy of the DoH/HTTPS listener
+func (c *TLS) ForDoH() TLSPolicy { return c.TLSPolicy.merge(c.DoH) }
+
+// ForUpstreams returns the effective policy of DoT/DoH upstream connections
+func (c *TLS) ForUpstreams() TLSPolicy { return c.TLSPolicy.merge(c.Upstreams) }
+
+// ForRedis returns the effective policy of the redis connection, nil if redis doesn't use TLS
+func (c *TLS) ForRedis() *TLSPolicy { return c.mergeOptional(c.Redis) }
+
+// ForNATS returns the effective policy of the NATS connection, nil if NATS doesn't use TLS
+func (c *TLS) ForNATS() *TLSPolicy { return c.mergeOptional(c.NATS) }
+
+// ForDatabase returns the effective policy of query log database connections, nil if not configured
+func (c *TLS) ForDatabase() *TLSPolicy { return c.mergeOptional(c.Database) }
+
+// ForEvents returns the effective policy of the event broker connection, nil if not configured
+func (c *TLS) ForEvents() *TLSPolicy { return c.mergeOptional(c.Events) }
+
+func (c *TLS) mergeOptional(override *TLSPolicy) *TLSPolicy {
+	if override == nil {
+		return nil
+	}
+
+	res := c.TLSPolicy.merge(override)
+
+	return &res
+}
+
+func (c *TLS) validate(logger *logrus.Entry) {
+	for name, policy := range map[string]*TLSPolicy{
+		"tls":           &c.TLSPolicy,
+		"tls.dot":       c.DoT,
+		"tls.doh":       c.DoH,
+		"tls.upstreams": c.Upstreams,
+		"tls.redis":     c.Redis,
+		"tls.nats":      c.NATS,
+		"tls.database":  c.Database,
+		"tls.events":    c.Events,
+	} {
+		if policy != nil {
+			policy.validate(log.WithPrefix(logger, name))
+		}
+	}
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *TLSPolicy) IsEnabled() bool {
+	return c.MinVersion != 0 || len(c.CipherSuites) != 0 || len(c.Curves) != 0 ||
+		c.ClientAuth != TLSClientAuthNone || c.CAFile != "" || c.CertFile != "" || c.KeyFile != ""
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *TLSPolicy) LogConfig(logger *logrus.Entry) {
+	if c.MinVersion != 0 {
+		logger.Infof("minVersion = %s", c.MinVersion)
+	}
+
+	if len(c.CipherSuites) != 0 {
+		logger.Infof("cipherSuites = %s", c.CipherSuites)
+	}
+
+	if len(c.Curves) != 0 {
+		logger.Infof("curves = %s", c.Curves)
+	}
+
+	if c.ClientAuth != TLSClientAuthNone {
+		logger.Infof("clientAuth = %s", c.ClientAuth)
+	}
+
+	if c.CAFile != "" {
+		logger.Infof("caFile = %s", c.CAFile)
+	}
+
+	if c.CertFile != "" {
+		logger.Infof("certFile = %s", c.CertFile)
+	}
+}
+
+// merge returns a copy of c where all settings defined in override replace the ones from c
+func (c TLSPolicy) merge(override *TLSPolicy) TLSPolicy {
+	if override == nil {
+		return c
+	}
+
+	if override.MinVersion != 0 {
+		c.MinVersion = override.MinVersion
+	}
+
+	if len(override.CipherSuites) != 0 {
+		c.CipherSuites = override.CipherSuites
+	}
+
+	if len(override.Curves) != 0 {
+		c.Curves = override.Curves
+	}
+
+	if override.ClientAuth != TLSClientAuthNone {
+		c.ClientAuth = override.ClientAuth
+	}
+
+	if override.CAFile != "" {
+		c.CAFile = override.CAFile
+	}
+
+	if override.CertFile != "" || override.KeyFile != "" {
+		c.CertFile = override.CertFile
+		c.KeyFile = override.KeyFile
+	}
+
+	return c
+}
+
+func (c *TLSPolicy) validate(logger *logrus.Entry) {
+	if c.MinVersion != 0 {
+		c.MinVersion.validate(logger)
+	}
+
+	if (c.ClientAuth == TLSClientAuthVerifyIfGiven || c.ClientAuth == TLSClientAuthRequireAndVerify) &&
+		c.CAFile == "" {
+		logger.Warnf("clientAuth %s without caFile: client certificates are verified using the system pool", c.ClientAuth)
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		logger.Warn("certFile and keyFile must both be set, ignoring client certificate")
+
+		c.CertFile = ""
+		c.KeyFile = ""
+	}
+}
+
+// ApplyServer applies the policy to the TLS config of a listener
+func (c *TLSPolicy) ApplyServer(tlsCfg *tls.Config) error {
+	c.applyCommon(tlsCfg)
+
+	tlsCfg.ClientAuth = tls.ClientAuthType(c.ClientAuth)
+
+	if c.CAFile != "" {
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return err
+		}
+
+		tlsCfg.ClientCAs = pool
+	}
+
+	return nil
+}
+
+// ApplyClient applies the policy to the TLS config of an outgoing connection
+func (c *TLSPolicy) ApplyClient(tlsCfg *tls.Config) error {
+	c.applyCommon(tlsCfg)
+
+	if c.CAFile != "" {
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return err
+		}
+
+		tlsCfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return fmt.Errorf("can't load client certificate: %w", err)
+		}
+
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return nil
+}
+
+// NewClientTLSConfig creates a TLS config for outgoing connections to serverName
+func (c *TLSPolicy) NewClientTLSConfig(serverName string) (*tls.Config, error) {
+	res := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if err := c.ApplyClient(res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *TLSPolicy) applyCommon(tlsCfg *tls.Config) {
+	if c.MinVersion != 0 {
+		tlsCfg.MinVersion = uint16(c.MinVersion)
+	}
+
+	if len(c.CipherSuites) != 0 {
+		tlsCfg.CipherSuites = make([]uint16, 0, len(c.CipherSuites))
+
+		for _, suite := range c.CipherSuites {
+			tlsCfg.CipherSuites = append(tlsCfg.CipherSuites, uint16(suite))
+		}
+	}
+
+	if len(c.Curves) != 0 {
+		tlsCfg.CurvePreferences = make([]tls.CurveID, 0, len(c.Curves))
+
+		for _, curve := range c.Curves {
+			tlsCfg.CurvePreferences = append(tlsCfg.CurvePreferences, tls.CurveID(curve))
+		}
+	}
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no valid certificate found in CA file '%s'", caFile)
+	}
+
+	return pool, nil
+}
+
+// TLSCipherSuite is a TLS cipher suite, configured by its IANA name (e.g. TLS_AES_128_GCM_SHA256)
+type TLSCipherSuite uint16
+
+// String implements `fmt.Stringer`.
+func (s TLSCipherSuite) String() string {
+	return tls.CipherSuiteName(uint16(s))
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (s *TLSCipherSuite) UnmarshalText(data []byte) error {
+	name := strings.TrimSpace(string(data))
+
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			*s = TLSCipherSuite(suite.ID)
+
+			return nil
+		}
+	}
+
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.Name == name {
+			return fmt.Errorf("cipher suite %s is insecure", name)
+		}
+	}
+
+	return fmt.Errorf("unknown cipher suite '%s'", name)
+}
+
+// TLSCurve is an elliptic curve used for TLS key exchange: X25519, P256, P384 or P521
+type TLSCurve tls.CurveID
+
+//nolint:gochecknoglobals
+var tlsCurveNames = map[string]tls.CurveID{
+	"X25519": tls.X25519,
+	"P256":   tls.CurveP256,
+	"P384":   tls.CurveP384,
+	"P521":   tls.CurveP521,
+}
+
+// String implements `fmt.Stringer`.
+func (c TLSCurve) String() string {
+	for name, id := range tlsCurveNames {
+		if id == tls.CurveID(c) {
+			return name
+		}
+	}
+
+	return tls.CurveID(c).String()
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (c *TLSCurve) UnmarshalText(data []byte) error {
+	name := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(string(data)), "-", ""))
+	name = strings.TrimPrefix(name, "CURVE")
+
+	id, ok := tlsCurveNames[name]
+	if !ok {
+		return fmt.Errorf("unknown curve '%s', must be one of X25519, P256, P384, P521", data)
+	}
+
+	*c = TLSCurve(id)
+
+	return nil
+}
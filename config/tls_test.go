@@ -0,0 +1,193 @@
+package config
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("TLS", func() {
+	var cfg TLS
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		cfg = TLS{}
+	})
+
+	Describe("UnmarshalYAML", func() {
+		It("should parse global settings and overrides", func() {
+			data := `
+minVersion: 1.3
+cipherSuites:
+  - TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+curves: [X25519, P-256, CurveP384]
+dot:
+  clientAuth: requireAndVerify
+redis:
+  caFile: /etc/ca.pem
+`
+			Expect(yaml.UnmarshalStrict([]byte(data), &cfg)).Should(Succeed())
+
+			Expect(cfg.MinVersion).Should(Equal(TLSVersion13))
+			Expect(cfg.CipherSuites).Should(Equal([]TLSCipherSuite{
+				TLSCipherSuite(tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256),
+			}))
+			Expect(cfg.Curves).Should(Equal([]TLSCurve{
+				TLSCurve(tls.X25519), TLSCurve(tls.CurveP256), TLSCurve(tls.CurveP384),
+			}))
+			Expect(cfg.DoT.ClientAuth).Should(Equal(TLSClientAuthRequireAndVerify))
+			Expect(cfg.Redis.CAFile).Should(Equal("/etc/ca.pem"))
+			Expect(cfg.DoH).Should(BeNil())
+		})
+
+		It("should reject insecure cipher suites", func() {
+			data := `cipherSuites: [TLS_RSA_WITH_RC4_128_SHA]`
+
+			Expect(yaml.UnmarshalStrict([]byte(data), &cfg)).Should(MatchError(ContainSubstring("insecure")))
+		})
+
+		It("should reject unknown cipher suites and curves", func() {
+			Expect(yaml.UnmarshalStrict([]byte(`cipherSuites: [foo]`), &cfg)).
+				Should(MatchError(ContainSubstring("unknown cipher suite")))
+			Expect(yaml.UnmarshalStrict([]byte(`curves: [foo]`), &cfg)).
+				Should(MatchError(ContainSubstring("unknown curve")))
+		})
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		It("should be true with an override", func() {
+			cfg.Database = &TLSPolicy{}
+
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log global settings and overrides", func() {
+			cfg.MinVersion = TLSVersion13
+			cfg.DoH = &TLSPolicy{Curves: []TLSCurve{TLSCurve(tls.X25519)}}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"minVersion = 1.3",
+				"doh:",
+				"curves = [X25519]",
+			))
+		})
+	})
+
+	Describe("effective policies", func() {
+		BeforeEach(func() {
+			cfg.TLSPolicy = TLSPolicy{
+				MinVersion: TLSVersion12,
+				Curves:     []TLSCurve{TLSCurve(tls.X25519)},
+			}
+		})
+
+		It("should use the global policy without override", func() {
+			Expect(cfg.ForDoT()).Should(Equal(cfg.TLSPolicy))
+			Expect(cfg.ForRedis()).Should(BeNil())
+		})
+
+		It("should replace only the overridden settings", func() {
+			cfg.Upstreams = &TLSPolicy{MinVersion: TLSVersion13}
+			cfg.Database = &TLSPolicy{CertFile: "cert.pem", KeyFile: "key.pem"}
+
+			upstreams := cfg.ForUpstreams()
+			Expect(upstreams.MinVersion).Should(Equal(TLSVersion13))
+			Expect(upstreams.Curves).Should(Equal(cfg.Curves))
+
+			database := cfg.ForDatabase()
+			Expect(database).ShouldNot(BeNil())
+			Expect(database.MinVersion).Should(Equal(TLSVersion12))
+			Expect(database.CertFile).Should(Equal("cert.pem"))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should replace insecure versions", func() {
+			cfg.DoT = &TLSPolicy{MinVersion: TLSVersion10}
+
+			cfg.validate(logger)
+
+			Expect(cfg.DoT.MinVersion).Should(Equal(TLSVersion12))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("insecure")))
+		})
+
+		It("should ignore incomplete client certificates", func() {
+			cfg.CertFile = "cert.pem"
+
+			cfg.validate(logger)
+
+			Expect(cfg.CertFile).Should(BeEmpty())
+		})
+	})
+
+	Describe("Apply", func() {
+		var tmpDir *TmpFolder
+
+		BeforeEach(func() {
+			tmpDir = NewTmpFolder("config")
+		})
+
+		It("should load the CA file", func() {
+			cert, err := util.TLSGenerateSelfSignedCert([]string{"blocky.invalid"})
+			Expect(err).Should(Succeed())
+
+			caFile := tmpDir.CreateStringFile("ca.pem",
+				string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})))
+
+			policy := TLSPolicy{CAFile: caFile.Path, ClientAuth: TLSClientAuthRequireAndVerify}
+
+			serverCfg := &tls.Config{}
+			Expect(policy.ApplyServer(serverCfg)).Should(Succeed())
+			Expect(serverCfg.ClientCAs).ShouldNot(BeNil())
+			Expect(serverCfg.ClientAuth).Should(Equal(tls.RequireAndVerifyClientCert))
+
+			clientCfg, err := policy.NewClientTLSConfig("blocky.invalid")
+			Expect(err).Should(Succeed())
+			Expect(clientCfg.RootCAs).ShouldNot(BeNil())
+			Expect(clientCfg.ServerName).Should(Equal("blocky.invalid"))
+		})
+
+		It("should fail on invalid files", func() {
+			invalid := tmpDir.CreateStringFile("invalid.pem", "invalid")
+
+			Expect((&TLSPolicy{CAFile: invalid.Path}).ApplyClient(&tls.Config{})).ShouldNot(Succeed())
+			Expect((&TLSPolicy{CertFile: invalid.Path, KeyFile: invalid.Path}).ApplyClient(&tls.Config{})).
+				ShouldNot(Succeed())
+		})
+	})
+
+	Describe("Config", func() {
+		It("should propagate the effective policies", func() {
+			c, err := WithDefaults[Config]()
+			Expect(err).Should(Succeed())
+
+			data := `
+tls:
+  minVersion: 1.3
+  redis:
+    curves: [P384]
+`
+			Expect(unmarshalConfig(logger, []byte(data), &c)).Should(Succeed())
+
+			Expect(c.Upstreams.TLS.MinVersion).Should(Equal(TLSVersion13))
+			Expect(c.Redis.TLS).ShouldNot(BeNil())
+			Expect(c.Redis.TLS.MinVersion).Should(Equal(TLSVersion13))
+			Expect(c.Redis.TLS.Curves).Should(Equal([]TLSCurve{TLSCurve(tls.CurveP384)}))
+			Expect(c.QueryLog.TLS).Should(BeNil())
+		})
+	})
+})
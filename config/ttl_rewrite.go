@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TTLRewrite overrides the TTLs of the answers of the domains matching the rules, replacing the global
+// `caching.minTime` and `caching.maxTime` for them
+type TTLRewrite struct {
+	Rules []TTLRewriteRule `yaml:"rules"`
+}
+
+// TTLRewriteRule sets or limits the TTLs of the answers of the domains, the most specific domain of all rules wins.
+// A domain matches itself and its subdomains, a domain starting with `*.` only its subdomains.
+type TTLRewriteRule struct {
+	Domains []string `yaml:"domains"`
+	// TTL replaces the TTLs, MinTime and MaxTime are ignored if it is set
+	TTL     Duration `yaml:"ttl"`
+	MinTime Duration `yaml:"minTime"`
+	MaxTime Duration `yaml:"maxTime"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *TTLRewrite) IsEnabled() bool {
+	return len(c.Rules) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *TTLRewrite) LogConfig(logger *logrus.Entry) {
+	for _, rule := range c.Rules {
+		rule.logConfig(logger)
+	}
+}
+
+func (c *TTLRewriteRule) logConfig(logger *logrus.Entry) {
+	logger.Infof("%s:", strings.Join(c.Domains, ", "))
+
+	if c.TTL.IsAboveZero() {
+		logger.Infof("  ttl     = %s", c.TTL)
+
+		return
+	}
+
+	if c.MinTime.IsAboveZero() {
+		logger.Infof("  minTime = %s", c.MinTime)
+	}
+
+	if c.MaxTime.IsAboveZero() {
+		logger.Infof("  maxTime = %s", c.MaxTime)
+	}
+}
+
+func (c *TTLRewrite) validate(logger *logrus.Entry) {
+	rules := make([]TTLRewriteRule, 0, len(c.Rules))
+
+	for i, rule := range c.Rules {
+		domains := make([]string, 0, len(rule.Domains))
+
+		for _, domain := range normalizeDomains(rule.Domains) {
+			if name := strings.TrimPrefix(domain, "*."); name == "" || strings.Contains(name, "*") {
+				logger.Warnf("caching.ttlRewrite.rules[%d]: ignoring invalid domain '%s'", i, domain)
+
+				continue
+			}
+
+			domains = append(domains, domain)
+		}
+
+		rule.Domains = domains
+
+		if len(rule.Domains) == 0 {
+			logger.Warnf("caching.ttlRewrite.rules[%d] has no domains, ignoring it", i)
+
+			continue
+		}
+
+		if !rule.TTL.IsAboveZero() && !rule.MinTime.IsAboveZero() && !rule.MaxTime.IsAboveZero() {
+			logger.Warnf("caching.ttlRewrite.rules[%d] doesn't change the TTL, ignoring it", i)
+
+			continue
+		}
+
+		if rule.MaxTime.IsAboveZero() && rule.MinTime > rule.MaxTime {
+			logger.Warnf("caching.ttlRewrite.rules[%d].minTime %s is greater than maxTime %s, setting to %s",
+				i, rule.MinTime, rule.MaxTime, rule.MaxTime)
+
+			rule.MinTime = rule.MaxTime
+		}
+
+		rules = append(rules, rule)
+	}
+
+	c.Rules = rules
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TTLRewriteConfig", func() {
+	var cfg TTLRewrite
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		cfg = TTLRewrite{
+			Rules: []TTLRewriteRule{
+				{Domains: []string{"*.netflix.com"}, MaxTime: Duration(time.Minute)},
+				{Domains: []string{"lan.internal"}, MinTime: Duration(time.Hour)},
+				{Domains: []string{"cdn.example.com"}, TTL: Duration(10 * time.Second)},
+			},
+		}
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			cfg := TTLRewrite{}
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("rules are defined", func() {
+			It("should be true", func() {
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"*.netflix.com:",
+				"  maxTime = 1 minute",
+				"lan.internal:",
+				"  minTime = 1 hour",
+				"cdn.example.com:",
+				"  ttl     = 10 seconds",
+			}))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should normalize the domains", func() {
+			cfg.Rules[0].Domains = []string{"*.NetFlix.com."}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Rules).Should(HaveLen(3))
+			Expect(cfg.Rules[0].Domains).Should(Equal([]string{"*.netflix.com"}))
+			Expect(hook.Messages).Should(BeEmpty())
+		})
+
+		It("should ignore invalid rules", func() {
+			cfg.Rules = append(cfg.Rules,
+				TTLRewriteRule{MaxTime: Duration(time.Minute)},
+				TTLRewriteRule{Domains: []string{"example.org"}},
+				TTLRewriteRule{Domains: []string{"*.", "a.*.example.org"}, TTL: Duration(time.Minute)},
+			)
+
+			cfg.validate(logger)
+
+			Expect(cfg.Rules).Should(HaveLen(3))
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("rules[3] has no domains"),
+				ContainSubstring("rules[4] doesn't change the TTL"),
+				ContainSubstring("rules[5]: ignoring invalid domain '*'"),
+				ContainSubstring("rules[5]: ignoring invalid domain 'a.*.example.org'"),
+				ContainSubstring("rules[5] has no domains"),
+			))
+		})
+
+		It("should limit the min time to the max time", func() {
+			cfg.Rules[0].MinTime = Duration(time.Hour)
+
+			cfg.validate(logger)
+
+			Expect(cfg.Rules[0].MinTime).Should(Equal(Duration(time.Minute)))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("minTime 1 hour is greater than maxTime")))
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/sirupsen/logrus"
+)
+
+// Tunneling configures the detection of DNS tunneling and data exfiltration: many queries of a client for unique,
+// long or random looking subdomains of the same zone
+type Tunneling struct {
+	Enable bool            `yaml:"enable" default:"false"`
+	Action TunnelingAction `yaml:"action" default:"log"`
+	// Window in which the queries of a client to a zone are analyzed
+	Window Duration `yaml:"window" default:"1m"`
+	// MinUniqueSubdomains of a zone a client queries within the window, before it is suspected
+	MinUniqueSubdomains uint `yaml:"minUniqueSubdomains" default:"50"`
+	// MinEntropy is the average Shannon entropy of the subdomains in bits per character, above it they look random
+	MinEntropy float64 `yaml:"minEntropy" default:"3.5"`
+	// MinLabelLength is the average length of the longest label of the subdomains, above it they look like encoded data
+	MinLabelLength uint `yaml:"minLabelLength" default:"40"`
+	// ActionDuration is how long the action applies to the client and the zone after the detection
+	ActionDuration Duration `yaml:"actionDuration" default:"10m"`
+	// RateLimit of the queries of a client to a suspected zone, for the `ratelimit` action
+	RateLimit RateLimit `yaml:"rateLimit"`
+	// Allowlist contains zones (and their subdomains) which are never suspected, e.g. of anti-virus lookups
+	Allowlist []string `yaml:"allowlist"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Tunneling) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Tunneling) LogConfig(logger *logrus.Entry) {
+	logger.Infof("action = %s", c.Action)
+	logger.Infof("window = %s", c.Window)
+	logger.Infof("minUniqueSubdomains = %d", c.MinUniqueSubdomains)
+	logger.Infof("minEntropy = %.2f", c.MinEntropy)
+	logger.Infof("minLabelLength = %d", c.MinLabelLength)
+	logger.Infof("actionDuration = %s", c.ActionDuration)
+
+	if c.Action == TunnelingActionRatelimit {
+		logger.Info("rateLimit:")
+		log.WithIndent(logger, "  ", c.RateLimit.LogConfig)
+	}
+
+	if len(c.Allowlist) != 0 {
+		logger.Infof("allowlist = %s", strings.Join(c.Allowlist, ", "))
+	}
+}
+
+func (c *Tunneling) validate(logger *logrus.Entry) {
+	c.Allowlist = normalizeDomains(c.Allowlist)
+
+	if !c.IsEnabled() {
+		return
+	}
+
+	def := mustDefault[Tunneling]()
+
+	if c.Window <= 0 {
+		logger.Warnf("tunneling.window <= 0, setting to %s", def.Window)
+
+		c.Window = def.Window
+	}
+
+	if c.MinUniqueSubdomains == 0 {
+		logger.Warnf("tunneling.minUniqueSubdomains is 0, setting to %d", def.MinUniqueSubdomains)
+
+		c.MinUniqueSubdomains = def.MinUniqueSubdomains
+	}
+
+	if c.Action == TunnelingActionRatelimit && !c.RateLimit.IsEnabled() {
+		logger.Warn("tunneling.rateLimit.rate is 0 with action ratelimit, setting to 1")
+
+		c.RateLimit.Rate = 1
+	}
+}
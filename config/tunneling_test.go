@@ -0,0 +1,76 @@
+package config
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TunnelingConfig", func() {
+	var cfg Tunneling
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[Tunneling]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("enabled", func() {
+			It("should be true", func() {
+				cfg.Enable = true
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.Action = TunnelingActionRatelimit
+			cfg.RateLimit.Rate = 2
+			cfg.Allowlist = []string{"sophosxl.net"}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"action = ratelimit",
+				"window = 1 minute",
+				"minUniqueSubdomains = 50",
+				"minEntropy = 3.50",
+				"minLabelLength = 40",
+				"rateLimit:",
+				"rate  = 2/s",
+				"allowlist = sophosxl.net",
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should fix invalid values", func() {
+			cfg.Enable = true
+			cfg.Action = TunnelingActionRatelimit
+			cfg.Window = 0
+			cfg.MinUniqueSubdomains = 0
+			cfg.Allowlist = []string{"SophosXL.net."}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Window).Should(Equal(mustDefault[Tunneling]().Window))
+			Expect(cfg.MinUniqueSubdomains).Should(BeEquivalentTo(50))
+			Expect(cfg.RateLimit.Rate).Should(BeEquivalentTo(1))
+			Expect(cfg.Allowlist).Should(Equal([]string{"sophosxl.net"}))
+			Expect(hook.Messages).Should(ConsistOf(
+				ContainSubstring("tunneling.window <= 0"),
+				ContainSubstring("tunneling.minUniqueSubdomains is 0"),
+				ContainSubstring("tunneling.rateLimit.rate is 0"),
+			))
+		})
+	})
+})
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -17,8 +18,12 @@ type Upstream struct {
 	Port       uint16
 	Path       string
 	CommonName string // Common Name to use for certificate verification; optional. "" uses .Host
+	HTTP3      bool   // DoH only: use HTTP/3 with fallback to HTTP/2, configured with the "h3:" scheme
 }
 
+// http3Scheme selects DoH over HTTP/3 for an upstream
+const http3Scheme = "h3"
+
 // IsDefault returns true if u is the default value
 func (u *Upstream) IsDefault() bool {
 	return *u == Upstream{}
@@ -30,9 +35,18 @@ func (u Upstream) String() string {
 		return "no upstream"
 	}
 
+	if u.Net == NetProtocolUnix {
+		return u.Net.String() + ":" + u.Path
+	}
+
 	var sb strings.Builder
 
-	sb.WriteString(u.Net.String())
+	if u.HTTP3 {
+		sb.WriteString(http3Scheme)
+	} else {
+		sb.WriteString(u.Net.String())
+	}
+
 	sb.WriteRune(':')
 
 	if u.Net == NetProtocolHttps {
@@ -82,8 +96,14 @@ func ParseUpstream(upstream string) (Upstream, error) {
 
 	commonName, upstream := extractCommonName(upstream)
 
+	isHTTP3, upstream := extractHTTP3(upstream)
+
 	n, upstream := extractNet(upstream)
 
+	if n == NetProtocolUnix {
+		return parseUnixUpstream(upstream)
+	}
+
 	path, upstream = extractPath(upstream)
 
 	host, portString, err := net.SplitHostPort(upstream)
@@ -122,9 +142,31 @@ func ParseUpstream(upstream string) (Upstream, error) {
 		Port:       port,
 		Path:       path,
 		CommonName: commonName,
+		HTTP3:      isHTTP3,
 	}, nil
 }
 
+// parseUnixUpstream creates an Upstream for the socket path: it has no host or port
+func parseUnixUpstream(socketPath string) (Upstream, error) {
+	if !filepath.IsAbs(socketPath) {
+		return Upstream{}, fmt.Errorf("unix socket path '%s' must be absolute", socketPath)
+	}
+
+	return Upstream{
+		Net:  NetProtocolUnix,
+		Path: filepath.Clean(socketPath),
+	}, nil
+}
+
+// extractHTTP3 replaces the "h3:" scheme with "https:"
+func extractHTTP3(upstream string) (bool, string) {
+	if rest, found := strings.CutPrefix(upstream, http3Scheme+":"); found {
+		return true, NetProtocolHttps.String() + ":" + rest
+	}
+
+	return false, upstream
+}
+
 func extractCommonName(in string) (string, string) {
 	upstream, cn, _ := strings.Cut(in, "#")
 
@@ -160,5 +202,11 @@ func extractNet(upstream string) (NetProtocol, string) {
 		return NetProtocolHttps, strings.TrimPrefix(upstream[len(httpsPrefix):], "//")
 	}
 
+	unixPrefix := NetProtocolUnix.String() + ":"
+	if strings.HasPrefix(upstream, unixPrefix) {
+		// unix:///path/to/socket is the URL form of unix:/path/to/socket
+		return NetProtocolUnix, strings.TrimPrefix(upstream[len(unixPrefix):], "//")
+	}
+
 	return NetProtocolTcpUdp, upstream
 }
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// upstreamPresets are well known public resolvers which can be used with `preset: name` in an upstream group.
+//
+// They use DoT with the provider's anycast IPs, so they don't need to be bootstrapped,
+// and the certificate is checked against the provider's host name.
+// For some providers the host name (SNI) selects the filtering profile.
+//
+//nolint:gochecknoglobals
+var upstreamPresets = map[string][]Upstream{
+	"adguard": {
+		dotPreset("94.140.14.14", "dns.adguard-dns.com"),
+		dotPreset("94.140.15.15", "dns.adguard-dns.com"),
+	},
+	"adguard-family": {
+		dotPreset("94.140.14.15", "family.adguard-dns.com"),
+		dotPreset("94.140.15.16", "family.adguard-dns.com"),
+	},
+	"cloudflare": {
+		dotPreset("1.1.1.1", "cloudflare-dns.com"),
+		dotPreset("1.0.0.1", "cloudflare-dns.com"),
+	},
+	"cloudflare-security": {
+		dotPreset("1.1.1.2", "security.cloudflare-dns.com"),
+		dotPreset("1.0.0.2", "security.cloudflare-dns.com"),
+	},
+	"cloudflare-family": {
+		dotPreset("1.1.1.3", "family.cloudflare-dns.com"),
+		dotPreset("1.0.0.3", "family.cloudflare-dns.com"),
+	},
+	"dns0": {
+		dotPreset("193.110.81.0", "dns0.eu"),
+		dotPreset("185.253.5.0", "dns0.eu"),
+	},
+	"dns0-kids": {
+		dotPreset("193.110.81.1", "kids.dns0.eu"),
+		dotPreset("185.253.5.1", "kids.dns0.eu"),
+	},
+	"google": {
+		dotPreset("8.8.8.8", "dns.google"),
+		dotPreset("8.8.4.4", "dns.google"),
+	},
+	"quad9": {
+		dotPreset("9.9.9.9", "dns.quad9.net"),
+		dotPreset("149.112.112.112", "dns.quad9.net"),
+	},
+	"quad9-unsecured": {
+		dotPreset("9.9.9.10", "dns10.quad9.net"),
+		dotPreset("149.112.112.10", "dns10.quad9.net"),
+	},
+}
+
+func dotPreset(ip, commonName string) Upstream {
+	return Upstream{
+		Net:        NetProtocolTcpTls,
+		Host:       ip,
+		Port:       netDefaultPort[NetProtocolTcpTls],
+		CommonName: commonName,
+	}
+}
+
+// UpstreamPresetNames returns the names of all upstream presets
+func UpstreamPresetNames() []string {
+	names := make([]string, 0, len(upstreamPresets))
+
+	for name := range upstreamPresets {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// UpstreamPreset returns the upstreams of the preset with the given name
+func UpstreamPreset(name string) ([]Upstream, error) {
+	upstreams, ok := upstreamPresets[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf(
+			"unknown upstream preset '%s', must be one of: %s", name, strings.Join(UpstreamPresetNames(), ", "),
+		)
+	}
+
+	return slices.Clone(upstreams), nil
+}
+
+// split in two types to avoid infinite recursion. See `upstreamOrPreset.UnmarshalYAML`.
+type (
+	upstreamOrPreset struct {
+		upstreams []Upstream
+	}
+	upstreamPresetRef struct {
+		Preset string `yaml:"preset"`
+	}
+)
+
+// UnmarshalYAML creates upstreamOrPreset from YAML: either a single upstream or a `preset: name` reference
+func (u *upstreamOrPreset) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single Upstream
+
+	upstreamErr := unmarshal(&single)
+	if upstreamErr == nil {
+		u.upstreams = []Upstream{single}
+
+		return nil
+	}
+
+	var ref upstreamPresetRef
+	if err := unmarshal(&ref); err != nil {
+		// not a preset either: report why it isn't a valid upstream
+		return upstreamErr
+	}
+
+	upstreams, err := UpstreamPreset(ref.Preset)
+	if err != nil {
+		return err
+	}
+
+	u.upstreams = upstreams
+
+	return nil
+}
+
+// UnmarshalYAML creates UpstreamGroups from YAML, expanding upstream presets
+func (g *UpstreamGroups) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var groups map[string][]upstreamOrPreset
+	if err := unmarshal(&groups); err != nil {
+		return err
+	}
+
+	res := make(UpstreamGroups, len(groups))
+
+	for name, entries := range groups {
+		upstreams := make([]Upstream, 0, len(entries))
+
+		for _, entry := range entries {
+			upstreams = append(upstreams, entry.upstreams...)
+		}
+
+		res[name] = upstreams
+	}
+
+	*g = res
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("UpstreamPresets", func() {
+	suiteBeforeEach()
+
+	Describe("preset definitions", func() {
+		It("should define the documented presets", func() {
+			Expect(UpstreamPresetNames()).Should(ContainElements(
+				"cloudflare", "cloudflare-security", "cloudflare-family", "quad9", "dns0-kids", "google",
+			))
+		})
+
+		It("should only contain pinned DoT upstreams which don't need bootstrapping", func() {
+			for _, name := range UpstreamPresetNames() {
+				upstreams, err := UpstreamPreset(name)
+				Expect(err).Should(Succeed())
+				Expect(upstreams).ShouldNot(BeEmpty(), name)
+
+				for _, upstream := range upstreams {
+					Expect(upstream.Net).Should(Equal(NetProtocolTcpTls), name)
+					Expect(upstream.Port).Should(BeEquivalentTo(853), name)
+					Expect(net.ParseIP(upstream.Host)).ShouldNot(BeNil(), name)
+					Expect(upstream.CommonName).Should(MatchRegexp(validDomain.String()), name)
+
+					// same as the user would write it
+					parsed, err := ParseUpstream("tcp-tls:" + upstream.Host + "#" + upstream.CommonName)
+					Expect(err).Should(Succeed())
+					Expect(parsed).Should(Equal(upstream), name)
+				}
+			}
+		})
+
+		It("should return a copy", func() {
+			upstreams, err := UpstreamPreset("quad9")
+			Expect(err).Should(Succeed())
+
+			upstreams[0].Host = "changed"
+
+			Expect(UpstreamPreset("quad9")).ShouldNot(ContainElement(HaveField("Host", "changed")))
+		})
+	})
+
+	Describe("UpstreamPreset", func() {
+		It("should ignore case and spaces", func() {
+			Expect(UpstreamPreset(" Quad9 ")).Should(ContainElement(HaveField("Host", "9.9.9.9")))
+		})
+
+		It("should fail for unknown presets", func() {
+			_, err := UpstreamPreset("unknown")
+			Expect(err).Should(MatchError(SatisfyAll(
+				ContainSubstring("unknown upstream preset 'unknown'"),
+				ContainSubstring("quad9"),
+			)))
+		})
+	})
+
+	Describe("UpstreamGroups.UnmarshalYAML", func() {
+		var groups UpstreamGroups
+
+		It("should expand presets and keep the order", func() {
+			data := `
+default:
+  - 1.2.3.4
+  - preset: cloudflare-security
+  - tcp-tls:dns.example.com
+other:
+  - preset: dns0-kids
+`
+			Expect(yaml.UnmarshalStrict([]byte(data), &groups)).Should(Succeed())
+
+			Expect(groups).Should(HaveLen(2))
+			Expect(groups[UpstreamDefaultCfgName]).Should(HaveLen(4))
+			Expect(groups[UpstreamDefaultCfgName][0].Host).Should(Equal("1.2.3.4"))
+			Expect(groups[UpstreamDefaultCfgName][1]).Should(Equal(Upstream{
+				Net: NetProtocolTcpTls, Host: "1.1.1.2", Port: 853, CommonName: "security.cloudflare-dns.com",
+			}))
+			Expect(groups[UpstreamDefaultCfgName][3].Host).Should(Equal("dns.example.com"))
+
+			Expect(groups["other"]).Should(HaveEach(HaveField("CommonName", "kids.dns0.eu")))
+		})
+
+		It("should fail for unknown presets", func() {
+			data := `
+default:
+  - preset: unknown
+`
+			Expect(yaml.UnmarshalStrict([]byte(data), &groups)).
+				Should(MatchError(ContainSubstring("unknown upstream preset")))
+		})
+
+		It("should fail for invalid upstreams", func() {
+			data := `
+default:
+  - invalid:upstream:definition
+`
+			Expect(yaml.UnmarshalStrict([]byte(data), &groups)).Should(HaveOccurred())
+		})
+	})
+})
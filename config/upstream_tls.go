@@ -0,0 +1,114 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UpstreamTLS are the TLS settings of one DoT/DoH upstream, they override the `tls.upstreams` policy
+type UpstreamTLS struct {
+	// CA to verify the certificate of the upstream, instead of the system pool
+	CAFile string `yaml:"caFile"`
+	// Hashes of the public keys, one of them must be in the certificate chain of the upstream
+	SPKIPins []SPKIPin `yaml:"spkiPins"`
+	// Don't verify the certificate, only the SPKI pins if any
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+	// Name sent via SNI and expected in the certificate, instead of the host of the upstream
+	ServerName string `yaml:"serverName"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *UpstreamTLS) IsEnabled() bool {
+	return c.CAFile != "" || len(c.SPKIPins) != 0 || c.InsecureSkipVerify || c.ServerName != ""
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *UpstreamTLS) LogConfig(logger *logrus.Entry) {
+	if c.CAFile != "" {
+		logger.Infof("caFile = %s", c.CAFile)
+	}
+
+	if len(c.SPKIPins) != 0 {
+		logger.Infof("spkiPins = %d", len(c.SPKIPins))
+	}
+
+	if c.InsecureSkipVerify {
+		logger.Info("insecureSkipVerify = true")
+	}
+
+	if c.ServerName != "" {
+		logger.Infof("serverName = %s", c.ServerName)
+	}
+}
+
+// ApplyClient applies the settings to the TLS config of a connection to the upstream
+func (c *UpstreamTLS) ApplyClient(tlsCfg *tls.Config) error {
+	if c.CAFile != "" {
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return err
+		}
+
+		tlsCfg.RootCAs = pool
+	}
+
+	if c.ServerName != "" {
+		tlsCfg.ServerName = c.ServerName
+	}
+
+	tlsCfg.InsecureSkipVerify = c.InsecureSkipVerify //nolint:gosec // explicitly configured
+
+	if len(c.SPKIPins) != 0 {
+		pins := c.SPKIPins
+
+		tlsCfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			for _, cert := range cs.PeerCertificates {
+				hash := SPKIPin(sha256.Sum256(cert.RawSubjectPublicKeyInfo))
+
+				for _, pin := range pins {
+					if subtle.ConstantTimeCompare(hash[:], pin[:]) == 1 {
+						return nil
+					}
+				}
+			}
+
+			return errors.New("no certificate of the upstream matches the SPKI pins")
+		}
+	}
+
+	return nil
+}
+
+// SPKIPin is the SHA-256 hash of the SubjectPublicKeyInfo of a certificate,
+// configured as base64, optionally with the prefix "sha256/"
+type SPKIPin [sha256.Size]byte
+
+// String implements `fmt.Stringer`.
+func (p SPKIPin) String() string {
+	return "sha256/" + base64.StdEncoding.EncodeToString(p[:])
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+func (p *SPKIPin) UnmarshalText(data []byte) error {
+	text := strings.TrimPrefix(strings.TrimSpace(string(data)), "sha256/")
+
+	hash, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return fmt.Errorf("invalid SPKI pin '%s': %w", data, err)
+	}
+
+	if len(hash) != sha256.Size {
+		return fmt.Errorf("invalid SPKI pin '%s': must be a base64 SHA-256 hash", data)
+	}
+
+	copy(p[:], hash)
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/pem"
+
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("UpstreamTLS", func() {
+	var (
+		cert tls.Certificate
+		pin  SPKIPin
+	)
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cert, err = util.TLSGenerateSelfSignedCert([]string{"dns.example.com"})
+		Expect(err).Should(Succeed())
+
+		pin = sha256.Sum256(cert.Leaf.RawSubjectPublicKeyInfo)
+	})
+
+	// handshake connects to a server with cert using the settings and returns the error of the client
+	handshake := func(settings UpstreamTLS) error {
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+		Expect(err).Should(Succeed())
+		DeferCleanup(listener.Close)
+
+		go func() {
+			conn, err := listener.Accept()
+			if err == nil {
+				_ = conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}
+		}()
+
+		clientCfg := &tls.Config{ServerName: "192.0.2.1", MinVersion: tls.VersionTLS12}
+		Expect(settings.ApplyClient(clientCfg)).Should(Succeed())
+
+		conn, err := tls.Dial("tcp", listener.Addr().String(), clientCfg)
+		if err == nil {
+			conn.Close()
+		}
+
+		return err
+	}
+
+	Describe("SPKIPin", func() {
+		It("should parse base64 hashes with and without prefix", func() {
+			encoded := base64.StdEncoding.EncodeToString(pin[:])
+
+			var parsed []SPKIPin
+			Expect(yaml.UnmarshalStrict([]byte("[sha256/"+encoded+", "+encoded+"]"), &parsed)).Should(Succeed())
+
+			Expect(parsed).Should(Equal([]SPKIPin{pin, pin}))
+			Expect(parsed[0].String()).Should(Equal("sha256/" + encoded))
+		})
+
+		It("should reject invalid hashes", func() {
+			var parsed SPKIPin
+
+			Expect(parsed.UnmarshalText([]byte("not base64"))).Should(MatchError(ContainSubstring("invalid SPKI pin")))
+			Expect(parsed.UnmarshalText([]byte("dG9vIHNob3J0"))).Should(MatchError(ContainSubstring("SHA-256")))
+		})
+	})
+
+	Describe("ApplyClient", func() {
+		It("should verify the certificate with the CA file and server name", func() {
+			caFile := NewTmpFolder("config").CreateStringFile("ca.pem",
+				string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})))
+
+			Expect(handshake(UpstreamTLS{CAFile: caFile.Path})).ShouldNot(Succeed())
+			Expect(handshake(UpstreamTLS{CAFile: caFile.Path, ServerName: "dns.example.com"})).Should(Succeed())
+		})
+
+		It("should accept a certificate matching a pin", func() {
+			other := SPKIPin{1}
+
+			Expect(handshake(UpstreamTLS{InsecureSkipVerify: true, SPKIPins: []SPKIPin{other, pin}})).Should(Succeed())
+		})
+
+		It("should reject a certificate not matching any pin", func() {
+			Expect(handshake(UpstreamTLS{InsecureSkipVerify: true, SPKIPins: []SPKIPin{{1}}})).
+				Should(MatchError(ContainSubstring("SPKI pins")))
+		})
+
+		It("should verify the certificate in addition to the pins", func() {
+			Expect(handshake(UpstreamTLS{SPKIPins: []SPKIPin{pin}})).ShouldNot(Succeed())
+		})
+
+		It("should fail on an invalid CA file", func() {
+			invalid := NewTmpFolder("config").CreateStringFile("invalid.pem", "invalid")
+
+			Expect((&UpstreamTLS{CAFile: invalid.Path}).ApplyClient(&tls.Config{})).ShouldNot(Succeed())
+		})
+	})
+
+	Describe("Upstreams", func() {
+		var cfg Upstreams
+
+		BeforeEach(func() {
+			cfg = Upstreams{Timeout: Duration(1)}
+		})
+
+		It("should be parsed with upstreams as keys", func() {
+			data := `
+tls:
+  tcp-tls:dns.example.com:
+    caFile: /etc/ca.pem
+    serverName: resolver.example.com
+`
+			Expect(yaml.UnmarshalStrict([]byte(data), &cfg)).Should(Succeed())
+
+			upstream, err := ParseUpstream("tcp-tls:dns.example.com")
+			Expect(err).Should(Succeed())
+
+			Expect(cfg.TLSOf(upstream)).Should(Equal(UpstreamTLS{
+				CAFile:     "/etc/ca.pem",
+				ServerName: "resolver.example.com",
+			}))
+			Expect(cfg.TLSOf(Upstream{Host: "other"})).Should(BeZero())
+		})
+
+		It("should ignore settings of upstreams without TLS", func() {
+			upstream := Upstream{Net: NetProtocolTcpUdp, Host: "192.0.2.1", Port: 53}
+			cfg.UpstreamTLS = map[Upstream]UpstreamTLS{upstream: {CAFile: "/etc/ca.pem"}}
+
+			cfg.validate(logger)
+
+			Expect(cfg.UpstreamTLS).Should(BeEmpty())
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("doesn't use TLS")))
+		})
+
+		It("should warn if the upstream isn't authenticated", func() {
+			upstream := Upstream{Net: NetProtocolTcpTls, Host: "192.0.2.1", Port: 853}
+			cfg.UpstreamTLS = map[Upstream]UpstreamTLS{upstream: {InsecureSkipVerify: true}}
+
+			cfg.validate(logger)
+
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("isn't authenticated")))
+		})
+
+		It("should be logged", func() {
+			upstream := Upstream{Net: NetProtocolTcpTls, Host: "192.0.2.1", Port: 853}
+			cfg.UpstreamTLS = map[Upstream]UpstreamTLS{upstream: {SPKIPins: []SPKIPin{pin}, ServerName: "dns"}}
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				"tls:",
+				ContainSubstring("tcp-tls:192.0.2.1"),
+				ContainSubstring("spkiPins = 1"),
+				ContainSubstring("serverName = dns"),
+			))
+		})
+	})
+})
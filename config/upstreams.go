@@ -1,11 +1,20 @@
 package config
 
 import (
+	"net"
+	"runtime"
+	"slices"
+	"strings"
+
 	"github.com/0xERR0R/blocky/log"
 	"github.com/sirupsen/logrus"
 )
 
-const UpstreamDefaultCfgName = "default"
+const (
+	UpstreamDefaultCfgName = "default"
+
+	defaultUpstreamWeight = 1
+)
 
 // Upstreams upstream servers configuration
 type Upstreams struct {
@@ -14,10 +23,224 @@ type Upstreams struct {
 	Groups    UpstreamGroups   `yaml:"groups"`
 	Strategy  UpstreamStrategy `yaml:"strategy" default:"parallel_best"`
 	UserAgent string           `yaml:"userAgent"`
+
+	// RandomizeCase sends the query names with random case to unencrypted upstreams (DNS 0x20)
+	RandomizeCase bool `yaml:"randomizeCase" default:"false"`
+
+	// Cookies sends DNS Cookies (RFC 7873) to unencrypted upstreams
+	Cookies bool `yaml:"cookies" default:"false"`
+
+	Retry          UpstreamRetry          `yaml:"retry"`
+	CircuitBreaker UpstreamCircuitBreaker `yaml:"circuitBreaker"`
+	HealthCheck    UpstreamHealthCheck    `yaml:"healthCheck"`
+	Hedging        UpstreamHedging        `yaml:"hedging"`
+	UDPPool        UpstreamUDPPool        `yaml:"udpPool"`
+
+	// GroupSettings override the timeout, retries and circuit breaker per group
+	GroupSettings map[string]UpstreamGroupSettings `yaml:"groupSettings"`
+
+	// Weights of upstreams for the weighted strategy, upstreams without a weight have weight 1
+	Weights map[Upstream]uint `yaml:"weights"`
+
+	// Bind of the connections to the upstreams per group
+	Bind map[string]UpstreamBind `yaml:"bind"`
+
+	// UpstreamTLS are the TLS settings of DoT/DoH upstreams
+	UpstreamTLS map[Upstream]UpstreamTLS `yaml:"tls"`
+
+	// PinnedIPs are the static IPs of upstream hostnames, used instead of resolving them via bootstrap DNS
+	PinnedIPs map[string][]net.IP `yaml:"pinnedIPs"`
+
+	// TLS is the policy for DoT/DoH upstreams, set from the global `tls` config
+	TLS TLSPolicy `yaml:"-"`
+
+	// ECSUpstreams are the only upstreams receiving the ECS option if not empty, set from `ecs.upstreams`
+	ECSUpstreams []Upstream `yaml:"-"`
+
+	// ProxyProtocolUpstreams receive a PROXY protocol header with the client IP, set from `proxyProtocol.upstreams`
+	ProxyProtocolUpstreams []Upstream `yaml:"-"`
 }
 
 type UpstreamGroups map[string][]Upstream
 
+// UpstreamRetry configures the retries of queries to an upstream which timed out
+type UpstreamRetry struct {
+	// Number of attempts, including the first one
+	Attempts uint `yaml:"attempts" default:"3"`
+	// Delay before the first retry, doubled for each further retry
+	Backoff Duration `yaml:"backoff" default:"1ms"`
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *UpstreamRetry) LogConfig(logger *logrus.Entry) {
+	logger.Info("attempts: ", c.Attempts)
+	logger.Info("backoff: ", c.Backoff)
+}
+
+// UpstreamCircuitBreaker takes upstreams out of rotation after consecutive failed queries
+type UpstreamCircuitBreaker struct {
+	// Number of consecutive failed queries after which an upstream is down, 0 disables the circuit breaker
+	FailureThreshold uint `yaml:"failureThreshold" default:"0"`
+	// How long an upstream is down before it gets queries again
+	Duration Duration `yaml:"duration" default:"30s"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *UpstreamCircuitBreaker) IsEnabled() bool {
+	return c.FailureThreshold > 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *UpstreamCircuitBreaker) LogConfig(logger *logrus.Entry) {
+	logger.Info("failureThreshold: ", c.FailureThreshold)
+	logger.Info("duration: ", c.Duration)
+}
+
+// UpstreamGroupSettings override the settings of `upstreams` for one group, settings which are not set are inherited
+type UpstreamGroupSettings struct {
+	Timeout        Duration               `yaml:"timeout"`
+	Retry          UpstreamRetry          `yaml:"retry"`
+	CircuitBreaker UpstreamCircuitBreaker `yaml:"circuitBreaker"`
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *UpstreamGroupSettings) LogConfig(logger *logrus.Entry) {
+	if c.Timeout != 0 {
+		logger.Info("timeout: ", c.Timeout)
+	}
+
+	if c.Retry.Attempts != 0 {
+		logger.Info("retry.attempts: ", c.Retry.Attempts)
+	}
+
+	if c.Retry.Backoff != 0 {
+		logger.Info("retry.backoff: ", c.Retry.Backoff)
+	}
+
+	if c.CircuitBreaker.FailureThreshold != 0 {
+		logger.Info("circuitBreaker.failureThreshold: ", c.CircuitBreaker.FailureThreshold)
+	}
+
+	if c.CircuitBreaker.Duration != 0 {
+		logger.Info("circuitBreaker.duration: ", c.CircuitBreaker.Duration)
+	}
+}
+
+// UpstreamHealthCheck configures active health checks of the upstreams
+type UpstreamHealthCheck struct {
+	// Interval between two checks of an upstream, 0 disables the health checks
+	Interval Duration `yaml:"interval" default:"0"`
+	// Name to query (type A) to check an upstream
+	Name string `yaml:"name" default:"."`
+	// Number of consecutive failed checks after which an upstream is unhealthy
+	FailureThreshold uint `yaml:"failureThreshold" default:"3"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *UpstreamHealthCheck) IsEnabled() bool {
+	return c.Interval.IsAboveZero()
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *UpstreamHealthCheck) LogConfig(logger *logrus.Entry) {
+	logger.Info("interval: ", c.Interval)
+	logger.Info("name: ", c.Name)
+	logger.Info("failureThreshold: ", c.FailureThreshold)
+}
+
+// UpstreamBind binds the connections to the upstreams of a group to a local address and/or network interface
+type UpstreamBind struct {
+	// Local source address
+	Address net.IP `yaml:"address"`
+	// Network interface (Linux only)
+	Interface string `yaml:"interface"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *UpstreamBind) IsEnabled() bool {
+	return c.Address != nil || c.Interface != ""
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *UpstreamBind) LogConfig(logger *logrus.Entry) {
+	if c.Address != nil {
+		logger.Info("address: ", c.Address)
+	}
+
+	if c.Interface != "" {
+		logger.Info("interface: ", c.Interface)
+	}
+}
+
+// UpstreamUDPPool configures the reuse of UDP sockets to unencrypted upstreams
+type UpstreamUDPPool struct {
+	// Maximum number of idle sockets kept per upstream, 0 uses a new socket per query
+	Size uint `yaml:"size" default:"0"`
+	// Number of queries after which a socket is replaced, to change the source port
+	MaxQueries uint `yaml:"maxQueries" default:"100"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *UpstreamUDPPool) IsEnabled() bool {
+	return c.Size > 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *UpstreamUDPPool) LogConfig(logger *logrus.Entry) {
+	logger.Info("size: ", c.Size)
+	logger.Info("maxQueries: ", c.MaxQueries)
+}
+
+// UpstreamHedging configures hedged requests: if the upstream does not answer within the usual time,
+// the query is also sent to the next upstream and the first answer is used
+type UpstreamHedging struct {
+	Enable bool `yaml:"enable" default:"false"`
+	// Percentile of the latest latencies of the upstream after which the next one is queried
+	Percentile uint `yaml:"percentile" default:"95"`
+	// Bounds of the delay, the maximum is also used while there are no latencies yet
+	MinDelay Duration `yaml:"minDelay" default:"10ms"`
+	MaxDelay Duration `yaml:"maxDelay" default:"500ms"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *UpstreamHedging) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *UpstreamHedging) LogConfig(logger *logrus.Entry) {
+	logger.Infof("percentile: p%d", c.Percentile)
+	logger.Info("minDelay: ", c.MinDelay)
+	logger.Info("maxDelay: ", c.MaxDelay)
+}
+
+func (c *UpstreamHedging) validate(logger *logrus.Entry, strategy UpstreamStrategy) {
+	const maxPercentile = 100
+
+	if !c.IsEnabled() {
+		return
+	}
+
+	if strategy != UpstreamStrategyStrict && strategy != UpstreamStrategyFastest {
+		logger.Warnf(
+			"upstreams.hedging is only used by the %s and %s strategies",
+			UpstreamStrategyStrict, UpstreamStrategyFastest,
+		)
+	}
+
+	if c.Percentile == 0 || c.Percentile > maxPercentile {
+		defaults := mustDefault[UpstreamHedging]()
+
+		logger.Warnf("upstreams.hedging.percentile must be in 1 - 100, setting to %d", defaults.Percentile)
+		c.Percentile = defaults.Percentile
+	}
+
+	if c.MaxDelay < c.MinDelay {
+		logger.Warnf("upstreams.hedging.maxDelay < minDelay, setting to %s", c.MinDelay)
+		c.MaxDelay = c.MinDelay
+	}
+}
+
 func (c *Upstreams) validate(logger *logrus.Entry) {
 	defaults := mustDefault[Upstreams]()
 
@@ -25,6 +248,173 @@ func (c *Upstreams) validate(logger *logrus.Entry) {
 		logger.Warnf("upstreams.timeout <= 0, setting to %s", defaults.Timeout)
 		c.Timeout = defaults.Timeout
 	}
+
+	if c.HealthCheck.FailureThreshold == 0 {
+		logger.Warnf("upstreams.healthCheck.failureThreshold = 0, setting to %d", defaults.HealthCheck.FailureThreshold)
+		c.HealthCheck.FailureThreshold = defaults.HealthCheck.FailureThreshold
+	}
+
+	if c.Retry.Attempts == 0 {
+		logger.Warnf("upstreams.retry.attempts = 0, setting to %d", defaults.Retry.Attempts)
+		c.Retry.Attempts = defaults.Retry.Attempts
+	}
+
+	if c.CircuitBreaker.IsEnabled() && !c.CircuitBreaker.Duration.IsAboveZero() {
+		logger.Warnf("upstreams.circuitBreaker.duration <= 0, setting to %s", defaults.CircuitBreaker.Duration)
+		c.CircuitBreaker.Duration = defaults.CircuitBreaker.Duration
+	}
+
+	if c.UDPPool.IsEnabled() && c.UDPPool.MaxQueries == 0 {
+		logger.Warnf("upstreams.udpPool.maxQueries = 0, setting to %d", defaults.UDPPool.MaxQueries)
+		c.UDPPool.MaxQueries = defaults.UDPPool.MaxQueries
+	}
+
+	c.Hedging.validate(logger, c.Strategy)
+	c.validateWeights(logger)
+	c.validateBind(logger)
+	c.validatePinnedIPs(logger)
+	c.validateTLS(logger)
+	c.validateGroupSettings(logger)
+}
+
+func (c *Upstreams) validateGroupSettings(logger *logrus.Entry) {
+	for group, settings := range c.GroupSettings {
+		if _, ok := c.Groups[group]; !ok {
+			logger.Warnf("upstreams.groupSettings: %s is not an upstream group", group)
+		}
+
+		if settings.Timeout < 0 || settings.Retry.Backoff < 0 || settings.CircuitBreaker.Duration < 0 {
+			logger.Warnf("upstreams.groupSettings: negative durations of %s are ignored", group)
+		}
+	}
+}
+
+// withGroupSettings returns a copy of c with the settings of the group applied
+func (c Upstreams) withGroupSettings(group string) Upstreams {
+	settings, ok := c.GroupSettings[group]
+	if !ok {
+		return c
+	}
+
+	if settings.Timeout.IsAboveZero() {
+		c.Timeout = settings.Timeout
+	}
+
+	if settings.Retry.Attempts != 0 {
+		c.Retry.Attempts = settings.Retry.Attempts
+	}
+
+	if settings.Retry.Backoff.IsAboveZero() {
+		c.Retry.Backoff = settings.Retry.Backoff
+	}
+
+	if settings.CircuitBreaker.FailureThreshold != 0 {
+		c.CircuitBreaker.FailureThreshold = settings.CircuitBreaker.FailureThreshold
+	}
+
+	if settings.CircuitBreaker.Duration.IsAboveZero() {
+		c.CircuitBreaker.Duration = settings.CircuitBreaker.Duration
+	}
+
+	return c
+}
+
+func (c *Upstreams) validateTLS(logger *logrus.Entry) {
+	for upstream, settings := range c.UpstreamTLS {
+		if upstream.Net != NetProtocolTcpTls && upstream.Net != NetProtocolHttps {
+			logger.Warnf("upstreams.tls: %s doesn't use TLS, ignoring its settings", upstream)
+			delete(c.UpstreamTLS, upstream)
+
+			continue
+		}
+
+		if settings.InsecureSkipVerify && len(settings.SPKIPins) == 0 {
+			logger.Warnf("upstreams.tls: insecureSkipVerify without spkiPins, %s isn't authenticated", upstream)
+		}
+	}
+}
+
+func (c *Upstreams) validatePinnedIPs(logger *logrus.Entry) {
+	if len(c.PinnedIPs) == 0 {
+		return
+	}
+
+	pinned := make(map[string][]net.IP, len(c.PinnedIPs))
+
+	for host, ips := range c.PinnedIPs {
+		host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+
+		switch {
+		case net.ParseIP(host) != nil:
+			logger.Warnf("upstreams.pinnedIPs: %s is an IP, ignoring it", host)
+
+		case len(ips) == 0:
+			logger.Warnf("upstreams.pinnedIPs: no IPs for %s, ignoring it", host)
+
+		default:
+			pinned[host] = append(pinned[host], ips...)
+		}
+	}
+
+	c.PinnedIPs = pinned
+}
+
+func (c *Upstreams) validateBind(logger *logrus.Entry) {
+	for group, bind := range c.Bind {
+		if _, ok := c.Groups[group]; !ok {
+			logger.Warnf("upstreams.bind: %s is not an upstream group", group)
+		}
+
+		if bind.Interface != "" && runtime.GOOS != "linux" {
+			logger.Warnf("upstreams.bind: binding %s to an interface is only supported on Linux", group)
+		}
+	}
+}
+
+func (c *Upstreams) validateWeights(logger *logrus.Entry) {
+	for upstream, weight := range c.Weights {
+		if weight == 0 {
+			logger.Warnf("upstreams.weights: weight of %s is 0, setting to %d", upstream, defaultUpstreamWeight)
+			c.Weights[upstream] = defaultUpstreamWeight
+		}
+
+		if !c.hasUpstream(upstream) {
+			logger.Warnf("upstreams.weights: %s is not an upstream of any group", upstream)
+		}
+	}
+
+	if len(c.Weights) != 0 && c.Strategy != UpstreamStrategyWeighted {
+		logger.Warnf("upstreams.weights are only used by the %s strategy", UpstreamStrategyWeighted)
+	}
+}
+
+func (c *Upstreams) hasUpstream(upstream Upstream) bool {
+	for _, upstreams := range c.Groups {
+		if slices.Contains(upstreams, upstream) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BindOf returns how to bind the connections to the upstreams of the group
+func (c *Upstreams) BindOf(group string) UpstreamBind {
+	return c.Bind[group]
+}
+
+// TLSOf returns the TLS settings of the upstream
+func (c *Upstreams) TLSOf(upstream Upstream) UpstreamTLS {
+	return c.UpstreamTLS[upstream]
+}
+
+// Weight returns the weight of the upstream for the weighted strategy
+func (c *Upstreams) Weight(upstream Upstream) uint {
+	if weight, ok := c.Weights[upstream]; ok {
+		return weight
+	}
+
+	return defaultUpstreamWeight
 }
 
 // IsEnabled implements `config.Configurable`.
@@ -39,6 +429,81 @@ func (c *Upstreams) LogConfig(logger *logrus.Entry) {
 
 	logger.Info("timeout: ", c.Timeout)
 	logger.Info("strategy: ", c.Strategy)
+
+	logger.Info("retry:")
+	log.WithIndent(logger, "  ", c.Retry.LogConfig)
+
+	if c.CircuitBreaker.IsEnabled() {
+		logger.Info("circuitBreaker:")
+		log.WithIndent(logger, "  ", c.CircuitBreaker.LogConfig)
+	}
+
+	if len(c.GroupSettings) != 0 {
+		logger.Info("groupSettings:")
+
+		for group, settings := range c.GroupSettings {
+			logger.Infof("  %s:", group)
+			log.WithIndent(logger, "    ", settings.LogConfig)
+		}
+	}
+
+	if c.RandomizeCase {
+		logger.Info("randomizeCase: true")
+	}
+
+	if c.Cookies {
+		logger.Info("cookies: true")
+	}
+
+	if c.Strategy == UpstreamStrategyWeighted && len(c.Weights) != 0 {
+		logger.Info("weights:")
+
+		for upstream, weight := range c.Weights {
+			logger.Infof("  %s: %d", upstream, weight)
+		}
+	}
+
+	if len(c.Bind) != 0 {
+		logger.Info("bind:")
+
+		for group, bind := range c.Bind {
+			logger.Infof("  %s:", group)
+			log.WithIndent(logger, "    ", bind.LogConfig)
+		}
+	}
+
+	if len(c.UpstreamTLS) != 0 {
+		logger.Info("tls:")
+
+		for upstream, settings := range c.UpstreamTLS {
+			logger.Infof("  %s:", upstream)
+			log.WithIndent(logger, "    ", settings.LogConfig)
+		}
+	}
+
+	if len(c.PinnedIPs) != 0 {
+		logger.Info("pinnedIPs:")
+
+		for host, ips := range c.PinnedIPs {
+			logger.Infof("  %s = %v", host, ips)
+		}
+	}
+
+	if c.HealthCheck.IsEnabled() {
+		logger.Info("healthCheck:")
+		log.WithIndent(logger, "  ", c.HealthCheck.LogConfig)
+	}
+
+	if c.Hedging.IsEnabled() {
+		logger.Info("hedging:")
+		log.WithIndent(logger, "  ", c.Hedging.LogConfig)
+	}
+
+	if c.UDPPool.IsEnabled() {
+		logger.Info("udpPool:")
+		log.WithIndent(logger, "  ", c.UDPPool.LogConfig)
+	}
+
 	logger.Info("groups:")
 
 	for name, upstreams := range c.Groups {
@@ -59,11 +524,11 @@ type UpstreamGroup struct {
 
 // NewUpstreamGroup creates an UpstreamGroup with the given name and upstreams.
 //
-// The upstreams from `cfg.Groups` are ignored.
+// The upstreams from `cfg.Groups` are ignored, the settings of the group from `cfg.GroupSettings` are applied.
 func NewUpstreamGroup(name string, cfg Upstreams, upstreams []Upstream) UpstreamGroup {
 	group := UpstreamGroup{
 		Name:      name,
-		Upstreams: cfg,
+		Upstreams: cfg.withGroupSettings(name),
 	}
 
 	group.Groups = UpstreamGroups{name: upstreams}
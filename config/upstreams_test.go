@@ -1,11 +1,13 @@
 package config
 
 import (
+	"net"
 	"time"
 
 	"github.com/creasty/defaults"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
 )
 
 var _ = Describe("ParallelBestConfig", func() {
@@ -79,6 +81,333 @@ var _ = Describe("ParallelBestConfig", func() {
 
 				Expect(hook.Messages).ShouldNot(ContainElement(ContainSubstring("timeout")))
 			})
+
+			It("should set the default health check failure threshold", func() {
+				cfg.HealthCheck.FailureThreshold = 0
+
+				cfg.validate(logger)
+
+				Expect(cfg.HealthCheck.FailureThreshold).Should(BeNumerically("==", 3))
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("failureThreshold")))
+			})
+
+			It("should fix invalid hedging values", func() {
+				cfg.Strategy = UpstreamStrategyStrict
+				cfg.Hedging = UpstreamHedging{
+					Enable:     true,
+					Percentile: 101,
+					MinDelay:   Duration(time.Second),
+					MaxDelay:   Duration(time.Millisecond),
+				}
+
+				cfg.validate(logger)
+
+				Expect(cfg.Hedging.Percentile).Should(BeNumerically("==", 95))
+				Expect(cfg.Hedging.MaxDelay).Should(Equal(Duration(time.Second)))
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("percentile"),
+					ContainSubstring("maxDelay"),
+				))
+			})
+
+			It("should warn if hedging is enabled but not used", func() {
+				cfg.Strategy = UpstreamStrategyParallelBest
+				cfg.Hedging = mustDefault[UpstreamHedging]()
+				cfg.Hedging.Enable = true
+
+				cfg.validate(logger)
+
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("hedging is only used by")))
+			})
+
+			It("should set the default maximum queries per UDP socket", func() {
+				cfg.UDPPool = UpstreamUDPPool{Size: 10}
+
+				cfg.validate(logger)
+
+				Expect(cfg.UDPPool.MaxQueries).Should(BeNumerically("==", 100))
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("udpPool.maxQueries")))
+			})
+
+			It("should reset weights of 0", func() {
+				cfg.Strategy = UpstreamStrategyWeighted
+				cfg.Weights = map[Upstream]uint{{Host: "host1"}: 0}
+
+				cfg.validate(logger)
+
+				Expect(cfg.Weight(Upstream{Host: "host1"})).Should(BeNumerically("==", 1))
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("is 0, setting to 1")))
+			})
+
+			It("should warn about weights of unknown upstreams", func() {
+				cfg.Strategy = UpstreamStrategyWeighted
+				cfg.Weights = map[Upstream]uint{{Host: "unknown"}: 2}
+
+				cfg.validate(logger)
+
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("is not an upstream")))
+			})
+
+			It("should warn if weights are set but not used", func() {
+				cfg.Strategy = UpstreamStrategyParallelBest
+				cfg.Weights = map[Upstream]uint{{Host: "host1"}: 2}
+
+				cfg.validate(logger)
+
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("only used by the weighted strategy")))
+			})
+		})
+
+		Describe("Hedging", func() {
+			It("should be disabled by default", func() {
+				cfg, err := WithDefaults[Upstreams]()
+				Expect(err).Should(Succeed())
+
+				Expect(cfg.Hedging.IsEnabled()).Should(BeFalse())
+				Expect(cfg.Hedging.Percentile).Should(BeNumerically("==", 95))
+			})
+
+			It("should be logged if enabled", func() {
+				cfg.Hedging = mustDefault[UpstreamHedging]()
+				cfg.Hedging.Enable = true
+
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("hedging:"),
+					ContainSubstring("percentile: p95"),
+					ContainSubstring("maxDelay: 500 milliseconds"),
+				))
+			})
+		})
+
+		Describe("Retry and circuit breaker", func() {
+			It("should have defaults", func() {
+				cfg, err := WithDefaults[Upstreams]()
+				Expect(err).Should(Succeed())
+
+				Expect(cfg.Retry).Should(Equal(UpstreamRetry{Attempts: 3, Backoff: Duration(time.Millisecond)}))
+				Expect(cfg.CircuitBreaker.IsEnabled()).Should(BeFalse())
+			})
+
+			It("should fix invalid values", func() {
+				cfg.CircuitBreaker = UpstreamCircuitBreaker{FailureThreshold: 3}
+
+				cfg.validate(logger)
+
+				Expect(cfg.Retry.Attempts).Should(BeNumerically("==", 3))
+				Expect(cfg.CircuitBreaker.Duration).Should(Equal(Duration(30 * time.Second)))
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("retry.attempts"),
+					ContainSubstring("circuitBreaker.duration"),
+				))
+			})
+
+			It("should warn about settings of unknown groups", func() {
+				cfg.GroupSettings = map[string]UpstreamGroupSettings{"unknown": {Timeout: Duration(time.Second)}}
+
+				cfg.validate(logger)
+
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("unknown is not an upstream group")))
+			})
+
+			It("should be logged", func() {
+				cfg.Retry = UpstreamRetry{Attempts: 2, Backoff: Duration(time.Second)}
+				cfg.CircuitBreaker = UpstreamCircuitBreaker{FailureThreshold: 5, Duration: Duration(time.Minute)}
+				cfg.GroupSettings = map[string]UpstreamGroupSettings{"slow": {Timeout: Duration(time.Second)}}
+
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("retry:"),
+					ContainSubstring("attempts: 2"),
+					ContainSubstring("circuitBreaker:"),
+					ContainSubstring("failureThreshold: 5"),
+					ContainSubstring("groupSettings:"),
+					ContainSubstring("slow:"),
+				))
+			})
+		})
+
+		Describe("UDPPool", func() {
+			It("should be disabled by default", func() {
+				cfg, err := WithDefaults[Upstreams]()
+				Expect(err).Should(Succeed())
+
+				Expect(cfg.UDPPool.IsEnabled()).Should(BeFalse())
+				Expect(cfg.UDPPool.MaxQueries).Should(BeNumerically("==", 100))
+			})
+
+			It("should be logged if enabled", func() {
+				cfg.UDPPool = UpstreamUDPPool{Size: 10, MaxQueries: 50}
+
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("udpPool:"),
+					ContainSubstring("size: 10"),
+					ContainSubstring("maxQueries: 50"),
+				))
+			})
+		})
+
+		Describe("Weight", func() {
+			BeforeEach(func() {
+				cfg.Strategy = UpstreamStrategyWeighted
+				cfg.Weights = map[Upstream]uint{{Host: "host1"}: 5}
+			})
+
+			It("should return the configured weight", func() {
+				Expect(cfg.Weight(Upstream{Host: "host1"})).Should(BeNumerically("==", 5))
+			})
+
+			It("should default to 1", func() {
+				Expect(cfg.Weight(Upstream{Host: "host2"})).Should(BeNumerically("==", 1))
+			})
+
+			It("should be logged", func() {
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("weights:"),
+					ContainSubstring("host1"),
+				))
+			})
+
+			It("should be parsed with upstreams as keys", func() {
+				var parsed Upstreams
+
+				Expect(yaml.UnmarshalStrict([]byte(`
+strategy: weighted
+weights:
+  tcp-tls:dns.example.com: 3
+  1.1.1.1: 1
+`), &parsed)).Should(Succeed())
+
+				Expect(parsed.Weight(Upstream{Net: NetProtocolTcpTls, Host: "dns.example.com", Port: 853})).
+					Should(BeNumerically("==", 3))
+				Expect(parsed.Weight(Upstream{Net: NetProtocolTcpUdp, Host: "1.1.1.1", Port: 53})).
+					Should(BeNumerically("==", 1))
+			})
+		})
+
+		Describe("Bind", func() {
+			BeforeEach(func() {
+				cfg.Bind = map[string]UpstreamBind{
+					UpstreamDefaultCfgName: {Address: net.ParseIP("192.168.1.2"), Interface: "wan2"},
+				}
+			})
+
+			It("should return the bind of the group", func() {
+				bind := cfg.BindOf(UpstreamDefaultCfgName)
+				Expect(bind.IsEnabled()).Should(BeTrue())
+				Expect(bind.Interface).Should(Equal("wan2"))
+
+				other := cfg.BindOf("other")
+				Expect(other.IsEnabled()).Should(BeFalse())
+			})
+
+			It("should be logged", func() {
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("bind:"),
+					ContainSubstring("address: 192.168.1.2"),
+					ContainSubstring("interface: wan2"),
+				))
+			})
+
+			It("should warn about unknown groups", func() {
+				cfg.Bind["unknown"] = UpstreamBind{Interface: "wan1"}
+
+				cfg.validate(logger)
+
+				Expect(hook.Messages).Should(ContainElement(ContainSubstring("unknown is not an upstream group")))
+			})
+
+			It("should be parsed", func() {
+				var parsed Upstreams
+
+				Expect(yaml.UnmarshalStrict([]byte(`
+bind:
+  international:
+    address: 10.0.1.2
+    interface: wan2
+`), &parsed)).Should(Succeed())
+
+				Expect(parsed.BindOf("international")).Should(Equal(UpstreamBind{
+					Address: net.ParseIP("10.0.1.2"), Interface: "wan2",
+				}))
+			})
+		})
+
+		Describe("PinnedIPs", func() {
+			BeforeEach(func() {
+				cfg.PinnedIPs = map[string][]net.IP{
+					"DNS.Example.com.": {net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")},
+					"1.1.1.1":          {net.ParseIP("192.0.2.2")},
+					"empty.example":    {},
+				}
+			})
+
+			It("should normalize the hosts and drop invalid entries", func() {
+				cfg.validate(logger)
+
+				Expect(cfg.PinnedIPs).Should(HaveLen(1))
+				Expect(cfg.PinnedIPs).Should(HaveKeyWithValue("dns.example.com", HaveLen(2)))
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("1.1.1.1 is an IP"),
+					ContainSubstring("no IPs for empty.example"),
+				))
+			})
+
+			It("should be logged", func() {
+				cfg.validate(logger)
+				cfg.LogConfig(logger)
+
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("pinnedIPs:"),
+					ContainSubstring("dns.example.com = [192.0.2.1 2001:db8::1]"),
+				))
+			})
+
+			It("should be parsed", func() {
+				var parsed Upstreams
+
+				Expect(yaml.UnmarshalStrict([]byte(`
+pinnedIPs:
+  dns.example.com:
+    - 192.0.2.1
+`), &parsed)).Should(Succeed())
+
+				Expect(parsed.PinnedIPs).Should(HaveKeyWithValue("dns.example.com", []net.IP{net.ParseIP("192.0.2.1")}))
+			})
+		})
+
+		Describe("HealthCheck", func() {
+			It("should be disabled by default", func() {
+				cfg, err := WithDefaults[Upstreams]()
+				Expect(err).Should(Succeed())
+
+				Expect(cfg.HealthCheck.IsEnabled()).Should(BeFalse())
+				Expect(cfg.HealthCheck.Name).Should(Equal("."))
+			})
+
+			It("should be logged if enabled", func() {
+				cfg.HealthCheck = UpstreamHealthCheck{
+					Interval: Duration(time.Minute), Name: "example.com", FailureThreshold: 2,
+				}
+
+				cfg.LogConfig(logger)
+
+				Expect(cfg.HealthCheck.IsEnabled()).Should(BeTrue())
+				Expect(hook.Messages).Should(ContainElements(
+					ContainSubstring("healthCheck:"),
+					ContainSubstring("interval: 1 minute"),
+					ContainSubstring("name: example.com"),
+					ContainSubstring("failureThreshold: 2"),
+				))
+			})
 		})
 	})
 
@@ -118,6 +447,44 @@ var _ = Describe("ParallelBestConfig", func() {
 			})
 		})
 
+		Describe("NewUpstreamGroup", func() {
+			var upstreamsCfg Upstreams
+
+			BeforeEach(func() {
+				var err error
+
+				upstreamsCfg, err = WithDefaults[Upstreams]()
+				Expect(err).Should(Succeed())
+
+				upstreamsCfg.GroupSettings = map[string]UpstreamGroupSettings{
+					"slow": {
+						Timeout:        Duration(10 * time.Second),
+						Retry:          UpstreamRetry{Attempts: 5},
+						CircuitBreaker: UpstreamCircuitBreaker{FailureThreshold: 4},
+					},
+				}
+			})
+
+			It("should apply the settings of the group", func() {
+				cfg := NewUpstreamGroup("slow", upstreamsCfg, nil)
+
+				Expect(cfg.Timeout).Should(Equal(Duration(10 * time.Second)))
+				Expect(cfg.Retry).Should(Equal(UpstreamRetry{Attempts: 5, Backoff: Duration(time.Millisecond)}))
+				Expect(cfg.CircuitBreaker).Should(Equal(UpstreamCircuitBreaker{
+					FailureThreshold: 4,
+					Duration:         Duration(30 * time.Second),
+				}))
+			})
+
+			It("should use the settings of upstreams for other groups", func() {
+				cfg := NewUpstreamGroup("default", upstreamsCfg, nil)
+
+				Expect(cfg.Timeout).Should(Equal(upstreamsCfg.Timeout))
+				Expect(cfg.Retry).Should(Equal(upstreamsCfg.Retry))
+				Expect(cfg.CircuitBreaker.IsEnabled()).Should(BeFalse())
+			})
+		})
+
 		Describe("LogConfig", func() {
 			It("should log configuration", func() {
 				cfg.LogConfig(logger)
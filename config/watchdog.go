@@ -0,0 +1,46 @@
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// growth needs at least two samples
+const minWatchdogSamples = 2
+
+// Watchdog configures the detection of goroutine, file descriptor and worker leaks
+type Watchdog struct {
+	Enable bool `yaml:"enable" default:"false"`
+	// Interval between two samples of the counts
+	Interval Duration `yaml:"interval" default:"1m"`
+	// Number of consecutive growing samples which raise an alarm
+	Samples uint `yaml:"samples" default:"10"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Watchdog) IsEnabled() bool {
+	return c.Enable
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Watchdog) LogConfig(logger *logrus.Entry) {
+	logger.Infof("interval = %s", c.Interval)
+	logger.Infof("samples  = %d", c.Samples)
+}
+
+func (c *Watchdog) validate(logger *logrus.Entry) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	defaults := mustDefault[Watchdog]()
+
+	if !c.Interval.IsAboveZero() {
+		logger.Warnf("watchdog.interval <= 0, setting to %s", defaults.Interval)
+		c.Interval = defaults.Interval
+	}
+
+	if c.Samples < minWatchdogSamples {
+		logger.Warnf("watchdog.samples < %d, setting to %d", minWatchdogSamples, minWatchdogSamples)
+		c.Samples = minWatchdogSamples
+	}
+}
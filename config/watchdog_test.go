@@ -0,0 +1,82 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WatchdogConfig", func() {
+	var cfg Watchdog
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[Watchdog]()
+		Expect(err).Should(Succeed())
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("enabled", func() {
+			It("should be true", func() {
+				cfg.Enable = true
+
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log configuration", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("interval = 1 minute"),
+				ContainSubstring("samples  = 10"),
+			))
+		})
+	})
+
+	Describe("validate", func() {
+		BeforeEach(func() {
+			cfg.Enable = true
+		})
+
+		It("should accept the defaults", func() {
+			cfg.validate(logger)
+
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+
+		It("should fix invalid values", func() {
+			cfg.Interval = 0
+			cfg.Samples = 1
+
+			cfg.validate(logger)
+
+			Expect(cfg.Interval).Should(Equal(Duration(time.Minute)))
+			Expect(cfg.Samples).Should(BeNumerically("==", 2))
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("watchdog.interval <= 0"),
+				ContainSubstring("watchdog.samples < 2"),
+			))
+		})
+
+		It("should ignore a disabled watchdog", func() {
+			cfg.Enable = false
+			cfg.Samples = 0
+
+			cfg.validate(logger)
+
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"net/url"
+
+	"github.com/sirupsen/logrus"
+)
+
+const pushoverURL = "https://api.pushover.net/1/messages.json"
+
+// Webhooks configures notifications about operational events like failed list downloads
+type Webhooks struct {
+	Targets []Webhook `yaml:"targets"`
+	// Minimal time between two notifications of the same event and subject (list, upstream group, ...)
+	Throttle Duration `yaml:"throttle" default:"1h"`
+}
+
+// Webhook is a receiver of the notifications
+type Webhook struct {
+	URL    string         `yaml:"url"`
+	Format WebhookFormat  `yaml:"format" default:"json"`
+	Events []WebhookEvent `yaml:"events"`
+	// ntfy: access token, pushover: application token
+	Token string `yaml:"token"`
+	// pushover: user key
+	User string `yaml:"user"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *Webhooks) IsEnabled() bool {
+	return len(c.Targets) > 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *Webhooks) LogConfig(logger *logrus.Entry) {
+	logger.Infof("throttle: %s", c.Throttle)
+	logger.Info("targets:")
+
+	for _, target := range c.Targets {
+		// the URL of Slack and Discord webhooks contains the secret, so only the host is logged
+		host := target.URL
+		if u, err := url.Parse(target.URL); err == nil {
+			host = u.Host
+		}
+
+		logger.Infof("  - %s %s: %s", target.Format, host, target.Events)
+	}
+}
+
+func (c *Webhooks) validate(logger *logrus.Entry) {
+	targets := c.Targets[:0]
+
+	for _, target := range c.Targets {
+		if target.Format == WebhookFormatPushover {
+			if target.URL == "" {
+				target.URL = pushoverURL
+			}
+
+			if target.Token == "" || target.User == "" {
+				logger.Warn("webhooks: pushover requires token and user, ignoring target")
+
+				continue
+			}
+		}
+
+		if u, err := url.Parse(target.URL); err != nil || u.Host == "" {
+			logger.Warnf("webhooks: '%s' is not a valid URL, ignoring target", target.URL)
+
+			continue
+		}
+
+		if len(target.Events) == 0 {
+			target.Events = WebhookEventValues()
+		}
+
+		targets = append(targets, target)
+	}
+
+	c.Targets = targets
+
+	if c.Throttle < 0 {
+		logger.Warn("webhooks.throttle is negative, notifications are not throttled")
+
+		c.Throttle = 0
+	}
+}
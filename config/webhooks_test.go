@@ -0,0 +1,99 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhooks", func() {
+	var cfg Webhooks
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		cfg = mustDefault[Webhooks]()
+		cfg.Targets = []Webhook{
+			{URL: "https://hooks.slack.com/services/T000/B000/secret", Format: WebhookFormatSlack},
+		}
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be false by default", func() {
+			cfg := mustDefault[Webhooks]()
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+
+		When("a target is configured", func() {
+			It("should be true", func() {
+				Expect(cfg.IsEnabled()).Should(BeTrue())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log the host of the targets only", func() {
+			cfg.validate(logger)
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElements(
+				ContainSubstring("throttle: 1 hour"),
+				ContainSubstring("slack hooks.slack.com: [listDownloadFailed"),
+			))
+			Expect(hook.Messages).ShouldNot(ContainElement(ContainSubstring("secret")))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should notify about all events by default", func() {
+			cfg.validate(logger)
+
+			Expect(cfg.Targets[0].Events).Should(Equal(WebhookEventValues()))
+			Expect(hook.Calls).Should(BeEmpty())
+		})
+
+		It("should keep the configured events", func() {
+			cfg.Targets[0].Events = []WebhookEvent{WebhookEventUpstreamsDown}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Targets[0].Events).Should(Equal([]WebhookEvent{WebhookEventUpstreamsDown}))
+		})
+
+		It("should ignore targets without valid URL", func() {
+			cfg.Targets = append(cfg.Targets, Webhook{URL: "not a URL"})
+
+			cfg.validate(logger)
+
+			Expect(cfg.Targets).Should(HaveLen(1))
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("is not a valid URL")))
+		})
+
+		It("should use the Pushover API by default", func() {
+			cfg.Targets = []Webhook{{Format: WebhookFormatPushover, Token: "app", User: "user"}}
+
+			cfg.validate(logger)
+
+			Expect(cfg.Targets[0].URL).Should(Equal("https://api.pushover.net/1/messages.json"))
+		})
+
+		It("should ignore Pushover targets without token", func() {
+			cfg.Targets = []Webhook{{Format: WebhookFormatPushover, User: "user"}}
+
+			cfg.validate(logger)
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+			Expect(hook.Messages).Should(ContainElement(ContainSubstring("pushover requires token and user")))
+		})
+
+		It("should not throttle with a negative value", func() {
+			cfg.Throttle = Duration(-time.Minute)
+
+			cfg.validate(logger)
+
+			Expect(cfg.Throttle).Should(BeZero())
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// ZoneFiles configures the zone files the custom DNS resolver loads from disk
+type ZoneFiles struct {
+	// Zones maps the name of a zone to its file, the name is the origin of relative names
+	Zones map[string]string `yaml:"zones"`
+	// CheckPeriod is the period to check the files for changes, 0 disables the check
+	CheckPeriod Duration `yaml:"checkPeriod" default:"10s"`
+}
+
+// IsEnabled implements `config.Configurable`.
+func (c *ZoneFiles) IsEnabled() bool {
+	return len(c.Zones) != 0
+}
+
+// LogConfig implements `config.Configurable`.
+func (c *ZoneFiles) LogConfig(logger *logrus.Entry) {
+	zones := maps.Keys(c.Zones)
+	slices.Sort(zones)
+
+	logger.Info("zones:")
+
+	for _, zone := range zones {
+		logger.Infof("  %s = %s", zone, c.Zones[zone])
+	}
+
+	if c.CheckPeriod.IsAboveZero() {
+		logger.Infof("checkPeriod = %s", c.CheckPeriod)
+	} else {
+		logger.Info("checkPeriod = disabled")
+	}
+}
+
+func (c *ZoneFiles) validate(logger *logrus.Entry) {
+	zones := make(map[string]string, len(c.Zones))
+
+	for zone, path := range c.Zones {
+		zone = strings.ToLower(strings.Trim(strings.TrimSpace(zone), "."))
+		if zone == "" {
+			logger.Warnf("customDNS.zoneFiles.zones: zone of %s has no name, ignoring it", path)
+
+			continue
+		}
+
+		zones[zone] = path
+	}
+
+	c.Zones = zones
+}
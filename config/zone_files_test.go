@@ -0,0 +1,67 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ZoneFilesConfig", func() {
+	var cfg ZoneFiles
+
+	suiteBeforeEach()
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = WithDefaults[ZoneFiles]()
+		Expect(err).Should(Succeed())
+
+		cfg.Zones = map[string]string{"home.lan": "/etc/blocky/home.lan.zone"}
+	})
+
+	Describe("IsEnabled", func() {
+		It("should be true with zones", func() {
+			Expect(cfg.IsEnabled()).Should(BeTrue())
+		})
+
+		It("should be false without zones", func() {
+			cfg.Zones = nil
+
+			Expect(cfg.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log the zones", func() {
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(Equal([]string{
+				"zones:",
+				"  home.lan = /etc/blocky/home.lan.zone",
+				"checkPeriod = 10 seconds",
+			}))
+		})
+
+		It("should log a disabled check", func() {
+			cfg.CheckPeriod = Duration(0)
+
+			cfg.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElement("checkPeriod = disabled"))
+		})
+	})
+
+	Describe("validate", func() {
+		It("should normalize the zone names", func() {
+			cfg.Zones = map[string]string{"Home.Lan.": "home.zone", " ": "empty.zone"}
+			cfg.CheckPeriod = Duration(time.Minute)
+
+			cfg.validate(logger)
+
+			Expect(cfg.Zones).Should(Equal(map[string]string{"home.lan": "home.zone"}))
+			Expect(hook.Messages).Should(ConsistOf(ContainSubstring("zone of empty.zone has no name")))
+		})
+	})
+})
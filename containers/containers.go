@@ -0,0 +1,283 @@
+// Package containers provides the domains of Docker and Podman containers from their labels
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+)
+
+const (
+	// label suffix of the IP addresses overriding the container's network addresses
+	ipLabelSuffix = ".ip"
+
+	listTimeout = 10 * time.Second
+
+	// delay before reconnecting to the event stream
+	defaultRetryDelay = 5 * time.Second
+)
+
+// Container is a running container with domains
+type Container struct {
+	Name    string
+	Domains []string
+	IPs     []net.IP
+}
+
+// Watcher lists the containers with domains and watches them being started and stopped
+type Watcher struct {
+	cfg        config.ContainerDNS
+	client     *http.Client
+	baseURL    string
+	retryDelay time.Duration
+}
+
+// NewWatcher creates a watcher for the Docker API endpoint of the configuration
+func NewWatcher(cfg config.ContainerDNS) (*Watcher, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	baseURL := endpoint.String()
+
+	switch endpoint.Scheme {
+	case "unix":
+		socket := endpoint.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+
+			return d.DialContext(ctx, "unix", socket)
+		}
+		// the host is ignored
+		baseURL = "http://docker"
+
+	case "tcp":
+		baseURL = "http://" + endpoint.Host
+
+	case "http", "https":
+
+	default:
+		return nil, fmt.Errorf("unsupported endpoint scheme '%s'", endpoint.Scheme)
+	}
+
+	return &Watcher{
+		cfg:        cfg,
+		client:     &http.Client{Transport: transport},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		retryDelay: defaultRetryDelay,
+	}, nil
+}
+
+// Start lists the containers and passes them to `onChange`, then again each time a container
+// starts or stops, until `ctx` is done. The first list is done synchronously.
+func (w *Watcher) Start(ctx context.Context, onChange func([]Container)) {
+	logger := log.PrefixedLog("containers")
+
+	refresh := func() {
+		containers, err := w.List(ctx)
+		if err != nil {
+			logger.WithError(err).Warn("can't list containers")
+
+			return
+		}
+
+		onChange(containers)
+	}
+
+	refresh()
+
+	go func() {
+		for {
+			// events while reconnecting are missed: list the containers again once connected
+			err := w.watch(ctx, refresh)
+			if ctx.Err() != nil {
+				return
+			}
+
+			logger.WithError(err).Warnf("container events interrupted, reconnecting in %s", w.retryDelay)
+
+			select {
+			case <-time.After(w.retryDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+type apiContainer struct {
+	Names           []string          `json:"Names"`
+	Labels          map[string]string `json:"Labels"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress         string `json:"IPAddress"`
+			GlobalIPv6Address string `json:"GlobalIPv6Address"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// List returns the running containers with domains
+func (w *Watcher) List(ctx context.Context) ([]Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+
+	resp, err := w.get(ctx, "/containers/json", map[string][]string{"label": {w.cfg.Label}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var apiContainers []apiContainer
+	if err := json.NewDecoder(resp.Body).Decode(&apiContainers); err != nil {
+		return nil, fmt.Errorf("invalid container list: %w", err)
+	}
+
+	result := make([]Container, 0, len(apiContainers))
+
+	for _, c := range apiContainers {
+		container := w.toContainer(c)
+
+		if len(container.Domains) == 0 || len(container.IPs) == 0 {
+			log.PrefixedLog("containers").Debugf("container %s has no domains or IP addresses", container.Name)
+
+			continue
+		}
+
+		result = append(result, container)
+	}
+
+	return result, nil
+}
+
+func (w *Watcher) toContainer(c apiContainer) Container {
+	var container Container
+
+	if len(c.Names) > 0 {
+		container.Name = strings.TrimPrefix(c.Names[0], "/")
+	}
+
+	container.Domains = splitLabel(c.Labels[w.cfg.Label], func(s string) (string, bool) {
+		return strings.ToLower(strings.TrimSuffix(s, ".")), true
+	})
+
+	if ips, ok := c.Labels[w.cfg.Label+ipLabelSuffix]; ok {
+		container.IPs = splitLabel(ips, func(s string) (net.IP, bool) {
+			ip := net.ParseIP(s)
+
+			return ip, ip != nil
+		})
+
+		return container
+	}
+
+	networks := make([]string, 0, len(c.NetworkSettings.Networks))
+	for name := range c.NetworkSettings.Networks {
+		networks = append(networks, name)
+	}
+
+	slices.Sort(networks)
+
+	for _, name := range networks {
+		if w.cfg.Network != "" && name != w.cfg.Network {
+			continue
+		}
+
+		network := c.NetworkSettings.Networks[name]
+
+		for _, address := range []string{network.IPAddress, network.GlobalIPv6Address} {
+			if ip := net.ParseIP(address); ip != nil {
+				container.IPs = append(container.IPs, ip)
+			}
+		}
+	}
+
+	return container
+}
+
+func splitLabel[T any](value string, convert func(string) (T, bool)) []T {
+	var result []T
+
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		if v, ok := convert(part); ok {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// watch calls `onEvent` once connected and for each container start and stop, until the stream ends
+func (w *Watcher) watch(ctx context.Context, onEvent func()) error {
+	resp, err := w.get(ctx, "/events", map[string][]string{
+		"type":  {"container"},
+		"event": {"start", "die"},
+		"label": {w.cfg.Label},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	onEvent()
+
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var event struct {
+			Action string `json:"Action"`
+		}
+
+		if err := decoder.Decode(&event); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			return fmt.Errorf("invalid event: %w", err)
+		}
+
+		onEvent()
+	}
+}
+
+func (w *Watcher) get(ctx context.Context, path string, filters map[string][]string) (*http.Response, error) {
+	filtersJSON, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	u := w.baseURL + path + "?" + url.Values{"filters": {string(filtersJSON)}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, errors.New(resp.Status)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,200 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		ctx      context.Context
+		cfg      config.ContainerDNS
+		handler  http.HandlerFunc
+		requests atomic.Int32
+
+		containersJSON string
+		eventsStatus   int
+	)
+
+	BeforeEach(func() {
+		var cancelFn context.CancelFunc
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		requests.Store(0)
+
+		containersJSON = `[
+			{"Names": ["/app"], "Labels": {"blocky.dns": "App.home.lan., other.home.lan"},
+			 "NetworkSettings": {"Networks": {
+				"frontend": {"IPAddress": "172.18.0.2", "GlobalIPv6Address": "fd00::2"},
+				"backend": {"IPAddress": "172.19.0.2"}
+			 }}},
+			{"Names": ["/proxy"], "Labels": {"blocky.dns": "proxy.home.lan", "blocky.dns.ip": "192.168.1.2, invalid"}},
+			{"Names": ["/no-domain"], "Labels": {"blocky.dns": ""},
+			 "NetworkSettings": {"Networks": {"bridge": {"IPAddress": "172.17.0.3"}}}},
+			{"Names": ["/host-network"], "Labels": {"blocky.dns": "host.home.lan"},
+			 "NetworkSettings": {"Networks": {"host": {"IPAddress": ""}}}}
+		]`
+		eventsStatus = http.StatusOK
+
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+
+			var filters map[string][]string
+			Expect(json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)).Should(Succeed())
+			Expect(filters).Should(HaveKeyWithValue("label", []string{"blocky.dns"}))
+
+			switch r.URL.Path {
+			case "/containers/json":
+				_, _ = w.Write([]byte(containersJSON))
+			case "/events":
+				Expect(filters).Should(HaveKeyWithValue("event", []string{"start", "die"}))
+
+				w.WriteHeader(eventsStatus)
+				_, _ = w.Write([]byte(`{"Type": "container", "Action": "start"}`))
+			}
+		}
+
+		cfg = config.ContainerDNS{Enable: true, Label: "blocky.dns"}
+	})
+
+	startServer := func() {
+		server := httptest.NewServer(handler)
+		DeferCleanup(server.Close)
+
+		cfg.Endpoint = server.URL
+	}
+
+	Describe("List", func() {
+		It("returns the containers with domains and IPs", func() {
+			startServer()
+
+			sut, err := NewWatcher(cfg)
+			Expect(err).Should(Succeed())
+
+			Expect(sut.List(ctx)).Should(Equal([]Container{
+				{
+					Name:    "app",
+					Domains: []string{"app.home.lan", "other.home.lan"},
+					IPs:     []net.IP{net.ParseIP("172.19.0.2"), net.ParseIP("172.18.0.2"), net.ParseIP("fd00::2")},
+				},
+				{Name: "proxy", Domains: []string{"proxy.home.lan"}, IPs: []net.IP{net.ParseIP("192.168.1.2")}},
+			}))
+		})
+
+		It("only uses the IPs of the configured network", func() {
+			cfg.Network = "frontend"
+			startServer()
+
+			sut, err := NewWatcher(cfg)
+			Expect(err).Should(Succeed())
+
+			containers, err := sut.List(ctx)
+			Expect(err).Should(Succeed())
+			Expect(containers[0].IPs).Should(Equal([]net.IP{net.ParseIP("172.18.0.2"), net.ParseIP("fd00::2")}))
+		})
+
+		It("connects to unix sockets", func() {
+			// short path: unix socket paths are limited to about 100 characters
+			dir, err := os.MkdirTemp("", "blocky")
+			Expect(err).Should(Succeed())
+			DeferCleanup(os.RemoveAll, dir)
+
+			socket := filepath.Join(dir, "docker.sock")
+
+			listener, err := net.Listen("unix", socket)
+			Expect(err).Should(Succeed())
+
+			server := httptest.NewUnstartedServer(handler)
+			server.Listener = listener
+			server.Start()
+			DeferCleanup(server.Close)
+
+			cfg.Endpoint = "unix://" + socket
+
+			sut, err := NewWatcher(cfg)
+			Expect(err).Should(Succeed())
+
+			Expect(sut.List(ctx)).Should(HaveLen(2))
+		})
+
+		It("fails on errors", func() {
+			handler = func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			startServer()
+
+			sut, err := NewWatcher(cfg)
+			Expect(err).Should(Succeed())
+
+			_, err = sut.List(ctx)
+			Expect(err).Should(MatchError("500 Internal Server Error"))
+		})
+	})
+
+	Describe("Start", func() {
+		It("lists the containers again on events and reconnects", func() {
+			startServer()
+
+			sut, err := NewWatcher(cfg)
+			Expect(err).Should(Succeed())
+
+			sut.retryDelay = 10 * time.Millisecond
+
+			var changes atomic.Int32
+
+			sut.Start(ctx, func(containers []Container) {
+				Expect(containers).Should(HaveLen(2))
+				changes.Add(1)
+			})
+
+			Expect(changes.Load()).Should(BeNumerically(">=", 1))
+
+			// the event stream ends after each event: list on connect and on the event, then reconnect
+			Eventually(changes.Load).Should(BeNumerically(">=", 5))
+		})
+
+		It("retries when events can't be watched", func() {
+			eventsStatus = http.StatusServiceUnavailable
+			startServer()
+
+			sut, err := NewWatcher(cfg)
+			Expect(err).Should(Succeed())
+
+			sut.retryDelay = 10 * time.Millisecond
+
+			sut.Start(ctx, func([]Container) {})
+
+			Eventually(requests.Load).Should(BeNumerically(">=", 4))
+		})
+	})
+
+	Describe("NewWatcher", func() {
+		It("fails on unsupported endpoints", func() {
+			cfg.Endpoint = "ftp://docker"
+
+			_, err := NewWatcher(cfg)
+			Expect(err).Should(MatchError(ContainSubstring("unsupported endpoint scheme")))
+		})
+
+		It("uses tcp endpoints as HTTP", func() {
+			cfg.Endpoint = "tcp://docker:2375"
+
+			sut, err := NewWatcher(cfg)
+			Expect(err).Should(Succeed())
+			Expect(sut.baseURL).Should(Equal("http://docker:2375"))
+		})
+	})
+})
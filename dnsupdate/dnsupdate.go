@@ -0,0 +1,482 @@
+// Package dnsupdate implements dynamic DNS updates (RFC 2136) authenticated with TSIG (RFC 8945),
+// storing the records for the custom DNS resolver.
+package dnsupdate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// record types the custom DNS resolver can answer with
+//
+//nolint:gochecknoglobals
+var supportedTypes = []uint16{dns.TypeA, dns.TypeAAAA, dns.TypeCNAME, dns.TypeTXT, dns.TypeSRV}
+
+// Zones holds the records of the zones accepting updates.
+// If a file is configured, the records are persisted in it after each update and loaded from it on start.
+type Zones struct {
+	cfg config.DynamicUpdates
+
+	lock     sync.Mutex
+	records  map[string][]dns.RR
+	onChange func(config.CustomDNSMapping)
+}
+
+// NewZones creates the zones and loads the records of the file
+func NewZones(cfg config.DynamicUpdates) (*Zones, error) {
+	z := &Zones{
+		cfg:     cfg,
+		records: make(map[string][]dns.RR),
+	}
+
+	if cfg.File != "" {
+		if err := z.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return z, nil
+}
+
+func logger() *logrus.Entry {
+	return log.PrefixedLog("dnsUpdate")
+}
+
+// Name implements `resolver.CustomDNSSource`.
+func (z *Zones) Name() string {
+	return "dynamic updates"
+}
+
+// OnChange registers the function which receives the records after each update
+func (z *Zones) OnChange(onChange func(config.CustomDNSMapping)) {
+	z.lock.Lock()
+	defer z.lock.Unlock()
+
+	z.onChange = onChange
+
+	onChange(z.mapping())
+}
+
+// TsigSecrets returns the secrets of the TSIG keys by fully qualified key name, for `dns.Server.TsigSecret`
+func (z *Zones) TsigSecrets() map[string]string {
+	result := make(map[string]string, len(z.cfg.Keys))
+
+	for name, secret := range z.cfg.Keys {
+		result[dns.Fqdn(name)] = secret
+	}
+
+	return result
+}
+
+// AcceptMsg extends `dns.DefaultMsgAcceptFunc`, which rejects UPDATE messages, for `dns.Server.MsgAcceptFunc`
+func AcceptMsg(dh dns.Header) dns.MsgAcceptAction {
+	const (
+		qrBit       = 1 << 15
+		opcodeShift = 11
+		opcodeMask  = 0xF
+	)
+
+	if dh.Bits&qrBit == 0 && int(dh.Bits>>opcodeShift)&opcodeMask == dns.OpcodeUpdate {
+		return dns.MsgAccept
+	}
+
+	return dns.DefaultMsgAcceptFunc(dh)
+}
+
+// ServeUpdate answers the UPDATE message.
+// The TSIG signature must have been verified by the server, see `TsigSecrets`. `tsigStatus` is the result of the
+// verification for this message: the TSIG state of a TCP writer belongs to the last message read on the connection,
+// the caller must pass an error if it can't tell that this is the message.
+func (z *Zones) ServeUpdate(w dns.ResponseWriter, req *dns.Msg, tsigStatus error) {
+	response := new(dns.Msg)
+	response.SetRcode(req, z.Update(req, tsigStatus))
+
+	// sign the response with the key of the request
+	if tsig := req.IsTsig(); tsig != nil && tsigStatus == nil {
+		response.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+	}
+
+	if err := w.WriteMsg(response); err != nil {
+		logger().WithError(err).Warn("can't write response")
+	}
+}
+
+// Update processes the UPDATE message and returns the response code (RFC 2136 section 3).
+// `tsigStatus` is the result of the verification of the TSIG signature.
+func (z *Zones) Update(req *dns.Msg, tsigStatus error) int {
+	logger := logger()
+
+	if rcode := z.authorize(req, tsigStatus); rcode != dns.RcodeSuccess {
+		logger.Debugf("refused unauthorized update: %s", dns.RcodeToString[rcode])
+
+		return rcode
+	}
+
+	// zone section, section 3.1
+	if len(req.Question) != 1 || req.Question[0].Qtype != dns.TypeSOA {
+		return dns.RcodeFormatError
+	}
+
+	zone := normalize(req.Question[0].Name)
+	if !slices.Contains(z.cfg.Zones, zone) {
+		return dns.RcodeNotAuth
+	}
+
+	z.lock.Lock()
+	defer z.lock.Unlock()
+
+	if rcode := z.checkPrerequisites(zone, req.Answer); rcode != dns.RcodeSuccess {
+		return rcode
+	}
+
+	if rcode := prescan(zone, req.Ns); rcode != dns.RcodeSuccess {
+		return rcode
+	}
+
+	records := z.apply(req.Ns)
+
+	if z.cfg.File != "" {
+		if err := save(z.cfg.File, records); err != nil {
+			logger.WithError(err).Error("can't persist the records, rejecting update")
+
+			return dns.RcodeServerFailure
+		}
+	}
+
+	z.records = records
+
+	logger.Debugf("applied %d updates to zone %s", len(req.Ns), zone)
+
+	if z.onChange != nil {
+		z.onChange(z.mapping())
+	}
+
+	return dns.RcodeSuccess
+}
+
+// authorize checks that the message is signed with a configured key
+func (z *Zones) authorize(req *dns.Msg, tsigStatus error) int {
+	tsig := req.IsTsig()
+	if tsig == nil {
+		return dns.RcodeRefused
+	}
+
+	if _, ok := z.cfg.Keys[normalize(tsig.Hdr.Name)]; !ok || tsigStatus != nil {
+		return dns.RcodeNotAuth
+	}
+
+	return dns.RcodeSuccess
+}
+
+// checkPrerequisites checks the prerequisite section, RFC 2136 section 3.2
+func (z *Zones) checkPrerequisites(zone string, prerequisites []dns.RR) int {
+	var valueDependent []dns.RR
+
+	for _, rr := range prerequisites {
+		hdr := rr.Header()
+		name := normalize(hdr.Name)
+
+		if hdr.Ttl != 0 {
+			return dns.RcodeFormatError
+		}
+
+		if !inZone(name, zone) {
+			return dns.RcodeNotZone
+		}
+
+		existing := z.records[name]
+
+		switch hdr.Class {
+		case dns.ClassANY:
+			if hdr.Rdlength != 0 {
+				return dns.RcodeFormatError
+			}
+
+			if hdr.Rrtype == dns.TypeANY && len(existing) == 0 {
+				// name is in use
+				return dns.RcodeNameError
+			}
+
+			if hdr.Rrtype != dns.TypeANY && !hasType(existing, hdr.Rrtype) {
+				// RRset exists (value independent)
+				return dns.RcodeNXRrset
+			}
+
+		case dns.ClassNONE:
+			if hdr.Rdlength != 0 {
+				return dns.RcodeFormatError
+			}
+
+			if hdr.Rrtype == dns.TypeANY && len(existing) != 0 {
+				// name is not in use
+				return dns.RcodeYXDomain
+			}
+
+			if hdr.Rrtype != dns.TypeANY && hasType(existing, hdr.Rrtype) {
+				// RRset does not exist
+				return dns.RcodeYXRrset
+			}
+
+		case dns.ClassINET:
+			valueDependent = append(valueDependent, rr)
+
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+
+	// RRset exists (value dependent)
+	for _, rr := range valueDependent {
+		name := normalize(rr.Header().Name)
+
+		expected := rrset(valueDependent, name, rr.Header().Rrtype)
+		actual := rrset(z.records[name], name, rr.Header().Rrtype)
+
+		if !sameRRset(expected, actual) {
+			return dns.RcodeNXRrset
+		}
+	}
+
+	return dns.RcodeSuccess
+}
+
+// prescan checks the update section before any change is applied, RFC 2136 section 3.4.1
+func prescan(zone string, updates []dns.RR) int {
+	for _, rr := range updates {
+		hdr := rr.Header()
+
+		if !inZone(normalize(hdr.Name), zone) {
+			return dns.RcodeNotZone
+		}
+
+		switch hdr.Class {
+		case dns.ClassINET:
+			if isMetaType(hdr.Rrtype) {
+				return dns.RcodeFormatError
+			}
+
+			if !slices.Contains(supportedTypes, hdr.Rrtype) {
+				return dns.RcodeRefused
+			}
+
+		case dns.ClassANY:
+			if hdr.Ttl != 0 || hdr.Rdlength != 0 || (isMetaType(hdr.Rrtype) && hdr.Rrtype != dns.TypeANY) {
+				return dns.RcodeFormatError
+			}
+
+		case dns.ClassNONE:
+			if hdr.Ttl != 0 || isMetaType(hdr.Rrtype) {
+				return dns.RcodeFormatError
+			}
+
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+
+	return dns.RcodeSuccess
+}
+
+// apply returns the records with the updates applied, RFC 2136 section 3.4.2
+func (z *Zones) apply(updates []dns.RR) map[string][]dns.RR {
+	records := make(map[string][]dns.RR, len(z.records))
+	for name, rrs := range z.records {
+		records[name] = slices.Clone(rrs)
+	}
+
+	for _, rr := range updates {
+		hdr := rr.Header()
+		name := normalize(hdr.Name)
+		existing := records[name]
+
+		switch hdr.Class {
+		case dns.ClassINET:
+			// a CNAME can't coexist with other data
+			if hdr.Rrtype == dns.TypeCNAME && slices.ContainsFunc(existing, isNotCNAME) ||
+				hdr.Rrtype != dns.TypeCNAME && hasType(existing, dns.TypeCNAME) {
+				continue
+			}
+
+			rr = dns.Copy(rr)
+			rr.Header().Name = dns.Fqdn(name)
+
+			// there is only one CNAME per name, and duplicates replace the existing record to update the TTL
+			existing = slices.DeleteFunc(existing, func(old dns.RR) bool {
+				return hdr.Rrtype == dns.TypeCNAME && old.Header().Rrtype == dns.TypeCNAME || dns.IsDuplicate(old, rr)
+			})
+
+			records[name] = append(existing, rr)
+
+		case dns.ClassANY:
+			if hdr.Rrtype == dns.TypeANY {
+				delete(records, name)
+
+				continue
+			}
+
+			records[name] = slices.DeleteFunc(existing, func(old dns.RR) bool {
+				return old.Header().Rrtype == hdr.Rrtype
+			})
+
+		case dns.ClassNONE:
+			deleted := dns.Copy(rr)
+			deleted.Header().Class = dns.ClassINET
+
+			records[name] = slices.DeleteFunc(existing, func(old dns.RR) bool {
+				return dns.IsDuplicate(old, deleted)
+			})
+		}
+
+		if len(records[name]) == 0 {
+			delete(records, name)
+		}
+	}
+
+	return records
+}
+
+// mapping returns the records for the custom DNS resolver, the lock must be held
+func (z *Zones) mapping() config.CustomDNSMapping {
+	result := make(config.CustomDNSMapping, len(z.records))
+
+	for name, rrs := range z.records {
+		result[name] = slices.Clone(rrs)
+	}
+
+	return result
+}
+
+func (z *Zones) load() error {
+	file, err := os.Open(z.cfg.File)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("can't open dynamic updates file: %w", err)
+	}
+
+	defer file.Close()
+
+	parser := dns.NewZoneParser(file, "", z.cfg.File)
+
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		name := normalize(rr.Header().Name)
+		z.records[name] = append(z.records[name], rr)
+	}
+
+	if err := parser.Err(); err != nil {
+		return fmt.Errorf("can't parse dynamic updates file: %w", err)
+	}
+
+	logger().Infof("loaded %d names from %s", len(z.records), z.cfg.File)
+
+	return nil
+}
+
+// save writes the records in zone file format, replacing the file atomically
+func save(path string, records map[string][]dns.RR) error {
+	names := maps.Keys(records)
+	slices.Sort(names)
+
+	var sb strings.Builder
+
+	sb.WriteString("; records of dynamic DNS updates, managed by blocky\n")
+
+	for _, name := range names {
+		for _, rr := range records[name] {
+			sb.WriteString(rr.String())
+			sb.WriteString("\n")
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(sb.String()); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func rrset(rrs []dns.RR, name string, rrtype uint16) []dns.RR {
+	var result []dns.RR
+
+	for _, rr := range rrs {
+		if normalize(rr.Header().Name) == name && rr.Header().Rrtype == rrtype {
+			result = append(result, rr)
+		}
+	}
+
+	return result
+}
+
+// sameRRset compares the records ignoring the TTL, the order and duplicates
+func sameRRset(a, b []dns.RR) bool {
+	contains := func(rrs []dns.RR, rr dns.RR) bool {
+		return slices.ContainsFunc(rrs, func(other dns.RR) bool { return dns.IsDuplicate(rr, other) })
+	}
+
+	for _, rr := range a {
+		if !contains(b, rr) {
+			return false
+		}
+	}
+
+	for _, rr := range b {
+		if !contains(a, rr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasType(rrs []dns.RR, rrtype uint16) bool {
+	return slices.ContainsFunc(rrs, func(rr dns.RR) bool { return rr.Header().Rrtype == rrtype })
+}
+
+func isNotCNAME(rr dns.RR) bool {
+	return rr.Header().Rrtype != dns.TypeCNAME
+}
+
+func isMetaType(rrtype uint16) bool {
+	switch rrtype {
+	case dns.TypeANY, dns.TypeAXFR, dns.TypeIXFR, dns.TypeMAILA, dns.TypeMAILB:
+		return true
+	}
+
+	return false
+}
+
+func inZone(name, zone string) bool {
+	return name == zone || strings.HasSuffix(name, "."+zone)
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
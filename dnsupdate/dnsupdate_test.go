@@ -0,0 +1,365 @@
+package dnsupdate
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/miekg/dns"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	keyName = "dhcp-key."
+	secret  = "c2VjcmV0LXNlY3JldC1zZWNyZXQ="
+)
+
+var _ = Describe("Zones", func() {
+	var (
+		sut  *Zones
+		cfg  config.DynamicUpdates
+		addr string
+
+		// the mappings are passed by the server's goroutine
+		lock     sync.Mutex
+		mappings []config.CustomDNSMapping
+	)
+
+	passedMappings := func() []config.CustomDNSMapping {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return slices.Clone(mappings)
+	}
+
+	newRR := func(s string) dns.RR {
+		rr, err := dns.NewRR(s)
+		Expect(err).Should(Succeed())
+
+		return rr
+	}
+
+	lastMapping := func() config.CustomDNSMapping {
+		passed := passedMappings()
+
+		return passed[len(passed)-1]
+	}
+
+	// exchange sends the update signed with `key` and returns the response code
+	exchange := func(msg *dns.Msg, key string) int {
+		client := &dns.Client{TsigSecret: map[string]string{keyName: key}}
+
+		msg.SetTsig(keyName, dns.HmacSHA256, 300, time.Now().Unix())
+
+		resp, _, err := client.Exchange(msg, addr)
+		Expect(err).Should(Succeed())
+
+		return resp.Rcode
+	}
+
+	update := func(prepare func(msg *dns.Msg)) int {
+		msg := new(dns.Msg)
+		msg.SetUpdate("home.lan.")
+		prepare(msg)
+
+		return exchange(msg, secret)
+	}
+
+	BeforeEach(func() {
+		cfg = config.DynamicUpdates{
+			Zones: []string{"home.lan"},
+			Keys:  map[string]string{"dhcp-key": secret},
+		}
+		lock.Lock()
+		mappings = nil
+		lock.Unlock()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = NewZones(cfg)
+		Expect(err).Should(Succeed())
+
+		sut.OnChange(func(mapping config.CustomDNSMapping) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			mappings = append(mappings, mapping)
+		})
+
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).Should(Succeed())
+
+		addr = pc.LocalAddr().String()
+
+		started := make(chan struct{})
+		server := &dns.Server{
+			PacketConn: pc,
+			Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+				sut.ServeUpdate(w, req, w.TsigStatus())
+			}),
+			TsigSecret:        sut.TsigSecrets(),
+			MsgAcceptFunc:     AcceptMsg,
+			NotifyStartedFunc: func() { close(started) },
+		}
+
+		go func() {
+			defer GinkgoRecover()
+
+			_ = server.ActivateAndServe()
+		}()
+
+		DeferCleanup(server.Shutdown)
+		Eventually(started).Should(BeClosed())
+	})
+
+	It("passes the empty mapping on registration", func() {
+		Expect(passedMappings()).Should(Equal([]config.CustomDNSMapping{{}}))
+	})
+
+	It("returns the secrets by fully qualified key name", func() {
+		Expect(sut.TsigSecrets()).Should(Equal(map[string]string{keyName: secret}))
+	})
+
+	Describe("authorization", func() {
+		It("refuses unsigned updates", func() {
+			msg := new(dns.Msg)
+			msg.SetUpdate("home.lan.")
+			msg.Insert([]dns.RR{newRR("nas.home.lan. 300 IN A 192.168.178.2")})
+
+			resp, err := dns.Exchange(msg, addr)
+			Expect(err).Should(Succeed())
+			Expect(resp.Rcode).Should(Equal(dns.RcodeRefused))
+			Expect(passedMappings()).Should(HaveLen(1))
+		})
+
+		It("rejects updates with a wrong signature", func() {
+			msg := new(dns.Msg)
+			msg.SetUpdate("home.lan.")
+			msg.Insert([]dns.RR{newRR("nas.home.lan. 300 IN A 192.168.178.2")})
+
+			client := &dns.Client{TsigSecret: map[string]string{keyName: "d3Jvbmc="}}
+			msg.SetTsig(keyName, dns.HmacSHA256, 300, time.Now().Unix())
+
+			resp, _, _ := client.Exchange(msg, addr)
+			Expect(resp).ShouldNot(BeNil())
+			Expect(resp.Rcode).Should(Equal(dns.RcodeNotAuth))
+			Expect(passedMappings()).Should(HaveLen(1))
+		})
+
+		It("rejects updates of other zones", func() {
+			msg := new(dns.Msg)
+			msg.SetUpdate("example.com.")
+			msg.Insert([]dns.RR{newRR("www.example.com. 300 IN A 192.168.178.2")})
+
+			client := &dns.Client{TsigSecret: map[string]string{keyName: secret}}
+			msg.SetTsig(keyName, dns.HmacSHA256, 300, time.Now().Unix())
+
+			// the client reports NOTAUTH responses as authentication errors
+			resp, _, _ := client.Exchange(msg, addr)
+			Expect(resp).ShouldNot(BeNil())
+			Expect(resp.Rcode).Should(Equal(dns.RcodeNotAuth))
+		})
+	})
+
+	Describe("updates", func() {
+		It("adds records", func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.Insert([]dns.RR{
+					newRR("NAS.home.lan. 300 IN A 192.168.178.2"),
+					newRR("nas.home.lan. 300 IN AAAA fd00::2"),
+					newRR("_acme-challenge.home.lan. 60 IN TXT \"token\""),
+				})
+			})).Should(Equal(dns.RcodeSuccess))
+
+			mapping := lastMapping()
+			Expect(mapping).Should(HaveKey("nas.home.lan"))
+			Expect(mapping["nas.home.lan"]).Should(HaveLen(2))
+			Expect(mapping["nas.home.lan"][0].String()).Should(Equal("nas.home.lan.\t300\tIN\tA\t192.168.178.2"))
+			Expect(mapping["_acme-challenge.home.lan"][0].String()).
+				Should(Equal("_acme-challenge.home.lan.\t60\tIN\tTXT\t\"token\""))
+		})
+
+		It("replaces duplicates", func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.Insert([]dns.RR{newRR("nas.home.lan. 300 IN A 192.168.178.2")})
+			})).Should(Equal(dns.RcodeSuccess))
+
+			Expect(update(func(msg *dns.Msg) {
+				msg.Insert([]dns.RR{newRR("nas.home.lan. 60 IN A 192.168.178.2")})
+			})).Should(Equal(dns.RcodeSuccess))
+
+			Expect(lastMapping()["nas.home.lan"]).Should(HaveLen(1))
+			Expect(lastMapping()["nas.home.lan"][0].Header().Ttl).Should(BeNumerically("==", 60))
+		})
+
+		It("ignores a CNAME for a name with other records", func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.Insert([]dns.RR{
+					newRR("nas.home.lan. 300 IN A 192.168.178.2"),
+					newRR("nas.home.lan. 300 IN CNAME other.home.lan."),
+				})
+			})).Should(Equal(dns.RcodeSuccess))
+
+			Expect(lastMapping()["nas.home.lan"]).Should(HaveLen(1))
+		})
+
+		It("deletes records", func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.Insert([]dns.RR{
+					newRR("nas.home.lan. 300 IN A 192.168.178.2"),
+					newRR("nas.home.lan. 300 IN A 192.168.178.3"),
+					newRR("nas.home.lan. 300 IN AAAA fd00::2"),
+					newRR("printer.home.lan. 300 IN A 192.168.178.4"),
+				})
+			})).Should(Equal(dns.RcodeSuccess))
+
+			By("deleting a record", func() {
+				Expect(update(func(msg *dns.Msg) {
+					msg.Remove([]dns.RR{newRR("nas.home.lan. 300 IN A 192.168.178.3")})
+				})).Should(Equal(dns.RcodeSuccess))
+
+				Expect(lastMapping()["nas.home.lan"]).Should(HaveLen(2))
+			})
+
+			By("deleting a RRset", func() {
+				Expect(update(func(msg *dns.Msg) {
+					msg.RemoveRRset([]dns.RR{newRR("nas.home.lan. 0 IN A 0.0.0.0")})
+				})).Should(Equal(dns.RcodeSuccess))
+
+				Expect(lastMapping()["nas.home.lan"]).Should(HaveLen(1))
+				Expect(lastMapping()["nas.home.lan"][0].Header().Rrtype).Should(Equal(dns.TypeAAAA))
+			})
+
+			By("deleting a name", func() {
+				Expect(update(func(msg *dns.Msg) {
+					msg.RemoveName([]dns.RR{newRR("nas.home.lan. 0 IN A 0.0.0.0")})
+				})).Should(Equal(dns.RcodeSuccess))
+
+				Expect(lastMapping()).ShouldNot(HaveKey("nas.home.lan"))
+				Expect(lastMapping()).Should(HaveKey("printer.home.lan"))
+			})
+		})
+
+		It("rejects names outside of the zone", func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.Insert([]dns.RR{newRR("nas.example.com. 300 IN A 192.168.178.2")})
+			})).Should(Equal(dns.RcodeNotZone))
+		})
+
+		It("refuses unsupported record types", func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.Insert([]dns.RR{newRR("home.lan. 300 IN MX 10 mail.home.lan.")})
+			})).Should(Equal(dns.RcodeRefused))
+
+			Expect(passedMappings()).Should(HaveLen(1))
+		})
+	})
+
+	Describe("prerequisites", func() {
+		JustBeforeEach(func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.Insert([]dns.RR{newRR("nas.home.lan. 300 IN A 192.168.178.2")})
+			})).Should(Equal(dns.RcodeSuccess))
+		})
+
+		insert := []dns.RR{newRR("printer.home.lan. 300 IN A 192.168.178.4")}
+
+		It("checks if a name is in use", func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.NameUsed([]dns.RR{newRR("unknown.home.lan. 0 IN A 0.0.0.0")})
+				msg.Insert(insert)
+			})).Should(Equal(dns.RcodeNameError))
+
+			Expect(update(func(msg *dns.Msg) {
+				msg.NameNotUsed([]dns.RR{newRR("nas.home.lan. 0 IN A 0.0.0.0")})
+				msg.Insert(insert)
+			})).Should(Equal(dns.RcodeYXDomain))
+
+			Expect(update(func(msg *dns.Msg) {
+				msg.NameUsed([]dns.RR{newRR("nas.home.lan. 0 IN A 0.0.0.0")})
+				msg.Insert(insert)
+			})).Should(Equal(dns.RcodeSuccess))
+		})
+
+		It("checks if a RRset exists", func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.RRsetUsed([]dns.RR{newRR("nas.home.lan. 0 IN AAAA ::")})
+				msg.Insert(insert)
+			})).Should(Equal(dns.RcodeNXRrset))
+
+			Expect(update(func(msg *dns.Msg) {
+				msg.RRsetNotUsed([]dns.RR{newRR("nas.home.lan. 0 IN A 0.0.0.0")})
+				msg.Insert(insert)
+			})).Should(Equal(dns.RcodeYXRrset))
+
+			Expect(update(func(msg *dns.Msg) {
+				msg.Used([]dns.RR{newRR("nas.home.lan. 0 IN A 192.168.178.3")})
+				msg.Insert(insert)
+			})).Should(Equal(dns.RcodeNXRrset))
+
+			Expect(update(func(msg *dns.Msg) {
+				msg.Used([]dns.RR{newRR("nas.home.lan. 0 IN A 192.168.178.2")})
+				msg.Insert(insert)
+			})).Should(Equal(dns.RcodeSuccess))
+
+			Expect(lastMapping()).Should(HaveKey("printer.home.lan"))
+		})
+	})
+
+	Describe("persistence", func() {
+		BeforeEach(func() {
+			cfg.File = filepath.Join(GinkgoT().TempDir(), "updates.zone")
+		})
+
+		It("restores the records from the file", func() {
+			Expect(update(func(msg *dns.Msg) {
+				msg.Insert([]dns.RR{newRR("nas.home.lan. 300 IN A 192.168.178.2")})
+			})).Should(Equal(dns.RcodeSuccess))
+
+			Expect(os.ReadFile(cfg.File)).Should(ContainSubstring("nas.home.lan.\t300\tIN\tA\t192.168.178.2"))
+
+			restored, err := NewZones(cfg)
+			Expect(err).Should(Succeed())
+
+			var mapping config.CustomDNSMapping
+
+			restored.OnChange(func(m config.CustomDNSMapping) { mapping = m })
+
+			Expect(mapping).Should(HaveKey("nas.home.lan"))
+		})
+
+		It("fails on an invalid file", func() {
+			Expect(os.WriteFile(cfg.File, []byte("invalid record\n"), 0o600)).Should(Succeed())
+
+			_, err := NewZones(cfg)
+			Expect(err).Should(MatchError(ContainSubstring("can't parse")))
+		})
+	})
+
+	Describe("Update", func() {
+		It("rejects messages without zone", func() {
+			msg := new(dns.Msg)
+			msg.Opcode = dns.OpcodeUpdate
+			msg.SetTsig(keyName, dns.HmacSHA256, 300, time.Now().Unix())
+
+			Expect(sut.Update(msg, nil)).Should(Equal(dns.RcodeFormatError))
+		})
+
+		It("rejects prerequisites with a TTL", func() {
+			msg := new(dns.Msg)
+			msg.SetUpdate("home.lan.")
+			msg.Answer = []dns.RR{newRR("nas.home.lan. 300 IN A 192.168.178.2")}
+			msg.SetTsig(keyName, dns.HmacSHA256, 300, time.Now().Unix())
+
+			Expect(sut.Update(msg, nil)).Should(Equal(dns.RcodeFormatError))
+		})
+	})
+})
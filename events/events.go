@@ -0,0 +1,213 @@
+// Package events publishes events like blocked queries to a message broker (MQTT or a Kafka REST proxy),
+// so home automation or SIEM pipelines can react to them in real time
+package events
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/lists"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/sirupsen/logrus"
+)
+
+const maxBatchSize = 100
+
+// Event is the payload of a published message
+type Event struct {
+	Type config.EventType `json:"type"`
+	Time time.Time        `json:"time"`
+	Data map[string]any   `json:"data"`
+}
+
+// sender delivers the events to the broker
+type sender interface {
+	send(ctx context.Context, events []*Event) error
+	close()
+}
+
+// Publisher passes the events of the bus to the broker.
+// Events are queued, so a slow or unreachable broker never blocks the processing of queries.
+type Publisher struct {
+	cfg    config.Events
+	sender sender
+	queue  chan *Event
+
+	dropped atomic.Uint64
+	failing bool
+
+	ctx context.Context
+}
+
+func logger() *logrus.Entry {
+	return log.PrefixedLog("events")
+}
+
+// Start subscribes the configured events and publishes them until ctx is done, returns nil if publishing is disabled
+func Start(ctx context.Context, cfg config.Events) (*Publisher, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil //nolint:nilnil
+	}
+
+	var (
+		s   sender
+		err error
+	)
+
+	switch cfg.Type {
+	case config.EventsTypeMqtt:
+		s, err = newMQTTSender(cfg)
+	case config.EventsTypeKafkaRest:
+		s, err = newKafkaRestSender(cfg)
+	default:
+		err = fmt.Errorf("unsupported events type: %s", cfg.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Publisher{
+		cfg:    cfg,
+		sender: s,
+		queue:  make(chan *Event, cfg.QueueSize),
+		ctx:    ctx,
+	}
+
+	if err := p.subscribe(); err != nil {
+		return nil, err
+	}
+
+	go p.run(ctx)
+
+	return p, nil
+}
+
+// subscribe registers the handlers of the configured events on the bus
+func (p *Publisher) subscribe() error {
+	for _, eventType := range p.cfg.Events {
+		var (
+			topic   string
+			handler any
+		)
+
+		switch eventType {
+		case config.EventTypeQueryBlocked:
+			topic = evt.BlockingQueryBlocked
+			handler = func(clientIP net.IP, clientNames []string, domain, reason string) {
+				p.enqueue(eventType, map[string]any{
+					"client_ip":    clientIP.String(),
+					"client_names": clientNames,
+					"domain":       domain,
+					"reason":       reason,
+				})
+			}
+
+		case config.EventTypeListRefreshed:
+			topic = evt.BlockingCacheGroupChanged
+			handler = func(listType lists.ListCacheType, group string, count int) {
+				p.enqueue(eventType, map[string]any{
+					"list_type": listType.String(),
+					"group":     group,
+					"count":     count,
+				})
+			}
+
+		case config.EventTypeListDownloadFailed:
+			topic = evt.CachingFailedDownloadChanged
+			handler = func(link string) {
+				p.enqueue(eventType, map[string]any{"link": link})
+			}
+
+		case config.EventTypeUpstreamHealthChanged:
+			topic = evt.UpstreamHealthChanged
+			handler = func(upstream string, healthy bool) {
+				p.enqueue(eventType, map[string]any{"upstream": upstream, "healthy": healthy})
+			}
+
+		case config.EventTypeBlockingChanged:
+			topic = evt.BlockingEnabledEvent
+			handler = func(enabled bool) {
+				p.enqueue(eventType, map[string]any{"enabled": enabled})
+			}
+		}
+
+		if err := evt.Bus().Subscribe(topic, handler); err != nil {
+			return fmt.Errorf("can't subscribe %s: %w", eventType, err)
+		}
+	}
+
+	return nil
+}
+
+// enqueue adds the event to the queue, it is dropped if the queue is full
+func (p *Publisher) enqueue(eventType config.EventType, data map[string]any) {
+	// the bus has no way to remove the handlers of a single publisher
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	select {
+	case p.queue <- &Event{Type: eventType, Time: time.Now(), Data: data}:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+func (p *Publisher) run(ctx context.Context) {
+	defer p.sender.close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event := <-p.queue:
+			p.publish(ctx, p.batch(event))
+		}
+	}
+}
+
+// batch returns the event with the further queued events
+func (p *Publisher) batch(event *Event) []*Event {
+	batch := []*Event{event}
+
+	for len(batch) < maxBatchSize {
+		select {
+		case event := <-p.queue:
+			batch = append(batch, event)
+		default:
+			return batch
+		}
+	}
+
+	return batch
+}
+
+func (p *Publisher) publish(ctx context.Context, batch []*Event) {
+	err := p.sender.send(ctx, batch)
+
+	switch {
+	case err != nil && !p.failing:
+		logger().Warnf("can't publish %d events, further errors are logged at debug level: %s", len(batch), err)
+
+		p.failing = true
+
+	case err != nil:
+		logger().Debugf("can't publish %d events: %s", len(batch), err)
+
+	case p.failing:
+		logger().Info("publishing events again")
+
+		p.failing = false
+	}
+
+	if dropped := p.dropped.Swap(0); dropped > 0 {
+		logger().Warnf("event queue is full, dropped %d events", dropped)
+	}
+}
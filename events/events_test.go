@@ -0,0 +1,125 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/lists"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Publisher", func() {
+	var (
+		cfg      config.Events
+		received chan *Event
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		received = make(chan *Event, 100)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body kafkaProduceRequest
+
+			Expect(json.NewDecoder(r.Body).Decode(&body)).Should(Succeed())
+
+			for _, record := range body.Records {
+				received <- record.Value
+			}
+
+			_, _ = w.Write([]byte(`{"offsets":[]}`))
+		}))
+		DeferCleanup(server.Close)
+
+		cfg = config.Events{
+			Type:      config.EventsTypeKafkaRest,
+			URL:       server.URL,
+			Topic:     "blocky",
+			Events:    config.EventTypeValues(),
+			QueueSize: 100,
+		}
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+	})
+
+	It("should be nil if disabled", func() {
+		Expect(Start(ctx, config.Events{})).Should(BeNil())
+	})
+
+	It("should publish the events of the bus", func() {
+		_, err := Start(ctx, cfg)
+		Expect(err).Should(Succeed())
+
+		evt.Bus().Publish(evt.BlockingQueryBlocked, net.ParseIP("192.168.178.25"), []string{"laptop"},
+			"example.com", "BLOCKED (ads)")
+
+		var event *Event
+
+		Eventually(received).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(config.EventTypeQueryBlocked))
+		Expect(event.Data).Should(Equal(map[string]any{
+			"client_ip":    "192.168.178.25",
+			"client_names": []any{"laptop"},
+			"domain":       "example.com",
+			"reason":       "BLOCKED (ads)",
+		}))
+
+		evt.Bus().Publish(evt.BlockingCacheGroupChanged, lists.ListCacheTypeDenylist, "ads", 42)
+
+		Eventually(received).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(config.EventTypeListRefreshed))
+		Expect(event.Data).Should(HaveKeyWithValue("count", BeNumerically("==", 42)))
+
+		evt.Bus().Publish(evt.UpstreamHealthChanged, "tcp+udp:1.1.1.1", false)
+
+		Eventually(received).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(config.EventTypeUpstreamHealthChanged))
+		Expect(event.Data).Should(HaveKeyWithValue("healthy", false))
+	})
+
+	It("should publish the configured events only", func() {
+		cfg.Events = []config.EventType{config.EventTypeBlockingChanged}
+
+		_, err := Start(ctx, cfg)
+		Expect(err).Should(Succeed())
+
+		evt.Bus().Publish(evt.CachingFailedDownloadChanged, "https://example.com/list.txt")
+		evt.Bus().Publish(evt.BlockingEnabledEvent, false)
+
+		var event *Event
+
+		Eventually(received).Should(Receive(&event))
+		Expect(event.Type).Should(Equal(config.EventTypeBlockingChanged))
+		Consistently(received).ShouldNot(Receive())
+	})
+
+	It("should stop publishing when the context is done", func() {
+		_, err := Start(ctx, cfg)
+		Expect(err).Should(Succeed())
+
+		cancelFn()
+
+		evt.Bus().Publish(evt.BlockingEnabledEvent, true)
+
+		Consistently(received).ShouldNot(Receive())
+	})
+
+	It("should drop events if the queue is full", func() {
+		p := &Publisher{cfg: cfg, queue: make(chan *Event, 1), ctx: ctx}
+
+		p.enqueue(config.EventTypeBlockingChanged, nil)
+		p.enqueue(config.EventTypeBlockingChanged, nil)
+
+		Expect(p.queue).Should(HaveLen(1))
+		Expect(p.dropped.Load()).Should(BeNumerically("==", 1))
+	})
+})
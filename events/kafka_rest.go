@@ -0,0 +1,122 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+)
+
+const (
+	kafkaContentType = "application/vnd.kafka.json.v2+json"
+	kafkaAccept      = "application/vnd.kafka.v2+json"
+	kafkaTimeout     = 10 * time.Second
+	kafkaMaxErrorLen = 256
+)
+
+// kafkaRestSender produces the events through a Kafka REST proxy (API v2), the event type is the key of the records.
+// It doesn't speak the Kafka protocol, so it can't connect to the brokers directly.
+type kafkaRestSender struct {
+	cfg    config.Events
+	url    string
+	client *http.Client
+}
+
+type kafkaRecord struct {
+	Key   config.EventType `json:"key"`
+	Value *Event           `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Error string `json:"error"`
+	} `json:"offsets"`
+}
+
+func newKafkaRestSender(cfg config.Events) (*kafkaRestSender, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid Kafka REST proxy URL: %s", cfg.URL)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.TLS != nil {
+		transport.TLSClientConfig, err = cfg.TLS.NewClientTLSConfig("")
+		if err != nil {
+			return nil, fmt.Errorf("can't create Kafka TLS config: %w", err)
+		}
+	}
+
+	return &kafkaRestSender{
+		cfg:    cfg,
+		url:    strings.TrimSuffix(cfg.URL, "/") + "/topics/" + url.PathEscape(cfg.Topic),
+		client: &http.Client{Transport: transport, Timeout: kafkaTimeout},
+	}, nil
+}
+
+func (s *kafkaRestSender) send(ctx context.Context, events []*Event) error {
+	produce := kafkaProduceRequest{Records: make([]kafkaRecord, 0, len(events))}
+
+	for _, event := range events {
+		produce.Records = append(produce.Records, kafkaRecord{Key: event.Type, Value: event})
+	}
+
+	body, err := json.Marshal(produce)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", kafkaAccept)
+
+	if s.cfg.Username != "" {
+		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, kafkaMaxErrorLen))
+
+		return fmt.Errorf("REST proxy returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var result kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid REST proxy response: %w", err)
+	}
+
+	for _, offset := range result.Offsets {
+		if offset.Error != "" {
+			return fmt.Errorf("can't produce record: %s", offset.Error)
+		}
+	}
+
+	return nil
+}
+
+func (s *kafkaRestSender) close() {
+	s.client.CloseIdleConnections()
+}
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Kafka REST sender", func() {
+	var (
+		cfg      config.Events
+		server   *httptest.Server
+		requests chan *http.Request
+		bodies   chan kafkaProduceRequest
+		response string
+		status   int
+	)
+
+	BeforeEach(func() {
+		requests = make(chan *http.Request, 10)
+		bodies = make(chan kafkaProduceRequest, 10)
+		response = `{"offsets":[{"partition":0,"offset":1}]}`
+		status = http.StatusOK
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body kafkaProduceRequest
+
+			Expect(json.NewDecoder(r.Body).Decode(&body)).Should(Succeed())
+
+			requests <- r
+			bodies <- body
+
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(response))
+		}))
+		DeferCleanup(server.Close)
+
+		cfg = config.Events{Type: config.EventsTypeKafkaRest, URL: server.URL + "/", Topic: "dns"}
+	})
+
+	send := func() error {
+		sut, err := newKafkaRestSender(cfg)
+		Expect(err).Should(Succeed())
+
+		DeferCleanup(sut.close)
+
+		return sut.send(context.Background(), []*Event{
+			{Type: config.EventTypeQueryBlocked, Time: time.Now(), Data: map[string]any{"domain": "example.com"}},
+			{Type: config.EventTypeBlockingChanged, Time: time.Now(), Data: map[string]any{"enabled": false}},
+		})
+	}
+
+	It("should produce the events to the topic", func() {
+		cfg.Username = "user"
+		cfg.Password = "pass"
+
+		Expect(send()).Should(Succeed())
+
+		req := <-requests
+		Expect(req.URL.Path).Should(Equal("/topics/dns"))
+		Expect(req.Header.Get("Content-Type")).Should(Equal(kafkaContentType))
+
+		user, pass, ok := req.BasicAuth()
+		Expect(ok).Should(BeTrue())
+		Expect(user).Should(Equal("user"))
+		Expect(pass).Should(Equal("pass"))
+
+		body := <-bodies
+		Expect(body.Records).Should(HaveLen(2))
+		Expect(body.Records[0].Key).Should(Equal(config.EventTypeQueryBlocked))
+		Expect(body.Records[0].Value.Data).Should(HaveKeyWithValue("domain", "example.com"))
+		Expect(body.Records[1].Key).Should(Equal(config.EventTypeBlockingChanged))
+	})
+
+	It("should fail if the REST proxy returns an error", func() {
+		status = http.StatusNotFound
+		response = `{"error_code":40401,"message":"Topic not found."}`
+
+		Expect(send()).Should(MatchError(ContainSubstring("Topic not found")))
+	})
+
+	It("should fail if a record is rejected", func() {
+		response = `{"offsets":[{"partition":null,"offset":null,"error_code":1,"error":"record too large"}]}`
+
+		Expect(send()).Should(MatchError(ContainSubstring("record too large")))
+	})
+
+	It("should reject URLs which are not HTTP", func() {
+		cfg.URL = "tcp://localhost:9092"
+
+		_, err := newKafkaRestSender(cfg)
+		Expect(err).Should(HaveOccurred())
+	})
+})
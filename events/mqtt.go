@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	// MQTT 3.1.1, without the fallback to 3.1
+	mqttProtocolVersion = 4
+
+	mqttKeepAlive = 60 * time.Second
+	mqttTimeout   = 10 * time.Second
+	// time to finish the pending work on disconnect, in milliseconds
+	mqttQuiesce = 250
+
+	mqttDefaultPort    = "1883"
+	mqttDefaultTLSPort = "8883"
+)
+
+// mqttSender publishes the events with QoS 0 to the topic `<topic>/<event type>`
+type mqttSender struct {
+	cfg    config.Events
+	broker string
+	tls    bool
+
+	client mqtt.Client
+}
+
+func newMQTTSender(cfg config.Events) (*mqttSender, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MQTT broker URL: %w", err)
+	}
+
+	opts := mqtt.NewClientOptions().
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetProtocolVersion(mqttProtocolVersion).
+		SetCleanSession(true).
+		SetKeepAlive(mqttKeepAlive).
+		SetConnectTimeout(mqttTimeout).
+		SetWriteTimeout(mqttTimeout).
+		// the connection is opened again by the next send
+		SetAutoReconnect(false)
+
+	s := &mqttSender{cfg: cfg}
+
+	port := mqttDefaultPort
+
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		port = mqttDefaultTLSPort
+
+		policy := config.TLSPolicy{}
+		if cfg.TLS != nil {
+			policy = *cfg.TLS
+		}
+
+		tlsCfg, err := policy.NewClientTLSConfig(u.Hostname())
+		if err != nil {
+			return nil, fmt.Errorf("can't create MQTT TLS config: %w", err)
+		}
+
+		opts.SetTLSConfig(tlsCfg)
+
+		s.tls = true
+
+	default:
+		return nil, fmt.Errorf("unsupported MQTT broker URL scheme: %s", u.Scheme)
+	}
+
+	if u.Port() != "" {
+		port = u.Port()
+	}
+
+	s.broker = u.Scheme + "://" + net.JoinHostPort(u.Hostname(), port)
+	s.client = mqtt.NewClient(opts.AddBroker(s.broker))
+
+	return s, nil
+}
+
+func (s *mqttSender) send(ctx context.Context, events []*Event) error {
+	if !s.client.IsConnectionOpen() {
+		if err := waitMQTT(ctx, s.client.Connect()); err != nil {
+			return fmt.Errorf("can't connect to MQTT broker %s: %w", s.broker, err)
+		}
+	}
+
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		token := s.client.Publish(s.cfg.Topic+"/"+string(event.Type), 0, false, payload)
+		if err := waitMQTT(ctx, token); err != nil {
+			return fmt.Errorf("can't publish to %s: %w", s.broker, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *mqttSender) close() {
+	if s.client.IsConnected() {
+		s.client.Disconnect(mqttQuiesce)
+	}
+}
+
+// waitMQTT waits for the completion of the token, at most until the timeout or the end of ctx
+func waitMQTT(ctx context.Context, token mqtt.Token) error {
+	ctx, cancel := context.WithTimeout(ctx, mqttTimeout)
+	defer cancel()
+
+	select {
+	case <-token.Done():
+		return token.Error()
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
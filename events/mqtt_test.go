@@ -0,0 +1,146 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// startMQTTBroker accepts one connection, acknowledges it with the return code and passes the received packets
+func startMQTTBroker(returnCode byte) (string, <-chan packets.ControlPacket) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).Should(Succeed())
+
+	DeferCleanup(listener.Close)
+
+	received := make(chan packets.ControlPacket, 100)
+
+	go func() {
+		defer GinkgoRecover()
+
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		for {
+			packet, err := packets.ReadPacket(conn)
+			if err != nil {
+				close(received)
+
+				return
+			}
+
+			received <- packet
+
+			switch packet.(type) {
+			case *packets.ConnectPacket:
+				connAck := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+				connAck.ReturnCode = returnCode
+				_ = connAck.Write(conn)
+
+			case *packets.DisconnectPacket:
+				close(received)
+
+				return
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+var _ = Describe("MQTT sender", func() {
+	var (
+		cfg config.Events
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		cfg = config.Events{Type: config.EventsTypeMqtt, Topic: "blocky", ClientID: "test"}
+
+		var cancelFn context.CancelFunc
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+	})
+
+	event := &Event{Type: config.EventTypeQueryBlocked, Time: time.Now(), Data: map[string]any{"domain": "example.com"}}
+
+	It("should connect and publish the events", func() {
+		addr, received := startMQTTBroker(0)
+		cfg.URL = "tcp://" + addr
+		cfg.Username = "user"
+		cfg.Password = "pass"
+
+		sut, err := newMQTTSender(cfg)
+		Expect(err).Should(Succeed())
+
+		Expect(sut.send(ctx, []*Event{event})).Should(Succeed())
+
+		connect, ok := (<-received).(*packets.ConnectPacket)
+		Expect(ok).Should(BeTrue())
+		Expect(connect.ProtocolName).Should(Equal("MQTT"))
+		Expect(connect.CleanSession).Should(BeTrue())
+		Expect(connect.ClientIdentifier).Should(Equal("test"))
+		Expect(connect.Username).Should(Equal("user"))
+		Expect(connect.Password).Should(Equal([]byte("pass")))
+
+		publish, ok := (<-received).(*packets.PublishPacket)
+		Expect(ok).Should(BeTrue())
+		Expect(publish.TopicName).Should(Equal("blocky/queryBlocked"))
+		Expect(publish.Qos).Should(BeZero())
+
+		var event Event
+
+		Expect(json.Unmarshal(publish.Payload, &event)).Should(Succeed())
+		Expect(event.Type).Should(Equal(config.EventTypeQueryBlocked))
+		Expect(event.Data).Should(HaveKeyWithValue("domain", "example.com"))
+
+		sut.close()
+
+		Expect(<-received).Should(BeAssignableToTypeOf(&packets.DisconnectPacket{}))
+	})
+
+	It("should fail if the broker refuses the connection", func() {
+		addr, _ := startMQTTBroker(5)
+		cfg.URL = "mqtt://" + addr
+
+		sut, err := newMQTTSender(cfg)
+		Expect(err).Should(Succeed())
+
+		Expect(sut.send(ctx, []*Event{event})).Should(MatchError(packets.ErrorRefusedNotAuthorised))
+	})
+
+	It("should use the default ports", func() {
+		cfg.URL = "tcp://broker"
+
+		sut, err := newMQTTSender(cfg)
+		Expect(err).Should(Succeed())
+		Expect(sut.broker).Should(Equal("tcp://broker:1883"))
+		Expect(sut.tls).Should(BeFalse())
+
+		cfg.URL = "mqtts://broker"
+
+		sut, err = newMQTTSender(cfg)
+		Expect(err).Should(Succeed())
+		Expect(sut.broker).Should(Equal("mqtts://broker:8883"))
+		Expect(sut.tls).Should(BeTrue())
+	})
+
+	It("should reject unknown schemes", func() {
+		cfg.URL = "http://broker"
+
+		_, err := newMQTTSender(cfg)
+		Expect(err).Should(HaveOccurred())
+	})
+})
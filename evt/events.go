@@ -11,6 +11,36 @@ const (
 	// BlockingCacheGroupChanged fires, if a list group is changed. Parameter: list type, group name, element count
 	BlockingCacheGroupChanged = "blocking:cachingGroupChanged"
 
+	// BlockingGroupHit fires if a query is blocked by a denylist group. Parameter: group name
+	BlockingGroupHit = "blocking:groupHit"
+
+	// BlockingCategoryHit fires if a query is blocked by denylist groups of a category. Parameter: category
+	BlockingCategoryHit = "blocking:categoryHit"
+
+	// BlockingFeedStale fires if a feed was not refreshed within its max age, or is refreshed again.
+	// Parameter: feed name, true if stale
+	BlockingFeedStale = "blocking:feedStale"
+
+	// BlockingQueryBlocked fires if a query is blocked. Parameter: client IP, client names, domain, reason
+	BlockingQueryBlocked = "blocking:queryBlocked"
+
+	// NewDomainDetected fires if a query for a newly observed or registered domain is flagged.
+	// Parameter: kind (observed or registered)
+	NewDomainDetected = "newDomains:detected"
+
+	// TunnelingSuspected fires if a client is suspected of DNS tunneling through a zone. Parameter: client IP, zone
+	TunnelingSuspected = "tunneling:suspected"
+
+	// UpstreamQueried fires after a query to an upstream server. Parameter: upstream name, duration, true on error
+	UpstreamQueried = "upstream:queried"
+
+	// UpstreamHealthChanged fires if an upstream becomes unhealthy or healthy again. Parameter: upstream name, healthy
+	UpstreamHealthChanged = "upstream:healthChanged"
+
+	// UpstreamGroupHealthChanged fires if all upstreams of a group become unhealthy or one is healthy again.
+	// Parameter: group name, healthy
+	UpstreamGroupHealthChanged = "upstream:groupHealthChanged"
+
 	// CachingDomainPrefetched fires if a domain will be prefetched, Parameter: domain name
 	CachingDomainPrefetched = "caching:prefetched"
 
@@ -26,6 +56,17 @@ const (
 	// CachingFailedDownloadChanged fires, if a download of a blocking list or hosts file fails
 	CachingFailedDownloadChanged = "caching:failedDownload"
 
+	// WatchdogAlarm fires if the watchdog detects a possible leak. Parameter: resource name, current count
+	WatchdogAlarm = "watchdog:alarm"
+
+	// RateLimited fires if a query of a client exceeds a rate limit.
+	// Parameter: limit name (queries, nxdomain, any, rrl, tunneling)
+	RateLimited = "server:rateLimited"
+
+	// CertificateExpiring fires once a day if the certificate of the TLS listeners expires soon.
+	// Parameter: domains, expiry time
+	CertificateExpiring = "server:certificateExpiring"
+
 	// ApplicationStarted fires on start of the application. Parameter: version number, build time
 	ApplicationStarted = "application:started"
 )
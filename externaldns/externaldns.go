@@ -0,0 +1,295 @@
+// Package externaldns implements the webhook provider API of Kubernetes ExternalDNS,
+// storing the records for the custom DNS resolver.
+package externaldns
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+
+	"github.com/miekg/dns"
+	"golang.org/x/exp/maps"
+)
+
+// Endpoint is a record set managed by ExternalDNS
+type Endpoint struct {
+	DNSName          string                     `json:"dnsName,omitempty"`
+	Targets          []string                   `json:"targets,omitempty"`
+	RecordType       string                     `json:"recordType,omitempty"`
+	SetIdentifier    string                     `json:"setIdentifier,omitempty"`
+	RecordTTL        int64                      `json:"recordTTL,omitempty"`
+	Labels           map[string]string          `json:"labels,omitempty"`
+	ProviderSpecific []ProviderSpecificProperty `json:"providerSpecific,omitempty"`
+}
+
+// ProviderSpecificProperty is a provider specific option of an endpoint, blocky has none
+type ProviderSpecificProperty struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// Changes are the endpoints to create, update and delete
+type Changes struct {
+	Create    []*Endpoint `json:"create,omitempty"`
+	UpdateOld []*Endpoint `json:"updateOld,omitempty"`
+	UpdateNew []*Endpoint `json:"updateNew,omitempty"`
+	Delete    []*Endpoint `json:"delete,omitempty"`
+}
+
+// DomainFilter are the domains ExternalDNS may manage records in
+type DomainFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// supported record types, ExternalDNS uses TXT records to store the owner of the other records
+//
+//nolint:gochecknoglobals
+var supportedTypes = []string{"A", "AAAA", "CNAME", "TXT", "SRV"}
+
+// Provider holds the endpoints created by ExternalDNS.
+// They are kept in memory only: after a restart, ExternalDNS creates them again with its next synchronization.
+type Provider struct {
+	cfg        config.ExternalDNS
+	defaultTTL uint32
+
+	lock      sync.Mutex
+	endpoints map[string]*Endpoint
+	onChange  func(config.CustomDNSMapping)
+}
+
+// NewProvider creates a provider without endpoints, `defaultTTL` is used for endpoints without TTL
+func NewProvider(cfg config.ExternalDNS, defaultTTL config.Duration) *Provider {
+	return &Provider{
+		cfg:        cfg,
+		defaultTTL: defaultTTL.SecondsU32(),
+		endpoints:  make(map[string]*Endpoint),
+	}
+}
+
+// Name implements `resolver.CustomDNSSource`.
+func (p *Provider) Name() string {
+	return "ExternalDNS"
+}
+
+// OnChange registers the function which receives the records of the endpoints after each change
+func (p *Provider) OnChange(onChange func(config.CustomDNSMapping)) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.onChange = onChange
+
+	onChange(p.mapping())
+}
+
+// DomainFilter returns the domains of the configuration
+func (p *Provider) DomainFilter() DomainFilter {
+	return DomainFilter{Include: p.cfg.Domains}
+}
+
+// Records returns all endpoints
+func (p *Provider) Records() []*Endpoint {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	keys := maps.Keys(p.endpoints)
+	slices.Sort(keys)
+
+	result := make([]*Endpoint, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, p.endpoints[key])
+	}
+
+	return result
+}
+
+// AdjustEndpoints normalizes the names of the endpoints and drops the endpoints blocky can't store,
+// so ExternalDNS doesn't try to create them over and over again
+func (p *Provider) AdjustEndpoints(endpoints []*Endpoint) []*Endpoint {
+	logger := log.PrefixedLog("externalDNS")
+
+	result := make([]*Endpoint, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		endpoint.DNSName = normalize(endpoint.DNSName)
+
+		if err := p.validate(endpoint); err != nil {
+			logger.WithError(err).Debug("ignoring endpoint")
+
+			continue
+		}
+
+		result = append(result, endpoint)
+	}
+
+	return result
+}
+
+// ApplyChanges deletes, updates and creates the endpoints.
+// No change is applied if one of the endpoints is invalid. Deleting unknown endpoints is not an error.
+func (p *Provider) ApplyChanges(changes Changes) error {
+	for _, endpoint := range slices.Concat(changes.Create, changes.UpdateNew) {
+		if err := p.validate(endpoint); err != nil {
+			return err
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, endpoint := range slices.Concat(changes.Delete, changes.UpdateOld) {
+		delete(p.endpoints, key(endpoint))
+	}
+
+	for _, endpoint := range slices.Concat(changes.UpdateNew, changes.Create) {
+		p.endpoints[key(endpoint)] = endpoint
+	}
+
+	log.PrefixedLog("externalDNS").Debugf("applied changes: %d created, %d updated, %d deleted, %d endpoints",
+		len(changes.Create), len(changes.UpdateNew), len(changes.Delete), len(p.endpoints))
+
+	if p.onChange != nil {
+		p.onChange(p.mapping())
+	}
+
+	return nil
+}
+
+func (p *Provider) validate(endpoint *Endpoint) error {
+	name := normalize(endpoint.DNSName)
+
+	switch {
+	case name == "":
+		return fmt.Errorf("endpoint without name")
+	case !slices.Contains(supportedTypes, endpoint.RecordType):
+		return fmt.Errorf("%s: unsupported record type '%s'", name, endpoint.RecordType)
+	case !p.inDomains(name):
+		return fmt.Errorf("%s: not in the configured domains", name)
+	}
+
+	_, err := toRRs(endpoint, 0)
+
+	return err
+}
+
+func (p *Provider) inDomains(name string) bool {
+	if len(p.cfg.Domains) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(p.cfg.Domains, func(domain string) bool {
+		return name == domain || strings.HasSuffix(name, "."+domain)
+	})
+}
+
+// mapping returns the records of all endpoints, the lock must be held
+func (p *Provider) mapping() config.CustomDNSMapping {
+	result := make(config.CustomDNSMapping, len(p.endpoints))
+
+	for _, endpoint := range p.endpoints {
+		ttl := p.defaultTTL
+		if endpoint.RecordTTL > 0 {
+			ttl = uint32(endpoint.RecordTTL)
+		}
+
+		// endpoints are validated before they are stored
+		rrs, _ := toRRs(endpoint, ttl)
+
+		name := normalize(endpoint.DNSName)
+		result[name] = append(result[name], rrs...)
+	}
+
+	return result
+}
+
+func toRRs(endpoint *Endpoint, ttl uint32) ([]dns.RR, error) {
+	name := normalize(endpoint.DNSName)
+
+	if len(endpoint.Targets) == 0 {
+		return nil, fmt.Errorf("%s: %s endpoint without targets", name, endpoint.RecordType)
+	}
+
+	result := make([]dns.RR, 0, len(endpoint.Targets))
+
+	for _, target := range endpoint.Targets {
+		hdr := dns.RR_Header{Name: dns.Fqdn(name), Class: dns.ClassINET, Ttl: ttl}
+
+		rr, err := toRR(hdr, endpoint.RecordType, target)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid %s target '%s': %w", name, endpoint.RecordType, target, err)
+		}
+
+		result = append(result, rr)
+	}
+
+	if endpoint.RecordType == "CNAME" && len(result) > 1 {
+		return nil, fmt.Errorf("%s: CNAME endpoint with multiple targets", name)
+	}
+
+	return result, nil
+}
+
+func toRR(hdr dns.RR_Header, recordType, target string) (dns.RR, error) {
+	switch recordType {
+	case "A", "AAAA":
+		ip := net.ParseIP(target)
+		if ip == nil || (ip.To4() != nil) != (recordType == "A") {
+			return nil, fmt.Errorf("no IPv4/IPv6 address of the record type")
+		}
+
+		if recordType == "A" {
+			hdr.Rrtype = dns.TypeA
+
+			return &dns.A{Hdr: hdr, A: ip.To4()}, nil
+		}
+
+		hdr.Rrtype = dns.TypeAAAA
+
+		return &dns.AAAA{Hdr: hdr, AAAA: ip}, nil
+
+	case "CNAME":
+		hdr.Rrtype = dns.TypeCNAME
+
+		return &dns.CNAME{Hdr: hdr, Target: dns.Fqdn(normalize(target))}, nil
+
+	case "TXT":
+		hdr.Rrtype = dns.TypeTXT
+
+		// ExternalDNS quotes the values of its ownership records
+		if unquoted, err := strconv.Unquote(target); err == nil {
+			target = unquoted
+		}
+
+		return &dns.TXT{Hdr: hdr, Txt: []string{target}}, nil
+
+	case "SRV":
+		var srv dns.SRV
+
+		// priority weight port target
+		if _, err := fmt.Sscanf(target, "%d %d %d %s", &srv.Priority, &srv.Weight, &srv.Port, &srv.Target); err != nil {
+			return nil, err
+		}
+
+		hdr.Rrtype = dns.TypeSRV
+		srv.Hdr = hdr
+		srv.Target = dns.Fqdn(srv.Target)
+
+		return &srv, nil
+	}
+
+	return nil, fmt.Errorf("unsupported record type")
+}
+
+func key(endpoint *Endpoint) string {
+	return strings.Join([]string{normalize(endpoint.DNSName), endpoint.RecordType, endpoint.SetIdentifier}, "/")
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
@@ -0,0 +1,181 @@
+package externaldns
+
+import (
+	"net"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/miekg/dns"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Provider", func() {
+	var (
+		sut      *Provider
+		cfg      config.ExternalDNS
+		mappings []config.CustomDNSMapping
+	)
+
+	BeforeEach(func() {
+		cfg = config.ExternalDNS{Enable: true, Domains: []string{"k8s.lan"}}
+		mappings = nil
+	})
+
+	JustBeforeEach(func() {
+		sut = NewProvider(cfg, config.Duration(time.Hour))
+		sut.OnChange(func(mapping config.CustomDNSMapping) {
+			mappings = append(mappings, mapping)
+		})
+	})
+
+	lastMapping := func() config.CustomDNSMapping {
+		return mappings[len(mappings)-1]
+	}
+
+	It("passes the empty mapping on registration", func() {
+		Expect(mappings).Should(Equal([]config.CustomDNSMapping{{}}))
+	})
+
+	Describe("ApplyChanges", func() {
+		It("creates the records of the endpoints", func() {
+			Expect(sut.ApplyChanges(Changes{Create: []*Endpoint{
+				{DNSName: "App.k8s.lan.", RecordType: "A", Targets: []string{"10.0.0.1", "10.0.0.2"}, RecordTTL: 60},
+				{DNSName: "app.k8s.lan", RecordType: "AAAA", Targets: []string{"fd00::1"}},
+				{DNSName: "www.k8s.lan", RecordType: "CNAME", Targets: []string{"app.k8s.lan"}},
+				{DNSName: "a-app.k8s.lan", RecordType: "TXT", Targets: []string{`"heritage=external-dns,external-dns/owner=default"`}},
+				{DNSName: "_http._tcp.k8s.lan", RecordType: "SRV", Targets: []string{"0 5 80 app.k8s.lan"}},
+			}})).Should(Succeed())
+
+			mapping := lastMapping()
+			Expect(mapping).Should(HaveLen(4))
+			Expect(mapping["app.k8s.lan"]).Should(ConsistOf(
+				&dns.A{
+					Hdr: dns.RR_Header{Name: "app.k8s.lan.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+					A:   net.ParseIP("10.0.0.1").To4(),
+				},
+				&dns.A{
+					Hdr: dns.RR_Header{Name: "app.k8s.lan.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+					A:   net.ParseIP("10.0.0.2").To4(),
+				},
+				&dns.AAAA{
+					Hdr:  dns.RR_Header{Name: "app.k8s.lan.", Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: 3600},
+					AAAA: net.ParseIP("fd00::1"),
+				},
+			))
+			Expect(mapping["www.k8s.lan"]).Should(ConsistOf(
+				&dns.CNAME{
+					Hdr:    dns.RR_Header{Name: "www.k8s.lan.", Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 3600},
+					Target: "app.k8s.lan.",
+				},
+			))
+			Expect(mapping["a-app.k8s.lan"]).Should(ConsistOf(
+				&dns.TXT{
+					Hdr: dns.RR_Header{Name: "a-app.k8s.lan.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 3600},
+					Txt: []string{"heritage=external-dns,external-dns/owner=default"},
+				},
+			))
+			Expect(mapping["_http._tcp.k8s.lan"]).Should(ConsistOf(
+				&dns.SRV{
+					Hdr:      dns.RR_Header{Name: "_http._tcp.k8s.lan.", Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: 3600},
+					Priority: 0, Weight: 5, Port: 80, Target: "app.k8s.lan.",
+				},
+			))
+		})
+
+		It("updates and deletes endpoints", func() {
+			app := &Endpoint{DNSName: "app.k8s.lan", RecordType: "A", Targets: []string{"10.0.0.1"}}
+			owner := &Endpoint{DNSName: "a-app.k8s.lan", RecordType: "TXT", Targets: []string{`"owner"`}}
+
+			Expect(sut.ApplyChanges(Changes{Create: []*Endpoint{app, owner}})).Should(Succeed())
+
+			moved := &Endpoint{DNSName: "app.k8s.lan", RecordType: "A", Targets: []string{"10.0.0.3"}}
+
+			Expect(sut.ApplyChanges(Changes{UpdateOld: []*Endpoint{app}, UpdateNew: []*Endpoint{moved}})).Should(Succeed())
+			Expect(sut.Records()).Should(Equal([]*Endpoint{owner, moved}))
+
+			Expect(sut.ApplyChanges(Changes{Delete: []*Endpoint{moved, owner}})).Should(Succeed())
+			Expect(sut.Records()).Should(BeEmpty())
+			Expect(lastMapping()).Should(BeEmpty())
+		})
+
+		It("ignores the deletion of unknown endpoints", func() {
+			Expect(sut.ApplyChanges(Changes{Delete: []*Endpoint{
+				{DNSName: "unknown.k8s.lan", RecordType: "A", Targets: []string{"10.0.0.1"}},
+			}})).Should(Succeed())
+		})
+
+		It("distinguishes endpoints by set identifier", func() {
+			Expect(sut.ApplyChanges(Changes{Create: []*Endpoint{
+				{DNSName: "app.k8s.lan", RecordType: "A", Targets: []string{"10.0.0.1"}, SetIdentifier: "a"},
+				{DNSName: "app.k8s.lan", RecordType: "A", Targets: []string{"10.0.0.2"}, SetIdentifier: "b"},
+			}})).Should(Succeed())
+
+			Expect(sut.Records()).Should(HaveLen(2))
+			Expect(lastMapping()["app.k8s.lan"]).Should(HaveLen(2))
+		})
+
+		DescribeTable("rejects invalid endpoints without applying any change",
+			func(endpoint *Endpoint, expectedErr string) {
+				valid := &Endpoint{DNSName: "app.k8s.lan", RecordType: "A", Targets: []string{"10.0.0.1"}}
+
+				err := sut.ApplyChanges(Changes{Create: []*Endpoint{valid, endpoint}})
+				Expect(err).Should(MatchError(ContainSubstring(expectedErr)))
+
+				Expect(sut.Records()).Should(BeEmpty())
+				Expect(mappings).Should(HaveLen(1))
+			},
+			Entry("without name",
+				&Endpoint{RecordType: "A", Targets: []string{"10.0.0.1"}}, "endpoint without name"),
+			Entry("unsupported type",
+				&Endpoint{DNSName: "mx.k8s.lan", RecordType: "MX", Targets: []string{"10 mail"}}, "unsupported record type 'MX'"),
+			Entry("outside the domains",
+				&Endpoint{DNSName: "app.other.lan", RecordType: "A", Targets: []string{"10.0.0.1"}}, "not in the configured domains"),
+			Entry("similar domain",
+				&Endpoint{DNSName: "appk8s.lan", RecordType: "A", Targets: []string{"10.0.0.1"}}, "not in the configured domains"),
+			Entry("without targets",
+				&Endpoint{DNSName: "app.k8s.lan", RecordType: "A"}, "A endpoint without targets"),
+			Entry("IPv6 address of A endpoint",
+				&Endpoint{DNSName: "app.k8s.lan", RecordType: "A", Targets: []string{"fd00::1"}}, "invalid A target 'fd00::1'"),
+			Entry("IPv4 address of AAAA endpoint",
+				&Endpoint{DNSName: "app.k8s.lan", RecordType: "AAAA", Targets: []string{"10.0.0.1"}}, "invalid AAAA target"),
+			Entry("multiple CNAME targets",
+				&Endpoint{DNSName: "www.k8s.lan", RecordType: "CNAME", Targets: []string{"a.k8s.lan", "b.k8s.lan"}},
+				"CNAME endpoint with multiple targets"),
+			Entry("invalid SRV target",
+				&Endpoint{DNSName: "_http._tcp.k8s.lan", RecordType: "SRV", Targets: []string{"app.k8s.lan"}},
+				"invalid SRV target"),
+		)
+
+		When("no domains are configured", func() {
+			BeforeEach(func() {
+				cfg.Domains = nil
+			})
+
+			It("accepts endpoints of all domains", func() {
+				Expect(sut.ApplyChanges(Changes{Create: []*Endpoint{
+					{DNSName: "app.other.lan", RecordType: "A", Targets: []string{"10.0.0.1"}},
+				}})).Should(Succeed())
+			})
+		})
+	})
+
+	Describe("AdjustEndpoints", func() {
+		It("normalizes names and drops unsupported endpoints", func() {
+			Expect(sut.AdjustEndpoints([]*Endpoint{
+				{DNSName: "App.k8s.lan.", RecordType: "A", Targets: []string{"10.0.0.1"}},
+				{DNSName: "mx.k8s.lan", RecordType: "MX", Targets: []string{"10 mail"}},
+				{DNSName: "app.other.lan", RecordType: "A", Targets: []string{"10.0.0.1"}},
+			})).Should(Equal([]*Endpoint{
+				{DNSName: "app.k8s.lan", RecordType: "A", Targets: []string{"10.0.0.1"}},
+			}))
+		})
+	})
+
+	Describe("DomainFilter", func() {
+		It("returns the configured domains", func() {
+			Expect(sut.DomainFilter()).Should(Equal(DomainFilter{Include: []string{"k8s.lan"}}))
+		})
+	})
+})
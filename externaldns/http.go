@@ -0,0 +1,71 @@
+package externaldns
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/0xERR0R/blocky/log"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// media type of the ExternalDNS webhook API
+	mediaType = "application/external.dns.webhook+json;version=1"
+
+	contentTypeHeader = "Content-Type"
+)
+
+// Handler returns the webhook API: ExternalDNS negotiates the domain filter via `GET /`,
+// reads and changes the records via `/records` and adjusts its endpoints via `POST /adjustendpoints`
+func (p *Provider) Handler() http.Handler {
+	router := chi.NewRouter()
+
+	router.Get("/", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, p.DomainFilter())
+	})
+
+	router.Get("/records", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, p.Records())
+	})
+
+	router.Post("/records", func(w http.ResponseWriter, r *http.Request) {
+		var changes Changes
+		if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+			http.Error(w, "invalid changes: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		if err := p.ApplyChanges(changes); err != nil {
+			log.PrefixedLog("externalDNS").WithError(err).Warn("can't apply changes")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	router.Post("/adjustendpoints", func(w http.ResponseWriter, r *http.Request) {
+		var endpoints []*Endpoint
+		if err := json.NewDecoder(r.Body).Decode(&endpoints); err != nil {
+			http.Error(w, "invalid endpoints: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		writeJSON(w, p.AdjustEndpoints(endpoints))
+	})
+
+	return router
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set(contentTypeHeader, mediaType)
+	w.Header().Set("Vary", contentTypeHeader)
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.PrefixedLog("externalDNS").WithError(err).Warn("can't write response")
+	}
+}
@@ -0,0 +1,88 @@
+package externaldns
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		sut      *Provider
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		sut = NewProvider(config.ExternalDNS{Enable: true, Domains: []string{"k8s.lan"}}, config.Duration(time.Hour))
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Accept", mediaType)
+
+		sut.Handler().ServeHTTP(recorder, req)
+	}
+
+	It("negotiates the domain filter", func() {
+		serve(http.MethodGet, "/", "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).Should(Equal(mediaType))
+		Expect(recorder.Body.String()).Should(MatchJSON(`{"include": ["k8s.lan"]}`))
+	})
+
+	It("applies changes and returns the records", func() {
+		serve(http.MethodPost, "/records", `{
+			"create": [{"dnsName": "app.k8s.lan", "recordType": "A", "targets": ["10.0.0.1"],
+			            "labels": {"owner": "default"}}]
+		}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusNoContent))
+
+		recorder = httptest.NewRecorder()
+		serve(http.MethodGet, "/records", "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).Should(MatchJSON(`[
+			{"dnsName": "app.k8s.lan", "recordType": "A", "targets": ["10.0.0.1"], "labels": {"owner": "default"}}
+		]`))
+	})
+
+	It("rejects invalid changes", func() {
+		serve(http.MethodPost, "/records", `{"create": [{"dnsName": "app.k8s.lan", "recordType": "MX", "targets": ["mail"]}]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).Should(ContainSubstring("unsupported record type 'MX'"))
+	})
+
+	It("rejects invalid JSON", func() {
+		serve(http.MethodPost, "/records", `{`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+	})
+
+	It("adjusts endpoints", func() {
+		serve(http.MethodPost, "/adjustendpoints", `[
+			{"dnsName": "App.k8s.lan", "recordType": "A", "targets": ["10.0.0.1"]},
+			{"dnsName": "app.k8s.lan", "recordType": "NS", "targets": ["ns.k8s.lan"]}
+		]`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).Should(MatchJSON(`[
+			{"dnsName": "app.k8s.lan", "recordType": "A", "targets": ["10.0.0.1"]}
+		]`))
+	})
+
+	It("rejects invalid endpoints to adjust", func() {
+		serve(http.MethodPost, "/adjustendpoints", `{}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+	})
+})
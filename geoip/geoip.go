@@ -0,0 +1,267 @@
+// Package geoip looks up information about IP addresses in MMDB files of MaxMind or IPinfo.
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/sirupsen/logrus"
+)
+
+// DB is a set of opened IP databases, all lookups of a nil DB fail
+type DB struct {
+	asn     *database
+	country *database
+}
+
+// database is a MMDB file which is reopened when it changes
+type database struct {
+	name string
+	path string
+
+	lock    sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+	size    int64
+}
+
+// asnRecord contains the fields of MaxMind GeoLite2/GeoIP2 ASN and IPinfo ASN databases
+type asnRecord struct {
+	// MaxMind
+	Number uint `maxminddb:"autonomous_system_number"`
+	// IPinfo, e.g. "AS15169"
+	ASN string `maxminddb:"asn"`
+}
+
+// countryRecord contains the fields of MaxMind GeoLite2/GeoIP2 country and IPinfo country databases
+type countryRecord struct {
+	// MaxMind: a map with the "iso_code", IPinfo: the ISO code
+	Country any `maxminddb:"country"`
+}
+
+func logger() *logrus.Entry {
+	return log.PrefixedLog("geoip")
+}
+
+// Open opens the configured databases, returns nil if none is configured.
+// The files are checked for changes every `cfg.ReloadInterval` until ctx is done.
+func Open(ctx context.Context, cfg *config.GeoIP) (*DB, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil //nolint:nilnil
+	}
+
+	db := &DB{}
+
+	for _, d := range []struct {
+		name string
+		path string
+		dst  **database
+	}{
+		{"ASN", cfg.ASNDatabase, &db.asn},
+		{"country", cfg.CountryDatabase, &db.country},
+	} {
+		if d.path == "" {
+			continue
+		}
+
+		database := &database{name: d.name, path: d.path}
+		if err := database.open(); err != nil {
+			_ = db.Close()
+
+			return nil, err
+		}
+
+		*d.dst = database
+	}
+
+	if cfg.ReloadInterval > 0 {
+		go db.periodicReload(ctx, cfg.ReloadInterval.ToDuration())
+	}
+
+	return db, nil
+}
+
+// ASN returns the number of the autonomous system which announces the network of ip
+func (db *DB) ASN(ip net.IP) (uint, bool) {
+	if db == nil {
+		return 0, false
+	}
+
+	var record asnRecord
+	if !db.asn.lookup(ip, &record) {
+		return 0, false
+	}
+
+	if record.Number != 0 {
+		return record.Number, true
+	}
+
+	number, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(record.ASN), "AS"), 10, 32)
+	if err != nil || number == 0 {
+		return 0, false
+	}
+
+	return uint(number), true
+}
+
+// Country returns the upper case ISO 3166-1 code of the country where ip is located
+func (db *DB) Country(ip net.IP) (string, bool) {
+	if db == nil {
+		return "", false
+	}
+
+	var record countryRecord
+	if !db.country.lookup(ip, &record) {
+		return "", false
+	}
+
+	var code string
+
+	switch v := record.Country.(type) {
+	case string:
+		code = v
+	case map[string]any:
+		code, _ = v["iso_code"].(string)
+	}
+
+	if code == "" {
+		return "", false
+	}
+
+	return strings.ToUpper(code), true
+}
+
+// Close closes the databases
+func (db *DB) Close() error {
+	if db == nil {
+		return nil
+	}
+
+	for _, database := range []*database{db.asn, db.country} {
+		if database == nil {
+			continue
+		}
+
+		if err := database.close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *DB) periodicReload(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, database := range []*database{db.asn, db.country} {
+				if database != nil {
+					database.reloadIfChanged()
+				}
+			}
+
+		case <-ctx.Done():
+			_ = db.Close()
+
+			return
+		}
+	}
+}
+
+// open opens the file and replaces the current reader
+func (d *database) open() error {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return fmt.Errorf("can't open %s database: %w", d.name, err)
+	}
+
+	reader, err := maxminddb.Open(d.path)
+	if err != nil {
+		return fmt.Errorf("can't open %s database: %w", d.name, err)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	old := d.reader
+
+	d.reader = reader
+	d.modTime = info.ModTime()
+	d.size = info.Size()
+
+	if old != nil {
+		return old.Close()
+	}
+
+	return nil
+}
+
+// reloadIfChanged reopens the file if its modification time or size changed, the current reader is kept on errors
+func (d *database) reloadIfChanged() {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		logger().WithError(err).Warnf("can't check %s database", d.name)
+
+		return
+	}
+
+	d.lock.RLock()
+	changed := !info.ModTime().Equal(d.modTime) || info.Size() != d.size
+	d.lock.RUnlock()
+
+	if !changed {
+		return
+	}
+
+	if err := d.open(); err != nil {
+		logger().WithError(err).Warnf("can't reload %s database, keeping the previous one", d.name)
+
+		return
+	}
+
+	logger().Infof("reloaded %s database %s", d.name, d.path)
+}
+
+// lookup decodes the record of ip into result, returns false if there is none
+func (d *database) lookup(ip net.IP, result any) bool {
+	if d == nil {
+		return false
+	}
+
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	if d.reader == nil {
+		return false
+	}
+
+	network, found, err := d.reader.LookupNetwork(ip, result)
+
+	return err == nil && found && network != nil
+}
+
+func (d *database) close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.reader == nil {
+		return nil
+	}
+
+	err := d.reader.Close()
+	d.reader = nil
+
+	return err
+}
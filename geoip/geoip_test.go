@@ -0,0 +1,160 @@
+package geoip
+
+import (
+	"context"
+	"net"
+	"os"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GeoIP", func() {
+	var (
+		cfg config.GeoIP
+		ctx context.Context
+	)
+
+	open := func() *DB {
+		db, err := Open(ctx, &cfg)
+		Expect(err).Should(Succeed())
+		DeferCleanup(db.Close)
+
+		return db
+	}
+
+	BeforeEach(func() {
+		var cancelFn context.CancelFunc
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		cfg = config.GeoIP{
+			ASNDatabase: TempMMDB(map[string]mmdbtype.Map{
+				"1.1.1.0/24": {"autonomous_system_number": mmdbtype.Uint32(13335)},
+				"8.8.8.0/24": {"asn": mmdbtype.String("AS15169")},
+				"9.9.9.0/24": {"asn": mmdbtype.String("invalid")},
+			}),
+			CountryDatabase: TempMMDB(map[string]mmdbtype.Map{
+				"1.1.1.0/24": {"country": mmdbtype.Map{"iso_code": mmdbtype.String("AU")}},
+				"8.8.8.0/24": {"country": mmdbtype.String("us")},
+			}),
+		}
+	})
+
+	Describe("Open", func() {
+		It("should return nil without databases", func() {
+			cfg = config.GeoIP{}
+
+			Expect(Open(ctx, &cfg)).Should(BeNil())
+		})
+
+		It("should fail if the database can't be opened", func() {
+			cfg.CountryDatabase = "/does/not/exist.mmdb"
+
+			_, err := Open(ctx, &cfg)
+			Expect(err).Should(MatchError(ContainSubstring("can't open country database")))
+		})
+
+		It("should fail if the file is no MMDB", func() {
+			cfg.ASNDatabase = TempFile("invalid").Name()
+
+			_, err := Open(ctx, &cfg)
+			Expect(err).Should(MatchError(ContainSubstring("can't open ASN database")))
+		})
+	})
+
+	Describe("ASN", func() {
+		It("should return the number of MaxMind databases", func() {
+			asn, found := open().ASN(net.ParseIP("1.1.1.1"))
+			Expect(found).Should(BeTrue())
+			Expect(asn).Should(BeEquivalentTo(13335))
+		})
+
+		It("should return the number of IPinfo databases", func() {
+			asn, found := open().ASN(net.ParseIP("8.8.8.8"))
+			Expect(found).Should(BeTrue())
+			Expect(asn).Should(BeEquivalentTo(15169))
+		})
+
+		It("should fail for unknown or invalid networks", func() {
+			db := open()
+
+			_, found := db.ASN(net.ParseIP("9.9.9.9"))
+			Expect(found).Should(BeFalse())
+
+			_, found = db.ASN(net.ParseIP("4.4.4.4"))
+			Expect(found).Should(BeFalse())
+		})
+
+		It("should fail without database", func() {
+			var db *DB
+
+			_, found := db.ASN(net.ParseIP("1.1.1.1"))
+			Expect(found).Should(BeFalse())
+
+			cfg.ASNDatabase = ""
+
+			_, found = open().ASN(net.ParseIP("1.1.1.1"))
+			Expect(found).Should(BeFalse())
+		})
+	})
+
+	Describe("Country", func() {
+		It("should return the code of MaxMind databases", func() {
+			country, found := open().Country(net.ParseIP("1.1.1.1"))
+			Expect(found).Should(BeTrue())
+			Expect(country).Should(Equal("AU"))
+		})
+
+		It("should return the code of IPinfo databases", func() {
+			country, found := open().Country(net.ParseIP("8.8.8.8"))
+			Expect(found).Should(BeTrue())
+			Expect(country).Should(Equal("US"))
+		})
+
+		It("should fail for unknown networks", func() {
+			_, found := open().Country(net.ParseIP("4.4.4.4"))
+			Expect(found).Should(BeFalse())
+		})
+	})
+
+	Describe("reloading", func() {
+		BeforeEach(func() {
+			cfg.ReloadInterval = config.Duration(10 * time.Millisecond)
+		})
+
+		It("should reload changed files", func() {
+			db := open()
+
+			updated := TempMMDB(map[string]mmdbtype.Map{
+				"4.4.4.0/24": {"country": mmdbtype.String("DE")},
+			})
+
+			// files are replaced atomically, like geoipupdate does
+			Expect(os.Rename(updated, cfg.CountryDatabase)).Should(Succeed())
+
+			Eventually(func() string {
+				country, _ := db.Country(net.ParseIP("4.4.4.4"))
+
+				return country
+			}).Should(Equal("DE"))
+		})
+
+		It("should keep the database if the file is invalid", func() {
+			db := open()
+
+			Expect(os.Rename(TempFile("invalid").Name(), cfg.CountryDatabase)).Should(Succeed())
+
+			Consistently(func() string {
+				country, _ := db.Country(net.ParseIP("1.1.1.1"))
+
+				return country
+			}, "50ms").Should(Equal("AU"))
+		})
+	})
+})
@@ -0,0 +1,40 @@
+package helpertest
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+// TempMMDB writes a MMDB file with a record per network (CIDR) into a temporary folder and returns its path
+func TempMMDB(records map[string]mmdbtype.Map) string {
+	writer, err := mmdbwriter.New(mmdbwriter.Options{
+		DatabaseType:            "blocky-test",
+		IncludeReservedNetworks: true,
+	})
+	gomega.Expect(err).Should(gomega.Succeed())
+
+	for cidr, record := range records {
+		_, network, err := net.ParseCIDR(cidr)
+		gomega.Expect(err).Should(gomega.Succeed())
+
+		gomega.Expect(writer.Insert(network, record)).Should(gomega.Succeed())
+	}
+
+	path := filepath.Join(ginkgo.GinkgoT().TempDir(), "test.mmdb")
+
+	f, err := os.Create(path)
+	gomega.Expect(err).Should(gomega.Succeed())
+
+	defer f.Close()
+
+	_, err = writer.WriteTo(f)
+	gomega.Expect(err).Should(gomega.Succeed())
+
+	return path
+}
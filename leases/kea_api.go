@@ -0,0 +1,141 @@
+package leases
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	keaResultSuccess = 0
+	keaResultEmpty   = 3
+)
+
+type keaCommand struct {
+	Command string   `json:"command"`
+	Service []string `json:"service"`
+}
+
+type keaResponse struct {
+	Result    int    `json:"result"`
+	Text      string `json:"text"`
+	Arguments struct {
+		Leases []keaLease `json:"leases"`
+	} `json:"arguments"`
+}
+
+type keaLease struct {
+	IPAddress string `json:"ip-address"`
+	HWAddress string `json:"hw-address"`
+	DUID      string `json:"duid"`
+	Hostname  string `json:"hostname"`
+	State     int    `json:"state"`
+}
+
+// FetchKea gets the DHCPv4 and DHCPv6 leases from the Kea control agent at `url`.
+//
+// An error is only returned if no service returned its leases, since often only one of DHCPv4 and DHCPv6 is used.
+func FetchKea(ctx context.Context, client *http.Client, url string) ([]Lease, error) {
+	var (
+		result []Lease
+		errs   *multierror.Error
+		ok     bool
+	)
+
+	for _, cmd := range []keaCommand{
+		{Command: "lease4-get-all", Service: []string{"dhcp4"}},
+		{Command: "lease6-get-all", Service: []string{"dhcp6"}},
+	} {
+		leases, err := fetchKeaCommand(ctx, client, url, cmd)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("%s: %w", cmd.Command, err))
+
+			continue
+		}
+
+		ok = true
+
+		result = append(result, leases...)
+	}
+
+	if !ok {
+		return nil, errs.ErrorOrNil()
+	}
+
+	return result, nil
+}
+
+func fetchKeaCommand(ctx context.Context, client *http.Client, url string, cmd keaCommand) ([]Lease, error) {
+	body, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status code %d", resp.StatusCode)
+	}
+
+	// the control agent answers with one response per service
+	var responses []keaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	if len(responses) == 0 {
+		return nil, errors.New("empty response")
+	}
+
+	var result []Lease
+
+	for _, r := range responses {
+		switch r.Result {
+		case keaResultSuccess:
+		case keaResultEmpty:
+			continue
+		default:
+			return nil, fmt.Errorf("error %d: %s", r.Result, r.Text)
+		}
+
+		for _, l := range r.Arguments.Leases {
+			ip := net.ParseIP(l.IPAddress)
+
+			// state 0 is the default (assigned) state
+			if ip == nil || l.State != 0 {
+				continue
+			}
+
+			clientID := l.HWAddress
+			if l.DUID != "" {
+				clientID = l.DUID
+			}
+
+			result = append(result, Lease{
+				IP:       ip,
+				Hostname: strings.TrimSuffix(l.Hostname, "."),
+				ClientID: clientID,
+			})
+		}
+	}
+
+	return result, nil
+}
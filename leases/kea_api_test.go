@@ -0,0 +1,83 @@
+package leases
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FetchKea", func() {
+	var (
+		ctx       context.Context
+		server    *httptest.Server
+		responses map[string]string
+	)
+
+	BeforeEach(func() {
+		var cancelFn context.CancelFunc
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		responses = map[string]string{
+			"lease4-get-all": `[{"result": 0, "arguments": {"leases": [
+				{"ip-address": "192.168.1.10", "hw-address": "aa:bb:cc:dd:ee:01", "hostname": "laptop.", "state": 0},
+				{"ip-address": "192.168.1.11", "hw-address": "aa:bb:cc:dd:ee:02", "hostname": "", "state": 1}
+			]}}]`,
+			"lease6-get-all": `[{"result": 1, "text": "Unable to forward command to the dhcp6 service"}]`,
+		}
+	})
+
+	JustBeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var cmd keaCommand
+			Expect(json.NewDecoder(r.Body).Decode(&cmd)).Should(Succeed())
+
+			_, _ = w.Write([]byte(responses[cmd.Command]))
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("returns the leases of the services answering", func() {
+		leases, err := FetchKea(ctx, server.Client(), server.URL)
+		Expect(err).Should(Succeed())
+
+		Expect(leases).Should(Equal([]Lease{
+			{IP: net.ParseIP("192.168.1.10"), Hostname: "laptop", ClientID: "aa:bb:cc:dd:ee:01"},
+		}))
+	})
+
+	When("DHCPv6 has leases", func() {
+		BeforeEach(func() {
+			responses["lease4-get-all"] = `[{"result": 3, "text": "0 IPv4 lease(s) found."}]`
+			responses["lease6-get-all"] = `[{"result": 0, "arguments": {"leases": [
+				{"ip-address": "fd00::10", "duid": "00:03:00:01:aa:bb:cc:dd:ee:04", "hostname": "phone", "state": 0}
+			]}}]`
+		})
+
+		It("uses the DUID as client ID", func() {
+			leases, err := FetchKea(ctx, server.Client(), server.URL)
+			Expect(err).Should(Succeed())
+
+			Expect(leases).Should(Equal([]Lease{
+				{IP: net.ParseIP("fd00::10"), Hostname: "phone", ClientID: "00:03:00:01:aa:bb:cc:dd:ee:04"},
+			}))
+		})
+	})
+
+	When("no service answers", func() {
+		BeforeEach(func() {
+			responses["lease4-get-all"] = "no JSON"
+		})
+
+		It("fails", func() {
+			_, err := FetchKea(ctx, server.Client(), server.URL)
+			Expect(err).Should(MatchError(ContainSubstring("lease4-get-all: invalid response")))
+			Expect(err).Should(MatchError(ContainSubstring("lease6-get-all: error 1")))
+		})
+	})
+})
@@ -0,0 +1,202 @@
+// Package leases provides the client names and IDs of DHCP leases
+package leases
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Lease of a DHCP client
+type Lease struct {
+	IP       net.IP
+	Hostname string
+	// ClientID is the hardware address of a DHCPv4 client or the DUID of a DHCPv6 client
+	ClientID string
+}
+
+// Names returns the hostname and client ID of the lease, if known
+func (l Lease) Names() []string {
+	names := make([]string, 0, 2) //nolint:mnd
+
+	if l.Hostname != "" {
+		names = append(names, l.Hostname)
+	}
+
+	if l.ClientID != "" {
+		names = append(names, l.ClientID)
+	}
+
+	return names
+}
+
+// Store holds the leases of all sources and reloads them when they change
+type Store struct {
+	cfg    config.DHCPLeases
+	client *http.Client
+
+	lock   sync.RWMutex
+	leases map[string]Lease // by IP
+
+	sources []sourceState
+}
+
+// sourceState is the last loaded state of a source
+type sourceState struct {
+	modTime time.Time
+	size    int64
+	leases  []Lease
+}
+
+// NewStore creates a new store for the configured sources, `client` is used for the Kea control agent
+func NewStore(cfg config.DHCPLeases, client *http.Client) *Store {
+	return &Store{
+		cfg:    cfg,
+		client: client,
+
+		leases:  make(map[string]Lease),
+		sources: make([]sourceState, len(cfg.Sources)),
+	}
+}
+
+// Start loads the leases and checks the sources for changes in the background, until `ctx` is done.
+// `onChange` is called each time the leases changed after the initial load.
+func (s *Store) Start(ctx context.Context, onChange func()) {
+	s.refresh(ctx)
+
+	go func() {
+		ticker := time.NewTicker(s.cfg.CheckInterval.ToDuration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if s.refresh(ctx) && onChange != nil {
+					onChange()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Lookup returns the lease of the IP
+func (s *Store) Lookup(ip net.IP) (Lease, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	lease, ok := s.leases[ip.String()]
+
+	return lease, ok
+}
+
+// Count returns the number of leases
+func (s *Store) Count() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.leases)
+}
+
+// refresh reloads the changed sources and returns true if the leases changed
+func (s *Store) refresh(ctx context.Context) bool {
+	logger := log.PrefixedLog("dhcp_leases")
+
+	for i, source := range s.cfg.Sources {
+		if err := s.loadSource(ctx, source, &s.sources[i]); err != nil {
+			logger.WithFields(logrus.Fields{
+				"format": source.Format,
+				"source": source.Source,
+			}).WithError(err).Warn("can't load DHCP leases, keeping the previous ones")
+		}
+	}
+
+	leases := make(map[string]Lease)
+
+	// later sources take precedence
+	for _, state := range s.sources {
+		for _, lease := range state.leases {
+			leases[lease.IP.String()] = lease
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if maps.EqualFunc(s.leases, leases, leaseEqual) {
+		return false
+	}
+
+	s.leases = leases
+
+	logger.Debugf("loaded %d DHCP leases", len(leases))
+
+	return true
+}
+
+func (s *Store) loadSource(ctx context.Context, source config.DHCPLeaseSource, state *sourceState) error {
+	if source.Format == config.DHCPLeaseFormatKeaApi {
+		leases, err := FetchKea(ctx, s.client, source.Source)
+		if err != nil {
+			return err
+		}
+
+		state.leases = leases
+
+		return nil
+	}
+
+	info, err := os.Stat(source.Source)
+	if err != nil {
+		return err
+	}
+
+	// a lease which expired since is kept until the file changes: the DHCP server rewrites it before the IP is reused
+	if info.ModTime().Equal(state.modTime) && info.Size() == state.size {
+		return nil
+	}
+
+	f, err := os.Open(source.Source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var leases []Lease
+
+	now := time.Now()
+
+	switch source.Format {
+	case config.DHCPLeaseFormatDnsmasq:
+		leases, err = ParseDnsmasq(f, now)
+	case config.DHCPLeaseFormatIsc:
+		leases, err = ParseISC(f)
+	case config.DHCPLeaseFormatKea:
+		leases, err = ParseKea(f, now)
+	default:
+		err = fmt.Errorf("unsupported format %s", source.Format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*state = sourceState{modTime: info.ModTime(), size: info.Size(), leases: leases}
+
+	return nil
+}
+
+func leaseEqual(a, b Lease) bool {
+	return a.IP.Equal(b.IP) && a.Hostname == b.Hostname && a.ClientID == b.ClientID
+}
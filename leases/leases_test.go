@@ -0,0 +1,113 @@
+package leases
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Store", func() {
+	var (
+		ctx     context.Context
+		dir     string
+		sut     *Store
+		changes atomic.Int32
+	)
+
+	writeFile := func(name, content string, modTime time.Time) string {
+		path := filepath.Join(dir, name)
+
+		Expect(os.WriteFile(path, []byte(content), 0o600)).Should(Succeed())
+		Expect(os.Chtimes(path, modTime, modTime)).Should(Succeed())
+
+		return path
+	}
+
+	lookup := func(ip net.IP) Lease {
+		lease, ok := sut.Lookup(ip)
+		ExpectWithOffset(1, ok).Should(BeTrue())
+
+		return lease
+	}
+
+	BeforeEach(func() {
+		var cancelFn context.CancelFunc
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		dir = GinkgoT().TempDir()
+		changes.Store(0)
+	})
+
+	Describe("Lease", func() {
+		It("has the hostname and client ID as names", func() {
+			Expect(Lease{Hostname: "laptop", ClientID: "aa:bb:cc:dd:ee:01"}.Names()).
+				Should(Equal([]string{"laptop", "aa:bb:cc:dd:ee:01"}))
+			Expect(Lease{ClientID: "aa:bb:cc:dd:ee:01"}.Names()).Should(Equal([]string{"aa:bb:cc:dd:ee:01"}))
+			Expect(Lease{}.Names()).Should(BeEmpty())
+		})
+	})
+
+	When("lease files are configured", func() {
+		var dnsmasqFile, iscFile string
+
+		BeforeEach(func() {
+			start := time.Now().Add(-time.Hour)
+
+			dnsmasqFile = writeFile("dnsmasq.leases", "0 aa:bb:cc:dd:ee:01 192.168.1.10 laptop *\n", start)
+			iscFile = writeFile("dhcpd.leases", "lease 192.168.1.10 {\n  client-hostname \"other\";\n}\n"+
+				"lease 192.168.1.20 {\n  hardware ethernet aa:bb:cc:dd:ee:02;\n}\n", start)
+
+			sut = NewStore(config.DHCPLeases{
+				Sources: []config.DHCPLeaseSource{
+					{Format: config.DHCPLeaseFormatIsc, Source: iscFile},
+					{Format: config.DHCPLeaseFormatDnsmasq, Source: dnsmasqFile},
+				},
+				CheckInterval: config.Duration(10 * time.Millisecond),
+			}, http.DefaultClient)
+
+			sut.Start(ctx, func() { changes.Add(1) })
+		})
+
+		It("loads the leases, later sources take precedence", func() {
+			Expect(sut.Count()).Should(Equal(2))
+
+			Expect(lookup(net.ParseIP("192.168.1.10"))).Should(Equal(
+				Lease{IP: net.ParseIP("192.168.1.10"), Hostname: "laptop", ClientID: "aa:bb:cc:dd:ee:01"},
+			))
+			Expect(lookup(net.ParseIP("192.168.1.20"))).Should(Equal(
+				Lease{IP: net.ParseIP("192.168.1.20"), ClientID: "aa:bb:cc:dd:ee:02"},
+			))
+
+			_, ok := sut.Lookup(net.ParseIP("192.168.1.30"))
+			Expect(ok).Should(BeFalse())
+		})
+
+		It("reloads changed files", func() {
+			Consistently(changes.Load, "50ms").Should(BeZero())
+
+			writeFile("dnsmasq.leases", "0 aa:bb:cc:dd:ee:03 192.168.1.30 tv *\n", time.Now())
+
+			Eventually(changes.Load).Should(BeEquivalentTo(1))
+
+			Expect(lookup(net.ParseIP("192.168.1.30"))).Should(HaveField("Hostname", "tv"))
+			Expect(lookup(net.ParseIP("192.168.1.10"))).Should(HaveField("Hostname", "other"))
+		})
+
+		It("keeps the leases of files which can't be read", func() {
+			Expect(os.Remove(dnsmasqFile)).Should(Succeed())
+
+			Consistently(changes.Load, "50ms").Should(BeZero())
+			Expect(lookup(net.ParseIP("192.168.1.10"))).Should(HaveField("Hostname", "laptop"))
+		})
+	})
+})
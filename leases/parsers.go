@@ -0,0 +1,243 @@
+package leases
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dnsmasq writes an infinite lease with expiry time 0
+const dnsmasqInfinite = 0
+
+// ParseDnsmasq parses a dnsmasq lease file.
+//
+// Lines are `<expiry> <MAC> <IP> <hostname> <client ID>` for DHCPv4 and `<expiry> <IAID> <IP> <hostname> <DUID>`
+// for DHCPv6. Expired leases are skipped.
+func ParseDnsmasq(r io.Reader, now time.Time) ([]Lease, error) {
+	var result []Lease
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 || fields[0] == "duid" {
+			continue
+		}
+
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("invalid dnsmasq lease: %s", scanner.Text())
+		}
+
+		expiry, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dnsmasq lease expiry: %w", err)
+		}
+
+		if expiry != dnsmasqInfinite && time.Unix(expiry, 0).Before(now) {
+			continue
+		}
+
+		ip := net.ParseIP(fields[2])
+		if ip == nil {
+			return nil, fmt.Errorf("invalid dnsmasq lease IP: %s", fields[2])
+		}
+
+		lease := Lease{IP: ip, Hostname: dnsmasqValue(fields[3]), ClientID: fields[1]}
+
+		if ip.To4() == nil {
+			// the second field is the IAID
+			lease.ClientID = dnsmasqValue(fields[4])
+		}
+
+		result = append(result, lease)
+	}
+
+	return result, scanner.Err()
+}
+
+func dnsmasqValue(value string) string {
+	if value == "*" {
+		return ""
+	}
+
+	return value
+}
+
+// ParseISC parses an ISC DHCP dhcpd.leases file.
+//
+// The file is a journal: the latest declaration of a lease wins. Leases which are not active are skipped.
+func ParseISC(r io.Reader) ([]Lease, error) {
+	var (
+		byIP    = make(map[string]Lease)
+		order   []string
+		current *Lease
+		active  bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+
+		case strings.HasPrefix(line, "lease ") && strings.HasSuffix(line, "{"):
+			fields := strings.Fields(line)
+
+			ip := net.ParseIP(fields[1])
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ISC lease IP: %s", fields[1])
+			}
+
+			current = &Lease{IP: ip}
+			active = true
+
+		case current == nil:
+			// other declarations, like `server-duid`
+			continue
+
+		case line == "}":
+			key := current.IP.String()
+
+			if _, ok := byIP[key]; !ok {
+				order = append(order, key)
+			}
+
+			if active {
+				byIP[key] = *current
+			} else {
+				delete(byIP, key)
+			}
+
+			current = nil
+
+		default:
+			statement := strings.TrimSuffix(line, ";")
+
+			switch {
+			case strings.HasPrefix(statement, "binding state "):
+				active = strings.TrimPrefix(statement, "binding state ") == "active"
+			case strings.HasPrefix(statement, "hardware ethernet "):
+				current.ClientID = strings.ToLower(strings.TrimPrefix(statement, "hardware ethernet "))
+			case strings.HasPrefix(statement, "client-hostname "):
+				current.Hostname = strings.Trim(strings.TrimPrefix(statement, "client-hostname "), `"`)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Lease, 0, len(byIP))
+
+	for _, key := range order {
+		if lease, ok := byIP[key]; ok {
+			result = append(result, lease)
+		}
+	}
+
+	return result, nil
+}
+
+// Kea escapes commas in values of its CSV files
+const keaEscapedComma = "&#x2c"
+
+// ParseKea parses a Kea memfile CSV lease file of DHCPv4 or DHCPv6 leases.
+//
+// The file is a journal: the latest row of an address wins. Expired and declined leases are skipped.
+func ParseKea(r io.Reader, now time.Time) ([]Lease, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kea lease file header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	if _, ok := columns["address"]; !ok {
+		return nil, errors.New("invalid Kea lease file header: no address column")
+	}
+
+	value := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.ReplaceAll(record[i], keaEscapedComma, ",")
+		}
+
+		return ""
+	}
+
+	var (
+		byIP  = make(map[string]Lease)
+		order []string
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kea lease: %w", err)
+		}
+
+		ip := net.ParseIP(value(record, "address"))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid Kea lease IP: %s", value(record, "address"))
+		}
+
+		key := ip.String()
+
+		if _, ok := byIP[key]; !ok {
+			order = append(order, key)
+		}
+
+		expire, _ := strconv.ParseInt(value(record, "expire"), 10, 64)
+
+		// state 0 is the default (assigned) state
+		if state := value(record, "state"); (state != "" && state != "0") || time.Unix(expire, 0).Before(now) {
+			delete(byIP, key)
+
+			continue
+		}
+
+		clientID := value(record, "hwaddr")
+		if duid := value(record, "duid"); duid != "" {
+			clientID = duid
+		}
+
+		byIP[key] = Lease{
+			IP:       ip,
+			Hostname: strings.TrimSuffix(value(record, "hostname"), "."),
+			ClientID: clientID,
+		}
+	}
+
+	result := make([]Lease, 0, len(byIP))
+
+	for _, key := range order {
+		if lease, ok := byIP[key]; ok {
+			result = append(result, lease)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package leases
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func linesReader(lines ...string) *strings.Reader {
+	return strings.NewReader(strings.Join(lines, "\n"))
+}
+
+var _ = Describe("Parsers", func() {
+	now := time.Unix(1700000000, 0)
+
+	Describe("ParseDnsmasq", func() {
+		It("parses DHCPv4 and DHCPv6 leases", func() {
+			leases, err := ParseDnsmasq(linesReader(
+				"1700003600 aa:bb:cc:dd:ee:01 192.168.1.10 laptop 01:aa:bb:cc:dd:ee:01",
+				"0 aa:bb:cc:dd:ee:02 192.168.1.11 * *",
+				"1699990000 aa:bb:cc:dd:ee:03 192.168.1.12 expired *",
+				"duid 00:01:00:01:2c:aa:bb:cc",
+				"1700003600 12345 fd00::10 phone 00:03:00:01:aa:bb:cc:dd:ee:04",
+			), now)
+			Expect(err).Should(Succeed())
+
+			Expect(leases).Should(Equal([]Lease{
+				{IP: net.ParseIP("192.168.1.10"), Hostname: "laptop", ClientID: "aa:bb:cc:dd:ee:01"},
+				{IP: net.ParseIP("192.168.1.11"), ClientID: "aa:bb:cc:dd:ee:02"},
+				{IP: net.ParseIP("fd00::10"), Hostname: "phone", ClientID: "00:03:00:01:aa:bb:cc:dd:ee:04"},
+			}))
+		})
+
+		It("fails on invalid leases", func() {
+			_, err := ParseDnsmasq(linesReader("1700003600 aa:bb:cc:dd:ee:01 192.168.1.10"), now)
+			Expect(err).Should(MatchError(ContainSubstring("invalid dnsmasq lease")))
+
+			_, err = ParseDnsmasq(linesReader("1700003600 aa:bb:cc:dd:ee:01 host laptop *"), now)
+			Expect(err).Should(MatchError(ContainSubstring("invalid dnsmasq lease IP")))
+		})
+	})
+
+	Describe("ParseISC", func() {
+		It("returns the latest active leases", func() {
+			leases, err := ParseISC(linesReader(
+				"# The format of this file is documented in the dhcpd.leases(5) manual page.",
+				`server-duid "\000\001";`,
+				"lease 192.168.1.10 {",
+				"  starts 4 2023/11/14 22:13:20;",
+				"  binding state active;",
+				"  hardware ethernet AA:BB:CC:DD:EE:01;",
+				`  client-hostname "old";`,
+				"}",
+				"lease 192.168.1.11 {",
+				"  binding state active;",
+				"  hardware ethernet aa:bb:cc:dd:ee:02;",
+				"}",
+				"lease 192.168.1.10 {",
+				"  binding state active;",
+				"  hardware ethernet aa:bb:cc:dd:ee:01;",
+				`  client-hostname "laptop";`,
+				"}",
+				"lease 192.168.1.11 {",
+				"  binding state free;",
+				"}",
+			))
+			Expect(err).Should(Succeed())
+
+			Expect(leases).Should(Equal([]Lease{
+				{IP: net.ParseIP("192.168.1.10"), Hostname: "laptop", ClientID: "aa:bb:cc:dd:ee:01"},
+			}))
+		})
+
+		It("fails on invalid leases", func() {
+			_, err := ParseISC(linesReader("lease host {", "}"))
+			Expect(err).Should(MatchError(ContainSubstring("invalid ISC lease IP")))
+		})
+	})
+
+	Describe("ParseKea", func() {
+		It("parses DHCPv4 leases", func() {
+			leases, err := ParseKea(linesReader(
+				"address,hwaddr,client_id,valid_lifetime,expire,subnet_id,fqdn_fwd,fqdn_rev,hostname,state,user_context",
+				"192.168.1.10,aa:bb:cc:dd:ee:01,,3600,1700003600,1,0,0,laptop.lan.,0,",
+				"192.168.1.11,aa:bb:cc:dd:ee:02,,3600,1700003600,1,0,0,,0,",
+				"192.168.1.12,aa:bb:cc:dd:ee:03,,3600,1700003600,1,0,0,declined,1,",
+				"192.168.1.13,aa:bb:cc:dd:ee:04,,3600,1700003600,1,0,0,tv,0,",
+				"192.168.1.13,aa:bb:cc:dd:ee:04,,0,1699990000,1,0,0,tv,0,",
+				"192.168.1.14,aa:bb:cc:dd:ee:05,,3600,1700003600,1,0,0,a&#x2cb,0,",
+			), now)
+			Expect(err).Should(Succeed())
+
+			Expect(leases).Should(Equal([]Lease{
+				{IP: net.ParseIP("192.168.1.10"), Hostname: "laptop.lan", ClientID: "aa:bb:cc:dd:ee:01"},
+				{IP: net.ParseIP("192.168.1.11"), ClientID: "aa:bb:cc:dd:ee:02"},
+				{IP: net.ParseIP("192.168.1.14"), Hostname: "a,b", ClientID: "aa:bb:cc:dd:ee:05"},
+			}))
+		})
+
+		It("parses DHCPv6 leases", func() {
+			leases, err := ParseKea(linesReader(
+				"address,duid,valid_lifetime,expire,subnet_id,pref_lifetime,lease_type,iaid,prefix_len,"+
+					"fqdn_fwd,fqdn_rev,hostname,hwaddr,state",
+				"fd00::10,00:03:00:01:aa:bb:cc:dd:ee:04,3600,1700003600,1,1800,0,1,128,0,0,phone,,0",
+			), now)
+			Expect(err).Should(Succeed())
+
+			Expect(leases).Should(Equal([]Lease{
+				{IP: net.ParseIP("fd00::10"), Hostname: "phone", ClientID: "00:03:00:01:aa:bb:cc:dd:ee:04"},
+			}))
+		})
+
+		It("accepts empty files", func() {
+			Expect(ParseKea(linesReader(), now)).Should(BeEmpty())
+		})
+
+		It("fails on invalid files", func() {
+			_, err := ParseKea(linesReader("ip,hwaddr"), now)
+			Expect(err).Should(MatchError(ContainSubstring("no address column")))
+
+			_, err = ParseKea(linesReader("address,hwaddr", "host,aa:bb:cc:dd:ee:01"), now)
+			Expect(err).Should(MatchError(ContainSubstring("invalid Kea lease IP")))
+		})
+	})
+})
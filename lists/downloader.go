@@ -2,17 +2,25 @@ package lists
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/evt"
 	"github.com/avast/retry-go/v4"
 )
 
+const kibibyte = 1024
+
 // TransientError represents a temporary error like timeout, network errors...
 type TransientError struct {
 	inner error
@@ -36,6 +44,9 @@ type httpDownloader struct {
 	cfg config.Downloader
 
 	client http.Client
+
+	// nil if the bandwidth is unlimited
+	bandwidth *bandwidthLimiter
 }
 
 func NewDownloader(cfg config.Downloader, transport http.RoundTripper) FileDownloader {
@@ -43,7 +54,7 @@ func NewDownloader(cfg config.Downloader, transport http.RoundTripper) FileDownl
 }
 
 func newDownloader(cfg config.Downloader, transport http.RoundTripper) *httpDownloader {
-	return &httpDownloader{
+	d := &httpDownloader{
 		cfg: cfg,
 
 		client: http.Client{
@@ -51,11 +62,62 @@ func newDownloader(cfg config.Downloader, transport http.RoundTripper) *httpDown
 			Timeout:   cfg.Timeout.ToDuration(),
 		},
 	}
+
+	if cfg.MaxBandwidth > 0 {
+		d.bandwidth = &bandwidthLimiter{bytesPerSecond: float64(cfg.MaxBandwidth) * kibibyte}
+	}
+
+	return d
 }
 
+// DownloadFile downloads the file from the link or its mirrors.
+// If all fail, the last successful download is used if there is one in the cache directory.
 func (d *httpDownloader) DownloadFile(ctx context.Context, link string) (io.ReadCloser, error) {
+	var err error
+
+	for _, url := range append([]string{link}, d.cfg.Mirrors[link]...) {
+		var body io.ReadCloser
+
+		body, err = d.download(ctx, url)
+		if err == nil {
+			if d.bandwidth != nil {
+				body = &limitedReader{ReadCloser: body, ctx: ctx, limiter: d.bandwidth}
+			}
+
+			return d.cacheWhileReading(link, body), nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		if url != link || len(d.cfg.Mirrors[link]) != 0 {
+			logger().WithField("link", url).Warnf("download failed, trying next mirror if any: %s", err)
+		}
+	}
+
+	if d.cfg.CacheDir == "" {
+		return nil, err
+	}
+
+	cached, cacheErr := os.Open(d.cachePath(link))
+	if cacheErr != nil {
+		return nil, err
+	}
+
+	logger().WithField("link", link).Warnf("download failed, using last successful download: %s", err)
+
+	return cached, nil
+}
+
+func (d *httpDownloader) download(ctx context.Context, link string) (io.ReadCloser, error) {
 	var body io.ReadCloser
 
+	delayType := retry.FixedDelay
+	if d.cfg.MaxCooldown > d.cfg.Cooldown {
+		delayType = retry.BackOffDelay
+	}
+
 	err := retry.Do(
 		func() error {
 			req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
@@ -84,8 +146,9 @@ func (d *httpDownloader) DownloadFile(ctx context.Context, link string) (io.Read
 			return httpErr
 		},
 		retry.Attempts(d.cfg.Attempts),
-		retry.DelayType(retry.FixedDelay),
+		retry.DelayType(delayType),
 		retry.Delay(d.cfg.Cooldown.ToDuration()),
+		retry.MaxDelay(max(d.cfg.MaxCooldown, d.cfg.Cooldown).ToDuration()),
 		retry.LastErrorOnly(true),
 		retry.OnRetry(func(n uint, err error) {
 			var transientErr *TransientError
@@ -114,3 +177,124 @@ func (d *httpDownloader) DownloadFile(ctx context.Context, link string) (io.Read
 func onDownloadError(link string) {
 	evt.Bus().Publish(evt.CachingFailedDownloadChanged, link)
 }
+
+func (d *httpDownloader) cachePath(link string) string {
+	hash := sha256.Sum256([]byte(link))
+
+	return filepath.Join(d.cfg.CacheDir, hex.EncodeToString(hash[:]))
+}
+
+// cacheWhileReading returns a reader writing the file to the cache directory.
+// The cached file is replaced once the whole file was read.
+func (d *httpDownloader) cacheWhileReading(link string, body io.ReadCloser) io.ReadCloser {
+	if d.cfg.CacheDir == "" {
+		return body
+	}
+
+	if err := os.MkdirAll(d.cfg.CacheDir, 0o750); err != nil { //nolint:mnd
+		logger().Warnf("can't create download cache directory: %s", err)
+
+		return body
+	}
+
+	tmp, err := os.CreateTemp(d.cfg.CacheDir, "download-*")
+	if err != nil {
+		logger().Warnf("can't cache download: %s", err)
+
+		return body
+	}
+
+	return &cachingReader{body: body, tmp: tmp, path: d.cachePath(link)}
+}
+
+// cachingReader copies the read data to a temporary file, which is moved to `path` if the whole body was read
+type cachingReader struct {
+	body io.ReadCloser
+	tmp  *os.File
+	path string
+
+	complete bool
+	failed   bool
+}
+
+func (r *cachingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+
+	if n > 0 && !r.failed {
+		if _, werr := r.tmp.Write(p[:n]); werr != nil {
+			logger().Warnf("can't cache download: %s", werr)
+
+			r.failed = true
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		r.complete = true
+	}
+
+	return n, err
+}
+
+func (r *cachingReader) Close() error {
+	err := r.body.Close()
+
+	closeErr := r.tmp.Close()
+
+	if r.complete && !r.failed && closeErr == nil {
+		if renameErr := os.Rename(r.tmp.Name(), r.path); renameErr == nil {
+			return err
+		}
+	}
+
+	_ = os.Remove(r.tmp.Name())
+
+	return err
+}
+
+// bandwidthLimiter limits the download speed of all readers sharing it
+type bandwidthLimiter struct {
+	bytesPerSecond float64
+
+	lock sync.Mutex
+	next time.Time
+}
+
+// wait waits until n more bytes may be read
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	l.lock.Lock()
+
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+
+	l.next = l.next.Add(time.Duration(float64(n) / l.bytesPerSecond * float64(time.Second)))
+	delay := l.next.Sub(now)
+
+	l.lock.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+type limitedReader struct {
+	io.ReadCloser
+
+	ctx     context.Context //nolint:containedctx
+	limiter *bandwidthLimiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := r.limiter.wait(r.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+
+	return n, err
+}
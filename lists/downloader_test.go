@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -213,6 +214,155 @@ var _ = Describe("Downloader", func() {
 					Expect(loggerHook.LastEntry().Message).Should(ContainSubstring("Name resolution err: "))
 				})
 		})
+		When("mirrors are configured", func() {
+			var mirror *httptest.Server
+
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					rw.WriteHeader(http.StatusServiceUnavailable)
+				}))
+				DeferCleanup(server.Close)
+
+				mirror = TestServer("mirrored.com")
+
+				sutConfig.Attempts = 1
+				sutConfig.Mirrors = map[string][]string{server.URL: {"somewrongurl", mirror.URL}}
+			})
+
+			It("should use the first mirror which works", func(ctx context.Context) {
+				reader, err := sut.DownloadFile(ctx, server.URL)
+				Expect(err).Should(Succeed())
+				DeferCleanup(reader.Close)
+
+				data, err := io.ReadAll(reader)
+				Expect(err).Should(Succeed())
+				Expect(string(data)).Should(Equal("mirrored.com"))
+			})
+
+			It("should fail if all mirrors fail", func(ctx context.Context) {
+				mirror.Close()
+
+				_, err := sut.DownloadFile(ctx, server.URL)
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+		When("a cache directory is configured", func() {
+			var content atomic.Value
+
+			BeforeEach(func() {
+				content.Store("cached.com")
+
+				server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					data := content.Load().(string)
+					if data == "" {
+						rw.WriteHeader(http.StatusServiceUnavailable)
+
+						return
+					}
+
+					_, _ = rw.Write([]byte(data))
+				}))
+				DeferCleanup(server.Close)
+
+				sutConfig.Attempts = 1
+				sutConfig.CacheDir = GinkgoT().TempDir()
+			})
+
+			read := func(ctx context.Context) string {
+				reader, err := sut.DownloadFile(ctx, server.URL)
+				Expect(err).Should(Succeed())
+
+				data, err := io.ReadAll(reader)
+				Expect(err).Should(Succeed())
+				Expect(reader.Close()).Should(Succeed())
+
+				return string(data)
+			}
+
+			It("should use the last successful download if the download fails", func(ctx context.Context) {
+				Expect(read(ctx)).Should(Equal("cached.com"))
+
+				content.Store("")
+
+				Expect(read(ctx)).Should(Equal("cached.com"))
+				Expect(loggerHook.LastEntry().Message).Should(ContainSubstring("using last successful download"))
+			})
+
+			It("should only replace the cached file by complete downloads", func(ctx context.Context) {
+				Expect(read(ctx)).Should(Equal("cached.com"))
+
+				content.Store("partial.com")
+
+				reader, err := sut.DownloadFile(ctx, server.URL)
+				Expect(err).Should(Succeed())
+				_, err = reader.Read(make([]byte, 3))
+				Expect(err).Should(Succeed())
+				Expect(reader.Close()).Should(Succeed())
+
+				content.Store("")
+
+				Expect(read(ctx)).Should(Equal("cached.com"))
+
+				entries, err := os.ReadDir(sutConfig.CacheDir)
+				Expect(err).Should(Succeed())
+				Expect(entries).Should(HaveLen(1))
+			})
+
+			It("should fail if nothing was cached", func(ctx context.Context) {
+				content.Store("")
+
+				_, err := sut.DownloadFile(ctx, server.URL)
+				Expect(err).Should(MatchError("got status code 503"))
+			})
+		})
+		When("the cooldown has a maximum", func() {
+			var times []time.Time
+
+			BeforeEach(func() {
+				times = nil
+
+				server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					times = append(times, time.Now())
+
+					rw.WriteHeader(http.StatusNotFound)
+				}))
+				DeferCleanup(server.Close)
+
+				sutConfig.Attempts = 4
+				sutConfig.Cooldown = config.Duration(10 * time.Millisecond)
+				sutConfig.MaxCooldown = config.Duration(40 * time.Millisecond)
+			})
+
+			It("should back off between the attempts", func(ctx context.Context) {
+				_, err := sut.DownloadFile(ctx, server.URL)
+				Expect(err).Should(HaveOccurred())
+
+				Expect(times).Should(HaveLen(4))
+				Expect(times[3].Sub(times[2])).Should(BeNumerically(">", times[1].Sub(times[0])))
+			})
+		})
+		When("the bandwidth is limited", func() {
+			BeforeEach(func() {
+				server = TestServer(strings.Repeat("a", 2*1024))
+
+				sutConfig.MaxBandwidth = 8
+			})
+
+			It("should slow down the download", func(ctx context.Context) {
+				start := time.Now()
+
+				reader, err := sut.DownloadFile(ctx, server.URL)
+				Expect(err).Should(Succeed())
+				DeferCleanup(reader.Close)
+
+				data, err := io.ReadAll(reader)
+				Expect(err).Should(Succeed())
+				Expect(data).Should(HaveLen(2 * 1024))
+
+				// 2 KiB at 8 KiB/s
+				Expect(time.Since(start)).Should(BeNumerically(">=", 200*time.Millisecond))
+			})
+		})
 		When("a proxy is configured", func() {
 			It("should be used", func(ctx context.Context) {
 				proxy := TestHTTPProxy()
@@ -0,0 +1,315 @@
+// Package formats converts list rules between blocky and the formats of other blockers
+package formats
+
+//go:generate go run github.com/abice/go-enum -f=$GOFILE --marshal --names --values
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/0xERR0R/blocky/lists/parsers"
+)
+
+// Format of a list of rules ENUM(
+// blocky  // blocky list format
+// pihole  // Pi-hole domains and regexes, one per line
+// adguard // AdGuard user rules
+// )
+type Format int
+
+// RuleKind of a rule ENUM(
+// exact    // the domain only
+// wildcard // the domain and all its subdomains
+// regex    // domains matching a regular expression
+// )
+type RuleKind int
+
+// Rule is a single allow or deny rule
+type Rule struct {
+	Allow bool
+	Kind  RuleKind
+	// Domain for exact and wildcard rules, regular expression (without slashes) for regex rules
+	Value string
+}
+
+// LineError is a line which can't be converted
+type LineError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d '%s': %s", e.Line, e.Text, e.Err)
+}
+
+const (
+	adguardAllowPrefix = "@@"
+
+	// Pi-hole stores wildcards as regex
+	piholeWildcardPrefix = `(\.|^)`
+	piholeWildcardSuffix = `$`
+)
+
+var (
+	errUnsupported   = fmt.Errorf("unsupported rule")
+	errIPUnsupported = fmt.Errorf("IP rules are not supported")
+
+	// Pi-hole regex extensions, like `;querytype=A`
+	piholeExtensionRegex = regexp.MustCompile(`;(querytype|invert|reply)=?`)
+)
+
+// Read parses the rules of `r`.
+//
+// Formats without allow rules (blocky and Pi-hole) use `allow` for the type of all rules.
+// Lines which can't be converted are returned as `skipped`.
+func Read(r io.Reader, format Format, allow bool) (rules []Rule, skipped []LineError, err error) {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || isComment(text, format) {
+			continue
+		}
+
+		var lineRules []Rule
+
+		switch format {
+		case FormatBlocky:
+			lineRules, err = readBlocky(text, allow)
+		case FormatPihole:
+			lineRules, err = readPihole(text, allow)
+		case FormatAdguard:
+			lineRules, err = readAdguard(text)
+		default:
+			return nil, nil, fmt.Errorf("unknown format %d", format)
+		}
+
+		if err != nil {
+			skipped = append(skipped, LineError{Line: lineNo, Text: text, Err: err})
+
+			continue
+		}
+
+		rules = append(rules, lineRules...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return rules, skipped, nil
+}
+
+// Write writes the rules in the format.
+//
+// Formats without allow rules (blocky and Pi-hole) write the rules of both types alike,
+// so they should only get rules of one type.
+func Write(w io.Writer, format Format, rules []Rule) error {
+	bw := bufio.NewWriter(w)
+
+	for _, rule := range rules {
+		var line string
+
+		switch format {
+		case FormatBlocky:
+			line = writeBlocky(rule)
+		case FormatPihole:
+			line = writePihole(rule)
+		case FormatAdguard:
+			line = writeAdguard(rule)
+		default:
+			return fmt.Errorf("unknown format %d", format)
+		}
+
+		if _, err := bw.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Import converts the rules of `r` to a blocky list of the rules of the type `allow`.
+// Lines which can't be converted, and AdGuard rules of the other type, are written as comments.
+func Import(w io.Writer, r io.Reader, format Format, allow bool) error {
+	rules, skipped, err := Read(r, format, allow)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range skipped {
+		if _, err := fmt.Fprintf(w, "# skipped %s\n", s); err != nil {
+			return err
+		}
+	}
+
+	imported := make([]Rule, 0, len(rules))
+
+	for _, rule := range rules {
+		if rule.Allow != allow {
+			if _, err := fmt.Fprintf(w, "# skipped rule of the other type: %s\n", writeBlocky(rule)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		imported = append(imported, rule)
+	}
+
+	return Write(w, FormatBlocky, imported)
+}
+
+func isComment(text string, format Format) bool {
+	if format == FormatAdguard {
+		return strings.HasPrefix(text, "!") || strings.HasPrefix(text, "#")
+	}
+
+	return strings.HasPrefix(text, "#")
+}
+
+func readBlocky(text string, allow bool) ([]Rule, error) {
+	// end of line comment
+	text, _, _ = strings.Cut(text, "#")
+	text = strings.TrimSpace(text)
+
+	var entry parsers.HostsIterator
+	if err := entry.UnmarshalText([]byte(text)); err != nil {
+		// the error of each entry type would be noise
+		return nil, errUnsupported
+	}
+
+	var rules []Rule
+
+	err := entry.ForEach(func(host string) error {
+		switch {
+		case strings.HasPrefix(host, "*."):
+			rules = append(rules, Rule{Allow: allow, Kind: RuleKindWildcard, Value: host[2:]})
+		case len(host) > 1 && strings.HasPrefix(host, "/") && strings.HasSuffix(host, "/"):
+			rules = append(rules, Rule{Allow: allow, Kind: RuleKindRegex, Value: host[1 : len(host)-1]})
+		case net.ParseIP(host) != nil:
+			return errIPUnsupported
+		default:
+			rules = append(rules, Rule{Allow: allow, Kind: RuleKindExact, Value: host})
+		}
+
+		return nil
+	})
+
+	return rules, err
+}
+
+func writeBlocky(rule Rule) string {
+	switch rule.Kind {
+	case RuleKindWildcard:
+		return "*." + rule.Value
+	case RuleKindRegex:
+		return "/" + rule.Value + "/"
+	default:
+		return rule.Value
+	}
+}
+
+func readPihole(text string, allow bool) ([]Rule, error) {
+	if isDomain(text) {
+		return []Rule{{Allow: allow, Kind: RuleKindExact, Value: strings.ToLower(text)}}, nil
+	}
+
+	if domain, ok := strings.CutPrefix(text, piholeWildcardPrefix); ok {
+		if domain, ok := strings.CutSuffix(domain, piholeWildcardSuffix); ok {
+			if unescaped := strings.ReplaceAll(domain, `\.`, "."); isDomain(unescaped) {
+				return []Rule{{Allow: allow, Kind: RuleKindWildcard, Value: strings.ToLower(unescaped)}}, nil
+			}
+		}
+	}
+
+	if piholeExtensionRegex.MatchString(text) {
+		return nil, fmt.Errorf("%w: Pi-hole regex extensions", errUnsupported)
+	}
+
+	if _, err := regexp.Compile(text); err != nil {
+		return nil, err
+	}
+
+	return []Rule{{Allow: allow, Kind: RuleKindRegex, Value: text}}, nil
+}
+
+func writePihole(rule Rule) string {
+	switch rule.Kind {
+	case RuleKindWildcard:
+		return piholeWildcardPrefix + regexp.QuoteMeta(rule.Value) + piholeWildcardSuffix
+	default:
+		return rule.Value
+	}
+}
+
+func readAdguard(text string) ([]Rule, error) {
+	rule := Rule{}
+
+	text, rule.Allow = strings.CutPrefix(text, adguardAllowPrefix)
+
+	switch {
+	case len(text) > 1 && strings.HasPrefix(text, "/") && strings.HasSuffix(text, "/"):
+		rule.Kind = RuleKindRegex
+		rule.Value = text[1 : len(text)-1]
+
+		if _, err := regexp.Compile(rule.Value); err != nil {
+			return nil, err
+		}
+
+	case strings.HasPrefix(text, "||") && strings.HasSuffix(text, "^"):
+		rule.Kind = RuleKindWildcard
+		rule.Value = text[2 : len(text)-1]
+
+	case strings.HasPrefix(text, "|") && strings.HasSuffix(text, "^"):
+		rule.Kind = RuleKindExact
+		rule.Value = text[1 : len(text)-1]
+
+	default:
+		// domains-only and hosts syntax
+		return readBlocky(text, rule.Allow)
+	}
+
+	if rule.Kind != RuleKindRegex {
+		if !isDomain(rule.Value) {
+			return nil, errUnsupported
+		}
+
+		rule.Value = strings.ToLower(rule.Value)
+	}
+
+	return []Rule{rule}, nil
+}
+
+func writeAdguard(rule Rule) string {
+	var line string
+
+	switch rule.Kind {
+	case RuleKindWildcard:
+		line = "||" + rule.Value + "^"
+	case RuleKindRegex:
+		line = "/" + rule.Value + "/"
+	default:
+		line = "|" + rule.Value + "^"
+	}
+
+	if rule.Allow {
+		return adguardAllowPrefix + line
+	}
+
+	return line
+}
+
+func isDomain(text string) bool {
+	var entry parsers.HostListEntry
+
+	return entry.UnmarshalText([]byte(text)) == nil && net.ParseIP(text) == nil &&
+		!strings.HasPrefix(text, "/")
+}
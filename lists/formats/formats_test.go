@@ -0,0 +1,169 @@
+package formats
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func linesReader(lines ...string) *strings.Reader {
+	return strings.NewReader(strings.Join(lines, "\n"))
+}
+
+func write(format Format, rules []Rule) string {
+	var sb strings.Builder
+
+	ExpectWithOffset(1, Write(&sb, format, rules)).Should(Succeed())
+
+	return sb.String()
+}
+
+var _ = Describe("Formats", func() {
+	var rules []Rule
+
+	BeforeEach(func() {
+		rules = []Rule{
+			{Kind: RuleKindExact, Value: "ads.example.com"},
+			{Kind: RuleKindWildcard, Value: "tracker.net"},
+			{Kind: RuleKindRegex, Value: `^ad[0-9]+\.`},
+		}
+	})
+
+	Describe("blocky", func() {
+		It("reads domains, wildcards, regexes and hosts file entries", func() {
+			read, skipped, err := Read(linesReader(
+				"# comment",
+				"",
+				"ads.example.com # trailing comment",
+				"*.tracker.net",
+				`/^ad[0-9]+\./`,
+				"0.0.0.0 host1.com host2.com",
+				"1.2.3.4",
+			), FormatBlocky, true)
+			Expect(err).Should(Succeed())
+
+			Expect(read).Should(Equal([]Rule{
+				{Allow: true, Kind: RuleKindExact, Value: "ads.example.com"},
+				{Allow: true, Kind: RuleKindWildcard, Value: "tracker.net"},
+				{Allow: true, Kind: RuleKindRegex, Value: `^ad[0-9]+\.`},
+				{Allow: true, Kind: RuleKindExact, Value: "host1.com"},
+				{Allow: true, Kind: RuleKindExact, Value: "host2.com"},
+			}))
+
+			Expect(skipped).Should(HaveLen(1))
+			Expect(skipped[0].Line).Should(Equal(7))
+			Expect(skipped[0].Error()).Should(ContainSubstring("IP rules are not supported"))
+		})
+
+		It("writes", func() {
+			Expect(write(FormatBlocky, rules)).Should(Equal(
+				"ads.example.com\n*.tracker.net\n/^ad[0-9]+\\./\n",
+			))
+		})
+	})
+
+	Describe("pihole", func() {
+		It("reads exact domains, wildcards and regexes", func() {
+			read, skipped, err := Read(linesReader(
+				"# comment",
+				"ADS.example.com",
+				`(\.|^)tracker\.net$`,
+				`^ad[0-9]+\.`,
+				`^ad.*;querytype=AAAA`,
+				`^ad[`,
+			), FormatPihole, false)
+			Expect(err).Should(Succeed())
+
+			Expect(read).Should(Equal(rules))
+
+			Expect(skipped).Should(HaveLen(2))
+			Expect(skipped[0].Line).Should(Equal(5))
+			Expect(skipped[0].Err).Should(MatchError(ContainSubstring("Pi-hole regex extensions")))
+			Expect(skipped[1].Line).Should(Equal(6))
+		})
+
+		It("writes", func() {
+			Expect(write(FormatPihole, rules)).Should(Equal(
+				"ads.example.com\n(\\.|^)tracker\\.net$\n^ad[0-9]+\\.\n",
+			))
+		})
+	})
+
+	Describe("adguard", func() {
+		It("reads deny and allow rules", func() {
+			read, skipped, err := Read(linesReader(
+				"! comment",
+				"# comment",
+				"|ads.example.com^",
+				"||tracker.net^",
+				`/^ad[0-9]+\./`,
+				"@@||good.com^",
+				"@@plain.com",
+				"0.0.0.0 hosts.com",
+				"||ads.com^$important",
+				"example.org/banner",
+			), FormatAdguard, true)
+			Expect(err).Should(Succeed())
+
+			Expect(read).Should(Equal(append(rules,
+				Rule{Allow: true, Kind: RuleKindWildcard, Value: "good.com"},
+				Rule{Allow: true, Kind: RuleKindExact, Value: "plain.com"},
+				Rule{Kind: RuleKindExact, Value: "hosts.com"},
+			)))
+
+			Expect(skipped).Should(HaveLen(2))
+			Expect(skipped[0].Line).Should(Equal(9))
+			Expect(skipped[1].Line).Should(Equal(10))
+		})
+
+		It("writes deny and allow rules", func() {
+			rules = append(rules, Rule{Allow: true, Kind: RuleKindWildcard, Value: "good.com"})
+
+			Expect(write(FormatAdguard, rules)).Should(Equal(
+				"|ads.example.com^\n||tracker.net^\n/^ad[0-9]+\\./\n@@||good.com^\n",
+			))
+		})
+	})
+
+	DescribeTable("round trips", func(format Format, allow bool) {
+		for i := range rules {
+			rules[i].Allow = allow
+		}
+
+		read, skipped, err := Read(strings.NewReader(write(format, rules)), format, allow)
+		Expect(err).Should(Succeed())
+		Expect(skipped).Should(BeEmpty())
+		Expect(read).Should(Equal(rules))
+	},
+		Entry("blocky", FormatBlocky, false),
+		Entry("pihole", FormatPihole, true),
+		Entry("adguard deny", FormatAdguard, false),
+		Entry("adguard allow", FormatAdguard, true),
+	)
+
+	Describe("Import", func() {
+		It("converts the rules of the type and comments the others", func() {
+			var sb strings.Builder
+
+			Expect(Import(&sb, linesReader(
+				"@@||good.com^",
+				"||ads.com^",
+				"||ads.com^$third-party",
+			), FormatAdguard, false)).Should(Succeed())
+
+			Expect(sb.String()).Should(Equal(
+				"# skipped line 3 '||ads.com^$third-party': unsupported rule\n" +
+					"# skipped rule of the other type: *.good.com\n" +
+					"*.ads.com\n",
+			))
+		})
+	})
+
+	It("fails with an unknown format", func() {
+		_, _, err := Read(linesReader("a.com"), Format(99), false)
+		Expect(err).Should(HaveOccurred())
+
+		Expect(Write(&strings.Builder{}, Format(99), rules)).ShouldNot(Succeed())
+	})
+})
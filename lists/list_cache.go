@@ -2,10 +2,17 @@ package lists
 
 //go:generate go run github.com/abice/go-enum -f=$GOFILE --marshal --names
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -14,6 +21,7 @@ import (
 	"github.com/0xERR0R/blocky/evt"
 	"github.com/0xERR0R/blocky/lists/parsers"
 	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/watchdog"
 	"github.com/ThinkChaos/parcour"
 	"github.com/ThinkChaos/parcour/jobgroup"
 )
@@ -26,6 +34,8 @@ const (
 // ListCacheType represents the type of cached list ENUM(
 // denylist // is a list with blocked domains
 // allowlist // is a list with allowlisted domains / IPs
+// conditional // is a list with domains for conditional forwarding
+// feed // is a fast-refreshing threat-intel feed with blocked domains
 // )
 type ListCacheType int
 
@@ -35,6 +45,18 @@ type Matcher interface {
 	Match(domain string, groupsToCheck []string) (groups []string)
 }
 
+// GroupSharing shares the parsed entries of the groups between instances, so only the leader loads the sources
+type GroupSharing interface {
+	// IsLeader returns true if this instance loads the sources
+	IsLeader() bool
+
+	// Load returns the gzip compressed entries of the group loaded by the leader, one per line
+	Load(ctx context.Context, listType ListCacheType, group string) (io.ReadCloser, error)
+
+	// Store makes the gzip compressed entries of the group available to the other instances
+	Store(listType ListCacheType, group string, entries []byte)
+}
+
 // ListCache generic cache of strings divided in groups
 type ListCache struct {
 	groupedCache stringcache.GroupedStringCache
@@ -44,6 +66,10 @@ type ListCache struct {
 	listType     ListCacheType
 	groupSources map[string][]config.BytesSource
 	downloader   FileDownloader
+	sharing      GroupSharing
+
+	// state of the local files per group, only used by `watch`
+	fileStates map[string]map[string]fileState
 }
 
 // LogConfig implements `config.Configurable`.
@@ -68,10 +94,10 @@ func (b *ListCache) LogConfig(logger *logrus.Entry) {
 	logger.Infof("TOTAL: %d entries", total)
 }
 
-// NewListCache creates new list instance
+// NewListCache creates new list instance, sharing is optional
 func NewListCache(ctx context.Context,
 	t ListCacheType, cfg config.SourceLoading,
-	groupSources map[string][]config.BytesSource, downloader FileDownloader,
+	groupSources map[string][]config.BytesSource, downloader FileDownloader, sharing GroupSharing,
 ) (*ListCache, error) {
 	regexCache := stringcache.NewInMemoryGroupedRegexCache()
 
@@ -79,6 +105,7 @@ func NewListCache(ctx context.Context,
 		groupedCache: stringcache.NewChainedGroupedCache(
 			regexCache,
 			stringcache.NewInMemoryGroupedWildcardCache(), // must be after regex which can contain '*'
+			stringcache.NewInMemoryGroupedCIDRCache(),     // must be after regex which can contain '/'
 			stringcache.NewInMemoryGroupedStringCache(),   // accepts all values, must be last
 		),
 		regexCache: regexCache,
@@ -87,18 +114,87 @@ func NewListCache(ctx context.Context,
 		listType:     t,
 		groupSources: groupSources,
 		downloader:   downloader,
+		sharing:      sharing,
 	}
 
-	err := cfg.StartPeriodicRefresh(ctx, c.refresh, func(err error) {
+	watch := cfg.CheckPeriod.IsAboveZero() && c.hasFileSources()
+	if watch {
+		// before the initial load, so changes made while loading are picked up
+		c.fileStates = c.currentFileStates()
+	}
+
+	err := cfg.StartPeriodicRefresh(ctx, c.loader(), c.refresh, func(err error) {
 		logger().WithError(err).Errorf("could not init %s", t)
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if watch {
+		go c.watch(ctx)
+	}
+
 	return c, nil
 }
 
+func (b *ListCache) hasFileSources() bool {
+	for _, sources := range b.groupSources {
+		if hasFileSources(sources) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *ListCache) currentFileStates() map[string]map[string]fileState {
+	res := make(map[string]map[string]fileState, len(b.groupSources))
+
+	for group, sources := range b.groupSources {
+		res[group] = filesState(sources)
+	}
+
+	return res
+}
+
+// watch reloads the groups whose local files changed, were added or removed until ctx is done
+func (b *ListCache) watch(ctx context.Context) {
+	ticker := time.NewTicker(b.cfg.CheckPeriod.ToDuration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.reloadChangedFiles(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *ListCache) reloadChangedFiles(ctx context.Context) {
+	states := b.currentFileStates()
+	changed := make(map[string][]config.BytesSource)
+
+	for group, state := range states {
+		if !maps.Equal(state, b.fileStates[group]) {
+			changed[group] = b.groupSources[group]
+
+			logger().WithField("group", group).Infof("files of %s changed, reloading group", b.listType)
+		}
+	}
+
+	b.fileStates = states
+
+	if len(changed) == 0 {
+		return
+	}
+
+	if _, err := b.refreshGroups(ctx, changed); err != nil {
+		logger().WithError(err).Errorf("could not reload %s", b.listType)
+	}
+}
+
 func logger() *logrus.Entry {
 	return log.PrefixedLog("list_cache")
 }
@@ -114,17 +210,45 @@ func (b *ListCache) Refresh() error {
 }
 
 func (b *ListCache) refresh(ctx context.Context) error {
+	_, err := b.refreshGroups(ctx, b.groupSources)
+
+	return err
+}
+
+// loader returns the initial load: all groups on the first call, then only the groups which failed
+func (b *ListCache) loader() func(context.Context) error {
+	pending := b.groupSources
+
+	return func(ctx context.Context) error {
+		failed, err := b.refreshGroups(ctx, pending)
+		pending = failed
+
+		return err
+	}
+}
+
+// refreshGroups refreshes the groups and returns the ones which failed
+func (b *ListCache) refreshGroups(
+	ctx context.Context, groupSources map[string][]config.BytesSource,
+) (map[string][]config.BytesSource, error) {
+	var (
+		failedMu sync.Mutex
+		failed   = make(map[string][]config.BytesSource)
+	)
+
 	unlimitedGrp, _ := jobgroup.WithContext(ctx)
 	defer unlimitedGrp.Close()
 
 	producersGrp := jobgroup.WithMaxConcurrency(unlimitedGrp, b.cfg.Concurrency)
 	defer producersGrp.Close()
 
-	for group, sources := range b.groupSources {
+	for group, sources := range groupSources {
 		group, sources := group, sources
 
 		unlimitedGrp.Go(func(ctx context.Context) error {
-			err := b.createCacheForGroup(producersGrp, unlimitedGrp, group, sources)
+			defer watchdog.TrackWorker("list_loading")()
+
+			err := b.loadGroup(ctx, producersGrp, unlimitedGrp, group, sources)
 			if err != nil {
 				count := b.groupedCache.ElementCount(group)
 
@@ -139,6 +263,10 @@ func (b *ListCache) refresh(ctx context.Context) error {
 					logger.Warn("Populating of group cache failed, using existing cache, if any")
 				}
 
+				failedMu.Lock()
+				failed[group] = sources
+				failedMu.Unlock()
+
 				return err
 			}
 
@@ -155,7 +283,55 @@ func (b *ListCache) refresh(ctx context.Context) error {
 		})
 	}
 
-	return unlimitedGrp.Wait()
+	err := unlimitedGrp.Wait()
+
+	return failed, err
+}
+
+// loadGroup loads the group from the leader if the instance is a follower in a cluster, or from its sources
+func (b *ListCache) loadGroup(
+	ctx context.Context, producersGrp, consumersGrp jobgroup.JobGroup, group string, sources []config.BytesSource,
+) error {
+	if b.sharing == nil || b.sharing.IsLeader() {
+		return b.createCacheForGroup(producersGrp, consumersGrp, group, sources)
+	}
+
+	err := b.loadSharedGroup(ctx, group)
+	if err == nil {
+		return nil
+	}
+
+	logger().WithField("group", group).Warn("can't load group from the cluster leader, loading the sources: ", err)
+
+	return b.createCacheForGroup(producersGrp, consumersGrp, group, sources)
+}
+
+func (b *ListCache) loadSharedGroup(ctx context.Context, group string) error {
+	r, err := b.sharing.Load(ctx, b.listType, group)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+
+	groupFactory := b.groupedCache.Refresh(group)
+	scanner := bufio.NewScanner(gz)
+
+	for scanner.Scan() {
+		groupFactory.AddEntry(scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	groupFactory.Finish()
+
+	return nil
 }
 
 func (b *ListCache) createCacheForGroup(
@@ -163,10 +339,20 @@ func (b *ListCache) createCacheForGroup(
 ) error {
 	groupFactory := b.groupedCache.Refresh(group)
 
+	// the entries shared with the other instances of the cluster
+	var (
+		shared   bytes.Buffer
+		sharedGz *gzip.Writer
+	)
+
+	if b.sharing != nil {
+		sharedGz = gzip.NewWriter(&shared)
+	}
+
 	producers := parcour.NewProducersWithBuffer[string](producersGrp, consumersGrp, groupProducersBufferCap)
 	defer producers.Close()
 
-	for i, source := range sources {
+	for i, source := range expandGlobs(sources) {
 		i, source := i, source
 
 		producers.GoProduce(func(ctx context.Context, hostsChan chan<- string) error {
@@ -187,6 +373,10 @@ func (b *ListCache) createCacheForGroup(
 		for host := range ch {
 			if groupFactory.AddEntry(host) {
 				hasEntries = true
+
+				if sharedGz != nil {
+					_, _ = sharedGz.Write([]byte(host + "\n"))
+				}
 			} else {
 				logger().WithField("host", host).Warn("no list cache was able to use host")
 			}
@@ -212,6 +402,10 @@ func (b *ListCache) createCacheForGroup(
 
 	groupFactory.Finish()
 
+	if sharedGz != nil && sharedGz.Close() == nil {
+		b.sharing.Store(b.listType, group, shared.Bytes())
+	}
+
 	return nil
 }
 
@@ -18,15 +18,23 @@ const (
 	// ListCacheTypeAllowlist is a ListCacheType of type Allowlist.
 	// is a list with allowlisted domains / IPs
 	ListCacheTypeAllowlist
+	// ListCacheTypeConditional is a ListCacheType of type Conditional.
+	// is a list with domains for conditional forwarding
+	ListCacheTypeConditional
+	// ListCacheTypeFeed is a ListCacheType of type Feed.
+	// is a fast-refreshing threat-intel feed with blocked domains
+	ListCacheTypeFeed
 )
 
 var ErrInvalidListCacheType = fmt.Errorf("not a valid ListCacheType, try [%s]", strings.Join(_ListCacheTypeNames, ", "))
 
-const _ListCacheTypeName = "denylistallowlist"
+const _ListCacheTypeName = "denylistallowlistconditionalfeed"
 
 var _ListCacheTypeNames = []string{
 	_ListCacheTypeName[0:8],
 	_ListCacheTypeName[8:17],
+	_ListCacheTypeName[17:28],
+	_ListCacheTypeName[28:32],
 }
 
 // ListCacheTypeNames returns a list of possible string values of ListCacheType.
@@ -37,8 +45,10 @@ func ListCacheTypeNames() []string {
 }
 
 var _ListCacheTypeMap = map[ListCacheType]string{
-	ListCacheTypeDenylist:  _ListCacheTypeName[0:8],
-	ListCacheTypeAllowlist: _ListCacheTypeName[8:17],
+	ListCacheTypeDenylist:    _ListCacheTypeName[0:8],
+	ListCacheTypeAllowlist:   _ListCacheTypeName[8:17],
+	ListCacheTypeConditional: _ListCacheTypeName[17:28],
+	ListCacheTypeFeed:        _ListCacheTypeName[28:32],
 }
 
 // String implements the Stringer interface.
@@ -57,8 +67,10 @@ func (x ListCacheType) IsValid() bool {
 }
 
 var _ListCacheTypeValue = map[string]ListCacheType{
-	_ListCacheTypeName[0:8]:  ListCacheTypeDenylist,
-	_ListCacheTypeName[8:17]: ListCacheTypeAllowlist,
+	_ListCacheTypeName[0:8]:   ListCacheTypeDenylist,
+	_ListCacheTypeName[8:17]:  ListCacheTypeAllowlist,
+	_ListCacheTypeName[17:28]: ListCacheTypeConditional,
+	_ListCacheTypeName[28:32]: ListCacheTypeFeed,
 }
 
 // ParseListCacheType attempts to convert a string to a ListCacheType.
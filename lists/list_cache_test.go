@@ -2,13 +2,18 @@ package lists
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/0xERR0R/blocky/config"
 	. "github.com/0xERR0R/blocky/evt"
@@ -35,6 +40,7 @@ var _ = Describe("ListCache", func() {
 		lists          map[string][]config.BytesSource
 		downloader     FileDownloader
 		mockDownloader *MockDownloader
+		sharing        GroupSharing
 		ctx            context.Context
 		cancelFn       context.CancelFunc
 		err            error
@@ -55,6 +61,7 @@ var _ = Describe("ListCache", func() {
 
 		downloader = NewDownloader(config.Downloader{}, nil)
 		mockDownloader = nil
+		sharing = nil
 
 		server1 = TestServer("blocked1.com\nblocked1a.com\n192.168.178.55")
 		server2 = TestServer("blocked2.com")
@@ -77,7 +84,7 @@ var _ = Describe("ListCache", func() {
 			downloader = mockDownloader
 		}
 
-		sut, err = NewListCache(ctx, listCacheType, sutConfig, lists, downloader)
+		sut, err = NewListCache(ctx, listCacheType, sutConfig, lists, downloader, sharing)
 		if expectFail {
 			Expect(err).Should(HaveOccurred())
 		} else {
@@ -306,7 +313,7 @@ var _ = Describe("ListCache", func() {
 				}
 			})
 			It("should match", func() {
-				sut, err = NewListCache(ctx, ListCacheTypeDenylist, sutConfig, lists, downloader)
+				sut, err = NewListCache(ctx, ListCacheTypeDenylist, sutConfig, lists, downloader, nil)
 				Expect(err).Should(Succeed())
 
 				Expect(sut.groupedCache.ElementCount("gr1")).Should(Equal(lines1 + lines2 + lines3))
@@ -392,6 +399,62 @@ var _ = Describe("ListCache", func() {
 			})
 		})
 	})
+	Describe("local files", func() {
+		var listDir *TmpFolder
+
+		BeforeEach(func() {
+			listDir = tmpDir.CreateSubFolder("lists")
+			listDir.CreateStringFile("a.txt", "glob-a.com")
+			listDir.CreateStringFile("b.txt", "glob-b.com")
+			listDir.CreateStringFile("c.conf", "glob-c.com")
+
+			lists = map[string][]config.BytesSource{
+				"gr1": config.NewBytesSources(listDir.JoinPath("*.txt")),
+				"gr2": config.NewBytesSources(file2.Path),
+			}
+		})
+
+		When("a glob pattern is used", func() {
+			It("should load all matching files", func() {
+				Expect(sut.groupedCache.ElementCount("gr1")).Should(Equal(2))
+				Expect(sut.Match("glob-a.com", []string{"gr1"})).Should(ContainElement("gr1"))
+				Expect(sut.Match("glob-b.com", []string{"gr1"})).Should(ContainElement("gr1"))
+				Expect(sut.Match("glob-c.com", []string{"gr1"})).Should(BeEmpty())
+			})
+		})
+
+		When("files are checked for changes", func() {
+			BeforeEach(func() {
+				sutConfig.CheckPeriod = config.Duration(10 * time.Millisecond)
+			})
+
+			It("should reload the group if a matching file is added", func() {
+				listDir.CreateStringFile("d.txt", "glob-d.com")
+
+				Eventually(func() []string {
+					return sut.Match("glob-d.com", []string{"gr1"})
+				}, "1s").Should(ContainElement("gr1"))
+			})
+
+			It("should reload the group if a file changes", func() {
+				Expect(os.WriteFile(file2.Path, []byte("changed.com\nother.com\n"), 0o600)).Should(Succeed())
+
+				Eventually(func() []string {
+					return sut.Match("changed.com", []string{"gr2"})
+				}, "1s").Should(ContainElement("gr2"))
+				Expect(sut.Match("blocked2.com", []string{"gr2"})).Should(BeEmpty())
+			})
+
+			It("should reload the group if a file is removed", func() {
+				Expect(os.Remove(listDir.JoinPath("a.txt"))).Should(Succeed())
+
+				Eventually(func() []string {
+					return sut.Match("glob-a.com", []string{"gr1"})
+				}, "1s").Should(BeEmpty())
+			})
+		})
+	})
+
 	Describe("LogConfig", func() {
 		var (
 			logger *logrus.Entry
@@ -408,7 +471,7 @@ var _ = Describe("ListCache", func() {
 		})
 
 		It("should print list configuration", func() {
-			sut, err = NewListCache(ctx, ListCacheTypeDenylist, sutConfig, lists, downloader)
+			sut, err = NewListCache(ctx, ListCacheTypeDenylist, sutConfig, lists, downloader, nil)
 			Expect(err).Should(Succeed())
 
 			sut.LogConfig(logger)
@@ -432,13 +495,154 @@ var _ = Describe("ListCache", func() {
 			})
 
 			It("should never return an error", func() {
-				_, err := NewListCache(ctx, ListCacheTypeDenylist, sutConfig, lists, downloader)
+				_, err := NewListCache(ctx, ListCacheTypeDenylist, sutConfig, lists, downloader, nil)
 				Expect(err).Should(Succeed())
 			})
 		})
+
+		When("retries are enabled", func() {
+			var (
+				downloads   atomic.Int32
+				missingFile string
+			)
+
+			BeforeEach(func() {
+				sutConfig.Strategy = config.InitStrategyFast
+				sutConfig.Retry = config.InitRetry{
+					MinDelay: config.Duration(10 * time.Millisecond),
+					MaxDelay: config.Duration(10 * time.Millisecond),
+				}
+
+				downloads.Store(0)
+
+				srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+					downloads.Add(1)
+
+					_, _ = rw.Write([]byte("blocked1.com"))
+				}))
+				DeferCleanup(srv.Close)
+
+				missingFile = tmpDir.JoinPath("missing")
+
+				lists = map[string][]config.BytesSource{
+					"gr1": config.NewBytesSources(srv.URL),
+					"gr2": config.NewBytesSources(missingFile),
+				}
+			})
+
+			It("should only retry the failed groups", func() {
+				Eventually(func() []string {
+					return sut.Match("blocked1.com", []string{"gr1"})
+				}, "1s").Should(ContainElement("gr1"))
+
+				Expect(os.WriteFile(missingFile, []byte("blocked2.com"), 0o600)).Should(Succeed())
+
+				Eventually(func() []string {
+					return sut.Match("blocked2.com", []string{"gr2"})
+				}, "1s").Should(ContainElement("gr2"))
+
+				Expect(downloads.Load()).Should(BeNumerically("==", 1))
+			})
+		})
+	})
+
+	Describe("group sharing", func() {
+		var mockSharing *MockGroupSharing
+
+		BeforeEach(func() {
+			mockSharing = &MockGroupSharing{stored: make(map[string][]byte)}
+			sharing = mockSharing
+
+			lists = map[string][]config.BytesSource{
+				"gr1": {config.TextBytesSource("blocked1.com", "blocked2.com")},
+			}
+		})
+
+		When("the instance is the leader", func() {
+			BeforeEach(func() {
+				mockSharing.leader = true
+			})
+
+			It("should load the sources and store the entries", func() {
+				Expect(sut.Match("blocked1.com", []string{"gr1"})).Should(ConsistOf("gr1"))
+				Expect(mockSharing.loaded).Should(BeFalse())
+
+				Expect(readGzip(mockSharing.stored["denylist:gr1"])).Should(Equal("blocked1.com\nblocked2.com\n"))
+			})
+		})
+
+		When("the instance is a follower", func() {
+			BeforeEach(func() {
+				mockSharing.load = gzipLines("shared.com")
+			})
+
+			It("should load the entries of the leader", func() {
+				Expect(mockSharing.loaded).Should(BeTrue())
+				Expect(sut.Match("shared.com", []string{"gr1"})).Should(ConsistOf("gr1"))
+				Expect(sut.Match("blocked1.com", []string{"gr1"})).Should(BeEmpty())
+			})
+		})
+
+		When("the leader can't provide the entries", func() {
+			It("should load the sources", func() {
+				Expect(mockSharing.loaded).Should(BeTrue())
+				Expect(sut.Match("blocked1.com", []string{"gr1"})).Should(ConsistOf("gr1"))
+			})
+		})
 	})
 })
 
+// MockGroupSharing is a cluster which shares the configured entries, nil entries make the load fail
+type MockGroupSharing struct {
+	leader bool
+	load   []byte
+	loaded bool
+	stored map[string][]byte
+}
+
+func (m *MockGroupSharing) IsLeader() bool {
+	return m.leader
+}
+
+func (m *MockGroupSharing) Load(_ context.Context, _ ListCacheType, _ string) (io.ReadCloser, error) {
+	m.loaded = true
+
+	if m.load == nil {
+		return nil, errors.New("not loaded")
+	}
+
+	return io.NopCloser(bytes.NewReader(m.load)), nil
+}
+
+func (m *MockGroupSharing) Store(listType ListCacheType, group string, entries []byte) {
+	m.stored[listType.String()+":"+group] = entries
+}
+
+func gzipLines(lines ...string) []byte {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+
+	for _, line := range lines {
+		_, err := fmt.Fprintln(w, line)
+		Expect(err).Should(Succeed())
+	}
+
+	Expect(w.Close()).Should(Succeed())
+
+	return buf.Bytes()
+}
+
+func readGzip(data []byte) string {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	Expect(err).Should(Succeed())
+
+	res, err := io.ReadAll(r)
+	Expect(err).Should(Succeed())
+
+	return string(res)
+}
+
 type MockDownloader struct {
 	MockCallSequence[string]
 }
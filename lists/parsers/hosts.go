@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/netip"
 	"regexp"
 	"strings"
 
@@ -26,6 +27,9 @@ const (
 // https://www.rfc-editor.org/rfc/rfc1034#section-3.5
 var domainNameRegex = regexp.MustCompile(`^` + dnsLabelPattern + `(\.` + dnsLabelPattern + `)*[\._]?$`)
 
+// Entry matching the country of answer IPs, e.g. `country:US`
+var countryEntryRegex = regexp.MustCompile(`^country:[a-zA-Z]{2}$`)
+
 // Hosts parses `r` as a series of `HostsIterator`.
 // It supports both the hosts file and host list formats.
 //
@@ -275,6 +279,14 @@ func validateHostsListEntry(host string) error {
 		return nil
 	}
 
+	if _, err := netip.ParsePrefix(host); err == nil {
+		return nil
+	}
+
+	if countryEntryRegex.MatchString(host) {
+		return nil
+	}
+
 	if isRegex(host) {
 		_, err := regexp.Compile(host)
 
@@ -37,6 +37,9 @@ var _ = Describe("Hosts", func() {
 				`/^(.*\.)?2023\.xn--aptslabs-6fd\.net$/`,
 				`müller.com`,
 				`*.example.com`,
+				"10.0.0.0/8",
+				"2001:db8::/32",
+				"country:US",
 			)
 		})
 
@@ -76,11 +79,26 @@ var _ = Describe("Hosts", func() {
 			Expect(iteratorToList(it.ForEach)).Should(Equal([]string{"*.example.com"}))
 			Expect(sut.Position()).Should(Equal("line 9"))
 
+			it, err = sut.Next(context.Background())
+			Expect(err).Should(Succeed())
+			Expect(iteratorToList(it.ForEach)).Should(Equal([]string{"10.0.0.0/8"}))
+			Expect(sut.Position()).Should(Equal("line 10"))
+
+			it, err = sut.Next(context.Background())
+			Expect(err).Should(Succeed())
+			Expect(iteratorToList(it.ForEach)).Should(Equal([]string{"2001:db8::/32"}))
+			Expect(sut.Position()).Should(Equal("line 11"))
+
+			it, err = sut.Next(context.Background())
+			Expect(err).Should(Succeed())
+			Expect(iteratorToList(it.ForEach)).Should(Equal([]string{"country:US"}))
+			Expect(sut.Position()).Should(Equal("line 12"))
+
 			_, err = sut.Next(context.Background())
 			Expect(err).Should(HaveOccurred())
 			Expect(err).Should(MatchError(io.EOF))
 			Expect(IsNonResumableErr(err)).Should(BeTrue())
-			Expect(sut.Position()).Should(Equal("line 10"))
+			Expect(sut.Position()).Should(Equal("line 13"))
 		})
 	})
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/0xERR0R/blocky/config"
@@ -68,3 +69,67 @@ func (o *fileOpener) Open(_ context.Context) (io.ReadCloser, error) {
 func (o *fileOpener) String() string {
 	return o.source.String()
 }
+
+// expandGlobs replaces the file sources with glob patterns by the matching files
+func expandGlobs(sources []config.BytesSource) []config.BytesSource {
+	res := make([]config.BytesSource, 0, len(sources))
+
+	for _, source := range sources {
+		if source.Type != config.BytesSourceTypeFile || !strings.ContainsAny(source.From, "*?[") {
+			res = append(res, source)
+
+			continue
+		}
+
+		matches, err := filepath.Glob(source.From)
+		if err != nil {
+			// invalid pattern: keep it, opening it reports the error
+			res = append(res, source)
+
+			continue
+		}
+
+		for _, match := range matches {
+			res = append(res, config.BytesSource{Type: config.BytesSourceTypeFile, From: match})
+		}
+	}
+
+	return res
+}
+
+type fileState struct {
+	modTime int64
+	size    int64
+}
+
+// filesState returns the state of the local files of the sources, to detect changes
+func filesState(sources []config.BytesSource) map[string]fileState {
+	res := make(map[string]fileState)
+
+	for _, source := range expandGlobs(sources) {
+		if source.Type != config.BytesSourceTypeFile {
+			continue
+		}
+
+		var state fileState
+
+		if info, err := os.Stat(source.From); err == nil {
+			state = fileState{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		}
+
+		res[source.From] = state
+	}
+
+	return res
+}
+
+// hasFileSources returns true if one of the sources is a local file
+func hasFileSources(sources []config.BytesSource) bool {
+	for _, source := range sources {
+		if source.Type == config.BytesSourceTypeFile {
+			return true
+		}
+	}
+
+	return false
+}
@@ -142,6 +142,26 @@ func (f nopFormatter) Format(*logrus.Entry) ([]byte, error) {
 	return nil, nil
 }
 
+// CaptureMessages returns the messages logged by the callback with the passed logger, one per line
+func CaptureMessages(callback func(*logrus.Entry)) string {
+	var buf strings.Builder
+
+	capture := logrus.New()
+	capture.SetOutput(&buf)
+	capture.SetFormatter(messageFormatter{})
+
+	callback(logrus.NewEntry(capture))
+
+	return buf.String()
+}
+
+// messageFormatter writes only the message
+type messageFormatter struct{}
+
+func (f messageFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return []byte(entry.Message + "\n"), nil
+}
+
 func WithIndent(log *logrus.Entry, prefix string, callback func(*logrus.Entry)) {
 	undo := indentMessages(prefix, log.Logger)
 	defer undo()
@@ -153,7 +173,9 @@ func WithIndent(log *logrus.Entry, prefix string, callback func(*logrus.Entry))
 //
 // The returned function must be called to remove the prefix.
 func indentMessages(prefix string, logger *logrus.Logger) func() {
-	if _, ok := logger.Formatter.(*prefixed.TextFormatter); !ok {
+	switch logger.Formatter.(type) {
+	case *prefixed.TextFormatter, messageFormatter:
+	default:
 		// log is not plaintext, do nothing
 		return func() {}
 	}
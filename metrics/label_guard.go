@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/0xERR0R/blocky/log"
+)
+
+// OverflowLabelValue replaces label values once a LabelGuard is full
+const OverflowLabelValue = "other"
+
+// LabelGuard limits the number of distinct values of a metric label to protect
+// prometheus from unbounded cardinality (e.g. one time series per client).
+type LabelGuard struct {
+	name      string
+	maxValues uint
+	values    map[string]struct{}
+	warned    bool
+	lock      sync.Mutex
+}
+
+// NewLabelGuard creates a guard for the label `name` allowing up to maxValues distinct values.
+// 0 means unlimited.
+func NewLabelGuard(name string, maxValues uint) *LabelGuard {
+	return &LabelGuard{
+		name:      name,
+		maxValues: maxValues,
+		values:    make(map[string]struct{}),
+	}
+}
+
+// Value returns the label value to use for v: v itself, or OverflowLabelValue if the limit is reached
+func (g *LabelGuard) Value(v string) string {
+	if g.maxValues == 0 {
+		return v
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if _, ok := g.values[v]; ok {
+		return v
+	}
+
+	if uint(len(g.values)) < g.maxValues {
+		g.values[v] = struct{}{}
+
+		return v
+	}
+
+	if !g.warned {
+		g.warned = true
+
+		log.PrefixedLog("metrics").Warnf("more than %d distinct values for label '%s', reporting new values as '%s'",
+			g.maxValues, g.name, OverflowLabelValue)
+	}
+
+	return OverflowLabelValue
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LabelGuard", func() {
+	When("the limit is reached", func() {
+		It("should replace new values", func() {
+			sut := NewLabelGuard("client", 2)
+
+			Expect(sut.Value("a")).Should(Equal("a"))
+			Expect(sut.Value("b")).Should(Equal("b"))
+			Expect(sut.Value("c")).Should(Equal(OverflowLabelValue))
+
+			By("keeping known values", func() {
+				Expect(sut.Value("a")).Should(Equal("a"))
+			})
+		})
+	})
+
+	When("the limit is 0", func() {
+		It("should not limit values", func() {
+			sut := NewLabelGuard("client", 0)
+
+			for _, v := range []string{"a", "b", "c"} {
+				Expect(sut.Value(v)).Should(Equal(v))
+			}
+		})
+	})
+})
@@ -9,6 +9,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// nativeHistogramBucketFactor controls the resolution of native histograms.
+// The value of 1.05 is slightly higher accuracy than the default of 1.1.
+const nativeHistogramBucketFactor = 1.05
+
 //nolint:gochecknoglobals
 var Reg = prometheus.NewRegistry()
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/evt"
 	"github.com/0xERR0R/blocky/lists"
 	"github.com/0xERR0R/blocky/util"
@@ -12,10 +13,22 @@ import (
 )
 
 // RegisterEventListeners registers all metric handlers by the event bus
-func RegisterEventListeners() {
+func RegisterEventListeners(cfg config.Metrics) {
 	registerBlockingEventListeners()
 	registerCachingEventListeners()
 	registerApplicationEventListeners()
+	registerWatchdogEventListeners()
+	registerRateLimitEventListeners()
+	registerNewDomainEventListeners()
+	registerTunnelingEventListeners()
+
+	if cfg.Enable && cfg.PerUpstream {
+		registerUpstreamEventListeners(NewLabelGuard("upstream", cfg.MaxLabelValues))
+	}
+
+	if cfg.Enable && cfg.PerGroup {
+		registerGroupEventListeners(NewLabelGuard("group", cfg.MaxLabelValues))
+	}
 }
 
 func registerApplicationEventListeners() {
@@ -38,6 +51,84 @@ func versionNumberGauge() *prometheus.GaugeVec {
 	return denylistCnt
 }
 
+func registerWatchdogEventListeners() {
+	RegisterMetric(newWorkersCollector())
+
+	alarmCount := watchdogAlarmCount()
+
+	RegisterMetric(alarmCount)
+
+	subscribe(evt.WatchdogAlarm, func(resource string, _ int64) {
+		alarmCount.WithLabelValues(resource).Inc()
+	})
+}
+
+func watchdogAlarmCount() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_watchdog_alarms_total",
+			Help: "Number of possible leaks detected by the watchdog per resource",
+		}, []string{"resource"},
+	)
+}
+
+func registerRateLimitEventListeners() {
+	limitedCount := rateLimitedCount()
+
+	RegisterMetric(limitedCount)
+
+	subscribe(evt.RateLimited, func(limit string) {
+		limitedCount.WithLabelValues(limit).Inc()
+	})
+}
+
+func rateLimitedCount() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_rate_limited_total",
+			Help: "Number of queries exceeding a rate limit of their client per limit",
+		}, []string{"limit"},
+	)
+}
+
+func registerNewDomainEventListeners() {
+	detectedCount := newDomainsCount()
+
+	RegisterMetric(detectedCount)
+
+	subscribe(evt.NewDomainDetected, func(kind string) {
+		detectedCount.WithLabelValues(kind).Inc()
+	})
+}
+
+func newDomainsCount() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_new_domains_total",
+			Help: "Number of queries for newly observed or registered domains per kind",
+		}, []string{"kind"},
+	)
+}
+
+func registerTunnelingEventListeners() {
+	suspectedCount := tunnelingSuspectedCount()
+
+	RegisterMetric(suspectedCount)
+
+	subscribe(evt.TunnelingSuspected, func(_, _ string) {
+		suspectedCount.Inc()
+	})
+}
+
+func tunnelingSuspectedCount() prometheus.Counter {
+	return prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "blocky_tunneling_suspected_total",
+			Help: "Number of clients and zones suspected of DNS tunneling",
+		},
+	)
+}
+
 func registerBlockingEventListeners() {
 	enabledGauge := enabledGauge()
 
@@ -61,7 +152,24 @@ func registerBlockingEventListeners() {
 	RegisterMetric(allowlistCnt)
 	RegisterMetric(lastListGroupRefresh)
 
+	feedCnt := feedGauge()
+	feedLastRefresh := feedLastRefresh()
+	feedStale := feedStaleGauge()
+
+	RegisterMetric(feedCnt)
+	RegisterMetric(feedLastRefresh)
+	RegisterMetric(feedStale)
+
 	subscribe(evt.BlockingCacheGroupChanged, func(listType lists.ListCacheType, groupName string, cnt int) {
+		if listType == lists.ListCacheTypeFeed {
+			// feeds are refreshed every few minutes and would hide outdated lists
+			feedCnt.WithLabelValues(groupName).Set(float64(cnt))
+			feedLastRefresh.WithLabelValues(groupName).Set(float64(time.Now().Unix()))
+			feedStale.WithLabelValues(groupName).Set(0)
+
+			return
+		}
+
 		lastListGroupRefresh.Set(float64(time.Now().Unix()))
 
 		switch listType {
@@ -71,6 +179,22 @@ func registerBlockingEventListeners() {
 			allowlistCnt.WithLabelValues(groupName).Set(float64(cnt))
 		}
 	})
+
+	subscribe(evt.BlockingFeedStale, func(feed string, stale bool) {
+		if stale {
+			feedStale.WithLabelValues(feed).Set(1)
+		} else {
+			feedStale.WithLabelValues(feed).Set(0)
+		}
+	})
+
+	categoryHits := categoryHitCount()
+
+	RegisterMetric(categoryHits)
+
+	subscribe(evt.BlockingCategoryHit, func(category string) {
+		categoryHits.WithLabelValues(category).Inc()
+	})
 }
 
 func enabledGauge() prometheus.Gauge {
@@ -114,6 +238,122 @@ func lastListGroupRefresh() prometheus.Gauge {
 	)
 }
 
+func feedGauge() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "blocky_feed_cache_entries",
+			Help: "Number of entries in the cache of a threat-intel feed",
+		}, []string{"feed"},
+	)
+}
+
+func feedLastRefresh() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "blocky_feed_last_refresh_timestamp_seconds",
+			Help: "Timestamp of the last successful refresh of a threat-intel feed",
+		}, []string{"feed"},
+	)
+}
+
+func feedStaleGauge() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "blocky_feed_stale",
+			Help: "1 if a threat-intel feed was not refreshed within its max age",
+		}, []string{"feed"},
+	)
+}
+
+func registerUpstreamEventListeners(guard *LabelGuard) {
+	duration := upstreamDurationHistogram()
+	errorCount := upstreamErrorCount()
+	healthy := upstreamHealthyGauge()
+
+	RegisterMetric(duration)
+	RegisterMetric(errorCount)
+	RegisterMetric(healthy)
+
+	subscribe(evt.UpstreamQueried, func(upstream string, d time.Duration, failed bool) {
+		upstream = guard.Value(upstream)
+
+		if failed {
+			errorCount.WithLabelValues(upstream).Inc()
+
+			return
+		}
+
+		duration.WithLabelValues(upstream).Observe(d.Seconds())
+	})
+
+	subscribe(evt.UpstreamHealthChanged, func(upstream string, isHealthy bool) {
+		upstream = guard.Value(upstream)
+
+		if isHealthy {
+			healthy.WithLabelValues(upstream).Set(1)
+		} else {
+			healthy.WithLabelValues(upstream).Set(0)
+		}
+	})
+}
+
+func upstreamDurationHistogram() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:                        "blocky_upstream_request_duration_seconds",
+			Help:                        "Upstream request duration distribution per upstream",
+			Buckets:                     []float64{0.005, 0.01, 0.02, 0.03, 0.05, 0.075, 0.1, 0.2, 0.5, 1.0, 2.0},
+			NativeHistogramBucketFactor: nativeHistogramBucketFactor,
+		}, []string{"upstream"},
+	)
+}
+
+func upstreamErrorCount() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_upstream_errors_total",
+			Help: "Number of failed upstream requests per upstream",
+		}, []string{"upstream"},
+	)
+}
+
+func upstreamHealthyGauge() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "blocky_upstream_healthy",
+			Help: "Health check status per upstream: 1 if healthy, 0 if out of rotation",
+		}, []string{"upstream"},
+	)
+}
+
+func registerGroupEventListeners(guard *LabelGuard) {
+	hits := groupHitCount()
+
+	RegisterMetric(hits)
+
+	subscribe(evt.BlockingGroupHit, func(group string) {
+		hits.WithLabelValues(guard.Value(group)).Inc()
+	})
+}
+
+func groupHitCount() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_blocking_group_hits_total",
+			Help: "Number of blocked queries per denylist group",
+		}, []string{"group"},
+	)
+}
+
+func categoryHitCount() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_blocking_category_hits_total",
+			Help: "Number of blocked queries per category of the denylist groups",
+		}, []string{"category"},
+	)
+}
+
 func registerCachingEventListeners() {
 	entryCount := cacheEntryCount()
 	prefetchDomainCount := prefetchDomainCacheCount()
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"github.com/0xERR0R/blocky/watchdog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// workersCollector exposes the running workers per subsystem, counted by `watchdog.TrackWorker`
+type workersCollector struct {
+	desc *prometheus.Desc
+}
+
+func newWorkersCollector() *workersCollector {
+	return &workersCollector{
+		desc: prometheus.NewDesc(
+			"blocky_workers",
+			"Number of running workers per subsystem",
+			[]string{"subsystem"}, nil,
+		),
+	}
+}
+
+// Describe implements `prometheus.Collector`.
+func (c *workersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements `prometheus.Collector`.
+func (c *workersCollector) Collect(ch chan<- prometheus.Metric) {
+	for subsystem, cnt := range watchdog.Workers() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(cnt), subsystem)
+	}
+}
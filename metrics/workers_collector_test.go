@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"strings"
+
+	"github.com/0xERR0R/blocky/watchdog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("WorkersCollector", func() {
+	It("should expose the running workers per subsystem", func() {
+		done := watchdog.TrackWorker("collector_test")
+		DeferCleanup(done)
+
+		expected := `
+# HELP blocky_workers Number of running workers per subsystem
+# TYPE blocky_workers gauge
+blocky_workers{subsystem="collector_test"} 1
+`
+
+		Expect(testutil.CollectAndCompare(newWorkersCollector(), strings.NewReader(expected), "blocky_workers")).
+			Should(Succeed())
+	})
+})
@@ -51,6 +51,8 @@ type Response struct {
 	Res    *dns.Msg
 	Reason string
 	RType  ResponseType
+	// Categories of the denylist groups which blocked the query
+	Categories []string
 }
 
 // RequestProtocol represents the server protocol ENUM(
@@ -65,6 +67,45 @@ type Request struct {
 	RequestClientID string
 	Protocol        RequestProtocol
 	ClientNames     []string
+	ClientMAC       net.HardwareAddr
 	Req             *dns.Msg
 	RequestTS       time.Time
+	// EDNS of the query as received from the client, nil without OPT record
+	EDNS *EDNS
+}
+
+// EDNS describes the EDNS(0) options of a query
+type EDNS struct {
+	// UDPSize is the advertised UDP buffer size
+	UDPSize uint16
+	// DO is the DNSSEC OK bit
+	DO bool
+	// ECS is true if the query contains an EDNS Client Subnet option
+	ECS bool
+	// Cookie is true if the query contains a DNS Cookie
+	Cookie bool
+}
+
+// NewEDNS returns the EDNS of the message, nil if it has no OPT record
+func NewEDNS(msg *dns.Msg) *EDNS {
+	opt := msg.IsEdns0()
+	if opt == nil {
+		return nil
+	}
+
+	edns := EDNS{
+		UDPSize: opt.UDPSize(),
+		DO:      opt.Do(),
+	}
+
+	for _, o := range opt.Option {
+		switch o.Option() {
+		case dns.EDNS0SUBNET:
+			edns.ECS = true
+		case dns.EDNS0COOKIE:
+			edns.Cookie = true
+		}
+	}
+
+	return &edns
 }
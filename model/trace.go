@@ -0,0 +1,20 @@
+package model
+
+// TraceStep is a decision of a resolver while resolving a traced query
+type TraceStep struct {
+	// Type of the resolver, e.g. "blocking"
+	Resolver string
+	Message  string
+}
+
+// Trace describes how a query was resolved by the resolver chain
+type Trace struct {
+	// Steps in the order of the resolver chain
+	Steps []TraceStep
+	// Result of the cache lookup: "HIT" or "MISS", empty if the cache is disabled or wasn't reached
+	Cache string
+	// List groups matching the query: denylist groups if it was blocked, allowlist groups if it was allowed
+	Lists []string
+	// Upstream group the query was sent to, empty if no upstream was queried
+	UpstreamGroup string
+}
@@ -0,0 +1,362 @@
+// Package nats synchronizes the cache and the blocking state of multiple instances through NATS
+package nats
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/0xERR0R/blocky/cachesync"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/miekg/dns"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	chanCap           = 1000
+	messageTypeCache  = 0
+	messageTypeEnable = 1
+)
+
+// storeMessage is a cache entry which is written to the bucket
+type storeMessage struct {
+	key    string
+	packed []byte
+}
+
+// natsMessage is the payload of the messages on the sync subject
+type natsMessage struct {
+	Key     string `json:"k,omitempty"`
+	Type    int    `json:"t"`
+	Message []byte `json:"m"`
+}
+
+// Client for NATS communication
+type Client struct {
+	config *config.NATS
+	conn   *nats.Conn
+	kv     jetstream.KeyValue
+	l      *logrus.Entry
+
+	storeBuffer    chan *storeMessage
+	cacheChannel   chan *cachesync.CacheMessage
+	enabledChannel chan *cachesync.EnabledMessage
+}
+
+// New creates a new NATS client, the messages of this instance are not delivered back to it
+func New(ctx context.Context, cfg *config.NATS) (*Client, error) {
+	// disable NATS if no URL is provided
+	if cfg == nil || len(cfg.URL) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	opts := []nats.Option{
+		nats.Name("blocky"),
+		nats.NoEcho(),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(cfg.ConnectionCooldown.ToDuration()),
+	}
+
+	if cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+
+	if cfg.TLS != nil {
+		// empty server name: the client uses the host of the URL
+		tlsCfg, err := cfg.TLS.NewClientTLSConfig("")
+		if err != nil {
+			return nil, fmt.Errorf("can't create NATS TLS config: %w", err)
+		}
+
+		opts = append(opts, nats.Secure(tlsCfg))
+	}
+
+	conn, err := connect(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Client{
+		config:         cfg,
+		conn:           conn,
+		l:              log.PrefixedLog("nats"),
+		storeBuffer:    make(chan *storeMessage, chanCap),
+		cacheChannel:   make(chan *cachesync.CacheMessage, chanCap),
+		enabledChannel: make(chan *cachesync.EnabledMessage, chanCap),
+	}
+
+	if cfg.Bucket != "" {
+		res.kv, err = createBucket(ctx, conn, cfg)
+		if err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+	}
+
+	if err := res.startup(ctx); err != nil {
+		conn.Close()
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// connect tries to connect to the server until the connection attempts are exhausted
+func connect(cfg *config.NATS, opts []nats.Option) (conn *nats.Conn, err error) {
+	for attempt := 1; attempt <= max(cfg.ConnectionAttempts, 1); attempt++ {
+		conn, err = nats.Connect(cfg.URL, opts...)
+		if err == nil {
+			return conn, nil
+		}
+
+		if attempt < cfg.ConnectionAttempts {
+			time.Sleep(cfg.ConnectionCooldown.ToDuration())
+		}
+	}
+
+	return nil, fmt.Errorf("can't connect to NATS: %w", err)
+}
+
+// createBucket returns the key value bucket which stores the cache, it is created if it doesn't exist
+func createBucket(ctx context.Context, conn *nats.Conn, cfg *config.NATS) (jetstream.KeyValue, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("can't use JetStream: %w", err)
+	}
+
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:  cfg.Bucket,
+		History: 1,
+		TTL:     cfg.BucketMaxAge.ToDuration(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't create NATS bucket '%s': %w", cfg.Bucket, err)
+	}
+
+	return kv, nil
+}
+
+// startup subscribes to the sync subject, the connection is closed when the context is done
+func (c *Client) startup(ctx context.Context) error {
+	_, err := c.conn.Subscribe(c.config.Subject, func(msg *nats.Msg) {
+		c.processReceivedMessage(ctx, msg.Data)
+	})
+	if err != nil {
+		return fmt.Errorf("can't subscribe to '%s': %w", c.config.Subject, err)
+	}
+
+	// make sure the subscription is active before the first message is published
+	if err := c.conn.Flush(); err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			select {
+			case s := <-c.storeBuffer:
+				c.store(ctx, s)
+			case <-ctx.Done():
+				c.conn.Close()
+
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// CacheMessages implements `cachesync.Client`
+func (c *Client) CacheMessages() <-chan *cachesync.CacheMessage {
+	return c.cacheChannel
+}
+
+// EnabledMessages implements `cachesync.Client`
+func (c *Client) EnabledMessages() <-chan *cachesync.EnabledMessage {
+	return c.enabledChannel
+}
+
+// Ping implements `cachesync.Client`, ctx must have a deadline
+func (c *Client) Ping(ctx context.Context) error {
+	return c.conn.FlushWithContext(ctx)
+}
+
+// PublishCache publishes the cache entry and stores it in the bucket, if configured
+func (c *Client) PublishCache(key string, message *dns.Msg) {
+	if len(key) == 0 || message == nil {
+		return
+	}
+
+	message.Compress = true
+
+	binRes, err := message.Pack()
+	if err != nil {
+		c.l.Error("can't pack message: ", err)
+
+		return
+	}
+
+	c.publish(&natsMessage{Key: key, Type: messageTypeCache, Message: binRes})
+
+	if c.kv != nil {
+		c.storeBuffer <- &storeMessage{key: key, packed: binRes}
+	}
+}
+
+func (c *Client) store(ctx context.Context, s *storeMessage) {
+	if _, err := c.kv.Put(ctx, encodeKey(s.key), s.packed); err != nil {
+		c.l.Error("can't store cache entry: ", err)
+	}
+}
+
+// PublishEnabled publishes the blocking state
+func (c *Client) PublishEnabled(_ context.Context, state *cachesync.EnabledMessage) {
+	binState, err := json.Marshal(state)
+	if err != nil {
+		c.l.Error("can't marshal state: ", err)
+
+		return
+	}
+
+	c.publish(&natsMessage{Type: messageTypeEnable, Message: binState})
+}
+
+func (c *Client) publish(msg *natsMessage) {
+	binMsg, err := json.Marshal(msg)
+	if err != nil {
+		c.l.Error("can't marshal message: ", err)
+
+		return
+	}
+
+	// the message is buffered by the connection while reconnecting
+	if err := c.conn.Publish(c.config.Subject, binMsg); err != nil {
+		c.l.Error("can't publish message: ", err)
+	}
+}
+
+// LoadCache reads the cache entries of the bucket and publishes them to the channel
+func (c *Client) LoadCache(ctx context.Context) {
+	if c.kv == nil {
+		return
+	}
+
+	c.l.Debug("LoadCache")
+
+	go func() {
+		watcher, err := c.kv.WatchAll(ctx, jetstream.IgnoreDeletes())
+		if err != nil {
+			c.l.Error("LoadCache ", err)
+
+			return
+		}
+
+		defer watcher.Stop() //nolint:errcheck
+
+		for {
+			var entry jetstream.KeyValueEntry
+
+			select {
+			case entry = <-watcher.Updates():
+			case <-ctx.Done():
+				return
+			}
+
+			// nil marks the end of the stored entries
+			if entry == nil {
+				return
+			}
+
+			msg, err := storedMessage(entry)
+			if err != nil {
+				c.l.Error("LoadCache ", err)
+
+				continue
+			}
+
+			if msg != nil && !util.CtxSend(ctx, c.cacheChannel, msg) {
+				return
+			}
+		}
+	}()
+}
+
+// storedMessage converts a bucket entry, nil if the records expired since they were stored
+func storedMessage(entry jetstream.KeyValueEntry) (*cachesync.CacheMessage, error) {
+	key, err := decodeKey(entry.Key())
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(dns.Msg)
+	if err := msg.Unpack(entry.Value()); err != nil {
+		return nil, fmt.Errorf("conversion error: %w", err)
+	}
+
+	ttl := cachesync.TTL(msg) - time.Since(entry.Created())
+	if ttl < time.Second {
+		return nil, nil //nolint:nilnil
+	}
+
+	return cachesync.NewCacheMessage(key, entry.Value(), ttl)
+}
+
+func (c *Client) processReceivedMessage(ctx context.Context, data []byte) {
+	var nm natsMessage
+
+	if err := json.Unmarshal(data, &nm); err != nil {
+		c.l.Error("Processing error: ", err)
+
+		return
+	}
+
+	switch nm.Type {
+	case messageTypeCache:
+		cm, err := cachesync.NewCacheMessage(nm.Key, nm.Message, 0)
+		if err != nil {
+			c.l.Error("Processing CacheMessage error: ", err)
+
+			return
+		}
+
+		util.CtxSend(ctx, c.cacheChannel, cm)
+	case messageTypeEnable:
+		var msg cachesync.EnabledMessage
+
+		if err := json.Unmarshal(nm.Message, &msg); err != nil {
+			c.l.Error("Processing EnabledMessage error: ", err)
+
+			return
+		}
+
+		util.CtxSend(ctx, c.enabledChannel, &msg)
+	default:
+		c.l.Warn("Unknown message type: ", nm.Type)
+	}
+}
+
+// encodeKey encodes the binary cache key, bucket keys are limited to letters, digits and `-/_=.`
+func encodeKey(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeKey(key string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(key)
+	if err != nil {
+		return "", fmt.Errorf("invalid key in bucket: %s", key)
+	}
+
+	return string(b), nil
+}
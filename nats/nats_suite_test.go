@@ -0,0 +1,18 @@
+package nats
+
+import (
+	"testing"
+
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func init() {
+	log.Silence()
+}
+
+func TestNATSClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "NATS Suite")
+}
@@ -0,0 +1,202 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xERR0R/blocky/cachesync"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/creasty/defaults"
+	"github.com/miekg/dns"
+	"github.com/nats-io/nats-server/v2/server"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NATS client", func() {
+	var (
+		natsConfig *config.NATS
+		ctx        context.Context
+		cancelFn   context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		var cfg config.NATS
+		Expect(defaults.Set(&cfg)).Should(Succeed())
+
+		cfg.ConnectionAttempts = 1
+		natsConfig = &cfg
+	})
+
+	newClient := func() *Client {
+		client, err := New(ctx, natsConfig)
+		Expect(err).Should(Succeed())
+		Expect(client).ShouldNot(BeNil())
+
+		return client
+	}
+
+	newMsg := func(ttl uint) *dns.Msg {
+		msg, err := util.NewMsgWithAnswer("example.com.", ttl, dns.Type(dns.TypeA), "1.1.1.1")
+		Expect(err).Should(Succeed())
+
+		return msg
+	}
+
+	cacheKey := util.GenerateCacheKey(dns.Type(dns.TypeA), "example.com")
+
+	Describe("Client creation", func() {
+		When("configuration has no URL", func() {
+			It("should return nil without error", func() {
+				Expect(New(ctx, natsConfig)).Should(BeNil())
+			})
+		})
+
+		When("the server is not reachable", func() {
+			It("should fail with error", func() {
+				natsConfig.URL = "nats://127.0.0.1:1"
+
+				_, err := New(ctx, natsConfig)
+				Expect(err).Should(MatchError(ContainSubstring("can't connect to NATS")))
+			})
+		})
+	})
+
+	When("the server is running", func() {
+		BeforeEach(func() {
+			natsConfig.URL = startServer()
+		})
+
+		It("should send cache entries to the other instances", func() {
+			sender := newClient()
+			receiver := newClient()
+
+			sender.PublishCache(cacheKey, newMsg(300))
+
+			var msg *cachesync.CacheMessage
+			Eventually(receiver.CacheMessages()).Should(Receive(&msg))
+
+			Expect(msg.Key).Should(Equal(cacheKey))
+			Expect(msg.Response.Res.Answer).Should(HaveLen(1))
+			Expect(msg.Response.Reason).Should(Equal("EXTERNAL_CACHE"))
+
+			Consistently(sender.CacheMessages(), "100ms").ShouldNot(Receive())
+		})
+
+		It("should ping the server", func() {
+			client := newClient()
+
+			pingCtx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+
+			Expect(client.Ping(pingCtx)).Should(Succeed())
+		})
+
+		It("should send the blocking state to the other instances", func() {
+			sender := newClient()
+			receiver := newClient()
+
+			sender.PublishEnabled(ctx, &cachesync.EnabledMessage{
+				State:    false,
+				Duration: time.Minute,
+				Groups:   []string{"ads"},
+			})
+
+			var msg *cachesync.EnabledMessage
+			Eventually(receiver.EnabledMessages()).Should(Receive(&msg))
+
+			Expect(msg.State).Should(BeFalse())
+			Expect(msg.Duration).Should(Equal(time.Minute))
+			Expect(msg.Groups).Should(Equal([]string{"ads"}))
+		})
+
+		It("should ignore invalid messages", func() {
+			receiver := newClient()
+
+			receiver.processReceivedMessage(ctx, []byte("invalid"))
+			receiver.processReceivedMessage(ctx, []byte(`{"t":0,"m":"aW52YWxpZA=="}`))
+			receiver.processReceivedMessage(ctx, []byte(`{"t":1,"m":"aW52YWxpZA=="}`))
+			receiver.processReceivedMessage(ctx, []byte(`{"t":5}`))
+
+			Expect(receiver.CacheMessages()).ShouldNot(Receive())
+			Expect(receiver.EnabledMessages()).ShouldNot(Receive())
+		})
+
+		It("should not load anything without bucket", func() {
+			client := newClient()
+			client.PublishCache(cacheKey, newMsg(300))
+
+			client.LoadCache(ctx)
+
+			Consistently(client.CacheMessages(), "100ms").ShouldNot(Receive())
+		})
+
+		When("a bucket is configured", func() {
+			BeforeEach(func() {
+				natsConfig.Bucket = "blocky"
+			})
+
+			It("should load the stored entries", func() {
+				sender := newClient()
+				sender.PublishCache(cacheKey, newMsg(300))
+				sender.PublishCache(util.GenerateCacheKey(dns.Type(dns.TypeA), "expired.com"), newMsg(1))
+
+				// wait until the entries are stored
+				Eventually(func(g Gomega) {
+					keys, err := sender.kv.Keys(ctx)
+					g.Expect(err).Should(Succeed())
+					g.Expect(keys).Should(HaveLen(2))
+				}).Should(Succeed())
+
+				loader := newClient()
+				loader.LoadCache(ctx)
+
+				var msg *cachesync.CacheMessage
+				Eventually(loader.CacheMessages()).Should(Receive(&msg))
+
+				Expect(msg.Key).Should(Equal(cacheKey))
+				Expect(msg.Response.Res.Answer[0].Header().Ttl).Should(BeNumerically("<=", 300))
+
+				// the record with a TTL of 1s is expired
+				Consistently(loader.CacheMessages(), "100ms").ShouldNot(Receive())
+			})
+		})
+	})
+
+	Describe("keys", func() {
+		It("should be encoded for the bucket", func() {
+			encoded := encodeKey(cacheKey)
+			Expect(encoded).Should(MatchRegexp(`^[-_a-zA-Z0-9]+$`))
+			Expect(decodeKey(encoded)).Should(Equal(cacheKey))
+		})
+
+		It("should fail on invalid keys", func() {
+			_, err := decodeKey("!")
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
+
+// startServer starts a NATS server with JetStream and returns its URL
+func startServer() string {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  GinkgoT().TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	Expect(err).Should(Succeed())
+
+	go srv.Start()
+	DeferCleanup(srv.Shutdown)
+
+	Expect(srv.ReadyForConnections(5 * time.Second)).Should(BeTrue())
+
+	return srv.ClientURL()
+}
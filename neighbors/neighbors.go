@@ -0,0 +1,69 @@
+// Package neighbors looks up the hardware (MAC) addresses of clients in the ARP/neighbor table of the OS.
+package neighbors
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/log"
+)
+
+const (
+	// entries older than this are reloaded
+	maxAge = 30 * time.Second
+
+	// minimum delay between two reloads caused by unknown IP addresses
+	minReloadInterval = time.Second
+)
+
+// Table is a snapshot of the neighbor table, reloaded when it is outdated
+type Table struct {
+	lock     sync.Mutex
+	entries  map[string]net.HardwareAddr
+	loadedAt time.Time
+
+	load func() (map[string]net.HardwareAddr, error)
+	now  func() time.Time
+}
+
+// NewTable creates a table of the OS neighbors, which is loaded on the first lookup
+func NewTable() *Table {
+	return &Table{
+		load: readTable,
+		now:  time.Now,
+	}
+}
+
+// Lookup returns the hardware address of the IP address.
+// A client which hasn't sent a packet before its first query may be missing in the snapshot, so unknown
+// addresses cause a reload at most once per `minReloadInterval`.
+func (t *Table) Lookup(ip net.IP) (net.HardwareAddr, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	age := t.now().Sub(t.loadedAt)
+
+	mac, ok := t.entries[ip.String()]
+	if age > maxAge || (!ok && age > minReloadInterval) {
+		t.reload()
+
+		mac, ok = t.entries[ip.String()]
+	}
+
+	return mac, ok
+}
+
+func (t *Table) reload() {
+	// also on error, to not retry on each query
+	t.loadedAt = t.now()
+
+	entries, err := t.load()
+	if err != nil {
+		log.PrefixedLog("neighbors").WithError(err).Warn("can't read neighbor table")
+
+		return
+	}
+
+	t.entries = entries
+}
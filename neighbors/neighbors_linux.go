@@ -0,0 +1,101 @@
+//go:build linux
+
+package neighbors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+const (
+	// size of `struct ndmsg`
+	sizeofNdMsg = 12
+
+	// attribute types of `struct ndmsg`
+	ndaDst    = 1
+	ndaLLAddr = 2
+
+	// neighbor states without a usable hardware address
+	nudIncomplete = 0x01
+	nudFailed     = 0x20
+)
+
+// readTable dumps the IPv4 (ARP) and IPv6 (NDP) neighbors via netlink
+func readTable() (map[string]net.HardwareAddr, error) {
+	data, err := syscall.NetlinkRIB(syscall.RTM_GETNEIGH, syscall.AF_UNSPEC)
+	if err != nil {
+		return nil, fmt.Errorf("can't dump neighbors: %w", err)
+	}
+
+	msgs, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid netlink message: %w", err)
+	}
+
+	return parseNeighbors(msgs), nil
+}
+
+func parseNeighbors(msgs []syscall.NetlinkMessage) map[string]net.HardwareAddr {
+	result := make(map[string]net.HardwareAddr)
+
+	for _, msg := range msgs {
+		if msg.Header.Type != syscall.RTM_NEWNEIGH || len(msg.Data) < sizeofNdMsg {
+			continue
+		}
+
+		state := binary.NativeEndian.Uint16(msg.Data[8:10])
+		if state&(nudIncomplete|nudFailed) != 0 {
+			continue
+		}
+
+		var (
+			ip  net.IP
+			mac net.HardwareAddr
+		)
+
+		for attrs := msg.Data[sizeofNdMsg:]; len(attrs) >= syscall.SizeofRtAttr; {
+			length := int(binary.NativeEndian.Uint16(attrs[0:2]))
+			if length < syscall.SizeofRtAttr || length > len(attrs) {
+				break
+			}
+
+			value := attrs[syscall.SizeofRtAttr:length]
+
+			switch binary.NativeEndian.Uint16(attrs[2:4]) {
+			case ndaDst:
+				ip = net.IP(value)
+			case ndaLLAddr:
+				mac = net.HardwareAddr(value)
+			}
+
+			// attributes are aligned to 4 bytes
+			aligned := (length + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+			if aligned > len(attrs) {
+				break
+			}
+
+			attrs = attrs[aligned:]
+		}
+
+		// loopback and tunnel devices have no hardware addresses
+		if ip == nil || len(mac) == 0 || isZero(mac) {
+			continue
+		}
+
+		result[ip.String()] = append(net.HardwareAddr(nil), mac...)
+	}
+
+	return result
+}
+
+func isZero(mac net.HardwareAddr) bool {
+	for _, b := range mac {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,78 @@
+//go:build linux
+
+package neighbors
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Linux neighbor table", func() {
+	attr := func(typ uint16, value []byte) []byte {
+		length := syscall.SizeofRtAttr + len(value)
+		b := make([]byte, (length+3)&^3)
+		binary.NativeEndian.PutUint16(b[0:2], uint16(length))
+		binary.NativeEndian.PutUint16(b[2:4], typ)
+		copy(b[syscall.SizeofRtAttr:], value)
+
+		return b
+	}
+
+	neighbor := func(state uint16, ip net.IP, mac net.HardwareAddr) syscall.NetlinkMessage {
+		data := make([]byte, sizeofNdMsg)
+		binary.NativeEndian.PutUint16(data[8:10], state)
+
+		data = append(data, attr(ndaDst, ip)...)
+		if mac != nil {
+			data = append(data, attr(ndaLLAddr, mac)...)
+		}
+
+		return syscall.NetlinkMessage{
+			Header: syscall.NlMsghdr{Type: syscall.RTM_NEWNEIGH},
+			Data:   data,
+		}
+	}
+
+	Describe("parseNeighbors", func() {
+		It("returns the neighbors with hardware addresses", func() {
+			const (
+				nudReachable = 0x02
+				nudStale     = 0x04
+			)
+
+			mac1 := net.HardwareAddr{0xaa, 0xbb, 0xcc, 0x00, 0x00, 0x01}
+			mac2 := net.HardwareAddr{0xaa, 0xbb, 0xcc, 0x00, 0x00, 0x02}
+
+			Expect(parseNeighbors([]syscall.NetlinkMessage{
+				neighbor(nudReachable, net.ParseIP("192.168.1.1").To4(), mac1),
+				neighbor(nudStale, net.ParseIP("fe80::1"), mac2),
+				neighbor(nudIncomplete, net.ParseIP("192.168.1.2").To4(), mac2),
+				neighbor(nudFailed, net.ParseIP("192.168.1.3").To4(), mac2),
+				neighbor(nudReachable, net.ParseIP("192.168.1.4").To4(), nil),
+				neighbor(nudReachable, net.ParseIP("192.168.1.5").To4(), make(net.HardwareAddr, 6)),
+				{Header: syscall.NlMsghdr{Type: syscall.NLMSG_DONE}},
+			})).Should(Equal(map[string]net.HardwareAddr{
+				"192.168.1.1": mac1,
+				"fe80::1":     mac2,
+			}))
+		})
+
+		It("ignores truncated messages", func() {
+			msg := neighbor(0, net.ParseIP("192.168.1.1").To4(), net.HardwareAddr{1, 2, 3, 4, 5, 6})
+			msg.Data = msg.Data[:len(msg.Data)-4]
+
+			Expect(parseNeighbors([]syscall.NetlinkMessage{msg})).Should(BeEmpty())
+		})
+	})
+
+	Describe("readTable", func() {
+		It("reads the neighbors of the OS", func() {
+			_, err := readTable()
+			Expect(err).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package neighbors
+
+import (
+	"errors"
+	"net"
+)
+
+var errUnsupported = errors.New("reading the neighbor table is only supported on Linux")
+
+// readTable fails, as this OS is not supported
+func readTable() (map[string]net.HardwareAddr, error) {
+	return nil, errUnsupported
+}
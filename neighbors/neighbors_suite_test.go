@@ -0,0 +1,19 @@
+package neighbors
+
+import (
+	"testing"
+
+	"github.com/0xERR0R/blocky/log"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func init() {
+	log.Silence()
+}
+
+func TestNeighbors(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Neighbors Suite")
+}
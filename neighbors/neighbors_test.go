@@ -0,0 +1,93 @@
+package neighbors
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Table", func() {
+	var (
+		sut   *Table
+		now   time.Time
+		loads int
+		table map[string]net.HardwareAddr
+		err   error
+
+		mac1 = net.HardwareAddr{0xaa, 0xbb, 0xcc, 0x00, 0x00, 0x01}
+		mac2 = net.HardwareAddr{0xaa, 0xbb, 0xcc, 0x00, 0x00, 0x02}
+	)
+
+	lookup := func(ip string) net.HardwareAddr {
+		mac, ok := sut.Lookup(net.ParseIP(ip))
+		if !ok {
+			return nil
+		}
+
+		return mac
+	}
+
+	BeforeEach(func() {
+		now = time.Now()
+		loads = 0
+		table = map[string]net.HardwareAddr{"192.168.1.1": mac1}
+		err = nil
+
+		sut = &Table{
+			load: func() (map[string]net.HardwareAddr, error) {
+				loads++
+
+				return table, err
+			},
+			now: func() time.Time { return now },
+		}
+	})
+
+	It("loads the table on the first lookup", func() {
+		Expect(lookup("192.168.1.1")).Should(Equal(mac1))
+		Expect(lookup("192.168.1.1")).Should(Equal(mac1))
+		Expect(loads).Should(Equal(1))
+	})
+
+	It("reloads outdated tables", func() {
+		Expect(lookup("192.168.1.1")).Should(Equal(mac1))
+
+		table = map[string]net.HardwareAddr{"192.168.1.1": mac2}
+		now = now.Add(maxAge + time.Second)
+
+		Expect(lookup("192.168.1.1")).Should(Equal(mac2))
+		Expect(loads).Should(Equal(2))
+	})
+
+	It("limits the reloads for unknown IP addresses", func() {
+		Expect(lookup("192.168.1.2")).Should(BeNil())
+
+		table = map[string]net.HardwareAddr{"192.168.1.2": mac2}
+
+		Expect(lookup("192.168.1.2")).Should(BeNil())
+		Expect(loads).Should(Equal(1))
+
+		now = now.Add(minReloadInterval + time.Millisecond)
+
+		Expect(lookup("192.168.1.2")).Should(Equal(mac2))
+		Expect(loads).Should(Equal(2))
+	})
+
+	It("keeps the previous entries on errors", func() {
+		Expect(lookup("192.168.1.1")).Should(Equal(mac1))
+
+		err = errors.New("boom")
+		now = now.Add(maxAge + time.Second)
+
+		Expect(lookup("192.168.1.1")).Should(Equal(mac1))
+		Expect(lookup("192.168.1.1")).Should(Equal(mac1))
+		Expect(loads).Should(Equal(2))
+	})
+
+	It("matches IPv4-mapped IPv6 addresses", func() {
+		Expect(lookup("::ffff:192.168.1.1")).Should(Equal(mac1))
+	})
+})
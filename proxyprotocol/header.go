@@ -0,0 +1,212 @@
+// Package proxyprotocol implements the HAProxy PROXY protocol (version 1 and 2) for TCP connections:
+// a proxy sends a header with the addresses of the original connection before the proxied data.
+// See https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt
+package proxyprotocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+const (
+	v1Prefix    = "PROXY "
+	v1MaxLength = 107
+
+	v2HeaderLength = 16
+	v2Version      = 0x20
+	v2CmdLocal     = 0x00
+	v2CmdProxy     = 0x01
+	v2FamilyInet   = 0x10
+	v2FamilyInet6  = 0x20
+	v2ProtoStream  = 0x01
+
+	v2Inet4AddrsLength = 2*net.IPv4len + 4
+	v2Inet6AddrsLength = 2*net.IPv6len + 4
+)
+
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// ErrInvalidHeader is returned if a connection doesn't start with a valid PROXY protocol header
+var ErrInvalidHeader = errors.New("invalid PROXY protocol header")
+
+// Header contains the addresses of the proxied connection.
+// The addresses are nil if the proxy doesn't know them, e.g. for its own health checks.
+type Header struct {
+	Source      *net.TCPAddr
+	Destination *net.TCPAddr
+}
+
+// ReadHeader reads a version 1 or 2 header
+func ReadHeader(r *bufio.Reader) (*Header, error) {
+	prefix, err := r.Peek(len(v1Prefix))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+	}
+
+	if string(prefix) == v1Prefix {
+		return readV1(r)
+	}
+
+	return readV2(r)
+}
+
+func readV1(r *bufio.Reader) (*Header, error) {
+	line := make([]byte, 0, v1MaxLength)
+
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) == v1MaxLength {
+			return nil, fmt.Errorf("%w: line too long", ErrInvalidHeader)
+		}
+
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+		}
+
+		line = append(line, b)
+	}
+
+	fields := strings.Fields(string(line))
+
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return &Header{}, nil
+	}
+
+	const v1Fields = 6
+	if len(fields) != v1Fields || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidHeader, strings.TrimSpace(string(line)))
+	}
+
+	src, err := parseV1Addr(fields[2], fields[4])
+	if err != nil {
+		return nil, err
+	}
+
+	dst, err := parseV1Addr(fields[3], fields[5])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Header{Source: src, Destination: dst}, nil
+}
+
+func parseV1Addr(ip, port string) (*net.TCPAddr, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid port %q", ErrInvalidHeader, port)
+	}
+
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, uint16(p))), nil
+}
+
+func readV2(r *bufio.Reader) (*Header, error) {
+	header := make([]byte, v2HeaderLength)
+
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+	}
+
+	if !bytes.Equal(header[:len(v2Signature)], v2Signature) {
+		return nil, fmt.Errorf("%w: missing signature", ErrInvalidHeader)
+	}
+
+	verCmd, family := header[12], header[13]
+
+	if verCmd&0xF0 != v2Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHeader, verCmd>>4)
+	}
+
+	// addresses and TLVs
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+	}
+
+	switch verCmd & 0x0F {
+	case v2CmdLocal:
+		return &Header{}, nil
+
+	case v2CmdProxy:
+		return parseV2Addrs(family, payload)
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported command %d", ErrInvalidHeader, verCmd&0x0F)
+	}
+}
+
+func parseV2Addrs(family byte, payload []byte) (*Header, error) {
+	var ipLen int
+
+	switch family & 0xF0 {
+	case v2FamilyInet:
+		ipLen = net.IPv4len
+	case v2FamilyInet6:
+		ipLen = net.IPv6len
+	default:
+		// e.g. unix sockets: the addresses can't be used
+		return &Header{}, nil
+	}
+
+	if len(payload) < 2*ipLen+4 {
+		return nil, fmt.Errorf("%w: addresses too short", ErrInvalidHeader)
+	}
+
+	src, _ := netip.AddrFromSlice(payload[:ipLen])
+	dst, _ := netip.AddrFromSlice(payload[ipLen : 2*ipLen])
+	ports := payload[2*ipLen:]
+
+	return &Header{
+		Source:      net.TCPAddrFromAddrPort(netip.AddrPortFrom(src, binary.BigEndian.Uint16(ports))),
+		Destination: net.TCPAddrFromAddrPort(netip.AddrPortFrom(dst, binary.BigEndian.Uint16(ports[2:]))),
+	}, nil
+}
+
+// WriteTo writes the header in version 2 format, a LOCAL header if an address is missing.
+// An IPv4 address is sent as IPv4-mapped IPv6 address if the other address is an IPv6 address.
+func (h *Header) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, v2HeaderLength, v2HeaderLength+v2Inet6AddrsLength)
+	copy(buf, v2Signature)
+
+	buf[12] = v2Version | v2CmdLocal
+
+	if h.Source != nil && h.Destination != nil {
+		src, dst := h.Source.AddrPort(), h.Destination.AddrPort()
+		srcIP, dstIP := src.Addr().Unmap(), dst.Addr().Unmap()
+
+		buf[12] = v2Version | v2CmdProxy
+
+		if srcIP.Is4() && dstIP.Is4() {
+			buf[13] = v2FamilyInet | v2ProtoStream
+			buf = binary.BigEndian.AppendUint16(buf[:14], v2Inet4AddrsLength)
+			buf = append(buf, srcIP.AsSlice()...)
+			buf = append(buf, dstIP.AsSlice()...)
+		} else {
+			buf[13] = v2FamilyInet6 | v2ProtoStream
+			buf = binary.BigEndian.AppendUint16(buf[:14], v2Inet6AddrsLength)
+			src16, dst16 := srcIP.As16(), dstIP.As16()
+			buf = append(buf, src16[:]...)
+			buf = append(buf, dst16[:]...)
+		}
+
+		buf = binary.BigEndian.AppendUint16(buf, src.Port())
+		buf = binary.BigEndian.AppendUint16(buf, dst.Port())
+	}
+
+	n, err := w.Write(buf)
+
+	return int64(n), err
+}
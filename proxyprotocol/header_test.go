@@ -0,0 +1,143 @@
+package proxyprotocol
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Header", func() {
+	read := func(data string) (*Header, error) {
+		return ReadHeader(bufio.NewReader(strings.NewReader(data)))
+	}
+
+	roundTrip := func(header *Header) *Header {
+		var buf bytes.Buffer
+
+		_, err := header.WriteTo(&buf)
+		Expect(err).Should(Succeed())
+
+		res, err := ReadHeader(bufio.NewReader(&buf))
+		Expect(err).Should(Succeed())
+
+		return res
+	}
+
+	Describe("version 1", func() {
+		It("should read TCP4 headers", func() {
+			header, err := read("PROXY TCP4 192.0.2.1 198.51.100.1 56324 53\r\nquery")
+			Expect(err).Should(Succeed())
+
+			Expect(header.Source.String()).Should(Equal("192.0.2.1:56324"))
+			Expect(header.Destination.String()).Should(Equal("198.51.100.1:53"))
+		})
+
+		It("should read TCP6 headers", func() {
+			header, err := read("PROXY TCP6 2001:db8::1 2001:db8::53 56324 853\r\n")
+			Expect(err).Should(Succeed())
+
+			Expect(header.Source.String()).Should(Equal("[2001:db8::1]:56324"))
+		})
+
+		It("should read UNKNOWN headers without addresses", func() {
+			header, err := read("PROXY UNKNOWN\r\n")
+			Expect(err).Should(Succeed())
+
+			Expect(header.Source).Should(BeNil())
+		})
+
+		It("should fail on invalid headers", func() {
+			for _, data := range []string{
+				"PROXY TCP4 192.0.2.1 198.51.100.1 56324\r\n",
+				"PROXY UDP4 192.0.2.1 198.51.100.1 56324 53\r\n",
+				"PROXY TCP4 host 198.51.100.1 56324 53\r\n",
+				"PROXY TCP4 192.0.2.1 198.51.100.1 65536 53\r\n",
+				"PROXY TCP4 192.0.2.1 198.51.100.1 56324 53",
+				"PROXY " + strings.Repeat("A", 200) + "\r\n",
+			} {
+				_, err := read(data)
+				Expect(err).Should(MatchError(ErrInvalidHeader), data)
+			}
+		})
+	})
+
+	Describe("version 2", func() {
+		It("should write and read IPv4 addresses", func() {
+			header := roundTrip(&Header{
+				Source:      &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 56324},
+				Destination: &net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 53},
+			})
+
+			Expect(header.Source.String()).Should(Equal("192.0.2.1:56324"))
+			Expect(header.Destination.String()).Should(Equal("198.51.100.1:53"))
+		})
+
+		It("should write and read IPv6 addresses", func() {
+			header := roundTrip(&Header{
+				Source:      &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 56324},
+				Destination: &net.TCPAddr{IP: net.ParseIP("2001:db8::53"), Port: 53},
+			})
+
+			Expect(header.Source.String()).Should(Equal("[2001:db8::1]:56324"))
+			Expect(header.Destination.String()).Should(Equal("[2001:db8::53]:53"))
+		})
+
+		It("should map IPv4 addresses if the other address is an IPv6 address", func() {
+			header := roundTrip(&Header{
+				Source:      &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 56324},
+				Destination: &net.TCPAddr{IP: net.ParseIP("2001:db8::53"), Port: 53},
+			})
+
+			Expect(header.Source.AddrPort().Addr().Unmap().String()).Should(Equal("192.0.2.1"))
+		})
+
+		It("should write a LOCAL header without addresses", func() {
+			header := roundTrip(&Header{})
+
+			Expect(header.Source).Should(BeNil())
+			Expect(header.Destination).Should(BeNil())
+		})
+
+		It("should keep the data following the header", func() {
+			var buf bytes.Buffer
+
+			_, err := (&Header{}).WriteTo(&buf)
+			Expect(err).Should(Succeed())
+
+			buf.WriteString("query")
+
+			reader := bufio.NewReader(&buf)
+
+			_, err = ReadHeader(reader)
+			Expect(err).Should(Succeed())
+
+			rest, err := reader.ReadString(0)
+			Expect(rest).Should(Equal("query"))
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should fail on invalid headers", func() {
+			var buf bytes.Buffer
+
+			_, err := (&Header{}).WriteTo(&buf)
+			Expect(err).Should(Succeed())
+
+			valid := buf.Bytes()
+
+			for _, data := range [][]byte{
+				[]byte("GET / HTTP/1.1\r\n"),
+				append([]byte{}, valid[:10]...),
+				append(append([]byte{}, valid[:12]...), 0x11, 0x00, 0x00, 0x00),
+				append(append([]byte{}, valid[:12]...), 0x2F, 0x00, 0x00, 0x00),
+				append(append([]byte{}, valid[:12]...), 0x21, 0x11, 0x00, 0x04, 1, 2, 3, 4),
+			} {
+				_, err := ReadHeader(bufio.NewReader(bytes.NewReader(data)))
+				Expect(err).Should(MatchError(ErrInvalidHeader), string(data))
+			}
+		})
+	})
+})
@@ -0,0 +1,238 @@
+package proxyprotocol
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/log"
+
+	"github.com/sirupsen/logrus"
+)
+
+func logger() *logrus.Entry {
+	return log.PrefixedLog("proxy_protocol")
+}
+
+// Listener reads the PROXY protocol header of the connections of trusted proxies.
+// Connections of other sources are used as they are.
+//
+// The headers are read before the connections are returned by `Accept`, so a slow proxy doesn't block other
+// connections and the deadlines of the server using the connection are not changed.
+type Listener struct {
+	net.Listener
+
+	trusted []netip.Prefix
+	timeout time.Duration
+
+	startOnce sync.Once
+	conns     chan net.Conn
+	errs      chan error
+
+	// closed when the listener is closed
+	done      chan struct{}
+	closeOnce sync.Once
+
+	lock sync.Mutex
+	// connections whose header is being read
+	pending map[net.Conn]struct{}
+}
+
+// NewListener returns a listener which expects a header from connections of the trusted prefixes within `timeout`
+func NewListener(inner net.Listener, trusted []netip.Prefix, timeout time.Duration) *Listener {
+	return &Listener{
+		Listener: inner,
+		trusted:  trusted,
+		timeout:  timeout,
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		done:     make(chan struct{}),
+		pending:  make(map[net.Conn]struct{}),
+	}
+}
+
+// Accept implements `net.Listener`.
+func (l *Listener) Accept() (net.Conn, error) {
+	l.startOnce.Do(func() {
+		go l.acceptLoop()
+	})
+
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+
+	case err := <-l.errs:
+		return nil, err
+
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements `net.Listener`, connections which aren't returned by `Accept` yet are closed.
+func (l *Listener) Close() error {
+	l.shutdown()
+
+	return l.Listener.Close()
+}
+
+// shutdown stops the delivery of connections and closes the connections whose header is being read
+func (l *Listener) shutdown() {
+	l.closeOnce.Do(func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		close(l.done)
+
+		for conn := range l.pending {
+			conn.Close()
+		}
+	})
+}
+
+func (l *Listener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				l.shutdown()
+
+				return
+			}
+
+			// e.g. too many open files: the server decides whether to retry
+			select {
+			case l.errs <- err:
+			case <-l.done:
+				return
+			}
+
+			continue
+		}
+
+		if !l.isTrusted(conn.RemoteAddr()) {
+			l.deliver(conn)
+
+			continue
+		}
+
+		if !l.track(conn) {
+			conn.Close()
+
+			return
+		}
+
+		go func() {
+			proxied, err := l.readHeader(conn)
+
+			l.untrack(conn)
+
+			if err != nil {
+				logger().Debugf("closing connection of %s: %s", conn.RemoteAddr(), err)
+
+				conn.Close()
+
+				return
+			}
+
+			l.deliver(proxied)
+		}()
+	}
+}
+
+func (l *Listener) deliver(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// track adds the connection to the pending ones, it returns false if the listener is closed
+func (l *Listener) track(conn net.Conn) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	select {
+	case <-l.done:
+		return false
+	default:
+		l.pending[conn] = struct{}{}
+
+		return true
+	}
+}
+
+func (l *Listener) untrack(conn net.Conn) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.pending, conn)
+}
+
+func (l *Listener) isTrusted(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	ip := tcpAddr.AddrPort().Addr().Unmap()
+
+	for _, prefix := range l.trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *Listener) readHeader(conn net.Conn) (net.Conn, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(l.timeout)); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+
+	header, err := ReadHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	res := &Conn{Conn: conn, reader: reader}
+
+	if header.Source != nil {
+		res.remote = header.Source
+	}
+
+	return res, nil
+}
+
+// Conn is a connection of a trusted proxy, its remote address is the source address of the proxied connection
+type Conn struct {
+	net.Conn
+
+	reader *bufio.Reader // buffers data following the header
+	remote net.Addr
+}
+
+// Read implements `net.Conn`.
+func (c *Conn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// RemoteAddr implements `net.Conn`.
+func (c *Conn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+
+	return c.Conn.RemoteAddr()
+}
@@ -0,0 +1,171 @@
+package proxyprotocol
+
+import (
+	"bufio"
+	"net"
+	"net/netip"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Listener", func() {
+	var (
+		sut     *Listener
+		trusted []netip.Prefix
+		timeout time.Duration
+	)
+
+	BeforeEach(func() {
+		trusted = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+		timeout = 200 * time.Millisecond
+	})
+
+	JustBeforeEach(func() {
+		inner, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).Should(Succeed())
+
+		sut = NewListener(inner, trusted, timeout)
+		DeferCleanup(func() { _ = sut.Close() })
+	})
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("tcp", sut.Addr().String())
+		Expect(err).Should(Succeed())
+		DeferCleanup(conn.Close)
+
+		return conn
+	}
+
+	accept := func() net.Conn {
+		conn, err := sut.Accept()
+		Expect(err).Should(Succeed())
+		DeferCleanup(conn.Close)
+
+		return conn
+	}
+
+	It("should use the source of the header as remote address", func() {
+		conn := dial()
+
+		_, err := conn.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 53\r\nquery\n"))
+		Expect(err).Should(Succeed())
+
+		accepted := accept()
+		Expect(accepted.RemoteAddr().String()).Should(Equal("192.0.2.1:56324"))
+
+		data, err := bufio.NewReader(accepted).ReadString('\n')
+		Expect(err).Should(Succeed())
+		Expect(data).Should(Equal("query\n"))
+	})
+
+	It("should keep the remote address for LOCAL headers", func() {
+		conn := dial()
+
+		_, err := (&Header{}).WriteTo(conn)
+		Expect(err).Should(Succeed())
+
+		Expect(accept().RemoteAddr()).Should(Equal(conn.LocalAddr()))
+	})
+
+	It("should close connections without valid header", func() {
+		invalid := dial()
+
+		_, err := invalid.Write([]byte("GET / HTTP/1.1\r\n"))
+		Expect(err).Should(Succeed())
+
+		// no header at all
+		silent := dial()
+
+		valid := dial()
+
+		_, err = (&Header{}).WriteTo(valid)
+		Expect(err).Should(Succeed())
+
+		// the other connections don't block the valid one
+		Expect(accept().RemoteAddr()).Should(Equal(valid.LocalAddr()))
+
+		for _, conn := range []net.Conn{invalid, silent} {
+			Expect(conn.SetReadDeadline(time.Now().Add(time.Second))).Should(Succeed())
+
+			_, err = conn.Read(make([]byte, 1))
+			Expect(err).Should(HaveOccurred())
+			Expect(err).ShouldNot(MatchError(ContainSubstring("timeout")))
+		}
+	})
+
+	When("the source is not trusted", func() {
+		BeforeEach(func() {
+			trusted = []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+		})
+
+		It("should use the connection as it is", func() {
+			conn := dial()
+
+			_, err := conn.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 53\r\n"))
+			Expect(err).Should(Succeed())
+
+			accepted := accept()
+			Expect(accepted.RemoteAddr()).Should(Equal(conn.LocalAddr()))
+
+			data, err := bufio.NewReader(accepted).ReadString('\n')
+			Expect(err).Should(Succeed())
+			Expect(data).Should(HavePrefix("PROXY"))
+		})
+	})
+
+	When("it is closed while connections aren't accepted", func() {
+		BeforeEach(func() {
+			trusted = []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}
+			timeout = time.Minute
+		})
+
+		It("should close them", func() {
+			first := dial()
+
+			_, err := (&Header{}).WriteTo(first)
+			Expect(err).Should(Succeed())
+
+			accept()
+
+			// header is being read
+			silent := dial()
+
+			Eventually(func() []string {
+				sut.lock.Lock()
+				defer sut.lock.Unlock()
+
+				var res []string
+				for conn := range sut.pending {
+					res = append(res, conn.RemoteAddr().String())
+				}
+
+				return res
+			}).Should(ConsistOf(silent.LocalAddr().String()))
+
+			// not trusted, so delivered by the accept loop itself, but not accepted
+			untrusted, err := (&net.Dialer{LocalAddr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 2)}}).
+				Dial("tcp", sut.Addr().String())
+			Expect(err).Should(Succeed())
+			DeferCleanup(untrusted.Close)
+
+			Expect(sut.Close()).Should(Succeed())
+
+			for _, conn := range []net.Conn{silent, untrusted} {
+				Expect(conn.SetReadDeadline(time.Now().Add(time.Second))).Should(Succeed())
+
+				_, err = conn.Read(make([]byte, 1))
+				Expect(err).Should(HaveOccurred())
+				Expect(err).ShouldNot(MatchError(ContainSubstring("timeout")))
+			}
+		})
+	})
+
+	It("should fail to accept when it is closed", func() {
+		Expect(sut.Close()).Should(Succeed())
+
+		_, err := sut.Accept()
+		Expect(err).Should(MatchError(net.ErrClosed))
+	})
+})
@@ -0,0 +1,19 @@
+package proxyprotocol
+
+import (
+	"testing"
+
+	"github.com/0xERR0R/blocky/log"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func init() {
+	log.Silence()
+}
+
+func TestProxyProtocol(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "PROXY Protocol Suite")
+}
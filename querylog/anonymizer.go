@@ -0,0 +1,137 @@
+package querylog
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
+)
+
+const (
+	saltLen = 32
+	hashLen = 8 // bytes of the HMAC kept, as hex
+)
+
+// Anonymizer anonymizes the clients of the log entries.
+// The methods can be called on a nil Anonymizer, which keeps the clients as they are.
+type Anonymizer struct {
+	cfg config.QueryLogAnonymize
+
+	lock       sync.Mutex
+	salt       []byte
+	saltExpiry time.Time
+
+	// replaceable for tests
+	now func() time.Time
+}
+
+// NewAnonymizer returns nil if the anonymization is disabled
+func NewAnonymizer(cfg config.QueryLogAnonymize) *Anonymizer {
+	if !cfg.IsEnabled() {
+		return nil
+	}
+
+	return &Anonymizer{cfg: cfg, now: time.Now}
+}
+
+// For returns the anonymizer for the client, nil if the client is exempt
+func (a *Anonymizer) For(ip net.IP, names []string, mac net.HardwareAddr) *Anonymizer {
+	if a == nil {
+		return nil
+	}
+
+	for _, client := range a.cfg.Exempt {
+		if clientIP := net.ParseIP(client); clientIP != nil && clientIP.Equal(ip) ||
+			util.CidrContainsIP(client, ip) ||
+			util.ClientMACMatchesGroupName(client, mac) {
+			return nil
+		}
+
+		for _, name := range names {
+			if util.ClientNameMatchesGroupName(client, name) {
+				return nil
+			}
+		}
+	}
+
+	return a
+}
+
+// DropsClient returns true if neither the client IP nor the client names are logged
+func (a *Anonymizer) DropsClient() bool {
+	return a != nil && a.cfg.DropClient
+}
+
+// IP returns the anonymized client IP
+func (a *Anonymizer) IP(ip net.IP) string {
+	if a == nil || ip == nil {
+		return ip.String()
+	}
+
+	switch a.cfg.ClientIP {
+	case config.QueryLogIPAnonymizationTruncate:
+		bits, prefix := net.IPv4len*8, a.cfg.IPv4Prefix //nolint:mnd
+		if ip.To4() == nil {
+			bits, prefix = net.IPv6len*8, a.cfg.IPv6Prefix //nolint:mnd
+		}
+
+		return ip.Mask(net.CIDRMask(int(prefix), bits)).String()
+
+	case config.QueryLogIPAnonymizationHash:
+		return a.hash(ip.String())
+
+	default:
+		return ip.String()
+	}
+}
+
+// Names returns the anonymized client names
+func (a *Anonymizer) Names(names []string) []string {
+	if a == nil || !a.cfg.HashClientNames {
+		return names
+	}
+
+	res := make([]string, len(names))
+	for i, name := range names {
+		res[i] = a.hash(name)
+	}
+
+	return res
+}
+
+// hash returns the HMAC of the value with the current salt
+func (a *Anonymizer) hash(value string) string {
+	mac := hmac.New(sha256.New, a.currentSalt())
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil)[:hashLen])
+}
+
+// currentSalt returns the salt, a new one is created if it expired
+func (a *Anonymizer) currentSalt() []byte {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	now := a.now()
+
+	if a.salt != nil && (a.saltExpiry.IsZero() || now.Before(a.saltExpiry)) {
+		return a.salt
+	}
+
+	salt := make([]byte, saltLen)
+	_, _ = rand.Read(salt)
+
+	a.salt = salt
+
+	if a.cfg.SaltRotation.IsAboveZero() {
+		a.saltExpiry = now.Add(a.cfg.SaltRotation.ToDuration())
+	}
+
+	return a.salt
+}
@@ -0,0 +1,116 @@
+package querylog
+
+import (
+	"net"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Anonymizer", func() {
+	var (
+		cfg config.QueryLogAnonymize
+		sut *Anonymizer
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = config.WithDefaults[config.QueryLogAnonymize]()
+		Expect(err).Should(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		sut = NewAnonymizer(cfg)
+	})
+
+	When("disabled", func() {
+		It("should keep the client", func() {
+			Expect(sut).Should(BeNil())
+
+			Expect(sut.IP(net.ParseIP("192.168.1.10"))).Should(Equal("192.168.1.10"))
+			Expect(sut.Names([]string{"laptop"})).Should(Equal([]string{"laptop"}))
+			Expect(sut.DropsClient()).Should(BeFalse())
+		})
+	})
+
+	When("IPs are truncated", func() {
+		BeforeEach(func() {
+			cfg.ClientIP = config.QueryLogIPAnonymizationTruncate
+		})
+
+		It("should keep the network only", func() {
+			Expect(sut.IP(net.ParseIP("192.168.1.10"))).Should(Equal("192.168.1.0"))
+			Expect(sut.IP(net.ParseIP("2001:db8:1:2::10"))).Should(Equal("2001:db8:1::"))
+			Expect(sut.Names([]string{"laptop"})).Should(Equal([]string{"laptop"}))
+		})
+	})
+
+	When("IPs and names are hashed", func() {
+		var now time.Time
+
+		BeforeEach(func() {
+			cfg.ClientIP = config.QueryLogIPAnonymizationHash
+			cfg.HashClientNames = true
+			cfg.SaltRotation = config.Duration(time.Hour)
+
+			now = time.Now()
+		})
+
+		JustBeforeEach(func() {
+			sut.now = func() time.Time { return now }
+		})
+
+		It("should hash them consistently until the salt rotates", func() {
+			ip := net.ParseIP("192.168.1.10")
+
+			hash := sut.IP(ip)
+			Expect(hash).Should(HaveLen(16))
+			Expect(hash).ShouldNot(ContainSubstring("192"))
+			Expect(sut.IP(ip)).Should(Equal(hash))
+			Expect(sut.IP(net.ParseIP("192.168.1.11"))).ShouldNot(Equal(hash))
+
+			names := sut.Names([]string{"laptop"})
+			Expect(names).Should(HaveLen(1))
+			Expect(names[0]).ShouldNot(Equal("laptop"))
+
+			now = now.Add(2 * time.Hour)
+
+			Expect(sut.IP(ip)).ShouldNot(Equal(hash))
+		})
+
+		It("should keep the salt without rotation", func() {
+			sut.cfg.SaltRotation = 0
+
+			hash := sut.IP(net.ParseIP("192.168.1.10"))
+
+			now = now.Add(1000 * time.Hour)
+
+			Expect(sut.IP(net.ParseIP("192.168.1.10"))).Should(Equal(hash))
+		})
+	})
+
+	When("clients are exempt", func() {
+		BeforeEach(func() {
+			cfg.DropClient = true
+			cfg.Exempt = []string{"admin*", "10.0.0.1", "10.1.0.0/16", "aa:bb:cc"}
+		})
+
+		It("should only anonymize the other clients", func() {
+			mac, err := net.ParseMAC("aa:bb:cc:00:11:22")
+			Expect(err).Should(Succeed())
+
+			Expect(sut.For(net.ParseIP("192.168.1.10"), []string{"admin-pc"}, nil)).Should(BeNil())
+			Expect(sut.For(net.ParseIP("10.0.0.1"), nil, nil)).Should(BeNil())
+			Expect(sut.For(net.ParseIP("10.1.2.3"), nil, nil)).Should(BeNil())
+			Expect(sut.For(net.ParseIP("192.168.1.10"), nil, mac)).Should(BeNil())
+
+			other := sut.For(net.ParseIP("192.168.1.10"), []string{"laptop"}, nil)
+			Expect(other).Should(BeIdenticalTo(sut))
+			Expect(other.DropsClient()).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,266 @@
+package querylog
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	loggerPrefixArchiveWriter = "archiveQueryLogWriter"
+
+	archiveFileSuffix  = ".jsonl.gz"
+	archivePartSuffix  = ".part"
+	archiveCheckPeriod = time.Minute
+
+	bytesPerMiB = 1024 * 1024
+)
+
+// archiveEntry is a line of an archive file, the keys match the columns of the database writer
+type archiveEntry struct {
+	RequestTS     time.Time `json:"request_ts"`
+	ClientIP      string    `json:"client_ip"`
+	ClientName    string    `json:"client_name"`
+	DurationMs    int64     `json:"duration_ms"`
+	Reason        string    `json:"reason"`
+	Categories    string    `json:"categories"`
+	ResponseType  string    `json:"response_type"`
+	ResponseCode  string    `json:"response_code"`
+	QuestionType  string    `json:"question_type"`
+	QuestionName  string    `json:"question_name"`
+	Answer        string    `json:"answer"`
+	AnswerCountry string    `json:"answer_country"`
+	AnswerASN     string    `json:"answer_asn"`
+	EdnsUDPSize   uint16    `json:"edns_udp_size"`
+	EdnsDo        bool      `json:"edns_do"`
+	EdnsEcs       bool      `json:"edns_ecs"`
+	EdnsCookie    bool      `json:"edns_cookie"`
+	Hostname      string    `json:"hostname"`
+}
+
+// ArchiveWriter writes the entries as gzip compressed JSON lines, one file per hour or day.
+// A file is started if it exceeds the maximal size.
+// The file in progress has the suffix `.part`, it gets its final name when it is complete.
+type ArchiveWriter struct {
+	target           string
+	hourly           bool
+	maxFileSize      int64
+	logRetentionDays uint64
+
+	lock sync.Mutex
+	file *archiveFile
+}
+
+type archiveFile struct {
+	path   string
+	period time.Time
+
+	file *os.File
+	size *countingWriter
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+
+	return n, err
+}
+
+// NewArchiveWriter creates the writer, the file in progress is completed when ctx is done
+func NewArchiveWriter(ctx context.Context, target string, cfg config.QueryLogArchive, logRetentionDays uint64,
+) (*ArchiveWriter, error) {
+	if _, err := os.Stat(target); err != nil {
+		return nil, fmt.Errorf("query log directory '%s' does not exist or is not writable", target)
+	}
+
+	w := &ArchiveWriter{
+		target:           target,
+		hourly:           cfg.Rotation == config.QueryLogRotationHourly,
+		maxFileSize:      int64(cfg.MaxFileSize) * bytesPerMiB,
+		logRetentionDays: logRetentionDays,
+	}
+
+	go w.periodicCompletion(ctx)
+
+	return w, nil
+}
+
+func archiveLogger() *logrus.Entry {
+	return log.PrefixedLog(loggerPrefixArchiveWriter)
+}
+
+func (d *ArchiveWriter) Write(entry *LogEntry) {
+	period := d.periodOf(entry.Start)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// late entries of the previous period are kept in the current file
+	if d.file != nil && (period.After(d.file.period) || d.maxFileSize > 0 && d.file.size.n >= d.maxFileSize) {
+		d.complete()
+	}
+
+	if d.file == nil {
+		file, err := d.create(period)
+		if err != nil {
+			archiveLogger().Error("can't create archive file: ", err)
+
+			return
+		}
+
+		d.file = file
+	}
+
+	err := d.file.enc.Encode(&archiveEntry{
+		RequestTS:     entry.Start,
+		ClientIP:      entry.ClientIP,
+		ClientName:    strings.Join(entry.ClientNames, "; "),
+		DurationMs:    entry.DurationMs,
+		Reason:        entry.ResponseReason,
+		Categories:    strings.Join(entry.Categories, ", "),
+		ResponseType:  entry.ResponseType,
+		ResponseCode:  entry.ResponseCode,
+		QuestionType:  entry.QuestionType,
+		QuestionName:  entry.QuestionName,
+		Answer:        entry.Answer,
+		AnswerCountry: entry.AnswerCountry,
+		AnswerASN:     entry.AnswerASN,
+		EdnsUDPSize:   entry.EDNSUDPSize,
+		EdnsDo:        entry.EDNSDNSSECOK,
+		EdnsEcs:       entry.EDNSECS,
+		EdnsCookie:    entry.EDNSCookie,
+		Hostname:      entry.BlockyInstance,
+	})
+	util.LogOnErrorWithEntry(archiveLogger().WithField("file_name", d.file.path), "can't write to file", err)
+}
+
+// CleanUp deletes old archive files
+func (d *ArchiveWriter) CleanUp() {
+	deleteOldFiles(archiveLogger(), d.target, d.logRetentionDays, func(name string) bool {
+		return strings.HasSuffix(name, archiveFileSuffix) || strings.HasSuffix(name, archiveFileSuffix+archivePartSuffix)
+	})
+}
+
+// periodicCompletion completes the file in progress once its period is over, even if there are no new entries
+func (d *ArchiveWriter) periodicCompletion(ctx context.Context) {
+	ticker := time.NewTicker(archiveCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.completeEnded(time.Now())
+
+		case <-ctx.Done():
+			d.lock.Lock()
+			defer d.lock.Unlock()
+
+			d.complete()
+
+			return
+		}
+	}
+}
+
+// completeEnded completes the file in progress if its period is over at now
+func (d *ArchiveWriter) completeEnded(now time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.file != nil && d.periodOf(now).After(d.file.period) {
+		d.complete()
+	}
+}
+
+// periodOf returns the start of the hour or day of t
+func (d *ArchiveWriter) periodOf(t time.Time) time.Time {
+	hour := 0
+	if d.hourly {
+		hour = t.Hour()
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, t.Location())
+}
+
+// create starts a new file for the period, numbered after the existing files of the period
+func (d *ArchiveWriter) create(period time.Time) (*archiveFile, error) {
+	layout := "2006-01-02"
+	if d.hourly {
+		layout = "2006-01-02T15"
+	}
+
+	var path string
+
+	for seq := 0; ; seq++ {
+		path = filepath.Join(d.target, fmt.Sprintf("%s.%03d%s", period.Format(layout), seq, archiveFileSuffix))
+
+		if !fileExists(path) && !fileExists(path+archivePartSuffix) {
+			break
+		}
+	}
+
+	file, err := os.OpenFile(path+archivePartSuffix, os.O_CREATE|os.O_EXCL|os.O_WRONLY, filePermission)
+	if err != nil {
+		return nil, err
+	}
+
+	size := &countingWriter{w: file}
+	gz := gzip.NewWriter(size)
+
+	return &archiveFile{
+		path:   path,
+		period: period,
+		file:   file,
+		size:   size,
+		gz:     gz,
+		enc:    json.NewEncoder(gz),
+	}, nil
+}
+
+// complete closes the file in progress and gives it its final name
+func (d *ArchiveWriter) complete() {
+	if d.file == nil {
+		return
+	}
+
+	file := d.file
+	d.file = nil
+
+	logger := archiveLogger().WithField("file_name", file.path)
+
+	err := errors.Join(file.gz.Close(), file.file.Close())
+	if err != nil {
+		logger.Error("can't complete file: ", err)
+
+		return
+	}
+
+	err = os.Rename(file.path+archivePartSuffix, file.path)
+	util.LogOnErrorWithEntry(logger, "can't rename file", err)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
@@ -0,0 +1,206 @@
+package querylog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/helpertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ArchiveWriter", func() {
+	var (
+		tmpDir *helpertest.TmpFolder
+		cfg    config.QueryLogArchive
+		writer *ArchiveWriter
+		err    error
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		tmpDir = helpertest.NewTmpFolder("archiveWriter")
+		cfg = config.QueryLogArchive{Rotation: config.QueryLogRotationDaily}
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+	})
+
+	JustBeforeEach(func() {
+		writer, err = NewArchiveWriter(ctx, tmpDir.Path, cfg, 1)
+		Expect(err).Should(Succeed())
+	})
+
+	listFiles := func() []string {
+		files, err := filepath.Glob(tmpDir.JoinPath("*"))
+		Expect(err).Should(Succeed())
+
+		for i, file := range files {
+			files[i] = filepath.Base(file)
+		}
+
+		return files
+	}
+
+	When("target dir does not exist", func() {
+		It("should return error", func() {
+			_, err = NewArchiveWriter(ctx, "wrongdir", cfg, 0)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	It("should write the entries as JSON lines", func() {
+		start := time.Date(2024, 5, 1, 10, 20, 30, 0, time.Local)
+
+		writer.Write(&LogEntry{
+			Start:        start,
+			ClientIP:     "192.168.178.25",
+			ClientNames:  []string{"client1", "client2"},
+			DurationMs:   20,
+			Categories:   []string{"ads", "tracking"},
+			QuestionName: "example.com",
+			EDNSUDPSize:  1232,
+			EDNSDNSSECOK: true,
+		})
+		writer.Write(&LogEntry{Start: start, QuestionName: "example.org"})
+
+		Expect(listFiles()).Should(ConsistOf("2024-05-01.000.jsonl.gz.part"))
+
+		writer.completeEnded(start.AddDate(0, 0, 1))
+
+		Expect(listFiles()).Should(ConsistOf("2024-05-01.000.jsonl.gz"))
+
+		lines := readArchive(tmpDir.JoinPath("2024-05-01.000.jsonl.gz"))
+		Expect(lines).Should(HaveLen(2))
+		Expect(lines[0]).Should(Equal(map[string]any{
+			"request_ts":     start.Format(time.RFC3339Nano),
+			"client_ip":      "192.168.178.25",
+			"client_name":    "client1; client2",
+			"duration_ms":    float64(20),
+			"reason":         "",
+			"categories":     "ads, tracking",
+			"response_type":  "",
+			"response_code":  "",
+			"question_type":  "",
+			"question_name":  "example.com",
+			"answer":         "",
+			"answer_country": "",
+			"answer_asn":     "",
+			"edns_udp_size":  float64(1232),
+			"edns_do":        true,
+			"edns_ecs":       false,
+			"edns_cookie":    false,
+			"hostname":       "",
+		}))
+		Expect(lines[1]).Should(HaveKeyWithValue("question_name", "example.org"))
+	})
+
+	It("should keep the file until its period is over", func() {
+		start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+
+		writer.Write(&LogEntry{Start: start})
+		writer.completeEnded(start.Add(time.Hour))
+
+		Expect(listFiles()).Should(ConsistOf("2024-05-01.000.jsonl.gz.part"))
+	})
+
+	It("should complete the file when the context is done", func() {
+		writer.Write(&LogEntry{Start: time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)})
+
+		cancelFn()
+
+		Eventually(listFiles).Should(ConsistOf("2024-05-01.000.jsonl.gz"))
+	})
+
+	When("rotation is hourly", func() {
+		BeforeEach(func() {
+			cfg.Rotation = config.QueryLogRotationHourly
+		})
+
+		It("should start a file per hour", func() {
+			start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+
+			writer.Write(&LogEntry{Start: start})
+			writer.Write(&LogEntry{Start: start.Add(time.Hour)})
+			// late entry of the previous hour
+			writer.Write(&LogEntry{Start: start.Add(time.Hour - time.Second)})
+
+			Expect(listFiles()).Should(ConsistOf("2024-05-01T10.000.jsonl.gz", "2024-05-01T11.000.jsonl.gz.part"))
+		})
+	})
+
+	When("the maximal file size is exceeded", func() {
+		BeforeEach(func() {
+			cfg.MaxFileSize = 1
+		})
+
+		It("should start a new file", func() {
+			start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+
+			writer.Write(&LogEntry{Start: start})
+			writer.file.size.n = bytesPerMiB
+			writer.Write(&LogEntry{Start: start})
+
+			Expect(listFiles()).Should(ConsistOf("2024-05-01.000.jsonl.gz", "2024-05-01.001.jsonl.gz.part"))
+		})
+	})
+
+	It("should not overwrite existing files", func() {
+		tmpDir.CreateEmptyFile("2024-05-01.000.jsonl.gz")
+
+		writer.Write(&LogEntry{Start: time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)})
+
+		Expect(listFiles()).Should(ConsistOf("2024-05-01.000.jsonl.gz", "2024-05-01.001.jsonl.gz.part"))
+	})
+
+	It("should delete files older than the retention", func() {
+		old := time.Now().AddDate(0, 0, -3).Format("2006-01-02")
+
+		tmpDir.CreateEmptyFile(old + ".000.jsonl.gz")
+		tmpDir.CreateEmptyFile(old + ".001.jsonl.gz.part")
+		tmpDir.CreateEmptyFile(old + "_ALL.log")
+
+		writer.Write(&LogEntry{Start: time.Now()})
+
+		writer.CleanUp()
+
+		Expect(listFiles()).Should(ConsistOf(
+			old+"_ALL.log",
+			time.Now().Format("2006-01-02")+".000.jsonl.gz.part",
+		))
+	})
+})
+
+func readArchive(path string) []map[string]any {
+	file, err := os.Open(path)
+	Expect(err).Should(Succeed())
+
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	Expect(err).Should(Succeed())
+
+	var result []map[string]any
+
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var line map[string]any
+
+		Expect(json.Unmarshal(scanner.Bytes(), &line)).Should(Succeed())
+
+		result = append(result, line)
+	}
+
+	Expect(scanner.Err()).Should(Succeed())
+
+	return result
+}
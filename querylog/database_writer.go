@@ -2,6 +2,8 @@ package querylog
 
 import (
 	"context"
+	"crypto/tls"
+	"database/sql"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -16,6 +18,9 @@ import (
 
 	"github.com/0xERR0R/blocky/util"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"golang.org/x/net/publicsuffix"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
@@ -28,12 +33,19 @@ type logEntry struct {
 	ClientName    string `gorm:"index"`
 	DurationMs    int64
 	Reason        string
+	Categories    string
 	ResponseType  string `gorm:"index"`
 	QuestionType  string
 	QuestionName  string
 	EffectiveTLDP string
 	Answer        string
+	AnswerCountry string
+	AnswerASN     string
 	ResponseCode  string
+	EdnsUDPSize   uint16
+	EdnsDo        bool
+	EdnsEcs       bool
+	EdnsCookie    bool
 	Hostname      string
 }
 
@@ -45,19 +57,96 @@ type DatabaseWriter struct {
 	dbFlushPeriod    time.Duration
 }
 
-func NewDatabaseWriter(ctx context.Context, dbType, target string, logRetentionDays uint64,
+// NewDatabaseWriter creates a writer for the given database type.
+//
+// If tlsCfg is not nil and the target enables TLS, the TLS settings from tlsCfg are used for the connection.
+func NewDatabaseWriter(ctx context.Context, dbType, target string, tlsCfg *tls.Config, logRetentionDays uint64,
 	dbFlushPeriod time.Duration,
 ) (*DatabaseWriter, error) {
 	switch dbType {
 	case "mysql":
-		return newDatabaseWriter(ctx, mysql.Open(target), logRetentionDays, dbFlushPeriod, dbType)
+		dialector, err := mysqlDialector(target, tlsCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return newDatabaseWriter(ctx, dialector, logRetentionDays, dbFlushPeriod, dbType)
 	case "postgresql", "timescale":
-		return newDatabaseWriter(ctx, postgres.Open(target), logRetentionDays, dbFlushPeriod, dbType)
+		dialector, err := postgresDialector(target, tlsCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return newDatabaseWriter(ctx, dialector, logRetentionDays, dbFlushPeriod, dbType)
 	}
 
 	return nil, fmt.Errorf("incorrect database type provided: %s", dbType)
 }
 
+func mysqlDialector(target string, tlsCfg *tls.Config) (gorm.Dialector, error) {
+	if tlsCfg == nil {
+		return mysql.Open(target), nil
+	}
+
+	dsn, err := mysqldriver.ParseDSN(target)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse database target: %w", err)
+	}
+
+	if dsn.TLS == nil {
+		return mysql.Open(target), nil
+	}
+
+	applyTLSSettings(dsn.TLS, tlsCfg)
+
+	connector, err := mysqldriver.NewConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("can't create database connector: %w", err)
+	}
+
+	return mysql.New(mysql.Config{Conn: sql.OpenDB(connector)}), nil
+}
+
+func postgresDialector(target string, tlsCfg *tls.Config) (gorm.Dialector, error) {
+	if tlsCfg == nil {
+		return postgres.Open(target), nil
+	}
+
+	connCfg, err := pgx.ParseConfig(target)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse database target: %w", err)
+	}
+
+	if connCfg.TLSConfig == nil {
+		return postgres.Open(target), nil
+	}
+
+	applyTLSSettings(connCfg.TLSConfig, tlsCfg)
+
+	for _, fallback := range connCfg.Fallbacks {
+		if fallback.TLSConfig != nil {
+			applyTLSSettings(fallback.TLSConfig, tlsCfg)
+		}
+	}
+
+	return postgres.New(postgres.Config{Conn: stdlib.OpenDB(*connCfg)}), nil
+}
+
+// applyTLSSettings copies the policy related settings, but keeps connection specific ones like the server name
+func applyTLSSettings(dst, src *tls.Config) {
+	dst.MinVersion = src.MinVersion
+	dst.CipherSuites = src.CipherSuites
+	dst.CurvePreferences = src.CurvePreferences
+
+	if src.RootCAs != nil {
+		dst.RootCAs = src.RootCAs
+	}
+
+	if len(src.Certificates) != 0 {
+		dst.Certificates = src.Certificates
+	}
+}
+
 func newDatabaseWriter(ctx context.Context, target gorm.Dialector, logRetentionDays uint64,
 	dbFlushPeriod time.Duration, dbType string,
 ) (*DatabaseWriter, error) {
@@ -170,12 +259,19 @@ func (d *DatabaseWriter) Write(entry *LogEntry) {
 		ClientName:    strings.Join(entry.ClientNames, "; "),
 		DurationMs:    entry.DurationMs,
 		Reason:        entry.ResponseReason,
+		Categories:    strings.Join(entry.Categories, ", "),
 		ResponseType:  entry.ResponseType,
 		QuestionType:  entry.QuestionType,
 		QuestionName:  domain,
 		EffectiveTLDP: eTLD,
 		Answer:        entry.Answer,
+		AnswerCountry: entry.AnswerCountry,
+		AnswerASN:     entry.AnswerASN,
 		ResponseCode:  entry.ResponseCode,
+		EdnsUDPSize:   entry.EDNSUDPSize,
+		EdnsDo:        entry.EDNSDNSSECOK,
+		EdnsEcs:       entry.EDNSECS,
+		EdnsCookie:    entry.EDNSCookie,
 		Hostname:      entry.BlockyInstance,
 	}
 
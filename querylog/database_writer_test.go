@@ -170,7 +170,7 @@ var _ = Describe("DatabaseWriter", func() {
 	Describe("Database query log fails", func() {
 		When("mysql connection parameters wrong", func() {
 			It("should be log with fatal", func() {
-				_, err := NewDatabaseWriter(ctx, "mysql", "wrong param", 7, 1)
+				_, err := NewDatabaseWriter(ctx, "mysql", "wrong param", nil, 7, 1)
 				Expect(err).Should(HaveOccurred())
 				Expect(err.Error()).Should(HavePrefix("can't create database connection"))
 			})
@@ -178,7 +178,7 @@ var _ = Describe("DatabaseWriter", func() {
 
 		When("postgresql connection parameters wrong", func() {
 			It("should be log with fatal", func() {
-				_, err := NewDatabaseWriter(ctx, "postgresql", "wrong param", 7, 1)
+				_, err := NewDatabaseWriter(ctx, "postgresql", "wrong param", nil, 7, 1)
 				Expect(err).Should(HaveOccurred())
 				Expect(err.Error()).Should(HavePrefix("can't create database connection"))
 			})
@@ -186,7 +186,7 @@ var _ = Describe("DatabaseWriter", func() {
 
 		When("invalid database type is specified", func() {
 			It("should be log with fatal", func() {
-				_, err := NewDatabaseWriter(ctx, "invalidsql", "", 7, 1)
+				_, err := NewDatabaseWriter(ctx, "invalidsql", "", nil, 7, 1)
 				Expect(err).Should(HaveOccurred())
 				Expect(err.Error()).Should(HavePrefix("incorrect database type provided"))
 			})
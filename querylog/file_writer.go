@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -72,30 +73,35 @@ func (d *FileWriter) Write(entry *LogEntry) {
 
 // CleanUp deletes old log files
 func (d *FileWriter) CleanUp() {
-	const hoursPerDay = 24
+	deleteOldFiles(log.PrefixedLog(loggerPrefixFileWriter), d.target, d.logRetentionDays, func(name string) bool {
+		return strings.HasSuffix(name, ".log")
+	})
+}
 
-	logger := log.PrefixedLog(loggerPrefixFileWriter)
+// deleteOldFiles deletes the log files in dir, which names start with a date older than logRetentionDays
+func deleteOldFiles(logger *logrus.Entry, dir string, logRetentionDays uint64, isLogFile func(name string) bool) {
+	const hoursPerDay = 24
 
 	logger.Trace("starting clean up")
 
-	files, err := os.ReadDir(d.target)
+	files, err := os.ReadDir(dir)
 
-	util.LogOnErrorWithEntry(logger.WithField("target", d.target), "can't list log directory: ", err)
+	util.LogOnErrorWithEntry(logger.WithField("target", dir), "can't list log directory: ", err)
 
 	// search for log files, which names starts with date
 	for _, f := range files {
-		if strings.HasSuffix(f.Name(), ".log") && len(f.Name()) > 10 {
+		if isLogFile(f.Name()) && len(f.Name()) > 10 {
 			t, err := time.ParseInLocation("2006-01-02", f.Name()[:10], time.Local)
 			if err == nil {
 				differenceDays := uint64(time.Since(t).Hours() / hoursPerDay)
-				if d.logRetentionDays > 0 && differenceDays > d.logRetentionDays {
+				if logRetentionDays > 0 && differenceDays > logRetentionDays {
 					logger.WithFields(logrus.Fields{
 						"file":             f.Name(),
 						"ageInDays":        differenceDays,
-						"logRetentionDays": d.logRetentionDays,
+						"logRetentionDays": logRetentionDays,
 					}).Info("existing log file is older than retention time and will be deleted")
 
-					err := os.Remove(filepath.Join(d.target, f.Name()))
+					err := os.Remove(filepath.Join(dir, f.Name()))
 					util.LogOnErrorWithEntry(logger.WithField("file", f.Name()), "can't remove file: ", err)
 				}
 			}
@@ -116,6 +122,13 @@ func createQueryLogRow(logEntry *LogEntry) []string {
 		logEntry.ResponseType,
 		logEntry.QuestionType,
 		logEntry.BlockyInstance,
+		logEntry.AnswerCountry,
+		logEntry.AnswerASN,
+		strings.Join(logEntry.Categories, ", "),
+		strconv.FormatUint(uint64(logEntry.EDNSUDPSize), 10),
+		strconv.FormatBool(logEntry.EDNSDNSSECOK),
+		strconv.FormatBool(logEntry.EDNSECS),
+		strconv.FormatBool(logEntry.EDNSCookie),
 	}
 }
 
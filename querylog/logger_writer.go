@@ -33,11 +33,18 @@ func LogEntryFields(entry *LogEntry) logrus.Fields {
 		"client_ip":       entry.ClientIP,
 		"client_names":    strings.Join(entry.ClientNames, "; "),
 		"response_reason": entry.ResponseReason,
+		"categories":      strings.Join(entry.Categories, ", "),
 		"response_type":   entry.ResponseType,
 		"response_code":   entry.ResponseCode,
 		"question_name":   entry.QuestionName,
 		"question_type":   entry.QuestionType,
 		"answer":          entry.Answer,
+		"answer_country":  entry.AnswerCountry,
+		"answer_asn":      entry.AnswerASN,
+		"edns_udp_size":   entry.EDNSUDPSize,
+		"edns_do":         entry.EDNSDNSSECOK,
+		"edns_ecs":        entry.EDNSECS,
+		"edns_cookie":     entry.EDNSCookie,
 		"duration_ms":     entry.DurationMs,
 		"instance":        entry.BlockyInstance,
 	})
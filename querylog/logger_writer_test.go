@@ -39,10 +39,15 @@ var _ = Describe("LoggerWriter", func() {
 	Describe("LogEntryFields", func() {
 		It("should return log fields", func() {
 			entry := LogEntry{
-				ClientIP:     "ip",
-				DurationMs:   100,
-				QuestionType: "qtype",
-				ResponseCode: "rcode",
+				ClientIP:      "ip",
+				DurationMs:    100,
+				QuestionType:  "qtype",
+				ResponseCode:  "rcode",
+				AnswerCountry: "DE",
+				AnswerASN:     "AS64500",
+				Categories:    []string{"ads", "tracking"},
+				EDNSUDPSize:   1232,
+				EDNSECS:       true,
 			}
 
 			fields := LogEntryFields(&entry)
@@ -51,9 +56,15 @@ var _ = Describe("LoggerWriter", func() {
 			Expect(fields).Should(HaveKeyWithValue("duration_ms", entry.DurationMs))
 			Expect(fields).Should(HaveKeyWithValue("question_type", entry.QuestionType))
 			Expect(fields).Should(HaveKeyWithValue("response_code", entry.ResponseCode))
+			Expect(fields).Should(HaveKeyWithValue("answer_country", entry.AnswerCountry))
+			Expect(fields).Should(HaveKeyWithValue("answer_asn", entry.AnswerASN))
+			Expect(fields).Should(HaveKeyWithValue("categories", "ads, tracking"))
+			Expect(fields).Should(HaveKeyWithValue("edns_udp_size", entry.EDNSUDPSize))
+			Expect(fields).Should(HaveKeyWithValue("edns_ecs", true))
 
 			Expect(fields).ShouldNot(HaveKey("client_names"))
 			Expect(fields).ShouldNot(HaveKey("question_name"))
+			Expect(fields).ShouldNot(HaveKey("edns_do"))
 		})
 	})
 
@@ -0,0 +1,51 @@
+package querylog
+
+import "sync"
+
+// Recent keeps the latest log entries in memory, independent of the writer
+type Recent struct {
+	lock    sync.RWMutex
+	entries []*LogEntry
+	next    int
+	full    bool
+}
+
+// NewRecent creates a buffer for the latest size entries
+func NewRecent(size int) *Recent {
+	return &Recent{entries: make([]*LogEntry, size)}
+}
+
+// Add stores the entry, replacing the oldest one if the buffer is full
+func (r *Recent) Add(entry *LogEntry) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.entries[r.next] = entry
+
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// Entries returns up to limit of the latest entries, newest first
+func (r *Recent) Entries(limit int) []*LogEntry {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.entries)
+	}
+
+	count = min(count, limit)
+
+	res := make([]*LogEntry, 0, count)
+
+	for i := 1; i <= count; i++ {
+		res = append(res, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+
+	return res
+}
@@ -0,0 +1,61 @@
+package querylog
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recent", func() {
+	var sut *Recent
+
+	entry := func(name string) *LogEntry {
+		return &LogEntry{QuestionName: name}
+	}
+
+	names := func(entries []*LogEntry) []string {
+		res := make([]string, 0, len(entries))
+		for _, e := range entries {
+			res = append(res, e.QuestionName)
+		}
+
+		return res
+	}
+
+	BeforeEach(func() {
+		sut = NewRecent(3)
+	})
+
+	When("no entry was added", func() {
+		It("should return nothing", func() {
+			Expect(sut.Entries(10)).Should(BeEmpty())
+		})
+	})
+
+	When("less entries than the size were added", func() {
+		BeforeEach(func() {
+			sut.Add(entry("a"))
+			sut.Add(entry("b"))
+		})
+
+		It("should return them newest first", func() {
+			Expect(names(sut.Entries(10))).Should(Equal([]string{"b", "a"}))
+		})
+
+		It("should respect the limit", func() {
+			Expect(names(sut.Entries(1))).Should(Equal([]string{"b"}))
+		})
+	})
+
+	When("more entries than the size were added", func() {
+		BeforeEach(func() {
+			for _, name := range []string{"a", "b", "c", "d", "e"} {
+				sut.Add(entry(name))
+			}
+		})
+
+		It("should keep only the latest ones", func() {
+			Expect(names(sut.Entries(10))).Should(Equal([]string{"e", "d", "c"}))
+			Expect(names(sut.Entries(2))).Should(Equal([]string{"e", "d"}))
+		})
+	})
+})
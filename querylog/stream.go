@@ -0,0 +1,50 @@
+package querylog
+
+import "sync"
+
+// Stream passes new log entries to its subscribers.
+// Entries are dropped for subscribers which don't keep up, so a slow subscriber never blocks logging.
+type Stream struct {
+	lock        sync.RWMutex
+	subscribers map[chan *LogEntry]struct{}
+}
+
+// NewStream creates a stream without subscribers
+func NewStream() *Stream {
+	return &Stream{subscribers: make(map[chan *LogEntry]struct{})}
+}
+
+// Publish passes the entry to all subscribers with free buffer space
+func (s *Stream) Publish(entry *LogEntry) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- entry:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving new entries, buffering up to size entries.
+// The returned function ends the subscription and closes the channel.
+func (s *Stream) Subscribe(size int) (<-chan *LogEntry, func()) {
+	ch := make(chan *LogEntry, size)
+
+	s.lock.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.lock.Unlock()
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			s.lock.Lock()
+			delete(s.subscribers, ch)
+			s.lock.Unlock()
+
+			close(ch)
+		})
+	}
+}
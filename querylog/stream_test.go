@@ -0,0 +1,51 @@
+package querylog
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stream", func() {
+	var sut *Stream
+
+	BeforeEach(func() {
+		sut = NewStream()
+	})
+
+	It("should pass entries to all subscribers", func() {
+		first, cancelFirst := sut.Subscribe(1)
+		DeferCleanup(cancelFirst)
+
+		second, cancelSecond := sut.Subscribe(1)
+		DeferCleanup(cancelSecond)
+
+		entry := &LogEntry{QuestionName: "example.com"}
+		sut.Publish(entry)
+
+		Expect(first).Should(Receive(BeIdenticalTo(entry)))
+		Expect(second).Should(Receive(BeIdenticalTo(entry)))
+	})
+
+	It("should drop entries if the buffer of a subscriber is full", func() {
+		entries, cancel := sut.Subscribe(1)
+		DeferCleanup(cancel)
+
+		sut.Publish(&LogEntry{QuestionName: "a"})
+		sut.Publish(&LogEntry{QuestionName: "b"})
+
+		Expect(entries).Should(Receive(HaveField("QuestionName", "a")))
+		Expect(entries).ShouldNot(Receive())
+	})
+
+	It("should close the channel when the subscription ends", func() {
+		entries, cancel := sut.Subscribe(1)
+
+		cancel()
+		cancel()
+
+		Expect(entries).Should(BeClosed())
+
+		// publishing without subscribers is no problem
+		sut.Publish(&LogEntry{})
+	})
+})
@@ -10,11 +10,20 @@ type LogEntry struct {
 	ClientNames    []string
 	DurationMs     int64
 	ResponseReason string
-	ResponseType   string
-	ResponseCode   string
-	QuestionType   string
-	QuestionName   string
-	Answer         string
+	// Categories of the denylist groups which blocked the query
+	Categories    []string
+	ResponseType  string
+	ResponseCode  string
+	QuestionType  string
+	QuestionName  string
+	Answer        string
+	AnswerCountry string
+	AnswerASN     string
+	// EDNS of the query, the UDP size is 0 without OPT record
+	EDNSUDPSize    uint16
+	EDNSDNSSECOK   bool
+	EDNSECS        bool
+	EDNSCookie     bool
 	BlockyInstance string
 }
 
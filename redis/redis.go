@@ -3,15 +3,15 @@ package redis
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"math"
 	"strings"
 	"time"
 
+	"github.com/0xERR0R/blocky/cachesync"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/log"
-	"github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/util"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
@@ -23,8 +23,6 @@ const (
 	SyncChannelName   = "blocky_sync"
 	CacheStorePrefix  = "blocky:cache:"
 	chanCap           = 1000
-	cacheReason       = "EXTERNAL_CACHE"
-	defaultCacheTime  = 1 * time.Second
 	messageTypeCache  = 0
 	messageTypeEnable = 1
 )
@@ -43,18 +41,6 @@ type redisMessage struct {
 	Client  []byte `json:"c"`
 }
 
-// CacheChannel message
-type CacheMessage struct {
-	Key      string
-	Response *model.Response
-}
-
-type EnabledMessage struct {
-	State    bool          `json:"s"`
-	Duration time.Duration `json:"d,omitempty"`
-	Groups   []string      `json:"g,omitempty"`
-}
-
 // Client for redis communication
 type Client struct {
 	config         *config.Redis
@@ -62,8 +48,8 @@ type Client struct {
 	l              *logrus.Entry
 	id             []byte
 	sendBuffer     chan *bufferMessage
-	CacheChannel   chan *CacheMessage
-	EnabledChannel chan *EnabledMessage
+	CacheChannel   chan *cachesync.CacheMessage
+	EnabledChannel chan *cachesync.EnabledMessage
 }
 
 // New creates a new redis client
@@ -73,6 +59,47 @@ func New(ctx context.Context, cfg *config.Redis) (*Client, error) {
 		return nil, nil //nolint:nilnil
 	}
 
+	rdb, err := Connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.New().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	// construct client
+	res := &Client{
+		config:         cfg,
+		client:         rdb,
+		l:              log.PrefixedLog("redis"),
+		id:             id,
+		sendBuffer:     make(chan *bufferMessage, chanCap),
+		CacheChannel:   make(chan *cachesync.CacheMessage, chanCap),
+		EnabledChannel: make(chan *cachesync.EnabledMessage, chanCap),
+	}
+
+	// start channel handling go routine
+	err = res.startup(ctx)
+
+	return res, err
+}
+
+// Connect creates a connection to the configured redis server and checks that it is reachable
+func Connect(ctx context.Context, cfg *config.Redis) (*redis.Client, error) {
+	var tlsCfg *tls.Config
+
+	if cfg.TLS != nil {
+		var err error
+
+		// empty server name: the TLS dialer uses the host of each (sentinel) address
+		tlsCfg, err = cfg.TLS.NewClientTLSConfig("")
+		if err != nil {
+			return nil, fmt.Errorf("can't create redis TLS config: %w", err)
+		}
+	}
+
 	var baseClient *redis.Client
 	if len(cfg.SentinelAddresses) > 0 {
 		baseClient = redis.NewFailoverClient(&redis.FailoverOptions{
@@ -85,6 +112,7 @@ func New(ctx context.Context, cfg *config.Redis) (*Client, error) {
 			DB:               cfg.Database,
 			MaxRetries:       cfg.ConnectionAttempts,
 			MaxRetryBackoff:  cfg.ConnectionCooldown.ToDuration(),
+			TLSConfig:        tlsCfg,
 		})
 	} else {
 		baseClient = redis.NewClient(&redis.Options{
@@ -94,36 +122,22 @@ func New(ctx context.Context, cfg *config.Redis) (*Client, error) {
 			DB:              cfg.Database,
 			MaxRetries:      cfg.ConnectionAttempts,
 			MaxRetryBackoff: cfg.ConnectionCooldown.ToDuration(),
+			TLSConfig:       tlsCfg,
 		})
 	}
 
 	rdb := baseClient.WithContext(ctx)
 
-	_, err := rdb.Ping(ctx).Result()
-	if err == nil {
-		var id []byte
-
-		id, err = uuid.New().MarshalBinary()
-		if err == nil {
-			// construct client
-			res := &Client{
-				config:         cfg,
-				client:         rdb,
-				l:              log.PrefixedLog("redis"),
-				id:             id,
-				sendBuffer:     make(chan *bufferMessage, chanCap),
-				CacheChannel:   make(chan *CacheMessage, chanCap),
-				EnabledChannel: make(chan *EnabledMessage, chanCap),
-			}
-
-			// start channel handling go routine
-			err = res.startup(ctx)
-
-			return res, err
-		}
+	if _, err := rdb.Ping(ctx).Result(); err != nil {
+		return nil, err
 	}
 
-	return nil, err
+	return rdb, nil
+}
+
+// Ping implements `cachesync.Client`
+func (c *Client) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
 }
 
 // PublishCache publish cache to redis async
@@ -136,7 +150,8 @@ func (c *Client) PublishCache(key string, message *dns.Msg) {
 	}
 }
 
-func (c *Client) PublishEnabled(ctx context.Context, state *EnabledMessage) {
+// PublishEnabled publishes the blocking state
+func (c *Client) PublishEnabled(ctx context.Context, state *cachesync.EnabledMessage) {
 	binState, sErr := json.Marshal(state)
 	if sErr == nil {
 		binMsg, mErr := json.Marshal(redisMessage{
@@ -151,14 +166,24 @@ func (c *Client) PublishEnabled(ctx context.Context, state *EnabledMessage) {
 	}
 }
 
-// GetRedisCache reads the redis cache and publish it to the channel
-func (c *Client) GetRedisCache(ctx context.Context) {
-	c.l.Debug("GetRedisCache")
+// CacheMessages implements `cachesync.Client`
+func (c *Client) CacheMessages() <-chan *cachesync.CacheMessage {
+	return c.CacheChannel
+}
+
+// EnabledMessages implements `cachesync.Client`
+func (c *Client) EnabledMessages() <-chan *cachesync.EnabledMessage {
+	return c.EnabledChannel
+}
+
+// LoadCache reads the redis cache and publish it to the channel
+func (c *Client) LoadCache(ctx context.Context) {
+	c.l.Debug("LoadCache")
 
 	go func() {
 		iter := c.client.Scan(ctx, 0, prefixKey("*"), 0).Iterator()
 		if err := iter.Err(); err != nil {
-			c.l.Error("GetRedisCache ", err)
+			c.l.Error("LoadCache ", err)
 
 			return
 		}
@@ -172,7 +197,7 @@ func (c *Client) GetRedisCache(ctx context.Context) {
 					}
 				}
 			} else {
-				c.l.Error("GetRedisCache ", err)
+				c.l.Error("LoadCache ", err)
 			}
 		}
 	}()
@@ -231,7 +256,7 @@ func (c *Client) publishMessageFromBuffer(ctx context.Context, s *bufferMessage)
 		c.client.Set(ctx,
 			prefixKey(s.Key),
 			binRes,
-			c.getTTL(origRes))
+			cachesync.TTL(origRes))
 	}
 }
 
@@ -248,9 +273,7 @@ func (c *Client) processReceivedMessage(ctx context.Context, msg *redis.Message)
 	if !bytes.Equal(rm.Client, c.id) {
 		switch rm.Type {
 		case messageTypeCache:
-			var cm *CacheMessage
-
-			cm, err := convertMessage(&rm, 0)
+			cm, err := cachesync.NewCacheMessage(rm.Key, rm.Message, 0)
 			if err != nil {
 				c.l.Error("Processing CacheMessage error: ", err)
 
@@ -259,7 +282,7 @@ func (c *Client) processReceivedMessage(ctx context.Context, msg *redis.Message)
 
 			util.CtxSend(ctx, c.CacheChannel, cm)
 		case messageTypeEnable:
-			var msg EnabledMessage
+			var msg cachesync.EnabledMessage
 
 			if err := json.Unmarshal(rm.Message, &msg); err != nil {
 				c.l.Error("Processing EnabledMessage error: ", err)
@@ -275,19 +298,16 @@ func (c *Client) processReceivedMessage(ctx context.Context, msg *redis.Message)
 }
 
 // getResponse returns model.Response for a key
-func (c *Client) getResponse(ctx context.Context, key string) (*CacheMessage, error) {
+func (c *Client) getResponse(ctx context.Context, key string) (*cachesync.CacheMessage, error) {
 	resp, err := c.client.Get(ctx, key).Result()
 	if err == nil {
 		var ttl time.Duration
 		ttl, err = c.client.TTL(ctx, key).Result()
 
 		if err == nil {
-			var result *CacheMessage
+			var result *cachesync.CacheMessage
 
-			result, err = convertMessage(&redisMessage{
-				Key:     cleanKey(key),
-				Message: []byte(resp),
-			}, ttl)
+			result, err = cachesync.NewCacheMessage(cleanKey(key), []byte(resp), ttl)
 			if err != nil {
 				return nil, fmt.Errorf("conversion error: %w", err)
 			}
@@ -299,47 +319,6 @@ func (c *Client) getResponse(ctx context.Context, key string) (*CacheMessage, er
 	return nil, err
 }
 
-// convertMessage converts redisMessage to CacheMessage
-func convertMessage(message *redisMessage, ttl time.Duration) (*CacheMessage, error) {
-	msg := dns.Msg{}
-
-	err := msg.Unpack(message.Message)
-	if err == nil {
-		if ttl > 0 {
-			for _, a := range msg.Answer {
-				a.Header().Ttl = uint32(ttl.Seconds())
-			}
-		}
-
-		res := &CacheMessage{
-			Key: message.Key,
-			Response: &model.Response{
-				RType:  model.ResponseTypeCACHED,
-				Reason: cacheReason,
-				Res:    &msg,
-			},
-		}
-
-		return res, nil
-	}
-
-	return nil, err
-}
-
-// getTTL of dns message or return defaultCacheTime if 0
-func (c *Client) getTTL(dns *dns.Msg) time.Duration {
-	ttl := uint32(math.MaxInt32)
-	for _, a := range dns.Answer {
-		ttl = min(ttl, a.Header().Ttl)
-	}
-
-	if ttl == 0 {
-		return defaultCacheTime
-	}
-
-	return time.Duration(ttl) * time.Second
-}
-
 // prefixKey with CacheStorePrefix
 func prefixKey(key string) string {
 	return fmt.Sprintf("%s%s", CacheStorePrefix, key)
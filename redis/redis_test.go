@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/0xERR0R/blocky/cachesync"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/util"
 	"github.com/alicebob/miniredis/v2"
@@ -77,6 +78,22 @@ var _ = Describe("Redis client", func() {
 		})
 	})
 
+	Describe("Ping", func() {
+		It("should fail if the server is not reachable anymore", func(ctx context.Context) {
+			redisServer := setupRedisServer(redisConfig)
+			redisConfig.ConnectionAttempts = 1
+
+			redisClient, err = New(ctx, redisConfig)
+			Expect(err).Should(Succeed())
+
+			Expect(redisClient.Ping(ctx)).Should(Succeed())
+
+			redisServer.Close()
+
+			Expect(redisClient.Ping(ctx)).ShouldNot(Succeed())
+		})
+	})
+
 	Describe("Publish message", func() {
 		var redisServer *miniredis.Miniredis
 		BeforeEach(func() {
@@ -136,7 +153,7 @@ var _ = Describe("Redis client", func() {
 					}).Should(BeTrue())
 
 					ttl := redisServer.DB(redisConfig.Database).TTL(exampleComKey)
-					Expect(ttl.Seconds()).Should(BeNumerically("~", defaultCacheTime.Seconds()))
+					Expect(ttl.Seconds()).Should(BeNumerically("~", cachesync.DefaultTTL.Seconds()))
 				})
 			})
 		})
@@ -145,7 +162,7 @@ var _ = Describe("Redis client", func() {
 				redisClient, err = New(ctx, redisConfig)
 				Expect(err).Should(Succeed())
 
-				redisClient.PublishEnabled(ctx, &EnabledMessage{
+				redisClient.PublishEnabled(ctx, &cachesync.EnabledMessage{
 					State: true,
 				})
 				Eventually(func() map[string]int {
@@ -166,7 +183,7 @@ var _ = Describe("Redis client", func() {
 				Expect(err).Should(Succeed())
 
 				var binState []byte
-				binState, err = json.Marshal(EnabledMessage{State: true})
+				binState, err = json.Marshal(cachesync.EnabledMessage{State: true})
 				Expect(err).Should(Succeed())
 
 				var id []byte
@@ -186,7 +203,7 @@ var _ = Describe("Redis client", func() {
 				rec := redisServer.Publish(SyncChannelName, string(binMsg))
 				Expect(rec).Should(Equal(1))
 
-				Eventually(func() chan *EnabledMessage {
+				Eventually(func() chan *cachesync.EnabledMessage {
 					return redisClient.EnabledChannel
 				}).Should(HaveLen(lenE + 1))
 			})
@@ -222,7 +239,7 @@ var _ = Describe("Redis client", func() {
 				rec := redisServer.Publish(SyncChannelName, string(binMsg))
 				Expect(rec).Should(Equal(1))
 
-				Eventually(func() chan *CacheMessage {
+				Eventually(func() chan *cachesync.CacheMessage {
 					return redisClient.CacheChannel
 				}).Should(HaveLen(lenE + 1))
 			}, SpecTimeout(time.Second*6))
@@ -251,11 +268,11 @@ var _ = Describe("Redis client", func() {
 				rec := redisServer.Publish(SyncChannelName, string(binMsg))
 				Expect(rec).Should(Equal(1))
 
-				Eventually(func() chan *EnabledMessage {
+				Eventually(func() chan *cachesync.EnabledMessage {
 					return redisClient.EnabledChannel
 				}).Should(HaveLen(lenE))
 
-				Eventually(func() chan *CacheMessage {
+				Eventually(func() chan *cachesync.CacheMessage {
 					return redisClient.CacheChannel
 				}).Should(HaveLen(lenC))
 			}, SpecTimeout(time.Second*6))
@@ -284,11 +301,11 @@ var _ = Describe("Redis client", func() {
 
 				time.Sleep(2 * time.Second)
 
-				Eventually(func() chan *EnabledMessage {
+				Eventually(func() chan *cachesync.EnabledMessage {
 					return redisClient.EnabledChannel
 				}).Should(HaveLen(lenE))
 
-				Eventually(func() chan *CacheMessage {
+				Eventually(func() chan *cachesync.CacheMessage {
 					return redisClient.CacheChannel
 				}).Should(HaveLen(lenC))
 			}, SpecTimeout(time.Second*6))
@@ -300,7 +317,7 @@ var _ = Describe("Redis client", func() {
 		BeforeEach(func() {
 			redisServer = setupRedisServer(redisConfig)
 		})
-		When("GetRedisCache is called with valid database entries", func() {
+		When("LoadCache is called with valid database entries", func() {
 			It("Should read data from Redis and propagate it via cache channel", func(ctx context.Context) {
 				redisClient, err = New(ctx, redisConfig)
 				Expect(err).Should(Succeed())
@@ -327,20 +344,20 @@ var _ = Describe("Redis client", func() {
 					}).Should(HaveLen(1))
 				})
 
-				By("call GetRedisCache - It should read one entry from redis and propagate it via channel", func() {
-					redisClient.GetRedisCache(ctx)
+				By("call LoadCache - It should read one entry from redis and propagate it via channel", func() {
+					redisClient.LoadCache(ctx)
 
 					Eventually(redisClient.CacheChannel).Should(HaveLen(1))
 				})
 			}, SpecTimeout(time.Second*4))
 		})
-		When("GetRedisCache is called and database contains not valid entry", func() {
+		When("LoadCache is called and database contains not valid entry", func() {
 			It("Should do nothing (only log error)", func(ctx context.Context) {
 				redisClient, err = New(ctx, redisConfig)
 				Expect(err).Should(Succeed())
 
 				Expect(redisServer.DB(redisConfig.Database).Set(CacheStorePrefix+"test", "test")).Should(Succeed())
-				redisClient.GetRedisCache(ctx)
+				redisClient.LoadCache(ctx)
 				Consistently(redisClient.CacheChannel).Should(BeEmpty())
 			}, SpecTimeout(time.Second*2))
 		})
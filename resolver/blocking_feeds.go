@@ -0,0 +1,123 @@
+package resolver
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/lists"
+	"github.com/0xERR0R/blocky/log"
+
+	"github.com/sirupsen/logrus"
+)
+
+// blockingFeed is a threat-intel feed with its own list cache, so its frequent refreshes don't reload the denylists
+type blockingFeed struct {
+	name  string
+	cfg   config.BlockingFeed
+	cache *lists.ListCache
+
+	// unix nanos of the last successful refresh, or of the start until the first one
+	lastRefresh atomic.Int64
+	stale       atomic.Bool
+}
+
+func newBlockingFeeds(ctx context.Context, cfg *config.Blocking, downloader lists.FileDownloader,
+) (map[string]*blockingFeed, error) {
+	feeds := make(map[string]*blockingFeed, len(cfg.Feeds))
+
+	for name, feedCfg := range cfg.Feeds {
+		feed := &blockingFeed{name: name, cfg: feedCfg}
+		feed.lastRefresh.Store(time.Now().UnixNano())
+		feeds[name] = feed
+	}
+
+	if len(feeds) == 0 {
+		return feeds, nil
+	}
+
+	// subscribe before the initial load, to not miss it
+	err := evt.Bus().Subscribe(evt.BlockingCacheGroupChanged,
+		func(listType lists.ListCacheType, group string, _ int) {
+			if feed, ok := feeds[group]; ok && listType == lists.ListCacheTypeFeed && ctx.Err() == nil {
+				feed.refreshed()
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for name, feed := range feeds {
+		feed.cache, err = lists.NewListCache(ctx, lists.ListCacheTypeFeed,
+			feed.cfg.Loading(cfg.Loading), map[string][]config.BytesSource{name: feed.cfg.Sources}, downloader, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		go feed.watch(ctx)
+	}
+
+	return feeds, nil
+}
+
+func (f *blockingFeed) logger() *logrus.Entry {
+	return log.PrefixedLog("blocking_feeds").WithField("feed", f.name)
+}
+
+func (f *blockingFeed) refreshed() {
+	f.lastRefresh.Store(time.Now().UnixNano())
+
+	if f.stale.CompareAndSwap(true, false) {
+		f.logger().Info("feed is up to date again")
+
+		evt.Bus().Publish(evt.BlockingFeedStale, f.name, false)
+	}
+}
+
+// watch reports the feed as stale, if it wasn't refreshed within its max age
+func (f *blockingFeed) watch(ctx context.Context) {
+	ticker := time.NewTicker(f.cfg.RefreshPeriod.ToDuration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.checkStale(time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (f *blockingFeed) checkStale(now time.Time) {
+	age := now.Sub(time.Unix(0, f.lastRefresh.Load()))
+
+	if age > f.cfg.MaxAge.ToDuration() && f.stale.CompareAndSwap(false, true) {
+		f.logger().Warnf("feed was not refreshed for %s, still blocking with the last loaded entries", config.Duration(age))
+
+		evt.Bus().Publish(evt.BlockingFeedStale, f.name, true)
+	}
+}
+
+// denylistMatches returns the denylist groups and the feeds of `groupsToCheck` containing the entry
+func (r *BlockingResolver) denylistMatches(groupsToCheck []string, entry string) []string {
+	groups := r.matches(groupsToCheck, r.denylistMatcher, entry)
+
+	matchedFeed := false
+
+	for name, feed := range r.feeds {
+		if slices.Contains(groupsToCheck, name) && len(feed.cache.Match(entry, []string{name})) > 0 {
+			groups = append(groups, name)
+			matchedFeed = true
+		}
+	}
+
+	if matchedFeed {
+		slices.Sort(groups)
+	}
+
+	return groups
+}
@@ -2,6 +2,7 @@ package resolver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"slices"
@@ -17,28 +18,39 @@ import (
 	"github.com/hashicorp/go-multierror"
 
 	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/cachesync"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/geoip"
 	"github.com/0xERR0R/blocky/lists"
+	"github.com/0xERR0R/blocky/lists/formats"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/0xERR0R/blocky/model"
-	"github.com/0xERR0R/blocky/redis"
 	"github.com/0xERR0R/blocky/util"
 
 	"github.com/miekg/dns"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/publicsuffix"
 )
 
 const defaultBlockingCleanUpInterval = 5 * time.Second
 
-func createBlockHandler(cfg config.Blocking) (blockHandler, error) {
-	cfgBlockType := cfg.BlockType
+func createBlockHandler(cfgBlockType string, blockTTL config.Duration) (blockHandler, error) {
+	cfgBlockType = strings.TrimSpace(cfgBlockType)
 
 	if strings.EqualFold(cfgBlockType, "NXDOMAIN") {
-		return nxDomainBlockHandler{}, nil
+		return rcodeBlockHandler{rcode: dns.RcodeNameError}, nil
 	}
 
-	blockTime := cfg.BlockTTL.SecondsU32()
+	if strings.EqualFold(cfgBlockType, "NODATA") {
+		return rcodeBlockHandler{rcode: dns.RcodeSuccess}, nil
+	}
+
+	if strings.EqualFold(cfgBlockType, "REFUSED") {
+		return rcodeBlockHandler{rcode: dns.RcodeRefused}, nil
+	}
+
+	blockTime := blockTTL.SecondsU32()
 
 	if strings.EqualFold(cfgBlockType, "ZEROIP") {
 		return zeroIPBlockHandler{
@@ -65,8 +77,45 @@ func createBlockHandler(cfg config.Blocking) (blockHandler, error) {
 	}
 
 	return nil,
-		fmt.Errorf("unknown blockType '%s', please use one of: ZeroIP, NxDomain or specify destination IP address(es)",
-			cfgBlockType)
+		fmt.Errorf("unknown blockType '%s', please use one of: ZeroIP, NxDomain, NoData, Refused "+
+			"or specify destination IP address(es)", cfgBlockType)
+}
+
+// createBlockHandlers returns the block handlers by denylist group name
+func createBlockHandlers(blockTypes map[string]string, blockTTL config.Duration) (map[string]blockHandler, error) {
+	res := make(map[string]blockHandler, len(blockTypes))
+
+	for group, blockType := range blockTypes {
+		handler, err := createBlockHandler(blockType, blockTTL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", group, err)
+		}
+
+		res[group] = handler
+	}
+
+	return res, nil
+}
+
+// createClientBlockHandlers returns the block handlers by client group identifier,
+// the keys are split and lowercased like the ones of clientGroupsBlock
+func createClientBlockHandlers(
+	blockTypes map[string]string, blockTTL config.Duration,
+) (map[string]blockHandler, error) {
+	res := make(map[string]blockHandler, len(blockTypes))
+
+	for key, blockType := range blockTypes {
+		handler, err := createBlockHandler(blockType, blockTTL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		for _, k := range strings.Split(strings.ToLower(key), ",") {
+			res[k] = handler
+		}
+	}
+
+	return res, nil
 }
 
 type status struct {
@@ -76,9 +125,17 @@ type status struct {
 	disabledGroups []string
 	enableTimer    *time.Timer
 	disableEnd     time.Time
+	// temporarily allowed domains and the end of their allowance
+	allowedDomains map[clientDomain]time.Time
 	lock           sync.RWMutex
 }
 
+// clientDomain is a temporarily allowed domain, the client IP is empty if it is allowed for all clients
+type clientDomain struct {
+	clientIP string
+	domain   string
+}
+
 // BlockingResolver checks request's question (domain name) against allow/denylists
 type BlockingResolver struct {
 	configurable[*config.Blocking]
@@ -87,12 +144,16 @@ type BlockingResolver struct {
 
 	denylistMatcher     *lists.ListCache
 	allowlistMatcher    *lists.ListCache
+	feeds               map[string]*blockingFeed
 	blockHandler        blockHandler
+	groupBlockHandlers  map[string]blockHandler
+	clientBlockHandlers map[string]blockHandler
 	allowlistOnlyGroups map[string]bool
 	status              *status
 	clientGroupsBlock   map[string][]string
-	redisClient         *redis.Client
+	syncClient          cachesync.Client
 	fqdnIPCache         expirationcache.ExpiringCache[[]net.IP]
+	geoIP               *geoip.DB
 }
 
 func clientGroupsBlock(cfg config.Blocking) map[string][]string {
@@ -115,23 +176,51 @@ func clientGroupsBlock(cfg config.Blocking) map[string][]string {
 // NewBlockingResolver returns a new configured instance of the resolver
 func NewBlockingResolver(ctx context.Context,
 	cfg config.Blocking,
-	redis *redis.Client,
+	syncClient cachesync.Client,
 	bootstrap *Bootstrap,
+	geoIP *geoip.DB,
 ) (r *BlockingResolver, err error) {
-	blockHandler, err := createBlockHandler(cfg)
+	blockHandler, err := createBlockHandler(cfg.BlockType, cfg.BlockTTL)
 	if err != nil {
 		return nil, err
 	}
 
+	groupBlockHandlers, err := createBlockHandlers(cfg.BlockTypes, cfg.BlockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("blockTypes: %w", err)
+	}
+
+	clientBlockHandlers, err := createClientBlockHandlers(cfg.ClientBlockTypes, cfg.BlockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("clientBlockTypes: %w", err)
+	}
+
+	// blocked answers of a feed have its short TTL
+	for name, feed := range cfg.Feeds {
+		blockType, ok := cfg.BlockTypes[name]
+		if !ok {
+			blockType = cfg.BlockType
+		}
+
+		groupBlockHandlers[name], err = createBlockHandler(blockType, feed.BlockTTL)
+		if err != nil {
+			return nil, fmt.Errorf("feeds: %s: %w", name, err)
+		}
+	}
+
 	downloader := lists.NewDownloader(cfg.Loading.Downloads, bootstrap.NewHTTPTransport())
 
+	// a cluster shares the loaded lists, redis and NATS don't
+	sharing, _ := syncClient.(lists.GroupSharing)
+
 	denylistMatcher, blErr := lists.NewListCache(ctx, lists.ListCacheTypeDenylist,
-		cfg.Loading, cfg.Denylists, downloader)
+		cfg.Loading, cfg.Denylists, downloader, sharing)
 	allowlistMatcher, wlErr := lists.NewListCache(ctx, lists.ListCacheTypeAllowlist,
-		cfg.Loading, cfg.Allowlists, downloader)
+		cfg.Loading, cfg.Allowlists, downloader, sharing)
+	feeds, feedsErr := newBlockingFeeds(ctx, &cfg, downloader)
 	allowlistOnlyGroups := determineAllowlistOnlyGroups(&cfg)
 
-	err = multierror.Append(err, blErr, wlErr).ErrorOrNil()
+	err = multierror.Append(err, blErr, wlErr, feedsErr).ErrorOrNil()
 	if err != nil {
 		return nil, err
 	}
@@ -141,15 +230,19 @@ func NewBlockingResolver(ctx context.Context,
 		typed:        withType("blocking"),
 
 		blockHandler:        blockHandler,
+		groupBlockHandlers:  groupBlockHandlers,
+		clientBlockHandlers: clientBlockHandlers,
 		denylistMatcher:     denylistMatcher,
 		allowlistMatcher:    allowlistMatcher,
+		feeds:               feeds,
 		allowlistOnlyGroups: allowlistOnlyGroups,
 		status: &status{
 			enabled:     true,
 			enableTimer: time.NewTimer(0),
 		},
 		clientGroupsBlock: clientGroupsBlock(cfg),
-		redisClient:       redis,
+		syncClient:        syncClient,
+		geoIP:             geoIP,
 	}
 
 	res.fqdnIPCache = expirationcache.NewCacheWithOnExpired[[]net.IP](ctx, expirationcache.Options{
@@ -158,8 +251,8 @@ func NewBlockingResolver(ctx context.Context,
 		return res.queryForFQIdentifierIPs(ctx, key)
 	})
 
-	if res.redisClient != nil {
-		go res.redisSubscriber(ctx)
+	if res.syncClient != nil {
+		go res.syncSubscriber(ctx)
 	}
 
 	err = evt.Bus().SubscribeOnce(evt.ApplicationStarted, func(_ ...string) {
@@ -172,14 +265,14 @@ func NewBlockingResolver(ctx context.Context,
 	return res, nil
 }
 
-func (r *BlockingResolver) redisSubscriber(ctx context.Context) {
+func (r *BlockingResolver) syncSubscriber(ctx context.Context) {
 	ctx, logger := r.log(ctx)
 
 	for {
 		select {
-		case em := <-r.redisClient.EnabledChannel:
+		case em := <-r.syncClient.EnabledMessages():
 			if em != nil {
-				logger.Debug("Received state from redis: ", em)
+				logger.Debug("Received state from other instance: ", em)
 
 				if em.State {
 					r.internalEnableBlocking()
@@ -197,16 +290,99 @@ func (r *BlockingResolver) redisSubscriber(ctx context.Context) {
 	}
 }
 
-// RefreshLists triggers the refresh of all allow/denylists in the cache
+// RefreshLists triggers the refresh of all allow/denylists and feeds in the cache
 func (r *BlockingResolver) RefreshLists() error {
 	var err *multierror.Error
 
 	err = multierror.Append(err, r.denylistMatcher.Refresh())
 	err = multierror.Append(err, r.allowlistMatcher.Refresh())
 
+	for _, feed := range r.feeds {
+		err = multierror.Append(err, feed.cache.Refresh())
+	}
+
 	return err.ErrorOrNil()
 }
 
+// LocalListRules returns the rules of the inline and local file allow/denylists of the groups, or of all groups
+// if empty. Lists downloaded over HTTP are skipped.
+func (r *BlockingResolver) LocalListRules(ctx context.Context, groups []string) ([]formats.Rule, error) {
+	for _, g := range groups {
+		_, isDenylist := r.cfg.Denylists[g]
+		_, isAllowlist := r.cfg.Allowlists[g]
+
+		if !isDenylist && !isAllowlist {
+			return nil, fmt.Errorf("group '%s' is unknown", g)
+		}
+	}
+
+	var (
+		rules []formats.Rule
+		seen  = make(map[formats.Rule]struct{})
+	)
+
+	for _, allow := range []bool{false, true} {
+		groupSources := r.cfg.Denylists
+		if allow {
+			groupSources = r.cfg.Allowlists
+		}
+
+		groupNames := maps.Keys(groupSources)
+		slices.Sort(groupNames)
+
+		for _, group := range groupNames {
+			if len(groups) != 0 && !slices.Contains(groups, group) {
+				continue
+			}
+
+			for _, source := range groupSources[group] {
+				if source.Type == config.BytesSourceTypeHttp {
+					continue
+				}
+
+				sourceRules, err := readLocalListRules(ctx, group, source, allow)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, rule := range sourceRules {
+					if _, ok := seen[rule]; !ok {
+						seen[rule] = struct{}{}
+						rules = append(rules, rule)
+					}
+				}
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+func readLocalListRules(ctx context.Context, group string, source config.BytesSource, allow bool,
+) ([]formats.Rule, error) {
+	opener, err := lists.NewSourceOpener(group, source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := opener.Open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't read %s: %w", opener, err)
+	}
+	defer reader.Close()
+
+	rules, skipped, err := formats.Read(reader, formats.FormatBlocky, allow)
+	if err != nil {
+		return nil, fmt.Errorf("can't read %s: %w", opener, err)
+	}
+
+	for _, s := range skipped {
+		log.Log().Debugf("skipping rule of %s: %s", opener, log.EscapeInput(s.Error()))
+	}
+
+	return rules, nil
+}
+
 func (r *BlockingResolver) retrieveAllBlockingGroups() []string {
 	result := maps.Keys(r.cfg.Denylists)
 
@@ -220,8 +396,8 @@ func (r *BlockingResolver) retrieveAllBlockingGroups() []string {
 func (r *BlockingResolver) EnableBlocking(ctx context.Context) {
 	r.internalEnableBlocking()
 
-	if r.redisClient != nil {
-		r.redisClient.PublishEnabled(ctx, &redis.EnabledMessage{State: true})
+	if r.syncClient != nil {
+		r.syncClient.PublishEnabled(ctx, &cachesync.EnabledMessage{State: true})
 	}
 }
 
@@ -239,8 +415,8 @@ func (r *BlockingResolver) internalEnableBlocking() {
 // DisableBlocking deactivates the blocking for a particular duration (or forever if 0).
 func (r *BlockingResolver) DisableBlocking(ctx context.Context, duration time.Duration, disableGroups []string) error {
 	err := r.internalDisableBlocking(ctx, duration, disableGroups)
-	if err == nil && r.redisClient != nil {
-		r.redisClient.PublishEnabled(ctx, &redis.EnabledMessage{
+	if err == nil && r.syncClient != nil {
+		r.syncClient.PublishEnabled(ctx, &cachesync.EnabledMessage{
 			State:    false,
 			Duration: duration,
 			Groups:   disableGroups,
@@ -293,6 +469,110 @@ func (r *BlockingResolver) internalDisableBlocking(ctx context.Context, duration
 	return nil
 }
 
+// AllowDomain allows the domain and its subdomains for all clients for the duration
+func (r *BlockingResolver) AllowDomain(_ context.Context, domain string, duration time.Duration) error {
+	domain, err := validateAllowedDomain(domain, duration)
+	if err != nil {
+		return err
+	}
+
+	r.status.allow(clientDomain{domain: domain}, duration)
+
+	log.Log().Infof("allow domain '%s' for %s", log.EscapeInput(domain), duration)
+
+	return nil
+}
+
+// AllowDomainForClient allows exactly the domain, without its subdomains, for a single client for the duration
+func (r *BlockingResolver) AllowDomainForClient(
+	_ context.Context, clientIP net.IP, domain string, duration time.Duration,
+) error {
+	if clientIP == nil {
+		return errors.New("client IP is unknown")
+	}
+
+	domain, err := validateAllowedDomain(domain, duration)
+	if err != nil {
+		return err
+	}
+
+	r.status.allow(clientDomain{clientIP: clientIP.String(), domain: domain}, duration)
+
+	log.Log().Infof("allow domain '%s' for client %s for %s", log.EscapeInput(domain), clientIP, duration)
+
+	return nil
+}
+
+// validateAllowedDomain returns the normalized domain, public suffixes like `com` or `co.uk` can't be allowed
+func validateAllowedDomain(domain string, duration time.Duration) (string, error) {
+	domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	if domain == "" {
+		return "", errors.New("domain is empty")
+	}
+
+	if suffix, _ := publicsuffix.PublicSuffix(domain); suffix == domain {
+		return "", fmt.Errorf("'%s' is a public suffix", log.EscapeInput(domain))
+	}
+
+	if duration <= 0 {
+		return "", fmt.Errorf("duration must be positive: %s", duration)
+	}
+
+	return domain, nil
+}
+
+func (s *status) allow(key clientDomain, duration time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+
+	if s.allowedDomains == nil {
+		s.allowedDomains = make(map[clientDomain]time.Time)
+	}
+
+	maps.DeleteFunc(s.allowedDomains, func(_ clientDomain, end time.Time) bool {
+		return !end.After(now)
+	})
+
+	s.allowedDomains[key] = now.Add(duration)
+}
+
+// isTemporarilyAllowed returns true if the domain of the question or one of its parents is temporarily allowed,
+// or the domain itself is allowed for the client
+func (r *BlockingResolver) isTemporarilyAllowed(request *model.Request) bool {
+	r.status.lock.RLock()
+	defer r.status.lock.RUnlock()
+
+	if len(r.status.allowedDomains) == 0 || len(request.Req.Question) == 0 {
+		return false
+	}
+
+	now := time.Now()
+	domain := util.ExtractDomain(request.Req.Question[0])
+
+	if request.ClientIP != nil {
+		key := clientDomain{clientIP: request.ClientIP.String(), domain: domain}
+
+		if end, ok := r.status.allowedDomains[key]; ok && end.After(now) {
+			return true
+		}
+	}
+
+	for {
+		if end, ok := r.status.allowedDomains[clientDomain{domain: domain}]; ok && end.After(now) {
+			return true
+		}
+
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			return false
+		}
+
+		domain = parent
+	}
+}
+
 // BlockingStatus returns the current blocking status
 func (r *BlockingResolver) BlockingStatus() api.BlockingStatus {
 	var autoEnableDuration time.Duration
@@ -311,13 +591,36 @@ func (r *BlockingResolver) BlockingStatus() api.BlockingStatus {
 	}
 }
 
-// returns groups, which have only allowlist entries
+// BlockingSchedule returns the current schedule state of all groups with a schedule
+func (r *BlockingResolver) BlockingSchedule() []api.BlockingScheduleStatus {
+	now := time.Now()
+
+	result := make([]api.BlockingScheduleStatus, 0, len(r.cfg.Schedules))
+
+	for group, schedule := range r.cfg.Schedules {
+		result = append(result, api.BlockingScheduleStatus{
+			Group:      group,
+			Active:     schedule.IsActive(now),
+			NextChange: schedule.NextChange(now),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Group < result[j].Group
+	})
+
+	return result
+}
+
+// returns groups, which have only allowlist entries. Allowlists of categories are not groups.
 func determineAllowlistOnlyGroups(cfg *config.Blocking) (result map[string]bool) {
 	result = make(map[string]bool, len(cfg.Allowlists))
 
+	categories := maps.Values(cfg.Categories)
+
 	for g, links := range cfg.Allowlists {
-		if len(links) > 0 {
-			if _, found := cfg.Denylists[g]; !found {
+		if len(links) > 0 && !slices.Contains(categories, g) {
+			if !cfg.IsDenylistGroup(g) {
 				result[g] = true
 			}
 		}
@@ -327,17 +630,82 @@ func determineAllowlistOnlyGroups(cfg *config.Blocking) (result map[string]bool)
 }
 
 // sets answer and/or return code for DNS response, if request should be blocked
-func (r *BlockingResolver) handleBlocked(logger *logrus.Entry,
-	request *model.Request, question dns.Question, reason string,
+func (r *BlockingResolver) handleBlocked(ctx context.Context, logger *logrus.Entry,
+	request *model.Request, question dns.Question, groups []string, reason string,
 ) (*model.Response, error) {
 	response := new(dns.Msg)
 	response.SetReply(request.Req)
 
-	r.blockHandler.handleBlock(question, response)
+	r.blockHandlerFor(request, groups).handleBlock(question, response)
 
 	logger.Debugf("blocking request '%s'", reason)
 
-	return &model.Response{Res: response, RType: model.ResponseTypeBLOCKED, Reason: reason}, nil
+	categories := r.categoriesOf(groups)
+
+	if isDryRun(ctx) {
+		r.trace(ctx, "blocked: %s", reason)
+		traced(ctx, func(trace *model.Trace) { trace.Lists = groups })
+	} else {
+		evt.Bus().Publish(evt.BlockingQueryBlocked, request.ClientIP, request.ClientNames, util.ExtractDomain(question), reason)
+
+		for _, category := range categories {
+			evt.Bus().Publish(evt.BlockingCategoryHit, category)
+		}
+	}
+
+	return &model.Response{Res: response, RType: model.ResponseTypeBLOCKED, Reason: reason, Categories: categories}, nil
+}
+
+// categoriesOf returns the distinct categories of the denylist groups
+func (r *BlockingResolver) categoriesOf(groups []string) []string {
+	var categories []string
+
+	for _, group := range groups {
+		if category, ok := r.cfg.Categories[group]; ok && !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+
+	return categories
+}
+
+// allowlistGroups returns the allowlist groups to check for the denylist groups: the groups themselves
+// and their categories, so an allowlist named after a category applies to all groups of the category
+func (r *BlockingResolver) allowlistGroups(groupsToCheck []string) []string {
+	categories := r.categoriesOf(groupsToCheck)
+	if len(categories) == 0 {
+		return groupsToCheck
+	}
+
+	result := slices.Clone(groupsToCheck)
+
+	for _, category := range categories {
+		if !slices.Contains(result, category) {
+			result = append(result, category)
+		}
+	}
+
+	return result
+}
+
+// blockHandlerFor returns the block handler of the first denylist group with an own block type,
+// otherwise the one of the client's group or the default
+func (r *BlockingResolver) blockHandlerFor(request *model.Request, groups []string) blockHandler {
+	for _, group := range groups {
+		if handler, ok := r.groupBlockHandlers[group]; ok {
+			return handler
+		}
+	}
+
+	if len(r.clientBlockHandlers) != 0 {
+		for _, identifier := range r.clientIdentifiers(request) {
+			if handler, ok := r.clientBlockHandlers[identifier]; ok {
+				return handler
+			}
+		}
+	}
+
+	return r.blockHandler
 }
 
 // LogConfig implements `config.Configurable`.
@@ -349,6 +717,14 @@ func (r *BlockingResolver) LogConfig(logger *logrus.Entry) {
 
 	logger.Info("allowlist cache entries:")
 	log.WithIndent(logger, "  ", r.allowlistMatcher.LogConfig)
+
+	if len(r.feeds) != 0 {
+		logger.Info("feed cache entries:")
+
+		for _, feed := range r.feeds {
+			log.WithIndent(logger, "  ", feed.cache.LogConfig)
+		}
+	}
 }
 
 func (r *BlockingResolver) hasAllowlistOnlyAllowed(groupsToCheck []string) bool {
@@ -371,22 +747,28 @@ func (r *BlockingResolver) handleDenylist(ctx context.Context, groupsToCheck []s
 		domain := util.ExtractDomain(question)
 		logger := logger.WithField("domain", domain)
 
-		if groups := r.matches(groupsToCheck, r.allowlistMatcher, domain); len(groups) > 0 {
+		if groups := r.matches(r.allowlistGroups(groupsToCheck), r.allowlistMatcher, domain); len(groups) > 0 {
 			logger.WithField("groups", groups).Debugf("domain is allowlisted")
 
+			r.trace(ctx, "allowlisted by %s", strings.Join(groups, ","))
+			traced(ctx, func(trace *model.Trace) { trace.Lists = groups })
+
 			resp, err := r.next.Resolve(ctx, request)
 
 			return true, resp, err
 		}
 
 		if allowlistOnlyAllowed {
-			resp, err := r.handleBlocked(logger, request, question, "BLOCKED (ALLOWLIST ONLY)")
+			resp, err := r.handleBlocked(ctx, logger, request, question, nil, "BLOCKED (ALLOWLIST ONLY)")
 
 			return true, resp, err
 		}
 
-		if groups := r.matches(groupsToCheck, r.denylistMatcher, domain); len(groups) > 0 {
-			resp, err := r.handleBlocked(logger, request, question, fmt.Sprintf("BLOCKED (%s)", strings.Join(groups, ",")))
+		if groups := r.denylistMatches(groupsToCheck, domain); len(groups) > 0 {
+			publishGroupHits(ctx, groups)
+
+			resp, err := r.handleBlocked(ctx, logger, request, question, groups,
+				fmt.Sprintf("BLOCKED (%s)", strings.Join(groups, ",")))
 
 			return true, resp, err
 		}
@@ -400,6 +782,19 @@ func (r *BlockingResolver) Resolve(ctx context.Context, request *model.Request)
 	ctx, logger := r.log(ctx)
 	groupsToCheck := r.groupsToCheckForClient(request)
 
+	if len(groupsToCheck) == 0 {
+		r.trace(ctx, "no groups to check for the client")
+	} else {
+		r.trace(ctx, "checking groups %s", strings.Join(groupsToCheck, ","))
+	}
+
+	if len(groupsToCheck) > 0 && r.isTemporarilyAllowed(request) {
+		logger.Debug("domain is temporarily allowed")
+		r.trace(ctx, "domain is temporarily allowed")
+
+		return r.next.Resolve(ctx, request)
+	}
+
 	if len(groupsToCheck) > 0 {
 		handled, resp, err := r.handleDenylist(ctx, groupsToCheck, request, logger)
 		if handled {
@@ -411,37 +806,164 @@ func (r *BlockingResolver) Resolve(ctx context.Context, request *model.Request)
 
 	if err == nil && len(groupsToCheck) > 0 && respFromNext.Res != nil {
 		for _, rr := range respFromNext.Res.Answer {
-			entryToCheck, tName := extractEntryToCheckFromResponse(rr)
-			if len(entryToCheck) > 0 {
-				logger := logger.WithField("response_entry", entryToCheck)
-
-				if groups := r.matches(groupsToCheck, r.allowlistMatcher, entryToCheck); len(groups) > 0 {
-					logger.WithField("groups", groups).Debugf("%s is allowlisted", tName)
-				} else if groups := r.matches(groupsToCheck, r.denylistMatcher, entryToCheck); len(groups) > 0 {
-					return r.handleBlocked(logger, request, request.Req.Question[0], fmt.Sprintf("BLOCKED %s (%s)", tName,
-						strings.Join(groups, ",")))
-				}
+			entriesToCheck, tName := extractEntriesToCheckFromResponse(rr)
+			if groups, reason := r.matchResponseEntries(ctx, logger, groupsToCheck, entriesToCheck, tName); reason != "" {
+				return r.handleBlocked(ctx, logger, request, request.Req.Question[0], groups, reason)
+			}
+
+			asn, country := r.answerGeoEntries(rr)
+
+			if groups, reason := r.matchResponseEntries(ctx, logger, groupsToCheck, asn, "ASN"); reason != "" {
+				return r.handleBlocked(ctx, logger, request, request.Req.Question[0], groups, reason)
+			}
+
+			if groups, reason := r.matchResponseEntries(ctx, logger, groupsToCheck, country, "COUNTRY"); reason != "" {
+				return r.handleBlocked(ctx, logger, request, request.Req.Question[0], groups, reason)
 			}
 		}
+
+		r.stripECH(logger, respFromNext.Res)
 	}
 
 	return respFromNext, err
 }
 
-func extractEntryToCheckFromResponse(rr dns.RR) (entryToCheck, tName string) {
+// matchResponseEntries returns the matching groups and the block reason
+// if an entry of the response is denylisted and not allowlisted
+func (r *BlockingResolver) matchResponseEntries(ctx context.Context,
+	logger *logrus.Entry, groupsToCheck, entriesToCheck []string, tName string,
+) (groups []string, reason string) {
+	for _, entryToCheck := range entriesToCheck {
+		logger := logger.WithField("response_entry", entryToCheck)
+
+		if groups := r.matches(r.allowlistGroups(groupsToCheck), r.allowlistMatcher, entryToCheck); len(groups) > 0 {
+			logger.WithField("groups", groups).Debugf("%s is allowlisted", tName)
+			r.trace(ctx, "%s %s of the response is allowlisted by %s", tName, entryToCheck, strings.Join(groups, ","))
+		} else if groups := r.denylistMatches(groupsToCheck, entryToCheck); len(groups) > 0 {
+			publishGroupHits(ctx, groups)
+
+			return groups, fmt.Sprintf("BLOCKED %s (%s)", tName, strings.Join(groups, ","))
+		}
+	}
+
+	return nil, ""
+}
+
+// answerGeoEntries returns the autonomous system and the country of the address of an A or AAAA record
+// as list entries, e.g. "AS15169" and "country:US"
+func (r *BlockingResolver) answerGeoEntries(rr dns.RR) (asn, country []string) {
+	ip := util.AnswerIP(rr)
+	if r.geoIP == nil || ip == nil {
+		return nil, nil
+	}
+
+	if number, found := r.geoIP.ASN(ip); found {
+		asn = []string{fmt.Sprintf("AS%d", number)}
+	}
+
+	if code, found := r.geoIP.Country(ip); found {
+		country = []string{"country:" + code}
+	}
+
+	return asn, country
+}
+
+// stripECH removes the Encrypted ClientHello configuration from HTTPS/SVCB answers for configured domains,
+// so clients send the real server name in plain text and it stays visible to network filters
+func (r *BlockingResolver) stripECH(logger *logrus.Entry, response *dns.Msg) {
+	if len(r.cfg.StripECH) == 0 {
+		return
+	}
+
+	for _, rr := range response.Answer {
+		var svcb *dns.SVCB
+
+		switch v := rr.(type) {
+		case *dns.HTTPS:
+			svcb = &v.SVCB
+		case *dns.SVCB:
+			svcb = v
+		default:
+			continue
+		}
+
+		domain := util.ExtractDomainOnly(rr.Header().Name)
+		if !r.isECHStripped(domain) {
+			continue
+		}
+
+		count := len(svcb.Value)
+
+		svcb.Value = slices.DeleteFunc(svcb.Value, func(kv dns.SVCBKeyValue) bool {
+			return kv.Key() == dns.SVCB_ECHCONFIG
+		})
+
+		if len(svcb.Value) != count {
+			logger.WithField("domain", domain).Debug("removed ech parameter from response")
+		}
+	}
+}
+
+func (r *BlockingResolver) isECHStripped(domain string) bool {
+	for _, stripped := range r.cfg.StripECH {
+		stripped = strings.ToLower(strings.TrimSuffix(stripped, "."))
+
+		if domain == stripped || strings.HasSuffix(domain, "."+stripped) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func publishGroupHits(ctx context.Context, groups []string) {
+	if isDryRun(ctx) {
+		return
+	}
+
+	for _, group := range groups {
+		evt.Bus().Publish(evt.BlockingGroupHit, group)
+	}
+}
+
+func extractEntriesToCheckFromResponse(rr dns.RR) (entriesToCheck []string, tName string) {
 	switch v := rr.(type) {
 	case *dns.A:
-		entryToCheck = v.A.String()
-		tName = "IP"
+		return []string{v.A.String()}, "IP"
 	case *dns.AAAA:
-		entryToCheck = strings.ToLower(v.AAAA.String())
-		tName = "IP"
+		return []string{strings.ToLower(v.AAAA.String())}, "IP"
 	case *dns.CNAME:
-		entryToCheck = util.ExtractDomainOnly(v.Target)
-		tName = "CNAME"
+		return []string{util.ExtractDomainOnly(v.Target)}, "CNAME"
+	case *dns.HTTPS:
+		return extractEntriesToCheckFromSVCB(&v.SVCB), "HTTPS"
+	case *dns.SVCB:
+		return extractEntriesToCheckFromSVCB(v), "SVCB"
 	}
 
-	return
+	return nil, ""
+}
+
+// extractEntriesToCheckFromSVCB returns the target name and the IP hints of a HTTPS/SVCB record:
+// clients may use them to connect without any further A/AAAA query
+func extractEntriesToCheckFromSVCB(rr *dns.SVCB) (entriesToCheck []string) {
+	if rr.Target != "." {
+		entriesToCheck = append(entriesToCheck, util.ExtractDomainOnly(rr.Target))
+	}
+
+	for _, kv := range rr.Value {
+		switch hint := kv.(type) {
+		case *dns.SVCBIPv4Hint:
+			for _, ip := range hint.Hint {
+				entriesToCheck = append(entriesToCheck, ip.String())
+			}
+		case *dns.SVCBIPv6Hint:
+			for _, ip := range hint.Hint {
+				entriesToCheck = append(entriesToCheck, strings.ToLower(ip.String()))
+			}
+		}
+	}
+
+	return entriesToCheck
 }
 
 func (r *BlockingResolver) isGroupDisabled(group string) bool {
@@ -457,44 +979,74 @@ func (r *BlockingResolver) isGroupDisabled(group string) bool {
 	return false
 }
 
-// returns groups which should be checked for client's request
-func (r *BlockingResolver) groupsToCheckForClient(request *model.Request) []string {
-	r.status.lock.RLock()
-	defer r.status.lock.RUnlock()
+// returns true if the group has no schedule or if its schedule is active at t
+func (r *BlockingResolver) isGroupScheduled(group string, t time.Time) bool {
+	schedule, found := r.cfg.Schedules[group]
+	if !found {
+		return true
+	}
+
+	return schedule.IsActive(t)
+}
+
+// clientIdentifiers returns the sorted identifiers of clientGroupsBlock matching the client, "default" if none matches
+func (r *BlockingResolver) clientIdentifiers(request *model.Request) []string {
+	var identifiers []string
 
-	var groups []string
 	// try client names
 	for _, cName := range request.ClientNames {
-		for blockGroup, groupsByName := range r.clientGroupsBlock {
+		for blockGroup := range r.clientGroupsBlock {
 			if util.ClientNameMatchesGroupName(blockGroup, cName) {
-				groups = append(groups, groupsByName...)
+				identifiers = append(identifiers, blockGroup)
 			}
 		}
 	}
 
 	// try IP
-	groupsByIP, found := r.clientGroupsBlock[request.ClientIP.String()]
-
-	if found {
-		groups = append(groups, groupsByIP...)
+	if _, found := r.clientGroupsBlock[request.ClientIP.String()]; found {
+		identifiers = append(identifiers, request.ClientIP.String())
 	}
 
-	for clientIdentifier, groupsByCidr := range r.clientGroupsBlock {
+	for clientIdentifier := range r.clientGroupsBlock {
+		switch {
 		// try CIDR
-		if util.CidrContainsIP(clientIdentifier, request.ClientIP) {
-			groups = append(groups, groupsByCidr...)
-		} else if isFQDN(clientIdentifier) && r.fqdnIPCache != nil {
+		case util.CidrContainsIP(clientIdentifier, request.ClientIP):
+			identifiers = append(identifiers, clientIdentifier)
+		// try MAC address or OUI
+		case util.ClientMACMatchesGroupName(clientIdentifier, request.ClientMAC):
+			identifiers = append(identifiers, clientIdentifier)
+		case isFQDN(clientIdentifier) && r.fqdnIPCache != nil:
 			ips, _ := r.fqdnIPCache.Get(clientIdentifier)
 			if ips != nil {
 				for _, ip := range *ips {
 					if ip.Equal(request.ClientIP) {
-						groups = append(groups, groupsByCidr...)
+						identifiers = append(identifiers, clientIdentifier)
 					}
 				}
 			}
 		}
 	}
 
+	if len(identifiers) == 0 {
+		return []string{"default"}
+	}
+
+	sort.Strings(identifiers)
+
+	return identifiers
+}
+
+// returns groups which should be checked for client's request
+func (r *BlockingResolver) groupsToCheckForClient(request *model.Request) []string {
+	r.status.lock.RLock()
+	defer r.status.lock.RUnlock()
+
+	var groups []string
+
+	for _, identifier := range r.clientIdentifiers(request) {
+		groups = append(groups, r.clientGroupsBlock[identifier]...)
+	}
+
 	if len(groups) == 0 {
 		// return default
 		groups = r.clientGroupsBlock["default"]
@@ -502,8 +1054,10 @@ func (r *BlockingResolver) groupsToCheckForClient(request *model.Request) []stri
 
 	var result []string
 
+	now := time.Now()
+
 	for _, g := range groups {
-		if !r.isGroupDisabled(g) {
+		if !r.isGroupDisabled(g) && r.isGroupScheduled(g, now) {
 			result = append(result, g)
 		}
 	}
@@ -531,7 +1085,10 @@ type zeroIPBlockHandler struct {
 	BlockTimeSec uint32
 }
 
-type nxDomainBlockHandler struct{}
+// rcodeBlockHandler answers with the return code and without records
+type rcodeBlockHandler struct {
+	rcode int
+}
 
 type ipBlockHandler struct {
 	destinations    []net.IP
@@ -558,8 +1115,8 @@ func (b zeroIPBlockHandler) handleBlock(question dns.Question, response *dns.Msg
 	response.Answer = append(response.Answer, rr)
 }
 
-func (b nxDomainBlockHandler) handleBlock(_ dns.Question, response *dns.Msg) {
-	response.Rcode = dns.RcodeNameError
+func (b rcodeBlockHandler) handleBlock(_ dns.Question, response *dns.Msg) {
+	response.Rcode = b.rcode
 }
 
 func (b ipBlockHandler) handleBlock(question dns.Question, response *dns.Msg) {
@@ -2,18 +2,25 @@ package resolver
 
 import (
 	"context"
+	"net"
+	"sync/atomic"
 	"time"
 
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/cachesync"
 	"github.com/0xERR0R/blocky/config"
 	. "github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/geoip"
 	. "github.com/0xERR0R/blocky/helpertest"
 	"github.com/0xERR0R/blocky/lists"
+	"github.com/0xERR0R/blocky/lists/formats"
 	"github.com/0xERR0R/blocky/log"
 	. "github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/redis"
 	"github.com/0xERR0R/blocky/util"
 	"github.com/alicebob/miniredis/v2"
 	"github.com/creasty/defaults"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
 
 	"github.com/miekg/dns"
 	. "github.com/onsi/ginkgo/v2"
@@ -34,6 +41,9 @@ var _ = BeforeSuite(func() {
 		"blocked3.com",
 		"123.145.123.145",
 		"2001:db8:85a3:08d3::370:7344",
+		"198.51.100.0/24",
+		"AS64500",
+		"country:KP",
 		"badcnamedomain.com")
 })
 
@@ -41,6 +51,7 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 	var (
 		sut        *BlockingResolver
 		sutConfig  config.Blocking
+		sutGeoIP   *geoip.DB
 		m          *mockResolver
 		mockAnswer *dns.Msg
 		ctx        context.Context
@@ -62,6 +73,7 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 			BlockTTL:  config.Duration(time.Minute),
 		}
 
+		sutGeoIP = nil
 		mockAnswer = new(dns.Msg)
 	})
 
@@ -71,7 +83,7 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 		m = &mockResolver{}
 		m.On("Resolve", mock.Anything).Return(&Response{Res: mockAnswer}, nil)
 
-		sut, err = NewBlockingResolver(ctx, sutConfig, nil, systemResolverBootstrap)
+		sut, err = NewBlockingResolver(ctx, sutConfig, nil, systemResolverBootstrap, sutGeoIP)
 		Expect(err).Should(Succeed())
 		sut.Next(m)
 	})
@@ -112,12 +124,209 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 				Expect(err).Should(Succeed())
 
 				// recreate to trigger a reload
-				sut, err = NewBlockingResolver(ctx, sutConfig, nil, systemResolverBootstrap)
+				sut, err = NewBlockingResolver(ctx, sutConfig, nil, systemResolverBootstrap, sutGeoIP)
 				Expect(err).Should(Succeed())
 
 				Eventually(groupCnt, "1s").Should(HaveLen(2))
 			})
 		})
+		When("Query is blocked", func() {
+			BeforeEach(func() {
+				sutConfig.ClientGroupsBlock = map[string][]string{
+					"default": {"gr1"},
+				}
+			})
+			It("group hit event should be fired", func() {
+				var hitGroup string
+				Expect(Bus().SubscribeOnce(BlockingGroupHit, func(group string) {
+					hitGroup = group
+				})).Should(Succeed())
+
+				Expect(sut.Resolve(ctx, newRequestWithClient("domain1.com.", A, "1.2.1.2", "unknown"))).
+					Should(HaveResponseType(ResponseTypeBLOCKED))
+
+				Eventually(func() string { return hitGroup }, "1s").Should(Equal("gr1"))
+			})
+			It("query blocked event should be fired", func() {
+				var blocked []string
+				Expect(Bus().SubscribeOnce(BlockingQueryBlocked,
+					func(clientIP net.IP, clientNames []string, domain, reason string) {
+						blocked = []string{clientIP.String(), clientNames[0], domain, reason}
+					})).Should(Succeed())
+
+				Expect(sut.Resolve(ctx, newRequestWithClient("domain1.com.", A, "1.2.1.2", "unknown"))).
+					Should(HaveResponseType(ResponseTypeBLOCKED))
+
+				Eventually(func() []string { return blocked }, "1s").
+					Should(Equal([]string{"1.2.1.2", "unknown", "domain1.com", "BLOCKED (gr1)"}))
+			})
+			It("should trace the query instead of firing events in a dry run", func() {
+				fired := make(chan string, 2)
+				onHit := func(group string) { fired <- group }
+				onBlocked := func(_ net.IP, _ []string, domain, _ string) { fired <- domain }
+
+				Expect(Bus().Subscribe(BlockingGroupHit, onHit)).Should(Succeed())
+				DeferCleanup(Bus().Unsubscribe, BlockingGroupHit, onHit)
+				Expect(Bus().Subscribe(BlockingQueryBlocked, onBlocked)).Should(Succeed())
+				DeferCleanup(Bus().Unsubscribe, BlockingQueryBlocked, onBlocked)
+
+				traceCtx, trace := WithTrace(ctx)
+
+				Expect(sut.Resolve(traceCtx, newRequestWithClient("domain1.com.", A, "1.2.1.2", "unknown"))).
+					Should(HaveResponseType(ResponseTypeBLOCKED))
+
+				Expect(trace()).Should(Equal(Trace{
+					Steps: []TraceStep{
+						{Resolver: "blocking", Message: "checking groups gr1"},
+						{Resolver: "blocking", Message: "blocked: BLOCKED (gr1)"},
+					},
+					Lists: []string{"gr1"},
+				}))
+				Consistently(fired, "100ms").ShouldNot(Receive())
+			})
+		})
+	})
+
+	Describe("Categories", func() {
+		BeforeEach(func() {
+			sutConfig = config.Blocking{
+				BlockType: "ZEROIP",
+				BlockTTL:  config.Duration(time.Minute),
+				Denylists: map[string][]config.BytesSource{
+					"gr1": config.NewBytesSources(group1File.Path),
+					"gr2": config.NewBytesSources(group2File.Path),
+				},
+				Allowlists: map[string][]config.BytesSource{
+					"tracking": config.NewBytesSources(group2File.Path),
+				},
+				ClientGroupsBlock: map[string][]string{
+					"default": {"gr1", "gr2"},
+				},
+				Categories: map[string]string{
+					"gr1": "ads",
+					"gr2": "tracking",
+				},
+			}
+		})
+
+		It("should return the categories of the blocking groups and fire a hit event", func() {
+			var hitCategory atomic.Value
+			Expect(Bus().SubscribeOnce(BlockingCategoryHit, func(category string) {
+				hitCategory.Store(category)
+			})).Should(Succeed())
+
+			resp, err := sut.Resolve(ctx, newRequestWithClient("domain1.com.", A, "1.2.1.2", "unknown"))
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(HaveResponseType(ResponseTypeBLOCKED))
+			Expect(resp.Categories).Should(Equal([]string{"ads"}))
+
+			Eventually(hitCategory.Load, "1s").Should(Equal("ads"))
+		})
+
+		It("should apply the allowlist of a category to its groups", func() {
+			resp, err := sut.Resolve(ctx, newRequestWithClient("blocked2.com.", A, "1.2.1.2", "unknown"))
+			Expect(err).Should(Succeed())
+			Expect(resp).Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(resp.Categories).Should(BeEmpty())
+		})
+
+		It("should not treat the allowlist of a category as allowlist only group", func() {
+			Expect(sut.allowlistOnlyGroups).Should(BeEmpty())
+		})
+	})
+
+	Describe("Feeds", func() {
+		var feedFile *TmpFile
+
+		BeforeEach(func() {
+			feedFile = tmpDir.CreateStringFile("feedFile", "phishing.com")
+
+			sutConfig = config.Blocking{
+				BlockType: "ZEROIP",
+				BlockTTL:  config.Duration(time.Hour),
+				Denylists: map[string][]config.BytesSource{
+					"gr1": config.NewBytesSources(group1File.Path),
+				},
+				Feeds: map[string]config.BlockingFeed{
+					"phishing": {
+						Sources:       config.NewBytesSources(feedFile.Path),
+						RefreshPeriod: config.Duration(time.Hour),
+						BlockTTL:      config.Duration(time.Minute),
+						MaxAge:        config.Duration(time.Hour),
+					},
+				},
+				ClientGroupsBlock: map[string][]string{
+					"default": {"gr1", "phishing"},
+					"kids":    {"gr1"},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			// the feeds are loaded in the background
+			Eventually(func(g Gomega) {
+				g.Expect(sut.Resolve(ctx, newRequestWithClient("phishing.com.", A, "1.2.1.2", "unknown"))).
+					Should(HaveResponseType(ResponseTypeBLOCKED))
+			}, "1s").Should(Succeed())
+		})
+
+		It("should block the domains of the feed with its TTL", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("phishing.com.", A, "1.2.1.2", "unknown"))).
+				Should(And(
+					BeDNSRecord("phishing.com.", A, "0.0.0.0"),
+					HaveTTL(BeNumerically("==", 60)),
+					HaveReason("BLOCKED (phishing)"),
+				))
+
+			Expect(sut.Resolve(ctx, newRequestWithClient("domain1.com.", A, "1.2.1.2", "unknown"))).
+				Should(HaveTTL(BeNumerically("==", 3600)))
+		})
+
+		It("should only block the domains of the feed for clients with the feed", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("phishing.com.", A, "1.2.1.2", "kids"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+		})
+
+		It("should reload the feed without reloading the denylists", func() {
+			var reloaded []string
+
+			handler := func(listType lists.ListCacheType, group string, _ int) {
+				reloaded = append(reloaded, listType.String()+":"+group)
+			}
+			Expect(Bus().Subscribe(BlockingCacheGroupChanged, handler)).Should(Succeed())
+			DeferCleanup(Bus().Unsubscribe, BlockingCacheGroupChanged, handler)
+
+			Expect(sut.feeds["phishing"].cache.Refresh()).Should(Succeed())
+			Expect(reloaded).Should(Equal([]string{"feed:phishing"}))
+		})
+
+		It("should not treat the allowlist of a feed as allowlist only group", func() {
+			sutConfig.Allowlists = map[string][]config.BytesSource{
+				"phishing": config.NewBytesSources(group1File.Path),
+			}
+
+			Expect(determineAllowlistOnlyGroups(&sutConfig)).Should(BeEmpty())
+		})
+
+		When("the feed is not refreshed within its max age", func() {
+			It("should report it as stale until it is refreshed", func() {
+				var stale atomic.Value
+
+				handler := func(_ string, isStale bool) { stale.Store(isStale) }
+				Expect(Bus().Subscribe(BlockingFeedStale, handler)).Should(Succeed())
+				DeferCleanup(Bus().Unsubscribe, BlockingFeedStale, handler)
+
+				feed := sut.feeds["phishing"]
+				feed.checkStale(time.Now())
+				Expect(stale.Load()).Should(BeNil())
+
+				feed.checkStale(time.Now().Add(2 * time.Hour))
+				Expect(stale.Load()).Should(BeTrue())
+
+				feed.refreshed()
+				Expect(stale.Load()).Should(BeFalse())
+			})
+		})
 	})
 
 	Describe("Blocking with full-qualified client name", func() {
@@ -202,14 +411,16 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 					"defaultGroup": config.NewBytesSources(defaultGroupFile.Path),
 				},
 				ClientGroupsBlock: map[string][]string{
-					"Client1":         {"gr1"},
-					"client2,client3": {"gr1"},
-					"client3":         {"gr2"},
-					"192.168.178.55":  {"gr1"},
-					"altName":         {"gr2"},
-					"10.43.8.67/28":   {"gr1"},
-					"wildcard[0-9]*":  {"gr1"},
-					"default":         {"defaultGroup"},
+					"Client1":           {"gr1"},
+					"client2,client3":   {"gr1"},
+					"client3":           {"gr2"},
+					"192.168.178.55":    {"gr1"},
+					"altName":           {"gr2"},
+					"10.43.8.67/28":     {"gr1"},
+					"wildcard[0-9]*":    {"gr1"},
+					"aa:bb:cc:00:00:01": {"gr2"},
+					"AA-BB-CD":          {"gr1"},
+					"default":           {"defaultGroup"},
 				},
 				BlockType: "ZeroIP",
 			}
@@ -294,6 +505,47 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 						))
 			})
 		})
+		When("Client MAC address or OUI is defined in client groups block", func() {
+			withMAC := func(request *Request, mac string) *Request {
+				request.ClientMAC, _ = net.ParseMAC(mac)
+
+				return request
+			}
+
+			It("should block the query if domain is on the denylist of the MAC address group", func() {
+				request := withMAC(newRequestWithClient("blocked2.com.", A, "192.168.178.2", "unknown"), "aa:bb:cc:00:00:01")
+
+				Expect(sut.Resolve(ctx, request)).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("blocked2.com.", A, "0.0.0.0"),
+							HaveResponseType(ResponseTypeBLOCKED),
+							HaveReason("BLOCKED (gr2)"),
+						))
+			})
+			It("should block the query if domain is on the denylist of the OUI group", func() {
+				request := withMAC(newRequestWithClient("domain1.com.", A, "192.168.178.2", "unknown"), "aa:bb:cd:12:34:56")
+
+				Expect(sut.Resolve(ctx, request)).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("domain1.com.", A, "0.0.0.0"),
+							HaveResponseType(ResponseTypeBLOCKED),
+							HaveReason("BLOCKED (gr1)"),
+						))
+			})
+			It("should use the default group for other MAC addresses", func() {
+				request := withMAC(newRequestWithClient("domain1.com.", A, "192.168.178.2", "unknown"), "aa:bb:cc:00:00:02")
+
+				Expect(sut.Resolve(ctx, request)).
+					Should(
+						SatisfyAll(
+							HaveNoAnswer(),
+							HaveResponseType(ResponseTypeRESOLVED),
+						))
+			})
+		})
+
 		When("Client CIDR (10.43.8.64 - 10.43.8.79) is defined in client groups block", func() {
 			It("should not block the query for 10.43.8.63 if domain is on the denylist", func() {
 				Expect(sut.Resolve(ctx, newRequestWithClient("domain1.com.", A, "10.43.8.63", "unknown"))).
@@ -535,6 +787,84 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 			})
 		})
 
+		When("BlockType is NoData or Refused", func() {
+			It("should answer without records and the return code", func() {
+				for blockType, rcode := range map[string]int{"NoData": dns.RcodeSuccess, "refused": dns.RcodeRefused} {
+					sutConfig.BlockType = blockType
+
+					sut, err := NewBlockingResolver(ctx, sutConfig, nil, systemResolverBootstrap, nil)
+					Expect(err).Should(Succeed())
+
+					Expect(sut.Resolve(ctx, newRequestWithClient("blocked3.com.", A, "1.2.1.2", "unknown"))).
+						Should(
+							SatisfyAll(
+								HaveNoAnswer(),
+								HaveResponseType(ResponseTypeBLOCKED),
+								HaveReturnCode(rcode),
+								HaveReason("BLOCKED (defaultGroup)"),
+							))
+				}
+			})
+		})
+
+		When("block types are set per group and client", func() {
+			BeforeEach(func() {
+				sutConfig.BlockTypes = map[string]string{
+					"gr2": "12.12.12.12",
+				}
+				sutConfig.ClientBlockTypes = map[string]string{
+					"client2,client3": "NXDOMAIN",
+				}
+			})
+
+			It("should use the block type of the group first", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("blocked2.com.", A, "1.2.1.2", "client3"))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("blocked2.com.", A, "12.12.12.12"),
+							HaveResponseType(ResponseTypeBLOCKED),
+							HaveReason("BLOCKED (gr2)"),
+						))
+			})
+
+			It("should use the block type of the client for other groups", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("domain1.com.", A, "1.2.1.2", "client3"))).
+					Should(
+						SatisfyAll(
+							HaveNoAnswer(),
+							HaveResponseType(ResponseTypeBLOCKED),
+							HaveReturnCode(dns.RcodeNameError),
+							HaveReason("BLOCKED (gr1)"),
+						))
+			})
+
+			It("should use the block type of a group with mixed case name", func() {
+				sutConfig.BlockTypes["defaultGroup"] = "NXDOMAIN"
+
+				sut, err := NewBlockingResolver(ctx, sutConfig, nil, systemResolverBootstrap, nil)
+				Expect(err).Should(Succeed())
+
+				Expect(sut.Resolve(ctx, newRequestWithClient("blocked3.com.", A, "1.2.1.2", "unknown"))).
+					Should(
+						SatisfyAll(
+							HaveNoAnswer(),
+							HaveResponseType(ResponseTypeBLOCKED),
+							HaveReturnCode(dns.RcodeNameError),
+							HaveReason("BLOCKED (defaultGroup)"),
+						))
+			})
+
+			It("should use the default block type for other clients", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("domain1.com.", A, "1.2.1.2", "client1"))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("domain1.com.", A, "0.0.0.0"),
+							HaveResponseType(ResponseTypeBLOCKED),
+							HaveReason("BLOCKED (gr1)"),
+						))
+			})
+		})
+
 		When("Denylist contains IP", func() {
 			When("IP4", func() {
 				BeforeEach(func() {
@@ -575,6 +905,98 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 			})
 		})
 
+		When("Denylist contains network", func() {
+			BeforeEach(func() {
+				mockAnswer, _ = util.NewMsgWithAnswer("example.com.", 300, A, "198.51.100.17")
+			})
+
+			It("should block query, if lookup result contains IP in the network", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", A, "1.2.1.2", "unknown"))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("example.com.", A, "0.0.0.0"),
+							HaveResponseType(ResponseTypeBLOCKED),
+							HaveReason("BLOCKED IP (defaultGroup)"),
+						))
+			})
+		})
+
+		When("Denylist contains ASN", func() {
+			BeforeEach(func() {
+				mockAnswer, _ = util.NewMsgWithAnswer("example.com.", 300, A, "192.0.2.1")
+			})
+
+			It("should not block query without ASN database", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", A, "1.2.1.2", "unknown"))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("example.com.", A, "192.0.2.1"),
+							HaveResponseType(ResponseTypeRESOLVED),
+						))
+			})
+
+			When("ASN database is configured", func() {
+				BeforeEach(func() {
+					var err error
+
+					sutGeoIP, err = geoip.Open(ctx, &config.GeoIP{
+						ASNDatabase: TempMMDB(map[string]mmdbtype.Map{
+							"192.0.2.0/24": {"autonomous_system_number": mmdbtype.Uint32(64500)},
+						}),
+					})
+					Expect(err).Should(Succeed())
+					DeferCleanup(sutGeoIP.Close)
+				})
+
+				It("should block query, if lookup result contains IP of the ASN", func() {
+					Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", A, "1.2.1.2", "unknown"))).
+						Should(
+							SatisfyAll(
+								BeDNSRecord("example.com.", A, "0.0.0.0"),
+								HaveResponseType(ResponseTypeBLOCKED),
+								HaveReason("BLOCKED ASN (defaultGroup)"),
+							))
+				})
+			})
+		})
+
+		When("Denylist contains country", func() {
+			BeforeEach(func() {
+				mockAnswer, _ = util.NewMsgWithAnswer("example.com.", 300, A, "175.45.176.1")
+
+				var err error
+
+				sutGeoIP, err = geoip.Open(ctx, &config.GeoIP{
+					CountryDatabase: TempMMDB(map[string]mmdbtype.Map{
+						"175.45.176.0/22": {"country": mmdbtype.Map{"iso_code": mmdbtype.String("KP")}},
+					}),
+				})
+				Expect(err).Should(Succeed())
+				DeferCleanup(sutGeoIP.Close)
+			})
+
+			It("should block query, if lookup result contains IP in the country", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", A, "1.2.1.2", "unknown"))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("example.com.", A, "0.0.0.0"),
+							HaveResponseType(ResponseTypeBLOCKED),
+							HaveReason("BLOCKED COUNTRY (defaultGroup)"),
+						))
+			})
+
+			When("the IP is in another country", func() {
+				BeforeEach(func() {
+					mockAnswer, _ = util.NewMsgWithAnswer("example.com.", 300, A, "1.1.1.1")
+				})
+
+				It("should not block query", func() {
+					Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", A, "1.2.1.2", "unknown"))).
+						Should(HaveResponseType(ResponseTypeRESOLVED))
+				})
+			})
+		})
+
 		When("denylist contains domain which is CNAME in response", func() {
 			BeforeEach(func() {
 				// reconfigure mock, to return CNAMEs
@@ -596,6 +1018,67 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 						))
 			})
 		})
+
+		When("response contains HTTPS records", func() {
+			When("the target is on a denylist", func() {
+				BeforeEach(func() {
+					rr, _ := dns.NewRR("example.com 300 IN HTTPS 1 badcnamedomain.com. alpn=h2")
+					mockAnswer = new(dns.Msg)
+					mockAnswer.Answer = []dns.RR{rr}
+				})
+
+				It("should block the query", func() {
+					Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", HTTPS, "1.2.1.2", "unknown"))).
+						Should(
+							SatisfyAll(
+								HaveNoAnswer(),
+								HaveResponseType(ResponseTypeBLOCKED),
+								HaveReturnCode(dns.RcodeNameError),
+								HaveReason("BLOCKED HTTPS (defaultGroup)"),
+							))
+				})
+			})
+
+			When("an IP hint is on a denylist", func() {
+				BeforeEach(func() {
+					rr, _ := dns.NewRR("example.com 300 IN HTTPS 1 . alpn=h2 " +
+						"ipv4hint=1.2.3.4 ipv6hint=2001:db8:85a3:8d3::370:7344")
+					mockAnswer = new(dns.Msg)
+					mockAnswer.Answer = []dns.RR{rr}
+				})
+
+				It("should block the query", func() {
+					Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", HTTPS, "1.2.1.2", "unknown"))).
+						Should(
+							SatisfyAll(
+								HaveResponseType(ResponseTypeBLOCKED),
+								HaveReturnCode(dns.RcodeNameError),
+								HaveReason("BLOCKED HTTPS (defaultGroup)"),
+							))
+				})
+			})
+
+			When("stripECH is configured", func() {
+				BeforeEach(func() {
+					sutConfig.StripECH = []string{"example.com."}
+
+					rr1, _ := dns.NewRR("www.example.com 300 IN HTTPS 1 . alpn=h2 ech=AEX+DQBB")
+					rr2, _ := dns.NewRR("example.org 300 IN HTTPS 1 . alpn=h2 ech=AEX+DQBB")
+					mockAnswer = new(dns.Msg)
+					mockAnswer.Answer = []dns.RR{rr1, rr2}
+				})
+
+				It("should remove the ech parameter for configured domains only", func() {
+					resp, err := sut.Resolve(ctx, newRequestWithClient("www.example.com.", HTTPS, "1.2.1.2", "unknown"))
+					Expect(err).Should(Succeed())
+					Expect(resp.RType).Should(Equal(ResponseTypeRESOLVED))
+					Expect(resp.Res.Answer).Should(HaveLen(2))
+
+					Expect(resp.Res.Answer[0].String()).ShouldNot(ContainSubstring("ech="))
+					Expect(resp.Res.Answer[1].String()).Should(ContainSubstring("ech="))
+				})
+			})
+		})
 	})
 
 	Describe("Allowlisting", func() {
@@ -1078,6 +1561,51 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 			})
 		})
 
+		When("a domain is allowed temporarily", func() {
+			It("should not block the domain and its subdomains until the duration elapsed", func() {
+				Expect(sut.AllowDomain(ctx, "Blocked3.com.", 100*time.Millisecond)).Should(Succeed())
+
+				Expect(sut.Resolve(ctx, newRequestWithClient("blocked3.com.", A, "1.2.1.2", "unknown"))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+				Expect(sut.Resolve(ctx, newRequestWithClient("sub.blocked3.com.", A, "1.2.1.2", "unknown"))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+				Expect(sut.Resolve(ctx, newRequestWithClient("domain1.com.", A, "1.2.1.2", "unknown"))).
+					Should(HaveResponseType(ResponseTypeBLOCKED))
+
+				Eventually(sut.Resolve).
+					WithArguments(ctx, newRequestWithClient("blocked3.com.", A, "1.2.1.2", "unknown")).
+					Should(HaveResponseType(ResponseTypeBLOCKED))
+			})
+
+			It("should fail without domain or duration", func() {
+				Expect(sut.AllowDomain(ctx, " ", time.Minute)).Should(MatchError("domain is empty"))
+				Expect(sut.AllowDomain(ctx, "blocked3.com", 0)).Should(MatchError(ContainSubstring("must be positive")))
+			})
+
+			It("should refuse public suffixes", func() {
+				Expect(sut.AllowDomain(ctx, "com", time.Minute)).Should(MatchError(ContainSubstring("public suffix")))
+				Expect(sut.AllowDomainForClient(ctx, net.ParseIP("1.2.1.2"), "co.uk.", time.Minute)).
+					Should(MatchError(ContainSubstring("public suffix")))
+			})
+		})
+
+		When("a domain is allowed temporarily for a client", func() {
+			It("should not block the domain for this client only", func() {
+				Expect(sut.AllowDomainForClient(ctx, net.ParseIP("1.2.1.2"), "Blocked3.com.", time.Minute)).
+					Should(Succeed())
+
+				Expect(sut.Resolve(ctx, newRequestWithClient("blocked3.com.", A, "1.2.1.2", "unknown"))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+				Expect(sut.Resolve(ctx, newRequestWithClient("blocked3.com.", A, "1.2.1.3", "unknown"))).
+					Should(HaveResponseType(ResponseTypeBLOCKED))
+			})
+
+			It("should fail without client IP", func() {
+				Expect(sut.AllowDomainForClient(ctx, nil, "blocked3.com", time.Minute)).
+					Should(MatchError("client IP is unknown"))
+			})
+		})
+
 		When("Disable blocking is called with wrong group name", func() {
 			It("should fail", func() {
 				err := sut.DisableBlocking(context.TODO(), 500*time.Millisecond, []string{"unknownGroupName"})
@@ -1110,15 +1638,117 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 		})
 	})
 
+	Describe("Local list rules", func() {
+		BeforeEach(func() {
+			sutConfig = config.Blocking{
+				Denylists: map[string][]config.BytesSource{
+					"ads":   {config.TextBytesSource("ads.com", "*.tracker.net", "/^ad[0-9]+\\./")},
+					"adult": {config.TextBytesSource("adult.com", "ads.com", "1.2.3.4")},
+				},
+				Allowlists: map[string][]config.BytesSource{
+					"ads": {config.TextBytesSource("good.ads.com")},
+				},
+				ClientGroupsBlock: map[string][]string{
+					"default": {"ads", "adult"},
+				},
+				BlockType: "ZeroIP",
+			}
+		})
+
+		It("returns the rules of all groups without duplicates", func() {
+			Expect(sut.LocalListRules(ctx, nil)).Should(Equal([]formats.Rule{
+				{Kind: formats.RuleKindExact, Value: "ads.com"},
+				{Kind: formats.RuleKindWildcard, Value: "tracker.net"},
+				{Kind: formats.RuleKindRegex, Value: `^ad[0-9]+\.`},
+				{Kind: formats.RuleKindExact, Value: "adult.com"},
+				{Allow: true, Kind: formats.RuleKindExact, Value: "good.ads.com"},
+			}))
+		})
+
+		It("returns the rules of the groups", func() {
+			Expect(sut.LocalListRules(ctx, []string{"adult"})).Should(Equal([]formats.Rule{
+				{Kind: formats.RuleKindExact, Value: "adult.com"},
+				{Kind: formats.RuleKindExact, Value: "ads.com"},
+			}))
+		})
+
+		It("fails for unknown groups", func() {
+			_, err := sut.LocalListRules(ctx, []string{"unknown"})
+			Expect(err).Should(MatchError("group 'unknown' is unknown"))
+		})
+	})
+
+	Describe("Scheduled blocking", func() {
+		BeforeEach(func() {
+			sutConfig = config.Blocking{
+				Denylists: map[string][]config.BytesSource{
+					"defaultGroup": config.NewBytesSources(defaultGroupFile.Path),
+					"group1":       config.NewBytesSources(group1File.Path),
+				},
+				ClientGroupsBlock: map[string][]string{
+					"default": {"defaultGroup", "group1"},
+				},
+				Schedules: map[string]config.BlockingSchedule{
+					// a window without days and times covers the whole week
+					"defaultGroup": {Windows: []config.ScheduleWindow{{}}},
+					"group1":       {},
+				},
+				BlockType: "ZeroIP",
+			}
+		})
+
+		When("the schedule of a group is active", func() {
+			It("should block", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("blocked3.com.", A, "1.2.1.2", "unknown"))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("blocked3.com.", A, "0.0.0.0"),
+							HaveResponseType(ResponseTypeBLOCKED),
+							HaveReason("BLOCKED (defaultGroup)"),
+						))
+			})
+		})
+
+		When("the schedule of a group is inactive", func() {
+			It("should not block", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("domain1.com.", A, "1.2.1.2", "unknown"))).
+					Should(
+						SatisfyAll(
+							HaveNoAnswer(),
+							HaveResponseType(ResponseTypeRESOLVED),
+						))
+			})
+		})
+
+		When("Blocking schedule is called", func() {
+			It("should return the state of all scheduled groups", func() {
+				Expect(sut.BlockingSchedule()).Should(Equal([]api.BlockingScheduleStatus{
+					{Group: "defaultGroup", Active: true},
+					{Group: "group1", Active: false},
+				}))
+			})
+		})
+	})
+
 	Describe("Create resolver with wrong parameter", func() {
 		When("Wrong blockType is used", func() {
 			It("should return error", func() {
 				_, err := NewBlockingResolver(ctx, config.Blocking{
 					BlockType: "wrong",
-				}, nil, systemResolverBootstrap)
+				}, nil, systemResolverBootstrap, nil)
 
 				Expect(err).Should(
-					MatchError("unknown blockType 'wrong', please use one of: ZeroIP, NxDomain or specify destination IP address(es)"))
+					MatchError("unknown blockType 'wrong', please use one of: ZeroIP, NxDomain, NoData, Refused " +
+						"or specify destination IP address(es)"))
+			})
+
+			It("should return error for block types of groups", func() {
+				_, err := NewBlockingResolver(ctx, config.Blocking{
+					BlockType:  "zeroIp",
+					BlockTypes: map[string]string{"gr1": "wrong"},
+				}, nil, systemResolverBootstrap, nil)
+
+				Expect(err).Should(MatchError(ContainSubstring("blockTypes: gr1: unknown blockType 'wrong'")))
 			})
 		})
 		When("strategy is failOnError", func() {
@@ -1130,7 +1760,7 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 						Init: config.Init{Strategy: config.InitStrategyFailOnError},
 					},
 					BlockType: "zeroIp",
-				}, nil, systemResolverBootstrap)
+				}, nil, systemResolverBootstrap, nil)
 				Expect(err).Should(HaveOccurred())
 			})
 		})
@@ -1159,7 +1789,7 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 				BlockTTL:  config.Duration(time.Minute),
 			}
 
-			sut, err = NewBlockingResolver(ctx, sutConfig, redisClient, systemResolverBootstrap)
+			sut, err = NewBlockingResolver(ctx, sutConfig, redisClient, systemResolverBootstrap, nil)
 			Expect(err).Should(Succeed())
 		})
 		JustAfterEach(func() {
@@ -1169,7 +1799,7 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 			It("should return disable", func() {
 				sut.EnableBlocking(context.TODO())
 
-				redisMockMsg := &redis.EnabledMessage{
+				redisMockMsg := &cachesync.EnabledMessage{
 					State: false,
 				}
 				redisClient.EnabledChannel <- redisMockMsg
@@ -1182,7 +1812,7 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 		When("disable", func() {
 			It("should return disable", func() {
 				sut.EnableBlocking(context.TODO())
-				redisMockMsg := &redis.EnabledMessage{
+				redisMockMsg := &cachesync.EnabledMessage{
 					State:  false,
 					Groups: []string{"unknown"},
 				}
@@ -1198,7 +1828,7 @@ var _ = Describe("BlockingResolver", Label("blockingResolver"), func() {
 				err = sut.DisableBlocking(context.TODO(), time.Hour, []string{})
 				Expect(err).Should(Succeed())
 
-				redisMockMsg := &redis.EnabledMessage{
+				redisMockMsg := &cachesync.EnabledMessage{
 					State: true,
 				}
 				redisClient.EnabledChannel <- redisMockMsg
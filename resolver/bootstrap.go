@@ -7,7 +7,9 @@ import (
 	"math/rand"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -29,6 +31,7 @@ type bootstrapConfig struct {
 
 	connectIPVersion config.IPVersion
 	timeout          config.Duration
+	pinnedIPs        map[string][]net.IP
 }
 
 func newBootstrapConfig(cfg *config.Config) *bootstrapConfig {
@@ -37,6 +40,7 @@ func newBootstrapConfig(cfg *config.Config) *bootstrapConfig {
 
 		connectIPVersion: cfg.ConnectIPVersion,
 		timeout:          cfg.Upstreams.Timeout,
+		pinnedIPs:        cfg.Upstreams.PinnedIPs,
 	}
 }
 
@@ -48,6 +52,9 @@ type Bootstrap struct {
 	resolver    Resolver
 	bootstraped bootstrapedResolvers
 
+	// IPs of the upstreams by resolver, kept to not lose the rotation between queries
+	upstreamIPs sync.Map
+
 	// To allow replacing during tests
 	systemResolver *net.Resolver
 	dialer         interface {
@@ -91,6 +98,8 @@ func NewBootstrap(ctx context.Context, cfg *config.Config) (b *Bootstrap, err er
 	//   2. forward the user request to the server looked-up in 1
 	cachingCfg := cfg.Caching
 	cachingCfg.EnablePrefetch()
+	// the bootstrap resolver doesn't rewrite TTLs
+	cachingCfg.TTLRewrite = config.TTLRewrite{}
 
 	if !cachingCfg.MinCachingTime.IsAboveZero() {
 		// Set a min time in case the user didn't to avoid prefetching too often
@@ -99,13 +108,19 @@ func NewBootstrap(ctx context.Context, cfg *config.Config) (b *Bootstrap, err er
 
 	b.bootstraped = bootstraped
 
+	// All bootstrap servers are tried in turn, the healthy and fastest first:
+	// a single dead one doesn't fail the lookups
+	fastest := newFastestResolver(pbCfg, bootstraped.Resolvers())
+
 	b.resolver = Chain(
 		NewFilteringResolver(cfg.Filtering),
 		// false: no metrics, to not overwrite the main blocking resolver ones
 		newCachingResolver(ctx, cachingCfg, nil, false),
-		newParallelBestResolver(pbCfg, bootstraped.Resolvers()),
+		fastest,
 	)
 
+	startHealthChecks(ctx, pbCfg, *fastest.resolvers.Load())
+
 	return b, nil
 }
 
@@ -123,7 +138,13 @@ func (b *Bootstrap) Resolve(ctx context.Context, request *model.Request) (*model
 }
 
 func (b *Bootstrap) UpstreamIPs(ctx context.Context, r *UpstreamResolver) (*IPSet, error) {
-	hostname := r.Upstream().Host
+	upstream := r.Upstream()
+	if upstream.Net == config.NetProtocolUnix {
+		// connects to the socket path: nothing to resolve
+		return newIPSet([]net.IP{nil}), nil
+	}
+
+	hostname := upstream.Host
 
 	if ip := net.ParseIP(hostname); ip != nil { // nil-safe when hostname is an IP: makes writing tests easier
 		return newIPSet([]net.IP{ip}), nil
@@ -134,7 +155,22 @@ func (b *Bootstrap) UpstreamIPs(ctx context.Context, r *UpstreamResolver) (*IPSe
 		return nil, fmt.Errorf("could not resolve IPs for upstream %s: %w", hostname, err)
 	}
 
-	return newIPSet(ips), nil
+	return b.ipSetOf(r, ips), nil
+}
+
+// ipSetOf returns the IPSet of the upstream, the previous one is kept as long as the IPs don't change
+// so an IP that failed stays rotated away for the next queries
+func (b *Bootstrap) ipSetOf(r *UpstreamResolver, ips []net.IP) *IPSet {
+	if v, ok := b.upstreamIPs.Load(r); ok {
+		if set := v.(*IPSet); slices.EqualFunc(set.values, ips, net.IP.Equal) {
+			return set
+		}
+	}
+
+	set := newIPSet(ips)
+	b.upstreamIPs.Store(r, set)
+
+	return set
 }
 
 func (b *Bootstrap) resolveUpstream(ctx context.Context, r Resolver, host string) ([]net.IP, error) {
@@ -143,6 +179,10 @@ func (b *Bootstrap) resolveUpstream(ctx context.Context, r Resolver, host string
 		return ips, nil
 	}
 
+	if ips := b.pinnedIPs(host, b.cfg.connectIPVersion.QTypes()); len(ips) != 0 {
+		return ips, nil
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, b.cfg.timeout.ToDuration())
 	defer cancel()
 
@@ -163,7 +203,7 @@ func (b *Bootstrap) NewHTTPTransport() *http.Transport {
 }
 
 func (b *Bootstrap) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
-	if b.resolver == nil {
+	if b.resolver == nil && len(b.cfg.pinnedIPs) == 0 {
 		return b.dialer.DialContext(ctx, network, addr)
 	}
 
@@ -189,12 +229,19 @@ func (b *Bootstrap) dialContext(ctx context.Context, network, addr string) (net.
 		qTypes = config.IPVersionDual.QTypes()
 	}
 
-	// Resolve the host with the bootstrap DNS
-	ips, err := b.resolve(ctx, host, qTypes)
-	if err != nil {
-		logger.Errorf("resolve error: %s", err)
+	// Use the pinned IPs of the host or resolve it with the bootstrap DNS
+	ips := b.pinnedIPs(host, qTypes)
+	if len(ips) == 0 {
+		if b.resolver == nil {
+			return b.dialer.DialContext(ctx, network, addr)
+		}
 
-		return nil, err
+		ips, err = b.resolve(ctx, host, qTypes)
+		if err != nil {
+			logger.Errorf("resolve error: %s", err)
+
+			return nil, err
+		}
 	}
 
 	ip := ips[rand.Intn(len(ips))] //nolint:gosec
@@ -207,6 +254,26 @@ func (b *Bootstrap) dialContext(ctx context.Context, network, addr string) (net.
 	return b.dialer.DialContext(ctx, network, addrWithIP)
 }
 
+// pinnedIPs returns the statically configured IPs of the host matching the query types
+func (b *Bootstrap) pinnedIPs(host string, qTypes []dns.Type) []net.IP {
+	pinned := b.cfg.pinnedIPs[strings.ToLower(host)]
+
+	ips := make([]net.IP, 0, len(pinned))
+
+	for _, ip := range pinned {
+		qType := dns.Type(dns.TypeAAAA)
+		if ip.To4() != nil {
+			qType = dns.Type(dns.TypeA)
+		}
+
+		if slices.Contains(qTypes, qType) {
+			ips = append(ips, ip)
+		}
+	}
+
+	return ips
+}
+
 func (b *Bootstrap) resolve(ctx context.Context, hostname string, qTypes []dns.Type) (ips []net.IP, err error) {
 	ips = make([]net.IP, 0, len(qTypes))
 
@@ -307,7 +374,12 @@ func newBootstrapedResolvers(
 			continue
 		}
 
-		resolver := newUpstreamResolverUnchecked(newUpstreamConfig(upstream, upstreamsCfg), b)
+		resolver, err := newUpstreamResolverUnchecked(newUpstreamConfig(upstream, upstreamsCfg), b)
+		if err != nil {
+			multiErr = multierror.Append(multiErr, fmt.Errorf("item %d: '%s': %w", i, upstream, err))
+
+			continue
+		}
 
 		upstreamIPs[resolver] = ips
 	}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net"
+	"slices"
+	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/0xERR0R/blocky/api"
 	"github.com/0xERR0R/blocky/cache/expirationcache"
+	"github.com/0xERR0R/blocky/cachesync"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/evt"
 	"github.com/0xERR0R/blocky/metrics"
 	"github.com/0xERR0R/blocky/model"
-	"github.com/0xERR0R/blocky/redis"
 	"github.com/0xERR0R/blocky/util"
 
 	"github.com/miekg/dns"
@@ -21,10 +25,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-const defaultCachingCleanUpInterval = 5 * time.Second
+const (
+	defaultCachingCleanUpInterval = 5 * time.Second
+
+	// separates the client subnet from the domain in cache keys, can't be part of a domain name
+	subnetCacheKeySeparator = byte(0)
+)
 
 //nolint:gochecknoglobals
 var (
+	// upper bounds of the TTL buckets of the cache stats, the last bucket is unbounded
+	cacheTTLBucketBounds = []time.Duration{time.Minute, 10 * time.Minute, time.Hour}
+
 	cacheHits = promauto.With(metrics.Reg).NewCounter(
 		prometheus.CounterOpts{
 			Name: "blocky_cache_hits_total",
@@ -50,35 +62,42 @@ type CachingResolver struct {
 
 	resultCache expirationcache.ExpiringCache[[]byte]
 
-	redisClient *redis.Client
+	// hits and misses per TTL bucket, see `cacheTTLBucketBounds`
+	bucketHits   []atomic.Uint64
+	bucketMisses []atomic.Uint64
+
+	syncClient cachesync.Client
 }
 
 // NewCachingResolver creates a new resolver instance
 func NewCachingResolver(ctx context.Context,
 	cfg config.Caching,
-	redis *redis.Client,
+	syncClient cachesync.Client,
 ) *CachingResolver {
-	return newCachingResolver(ctx, cfg, redis, true)
+	return newCachingResolver(ctx, cfg, syncClient, true)
 }
 
 func newCachingResolver(ctx context.Context,
 	cfg config.Caching,
-	redis *redis.Client,
+	syncClient cachesync.Client,
 	emitMetricEvents bool,
 ) *CachingResolver {
 	c := &CachingResolver{
 		configurable: withConfig(&cfg),
 		typed:        withType("caching"),
 
-		redisClient:      redis,
+		bucketHits:   make([]atomic.Uint64, len(cacheTTLBucketBounds)+1),
+		bucketMisses: make([]atomic.Uint64, len(cacheTTLBucketBounds)+1),
+
+		syncClient:       syncClient,
 		emitMetricEvents: emitMetricEvents,
 	}
 
 	configureCaches(ctx, c, &cfg)
 
-	if c.redisClient != nil {
-		go c.redisSubscriber(ctx)
-		c.redisClient.GetRedisCache(ctx)
+	if c.syncClient != nil {
+		go c.syncSubscriber(ctx)
+		c.syncClient.LoadCache(ctx)
 	}
 
 	return c
@@ -97,15 +116,21 @@ func configureCaches(ctx context.Context, c *CachingResolver, cfg *config.Cachin
 		OnAfterPutFn: func(newSize int) {
 			c.publishMetricsIfEnabled(evt.CachingResultCacheChanged, newSize)
 		},
+		TinyLFU:           cfg.EvictionPolicy == config.CacheEvictionPolicyTinylfu,
+		CompressIdleAfter: cfg.CompressIdleAfter.ToDuration(),
+		MaxMemory:         cfg.MaxMemory,
 	}
 
 	if cfg.Prefetching {
+		options.ExpirationConcurrency = cfg.PrefetchMaxConcurrent
+
 		prefetchingOptions := expirationcache.PrefetchingOptions[[]byte]{
 			Options:               options,
 			PrefetchExpires:       time.Duration(cfg.PrefetchExpires),
 			PrefetchThreshold:     cfg.PrefetchThreshold,
 			PrefetchMaxItemsCount: cfg.PrefetchMaxItemsCount,
 			ReloadFn:              c.reloadCacheEntry,
+			ShouldPrefetchFn:      c.shouldPrefetch,
 			OnPrefetchAfterPut: func(newSize int) {
 				c.publishMetricsIfEnabled(evt.CachingDomainsToPrefetchCountChanged, newSize)
 			},
@@ -123,13 +148,72 @@ func configureCaches(ctx context.Context, c *CachingResolver, cfg *config.Cachin
 	}
 }
 
+// shouldPrefetch applies the prefetch settings of the most specific matching domain to an expired entry
+func (r *CachingResolver) shouldPrefetch(cacheKey string, queryCount int, ttl time.Duration) bool {
+	cacheKey, _ = splitSubnetCacheKey(cacheKey)
+	_, domainName := util.ExtractCacheKey(cacheKey)
+
+	for _, excluded := range r.cfg.PrefetchExclude {
+		if domainMatches(domainName, excluded) {
+			return false
+		}
+	}
+
+	threshold, minTTL := r.cfg.PrefetchThreshold, r.cfg.PrefetchMinTTL
+	matchLen := 0
+
+	for _, rule := range r.cfg.PrefetchDomains {
+		for _, domain := range rule.Domains {
+			if len(domain) <= matchLen || !domainMatches(domainName, domain) {
+				continue
+			}
+
+			matchLen = len(domain)
+			threshold, minTTL = r.cfg.PrefetchThreshold, r.cfg.PrefetchMinTTL
+
+			if rule.Threshold != nil {
+				threshold = *rule.Threshold
+			}
+
+			if rule.MinTTL != nil {
+				minTTL = *rule.MinTTL
+			}
+		}
+	}
+
+	if ttl < minTTL.ToDuration() {
+		return false
+	}
+
+	return threshold == 0 || queryCount > threshold
+}
+
+// domainMatches returns true if the name is the domain or one of its subdomains
+func domainMatches(name, domain string) bool {
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// matchesDomainList returns true if the name is one of the domains of the list or one of their subdomains
+func matchesDomainList(list []string, name string) bool {
+	return slices.ContainsFunc(list, func(domain string) bool {
+		return domainMatches(name, domain)
+	})
+}
+
 func (r *CachingResolver) reloadCacheEntry(ctx context.Context, cacheKey string) (*[]byte, time.Duration) {
+	cacheKey, subnet := splitSubnetCacheKey(cacheKey)
 	qType, domainName := util.ExtractCacheKey(cacheKey)
 	ctx, logger := r.log(ctx)
 
 	logger.Debugf("prefetching '%s' (%s)", util.Obfuscate(domainName), qType)
 
 	req := newRequest(dns.Fqdn(domainName), qType)
+
+	if subnet != nil {
+		// the entry is specific to a client subnet: reload it for the same subnet
+		util.SetEdns0Option(req.Req, subnet)
+	}
+
 	response, err := r.next.Resolve(ctx, req)
 
 	if err == nil {
@@ -141,7 +225,7 @@ func (r *CachingResolver) reloadCacheEntry(ctx context.Context, cacheKey string)
 				return nil, 0
 			}
 
-			return &packed, r.adjustTTLs(response.Res.Answer)
+			return &packed, r.cacheTTL(response.Res)
 		}
 	} else {
 		util.LogOnError(ctx, fmt.Sprintf("can't prefetch '%s' ", domainName), err)
@@ -150,15 +234,15 @@ func (r *CachingResolver) reloadCacheEntry(ctx context.Context, cacheKey string)
 	return nil, 0
 }
 
-func (r *CachingResolver) redisSubscriber(ctx context.Context) {
+func (r *CachingResolver) syncSubscriber(ctx context.Context) {
 	ctx, logger := r.log(ctx)
 
 	for {
 		select {
-		case rc := <-r.redisClient.CacheChannel:
+		case rc := <-r.syncClient.CacheMessages():
 			if rc != nil {
-				logger.Debug("Received key from redis: ", rc.Key)
-				ttl := r.adjustTTLs(rc.Response.Res.Answer)
+				logger.Debug("Received key from shared cache: ", rc.Key)
+				ttl := r.cacheTTL(rc.Response.Res)
 				r.putInCache(ctx, rc.Key, rc.Response, ttl, false)
 			}
 
@@ -180,7 +264,7 @@ func (r *CachingResolver) LogConfig(logger *logrus.Entry) {
 func (r *CachingResolver) Resolve(ctx context.Context, request *model.Request) (response *model.Response, err error) {
 	ctx, logger := r.log(ctx)
 
-	if !r.IsEnabled() || !isRequestCacheable(request) {
+	if !r.IsEnabled() {
 		logger.Debug("skip cache")
 
 		return r.next.Resolve(ctx, request)
@@ -189,13 +273,32 @@ func (r *CachingResolver) Resolve(ctx context.Context, request *model.Request) (
 	for _, question := range request.Req.Question {
 		domain := util.ExtractDomain(question)
 		cacheKey := util.GenerateCacheKey(dns.Type(question.Qtype), domain)
+		subnetKey := subnetCacheKey(cacheKey, request.Req)
 		logger := logger.WithField("domain", util.Obfuscate(domain))
 
-		val, ttl := r.getFromCache(logger, cacheKey)
+		var (
+			val *dns.Msg
+			ttl time.Duration
+		)
+
+		if subnetKey != "" {
+			val, ttl = r.getFromCache(logger, subnetKey)
+		}
+
+		if val == nil {
+			val, ttl = r.getFromCache(logger, cacheKey)
+		}
 
 		if val != nil {
 			logger.Debug("domain is cached")
 
+			if isDryRun(ctx) {
+				r.trace(ctx, "cached, remaining TTL %s", ttl.Round(time.Second))
+				traced(ctx, func(trace *model.Trace) { trace.Cache = "HIT" })
+			} else {
+				r.bucketHits[ttlBucket(r.cacheTTL(val))].Add(1)
+			}
+
 			val.SetRcode(request.Req, val.Rcode)
 
 			// Adjust TTL
@@ -209,10 +312,23 @@ func (r *CachingResolver) Resolve(ctx context.Context, request *model.Request) (
 		}
 
 		logger.WithField("next_resolver", Name(r.next)).Trace("not in cache: go to next resolver")
+
+		if isDryRun(ctx) {
+			r.trace(ctx, "not cached")
+			traced(ctx, func(trace *model.Trace) { trace.Cache = "MISS" })
+
+			return r.next.Resolve(ctx, request)
+		}
+
 		response, err = r.next.Resolve(ctx, request)
 
 		if err == nil {
-			cacheTTL := r.adjustTTLs(response.Res.Answer)
+			if subnetKey != "" && isSubnetSpecific(response.Res) {
+				cacheKey = subnetKey
+			}
+
+			cacheTTL := r.cacheTTL(response.Res)
+			r.bucketMisses[ttlBucket(cacheTTL)].Add(1)
 			r.putInCache(ctx, cacheKey, response, cacheTTL, true)
 		}
 	}
@@ -248,19 +364,57 @@ func setTTLInCachedResponse(resp *dns.Msg, ttl time.Duration) {
 	for _, rr := range resp.Answer {
 		rr.Header().Ttl = rr.Header().Ttl - minTTL + uint32(ttl.Seconds())
 	}
-}
 
-// isRequestCacheable returns true if the request should be cached
-func isRequestCacheable(request *model.Request) bool {
-	// don't cache responses with EDNS Client Subnet option with masks that include more than one client
-	if so := util.GetEdns0Option[*dns.EDNS0_SUBNET](request.Req); so != nil {
-		if (so.Family == ecsFamilyIPv4 && so.SourceNetmask != ecsMaskIPv4) ||
-			(so.Family == ecsFamilyIPv6 && so.SourceNetmask != ecsMaskIPv6) {
-			return false
+	if len(resp.Answer) == 0 {
+		// the SOA TTL of a negative response is how long it may be cached (RFC 2308)
+		if soa := authoritySOA(resp); soa != nil {
+			soa.Hdr.Ttl = min(soa.Hdr.Ttl, uint32(ttl.Seconds()))
 		}
 	}
+}
+
+// subnetCacheKey returns the cache key for answers specific to the EDNS Client Subnet of msg,
+// or "" if msg has no ECS option
+func subnetCacheKey(cacheKey string, msg *dns.Msg) string {
+	so := util.GetEdns0Option[*dns.EDNS0_SUBNET](msg)
+	if so == nil || so.Address == nil {
+		return ""
+	}
 
-	return true
+	return fmt.Sprintf("%s%c%s/%d", cacheKey, subnetCacheKeySeparator, so.Address, so.SourceNetmask)
+}
+
+// splitSubnetCacheKey is the reverse of `subnetCacheKey`: subnet is nil if key is not specific to a subnet
+func splitSubnetCacheKey(key string) (cacheKey string, subnet *dns.EDNS0_SUBNET) {
+	// the first bytes of the key are the binary query type, which can contain the separator
+	const qTypeLength = 2
+
+	idx := strings.LastIndexByte(key, subnetCacheKeySeparator)
+	if idx < qTypeLength {
+		return key, nil
+	}
+
+	cacheKey, cidr := key[:idx], key[idx+1:]
+
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return cacheKey, nil
+	}
+
+	mask, _ := ipNet.Mask.Size()
+
+	if ip := ipNet.IP.To4(); ip != nil {
+		return cacheKey, newEdnsSubnetOption(ip, ecsFamilyIPv4, config.ECSv4Mask(mask))
+	}
+
+	return cacheKey, newEdnsSubnetOption(ipNet.IP, ecsFamilyIPv6, config.ECSv6Mask(mask))
+}
+
+// isSubnetSpecific returns true if the upstream tailored msg to the client subnet (ECS scope > 0)
+func isSubnetSpecific(msg *dns.Msg) bool {
+	so := util.GetEdns0Option[*dns.EDNS0_SUBNET](msg)
+
+	return so != nil && so.SourceScope > 0
 }
 
 // isResponseCacheable returns true if the response is not truncated and its CD flag isn't set.
@@ -280,43 +434,84 @@ func (r *CachingResolver) putInCache(
 	packed, err := respCopy.Pack()
 	util.LogOnError(ctx, "error on packing", err)
 
-	if err == nil {
-		if response.Res.Rcode == dns.RcodeSuccess && isResponseCacheable(response.Res) {
-			// put value into cache
-			r.resultCache.Put(cacheKey, &packed, ttl)
-		} else if response.Res.Rcode == dns.RcodeNameError {
-			if r.cfg.CacheTimeNegative.IsAboveZero() {
-				// put negative cache if result code is NXDOMAIN
-				r.resultCache.Put(cacheKey, &packed, r.cfg.CacheTimeNegative.ToDuration())
-			}
-		}
+	if err == nil && isResponseCacheable(response.Res) {
+		// put value into cache, a TTL of 0 is not cached
+		r.resultCache.Put(cacheKey, &packed, ttl)
 	}
 
-	if publish && r.redisClient != nil {
+	if publish && r.syncClient != nil {
 		res := *respCopy
-		r.redisClient.PublishCache(cacheKey, &res)
+		r.syncClient.PublishCache(cacheKey, &res)
+	}
+}
+
+// cacheTTL returns how long the response is cached, 0 if it isn't cached
+func (r *CachingResolver) cacheTTL(msg *dns.Msg) time.Duration {
+	switch {
+	case msg.Rcode == dns.RcodeNameError:
+		return r.negativeTTL(msg, r.cfg.NXDomain)
+	case msg.Rcode == dns.RcodeServerFailure:
+		return r.cfg.ServFailTime.ToDuration()
+	case msg.Rcode != dns.RcodeSuccess:
+		return 0
+	case len(msg.Answer) == 0:
+		return r.negativeTTL(msg, r.cfg.NoData)
+	default:
+		return r.adjustTTLs(msg)
+	}
+}
+
+// negativeTTL returns how long a negative response is cached: like RFC 2308 the TTL of the SOA record in the
+// authority section or `cacheTimeNegative` without SOA, limited by the settings of the kind of response
+func (r *CachingResolver) negativeTTL(msg *dns.Msg, limits config.NegativeCaching) time.Duration {
+	if !r.cfg.CacheTimeNegative.IsAboveZero() {
+		// negative caching is disabled
+		return 0
+	}
+
+	ttl := r.cfg.CacheTimeNegative.ToDuration()
+
+	if soa := authoritySOA(msg); soa != nil {
+		ttl = time.Duration(min(soa.Hdr.Ttl, soa.Minttl)) * time.Second
+	}
+
+	maxTTL := r.cfg.CacheTimeNegative
+	if limits.MaxTime.IsAboveZero() {
+		maxTTL = limits.MaxTime
+	}
+
+	return max(min(ttl, maxTTL.ToDuration()), limits.MinTime.ToDuration())
+}
+
+// authoritySOA returns the SOA record of the authority section, nil if there is none
+func authoritySOA(msg *dns.Msg) *dns.SOA {
+	for _, rr := range msg.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa
+		}
 	}
+
+	return nil
 }
 
 // adjustTTLs calculates and returns the min TTL (considers also the min and max cache time)
-// for all records from answer or a negative cache time for empty answer
-// adjust the TTL in the answer header accordingly
-func (r *CachingResolver) adjustTTLs(answer []dns.RR) (ttl time.Duration) {
+// for all records from a non-empty answer and adjusts the TTL in the answer header accordingly
+func (r *CachingResolver) adjustTTLs(msg *dns.Msg) (ttl time.Duration) {
 	minTTL := uint32(math.MaxInt32)
 
-	if len(answer) == 0 {
-		return r.cfg.CacheTimeNegative.ToDuration()
-	}
+	// the TTLs of domains with a TTL rewrite rule were already set by the TTL rewrite resolver
+	limit := len(msg.Question) == 0 ||
+		ttlRewriteRuleFor(r.cfg.TTLRewrite.Rules, util.ExtractDomain(msg.Question[0])) == nil
 
-	for _, a := range answer {
+	for _, a := range msg.Answer {
 		// if TTL < mitTTL -> adjust the value, set minTTL
-		if r.cfg.MinCachingTime.IsAboveZero() {
+		if limit && r.cfg.MinCachingTime.IsAboveZero() {
 			if atomic.LoadUint32(&a.Header().Ttl) < r.cfg.MinCachingTime.SecondsU32() {
 				atomic.StoreUint32(&a.Header().Ttl, r.cfg.MinCachingTime.SecondsU32())
 			}
 		}
 
-		if r.cfg.MaxCachingTime.IsAboveZero() {
+		if limit && r.cfg.MaxCachingTime.IsAboveZero() {
 			if atomic.LoadUint32(&a.Header().Ttl) > r.cfg.MaxCachingTime.SecondsU32() {
 				atomic.StoreUint32(&a.Header().Ttl, r.cfg.MaxCachingTime.SecondsU32())
 			}
@@ -343,3 +538,106 @@ func (r *CachingResolver) FlushCaches(ctx context.Context) {
 	logger.Debug("flush caches")
 	r.resultCache.Clear()
 }
+
+// CacheEntries implements `api.CacheControl`
+func (r *CachingResolver) CacheEntries(name string) []api.CacheEntry {
+	name = util.ExtractDomainOnly(name)
+
+	var res []api.CacheEntry
+
+	r.resultCache.Entries(func(key string, val *[]byte, ttl time.Duration) bool {
+		cacheKey, subnet := splitSubnetCacheKey(key)
+		qType, domainName := util.ExtractCacheKey(cacheKey)
+
+		if !strings.Contains(domainName, name) {
+			return true
+		}
+
+		msg := new(dns.Msg)
+		if err := msg.Unpack(*val); err != nil {
+			return true
+		}
+
+		entry := api.CacheEntry{Name: domainName, Type: qType, TTL: ttl, Response: msg}
+
+		if subnet != nil {
+			entry.Subnet = fmt.Sprintf("%s/%d", subnet.Address, subnet.SourceNetmask)
+		}
+
+		res = append(res, entry)
+
+		return true
+	})
+
+	return res
+}
+
+// RemoveCacheEntries implements `api.CacheControl`
+func (r *CachingResolver) RemoveCacheEntries(ctx context.Context, name string) int {
+	_, logger := r.log(ctx)
+
+	name = util.ExtractDomainOnly(name)
+
+	var keys []string
+
+	r.resultCache.Entries(func(key string, _ *[]byte, _ time.Duration) bool {
+		cacheKey, _ := splitSubnetCacheKey(key)
+		_, domainName := util.ExtractCacheKey(cacheKey)
+
+		if domainMatches(domainName, name) {
+			keys = append(keys, key)
+		}
+
+		return true
+	})
+
+	for _, key := range keys {
+		r.resultCache.Remove(key)
+	}
+
+	logger.Debugf("removed %d cache entries of '%s'", len(keys), util.Obfuscate(name))
+
+	return len(keys)
+}
+
+// CacheStats implements `api.CacheControl`
+func (r *CachingResolver) CacheStats() api.CacheStats {
+	var res api.CacheStats
+
+	r.resultCache.Entries(func(key string, val *[]byte, _ time.Duration) bool {
+		res.Entries++
+		res.MemoryBytes += len(key) + len(*val)
+
+		return true
+	})
+
+	var minTTL time.Duration
+
+	for i := range r.bucketHits {
+		bucket := api.CacheTTLBucket{
+			MinTTL: minTTL,
+			Hits:   r.bucketHits[i].Load(),
+			Misses: r.bucketMisses[i].Load(),
+		}
+
+		if i < len(cacheTTLBucketBounds) {
+			bucket.MaxTTL = cacheTTLBucketBounds[i]
+			minTTL = bucket.MaxTTL
+		}
+
+		res.Buckets = append(res.Buckets, bucket)
+	}
+
+	return res
+}
+
+// ttlBucket returns the index of the bucket of the TTL, see `cacheTTLBucketBounds`
+func ttlBucket(ttl time.Duration) int {
+	for i, bound := range cacheTTLBucketBounds {
+		if ttl < bound {
+			return i
+		}
+	}
+
+	return len(cacheTTLBucketBounds)
+}
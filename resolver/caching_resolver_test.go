@@ -6,6 +6,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/0xERR0R/blocky/cachesync"
 	"github.com/0xERR0R/blocky/config"
 	. "github.com/0xERR0R/blocky/evt"
 	. "github.com/0xERR0R/blocky/helpertest"
@@ -83,6 +84,80 @@ var _ = Describe("CachingResolver", func() {
 		})
 	})
 
+	Describe("Dry run", func() {
+		It("should not cache traced queries", func() {
+			mockAnswer, _ = util.NewMsgWithAnswer("example.com.", 300, A, "192.0.2.1")
+
+			traceCtx, trace := WithTrace(ctx)
+
+			Expect(sut.Resolve(traceCtx, newRequest("example.com.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(trace()).Should(Equal(Trace{
+				Steps: []TraceStep{{Resolver: "caching", Message: "not cached"}},
+				Cache: "MISS",
+			}))
+			Expect(sut.resultCache.TotalCount()).Should(BeZero())
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(sut.resultCache.TotalCount()).Should(Equal(1))
+
+			traceCtx, trace = WithTrace(ctx)
+
+			Expect(sut.Resolve(traceCtx, newRequest("example.com.", A))).Should(HaveResponseType(ResponseTypeCACHED))
+			Expect(trace().Cache).Should(Equal("HIT"))
+		})
+	})
+
+	Describe("shouldPrefetch", func() {
+		key := func(domain string) string {
+			return util.GenerateCacheKey(A, domain)
+		}
+
+		BeforeEach(func() {
+			two := 2
+			noMinTTL := config.Duration(0)
+
+			sutConfig.Prefetching = true
+			sutConfig.PrefetchThreshold = 5
+			sutConfig.PrefetchMinTTL = config.Duration(time.Minute)
+			sutConfig.PrefetchExclude = []string{"cdn.net"}
+			sutConfig.PrefetchDomains = []config.PrefetchDomain{
+				{Domains: []string{"example.com"}, Threshold: &two},
+				{Domains: []string{"short.example.com"}, MinTTL: &noMinTTL},
+			}
+		})
+
+		It("should use the global settings for other domains", func() {
+			Expect(sut.shouldPrefetch(key("other.com"), 6, time.Hour)).Should(BeTrue())
+			Expect(sut.shouldPrefetch(key("other.com"), 5, time.Hour)).Should(BeFalse())
+			Expect(sut.shouldPrefetch(key("other.com"), 6, time.Second)).Should(BeFalse())
+		})
+
+		It("should not prefetch excluded domains and their subdomains", func() {
+			Expect(sut.shouldPrefetch(key("cdn.net"), 100, time.Hour)).Should(BeFalse())
+			Expect(sut.shouldPrefetch(key("edge.cdn.net"), 100, time.Hour)).Should(BeFalse())
+			Expect(sut.shouldPrefetch(key("mycdn.net"), 100, time.Hour)).Should(BeTrue())
+		})
+
+		It("should use the settings of the most specific domain", func() {
+			Expect(sut.shouldPrefetch(key("www.example.com"), 3, time.Hour)).Should(BeTrue())
+			Expect(sut.shouldPrefetch(key("www.example.com"), 3, time.Second)).Should(BeFalse())
+
+			// the threshold isn't overridden by the more specific domain
+			Expect(sut.shouldPrefetch(key("short.example.com"), 3, time.Second)).Should(BeFalse())
+			Expect(sut.shouldPrefetch(key("short.example.com"), 6, time.Second)).Should(BeTrue())
+		})
+
+		When("the threshold is 0", func() {
+			BeforeEach(func() {
+				sutConfig.PrefetchThreshold = 0
+			})
+
+			It("should always prefetch", func() {
+				Expect(sut.shouldPrefetch(key("other.com"), 0, time.Hour)).Should(BeTrue())
+			})
+		})
+	})
+
 	Describe("Caching responses", func() {
 		When("prefetching is enabled", func() {
 			BeforeEach(func() {
@@ -196,6 +271,28 @@ var _ = Describe("CachingResolver", func() {
 				})
 			})
 		})
+		When("a TTL rewrite rule matches the domain", func() {
+			BeforeEach(func() {
+				sutConfig = config.Caching{
+					MinCachingTime: config.Duration(time.Minute * 5),
+					TTLRewrite: config.TTLRewrite{Rules: []config.TTLRewriteRule{{
+						Domains: []string{"example.com"},
+						MaxTime: config.Duration(time.Minute),
+					}}},
+				}
+				mockAnswer, _ = util.NewMsgWithAnswer("www.example.com.", 60, A, "123.122.121.120")
+				mockAnswer.SetQuestion("www.example.com.", dns.TypeA)
+			})
+
+			It("should not apply the min caching time", func() {
+				Expect(sut.Resolve(ctx, newRequest("www.example.com.", A))).
+					Should(
+						SatisfyAll(
+							HaveResponseType(ResponseTypeRESOLVED),
+							BeDNSRecord("www.example.com.", A, "123.122.121.120"),
+							HaveTTL(BeNumerically("==", 60))))
+			})
+		})
 		When("min caching time is defined", func() {
 			BeforeEach(func() {
 				sutConfig = config.Caching{
@@ -239,7 +336,6 @@ var _ = Describe("CachingResolver", func() {
 
 							// still one call to upstream
 							g.Expect(m.Calls).Should(HaveLen(1))
-
 						}, "1s").Should(Succeed())
 					})
 				})
@@ -560,6 +656,107 @@ var _ = Describe("CachingResolver", func() {
 		})
 	})
 
+	Describe("Negative caching TTL", func() {
+		withSOA := func(msg *dns.Msg, ttl, minTTL uint32) *dns.Msg {
+			msg.Ns = append(msg.Ns, &dns.SOA{
+				Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: ttl},
+				Ns:     "ns.example.com.",
+				Mbox:   "hostmaster.example.com.",
+				Minttl: minTTL,
+			})
+
+			return msg
+		}
+
+		negativeMsg := func(rcode int) *dns.Msg {
+			msg := new(dns.Msg)
+			msg.Rcode = rcode
+
+			return msg
+		}
+
+		It("should use the SOA of the authority section", func() {
+			Expect(sut.cacheTTL(withSOA(negativeMsg(dns.RcodeNameError), 600, 300))).Should(Equal(5 * time.Minute))
+			Expect(sut.cacheTTL(withSOA(negativeMsg(dns.RcodeSuccess), 120, 300))).Should(Equal(2 * time.Minute))
+		})
+
+		It("should use cacheTimeNegative without SOA and as upper limit", func() {
+			Expect(sut.cacheTTL(negativeMsg(dns.RcodeNameError))).Should(Equal(30 * time.Minute))
+			Expect(sut.cacheTTL(withSOA(negativeMsg(dns.RcodeNameError), 7200, 7200))).Should(Equal(30 * time.Minute))
+		})
+
+		It("should not cache other errors", func() {
+			Expect(sut.cacheTTL(negativeMsg(dns.RcodeServerFailure))).Should(BeZero())
+			Expect(sut.cacheTTL(negativeMsg(dns.RcodeRefused))).Should(BeZero())
+		})
+
+		When("limits per response kind are configured", func() {
+			BeforeEach(func() {
+				sutConfig.NXDomain = config.NegativeCaching{MaxTime: config.Duration(time.Minute)}
+				sutConfig.NoData = config.NegativeCaching{
+					MinTime: config.Duration(10 * time.Minute),
+					MaxTime: config.Duration(time.Hour),
+				}
+			})
+
+			It("should apply them", func() {
+				Expect(sut.cacheTTL(withSOA(negativeMsg(dns.RcodeNameError), 600, 300))).Should(Equal(time.Minute))
+				Expect(sut.cacheTTL(withSOA(negativeMsg(dns.RcodeSuccess), 60, 60))).Should(Equal(10 * time.Minute))
+				Expect(sut.cacheTTL(withSOA(negativeMsg(dns.RcodeSuccess), 7200, 7200))).Should(Equal(time.Hour))
+			})
+		})
+
+		When("negative caching is disabled", func() {
+			BeforeEach(func() {
+				sutConfig.CacheTimeNegative = config.Duration(-1)
+				sutConfig.NoData.MinTime = config.Duration(time.Minute)
+			})
+
+			It("should not cache negative responses", func() {
+				Expect(sut.cacheTTL(withSOA(negativeMsg(dns.RcodeNameError), 600, 300))).Should(BeZero())
+				Expect(sut.cacheTTL(negativeMsg(dns.RcodeSuccess))).Should(BeZero())
+			})
+		})
+
+		When("the upstream returns SERVFAIL and servfailTime is set", func() {
+			BeforeEach(func() {
+				sutConfig.ServFailTime = config.Duration(time.Minute)
+				mockAnswer.Rcode = dns.RcodeServerFailure
+			})
+
+			It("should be cached", func() {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(SatisfyAll(
+						HaveResponseType(ResponseTypeCACHED),
+						HaveReason("CACHED NEGATIVE"),
+						HaveReturnCode(dns.RcodeServerFailure),
+					))
+
+				Expect(m.Calls).Should(HaveLen(1))
+			})
+		})
+
+		When("a cached negative response has a SOA", func() {
+			BeforeEach(func() {
+				mockAnswer = withSOA(negativeMsg(dns.RcodeNameError), 600, 300)
+			})
+
+			It("should count down the SOA TTL", func() {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				resp, err := sut.Resolve(ctx, newRequest("example.com.", A))
+				Expect(err).Should(Succeed())
+				Expect(resp.RType).Should(Equal(ResponseTypeCACHED))
+				Expect(resp.Res.Ns).Should(HaveLen(1))
+				Expect(resp.Res.Ns[0].Header().Ttl).Should(BeNumerically("<=", 300))
+			})
+		})
+	})
+
 	Describe("Not A / AAAA queries should also be cached", func() {
 		When("MX query will be performed", func() {
 			BeforeEach(func() {
@@ -770,7 +967,7 @@ var _ = Describe("CachingResolver", func() {
 				request := newRequest("example2.com.", A)
 				domain := util.ExtractDomain(request.Req.Question[0])
 				cacheKey := util.GenerateCacheKey(A, domain)
-				redisMockMsg := &redis.CacheMessage{
+				redisMockMsg := &cachesync.CacheMessage{
 					Key: cacheKey,
 					Response: &Response{
 						RType:  ResponseTypeCACHED,
@@ -791,39 +988,199 @@ var _ = Describe("CachingResolver", func() {
 			})
 		})
 	})
-	Context("isRequestCacheable", func() {
-		var request *Request
-		When("request is not cacheable", func() {
+	Describe("EDNS Client Subnet", func() {
+		withSubnet := func(msg *dns.Msg, address string, mask, scope uint8) *dns.Msg {
+			util.SetEdns0Option(msg, &dns.EDNS0_SUBNET{
+				Code:          dns.EDNS0SUBNET,
+				Family:        ecsFamilyIPv4,
+				SourceNetmask: mask,
+				SourceScope:   scope,
+				Address:       net.ParseIP(address).To4(),
+			})
+
+			return msg
+		}
+
+		requestFromSubnet := func(address string) *Request {
+			request := newRequest("example.com.", A)
+			withSubnet(request.Req, address, 24, 0)
+
+			return request
+		}
+
+		BeforeEach(func() {
+			var err error
+
+			mockAnswer, err = util.NewMsgWithAnswer("example.com.", 300, A, "1.2.3.4")
+			Expect(err).Should(Succeed())
+		})
+
+		When("the upstream answer is specific to the client subnet", func() {
+			BeforeEach(func() {
+				withSubnet(mockAnswer, "192.168.1.0", 24, 24)
+			})
+
+			It("should only be used for the same subnet", func() {
+				Expect(sut.Resolve(ctx, requestFromSubnet("192.168.1.0"))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(sut.Resolve(ctx, requestFromSubnet("192.168.1.0"))).
+					Should(HaveResponseType(ResponseTypeCACHED))
+
+				Expect(sut.Resolve(ctx, requestFromSubnet("10.0.0.0"))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(m.Calls).Should(HaveLen(3))
+			})
+		})
+
+		When("the upstream answer has scope 0", func() {
+			BeforeEach(func() {
+				withSubnet(mockAnswer, "192.168.1.0", 24, 0)
+			})
+
+			It("should be used for all clients", func() {
+				Expect(sut.Resolve(ctx, requestFromSubnet("192.168.1.0"))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(sut.Resolve(ctx, requestFromSubnet("10.0.0.0"))).
+					Should(HaveResponseType(ResponseTypeCACHED))
+
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeCACHED))
+
+				Expect(m.Calls).Should(HaveLen(1))
+			})
+		})
+
+		Describe("cache keys", func() {
+			It("should contain the subnet, which is restored for prefetching", func() {
+				cacheKey := util.GenerateCacheKey(A, "example.com")
+
+				subnetKey := subnetCacheKey(cacheKey, requestFromSubnet("192.168.1.0").Req)
+				Expect(subnetKey).ShouldNot(Equal(cacheKey))
+
+				key, subnet := splitSubnetCacheKey(subnetKey)
+				Expect(key).Should(Equal(cacheKey))
+				Expect(subnet.Family).Should(Equal(ecsFamilyIPv4))
+				Expect(subnet.SourceNetmask).Should(BeNumerically("==", 24))
+				Expect(subnet.Address.String()).Should(Equal("192.168.1.0"))
+			})
+
+			It("should be empty without ECS option", func() {
+				cacheKey := util.GenerateCacheKey(A, "example.com")
+
+				Expect(subnetCacheKey(cacheKey, newRequest("example.com.", A).Req)).Should(BeEmpty())
+
+				key, subnet := splitSubnetCacheKey(cacheKey)
+				Expect(key).Should(Equal(cacheKey))
+				Expect(subnet).Should(BeNil())
+			})
+		})
+	})
+
+	Describe("Cache size limits", func() {
+		BeforeEach(func() {
+			var err error
+
+			mockAnswer, err = util.NewMsgWithAnswer("example.com.", 300, A, "1.2.3.4")
+			Expect(err).Should(Succeed())
+		})
+
+		When("the memory is limited", func() {
 			BeforeEach(func() {
-				request = newRequest("example.com.", A)
-				e := new(dns.EDNS0_SUBNET)
-				e.SourceScope = 0
-				e.Address = net.ParseIP("192.168.0.0")
-				e.Family = 1
-				e.SourceNetmask = 24
-				util.SetEdns0Option(request.Req, e)
+				// enough for about 2 entries
+				sutConfig.MaxMemory = 2 * (150 + 100)
 			})
 
-			It("should return false", func() {
-				Expect(isRequestCacheable(request)).
-					Should(BeFalse())
+			It("should remove the least recently used entries", func() {
+				for _, domain := range []string{"a.com.", "b.com.", "c.com."} {
+					Expect(sut.Resolve(ctx, newRequest(domain, A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+				}
+
+				Expect(sut.resultCache.TotalCount()).Should(Equal(2))
+				Expect(sut.Resolve(ctx, newRequest("a.com.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+				Expect(sut.Resolve(ctx, newRequest("c.com.", A))).Should(HaveResponseType(ResponseTypeCACHED))
 			})
 		})
-		When("request is cacheable", func() {
+
+		When("the eviction policy is tinylfu", func() {
 			BeforeEach(func() {
-				request = newRequest("example.com.", A)
-				e := new(dns.EDNS0_SUBNET)
-				e.SourceScope = 0
-				e.Address = net.ParseIP("192.168.0.10")
-				e.Family = 1
-				e.SourceNetmask = 32
-				util.SetEdns0Option(request.Req, e)
+				sutConfig.MaxItemsCount = 1
+				sutConfig.EvictionPolicy = config.CacheEvictionPolicyTinylfu
 			})
 
-			It("should return true", func() {
-				Expect(isRequestCacheable(request)).
-					Should(BeTrue())
+			It("should keep the entry which is requested more often", func() {
+				for range 3 {
+					_, _ = sut.Resolve(ctx, newRequest("a.com.", A))
+				}
+
+				Expect(sut.Resolve(ctx, newRequest("b.com.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+				Expect(sut.Resolve(ctx, newRequest("a.com.", A))).Should(HaveResponseType(ResponseTypeCACHED))
 			})
 		})
 	})
+
+	Describe("Cache inspection", func() {
+		BeforeEach(func() {
+			var err error
+
+			mockAnswer, err = util.NewMsgWithAnswer("example.com.", 300, A, "1.2.3.4")
+			Expect(err).Should(Succeed())
+		})
+
+		JustBeforeEach(func() {
+			for _, domain := range []string{"example.com.", "www.example.com.", "notexample.com.", "other.org."} {
+				Expect(sut.Resolve(ctx, newRequest(domain, A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+			}
+		})
+
+		It("should search the entries by name", func() {
+			entries := sut.CacheEntries("example.COM.")
+			Expect(entries).Should(HaveLen(3))
+
+			names := make([]string, 0, len(entries))
+			for _, e := range entries {
+				Expect(e.Type).Should(Equal(A))
+				Expect(e.TTL).Should(BeNumerically("~", 300*time.Second, time.Second))
+				Expect(e.Response.Answer).Should(HaveLen(1))
+				Expect(e.Subnet).Should(BeEmpty())
+
+				names = append(names, e.Name)
+			}
+
+			Expect(names).Should(ConsistOf("example.com", "www.example.com", "notexample.com"))
+			Expect(sut.CacheEntries("")).Should(HaveLen(4))
+		})
+
+		It("should remove a domain and its subdomains", func() {
+			Expect(sut.RemoveCacheEntries(ctx, "example.com")).Should(Equal(2))
+
+			Expect(sut.CacheEntries("")).Should(HaveLen(2))
+			Expect(sut.Resolve(ctx, newRequest("www.example.com.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(sut.Resolve(ctx, newRequest("notexample.com.", A))).Should(HaveResponseType(ResponseTypeCACHED))
+		})
+
+		It("should return the stats", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).Should(HaveResponseType(ResponseTypeCACHED))
+
+			stats := sut.CacheStats()
+			Expect(stats.Entries).Should(Equal(4))
+			Expect(stats.MemoryBytes).Should(BeNumerically(">", 4*mockAnswer.Len()))
+			Expect(stats.Buckets).Should(HaveLen(4))
+
+			// TTL of 300s is in the bucket from 1 to 10 minutes
+			Expect(stats.Buckets[1].MinTTL).Should(Equal(time.Minute))
+			Expect(stats.Buckets[1].MaxTTL).Should(Equal(10 * time.Minute))
+			Expect(stats.Buckets[1].Hits).Should(BeNumerically("==", 1))
+			Expect(stats.Buckets[1].Misses).Should(BeNumerically("==", 4))
+
+			Expect(stats.Buckets[0].Hits + stats.Buckets[0].Misses).Should(BeZero())
+			Expect(stats.Buckets[3].MinTTL).Should(Equal(time.Hour))
+			Expect(stats.Buckets[3].MaxTTL).Should(BeZero())
+		})
+	})
 })
@@ -3,13 +3,19 @@ package resolver
 import (
 	"context"
 	"net"
+	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/0xERR0R/blocky/cache/expirationcache"
+	"github.com/0xERR0R/blocky/clientnames"
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/leases"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/neighbors"
 	"github.com/0xERR0R/blocky/util"
 
 	"github.com/miekg/dns"
@@ -24,6 +30,24 @@ type ClientNamesResolver struct {
 
 	cache            expirationcache.ExpiringCache[[]string]
 	externalResolver Resolver
+	leases           *leases.Store
+	external         *clientnames.Store
+	neighbors        macLookup
+
+	// lookups in progress, by client IP
+	lookupsLock sync.Mutex
+	lookups     map[string]*clientNamesLookup
+}
+
+// clientNamesLookup resolves the names of a client IP, queries of the client wait for it until done is closed
+type clientNamesLookup struct {
+	done  chan struct{}
+	names []string
+}
+
+// macLookup returns the hardware address of an IP address in the local network
+type macLookup interface {
+	Lookup(ip net.IP) (net.HardwareAddr, bool)
 }
 
 // NewClientNamesResolver creates new resolver instance
@@ -44,8 +68,32 @@ func NewClientNamesResolver(ctx context.Context,
 
 		cache: expirationcache.NewCache[[]string](ctx, expirationcache.Options{
 			CleanupInterval: time.Hour,
+			MaxSize:         cfg.Cache.MaxItemsCount,
 		}),
 		externalResolver: r,
+		lookups:          make(map[string]*clientNamesLookup),
+	}
+
+	if cfg.Leases.IsEnabled() {
+		cr.leases = leases.NewStore(cfg.Leases, &http.Client{
+			Transport: bootstrap.NewHTTPTransport(),
+			Timeout:   upstreamsCfg.Timeout.ToDuration(),
+		})
+
+		// names resolved from the previous leases are outdated
+		cr.leases.Start(ctx, cr.FlushCache)
+	}
+
+	if cfg.External.IsEnabled() {
+		cr.external = clientnames.NewStore(cfg.External, &http.Client{
+			Transport: bootstrap.NewHTTPTransport(),
+		})
+
+		cr.external.Start(ctx, cr.FlushCache)
+	}
+
+	if cfg.MACLookup {
+		cr.neighbors = neighbors.NewTable()
 	}
 
 	return
@@ -56,6 +104,14 @@ func (r *ClientNamesResolver) LogConfig(logger *logrus.Entry) {
 	r.cfg.LogConfig(logger)
 
 	logger.Infof("cache entries = %d", r.cache.TotalCount())
+
+	if r.leases != nil {
+		logger.Infof("DHCP leases = %d", r.leases.Count())
+	}
+
+	if r.external != nil {
+		logger.Infof("external clients = %d", r.external.Count())
+	}
 }
 
 // Resolve tries to resolve the client name from the ip address
@@ -65,6 +121,14 @@ func (r *ClientNamesResolver) Resolve(ctx context.Context, request *model.Reques
 	request.ClientNames = clientNames
 	ctx, _ = log.CtxWithFields(ctx, logrus.Fields{"client_names": strings.Join(clientNames, "; ")})
 
+	if r.neighbors != nil && request.ClientIP != nil {
+		// not cached with the names: the IP address can move to another device
+		if mac, ok := r.neighbors.Lookup(request.ClientIP); ok {
+			request.ClientMAC = mac
+			ctx, _ = log.CtxWithFields(ctx, logrus.Fields{"client_mac": mac.String()})
+		}
+	}
+
 	return r.next.Resolve(ctx, request)
 }
 
@@ -79,20 +143,67 @@ func (r *ClientNamesResolver) getClientNames(ctx context.Context, request *model
 		return []string{}
 	}
 
-	c, _ := r.cache.Get(ip.String())
+	c, ttl := r.cache.Get(ip.String())
 	if c != nil {
+		if ttl <= 0 {
+			// expired: keep using the names until the lookup updated them
+			r.lookup(ctx, ip)
+		}
+
 		// return copy here, since we can't control all usages here
-		cpy := make([]string, len(*c))
-		copy(cpy, *c)
+		return slices.Clone(*c)
+	}
+
+	lookup := r.lookup(ctx, ip)
+
+	wait := time.NewTimer(r.cfg.MaxWait.ToDuration())
+	defer wait.Stop()
+
+	select {
+	case <-lookup.done:
+		return slices.Clone(lookup.names)
+
+	case <-wait.C:
+	case <-ctx.Done():
+	}
+
+	_, logger := r.log(ctx)
+	logger.Debugf("client name lookup takes longer than %s, continuing in background", r.cfg.MaxWait)
+
+	return []string{ip.String()}
+}
+
+// lookup starts resolving the names of the client IP in the background, unless it's already in progress
+func (r *ClientNamesResolver) lookup(ctx context.Context, ip net.IP) *clientNamesLookup {
+	key := ip.String()
 
-		return cpy
+	r.lookupsLock.Lock()
+	defer r.lookupsLock.Unlock()
+
+	if lookup, ok := r.lookups[key]; ok {
+		return lookup
 	}
 
-	names := r.resolveClientNames(ctx, ip)
+	lookup := &clientNamesLookup{done: make(chan struct{})}
+	r.lookups[key] = lookup
+
+	// the query can be done before the lookup
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		names, ttl := r.resolveClientNames(ctx, ip)
+
+		r.cache.Put(key, &names, ttl)
 
-	r.cache.Put(ip.String(), &names, time.Hour)
+		r.lookupsLock.Lock()
+		delete(r.lookups, key)
+		r.lookupsLock.Unlock()
 
-	return names
+		lookup.names = names
+		close(lookup.done)
+	}()
+
+	return lookup
 }
 
 func extractClientNamesFromAnswer(answer []dns.RR, fallbackIP net.IP) (clientNames []string) {
@@ -110,20 +221,81 @@ func extractClientNamesFromAnswer(answer []dns.RR, fallbackIP net.IP) (clientNam
 	return
 }
 
-// tries to resolve client name from mapping, performs reverse DNS lookup otherwise
-func (r *ClientNamesResolver) resolveClientNames(ctx context.Context, ip net.IP) (result []string) {
-	ctx, logger := r.log(ctx)
+// minPTRTTL returns the lowest TTL of the PTR records in the answer, false if there is none
+func minPTRTTL(answer []dns.RR) (ttl time.Duration, found bool) {
+	for _, rr := range answer {
+		if t, ok := rr.(*dns.PTR); ok {
+			rrTTL := time.Duration(t.Hdr.Ttl) * time.Second
+
+			if !found || rrTTL < ttl {
+				ttl = rrTTL
+			}
+
+			found = true
+		}
+	}
+
+	return ttl, found
+}
+
+// tries to resolve client name from mapping, external sources and DHCP leases, performs reverse DNS lookup otherwise.
+// The groups of the client in the external sources are added to the names.
+// Returns the names and how long they can be cached.
+func (r *ClientNamesResolver) resolveClientNames(ctx context.Context, ip net.IP) ([]string, time.Duration) {
+	maxTime := r.cfg.Cache.MaxTime.ToDuration()
 
 	// try client mapping first
-	result = r.getNameFromIPMapping(ip, result)
-	if len(result) > 0 {
-		return result
+	if result := r.getNameFromIPMapping(ip, nil); len(result) > 0 {
+		return result, maxTime
+	}
+
+	var (
+		client clientnames.Client
+		ok     bool
+	)
+
+	if r.external != nil {
+		client, ok = r.external.Lookup(ip)
+	}
+
+	if !ok {
+		return r.lookupClientNames(ctx, ip)
+	}
+
+	_, logger := r.log(ctx)
+	logger.WithField("client_names", strings.Join(client.Names(), "; ")).Debug("resolved client name(s) from external source")
+
+	if client.Name != "" {
+		return client.Names(), maxTime
+	}
+
+	names, ttl := r.lookupClientNames(ctx, ip)
+
+	return append(names, client.Groups...), ttl
+}
+
+// resolves the client name from DHCP leases, performs reverse DNS lookup otherwise
+func (r *ClientNamesResolver) lookupClientNames(ctx context.Context, ip net.IP) ([]string, time.Duration) {
+	ctx, logger := r.log(ctx)
+
+	maxTime := r.cfg.Cache.MaxTime.ToDuration()
+
+	if r.leases != nil {
+		if lease, ok := r.leases.Lookup(ip); ok && len(lease.Names()) > 0 {
+			result := lease.Names()
+
+			logger.WithField("client_names", strings.Join(result, "; ")).Debug("resolved client name(s) from DHCP lease")
+
+			return result, maxTime
+		}
 	}
 
 	if r.externalResolver == nil {
-		return []string{ip.String()}
+		return []string{ip.String()}, maxTime
 	}
 
+	negativeTime := r.cfg.Cache.NegativeTime.ToDuration()
+
 	reverse, _ := dns.ReverseAddr(ip.String())
 
 	resp, err := r.externalResolver.Resolve(ctx, &model.Request{
@@ -132,11 +304,20 @@ func (r *ClientNamesResolver) resolveClientNames(ctx context.Context, ip net.IP)
 	if err != nil {
 		logger.Error("can't resolve client name: ", err)
 
-		return []string{ip.String()}
+		return []string{ip.String()}, negativeTime
 	}
 
 	clientNames := extractClientNamesFromAnswer(resp.Res.Answer, ip)
 
+	ttl, found := minPTRTTL(resp.Res.Answer)
+	if !found {
+		return clientNames, negativeTime
+	}
+
+	ttl = min(max(ttl, r.cfg.Cache.MinTime.ToDuration()), maxTime)
+
+	var result []string
+
 	// optional: if singleNameOrder is set, use only one name in the defined order
 	if len(r.cfg.SingleNameOrder) > 0 {
 		for _, i := range r.cfg.SingleNameOrder {
@@ -152,7 +333,7 @@ func (r *ClientNamesResolver) resolveClientNames(ctx context.Context, ip net.IP)
 
 	logger.WithField("client_names", strings.Join(result, "; ")).Debug("resolved client name(s) from external resolver")
 
-	return result
+	return result, ttl
 }
 
 func (r *ClientNamesResolver) getNameFromIPMapping(ip net.IP, result []string) []string {
@@ -4,9 +4,15 @@ import (
 	"context"
 	"errors"
 	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
 
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/creasty/defaults"
 
 	. "github.com/0xERR0R/blocky/helpertest"
 	. "github.com/0xERR0R/blocky/model"
@@ -39,6 +45,8 @@ var _ = Describe("ClientResolver", Label("clientNamesResolver"), func() {
 		ctx, cancelFn = context.WithCancel(context.Background())
 		DeferCleanup(cancelFn)
 
+		Expect(defaults.Set(&sutConfig)).Should(Succeed())
+
 		sut, err = NewClientNamesResolver(ctx, sutConfig, defaultUpstreamsConfig, nil)
 		Expect(err).Should(Succeed())
 		m = &mockResolver{}
@@ -148,6 +156,149 @@ var _ = Describe("ClientResolver", Label("clientNamesResolver"), func() {
 		})
 	})
 
+	Describe("Resolve client name from DHCP leases", func() {
+		var leaseFile *TmpFile
+
+		BeforeEach(func() {
+			tmpDir := NewTmpFolder("leases")
+			leaseFile = tmpDir.CreateStringFile("dnsmasq.leases",
+				"0 aa:bb:cc:dd:ee:01 192.168.1.10 laptop *",
+				"0 aa:bb:cc:dd:ee:02 192.168.1.11 * *",
+			)
+
+			sutConfig = config.ClientLookup{
+				ClientnameIPMapping: map[string][]net.IP{
+					"mapped": {net.ParseIP("192.168.1.11")},
+				},
+				Leases: config.DHCPLeases{
+					Sources: []config.DHCPLeaseSource{
+						{Format: config.DHCPLeaseFormatDnsmasq, Source: leaseFile.Path},
+					},
+					CheckInterval: config.Duration(time.Hour),
+				},
+			}
+		})
+
+		It("should log the number of leases", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElement("DHCP leases = 2"))
+		})
+
+		It("should use the hostname and MAC address of the lease", func() {
+			request := newRequestWithClient("google.de.", dns.Type(dns.TypeA), "192.168.1.10")
+			Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(request.ClientNames).Should(Equal([]string{"laptop", "aa:bb:cc:dd:ee:01"}))
+		})
+
+		It("should prefer the custom name mapping", func() {
+			request := newRequestWithClient("google.de.", dns.Type(dns.TypeA), "192.168.1.11")
+			Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(request.ClientNames).Should(ConsistOf("mapped"))
+		})
+
+		It("should use IP as fallback without lease", func() {
+			request := newRequestWithClient("google.de.", dns.Type(dns.TypeA), "192.168.1.12")
+			Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(request.ClientNames).Should(ConsistOf("192.168.1.12"))
+		})
+	})
+
+	Describe("Resolve client name from external sources", func() {
+		BeforeEach(func() {
+			script := filepath.Join(GinkgoT().TempDir(), "clients.sh")
+			Expect(os.WriteFile(script, []byte(`#!/bin/sh
+echo '{
+  "192.168.1.10": {"name": "tv", "groups": ["iot"]},
+  "192.168.1.11": {"groups": ["kids"]},
+  "192.168.1.12": {"name": "other"}
+}'
+`), 0o700)).Should(Succeed())
+
+			tmpDir := NewTmpFolder("leases")
+			leaseFile := tmpDir.CreateStringFile("dnsmasq.leases",
+				"0 aa:bb:cc:dd:ee:01 192.168.1.10 laptop *",
+				"0 aa:bb:cc:dd:ee:02 192.168.1.11 tablet *",
+			)
+
+			sutConfig = config.ClientLookup{
+				ClientnameIPMapping: map[string][]net.IP{
+					"mapped": {net.ParseIP("192.168.1.12")},
+				},
+				Leases: config.DHCPLeases{
+					Sources: []config.DHCPLeaseSource{
+						{Format: config.DHCPLeaseFormatDnsmasq, Source: leaseFile.Path},
+					},
+					CheckInterval: config.Duration(time.Hour),
+				},
+				External: config.ExternalClientNames{
+					Sources:       []config.ExternalClientNamesSource{{Command: script}},
+					RefreshPeriod: config.Duration(time.Hour),
+					Timeout:       config.Duration(time.Second),
+				},
+			}
+		})
+
+		resolveNames := func(ip string) []string {
+			request := newRequestWithClient("google.de.", dns.Type(dns.TypeA), ip)
+			Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			return request.ClientNames
+		}
+
+		It("should log the number of clients", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Messages).Should(ContainElement("external clients = 3"))
+		})
+
+		It("should use the name and the groups of the client", func() {
+			Expect(resolveNames("192.168.1.10")).Should(Equal([]string{"tv", "iot"}))
+		})
+
+		It("should add the groups to the names of the lease", func() {
+			Expect(resolveNames("192.168.1.11")).Should(Equal([]string{"tablet", "aa:bb:cc:dd:ee:02", "kids"}))
+		})
+
+		It("should prefer the custom name mapping", func() {
+			Expect(resolveNames("192.168.1.12")).Should(ConsistOf("mapped"))
+		})
+	})
+
+	Describe("Resolve client MAC address", func() {
+		BeforeEach(func() {
+			sutConfig = config.ClientLookup{MACLookup: true}
+		})
+
+		JustBeforeEach(func() {
+			Expect(sut.neighbors).ShouldNot(BeNil())
+
+			sut.neighbors = staticNeighbors{"192.168.1.10": {0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0x01}}
+		})
+
+		It("should set the MAC address of a known client", func() {
+			request := newRequestWithClient("google.de.", dns.Type(dns.TypeA), "192.168.1.10")
+			Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(request.ClientMAC.String()).Should(Equal("aa:bb:cc:dd:ee:01"))
+			Expect(request.ClientNames).Should(ConsistOf("192.168.1.10"))
+		})
+
+		It("should not set the MAC address of an unknown client", func() {
+			request := newRequestWithClient("google.de.", dns.Type(dns.TypeA), "192.168.1.11")
+			Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(request.ClientMAC).Should(BeNil())
+		})
+	})
+
 	Describe("Resolve client name via rDNS lookup", func() {
 		var testUpstream *MockUDPUpstreamServer
 
@@ -308,6 +459,124 @@ var _ = Describe("ClientResolver", Label("clientNamesResolver"), func() {
 			})
 		})
 
+		Context("Caching", func() {
+			const clientIP = "192.168.178.25"
+
+			type answerFunc func(request *dns.Msg) *dns.Msg
+
+			// the upstream of a spec only uses its own answer as lookups may outlive the spec
+			var answerFn *atomic.Pointer[answerFunc]
+
+			setAnswerFn := func(fn answerFunc) {
+				answerFn.Store(&fn)
+			}
+
+			resolveNames := func() []string {
+				request := newRequestWithClient("google.de.", dns.Type(dns.TypeA), clientIP)
+				Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				return request.ClientNames
+			}
+
+			BeforeEach(func() {
+				answer := new(atomic.Pointer[answerFunc])
+				answerFn = answer
+
+				setAnswerFn(func(request *dns.Msg) *dns.Msg {
+					response, err := util.NewMsgWithAnswer("25.178.168.192.in-addr.arpa.", 600, PTR, "host1")
+					Expect(err).Should(Succeed())
+
+					return response
+				})
+
+				testUpstream = NewMockUDPUpstreamServer().WithAnswerFn(func(request *dns.Msg) *dns.Msg {
+					return (*answer.Load())(request)
+				})
+
+				sutConfig = config.ClientLookup{Upstream: testUpstream.Start()}
+			})
+
+			JustBeforeEach(func() {
+				// Don't count the resolver test
+				testUpstream.ResetCallCount()
+			})
+
+			It("should cache the names with the TTL of the PTR records", func() {
+				Expect(resolveNames()).Should(ConsistOf("host1"))
+
+				_, ttl := sut.cache.Get(clientIP)
+				Expect(ttl).Should(BeNumerically("~", 600*time.Second, time.Second))
+			})
+
+			When("the TTL is out of the bounds", func() {
+				BeforeEach(func() {
+					sutConfig.Cache.MinTime = config.Duration(20 * time.Minute)
+				})
+
+				It("should use the bounds", func() {
+					Expect(resolveNames()).Should(ConsistOf("host1"))
+
+					_, ttl := sut.cache.Get(clientIP)
+					Expect(ttl).Should(BeNumerically("~", 20*time.Minute, time.Second))
+				})
+			})
+
+			When("the client has no name", func() {
+				BeforeEach(func() {
+					setAnswerFn(func(request *dns.Msg) *dns.Msg {
+						return new(dns.Msg).SetRcode(request, dns.RcodeNameError)
+					})
+				})
+
+				It("should cache the failure for the negative time", func() {
+					Expect(resolveNames()).Should(ConsistOf(clientIP))
+					Expect(resolveNames()).Should(ConsistOf(clientIP))
+
+					Expect(testUpstream.GetCallCount()).Should(Equal(1))
+
+					_, ttl := sut.cache.Get(clientIP)
+					Expect(ttl).Should(BeNumerically("~", 5*time.Minute, time.Second))
+				})
+			})
+
+			When("the lookup is slow", func() {
+				BeforeEach(func() {
+					answer := *answerFn.Load()
+					setAnswerFn(func(request *dns.Msg) *dns.Msg {
+						time.Sleep(30 * time.Millisecond)
+
+						return answer(request)
+					})
+
+					sutConfig.MaxWait = config.Duration(time.Millisecond)
+				})
+
+				It("should use the IP until the lookup is done", func() {
+					start := time.Now()
+
+					Expect(resolveNames()).Should(ConsistOf(clientIP))
+					Expect(resolveNames()).Should(ConsistOf(clientIP))
+					Expect(time.Since(start)).Should(BeNumerically("<", 30*time.Millisecond))
+
+					Eventually(resolveNames).Should(ConsistOf("host1"))
+					Expect(testUpstream.GetCallCount()).Should(Equal(1))
+				})
+			})
+
+			When("the names are expired", func() {
+				It("should use them until they are updated", func() {
+					Expect(resolveNames()).Should(ConsistOf("host1"))
+
+					sut.cache.Put(clientIP, &[]string{"old"}, time.Millisecond)
+					time.Sleep(5 * time.Millisecond)
+
+					Expect(resolveNames()).Should(ConsistOf("old"))
+					Eventually(resolveNames).Should(ConsistOf("host1"))
+					Expect(testUpstream.GetCallCount()).Should(Equal(2))
+				})
+			})
+		})
+
 		Context("Error cases", func() {
 			When("Upstream can't resolve client name via rDNS", func() {
 				BeforeEach(func() {
@@ -409,3 +678,11 @@ var _ = Describe("ClientResolver", Label("clientNamesResolver"), func() {
 		})
 	})
 })
+
+type staticNeighbors map[string]net.HardwareAddr
+
+func (n staticNeighbors) Lookup(ip net.IP) (net.HardwareAddr, bool) {
+	mac, ok := n[ip.String()]
+
+	return mac, ok
+}
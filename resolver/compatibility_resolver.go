@@ -0,0 +1,98 @@
+package resolver
+
+import (
+	"context"
+	"slices"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+)
+
+// CompatibilityResolver handles queries using unusual parts of the DNS protocol:
+// multiple questions, unknown EDNS options and unknown classes.
+// It must be first in the chain, the following resolvers only look at the first question.
+type CompatibilityResolver struct {
+	configurable[*config.Compatibility]
+	NextResolver
+	typed
+}
+
+func NewCompatibilityResolver(cfg config.Compatibility) *CompatibilityResolver {
+	return &CompatibilityResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("compatibility"),
+	}
+}
+
+// Resolve implements `Resolver`.
+func (r *CompatibilityResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	if r.cfg.UnknownClasses == config.CompatibilityActionRefuse && hasUnknownClass(request.Req) {
+		logger.Debug("refusing query with unknown class")
+
+		return refuse(request, dns.RcodeRefused, "UNKNOWN CLASS"), nil
+	}
+
+	if len(request.Req.Question) > 1 {
+		switch r.cfg.MultipleQuestions {
+		case config.CompatibilityActionRefuse:
+			logger.Debug("refusing query with multiple questions")
+
+			// RFC 9619: a query with more than one question is a format error
+			return refuse(request, dns.RcodeFormatError, "MULTIPLE QUESTIONS"), nil
+
+		case config.CompatibilityActionStrip:
+			logger.Debugf("removing %d additional questions", len(request.Req.Question)-1)
+
+			request.Req.Question = request.Req.Question[:1]
+
+		case config.CompatibilityActionForward:
+		}
+	}
+
+	if opt := request.Req.IsEdns0(); opt != nil && slices.ContainsFunc(opt.Option, isUnknownEdns0Option) {
+		switch r.cfg.UnknownEDNSOptions {
+		case config.CompatibilityActionRefuse:
+			logger.Debug("refusing query with unknown EDNS options")
+
+			return refuse(request, dns.RcodeRefused, "UNKNOWN EDNS OPTION"), nil
+
+		case config.CompatibilityActionStrip:
+			logger.Debug("removing unknown EDNS options")
+
+			opt.Option = slices.DeleteFunc(opt.Option, isUnknownEdns0Option)
+
+		case config.CompatibilityActionForward:
+		}
+	}
+
+	return r.next.Resolve(ctx, request)
+}
+
+func refuse(request *model.Request, rcode int, reason string) *model.Response {
+	response := new(dns.Msg)
+	response.SetRcode(request.Req, rcode)
+
+	return &model.Response{Res: response, RType: model.ResponseTypeFILTERED, Reason: reason}
+}
+
+func hasUnknownClass(msg *dns.Msg) bool {
+	return slices.ContainsFunc(msg.Question, func(q dns.Question) bool {
+		_, known := dns.ClassToString[q.Qclass]
+
+		return !known
+	})
+}
+
+// isUnknownEdns0Option returns true for options miekg/dns has no type for: they are kept as raw data
+func isUnknownEdns0Option(option dns.EDNS0) bool {
+	_, unknown := option.(*dns.EDNS0_LOCAL)
+
+	return unknown
+}
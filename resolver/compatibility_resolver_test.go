@@ -0,0 +1,225 @@
+package resolver
+
+import (
+	"context"
+	"net"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("CompatibilityResolver", func() {
+	var (
+		sut        *CompatibilityResolver
+		sutConfig  config.Compatibility
+		m          *mockResolver
+		mockAnswer *dns.Msg
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		var err error
+
+		sutConfig, err = config.WithDefaults[config.Compatibility]()
+		Expect(err).Should(Succeed())
+
+		mockAnswer = new(dns.Msg)
+	})
+
+	JustBeforeEach(func() {
+		sut = NewCompatibilityResolver(sutConfig)
+		m = &mockResolver{}
+		m.On("Resolve", mock.Anything).Return(&Response{Res: mockAnswer}, nil)
+		sut.Next(m)
+	})
+
+	ecsOption := func() *dns.EDNS0_SUBNET {
+		return newEdnsSubnetOption(net.ParseIP("192.0.2.1"), ecsFamilyIPv4, config.ECSv4Mask(24))
+	}
+
+	// request with 2 questions and an unknown EDNS option next to ECS
+	unusualRequest := func() *Request {
+		request := newRequest("example.com.", A)
+		request.Req.Question = append(request.Req.Question, dns.Question{
+			Name: "example.org.", Qtype: dns.TypeA, Qclass: dns.ClassINET,
+		})
+
+		util.SetEdns0Option(request.Req, ecsOption())
+		util.SetEdns0Option(request.Req, &dns.EDNS0_LOCAL{Code: dns.EDNS0LOCALSTART, Data: []byte{1, 2, 3}})
+
+		return request
+	}
+
+	Describe("IsEnabled", func() {
+		It("is false by default", func() {
+			Expect(sut.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	When("all actions are forward", func() {
+		It("should forward the request verbatim", func() {
+			request := unusualRequest()
+			expected := request.Req.String()
+
+			Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(m.Calls).Should(HaveLen(1))
+			Expect(request.Req.String()).Should(Equal(expected))
+		})
+	})
+
+	Describe("multiple questions", func() {
+		When("action is strip", func() {
+			BeforeEach(func() {
+				sutConfig.MultipleQuestions = config.CompatibilityActionStrip
+			})
+
+			It("should only resolve the first question", func() {
+				request := unusualRequest()
+
+				Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(m.Calls).Should(HaveLen(1))
+				Expect(request.Req.Question).Should(HaveLen(1))
+				Expect(request.Req.Question[0].Name).Should(Equal("example.com."))
+			})
+
+			It("should not change queries with one question", func() {
+				request := newRequest("example.com.", A)
+				expected := request.Req.String()
+
+				Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(request.Req.String()).Should(Equal(expected))
+			})
+		})
+
+		When("action is refuse", func() {
+			BeforeEach(func() {
+				sutConfig.MultipleQuestions = config.CompatibilityActionRefuse
+			})
+
+			It("should answer with FORMERR", func() {
+				Expect(sut.Resolve(ctx, unusualRequest())).
+					Should(SatisfyAll(
+						HaveNoAnswer(),
+						HaveResponseType(ResponseTypeFILTERED),
+						HaveReason("MULTIPLE QUESTIONS"),
+						HaveReturnCode(dns.RcodeFormatError),
+					))
+
+				Expect(m.Calls).Should(BeEmpty())
+			})
+
+			It("should resolve queries with one question", func() {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(m.Calls).Should(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("unknown EDNS options", func() {
+		When("action is strip", func() {
+			BeforeEach(func() {
+				sutConfig.UnknownEDNSOptions = config.CompatibilityActionStrip
+			})
+
+			It("should only remove the unknown options", func() {
+				request := unusualRequest()
+
+				Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(m.Calls).Should(HaveLen(1))
+				Expect(request.Req.IsEdns0().Option).Should(ConsistOf(BeAssignableToTypeOf(&dns.EDNS0_SUBNET{})))
+				Expect(request.Req.Question).Should(HaveLen(2))
+			})
+		})
+
+		When("action is refuse", func() {
+			BeforeEach(func() {
+				sutConfig.UnknownEDNSOptions = config.CompatibilityActionRefuse
+			})
+
+			It("should answer with REFUSED", func() {
+				Expect(sut.Resolve(ctx, unusualRequest())).
+					Should(SatisfyAll(
+						HaveResponseType(ResponseTypeFILTERED),
+						HaveReason("UNKNOWN EDNS OPTION"),
+						HaveReturnCode(dns.RcodeRefused),
+					))
+
+				Expect(m.Calls).Should(BeEmpty())
+			})
+
+			It("should resolve queries with known options only", func() {
+				request := newRequest("example.com.", A)
+				util.SetEdns0Option(request.Req, ecsOption())
+
+				Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(m.Calls).Should(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("unknown classes", func() {
+		When("action is refuse", func() {
+			BeforeEach(func() {
+				sutConfig.UnknownClasses = config.CompatibilityActionRefuse
+			})
+
+			It("should answer with REFUSED", func() {
+				request := newRequest("example.com.", A)
+				request.Req.Question[0].Qclass = 42
+
+				Expect(sut.Resolve(ctx, request)).
+					Should(SatisfyAll(
+						HaveResponseType(ResponseTypeFILTERED),
+						HaveReason("UNKNOWN CLASS"),
+						HaveReturnCode(dns.RcodeRefused),
+					))
+
+				Expect(m.Calls).Should(BeEmpty())
+			})
+
+			It("should resolve queries with known classes", func() {
+				request := newRequest("version.bind.", TXT)
+				request.Req.Question[0].Qclass = dns.ClassCHAOS
+
+				Expect(sut.Resolve(ctx, request)).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(m.Calls).Should(HaveLen(1))
+			})
+		})
+	})
+})
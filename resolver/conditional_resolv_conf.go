@@ -0,0 +1,166 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	"github.com/miekg/dns"
+)
+
+// resolvConfZones are the mappings of the search domains of a resolv.conf to its name servers
+type resolvConfZones struct {
+	mapping map[string]Resolver
+	// servers and search domains the mappings were created from, to detect changes
+	state string
+	// stops the health checks of the resolvers
+	cancel context.CancelFunc
+}
+
+// resolvConf is the content of a resolv.conf relevant for the mappings
+type resolvConf struct {
+	servers []config.Upstream
+	domains []string
+}
+
+func (c *resolvConf) String() string {
+	return fmt.Sprintf("%v -> %v", c.domains, c.servers)
+}
+
+// readResolvConf returns the name servers and search domains of the file,
+// loopback servers are skipped as they are usually blocky itself or a local stub resolver
+func readResolvConf(path string) (*resolvConf, error) {
+	cc, err := dns.ClientConfigFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read %s: %w", path, err)
+	}
+
+	port, err := strconv.ParseUint(cc.Port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %s in %s: %w", cc.Port, path, err)
+	}
+
+	res := &resolvConf{}
+
+	for _, server := range cc.Servers {
+		ip := net.ParseIP(server)
+		if ip == nil || ip.IsLoopback() {
+			continue
+		}
+
+		res.servers = append(res.servers, config.Upstream{
+			Net:  config.NetProtocolTcpUdp,
+			Host: ip.String(),
+			Port: uint16(port),
+		})
+	}
+
+	for _, domain := range cc.Search {
+		domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+		if domain != "" && !slices.Contains(res.domains, domain) {
+			res.domains = append(res.domains, domain)
+		}
+	}
+
+	return res, nil
+}
+
+// watchResolvConf creates the mappings of the resolv.conf and recreates them if its content changes, until ctx is done
+func (r *ConditionalUpstreamResolver) watchResolvConf(
+	ctx context.Context, upstreamsCfg config.Upstreams, bootstrap *Bootstrap,
+) {
+	r.updateResolvConfZones(ctx, upstreamsCfg, bootstrap)
+
+	if !r.cfg.ResolvConf.CheckPeriod.IsAboveZero() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.cfg.ResolvConf.CheckPeriod.ToDuration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.updateResolvConfZones(ctx, upstreamsCfg, bootstrap)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// updateResolvConfZones reads the resolv.conf and replaces the mappings if its content changed
+func (r *ConditionalUpstreamResolver) updateResolvConfZones(
+	ctx context.Context, upstreamsCfg config.Upstreams, bootstrap *Bootstrap,
+) {
+	ctx, logger := r.log(ctx)
+
+	conf, err := readResolvConf(r.cfg.ResolvConf.File)
+	if err != nil {
+		logger.Warn("can't update the mappings of the resolv.conf: ", err)
+
+		return
+	}
+
+	current := r.resolvConfZones.Load()
+	if current != nil && current.state == conf.String() {
+		return
+	}
+
+	zonesCtx, cancel := context.WithCancel(ctx)
+	zones := &resolvConfZones{
+		mapping: make(map[string]Resolver, len(conf.domains)),
+		state:   conf.String(),
+		cancel:  cancel,
+	}
+
+	if len(conf.servers) != 0 {
+		resolver, err := newConditionalGroupResolver(zonesCtx, *r.cfg, "resolv.conf", upstreamsCfg, conf.servers, bootstrap)
+		if err != nil {
+			cancel()
+			logger.Warn("can't create the resolver of the resolv.conf name servers: ", err)
+
+			return
+		}
+
+		for _, domain := range conf.domains {
+			zones.mapping[domain] = resolver
+		}
+	}
+
+	r.resolvConfZones.Store(zones)
+
+	if current != nil {
+		current.cancel()
+	}
+
+	logger.Infof("forwarding the search domains of %s: %s", r.cfg.ResolvConf.File, conf)
+}
+
+// resolvConfResolver returns the resolver of the search domain which is the domain or one of its parents
+func (r *ConditionalUpstreamResolver) resolvConfResolver(domain string) (Resolver, string) {
+	zones := r.resolvConfZones.Load()
+	if zones == nil || len(zones.mapping) == 0 {
+		return nil, ""
+	}
+
+	for {
+		if resolver, ok := zones.mapping[domain]; ok {
+			return resolver, domain
+		}
+
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			return nil, ""
+		}
+
+		domain = parent
+	}
+}
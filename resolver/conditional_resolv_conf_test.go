@@ -0,0 +1,149 @@
+package resolver
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Conditional resolv.conf mappings", Label("conditionalResolver"), func() {
+	var (
+		tmpDir *TmpFolder
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		var cancelFn context.CancelFunc
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		tmpDir = NewTmpFolder("resolvconf")
+		DeferCleanup(tmpDir.Clean)
+	})
+
+	Describe("readResolvConf", func() {
+		It("should return the name servers and search domains", func() {
+			file := tmpDir.CreateStringFile("resolv.conf",
+				"nameserver 127.0.0.53",
+				"nameserver 192.0.2.1",
+				"nameserver ::1",
+				"nameserver 2001:db8::1",
+				"search Corp.Example. lan corp.example")
+
+			conf, err := readResolvConf(file.Path)
+			Expect(err).Should(Succeed())
+			Expect(conf.servers).Should(Equal([]config.Upstream{
+				{Net: config.NetProtocolTcpUdp, Host: "192.0.2.1", Port: 53},
+				{Net: config.NetProtocolTcpUdp, Host: "2001:db8::1", Port: 53},
+			}))
+			Expect(conf.domains).Should(Equal([]string{"corp.example", "lan"}))
+		})
+
+		It("should fail if the file doesn't exist", func() {
+			_, err := readResolvConf(tmpDir.JoinPath("missing"))
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("resolver", func() {
+		var (
+			sut       *ConditionalUpstreamResolver
+			sutConfig config.ConditionalUpstream
+			file      string
+		)
+
+		BeforeEach(func() {
+			file = tmpDir.CreateStringFile("resolv.conf", "nameserver 192.0.2.1", "search corp.example").Path
+
+			sutConfig = config.ConditionalUpstream{
+				ResolvConf: config.ConditionalResolvConf{
+					File:        file,
+					CheckPeriod: config.Duration(10 * time.Millisecond),
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			upstreamsCfg := defaultUpstreamsConfig
+			upstreamsCfg.Init.Strategy = config.InitStrategyFast
+
+			var err error
+
+			sut, err = NewConditionalUpstreamResolver(ctx, sutConfig, upstreamsCfg, systemResolverBootstrap)
+			Expect(err).Should(Succeed())
+		})
+
+		zone := func(domain string) func() string {
+			return func() string {
+				_, zone := sut.resolvConfResolver(domain)
+
+				return zone
+			}
+		}
+
+		It("should map the search domains and their sub-domains", func() {
+			Expect(zone("host.corp.example")()).Should(Equal("corp.example"))
+			Expect(zone("corp.example")()).Should(Equal("corp.example"))
+			Expect(zone("example")()).Should(BeEmpty())
+		})
+
+		It("should update the mappings if the file changes", func() {
+			Expect(os.WriteFile(file, []byte("nameserver 192.0.2.2\nsearch home.example\n"), 0o600)).Should(Succeed())
+
+			Eventually(zone("host.home.example")).Should(Equal("home.example"))
+			Expect(zone("host.corp.example")()).Should(BeEmpty())
+		})
+
+		It("should prefer the configured mapping", func() {
+			m := &mockResolver{}
+			m.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg)}, nil)
+
+			sut.mapping["corp.example"] = m
+
+			Expect(sut.Resolve(ctx, newRequest("host.corp.example.", A))).
+				Should(HaveResponseType(ResponseTypeCONDITIONAL))
+			m.AssertExpectations(GinkgoT())
+		})
+
+		It("should forward the queries of the search domains", func() {
+			m := &mockResolver{}
+			m.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg)}, nil)
+
+			sut.resolvConfZones.Store(&resolvConfZones{
+				mapping: map[string]Resolver{"corp.example": m},
+				cancel:  func() {},
+			})
+
+			next := &mockResolver{}
+			sut.Next(next)
+
+			Expect(sut.Resolve(ctx, newRequest("host.corp.example.", A))).
+				Should(HaveResponseType(ResponseTypeCONDITIONAL))
+			m.AssertExpectations(GinkgoT())
+			Expect(next.Calls).Should(BeEmpty())
+		})
+
+		When("the check period is 0", func() {
+			BeforeEach(func() {
+				sutConfig.ResolvConf.CheckPeriod = 0
+			})
+
+			It("should only read the file on start", func() {
+				Expect(os.WriteFile(file, []byte("nameserver 192.0.2.2\nsearch home.example\n"), 0o600)).Should(Succeed())
+
+				Consistently(zone("host.home.example"), "50ms").Should(BeEmpty())
+				Expect(zone("host.corp.example")()).Should(Equal("corp.example"))
+			})
+		})
+	})
+})
@@ -3,9 +3,13 @@ package resolver
 import (
 	"context"
 	"fmt"
+	"path"
+	"slices"
 	"strings"
+	"sync/atomic"
 
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/lists"
 	"github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/util"
 
@@ -19,37 +23,114 @@ type ConditionalUpstreamResolver struct {
 	NextResolver
 	typed
 
-	mapping map[string]Resolver
+	mapping   map[string]Resolver
+	wildcards []conditionalMatch // longest pattern first
+	lists     []conditionalMatch // sorted by name
+
+	listMatcher lists.Matcher
+	listNames   []string
+
+	resolvConfZones atomic.Pointer[resolvConfZones]
+}
+
+// conditionalMatch is a wildcard pattern or list name with its resolver
+type conditionalMatch struct {
+	key      string
+	resolver Resolver
 }
 
 // NewConditionalUpstreamResolver returns new resolver instance
 func NewConditionalUpstreamResolver(
 	ctx context.Context, cfg config.ConditionalUpstream, upstreamsCfg config.Upstreams, bootstrap *Bootstrap,
 ) (*ConditionalUpstreamResolver, error) {
-	m := make(map[string]Resolver, len(cfg.Mapping.Upstreams))
+	r := ConditionalUpstreamResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("conditional_upstream"),
 
-	for domain, upstreams := range cfg.Mapping.Upstreams {
-		name := fmt.Sprintf("<conditional in %s>", domain)
-		cfg := config.NewUpstreamGroup(name, upstreamsCfg, upstreams)
+		mapping: make(map[string]Resolver, len(cfg.Mapping.Upstreams)),
+	}
 
-		r, err := NewParallelBestResolver(ctx, cfg, bootstrap)
+	for key, upstreams := range cfg.Mapping.Upstreams {
+		resolver, err := newConditionalGroupResolver(ctx, cfg, key, upstreamsCfg, upstreams, bootstrap)
 		if err != nil {
 			return nil, err
 		}
 
-		m[strings.ToLower(domain)] = r
+		switch listName, isList := cfg.ListName(key); {
+		case isList:
+			if _, ok := cfg.Lists[listName]; !ok {
+				return nil, fmt.Errorf("mapping '%s' references unknown list '%s'", key, listName)
+			}
+
+			r.lists = append(r.lists, conditionalMatch{listName, resolver})
+			r.listNames = append(r.listNames, listName)
+
+		case strings.ContainsAny(key, "*?["):
+			if _, err := path.Match(key, ""); err != nil {
+				return nil, fmt.Errorf("invalid wildcard '%s': %w", key, err)
+			}
+
+			r.wildcards = append(r.wildcards, conditionalMatch{strings.ToLower(key), resolver})
+
+		default:
+			r.mapping[strings.ToLower(key)] = resolver
+		}
 	}
 
-	r := ConditionalUpstreamResolver{
-		configurable: withConfig(&cfg),
-		typed:        withType("conditional_upstream"),
+	// the most specific pattern wins
+	slices.SortFunc(r.wildcards, func(a, b conditionalMatch) int {
+		if d := len(b.key) - len(a.key); d != 0 {
+			return d
+		}
 
-		mapping: m,
+		return strings.Compare(a.key, b.key)
+	})
+
+	slices.SortFunc(r.lists, func(a, b conditionalMatch) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	if len(r.lists) != 0 {
+		downloader := lists.NewDownloader(cfg.Loading.Downloads, bootstrap.NewHTTPTransport())
+
+		listMatcher, err := lists.NewListCache(ctx, lists.ListCacheTypeConditional, cfg.Loading, cfg.Lists, downloader, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		r.listMatcher = listMatcher
+	}
+
+	if cfg.ResolvConf.IsEnabled() {
+		r.watchResolvConf(ctx, upstreamsCfg, bootstrap)
 	}
 
 	return &r, nil
 }
 
+// newConditionalGroupResolver returns the resolver of the upstreams of a mapping with the strategy of the mapping,
+// mappings without strategy use the parallel_best resolver
+func newConditionalGroupResolver(
+	ctx context.Context, cfg config.ConditionalUpstream, key string,
+	upstreamsCfg config.Upstreams, upstreams []config.Upstream, bootstrap *Bootstrap,
+) (Resolver, error) {
+	name := fmt.Sprintf("<conditional in %s>", key)
+	groupCfg := config.NewUpstreamGroup(name, upstreamsCfg, upstreams)
+
+	if cfg.HealthCheck.IsEnabled() {
+		groupCfg.HealthCheck = cfg.HealthCheck
+	}
+
+	strategy, ok := cfg.Mapping.Strategies[key]
+	if !ok {
+		return NewParallelBestResolver(ctx, groupCfg, bootstrap)
+	}
+
+	groupCfg.Strategy = strategy
+
+	return newUpstreamGroupResolver(ctx, groupCfg, bootstrap)
+}
+
 func (r *ConditionalUpstreamResolver) processRequest(
 	ctx context.Context, request *model.Request,
 ) (bool, *model.Response, error) {
@@ -77,14 +158,59 @@ func (r *ConditionalUpstreamResolver) processRequest(
 		return true, resp, err
 	}
 
+	if match := r.matchWildcardOrList(domainFromQuestion); match != nil {
+		resp, err := r.internalResolve(ctx, match.resolver, domainFromQuestion, match.key, request)
+
+		return true, resp, err
+	}
+
+	if resolver, zone := r.resolvConfResolver(domainFromQuestion); resolver != nil {
+		resp, err := r.internalResolve(ctx, resolver, domainFromQuestion, zone, request)
+
+		return true, resp, err
+	}
+
 	return false, nil, nil
 }
 
+// matchWildcardOrList returns the first wildcard matching the domain, or else the first list containing the domain
+// or one of its parents
+func (r *ConditionalUpstreamResolver) matchWildcardOrList(domain string) *conditionalMatch {
+	for i, wildcard := range r.wildcards {
+		if ok, _ := path.Match(wildcard.key, domain); ok {
+			return &r.wildcards[i]
+		}
+	}
+
+	if r.listMatcher == nil {
+		return nil
+	}
+
+	for len(domain) > 0 {
+		if groups := r.listMatcher.Match(domain, r.listNames); len(groups) != 0 {
+			for i, list := range r.lists {
+				if slices.Contains(groups, list.key) {
+					return &r.lists[i]
+				}
+			}
+		}
+
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+
+		domain = parent
+	}
+
+	return nil
+}
+
 // Resolve uses the conditional resolver to resolve the query
 func (r *ConditionalUpstreamResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
 	ctx, logger := r.log(ctx)
 
-	if len(r.mapping) > 0 {
+	if len(r.mapping) > 0 || len(r.wildcards) > 0 || len(r.lists) > 0 || r.cfg.ResolvConf.IsEnabled() {
 		resolved, resp, err := r.processRequest(ctx, request)
 		if resolved {
 			return resp, err
@@ -103,6 +229,9 @@ func (r *ConditionalUpstreamResolver) internalResolve(ctx context.Context, reso
 	ctx, logger := r.log(ctx)
 
 	req.Req.Question[0].Name = dns.Fqdn(doFQ)
+
+	r.trace(ctx, "forwarding %s to %s", doFQ, reso)
+
 	response, err := reso.Resolve(ctx, req)
 
 	if err == nil {
@@ -2,6 +2,8 @@ package resolver
 
 import (
 	"context"
+	"net"
+	"time"
 
 	"github.com/0xERR0R/blocky/config"
 	. "github.com/0xERR0R/blocky/helpertest"
@@ -178,6 +180,133 @@ var _ = Describe("ConditionalUpstreamResolver", Label("conditionalResolver"), fu
 			})
 		})
 	})
+	Describe("Wildcard and list conditions", func() {
+		BeforeEach(func() {
+			wildcardUpstream := NewMockUDPUpstreamServer().WithAnswerFn(func(request *dns.Msg) (response *dns.Msg) {
+				response, _ = util.NewMsgWithAnswer(request.Question[0].Name, 100, A, "10.0.0.1")
+
+				return response
+			})
+
+			listUpstream := NewMockUDPUpstreamServer().WithAnswerFn(func(request *dns.Msg) (response *dns.Msg) {
+				response, _ = util.NewMsgWithAnswer(request.Question[0].Name, 100, A, "10.0.0.2")
+
+				return response
+			})
+
+			loading, err := config.WithDefaults[config.SourceLoading]()
+			Expect(err).Should(Succeed())
+
+			sutConfig.Loading = loading
+			sutConfig.Lists = map[string][]config.BytesSource{
+				"China": {config.TextBytesSource("baidu.com", "*.qq.com")},
+			}
+
+			sutConfig.Mapping.Upstreams["*.CORP.*"] = []config.Upstream{wildcardUpstream.Start()}
+			sutConfig.Mapping.Upstreams["*box"] = []config.Upstream{wildcardUpstream.Start()}
+			sutConfig.Mapping.Upstreams["list:China"] = []config.Upstream{listUpstream.Start()}
+		})
+
+		It("should resolve domains matching a wildcard", func() {
+			Expect(sut.Resolve(ctx, newRequest("dev.corp.example.com.", A))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("dev.corp.example.com.", A, "10.0.0.1"),
+						HaveResponseType(ResponseTypeCONDITIONAL),
+					))
+		})
+
+		It("should prefer domain conditions over wildcards", func() {
+			Expect(sut.Resolve(ctx, newRequest("fritz.box.", A))).
+				Should(BeDNSRecord("fritz.box.", A, "123.124.122.122"))
+
+			Expect(sut.Resolve(ctx, newRequest("juke.box.", A))).
+				Should(BeDNSRecord("juke.box.", A, "10.0.0.1"))
+		})
+
+		It("should resolve domains of a list and their sub-domains", func() {
+			Expect(sut.Resolve(ctx, newRequest("baidu.com.", A))).
+				Should(BeDNSRecord("baidu.com.", A, "10.0.0.2"))
+
+			Expect(sut.Resolve(ctx, newRequest("www.baidu.com.", A))).
+				Should(BeDNSRecord("www.baidu.com.", A, "10.0.0.2"))
+
+			Expect(sut.Resolve(ctx, newRequest("im.qq.com.", A))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("im.qq.com.", A, "10.0.0.2"),
+						HaveResponseType(ResponseTypeCONDITIONAL),
+					))
+		})
+
+		It("should delegate other domains to next resolver", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			m.AssertExpectations(GinkgoT())
+		})
+
+		It("should fail for unknown lists", func() {
+			sutConfig.Mapping.Upstreams["list:unknown"] = sutConfig.Mapping.Upstreams["list:China"]
+
+			_, err := NewConditionalUpstreamResolver(ctx, sutConfig, defaultUpstreamsConfig, systemResolverBootstrap)
+			Expect(err).Should(MatchError(ContainSubstring("unknown list 'unknown'")))
+		})
+
+		It("should fail for invalid wildcards", func() {
+			sutConfig.Mapping.Upstreams["[.example.com"] = sutConfig.Mapping.Upstreams["list:China"]
+
+			_, err := NewConditionalUpstreamResolver(ctx, sutConfig, defaultUpstreamsConfig, systemResolverBootstrap)
+			Expect(err).Should(MatchError(ContainSubstring("invalid wildcard")))
+		})
+	})
+
+	Describe("Strategies and health checks", func() {
+		var deadUpstream config.Upstream
+
+		BeforeEach(func() {
+			// a port without listener: queries fail immediately
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).Should(Succeed())
+
+			port := conn.LocalAddr().(*net.UDPAddr).Port
+			Expect(conn.Close()).Should(Succeed())
+
+			deadUpstream = config.Upstream{Net: config.NetProtocolTcpUdp, Host: "127.0.0.1", Port: uint16(port)}
+
+			sutConfig.Mapping.Upstreams["corp.example"] = append(
+				[]config.Upstream{deadUpstream}, sutConfig.Mapping.Upstreams["fritz.box"]...)
+			sutConfig.Mapping.Strategies = map[string]config.UpstreamStrategy{
+				"corp.example": config.UpstreamStrategyStrict,
+			}
+			sutConfig.HealthCheck = config.UpstreamHealthCheck{
+				Interval:         config.Duration(10 * time.Millisecond),
+				Name:             "corp.example",
+				FailureThreshold: 1,
+			}
+		})
+
+		It("should use the strategy of the mapping", func() {
+			Expect(sut.mapping["corp.example"]).Should(BeAssignableToTypeOf(&StrictResolver{}))
+			Expect(sut.mapping["fritz.box"]).Should(BeAssignableToTypeOf(&ParallelBestResolver{}))
+		})
+
+		It("should skip upstreams failing the health checks", func() {
+			strict := sut.mapping["corp.example"].(*StrictResolver)
+
+			Eventually(func() bool {
+				return strict.UpstreamStatus()[0].Healthy
+			}).Should(BeFalse())
+
+			Expect(sut.Resolve(ctx, newRequest("corp.example.", A))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("corp.example.", A, "123.124.122.122"),
+						HaveResponseType(ResponseTypeCONDITIONAL),
+					))
+		})
+	})
+
 	Describe("Delegation to next resolver", func() {
 		When("Query doesn't match defined mapping", func() {
 			It("should delegate to next resolver", func() {
@@ -3,13 +3,20 @@ package resolver
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net"
+	"net/netip"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/containers"
+	"github.com/0xERR0R/blocky/log"
 	"github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/util"
+	"github.com/0xERR0R/blocky/zonefiles"
 
 	"github.com/miekg/dns"
 	"github.com/sirupsen/logrus"
@@ -24,12 +31,40 @@ type CustomDNSResolver struct {
 	typed
 
 	createAnswerFromQuestion createAnswerFunc
-	mapping                  config.CustomDNSMapping
-	reverseAddresses         map[string][]string
+	staticMapping            config.CustomDNSMapping
+	records                  atomic.Pointer[customDNSRecords]
+	selfHostnames            map[string]struct{}
+
+	// mappings of the views and their names in the order the clients are matched
+	viewMappings map[string]config.CustomDNSMapping
+	viewNames    []string
+
+	// records of containers and the other sources by source name
+	dynamicLock    sync.Mutex
+	dynamicRecords map[string]config.CustomDNSMapping
+}
+
+// customDNSRecords are the records of the configuration, of containers and of the other sources
+type customDNSRecords struct {
+	mapping          config.CustomDNSMapping
+	reverseAddresses map[string][]*dns.PTR
+
+	// records of the views by view name
+	views map[string]*customDNSRecords
+}
+
+// CustomDNSSource provides records to the custom DNS resolver which change at runtime
+type CustomDNSSource interface {
+	// Name of the source for log messages
+	Name() string
+	// OnChange registers the function receiving all records of the source after each change
+	OnChange(onChange func(config.CustomDNSMapping))
 }
 
-// NewCustomDNSResolver creates new resolver instance
-func NewCustomDNSResolver(cfg config.CustomDNS) *CustomDNSResolver {
+// NewCustomDNSResolver creates new resolver instance, `sources` provide records in addition to the configured ones
+func NewCustomDNSResolver(
+	ctx context.Context, cfg config.CustomDNS, sources ...CustomDNSSource,
+) (*CustomDNSResolver, error) {
 	dnsRecords := make(config.CustomDNSMapping, len(cfg.Mapping)+len(cfg.Zone.RRs))
 
 	for url, entries := range cfg.Mapping {
@@ -46,34 +81,210 @@ func NewCustomDNSResolver(cfg config.CustomDNS) *CustomDNSResolver {
 		dnsRecords[url] = entries
 	}
 
-	reverse := make(map[string][]string, len(dnsRecords))
+	viewMappings := make(map[string]config.CustomDNSMapping, len(cfg.Views))
+	viewNames := make([]string, 0, len(cfg.Views))
 
-	for url, entries := range dnsRecords {
-		for _, entry := range entries {
-			a, isA := entry.(*dns.A)
-
-			if isA {
-				r, _ := dns.ReverseAddr(a.A.String())
-				reverse[r] = append(reverse[r], url)
-			}
+	for name, view := range cfg.Views {
+		mapping := make(config.CustomDNSMapping, len(view.Mapping))
 
-			aaaa, isAAAA := entry.(*dns.AAAA)
+		for url, entries := range view.Mapping {
+			mapping[util.ExtractDomainOnly(url)] = entries
 
-			if isAAAA {
-				r, _ := dns.ReverseAddr(aaaa.AAAA.String())
-				reverse[r] = append(reverse[r], url)
+			for _, entry := range entries {
+				entry.Header().Ttl = cfg.CustomTTL.SecondsU32()
 			}
 		}
+
+		viewMappings[name] = mapping
+		viewNames = append(viewNames, name)
+	}
+
+	slices.Sort(viewNames)
+
+	self := make(map[string]struct{}, len(cfg.SelfHostnames))
+	for _, name := range cfg.SelfHostnames {
+		self[util.ExtractDomainOnly(name)] = struct{}{}
 	}
 
-	return &CustomDNSResolver{
+	r := &CustomDNSResolver{
 		configurable: withConfig(&cfg),
 		typed:        withType("custom_dns"),
 
 		createAnswerFromQuestion: util.CreateAnswerFromQuestion,
-		mapping:                  dnsRecords,
-		reverseAddresses:         reverse,
+		staticMapping:            dnsRecords,
+		selfHostnames:            self,
+		viewMappings:             viewMappings,
+		viewNames:                viewNames,
+		dynamicRecords:           make(map[string]config.CustomDNSMapping),
+	}
+
+	r.storeRecords(dnsRecords)
+
+	if cfg.ZoneFiles.IsEnabled() {
+		zoneFiles, err := zonefiles.NewWatcher(ctx, cfg.ZoneFiles)
+		if err != nil {
+			return nil, fmt.Errorf("can't load zone files: %w", err)
+		}
+
+		sources = append(sources, zoneFiles)
+	}
+
+	if cfg.Containers.IsEnabled() {
+		watcher, err := containers.NewWatcher(cfg.Containers)
+		if err != nil {
+			return nil, fmt.Errorf("can't watch containers: %w", err)
+		}
+
+		watcher.Start(ctx, r.setContainers)
 	}
+
+	for _, source := range sources {
+		source.OnChange(func(mapping config.CustomDNSMapping) {
+			r.setDynamicRecords(source.Name(), mapping)
+		})
+	}
+
+	return r, nil
+}
+
+func newCustomDNSRecords(mapping config.CustomDNSMapping) *customDNSRecords {
+	reverse := make(map[string][]*dns.PTR, len(mapping))
+
+	addPTR := func(ip net.IP, url string, ttl uint32) {
+		r, _ := dns.ReverseAddr(ip.String())
+		reverse[r] = append(reverse[r], &dns.PTR{
+			Hdr: dns.RR_Header{Class: dns.ClassINET, Rrtype: dns.TypePTR, Ttl: ttl},
+			Ptr: dns.Fqdn(url),
+		})
+	}
+
+	for url, entries := range mapping {
+		// a wildcard has no name to point to
+		if strings.HasPrefix(url, "*.") {
+			continue
+		}
+
+		for _, entry := range entries {
+			switch v := entry.(type) {
+			case *dns.A:
+				addPTR(v.A, url, v.Hdr.Ttl)
+			case *dns.AAAA:
+				addPTR(v.AAAA, url, v.Hdr.Ttl)
+			}
+		}
+	}
+
+	return &customDNSRecords{mapping: mapping, reverseAddresses: reverse}
+}
+
+// storeRecords replaces the records, the records of a view replace the records of the same domains
+func (r *CustomDNSResolver) storeRecords(mapping config.CustomDNSMapping) {
+	records := newCustomDNSRecords(mapping)
+	records.views = make(map[string]*customDNSRecords, len(r.viewMappings))
+
+	for name, viewMapping := range r.viewMappings {
+		merged := maps.Clone(mapping)
+		maps.Copy(merged, viewMapping)
+
+		records.views[name] = newCustomDNSRecords(merged)
+	}
+
+	r.records.Store(records)
+}
+
+// recordsFor returns the records of the client's view, the first matching view is used
+func (r *CustomDNSResolver) recordsFor(request *model.Request) *customDNSRecords {
+	records := r.records.Load()
+
+	for _, name := range r.viewNames {
+		if viewMatchesClient(r.cfg.Views[name].Clients, request) {
+			return records.views[name]
+		}
+	}
+
+	return records
+}
+
+// viewMatchesClient returns if the client's IP, name or MAC address matches one of the view's clients
+func viewMatchesClient(clients []string, request *model.Request) bool {
+	clientIP := request.ClientIP.String()
+
+	for _, client := range clients {
+		if client == clientIP || util.CidrContainsIP(client, request.ClientIP) ||
+			util.ClientMACMatchesGroupName(client, request.ClientMAC) {
+			return true
+		}
+
+		for _, name := range request.ClientNames {
+			if util.ClientNameMatchesGroupName(client, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// setDynamicRecords replaces the records of the source, the configured records take precedence
+func (r *CustomDNSResolver) setDynamicRecords(source string, mapping config.CustomDNSMapping) {
+	r.dynamicLock.Lock()
+	defer r.dynamicLock.Unlock()
+
+	logger := log.PrefixedLog(r.Type())
+
+	r.dynamicRecords[source] = mapping
+
+	merged := maps.Clone(r.staticMapping)
+
+	sources := make([]string, 0, len(r.dynamicRecords))
+	for name := range r.dynamicRecords {
+		sources = append(sources, name)
+	}
+
+	slices.Sort(sources)
+
+	for _, name := range sources {
+		for domain, entries := range r.dynamicRecords[name] {
+			if _, ok := r.staticMapping[domain]; ok {
+				logger.Warnf("%s: %s is already mapped in the configuration, ignoring it", name, domain)
+
+				continue
+			}
+
+			merged[domain] = append(slices.Clip(merged[domain]), entries...)
+		}
+	}
+
+	r.storeRecords(merged)
+}
+
+// setContainers replaces the records of containers
+func (r *CustomDNSResolver) setContainers(list []containers.Container) {
+	mapping := make(config.CustomDNSMapping)
+
+	for _, container := range list {
+		for _, domain := range container.Domains {
+			for _, ip := range container.IPs {
+				hdr := dns.RR_Header{Name: dns.Fqdn(domain), Class: dns.ClassINET, Ttl: r.cfg.CustomTTL.SecondsU32()}
+
+				var rr dns.RR
+
+				if ip4 := ip.To4(); ip4 != nil {
+					hdr.Rrtype = dns.TypeA
+					rr = &dns.A{Hdr: hdr, A: ip4}
+				} else {
+					hdr.Rrtype = dns.TypeAAAA
+					rr = &dns.AAAA{Hdr: hdr, AAAA: ip}
+				}
+
+				mapping[domain] = append(mapping[domain], rr)
+			}
+		}
+	}
+
+	r.setDynamicRecords("containers", mapping)
+
+	log.PrefixedLog(r.Type()).Debugf("%d containers with domains", len(list))
 }
 
 func isSupportedType(ip net.IP, question dns.Question) bool {
@@ -81,19 +292,17 @@ func isSupportedType(ip net.IP, question dns.Question) bool {
 		(strings.Contains(ip.String(), ":") && question.Qtype == dns.TypeAAAA)
 }
 
-func (r *CustomDNSResolver) handleReverseDNS(request *model.Request) *model.Response {
+func (r *CustomDNSResolver) handleReverseDNS(request *model.Request, records *customDNSRecords) *model.Response {
 	question := request.Req.Question[0]
 	if question.Qtype == dns.TypePTR {
-		urls, found := r.reverseAddresses[question.Name]
+		ptrs, found := records.reverseAddresses[question.Name]
 		if found {
 			response := new(dns.Msg)
 			response.SetReply(request.Req)
 
-			for _, url := range urls {
-				h := util.CreateHeader(question, r.cfg.CustomTTL.SecondsU32())
-				ptr := new(dns.PTR)
-				ptr.Ptr = dns.Fqdn(url)
-				ptr.Hdr = h
+			for _, ptr := range ptrs {
+				ptr := dns.Copy(ptr)
+				ptr.Header().Name = question.Name
 				response.Answer = append(response.Answer, ptr)
 			}
 
@@ -104,10 +313,117 @@ func (r *CustomDNSResolver) handleReverseDNS(request *model.Request) *model.Resp
 	return nil
 }
 
+// handleReverseRanges answers the PTR records of addresses in the reverse ranges and the addresses of the
+// generated names, so forward and reverse lookups match
+func (r *CustomDNSResolver) handleReverseRanges(request *model.Request) *model.Response {
+	if len(r.cfg.ReverseRanges) == 0 {
+		return nil
+	}
+
+	question := request.Req.Question[0]
+	name := strings.ToLower(question.Name)
+	hdr := util.CreateHeader(question, r.cfg.CustomTTL.SecondsU32())
+
+	var answer []dns.RR
+
+	if question.Qtype == dns.TypePTR && isReverseName(name) {
+		addr, rng := r.reverseRangeOfArpa(name)
+		if rng == nil {
+			return nil
+		}
+
+		answer = append(answer, &dns.PTR{Hdr: hdr, Ptr: reverseRangeName(addr, rng.Domain)})
+	} else {
+		addr, rng := r.reverseRangeOfName(name)
+		if rng == nil {
+			return nil
+		}
+
+		switch {
+		case question.Qtype == dns.TypeA && addr.Is4():
+			answer = append(answer, &dns.A{Hdr: hdr, A: addr.AsSlice()})
+		case question.Qtype == dns.TypeAAAA && addr.Is6():
+			answer = append(answer, &dns.AAAA{Hdr: hdr, AAAA: addr.AsSlice()})
+		}
+		// other types get an empty answer, the name exists
+	}
+
+	response := new(dns.Msg)
+	response.SetReply(request.Req)
+	response.Answer = answer
+
+	return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}
+}
+
+func isReverseName(name string) bool {
+	return strings.HasSuffix(name, util.IPv4PtrSuffix) || strings.HasSuffix(name, util.IPv6PtrSuffix)
+}
+
+// reverseRangeOfArpa returns the address of the reverse name and the first range containing it
+func (r *CustomDNSResolver) reverseRangeOfArpa(name string) (netip.Addr, *config.ReverseRange) {
+	ip, err := util.ParseIPFromArpaAddr(name)
+	if err != nil {
+		return netip.Addr{}, nil
+	}
+
+	addr, _ := netip.AddrFromSlice(ip)
+	addr = addr.Unmap()
+
+	for i := range r.cfg.ReverseRanges {
+		if rng := &r.cfg.ReverseRanges[i]; rng.Prefix.Contains(addr) {
+			return addr, rng
+		}
+	}
+
+	return netip.Addr{}, nil
+}
+
+// reverseRangeOfName returns the address of a generated name and its range
+func (r *CustomDNSResolver) reverseRangeOfName(name string) (netip.Addr, *config.ReverseRange) {
+	label, domain, ok := strings.Cut(name, ".")
+	if !ok {
+		return netip.Addr{}, nil
+	}
+
+	domain = strings.TrimSuffix(domain, ".")
+
+	separator := ":"
+	if strings.Count(label, "-") == net.IPv4len-1 {
+		separator = "."
+	}
+
+	addr, err := netip.ParseAddr(strings.ReplaceAll(label, "-", separator))
+	if err != nil {
+		return netip.Addr{}, nil
+	}
+
+	for i := range r.cfg.ReverseRanges {
+		rng := &r.cfg.ReverseRanges[i]
+
+		// only the generated name of an address is valid, not other spellings of it
+		if rng.Domain == domain && rng.Prefix.Contains(addr) && reverseRangeName(addr, domain) == dns.Fqdn(name) {
+			return addr, rng
+		}
+	}
+
+	return netip.Addr{}, nil
+}
+
+// reverseRangeName returns the generated name of the address: 192-168-178-20.domain or
+// 2001-0db8-0000-0000-0000-0000-0000-0001.domain
+func reverseRangeName(addr netip.Addr, domain string) string {
+	if addr.Is4() {
+		return dns.Fqdn(strings.ReplaceAll(addr.String(), ".", "-") + "." + domain)
+	}
+
+	return dns.Fqdn(strings.ReplaceAll(addr.StringExpanded(), ":", "-") + "." + domain)
+}
+
 func (r *CustomDNSResolver) processRequest(
 	ctx context.Context,
 	logger *logrus.Entry,
 	request *model.Request,
+	records *customDNSRecords,
 	resolvedCnames []string,
 ) (*model.Response, error) {
 	response := new(dns.Msg)
@@ -115,17 +431,28 @@ func (r *CustomDNSResolver) processRequest(
 
 	question := request.Req.Question[0]
 	domain := util.ExtractDomain(question)
+	mapping := records.mapping
+
+	// names of zones with a SOA record are answered authoritatively
+	if apex, soa := findZone(mapping, domain); soa != nil {
+		return r.processZoneRequest(ctx, logger, request, records, resolvedCnames, apex, soa)
+	}
+
+	// blocky's own hostnames are never forwarded, to not depend on upstreams to reach blocky
+	_, isSelf := r.selfHostnames[domain]
+
+	name := domain
 
 	for len(domain) > 0 {
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
 
-		entries, found := r.mapping[domain]
+		entries, found := mappingEntries(mapping, domain, domain != name)
 
 		if found {
 			for _, entry := range entries {
-				result, err := r.processDNSEntry(ctx, logger, request, resolvedCnames, question, entry)
+				result, err := r.processDNSEntry(ctx, logger, request, records, resolvedCnames, question, entry)
 				if err != nil {
 					return nil, err
 				}
@@ -143,7 +470,7 @@ func (r *CustomDNSResolver) processRequest(
 			}
 
 			// Mapping exists for this domain, but for another type
-			if !r.cfg.FilterUnmappedTypes {
+			if !r.cfg.FilterUnmappedTypes && !isSelf {
 				// go to next resolver
 				break
 			}
@@ -159,15 +486,139 @@ func (r *CustomDNSResolver) processRequest(
 		}
 	}
 
+	if isSelf {
+		logger.WithField("domain", util.ExtractDomain(question)).Debug("returning empty answer for own hostname")
+
+		return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}, nil
+	}
+
 	logger.WithField("next_resolver", Name(r.next)).Trace("go to next resolver")
 
 	return r.next.Resolve(ctx, request)
 }
 
+// mappingEntries returns the entries of the domain. For names below the domain, the wildcard "*.domain" is more
+// specific than the domain itself and takes precedence.
+func mappingEntries(mapping config.CustomDNSMapping, domain string, isParent bool) (config.CustomDNSEntries, bool) {
+	if isParent {
+		if entries, found := mapping["*."+domain]; found {
+			return entries, true
+		}
+	}
+
+	entries, found := mapping[domain]
+
+	return entries, found
+}
+
+// processZoneRequest answers a name of a zone: names without records don't exist, unless a wildcard matches them
+func (r *CustomDNSResolver) processZoneRequest(
+	ctx context.Context,
+	logger *logrus.Entry,
+	request *model.Request,
+	records *customDNSRecords,
+	resolvedCnames []string,
+	apex string,
+	soa *dns.SOA,
+) (*model.Response, error) {
+	response := new(dns.Msg)
+	response.SetReply(request.Req)
+	response.Authoritative = true
+
+	question := request.Req.Question[0]
+	domain := util.ExtractDomain(question)
+	mapping := records.mapping
+
+	entries, found := mapping[domain]
+	if !found {
+		entries, found = wildcardEntries(mapping, domain, apex)
+	}
+
+	for _, entry := range entries {
+		result, err := r.processDNSEntry(ctx, logger, request, records, resolvedCnames, question, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Answer = append(response.Answer, result...)
+	}
+
+	if len(response.Answer) == 0 {
+		if !found && !hasSubdomains(mapping, domain) {
+			response.Rcode = dns.RcodeNameError
+		}
+
+		// the SOA record tells how long the negative answer can be cached, RFC 2308
+		negative := dns.Copy(soa).(*dns.SOA)
+		negative.Hdr.Ttl = min(negative.Hdr.Ttl, negative.Minttl)
+		response.Ns = append(response.Ns, negative)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"answer": util.AnswerToString(response.Answer),
+		"zone":   apex,
+	}).Debugf("returning zone entry")
+
+	return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}, nil
+}
+
+// findZone returns the closest zone of the domain, which is the closest parent with a SOA record
+func findZone(mapping config.CustomDNSMapping, domain string) (string, *dns.SOA) {
+	for {
+		for _, entry := range mapping[domain] {
+			if soa, ok := entry.(*dns.SOA); ok {
+				return domain, soa
+			}
+		}
+
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			return "", nil
+		}
+
+		domain = parent
+	}
+}
+
+// wildcardEntries returns the entries of the wildcard matching the domain.
+// A wildcard doesn't match names below existing names (RFC 4592 section 3.3.1).
+func wildcardEntries(mapping config.CustomDNSMapping, domain, apex string) (config.CustomDNSEntries, bool) {
+	for domain != apex {
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			break
+		}
+
+		if entries, found := mapping["*."+parent]; found {
+			return entries, true
+		}
+
+		if _, exists := mapping[parent]; exists {
+			break
+		}
+
+		domain = parent
+	}
+
+	return nil, false
+}
+
+// hasSubdomains returns if the domain has subdomains with records, which makes it an empty non-terminal
+func hasSubdomains(mapping config.CustomDNSMapping, domain string) bool {
+	for name := range mapping {
+		if strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (r *CustomDNSResolver) processDNSEntry(
 	ctx context.Context,
 	logger *logrus.Entry,
 	request *model.Request,
+	records *customDNSRecords,
 	resolvedCnames []string,
 	question dns.Question,
 	entry dns.RR,
@@ -182,22 +633,36 @@ func (r *CustomDNSResolver) processDNSEntry(
 	case *dns.SRV:
 		return r.processSRV(*v, question, v.Header().Ttl)
 	case *dns.CNAME:
-		return r.processCNAME(ctx, logger, request, *v, resolvedCnames, question, v.Header().Ttl)
+		return r.processCNAME(ctx, logger, request, records, *v, resolvedCnames, question, v.Header().Ttl)
 	}
 
-	return nil, fmt.Errorf("unsupported customDNS RR type %T", entry)
+	// other types, like the SOA, NS and MX records of zone files, are returned as they are
+	if entry.Header().Rrtype != question.Qtype {
+		return nil, nil
+	}
+
+	rr := dns.Copy(entry)
+	rr.Header().Name = question.Name
+
+	return []dns.RR{rr}, nil
 }
 
 // Resolve uses internal mapping to resolve the query
 func (r *CustomDNSResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
 	ctx, logger := r.log(ctx)
 
-	reverseResp := r.handleReverseDNS(request)
+	records := r.recordsFor(request)
+
+	reverseResp := r.handleReverseDNS(request, records)
 	if reverseResp != nil {
 		return reverseResp, nil
 	}
 
-	return r.processRequest(ctx, logger, request, make([]string, 0, len(r.cfg.Mapping)))
+	if rangeResp := r.handleReverseRanges(request); rangeResp != nil {
+		return rangeResp, nil
+	}
+
+	return r.processRequest(ctx, logger, request, records, make([]string, 0, len(r.cfg.Mapping)))
 }
 
 func (r *CustomDNSResolver) processIP(ip net.IP, question dns.Question, ttl uint32) (result []dns.RR, err error) {
@@ -248,6 +713,7 @@ func (r *CustomDNSResolver) processCNAME(
 	ctx context.Context,
 	logger *logrus.Entry,
 	request *model.Request,
+	records *customDNSRecords,
 	targetCname dns.CNAME,
 	resolvedCnames []string,
 	question dns.Question,
@@ -276,7 +742,7 @@ func (r *CustomDNSResolver) processCNAME(
 	targetRequest := newRequestWithClientID(targetWithoutDot, dns.Type(question.Qtype), clientIP, clientID)
 
 	// resolve the target recursively
-	targetResp, err := r.processRequest(ctx, logger, targetRequest, cnames)
+	targetResp, err := r.processRequest(ctx, logger, targetRequest, records, cnames)
 	if err != nil {
 		return nil, err
 	}
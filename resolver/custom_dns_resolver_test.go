@@ -4,9 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
 	"time"
 
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/externaldns"
 	. "github.com/0xERR0R/blocky/helpertest"
 	"github.com/0xERR0R/blocky/log"
 	. "github.com/0xERR0R/blocky/model"
@@ -21,9 +26,10 @@ var _ = Describe("CustomDNSResolver", func() {
 		TTL     = uint32(time.Now().Second())
 		zoneTTL = uint32(time.Now().Second() * 2)
 
-		sut *CustomDNSResolver
-		m   *mockResolver
-		cfg config.CustomDNS
+		sut     *CustomDNSResolver
+		m       *mockResolver
+		cfg     config.CustomDNS
+		sources []CustomDNSSource
 
 		ctx      context.Context
 		cancelFn context.CancelFunc
@@ -39,6 +45,8 @@ var _ = Describe("CustomDNSResolver", func() {
 		ctx, cancelFn = context.WithCancel(context.Background())
 		DeferCleanup(cancelFn)
 
+		sources = nil
+
 		zoneHdr := dns.RR_Header{Ttl: zoneTTL}
 
 		cfg = config.CustomDNS{
@@ -60,7 +68,7 @@ var _ = Describe("CustomDNSResolver", func() {
 					"cname.recursive.": {&dns.CNAME{Target: "cname.recursive", Hdr: zoneHdr}},
 					"srv.":             {&dns.SRV{Priority: 0, Weight: 5, Port: 12345, Target: "service", Hdr: zoneHdr}},
 					"txt.":             {&dns.TXT{Txt: []string{"space", "separated", "value"}, Hdr: zoneHdr}},
-					"mx.domain.":       {&dns.MX{Mx: "mx.domain", Hdr: zoneHdr}},
+					"mx.domain.":       {&dns.MX{Mx: "mx.domain", Hdr: dns.RR_Header{Rrtype: dns.TypeMX, Ttl: zoneTTL}}},
 				},
 			},
 			CustomTTL:           config.Duration(time.Duration(TTL) * time.Second),
@@ -69,7 +77,11 @@ var _ = Describe("CustomDNSResolver", func() {
 	})
 
 	JustBeforeEach(func() {
-		sut = NewCustomDNSResolver(cfg)
+		var err error
+
+		sut, err = NewCustomDNSResolver(ctx, cfg, sources...)
+		Expect(err).Should(Succeed())
+
 		m = &mockResolver{}
 		m.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg)}, nil)
 		sut.Next(m)
@@ -405,15 +417,134 @@ var _ = Describe("CustomDNSResolver", func() {
 						))
 			})
 		})
-		When("An unsupported DNS query type is queried from the resolver but found in the config mapping ", func() {
-			It("an error should be returned", func() {
+		When("Another DNS query type is queried from the resolver and found in the config mapping ", func() {
+			It("the record should be returned", func() {
 				By("MX query", func() {
-					_, err := sut.Resolve(ctx, newRequest("mx.domain", MX))
-					Expect(err).Should(HaveOccurred())
-					Expect(err.Error()).Should(ContainSubstring("unsupported customDNS RR type *dns.MX"))
+					Expect(sut.Resolve(ctx, newRequest("mx.domain.", MX))).
+						Should(
+							SatisfyAll(
+								BeDNSRecord("mx.domain.", MX, "mx.domain"),
+								HaveTTL(BeNumerically("==", zoneTTL)),
+								HaveResponseType(ResponseTypeCUSTOMDNS),
+							))
 				})
 			})
 		})
+		When("The mapping contains records with type", func() {
+			BeforeEach(func() {
+				newRR := func(s string) dns.RR {
+					rr, err := dns.NewRR(". " + s)
+					Expect(err).Should(Succeed())
+
+					return rr
+				}
+
+				cfg.Mapping["home.lan"] = config.CustomDNSEntries{
+					&dns.A{A: net.ParseIP("192.168.178.2")},
+					newRR("MX 10 mail.home.lan."),
+					newRR(`CAA 0 issue "letsencrypt.org"`),
+				}
+				cfg.Mapping["app.home.lan"] = config.CustomDNSEntries{newRR("HTTPS 1 . alpn=h2")}
+			})
+
+			It("should return the records with the TTL of the mapping", func() {
+				Expect(sut.Resolve(ctx, newRequest("home.lan.", MX))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("home.lan.", MX, "mail.home.lan."),
+							HaveTTL(BeNumerically("==", TTL)),
+							HaveResponseType(ResponseTypeCUSTOMDNS),
+						))
+
+				Expect(sut.Resolve(ctx, newRequest("home.lan.", dns.Type(dns.TypeCAA)))).
+					Should(WithTransform(ToAnswer, ConsistOf(
+						WithTransform(dns.RR.String, Equal(
+							fmt.Sprintf("home.lan.\t%d\tIN\tCAA\t0 issue \"letsencrypt.org\"", TTL),
+						)),
+					)))
+
+				Expect(sut.Resolve(ctx, newRequest("app.home.lan.", dns.Type(dns.TypeHTTPS)))).
+					Should(WithTransform(ToAnswer, ConsistOf(
+						WithTransform(dns.RR.String, Equal(
+							fmt.Sprintf("app.home.lan.\t%d\tIN\tHTTPS\t1 . alpn=\"h2\"", TTL),
+						)),
+					)))
+
+				m.AssertNotCalled(GinkgoT(), "Resolve", mock.Anything)
+			})
+		})
+		When("Reverse DNS request for a zone record is received", func() {
+			It("should use the TTL of the record", func() {
+				Expect(sut.Resolve(ctx, newRequest("4.3.2.1.in-addr.arpa.", PTR))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("4.3.2.1.in-addr.arpa.", PTR, "example.zone."),
+							HaveTTL(BeNumerically("==", zoneTTL)),
+						))
+			})
+		})
+		When("Reverse ranges are configured", func() {
+			BeforeEach(func() {
+				cfg.ReverseRanges = []config.ReverseRange{
+					{Prefix: netip.MustParsePrefix("192.168.143.0/24"), Domain: "dhcp.lan"},
+					{Prefix: netip.MustParsePrefix("fd00::/64"), Domain: "dhcp6.lan"},
+				}
+			})
+
+			It("should generate names for addresses without record", func() {
+				Expect(sut.Resolve(ctx, newRequest("50.143.168.192.in-addr.arpa.", PTR))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("50.143.168.192.in-addr.arpa.", PTR, "192-168-143-50.dhcp.lan."),
+							HaveTTL(BeNumerically("==", TTL)),
+							HaveResponseType(ResponseTypeCUSTOMDNS),
+						))
+
+				ip6Arpa := "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.d.f.ip6.arpa."
+
+				Expect(sut.Resolve(ctx, newRequest(ip6Arpa, PTR))).
+					Should(BeDNSRecord(ip6Arpa, PTR, "fd00-0000-0000-0000-0000-0000-0000-0001.dhcp6.lan."))
+
+				m.AssertNotCalled(GinkgoT(), "Resolve", mock.Anything)
+			})
+
+			It("should prefer the records", func() {
+				Expect(sut.Resolve(ctx, newRequest("123.143.168.192.in-addr.arpa.", PTR))).
+					Should(WithTransform(ToAnswer, ContainElement(
+						BeDNSRecord("123.143.168.192.in-addr.arpa.", PTR, "custom.domain."),
+					)))
+			})
+
+			It("should resolve the generated names", func() {
+				Expect(sut.Resolve(ctx, newRequest("192-168-143-50.dhcp.lan.", A))).
+					Should(BeDNSRecord("192-168-143-50.dhcp.lan.", A, "192.168.143.50"))
+
+				Expect(sut.Resolve(ctx, newRequest("fd00-0000-0000-0000-0000-0000-0000-0001.dhcp6.lan.", AAAA))).
+					Should(BeDNSRecord("fd00-0000-0000-0000-0000-0000-0000-0001.dhcp6.lan.", AAAA, "fd00::1"))
+
+				Expect(sut.Resolve(ctx, newRequest("192-168-143-50.dhcp.lan.", AAAA))).
+					Should(
+						SatisfyAll(
+							HaveNoAnswer(),
+							HaveReturnCode(dns.RcodeSuccess),
+						))
+
+				m.AssertNotCalled(GinkgoT(), "Resolve", mock.Anything)
+			})
+
+			It("should forward other names and addresses", func() {
+				for _, request := range []*Request{
+					newRequest("192-168-144-50.dhcp.lan.", A),
+					newRequest("192-168-143-50.other.lan.", A),
+					newRequest("50.144.168.192.in-addr.arpa.", PTR),
+				} {
+					_, err := sut.Resolve(ctx, request)
+					Expect(err).Should(Succeed())
+				}
+
+				Expect(m.Calls).Should(HaveLen(3))
+			})
+		})
 		When("Reverse DNS request is received", func() {
 			It("should resolve the defined domain name", func() {
 				By("ipv4", func() {
@@ -473,6 +604,38 @@ var _ = Describe("CustomDNSResolver", func() {
 				m.AssertNotCalled(GinkgoT(), "Resolve", mock.Anything)
 			})
 		})
+		When("Wildcard mapping is defined", func() {
+			BeforeEach(func() {
+				cfg.Mapping["*.lab.home"] = config.CustomDNSEntries{&dns.A{A: net.ParseIP("192.168.50.10")}}
+				cfg.Mapping["lab.home"] = config.CustomDNSEntries{&dns.A{A: net.ParseIP("192.168.50.1")}}
+				cfg.Mapping["nas.lab.home"] = config.CustomDNSEntries{&dns.A{A: net.ParseIP("192.168.50.2")}}
+			})
+
+			It("should match all subdomains", func() {
+				Expect(sut.Resolve(ctx, newRequest("app.lab.home.", A))).
+					Should(SatisfyAll(
+						BeDNSRecord("app.lab.home.", A, "192.168.50.10"),
+						HaveTTL(BeNumerically("==", TTL)),
+						HaveResponseType(ResponseTypeCUSTOMDNS),
+					))
+				Expect(sut.Resolve(ctx, newRequest("a.b.lab.home.", A))).
+					Should(BeDNSRecord("a.b.lab.home.", A, "192.168.50.10"))
+			})
+
+			It("should prefer longer matches", func() {
+				Expect(sut.Resolve(ctx, newRequest("lab.home.", A))).
+					Should(BeDNSRecord("lab.home.", A, "192.168.50.1"))
+				Expect(sut.Resolve(ctx, newRequest("nas.lab.home.", A))).
+					Should(BeDNSRecord("nas.lab.home.", A, "192.168.50.2"))
+				Expect(sut.Resolve(ctx, newRequest("www.nas.lab.home.", A))).
+					Should(BeDNSRecord("www.nas.lab.home.", A, "192.168.50.2"))
+			})
+
+			It("should not create a reverse record for the wildcard", func() {
+				Expect(sut.Resolve(ctx, newRequest("10.50.168.192.in-addr.arpa.", PTR))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+			})
+		})
 	})
 
 	Describe("Delegating to next resolver", func() {
@@ -490,4 +653,363 @@ var _ = Describe("CustomDNSResolver", func() {
 			})
 		})
 	})
+
+	Describe("Own hostnames", func() {
+		BeforeEach(func() {
+			cfg.FilterUnmappedTypes = false
+			cfg.SelfHostnames = []string{"custom.domain", "DNS.Unmapped.Domain."}
+		})
+
+		It("should resolve the mapping", func() {
+			Expect(sut.Resolve(ctx, newRequest("custom.domain.", A))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("custom.domain.", A, "192.168.143.123"),
+						HaveResponseType(ResponseTypeCUSTOMDNS),
+					))
+
+			m.AssertNotCalled(GinkgoT(), "Resolve", mock.Anything)
+		})
+
+		It("should not delegate unmapped types to next resolver", func() {
+			Expect(sut.Resolve(ctx, newRequest("custom.domain.", AAAA))).
+				Should(
+					SatisfyAll(
+						HaveNoAnswer(),
+						HaveResponseType(ResponseTypeCUSTOMDNS),
+						HaveReturnCode(dns.RcodeSuccess),
+					))
+
+			m.AssertNotCalled(GinkgoT(), "Resolve", mock.Anything)
+		})
+
+		It("should not delegate own hostnames without mapping to next resolver", func() {
+			Expect(sut.Resolve(ctx, newRequest("dns.unmapped.domain.", A))).
+				Should(
+					SatisfyAll(
+						HaveNoAnswer(),
+						HaveResponseType(ResponseTypeCUSTOMDNS),
+						HaveReturnCode(dns.RcodeSuccess),
+					))
+
+			m.AssertNotCalled(GinkgoT(), "Resolve", mock.Anything)
+		})
+
+		It("should still delegate other domains to next resolver", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			m.AssertExpectations(GinkgoT())
+		})
+	})
+
+	Describe("Containers", func() {
+		var (
+			containersJSON atomic.Value
+			events         chan string
+		)
+
+		BeforeEach(func() {
+			containersJSON.Store(`[
+				{"Names": ["/app"], "Labels": {"blocky.dns": "app.home.lan"},
+				 "NetworkSettings": {"Networks": {"bridge": {"IPAddress": "172.17.0.2"}}}},
+				{"Names": ["/clash"], "Labels": {"blocky.dns": "custom.domain, v6.home.lan", "blocky.dns.ip": "fd00::2"}}
+			]`)
+			events = make(chan string)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/containers/json":
+					_, _ = w.Write([]byte(containersJSON.Load().(string)))
+				case "/events":
+					w.(http.Flusher).Flush()
+
+					for {
+						select {
+						case event := <-events:
+							_, _ = w.Write([]byte(event))
+							w.(http.Flusher).Flush()
+						case <-r.Context().Done():
+							return
+						}
+					}
+				}
+			}))
+			DeferCleanup(server.Close)
+			DeferCleanup(cancelFn)
+
+			cfg.Containers = config.ContainerDNS{Enable: true, Endpoint: server.URL, Label: "blocky.dns"}
+		})
+
+		It("should resolve container domains", func() {
+			Expect(sut.Resolve(ctx, newRequest("app.home.lan.", A))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("app.home.lan.", A, "172.17.0.2"),
+						HaveTTL(BeNumerically("==", TTL)),
+						HaveResponseType(ResponseTypeCUSTOMDNS),
+					))
+
+			Expect(sut.Resolve(ctx, newRequest("v6.home.lan.", AAAA))).
+				Should(BeDNSRecord("v6.home.lan.", AAAA, "fd00::2"))
+
+			Expect(sut.Resolve(ctx, newRequest("2.0.17.172.in-addr.arpa.", PTR))).
+				Should(BeDNSRecord("2.0.17.172.in-addr.arpa.", PTR, "app.home.lan."))
+		})
+
+		It("should prefer configured records", func() {
+			Expect(sut.Resolve(ctx, newRequest("custom.domain.", A))).
+				Should(BeDNSRecord("custom.domain.", A, "192.168.143.123"))
+		})
+
+		It("should update the records when containers start and stop", func() {
+			containersJSON.Store(`[]`)
+			events <- `{"Type": "container", "Action": "die"}`
+
+			Eventually(func(g Gomega) {
+				g.Expect(sut.Resolve(ctx, newRequest("app.home.lan.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+			}).Should(Succeed())
+		})
+	})
+
+	Describe("Views", func() {
+		BeforeEach(func() {
+			cfg.Mapping["nas.home.lan"] = config.CustomDNSEntries{&dns.A{A: net.ParseIP("203.0.113.10")}}
+			cfg.Mapping["www.home.lan"] = config.CustomDNSEntries{&dns.CNAME{Target: "nas.home.lan"}}
+
+			cfg.Views = map[string]config.CustomDNSView{
+				"internal": {
+					Clients: []string{"192.168.178.0/24", "laptop*"},
+					Mapping: config.CustomDNSMapping{
+						"nas.home.lan": {&dns.A{A: net.ParseIP("192.168.178.10")}},
+					},
+				},
+				"vpn": {
+					Clients: []string{"10.8.0.2"},
+					Mapping: config.CustomDNSMapping{
+						"nas.home.lan": {&dns.A{A: net.ParseIP("10.8.0.1")}},
+					},
+				},
+			}
+		})
+
+		It("should answer with the records of the client's view", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("nas.home.lan.", A, "192.168.178.20"))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("nas.home.lan.", A, "192.168.178.10"),
+						HaveTTL(BeNumerically("==", TTL)),
+						HaveResponseType(ResponseTypeCUSTOMDNS),
+					))
+
+			Expect(sut.Resolve(ctx, newRequestWithClient("nas.home.lan.", A, "10.8.0.2"))).
+				Should(BeDNSRecord("nas.home.lan.", A, "10.8.0.1"))
+
+			Expect(sut.Resolve(ctx, newRequestWithClient("nas.home.lan.", A, "172.16.0.1", "laptop-anna"))).
+				Should(BeDNSRecord("nas.home.lan.", A, "192.168.178.10"))
+		})
+
+		It("should answer other clients with the default records", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("nas.home.lan.", A, "172.16.0.1", "phone"))).
+				Should(BeDNSRecord("nas.home.lan.", A, "203.0.113.10"))
+		})
+
+		It("should use the default records for domains not in the view", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("custom.domain.", A, "192.168.178.20"))).
+				Should(BeDNSRecord("custom.domain.", A, "192.168.143.123"))
+		})
+
+		It("should resolve CNAME targets in the view", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("www.home.lan.", A, "192.168.178.20"))).
+				Should(WithTransform(ToAnswer, ContainElements(
+					BeDNSRecord("www.home.lan.", CNAME, "nas.home.lan."),
+					BeDNSRecord("nas.home.lan.", A, "192.168.178.10"),
+				)))
+		})
+
+		It("should answer reverse lookups with the view's records", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("10.178.168.192.in-addr.arpa.", PTR, "192.168.178.20"))).
+				Should(BeDNSRecord("10.178.168.192.in-addr.arpa.", PTR, "nas.home.lan."))
+
+			_, err := sut.Resolve(ctx, newRequestWithClient("10.178.168.192.in-addr.arpa.", PTR, "172.16.0.1"))
+			Expect(err).Should(Succeed())
+			m.AssertCalled(GinkgoT(), "Resolve", mock.Anything)
+		})
+
+		When("records of sources change", func() {
+			BeforeEach(func() {
+				sources = append(sources, &staticSource{mapping: config.CustomDNSMapping{
+					"nas.home.lan":     {&dns.A{A: net.ParseIP("198.51.100.1")}},
+					"printer.home.lan": {&dns.A{A: net.ParseIP("192.168.178.30")}},
+				}})
+			})
+
+			It("should keep the view's records", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("printer.home.lan.", A, "192.168.178.20"))).
+					Should(BeDNSRecord("printer.home.lan.", A, "192.168.178.30"))
+
+				Expect(sut.Resolve(ctx, newRequestWithClient("nas.home.lan.", A, "192.168.178.20"))).
+					Should(BeDNSRecord("nas.home.lan.", A, "192.168.178.10"))
+			})
+		})
+	})
+
+	Describe("Zone files", func() {
+		BeforeEach(func() {
+			tmpDir := NewTmpFolder("zones")
+			zoneFile := tmpDir.CreateStringFile("home.lan.zone",
+				"$TTL 300",
+				"@        IN SOA ns.home.lan. admin.home.lan. 1 3600 600 86400 60",
+				"@        IN NS  ns",
+				"@        IN MX  10 mail",
+				"@        IN CAA 0 issue \"letsencrypt.org\"",
+				"ns       IN A   192.168.178.2",
+				"mail     IN A   192.168.178.3",
+				"www      IN CNAME mail",
+				"*.apps   IN A   192.168.178.4",
+				"db.apps  IN A   192.168.178.5",
+				"a.b      IN A   192.168.178.6",
+			)
+
+			cfg.ZoneFiles = config.ZoneFiles{Zones: map[string]string{"home.lan": zoneFile.Path}}
+		})
+
+		It("should answer the records of the zone", func() {
+			Expect(sut.Resolve(ctx, newRequest("home.lan.", MX))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("home.lan.", MX, "mail.home.lan."),
+						HaveTTL(BeNumerically("==", 300)),
+						HaveResponseType(ResponseTypeCUSTOMDNS),
+					))
+
+			Expect(sut.Resolve(ctx, newRequest("home.lan.", dns.Type(dns.TypeCAA)))).
+				Should(WithTransform(ToAnswer, ContainElement(
+					BeAssignableToTypeOf(&dns.CAA{}),
+				)))
+
+			Expect(sut.Resolve(ctx, newRequest("www.home.lan.", A))).
+				Should(WithTransform(ToAnswer, SatisfyAll(
+					HaveLen(2),
+					ContainElements(
+						BeDNSRecord("www.home.lan.", CNAME, "mail.home.lan."),
+						BeDNSRecord("mail.home.lan.", A, "192.168.178.3"),
+					),
+				)))
+		})
+
+		It("should answer wildcards", func() {
+			Expect(sut.Resolve(ctx, newRequest("app1.apps.home.lan.", A))).
+				Should(BeDNSRecord("app1.apps.home.lan.", A, "192.168.178.4"))
+
+			Expect(sut.Resolve(ctx, newRequest("db.apps.home.lan.", A))).
+				Should(BeDNSRecord("db.apps.home.lan.", A, "192.168.178.5"))
+		})
+
+		It("should answer unknown names with NXDOMAIN", func() {
+			Expect(sut.Resolve(ctx, newRequest("unknown.home.lan.", A))).
+				Should(
+					SatisfyAll(
+						HaveNoAnswer(),
+						HaveReturnCode(dns.RcodeNameError),
+						HaveResponseType(ResponseTypeCUSTOMDNS),
+						WithTransform(func(resp *Response) []dns.RR { return resp.Res.Ns }, ConsistOf(
+							SatisfyAll(
+								BeAssignableToTypeOf(&dns.SOA{}),
+								WithTransform(func(rr dns.RR) uint32 { return rr.Header().Ttl }, BeNumerically("==", 60)),
+							),
+						)),
+					))
+
+			m.AssertNotCalled(GinkgoT(), "Resolve", mock.Anything)
+		})
+
+		It("should answer unmapped types and empty non-terminals without error", func() {
+			Expect(sut.Resolve(ctx, newRequest("ns.home.lan.", AAAA))).
+				Should(
+					SatisfyAll(
+						HaveNoAnswer(),
+						HaveReturnCode(dns.RcodeSuccess),
+					))
+
+			Expect(sut.Resolve(ctx, newRequest("b.home.lan.", A))).
+				Should(
+					SatisfyAll(
+						HaveNoAnswer(),
+						HaveReturnCode(dns.RcodeSuccess),
+					))
+		})
+
+		When("the zone file is invalid", func() {
+			It("should fail", func() {
+				cfg.ZoneFiles.Zones["home.lan"] = "/does/not/exist"
+
+				_, err := NewCustomDNSResolver(ctx, cfg)
+				Expect(err).Should(MatchError(ContainSubstring("can't load zone files")))
+			})
+		})
+	})
+
+	Describe("ExternalDNS", func() {
+		var externalDNS *externaldns.Provider
+
+		BeforeEach(func() {
+			cfg.ExternalDNS = config.ExternalDNS{Enable: true}
+			externalDNS = externaldns.NewProvider(cfg.ExternalDNS, cfg.CustomTTL)
+			sources = append(sources, externalDNS)
+		})
+
+		JustBeforeEach(func() {
+			Expect(externalDNS.ApplyChanges(externaldns.Changes{Create: []*externaldns.Endpoint{
+				{DNSName: "app.k8s.lan", RecordType: "A", Targets: []string{"10.0.0.1"}, RecordTTL: 60},
+				{DNSName: "a-app.k8s.lan", RecordType: "TXT", Targets: []string{`"heritage=external-dns"`}},
+				{DNSName: "custom.domain", RecordType: "A", Targets: []string{"10.0.0.2"}},
+			}})).Should(Succeed())
+		})
+
+		It("should resolve the records of ExternalDNS", func() {
+			Expect(sut.Resolve(ctx, newRequest("app.k8s.lan.", A))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("app.k8s.lan.", A, "10.0.0.1"),
+						HaveTTL(BeNumerically("==", 60)),
+						HaveResponseType(ResponseTypeCUSTOMDNS),
+					))
+
+			Expect(sut.Resolve(ctx, newRequest("a-app.k8s.lan.", TXT))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("a-app.k8s.lan.", TXT, "heritage=external-dns"),
+						HaveTTL(BeNumerically("==", TTL)),
+					))
+		})
+
+		It("should prefer configured records", func() {
+			Expect(sut.Resolve(ctx, newRequest("custom.domain.", A))).
+				Should(BeDNSRecord("custom.domain.", A, "192.168.143.123"))
+		})
+
+		It("should remove deleted records", func() {
+			Expect(externalDNS.ApplyChanges(externaldns.Changes{Delete: []*externaldns.Endpoint{
+				{DNSName: "app.k8s.lan", RecordType: "A", Targets: []string{"10.0.0.1"}},
+			}})).Should(Succeed())
+
+			Expect(sut.Resolve(ctx, newRequest("app.k8s.lan.", A))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+		})
+	})
 })
+
+// staticSource is a `CustomDNSSource` with fixed records
+type staticSource struct {
+	mapping config.CustomDNSMapping
+}
+
+func (s *staticSource) Name() string {
+	return "static"
+}
+
+func (s *staticSource) OnChange(onChange func(config.CustomDNSMapping)) {
+	onChange(s.mapping)
+}
@@ -0,0 +1,169 @@
+package resolver
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/netip"
+	"slices"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+)
+
+// position of the reserved octet "u" in IPv4-embedded IPv6 addresses (RFC 6052, section 2.2)
+const dns64ReservedOctet = 8
+
+// DNS64Resolver synthesizes AAAA records from A records for IPv6-only clients behind NAT64 (RFC 6147)
+type DNS64Resolver struct {
+	configurable[*config.DNS64]
+	NextResolver
+	typed
+}
+
+// NewDNS64Resolver creates a new resolver instance
+func NewDNS64Resolver(cfg config.DNS64) *DNS64Resolver {
+	return &DNS64Resolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("dns64"),
+	}
+}
+
+// Resolve synthesizes AAAA records if the AAAA query has no answer but the A query has
+func (r *DNS64Resolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() || !r.isCandidate(request.Req) {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	response, err := r.next.Resolve(ctx, request)
+	if err != nil || response.Res.Rcode == dns.RcodeNameError || r.hasAAAA(response.Res) {
+		return response, err
+	}
+
+	aRequest := *request
+	aRequest.Req = request.Req.Copy()
+	aRequest.Req.Question[0].Qtype = dns.TypeA
+
+	aResponse, err := r.next.Resolve(ctx, &aRequest)
+	if err != nil || aResponse.Res.Rcode != dns.RcodeSuccess {
+		// the AAAA response is still valid
+		return response, nil //nolint:nilerr
+	}
+
+	answer := r.synthesize(aResponse.Res.Answer, negativeTTL(response.Res))
+	if answer == nil {
+		return response, nil
+	}
+
+	logger.Debugf("synthesized %d AAAA records", len(answer))
+
+	res := new(dns.Msg)
+	res.SetReply(request.Req)
+	res.RecursionAvailable = aResponse.Res.RecursionAvailable
+	res.Answer = answer
+
+	return &model.Response{Res: res, RType: aResponse.RType, Reason: "DNS64"}, nil
+}
+
+// isCandidate returns true for AAAA queries, except if the client validates DNSSEC itself (RFC 6147, section 5.5)
+func (r *DNS64Resolver) isCandidate(msg *dns.Msg) bool {
+	question := msg.Question[0]
+	if question.Qtype != dns.TypeAAAA || question.Qclass != dns.ClassINET {
+		return false
+	}
+
+	opt := msg.IsEdns0()
+
+	return !(msg.CheckingDisabled && opt != nil && opt.Do())
+}
+
+// hasAAAA returns true if the answer contains AAAA records not excluded (RFC 6147, section 5.1.4)
+func (r *DNS64Resolver) hasAAAA(msg *dns.Msg) bool {
+	return slices.ContainsFunc(msg.Answer, func(rr dns.RR) bool {
+		aaaa, ok := rr.(*dns.AAAA)
+
+		return ok && !r.isExcluded(aaaa.AAAA.To16())
+	})
+}
+
+// synthesize returns the CNAMEs and the AAAA records of the A records, nil if there is no AAAA record
+func (r *DNS64Resolver) synthesize(answer []dns.RR, maxTTL uint32) []dns.RR {
+	res := make([]dns.RR, 0, len(answer))
+	synthesized := false
+
+	for _, rr := range answer {
+		switch v := rr.(type) {
+		case *dns.CNAME:
+			res = append(res, dns.Copy(v))
+
+		case *dns.A:
+			if r.isExcluded(v.A.To4()) {
+				continue
+			}
+
+			res = append(res, &dns.AAAA{
+				Hdr: dns.RR_Header{
+					Name:   v.Hdr.Name,
+					Rrtype: dns.TypeAAAA,
+					Class:  v.Hdr.Class,
+					Ttl:    min(v.Hdr.Ttl, maxTTL),
+				},
+				AAAA: embedIPv4(r.cfg.Prefix, v.A),
+			})
+
+			synthesized = true
+		}
+	}
+
+	if !synthesized {
+		return nil
+	}
+
+	return res
+}
+
+// isExcluded returns true if the address of an A (`ip.To4()`) or AAAA (`ip.To16()`) record is excluded
+func (r *DNS64Resolver) isExcluded(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+
+	return slices.ContainsFunc(r.cfg.Exclude, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+}
+
+// embedIPv4 returns the IPv4-embedded IPv6 address of the IPv4 address (RFC 6052, section 2.2)
+func embedIPv4(prefix netip.Prefix, ip net.IP) net.IP {
+	res := prefix.Masked().Addr().As16()
+	pos := prefix.Bits() / 8 //nolint:mnd
+
+	for _, b := range ip.To4() {
+		if pos == dns64ReservedOctet {
+			pos++
+		}
+
+		res[pos] = b
+		pos++
+	}
+
+	return res[:]
+}
+
+// negativeTTL returns the TTL of the negative response from its SOA record (RFC 6147, section 5.1.7)
+func negativeTTL(msg *dns.Msg) uint32 {
+	ttl := uint32(math.MaxUint32)
+
+	for _, rr := range msg.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			ttl = min(ttl, soa.Hdr.Ttl, soa.Minttl)
+		}
+	}
+
+	return ttl
+}
@@ -0,0 +1,221 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("DNS64Resolver", func() {
+	var (
+		sut       *DNS64Resolver
+		sutConfig config.DNS64
+		m         *mockResolver
+
+		answers map[dns.Type]*dns.Msg
+		aErr    error
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	newRR := func(s string) dns.RR {
+		rr, err := dns.NewRR(s)
+		Expect(err).Should(Succeed())
+
+		return rr
+	}
+
+	msgWith := func(rcode int, rrs ...string) *dns.Msg {
+		msg := new(dns.Msg)
+		msg.Rcode = rcode
+
+		for _, rr := range rrs {
+			msg.Answer = append(msg.Answer, newRR(rr))
+		}
+
+		return msg
+	}
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		var err error
+
+		sutConfig, err = config.WithDefaults[config.DNS64]()
+		Expect(err).Should(Succeed())
+
+		sutConfig.Enable = true
+
+		aErr = nil
+		answers = map[dns.Type]*dns.Msg{
+			A:    msgWith(dns.RcodeSuccess, "example.com. 300 IN A 192.0.2.33"),
+			AAAA: msgWith(dns.RcodeSuccess),
+		}
+	})
+
+	JustBeforeEach(func() {
+		sut = NewDNS64Resolver(sutConfig)
+
+		m = &mockResolver{}
+		m.ResolveFn = func(_ context.Context, req *Request) (*Response, error) {
+			qType := dns.Type(req.Req.Question[0].Qtype)
+			if qType == A && aErr != nil {
+				return nil, aErr
+			}
+
+			return &Response{Res: answers[qType].Copy(), RType: ResponseTypeRESOLVED, Reason: "RESOLVED"}, nil
+		}
+		m.On("Resolve", mock.Anything)
+
+		sut.Next(m)
+	})
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	Describe("IsEnabled", func() {
+		It("is false by default", func() {
+			sut := NewDNS64Resolver(config.DNS64{})
+
+			Expect(sut.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	It("should synthesize AAAA records from A records", func() {
+		Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+			Should(SatisfyAll(
+				BeDNSRecord("example.com.", AAAA, "64:ff9b::c000:221"),
+				HaveTTL(BeNumerically("==", 300)),
+				HaveReason("DNS64"),
+				HaveResponseType(ResponseTypeRESOLVED),
+				HaveReturnCode(dns.RcodeSuccess),
+			))
+
+		Expect(m.Calls).Should(HaveLen(2))
+	})
+
+	It("should not change existing AAAA records", func() {
+		answers[AAAA] = msgWith(dns.RcodeSuccess, "example.com. 300 IN AAAA 2001:db8::1")
+
+		Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+			Should(BeDNSRecord("example.com.", AAAA, "2001:db8::1"))
+
+		Expect(m.Calls).Should(HaveLen(1))
+	})
+
+	It("should not synthesize for NXDOMAIN", func() {
+		answers[AAAA] = msgWith(dns.RcodeNameError)
+
+		Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+			Should(HaveReturnCode(dns.RcodeNameError))
+
+		Expect(m.Calls).Should(HaveLen(1))
+	})
+
+	It("should synthesize for other errors", func() {
+		answers[AAAA] = msgWith(dns.RcodeServerFailure)
+
+		Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+			Should(BeDNSRecord("example.com.", AAAA, "64:ff9b::c000:221"))
+	})
+
+	It("should keep CNAMEs of the A answer", func() {
+		answers[A] = msgWith(dns.RcodeSuccess,
+			"example.com. 300 IN CNAME cdn.example.net.",
+			"cdn.example.net. 60 IN A 192.0.2.33",
+		)
+
+		resp, err := sut.Resolve(ctx, newRequest("example.com.", AAAA))
+		Expect(err).Should(Succeed())
+		Expect(resp.Res.Answer).Should(HaveLen(2))
+		Expect(resp.Res.Answer[0]).Should(BeDNSRecord("example.com.", CNAME, "cdn.example.net."))
+		Expect(resp.Res.Answer[1]).Should(BeDNSRecord("cdn.example.net.", AAAA, "64:ff9b::c000:221"))
+	})
+
+	It("should limit the TTL to the one of the negative answer", func() {
+		answers[AAAA].Ns = []dns.RR{newRR("example.com. 60 IN SOA ns. admin. 1 2 3 4 30")}
+
+		Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+			Should(HaveTTL(BeNumerically("==", 30)))
+	})
+
+	It("should return the AAAA answer if the A query fails", func() {
+		aErr = errors.New("boom")
+
+		Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+			Should(SatisfyAll(HaveNoAnswer(), HaveReason("RESOLVED")))
+	})
+
+	It("should not touch other query types", func() {
+		Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+			Should(BeDNSRecord("example.com.", A, "192.0.2.33"))
+
+		Expect(m.Calls).Should(HaveLen(1))
+	})
+
+	It("should not synthesize if the client validates DNSSEC", func() {
+		request := newRequest("example.com.", AAAA)
+		request.Req.CheckingDisabled = true
+		request.Req.SetEdns0(dns.DefaultMsgSize, true)
+
+		Expect(sut.Resolve(ctx, request)).Should(HaveNoAnswer())
+	})
+
+	Describe("exclusions", func() {
+		BeforeEach(func() {
+			sutConfig.Exclude = append(sutConfig.Exclude, netip.MustParsePrefix("10.0.0.0/8"))
+		})
+
+		It("should treat excluded AAAA records as missing", func() {
+			answers[AAAA] = msgWith(dns.RcodeSuccess, "example.com. 300 IN AAAA ::ffff:192.0.2.1")
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+				Should(BeDNSRecord("example.com.", AAAA, "64:ff9b::c000:221"))
+		})
+
+		It("should not synthesize excluded A records", func() {
+			answers[A] = msgWith(dns.RcodeSuccess, "example.com. 300 IN A 10.1.2.3")
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+				Should(SatisfyAll(HaveNoAnswer(), HaveReason("RESOLVED")))
+		})
+	})
+
+	DescribeTable("embedIPv4 should embed the address depending on the prefix length",
+		func(prefix, expected string) {
+			Expect(embedIPv4(netip.MustParsePrefix(prefix), netip.MustParseAddr("192.0.2.33").AsSlice()).String()).
+				Should(Equal(expected))
+		},
+		// examples of RFC 6052, section 2.4
+		Entry("/32", "2001:db8::/32", "2001:db8:c000:221::"),
+		Entry("/40", "2001:db8:100::/40", "2001:db8:1c0:2:21::"),
+		Entry("/48", "2001:db8:122::/48", "2001:db8:122:c000:2:2100::"),
+		Entry("/56", "2001:db8:122:300::/56", "2001:db8:122:3c0:0:221::"),
+		Entry("/64", "2001:db8:122:344::/64", "2001:db8:122:344:c0:2:2100:0"),
+		Entry("/96", "2001:db8:122:344::/96", "2001:db8:122:344::c000:221"),
+	)
+})
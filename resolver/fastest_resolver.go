@@ -0,0 +1,146 @@
+package resolver
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	fastestResolverType = "fastest"
+
+	// share of queries sent to a random upstream first, to keep the latency of all upstreams up to date
+	fastestExplorationRate = 0.1
+)
+
+// FastestResolver delegates the DNS message to the upstream with the lowest expected latency.
+// If it fails, the next fastest is used.
+type FastestResolver struct {
+	configurable[*config.UpstreamGroup]
+	typed
+
+	resolvers atomic.Pointer[[]*upstreamResolverStatus]
+
+	// returns a number in [0.0,1.0), replaceable for tests
+	random func() float64
+}
+
+// NewFastestResolver creates a new fastest resolver instance
+func NewFastestResolver(
+	ctx context.Context, cfg config.UpstreamGroup, bootstrap *Bootstrap,
+) (*FastestResolver, error) {
+	r := newFastestResolver(
+		cfg,
+		[]Resolver{bootstrap}, // if init strategy is fast, use bootstrap until init finishes
+	)
+
+	return initGroupResolvers(ctx, r, cfg, bootstrap)
+}
+
+func newFastestResolver(
+	cfg config.UpstreamGroup, resolvers []Resolver,
+) *FastestResolver {
+	r := FastestResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType(fastestResolverType),
+
+		random: rand.Float64, //nolint:gosec // pseudo-randomness is good enough
+	}
+
+	r.setResolvers(newUpstreamResolverStatuses(resolvers))
+
+	return &r
+}
+
+func (r *FastestResolver) setResolvers(resolvers []*upstreamResolverStatus) {
+	r.resolvers.Store(&resolvers)
+}
+
+// UpstreamStatus implements `api.UpstreamStatusProvider`.
+func (r *FastestResolver) UpstreamStatus() []api.UpstreamStatus {
+	return healthStatus(r.cfg.Name, *r.resolvers.Load())
+}
+
+func (r *FastestResolver) Name() string {
+	return r.String()
+}
+
+func (r *FastestResolver) String() string {
+	resolvers := *r.resolvers.Load()
+
+	upstreams := make([]string, len(resolvers))
+	for i, s := range resolvers {
+		upstreams[i] = s.resolver.String()
+	}
+
+	return fmt.Sprintf("%s upstreams '%s (%s)'", fastestResolverType, r.cfg.Name, strings.Join(upstreams, ","))
+}
+
+// Resolve sends the query request to the upstream resolvers, fastest first
+func (r *FastestResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	ctx, logger := r.log(ctx)
+
+	resolvers := r.order(healthyOrAll(*r.resolvers.Load()))
+
+	if r.cfg.Hedging.IsEnabled() {
+		return resolveHedged(ctx, logger, request, resolvers, r.cfg.Hedging)
+	}
+
+	for _, resolver := range resolvers {
+		logger.Debugf("using %s as resolver", resolver.resolver)
+
+		resp, err := resolver.resolve(ctx, request)
+		if err != nil {
+			// log error and try next upstream
+			logger.WithField("resolver", resolver.resolver).Debug("resolution failed from resolver, cause: ", err)
+
+			continue
+		}
+
+		logger.WithFields(logrus.Fields{
+			"resolver": *resolver,
+			"answer":   util.AnswerToString(resp.Res.Answer),
+		}).Debug("using response from resolver")
+
+		return resp, nil
+	}
+
+	return nil, errors.New("resolution was not successful, no resolver returned an answer in time")
+}
+
+// order returns the resolvers sorted by their score, sometimes with a random one first to explore
+func (r *FastestResolver) order(resolvers []*upstreamResolverStatus) []*upstreamResolverStatus {
+	scores := make(map[*upstreamResolverStatus]time.Duration, len(resolvers))
+	for _, res := range resolvers {
+		scores[res] = res.health.score()
+	}
+
+	ordered := slices.Clone(resolvers)
+	slices.SortStableFunc(ordered, func(a, b *upstreamResolverStatus) int {
+		return cmp.Compare(scores[a], scores[b])
+	})
+
+	if len(ordered) > 1 && r.random() < fastestExplorationRate {
+		// move a random one of the others to the front
+		i := 1 + int(r.random()*float64(len(ordered)-1))
+		explored := ordered[i]
+
+		copy(ordered[1:i+1], ordered[:i])
+		ordered[0] = explored
+	}
+
+	return ordered
+}
@@ -0,0 +1,169 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("FastestResolver", Label("fastestResolver"), func() {
+	var (
+		sut       *FastestResolver
+		sutConfig config.UpstreamGroup
+
+		slow, fast, failing *mockResolver
+		statuses            []*upstreamResolverStatus
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	newMock := func(err error) *mockResolver {
+		m := &mockResolver{}
+
+		if err != nil {
+			m.On("Resolve", mock.Anything).Return(nil, err)
+		} else {
+			m.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg)}, nil)
+		}
+
+		return m
+	}
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		sutConfig = config.NewUpstreamGroup("test", defaultUpstreamsConfig, []config.Upstream{{Host: "127.0.0.1"}})
+
+		slow = newMock(nil)
+		fast = newMock(nil)
+		failing = newMock(errors.New("boom"))
+
+		statuses = newUpstreamResolverStatuses([]Resolver{slow, fast, failing})
+		statuses[0].health.record(nil, 100*time.Millisecond)
+		statuses[1].health.record(nil, 10*time.Millisecond)
+		statuses[2].health.record(errors.New("boom"), time.Millisecond)
+	})
+
+	JustBeforeEach(func() {
+		sut = newFastestResolver(sutConfig, nil)
+		sut.setResolvers(statuses)
+		sut.random = func() float64 { return 1 } // no exploration
+	})
+
+	Describe("IsEnabled", func() {
+		It("is true", func() {
+			Expect(sut.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	Describe("Name", func() {
+		It("should contain correct resolver", func() {
+			Expect(sut.Name()).Should(ContainSubstring(fastestResolverType))
+		})
+	})
+
+	Describe("Resolve", func() {
+		It("should use the fastest upstream", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(fast.Calls).Should(HaveLen(1))
+			Expect(slow.Calls).Should(BeEmpty())
+			Expect(failing.Calls).Should(BeEmpty())
+		})
+
+		When("the fastest upstream fails", func() {
+			BeforeEach(func() {
+				fast = newMock(errors.New("boom"))
+				statuses[1] = newUpstreamResolverStatus(fast)
+				statuses[1].health.record(nil, 10*time.Millisecond)
+			})
+
+			It("should use the next fastest", func() {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(fast.Calls).Should(HaveLen(1))
+				Expect(slow.Calls).Should(HaveLen(1))
+				Expect(failing.Calls).Should(BeEmpty())
+			})
+		})
+
+		When("all upstreams fail", func() {
+			BeforeEach(func() {
+				statuses = newUpstreamResolverStatuses([]Resolver{failing})
+			})
+
+			It("should return an error", func() {
+				_, err := sut.Resolve(ctx, newRequest("example.com.", A))
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("order", func() {
+		It("should sort by score", func() {
+			Expect(sut.order(statuses)).Should(Equal([]*upstreamResolverStatus{statuses[1], statuses[0], statuses[2]}))
+		})
+
+		It("should prefer upstreams without results", func() {
+			untested := newUpstreamResolverStatus(newMock(nil))
+
+			Expect(sut.order(append(statuses, untested))[0]).Should(Equal(untested))
+		})
+
+		It("should penalize errors", func() {
+			for range 3 {
+				statuses[1].health.record(errors.New("boom"), time.Second)
+			}
+
+			// fast: 10ms with 75% errors -> 40ms, slow: 100ms
+			Expect(sut.order(statuses)[0]).Should(Equal(statuses[1]))
+
+			for range 10 {
+				statuses[1].health.record(errors.New("boom"), time.Second)
+			}
+
+			Expect(sut.order(statuses)[0]).Should(Equal(statuses[0]))
+		})
+
+		It("should sometimes explore a random upstream first", func() {
+			values := []float64{0, 0.99} // explore the last one
+			sut.random = func() float64 {
+				v := values[0]
+				values = values[1:]
+
+				return v
+			}
+
+			Expect(sut.order(statuses)).Should(Equal([]*upstreamResolverStatus{statuses[2], statuses[1], statuses[0]}))
+		})
+	})
+})
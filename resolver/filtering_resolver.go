@@ -5,6 +5,7 @@ import (
 
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
 	"github.com/miekg/dns"
 )
 
@@ -24,13 +25,98 @@ func NewFilteringResolver(cfg config.Filtering) *FilteringResolver {
 }
 
 func (r *FilteringResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	filtering := r.filteringForClient(request)
+
 	qType := request.Req.Question[0].Qtype
-	if r.cfg.QueryTypes.Contains(dns.Type(qType)) {
-		response := new(dns.Msg)
-		response.SetRcode(request.Req, dns.RcodeSuccess)
+	if filtering.QueryTypes.Contains(dns.Type(qType)) {
+		return filteredResponse(request, ""), nil
+	}
 
-		return &model.Response{Res: response, RType: model.ResponseTypeFILTERED}, nil
+	if filtering.SuppressAAAA && qType == dns.TypeAAAA && r.hasARecords(ctx, request) {
+		return filteredResponse(request, "SUPPRESSED AAAA"), nil
 	}
 
 	return r.next.Resolve(ctx, request)
 }
+
+// filteringForClient returns the filtering of the groups matching the client, the default filtering if none matches.
+// Like for upstream groups, CIDRs are only checked if neither the IP nor a client name or MAC address matched.
+func (r *FilteringResolver) filteringForClient(request *model.Request) config.FilteringGroup {
+	defaultFiltering := config.FilteringGroup{QueryTypes: r.cfg.QueryTypes, SuppressAAAA: r.cfg.SuppressAAAA}
+
+	if len(r.cfg.ClientGroups) == 0 {
+		return defaultFiltering
+	}
+
+	var groups []config.FilteringGroup
+
+	if group, ok := r.cfg.ClientGroups[request.ClientIP.String()]; ok {
+		groups = append(groups, group)
+	}
+
+	for client, group := range r.cfg.ClientGroups {
+		for _, name := range request.ClientNames {
+			if util.ClientNameMatchesGroupName(client, name) {
+				groups = append(groups, group)
+
+				break
+			}
+		}
+
+		if util.ClientMACMatchesGroupName(client, request.ClientMAC) {
+			groups = append(groups, group)
+		}
+	}
+
+	if len(groups) == 0 {
+		for client, group := range r.cfg.ClientGroups {
+			if util.CidrContainsIP(client, request.ClientIP) {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	if len(groups) == 0 {
+		return defaultFiltering
+	}
+
+	res := config.FilteringGroup{QueryTypes: config.NewQTypeSet()}
+
+	for _, group := range groups {
+		for qType := range group.QueryTypes {
+			res.QueryTypes.Insert(dns.Type(qType))
+		}
+
+		res.SuppressAAAA = res.SuppressAAAA || group.SuppressAAAA
+	}
+
+	return res
+}
+
+// hasARecords returns true if the A query for the domain of the AAAA request has an answer with A records.
+// The A query isn't logged or counted as the client didn't send it.
+func (r *FilteringResolver) hasARecords(ctx context.Context, request *model.Request) bool {
+	aRequest := *request
+	aRequest.Req = request.Req.Copy()
+	aRequest.Req.Question[0].Qtype = dns.TypeA
+
+	response, err := r.next.Resolve(withInternalQuery(ctx), &aRequest)
+	if err != nil || response.Res.Rcode != dns.RcodeSuccess {
+		return false
+	}
+
+	for _, rr := range response.Res.Answer {
+		if _, ok := rr.(*dns.A); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func filteredResponse(request *model.Request, reason string) *model.Response {
+	response := new(dns.Msg)
+	response.SetRcode(request.Req, dns.RcodeSuccess)
+
+	return &model.Response{Res: response, RType: model.ResponseTypeFILTERED, Reason: reason}
+}
@@ -2,11 +2,14 @@ package resolver
 
 import (
 	"context"
+	"time"
 
 	"github.com/0xERR0R/blocky/config"
 	. "github.com/0xERR0R/blocky/helpertest"
 	"github.com/0xERR0R/blocky/log"
 	. "github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/creasty/defaults"
 
 	"github.com/miekg/dns"
 	. "github.com/onsi/ginkgo/v2"
@@ -110,4 +113,127 @@ var _ = Describe("FilteringResolver", func() {
 			Expect(m.Calls).Should(HaveLen(1))
 		})
 	})
+
+	When("AAAA suppression is enabled", func() {
+		BeforeEach(func() {
+			sutConfig = config.Filtering{SuppressAAAA: true}
+		})
+
+		JustBeforeEach(func() {
+			m.ResolveFn = func(_ context.Context, req *Request) (*Response, error) {
+				if req.Req.Question[0].Qtype == dns.TypeA && req.Req.Question[0].Name == "example.com." {
+					return &Response{Res: new(dns.Msg).SetReply(req.Req).SetRcode(req.Req, dns.RcodeSuccess)}, nil
+				}
+
+				return &Response{Res: mockAnswer, RType: ResponseTypeRESOLVED}, nil
+			}
+		})
+
+		It("should return NODATA for AAAA if the domain has A records", func() {
+			msg, err := util.NewMsgWithAnswer("example.com.", 300, A, "1.2.3.4")
+			Expect(err).Should(Succeed())
+
+			m.ResolveFn = func(context.Context, *Request) (*Response, error) {
+				return &Response{Res: msg, RType: ResponseTypeRESOLVED}, nil
+			}
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+				Should(
+					SatisfyAll(
+						HaveNoAnswer(),
+						HaveResponseType(ResponseTypeFILTERED),
+						HaveReason("SUPPRESSED AAAA"),
+						HaveReturnCode(dns.RcodeSuccess),
+					))
+
+			// only the A query
+			Expect(m.Calls).Should(HaveLen(1))
+		})
+
+		It("should resolve AAAA if the domain has no A records", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", AAAA))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(m.Calls).Should(HaveLen(2))
+		})
+
+		It("should not change other queries", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.org.", A))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(m.Calls).Should(HaveLen(1))
+		})
+
+		It("should not log or count the A query", func() {
+			var statsConfig config.Stats
+			Expect(defaults.Set(&statsConfig)).Should(Succeed())
+			statsConfig.Enable = true
+
+			statistics, err := NewStatsResolver(ctx, statsConfig, &config.Redis{})
+			Expect(err).Should(Succeed())
+
+			var queryLogConfig config.QueryLog
+			Expect(defaults.Set(&queryLogConfig)).Should(Succeed())
+			queryLogConfig.Type = config.QueryLogTypeNone
+
+			queryLogging, err := NewQueryLoggingResolver(ctx, queryLogConfig, nil)
+			Expect(err).Should(Succeed())
+
+			Chain(sut, queryLogging, statistics, m)
+
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", AAAA, "192.168.178.20"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			entries := queryLogging.RecentQueries(10)
+			Expect(entries).Should(HaveLen(1))
+			Expect(entries[0].QuestionType).Should(Equal("AAAA"))
+
+			overview, err := statistics.StatsOverview(ctx, time.Hour)
+			Expect(err).Should(Succeed())
+			Expect(overview.Counters.Total).Should(BeEquivalentTo(1))
+		})
+	})
+
+	When("client groups are defined", func() {
+		BeforeEach(func() {
+			sutConfig = config.Filtering{
+				QueryTypes: config.NewQTypeSet(MX),
+				ClientGroups: map[string]config.FilteringGroup{
+					"iot*":           {QueryTypes: config.NewQTypeSet(AAAA)},
+					"192.168.1.0/24": {QueryTypes: config.NewQTypeSet(HTTPS)},
+					"10.0.0.5":       {QueryTypes: config.NewQTypeSet(TXT)},
+				},
+			}
+		})
+
+		It("should use the query types of the matching client name", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", AAAA, "192.168.1.2", "iot-cam"))).
+				Should(HaveResponseType(ResponseTypeFILTERED))
+
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", MX, "192.168.1.2", "iot-cam"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			// CIDR is only checked if no name matched
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", HTTPS, "192.168.1.2", "iot-cam"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+		})
+
+		It("should use the query types of the matching IP", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", TXT, "10.0.0.5", "laptop"))).
+				Should(HaveResponseType(ResponseTypeFILTERED))
+		})
+
+		It("should use the query types of the matching CIDR", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", HTTPS, "192.168.1.2", "laptop"))).
+				Should(HaveResponseType(ResponseTypeFILTERED))
+		})
+
+		It("should use the default query types for other clients", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", MX, "10.0.0.1", "laptop"))).
+				Should(HaveResponseType(ResponseTypeFILTERED))
+
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", AAAA, "10.0.0.1", "laptop"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+		})
+	})
 })
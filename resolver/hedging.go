@@ -0,0 +1,106 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/0xERR0R/blocky/watchdog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maximum number of concurrent requests of a hedged query
+const hedgingMaxInFlight = 2
+
+// resolveHedged sends the query to the resolvers in order, like the strict strategy.
+// If a resolver does not answer within its hedging delay, the next resolver is queried too and the first answer is used.
+func resolveHedged(
+	ctx context.Context, logger *logrus.Entry, request *model.Request,
+	resolvers []*upstreamResolverStatus, cfg config.UpstreamHedging,
+) (*model.Response, error) {
+	// cancel the requests which lost the race
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		resolver *upstreamResolverStatus
+		resp     *model.Response
+		err      error
+	}
+
+	results := make(chan result, len(resolvers))
+	next, inFlight := 0, 0
+
+	var hedge <-chan time.Time
+
+	startNext := func() {
+		resolver := resolvers[next]
+		next++
+		inFlight++
+
+		logger.Debugf("using %s as resolver", resolver.resolver)
+
+		go func() {
+			defer watchdog.TrackWorker("upstream_queries")()
+
+			resp, err := resolver.resolve(ctx, request)
+			results <- result{resolver, resp, err}
+		}()
+
+		hedge = nil
+		if next < len(resolvers) {
+			hedge = time.After(hedgingDelay(resolver, cfg))
+		}
+	}
+
+	if len(resolvers) != 0 {
+		startNext()
+	}
+
+	for inFlight > 0 {
+		select {
+		case <-hedge:
+			hedge = nil
+
+			if inFlight < hedgingMaxInFlight {
+				logger.Debug("no answer within hedging delay, querying next resolver too")
+				startNext()
+			}
+
+		case res := <-results:
+			inFlight--
+
+			if res.err == nil {
+				logger.WithFields(logrus.Fields{
+					"resolver": *res.resolver,
+					"answer":   util.AnswerToString(res.resp.Res.Answer),
+				}).Debug("using response from resolver")
+
+				return res.resp, nil
+			}
+
+			// log error and try next upstream
+			logger.WithField("resolver", res.resolver.resolver).Debug("resolution failed from resolver, cause: ", res.err)
+
+			if next < len(resolvers) {
+				startNext()
+			}
+		}
+	}
+
+	return nil, errors.New("resolution was not successful, no resolver returned an answer in time")
+}
+
+// hedgingDelay returns how long to wait for the resolver before querying the next one
+func hedgingDelay(resolver *upstreamResolverStatus, cfg config.UpstreamHedging) time.Duration {
+	latency, ok := resolver.health.latencyPercentile(cfg.Percentile)
+	if !ok {
+		return cfg.MaxDelay.ToDuration()
+	}
+
+	return min(max(latency, cfg.MinDelay.ToDuration()), cfg.MaxDelay.ToDuration())
+}
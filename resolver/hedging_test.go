@@ -0,0 +1,198 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Hedged requests", func() {
+	var (
+		cfg config.UpstreamHedging
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	type delayedResolver struct {
+		*mockResolver
+		calls atomic.Int32
+	}
+
+	// newDelayed returns a resolver answering with the reason `name` after `delay`, or failing if `err` is set
+	newDelayed := func(name string, delay time.Duration, err error) *delayedResolver {
+		r := &delayedResolver{mockResolver: &mockResolver{}}
+
+		r.ResolveFn = func(ctx context.Context, _ *Request) (*Response, error) {
+			r.calls.Add(1)
+
+			select {
+			case <-time.After(delay):
+				if err != nil {
+					return nil, err
+				}
+
+				return &Response{Res: new(dns.Msg), Reason: name}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		r.On("Resolve", mock.Anything)
+
+		return r
+	}
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		cfg = config.UpstreamHedging{
+			Enable:     true,
+			Percentile: 95,
+			MinDelay:   config.Duration(20 * time.Millisecond),
+			MaxDelay:   config.Duration(20 * time.Millisecond),
+		}
+	})
+
+	resolve := func(resolvers ...*delayedResolver) (*Response, error) {
+		statuses := make([]*upstreamResolverStatus, len(resolvers))
+		for i, r := range resolvers {
+			statuses[i] = newUpstreamResolverStatus(r)
+		}
+
+		logger, _ := log.NewMockEntry()
+
+		return resolveHedged(ctx, logger, newRequest("example.com.", A), statuses, cfg)
+	}
+
+	It("should only query the first resolver if it answers in time", func() {
+		first := newDelayed("first", 0, nil)
+		second := newDelayed("second", 0, nil)
+
+		Expect(resolve(first, second)).Should(HaveReason("first"))
+		Expect(second.calls.Load()).Should(BeZero())
+	})
+
+	It("should use the answer of the next resolver if the first is too slow", func() {
+		first := newDelayed("first", time.Second, nil)
+		second := newDelayed("second", 0, nil)
+
+		start := time.Now()
+
+		Expect(resolve(first, second)).Should(HaveReason("second"))
+		Expect(time.Since(start)).Should(BeNumerically("<", 500*time.Millisecond))
+		Expect(first.calls.Load()).Should(BeNumerically("==", 1))
+	})
+
+	It("should use the answer of the first resolver if it still answers first", func() {
+		first := newDelayed("first", 50*time.Millisecond, nil)
+		second := newDelayed("second", time.Second, nil)
+
+		Expect(resolve(first, second)).Should(HaveReason("first"))
+		Expect(second.calls.Load()).Should(BeNumerically("==", 1))
+	})
+
+	It("should not query more than 2 resolvers concurrently", func() {
+		first := newDelayed("first", time.Second, nil)
+		second := newDelayed("second", 100*time.Millisecond, nil)
+		third := newDelayed("third", 0, nil)
+
+		Expect(resolve(first, second, third)).Should(HaveReason("second"))
+		Expect(third.calls.Load()).Should(BeZero())
+	})
+
+	It("should query the next resolver immediately on errors", func() {
+		first := newDelayed("first", 0, errors.New("boom"))
+		second := newDelayed("second", 0, nil)
+
+		cfg.MinDelay = config.Duration(time.Hour)
+		cfg.MaxDelay = config.Duration(time.Hour)
+
+		Expect(resolve(first, second)).Should(HaveReason("second"))
+	})
+
+	It("should fail if all resolvers fail", func() {
+		_, err := resolve(newDelayed("first", 0, errors.New("boom")), newDelayed("second", 0, errors.New("boom")))
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should fail without resolvers", func() {
+		_, err := resolve()
+		Expect(err).Should(HaveOccurred())
+	})
+
+	Describe("hedgingDelay", func() {
+		var status *upstreamResolverStatus
+
+		BeforeEach(func() {
+			cfg.MinDelay = config.Duration(10 * time.Millisecond)
+			cfg.MaxDelay = config.Duration(100 * time.Millisecond)
+
+			status = newUpstreamResolverStatus(newDelayed("x", 0, nil))
+		})
+
+		It("should use the maximum without latencies", func() {
+			Expect(hedgingDelay(status, cfg)).Should(Equal(100 * time.Millisecond))
+		})
+
+		It("should use the percentile of the latencies", func() {
+			status.health.record(nil, 50*time.Millisecond)
+
+			Expect(hedgingDelay(status, cfg)).Should(Equal(50 * time.Millisecond))
+		})
+
+		It("should be limited to the bounds", func() {
+			status.health.record(nil, time.Millisecond)
+			Expect(hedgingDelay(status, cfg)).Should(Equal(10 * time.Millisecond))
+
+			for range upstreamHealthWindow {
+				status.health.record(nil, time.Second)
+			}
+
+			Expect(hedgingDelay(status, cfg)).Should(Equal(100 * time.Millisecond))
+		})
+	})
+
+	Describe("strategies", func() {
+		var upstreamsCfg config.Upstreams
+
+		BeforeEach(func() {
+			upstreamsCfg = defaultUpstreamsConfig
+			upstreamsCfg.Hedging = cfg
+		})
+
+		It("strict should hedge if enabled", func() {
+			sut := newStrictResolver(config.NewUpstreamGroup("test", upstreamsCfg, nil), nil)
+			sut.setResolvers(newUpstreamResolverStatuses([]Resolver{
+				newDelayed("first", time.Second, nil), newDelayed("second", 0, nil),
+			}))
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).Should(HaveReason("second"))
+		})
+
+		It("fastest should hedge if enabled", func() {
+			slow := newDelayed("slow", time.Second, nil)
+
+			statuses := newUpstreamResolverStatuses([]Resolver{slow, newDelayed("fast", 0, nil)})
+			statuses[1].health.record(nil, time.Second) // known as slower than the first
+
+			sut := newFastestResolver(config.NewUpstreamGroup("test", upstreamsCfg, nil), nil)
+			sut.setResolvers(statuses)
+			sut.random = func() float64 { return 1 } // no exploration
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).Should(HaveReason("fast"))
+			Expect(slow.calls.Load()).Should(BeNumerically("==", 1))
+		})
+	})
+})
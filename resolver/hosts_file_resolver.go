@@ -45,7 +45,7 @@ func NewHostsFileResolver(ctx context.Context,
 		downloader: lists.NewDownloader(cfg.Loading.Downloads, bootstrap.NewHTTPTransport()),
 	}
 
-	err := cfg.Loading.StartPeriodicRefresh(ctx, r.loadSources, func(err error) {
+	err := cfg.Loading.StartPeriodicRefresh(ctx, r.loadSources, r.loadSources, func(err error) {
 		_, logger := r.log(ctx)
 		logger.WithError(err).Errorf("could not load hosts files")
 	})
@@ -0,0 +1,342 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/ipv4"
+)
+
+const (
+	// mDNS messages can be up to 9000 bytes, RFC 6762 section 17
+	mdnsMaxMessageSize = 9000
+
+	mdnsReason  = "RESOLVED (mDNS)"
+	llmnrReason = "RESOLVED (LLMNR)"
+)
+
+//nolint:gochecknoglobals
+var (
+	// RFC 6762 section 3 and 12: `.local` and the reverse mapping zones of the link-local addresses
+	mdnsDomains = []string{
+		"local.",
+		"254.169.in-addr.arpa.",
+		"8.e.f.ip6.arpa.",
+		"9.e.f.ip6.arpa.",
+		"a.e.f.ip6.arpa.",
+		"b.e.f.ip6.arpa.",
+	}
+
+	mdnsGroups = []*net.UDPAddr{
+		{IP: net.IPv4(224, 0, 0, 251), Port: 5353},
+		{IP: net.ParseIP("ff02::fb"), Port: 5353},
+	}
+
+	llmnrGroups = []*net.UDPAddr{
+		{IP: net.IPv4(224, 0, 0, 252), Port: 5355},
+		{IP: net.ParseIP("ff02::1:3"), Port: 5355},
+	}
+)
+
+// MDNSResolver answers queries for link-local names with multicast DNS instead of forwarding them to the upstreams.
+//
+// The queries are sent as one-shot queries from an ephemeral port, so the responders send unicast responses
+// (RFC 6762 section 5.1 and 6.7, RFC 4795 section 2.4).
+type MDNSResolver struct {
+	configurable[*config.MDNS]
+	NextResolver
+	typed
+
+	mdnsGroups  []*net.UDPAddr
+	llmnrGroups []*net.UDPAddr
+	interfaces  []net.Interface
+}
+
+// NewMDNSResolver creates a new resolver instance
+func NewMDNSResolver(cfg config.MDNS) (*MDNSResolver, error) {
+	r := &MDNSResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("mdns"),
+
+		mdnsGroups:  mdnsGroups,
+		llmnrGroups: llmnrGroups,
+	}
+
+	if !cfg.IsEnabled() {
+		return r, nil
+	}
+
+	interfaces, err := mdnsInterfaces(cfg.Interfaces)
+	if err != nil {
+		return nil, err
+	}
+
+	r.interfaces = interfaces
+
+	return r, nil
+}
+
+// mdnsInterfaces returns the named interfaces or all multicast capable ones
+func mdnsInterfaces(names []string) ([]net.Interface, error) {
+	if len(names) != 0 {
+		result := make([]net.Interface, 0, len(names))
+
+		for _, name := range names {
+			ifi, err := net.InterfaceByName(name)
+			if err != nil {
+				return nil, fmt.Errorf("unknown interface '%s': %w", name, err)
+			}
+
+			result = append(result, *ifi)
+		}
+
+		return result, nil
+	}
+
+	all, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("can't list the interfaces: %w", err)
+	}
+
+	var result []net.Interface
+
+	for _, ifi := range all {
+		if ifi.Flags&net.FlagUp != 0 && ifi.Flags&net.FlagMulticast != 0 && ifi.Flags&net.FlagLoopback == 0 {
+			result = append(result, ifi)
+		}
+	}
+
+	return result, nil
+}
+
+// Resolve answers link-local names with mDNS and, if enabled, single-label names with LLMNR
+func (r *MDNSResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() {
+		return r.next.Resolve(ctx, request)
+	}
+
+	question := request.Req.Question[0]
+	name := strings.ToLower(question.Name)
+
+	switch {
+	case isMDNSName(name):
+		answer := r.query(ctx, request, r.mdnsGroups)
+		if len(answer) == 0 {
+			// the name is link-local, forwarding it would leak it to the upstreams
+			return newResponse(request, dns.RcodeNameError, model.ResponseTypeRESOLVED, mdnsReason), nil
+		}
+
+		return r.newResponse(request, answer, mdnsReason), nil
+
+	case r.cfg.LLMNR && dns.CountLabel(name) == 1:
+		answer := r.query(ctx, request, r.llmnrGroups)
+		if len(answer) != 0 {
+			return r.newResponse(request, answer, llmnrReason), nil
+		}
+	}
+
+	return r.next.Resolve(ctx, request)
+}
+
+func isMDNSName(name string) bool {
+	for _, domain := range mdnsDomains {
+		if dns.IsSubDomain(domain, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *MDNSResolver) newResponse(request *model.Request, answer []dns.RR, reason string) *model.Response {
+	response := newResponse(request, dns.RcodeSuccess, model.ResponseTypeRESOLVED, reason)
+	response.Res.Answer = answer
+
+	return response
+}
+
+// query sends the question to all groups and returns the answer of the first response
+func (r *MDNSResolver) query(ctx context.Context, request *model.Request, groups []*net.UDPAddr) []dns.RR {
+	ctx, logger := r.log(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout.ToDuration())
+	defer cancel()
+
+	question := request.Req.Question[0]
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(question.Name, question.Qtype)
+	msg.RecursionDesired = false
+
+	answers := make(chan []dns.RR, len(groups))
+
+	var wg sync.WaitGroup
+
+	for _, group := range groups {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			answer, err := r.queryGroup(ctx, msg, group)
+			if err != nil {
+				logger.WithField("group", group).Debugf("query failed: %s", err)
+
+				return
+			}
+
+			answers <- answer
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(answers)
+	}()
+
+	for answer := range answers {
+		if len(answer) != 0 {
+			logger.WithFields(logrus.Fields{
+				"question": util.Obfuscate(question.Name),
+				"answer":   util.AnswerToString(answer),
+			}).Debug("received multicast response")
+
+			return answer
+		}
+	}
+
+	return nil
+}
+
+// queryGroup sends the query to the group on each interface and waits for a response with an answer
+func (r *MDNSResolver) queryGroup(ctx context.Context, msg *dns.Msg, group *net.UDPAddr) ([]dns.RR, error) {
+	network := "udp4"
+	if group.IP.To4() == nil {
+		network = "udp6"
+	}
+
+	conn, err := net.ListenUDP(network, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// unblock the read if another group answered first
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.send(conn, packed, group); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, mdnsMaxMessageSize)
+
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) || isTimeout(err) {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		response := new(dns.Msg)
+		if err := response.Unpack(buf[:n]); err != nil || !response.Response || response.Id != msg.Id {
+			continue
+		}
+
+		if answer := mdnsAnswer(msg.Question[0], response); len(answer) != 0 {
+			return answer, nil
+		}
+	}
+}
+
+// send sends the packet to the group on each interface or via the default route if no interfaces are known
+func (r *MDNSResolver) send(conn *net.UDPConn, packet []byte, group *net.UDPAddr) error {
+	if len(r.interfaces) == 0 {
+		_, err := conn.WriteToUDP(packet, group)
+
+		return err
+	}
+
+	var errs []error
+
+	sent := false
+
+	for _, ifi := range r.interfaces {
+		dest := *group
+
+		if group.IP.To4() != nil {
+			if err := ipv4.NewPacketConn(conn).SetMulticastInterface(&ifi); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ifi.Name, err))
+
+				continue
+			}
+		} else {
+			// IPv6 link-local multicast addresses need the zone of the interface
+			dest.Zone = ifi.Name
+		}
+
+		if _, err := conn.WriteToUDP(packet, &dest); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ifi.Name, err))
+
+			continue
+		}
+
+		sent = true
+	}
+
+	if !sent {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// mdnsAnswer returns the records of the response answering the question
+func mdnsAnswer(question dns.Question, response *dns.Msg) []dns.RR {
+	var result []dns.RR
+
+	for _, rr := range response.Answer {
+		hdr := rr.Header()
+
+		if !strings.EqualFold(hdr.Name, question.Name) {
+			continue
+		}
+
+		if hdr.Rrtype != question.Qtype && hdr.Rrtype != dns.TypeCNAME {
+			continue
+		}
+
+		rr = dns.Copy(rr)
+		// the top bit of the class is the cache-flush bit, RFC 6762 section 10.2
+		rr.Header().Class &^= 1 << 15 //nolint:mnd
+		rr.Header().Name = question.Name
+
+		result = append(result, rr)
+	}
+
+	return result
+}
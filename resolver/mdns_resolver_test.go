@@ -0,0 +1,237 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("MDNSResolver", func() {
+	var (
+		sut       *MDNSResolver
+		sutConfig config.MDNS
+		m         *mockResolver
+
+		responder      *MockUDPUpstreamServer
+		llmnrResponder *MockUDPUpstreamServer
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	groupOf := func(srv *MockUDPUpstreamServer) []*net.UDPAddr {
+		upstream := srv.Start()
+
+		return []*net.UDPAddr{{IP: net.IPv4(127, 0, 0, 1), Port: int(upstream.Port)}}
+	}
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		var err error
+
+		sutConfig, err = config.WithDefaults[config.MDNS]()
+		Expect(err).Should(Succeed())
+
+		sutConfig.Enable = true
+		sutConfig.Timeout = config.Duration(200 * time.Millisecond)
+
+		responder = NewMockUDPUpstreamServer().WithAnswerFn(func(request *dns.Msg) *dns.Msg {
+			msg := new(dns.Msg)
+
+			switch request.Question[0].Name {
+			case "printer.local.":
+				rr, err := dns.NewRR("printer.local. 10 IN A 192.168.178.20")
+				Expect(err).Should(Succeed())
+
+				// cache-flush bit
+				rr.Header().Class |= 1 << 15
+
+				msg.Answer = append(msg.Answer, rr)
+			case "20.1.254.169.in-addr.arpa.":
+				rr, err := dns.NewRR("20.1.254.169.in-addr.arpa. 10 IN PTR printer.local.")
+				Expect(err).Should(Succeed())
+
+				msg.Answer = append(msg.Answer, rr)
+			}
+
+			return msg
+		})
+
+		llmnrResponder = NewMockUDPUpstreamServer().WithAnswerRR("nas. 30 IN A 192.168.178.30")
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = NewMDNSResolver(sutConfig)
+		Expect(err).Should(Succeed())
+
+		sut.mdnsGroups = groupOf(responder)
+		sut.llmnrGroups = groupOf(llmnrResponder)
+		sut.interfaces = nil
+
+		m = &mockResolver{}
+		m.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg)}, nil)
+		sut.Next(m)
+	})
+
+	Describe("IsEnabled", func() {
+		It("is true", func() {
+			Expect(sut.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	Describe("NewMDNSResolver", func() {
+		When("an interface does not exist", func() {
+			It("should fail", func() {
+				sutConfig.Interfaces = []string{"does-not-exist0"}
+
+				_, err := NewMDNSResolver(sutConfig)
+				Expect(err).Should(MatchError(ContainSubstring("does-not-exist0")))
+			})
+		})
+	})
+
+	Describe("Resolve", func() {
+		When("disabled", func() {
+			BeforeEach(func() {
+				sutConfig.Enable = false
+			})
+
+			It("should delegate to the next resolver", func() {
+				_, err := sut.Resolve(ctx, newRequest("printer.local.", A))
+				Expect(err).Should(Succeed())
+
+				m.AssertExpectations(GinkgoT())
+				Expect(responder.GetCallCount()).Should(BeZero())
+			})
+		})
+
+		It("should answer .local names with mDNS", func() {
+			Expect(sut.Resolve(ctx, newRequest("printer.local.", A))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("printer.local.", A, "192.168.178.20"),
+						HaveTTL(BeNumerically("==", 10)),
+						HaveResponseType(ResponseTypeRESOLVED),
+						HaveReason("RESOLVED (mDNS)"),
+					))
+
+			Expect(m.Calls).Should(BeEmpty())
+		})
+
+		It("should answer reverse link-local names with mDNS", func() {
+			Expect(sut.Resolve(ctx, newRequest("20.1.254.169.in-addr.arpa.", PTR))).
+				Should(BeDNSRecord("20.1.254.169.in-addr.arpa.", PTR, "printer.local."))
+		})
+
+		It("should return NXDOMAIN for unknown .local names", func() {
+			Expect(sut.Resolve(ctx, newRequest("unknown.local.", A))).
+				Should(
+					SatisfyAll(
+						HaveNoAnswer(),
+						HaveReturnCode(dns.RcodeNameError),
+						HaveReason("RESOLVED (mDNS)"),
+					))
+
+			Expect(m.Calls).Should(BeEmpty())
+		})
+
+		It("should delegate other names to the next resolver", func() {
+			_, err := sut.Resolve(ctx, newRequest("example.com.", A))
+			Expect(err).Should(Succeed())
+
+			m.AssertExpectations(GinkgoT())
+			Expect(responder.GetCallCount()).Should(BeZero())
+		})
+
+		When("LLMNR is enabled", func() {
+			BeforeEach(func() {
+				sutConfig.LLMNR = true
+			})
+
+			It("should answer single-label names", func() {
+				Expect(sut.Resolve(ctx, newRequest("nas.", A))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("nas.", A, "192.168.178.30"),
+							HaveReason("RESOLVED (LLMNR)"),
+						))
+			})
+
+			When("no responder answers", func() {
+				BeforeEach(func() {
+					llmnrResponder.WithAnswerRR()
+				})
+
+				It("should delegate to the next resolver", func() {
+					_, err := sut.Resolve(ctx, newRequest("unknown.", A))
+					Expect(err).Should(Succeed())
+
+					m.AssertExpectations(GinkgoT())
+				})
+			})
+		})
+
+		When("LLMNR is disabled", func() {
+			It("should delegate single-label names to the next resolver", func() {
+				_, err := sut.Resolve(ctx, newRequest("nas.", A))
+				Expect(err).Should(Succeed())
+
+				m.AssertExpectations(GinkgoT())
+				Expect(llmnrResponder.GetCallCount()).Should(BeZero())
+			})
+		})
+	})
+
+	Describe("mdnsAnswer", func() {
+		It("should only return records answering the question", func() {
+			response := new(dns.Msg)
+
+			for _, s := range []string{
+				"Printer.local. 10 IN A 192.168.178.20",
+				"printer.local. 10 IN AAAA fe80::1",
+				"other.local. 10 IN A 192.168.178.21",
+			} {
+				rr, err := dns.NewRR(s)
+				Expect(err).Should(Succeed())
+
+				response.Answer = append(response.Answer, rr)
+			}
+
+			answer := mdnsAnswer(dns.Question{Name: "printer.local.", Qtype: dns.TypeA, Qclass: dns.ClassINET}, response)
+
+			Expect(answer).Should(HaveLen(1))
+			Expect(answer[0].String()).Should(Equal("printer.local.\t10\tIN\tA\t192.168.178.20"))
+		})
+	})
+})
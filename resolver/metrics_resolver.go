@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/geoip"
 	"github.com/0xERR0R/blocky/metrics"
 	"github.com/0xERR0R/blocky/model"
 
@@ -27,13 +28,24 @@ type MetricsResolver struct {
 	totalResponse     *prometheus.CounterVec
 	totalErrors       prometheus.Counter
 	durationHistogram *prometheus.HistogramVec
+	ednsQueries       *prometheus.CounterVec
+
+	// only set if per client metrics are enabled
+	clientQueries     *prometheus.CounterVec
+	clientEDNSQueries *prometheus.CounterVec
+	clientGuard       *metrics.LabelGuard
+
+	// only set if an IP database is configured
+	geoIP         *geoip.DB
+	answerCountry *prometheus.CounterVec
+	answerASN     *prometheus.CounterVec
 }
 
 // Resolve resolves the passed request
 func (r *MetricsResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
 	response, err := r.next.Resolve(ctx, request)
 
-	if r.cfg.Enable {
+	if r.cfg.Enable && !isDryRun(ctx) && !isInternalQuery(ctx) {
 		r.totalQueries.With(prometheus.Labels{
 			"client": strings.Join(request.ClientNames, ","),
 			"type":   dns.TypeToString[request.Req.Question[0].Qtype],
@@ -48,6 +60,12 @@ func (r *MetricsResolver) Resolve(ctx context.Context, request *model.Request) (
 
 		r.durationHistogram.WithLabelValues(responseType).Observe(reqDuration.Seconds())
 
+		if r.clientQueries != nil {
+			r.clientQueries.WithLabelValues(r.clientGuard.Value(clientName(request)), responseType).Inc()
+		}
+
+		r.countEDNS(request)
+
 		if err != nil {
 			r.totalErrors.Inc()
 		} else {
@@ -56,6 +74,8 @@ func (r *MetricsResolver) Resolve(ctx context.Context, request *model.Request) (
 				"response_code": dns.RcodeToString[response.Res.Rcode],
 				"response_type": response.RType.String(),
 			}).Inc()
+
+			r.countAnswerGeo(response.Res.Answer)
 		}
 	}
 
@@ -63,7 +83,7 @@ func (r *MetricsResolver) Resolve(ctx context.Context, request *model.Request) (
 }
 
 // NewMetricsResolver creates a new intance of the MetricsResolver type
-func NewMetricsResolver(cfg config.Metrics) *MetricsResolver {
+func NewMetricsResolver(cfg config.Metrics, geoIP *geoip.DB) *MetricsResolver {
 	m := MetricsResolver{
 		configurable: withConfig(&cfg),
 		typed:        withType("metrics"),
@@ -72,6 +92,19 @@ func NewMetricsResolver(cfg config.Metrics) *MetricsResolver {
 		totalQueries:      totalQueriesMetric(),
 		totalResponse:     totalResponseMetric(),
 		totalErrors:       totalErrorMetric(),
+		ednsQueries:       ednsQueriesMetric(),
+	}
+
+	if cfg.PerClient {
+		m.clientQueries = clientQueriesMetric()
+		m.clientEDNSQueries = clientEDNSQueriesMetric()
+		m.clientGuard = metrics.NewLabelGuard("client", cfg.MaxLabelValues)
+	}
+
+	if geoIP != nil {
+		m.geoIP = geoIP
+		m.answerCountry = answerCountryMetric()
+		m.answerASN = answerASNMetric()
 	}
 
 	m.registerMetrics()
@@ -84,6 +117,97 @@ func (r *MetricsResolver) registerMetrics() {
 	metrics.RegisterMetric(r.totalQueries)
 	metrics.RegisterMetric(r.totalResponse)
 	metrics.RegisterMetric(r.totalErrors)
+	metrics.RegisterMetric(r.ednsQueries)
+
+	if r.clientQueries != nil {
+		metrics.RegisterMetric(r.clientQueries)
+		metrics.RegisterMetric(r.clientEDNSQueries)
+	}
+
+	if r.geoIP != nil {
+		metrics.RegisterMetric(r.answerCountry)
+		metrics.RegisterMetric(r.answerASN)
+	}
+}
+
+// countAnswerGeo counts the countries and ASNs of the answer, each once per response
+func (r *MetricsResolver) countAnswerGeo(answer []dns.RR) {
+	if r.geoIP == nil {
+		return
+	}
+
+	countries, asns := answerGeo(r.geoIP, answer)
+
+	for _, country := range countries {
+		r.answerCountry.WithLabelValues(country).Inc()
+	}
+
+	for _, asn := range asns {
+		r.answerASN.WithLabelValues(asn).Inc()
+	}
+}
+
+// countEDNS counts the query once for each EDNS feature it uses
+func (r *MetricsResolver) countEDNS(request *model.Request) {
+	edns := request.EDNS
+	if edns == nil {
+		return
+	}
+
+	features := map[string]bool{
+		"edns":   true,
+		"do":     edns.DO,
+		"ecs":    edns.ECS,
+		"cookie": edns.Cookie,
+	}
+
+	for feature, used := range features {
+		if !used {
+			continue
+		}
+
+		r.ednsQueries.WithLabelValues(feature).Inc()
+
+		if r.clientEDNSQueries != nil {
+			r.clientEDNSQueries.WithLabelValues(r.clientGuard.Value(clientName(request)), feature).Inc()
+		}
+	}
+}
+
+// clientName returns the first client name, or the IP if the client has no name
+func clientName(request *model.Request) string {
+	if len(request.ClientNames) > 0 && request.ClientNames[0] != "" {
+		return request.ClientNames[0]
+	}
+
+	return request.ClientIP.String()
+}
+
+func clientQueriesMetric() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_client_queries_total",
+			Help: "Number of queries per client",
+		}, []string{"client", "response_type"},
+	)
+}
+
+func ednsQueriesMetric() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_edns_queries_total",
+			Help: "Number of queries using an EDNS feature",
+		}, []string{"feature"},
+	)
+}
+
+func clientEDNSQueriesMetric() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_client_edns_queries_total",
+			Help: "Number of queries per client using an EDNS feature",
+		}, []string{"client", "feature"},
+	)
 }
 
 func totalQueriesMetric() *prometheus.CounterVec {
@@ -124,3 +248,21 @@ func totalResponseMetric() *prometheus.CounterVec {
 		}, []string{"reason", "response_code", "response_type"},
 	)
 }
+
+func answerCountryMetric() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_answer_country_total",
+			Help: "Number of responses with addresses in a country",
+		}, []string{"country"},
+	)
+}
+
+func answerASNMetric() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_answer_asn_total",
+			Help: "Number of responses with addresses of an autonomous system",
+		}, []string{"asn"},
+	)
+}
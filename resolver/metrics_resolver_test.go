@@ -5,11 +5,14 @@ import (
 	"errors"
 
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/geoip"
 	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/util"
 
 	. "github.com/0xERR0R/blocky/helpertest"
 	. "github.com/0xERR0R/blocky/model"
 
+	"github.com/maxmind/mmdbwriter/mmdbtype"
 	"github.com/miekg/dns"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -20,8 +23,9 @@ import (
 
 var _ = Describe("MetricResolver", func() {
 	var (
-		sut *MetricsResolver
-		m   *mockResolver
+		sut      *MetricsResolver
+		m        *mockResolver
+		sutGeoIP *geoip.DB
 
 		ctx      context.Context
 		cancelFn context.CancelFunc
@@ -37,7 +41,8 @@ var _ = Describe("MetricResolver", func() {
 		ctx, cancelFn = context.WithCancel(context.Background())
 		DeferCleanup(cancelFn)
 
-		sut = NewMetricsResolver(config.Metrics{Enable: true})
+		sutGeoIP = nil
+		sut = NewMetricsResolver(config.Metrics{Enable: true}, sutGeoIP)
 		m = &mockResolver{}
 		m.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg)}, nil)
 		sut.Next(m)
@@ -92,5 +97,88 @@ var _ = Describe("MetricResolver", func() {
 				})
 			})
 		})
+
+		Context("Recording answer geo metrics", func() {
+			BeforeEach(func() {
+				var err error
+
+				sutGeoIP, err = geoip.Open(ctx, &config.GeoIP{
+					ASNDatabase: TempMMDB(map[string]mmdbtype.Map{
+						"192.0.2.0/24": {"autonomous_system_number": mmdbtype.Uint32(64500)},
+					}),
+					CountryDatabase: TempMMDB(map[string]mmdbtype.Map{
+						"192.0.2.0/24": {"country": mmdbtype.Map{"iso_code": mmdbtype.String("DE")}},
+					}),
+				})
+				Expect(err).Should(Succeed())
+				DeferCleanup(sutGeoIP.Close)
+
+				answer, err := util.NewMsgWithAnswer("example.com.", 300, A, "192.0.2.1")
+				Expect(err).Should(Succeed())
+
+				m = &mockResolver{}
+				m.On("Resolve", mock.Anything).Return(&Response{Res: answer, RType: ResponseTypeRESOLVED}, nil)
+
+				sut = NewMetricsResolver(config.Metrics{Enable: true}, sutGeoIP)
+				sut.Next(m)
+			})
+
+			It("Should record the country and ASN of the answer", func() {
+				_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "", "client"))
+				Expect(err).Should(Succeed())
+
+				Expect(testutil.ToFloat64(sut.answerCountry.WithLabelValues("DE"))).Should(BeNumerically("==", 1))
+				Expect(testutil.ToFloat64(sut.answerASN.WithLabelValues("AS64500"))).Should(BeNumerically("==", 1))
+			})
+		})
+
+		Context("Recording per client metrics", func() {
+			BeforeEach(func() {
+				sut = NewMetricsResolver(config.Metrics{Enable: true, PerClient: true, MaxLabelValues: 1}, nil)
+				sut.Next(m)
+			})
+
+			It("Should record metrics per client up to the label limit", func() {
+				_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "1.2.3.4", "client1"))
+				Expect(err).Should(Succeed())
+				_, err = sut.Resolve(ctx, newRequestWithClient("example.com.", A, "1.2.3.5", "client2"))
+				Expect(err).Should(Succeed())
+
+				Expect(testutil.ToFloat64(sut.clientQueries.WithLabelValues("client1", "RESOLVED"))).
+					Should(BeNumerically("==", 1))
+				Expect(testutil.ToFloat64(sut.clientQueries.WithLabelValues("other", "RESOLVED"))).
+					Should(BeNumerically("==", 1))
+			})
+
+			It("Should record the EDNS features per client", func() {
+				request := newRequestWithClient("example.com.", A, "1.2.3.4", "client1")
+				request.EDNS = &EDNS{UDPSize: 1232, ECS: true}
+
+				_, err := sut.Resolve(ctx, request)
+				Expect(err).Should(Succeed())
+
+				Expect(testutil.ToFloat64(sut.clientEDNSQueries.WithLabelValues("client1", "ecs"))).
+					Should(BeNumerically("==", 1))
+				Expect(testutil.ToFloat64(sut.clientEDNSQueries.WithLabelValues("client1", "do"))).
+					Should(BeNumerically("==", 0))
+			})
+		})
+
+		Context("Recording EDNS metrics", func() {
+			It("Should count the queries per EDNS feature", func() {
+				request := newRequestWithClient("example.com.", A, "", "client")
+				request.EDNS = &EDNS{UDPSize: 1232, DO: true, Cookie: true}
+
+				_, err := sut.Resolve(ctx, request)
+				Expect(err).Should(Succeed())
+				_, err = sut.Resolve(ctx, newRequestWithClient("example.com.", A, "", "client"))
+				Expect(err).Should(Succeed())
+
+				Expect(testutil.ToFloat64(sut.ednsQueries.WithLabelValues("edns"))).Should(BeNumerically("==", 1))
+				Expect(testutil.ToFloat64(sut.ednsQueries.WithLabelValues("do"))).Should(BeNumerically("==", 1))
+				Expect(testutil.ToFloat64(sut.ednsQueries.WithLabelValues("cookie"))).Should(BeNumerically("==", 1))
+				Expect(testutil.ToFloat64(sut.ednsQueries.WithLabelValues("ecs"))).Should(BeNumerically("==", 0))
+			})
+		})
 	})
 })
@@ -0,0 +1,237 @@
+package resolver
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/metrics"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// results of mirrored queries
+const (
+	mirrorResultMatch          = "match"
+	mirrorResultRcodeMismatch  = "rcode_mismatch"
+	mirrorResultAnswerMismatch = "answer_mismatch"
+	mirrorResultError          = "error"
+	mirrorResultSkipped        = "skipped"
+)
+
+// MirrorResolver sends a percentage of the queries also to a shadow upstream and reports the responses diverging
+// from the ones of the next resolver. The shadow responses never reach the clients.
+type MirrorResolver struct {
+	configurable[*config.Mirror]
+	NextResolver
+	typed
+
+	shadow   Resolver
+	timeout  time.Duration
+	inFlight chan struct{}
+	sample   func() bool
+
+	queries  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// mirrorResult is the result of a query, `response` is a copy which later resolvers can't modify
+type mirrorResult struct {
+	response *dns.Msg
+	err      error
+	duration time.Duration
+}
+
+// NewMirrorResolver creates a new resolver instance
+func NewMirrorResolver(
+	ctx context.Context, cfg config.Mirror, upstreamsCfg config.Upstreams, bootstrap *Bootstrap,
+) (*MirrorResolver, error) {
+	r := &MirrorResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("mirror"),
+	}
+
+	if !cfg.IsEnabled() {
+		return r, nil
+	}
+
+	shadow, err := NewUpstreamResolver(ctx, newUpstreamConfig(cfg.Upstream, upstreamsCfg), bootstrap)
+	if err != nil {
+		return nil, err
+	}
+
+	r.shadow = shadow
+	r.timeout = upstreamsCfg.Timeout.ToDuration()
+	r.inFlight = make(chan struct{}, cfg.MaxInFlight)
+	r.sample = func() bool {
+		return uint(rand.Intn(100)) < cfg.Percentage //nolint:gosec,mnd // pseudo-randomness is good enough
+	}
+
+	r.queries = mirrorQueriesMetric()
+	r.duration = mirrorDurationMetric()
+
+	metrics.RegisterMetric(r.queries)
+	metrics.RegisterMetric(r.duration)
+
+	return r, nil
+}
+
+// Resolve passes the request to the next resolver and mirrors it to the shadow upstream, if it is sampled
+func (r *MirrorResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if r.shadow == nil || isDryRun(ctx) || !r.sample() {
+		return r.next.Resolve(ctx, request)
+	}
+
+	select {
+	case r.inFlight <- struct{}{}:
+	default:
+		// the shadow upstream is too slow, don't pile up goroutines
+		r.queries.WithLabelValues(mirrorResultSkipped).Inc()
+
+		return r.next.Resolve(ctx, request)
+	}
+
+	primary := make(chan mirrorResult, 1)
+
+	shadowRequest := &model.Request{
+		ClientIP:    request.ClientIP,
+		ClientNames: request.ClientNames,
+		Protocol:    request.Protocol,
+		Req:         request.Req.Copy(),
+		RequestTS:   time.Now(),
+	}
+
+	// the shadow query must not be canceled with the client's query
+	go r.mirror(context.WithoutCancel(ctx), shadowRequest, primary)
+
+	start := time.Now()
+	response, err := r.next.Resolve(ctx, request)
+
+	result := mirrorResult{err: err, duration: time.Since(start)}
+	if err == nil {
+		result.response = response.Res.Copy()
+	}
+
+	primary <- result
+
+	return response, err
+}
+
+func (r *MirrorResolver) mirror(ctx context.Context, request *model.Request, primaryCh <-chan mirrorResult) {
+	defer func() { <-r.inFlight }()
+
+	ctx, logger := r.log(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	shadow := mirrorResult{}
+
+	response, err := r.shadow.Resolve(ctx, request)
+	if err == nil {
+		shadow.response = response.Res
+	}
+
+	shadow.err = err
+	shadow.duration = time.Since(start)
+
+	primary := <-primaryCh
+
+	r.duration.WithLabelValues("blocky").Observe(primary.duration.Seconds())
+	r.duration.WithLabelValues("shadow").Observe(shadow.duration.Seconds())
+
+	result := compareMirrorResults(primary, shadow)
+	r.queries.WithLabelValues(result).Inc()
+
+	if result == mirrorResultMatch {
+		return
+	}
+
+	question := request.Req.Question[0]
+
+	logger = logger.WithFields(logrus.Fields{
+		"question":        util.Obfuscate(question.Name),
+		"type":            dns.TypeToString[question.Qtype],
+		"result":          result,
+		"blocky_duration": primary.duration.Milliseconds(),
+		"shadow_duration": shadow.duration.Milliseconds(),
+	})
+
+	if result == mirrorResultError {
+		logger.WithFields(logrus.Fields{
+			"blocky_error": primary.err,
+			"shadow_error": shadow.err,
+		}).Debug("mirrored query failed")
+
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"blocky_response": mirrorResponseString(primary.response),
+		"shadow_response": mirrorResponseString(shadow.response),
+	}).Info("shadow upstream response diverges")
+}
+
+// compareMirrorResults compares the return codes and answers, ignoring the TTLs and the order of the records
+func compareMirrorResults(primary, shadow mirrorResult) string {
+	switch {
+	case primary.err != nil || shadow.err != nil:
+		return mirrorResultError
+	case primary.response.Rcode != shadow.response.Rcode:
+		return mirrorResultRcodeMismatch
+	case !slices.Equal(normalizedAnswer(primary.response), normalizedAnswer(shadow.response)):
+		return mirrorResultAnswerMismatch
+	}
+
+	return mirrorResultMatch
+}
+
+func normalizedAnswer(msg *dns.Msg) []string {
+	result := make([]string, 0, len(msg.Answer))
+
+	for _, rr := range msg.Answer {
+		rr = dns.Copy(rr)
+		rr.Header().Ttl = 0
+		rr.Header().Name = strings.ToLower(rr.Header().Name)
+
+		result = append(result, rr.String())
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+func mirrorResponseString(msg *dns.Msg) string {
+	return dns.RcodeToString[msg.Rcode] + " " + util.AnswerToString(msg.Answer)
+}
+
+func mirrorQueriesMetric() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "blocky_mirror_queries_total",
+			Help: "Number of queries mirrored to the shadow upstream by result of the comparison",
+		}, []string{"result"},
+	)
+}
+
+func mirrorDurationMetric() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:                        "blocky_mirror_duration_seconds",
+			Help:                        "Duration distribution of the mirrored queries of blocky and the shadow upstream",
+			Buckets:                     []float64{0.005, 0.01, 0.02, 0.03, 0.05, 0.075, 0.1, 0.2, 0.5, 1.0, 2.0},
+			NativeHistogramBucketFactor: nativeHistogramBucketFactor,
+		},
+		[]string{"resolver"},
+	)
+}
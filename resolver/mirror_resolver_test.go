@@ -0,0 +1,248 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("MirrorResolver", func() {
+	var (
+		sut       *MirrorResolver
+		sutConfig config.Mirror
+		m         *mockResolver
+
+		shadowUpstream *MockUDPUpstreamServer
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		var err error
+
+		sutConfig, err = config.WithDefaults[config.Mirror]()
+		Expect(err).Should(Succeed())
+
+		shadowUpstream = NewMockUDPUpstreamServer().WithAnswerRR("example.com. 300 IN A 123.122.121.120")
+		sutConfig.Upstream = shadowUpstream.Start()
+		sutConfig.Percentage = 100
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = NewMirrorResolver(ctx, sutConfig, defaultUpstreamsConfig, systemResolverBootstrap)
+		Expect(err).Should(Succeed())
+
+		// ignore the queries used to test the upstream on start
+		shadowUpstream.ResetCallCount()
+
+		m = &mockResolver{AnswerFn: func(dns.Type, string) (*dns.Msg, error) {
+			return new(dns.Msg), nil
+		}}
+		m.On("Resolve", mock.Anything)
+		sut.Next(m)
+	})
+
+	newRR := func(s string) dns.RR {
+		rr, err := dns.NewRR(s)
+		Expect(err).Should(Succeed())
+
+		return rr
+	}
+
+	resolvedBy := func(answer string) {
+		m.AnswerFn = func(dns.Type, string) (*dns.Msg, error) {
+			msg := new(dns.Msg)
+			msg.Answer = append(msg.Answer, newRR(answer))
+
+			return msg, nil
+		}
+	}
+
+	Describe("IsEnabled", func() {
+		It("is true", func() {
+			Expect(sut.IsEnabled()).Should(BeTrue())
+		})
+
+		When("no upstream is configured", func() {
+			BeforeEach(func() {
+				sutConfig.Upstream = config.Upstream{}
+			})
+
+			It("is false", func() {
+				Expect(sut.IsEnabled()).Should(BeFalse())
+			})
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	Describe("Resolve", func() {
+		When("disabled", func() {
+			BeforeEach(func() {
+				sutConfig.Percentage = 0
+			})
+
+			It("should only call the next resolver", func() {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(
+						SatisfyAll(
+							HaveResponseType(ResponseTypeRESOLVED),
+							HaveNoAnswer(),
+						))
+
+				m.AssertExpectations(GinkgoT())
+				Expect(shadowUpstream.GetCallCount()).Should(BeZero())
+			})
+		})
+
+		When("the responses match", func() {
+			It("should count a match", func() {
+				resolvedBy("example.com. 600 IN A 123.122.121.120")
+
+				Expect(sut.Resolve(ctx, newRequest("Example.com.", A))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("example.com.", A, "123.122.121.120"),
+							HaveTTL(BeNumerically("==", 600)),
+							HaveResponseType(ResponseTypeRESOLVED),
+						))
+
+				Eventually(func() float64 {
+					return testutil.ToFloat64(sut.queries.WithLabelValues(mirrorResultMatch))
+				}).Should(BeNumerically("==", 1))
+				Expect(shadowUpstream.GetCallCount()).Should(Equal(1))
+			})
+		})
+
+		When("the answers diverge", func() {
+			It("should return the response of the next resolver and count the divergence", func() {
+				resolvedBy("example.com. 300 IN A 1.2.3.4")
+
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(BeDNSRecord("example.com.", A, "1.2.3.4"))
+
+				Eventually(func() float64 {
+					return testutil.ToFloat64(sut.queries.WithLabelValues(mirrorResultAnswerMismatch))
+				}).Should(BeNumerically("==", 1))
+			})
+		})
+
+		When("the next resolver fails", func() {
+			It("should return the error and count it", func() {
+				m.AnswerFn = func(dns.Type, string) (*dns.Msg, error) {
+					return nil, errors.New("boom")
+				}
+
+				_, err := sut.Resolve(ctx, newRequest("example.com.", A))
+				Expect(err).Should(MatchError(ContainSubstring("boom")))
+
+				Eventually(func() float64 {
+					return testutil.ToFloat64(sut.queries.WithLabelValues(mirrorResultError))
+				}).Should(BeNumerically("==", 1))
+			})
+		})
+
+		When("too many mirrored queries are in flight", func() {
+			BeforeEach(func() {
+				sutConfig.MaxInFlight = 1
+			})
+
+			It("should skip mirroring", func() {
+				sut.inFlight <- struct{}{}
+
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Expect(testutil.ToFloat64(sut.queries.WithLabelValues(mirrorResultSkipped))).
+					Should(BeNumerically("==", 1))
+				Expect(shadowUpstream.GetCallCount()).Should(BeZero())
+			})
+		})
+
+		When("the query is not sampled", func() {
+			It("should not mirror it", func() {
+				sut.sample = func() bool { return false }
+
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Consistently(shadowUpstream.GetCallCount, 100*time.Millisecond).Should(BeZero())
+			})
+		})
+	})
+
+	Describe("compareMirrorResults", func() {
+		msgWith := func(rcode int, rrs ...string) *dns.Msg {
+			msg := new(dns.Msg)
+			msg.Rcode = rcode
+
+			for _, rr := range rrs {
+				msg.Answer = append(msg.Answer, newRR(rr))
+			}
+
+			return msg
+		}
+
+		It("should ignore the order, TTLs and case", func() {
+			primary := mirrorResult{response: msgWith(dns.RcodeSuccess,
+				"example.com. 300 IN A 1.1.1.1", "example.com. 300 IN A 2.2.2.2")}
+			shadow := mirrorResult{response: msgWith(dns.RcodeSuccess,
+				"EXAMPLE.com. 20 IN A 2.2.2.2", "example.com. 10 IN A 1.1.1.1")}
+
+			Expect(compareMirrorResults(primary, shadow)).Should(Equal(mirrorResultMatch))
+		})
+
+		It("should detect different return codes", func() {
+			primary := mirrorResult{response: msgWith(dns.RcodeSuccess)}
+			shadow := mirrorResult{response: msgWith(dns.RcodeNameError)}
+
+			Expect(compareMirrorResults(primary, shadow)).Should(Equal(mirrorResultRcodeMismatch))
+		})
+
+		It("should detect different answers", func() {
+			primary := mirrorResult{response: msgWith(dns.RcodeSuccess, "example.com. 300 IN A 1.1.1.1")}
+			shadow := mirrorResult{response: msgWith(dns.RcodeSuccess)}
+
+			Expect(compareMirrorResults(primary, shadow)).Should(Equal(mirrorResultAnswerMismatch))
+		})
+
+		It("should report errors", func() {
+			primary := mirrorResult{response: msgWith(dns.RcodeSuccess)}
+			shadow := mirrorResult{err: errors.New("timeout")}
+
+			Expect(compareMirrorResults(primary, shadow)).Should(Equal(mirrorResultError))
+		})
+	})
+})
@@ -133,7 +133,7 @@ func (t *MockUDPUpstreamServer) Start() config.Upstream {
 
 			go func() {
 				msg := new(dns.Msg)
-				err = msg.Unpack(buffer[0 : n-1])
+				err = msg.Unpack(buffer[:n])
 
 				util.FatalOnError("can't deserialize message: ", err)
 
@@ -0,0 +1,411 @@
+package resolver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/cache/expirationcache"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	newlyObservedReason   = "NEWLY OBSERVED DOMAIN"
+	newlyRegisteredReason = "NEWLY REGISTERED DOMAIN"
+
+	// failed RDAP lookups are retried after this time
+	rdapFailureCacheTime = 5 * time.Minute
+
+	hoursPerDay = 24
+)
+
+// NewDomainsResolver flags or blocks queries for domains which were never queried before on this instance
+// or which were registered recently
+type NewDomainsResolver struct {
+	configurable[*config.NewDomains]
+	NextResolver
+	typed
+
+	seen          *seenDomains         // nil if disabled
+	registrations *domainRegistrations // nil if disabled
+}
+
+// NewNewDomainsResolver creates a new resolver instance
+func NewNewDomainsResolver(ctx context.Context, cfg config.NewDomains, bootstrap *Bootstrap,
+) (*NewDomainsResolver, error) {
+	r := &NewDomainsResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("new_domains"),
+	}
+
+	if cfg.FirstSeen.Enable {
+		seen, err := newSeenDomains(ctx, cfg.FirstSeen)
+		if err != nil {
+			return nil, err
+		}
+
+		r.seen = seen
+	}
+
+	if cfg.Registered.IsEnabled() {
+		r.registrations = newDomainRegistrations(ctx, cfg.Registered, &http.Client{
+			Transport: bootstrap.NewHTTPTransport(),
+			Timeout:   cfg.Registered.Timeout.ToDuration(),
+		})
+	}
+
+	return r, nil
+}
+
+// LogConfig implements `config.Configurable`.
+func (r *NewDomainsResolver) LogConfig(logger *logrus.Entry) {
+	r.cfg.LogConfig(logger)
+
+	if r.seen != nil {
+		logger.Infof("seen domains = %d", r.seen.count())
+	}
+}
+
+// Resolve flags the query if its domain is new, and blocks it depending on the action
+func (r *NewDomainsResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	domain := util.ExtractDomain(request.Req.Question[0])
+
+	registrable, ok := registrableDomain(domain)
+	if !ok || matchesDomainList(r.cfg.Allowlist, domain) {
+		return r.next.Resolve(ctx, request)
+	}
+
+	kind, reason := r.check(ctx, registrable)
+	if reason == "" {
+		return r.next.Resolve(ctx, request)
+	}
+
+	message := fmt.Sprintf("%s, action: %s", strings.ToLower(reason), r.cfg.Action)
+
+	logger.WithField("domain", util.Obfuscate(registrable)).Info(message)
+	r.trace(ctx, "%s", message)
+
+	if !isDryRun(ctx) {
+		evt.Bus().Publish(evt.NewDomainDetected, kind)
+	}
+
+	if r.cfg.Action != config.NewDomainActionBlock {
+		return r.next.Resolve(ctx, request)
+	}
+
+	msg := new(dns.Msg)
+	msg.SetRcode(request.Req, dns.RcodeNameError)
+
+	return &model.Response{Res: msg, RType: model.ResponseTypeBLOCKED, Reason: reason}, nil
+}
+
+// check returns the kind of new domain ("observed" or "registered") and the reason, empty if the domain isn't new
+func (r *NewDomainsResolver) check(ctx context.Context, domain string) (kind, reason string) {
+	now := time.Now()
+
+	// a dry run must not change what is seen
+	if r.seen != nil && r.seen.isNew(domain, now, !isDryRun(ctx)) {
+		return "observed", newlyObservedReason
+	}
+
+	if r.registrations != nil {
+		registered, found := r.registrations.registered(ctx, domain)
+		if age := now.Sub(registered); found && age < r.cfg.Registered.MaxAge.ToDuration() {
+			return "registered", fmt.Sprintf("%s (%d days)", newlyRegisteredReason, int(age.Hours()/hoursPerDay))
+		}
+	}
+
+	return "", ""
+}
+
+// registrableDomain returns the part of the domain below its public suffix, e.g. `example.co.uk` for
+// `www.example.co.uk`. Domains with private or unknown suffixes, like `lan` or `in-addr.arpa`, are skipped.
+func registrableDomain(domain string) (string, bool) {
+	suffix, icann := publicsuffix.PublicSuffix(domain)
+	if !icann || suffix == "arpa" || strings.HasSuffix(suffix, ".arpa") {
+		return "", false
+	}
+
+	registrable, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		return "", false
+	}
+
+	return registrable, true
+}
+
+// seenDomains records when the registrable domains were queried for the first time
+type seenDomains struct {
+	cfg config.FirstSeenDomains
+
+	lock        sync.Mutex
+	firstSeen   map[string]time.Time
+	learningEnd time.Time
+	file        *os.File // nil without path
+}
+
+func newSeenDomains(ctx context.Context, cfg config.FirstSeenDomains) (*seenDomains, error) {
+	s := &seenDomains{
+		cfg:       cfg,
+		firstSeen: make(map[string]time.Time),
+	}
+
+	start := time.Now()
+
+	if cfg.Path != "" {
+		earliest, err := s.load(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		// the learning period started with the first recorded domain
+		if earliest.Before(start) {
+			start = earliest
+		}
+
+		s.file, err = os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("can't open seen domains: %w", err)
+		}
+
+		go func() {
+			<-ctx.Done()
+
+			s.lock.Lock()
+			defer s.lock.Unlock()
+
+			s.file.Close()
+			s.file = nil
+		}()
+	}
+
+	s.learningEnd = start.Add(cfg.LearningPeriod.ToDuration())
+
+	return s, nil
+}
+
+// load reads the seen domains, one `<domain> <unix time>` per line, and returns the earliest time
+func (s *seenDomains) load(path string) (earliest time.Time, err error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return earliest, nil
+	}
+
+	if err != nil {
+		return earliest, fmt.Errorf("can't read seen domains: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		domain, unix, found := strings.Cut(scanner.Text(), " ")
+		if !found {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(unix, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		t := time.Unix(seconds, 0)
+		s.firstSeen[domain] = t
+
+		if earliest.IsZero() || t.Before(earliest) {
+			earliest = t
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return earliest, fmt.Errorf("can't read seen domains: %w", err)
+	}
+
+	return earliest, nil
+}
+
+// isNew returns true if the domain was first queried within the flag period, after the learning period.
+// Unless `record` is false, the domain is recorded as seen.
+func (s *seenDomains) isNew(domain string, now time.Time, record bool) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	first, found := s.firstSeen[domain]
+	if !found {
+		first = now
+
+		if record {
+			s.firstSeen[domain] = now
+			s.persist(domain, now)
+		}
+	}
+
+	return !first.Before(s.learningEnd) && now.Sub(first) < s.cfg.FlagPeriod.ToDuration()
+}
+
+func (s *seenDomains) persist(domain string, t time.Time) {
+	if s.file == nil {
+		return
+	}
+
+	if _, err := fmt.Fprintf(s.file, "%s %d\n", domain, t.Unix()); err != nil {
+		log.PrefixedLog("new_domains").Warn("can't record seen domain: ", err)
+	}
+}
+
+func (s *seenDomains) count() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.firstSeen)
+}
+
+// domainRegistrations looks up the registration dates of domains with RDAP and caches them
+type domainRegistrations struct {
+	cfg    config.RegisteredDomains
+	client *http.Client
+
+	// zero time if the date is unknown
+	cache expirationcache.ExpiringCache[time.Time]
+
+	lookupsLock sync.Mutex
+	lookups     map[string]chan struct{}
+}
+
+func newDomainRegistrations(ctx context.Context, cfg config.RegisteredDomains, client *http.Client,
+) *domainRegistrations {
+	return &domainRegistrations{
+		cfg:    cfg,
+		client: client,
+		cache: expirationcache.NewCache[time.Time](ctx, expirationcache.Options{
+			CleanupInterval: time.Hour,
+		}),
+		lookups: make(map[string]chan struct{}),
+	}
+}
+
+// registered returns the registration date of the domain, false if it is unknown or the lookup takes longer
+// than the max wait time
+func (d *domainRegistrations) registered(ctx context.Context, domain string) (time.Time, bool) {
+	if t, ttl := d.cache.Get(domain); t != nil && ttl > 0 {
+		return *t, !t.IsZero()
+	}
+
+	done := d.lookup(ctx, domain)
+
+	wait := time.NewTimer(d.cfg.MaxWait.ToDuration())
+	defer wait.Stop()
+
+	select {
+	case <-done:
+		if t, _ := d.cache.Get(domain); t != nil {
+			return *t, !t.IsZero()
+		}
+
+	case <-wait.C:
+	case <-ctx.Done():
+	}
+
+	return time.Time{}, false
+}
+
+// lookup starts the RDAP lookup of the domain in the background, unless it's already in progress
+func (d *domainRegistrations) lookup(ctx context.Context, domain string) <-chan struct{} {
+	d.lookupsLock.Lock()
+	defer d.lookupsLock.Unlock()
+
+	if done, ok := d.lookups[domain]; ok {
+		return done
+	}
+
+	done := make(chan struct{})
+	d.lookups[domain] = done
+
+	// the query can be done before the lookup
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		registered, err := d.fetch(ctx, domain)
+		if err != nil {
+			log.PrefixedLog("new_domains").WithField("domain", util.Obfuscate(domain)).
+				Debug("RDAP lookup failed: ", err)
+
+			d.cache.Put(domain, &time.Time{}, rdapFailureCacheTime)
+		} else {
+			d.cache.Put(domain, &registered, d.cfg.CacheTime.ToDuration())
+		}
+
+		d.lookupsLock.Lock()
+		delete(d.lookups, domain)
+		d.lookupsLock.Unlock()
+
+		close(done)
+	}()
+
+	return done
+}
+
+// fetch returns the registration date of the domain, the zero time if RDAP doesn't know it
+func (d *domainRegistrations) fetch(ctx context.Context, domain string) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.cfg.RDAPURL+domain, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return time.Time{}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("got status code %d", resp.StatusCode)
+	}
+
+	var data struct {
+		Events []struct {
+			Action string    `json:"eventAction"`
+			Date   time.Time `json:"eventDate"`
+		} `json:"events"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return time.Time{}, fmt.Errorf("invalid RDAP response: %w", err)
+	}
+
+	for _, event := range data.Events {
+		if event.Action == "registration" {
+			return event.Date, nil
+		}
+	}
+
+	return time.Time{}, nil
+}
@@ -0,0 +1,258 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/evt"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("NewDomainsResolver", func() {
+	var (
+		sut       *NewDomainsResolver
+		sutConfig config.NewDomains
+		m         *mockResolver
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		var err error
+
+		sutConfig, err = config.WithDefaults[config.NewDomains]()
+		Expect(err).Should(Succeed())
+
+		sutConfig.FirstSeen.Enable = true
+		sutConfig.FirstSeen.LearningPeriod = 0
+		sutConfig.Allowlist = []string{"example.org"}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = NewNewDomainsResolver(ctx, sutConfig, systemResolverBootstrap)
+		Expect(err).Should(Succeed())
+
+		m = &mockResolver{}
+		m.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg), RType: ResponseTypeRESOLVED}, nil)
+
+		sut.Next(m)
+	})
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	Describe("IsEnabled", func() {
+		It("is false by default", func() {
+			sut, err := NewNewDomainsResolver(ctx, config.NewDomains{}, systemResolverBootstrap)
+			Expect(err).Should(Succeed())
+
+			Expect(sut.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	Describe("first seen domains", func() {
+		It("should flag the first queries of a domain and resolve them", func() {
+			var detected atomic.Value
+
+			handler := func(kind string) { detected.Store(kind) }
+			Expect(Bus().Subscribe(NewDomainDetected, handler)).Should(Succeed())
+			DeferCleanup(Bus().Unsubscribe, NewDomainDetected, handler)
+
+			Expect(sut.Resolve(ctx, newRequest("www.example.com.", A))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(detected.Load()).Should(Equal("observed"))
+
+			// the flag period applies to the registrable domain
+			Expect(sut.seen.isNew("example.com", time.Now(), true)).Should(BeTrue())
+			Expect(sut.seen.isNew("example.com", time.Now().Add(2*time.Hour), true)).Should(BeFalse())
+		})
+
+		It("should skip allowed domains and domains without public suffix", func() {
+			Expect(sut.Resolve(ctx, newRequest("www.example.org.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(sut.Resolve(ctx, newRequest("nas.lan.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(sut.Resolve(ctx, newRequest("1.1.168.192.in-addr.arpa.", PTR))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(sut.seen.count()).Should(BeZero())
+		})
+
+		When("the action is block", func() {
+			BeforeEach(func() {
+				sutConfig.Action = config.NewDomainActionBlock
+			})
+
+			It("should answer NXDOMAIN", func() {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(SatisfyAll(
+						HaveNoAnswer(),
+						HaveReturnCode(dns.RcodeNameError),
+						HaveResponseType(ResponseTypeBLOCKED),
+						HaveReason("NEWLY OBSERVED DOMAIN"),
+					))
+				m.AssertNotCalled(GinkgoT(), "Resolve", mock.Anything)
+			})
+		})
+
+		When("in the learning period", func() {
+			BeforeEach(func() {
+				sutConfig.FirstSeen.LearningPeriod = config.Duration(time.Hour)
+			})
+
+			It("should only record the domains", func() {
+				Expect(sut.seen.isNew("example.com", time.Now(), true)).Should(BeFalse())
+				Expect(sut.seen.isNew("example.com", time.Now().Add(90*time.Minute), true)).Should(BeFalse())
+				Expect(sut.seen.isNew("example.net", time.Now().Add(90*time.Minute), true)).Should(BeTrue())
+			})
+		})
+
+		When("the domains are recorded in a file", func() {
+			var path string
+
+			BeforeEach(func() {
+				path = filepath.Join(GinkgoT().TempDir(), "seen.txt")
+				sutConfig.FirstSeen.LearningPeriod = config.Duration(time.Hour)
+
+				old := time.Now().Add(-2 * time.Hour).Unix()
+				Expect(os.WriteFile(path, []byte(fmt.Sprintf("example.com %d\ninvalid\n", old)), 0o600)).
+					Should(Succeed())
+
+				sutConfig.FirstSeen.Path = path
+			})
+
+			It("should load them and continue the learning period", func() {
+				Expect(sut.seen.count()).Should(Equal(1))
+				Expect(sut.seen.isNew("example.com", time.Now(), true)).Should(BeFalse())
+				Expect(sut.seen.isNew("example.net", time.Now(), true)).Should(BeTrue())
+
+				Expect(os.ReadFile(path)).Should(ContainSubstring("example.net "))
+			})
+		})
+
+		When("it is a dry run", func() {
+			It("should not record the domain", func() {
+				ctx, _ := WithTrace(ctx)
+
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+				Expect(sut.seen.count()).Should(BeZero())
+			})
+		})
+	})
+
+	Describe("registered domains", func() {
+		var lookups atomic.Int32
+
+		BeforeEach(func() {
+			lookups.Store(0)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lookups.Add(1)
+
+				var registered time.Time
+
+				switch r.URL.Path {
+				case "/domain/new.com":
+					registered = time.Now().Add(-3 * 24 * time.Hour)
+				case "/domain/old.com":
+					registered = time.Now().Add(-3 * 365 * 24 * time.Hour)
+				case "/domain/slow.com":
+					time.Sleep(100 * time.Millisecond)
+
+					registered = time.Now()
+				default:
+					w.WriteHeader(http.StatusNotFound)
+
+					return
+				}
+
+				_, _ = fmt.Fprintf(w, `{"events": [{"eventAction": "expiration", "eventDate": "2099-01-01T00:00:00Z"},
+					{"eventAction": "registration", "eventDate": "%s"}]}`, registered.Format(time.RFC3339))
+			}))
+			DeferCleanup(server.Close)
+
+			sutConfig.FirstSeen.Enable = false
+			sutConfig.Action = config.NewDomainActionBlock
+			sutConfig.Registered.MaxAge = config.Duration(30 * 24 * time.Hour)
+			sutConfig.Registered.RDAPURL = server.URL + "/domain/"
+			sutConfig.Registered.MaxWait = config.Duration(time.Second)
+		})
+
+		JustBeforeEach(func() {
+			bootstrap := &Bootstrap{
+				dialer:       &net.Dialer{},
+				configurable: withConfig(newBootstrapConfig(&config.Config{Upstreams: defaultUpstreamsConfig})),
+			}
+
+			var err error
+
+			sut, err = NewNewDomainsResolver(ctx, sutConfig, bootstrap)
+			Expect(err).Should(Succeed())
+			sut.Next(m)
+		})
+
+		It("should block domains registered within the max age", func() {
+			Expect(sut.Resolve(ctx, newRequest("www.new.com.", A))).
+				Should(SatisfyAll(
+					HaveResponseType(ResponseTypeBLOCKED),
+					HaveReason("NEWLY REGISTERED DOMAIN (3 days)"),
+				))
+
+			Expect(sut.Resolve(ctx, newRequest("old.com.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(sut.Resolve(ctx, newRequest("unknown.com.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+		})
+
+		It("should cache the registration dates", func() {
+			for range 3 {
+				Expect(sut.Resolve(ctx, newRequest("new.com.", A))).Should(HaveResponseType(ResponseTypeBLOCKED))
+			}
+
+			Expect(lookups.Load()).Should(BeEquivalentTo(1))
+		})
+
+		When("the lookup takes longer than the max wait time", func() {
+			BeforeEach(func() {
+				sutConfig.Registered.MaxWait = config.Duration(time.Millisecond)
+			})
+
+			It("should resolve the query and use the date once it's known", func() {
+				Expect(sut.Resolve(ctx, newRequest("slow.com.", A))).Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				Eventually(func(g Gomega) {
+					g.Expect(sut.Resolve(ctx, newRequest("slow.com.", A))).Should(HaveResponseType(ResponseTypeBLOCKED))
+				}, "1s").Should(Succeed())
+			})
+		})
+	})
+})
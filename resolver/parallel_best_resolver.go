@@ -10,10 +10,12 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/0xERR0R/blocky/api"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/util"
+	"github.com/0xERR0R/blocky/watchdog"
 
 	"github.com/mroth/weightedrand/v2"
 	"github.com/sirupsen/logrus"
@@ -23,6 +25,7 @@ const (
 	upstreamDefaultCfgName    = config.UpstreamDefaultCfgName
 	parallelResolverType      = "parallel_best"
 	randomResolverType        = "random"
+	weightedResolverType      = "weighted"
 	parallelBestResolverCount = 2
 )
 
@@ -38,13 +41,18 @@ type ParallelBestResolver struct {
 }
 
 type upstreamResolverStatus struct {
-	resolver      Resolver
-	lastErrorTime atomic.Value
+	resolver       Resolver
+	lastErrorTime  atomic.Value
+	health         *upstreamHealth
+	weight         uint // static weight, only used by the weighted strategy
+	circuitBreaker config.UpstreamCircuitBreaker
 }
 
 func newUpstreamResolverStatus(resolver Resolver) *upstreamResolverStatus {
 	status := &upstreamResolverStatus{
 		resolver: resolver,
+		health:   newUpstreamHealth(),
+		weight:   1,
 	}
 
 	status.lastErrorTime.Store(time.Unix(0, 0))
@@ -63,20 +71,32 @@ func newUpstreamResolverStatuses(resolvers []Resolver) []*upstreamResolverStatus
 }
 
 func (r *upstreamResolverStatus) resolve(ctx context.Context, req *model.Request) (*model.Response, error) {
+	start := time.Now()
+
 	resp, err := r.resolver.Resolve(ctx, req)
 	if err != nil {
 		// Ignore `Canceled`: resolver lost the race, not an error
 		if !errors.Is(err, context.Canceled) {
 			r.lastErrorTime.Store(time.Now())
+
+			if r.health.recordQuery(err, time.Since(start), r.circuitBreaker) {
+				log.FromCtx(ctx).WithField("upstream", r.upstreamName()).Warnf(
+					"%d consecutive queries failed, removing upstream from rotation for %s",
+					r.circuitBreaker.FailureThreshold, r.circuitBreaker.Duration)
+			}
 		}
 
 		return nil, fmt.Errorf("%s: %w", r.resolver, err)
 	}
 
+	r.health.recordQuery(nil, time.Since(start), r.circuitBreaker)
+
 	return resp, nil
 }
 
 func (r *upstreamResolverStatus) resolveToChan(ctx context.Context, req *model.Request, ch chan<- requestResponse) {
+	defer watchdog.TrackWorker("upstream_queries")()
+
 	resp, err := r.resolve(ctx, req)
 
 	ch <- requestResponse{
@@ -105,12 +125,17 @@ func NewParallelBestResolver(
 }
 
 func newParallelBestResolver(cfg config.UpstreamGroup, resolvers []Resolver) *ParallelBestResolver {
-	typeName := "parallel_best"
+	typeName := parallelResolverType
 	resolverCount := parallelBestResolverCount
 	retryWithDifferentResolver := false
 
-	if cfg.Strategy == config.UpstreamStrategyRandom {
-		typeName = "random"
+	switch cfg.Strategy {
+	case config.UpstreamStrategyRandom:
+		typeName = randomResolverType
+		resolverCount = 1
+		retryWithDifferentResolver = true
+	case config.UpstreamStrategyWeighted:
+		typeName = weightedResolverType
 		resolverCount = 1
 		retryWithDifferentResolver = true
 	}
@@ -132,6 +157,11 @@ func (r *ParallelBestResolver) setResolvers(resolvers []*upstreamResolverStatus)
 	r.resolvers.Store(&resolvers)
 }
 
+// UpstreamStatus implements `api.UpstreamStatusProvider`.
+func (r *ParallelBestResolver) UpstreamStatus() []api.UpstreamStatus {
+	return healthStatus(r.cfg.Name, *r.resolvers.Load())
+}
+
 func (r *ParallelBestResolver) Name() string {
 	return r.String()
 }
@@ -151,7 +181,7 @@ func (r *ParallelBestResolver) String() string {
 func (r *ParallelBestResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
 	ctx, logger := r.log(ctx)
 
-	allResolvers := *r.resolvers.Load()
+	allResolvers := healthyOrAll(*r.resolvers.Load())
 
 	if len(allResolvers) == 1 {
 		resolver := allResolvers[0]
@@ -181,7 +211,7 @@ func (r *ParallelBestResolver) Resolve(ctx context.Context, request *model.Reque
 		return nil, fmt.Errorf("resolution failed: %w", errors.Join(collectedErrors...))
 	}
 
-	return r.retryWithDifferent(ctx, logger, request, resolvers)
+	return r.retryWithDifferent(ctx, logger, request, allResolvers, resolvers)
 }
 
 func evaluateResponses(
@@ -209,10 +239,10 @@ func evaluateResponses(
 }
 
 func (r *ParallelBestResolver) retryWithDifferent(
-	ctx context.Context, logger *logrus.Entry, request *model.Request, resolvers []*upstreamResolverStatus,
+	ctx context.Context, logger *logrus.Entry, request *model.Request, allResolvers, resolvers []*upstreamResolverStatus,
 ) (*model.Response, error) {
 	// second try (if retryWithDifferentResolver == true)
-	resolver := weightedRandom(ctx, *r.resolvers.Load(), resolvers)
+	resolver := weightedRandom(ctx, allResolvers, resolvers)
 	logger.Debugf("using %s as second resolver", resolver.resolver)
 
 	resp, err := resolver.resolve(ctx, request)
@@ -260,7 +290,7 @@ outer:
 			weight = math.Max(1, weight-(errorWindowInSec-time.Since(lastErrorTime).Minutes()))
 		}
 
-		choices = append(choices, weightedrand.NewChoice(res, uint(weight)))
+		choices = append(choices, weightedrand.NewChoice(res, uint(weight)*res.weight))
 	}
 
 	c, err := weightedrand.NewChooser(choices...)
@@ -18,6 +18,7 @@ var _ = Describe("ParallelBestResolver", Label("parallelBestResolver"), func() {
 		sut             *ParallelBestResolver
 		sutStrategy     config.UpstreamStrategy
 		sutInitStrategy config.InitStrategy
+		sutWeights      map[config.Upstream]uint
 		upstreams       []config.Upstream
 
 		ctx      context.Context
@@ -42,6 +43,7 @@ var _ = Describe("ParallelBestResolver", Label("parallelBestResolver"), func() {
 
 		sutInitStrategy = config.InitStrategyBlocking
 		sutStrategy = config.UpstreamStrategyParallelBest
+		sutWeights = nil
 
 		bootstrap = systemResolverBootstrap
 	})
@@ -53,6 +55,7 @@ var _ = Describe("ParallelBestResolver", Label("parallelBestResolver"), func() {
 			},
 			Strategy: sutStrategy,
 			Timeout:  config.Duration(timeout),
+			Weights:  sutWeights,
 		}
 
 		sutConfig := config.NewUpstreamGroup("test", upstreamsCfg, upstreams)
@@ -536,4 +539,48 @@ var _ = Describe("ParallelBestResolver", Label("parallelBestResolver"), func() {
 			})
 		})
 	})
+
+	Describe("weighted resolver strategy", func() {
+		var heavy, light config.Upstream
+
+		BeforeEach(func() {
+			sutStrategy = config.UpstreamStrategyWeighted
+
+			heavy = NewMockUDPUpstreamServer().WithAnswerRR("example.com 123 IN A 123.124.122.1").Start()
+			light = NewMockUDPUpstreamServer().WithAnswerRR("example.com 123 IN A 123.124.122.2").Start()
+
+			upstreams = []config.Upstream{heavy, light}
+			sutWeights = map[config.Upstream]uint{heavy: 9}
+		})
+
+		Describe("Name", func() {
+			It("should contain correct resolver", func() {
+				Expect(sut.Name()).Should(ContainSubstring(weightedResolverType))
+			})
+		})
+
+		It("should resolve using one of the upstreams", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(SatisfyAll(
+					HaveResponseType(ResponseTypeRESOLVED),
+					Or(
+						BeDNSRecord("example.com.", A, "123.124.122.1"),
+						BeDNSRecord("example.com.", A, "123.124.122.2"),
+					),
+				))
+		})
+
+		It("should select upstreams according to their weight", func(ctx context.Context) {
+			resolverCount := make(map[config.Upstream]int)
+
+			for i := 0; i < 2000; i++ {
+				r := weightedRandom(ctx, *sut.resolvers.Load(), nil)
+				resolverCount[r.resolver.(*UpstreamResolver).Upstream()]++
+			}
+
+			// weights 9:1 -> 1800 : 200
+			Expect(resolverCount[heavy]).Should(BeNumerically("~", 1800, 100))
+			Expect(resolverCount[light]).Should(BeNumerically("~", 200, 100))
+		})
+	})
 })
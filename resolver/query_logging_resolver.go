@@ -2,13 +2,16 @@ package resolver
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/geoip"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/querylog"
@@ -21,6 +24,7 @@ const (
 	cleanUpRunPeriod         = 12 * time.Hour
 	queryLoggingResolverType = "query_logging"
 	logChanCap               = 1000
+	recentEntriesCap         = 1000
 )
 
 // QueryLoggingResolver writes query information (question, answer, duration, ...)
@@ -31,13 +35,27 @@ type QueryLoggingResolver struct {
 
 	logChan    chan *querylog.LogEntry
 	writer     querylog.Writer
+	recent     *querylog.Recent
+	stream     *querylog.Stream
 	instanceID string
+	geoIP      *geoip.DB
+	anonymizer *querylog.Anonymizer
 }
 
 func GetQueryLoggingWriter(ctx context.Context, cfg config.QueryLog) (querylog.Writer, error) {
 	var writer querylog.Writer
 
-	var err error
+	var (
+		tlsCfg *tls.Config
+		err    error
+	)
+
+	if cfg.TLS != nil {
+		tlsCfg, err = cfg.TLS.NewClientTLSConfig("")
+		if err != nil {
+			return nil, fmt.Errorf("can't create database TLS config: %w", err)
+		}
+	}
 
 	switch cfg.Type {
 	case config.QueryLogTypeCsv:
@@ -45,14 +63,16 @@ func GetQueryLoggingWriter(ctx context.Context, cfg config.QueryLog) (querylog.W
 	case config.QueryLogTypeCsvClient:
 		writer, err = querylog.NewCSVWriter(cfg.Target, true, cfg.LogRetentionDays)
 	case config.QueryLogTypeMysql:
-		writer, err = querylog.NewDatabaseWriter(ctx, "mysql", cfg.Target, cfg.LogRetentionDays,
+		writer, err = querylog.NewDatabaseWriter(ctx, "mysql", cfg.Target, tlsCfg, cfg.LogRetentionDays,
 			cfg.FlushInterval.ToDuration())
 	case config.QueryLogTypePostgresql:
-		writer, err = querylog.NewDatabaseWriter(ctx, "postgresql", cfg.Target, cfg.LogRetentionDays,
+		writer, err = querylog.NewDatabaseWriter(ctx, "postgresql", cfg.Target, tlsCfg, cfg.LogRetentionDays,
 			cfg.FlushInterval.ToDuration())
 	case config.QueryLogTypeTimescale:
-		writer, err = querylog.NewDatabaseWriter(ctx, "timescale", cfg.Target, cfg.LogRetentionDays,
+		writer, err = querylog.NewDatabaseWriter(ctx, "timescale", cfg.Target, tlsCfg, cfg.LogRetentionDays,
 			cfg.FlushInterval.ToDuration())
+	case config.QueryLogTypeJsonl:
+		writer, err = querylog.NewArchiveWriter(ctx, cfg.Target, cfg.Archive, cfg.LogRetentionDays)
 	case config.QueryLogTypeConsole:
 		writer = querylog.NewLoggerWriter()
 	case config.QueryLogTypeNone:
@@ -63,7 +83,8 @@ func GetQueryLoggingWriter(ctx context.Context, cfg config.QueryLog) (querylog.W
 }
 
 // NewQueryLoggingResolver returns a new resolver instance
-func NewQueryLoggingResolver(ctx context.Context, cfg config.QueryLog) (*QueryLoggingResolver, error) {
+func NewQueryLoggingResolver(ctx context.Context, cfg config.QueryLog, geoIP *geoip.DB,
+) (*QueryLoggingResolver, error) {
 	logger := log.PrefixedLog(queryLoggingResolverType)
 
 	var writer querylog.Writer
@@ -104,7 +125,11 @@ func NewQueryLoggingResolver(ctx context.Context, cfg config.QueryLog) (*QueryLo
 
 		logChan:    logChan,
 		writer:     writer,
+		recent:     querylog.NewRecent(recentEntriesCap),
+		stream:     querylog.NewStream(),
 		instanceID: instanceID,
+		geoIP:      geoIP,
+		anonymizer: querylog.NewAnonymizer(cfg.Anonymize),
 	}
 
 	go resolver.writeLog(ctx)
@@ -148,12 +173,19 @@ func (r *QueryLoggingResolver) Resolve(ctx context.Context, request *model.Reque
 		return nil, err
 	}
 
+	if isDryRun(ctx) || isInternalQuery(ctx) {
+		return resp, nil
+	}
+
 	entry := r.createLogEntry(request, resp, start, duration)
 
 	if r.ignore(resp) {
 		// Log to the console for debugging purposes
 		logger.WithFields(querylog.LogEntryFields(entry)).Debug("ignored querylog entry")
 	} else {
+		r.recent.Add(entry)
+		r.stream.Publish(entry)
+
 		select {
 		case r.logChan <- entry:
 		default:
@@ -164,6 +196,16 @@ func (r *QueryLoggingResolver) Resolve(ctx context.Context, request *model.Reque
 	return resp, nil
 }
 
+// RecentQueries implements `api.QueryLogProvider`.
+func (r *QueryLoggingResolver) RecentQueries(limit int) []*querylog.LogEntry {
+	return r.recent.Entries(limit)
+}
+
+// SubscribeQueries implements `api.QueryLogProvider`.
+func (r *QueryLoggingResolver) SubscribeQueries(size int) (<-chan *querylog.LogEntry, func()) {
+	return r.stream.Subscribe(size)
+}
+
 func (r *QueryLoggingResolver) ignore(response *model.Response) bool {
 	cfg := r.cfg.Ignore
 
@@ -187,16 +229,24 @@ func (r *QueryLoggingResolver) createLogEntry(request *model.Request, response *
 		BlockyInstance: r.instanceID,
 	}
 
+	// nil if the client is logged as it is
+	anonymizer := r.anonymizer.For(request.ClientIP, request.ClientNames, request.ClientMAC)
+
 	for _, f := range r.cfg.Fields {
 		switch f {
 		case config.QueryLogFieldClientIP:
-			entry.ClientIP = request.ClientIP.String()
+			if !anonymizer.DropsClient() {
+				entry.ClientIP = anonymizer.IP(request.ClientIP)
+			}
 
 		case config.QueryLogFieldClientName:
-			entry.ClientNames = request.ClientNames
+			if !anonymizer.DropsClient() {
+				entry.ClientNames = anonymizer.Names(request.ClientNames)
+			}
 
 		case config.QueryLogFieldResponseReason:
 			entry.ResponseReason = response.Reason
+			entry.Categories = response.Categories
 			entry.ResponseType = response.RType.String()
 			entry.ResponseCode = dns.RcodeToString[response.Res.Rcode]
 
@@ -209,12 +259,51 @@ func (r *QueryLoggingResolver) createLogEntry(request *model.Request, response *
 
 		case config.QueryLogFieldDuration:
 			entry.DurationMs = durationMs
+
+		case config.QueryLogFieldAnswerGeo:
+			countries, asns := answerGeo(r.geoIP, response.Res.Answer)
+			entry.AnswerCountry = strings.Join(countries, ", ")
+			entry.AnswerASN = strings.Join(asns, ", ")
+
+		case config.QueryLogFieldEdns:
+			if request.EDNS != nil {
+				entry.EDNSUDPSize = request.EDNS.UDPSize
+				entry.EDNSDNSSECOK = request.EDNS.DO
+				entry.EDNSECS = request.EDNS.ECS
+				entry.EDNSCookie = request.EDNS.Cookie
+			}
 		}
 	}
 
 	return &entry
 }
 
+// answerGeo returns the distinct countries and ASNs of the addresses in the answer
+func answerGeo(db *geoip.DB, answer []dns.RR) (countries, asns []string) {
+	if db == nil {
+		return nil, nil
+	}
+
+	for _, rr := range answer {
+		ip := util.AnswerIP(rr)
+		if ip == nil {
+			continue
+		}
+
+		if country, found := db.Country(ip); found && !slices.Contains(countries, country) {
+			countries = append(countries, country)
+		}
+
+		if number, found := db.ASN(ip); found {
+			if asn := fmt.Sprintf("AS%d", number); !slices.Contains(asns, asn) {
+				asns = append(asns, asn)
+			}
+		}
+	}
+
+	return countries, asns
+}
+
 // write entry: if log directory is configured, write to log file
 func (r *QueryLoggingResolver) writeLog(ctx context.Context) {
 	ctx, logger := r.log(ctx)
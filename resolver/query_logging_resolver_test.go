@@ -7,9 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/0xERR0R/blocky/geoip"
 	. "github.com/0xERR0R/blocky/helpertest"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/0xERR0R/blocky/querylog"
@@ -20,6 +24,7 @@ import (
 	. "github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/util"
 
+	"github.com/maxmind/mmdbwriter/mmdbtype"
 	"github.com/miekg/dns"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -38,6 +43,28 @@ func (m *SlowMockWriter) Write(entry *querylog.LogEntry) {
 func (m *SlowMockWriter) CleanUp() {
 }
 
+type CollectingMockWriter struct {
+	lock    sync.Mutex
+	entries []*querylog.LogEntry
+}
+
+func (m *CollectingMockWriter) Write(entry *querylog.LogEntry) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.entries = append(m.entries, entry)
+}
+
+func (m *CollectingMockWriter) CleanUp() {
+}
+
+func (m *CollectingMockWriter) Entries() []*querylog.LogEntry {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return slices.Clone(m.entries)
+}
+
 var _ = Describe("QueryLoggingResolver", func() {
 	var (
 		sut        *QueryLoggingResolver
@@ -47,6 +74,7 @@ var _ = Describe("QueryLoggingResolver", func() {
 		tmpDir     *TmpFolder
 		mockRType  ResponseType
 		mockAnswer *dns.Msg
+		sutGeoIP   *geoip.DB
 
 		ctx      context.Context
 		cancelFn context.CancelFunc
@@ -67,6 +95,7 @@ var _ = Describe("QueryLoggingResolver", func() {
 
 		mockRType = ResponseTypeRESOLVED
 		mockAnswer = new(dns.Msg)
+		sutGeoIP = nil
 		tmpDir = NewTmpFolder("queryLoggingResolver")
 	})
 
@@ -75,7 +104,7 @@ var _ = Describe("QueryLoggingResolver", func() {
 			sutConfig.SetDefaults() // not called when using a struct literal
 		}
 
-		sut, err = NewQueryLoggingResolver(ctx, sutConfig)
+		sut, err = NewQueryLoggingResolver(ctx, sutConfig, sutGeoIP)
 		Expect(err).Should(Succeed())
 
 		m = &mockResolver{
@@ -345,6 +374,243 @@ var _ = Describe("QueryLoggingResolver", func() {
 				})
 			})
 		})
+
+		When("Configuration with jsonl archive", func() {
+			BeforeEach(func() {
+				sutConfig = config.QueryLog{
+					Target:           tmpDir.Path,
+					Type:             config.QueryLogTypeJsonl,
+					CreationAttempts: 1,
+					CreationCooldown: config.Duration(time.Millisecond),
+					Archive:          config.QueryLogArchive{Rotation: config.QueryLogRotationDaily},
+				}
+				mockAnswer, _ = util.NewMsgWithAnswer("example.com.", 300, A, "123.122.121.120")
+			})
+			It("should write the archive file", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "client1"))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				path := tmpDir.JoinPath(fmt.Sprintf("%s.000.jsonl.gz", time.Now().Format("2006-01-02")))
+
+				Eventually(func() string {
+					return path + ".part"
+				}, "1s").Should(BeAnExistingFile())
+
+				cancelFn()
+
+				Eventually(func() string {
+					return path
+				}, "1s").Should(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("Recent queries", func() {
+		BeforeEach(func() {
+			sutConfig = config.QueryLog{
+				Type:             config.QueryLogTypeNone,
+				CreationAttempts: 1,
+				CreationCooldown: config.Duration(time.Millisecond),
+			}
+		})
+
+		It("should return the latest queries, newest first", func() {
+			_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "client1"))
+			Expect(err).Should(Succeed())
+			_, err = sut.Resolve(ctx, newRequestWithClient("example.org.", A, "192.168.178.26", "client2"))
+			Expect(err).Should(Succeed())
+
+			entries := sut.RecentQueries(10)
+			Expect(entries).Should(HaveLen(2))
+			Expect(entries[0].QuestionName).Should(Equal("example.org."))
+			Expect(entries[0].ClientNames).Should(Equal([]string{"client2"}))
+			Expect(entries[1].QuestionName).Should(Equal("example.com."))
+
+			Expect(sut.RecentQueries(1)).Should(HaveLen(1))
+		})
+
+		It("should not return ignored queries", func() {
+			sut.cfg.Ignore.SUDN = true
+			mockRType = ResponseTypeSPECIAL
+
+			_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "client1"))
+			Expect(err).Should(Succeed())
+
+			Expect(sut.RecentQueries(10)).Should(BeEmpty())
+		})
+
+		It("should pass new queries to subscribers", func() {
+			entries, cancel := sut.SubscribeQueries(10)
+			DeferCleanup(cancel)
+
+			_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "client1"))
+			Expect(err).Should(Succeed())
+
+			Expect(entries).Should(Receive(HaveField("QuestionName", "example.com.")))
+		})
+	})
+
+	Describe("Anonymization", func() {
+		BeforeEach(func() {
+			sutConfig = config.QueryLog{
+				Type:             config.QueryLogTypeNone,
+				CreationAttempts: 1,
+				CreationCooldown: config.Duration(time.Millisecond),
+				Fields:           []config.QueryLogField{config.QueryLogFieldClientIP, config.QueryLogFieldClientName},
+				Anonymize: config.QueryLogAnonymize{
+					ClientIP:        config.QueryLogIPAnonymizationTruncate,
+					IPv4Prefix:      24,
+					HashClientNames: true,
+					Exempt:          []string{"admin*"},
+				},
+			}
+		})
+
+		It("should anonymize the client", func() {
+			_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "client1"))
+			Expect(err).Should(Succeed())
+
+			entries := sut.RecentQueries(1)
+			Expect(entries).Should(HaveLen(1))
+			Expect(entries[0].ClientIP).Should(Equal("192.168.178.0"))
+			Expect(entries[0].ClientNames).Should(HaveLen(1))
+			Expect(entries[0].ClientNames[0]).ShouldNot(Equal("client1"))
+		})
+
+		It("should not anonymize exempt clients", func() {
+			_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "admin-pc"))
+			Expect(err).Should(Succeed())
+
+			entries := sut.RecentQueries(1)
+			Expect(entries[0].ClientIP).Should(Equal("192.168.178.25"))
+			Expect(entries[0].ClientNames).Should(Equal([]string{"admin-pc"}))
+		})
+
+		When("the client is dropped", func() {
+			BeforeEach(func() {
+				sutConfig.Anonymize.DropClient = true
+			})
+
+			It("should log neither client IP nor client names", func() {
+				_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "client1"))
+				Expect(err).Should(Succeed())
+
+				entries := sut.RecentQueries(1)
+				Expect(entries[0].ClientIP).Should(Equal("0.0.0.0"))
+				Expect(entries[0].ClientNames).Should(Equal([]string{"none"}))
+			})
+		})
+	})
+
+	Describe("Answer geo fields", func() {
+		BeforeEach(func() {
+			sutConfig = config.QueryLog{
+				Type:             config.QueryLogTypeNone,
+				CreationAttempts: 1,
+				CreationCooldown: config.Duration(time.Millisecond),
+				Fields:           []config.QueryLogField{config.QueryLogFieldAnswerGeo},
+			}
+			mockAnswer, _ = util.NewMsgWithAnswer("example.com.", 300, A, "192.0.2.1")
+			mockAnswer.Answer = append(mockAnswer.Answer,
+				&dns.A{
+					Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+					A:   net.ParseIP("192.0.2.2"),
+				},
+				&dns.A{
+					Hdr: dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+					A:   net.ParseIP("198.51.100.1"),
+				})
+
+			sutGeoIP, err = geoip.Open(ctx, &config.GeoIP{
+				ASNDatabase: TempMMDB(map[string]mmdbtype.Map{
+					"192.0.2.0/24":    {"autonomous_system_number": mmdbtype.Uint32(64500)},
+					"198.51.100.0/24": {"autonomous_system_number": mmdbtype.Uint32(64501)},
+				}),
+				CountryDatabase: TempMMDB(map[string]mmdbtype.Map{
+					"192.0.2.0/24": {"country": mmdbtype.String("de")},
+				}),
+			})
+			Expect(err).Should(Succeed())
+			DeferCleanup(sutGeoIP.Close)
+		})
+
+		It("should log the distinct countries and ASNs of the answer", func() {
+			mockWriter := &CollectingMockWriter{}
+			sut.writer = mockWriter
+
+			_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "client1"))
+			Expect(err).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(mockWriter.Entries()).Should(HaveLen(1))
+				g.Expect(mockWriter.Entries()[0].AnswerCountry).Should(Equal("DE"))
+				g.Expect(mockWriter.Entries()[0].AnswerASN).Should(Equal("AS64500, AS64501"))
+			}, "1s").Should(Succeed())
+		})
+
+		When("no IP database is configured", func() {
+			BeforeEach(func() {
+				sutGeoIP = nil
+			})
+
+			It("should log empty fields", func() {
+				mockWriter := &CollectingMockWriter{}
+				sut.writer = mockWriter
+
+				_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "client1"))
+				Expect(err).Should(Succeed())
+
+				Eventually(func(g Gomega) {
+					g.Expect(mockWriter.Entries()).Should(HaveLen(1))
+					g.Expect(mockWriter.Entries()[0].AnswerCountry).Should(BeEmpty())
+					g.Expect(mockWriter.Entries()[0].AnswerASN).Should(BeEmpty())
+				}, "1s").Should(Succeed())
+			})
+		})
+	})
+
+	Describe("EDNS fields", func() {
+		BeforeEach(func() {
+			sutConfig = config.QueryLog{
+				Type:             config.QueryLogTypeNone,
+				CreationAttempts: 1,
+				CreationCooldown: config.Duration(time.Millisecond),
+				Fields:           []config.QueryLogField{config.QueryLogFieldEdns},
+			}
+		})
+
+		It("should log the EDNS of the query", func() {
+			mockWriter := &CollectingMockWriter{}
+			sut.writer = mockWriter
+
+			request := newRequestWithClient("example.com.", A, "192.168.178.25", "client1")
+			request.EDNS = &EDNS{UDPSize: 1232, DO: true, Cookie: true}
+
+			_, err := sut.Resolve(ctx, request)
+			Expect(err).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(mockWriter.Entries()).Should(HaveLen(1))
+				g.Expect(mockWriter.Entries()[0].EDNSUDPSize).Should(BeEquivalentTo(1232))
+				g.Expect(mockWriter.Entries()[0].EDNSDNSSECOK).Should(BeTrue())
+				g.Expect(mockWriter.Entries()[0].EDNSECS).Should(BeFalse())
+				g.Expect(mockWriter.Entries()[0].EDNSCookie).Should(BeTrue())
+			}, "1s").Should(Succeed())
+		})
+
+		It("should log zero values without OPT record", func() {
+			mockWriter := &CollectingMockWriter{}
+			sut.writer = mockWriter
+
+			_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.25", "client1"))
+			Expect(err).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(mockWriter.Entries()).Should(HaveLen(1))
+				g.Expect(mockWriter.Entries()[0].EDNSUDPSize).Should(BeZero())
+				g.Expect(mockWriter.Entries()[0].EDNSDNSSECOK).Should(BeFalse())
+			}, "1s").Should(Succeed())
+		})
 	})
 
 	Describe("Slow writer", func() {
@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+)
+
+const rebindProtectionReason = "REBINDING PROTECTION"
+
+// RebindProtectionResolver protects against DNS rebinding: answers of the next resolvers which contain
+// private, loopback or link-local addresses are replaced by NXDOMAIN or have these records removed
+type RebindProtectionResolver struct {
+	configurable[*config.RebindProtection]
+	NextResolver
+	typed
+}
+
+// NewRebindProtectionResolver creates a new resolver instance
+func NewRebindProtectionResolver(cfg config.RebindProtection) *RebindProtectionResolver {
+	return &RebindProtectionResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("rebind_protection"),
+	}
+}
+
+// Resolve checks the addresses in the answer of the next resolver unless the domain is allowed
+func (r *RebindProtectionResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	response, err := r.next.Resolve(ctx, request)
+	if err != nil || len(response.Res.Answer) == 0 {
+		return response, err
+	}
+
+	domain := util.ExtractDomain(request.Req.Question[0])
+	if matchesDomainList(r.cfg.Allowlist, domain) {
+		return response, nil
+	}
+
+	answer := make([]dns.RR, 0, len(response.Res.Answer))
+
+	for _, rr := range response.Res.Answer {
+		if !isRebindAnswer(rr) {
+			answer = append(answer, rr)
+		}
+	}
+
+	removed := len(response.Res.Answer) - len(answer)
+	if removed == 0 {
+		return response, nil
+	}
+
+	logger.WithField("domain", util.Obfuscate(domain)).Infof("answer contains %d private addresses, action: %s",
+		removed, r.cfg.Action)
+
+	if r.cfg.Action == config.RebindActionDrop {
+		response.Res.Answer = answer
+
+		return response, nil
+	}
+
+	msg := new(dns.Msg)
+	msg.SetRcode(request.Req, dns.RcodeNameError)
+
+	return &model.Response{Res: msg, RType: model.ResponseTypeFILTERED, Reason: rebindProtectionReason}, nil
+}
+
+// isRebindAnswer returns true if rr is an A or AAAA record with an address which isn't reachable from the internet
+func isRebindAnswer(rr dns.RR) bool {
+	addr, ok := netip.AddrFromSlice(util.AnswerIP(rr))
+	if !ok {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	return addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified()
+}
@@ -0,0 +1,198 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("RebindProtectionResolver", func() {
+	var (
+		sut       *RebindProtectionResolver
+		sutConfig config.RebindProtection
+		m         *mockResolver
+
+		answer *dns.Msg
+		err    error
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	msgWith := func(rrs ...string) *dns.Msg {
+		msg := new(dns.Msg)
+
+		for _, s := range rrs {
+			rr, err := dns.NewRR(s)
+			Expect(err).Should(Succeed())
+
+			msg.Answer = append(msg.Answer, rr)
+		}
+
+		return msg
+	}
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		err = nil
+		answer = msgWith("example.com. 300 IN A 192.168.178.1")
+
+		sutConfig = config.RebindProtection{
+			Enable:    true,
+			Action:    config.RebindActionNxdomain,
+			Allowlist: []string{"plex.direct"},
+		}
+	})
+
+	JustBeforeEach(func() {
+		sut = NewRebindProtectionResolver(sutConfig)
+
+		m = &mockResolver{}
+		m.ResolveFn = func(context.Context, *Request) (*Response, error) {
+			if err != nil {
+				return nil, err
+			}
+
+			return &Response{Res: answer.Copy(), RType: ResponseTypeRESOLVED}, nil
+		}
+		m.On("Resolve", mock.Anything)
+
+		sut.Next(m)
+	})
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	Describe("IsEnabled", func() {
+		It("is false by default", func() {
+			sut := NewRebindProtectionResolver(config.RebindProtection{})
+
+			Expect(sut.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	When("disabled", func() {
+		BeforeEach(func() {
+			sutConfig.Enable = false
+		})
+
+		It("should return the answer", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(BeDNSRecord("example.com.", A, "192.168.178.1"))
+		})
+	})
+
+	DescribeTable("should answer NXDOMAIN for private addresses",
+		func(rr string, qType dns.Type) {
+			answer = msgWith(rr)
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", qType))).
+				Should(SatisfyAll(
+					HaveNoAnswer(),
+					HaveReturnCode(dns.RcodeNameError),
+					HaveResponseType(ResponseTypeFILTERED),
+					HaveReason("REBINDING PROTECTION"),
+				))
+		},
+		Entry("RFC 1918", "example.com. 300 IN A 10.1.2.3", A),
+		Entry("loopback", "example.com. 300 IN A 127.0.0.1", A),
+		Entry("link-local", "example.com. 300 IN A 169.254.169.254", A),
+		Entry("unspecified", "example.com. 300 IN A 0.0.0.0", A),
+		Entry("IPv6 ULA", "example.com. 300 IN AAAA fd00::1", AAAA),
+		Entry("IPv6 loopback", "example.com. 300 IN AAAA ::1", AAAA),
+		Entry("IPv6 link-local", "example.com. 300 IN AAAA fe80::1", AAAA),
+		Entry("IPv4-mapped", "example.com. 300 IN AAAA ::ffff:192.168.0.1", AAAA),
+	)
+
+	It("should return public addresses", func() {
+		answer = msgWith("example.com. 300 IN A 1.1.1.1")
+
+		Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+			Should(SatisfyAll(
+				BeDNSRecord("example.com.", A, "1.1.1.1"),
+				HaveResponseType(ResponseTypeRESOLVED),
+			))
+	})
+
+	It("should check the addresses behind a CNAME", func() {
+		answer = msgWith(
+			"example.com. 300 IN CNAME attacker.net.",
+			"attacker.net. 300 IN A 192.168.0.1",
+		)
+
+		Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+			Should(HaveReturnCode(dns.RcodeNameError))
+	})
+
+	It("should return private addresses of allowed domains and their subdomains", func() {
+		answer = msgWith("abc.plex.direct. 300 IN A 192.168.178.10")
+
+		Expect(sut.Resolve(ctx, newRequest("abc.plex.direct.", A))).
+			Should(SatisfyAll(
+				BeDNSRecord("abc.plex.direct.", A, "192.168.178.10"),
+				HaveResponseType(ResponseTypeRESOLVED),
+			))
+	})
+
+	When("the action is drop", func() {
+		BeforeEach(func() {
+			sutConfig.Action = config.RebindActionDrop
+		})
+
+		It("should remove the private addresses", func() {
+			answer = msgWith(
+				"example.com. 300 IN A 192.168.178.1",
+				"example.com. 300 IN A 1.1.1.1",
+			)
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(SatisfyAll(
+					BeDNSRecord("example.com.", A, "1.1.1.1"),
+					HaveResponseType(ResponseTypeRESOLVED),
+					HaveReturnCode(dns.RcodeSuccess),
+				))
+		})
+
+		It("should return an empty answer if all addresses are private", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(SatisfyAll(
+					HaveNoAnswer(),
+					HaveReturnCode(dns.RcodeSuccess),
+				))
+		})
+	})
+
+	When("the next resolver fails", func() {
+		BeforeEach(func() {
+			err = errors.New("test")
+		})
+
+		It("should return the error", func() {
+			_, err := sut.Resolve(ctx, newRequest("example.com.", A))
+			Expect(err).Should(MatchError("test"))
+		})
+	})
+})
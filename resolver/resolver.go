@@ -115,19 +115,21 @@ func Chain(resolvers ...Resolver) ChainedResolver {
 }
 
 func GetFromChainWithType[T any](resolver ChainedResolver) (result T, err error) {
-	for resolver != nil {
-		if result, found := resolver.(T); found {
-			return result, nil
-		}
+	found := false
 
-		if cr, ok := resolver.GetNext().(ChainedResolver); ok {
-			resolver = cr
-		} else {
-			break
+	// includes the last resolver of the chain, which is not chained (e.g. the upstream tree)
+	ForEach(resolver, func(r Resolver) {
+		if res, ok := r.(T); ok && !found {
+			result = res
+			found = true
 		}
+	})
+
+	if !found {
+		return result, fmt.Errorf("type was not found in the chain")
 	}
 
-	return result, fmt.Errorf("type was not found in the chain")
+	return result, nil
 }
 
 // Name returns a user-friendly name of a resolver
@@ -207,6 +209,19 @@ func (t *typed) logWith(ctx context.Context, wrap func(*logrus.Entry) *logrus.En
 	})
 }
 
+type internalQueryKey struct{}
+
+// withInternalQuery returns a context for a query blocky sends itself through the chain,
+// it isn't logged or counted in the metrics and statistics like the queries of the clients
+func withInternalQuery(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalQueryKey{}, true)
+}
+
+// isInternalQuery returns true if the query of the context was sent by blocky itself
+func isInternalQuery(ctx context.Context) bool {
+	return ctx.Value(internalQueryKey{}) != nil
+}
+
 // Should be embedded in a Resolver to auto-implement `config.Configurable`.
 type configurable[T config.Configurable] struct {
 	cfg T
@@ -242,6 +257,8 @@ func initGroupResolvers[T initializable](
 
 		r.setResolvers(resolvers)
 
+		startHealthChecks(ctx, cfg, resolvers)
+
 		return nil
 	}
 
@@ -251,7 +268,7 @@ func initGroupResolvers[T initializable](
 		logger.WithError(err).Error("upstream verification error, will continue to use bootstrap DNS")
 	}
 
-	err := cfg.Init.Strategy.Do(ctx, init, onErr)
+	err := cfg.Init.Do(ctx, init, onErr)
 	if err != nil {
 		var zero T
 
@@ -268,12 +285,27 @@ func createGroupResolvers(
 	resolvers := make([]*upstreamResolverStatus, 0, len(upstreams))
 
 	for _, upstream := range upstreams {
-		resolver, err := NewUpstreamResolver(ctx, newUpstreamConfig(upstream, cfg.Upstreams), bootstrap)
+		upstreamCfg := newUpstreamConfig(upstream, cfg.Upstreams)
+		upstreamCfg.bind = cfg.BindOf(cfg.Name)
+
+		resolver, err := newUpstreamResolverUnchecked(upstreamCfg, bootstrap)
+		if err != nil {
+			return nil, err
+		}
+
+		err = resolver.init(ctx)
 		if err != nil {
 			continue // err was already logged
 		}
 
-		resolvers = append(resolvers, newUpstreamResolverStatus(resolver))
+		status := newUpstreamResolverStatus(resolver)
+		status.circuitBreaker = cfg.CircuitBreaker
+
+		if cfg.Strategy == config.UpstreamStrategyWeighted {
+			status.weight = cfg.Weight(upstream)
+		}
+
+		resolvers = append(resolvers, status)
 	}
 
 	if len(resolvers) == 0 {
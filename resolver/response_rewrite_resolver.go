@@ -0,0 +1,155 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+)
+
+// ResponseRewriteResolver rewrites the answers of the next resolvers: addresses, CNAME targets and record types
+type ResponseRewriteResolver struct {
+	configurable[*config.ResponseRewrite]
+	NextResolver
+	typed
+}
+
+// NewResponseRewriteResolver creates a new resolver instance
+func NewResponseRewriteResolver(cfg config.ResponseRewrite) *ResponseRewriteResolver {
+	return &ResponseRewriteResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("response_rewrite"),
+	}
+}
+
+// Resolve applies the rules matching the query name to the answer of the next resolver
+func (r *ResponseRewriteResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	response, err := r.next.Resolve(ctx, request)
+	if err != nil || len(response.Res.Answer) == 0 {
+		return response, err
+	}
+
+	domain := util.ExtractDomain(request.Req.Question[0])
+
+	for i := range r.cfg.Rules {
+		rule := &r.cfg.Rules[i]
+
+		if !rewriteRuleMatches(rule, domain) {
+			continue
+		}
+
+		if changed := rewriteAnswer(rule, response.Res); changed > 0 {
+			logger.WithField("domain", util.Obfuscate(domain)).Debugf("rewrote %d answer records", changed)
+		}
+	}
+
+	return response, nil
+}
+
+// rewriteRuleMatches returns true if the rule applies to queries of the domain
+func rewriteRuleMatches(rule *config.ResponseRewriteRule, domain string) bool {
+	if len(rule.Domains) == 0 {
+		return true
+	}
+
+	for _, d := range rule.Domains {
+		if domainMatches(domain, d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rewriteAnswer applies the rule to the answer of msg and returns the number of changed or removed records
+func rewriteAnswer(rule *config.ResponseRewriteRule, msg *dns.Msg) (changed int) {
+	answer := make([]dns.RR, 0, len(msg.Answer))
+
+	// owner names of the records after a rewritten CNAME, to keep the chain consistent
+	renamed := make(map[string]string)
+
+	for _, rr := range msg.Answer {
+		if rule.StripTypes.Contains(dns.Type(rr.Header().Rrtype)) {
+			changed++
+
+			continue
+		}
+
+		if newName, ok := renamed[strings.ToLower(rr.Header().Name)]; ok {
+			rr.Header().Name = newName
+		}
+
+		switch v := rr.(type) {
+		case *dns.A:
+			if rewriteAddress(rule, &v.A) {
+				changed++
+			}
+		case *dns.AAAA:
+			if rewriteAddress(rule, &v.AAAA) {
+				changed++
+			}
+		case *dns.CNAME:
+			if target, ok := rewriteCNAMETarget(rule, v.Target); ok {
+				renamed[strings.ToLower(v.Target)] = target
+				v.Target = target
+				changed++
+			}
+		}
+
+		answer = append(answer, rr)
+	}
+
+	msg.Answer = answer
+
+	return changed
+}
+
+// rewriteAddress replaces ip with the address of the first matching network
+func rewriteAddress(rule *config.ResponseRewriteRule, ip *net.IP) bool {
+	addr, ok := netip.AddrFromSlice(*ip)
+	if !ok {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, address := range rule.Addresses {
+		if address.From.Contains(addr) {
+			*ip = address.To.AsSlice()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// rewriteCNAMETarget returns the rewritten FQDN of the target, the longest matching domain wins
+func rewriteCNAMETarget(rule *config.ResponseRewriteRule, target string) (string, bool) {
+	name := util.ExtractDomainOnly(target)
+	match := ""
+
+	for from := range rule.CNAMEs {
+		if len(from) > len(match) && domainMatches(name, from) {
+			match = from
+		}
+	}
+
+	if match == "" {
+		return target, false
+	}
+
+	return dns.Fqdn(strings.TrimSuffix(name, match) + rule.CNAMEs[match]), true
+}
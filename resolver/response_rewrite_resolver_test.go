@@ -0,0 +1,202 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ResponseRewriteResolver", func() {
+	var (
+		sut       *ResponseRewriteResolver
+		sutConfig config.ResponseRewrite
+		m         *mockResolver
+
+		answer *dns.Msg
+		err    error
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	msgWith := func(rrs ...string) *dns.Msg {
+		msg := new(dns.Msg)
+
+		for _, s := range rrs {
+			rr, err := dns.NewRR(s)
+			Expect(err).Should(Succeed())
+
+			msg.Answer = append(msg.Answer, rr)
+		}
+
+		return msg
+	}
+
+	answerStrings := func(resp *Response) []string {
+		res := make([]string, 0, len(resp.Res.Answer))
+		for _, rr := range resp.Res.Answer {
+			res = append(res, rr.String())
+		}
+
+		return res
+	}
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		err = nil
+		answer = msgWith("example.com. 300 IN A 10.0.0.1")
+
+		sutConfig = config.ResponseRewrite{
+			Rules: []config.ResponseRewriteRule{{
+				Domains: []string{"example.com"},
+				Addresses: []config.AddressRewrite{{
+					From: netip.MustParsePrefix("10.0.0.0/8"),
+					To:   netip.MustParseAddr("192.168.1.1"),
+				}},
+			}},
+		}
+	})
+
+	JustBeforeEach(func() {
+		sut = NewResponseRewriteResolver(sutConfig)
+
+		m = &mockResolver{}
+		m.ResolveFn = func(context.Context, *Request) (*Response, error) {
+			if err != nil {
+				return nil, err
+			}
+
+			return &Response{Res: answer.Copy(), RType: ResponseTypeRESOLVED}, nil
+		}
+		m.On("Resolve", mock.Anything)
+
+		sut.Next(m)
+	})
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	Describe("IsEnabled", func() {
+		It("is false by default", func() {
+			sut := NewResponseRewriteResolver(config.ResponseRewrite{})
+
+			Expect(sut.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	Describe("addresses", func() {
+		It("should replace addresses in the network", func() {
+			Expect(sut.Resolve(ctx, newRequest("www.example.com.", A))).
+				Should(SatisfyAll(
+					BeDNSRecord("example.com.", A, "192.168.1.1"),
+					HaveTTL(BeNumerically("==", 300)),
+					HaveResponseType(ResponseTypeRESOLVED),
+				))
+		})
+
+		It("should not change addresses outside of the network", func() {
+			answer = msgWith("example.com. 300 IN A 1.1.1.1")
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(BeDNSRecord("example.com.", A, "1.1.1.1"))
+		})
+
+		It("should not change answers of other domains", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.org.", A))).
+				Should(BeDNSRecord("example.com.", A, "10.0.0.1"))
+		})
+
+		When("the rule has no domains", func() {
+			BeforeEach(func() {
+				sutConfig.Rules[0].Domains = nil
+				sutConfig.Rules[0].Addresses = []config.AddressRewrite{{
+					From: netip.MustParsePrefix("2001:db8::/32"),
+					To:   netip.MustParseAddr("fd00::1"),
+				}}
+
+				answer = msgWith("example.org. 300 IN AAAA 2001:db8::5")
+			})
+
+			It("should apply to all domains", func() {
+				Expect(sut.Resolve(ctx, newRequest("example.org.", AAAA))).
+					Should(BeDNSRecord("example.org.", AAAA, "fd00::1"))
+			})
+		})
+	})
+
+	Describe("CNAMEs", func() {
+		BeforeEach(func() {
+			sutConfig.Rules[0].CNAMEs = map[string]string{"cdn.old.net": "cdn.new.net"}
+
+			answer = msgWith(
+				"example.com. 300 IN CNAME edge.cdn.old.net.",
+				"edge.cdn.old.net. 60 IN A 1.1.1.1",
+			)
+		})
+
+		It("should rewrite the target and the chain", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(WithTransform(answerStrings, Equal([]string{
+					"example.com.\t300\tIN\tCNAME\tedge.cdn.new.net.",
+					"edge.cdn.new.net.\t60\tIN\tA\t1.1.1.1",
+				})))
+		})
+	})
+
+	Describe("record types", func() {
+		BeforeEach(func() {
+			sutConfig.Rules = append(sutConfig.Rules, config.ResponseRewriteRule{
+				Domains:    []string{"example.com"},
+				StripTypes: config.NewQTypeSet(AAAA),
+			})
+
+			answer = msgWith(
+				"example.com. 300 IN A 1.1.1.1",
+				"example.com. 300 IN AAAA 2001:db8::1",
+			)
+		})
+
+		It("should strip the records", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", dns.Type(dns.TypeANY)))).
+				Should(SatisfyAll(
+					BeDNSRecord("example.com.", A, "1.1.1.1"),
+					WithTransform(answerStrings, HaveLen(1)),
+				))
+		})
+	})
+
+	When("the next resolver fails", func() {
+		BeforeEach(func() {
+			err = errors.New("test")
+		})
+
+		It("should return the error", func() {
+			_, err := sut.Resolve(ctx, newRequest("example.com.", A))
+			Expect(err).Should(MatchError("test"))
+		})
+	})
+})
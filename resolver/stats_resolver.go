@@ -0,0 +1,65 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/stats"
+)
+
+// StatsResolver counts the queries for the rolling statistics of the API
+type StatsResolver struct {
+	configurable[*config.Stats]
+	NextResolver
+	typed
+
+	// nil if the statistics are disabled
+	collector *stats.Collector
+}
+
+// NewStatsResolver creates a new resolver instance
+func NewStatsResolver(ctx context.Context, cfg config.Stats, redisCfg *config.Redis) (*StatsResolver, error) {
+	collector, err := stats.New(ctx, cfg, redisCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatsResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("stats"),
+
+		collector: collector,
+	}, nil
+}
+
+// Resolve counts the query with its response
+func (r *StatsResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	response, err := r.next.Resolve(ctx, request)
+
+	if r.collector != nil && err == nil && !isDryRun(ctx) && !isInternalQuery(ctx) {
+		r.collector.Add(&stats.Query{
+			Time:       time.Now(),
+			Client:     clientName(request),
+			Domain:     request.Req.Question[0].Name,
+			Blocked:    response.RType == model.ResponseTypeBLOCKED,
+			Cached:     response.RType == model.ResponseTypeCACHED,
+			Categories: response.Categories,
+		})
+	}
+
+	return response, err
+}
+
+// StatsOverview implements `api.StatsProvider`
+func (r *StatsResolver) StatsOverview(ctx context.Context, period time.Duration) (stats.Overview, error) {
+	return r.collector.Overview(ctx, period)
+}
+
+// TopStats implements `api.StatsProvider`
+func (r *StatsResolver) TopStats(ctx context.Context,
+	period time.Duration, kind stats.Kind, limit int,
+) ([]stats.Count, error) {
+	return r.collector.Top(ctx, period, kind, limit)
+}
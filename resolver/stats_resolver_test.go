@@ -0,0 +1,173 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/stats"
+	"github.com/creasty/defaults"
+
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("StatsResolver", func() {
+	var (
+		sut       *StatsResolver
+		sutConfig config.Stats
+		m         *mockResolver
+		mockRType ResponseType
+
+		mockCategories []string
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		Expect(defaults.Set(&sutConfig)).Should(Succeed())
+		sutConfig.Enable = true
+
+		mockRType = ResponseTypeRESOLVED
+		mockCategories = nil
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = NewStatsResolver(ctx, sutConfig, &config.Redis{})
+		Expect(err).Should(Succeed())
+
+		m = &mockResolver{}
+		m.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg), RType: mockRType, Categories: mockCategories}, nil)
+		sut.Next(m)
+	})
+
+	Describe("IsEnabled", func() {
+		It("is true", func() {
+			Expect(sut.IsEnabled()).Should(BeTrue())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	Describe("Resolve", func() {
+		It("should count the queries", func() {
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.20", "laptop"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.21"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(sut.TopStats(ctx, time.Hour, stats.KindDomains, 10)).Should(Equal([]stats.Count{
+				{Key: "example.com", Count: 2},
+			}))
+			Expect(sut.TopStats(ctx, time.Hour, stats.KindClients, 10)).Should(ConsistOf(
+				stats.Count{Key: "laptop", Count: 1},
+				stats.Count{Key: "192.168.178.21", Count: 1},
+			))
+		})
+
+		When("the query is blocked", func() {
+			BeforeEach(func() {
+				mockRType = ResponseTypeBLOCKED
+				mockCategories = []string{"ads"}
+			})
+
+			It("should count it as blocked", func() {
+				_, err := sut.Resolve(ctx, newRequestWithClient("ads.com.", A, "192.168.178.20"))
+				Expect(err).Should(Succeed())
+
+				overview, err := sut.StatsOverview(ctx, time.Hour)
+				Expect(err).Should(Succeed())
+				Expect(overview.Counters).Should(Equal(stats.Counters{Total: 1, Blocked: 1}))
+
+				Expect(sut.TopStats(ctx, time.Hour, stats.KindBlockedDomains, 10)).Should(Equal([]stats.Count{
+					{Key: "ads.com", Count: 1},
+				}))
+				Expect(sut.TopStats(ctx, time.Hour, stats.KindBlockedCategories, 10)).Should(Equal([]stats.Count{
+					{Key: "ads", Count: 1},
+				}))
+			})
+		})
+
+		When("the query is answered from the cache", func() {
+			BeforeEach(func() {
+				mockRType = ResponseTypeCACHED
+			})
+
+			It("should count it as cached", func() {
+				_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.20"))
+				Expect(err).Should(Succeed())
+
+				overview, err := sut.StatsOverview(ctx, time.Hour)
+				Expect(err).Should(Succeed())
+				Expect(overview.Counters).Should(Equal(stats.Counters{Total: 1, Cached: 1}))
+			})
+		})
+
+		When("the next resolver fails", func() {
+			JustBeforeEach(func() {
+				m = &mockResolver{}
+				m.On("Resolve", mock.Anything).Return(nil, errors.New("error"))
+				sut.Next(m)
+			})
+
+			It("should not count the query", func() {
+				_, err := sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.20"))
+				Expect(err).Should(HaveOccurred())
+
+				overview, err := sut.StatsOverview(ctx, time.Hour)
+				Expect(err).Should(Succeed())
+				Expect(overview.Total).Should(BeZero())
+			})
+		})
+
+		When("statistics are disabled", func() {
+			BeforeEach(func() {
+				sutConfig.Enable = false
+			})
+
+			It("should only pass the query and return an error for the statistics", func() {
+				Expect(sut.Resolve(ctx, newRequestWithClient("example.com.", A, "192.168.178.20"))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+
+				_, err := sut.StatsOverview(ctx, time.Hour)
+				Expect(err).Should(MatchError(stats.ErrDisabled))
+			})
+		})
+	})
+
+	When("redis can't be reached", func() {
+		It("should fail", func() {
+			sutConfig.Redis = true
+
+			_, err := NewStatsResolver(ctx, sutConfig, &config.Redis{Address: "127.0.0.1:0"})
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync/atomic"
 
+	"github.com/0xERR0R/blocky/api"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/util"
@@ -56,6 +57,11 @@ func (r *StrictResolver) setResolvers(resolvers []*upstreamResolverStatus) {
 	r.resolvers.Store(&resolvers)
 }
 
+// UpstreamStatus implements `api.UpstreamStatusProvider`.
+func (r *StrictResolver) UpstreamStatus() []api.UpstreamStatus {
+	return healthStatus(r.cfg.Name, *r.resolvers.Load())
+}
+
 func (r *StrictResolver) Name() string {
 	return r.String()
 }
@@ -75,8 +81,14 @@ func (r *StrictResolver) String() string {
 func (r *StrictResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
 	ctx, logger := r.log(ctx)
 
+	resolvers := healthyOrAll(*r.resolvers.Load())
+
+	if r.cfg.Hedging.IsEnabled() {
+		return resolveHedged(ctx, logger, request, resolvers, r.cfg.Hedging)
+	}
+
 	// start with first resolver
-	for _, resolver := range *r.resolvers.Load() {
+	for _, resolver := range resolvers {
 		logger.Debugf("using %s as resolver", resolver.resolver)
 
 		resp, err := resolver.resolve(ctx, request)
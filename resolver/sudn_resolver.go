@@ -57,7 +57,7 @@ var (
 		// RFC 6762
 		// https://www.rfc-editor.org/rfc/rfc6762
 		//
-		// mDNS is not implemented, so just return NXDOMAIN
+		// Return NXDOMAIN, unless the mDNS resolver answered them already
 		//
 		// Section 3
 		"local.": sudnNXDomain,
@@ -84,6 +84,12 @@ var (
 		//
 		// Section 4
 		"home.arpa.": sudnHomeArpa,
+
+		// RFC 9476
+		// https://www.rfc-editor.org/rfc/rfc9476
+		//
+		// Section 2: names of alternative name systems, not resolvable in the DNS
+		"alt.": sudnNXDomain,
 	}
 )
 
@@ -118,7 +124,8 @@ func (r *SpecialUseDomainNamesResolver) Resolve(ctx context.Context, request *mo
 
 func (r *SpecialUseDomainNamesResolver) handler(request *model.Request) sudnHandler {
 	q := request.Req.Question[0]
-	domain := q.Name
+	// DNS names are case-insensitive, "LOCALHOST." must not reach the upstream either
+	domain := strings.ToLower(q.Name)
 
 	for {
 		handler, ok := sudnHandlers[domain]
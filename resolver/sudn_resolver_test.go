@@ -149,6 +149,9 @@ var _ = Describe("SudnResolver", Label("sudnResolver"), func() {
 			entry(A, "something.home.", dns.RcodeNameError),
 			entry(A, "something.lan.", dns.RcodeNameError),
 			entry(A, "something.onion.", dns.RcodeNameError),
+			entry(A, "something.alt.", dns.RcodeNameError),
+			entry(A, "Something.INVALID.", dns.RcodeNameError),
+			entry(A, "LocalHost.", dns.RcodeSuccess, BeDNSRecord("LocalHost.", A, loopbackV4.String())),
 		)
 
 		When("RFC 6762 Appendix G is disabled", func() {
@@ -0,0 +1,62 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/0xERR0R/blocky/model"
+)
+
+type tracerKey struct{}
+
+// tracer collects the trace of a query, resolvers of a group may add steps concurrently
+type tracer struct {
+	lock  sync.Mutex
+	trace model.Trace
+}
+
+// WithTrace returns a context which traces the resolution of a query, and a function returning the trace.
+//
+// A traced query is a dry run: it isn't cached, logged or counted and doesn't publish any event,
+// only the upstream servers are queried as usual.
+func WithTrace(ctx context.Context) (context.Context, func() model.Trace) {
+	t := &tracer{}
+
+	return context.WithValue(ctx, tracerKey{}, t), func() model.Trace {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+
+		trace := t.trace
+		trace.Steps = slices.Clone(trace.Steps)
+		trace.Lists = slices.Clone(trace.Lists)
+
+		return trace
+	}
+}
+
+// isDryRun returns true if the query of the context is traced and must not have any side effect
+func isDryRun(ctx context.Context) bool {
+	return ctx.Value(tracerKey{}) != nil
+}
+
+// traced updates the trace of the context, if the query is traced
+func traced(ctx context.Context, update func(trace *model.Trace)) {
+	t, ok := ctx.Value(tracerKey{}).(*tracer)
+	if !ok {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	update(&t.trace)
+}
+
+// trace adds a step of the resolver to the trace of the context, if the query is traced
+func (t *typed) trace(ctx context.Context, format string, args ...any) {
+	traced(ctx, func(trace *model.Trace) {
+		trace.Steps = append(trace.Steps, model.TraceStep{Resolver: t.Type(), Message: fmt.Sprintf(format, args...)})
+	})
+}
@@ -0,0 +1,54 @@
+package resolver
+
+import (
+	"context"
+
+	. "github.com/0xERR0R/blocky/model"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Trace", func() {
+	var sut typed
+
+	BeforeEach(func() {
+		sut = withType("test")
+	})
+
+	It("should record the steps of traced queries", func() {
+		ctx, trace := WithTrace(context.Background())
+
+		Expect(isDryRun(ctx)).Should(BeTrue())
+
+		sut.trace(ctx, "step %d", 1)
+		traced(ctx, func(trace *Trace) { trace.Cache = "MISS" })
+		sut.trace(ctx, "step %d", 2)
+
+		Expect(trace()).Should(Equal(Trace{
+			Steps: []TraceStep{{Resolver: "test", Message: "step 1"}, {Resolver: "test", Message: "step 2"}},
+			Cache: "MISS",
+		}))
+	})
+
+	It("should return a copy of the trace", func() {
+		ctx, trace := WithTrace(context.Background())
+
+		sut.trace(ctx, "step")
+
+		result := trace()
+		sut.trace(ctx, "later step")
+
+		Expect(result.Steps).Should(HaveLen(1))
+		Expect(trace().Steps).Should(HaveLen(2))
+	})
+
+	It("should ignore queries which aren't traced", func() {
+		ctx := context.Background()
+
+		Expect(isDryRun(ctx)).Should(BeFalse())
+
+		sut.trace(ctx, "step")
+		traced(ctx, func(*Trace) { Fail("not traced") })
+	})
+})
@@ -0,0 +1,108 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+)
+
+// TTLRewriteResolver sets or limits the TTLs of the answers of the next resolvers per domain.
+// It is placed behind the caching resolver, so the answers are cached with the rewritten TTLs.
+type TTLRewriteResolver struct {
+	configurable[*config.TTLRewrite]
+	NextResolver
+	typed
+}
+
+// NewTTLRewriteResolver creates a new resolver instance
+func NewTTLRewriteResolver(cfg config.TTLRewrite) *TTLRewriteResolver {
+	return &TTLRewriteResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("ttl_rewrite"),
+	}
+}
+
+// Resolve rewrites the TTLs of the answer with the most specific rule matching the query name
+func (r *TTLRewriteResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	response, err := r.next.Resolve(ctx, request)
+	if err != nil || len(response.Res.Answer) == 0 {
+		return response, err
+	}
+
+	domain := util.ExtractDomain(request.Req.Question[0])
+
+	rule := ttlRewriteRuleFor(r.cfg.Rules, domain)
+	if rule == nil {
+		return response, nil
+	}
+
+	if changed := rewriteTTLs(rule, response.Res.Answer); changed > 0 {
+		logger.WithField("domain", util.Obfuscate(domain)).Debugf("rewrote the TTL of %d answer records", changed)
+		r.trace(ctx, "rewrote the TTL of %d answer records", changed)
+	}
+
+	return response, nil
+}
+
+// ttlRewriteRuleFor returns the rule with the most specific domain matching the domain, nil if none matches
+func ttlRewriteRuleFor(rules []config.TTLRewriteRule, domain string) *config.TTLRewriteRule {
+	var (
+		match        *config.TTLRewriteRule
+		matchPattern string
+	)
+
+	for i := range rules {
+		for _, pattern := range rules[i].Domains {
+			if len(pattern) > len(matchPattern) && ttlRewritePatternMatches(domain, pattern) {
+				match = &rules[i]
+				matchPattern = pattern
+			}
+		}
+	}
+
+	return match
+}
+
+// ttlRewritePatternMatches returns true if the domain is the pattern or a subdomain,
+// only a subdomain if the pattern starts with `*.`
+func ttlRewritePatternMatches(domain, pattern string) bool {
+	if parent, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(domain, "."+parent)
+	}
+
+	return domainMatches(domain, pattern)
+}
+
+// rewriteTTLs applies the rule to the records and returns the number of changed TTLs
+func rewriteTTLs(rule *config.TTLRewriteRule, answer []dns.RR) (changed int) {
+	for _, rr := range answer {
+		ttl := rr.Header().Ttl
+
+		switch {
+		case rule.TTL.IsAboveZero():
+			ttl = rule.TTL.SecondsU32()
+		case rule.MinTime.IsAboveZero() && ttl < rule.MinTime.SecondsU32():
+			ttl = rule.MinTime.SecondsU32()
+		case rule.MaxTime.IsAboveZero() && ttl > rule.MaxTime.SecondsU32():
+			ttl = rule.MaxTime.SecondsU32()
+		}
+
+		if ttl != rr.Header().Ttl {
+			rr.Header().Ttl = ttl
+			changed++
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,151 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("TTLRewriteResolver", func() {
+	var (
+		sut       *TTLRewriteResolver
+		sutConfig config.TTLRewrite
+		m         *mockResolver
+
+		answer *dns.Msg
+		err    error
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		err = nil
+		answer, _ = util.NewMsgWithAnswer("www.netflix.com.", 300, A, "192.0.2.1")
+
+		sutConfig = config.TTLRewrite{
+			Rules: []config.TTLRewriteRule{
+				{Domains: []string{"*.netflix.com"}, MaxTime: config.Duration(time.Minute)},
+				{Domains: []string{"cdn.netflix.com"}, TTL: config.Duration(10 * time.Second)},
+				{Domains: []string{"lan.internal"}, MinTime: config.Duration(time.Hour)},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		sut = NewTTLRewriteResolver(sutConfig)
+
+		m = &mockResolver{}
+		m.ResolveFn = func(context.Context, *Request) (*Response, error) {
+			if err != nil {
+				return nil, err
+			}
+
+			return &Response{Res: answer.Copy(), RType: ResponseTypeRESOLVED}, nil
+		}
+		m.On("Resolve", mock.Anything)
+
+		sut.Next(m)
+	})
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	Describe("IsEnabled", func() {
+		It("is false by default", func() {
+			sut := NewTTLRewriteResolver(config.TTLRewrite{})
+
+			Expect(sut.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	Describe("Resolve", func() {
+		It("should limit the TTL to the max time", func() {
+			Expect(sut.Resolve(ctx, newRequest("www.netflix.com.", A))).
+				Should(SatisfyAll(
+					BeDNSRecord("www.netflix.com.", A, "192.0.2.1"),
+					HaveTTL(BeNumerically("==", 60)),
+					HaveResponseType(ResponseTypeRESOLVED),
+				))
+		})
+
+		It("should keep TTLs within the limits", func() {
+			answer, _ = util.NewMsgWithAnswer("www.netflix.com.", 30, A, "192.0.2.1")
+
+			Expect(sut.Resolve(ctx, newRequest("www.netflix.com.", A))).
+				Should(HaveTTL(BeNumerically("==", 30)))
+		})
+
+		It("should raise the TTL to the min time", func() {
+			answer, _ = util.NewMsgWithAnswer("nas.lan.internal.", 300, A, "192.168.0.2")
+
+			Expect(sut.Resolve(ctx, newRequest("nas.lan.internal.", A))).
+				Should(HaveTTL(BeNumerically("==", 3600)))
+		})
+
+		It("should apply the most specific rule", func() {
+			answer, _ = util.NewMsgWithAnswer("img.cdn.netflix.com.", 300, A, "192.0.2.1")
+
+			Expect(sut.Resolve(ctx, newRequest("img.cdn.netflix.com.", A))).
+				Should(HaveTTL(BeNumerically("==", 10)))
+		})
+
+		It("should only match subdomains of wildcard domains", func() {
+			answer, _ = util.NewMsgWithAnswer("netflix.com.", 300, A, "192.0.2.1")
+
+			Expect(sut.Resolve(ctx, newRequest("netflix.com.", A))).
+				Should(HaveTTL(BeNumerically("==", 300)))
+		})
+
+		It("should not change answers of other domains", func() {
+			answer, _ = util.NewMsgWithAnswer("example.com.", 300, A, "192.0.2.1")
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(HaveTTL(BeNumerically("==", 300)))
+		})
+
+		It("should return errors of the next resolver", func() {
+			err = errors.New("upstream error")
+
+			_, err := sut.Resolve(ctx, newRequest("www.netflix.com.", A))
+			Expect(err).Should(HaveOccurred())
+		})
+
+		When("disabled", func() {
+			BeforeEach(func() {
+				sutConfig = config.TTLRewrite{}
+			})
+
+			It("should not change the TTL", func() {
+				Expect(sut.Resolve(ctx, newRequest("www.netflix.com.", A))).
+					Should(HaveTTL(BeNumerically("==", 300)))
+			})
+		})
+	})
+})
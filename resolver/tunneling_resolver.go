@@ -0,0 +1,291 @@
+package resolver
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	tunnelingReason        = "DNS TUNNELING"
+	tunnelingLimitedReason = "DNS TUNNELING (RATE LIMITED)"
+
+	// the unique subdomains are only counted up to this multiple of the threshold, to bound the memory
+	tunnelingMaxSubdomainsFactor = 2
+)
+
+// TunnelingResolver detects DNS tunneling: a client querying many unique, long or random looking subdomains of
+// the same zone, e.g. to exfiltrate data. Depending on the action, further queries of the client to the zone are
+// rate limited or refused.
+type TunnelingResolver struct {
+	configurable[*config.Tunneling]
+	NextResolver
+	typed
+
+	limiter *util.KeyedRateLimiter
+
+	lock sync.Mutex
+	// statistics of the current window per client and zone
+	stats map[string]*tunnelingStats
+	// end of the action per client and zone, for suspected tunnels
+	suspected map[string]time.Time
+}
+
+// tunnelingStats are the statistics of the queries of a client to a zone within the window
+type tunnelingStats struct {
+	start      time.Time
+	queries    uint
+	txtOrNull  uint
+	subdomains map[string]struct{}
+	// sums over the unique subdomains
+	entropy     float64
+	labelLength uint
+}
+
+// NewTunnelingResolver creates a new resolver instance
+func NewTunnelingResolver(ctx context.Context, cfg config.Tunneling) *TunnelingResolver {
+	r := &TunnelingResolver{
+		configurable: withConfig(&cfg),
+		typed:        withType("tunneling"),
+
+		stats:     make(map[string]*tunnelingStats),
+		suspected: make(map[string]time.Time),
+	}
+
+	if cfg.Action == config.TunnelingActionRatelimit {
+		r.limiter = util.NewKeyedRateLimiter(float64(cfg.RateLimit.Rate), cfg.RateLimit.EffectiveBurst())
+	}
+
+	if cfg.IsEnabled() {
+		go r.periodicCleanup(ctx)
+	}
+
+	return r
+}
+
+// Resolve analyzes the query and applies the action if the client tunnels through the zone
+func (r *TunnelingResolver) Resolve(ctx context.Context, request *model.Request) (*model.Response, error) {
+	if !r.IsEnabled() || request.ClientIP == nil {
+		return r.next.Resolve(ctx, request)
+	}
+
+	ctx, logger := r.log(ctx)
+
+	question := request.Req.Question[0]
+	domain := util.ExtractDomain(question)
+
+	zone, ok := registrableDomain(domain)
+	if !ok || matchesDomainList(r.cfg.Allowlist, domain) {
+		return r.next.Resolve(ctx, request)
+	}
+
+	key := request.ClientIP.String() + " " + zone
+	now := time.Now()
+
+	suspected := r.isSuspected(key, now)
+
+	// a dry run must not change the statistics
+	if !suspected && !isDryRun(ctx) {
+		if stats := r.analyze(key, strings.TrimSuffix(domain, zone), question.Qtype, now); stats != nil {
+			suspected = true
+
+			stats.log(logger.WithFields(logrus.Fields{
+				"client_ip": request.ClientIP.String(),
+				"zone":      util.Obfuscate(zone),
+			}), r.cfg.Action)
+
+			evt.Bus().Publish(evt.TunnelingSuspected, request.ClientIP.String(), zone)
+		}
+	}
+
+	if !suspected {
+		return r.next.Resolve(ctx, request)
+	}
+
+	r.trace(ctx, "client is suspected of tunneling through %s, action: %s", zone, r.cfg.Action)
+
+	switch r.cfg.Action {
+	case config.TunnelingActionBlock:
+		return refusedResponse(request, tunnelingReason), nil
+
+	case config.TunnelingActionRatelimit:
+		if allowed, _ := r.limiter.Allow(key); !allowed {
+			if !isDryRun(ctx) {
+				evt.Bus().Publish(evt.RateLimited, "tunneling")
+			}
+
+			return refusedResponse(request, tunnelingLimitedReason), nil
+		}
+	}
+
+	return r.next.Resolve(ctx, request)
+}
+
+func refusedResponse(request *model.Request, reason string) *model.Response {
+	msg := new(dns.Msg)
+	msg.SetRcode(request.Req, dns.RcodeRefused)
+
+	return &model.Response{Res: msg, RType: model.ResponseTypeBLOCKED, Reason: reason}
+}
+
+func (r *TunnelingResolver) isSuspected(key string, now time.Time) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	end, found := r.suspected[key]
+
+	return found && now.Before(end)
+}
+
+// analyze adds the query to the statistics of the client and the zone,
+// and returns them if they exceed the thresholds. `subdomain` ends with a dot, if not empty.
+func (r *TunnelingResolver) analyze(key, subdomain string, qType uint16, now time.Time) *tunnelingStats {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	stats, found := r.stats[key]
+	if !found || now.Sub(stats.start) > r.cfg.Window.ToDuration() {
+		stats = &tunnelingStats{start: now, subdomains: make(map[string]struct{})}
+		r.stats[key] = stats
+	}
+
+	stats.add(strings.TrimSuffix(subdomain, "."), qType, r.cfg.MinUniqueSubdomains*tunnelingMaxSubdomainsFactor)
+
+	if !stats.exceeds(r.cfg) {
+		return nil
+	}
+
+	delete(r.stats, key)
+	r.suspected[key] = now.Add(r.cfg.ActionDuration.ToDuration())
+
+	return stats
+}
+
+func (r *TunnelingResolver) periodicCleanup(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Window.ToDuration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			r.cleanup(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// cleanup removes the statistics of past windows and the ended actions
+func (r *TunnelingResolver) cleanup(now time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for key, stats := range r.stats {
+		if now.Sub(stats.start) > r.cfg.Window.ToDuration() {
+			delete(r.stats, key)
+		}
+	}
+
+	for key, end := range r.suspected {
+		if !now.Before(end) {
+			delete(r.suspected, key)
+		}
+	}
+}
+
+func (s *tunnelingStats) add(subdomain string, qType uint16, maxSubdomains uint) {
+	s.queries++
+
+	if qType == dns.TypeTXT || qType == dns.TypeNULL {
+		s.txtOrNull++
+	}
+
+	if subdomain == "" || uint(len(s.subdomains)) >= maxSubdomains {
+		return
+	}
+
+	if _, found := s.subdomains[subdomain]; found {
+		return
+	}
+
+	s.subdomains[subdomain] = struct{}{}
+	s.entropy += shannonEntropy(strings.ReplaceAll(subdomain, ".", ""))
+	s.labelLength += longestLabel(subdomain)
+}
+
+// exceeds returns true if the subdomains are many and random looking or long
+func (s *tunnelingStats) exceeds(cfg *config.Tunneling) bool {
+	unique := uint(len(s.subdomains))
+	if unique < cfg.MinUniqueSubdomains {
+		return false
+	}
+
+	return s.avgEntropy() >= cfg.MinEntropy || s.avgLabelLength() >= float64(cfg.MinLabelLength)
+}
+
+func (s *tunnelingStats) avgEntropy() float64 {
+	return s.entropy / float64(len(s.subdomains))
+}
+
+func (s *tunnelingStats) avgLabelLength() float64 {
+	return float64(s.labelLength) / float64(len(s.subdomains))
+}
+
+func (s *tunnelingStats) log(logger *logrus.Entry, action config.TunnelingAction) {
+	const precision = 100
+
+	logger.WithFields(logrus.Fields{
+		"queries":             s.queries,
+		"rate":                math.Round(float64(s.queries)/max(time.Since(s.start).Seconds(), 1)*precision) / precision,
+		"unique_subdomains":   len(s.subdomains),
+		"avg_entropy":         math.Round(s.avgEntropy()*precision) / precision,
+		"avg_label_length":    math.Round(s.avgLabelLength()*precision) / precision,
+		"txt_or_null_queries": s.txtOrNull,
+	}).Warnf("client is suspected of DNS tunneling, action: %s", action)
+}
+
+// shannonEntropy returns the entropy of the characters of s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+
+	for _, c := range strings.ToLower(s) {
+		counts[c]++
+	}
+
+	var (
+		entropy float64
+		total   = float64(len(s))
+	)
+
+	for _, count := range counts {
+		p := float64(count) / total
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// longestLabel returns the length of the longest label of the domain
+func longestLabel(domain string) uint {
+	var longest int
+
+	for _, label := range strings.Split(domain, ".") {
+		longest = max(longest, len(label))
+	}
+
+	return uint(longest)
+}
@@ -0,0 +1,185 @@
+package resolver
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/evt"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("TunnelingResolver", func() {
+	var (
+		sut       *TunnelingResolver
+		sutConfig config.Tunneling
+		m         *mockResolver
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	// randomSubdomain returns a hex encoded label, like the ones of data exfiltrated through DNS
+	randomSubdomain := func(i int) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte{byte(i)}))[:32]
+	}
+
+	tunnel := func(clientIP, zone string, count int) {
+		for i := range count {
+			_, err := sut.Resolve(ctx, newRequestWithClient(randomSubdomain(i)+"."+zone+".", TXT, clientIP))
+			ExpectWithOffset(1, err).Should(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		var err error
+
+		sutConfig, err = config.WithDefaults[config.Tunneling]()
+		Expect(err).Should(Succeed())
+
+		sutConfig.Enable = true
+		sutConfig.MinUniqueSubdomains = 10
+		sutConfig.Allowlist = []string{"sophosxl.net"}
+	})
+
+	JustBeforeEach(func() {
+		sut = NewTunnelingResolver(ctx, sutConfig)
+
+		m = &mockResolver{}
+		m.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg), RType: ResponseTypeRESOLVED}, nil)
+
+		sut.Next(m)
+	})
+
+	Describe("Type", func() {
+		It("follows conventions", func() {
+			expectValidResolverType(sut)
+		})
+	})
+
+	Describe("IsEnabled", func() {
+		It("is false by default", func() {
+			sut := NewTunnelingResolver(ctx, config.Tunneling{})
+
+			Expect(sut.IsEnabled()).Should(BeFalse())
+		})
+	})
+
+	Describe("LogConfig", func() {
+		It("should log something", func() {
+			logger, hook := log.NewMockEntry()
+
+			sut.LogConfig(logger)
+
+			Expect(hook.Calls).ShouldNot(BeEmpty())
+		})
+	})
+
+	It("should detect many random subdomains of a zone and fire an event", func() {
+		var suspected atomic.Value
+
+		handler := func(clientIP, zone string) { suspected.Store(clientIP + " " + zone) }
+		Expect(Bus().Subscribe(TunnelingSuspected, handler)).Should(Succeed())
+		DeferCleanup(Bus().Unsubscribe, TunnelingSuspected, handler)
+
+		tunnel("192.168.178.2", "tunnel.example.com", 9)
+		Expect(suspected.Load()).Should(BeNil())
+
+		tunnel("192.168.178.2", "tunnel.example.com", 10)
+		Expect(suspected.Load()).Should(Equal("192.168.178.2 example.com"))
+
+		// the action is log
+		Expect(sut.Resolve(ctx, newRequestWithClient("data.example.com.", TXT, "192.168.178.2"))).
+			Should(HaveResponseType(ResponseTypeRESOLVED))
+	})
+
+	It("should not suspect usual subdomains", func() {
+		for i := range 30 {
+			Expect(sut.Resolve(ctx, newRequestWithClient(fmt.Sprintf("host%d.example.com.", i), A, "192.168.178.2"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+		}
+
+		Expect(sut.suspected).Should(BeEmpty())
+	})
+
+	It("should skip allowed zones", func() {
+		tunnel("192.168.178.2", "sophosxl.net", 20)
+
+		Expect(sut.suspected).Should(BeEmpty())
+	})
+
+	When("the action is block", func() {
+		BeforeEach(func() {
+			sutConfig.Action = config.TunnelingActionBlock
+		})
+
+		It("should refuse the queries of the client to the zone", func() {
+			tunnel("192.168.178.2", "example.com", 10)
+
+			Expect(sut.Resolve(ctx, newRequestWithClient("www.example.com.", A, "192.168.178.2"))).
+				Should(SatisfyAll(
+					HaveReturnCode(dns.RcodeRefused),
+					HaveResponseType(ResponseTypeBLOCKED),
+					HaveReason("DNS TUNNELING"),
+				))
+
+			Expect(sut.Resolve(ctx, newRequestWithClient("www.example.com.", A, "192.168.178.3"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+			Expect(sut.Resolve(ctx, newRequestWithClient("www.example.net.", A, "192.168.178.2"))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+		})
+	})
+
+	When("the action is ratelimit", func() {
+		BeforeEach(func() {
+			sutConfig.Action = config.TunnelingActionRatelimit
+			sutConfig.RateLimit = config.RateLimit{Rate: 1}
+		})
+
+		It("should refuse the queries exceeding the rate limit", func() {
+			tunnel("192.168.178.2", "example.com", 10)
+
+			Expect(sut.Resolve(ctx, newRequestWithClient("www.example.com.", A, "192.168.178.2"))).
+				Should(SatisfyAll(
+					HaveReturnCode(dns.RcodeRefused),
+					HaveReason("DNS TUNNELING (RATE LIMITED)"),
+				))
+		})
+	})
+
+	Describe("cleanup", func() {
+		It("should remove past windows and ended actions", func() {
+			tunnel("192.168.178.2", "example.com", 10)
+			tunnel("192.168.178.3", "example.net", 5)
+
+			Expect(sut.suspected).Should(HaveLen(1))
+			Expect(sut.stats).Should(HaveLen(1))
+
+			sut.cleanup(time.Now().Add(sutConfig.ActionDuration.ToDuration()))
+
+			Expect(sut.suspected).Should(BeEmpty())
+			Expect(sut.stats).Should(BeEmpty())
+		})
+	})
+
+	Describe("shannonEntropy", func() {
+		It("should return the bits per character", func() {
+			Expect(shannonEntropy("")).Should(BeZero())
+			Expect(shannonEntropy("aaaa")).Should(BeZero())
+			Expect(shannonEntropy("abcd")).Should(BeNumerically("~", 2))
+			Expect(shannonEntropy("AbAb")).Should(BeNumerically("~", 1))
+		})
+	})
+})
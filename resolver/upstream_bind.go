@@ -0,0 +1,27 @@
+package resolver
+
+import (
+	"net"
+
+	"github.com/0xERR0R/blocky/config"
+)
+
+// newUpstreamDialer returns a dialer for connections to upstreams, bound to the configured address and interface
+func newUpstreamDialer(bind config.UpstreamBind, network string) *net.Dialer {
+	dialer := new(net.Dialer)
+
+	if bind.Address != nil {
+		switch network {
+		case "udp":
+			dialer.LocalAddr = &net.UDPAddr{IP: bind.Address}
+		default:
+			dialer.LocalAddr = &net.TCPAddr{IP: bind.Address}
+		}
+	}
+
+	if bind.Interface != "" {
+		dialer.Control = bindToInterface(bind.Interface)
+	}
+
+	return dialer
+}
@@ -0,0 +1,23 @@
+//go:build linux
+
+package resolver
+
+import (
+	"syscall"
+)
+
+// bindToInterface returns a `net.Dialer.Control` function binding the socket to the network interface
+func bindToInterface(iface string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		var sockErr error
+
+		err := c.Control(func(fd uintptr) {
+			sockErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, iface)
+		})
+		if err != nil {
+			return err
+		}
+
+		return sockErr
+	}
+}
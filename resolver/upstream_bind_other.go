@@ -0,0 +1,17 @@
+//go:build !linux
+
+package resolver
+
+import (
+	"errors"
+	"syscall"
+)
+
+var errBindToInterfaceUnsupported = errors.New("binding to a network interface is only supported on Linux")
+
+// bindToInterface returns a `net.Dialer.Control` function failing, as this OS is not supported
+func bindToInterface(_ string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, _ syscall.RawConn) error {
+		return errBindToInterfaceUnsupported
+	}
+}
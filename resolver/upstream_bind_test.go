@@ -0,0 +1,105 @@
+package resolver
+
+import (
+	"context"
+	"net"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/0xERR0R/blocky/model"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Upstream bind", func() {
+	var bind config.UpstreamBind
+
+	BeforeEach(func() {
+		bind = config.UpstreamBind{}
+	})
+
+	Describe("newUpstreamDialer", func() {
+		It("should not bind by default", func() {
+			dialer := newUpstreamDialer(bind, "udp")
+
+			Expect(dialer.LocalAddr).Should(BeNil())
+			Expect(dialer.Control).Should(BeNil())
+		})
+
+		It("should bind to the address for the network", func() {
+			bind.Address = net.ParseIP("192.168.1.2")
+
+			Expect(newUpstreamDialer(bind, "udp").LocalAddr).Should(Equal(&net.UDPAddr{IP: bind.Address}))
+			Expect(newUpstreamDialer(bind, "tcp").LocalAddr).Should(Equal(&net.TCPAddr{IP: bind.Address}))
+		})
+
+		It("should bind to the interface", func() {
+			bind.Interface = "wan2"
+
+			Expect(newUpstreamDialer(bind, "tcp").Control).ShouldNot(BeNil())
+		})
+	})
+
+	Describe("upstream queries", func() {
+		var (
+			ctx      context.Context
+			cancelFn context.CancelFunc
+
+			sutConfig upstreamConfig
+		)
+
+		BeforeEach(func() {
+			ctx, cancelFn = context.WithCancel(context.Background())
+			DeferCleanup(cancelFn)
+
+			mockUpstream := NewMockUDPUpstreamServer().WithAnswerRR("example.com 123 IN A 123.124.122.122")
+			DeferCleanup(mockUpstream.Close)
+
+			sutConfig = newUpstreamConfig(mockUpstream.Start(), defaultUpstreamsConfig)
+		})
+
+		resolve := func() (*Response, error) {
+			sut, err := newUpstreamResolverUnchecked(sutConfig, nil)
+			Expect(err).Should(Succeed())
+
+			return sut.Resolve(ctx, newRequest("example.com.", A))
+		}
+
+		It("should use the bound address", func() {
+			sutConfig.bind.Address = net.ParseIP("127.0.0.1")
+
+			Expect(resolve()).Should(BeDNSRecord("example.com.", A, "123.124.122.122"))
+		})
+
+		It("should fail if the address is not local", func() {
+			sutConfig.bind.Address = net.ParseIP("192.0.2.1")
+
+			_, err := resolve()
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should fail if the interface doesn't exist", func() {
+			sutConfig.bind.Interface = "doesnotexist0"
+
+			_, err := resolve()
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("createGroupResolvers", func() {
+		It("should bind the upstreams of the group", func() {
+			upstreamsCfg := defaultUpstreamsConfig
+			upstreamsCfg.Bind = map[string]config.UpstreamBind{"wan2": {Address: net.ParseIP("127.0.0.1")}}
+
+			resolvers, err := createGroupResolvers(context.Background(),
+				config.NewUpstreamGroup("wan2", upstreamsCfg, []config.Upstream{{Host: "127.0.0.1"}}),
+				systemResolverBootstrap)
+			Expect(err).Should(Succeed())
+			Expect(resolvers).Should(HaveLen(1))
+
+			upstream := resolvers[0].resolver.(*UpstreamResolver)
+			Expect(upstream.cfg.bind.Address).Should(Equal(net.ParseIP("127.0.0.1")))
+		})
+	})
+})
@@ -0,0 +1,142 @@
+package resolver
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// consecutive TCP responses with another case of the name after which the upstream is considered to change it
+	caseMismatchThreshold = 3
+
+	// how long upstreams changing the case of the name get the name as it is
+	caseRandomizationFallbackCooldown = time.Hour
+)
+
+// caseRandomizingClient sends the query name with random case (DNS 0x20) and only accepts responses with the same
+// case: a spoofed response has to guess the case of each letter in addition to the ID and the port.
+//
+// A response with another case is dropped and the query is sent again over TCP, which can't be spoofed off-path.
+// Only TCP responses with another case count as the upstream changing the case.
+type caseRandomizingClient struct {
+	upstreamClient
+
+	upstream  string
+	tcpClient dnsExchanger
+
+	// number of consecutive TCP responses with another case
+	mismatches atomic.Uint32
+	// time (unix nanoseconds) until which the name is sent as it is
+	disabledUntil atomic.Int64
+}
+
+func newCaseRandomizingClient(upstream string, client upstreamClient, tcpClient dnsExchanger) *caseRandomizingClient {
+	return &caseRandomizingClient{upstreamClient: client, upstream: upstream, tcpClient: tcpClient}
+}
+
+func (c *caseRandomizingClient) callExternal(
+	ctx context.Context, msg *dns.Msg, upstreamURL string, protocol model.RequestProtocol,
+) (*dns.Msg, time.Duration, error) {
+	if len(msg.Question) != 1 || time.Now().UnixNano() < c.disabledUntil.Load() {
+		return c.upstreamClient.callExternal(ctx, msg, upstreamURL, protocol)
+	}
+
+	name := msg.Question[0].Name
+
+	randomized := msg.Copy()
+	randomized.Question[0].Name = randomizeCase(name)
+
+	resp, rtt, err := c.upstreamClient.callExternal(ctx, randomized, upstreamURL, protocol)
+	if resp == nil {
+		return resp, rtt, err
+	}
+
+	if hasQuestionName(resp, randomized.Question[0].Name) {
+		c.accept(resp, name)
+
+		return resp, rtt, err
+	}
+
+	// the response is spoofed or the upstream changes the case: ask again over TCP, which can't be spoofed
+	resp, rtt, err = c.tcpClient.ExchangeContext(ctx, randomized, upstreamURL)
+	if err != nil || len(resp.Question) == 0 {
+		return resp, rtt, err
+	}
+
+	if hasQuestionName(resp, randomized.Question[0].Name) {
+		c.accept(resp, name)
+
+		return resp, rtt, nil
+	}
+
+	if len(resp.Question) != 1 || !strings.EqualFold(resp.Question[0].Name, name) {
+		return nil, rtt, fmt.Errorf("upstream %s answered with another question", c.upstream)
+	}
+
+	if c.mismatches.Add(1) >= caseMismatchThreshold {
+		c.mismatches.Store(0)
+		c.disabledUntil.Store(time.Now().Add(caseRandomizationFallbackCooldown).UnixNano())
+
+		log.PrefixedLog("upstream").Warnf("%s doesn't keep the case of query names, not randomizing it for %s",
+			c.upstream, caseRandomizationFallbackCooldown)
+	}
+
+	restoreCase(resp, name)
+
+	return resp, rtt, nil
+}
+
+// accept resets the mismatches and restores the name of a response with the randomized name
+func (c *caseRandomizingClient) accept(resp *dns.Msg, name string) {
+	c.mismatches.Store(0)
+	restoreCase(resp, name)
+}
+
+func hasQuestionName(resp *dns.Msg, name string) bool {
+	return len(resp.Question) == 1 && resp.Question[0].Name == name
+}
+
+// randomizeCase returns the name with a random case of each letter
+func randomizeCase(name string) string {
+	random := make([]byte, len(name))
+	_, _ = rand.Read(random)
+
+	result := []byte(name)
+
+	for i, c := range result {
+		if random[i]&1 == 0 {
+			continue
+		}
+
+		switch {
+		case 'a' <= c && c <= 'z':
+			result[i] = c - 'a' + 'A'
+		case 'A' <= c && c <= 'Z':
+			result[i] = c - 'A' + 'a'
+		}
+	}
+
+	return string(result)
+}
+
+// restoreCase replaces the randomized name in the response with the queried one
+func restoreCase(resp *dns.Msg, name string) {
+	resp.Question[0].Name = name
+
+	for _, section := range [][]dns.RR{resp.Answer, resp.Ns, resp.Extra} {
+		for _, rr := range section {
+			if strings.EqualFold(rr.Header().Name, name) {
+				rr.Header().Name = name
+			}
+		}
+	}
+}
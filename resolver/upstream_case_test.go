@@ -0,0 +1,228 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeUpstreamClient answers with the response of fn and records the queried names.
+// It's also used as fake TCP exchanger.
+type fakeUpstreamClient struct {
+	fn    func(msg *dns.Msg) *dns.Msg
+	names []string
+}
+
+func (c *fakeUpstreamClient) fmtURL(ip net.IP, _ uint16, _ string) string {
+	return ip.String()
+}
+
+func (c *fakeUpstreamClient) callExternal(
+	_ context.Context, msg *dns.Msg, _ string, _ RequestProtocol,
+) (*dns.Msg, time.Duration, error) {
+	c.names = append(c.names, msg.Question[0].Name)
+
+	return c.fn(msg), time.Millisecond, nil
+}
+
+func (c *fakeUpstreamClient) ExchangeContext(
+	ctx context.Context, msg *dns.Msg, _ string,
+) (*dns.Msg, time.Duration, error) {
+	return c.callExternal(ctx, msg, "", RequestProtocolTCP)
+}
+
+var _ = Describe("Case randomization", func() {
+	const name = "www.example-domain.com."
+
+	var (
+		inner *fakeUpstreamClient
+		tcp   *fakeUpstreamClient
+		sut   *caseRandomizingClient
+		ctx   context.Context
+	)
+
+	// answer answers with an A record for the name of the question, changed by mangle
+	answer := func(mangle func(string) string) func(msg *dns.Msg) *dns.Msg {
+		return func(msg *dns.Msg) *dns.Msg {
+			qName := mangle(msg.Question[0].Name)
+
+			rr, err := dns.NewRR(qName + " 300 IN A 192.0.2.1")
+			Expect(err).Should(Succeed())
+
+			resp := new(dns.Msg)
+			resp.SetReply(msg)
+			resp.Question[0].Name = qName
+			resp.Answer = []dns.RR{rr}
+
+			return resp
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		inner = &fakeUpstreamClient{fn: answer(func(s string) string { return s })}
+		tcp = &fakeUpstreamClient{fn: answer(func(s string) string { return s })}
+		sut = newCaseRandomizingClient("upstream", inner, tcp)
+	})
+
+	Describe("randomizeCase", func() {
+		It("should only change the case of letters", func() {
+			for range 10 {
+				Expect(strings.EqualFold(randomizeCase(name), name)).Should(BeTrue())
+			}
+
+			Expect(randomizeCase("1.0.168.192.")).Should(Equal("1.0.168.192."))
+		})
+
+		It("should change the case randomly", func() {
+			names := make(map[string]struct{})
+			for range 10 {
+				names[randomizeCase(name)] = struct{}{}
+			}
+
+			Expect(len(names)).Should(BeNumerically(">", 1))
+		})
+	})
+
+	It("should send a random case and restore the name of the response", func() {
+		resp, _, err := sut.callExternal(ctx, util.NewMsgWithQuestion(name, A), "", RequestProtocolUDP)
+		Expect(err).Should(Succeed())
+
+		Expect(inner.names).Should(HaveLen(1))
+		Expect(strings.EqualFold(inner.names[0], name)).Should(BeTrue())
+		Expect(tcp.names).Should(BeEmpty())
+
+		Expect(resp.Question[0].Name).Should(Equal(name))
+		Expect(&Response{Res: resp}).Should(BeDNSRecord(name, A, "192.0.2.1"))
+	})
+
+	// query resolves the name until it was randomized to another case, which may be the same by chance
+	query := func() *dns.Msg {
+		for i := 0; ; i++ {
+			Expect(i).Should(BeNumerically("<", 10))
+
+			inner.names, tcp.names = nil, nil
+
+			resp, _, err := sut.callExternal(ctx, util.NewMsgWithQuestion(name, A), "", RequestProtocolUDP)
+			Expect(err).Should(Succeed())
+
+			if inner.names[0] != strings.ToLower(name) {
+				return resp
+			}
+		}
+	}
+
+	When("the response has another case", func() {
+		BeforeEach(func() {
+			// spoofed
+			inner.fn = answer(strings.ToLower)
+		})
+
+		It("should ask again over TCP with the randomized name", func() {
+			resp := query()
+			Expect(&Response{Res: resp}).Should(BeDNSRecord(name, A, "192.0.2.1"))
+
+			Expect(tcp.names).Should(Equal(inner.names))
+		})
+
+		It("should keep randomizing", func() {
+			for range 10 {
+				query()
+			}
+
+			Expect(sut.disabledUntil.Load()).Should(BeZero())
+			Expect(sut.mismatches.Load()).Should(BeZero())
+		})
+
+		It("should fail if the TCP response has another name", func() {
+			inner.fn = answer(func(string) string { return "other.com." })
+			tcp.fn = inner.fn
+
+			_, _, err := sut.callExternal(ctx, util.NewMsgWithQuestion(name, A), "", RequestProtocolUDP)
+			Expect(err).Should(MatchError(ContainSubstring("another question")))
+			Expect(sut.mismatches.Load()).Should(BeZero())
+		})
+	})
+
+	When("the upstream changes the case", func() {
+		BeforeEach(func() {
+			inner.fn = answer(strings.ToLower)
+			tcp.fn = answer(strings.ToLower)
+		})
+
+		It("should accept the TCP response", func() {
+			resp := query()
+			Expect(&Response{Res: resp}).Should(BeDNSRecord(name, A, "192.0.2.1"))
+			Expect(resp.Question[0].Name).Should(Equal(name))
+		})
+
+		It("should stop randomizing after consecutive mismatches", func() {
+			for range caseMismatchThreshold {
+				query()
+			}
+
+			Expect(sut.disabledUntil.Load()).Should(BeNumerically(">", time.Now().UnixNano()))
+
+			inner.names, tcp.names = nil, nil
+
+			_, _, err := sut.callExternal(ctx, util.NewMsgWithQuestion(name, A), "", RequestProtocolUDP)
+			Expect(err).Should(Succeed())
+			Expect(inner.names).Should(Equal([]string{name}))
+			Expect(tcp.names).Should(BeEmpty())
+		})
+	})
+
+	Describe("upstream resolver", func() {
+		It("should randomize the case for tcp+udp upstreams only", func() {
+			cfg := newUpstreamConfig(config.Upstream{Net: config.NetProtocolTcpUdp, Host: "192.0.2.1", Port: 53},
+				config.Upstreams{RandomizeCase: true})
+			Expect(createUpstreamClient(cfg)).Should(BeAssignableToTypeOf(&caseRandomizingClient{}))
+
+			cfg.Net = config.NetProtocolTcpTls
+			Expect(createUpstreamClient(cfg)).Should(BeAssignableToTypeOf(&dnsUpstreamClient{}))
+
+			cfg.Net = config.NetProtocolTcpUdp
+			cfg.RandomizeCase = false
+			Expect(createUpstreamClient(cfg)).Should(BeAssignableToTypeOf(&dnsUpstreamClient{}))
+		})
+
+		It("should resolve with randomized case", func() {
+			queried := make(chan string, 1)
+
+			upstream := NewMockUDPUpstreamServer().WithAnswerFn(func(request *dns.Msg) *dns.Msg {
+				queried <- request.Question[0].Name
+
+				rr, err := dns.NewRR(request.Question[0].Name + " 300 IN A 192.0.2.1")
+				Expect(err).Should(Succeed())
+
+				resp := new(dns.Msg)
+				resp.SetReply(request)
+				resp.Answer = []dns.RR{rr}
+
+				return resp
+			})
+			DeferCleanup(upstream.Close)
+
+			cfg := newUpstreamConfig(upstream.Start(), config.Upstreams{
+				RandomizeCase: true,
+				Timeout:       config.Duration(time.Second),
+			})
+
+			sut, err := newUpstreamResolverUnchecked(cfg, systemResolverBootstrap)
+			Expect(err).Should(Succeed())
+
+			Expect(sut.Resolve(ctx, newRequest(name, A))).Should(BeDNSRecord(name, A, "192.0.2.1"))
+			Expect(strings.EqualFold(<-queried, name)).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,194 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// sizes of the cookies (RFC 7873 section 4)
+	clientCookieLength    = 8
+	minServerCookieLength = 8
+	maxServerCookieLength = 32
+
+	// UDP size of the queries which get an OPT record for the cookie
+	cookieUDPSize = 1232
+
+	// how long upstreams answering queries with cookies with FORMERR get the queries without cookie
+	cookieFallbackCooldown = time.Hour
+)
+
+var errInvalidClientCookie = errors.New("response with another client cookie, it may be spoofed")
+
+// cookieClient sends DNS Cookies (RFC 7873) to the upstream: responses with another client cookie are rejected as
+// spoofed and the server cookie returned by the upstream proves to it that the queries aren't spoofed
+type cookieClient struct {
+	upstreamClient
+
+	upstream string
+	secret   []byte
+
+	lock sync.Mutex
+	// cookies per upstream address
+	cookies map[string]*upstreamCookies
+
+	// time (unix nanoseconds) until which the queries are sent without cookie
+	disabledUntil atomic.Int64
+}
+
+type upstreamCookies struct {
+	client []byte
+	// nil until the upstream returns one
+	server []byte
+}
+
+func newCookieClient(upstream string, client upstreamClient) *cookieClient {
+	secret := make([]byte, sha256.Size)
+	_, _ = rand.Read(secret)
+
+	return &cookieClient{
+		upstreamClient: client,
+		upstream:       upstream,
+		secret:         secret,
+		cookies:        make(map[string]*upstreamCookies),
+	}
+}
+
+func (c *cookieClient) callExternal(
+	ctx context.Context, msg *dns.Msg, upstreamURL string, protocol model.RequestProtocol,
+) (*dns.Msg, time.Duration, error) {
+	if time.Now().UnixNano() < c.disabledUntil.Load() {
+		return c.upstreamClient.callExternal(ctx, msg, upstreamURL, protocol)
+	}
+
+	clientCookie, serverCookie := c.cookiesFor(upstreamURL)
+
+	resp, rtt, err := c.upstreamClient.callExternal(ctx, withCookie(msg, clientCookie, serverCookie), upstreamURL,
+		protocol)
+	if err != nil || resp == nil {
+		return resp, rtt, err
+	}
+
+	if resp.Rcode == dns.RcodeFormatError && util.GetEdns0Option[*dns.EDNS0_COOKIE](resp) == nil {
+		c.disabledUntil.Store(time.Now().Add(cookieFallbackCooldown).UnixNano())
+
+		log.PrefixedLog("upstream").Warnf("%s doesn't accept DNS cookies, not sending them for %s",
+			c.upstream, cookieFallbackCooldown)
+
+		return c.upstreamClient.callExternal(ctx, msg, upstreamURL, protocol)
+	}
+
+	if err := c.learn(upstreamURL, clientCookie, resp); err != nil {
+		return nil, rtt, err
+	}
+
+	if resp.Rcode == dns.RcodeBadCookie {
+		// RFC 7873 section 5.3: ask again once with the new server cookie
+		_, serverCookie = c.cookiesFor(upstreamURL)
+
+		resp, rtt, err = c.upstreamClient.callExternal(ctx, withCookie(msg, clientCookie, serverCookie), upstreamURL,
+			protocol)
+		if err != nil || resp == nil {
+			return resp, rtt, err
+		}
+
+		if err := c.learn(upstreamURL, clientCookie, resp); err != nil {
+			return nil, rtt, err
+		}
+	}
+
+	removeCookie(resp, msg.IsEdns0() == nil)
+
+	return resp, rtt, nil
+}
+
+// cookiesFor returns the client cookie for the upstream address and its last server cookie
+func (c *cookieClient) cookiesFor(upstreamURL string) (clientCookie, serverCookie []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cookies, ok := c.cookies[upstreamURL]
+	if !ok {
+		// RFC 7873 appendix A.2: the client cookie depends on the server address
+		mac := hmac.New(sha256.New, c.secret)
+		mac.Write([]byte(upstreamURL))
+
+		cookies = &upstreamCookies{client: mac.Sum(nil)[:clientCookieLength]}
+		c.cookies[upstreamURL] = cookies
+	}
+
+	return cookies.client, cookies.server
+}
+
+// learn checks the client cookie of the response and records the server cookie
+func (c *cookieClient) learn(upstreamURL string, clientCookie []byte, resp *dns.Msg) error {
+	option := util.GetEdns0Option[*dns.EDNS0_COOKIE](resp)
+	if option == nil {
+		// the upstream doesn't support cookies
+		return nil
+	}
+
+	cookie, err := hex.DecodeString(option.Cookie)
+	if err != nil || len(cookie) < clientCookieLength || !bytes.Equal(cookie[:clientCookieLength], clientCookie) {
+		return errInvalidClientCookie
+	}
+
+	serverCookie := cookie[clientCookieLength:]
+	if len(serverCookie) < minServerCookieLength || len(serverCookie) > maxServerCookieLength {
+		return nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.cookies[upstreamURL].server = serverCookie
+
+	return nil
+}
+
+// withCookie returns a copy of the message with the cookies, replacing the cookie of the client of blocky
+func withCookie(msg *dns.Msg, clientCookie, serverCookie []byte) *dns.Msg {
+	res := msg.Copy()
+
+	if res.IsEdns0() == nil {
+		res.SetEdns0(cookieUDPSize, false)
+	}
+
+	util.SetEdns0Option(res, &dns.EDNS0_COOKIE{
+		Code:   dns.EDNS0COOKIE,
+		Cookie: hex.EncodeToString(slices.Concat(clientCookie, serverCookie)),
+	})
+
+	return res
+}
+
+// removeCookie removes the cookie of the upstream from the response, and the OPT record if it was only added for it
+func removeCookie(resp *dns.Msg, addedOPT bool) {
+	// extended response codes need the OPT record
+	if addedOPT && resp.Rcode <= 0xF {
+		util.RemoveEdns0Record(resp)
+
+		return
+	}
+
+	if opt := resp.IsEdns0(); opt != nil {
+		opt.Option = slices.DeleteFunc(opt.Option, func(o dns.EDNS0) bool {
+			return o.Option() == dns.EDNS0COOKIE
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package resolver
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNS cookies to upstreams", func() {
+	const serverCookie = "0100000065a1b2c3d4e5f60718293a4b"
+
+	var (
+		inner   *fakeUpstreamClient
+		sut     *cookieClient
+		ctx     context.Context
+		queried []string
+		rcode   int
+	)
+
+	// answer records the queried cookie and answers with the client cookie and the server cookie
+	answer := func(msg *dns.Msg) *dns.Msg {
+		resp := new(dns.Msg)
+		resp.SetRcode(msg, rcode)
+
+		cookie := util.GetEdns0Option[*dns.EDNS0_COOKIE](msg)
+		if cookie == nil {
+			queried = append(queried, "")
+
+			return resp
+		}
+
+		queried = append(queried, cookie.Cookie)
+
+		resp.SetEdns0(msg.IsEdns0().UDPSize(), false)
+		util.SetEdns0Option(resp, &dns.EDNS0_COOKIE{
+			Code:   dns.EDNS0COOKIE,
+			Cookie: cookie.Cookie[:2*clientCookieLength] + serverCookie,
+		})
+
+		return resp
+	}
+
+	query := func(msg *dns.Msg) (*dns.Msg, error) {
+		resp, _, err := sut.callExternal(ctx, msg, "192.0.2.1:53", RequestProtocolUDP)
+
+		return resp, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		queried = nil
+		rcode = dns.RcodeSuccess
+		inner = &fakeUpstreamClient{fn: answer}
+		sut = newCookieClient("upstream", inner)
+	})
+
+	It("should send the client cookie and then the server cookie of the upstream", func() {
+		resp, err := query(util.NewMsgWithQuestion("example.com.", A))
+		Expect(err).Should(Succeed())
+
+		// the OPT record was only added for the cookie
+		Expect(resp.IsEdns0()).Should(BeNil())
+
+		_, err = query(util.NewMsgWithQuestion("example.com.", A))
+		Expect(err).Should(Succeed())
+
+		Expect(queried).Should(HaveLen(2))
+		Expect(queried[0]).Should(HaveLen(2 * clientCookieLength))
+		Expect(queried[1]).Should(Equal(queried[0] + serverCookie))
+	})
+
+	It("should use another client cookie per upstream address", func() {
+		clientCookie, _ := sut.cookiesFor("192.0.2.1:53")
+		otherCookie, _ := sut.cookiesFor("192.0.2.2:53")
+
+		Expect(clientCookie).Should(HaveLen(clientCookieLength))
+		Expect(clientCookie).ShouldNot(Equal(otherCookie))
+	})
+
+	It("should replace the cookie of the client and remove the one of the upstream", func() {
+		msg := util.NewMsgWithQuestion("example.com.", A)
+		msg.SetEdns0(4096, true)
+		util.SetEdns0Option(msg, &dns.EDNS0_COOKIE{Code: dns.EDNS0COOKIE, Cookie: "0011223344556677"})
+
+		resp, err := query(msg)
+		Expect(err).Should(Succeed())
+
+		Expect(queried[0]).ShouldNot(Equal("0011223344556677"))
+		Expect(resp.IsEdns0()).ShouldNot(BeNil())
+		Expect(util.GetEdns0Option[*dns.EDNS0_COOKIE](resp)).Should(BeNil())
+
+		// the query of the client is unchanged
+		Expect(util.GetEdns0Option[*dns.EDNS0_COOKIE](msg).Cookie).Should(Equal("0011223344556677"))
+	})
+
+	It("should reject responses with another client cookie", func() {
+		inner.fn = func(msg *dns.Msg) *dns.Msg {
+			resp := answer(msg)
+			util.SetEdns0Option(resp, &dns.EDNS0_COOKIE{Code: dns.EDNS0COOKIE, Cookie: "0011223344556677" + serverCookie})
+
+			return resp
+		}
+
+		_, err := query(util.NewMsgWithQuestion("example.com.", A))
+		Expect(err).Should(MatchError(errInvalidClientCookie))
+	})
+
+	It("should ask again with the new server cookie after BADCOOKIE", func() {
+		inner.fn = func(msg *dns.Msg) *dns.Msg {
+			resp := answer(msg)
+			if len(queried) == 1 {
+				resp.Rcode = dns.RcodeBadCookie
+			}
+
+			return resp
+		}
+
+		resp, err := query(util.NewMsgWithQuestion("example.com.", A))
+		Expect(err).Should(Succeed())
+		Expect(resp.Rcode).Should(Equal(dns.RcodeSuccess))
+
+		Expect(queried).Should(HaveLen(2))
+		Expect(queried[1]).Should(HaveSuffix(serverCookie))
+	})
+
+	It("should stop sending cookies to upstreams answering them with FORMERR", func() {
+		inner.fn = func(msg *dns.Msg) *dns.Msg {
+			resp := new(dns.Msg)
+
+			if util.GetEdns0Option[*dns.EDNS0_COOKIE](msg) != nil {
+				queried = append(queried, "cookie")
+				resp.SetRcode(msg, dns.RcodeFormatError)
+			} else {
+				queried = append(queried, "")
+				resp.SetReply(msg)
+			}
+
+			return resp
+		}
+
+		resp, err := query(util.NewMsgWithQuestion("example.com.", A))
+		Expect(err).Should(Succeed())
+		Expect(resp.Rcode).Should(Equal(dns.RcodeSuccess))
+
+		_, err = query(util.NewMsgWithQuestion("example.com.", A))
+		Expect(err).Should(Succeed())
+
+		Expect(queried).Should(Equal([]string{"cookie", "", ""}))
+	})
+
+	Describe("upstream resolver", func() {
+		It("should send cookies to tcp+udp upstreams only", func() {
+			cfg := newUpstreamConfig(config.Upstream{Net: config.NetProtocolTcpUdp, Host: "192.0.2.1", Port: 53},
+				config.Upstreams{Cookies: true})
+			Expect(createUpstreamClient(cfg)).Should(BeAssignableToTypeOf(&cookieClient{}))
+
+			cfg.Net = config.NetProtocolTcpTls
+			Expect(createUpstreamClient(cfg)).Should(BeAssignableToTypeOf(&dnsUpstreamClient{}))
+		})
+
+		It("should resolve with cookies", func() {
+			cookies := make(chan string, 1)
+
+			upstream := NewMockUDPUpstreamServer().WithAnswerFn(func(request *dns.Msg) *dns.Msg {
+				cookies <- util.GetEdns0Option[*dns.EDNS0_COOKIE](request).Cookie
+
+				rr, err := dns.NewRR("example.com. 300 IN A 192.0.2.1")
+				Expect(err).Should(Succeed())
+
+				resp := new(dns.Msg)
+				resp.SetReply(request)
+				resp.Answer = []dns.RR{rr}
+
+				return resp
+			})
+			DeferCleanup(upstream.Close)
+
+			cfg := newUpstreamConfig(upstream.Start(), config.Upstreams{Cookies: true, Timeout: config.Duration(time.Second)})
+
+			r, err := newUpstreamResolverUnchecked(cfg, systemResolverBootstrap)
+			Expect(err).Should(Succeed())
+
+			Expect(r.Resolve(ctx, newRequest("example.com.", A))).Should(SatisfyAll(
+				BeDNSRecord("example.com.", A, "192.0.2.1"),
+				HaveResponseType(ResponseTypeRESOLVED),
+			))
+
+			decoded, err := hex.DecodeString(<-cookies)
+			Expect(err).Should(Succeed())
+			Expect(decoded).Should(HaveLen(clientCookieLength))
+		})
+	})
+})
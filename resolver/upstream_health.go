@@ -0,0 +1,328 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/miekg/dns"
+)
+
+const (
+	// number of latest results used for the rolling stats of an upstream
+	upstreamHealthWindow = 20
+
+	// weight of the latest result in the latency EWMA
+	upstreamLatencyEWMAWeight = 0.3
+
+	// lower bound of the success rate used in `upstreamHealth.score`, so failing upstreams still get a score
+	upstreamMinSuccessRate = 0.05
+)
+
+// upstreamHealth tracks the rolling error rate and latency of an upstream, if it passes the health checks
+// and if the circuit breaker took it out of rotation
+type upstreamHealth struct {
+	healthy atomic.Bool
+	// time (unix nanoseconds) until which the circuit breaker takes the upstream out of rotation
+	downUntil atomic.Int64
+
+	lock                sync.Mutex
+	results             [upstreamHealthWindow]upstreamResult
+	count, next         int
+	consecutiveFailures uint // health checks
+	failedQueries       uint // consecutive failed queries, for the circuit breaker
+	lastCheck           time.Time
+	latency             time.Duration // EWMA of successful queries
+}
+
+type upstreamResult struct {
+	failed   bool
+	duration time.Duration
+}
+
+func newUpstreamHealth() *upstreamHealth {
+	h := &upstreamHealth{}
+	h.healthy.Store(true)
+
+	return h
+}
+
+// record adds the result of a query to the rolling stats
+func (h *upstreamHealth) record(err error, duration time.Duration) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.recordLocked(err, duration)
+}
+
+func (h *upstreamHealth) recordLocked(err error, duration time.Duration) {
+	h.results[h.next] = upstreamResult{failed: err != nil, duration: duration}
+	h.next = (h.next + 1) % upstreamHealthWindow
+	h.count = min(h.count+1, upstreamHealthWindow)
+
+	if err != nil {
+		return
+	}
+
+	if h.latency == 0 {
+		h.latency = duration
+	} else {
+		h.latency += time.Duration(float64(duration-h.latency) * upstreamLatencyEWMAWeight)
+	}
+}
+
+// recordQuery adds the result of a query and returns true if the circuit breaker took the upstream out of rotation
+func (h *upstreamHealth) recordQuery(err error, duration time.Duration, cb config.UpstreamCircuitBreaker) (down bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.recordLocked(err, duration)
+
+	if err == nil {
+		h.failedQueries = 0
+
+		return false
+	}
+
+	h.failedQueries++
+
+	// once the duration is over, the next failure takes it out again
+	if !cb.IsEnabled() || h.failedQueries < cb.FailureThreshold || !h.isUp() {
+		return false
+	}
+
+	h.downUntil.Store(time.Now().Add(cb.Duration.ToDuration()).UnixNano())
+
+	return true
+}
+
+// isUp returns true if the upstream passes the health checks and the circuit breaker didn't take it out of rotation
+func (h *upstreamHealth) isUp() bool {
+	return h.healthy.Load() && time.Now().UnixNano() >= h.downUntil.Load()
+}
+
+// recordCheck adds the result of a health check and returns true if the health state changed
+func (h *upstreamHealth) recordCheck(err error, duration time.Duration, failureThreshold uint) (changed bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.recordLocked(err, duration)
+	h.lastCheck = time.Now()
+
+	if err == nil {
+		h.consecutiveFailures = 0
+
+		return h.healthy.CompareAndSwap(false, true)
+	}
+
+	h.consecutiveFailures++
+
+	if h.consecutiveFailures < failureThreshold {
+		return false
+	}
+
+	return h.healthy.CompareAndSwap(true, false)
+}
+
+// stats returns the error rate (0 - 1) and average latency of successful queries over the latest results
+func (h *upstreamHealth) stats() (errorRate float64, avgLatency time.Duration, lastCheck time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.count == 0 {
+		return 0, 0, h.lastCheck
+	}
+
+	var (
+		failed    int
+		totalTime time.Duration
+	)
+
+	for _, res := range h.results[:h.count] {
+		if res.failed {
+			failed++
+		} else {
+			totalTime += res.duration
+		}
+	}
+
+	if succeeded := h.count - failed; succeeded > 0 {
+		avgLatency = totalTime / time.Duration(succeeded)
+	}
+
+	return float64(failed) / float64(h.count), avgLatency, h.lastCheck
+}
+
+// latencyPercentile returns the p-th percentile (1 - 100) of the latencies of successful queries over the latest
+// results, false if there are none
+func (h *upstreamHealth) latencyPercentile(p uint) (time.Duration, bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	latencies := make([]time.Duration, 0, h.count)
+
+	for _, res := range h.results[:h.count] {
+		if !res.failed {
+			latencies = append(latencies, res.duration)
+		}
+	}
+
+	if len(latencies) == 0 {
+		return 0, false
+	}
+
+	slices.Sort(latencies)
+
+	// nearest-rank method
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(latencies)))) //nolint:mnd
+
+	return latencies[max(rank, 1)-1], true
+}
+
+// score returns the expected latency of the upstream, increased by its error rate: lower is better.
+// Upstreams without any result have the best score, so they get tried.
+func (h *upstreamHealth) score() time.Duration {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.count == 0 {
+		return 0
+	}
+
+	if h.latency == 0 {
+		// only failures so far
+		return time.Duration(math.MaxInt64)
+	}
+
+	failed := 0
+
+	for _, res := range h.results[:h.count] {
+		if res.failed {
+			failed++
+		}
+	}
+
+	successRate := max(1-float64(failed)/float64(h.count), upstreamMinSuccessRate)
+
+	return time.Duration(float64(h.latency) / successRate)
+}
+
+// healthyOrAll returns the healthy resolvers, or all if none is healthy: better try an unhealthy one than none
+func healthyOrAll(resolvers []*upstreamResolverStatus) []*upstreamResolverStatus {
+	unhealthy := func(r *upstreamResolverStatus) bool {
+		return !r.health.isUp()
+	}
+
+	if !slices.ContainsFunc(resolvers, unhealthy) {
+		return resolvers
+	}
+
+	healthy := slices.DeleteFunc(slices.Clone(resolvers), unhealthy)
+	if len(healthy) == 0 {
+		return resolvers
+	}
+
+	return healthy
+}
+
+// startHealthChecks checks all resolvers every `cfg.HealthCheck.Interval` until ctx is done
+func startHealthChecks(ctx context.Context, cfg config.UpstreamGroup, resolvers []*upstreamResolverStatus) {
+	if !cfg.HealthCheck.IsEnabled() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.HealthCheck.Interval.ToDuration())
+		defer ticker.Stop()
+
+		groupHealthy := true
+
+		for {
+			select {
+			case <-ticker.C:
+				for _, r := range resolvers {
+					r.check(ctx, cfg)
+				}
+
+				if healthy := anyHealthy(resolvers); healthy != groupHealthy {
+					groupHealthy = healthy
+
+					evt.Bus().Publish(evt.UpstreamGroupHealthChanged, cfg.Name, healthy)
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// anyHealthy returns true if at least one of the resolvers is healthy
+func anyHealthy(resolvers []*upstreamResolverStatus) bool {
+	return slices.ContainsFunc(resolvers, func(r *upstreamResolverStatus) bool {
+		return r.health.healthy.Load()
+	})
+}
+
+// check sends the health check query to the resolver and updates its health
+func (r *upstreamResolverStatus) check(ctx context.Context, cfg config.UpstreamGroup) {
+	upstream := r.upstreamName()
+	logger := log.FromCtx(ctx).WithField("upstream", upstream)
+
+	start := time.Now()
+
+	_, err := r.resolver.Resolve(ctx, newRequest(dns.Fqdn(cfg.HealthCheck.Name), dns.Type(dns.TypeA)))
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if !r.health.recordCheck(err, time.Since(start), cfg.HealthCheck.FailureThreshold) {
+		return
+	}
+
+	healthy := r.health.healthy.Load()
+	if healthy {
+		logger.Infof("upstream of group '%s' is healthy again", cfg.Name)
+	} else {
+		logger.WithError(err).Warnf("upstream of group '%s' is unhealthy, removing it from rotation", cfg.Name)
+	}
+
+	evt.Bus().Publish(evt.UpstreamHealthChanged, upstream, healthy)
+}
+
+// upstreamName returns the upstream of the resolver as it was configured
+func (r *upstreamResolverStatus) upstreamName() string {
+	if u, ok := r.resolver.(interface{ Upstream() config.Upstream }); ok {
+		return u.Upstream().String()
+	}
+
+	return r.resolver.String()
+}
+
+// healthStatus returns the health and rolling stats of all resolvers in the group
+func healthStatus(group string, resolvers []*upstreamResolverStatus) []api.UpstreamStatus {
+	res := make([]api.UpstreamStatus, 0, len(resolvers))
+
+	for _, r := range resolvers {
+		errorRate, avgLatency, lastCheck := r.health.stats()
+
+		res = append(res, api.UpstreamStatus{
+			Group:          group,
+			Upstream:       r.upstreamName(),
+			Healthy:        r.health.isUp(),
+			ErrorRate:      errorRate,
+			AverageLatency: avgLatency,
+			LastCheck:      lastCheck,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,365 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/evt"
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("upstreamHealth", func() {
+	var sut *upstreamHealth
+
+	BeforeEach(func() {
+		sut = newUpstreamHealth()
+	})
+
+	It("should be healthy without any result", func() {
+		Expect(sut.healthy.Load()).Should(BeTrue())
+
+		errorRate, avgLatency, lastCheck := sut.stats()
+		Expect(errorRate).Should(BeZero())
+		Expect(avgLatency).Should(BeZero())
+		Expect(lastCheck).Should(BeZero())
+	})
+
+	Describe("stats", func() {
+		It("should compute error rate and latency of successful queries", func() {
+			sut.record(nil, 10*time.Millisecond)
+			sut.record(nil, 30*time.Millisecond)
+			sut.record(errors.New("boom"), time.Second)
+			sut.record(nil, 20*time.Millisecond)
+
+			errorRate, avgLatency, _ := sut.stats()
+			Expect(errorRate).Should(BeNumerically("~", 0.25))
+			Expect(avgLatency).Should(Equal(20 * time.Millisecond))
+		})
+
+		It("should only consider the latest results", func() {
+			for range upstreamHealthWindow {
+				sut.record(errors.New("boom"), time.Second)
+			}
+
+			for range upstreamHealthWindow {
+				sut.record(nil, time.Millisecond)
+			}
+
+			errorRate, avgLatency, _ := sut.stats()
+			Expect(errorRate).Should(BeZero())
+			Expect(avgLatency).Should(Equal(time.Millisecond))
+		})
+	})
+
+	Describe("latencyPercentile", func() {
+		It("should be unknown without successful queries", func() {
+			sut.record(errors.New("boom"), time.Second)
+
+			_, ok := sut.latencyPercentile(95)
+			Expect(ok).Should(BeFalse())
+		})
+
+		It("should compute the percentile of successful queries", func() {
+			for i := range 10 {
+				sut.record(nil, time.Duration(10-i)*time.Millisecond)
+			}
+
+			sut.record(errors.New("boom"), time.Second)
+
+			percentile := func(p uint) time.Duration {
+				latency, ok := sut.latencyPercentile(p)
+				Expect(ok).Should(BeTrue())
+
+				return latency
+			}
+
+			Expect(percentile(50)).Should(Equal(5 * time.Millisecond))
+			Expect(percentile(95)).Should(Equal(10 * time.Millisecond))
+			Expect(percentile(1)).Should(Equal(time.Millisecond))
+		})
+	})
+
+	Describe("recordCheck", func() {
+		It("should become unhealthy after the threshold of consecutive failures", func() {
+			Expect(sut.recordCheck(errors.New("boom"), time.Second, 2)).Should(BeFalse())
+			Expect(sut.healthy.Load()).Should(BeTrue())
+
+			Expect(sut.recordCheck(errors.New("boom"), time.Second, 2)).Should(BeTrue())
+			Expect(sut.healthy.Load()).Should(BeFalse())
+
+			Expect(sut.recordCheck(errors.New("boom"), time.Second, 2)).Should(BeFalse())
+
+			_, _, lastCheck := sut.stats()
+			Expect(lastCheck).ShouldNot(BeZero())
+		})
+
+		It("should reset the failure count on success", func() {
+			Expect(sut.recordCheck(errors.New("boom"), time.Second, 2)).Should(BeFalse())
+			Expect(sut.recordCheck(nil, time.Millisecond, 2)).Should(BeFalse())
+			Expect(sut.recordCheck(errors.New("boom"), time.Second, 2)).Should(BeFalse())
+
+			Expect(sut.healthy.Load()).Should(BeTrue())
+		})
+
+		It("should become healthy again after a successful check", func() {
+			Expect(sut.recordCheck(errors.New("boom"), time.Second, 1)).Should(BeTrue())
+			Expect(sut.healthy.Load()).Should(BeFalse())
+
+			Expect(sut.recordCheck(nil, time.Millisecond, 1)).Should(BeTrue())
+			Expect(sut.healthy.Load()).Should(BeTrue())
+		})
+	})
+
+	Describe("recordQuery", func() {
+		var cb config.UpstreamCircuitBreaker
+
+		BeforeEach(func() {
+			cb = config.UpstreamCircuitBreaker{FailureThreshold: 2, Duration: config.Duration(time.Hour)}
+		})
+
+		It("should take the upstream out of rotation after the threshold of consecutive failures", func() {
+			Expect(sut.recordQuery(errors.New("boom"), time.Second, cb)).Should(BeFalse())
+			Expect(sut.isUp()).Should(BeTrue())
+
+			Expect(sut.recordQuery(errors.New("boom"), time.Second, cb)).Should(BeTrue())
+			Expect(sut.isUp()).Should(BeFalse())
+			Expect(sut.healthy.Load()).Should(BeTrue())
+
+			Expect(sut.recordQuery(errors.New("boom"), time.Second, cb)).Should(BeFalse())
+		})
+
+		It("should reset the failure count on success", func() {
+			Expect(sut.recordQuery(errors.New("boom"), time.Second, cb)).Should(BeFalse())
+			Expect(sut.recordQuery(nil, time.Millisecond, cb)).Should(BeFalse())
+			Expect(sut.recordQuery(errors.New("boom"), time.Second, cb)).Should(BeFalse())
+
+			Expect(sut.isUp()).Should(BeTrue())
+		})
+
+		It("should take the upstream out again on the next failure after the duration", func() {
+			cb.Duration = config.Duration(10 * time.Millisecond)
+
+			sut.recordQuery(errors.New("boom"), time.Second, cb)
+			Expect(sut.recordQuery(errors.New("boom"), time.Second, cb)).Should(BeTrue())
+
+			Eventually(sut.isUp).Should(BeTrue())
+
+			Expect(sut.recordQuery(errors.New("boom"), time.Second, cb)).Should(BeTrue())
+			Expect(sut.isUp()).Should(BeFalse())
+		})
+
+		It("should do nothing if the circuit breaker is disabled", func() {
+			cb.FailureThreshold = 0
+
+			for range 10 {
+				Expect(sut.recordQuery(errors.New("boom"), time.Second, cb)).Should(BeFalse())
+			}
+
+			Expect(sut.isUp()).Should(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Upstream health checks", func() {
+	var (
+		healthyResolver, failingResolver *mockResolver
+		resolvers                        []*upstreamResolverStatus
+		sutConfig                        config.UpstreamGroup
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		healthyResolver = &mockResolver{}
+		healthyResolver.On("Resolve", mock.Anything).Return(&Response{Res: new(dns.Msg)}, nil)
+
+		failingResolver = &mockResolver{}
+		failingResolver.On("Resolve", mock.Anything).Return(nil, errors.New("boom"))
+
+		resolvers = newUpstreamResolverStatuses([]Resolver{failingResolver, healthyResolver})
+
+		sutConfig = config.NewUpstreamGroup("test", defaultUpstreamsConfig, nil)
+		sutConfig.HealthCheck.Interval = config.Duration(10 * time.Millisecond)
+		sutConfig.HealthCheck.Name = "example.com"
+		sutConfig.HealthCheck.FailureThreshold = 1
+	})
+
+	markUnhealthy := func(r *upstreamResolverStatus) {
+		r.health.healthy.Store(false)
+	}
+
+	lastCheck := func(r *upstreamResolverStatus) func() time.Time {
+		return func() time.Time {
+			_, _, lastCheck := r.health.stats()
+
+			return lastCheck
+		}
+	}
+
+	Describe("healthyOrAll", func() {
+		It("should return all resolvers if all are healthy", func() {
+			Expect(healthyOrAll(resolvers)).Should(Equal(resolvers))
+		})
+
+		It("should skip unhealthy resolvers", func() {
+			markUnhealthy(resolvers[0])
+
+			Expect(healthyOrAll(resolvers)).Should(ConsistOf(resolvers[1]))
+			Expect(resolvers).Should(HaveLen(2))
+		})
+
+		It("should skip resolvers taken out by the circuit breaker", func() {
+			resolvers[0].health.downUntil.Store(time.Now().Add(time.Hour).UnixNano())
+
+			Expect(healthyOrAll(resolvers)).Should(ConsistOf(resolvers[1]))
+		})
+
+		It("should return all resolvers if none is healthy", func() {
+			markUnhealthy(resolvers[0])
+			markUnhealthy(resolvers[1])
+
+			Expect(healthyOrAll(resolvers)).Should(Equal(resolvers))
+		})
+	})
+
+	Describe("check", func() {
+		It("should query the configured name", func() {
+			resolvers[1].check(ctx, sutConfig)
+
+			Expect(healthyResolver.Calls).Should(HaveLen(1))
+
+			req := healthyResolver.Calls[0].Arguments.Get(0).(*Request)
+			Expect(req.Req.Question[0].Name).Should(Equal("example.com."))
+			Expect(req.Req.Question[0].Qtype).Should(Equal(dns.TypeA))
+		})
+
+		It("should fire an event if the health changes", func() {
+			var (
+				changed atomic.Bool
+				healthy atomic.Bool
+			)
+
+			handler := func(_ string, isHealthy bool) {
+				changed.Store(true)
+				healthy.Store(isHealthy)
+			}
+			Expect(Bus().Subscribe(UpstreamHealthChanged, handler)).Should(Succeed())
+			DeferCleanup(func() { _ = Bus().Unsubscribe(UpstreamHealthChanged, handler) })
+
+			resolvers[0].check(ctx, sutConfig)
+
+			Expect(changed.Load()).Should(BeTrue())
+			Expect(healthy.Load()).Should(BeFalse())
+			Expect(resolvers[0].health.healthy.Load()).Should(BeFalse())
+		})
+	})
+
+	Describe("startHealthChecks", func() {
+		It("should periodically check all resolvers", func() {
+			startHealthChecks(ctx, sutConfig, resolvers)
+
+			Eventually(resolvers[0].health.healthy.Load).Should(BeFalse())
+			Eventually(lastCheck(resolvers[1])).ShouldNot(BeZero())
+			Expect(resolvers[1].health.healthy.Load()).Should(BeTrue())
+		})
+
+		It("should fire an event if all upstreams of the group are unhealthy", func() {
+			var groupDown atomic.Value
+
+			handler := func(group string, healthy bool) {
+				if group == "test" && !healthy {
+					groupDown.Store(group)
+				}
+			}
+			Expect(Bus().Subscribe(UpstreamGroupHealthChanged, handler)).Should(Succeed())
+			DeferCleanup(func() { _ = Bus().Unsubscribe(UpstreamGroupHealthChanged, handler) })
+
+			resolvers = newUpstreamResolverStatuses([]Resolver{failingResolver})
+
+			startHealthChecks(ctx, sutConfig, resolvers)
+
+			Eventually(groupDown.Load).Should(Equal("test"))
+		})
+
+		It("should not check if disabled", func() {
+			sutConfig.HealthCheck.Interval = 0
+
+			startHealthChecks(ctx, sutConfig, resolvers)
+
+			Consistently(lastCheck(resolvers[1]), "50ms").Should(BeZero())
+		})
+	})
+
+	Describe("strategies", func() {
+		BeforeEach(func() {
+			markUnhealthy(resolvers[0])
+		})
+
+		It("parallel_best should not use unhealthy upstreams", func() {
+			sut := newParallelBestResolver(sutConfig, nil)
+			sut.setResolvers(resolvers)
+
+			for range 5 {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+			}
+
+			Expect(failingResolver.Calls).Should(BeEmpty())
+		})
+
+		It("strict should not use unhealthy upstreams", func() {
+			sut := newStrictResolver(sutConfig, nil)
+			sut.setResolvers(resolvers)
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			Expect(failingResolver.Calls).Should(BeEmpty())
+		})
+
+		It("should not use upstreams taken out by the circuit breaker", func() {
+			resolvers[0].health.healthy.Store(true)
+			resolvers[0].circuitBreaker = config.UpstreamCircuitBreaker{
+				FailureThreshold: 1,
+				Duration:         config.Duration(time.Hour),
+			}
+
+			sut := newStrictResolver(sutConfig, nil)
+			sut.setResolvers(resolvers)
+
+			for range 3 {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(HaveResponseType(ResponseTypeRESOLVED))
+			}
+
+			failingResolver.AssertNumberOfCalls(GinkgoT(), "Resolve", 1)
+		})
+
+		It("should report the status of each upstream", func() {
+			sut := newStrictResolver(sutConfig, nil)
+			sut.setResolvers(resolvers)
+
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(HaveResponseType(ResponseTypeRESOLVED))
+
+			status := sut.UpstreamStatus()
+			Expect(status).Should(HaveLen(2))
+			Expect(status[0].Group).Should(Equal("test"))
+			Expect(status[0].Healthy).Should(BeFalse())
+			Expect(status[1].Healthy).Should(BeTrue())
+			Expect(status[1].ErrorRate).Should(BeZero())
+		})
+	})
+})
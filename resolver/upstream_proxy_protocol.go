@@ -0,0 +1,84 @@
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/proxyprotocol"
+
+	"github.com/miekg/dns"
+)
+
+type proxyProtocolClientKey struct{}
+
+// withProxyProtocolClient returns a context with the client IP to send in the PROXY protocol header
+func withProxyProtocolClient(ctx context.Context, ip net.IP) context.Context {
+	return context.WithValue(ctx, proxyProtocolClientKey{}, ip)
+}
+
+// proxyProtocolUpstreamClient queries an upstream via TCP or DoT and starts each connection with a PROXY protocol
+// header, so the upstream sees the client IP.
+// As the header is per connection, each query uses its own connection and TCP+UDP upstreams are only queried via TCP.
+type proxyProtocolUpstreamClient struct {
+	client    *dns.Client
+	dialer    *net.Dialer
+	tlsConfig *tls.Config
+}
+
+func newProxyProtocolUpstreamClient(cfg upstreamConfig, tlsConfig *tls.Config) *proxyProtocolUpstreamClient {
+	res := &proxyProtocolUpstreamClient{
+		client: &dns.Client{Net: "tcp"},
+		dialer: newUpstreamDialer(cfg.bind, "tcp"),
+	}
+
+	if cfg.Net == config.NetProtocolTcpTls {
+		res.tlsConfig = tlsConfig
+	}
+
+	return res
+}
+
+func (r *proxyProtocolUpstreamClient) fmtURL(ip net.IP, port uint16, _ string) string {
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+}
+
+func (r *proxyProtocolUpstreamClient) callExternal(
+	ctx context.Context, msg *dns.Msg, upstreamURL string, _ model.RequestProtocol,
+) (*dns.Msg, time.Duration, error) {
+	conn, err := r.dialer.DialContext(ctx, "tcp", upstreamURL)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// without client, e.g. for test queries, a LOCAL header is sent
+	header := proxyprotocol.Header{}
+
+	if ip, ok := ctx.Value(proxyProtocolClientKey{}).(net.IP); ok && ip != nil {
+		header.Source = &net.TCPAddr{IP: ip}
+		header.Destination, _ = conn.RemoteAddr().(*net.TCPAddr)
+	}
+
+	if _, err := header.WriteTo(conn); err != nil {
+		return nil, 0, fmt.Errorf("can't send PROXY protocol header: %w", err)
+	}
+
+	if r.tlsConfig != nil {
+		conn = tls.Client(conn, r.tlsConfig)
+	}
+
+	return r.client.ExchangeWithConnContext(ctx, msg, &dns.Conn{Conn: conn})
+}
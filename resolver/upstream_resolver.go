@@ -9,23 +9,31 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"slices"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/avast/retry-go/v4"
 
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/util"
+	"github.com/0xERR0R/blocky/watchdog"
 
 	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	dnsContentType = "application/dns-message"
-	retryAttempts  = 3
+
+	// how long HTTP/3 upstreams use HTTP/2 after an HTTP/3 failure
+	http3FallbackCooldown = 5 * time.Minute
 )
 
 // UpstreamServerError wraps a response with RCode ServFail so no other resolver tries to use it.
@@ -40,10 +48,18 @@ func (e *UpstreamServerError) Error() string {
 type upstreamConfig struct {
 	config.Upstreams
 	config.Upstream
+
+	// bind of the connections, as configured for the group of the upstream
+	bind config.UpstreamBind
 }
 
 func newUpstreamConfig(upstream config.Upstream, cfg config.Upstreams) upstreamConfig {
-	return upstreamConfig{cfg, upstream}
+	return upstreamConfig{Upstreams: cfg, Upstream: upstream}
+}
+
+// sendsProxyProtocol returns true if the connections to the upstream start with a PROXY protocol header
+func (c upstreamConfig) sendsProxyProtocol() bool {
+	return slices.Contains(c.ProxyProtocolUpstreams, c.Upstream)
 }
 
 func (c upstreamConfig) String() string {
@@ -77,16 +93,21 @@ type upstreamClient interface {
 }
 
 type dnsUpstreamClient struct {
-	tcpClient, udpClient *dns.Client
+	tcpClient *dns.Client
+	udpClient dnsExchanger
 }
 
 type httpUpstreamClient struct {
 	client    *http.Client
 	host      string
 	userAgent string
+
+	// HTTP/3 upstreams only: client is used as fallback while HTTP/3 is broken
+	h3Client      *http.Client
+	h3BrokenUntil atomic.Int64
 }
 
-func createUpstreamClient(cfg upstreamConfig) upstreamClient {
+func createUpstreamClient(cfg upstreamConfig) (upstreamClient, error) {
 	tlsConfig := tls.Config{
 		ServerName: cfg.Host,
 		MinVersion: tls.VersionTLS12,
@@ -96,12 +117,29 @@ func createUpstreamClient(cfg upstreamConfig) upstreamClient {
 		tlsConfig.ServerName = cfg.CommonName
 	}
 
+	if err := cfg.TLS.ApplyClient(&tlsConfig); err != nil {
+		return nil, fmt.Errorf("can't apply TLS policy for upstream %s: %w", cfg.Upstream, err)
+	}
+
+	upstreamTLS := cfg.TLSOf(cfg.Upstream)
+	if err := upstreamTLS.ApplyClient(&tlsConfig); err != nil {
+		return nil, fmt.Errorf("can't apply TLS settings of upstream %s: %w", cfg.Upstream, err)
+	}
+
+	if cfg.sendsProxyProtocol() {
+		return newProxyProtocolUpstreamClient(cfg, &tlsConfig), nil
+	}
+
 	switch cfg.Net {
 	case config.NetProtocolHttps:
 		transport := util.DefaultHTTPTransport()
 		transport.TLSClientConfig = &tlsConfig
 
-		return &httpUpstreamClient{
+		if cfg.bind.IsEnabled() {
+			transport.DialContext = newUpstreamDialer(cfg.bind, "tcp").DialContext
+		}
+
+		client := &httpUpstreamClient{
 			userAgent: cfg.UserAgent,
 			client: &http.Client{
 				Transport: transport,
@@ -109,24 +147,68 @@ func createUpstreamClient(cfg upstreamConfig) upstreamClient {
 			host: cfg.Host,
 		}
 
+		// HTTP/3 uses its own UDP sockets, which are not bound
+		if cfg.HTTP3 && !cfg.bind.IsEnabled() {
+			client.h3Client = &http.Client{
+				Transport: &http3.RoundTripper{
+					TLSClientConfig: tlsConfig.Clone(),
+					QUICConfig: &quic.Config{
+						// leave time to fall back to HTTP/2 if UDP is blocked
+						HandshakeIdleTimeout: cfg.Timeout.ToDuration() / 2, //nolint:mnd
+					},
+				},
+			}
+		}
+
+		return client, nil
+
 	case config.NetProtocolTcpTls:
 		return &dnsUpstreamClient{
 			tcpClient: &dns.Client{
 				TLSConfig: &tlsConfig,
 				Net:       cfg.Net.String(),
+				Dialer:    newUpstreamDialer(cfg.bind, "tcp"),
 			},
-		}
+		}, nil
 
 	case config.NetProtocolTcpUdp:
-		return &dnsUpstreamClient{
+		udpClient := &dns.Client{
+			Net:    "udp",
+			Dialer: newUpstreamDialer(cfg.bind, "udp"),
+		}
+
+		client := &dnsUpstreamClient{
 			tcpClient: &dns.Client{
-				Net: "tcp",
-			},
-			udpClient: &dns.Client{
-				Net: "udp",
+				Net:    "tcp",
+				Dialer: newUpstreamDialer(cfg.bind, "tcp"),
 			},
+			udpClient: udpClient,
+		}
+
+		if cfg.UDPPool.IsEnabled() {
+			client.udpClient = newUDPConnPool(udpClient, cfg.UDPPool)
+		}
+
+		// encrypted upstreams can't be spoofed
+		var res upstreamClient = client
+
+		if cfg.Cookies {
+			res = newCookieClient(cfg.String(), res)
+		}
+
+		if cfg.RandomizeCase {
+			res = newCaseRandomizingClient(cfg.String(), res, client.tcpClient)
 		}
 
+		return res, nil
+
+	case config.NetProtocolUnix:
+		return &unixUpstreamClient{
+			client: &dns.Client{
+				Net: "unix",
+			},
+		}, nil
+
 	default:
 		log.Log().Fatalf("invalid protocol %s", cfg.Net)
 		panic("unreachable")
@@ -147,18 +229,9 @@ func (r *httpUpstreamClient) callExternal(
 		return nil, 0, fmt.Errorf("can't pack message: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upstreamURL, bytes.NewReader(rawDNSMessage))
+	httpResponse, err := r.post(ctx, rawDNSMessage, upstreamURL)
 	if err != nil {
-		return nil, 0, fmt.Errorf("can't create the new request %w", err)
-	}
-
-	req.Header.Set("User-Agent", r.userAgent)
-	req.Header.Set("Content-Type", dnsContentType)
-	req.Host = r.host
-
-	httpResponse, err := r.client.Do(req)
-	if err != nil {
-		return nil, 0, fmt.Errorf("can't perform https request: %w", err)
+		return nil, 0, err
 	}
 
 	defer func() {
@@ -190,6 +263,42 @@ func (r *httpUpstreamClient) callExternal(
 	return &response, time.Since(start), nil
 }
 
+// post sends the message via HTTP/3 if enabled and working, otherwise via HTTP/1.1 or HTTP/2
+func (r *httpUpstreamClient) post(ctx context.Context, rawDNSMessage []byte, upstreamURL string) (*http.Response, error) {
+	if r.h3Client != nil && time.Now().UnixNano() >= r.h3BrokenUntil.Load() {
+		resp, err := r.postWith(ctx, r.h3Client, rawDNSMessage, upstreamURL)
+		if err == nil || errors.Is(err, context.Canceled) {
+			return resp, err
+		}
+
+		log.FromCtx(ctx).Debugf("HTTP/3 request failed, falling back to HTTP/2 for %s: %s", http3FallbackCooldown, err)
+
+		r.h3BrokenUntil.Store(time.Now().Add(http3FallbackCooldown).UnixNano())
+	}
+
+	return r.postWith(ctx, r.client, rawDNSMessage, upstreamURL)
+}
+
+func (r *httpUpstreamClient) postWith(
+	ctx context.Context, client *http.Client, rawDNSMessage []byte, upstreamURL string,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upstreamURL, bytes.NewReader(rawDNSMessage))
+	if err != nil {
+		return nil, fmt.Errorf("can't create the new request %w", err)
+	}
+
+	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("Content-Type", dnsContentType)
+	req.Host = r.host
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("can't perform https request: %w", err)
+	}
+
+	return resp, nil
+}
+
 func (r *dnsUpstreamClient) fmtURL(ip net.IP, port uint16, _ string) string {
 	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
 }
@@ -204,6 +313,21 @@ func (r *dnsUpstreamClient) callExternal(
 	return r.raceClients(ctx, msg, upstreamURL, protocol)
 }
 
+// unixUpstreamClient queries an upstream listening on a Unix domain socket
+type unixUpstreamClient struct {
+	client *dns.Client
+}
+
+func (r *unixUpstreamClient) fmtURL(_ net.IP, _ uint16, socketPath string) string {
+	return socketPath
+}
+
+func (r *unixUpstreamClient) callExternal(
+	ctx context.Context, msg *dns.Msg, socketPath string, _ model.RequestProtocol,
+) (response *dns.Msg, rtt time.Duration, err error) {
+	return r.client.ExchangeContext(ctx, msg, socketPath)
+}
+
 type exchangeResult struct {
 	proto model.RequestProtocol
 	msg   *dns.Msg
@@ -221,7 +345,9 @@ func (r *dnsUpstreamClient) raceClients(
 	// it will be GC'ed and closed automatically.
 	ch := make(chan exchangeResult, 2) //nolint:mnd // TCP and UDP
 
-	exchange := func(client *dns.Client, proto model.RequestProtocol) {
+	exchange := func(client dnsExchanger, proto model.RequestProtocol) {
+		defer watchdog.TrackWorker("upstream_queries")()
+
 		msg, rtt, err := client.ExchangeContext(ctx, msg, upstreamURL)
 
 		if err == nil && msg.Rcode == dns.RcodeServerFailure {
@@ -272,15 +398,12 @@ func (r *dnsUpstreamClient) raceClients(
 func NewUpstreamResolver(
 	ctx context.Context, cfg upstreamConfig, bootstrap *Bootstrap,
 ) (*UpstreamResolver, error) {
-	r := newUpstreamResolverUnchecked(cfg, bootstrap)
-
-	onErr := func(err error) {
-		_, logger := r.log(ctx)
-
-		logger.WithError(err).Warn("initial resolver test failed")
+	r, err := newUpstreamResolverUnchecked(cfg, bootstrap)
+	if err != nil {
+		return nil, err
 	}
 
-	err := cfg.Init.Strategy.Do(ctx, r.testResolve, onErr)
+	err = r.init(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -288,9 +411,23 @@ func NewUpstreamResolver(
 	return r, nil
 }
 
+// init tests the upstream as configured by the init strategy
+func (r *UpstreamResolver) init(ctx context.Context) error {
+	onErr := func(err error) {
+		_, logger := r.log(ctx)
+
+		logger.WithError(err).Warn("initial resolver test failed")
+	}
+
+	return r.cfg.Init.Do(ctx, r.testResolve, onErr)
+}
+
 // newUpstreamResolverUnchecked creates new resolver instance without validating the upstream
-func newUpstreamResolverUnchecked(cfg upstreamConfig, bootstrap *Bootstrap) *UpstreamResolver {
-	upstreamClient := createUpstreamClient(cfg)
+func newUpstreamResolverUnchecked(cfg upstreamConfig, bootstrap *Bootstrap) (*UpstreamResolver, error) {
+	upstreamClient, err := createUpstreamClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	return &UpstreamResolver{
 		typed:        withType("upstream"),
@@ -298,7 +435,7 @@ func newUpstreamResolverUnchecked(cfg upstreamConfig, bootstrap *Bootstrap) *Ups
 
 		upstreamClient: upstreamClient,
 		bootstrap:      bootstrap,
-	}
+	}, nil
 }
 
 func (r UpstreamResolver) String() string {
@@ -339,6 +476,22 @@ func (r *UpstreamResolver) Resolve(ctx context.Context, request *model.Request)
 		ip   net.IP
 	)
 
+	msg := request.Req
+	if !r.isECSAllowed() && util.GetEdns0Option[*dns.EDNS0_SUBNET](msg) != nil {
+		// the request is shared with other upstreams: remove the client subnet from a copy
+		msg = msg.Copy()
+		util.RemoveEdns0Option[*dns.EDNS0_SUBNET](msg)
+	}
+
+	if r.cfg.sendsProxyProtocol() {
+		ctx = withProxyProtocolClient(ctx, request.ClientIP)
+	}
+
+	// 0 would retry forever
+	attempts := max(r.cfg.Retry.Attempts, 1)
+
+	start := time.Now()
+
 	err = retry.Do(
 		func() error {
 			ip = ips.Current()
@@ -347,7 +500,7 @@ func (r *UpstreamResolver) Resolve(ctx context.Context, request *model.Request)
 			ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout.ToDuration())
 			defer cancel()
 
-			response, rtt, err := r.upstreamClient.callExternal(ctx, request.Req, upstreamURL, request.Protocol)
+			response, rtt, err := r.upstreamClient.callExternal(ctx, msg, upstreamURL, request.Protocol)
 			if err != nil {
 				return fmt.Errorf("can't resolve request via upstream server %s (%s): %w", r.cfg, upstreamURL, err)
 			}
@@ -358,9 +511,9 @@ func (r *UpstreamResolver) Resolve(ctx context.Context, request *model.Request)
 			return nil
 		},
 		retry.Context(ctx),
-		retry.Attempts(retryAttempts),
-		retry.DelayType(retry.FixedDelay),
-		retry.Delay(1*time.Millisecond),
+		retry.Attempts(attempts),
+		retry.DelayType(retry.BackOffDelay),
+		retry.Delay(r.cfg.Retry.Backoff.ToDuration()),
 		retry.LastErrorOnly(true),
 		retry.RetryIf(isTimeout),
 		retry.OnRetry(func(n uint, err error) {
@@ -368,18 +521,31 @@ func (r *UpstreamResolver) Resolve(ctx context.Context, request *model.Request)
 				"upstream":    r.cfg.String(),
 				"upstream_ip": ip.String(),
 				"question":    util.QuestionToString(request.Req.Question),
-				"attempt":     fmt.Sprintf("%d/%d", n+1, retryAttempts),
+				"attempt":     fmt.Sprintf("%d/%d", n+1, attempts),
 			}).Debugf("%s, retrying...", err)
 
 			ips.Next()
 		}))
+
+	evt.Bus().Publish(evt.UpstreamQueried, r.cfg.String(), time.Since(start), err != nil)
+
 	if err != nil {
+		r.trace(ctx, "%s failed: %s", r.cfg, err)
+
 		return nil, err
 	}
 
+	r.trace(ctx, "%s (%s) answered %s in %s",
+		r.cfg, ip, dns.RcodeToString[resp.Rcode], time.Since(start).Round(time.Millisecond))
+
 	return &model.Response{Res: resp, Reason: fmt.Sprintf("RESOLVED (%s)", r.cfg)}, nil
 }
 
+// isECSAllowed returns true if the EDNS Client Subnet option may be sent to this upstream
+func (r *UpstreamResolver) isECSAllowed() bool {
+	return len(r.cfg.ECSUpstreams) == 0 || slices.Contains(r.cfg.ECSUpstreams, r.cfg.Upstream)
+}
+
 func (r *UpstreamResolver) logResponse(
 	logger *logrus.Entry, request *model.Request, resp *dns.Msg, ip net.IP, rtt time.Duration,
 ) {
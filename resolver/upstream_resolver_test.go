@@ -2,21 +2,28 @@ package resolver
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
+	"path/filepath"
 	"sync/atomic"
 	"time"
 
 	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/evt"
 	. "github.com/0xERR0R/blocky/helpertest"
 	"github.com/0xERR0R/blocky/log"
 	. "github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/proxyprotocol"
 	"github.com/0xERR0R/blocky/util"
 	"github.com/miekg/dns"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/quic-go/quic-go/http3"
 )
 
 var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
@@ -36,7 +43,9 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 	})
 
 	JustBeforeEach(func() {
-		sut = newUpstreamResolverUnchecked(sutConfig, systemResolverBootstrap)
+		var err error
+		sut, err = newUpstreamResolverUnchecked(sutConfig, systemResolverBootstrap)
+		Expect(err).Should(Succeed())
 	})
 
 	Describe("Type", func() {
@@ -61,13 +70,32 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 		})
 	})
 
+	Describe("NewUpstreamResolver", func() {
+		It("should fail if the client certificate can't be loaded", func() {
+			sutConfig.TLS = config.TLSPolicy{CertFile: "/does/not/exist.pem", KeyFile: "/does/not/exist.pem"}
+
+			_, err := NewUpstreamResolver(ctx, sutConfig, nil)
+			Expect(err).Should(MatchError(ContainSubstring("can't load client certificate")))
+		})
+
+		It("should fail if the CA file of the upstream can't be loaded", func() {
+			sutConfig.UpstreamTLS = map[config.Upstream]config.UpstreamTLS{
+				sutConfig.Upstream: {CAFile: "/does/not/exist.pem"},
+			}
+
+			_, err := NewUpstreamResolver(ctx, sutConfig, nil)
+			Expect(err).Should(MatchError(ContainSubstring("can't apply TLS settings of upstream")))
+		})
+	})
+
 	Describe("Using DNS upstream", func() {
 		When("Configured DNS resolver can resolve query", func() {
 			It("should return answer from DNS upstream", func() {
 				mockUpstream := NewMockUDPUpstreamServer().WithAnswerRR("example.com 123 IN A 123.124.122.122")
 
 				sutConfig.Upstream = mockUpstream.Start()
-				sut := newUpstreamResolverUnchecked(sutConfig, nil)
+				sut, err := newUpstreamResolverUnchecked(sutConfig, nil)
+				Expect(err).Should(Succeed())
 
 				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
 					Should(
@@ -79,13 +107,93 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 							HaveReason(fmt.Sprintf("RESOLVED (%s)", sutConfig.Upstream))),
 					)
 			})
+
+			It("should fire an event with the request duration", func() {
+				mockUpstream := NewMockUDPUpstreamServer().WithAnswerRR("example.com 123 IN A 123.124.122.122")
+
+				sutConfig.Upstream = mockUpstream.Start()
+				sut, err := newUpstreamResolverUnchecked(sutConfig, nil)
+				Expect(err).Should(Succeed())
+
+				var (
+					queried atomic.Bool
+					failed  atomic.Bool
+				)
+
+				handler := func(upstream string, _ time.Duration, isFailed bool) {
+					if upstream == sutConfig.Upstream.String() {
+						queried.Store(true)
+						failed.Store(isFailed)
+					}
+				}
+				Expect(Bus().Subscribe(UpstreamQueried, handler)).Should(Succeed())
+				DeferCleanup(func() { _ = Bus().Unsubscribe(UpstreamQueried, handler) })
+
+				_, err = sut.Resolve(ctx, newRequest("example.com.", A))
+				Expect(err).Should(Succeed())
+
+				Expect(queried.Load()).Should(BeTrue())
+				Expect(failed.Load()).Should(BeFalse())
+			})
+		})
+		When("ECS is restricted to other upstreams", func() {
+			var (
+				received atomic.Pointer[dns.Msg]
+				subnet   *dns.EDNS0_SUBNET
+			)
+
+			BeforeEach(func() {
+				received.Store(nil)
+				subnet = newEdnsSubnetOption(net.ParseIP("192.0.2.1"), ecsFamilyIPv4, config.ECSv4Mask(24))
+
+				mockUpstream := NewMockUDPUpstreamServer().WithAnswerFn(func(request *dns.Msg) *dns.Msg {
+					received.Store(request.Copy())
+
+					response, err := util.NewMsgWithAnswer("example.com.", 123, A, "123.124.122.122")
+					Expect(err).Should(Succeed())
+
+					return response
+				})
+
+				sutConfig.Upstream = mockUpstream.Start()
+				sutConfig.ECSUpstreams = []config.Upstream{{Net: config.NetProtocolTcpUdp, Host: "192.0.2.1", Port: 53}}
+			})
+
+			It("should remove the ECS option from the forwarded request only", func() {
+				sut, err := newUpstreamResolverUnchecked(sutConfig, nil)
+				Expect(err).Should(Succeed())
+
+				request := newRequest("example.com.", A)
+				util.SetEdns0Option(request.Req, subnet)
+
+				Expect(sut.Resolve(ctx, request)).Should(BeDNSRecord("example.com.", A, "123.124.122.122"))
+
+				Expect(received.Load()).ShouldNot(BeNil())
+				Expect(util.GetEdns0Option[*dns.EDNS0_SUBNET](received.Load())).Should(BeNil())
+				Expect(util.GetEdns0Option[*dns.EDNS0_SUBNET](request.Req)).ShouldNot(BeNil())
+			})
+
+			It("should forward the ECS option if the upstream is selected", func() {
+				sutConfig.ECSUpstreams = append(sutConfig.ECSUpstreams, sutConfig.Upstream)
+				sut, err := newUpstreamResolverUnchecked(sutConfig, nil)
+				Expect(err).Should(Succeed())
+
+				request := newRequest("example.com.", A)
+				util.SetEdns0Option(request.Req, subnet)
+
+				Expect(sut.Resolve(ctx, request)).Should(BeDNSRecord("example.com.", A, "123.124.122.122"))
+
+				Expect(received.Load()).ShouldNot(BeNil())
+				Expect(util.GetEdns0Option[*dns.EDNS0_SUBNET](received.Load())).ShouldNot(BeNil())
+			})
 		})
 		When("Configured DNS resolver can't resolve query", func() {
 			It("should return response code from DNS upstream", func() {
 				mockUpstream := NewMockUDPUpstreamServer().WithAnswerError(dns.RcodeNameError)
 
 				sutConfig.Upstream = mockUpstream.Start()
-				sut := newUpstreamResolverUnchecked(sutConfig, nil)
+				sut, err := newUpstreamResolverUnchecked(sutConfig, nil)
+				Expect(err).Should(Succeed())
 
 				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
 					Should(
@@ -104,9 +212,10 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 				})
 
 				sutConfig.Upstream = mockUpstream.Start()
-				sut := newUpstreamResolverUnchecked(sutConfig, nil)
+				sut, err := newUpstreamResolverUnchecked(sutConfig, nil)
+				Expect(err).Should(Succeed())
 
-				_, err := sut.Resolve(ctx, newRequest("example.com.", A))
+				_, err = sut.Resolve(ctx, newRequest("example.com.", A))
 				Expect(err).Should(HaveOccurred())
 			})
 		})
@@ -115,9 +224,10 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 				mockUpstream := NewMockUDPUpstreamServer().WithAnswerError(dns.RcodeServerFailure)
 
 				sutConfig.Upstream = mockUpstream.Start()
-				sut := newUpstreamResolverUnchecked(sutConfig, nil)
+				sut, err := newUpstreamResolverUnchecked(sutConfig, nil)
+				Expect(err).Should(Succeed())
 
-				_, err := sut.Resolve(ctx, newRequest("example.com.", A))
+				_, err = sut.Resolve(ctx, newRequest("example.com.", A))
 				Expect(err).Should(HaveOccurred())
 
 				var servErr *UpstreamServerError
@@ -170,6 +280,38 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 			})
 		})
 
+		When("the retries are configured", func() {
+			var counter atomic.Int32
+
+			BeforeEach(func() {
+				counter.Store(0)
+
+				mockUpstream := NewMockUDPUpstreamServer().WithAnswerFn(func(*dns.Msg) *dns.Msg {
+					counter.Add(1)
+					time.Sleep(2 * timeout)
+
+					response, err := util.NewMsgWithAnswer("example.com", 123, A, "123.124.122.122")
+					Expect(err).Should(Succeed())
+
+					return response
+				})
+
+				sutConfig.Upstream = mockUpstream.Start()
+				sutConfig.Retry = config.UpstreamRetry{Attempts: 2, Backoff: config.Duration(100 * time.Millisecond)}
+			})
+
+			It("should use the number of attempts and the backoff", func() {
+				start := time.Now()
+
+				_, err := sut.Resolve(ctx, newRequest("example.com.", A))
+				Expect(err).Should(MatchError(ContainSubstring("i/o timeout")))
+
+				Eventually(counter.Load).Should(BeNumerically("==", 2))
+				Consistently(counter.Load, 3*timeout).Should(BeNumerically("==", 2))
+				Expect(time.Since(start)).Should(BeNumerically(">=", 2*timeout+100*time.Millisecond))
+			})
+		})
+
 		When("user request is TCP", func() {
 			When("TCP upstream connection fails", func() {
 				BeforeEach(func() {
@@ -195,6 +337,98 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 		})
 	})
 
+	Describe("Using Unix socket upstream", func() {
+		BeforeEach(func() {
+			socketPath := filepath.Join(GinkgoT().TempDir(), "dns.sock")
+
+			listener, err := net.Listen("unix", socketPath)
+			Expect(err).Should(Succeed())
+
+			server := &dns.Server{
+				Listener: listener,
+				Handler: dns.HandlerFunc(func(w dns.ResponseWriter, request *dns.Msg) {
+					response, err := util.NewMsgWithAnswer(util.ExtractDomain(request.Question[0]), 123, A, "123.124.122.122")
+					Expect(err).Should(Succeed())
+
+					response.SetReply(request)
+					Expect(w.WriteMsg(response)).Should(Succeed())
+				}),
+			}
+
+			go func() {
+				defer GinkgoRecover()
+
+				Expect(server.ActivateAndServe()).Should(Succeed())
+			}()
+			DeferCleanup(server.Shutdown)
+
+			upstream, err := config.ParseUpstream("unix:" + socketPath)
+			Expect(err).Should(Succeed())
+
+			sutConfig.Upstream = upstream
+		})
+
+		It("should resolve via the socket", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(
+					SatisfyAll(
+						BeDNSRecord("example.com.", A, "123.124.122.122"),
+						HaveResponseType(ResponseTypeRESOLVED),
+						HaveReason(fmt.Sprintf("RESOLVED (unix:%s)", sutConfig.Path)),
+					))
+		})
+	})
+
+	Describe("Using an upstream with PROXY protocol", func() {
+		BeforeEach(func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).Should(Succeed())
+
+			trusted := []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+
+			server := &dns.Server{
+				Listener: proxyprotocol.NewListener(listener, trusted, time.Second),
+				Net:      "tcp",
+				Handler: dns.HandlerFunc(func(w dns.ResponseWriter, request *dns.Msg) {
+					// answers with the client IP the server sees
+					ip := w.RemoteAddr().(*net.TCPAddr).IP
+
+					response, err := util.NewMsgWithAnswer(util.ExtractDomain(request.Question[0]), 123, A, ip.String())
+					Expect(err).Should(Succeed())
+
+					response.SetReply(request)
+					Expect(w.WriteMsg(response)).Should(Succeed())
+				}),
+			}
+
+			go func() {
+				defer GinkgoRecover()
+
+				Expect(server.ActivateAndServe()).Should(Succeed())
+			}()
+			DeferCleanup(server.Shutdown)
+
+			upstream, err := config.ParseUpstream("tcp+udp:" + listener.Addr().String())
+			Expect(err).Should(Succeed())
+
+			sutConfig.Upstream = upstream
+			sutConfig.ProxyProtocolUpstreams = []config.Upstream{upstream}
+		})
+
+		It("should send the client IP via TCP", func() {
+			request := newRequestWithClient("example.com.", A, "192.168.178.99")
+			request.Protocol = RequestProtocolUDP
+
+			Expect(sut.Resolve(ctx, request)).
+				Should(BeDNSRecord("example.com.", A, "192.168.178.99"))
+		})
+
+		It("should send a LOCAL header without client", func() {
+			Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+				Should(BeDNSRecord("example.com.", A, "127.0.0.1"))
+		})
+	})
+
 	Describe("Using DNS over HTTPS (DoH) upstream", func() {
 		var (
 			respFn           func(request *dns.Msg) (response *dns.Msg)
@@ -219,7 +453,10 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 
 		JustBeforeEach(func() {
 			sutConfig.Upstream = newTestDOHUpstream(respFn, modifyHTTPRespFn)
-			sut = newUpstreamResolverUnchecked(sutConfig, nil)
+
+			var err error
+			sut, err = newUpstreamResolverUnchecked(sutConfig, nil)
+			Expect(err).Should(Succeed())
 
 			// use insecure certificates for test DoH upstream
 			transport().TLSClientConfig.InsecureSkipVerify = true
@@ -251,6 +488,72 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 						))
 			})
 		})
+		When("the certificate of the DoH resolver is pinned", func() {
+			JustBeforeEach(func() {
+				conn, err := tls.Dial("tcp", net.JoinHostPort(sutConfig.Host, fmt.Sprint(sutConfig.Port)),
+					&tls.Config{InsecureSkipVerify: true}) //nolint:gosec // test server
+				Expect(err).Should(Succeed())
+				DeferCleanup(conn.Close)
+
+				pin := sha256.Sum256(conn.ConnectionState().PeerCertificates[0].RawSubjectPublicKeyInfo)
+
+				sutConfig.UpstreamTLS = map[config.Upstream]config.UpstreamTLS{
+					sutConfig.Upstream: {InsecureSkipVerify: true, SPKIPins: []config.SPKIPin{pin}},
+				}
+
+				sut, err = newUpstreamResolverUnchecked(sutConfig, nil)
+				Expect(err).Should(Succeed())
+			})
+
+			It("should resolve via the upstream with the pinned key", func() {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(BeDNSRecord("example.com.", A, "123.124.122.122"))
+			})
+
+			When("the certificate doesn't match the pins", func() {
+				JustBeforeEach(func() {
+					sutConfig.UpstreamTLS = map[config.Upstream]config.UpstreamTLS{
+						sutConfig.Upstream: {InsecureSkipVerify: true, SPKIPins: []config.SPKIPin{{1}}},
+					}
+
+					var err error
+					sut, err = newUpstreamResolverUnchecked(sutConfig, nil)
+					Expect(err).Should(Succeed())
+				})
+
+				It("should fail", func() {
+					_, err := sut.Resolve(ctx, newRequest("example.com.", A))
+					Expect(err).Should(MatchError(ContainSubstring("SPKI pins")))
+				})
+			})
+		})
+		When("HTTP/3 is enabled but the DoH resolver only supports HTTP/2", func() {
+			JustBeforeEach(func() {
+				sutConfig.Upstream.HTTP3 = true
+
+				var err error
+				sut, err = newUpstreamResolverUnchecked(sutConfig, nil)
+				Expect(err).Should(Succeed())
+
+				transport().TLSClientConfig.InsecureSkipVerify = true
+
+				h3Transport := sut.upstreamClient.(*httpUpstreamClient).h3Client.Transport.(*http3.RoundTripper)
+				h3Transport.TLSClientConfig.InsecureSkipVerify = true
+			})
+			It("should fall back to HTTP/2", func() {
+				Expect(sut.Resolve(ctx, newRequest("example.com.", A))).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("example.com.", A, "123.124.122.122"),
+							HaveResponseType(ResponseTypeRESOLVED),
+						))
+
+				By("not trying HTTP/3 again for a while", func() {
+					brokenUntil := sut.upstreamClient.(*httpUpstreamClient).h3BrokenUntil.Load()
+					Expect(time.Unix(0, brokenUntil)).Should(BeTemporally(">", time.Now()))
+				})
+			})
+		})
 		When("Configured DoH resolver returns wrong http status code", func() {
 			BeforeEach(func() {
 				modifyHTTPRespFn = func(w http.ResponseWriter) {
@@ -295,7 +598,9 @@ var _ = Describe("UpstreamResolver", Label("upstreamResolver"), func() {
 					Host: "wronghost.example.com",
 				}
 
-				sut = newUpstreamResolverUnchecked(sutConfig, systemResolverBootstrap)
+				var err error
+				sut, err = newUpstreamResolverUnchecked(sutConfig, systemResolverBootstrap)
+				Expect(err).Should(Succeed())
 			})
 			It("should return error", func() {
 				_, err := sut.Resolve(ctx, newRequest("example.com.", A))
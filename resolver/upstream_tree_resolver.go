@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/0xERR0R/blocky/api"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/model"
 	"github.com/0xERR0R/blocky/util"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 )
 
 const (
@@ -58,22 +61,9 @@ func createUpstreamBranches(
 	errs := make([]error, 0, len(cfg.Groups))
 
 	for group, upstreams := range cfg.Groups {
-		var (
-			upstream Resolver
-			err      error
-		)
-
 		groupConfig := config.NewUpstreamGroup(group, cfg, upstreams)
 
-		switch cfg.Strategy {
-		case config.UpstreamStrategyParallelBest:
-			fallthrough
-		case config.UpstreamStrategyRandom:
-			upstream, err = NewParallelBestResolver(ctx, groupConfig, bootstrap)
-		case config.UpstreamStrategyStrict:
-			upstream, err = NewStrictResolver(ctx, groupConfig, bootstrap)
-		}
-
+		upstream, err := newUpstreamGroupResolver(ctx, groupConfig, bootstrap)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("group %s: %w", group, err))
 
@@ -90,6 +80,34 @@ func createUpstreamBranches(
 	return branches, nil
 }
 
+// newUpstreamGroupResolver returns the resolver of the strategy of the group
+func newUpstreamGroupResolver(ctx context.Context, cfg config.UpstreamGroup, bootstrap *Bootstrap) (Resolver, error) {
+	switch cfg.Strategy {
+	case config.UpstreamStrategyStrict:
+		return NewStrictResolver(ctx, cfg, bootstrap)
+	case config.UpstreamStrategyFastest:
+		return NewFastestResolver(ctx, cfg, bootstrap)
+	default:
+		return NewParallelBestResolver(ctx, cfg, bootstrap)
+	}
+}
+
+// UpstreamStatus implements `api.UpstreamStatusProvider`.
+func (r *UpstreamTreeResolver) UpstreamStatus() []api.UpstreamStatus {
+	groups := maps.Keys(r.branches)
+	slices.Sort(groups)
+
+	var res []api.UpstreamStatus
+
+	for _, group := range groups {
+		if provider, ok := r.branches[group].(api.UpstreamStatusProvider); ok {
+			res = append(res, provider.UpstreamStatus()...)
+		}
+	}
+
+	return res
+}
+
 func (r *UpstreamTreeResolver) Name() string {
 	return r.String()
 }
@@ -112,6 +130,9 @@ func (r *UpstreamTreeResolver) Resolve(ctx context.Context, request *model.Reque
 	// delegate request to group resolver
 	logger.WithField("resolver", fmt.Sprintf("%s (%s)", group, r.branches[group].Type())).Debug("delegating to resolver")
 
+	r.trace(ctx, "upstream group %s (%s)", group, r.branches[group].Type())
+	traced(ctx, func(trace *model.Trace) { trace.UpstreamGroup = group })
+
 	return r.branches[group].Resolve(ctx, request)
 }
 
@@ -133,7 +154,14 @@ func (r *UpstreamTreeResolver) upstreamGroupByClient(logger *logrus.Entry, reque
 		}
 	}
 
-	// try CIDR (only if no client name matched)
+	// try MAC address or OUI
+	for group := range r.branches {
+		if util.ClientMACMatchesGroupName(group, request.ClientMAC) {
+			groups = append(groups, group)
+		}
+	}
+
+	// try CIDR (only if no client name or MAC address matched)
 	if len(groups) == 0 {
 		for cidr := range r.branches {
 			if util.CidrContainsIP(cidr, request.ClientIP) {
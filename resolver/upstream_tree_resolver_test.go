@@ -3,6 +3,7 @@ package resolver
 import (
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/0xERR0R/blocky/config"
 	. "github.com/0xERR0R/blocky/helpertest"
@@ -82,6 +83,43 @@ var _ = Describe("UpstreamTreeResolver", Label("upstreamTreeResolver"), func() {
 				Expect(ok).Should(BeTrue())
 			})
 		})
+
+		When("strategy is weighted", func() {
+			BeforeEach(func() {
+				sutConfig.Strategy = config.UpstreamStrategyWeighted
+			})
+
+			It("returns the resolver directly", func() {
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(sut).Should(BeAssignableToTypeOf(&ParallelBestResolver{}))
+				Expect(sut.Type()).Should(Equal(weightedResolverType))
+			})
+		})
+
+		When("strategy is fastest", func() {
+			BeforeEach(func() {
+				sutConfig.Strategy = config.UpstreamStrategyFastest
+			})
+
+			It("returns the resolver directly", func() {
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(sut).Should(BeAssignableToTypeOf(&FastestResolver{}))
+			})
+		})
+
+		When("the client certificate of the TLS policy can't be loaded", func() {
+			BeforeEach(func() {
+				sutConfig.Init.Strategy = config.InitStrategyFailOnError
+				sutConfig.TLS = config.TLSPolicy{CertFile: "/does/not/exist.pem", KeyFile: "/does/not/exist.pem"}
+			})
+
+			It("should return the error", func() {
+				Expect(err).To(MatchError(ContainSubstring("can't load client certificate")))
+				Expect(sut).To(BeNil())
+			})
+		})
 	})
 
 	When("it has multiple groups", func() {
@@ -154,6 +192,7 @@ var _ = Describe("UpstreamTreeResolver", Label("upstreamTreeResolver"), func() {
 				"10.43.8.67/28":        "127.0.0.6",
 				"name-matches1":        "127.0.0.7",
 				"name-matches*":        "127.0.0.8",
+				"aa:bb:cc":             "127.0.0.9",
 			}
 
 			BeforeEach(func() {
@@ -244,6 +283,18 @@ var _ = Describe("UpstreamTreeResolver", Label("upstreamTreeResolver"), func() {
 							HaveReturnCode(dns.RcodeSuccess),
 						))
 			})
+			It("Should use client specific resolver if client's MAC address OUI matches before CIDR match", func() {
+				request := newRequestWithClient("example.com.", A, "10.43.8.70", "noname")
+				request.ClientMAC = net.HardwareAddr{0xaa, 0xbb, 0xcc, 0x01, 0x02, 0x03}
+
+				Expect(sut.Resolve(ctx, request)).
+					Should(
+						SatisfyAll(
+							BeDNSRecord("example.com.", A, groups["aa:bb:cc"]),
+							HaveResponseType(ResponseTypeRESOLVED),
+							HaveReturnCode(dns.RcodeSuccess),
+						))
+			})
 			It("Should use exact IP match before client name match", func() {
 				request := newRequestWithClient("example.com.", A, "192.168.178.33", "laptop")
 
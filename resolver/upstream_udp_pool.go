@@ -0,0 +1,171 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	"github.com/miekg/dns"
+)
+
+// sockets idle for longer are closed, so the source ports change even with little traffic
+const udpPoolIdleTimeout = time.Minute
+
+// dnsExchanger sends a query to an upstream and returns its response
+type dnsExchanger interface {
+	ExchangeContext(ctx context.Context, msg *dns.Msg, address string) (*dns.Msg, time.Duration, error)
+}
+
+// udpConnPool reuses connected UDP sockets to the upstreams instead of opening a new one per query.
+//
+// A socket is used by one query at a time and gets its source port randomly from the OS. It is replaced after
+// a number of queries or when idle, so the ports keep changing. Responses are only accepted if they match the ID
+// and the question of the query: anything else, like a late response to an earlier query, is skipped.
+type udpConnPool struct {
+	client *dns.Client
+	cfg    config.UpstreamUDPPool
+
+	lock sync.Mutex
+	idle map[string][]*pooledUDPConn
+}
+
+type pooledUDPConn struct {
+	*dns.Conn
+
+	queries  uint
+	lastUsed time.Time
+}
+
+func newUDPConnPool(client *dns.Client, cfg config.UpstreamUDPPool) *udpConnPool {
+	return &udpConnPool{
+		client: client,
+		cfg:    cfg,
+		idle:   make(map[string][]*pooledUDPConn),
+	}
+}
+
+// ExchangeContext implements `dnsExchanger`
+func (p *udpConnPool) ExchangeContext(
+	ctx context.Context, msg *dns.Msg, address string,
+) (*dns.Msg, time.Duration, error) {
+	conn, err := p.get(ctx, address)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// the query might be canceled before the deadline, e.g. if TCP answered first
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetReadDeadline(time.Now()) })
+
+	resp, rtt, err := exchangeWithConn(ctx, conn.Conn, msg)
+	if !stop() || err != nil {
+		// the socket might get the response later, or the cancellation change its deadline at any time: don't reuse it
+		conn.Close()
+
+		return resp, rtt, err
+	}
+
+	p.put(address, conn)
+
+	return resp, rtt, nil
+}
+
+// get returns an idle socket to the address or opens a new one
+func (p *udpConnPool) get(ctx context.Context, address string) (*pooledUDPConn, error) {
+	p.lock.Lock()
+
+	for conns := p.idle[address]; len(conns) > 0; conns = p.idle[address] {
+		conn := conns[len(conns)-1]
+		p.idle[address] = conns[:len(conns)-1]
+
+		// the deadline of the previous query is reset, so it can't expire while the next query is set up
+		if time.Since(conn.lastUsed) < udpPoolIdleTimeout && conn.SetDeadline(time.Time{}) == nil {
+			p.lock.Unlock()
+
+			return conn, nil
+		}
+
+		conn.Close()
+	}
+
+	p.lock.Unlock()
+
+	conn, err := p.client.DialContext(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pooledUDPConn{Conn: conn}, nil
+}
+
+// put returns the socket to the pool, or closes it if it's used up or the pool is full
+func (p *udpConnPool) put(address string, conn *pooledUDPConn) {
+	conn.queries++
+	conn.lastUsed = time.Now()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if conn.queries >= p.cfg.MaxQueries || uint(len(p.idle[address])) >= p.cfg.Size {
+		conn.Close()
+
+		return
+	}
+
+	p.idle[address] = append(p.idle[address], conn)
+}
+
+// exchangeWithConn sends the query over the socket and waits for the matching response until the deadline of ctx,
+// the caller stops the waiting if ctx is canceled earlier
+func exchangeWithConn(ctx context.Context, conn *dns.Conn, msg *dns.Msg) (*dns.Msg, time.Duration, error) {
+	conn.UDPSize = dns.MinMsgSize
+	if opt := msg.IsEdns0(); opt != nil && opt.UDPSize() >= dns.MinMsgSize {
+		conn.UDPSize = opt.UDPSize()
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil, 0, errors.New("query without deadline")
+	}
+
+	start := time.Now()
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, 0, err
+	}
+
+	if err := conn.WriteMsg(msg); err != nil {
+		return nil, 0, err
+	}
+
+	for {
+		resp, err := conn.ReadMsg()
+		if err != nil {
+			return nil, time.Since(start), err
+		}
+
+		if isResponseTo(resp, msg) {
+			return resp, time.Since(start), nil
+		}
+	}
+}
+
+// isResponseTo returns true if resp has the ID and the question of msg
+func isResponseTo(resp, msg *dns.Msg) bool {
+	if !resp.Response || resp.Id != msg.Id || len(resp.Question) != len(msg.Question) {
+		return false
+	}
+
+	for i, q := range msg.Question {
+		r := resp.Question[i]
+
+		if r.Qtype != q.Qtype || r.Qclass != q.Qclass || !strings.EqualFold(r.Name, q.Name) {
+			return false
+		}
+	}
+
+	return true
+}
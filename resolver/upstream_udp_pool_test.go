@@ -0,0 +1,241 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	. "github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UDP socket pool", func() {
+	const name = "example.com."
+
+	type responder func(request *dns.Msg) []*dns.Msg
+
+	var (
+		sut     *udpConnPool
+		cfg     config.UpstreamUDPPool
+		server  *net.UDPConn
+		respond *atomic.Pointer[responder]
+		ports   chan int
+		ctx     context.Context
+	)
+
+	// setRespond sets the responses of the server, which reads them concurrently
+	setRespond := func(fn responder) {
+		respond.Store(&fn)
+	}
+
+	reply := func(request *dns.Msg) *dns.Msg {
+		rr, err := dns.NewRR(request.Question[0].Name + " 300 IN A 192.0.2.1")
+		Expect(err).Should(Succeed())
+
+		resp := new(dns.Msg)
+		resp.SetReply(request)
+		resp.Answer = []dns.RR{rr}
+
+		return resp
+	}
+
+	query := func() (*dns.Msg, error) {
+		ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+
+		resp, _, err := sut.ExchangeContext(ctx, util.NewMsgWithQuestion(name, A), server.LocalAddr().String())
+
+		return resp, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		// the server goroutine only uses the variables of its spec as it may outlive it
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).Should(Succeed())
+		DeferCleanup(conn.Close)
+
+		responses := new(atomic.Pointer[responder])
+		portsOfSpec := make(chan int, 100)
+
+		server, respond, ports = conn, responses, portsOfSpec
+
+		setRespond(func(request *dns.Msg) []*dns.Msg { return []*dns.Msg{reply(request)} })
+
+		go func() {
+			defer GinkgoRecover()
+
+			buf := make([]byte, dns.MaxMsgSize)
+
+			for {
+				n, addr, err := conn.ReadFromUDP(buf)
+				if err != nil {
+					return
+				}
+
+				request := new(dns.Msg)
+				Expect(request.Unpack(buf[:n])).Should(Succeed())
+
+				portsOfSpec <- addr.Port
+
+				for _, resp := range (*responses.Load())(request) {
+					packed, err := resp.Pack()
+					Expect(err).Should(Succeed())
+
+					_, _ = conn.WriteToUDP(packed, addr)
+				}
+			}
+		}()
+
+		cfg = config.UpstreamUDPPool{Size: 2, MaxQueries: 100}
+	})
+
+	JustBeforeEach(func() {
+		sut = newUDPConnPool(&dns.Client{Net: "udp"}, cfg)
+	})
+
+	It("should reuse the socket", func() {
+		for range 3 {
+			resp, err := query()
+			Expect(err).Should(Succeed())
+			Expect(&Response{Res: resp}).Should(BeDNSRecord(name, A, "192.0.2.1"))
+		}
+
+		port := <-ports
+		Expect(<-ports).Should(Equal(port))
+		Expect(<-ports).Should(Equal(port))
+	})
+
+	When("a socket reached the maximum number of queries", func() {
+		BeforeEach(func() {
+			cfg.MaxQueries = 2
+		})
+
+		It("should use a new socket", func() {
+			for range 3 {
+				_, err := query()
+				Expect(err).Should(Succeed())
+			}
+
+			port := <-ports
+			Expect(<-ports).Should(Equal(port))
+			Expect(<-ports).ShouldNot(Equal(port))
+		})
+	})
+
+	It("should skip responses which don't match the query", func() {
+		setRespond(func(request *dns.Msg) []*dns.Msg {
+			wrongID := reply(request)
+			wrongID.Id++
+
+			wrongQuestion := reply(request)
+			wrongQuestion.Question[0].Name = "other.com."
+
+			wrongType := reply(request)
+			wrongType.Question[0].Qtype = dns.TypeAAAA
+
+			return []*dns.Msg{wrongID, wrongQuestion, wrongType, reply(request)}
+		})
+
+		resp, err := query()
+		Expect(err).Should(Succeed())
+		Expect(resp.Question[0].Name).Should(Equal(name))
+		Expect(&Response{Res: resp}).Should(BeDNSRecord(name, A, "192.0.2.1"))
+	})
+
+	When("the upstream doesn't answer", func() {
+		It("should not reuse the socket", func() {
+			setRespond(func(*dns.Msg) []*dns.Msg { return nil })
+
+			_, err := query()
+			Expect(err).Should(HaveOccurred())
+
+			setRespond(func(request *dns.Msg) []*dns.Msg { return []*dns.Msg{reply(request)} })
+
+			_, err = query()
+			Expect(err).Should(Succeed())
+
+			Expect(<-ports).ShouldNot(Equal(<-ports))
+		})
+	})
+
+	It("should stop waiting when the query is canceled", func() {
+		setRespond(func(*dns.Msg) []*dns.Msg { return nil })
+
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, _, err := sut.ExchangeContext(ctx, util.NewMsgWithQuestion(name, A), server.LocalAddr().String())
+		Expect(err).Should(HaveOccurred())
+		Expect(time.Since(start)).Should(BeNumerically("<", time.Second))
+	})
+
+	It("should not reuse the socket of a canceled query", func() {
+		queryCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		DeferCleanup(cancel)
+
+		// the cancellation races with the response: both the error and the response must not reuse the socket
+		setRespond(func(request *dns.Msg) []*dns.Msg {
+			cancel()
+
+			return []*dns.Msg{reply(request)}
+		})
+
+		_, _, _ = sut.ExchangeContext(queryCtx, util.NewMsgWithQuestion(name, A), server.LocalAddr().String())
+
+		setRespond(func(request *dns.Msg) []*dns.Msg { return []*dns.Msg{reply(request)} })
+
+		_, err := query()
+		Expect(err).Should(Succeed())
+
+		Expect(<-ports).ShouldNot(Equal(<-ports))
+	})
+
+	Describe("isResponseTo", func() {
+		It("should accept the name in another case", func() {
+			msg := util.NewMsgWithQuestion(name, A)
+			resp := reply(msg)
+			resp.Question[0].Name = "EXAMPLE.com."
+
+			Expect(isResponseTo(resp, msg)).Should(BeTrue())
+		})
+
+		It("should not accept queries", func() {
+			msg := util.NewMsgWithQuestion(name, A)
+
+			Expect(isResponseTo(msg.Copy(), msg)).Should(BeFalse())
+		})
+	})
+
+	Describe("upstream resolver", func() {
+		It("should use the pool for the UDP queries if enabled", func() {
+			upstreamCfg := newUpstreamConfig(config.Upstream{Net: config.NetProtocolTcpUdp, Host: "192.0.2.1", Port: 53},
+				config.Upstreams{UDPPool: cfg})
+
+			res, err := createUpstreamClient(upstreamCfg)
+			Expect(err).Should(Succeed())
+
+			client, ok := res.(*dnsUpstreamClient)
+			Expect(ok).Should(BeTrue())
+			Expect(client.udpClient).Should(BeAssignableToTypeOf(&udpConnPool{}))
+
+			upstreamCfg.UDPPool.Size = 0
+
+			res, err = createUpstreamClient(upstreamCfg)
+			Expect(err).Should(Succeed())
+
+			client, ok = res.(*dnsUpstreamClient)
+			Expect(ok).Should(BeTrue())
+			Expect(client.udpClient).Should(BeAssignableToTypeOf(&dns.Client{}))
+		})
+	})
+})
@@ -0,0 +1,334 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	ocspCheckInterval = time.Minute
+	ocspRetryInterval = 10 * time.Minute
+	ocspTimeout       = 10 * time.Second
+	ocspMaxSize       = 1 << 16
+
+	certificateExpiryCheckInterval = 24 * time.Hour
+)
+
+var errNoIssuer = errors.New("certFile contains no issuer certificate")
+
+// fileState is the state of a file at the last check
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// certificateStore serves the certificate from certFile and keyFile, reloads it if the files change
+// and staples the OCSP response
+type certificateStore struct {
+	cfg      config.Certificate
+	certFile string
+	keyFile  string
+	client   *http.Client
+
+	cert atomic.Pointer[tls.Certificate]
+
+	// guards the fields below, they are only used by the refresh
+	lock         sync.Mutex
+	files        [2]fileState
+	stapleUpdate time.Time
+	stapleExpiry time.Time
+}
+
+// newCertificateStore loads the certificate, a missing OCSP response doesn't prevent the start
+func newCertificateStore(ctx context.Context, cfg *config.Config) (*certificateStore, error) {
+	s := &certificateStore{
+		cfg:      cfg.Certificate,
+		certFile: cfg.CertFile,
+		keyFile:  cfg.KeyFile,
+		client:   &http.Client{Timeout: ocspTimeout},
+	}
+
+	if err := s.load(ctx); err != nil {
+		return nil, fmt.Errorf("can't load certificate files: %w", err)
+	}
+
+	return s, nil
+}
+
+// GetCertificate is the `tls.Config.GetCertificate` of the TLS listeners
+func (s *certificateStore) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return s.cert.Load(), nil
+}
+
+// Start checks the files for changes and refreshes the OCSP response until ctx is done
+func (s *certificateStore) Start(ctx context.Context) {
+	interval := s.cfg.CheckPeriod.ToDuration()
+
+	switch {
+	case interval > 0:
+	case s.cfg.OCSPStapling:
+		interval = ocspCheckInterval
+	default:
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.refresh(ctx, time.Now())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// refresh reloads the changed files, a certificate which can't be loaded keeps the previous one in use
+func (s *certificateStore) refresh(ctx context.Context, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.cfg.CheckPeriod.IsAboveZero() && s.filesChanged() {
+		if err := s.loadLocked(ctx); err != nil {
+			logger().WithError(err).Error("can't reload certificate, keeping the previous one")
+
+			return
+		}
+
+		logger().Info("reloaded certificate")
+
+		return
+	}
+
+	if s.cfg.OCSPStapling && !now.Before(s.stapleUpdate) {
+		cert := *s.cert.Load()
+
+		s.staple(ctx, &cert, now)
+		s.cert.Store(&cert)
+	}
+}
+
+func (s *certificateStore) load(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.loadLocked(ctx)
+}
+
+// loadLocked loads the certificate and staples the OCSP response, the lock must be held
+func (s *certificateStore) loadLocked(ctx context.Context) error {
+	files, err := s.stat()
+	if err != nil {
+		return err
+	}
+
+	// don't retry until the files change again
+	s.files = files
+
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return err
+	}
+
+	if s.cfg.OCSPStapling {
+		s.stapleExpiry = time.Time{}
+
+		s.staple(ctx, &cert, time.Now())
+	}
+
+	s.cert.Store(&cert)
+
+	return nil
+}
+
+func (s *certificateStore) stat() (files [2]fileState, err error) {
+	for i, name := range []string{s.certFile, s.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return files, err
+		}
+
+		files[i] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	return files, nil
+}
+
+func (s *certificateStore) filesChanged() bool {
+	files, err := s.stat()
+	if err != nil {
+		logger().WithError(err).Warn("can't check certificate files")
+
+		return false
+	}
+
+	return files != s.files
+}
+
+// staple sets the OCSP response of cert and schedules its refresh,
+// a failed request keeps the previous response until it expires
+func (s *certificateStore) staple(ctx context.Context, cert *tls.Certificate, now time.Time) {
+	resp, err := s.fetchOCSP(ctx, cert)
+	if err != nil {
+		logger().WithError(err).Warn("can't fetch OCSP response")
+
+		if !s.stapleExpiry.IsZero() && now.After(s.stapleExpiry) {
+			cert.OCSPStaple = nil
+		}
+
+		s.stapleUpdate = now.Add(ocspRetryInterval)
+
+		return
+	}
+
+	if resp.Status != ocsp.Good {
+		logger().Errorf("OCSP status of the certificate is %s, not stapling it", ocspStatus(resp.Status))
+
+		cert.OCSPStaple = nil
+		s.stapleUpdate = now.Add(ocspRetryInterval)
+
+		return
+	}
+
+	cert.OCSPStaple = resp.Raw
+	s.stapleExpiry = resp.NextUpdate
+
+	// refresh in the middle of the validity, like most web servers do
+	if resp.NextUpdate.IsZero() {
+		s.stapleUpdate = now.Add(ocspRetryInterval)
+	} else {
+		s.stapleUpdate = resp.ThisUpdate.Add(resp.NextUpdate.Sub(resp.ThisUpdate) / 2) //nolint:mnd
+	}
+}
+
+func (s *certificateStore) fetchOCSP(ctx context.Context, cert *tls.Certificate) (*ocsp.Response, error) {
+	if len(cert.Leaf.OCSPServer) == 0 {
+		return nil, errors.New("certificate has no OCSP server")
+	}
+
+	if len(cert.Certificate) < 2 { //nolint:mnd
+		return nil, errNoIssuer
+	}
+
+	issuer, err := x509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer certificate: %w", err)
+	}
+
+	body, err := ocsp.CreateRequest(cert.Leaf, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cert.Leaf.OCSPServer[0], bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/ocsp-request")
+
+	httpResp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP server returned %s", httpResp.Status)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(httpResp.Body, ocspMaxSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return ocsp.ParseResponseForCert(raw, cert.Leaf, issuer)
+}
+
+func ocspStatus(status int) string {
+	switch status {
+	case ocsp.Good:
+		return "good"
+	case ocsp.Revoked:
+		return "revoked"
+	default:
+		return "unknown"
+	}
+}
+
+// watchCertificateExpiry checks the certificate once a day until ctx is done
+func watchCertificateExpiry(ctx context.Context, cfg config.Certificate,
+	getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error),
+) {
+	if !cfg.ExpiryWarning.IsAboveZero() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(certificateExpiryCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			checkCertificateExpiry(cfg, getCertificate, time.Now())
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// checkCertificateExpiry warns if the certificate expires within `cfg.ExpiryWarning`
+func checkCertificateExpiry(cfg config.Certificate,
+	getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error), now time.Time,
+) {
+	cert, err := getCertificate(nil)
+	if err != nil || cert == nil || len(cert.Certificate) == 0 {
+		return
+	}
+
+	leaf := cert.Leaf
+	if leaf == nil {
+		leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return
+		}
+	}
+
+	if now.Add(cfg.ExpiryWarning.ToDuration()).Before(leaf.NotAfter) {
+		return
+	}
+
+	domains := strings.Join(leaf.DNSNames, ", ")
+
+	logger().Warnf("certificate for %s expires at %s", domains, leaf.NotAfter)
+
+	evt.Bus().Publish(evt.CertificateExpiring, domains, leaf.NotAfter)
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+
+	"github.com/creasty/defaults"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ocsp"
+)
+
+var _ = Describe("Certificate store", func() {
+	var (
+		cfg        config.Config
+		ctx        context.Context
+		caCert     *x509.Certificate
+		caKey      crypto.Signer
+		ocspStatus int
+		ocspServer *httptest.Server
+		sut        *certificateStore
+	)
+
+	// writeCert writes a certificate issued by the CA, its chain contains the CA certificate
+	writeCert := func(serial int64) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).Should(Succeed())
+
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			DNSNames:     []string{"dns.example.com"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			OCSPServer:   []string{ocspServer.URL},
+		}, caCert, &key.PublicKey, caKey)
+		Expect(err).Should(Succeed())
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).Should(Succeed())
+
+		certPEM := append(
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})...)
+
+		Expect(os.WriteFile(cfg.CertFile, certPEM, 0o600)).Should(Succeed())
+		Expect(os.WriteFile(cfg.KeyFile,
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)).Should(Succeed())
+
+		// the files must look changed even within the resolution of the modification time
+		modTime := time.Now().Add(time.Duration(serial) * time.Second)
+		Expect(os.Chtimes(cfg.CertFile, modTime, modTime)).Should(Succeed())
+	}
+
+	serial := func() int64 {
+		cert, err := sut.GetCertificate(nil)
+		Expect(err).Should(Succeed())
+
+		return cert.Leaf.SerialNumber.Int64()
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		Expect(defaults.Set(&cfg)).Should(Succeed())
+
+		dir := GinkgoT().TempDir()
+		cfg.CertFile = filepath.Join(dir, "cert.pem")
+		cfg.KeyFile = filepath.Join(dir, "key.pem")
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).Should(Succeed())
+
+		caKey = key
+
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "Test CA"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).Should(Succeed())
+
+		caCert, err = x509.ParseCertificate(der)
+		Expect(err).Should(Succeed())
+
+		ocspStatus = ocsp.Good
+
+		ocspServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Expect(err).Should(Succeed())
+
+			req, err := ocsp.ParseRequest(body)
+			Expect(err).Should(Succeed())
+
+			now := time.Now().Truncate(time.Minute)
+
+			resp, err := ocsp.CreateResponse(caCert, caCert, ocsp.Response{
+				Status:       ocspStatus,
+				SerialNumber: req.SerialNumber,
+				ThisUpdate:   now,
+				NextUpdate:   now.Add(2 * time.Hour),
+				RevokedAt:    now,
+			}, caKey)
+			Expect(err).Should(Succeed())
+
+			_, _ = w.Write(resp)
+		}))
+		DeferCleanup(ocspServer.Close)
+
+		writeCert(2)
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = newCertificateStore(ctx, &cfg)
+		Expect(err).Should(Succeed())
+	})
+
+	It("should serve the certificate of the files", func() {
+		Expect(serial()).Should(BeEquivalentTo(2))
+	})
+
+	It("should fail if the files can't be loaded", func() {
+		cfg.KeyFile = "/does/not/exist"
+
+		_, err := newCertificateStore(ctx, &cfg)
+		Expect(err).Should(HaveOccurred())
+	})
+
+	When("the files are checked for changes", func() {
+		BeforeEach(func() {
+			cfg.Certificate.CheckPeriod = config.Duration(time.Minute)
+		})
+
+		It("should reload changed files", func() {
+			sut.refresh(ctx, time.Now())
+			Expect(serial()).Should(BeEquivalentTo(2))
+
+			writeCert(3)
+
+			sut.refresh(ctx, time.Now())
+			Expect(serial()).Should(BeEquivalentTo(3))
+		})
+
+		It("should keep the certificate if the files are invalid", func() {
+			Expect(os.WriteFile(cfg.CertFile, []byte("invalid"), 0o600)).Should(Succeed())
+
+			sut.refresh(ctx, time.Now())
+			Expect(serial()).Should(BeEquivalentTo(2))
+		})
+	})
+
+	When("OCSP stapling is enabled", func() {
+		BeforeEach(func() {
+			cfg.Certificate.OCSPStapling = true
+		})
+
+		It("should staple the OCSP response", func() {
+			cert, err := sut.GetCertificate(nil)
+			Expect(err).Should(Succeed())
+			Expect(cert.OCSPStaple).ShouldNot(BeEmpty())
+
+			resp, err := ocsp.ParseResponse(cert.OCSPStaple, caCert)
+			Expect(err).Should(Succeed())
+			Expect(resp.Status).Should(Equal(ocsp.Good))
+
+			// refreshed in the middle of the validity
+			Expect(sut.stapleUpdate).Should(Equal(resp.ThisUpdate.Add(time.Hour)))
+		})
+
+		It("should refresh the response when it is due", func() {
+			ocspStatus = ocsp.Revoked
+
+			sut.refresh(ctx, time.Now())
+			Expect(sut.cert.Load().OCSPStaple).ShouldNot(BeEmpty())
+
+			sut.refresh(ctx, sut.stapleUpdate)
+			Expect(sut.cert.Load().OCSPStaple).Should(BeEmpty())
+		})
+
+		It("should keep the response until it expires if the OCSP server is unavailable", func() {
+			ocspServer.Close()
+
+			sut.refresh(ctx, sut.stapleUpdate)
+			Expect(sut.cert.Load().OCSPStaple).ShouldNot(BeEmpty())
+
+			sut.refresh(ctx, sut.stapleExpiry.Add(time.Minute))
+			Expect(sut.cert.Load().OCSPStaple).Should(BeEmpty())
+		})
+	})
+
+	Describe("expiry warning", func() {
+		var expiring []string
+
+		BeforeEach(func() {
+			expiring = nil
+
+			handler := func(domains string, notAfter time.Time) {
+				expiring = append(expiring, domains)
+			}
+			Expect(evt.Bus().Subscribe(evt.CertificateExpiring, handler)).Should(Succeed())
+			DeferCleanup(func() { _ = evt.Bus().Unsubscribe(evt.CertificateExpiring, handler) })
+		})
+
+		It("should fire an event if the certificate expires soon", func() {
+			cfg.Certificate.ExpiryWarning = config.Duration(2 * time.Hour)
+
+			checkCertificateExpiry(cfg.Certificate, sut.GetCertificate, time.Now())
+
+			Expect(expiring).Should(Equal([]string{"dns.example.com"}))
+		})
+
+		It("should not fire an event before the warning period", func() {
+			cfg.Certificate.ExpiryWarning = config.Duration(time.Minute)
+
+			checkCertificateExpiry(cfg.Certificate, sut.GetCertificate, time.Now())
+
+			Expect(expiring).Should(BeEmpty())
+		})
+	})
+})
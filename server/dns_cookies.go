@@ -0,0 +1,246 @@
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+)
+
+// sizes of the cookies (RFC 7873 section 4)
+const (
+	clientCookieLength    = 8
+	minServerCookieLength = 8
+	maxServerCookieLength = 32
+)
+
+// server cookies in the interoperable format of RFC 9018: version, reserved, timestamp and hash
+const (
+	serverCookieVersion = 1
+	serverCookieLength  = 16
+
+	// older server cookies are invalid
+	serverCookieMaxAge = time.Hour
+	// server cookies from the future are valid up to this clock skew between instances
+	serverCookieMaxSkew = 5 * time.Minute
+)
+
+var errInvalidCookie = errors.New("invalid DNS cookie")
+
+// dnsCookies adds server cookies to the responses of queries with a DNS cookie (RFC 7873), so a client can prove
+// with its next queries that its source address isn't spoofed
+type dnsCookies struct {
+	secret [config.DNSCookieSecretLength]byte
+}
+
+// newDNSCookies returns nil if the cookies are disabled
+func newDNSCookies(cfg config.DNSCookies) (*dnsCookies, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil //nolint:nilnil
+	}
+
+	c := &dnsCookies{}
+
+	if secret := cfg.SecretBytes(); secret != nil {
+		copy(c.secret[:], secret)
+	} else if _, err := rand.Read(c.secret[:]); err != nil {
+		return nil, fmt.Errorf("can't create DNS cookie secret: %w", err)
+	}
+
+	return c, nil
+}
+
+// wrap returns the handler adding the server cookies, the handler itself if the cookies are disabled
+func (c *dnsCookies) wrap(handler dns.HandlerFunc) dns.HandlerFunc {
+	if c == nil {
+		return handler
+	}
+
+	return func(w dns.ResponseWriter, msg *dns.Msg) {
+		option := util.GetEdns0Option[*dns.EDNS0_COOKIE](msg)
+		if option == nil {
+			handler(w, msg)
+
+			return
+		}
+
+		clientCookie, serverCookie, err := parseCookie(option.Cookie)
+		if err != nil {
+			// RFC 7873 section 5.2.2
+			resp := new(dns.Msg)
+			resp.SetRcode(msg, dns.RcodeFormatError)
+
+			util.LogOnErrorWithEntry(logger(), "can't write message: ", w.WriteMsg(resp))
+
+			return
+		}
+
+		clientIP, _ := resolveClientIPAndProtocol(w.RemoteAddr())
+
+		ip, _ := netip.AddrFromSlice(clientIP)
+		ip = ip.Unmap()
+
+		now := time.Now()
+
+		handler(&cookieWriter{
+			ResponseWriter: w,
+			request:        msg,
+			cookie:         hex.EncodeToString(slices.Concat(clientCookie, c.serverCookie(clientCookie, ip, now))),
+			verified:       serverCookie != nil && c.isValid(clientCookie, serverCookie, ip, now),
+		}, msg)
+	}
+}
+
+// parseCookie returns the client cookie and the server cookie, nil if the client doesn't know one yet
+func parseCookie(cookie string) (clientCookie, serverCookie []byte, err error) {
+	data, err := hex.DecodeString(cookie)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", errInvalidCookie, err)
+	}
+
+	switch l := len(data) - clientCookieLength; {
+	case l == 0:
+		return data, nil, nil
+	case l >= minServerCookieLength && l <= maxServerCookieLength:
+		return data[:clientCookieLength], data[clientCookieLength:], nil
+	default:
+		return nil, nil, fmt.Errorf("%w: length %d", errInvalidCookie, len(data))
+	}
+}
+
+// serverCookie returns the server cookie for the client at the time
+func (c *dnsCookies) serverCookie(clientCookie []byte, ip netip.Addr, now time.Time) []byte {
+	cookie := make([]byte, serverCookieLength)
+	cookie[0] = serverCookieVersion
+	binary.BigEndian.PutUint32(cookie[4:8], uint32(now.Unix())) //nolint:gosec // serial number arithmetic
+
+	binary.LittleEndian.PutUint64(cookie[8:], c.hash(clientCookie, cookie[:8], ip))
+
+	return cookie
+}
+
+// isValid returns true if the server cookie was created by this or another instance with the same secret
+// for the client within the max age
+func (c *dnsCookies) isValid(clientCookie, serverCookie []byte, ip netip.Addr, now time.Time) bool {
+	if len(serverCookie) != serverCookieLength || serverCookie[0] != serverCookieVersion {
+		return false
+	}
+
+	created := time.Unix(int64(binary.BigEndian.Uint32(serverCookie[4:8])), 0)
+	if now.Sub(created) > serverCookieMaxAge || created.Sub(now) > serverCookieMaxSkew {
+		return false
+	}
+
+	hash := binary.LittleEndian.AppendUint64(nil, c.hash(clientCookie, serverCookie[:8], ip))
+
+	return bytes.Equal(hash, serverCookie[8:])
+}
+
+// hash returns the SipHash-2-4 of the client cookie, the version, reserved and timestamp fields and the client IP
+func (c *dnsCookies) hash(clientCookie, header []byte, ip netip.Addr) uint64 {
+	data := slices.Concat(clientCookie, header, ip.AsSlice())
+
+	return sipHash24(c.secret, data)
+}
+
+// cookieWriter adds the server cookie to the response
+type cookieWriter struct {
+	dns.ResponseWriter
+
+	request *dns.Msg
+	// client and server cookie, hex encoded
+	cookie string
+	// the query contains a valid server cookie
+	verified bool
+}
+
+func (w *cookieWriter) Unwrap() dns.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *cookieWriter) WriteMsg(msg *dns.Msg) error {
+	if msg.IsEdns0() == nil {
+		opt := w.request.IsEdns0()
+		msg.SetEdns0(opt.UDPSize(), opt.Do())
+	}
+
+	util.SetEdns0Option(msg, &dns.EDNS0_COOKIE{Code: dns.EDNS0COOKIE, Cookie: w.cookie})
+
+	return w.ResponseWriter.WriteMsg(msg)
+}
+
+// hasValidCookie returns true if the query answered with the writer contains a valid server cookie
+func hasValidCookie(w dns.ResponseWriter) bool {
+	for ; w != nil; w = unwrapWriter(w) {
+		if cw, ok := w.(*cookieWriter); ok {
+			return cw.verified
+		}
+	}
+
+	return false
+}
+
+// sipHash24 returns the SipHash-2-4 of the data, as used for the server cookies of RFC 9018
+func sipHash24(key [16]byte, data []byte) uint64 {
+	k0 := binary.LittleEndian.Uint64(key[:8])
+	k1 := binary.LittleEndian.Uint64(key[8:])
+
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = bits.RotateLeft64(v1, 13) ^ v0
+		v0 = bits.RotateLeft64(v0, 32)
+		v2 += v3
+		v3 = bits.RotateLeft64(v3, 16) ^ v2
+		v0 += v3
+		v3 = bits.RotateLeft64(v3, 21) ^ v0
+		v2 += v1
+		v1 = bits.RotateLeft64(v1, 17) ^ v2
+		v2 = bits.RotateLeft64(v2, 32)
+	}
+
+	compress := func(m uint64) {
+		v3 ^= m
+		round()
+		round()
+		v0 ^= m
+	}
+
+	const blockSize = 8
+
+	n := len(data)
+
+	for ; len(data) >= blockSize; data = data[blockSize:] {
+		compress(binary.LittleEndian.Uint64(data))
+	}
+
+	// the last block holds the remaining bytes and the length
+	last := make([]byte, blockSize)
+	copy(last, data)
+	last[blockSize-1] = byte(n)
+
+	compress(binary.LittleEndian.Uint64(last))
+
+	v2 ^= 0xff
+
+	for range 4 {
+		round()
+	}
+
+	return v0 ^ v1 ^ v2 ^ v3
+}
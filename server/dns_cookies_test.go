@@ -0,0 +1,168 @@
+package server
+
+import (
+	"encoding/hex"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNS cookies", func() {
+	var (
+		cfg     config.DNSCookies
+		sut     *dnsCookies
+		handler dns.HandlerFunc
+		handled int
+		udpAddr = &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 53}
+		now     time.Time
+	)
+
+	clientCookie := []byte("\x24\x64\xc4\xab\xcf\x10\xc9\x57")
+	clientIP := netip.MustParseAddr("192.0.2.10")
+
+	BeforeEach(func() {
+		cfg = config.DNSCookies{Enable: true, Secret: "e5e973e5a6b2a43f48e7dc849e37bfcf", RateLimitFactor: 1}
+		handled = 0
+		now = time.Now()
+
+		handler = func(w dns.ResponseWriter, m *dns.Msg) {
+			handled++
+
+			Expect(hasValidCookie(w)).Should(Equal(m.Id == 1))
+
+			resp := new(dns.Msg)
+			resp.SetReply(m)
+			Expect(w.WriteMsg(resp)).Should(Succeed())
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = newDNSCookies(cfg)
+		Expect(err).Should(Succeed())
+	})
+
+	// query sends a query with the cookie, valid server cookies are marked with the ID 1
+	query := func(cookie string, valid bool) *recordingWriter {
+		msg := util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA))
+		msg.SetEdns0(1232, true)
+
+		msg.Id = 2
+		if valid {
+			msg.Id = 1
+		}
+
+		if cookie != "" {
+			util.SetEdns0Option(msg, &dns.EDNS0_COOKIE{Code: dns.EDNS0COOKIE, Cookie: cookie})
+		}
+
+		w := &recordingWriter{remote: udpAddr}
+		sut.wrap(handler)(w, msg)
+
+		return w
+	}
+
+	responseCookie := func(w *recordingWriter) string {
+		ExpectWithOffset(1, w.written).Should(HaveLen(1))
+
+		cookie := util.GetEdns0Option[*dns.EDNS0_COOKIE](w.written[0])
+		ExpectWithOffset(1, cookie).ShouldNot(BeNil())
+
+		return cookie.Cookie
+	}
+
+	When("cookies are disabled", func() {
+		BeforeEach(func() {
+			cfg.Enable = false
+		})
+
+		It("should not wrap the handler", func() {
+			Expect(sut).Should(BeNil())
+
+			Expect(query("2464c4abcf10c957", false).written[0].IsEdns0()).Should(BeNil())
+		})
+	})
+
+	It("should create interoperable server cookies (RFC 9018)", func() {
+		cookie := sut.serverCookie(clientCookie, netip.MustParseAddr("198.51.100.100"), time.Unix(1559731985, 0))
+
+		Expect(hex.EncodeToString(cookie)).Should(Equal("010000005cf79f111f8130c3eee29480"))
+	})
+
+	It("should answer queries without cookie as they are", func() {
+		w := query("", false)
+
+		Expect(handled).Should(Equal(1))
+		Expect(util.GetEdns0Option[*dns.EDNS0_COOKIE](w.written[0])).Should(BeNil())
+	})
+
+	It("should add a server cookie to the response of a query with a client cookie", func() {
+		cookie := responseCookie(query("2464c4abcf10c957", false))
+
+		Expect(cookie).Should(HavePrefix("2464c4abcf10c957"))
+		Expect(cookie).Should(HaveLen(2 * (clientCookieLength + serverCookieLength)))
+
+		// the returned cookie is valid
+		Expect(responseCookie(query(cookie, true))).Should(HavePrefix("2464c4abcf10c957"))
+	})
+
+	It("should keep the EDNS of the query in the response", func() {
+		w := query("2464c4abcf10c957", false)
+
+		Expect(w.written[0].IsEdns0().UDPSize()).Should(BeEquivalentTo(1232))
+		Expect(w.written[0].IsEdns0().Do()).Should(BeTrue())
+	})
+
+	It("should not accept server cookies of another client, secret or time", func() {
+		valid := sut.serverCookie(clientCookie, clientIP, now)
+		Expect(sut.isValid(clientCookie, valid, clientIP, now)).Should(BeTrue())
+
+		Expect(sut.isValid([]byte("otherone"), valid, clientIP, now)).Should(BeFalse())
+		Expect(sut.isValid(clientCookie, valid, netip.MustParseAddr("192.0.2.11"), now)).Should(BeFalse())
+		Expect(sut.isValid(clientCookie, valid, clientIP, now.Add(serverCookieMaxAge+time.Minute))).Should(BeFalse())
+		Expect(sut.isValid(clientCookie, valid, clientIP, now.Add(-serverCookieMaxSkew-time.Minute))).Should(BeFalse())
+
+		other, err := newDNSCookies(config.DNSCookies{Enable: true})
+		Expect(err).Should(Succeed())
+		Expect(other.isValid(clientCookie, valid, clientIP, now)).Should(BeFalse())
+
+		// a server cookie which isn't valid is answered with a new one
+		cookie := responseCookie(query("2464c4abcf10c957"+"0100000000000000abcdefabcdefabcd", false))
+		Expect(cookie).ShouldNot(HaveSuffix("abcdefabcdefabcd"))
+		Expect(handled).Should(Equal(1))
+	})
+
+	DescribeTable("should answer malformed cookies with FORMERR",
+		func(cookie string) {
+			w := query(cookie, false)
+
+			Expect(handled).Should(BeZero())
+			Expect(w.written).Should(HaveLen(1))
+			Expect(w.written[0].Rcode).Should(Equal(dns.RcodeFormatError))
+		},
+		Entry("too short", "2464c4ab"),
+		Entry("too short server cookie", "2464c4abcf10c95701"),
+		Entry("too long", "2464c4abcf10c957"+hex.EncodeToString(make([]byte, 33))),
+	)
+
+	Describe("sipHash24", func() {
+		It("should match the reference implementation", func() {
+			var key [16]byte
+			for i := range key {
+				key[i] = byte(i)
+			}
+
+			Expect(sipHash24(key, nil)).Should(BeEquivalentTo(uint64(0x726fdb47dd0e0e31)))
+			Expect(sipHash24(key, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14})).
+				Should(BeEquivalentTo(uint64(0xa129ca6149be45e5)))
+		})
+	})
+})
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"sync/atomic"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+)
+
+// names of the limits, used as metric label
+const (
+	rateLimitQueries  = "queries"
+	rateLimitNXDomain = "nxdomain"
+	rateLimitAny      = "any"
+)
+
+// ipv6ClientPrefixBits is the prefix length of IPv6 clients sharing their limits:
+// a single host usually has a whole /64 network and could use a new address for every query
+const ipv6ClientPrefixBits = 64
+
+// dnsRateLimiter limits the queries of each client IP on the DNS listeners with token buckets
+type dnsRateLimiter struct {
+	action  config.RateLimitAction
+	slip    uint64
+	slipped atomic.Uint64
+	// add an Extended DNS Error to refused queries
+	ede bool
+
+	// nil if clients without own limits are unlimited
+	defaults *clientRateLimiter
+	// limits of the configured clients, most specific prefix first
+	clients []prefixRateLimiter
+}
+
+type prefixRateLimiter struct {
+	prefix  netip.Prefix
+	limiter *clientRateLimiter
+}
+
+// clientRateLimiter holds the buckets of a set of limits, nil limiters are unlimited
+type clientRateLimiter struct {
+	queries  *util.KeyedRateLimiter
+	nxdomain *util.KeyedRateLimiter
+	anyQuery *util.KeyedRateLimiter
+
+	// relaxed limits for queries with a valid DNS cookie, nil if they have the same limits
+	verified *clientRateLimiter
+}
+
+// newDNSRateLimiter returns nil if no limit is enabled
+func newDNSRateLimiter(cfg config.DNSRateLimit, ede config.EDE, cookies config.DNSCookies) (*dnsRateLimiter, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil //nolint:nilnil
+	}
+
+	cookieFactor := uint(1)
+	if cookies.IsEnabled() {
+		cookieFactor = cookies.RateLimitFactor
+	}
+
+	res := &dnsRateLimiter{
+		action:   cfg.Action,
+		slip:     uint64(cfg.Slip),
+		ede:      ede.IsEnabled(),
+		defaults: newClientRateLimiter(cfg.DNSClientRateLimit, cookieFactor),
+		clients:  make([]prefixRateLimiter, 0, len(cfg.Clients)),
+	}
+
+	for key, limit := range cfg.Clients {
+		prefix, err := config.ParseClientPrefix(key)
+		if err != nil {
+			return nil, err
+		}
+
+		res.clients = append(res.clients, prefixRateLimiter{prefix: prefix, limiter: newClientRateLimiter(limit, cookieFactor)})
+	}
+
+	slices.SortFunc(res.clients, func(a, b prefixRateLimiter) int {
+		return b.prefix.Bits() - a.prefix.Bits()
+	})
+
+	return res, nil
+}
+
+// newClientRateLimiter returns the limits, the limits of queries with a valid DNS cookie are multiplied by the factor
+func newClientRateLimiter(cfg config.DNSClientRateLimit, cookieFactor uint) *clientRateLimiter {
+	if !cfg.IsEnabled() {
+		return nil
+	}
+
+	res := &clientRateLimiter{
+		queries:  newKeyedRateLimiter(cfg.RateLimit, 1),
+		nxdomain: newKeyedRateLimiter(cfg.NXDomain, 1),
+		anyQuery: newKeyedRateLimiter(cfg.Any, 1),
+	}
+
+	if cookieFactor > 1 {
+		res.verified = &clientRateLimiter{
+			queries:  newKeyedRateLimiter(cfg.RateLimit, cookieFactor),
+			nxdomain: newKeyedRateLimiter(cfg.NXDomain, cookieFactor),
+			anyQuery: newKeyedRateLimiter(cfg.Any, cookieFactor),
+		}
+	}
+
+	return res
+}
+
+func newKeyedRateLimiter(cfg config.RateLimit, factor uint) *util.KeyedRateLimiter {
+	if !cfg.IsEnabled() {
+		return nil
+	}
+
+	return util.NewKeyedRateLimiter(float64(cfg.Rate*factor), cfg.EffectiveBurst()*factor)
+}
+
+func allow(limiter *util.KeyedRateLimiter, key string) bool {
+	if limiter == nil {
+		return true
+	}
+
+	allowed, _ := limiter.Allow(key)
+
+	return allowed
+}
+
+// limiterFor returns the limits of the client, nil if it is unlimited
+func (l *dnsRateLimiter) limiterFor(ip netip.Addr) *clientRateLimiter {
+	for _, c := range l.clients {
+		if c.prefix.Contains(ip) {
+			return c.limiter
+		}
+	}
+
+	return l.defaults
+}
+
+// wrap returns the handler limiting the queries, the handler itself if no limit is enabled
+func (l *dnsRateLimiter) wrap(handler dns.HandlerFunc) dns.HandlerFunc {
+	if l == nil {
+		return handler
+	}
+
+	return func(w dns.ResponseWriter, msg *dns.Msg) {
+		clientIP, _ := resolveClientIPAndProtocol(w.RemoteAddr())
+
+		ip, _ := netip.AddrFromSlice(clientIP)
+		ip = ip.Unmap()
+
+		limiter := l.limiterFor(ip)
+		if limiter == nil {
+			handler(w, msg)
+
+			return
+		}
+
+		if limiter.verified != nil && hasValidCookie(w) {
+			limiter = limiter.verified
+		}
+
+		key := clientKey(ip)
+
+		if !allow(limiter.queries, key) {
+			l.limit(w, msg, rateLimitQueries)
+
+			return
+		}
+
+		if isAnyQuery(msg) && !allow(limiter.anyQuery, key) {
+			l.limit(w, msg, rateLimitAny)
+
+			return
+		}
+
+		if limiter.nxdomain != nil {
+			w = &nxDomainLimitWriter{ResponseWriter: w, l: l, limiter: limiter.nxdomain, key: key, request: msg}
+		}
+
+		handler(w, msg)
+	}
+}
+
+// clientKey returns the key of the client's buckets, the /64 network for IPv6 clients
+func clientKey(ip netip.Addr) string {
+	if ip.Is6() {
+		prefix, err := ip.Prefix(ipv6ClientPrefixBits)
+		if err == nil {
+			return prefix.String()
+		}
+	}
+
+	return ip.String()
+}
+
+// limit handles a query exceeding a limit according to the configured action
+func (l *dnsRateLimiter) limit(w dns.ResponseWriter, request *dns.Msg, limit string) {
+	evt.Bus().Publish(evt.RateLimited, limit)
+
+	resp := new(dns.Msg)
+
+	switch l.action {
+	case config.RateLimitActionDrop:
+		return
+
+	case config.RateLimitActionSlip:
+		// over TCP, the client can't retry with another protocol: refuse
+		if _, ok := w.RemoteAddr().(*net.UDPAddr); ok {
+			if l.slip == 0 || l.slipped.Add(1)%l.slip != 0 {
+				return
+			}
+
+			resp.SetReply(request)
+			resp.Truncated = true
+
+			break
+		}
+
+		resp.SetRcode(request, dns.RcodeRefused)
+
+	default:
+		resp.SetRcode(request, dns.RcodeRefused)
+	}
+
+	if resp.Rcode == dns.RcodeRefused {
+		l.addExtendedError(request, resp, limit)
+	}
+
+	util.LogOnErrorWithEntry(logger(), "can't write message: ", w.WriteMsg(resp))
+}
+
+// addExtendedError explains the refusal with an EDE (RFC 8914) if the client uses EDNS
+func (l *dnsRateLimiter) addExtendedError(request, resp *dns.Msg, limit string) {
+	opt := request.IsEdns0()
+	if !l.ede || opt == nil {
+		return
+	}
+
+	resp.SetEdns0(opt.UDPSize(), false)
+	util.SetEdns0Option(resp, &dns.EDNS0_EDE{
+		InfoCode:  dns.ExtendedErrorCodeProhibited,
+		ExtraText: fmt.Sprintf("RATE LIMITED (%s)", limit),
+	})
+}
+
+func isAnyQuery(msg *dns.Msg) bool {
+	return len(msg.Question) > 0 && msg.Question[0].Qtype == dns.TypeANY
+}
+
+// nxDomainLimitWriter applies the NXDOMAIN limit of the client to the response
+type nxDomainLimitWriter struct {
+	dns.ResponseWriter
+
+	l       *dnsRateLimiter
+	limiter *util.KeyedRateLimiter
+	key     string
+	request *dns.Msg
+}
+
+// Unwrap returns the wrapped writer
+func (w *nxDomainLimitWriter) Unwrap() dns.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *nxDomainLimitWriter) WriteMsg(msg *dns.Msg) error {
+	if msg.Rcode == dns.RcodeNameError && !allow(w.limiter, w.key) {
+		w.l.limit(w.ResponseWriter, w.request, rateLimitNXDomain)
+
+		return nil
+	}
+
+	return w.ResponseWriter.WriteMsg(msg)
+}
@@ -0,0 +1,282 @@
+package server
+
+import (
+	"encoding/hex"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// recordingWriter is a dns.ResponseWriter which records the written messages
+type recordingWriter struct {
+	dns.ResponseWriter
+
+	remote  net.Addr
+	written []*dns.Msg
+}
+
+func (w *recordingWriter) RemoteAddr() net.Addr {
+	return w.remote
+}
+
+func (w *recordingWriter) WriteMsg(msg *dns.Msg) error {
+	w.written = append(w.written, msg)
+
+	return nil
+}
+
+var _ = Describe("DNS rate limiting", func() {
+	var (
+		cfg      config.DNSRateLimit
+		ede      config.EDE
+		cookies  config.DNSCookies
+		sut      *dnsRateLimiter
+		rcode    int
+		handled  int
+		handler  dns.HandlerFunc
+		limited  []string
+		udpAddr  = &net.UDPAddr{IP: net.ParseIP("192.168.178.20"), Port: 53}
+		otherUDP = &net.UDPAddr{IP: net.ParseIP("192.168.178.21"), Port: 53}
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = config.WithDefaults[config.DNSRateLimit]()
+		Expect(err).Should(Succeed())
+
+		ede = config.EDE{}
+		cookies = config.DNSCookies{}
+		rcode = dns.RcodeSuccess
+		handled = 0
+		handler = func(w dns.ResponseWriter, m *dns.Msg) {
+			handled++
+
+			resp := new(dns.Msg)
+			resp.SetRcode(m, rcode)
+			Expect(w.WriteMsg(resp)).Should(Succeed())
+		}
+
+		limited = nil
+		onLimited := func(limit string) { limited = append(limited, limit) }
+		Expect(evt.Bus().Subscribe(evt.RateLimited, onLimited)).Should(Succeed())
+		DeferCleanup(func() { Expect(evt.Bus().Unsubscribe(evt.RateLimited, onLimited)).Should(Succeed()) })
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = newDNSRateLimiter(cfg, ede, cookies)
+		Expect(err).Should(Succeed())
+	})
+
+	query := func(remote net.Addr, qType uint16) *recordingWriter {
+		w := &recordingWriter{remote: remote}
+
+		sut.wrap(handler)(w, util.NewMsgWithQuestion("example.com.", dns.Type(qType)))
+
+		return w
+	}
+
+	When("no limit is enabled", func() {
+		It("should not wrap the handler", func() {
+			Expect(sut).Should(BeNil())
+
+			for range 10 {
+				Expect(query(udpAddr, dns.TypeA).written).Should(HaveLen(1))
+			}
+		})
+	})
+
+	When("the queries are limited", func() {
+		BeforeEach(func() {
+			cfg.Rate = 1
+			cfg.Burst = 2
+		})
+
+		It("should refuse queries exceeding the burst of each client", func() {
+			Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+			Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+			Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeRefused))
+
+			Expect(query(otherUDP, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+
+			Expect(handled).Should(Equal(3))
+			Expect(limited).Should(Equal([]string{rateLimitQueries}))
+		})
+
+		It("should limit the /64 network of IPv6 clients", func() {
+			Expect(query(&net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 53}, dns.TypeA).written[0].Rcode).
+				Should(Equal(dns.RcodeSuccess))
+			Expect(query(&net.UDPAddr{IP: net.ParseIP("2001:db8::2"), Port: 53}, dns.TypeA).written[0].Rcode).
+				Should(Equal(dns.RcodeSuccess))
+			Expect(query(&net.UDPAddr{IP: net.ParseIP("2001:db8::ffff:3"), Port: 53}, dns.TypeA).written[0].Rcode).
+				Should(Equal(dns.RcodeRefused))
+
+			Expect(query(&net.UDPAddr{IP: net.ParseIP("2001:db8:0:1::1"), Port: 53}, dns.TypeA).written[0].Rcode).
+				Should(Equal(dns.RcodeSuccess))
+		})
+
+		When("EDE is enabled", func() {
+			BeforeEach(func() {
+				ede.Enable = true
+			})
+
+			It("should add the limit as extended error if the client uses EDNS", func() {
+				w := &recordingWriter{remote: udpAddr}
+				msg := util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA))
+				msg.SetEdns0(1232, false)
+
+				for range 3 {
+					sut.wrap(handler)(w, msg)
+				}
+
+				Expect(w.written[2].Rcode).Should(Equal(dns.RcodeRefused))
+				Expect(w.written[2].IsEdns0().UDPSize()).Should(BeEquivalentTo(1232))
+				Expect(w.written[2].IsEdns0().Option).Should(ConsistOf(&dns.EDNS0_EDE{
+					InfoCode:  dns.ExtendedErrorCodeProhibited,
+					ExtraText: "RATE LIMITED (queries)",
+				}))
+
+				query(udpAddr, dns.TypeA)
+				Expect(query(udpAddr, dns.TypeA).written[0].IsEdns0()).Should(BeNil())
+			})
+		})
+
+		When("the action is drop", func() {
+			BeforeEach(func() {
+				cfg.Action = config.RateLimitActionDrop
+			})
+
+			It("should not answer", func() {
+				query(udpAddr, dns.TypeA)
+				query(udpAddr, dns.TypeA)
+
+				Expect(query(udpAddr, dns.TypeA).written).Should(BeEmpty())
+			})
+		})
+
+		When("the action is slip", func() {
+			BeforeEach(func() {
+				cfg.Action = config.RateLimitActionSlip
+				cfg.Slip = 2
+			})
+
+			It("should answer every second query truncated over UDP", func() {
+				query(udpAddr, dns.TypeA)
+				query(udpAddr, dns.TypeA)
+
+				Expect(query(udpAddr, dns.TypeA).written).Should(BeEmpty())
+
+				w := query(udpAddr, dns.TypeA)
+				Expect(w.written).Should(HaveLen(1))
+				Expect(w.written[0].Truncated).Should(BeTrue())
+				Expect(w.written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+			})
+
+			It("should refuse queries over TCP", func() {
+				tcpAddr := &net.TCPAddr{IP: net.ParseIP("192.168.178.20"), Port: 53}
+
+				query(tcpAddr, dns.TypeA)
+				query(tcpAddr, dns.TypeA)
+
+				Expect(query(tcpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeRefused))
+			})
+		})
+
+		When("DNS cookies are enabled", func() {
+			BeforeEach(func() {
+				cookies = config.DNSCookies{Enable: true, RateLimitFactor: 2}
+			})
+
+			It("should relax the limits for queries with a valid server cookie", func() {
+				cookieSut, err := newDNSCookies(cookies)
+				Expect(err).Should(Succeed())
+
+				clientCookie := []byte("client01")
+				ip := netip.MustParseAddr("192.168.178.20")
+
+				msg := util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA))
+				util.SetEdns0Option(msg, &dns.EDNS0_COOKIE{
+					Code:   dns.EDNS0COOKIE,
+					Cookie: hex.EncodeToString(append(clientCookie, cookieSut.serverCookie(clientCookie, ip, time.Now())...)),
+				})
+
+				w := &recordingWriter{remote: udpAddr}
+
+				for range 5 {
+					cookieSut.wrap(sut.wrap(handler))(w, msg)
+				}
+
+				Expect(w.written).Should(HaveLen(5))
+				Expect(w.written[3].Rcode).Should(Equal(dns.RcodeSuccess))
+				Expect(w.written[4].Rcode).Should(Equal(dns.RcodeRefused))
+
+				// the limits without cookie are not affected
+				Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+			})
+		})
+
+		When("a client has own limits", func() {
+			BeforeEach(func() {
+				cfg.Clients = map[string]config.DNSClientRateLimit{
+					"192.168.178.0/24": {RateLimit: config.RateLimit{Rate: 1, Burst: 3}},
+					"192.168.178.21":   {},
+				}
+			})
+
+			It("should apply the limits of the most specific prefix", func() {
+				for range 3 {
+					Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+				}
+
+				Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeRefused))
+
+				for range 10 {
+					Expect(query(otherUDP, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+				}
+			})
+		})
+	})
+
+	When("ANY queries are limited", func() {
+		BeforeEach(func() {
+			cfg.Any.Rate = 1
+		})
+
+		It("should only limit ANY queries", func() {
+			Expect(query(udpAddr, dns.TypeANY).written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+			Expect(query(udpAddr, dns.TypeANY).written[0].Rcode).Should(Equal(dns.RcodeRefused))
+			Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+
+			Expect(limited).Should(Equal([]string{rateLimitAny}))
+		})
+	})
+
+	When("NXDOMAIN responses are limited", func() {
+		BeforeEach(func() {
+			cfg.NXDomain.Rate = 1
+			rcode = dns.RcodeNameError
+		})
+
+		It("should limit the queries answered with NXDOMAIN", func() {
+			Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeNameError))
+			Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeRefused))
+
+			rcode = dns.RcodeSuccess
+
+			Expect(query(udpAddr, dns.TypeA).written[0].Rcode).Should(Equal(dns.RcodeSuccess))
+
+			Expect(handled).Should(Equal(3))
+			Expect(limited).Should(Equal([]string{rateLimitNXDomain}))
+		})
+	})
+})
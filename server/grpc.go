@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/api/admin"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/resolver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// listenerServer serves requests of a listener until the context is done
+type listenerServer interface {
+	fmt.Stringer
+
+	Serve(ctx context.Context, l net.Listener) error
+
+	// Shutdown stops accepting connections and waits for the active requests until ctx is done
+	Shutdown(ctx context.Context) error
+}
+
+// grpcMethodClasses are the endpoint classes of the gRPC methods, all other methods are control endpoints
+//
+//nolint:gochecknoglobals
+var grpcMethodClasses = map[string]string{
+	admin.AdminService_GetBlockingStatus_FullMethodName:   endpointClassRead,
+	admin.AdminService_WatchBlockingStatus_FullMethodName: endpointClassRead,
+	admin.AdminService_Query_FullMethodName:               endpointClassQuery,
+}
+
+type grpcServer struct {
+	inner *grpc.Server
+}
+
+func newGRPCServer(impl admin.AdminServiceServer, apiCfg config.API) *grpcServer {
+	var opts []grpc.ServerOption
+
+	if apiCfg.Auth.IsEnabled() {
+		auth := newGRPCAuth(apiCfg.Auth)
+
+		opts = append(opts, grpc.UnaryInterceptor(auth.unary), grpc.StreamInterceptor(auth.stream))
+	}
+
+	inner := grpc.NewServer(opts...)
+	admin.RegisterAdminServiceServer(inner, impl)
+
+	return &grpcServer{inner: inner}
+}
+
+func (s *grpcServer) String() string {
+	return "grpc"
+}
+
+func (s *grpcServer) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+
+		s.inner.Stop()
+	}()
+
+	err := s.inner.Serve(l)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *grpcServer) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+
+	go func() {
+		s.inner.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.inner.Stop()
+
+		return ctx.Err()
+	}
+}
+
+func (s *Server) createGRPCInterfaceImpl() (*api.GRPCInterfaceImpl, error) {
+	bControl, err := resolver.GetFromChainWithType[api.BlockingControl](s.queryResolver)
+	if err != nil {
+		return nil, fmt.Errorf("no blocking API implementation found %w", err)
+	}
+
+	refresher, err := resolver.GetFromChainWithType[api.ListRefresher](s.queryResolver)
+	if err != nil {
+		return nil, fmt.Errorf("no refresh API implementation found %w", err)
+	}
+
+	cacheControl, err := resolver.GetFromChainWithType[api.CacheControl](s.queryResolver)
+	if err != nil {
+		return nil, fmt.Errorf("no cache API implementation found %w", err)
+	}
+
+	return api.NewGRPCInterfaceImpl(bControl, s, refresher, cacheControl), nil
+}
+
+// grpcAuth checks the credentials of gRPC calls like the auth middleware of the REST API
+type grpcAuth struct {
+	auth *authenticator
+	cfg  config.APIAuth
+}
+
+func newGRPCAuth(cfg config.APIAuth) *grpcAuth {
+	return &grpcAuth{auth: newAuthenticator(cfg), cfg: cfg}
+}
+
+func (a *grpcAuth) unary(
+	ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+	if err := a.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *grpcAuth) stream(
+	srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	if err := a.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+// check returns a status error if the caller is not allowed to call the method
+func (a *grpcAuth) check(ctx context.Context, method string) error {
+	class, ok := grpcMethodClasses[method]
+	if !ok {
+		class = endpointClassControl
+	}
+
+	required := requiredRole(&a.cfg.Endpoints, class)
+
+	var authorization string
+	if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) != 0 {
+		authorization = values[0]
+	}
+
+	role, err := a.auth.role(ctx, authorization)
+	if err != nil {
+		var remote string
+		if p, ok := peer.FromContext(ctx); ok {
+			remote = p.Addr.String()
+		}
+
+		log.PrefixedLog("api").Debugf("authentication of %s failed: %s", remote, err)
+	}
+
+	switch {
+	case role >= required:
+		return nil
+
+	case authorization == "" || err != nil:
+		return status.Error(codes.Unauthenticated, "missing or invalid credentials")
+
+	default:
+		return status.Error(codes.PermissionDenied, "insufficient role")
+	}
+}
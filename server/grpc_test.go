@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"net"
+
+	"github.com/0xERR0R/blocky/api/admin"
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type adminServiceStub struct {
+	admin.UnimplementedAdminServiceServer
+}
+
+func (adminServiceStub) GetBlockingStatus(context.Context,
+	*admin.GetBlockingStatusRequest,
+) (*admin.BlockingStatus, error) {
+	return &admin.BlockingStatus{Enabled: true}, nil
+}
+
+func (adminServiceStub) WatchBlockingStatus(_ *admin.WatchBlockingStatusRequest,
+	stream admin.AdminService_WatchBlockingStatusServer,
+) error {
+	return stream.Send(&admin.BlockingStatus{Enabled: true})
+}
+
+func (adminServiceStub) Query(context.Context, *admin.QueryRequest) (*admin.QueryResponse, error) {
+	return &admin.QueryResponse{}, nil
+}
+
+func (adminServiceStub) FlushCache(context.Context, *admin.FlushCacheRequest) (*admin.FlushCacheResponse, error) {
+	return &admin.FlushCacheResponse{}, nil
+}
+
+var _ = Describe("gRPC server", func() {
+	var (
+		apiCfg config.API
+		client admin.AdminServiceClient
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		var cancelFn context.CancelFunc
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		apiCfg = config.API{}
+	})
+
+	JustBeforeEach(func() {
+		srv := newGRPCServer(adminServiceStub{}, apiCfg)
+		Expect(srv.String()).Should(Equal("grpc"))
+
+		listener := bufconn.Listen(1024 * 1024)
+
+		serveCtx, stop := context.WithCancel(context.Background())
+		DeferCleanup(stop)
+
+		go func() { _ = srv.Serve(serveCtx, listener) }()
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).Should(Succeed())
+		DeferCleanup(conn.Close)
+
+		client = admin.NewAdminServiceClient(conn)
+	})
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+
+	watch := func(ctx context.Context) error {
+		stream, err := client.WatchBlockingStatus(ctx, &admin.WatchBlockingStatusRequest{})
+		if err != nil {
+			return err
+		}
+
+		_, err = stream.Recv()
+
+		return err
+	}
+
+	When("authentication is disabled", func() {
+		It("should allow all calls", func() {
+			_, err := client.FlushCache(ctx, &admin.FlushCacheRequest{})
+			Expect(err).Should(Succeed())
+
+			Expect(watch(ctx)).Should(Succeed())
+		})
+	})
+
+	When("authentication is enabled", func() {
+		BeforeEach(func() {
+			apiCfg.Auth = config.APIAuth{
+				Tokens: []config.APIToken{
+					{Name: "ci", Token: "admin-token", Role: config.APIRoleAdmin},
+					{Name: "grafana", Token: "read-token", Role: config.APIRoleReadOnly},
+				},
+				Endpoints: config.APIEndpointRoles{
+					Read:    config.APIRoleReadOnly,
+					Query:   config.APIRoleNone,
+					Control: config.APIRoleAdmin,
+				},
+			}
+		})
+
+		It("should reject calls without credentials", func() {
+			_, err := client.GetBlockingStatus(ctx, &admin.GetBlockingStatusRequest{})
+			Expect(status.Code(err)).Should(Equal(codes.Unauthenticated))
+
+			Expect(status.Code(watch(ctx))).Should(Equal(codes.Unauthenticated))
+		})
+
+		It("should reject unknown tokens", func() {
+			_, err := client.GetBlockingStatus(withToken("wrong"), &admin.GetBlockingStatusRequest{})
+			Expect(status.Code(err)).Should(Equal(codes.Unauthenticated))
+		})
+
+		It("should allow public endpoint classes", func() {
+			_, err := client.Query(ctx, &admin.QueryRequest{})
+			Expect(err).Should(Succeed())
+		})
+
+		It("should check the role of the token", func() {
+			_, err := client.GetBlockingStatus(withToken("read-token"), &admin.GetBlockingStatusRequest{})
+			Expect(err).Should(Succeed())
+
+			Expect(watch(withToken("read-token"))).Should(Succeed())
+
+			_, err = client.FlushCache(withToken("read-token"), &admin.FlushCacheRequest{})
+			Expect(status.Code(err)).Should(Equal(codes.PermissionDenied))
+
+			_, err = client.FlushCache(withToken("admin-token"), &admin.FlushCacheRequest{})
+			Expect(err).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,405 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/lists"
+	"github.com/0xERR0R/blocky/resolver"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miekg/dns"
+)
+
+const (
+	pathHealthz = "/healthz"
+	pathReadyz  = "/readyz"
+
+	healthCheckName    = "healthcheck.blocky."
+	healthCheckTimeout = 2 * time.Second
+
+	jsonContentType = "application/json"
+)
+
+// readiness is the state of the instance and its dependencies, returned by the readiness endpoint
+type readiness struct {
+	// True if all listeners answer, the sync backend is reachable and each upstream group has a healthy upstream
+	Ready     bool                `json:"ready"`
+	Upstreams []upstreamReadiness `json:"upstreams"`
+	Sync      *syncReadiness      `json:"sync,omitempty"`
+	Lists     []listReadiness     `json:"lists"`
+	Listeners []listenerReadiness `json:"listeners"`
+}
+
+type upstreamReadiness struct {
+	Group    string `json:"group"`
+	Upstream string `json:"upstream"`
+	Healthy  bool   `json:"healthy"`
+}
+
+// syncReadiness is the connectivity of the backend which synchronizes the instances (redis, NATS or cluster)
+type syncReadiness struct {
+	Backend   string `json:"backend"`
+	Connected bool   `json:"connected"`
+	Error     string `json:"error,omitempty"`
+}
+
+type listReadiness struct {
+	Type        string    `json:"type"`
+	Group       string    `json:"group"`
+	Entries     int       `json:"entries"`
+	LastRefresh time.Time `json:"lastRefresh"`
+	// Seconds since the last refresh
+	Age int64 `json:"age"`
+}
+
+// listenerReadiness is the result of a query of the instance to itself over the listener
+type listenerReadiness struct {
+	Protocol string `json:"protocol"`
+	Address  string `json:"address"`
+	Healthy  bool   `json:"healthy"`
+	// Duration of the query in milliseconds
+	Latency int64  `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// listRefreshes records the latest refresh of each list group
+type listRefreshes struct {
+	lock      sync.RWMutex
+	refreshes map[string]listReadiness
+}
+
+func newListRefreshes(ctx context.Context) (*listRefreshes, error) {
+	r := &listRefreshes{refreshes: make(map[string]listReadiness)}
+
+	err := evt.Bus().Subscribe(evt.BlockingCacheGroupChanged,
+		func(listType lists.ListCacheType, group string, count int) {
+			if ctx.Err() != nil {
+				return
+			}
+
+			r.lock.Lock()
+			defer r.lock.Unlock()
+
+			r.refreshes[listType.String()+":"+group] = listReadiness{
+				Type:        listType.String(),
+				Group:       group,
+				Entries:     count,
+				LastRefresh: time.Now(),
+			}
+		})
+
+	return r, err
+}
+
+// get returns the refreshes ordered by type and group
+func (r *listRefreshes) get(now time.Time) []listReadiness {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	res := make([]listReadiness, 0, len(r.refreshes))
+
+	for _, refresh := range r.refreshes {
+		refresh.Age = int64(now.Sub(refresh.LastRefresh).Seconds())
+
+		res = append(res, refresh)
+	}
+
+	slices.SortFunc(res, func(a, b listReadiness) int {
+		return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Group, b.Group))
+	})
+
+	return res
+}
+
+// registerHealthEndpoints registers the liveness and readiness endpoints
+func (s *Server) registerHealthEndpoints(router *chi.Mux) error {
+	upstreams, err := resolver.GetFromChainWithType[api.UpstreamStatusProvider](s.queryResolver)
+	if err != nil {
+		return fmt.Errorf("no upstream status implementation found %w", err)
+	}
+
+	router.Get(pathHealthz, func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte("OK"))
+	})
+
+	router.Get(pathReadyz, func(rw http.ResponseWriter, req *http.Request) {
+		status := s.readiness(req.Context(), upstreams)
+
+		rw.Header().Set(contentTypeHeader, jsonContentType)
+
+		if !status.Ready {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		err := json.NewEncoder(rw).Encode(status)
+		logAndResponseWithError(err, "can't write readiness: ", rw)
+	})
+
+	return nil
+}
+
+// readiness checks the dependencies and queries each listener
+func (s *Server) readiness(ctx context.Context, upstreams api.UpstreamStatusProvider) *readiness {
+	res := &readiness{
+		Upstreams: checkUpstreams(upstreams),
+		Sync:      s.checkSync(ctx),
+		Lists:     s.listRefreshes.get(time.Now()),
+		Listeners: s.checkListeners(ctx),
+	}
+
+	res.Ready = upstreamGroupsHealthy(res.Upstreams) &&
+		(res.Sync == nil || res.Sync.Connected) &&
+		!slices.ContainsFunc(res.Listeners, func(l listenerReadiness) bool { return !l.Healthy })
+
+	return res
+}
+
+func checkUpstreams(upstreams api.UpstreamStatusProvider) []upstreamReadiness {
+	status := upstreams.UpstreamStatus()
+	res := make([]upstreamReadiness, 0, len(status))
+
+	for _, u := range status {
+		res = append(res, upstreamReadiness{Group: u.Group, Upstream: u.Upstream, Healthy: u.Healthy})
+	}
+
+	return res
+}
+
+// upstreamGroupsHealthy returns true if each group has at least one healthy upstream
+func upstreamGroupsHealthy(upstreams []upstreamReadiness) bool {
+	healthy := make(map[string]bool)
+
+	for _, u := range upstreams {
+		healthy[u.Group] = healthy[u.Group] || u.Healthy
+	}
+
+	for _, h := range healthy {
+		if !h {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkSync returns nil if no sync backend is configured
+func (s *Server) checkSync(ctx context.Context) *syncReadiness {
+	var res syncReadiness
+
+	switch {
+	case s.cfg.Redis.IsEnabled():
+		res.Backend = "redis"
+	case s.cfg.NATS.IsEnabled():
+		res.Backend = "nats"
+	case s.cfg.Cluster.IsEnabled():
+		res.Backend = "cluster"
+	default:
+		return nil
+	}
+
+	if s.syncClient == nil {
+		res.Error = "not connected"
+
+		return &res
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if err := s.syncClient.Ping(ctx); err != nil {
+		res.Error = err.Error()
+
+		return &res
+	}
+
+	res.Connected = true
+
+	return &res
+}
+
+// checkListeners sends a query to each DNS listener and to each DoH endpoint
+func (s *Server) checkListeners(ctx context.Context) []listenerReadiness {
+	var (
+		wg  sync.WaitGroup
+		res []listenerReadiness
+		mu  sync.Mutex
+	)
+
+	check := func(protocol, address string, query func(ctx context.Context, msg *dns.Msg) error) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result := selfQuery(ctx, protocol, address, query)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			res = append(res, result)
+		}()
+	}
+
+	for _, srv := range s.dnsServers {
+		address := srv.Addr
+		client := &dns.Client{Net: srv.Net, Timeout: healthCheckTimeout}
+
+		switch srv.Net {
+		case "unix":
+			address = srv.Listener.Addr().String()
+		case "tcp-tls":
+			// the certificate is not issued for the loopback address
+			client.TLSConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+		}
+
+		check(srv.Net, address, func(ctx context.Context, msg *dns.Msg) error {
+			return exchangeDNS(ctx, client, selfCheckAddress(srv.Net, address), msg)
+		})
+	}
+
+	if s.cfg.DoH.IsEnabled() {
+		for listener, srv := range s.servers {
+			scheme := srv.String()
+			if scheme != "http" && scheme != "https" {
+				continue
+			}
+
+			url := fmt.Sprintf("%s://%s%s", scheme, selfCheckAddress("tcp", listener.Addr().String()), s.cfg.DoH.Path)
+
+			check("doh", listener.Addr().String(), func(ctx context.Context, msg *dns.Msg) error {
+				return exchangeDoH(ctx, url, msg)
+			})
+		}
+	}
+
+	wg.Wait()
+
+	slices.SortFunc(res, func(a, b listenerReadiness) int {
+		return cmp.Or(cmp.Compare(a.Protocol, b.Protocol), cmp.Compare(a.Address, b.Address))
+	})
+
+	return res
+}
+
+func selfQuery(
+	ctx context.Context, protocol, address string, query func(ctx context.Context, msg *dns.Msg) error,
+) listenerReadiness {
+	msg := new(dns.Msg)
+	msg.SetQuestion(healthCheckName, dns.TypeA)
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := query(ctx, msg)
+
+	res := listenerReadiness{
+		Protocol: protocol,
+		Address:  address,
+		Healthy:  err == nil,
+		Latency:  time.Since(start).Milliseconds(),
+	}
+
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+func exchangeDNS(ctx context.Context, client *dns.Client, address string, msg *dns.Msg) error {
+	resp, _, err := client.ExchangeContext(ctx, msg, address)
+	if err != nil {
+		return err
+	}
+
+	return checkRcode(resp)
+}
+
+func exchangeDoH(ctx context.Context, url string, msg *dns.Msg) error {
+	packed, err := msg.Pack()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(packed))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(contentTypeHeader, dnsContentType)
+
+	client := http.Client{
+		Transport: &http.Transport{
+			// the certificate is not issued for the loopback address
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		},
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %s", httpResp.Status)
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+
+	resp := new(dns.Msg)
+	if err := resp.Unpack(body); err != nil {
+		return err
+	}
+
+	return checkRcode(resp)
+}
+
+func checkRcode(resp *dns.Msg) error {
+	if resp.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("response code %s", dns.RcodeToString[resp.Rcode])
+	}
+
+	return nil
+}
+
+// selfCheckAddress returns the address to reach the listener, the loopback address for listeners on all addresses
+func selfCheckAddress(network, address string) string {
+	if network == "unix" {
+		return address
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	ip := net.ParseIP(host)
+
+	switch {
+	case host == "", ip != nil && ip.To4() != nil && ip.IsUnspecified():
+		host = "127.0.0.1"
+	case ip != nil && ip.IsUnspecified():
+		host = "::1"
+	}
+
+	return net.JoinHostPort(host, port)
+}
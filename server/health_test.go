@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/lists"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Health endpoints", func() {
+	Describe("liveness", func() {
+		It("should return OK", func() {
+			resp, err := http.Get(baseURL + "healthz")
+			Expect(err).Should(Succeed())
+			DeferCleanup(resp.Body.Close)
+
+			Expect(resp).Should(SatisfyAll(
+				HaveHTTPStatus(http.StatusOK),
+				HaveHTTPBody("OK"),
+			))
+		})
+	})
+
+	Describe("readiness", func() {
+		It("should return the state of the dependencies and listeners", func() {
+			resp, err := http.Get(baseURL + "readyz")
+			Expect(err).Should(Succeed())
+			DeferCleanup(resp.Body.Close)
+
+			Expect(resp).Should(SatisfyAll(
+				HaveHTTPStatus(http.StatusOK),
+				HaveHTTPHeaderWithValue("Content-Type", "application/json"),
+			))
+
+			var status readiness
+
+			Expect(json.NewDecoder(resp.Body).Decode(&status)).Should(Succeed())
+
+			Expect(status.Ready).Should(BeTrue())
+			Expect(status.Sync).Should(BeNil())
+			Expect(status.Upstreams).Should(ContainElement(SatisfyAll(
+				HaveField("Group", "default"),
+				HaveField("Healthy", true),
+			)))
+			Expect(status.Lists).Should(ContainElement(SatisfyAll(
+				HaveField("Type", "denylist"),
+				HaveField("Group", "ads"),
+			)))
+
+			protocols := make([]string, 0, len(status.Listeners))
+			for _, l := range status.Listeners {
+				Expect(l.Healthy).Should(BeTrue(), l.Error)
+
+				protocols = append(protocols, l.Protocol)
+			}
+
+			Expect(protocols).Should(ConsistOf("doh", "doh", "tcp", "tcp-tls", "udp", "unix"))
+		})
+	})
+
+	Describe("list refreshes", func() {
+		It("should record the latest refresh of each group", func(ctx context.Context) {
+			sut, err := newListRefreshes(ctx)
+			Expect(err).Should(Succeed())
+
+			evt.Bus().Publish(evt.BlockingCacheGroupChanged, lists.ListCacheTypeDenylist, "ads", 10)
+			evt.Bus().Publish(evt.BlockingCacheGroupChanged, lists.ListCacheTypeAllowlist, "ads", 3)
+			evt.Bus().Publish(evt.BlockingCacheGroupChanged, lists.ListCacheTypeDenylist, "ads", 12)
+
+			refreshes := sut.get(time.Now().Add(time.Minute))
+			Expect(refreshes).Should(HaveLen(2))
+
+			Expect(refreshes[0].Type).Should(Equal("allowlist"))
+			Expect(refreshes[1].Type).Should(Equal("denylist"))
+			Expect(refreshes[1].Group).Should(Equal("ads"))
+			Expect(refreshes[1].Entries).Should(Equal(12))
+			Expect(refreshes[1].Age).Should(BeNumerically("~", 60, 1))
+		})
+	})
+
+	Describe("upstreamGroupsHealthy", func() {
+		It("should require a healthy upstream in each group", func() {
+			upstreams := []upstreamReadiness{
+				{Group: "default", Upstream: "a", Healthy: false},
+				{Group: "default", Upstream: "b", Healthy: true},
+				{Group: "laptop", Upstream: "c", Healthy: true},
+			}
+
+			Expect(upstreamGroupsHealthy(upstreams)).Should(BeTrue())
+
+			upstreams[2].Healthy = false
+
+			Expect(upstreamGroupsHealthy(upstreams)).Should(BeFalse())
+		})
+	})
+
+	Describe("checkSync", func() {
+		It("should not be connected if the backend wasn't reachable at startup", func(ctx context.Context) {
+			s := &Server{cfg: &config.Config{Redis: config.Redis{Address: "localhost:6379"}}}
+
+			Expect(s.checkSync(ctx)).Should(Equal(&syncReadiness{Backend: "redis", Error: "not connected"}))
+		})
+
+		It("should be nil without backend", func(ctx context.Context) {
+			s := &Server{cfg: &config.Config{}}
+
+			Expect(s.checkSync(ctx)).Should(BeNil())
+		})
+	})
+
+	Describe("selfCheckAddress", func() {
+		It("should use the loopback address for listeners on all addresses", func() {
+			Expect(selfCheckAddress("udp", ":53")).Should(Equal("127.0.0.1:53"))
+			Expect(selfCheckAddress("tcp", "0.0.0.0:53")).Should(Equal("127.0.0.1:53"))
+			Expect(selfCheckAddress("tcp", "[::]:853")).Should(Equal("[::1]:853"))
+			Expect(selfCheckAddress("tcp", "192.168.178.2:53")).Should(Equal("192.168.178.2:53"))
+			Expect(selfCheckAddress("unix", "/run/blocky.sock")).Should(Equal("/run/blocky.sock"))
+		})
+	})
+})
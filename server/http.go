@@ -2,11 +2,17 @@ package server
 
 import (
 	"context"
+	"math"
 	"net"
 	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 )
@@ -29,7 +35,7 @@ func newHTTPServer(name string, handler http.Handler, cfg *config.Config) *httpS
 			ReadTimeout:       time.Duration(readTimeout),
 			ReadHeaderTimeout: time.Duration(readHeaderTimeout),
 			WriteTimeout:      time.Duration(writeTimeout),
-			Handler:           withCommonMiddleware(handler),
+			Handler:           withCommonMiddleware(handler, cfg.API),
 		},
 
 		name: name,
@@ -50,7 +56,11 @@ func (s *httpServer) Serve(ctx context.Context, l net.Listener) error {
 	return s.inner.Serve(l)
 }
 
-func withCommonMiddleware(inner http.Handler) *chi.Mux {
+func (s *httpServer) Shutdown(ctx context.Context) error {
+	return s.inner.Shutdown(ctx)
+}
+
+func withCommonMiddleware(inner http.Handler, apiCfg config.API) *chi.Mux {
 	// Middleware must be defined before routes, so
 	// create a new router and mount the inner handler
 	mux := chi.NewMux()
@@ -60,6 +70,12 @@ func withCommonMiddleware(inner http.Handler) *chi.Mux {
 		newCORSMiddleware(),
 	)
 
+	mux.Use(newLimitsMiddlewares(apiCfg)...)
+
+	if apiCfg.Auth.IsEnabled() {
+		mux.Use(newAuthMiddleware(apiCfg.Auth))
+	}
+
 	mux.Mount("/", inner)
 
 	return mux
@@ -94,3 +110,113 @@ func newCORSMiddleware() httpMiddleware {
 
 	return cors.New(options).Handler
 }
+
+// newLimitsMiddlewares returns the middlewares protecting the process from HTTP clients
+// sending too many, too many parallel or too large requests.
+func newLimitsMiddlewares(cfg config.API) []httpMiddleware {
+	var res []httpMiddleware
+
+	if cfg.RateLimit.IsEnabled() {
+		res = append(res, newRateLimitMiddleware(cfg.RateLimit, cfg.TrustedPrefixes()))
+	}
+
+	if cfg.MaxConcurrentRequests > 0 {
+		res = append(res, newConcurrencyLimitMiddleware(cfg.MaxConcurrentRequests))
+	}
+
+	if cfg.MaxBodySize > 0 {
+		res = append(res, newBodySizeLimitMiddleware(cfg.MaxBodySize))
+	}
+
+	return res
+}
+
+func newRateLimitMiddleware(cfg config.RateLimit, trustedProxies []netip.Prefix) httpMiddleware {
+	limiter := util.NewKeyedRateLimiter(float64(cfg.Rate), cfg.EffectiveBurst())
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter := limiter.Allow(rateLimitKey(r, trustedProxies))
+			if !allowed {
+				w.Header().Set("retry-after", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey returns the IP of the connection. Only for connections of trusted proxies, it is the last address
+// in `X-Forwarded-For` which isn't a trusted proxy itself: the addresses before it can be forged by the client.
+func rateLimitKey(r *http.Request, trustedProxies []netip.Prefix) string {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		// e.g. unix sockets
+		return r.RemoteAddr
+	}
+
+	isTrusted := func(addr netip.Addr) bool {
+		return slices.ContainsFunc(trustedProxies, func(prefix netip.Prefix) bool {
+			return prefix.Contains(addr.Unmap())
+		})
+	}
+
+	client := addrPort.Addr()
+	if !isTrusted(client) {
+		return client.Unmap().String()
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+
+		client = addr
+
+		if !isTrusted(addr) {
+			break
+		}
+	}
+
+	return client.Unmap().String()
+}
+
+func newConcurrencyLimitMiddleware(maxConcurrent uint) httpMiddleware {
+	slots := make(chan struct{}, maxConcurrent)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+
+				next.ServeHTTP(w, r)
+			default:
+				// don't queue requests: waiting clients would keep holding connections
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+func newBodySizeLimitMiddleware(maxSize int64) httpMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxSize {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// http3Server serves HTTP/3 (QUIC) on the UDP side of the HTTPS ports.
+type http3Server struct {
+	inner http3.Server
+}
+
+func newHTTP3Server(handler http.Handler, tlsCfg *tls.Config, cfg *config.Config) *http3Server {
+	return &http3Server{
+		inner: http3.Server{
+			Handler:   withCommonMiddleware(handler, cfg.API),
+			TLSConfig: http3.ConfigureTLSConfig(tlsCfg),
+		},
+	}
+}
+
+func (s *http3Server) String() string {
+	return "http3"
+}
+
+func (s *http3Server) Serve(ctx context.Context, conn net.PacketConn) error {
+	go func() {
+		<-ctx.Done()
+
+		s.inner.Close()
+	}()
+
+	return s.inner.Serve(conn)
+}
+
+// altSvcMiddleware advertises HTTP/3 to clients connecting via HTTP/1.1 or HTTP/2,
+// so they can upgrade on the next request.
+func (s *http3Server) altSvcMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && r.ProtoMajor < 3 {
+			// fails only if no listener is running yet: nothing to advertise
+			_ = s.inner.SetQUICHeaders(w.Header())
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newUDPListeners(proto string, addresses config.ListenConfig, sockets *sockets) ([]net.PacketConn, error) {
+	conns := make([]net.PacketConn, 0, len(addresses))
+
+	for _, address := range addresses {
+		conn, err := sockets.listenPacket(address)
+		if err != nil {
+			return nil, fmt.Errorf("start %s listener on %s failed: %w", proto, address, err)
+		}
+
+		conns = append(conns, conn)
+	}
+
+	return conns, nil
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	authRealm    = `Basic realm="blocky", charset="UTF-8"`
+	bearerScheme = "Bearer"
+
+	endpointClassRead    = "read"
+	endpointClassQuery   = "query"
+	endpointClassControl = "control"
+)
+
+// endpointClass returns the class of the endpoint which determines the required role,
+// false if the endpoint is public. Like the gRPC methods, API endpoints which aren't known to be
+// read or query endpoints are control endpoints, especially all which don't use GET or HEAD.
+func endpointClass(r *http.Request) (string, bool) {
+	path := r.URL.Path
+
+	switch {
+	case strings.HasPrefix(path, "/debug/") || path == "/debug":
+		return endpointClassControl, true
+
+	case !strings.HasPrefix(path, "/api/"):
+		return "", false
+
+	case path == "/api/query", path == "/api/query/trace":
+		return endpointClassQuery, true
+
+	// the webhook reads the records via GET, they are managed by ExternalDNS only
+	case strings.HasPrefix(path, pathExternalDNS),
+		r.Method != http.MethodGet && r.Method != http.MethodHead,
+		path == "/api/blocking/enable",
+		path == "/api/blocking/disable":
+		return endpointClassControl, true
+	}
+
+	return endpointClassRead, true
+}
+
+// requiredRole returns the minimal role for the endpoint class
+func requiredRole(cfg *config.APIEndpointRoles, class string) config.APIRole {
+	switch class {
+	case endpointClassQuery:
+		return cfg.Query
+	case endpointClassControl:
+		return cfg.Control
+	default:
+		return cfg.Read
+	}
+}
+
+// authenticator determines the role of API clients by their credentials
+type authenticator struct {
+	cfg config.APIAuth
+
+	oidcLock     sync.Mutex
+	oidcVerifier *oidc.IDTokenVerifier
+}
+
+func newAuthenticator(cfg config.APIAuth) *authenticator {
+	return &authenticator{cfg: cfg}
+}
+
+// role returns the role of the client, an error if it sent invalid credentials and
+// `config.APIRoleNone` without error if it sent none
+func (a *authenticator) role(ctx context.Context, authorization string) (config.APIRole, error) {
+	if authorization == "" {
+		return config.APIRoleNone, nil
+	}
+
+	scheme, credentials, _ := strings.Cut(authorization, " ")
+
+	switch {
+	case strings.EqualFold(scheme, bearerScheme):
+		return a.bearerRole(ctx, strings.TrimSpace(credentials))
+
+	case strings.EqualFold(scheme, "Basic"):
+		req := http.Request{Header: http.Header{"Authorization": []string{authorization}}}
+
+		username, password, ok := req.BasicAuth()
+		if !ok {
+			return config.APIRoleNone, errors.New("invalid basic authentication")
+		}
+
+		return a.userRole(username, password)
+	}
+
+	return config.APIRoleNone, fmt.Errorf("unsupported authentication scheme '%s'", log.EscapeInput(scheme))
+}
+
+func (a *authenticator) bearerRole(ctx context.Context, token string) (config.APIRole, error) {
+	for _, t := range a.cfg.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			return t.Role, nil
+		}
+	}
+
+	if !a.cfg.OIDC.IsEnabled() {
+		return config.APIRoleNone, errors.New("unknown token")
+	}
+
+	return a.oidcRole(ctx, token)
+}
+
+func (a *authenticator) userRole(username, password string) (config.APIRole, error) {
+	for _, u := range a.cfg.Users {
+		if u.Username != username {
+			continue
+		}
+
+		if passwordMatches(u.Password, password) {
+			return u.Role, nil
+		}
+
+		break
+	}
+
+	return config.APIRoleNone, errors.New("invalid username or password")
+}
+
+// passwordMatches compares the password with a bcrypt hash or a plain text password
+func passwordMatches(expected, password string) bool {
+	if strings.HasPrefix(expected, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(expected), []byte(password)) == nil
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(password)) == 1
+}
+
+// oidcRole validates the token and returns the highest role mapped from its role claim
+func (a *authenticator) oidcRole(ctx context.Context, rawToken string) (config.APIRole, error) {
+	verifier, err := a.verifier(ctx)
+	if err != nil {
+		return config.APIRoleNone, err
+	}
+
+	token, err := verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return config.APIRoleNone, fmt.Errorf("invalid token: %w", err)
+	}
+
+	var claims map[string]any
+	if err := token.Claims(&claims); err != nil {
+		return config.APIRoleNone, fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	role := config.APIRoleNone
+
+	for _, value := range claimValues(claims[a.cfg.OIDC.RoleClaim]) {
+		if mapped, ok := a.cfg.OIDC.Roles[value]; ok && mapped > role {
+			role = mapped
+		}
+	}
+
+	return role, nil
+}
+
+// verifier discovers the OIDC provider on first use, so it doesn't need to be reachable on start
+func (a *authenticator) verifier(ctx context.Context) (*oidc.IDTokenVerifier, error) {
+	a.oidcLock.Lock()
+	defer a.oidcLock.Unlock()
+
+	if a.oidcVerifier != nil {
+		return a.oidcVerifier, nil
+	}
+
+	// the provider keeps the context to refresh its keys, so don't use the request context
+	provider, err := oidc.NewProvider(context.WithoutCancel(ctx), a.cfg.OIDC.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("can't discover OIDC provider: %w", err)
+	}
+
+	a.oidcVerifier = provider.Verifier(&oidc.Config{
+		ClientID:          a.cfg.OIDC.Audience,
+		SkipClientIDCheck: a.cfg.OIDC.Audience == "",
+	})
+
+	return a.oidcVerifier, nil
+}
+
+// claimValues returns the values of a string or string list claim
+func claimValues(claim any) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		res := make([]string, 0, len(v))
+
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				res = append(res, s)
+			}
+		}
+
+		return res
+	}
+
+	return nil
+}
+
+func newAuthMiddleware(cfg config.APIAuth) httpMiddleware {
+	auth := newAuthenticator(cfg)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class, protected := endpointClass(r)
+			if !protected {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			required := requiredRole(&cfg.Endpoints, class)
+
+			authorization := r.Header.Get("Authorization")
+
+			role, err := auth.role(r.Context(), authorization)
+			if err != nil {
+				log.PrefixedLog("api").Debugf("authentication of %s failed: %s", r.RemoteAddr, err)
+			}
+
+			switch {
+			case role >= required:
+				next.ServeHTTP(w, r)
+
+			case authorization == "" || err != nil:
+				if len(cfg.Users) != 0 {
+					w.Header().Add("www-authenticate", authRealm)
+				}
+
+				w.Header().Add("www-authenticate", bearerScheme)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			default:
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/externaldns"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-jose/go-jose/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = Describe("HTTP authentication", func() {
+	var (
+		authCfg config.APIAuth
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		authCfg = config.APIAuth{
+			Tokens: []config.APIToken{
+				{Name: "ci", Token: "admin-token", Role: config.APIRoleAdmin},
+				{Name: "grafana", Token: "read-token", Role: config.APIRoleReadOnly},
+			},
+			Users: []config.APIUser{
+				{Username: "plain", Password: "secret", Role: config.APIRoleAdmin},
+			},
+			Endpoints: config.APIEndpointRoles{
+				Read:    config.APIRoleReadOnly,
+				Query:   config.APIRoleReadOnly,
+				Control: config.APIRoleAdmin,
+			},
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("hashed-secret"), bcrypt.MinCost)
+		Expect(err).Should(Succeed())
+
+		authCfg.Users = append(authCfg.Users,
+			config.APIUser{Username: "hashed", Password: string(hash), Role: config.APIRoleReadOnly})
+	})
+
+	JustBeforeEach(func() {
+		handler = withCommonMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}), config.API{Auth: authCfg})
+	})
+
+	serve := func(method, path string, modify func(r *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if modify != nil {
+			modify(req)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	withBearer := func(token string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	withBasic := func(username, password string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.SetBasicAuth(username, password)
+		}
+	}
+
+	Describe("public endpoints", func() {
+		It("should not require authentication", func() {
+			Expect(serve(http.MethodGet, "/", nil).Code).Should(Equal(http.StatusOK))
+			Expect(serve(http.MethodGet, "/ui/", nil).Code).Should(Equal(http.StatusOK))
+			Expect(serve(http.MethodGet, "/dns-query?dns=abc", nil).Code).Should(Equal(http.StatusOK))
+		})
+	})
+
+	Describe("without credentials", func() {
+		It("should reject API requests", func() {
+			rec := serve(http.MethodGet, "/api/blocking/status", nil)
+
+			Expect(rec.Code).Should(Equal(http.StatusUnauthorized))
+			Expect(rec.Header().Values("www-authenticate")).Should(ContainElements(
+				ContainSubstring("Basic"),
+				"Bearer",
+			))
+
+			Expect(serve(http.MethodGet, "/debug/pprof/", nil).Code).Should(Equal(http.StatusUnauthorized))
+		})
+
+		When("an endpoint class is public", func() {
+			BeforeEach(func() {
+				authCfg.Endpoints.Read = config.APIRoleNone
+			})
+
+			It("should allow its endpoints only", func() {
+				Expect(serve(http.MethodGet, "/api/blocking/status", nil).Code).Should(Equal(http.StatusOK))
+				Expect(serve(http.MethodGet, "/api/blocking/disable", nil).Code).Should(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+
+	Describe("static tokens", func() {
+		It("should grant the role of the token", func() {
+			Expect(serve(http.MethodGet, "/api/blocking/status", withBearer("read-token")).Code).
+				Should(Equal(http.StatusOK))
+			Expect(serve(http.MethodPost, "/api/query", withBearer("read-token")).Code).
+				Should(Equal(http.StatusOK))
+			Expect(serve(http.MethodGet, "/api/blocking/disable", withBearer("read-token")).Code).
+				Should(Equal(http.StatusForbidden))
+			Expect(serve(http.MethodDelete, "/api/cache/entries/example.com", withBearer("read-token")).Code).
+				Should(Equal(http.StatusForbidden))
+			Expect(serve(http.MethodPost, "/api/blocking/allow", withBearer("read-token")).Code).
+				Should(Equal(http.StatusForbidden))
+			Expect(serve(http.MethodGet, "/api/cache/entries", withBearer("read-token")).Code).
+				Should(Equal(http.StatusOK))
+
+			Expect(serve(http.MethodGet, "/api/blocking/disable", withBearer("admin-token")).Code).
+				Should(Equal(http.StatusOK))
+			Expect(serve(http.MethodPost, "/api/snapshots/x/rollback", withBearer("admin-token")).Code).
+				Should(Equal(http.StatusOK))
+		})
+
+		It("should require the control role for the ExternalDNS webhook", func() {
+			Expect(serve(http.MethodGet, "/api/externaldns", nil).Code).
+				Should(Equal(http.StatusUnauthorized))
+			Expect(serve(http.MethodGet, "/api/externaldns/records", withBearer("read-token")).Code).
+				Should(Equal(http.StatusForbidden))
+			Expect(serve(http.MethodPost, "/api/externaldns/records", withBearer("admin-token")).Code).
+				Should(Equal(http.StatusOK))
+		})
+
+		It("should reject unknown tokens", func() {
+			Expect(serve(http.MethodGet, "/api/blocking/status", withBearer("wrong")).Code).
+				Should(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Describe("basic authentication", func() {
+		It("should accept plain and bcrypt hashed passwords", func() {
+			Expect(serve(http.MethodPost, "/api/lists/refresh", withBasic("plain", "secret")).Code).
+				Should(Equal(http.StatusOK))
+			Expect(serve(http.MethodGet, "/api/info", withBasic("hashed", "hashed-secret")).Code).
+				Should(Equal(http.StatusOK))
+			Expect(serve(http.MethodPost, "/api/lists/refresh", withBasic("hashed", "hashed-secret")).Code).
+				Should(Equal(http.StatusForbidden))
+		})
+
+		It("should reject wrong passwords and unknown users", func() {
+			Expect(serve(http.MethodGet, "/api/info", withBasic("plain", "wrong")).Code).
+				Should(Equal(http.StatusUnauthorized))
+			Expect(serve(http.MethodGet, "/api/info", withBasic("unknown", "secret")).Code).
+				Should(Equal(http.StatusUnauthorized))
+		})
+
+		It("should reject unsupported schemes", func() {
+			Expect(serve(http.MethodGet, "/api/info", func(r *http.Request) {
+				r.Header.Set("Authorization", "Digest abc")
+			}).Code).Should(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Describe("OIDC", func() {
+		var (
+			issuer string
+			sign   func(claims map[string]any) string
+		)
+
+		BeforeEach(func() {
+			issuer, sign = newTestOIDCProvider()
+
+			authCfg.OIDC = config.OIDC{
+				Issuer:    issuer,
+				Audience:  "blocky",
+				RoleClaim: "groups",
+				Roles: map[string]config.APIRole{
+					"dns-admins":  config.APIRoleAdmin,
+					"dns-viewers": config.APIRoleReadOnly,
+				},
+			}
+		})
+
+		claims := func(aud string, groups ...string) map[string]any {
+			return map[string]any{
+				"iss":    issuer,
+				"sub":    "user",
+				"aud":    aud,
+				"exp":    time.Now().Add(time.Hour).Unix(),
+				"iat":    time.Now().Unix(),
+				"groups": groups,
+			}
+		}
+
+		It("should grant the highest mapped role of the token", func() {
+			token := sign(claims("blocky", "dns-viewers", "dns-admins"))
+
+			Expect(serve(http.MethodGet, "/api/blocking/disable", withBearer(token)).Code).
+				Should(Equal(http.StatusOK))
+		})
+
+		It("should forbid tokens without mapped role", func() {
+			token := sign(claims("blocky", "other"))
+
+			Expect(serve(http.MethodGet, "/api/info", withBearer(token)).Code).
+				Should(Equal(http.StatusForbidden))
+		})
+
+		It("should reject tokens for another audience", func() {
+			token := sign(claims("other", "dns-admins"))
+
+			Expect(serve(http.MethodGet, "/api/info", withBearer(token)).Code).
+				Should(Equal(http.StatusUnauthorized))
+		})
+
+		It("should reject expired tokens", func() {
+			c := claims("blocky", "dns-admins")
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+
+			Expect(serve(http.MethodGet, "/api/info", withBearer(sign(c))).Code).
+				Should(Equal(http.StatusUnauthorized))
+		})
+
+		It("should still accept static tokens", func() {
+			Expect(serve(http.MethodGet, "/api/info", withBearer("read-token")).Code).
+				Should(Equal(http.StatusOK))
+		})
+	})
+
+	DescribeTable("claimValues",
+		func(claim any, expected []string) {
+			Expect(claimValues(claim)).Should(Equal(expected))
+		},
+		Entry("space separated string", "a b", []string{"a", "b"}),
+		Entry("list", []any{"a", 1, "b"}, []string{"a", "b"}),
+		Entry("missing", nil, nil),
+	)
+
+	Describe("endpoint classes", func() {
+		It("should classify all API routes", func() {
+			router := chi.NewRouter()
+			api.RegisterOpenAPIEndpoints(router, nil)
+			router.Mount(pathExternalDNS, externaldns.NewProvider(config.ExternalDNS{}, 0).Handler())
+
+			expected := map[string]string{
+				"GET /api/blocking/disable":             endpointClassControl,
+				"GET /api/blocking/enable":              endpointClassControl,
+				"POST /api/blocking/allow":              endpointClassControl,
+				"GET /api/blocking/status":              endpointClassRead,
+				"GET /api/blocking/schedule":            endpointClassRead,
+				"POST /api/lists/refresh":               endpointClassControl,
+				"GET /api/lists/export":                 endpointClassRead,
+				"POST /api/lists/import":                endpointClassControl,
+				"POST /api/query":                       endpointClassQuery,
+				"GET /api/query/trace":                  endpointClassQuery,
+				"POST /api/cache/flush":                 endpointClassControl,
+				"GET /api/cache/entries":                endpointClassRead,
+				"DELETE /api/cache/entries/{name}":      endpointClassControl,
+				"GET /api/cache/stats":                  endpointClassRead,
+				"GET /api/info":                         endpointClassRead,
+				"GET /api/config":                       endpointClassRead,
+				"GET /api/queries/recent":               endpointClassRead,
+				"GET /api/queries/stream":               endpointClassRead,
+				"GET /api/stats/overview":               endpointClassRead,
+				"GET /api/stats/topDomains":             endpointClassRead,
+				"GET /api/stats/topClients":             endpointClassRead,
+				"GET /api/stats/topCategories":          endpointClassRead,
+				"GET /api/upstreams/status":             endpointClassRead,
+				"GET /api/snapshots":                    endpointClassRead,
+				"POST /api/snapshots/{name}/rollback":   endpointClassControl,
+				"GET /api/externaldns/":                 endpointClassControl,
+				"GET /api/externaldns/records":          endpointClassControl,
+				"POST /api/externaldns/records":         endpointClassControl,
+				"POST /api/externaldns/adjustendpoints": endpointClassControl,
+			}
+
+			routes := map[string]string{}
+
+			err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+				path := strings.ReplaceAll(route, "{name}", "x")
+
+				class, protected := endpointClass(httptest.NewRequest(method, path, nil))
+				Expect(protected).Should(BeTrue(), route)
+
+				routes[method+" "+route] = class
+
+				return nil
+			})
+			Expect(err).Should(Succeed())
+
+			Expect(routes).Should(Equal(expected))
+		})
+
+		It("should treat unknown methods as control", func() {
+			class, protected := endpointClass(httptest.NewRequest(http.MethodPut, "/api/blocking/status", nil))
+
+			Expect(protected).Should(BeTrue())
+			Expect(class).Should(Equal(endpointClassControl))
+		})
+	})
+})
+
+// newTestOIDCProvider starts an OIDC provider and returns its issuer URL and a function signing tokens
+func newTestOIDCProvider() (string, func(claims map[string]any) string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048) //nolint:mnd
+	Expect(err).Should(Succeed())
+
+	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &key.PublicKey, KeyID: "test", Algorithm: string(jose.RS256), Use: "sig"},
+	}}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	DeferCleanup(srv.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                srv.URL,
+			"jwks_uri":                              srv.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwks)
+	})
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+	Expect(err).Should(Succeed())
+
+	return srv.URL, func(claims map[string]any) string {
+		payload, err := json.Marshal(claims)
+		Expect(err).Should(Succeed())
+
+		signed, err := signer.Sign(payload)
+		Expect(err).Should(Succeed())
+
+		token, err := signed.CompactSerialize()
+		Expect(err).Should(Succeed())
+
+		return token
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTP middlewares", func() {
+	var (
+		apiCfg  config.API
+		handler http.Handler
+		inner   http.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		apiCfg = config.API{}
+		inner = func(w http.ResponseWriter, r *http.Request) {
+			_, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+
+	JustBeforeEach(func() {
+		handler = withCommonMiddleware(inner, apiCfg)
+	})
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.RemoteAddr = "192.168.178.10:12345"
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	When("no limits are configured", func() {
+		It("should pass all requests", func() {
+			for range 10 {
+				Expect(serve(strings.Repeat("a", 10000)).Code).Should(Equal(http.StatusOK))
+			}
+		})
+	})
+
+	When("rate limit is configured", func() {
+		BeforeEach(func() {
+			apiCfg.RateLimit = config.RateLimit{Rate: 1, Burst: 2}
+		})
+
+		It("should reject requests exceeding the burst", func() {
+			Expect(serve("").Code).Should(Equal(http.StatusOK))
+			Expect(serve("").Code).Should(Equal(http.StatusOK))
+
+			rec := serve("")
+			Expect(rec.Code).Should(Equal(http.StatusTooManyRequests))
+			Expect(rec.Header().Get("retry-after")).Should(Equal("1"))
+		})
+
+		serveForwarded := func(forwardedFor string) int {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "192.168.178.10:12345"
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			return rec.Code
+		}
+
+		It("should ignore X-Forwarded-For of untrusted clients", func() {
+			Expect(serveForwarded("10.0.0.1")).Should(Equal(http.StatusOK))
+			Expect(serveForwarded("10.0.0.2")).Should(Equal(http.StatusOK))
+			Expect(serveForwarded("10.0.0.3")).Should(Equal(http.StatusTooManyRequests))
+		})
+
+		When("the client is a trusted proxy", func() {
+			BeforeEach(func() {
+				apiCfg.TrustedProxies = []string{"192.168.178.0/24"}
+			})
+
+			It("should limit the forwarded clients", func() {
+				for range 2 {
+					Expect(serveForwarded("10.0.0.1")).Should(Equal(http.StatusOK))
+					Expect(serveForwarded("10.0.0.2, 192.168.178.11")).Should(Equal(http.StatusOK))
+				}
+
+				Expect(serveForwarded("10.0.0.1")).Should(Equal(http.StatusTooManyRequests))
+			})
+
+			It("should ignore addresses forged by the client", func() {
+				Expect(serveForwarded("10.0.0.1, 10.0.0.9")).Should(Equal(http.StatusOK))
+				Expect(serveForwarded("10.0.0.2, 10.0.0.9")).Should(Equal(http.StatusOK))
+				Expect(serveForwarded("10.0.0.3, 10.0.0.9")).Should(Equal(http.StatusTooManyRequests))
+			})
+		})
+	})
+
+	When("max body size is configured", func() {
+		BeforeEach(func() {
+			apiCfg.MaxBodySize = 10
+		})
+
+		It("should reject too large bodies", func() {
+			Expect(serve("small").Code).Should(Equal(http.StatusOK))
+			Expect(serve("this body is too large").Code).Should(Equal(http.StatusRequestEntityTooLarge))
+		})
+	})
+
+	When("max concurrent requests is configured", func() {
+		var (
+			started chan struct{}
+			release chan struct{}
+		)
+
+		BeforeEach(func() {
+			apiCfg.MaxConcurrentRequests = 1
+
+			started = make(chan struct{})
+			release = make(chan struct{})
+
+			inner = func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				<-release
+				w.WriteHeader(http.StatusOK)
+			}
+		})
+
+		It("should reject requests over the limit", func() {
+			done := make(chan int)
+
+			go func() {
+				defer GinkgoRecover()
+
+				done <- serve("").Code
+			}()
+
+			Eventually(started).Should(BeClosed())
+
+			Expect(serve("").Code).Should(Equal(http.StatusServiceUnavailable))
+
+			close(release)
+			Eventually(done).Should(Receive(Equal(http.StatusOK)))
+		})
+	})
+})
+
+var _ = Describe("HTTP server", func() {
+	It("should finish the active requests on shutdown", func(ctx context.Context) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			close(started)
+			<-release
+
+			_, _ = w.Write([]byte("done"))
+		})
+
+		cfg, err := config.WithDefaults[config.Config]()
+		Expect(err).Should(Succeed())
+
+		sut := newHTTPServer("http", handler, &cfg)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).Should(Succeed())
+
+		go func() { _ = sut.Serve(ctx, listener) }()
+
+		responses := make(chan *http.Response, 1)
+
+		go func() {
+			defer GinkgoRecover()
+
+			resp, err := http.Get("http://" + listener.Addr().String())
+			Expect(err).Should(Succeed())
+
+			responses <- resp
+		}()
+
+		Eventually(started).Should(BeClosed())
+
+		shutdown := make(chan error, 1)
+
+		go func() { shutdown <- sut.Shutdown(ctx) }()
+
+		Consistently(shutdown, "100ms").ShouldNot(Receive())
+
+		close(release)
+
+		Eventually(shutdown).Should(Receive(BeNil()))
+
+		var resp *http.Response
+
+		Eventually(responses).Should(Receive(&resp))
+		Expect(resp).Should(HaveHTTPBody("done"))
+	})
+})
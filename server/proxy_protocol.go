@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net"
+	"net/netip"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/proxyprotocol"
+)
+
+// proxyProtocol wraps the listeners which accept PROXY protocol headers from trusted proxies
+type proxyProtocol struct {
+	cfg     config.ProxyProtocol
+	trusted []netip.Prefix
+}
+
+// newProxyProtocol returns nil if no listener accepts headers
+func newProxyProtocol(cfg config.ProxyProtocol) (*proxyProtocol, error) {
+	if len(cfg.Listeners) == 0 || len(cfg.TrustedProxies) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	trusted, err := cfg.TrustedPrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyProtocol{cfg: cfg, trusted: trusted}, nil
+}
+
+// wrap returns the listener to use for the kind of listener
+func (p *proxyProtocol) wrap(kind config.ProxyProtocolListener, listener net.Listener) net.Listener {
+	if !p.accepts(kind) {
+		return listener
+	}
+
+	return proxyprotocol.NewListener(listener, p.trusted, p.cfg.HeaderTimeout.ToDuration())
+}
+
+func (p *proxyProtocol) accepts(kind config.ProxyProtocolListener) bool {
+	return p != nil && p.cfg.Accepts(kind)
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/proxyprotocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PROXY protocol", func() {
+	var (
+		cfg      config.ProxyProtocol
+		listener net.Listener
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = config.WithDefaults[config.ProxyProtocol]()
+		Expect(err).Should(Succeed())
+
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).Should(Succeed())
+		DeferCleanup(listener.Close)
+	})
+
+	It("should not wrap listeners if disabled", func() {
+		sut, err := newProxyProtocol(cfg)
+		Expect(err).Should(Succeed())
+		Expect(sut).Should(BeNil())
+
+		Expect(sut.wrap(config.ProxyProtocolListenerDns, listener)).Should(BeIdenticalTo(listener))
+	})
+
+	When("listeners accept headers", func() {
+		BeforeEach(func() {
+			cfg.Listeners = []config.ProxyProtocolListener{config.ProxyProtocolListenerTls}
+			cfg.TrustedProxies = []string{"10.0.0.1"}
+		})
+
+		It("should only wrap the configured listeners", func() {
+			sut, err := newProxyProtocol(cfg)
+			Expect(err).Should(Succeed())
+
+			Expect(sut.wrap(config.ProxyProtocolListenerTls, listener)).Should(BeAssignableToTypeOf(&proxyprotocol.Listener{}))
+			Expect(sut.wrap(config.ProxyProtocolListenerDns, listener)).Should(BeIdenticalTo(listener))
+		})
+
+		It("should fail on invalid trusted proxies", func() {
+			cfg.TrustedProxies = []string{"haproxy"}
+
+			_, err := newProxyProtocol(cfg)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/miekg/dns"
+)
+
+const (
+	rateLimitRRL = "rrl"
+
+	rrlCleanupPeriod = time.Minute
+)
+
+// rrlClass groups the responses which are limited together
+type rrlClass uint8
+
+const (
+	rrlClassResponse rrlClass = iota
+	rrlClassNXDomain
+	rrlClassError
+)
+
+// rrlKey identifies the responses to a client prefix which share an account:
+// identical responses, all NXDOMAIN responses or all error responses
+type rrlKey struct {
+	prefix netip.Prefix
+	class  rrlClass
+	name   string
+	qType  uint16
+}
+
+// rrlAccount is the balance of a key: it is credited with the rate per second up to the rate,
+// each response takes one and the balance can drop to -rate * window
+type rrlAccount struct {
+	rate    float64
+	balance float64
+	last    time.Time
+}
+
+// responseRateLimiter implements Response Rate Limiting (RRL) like BIND for UDP responses:
+// a client prefix receiving too many identical responses gets only every n-th response truncated and no others.
+// As the client address of UDP queries can be spoofed, this limits blocky as amplification reflector.
+// If the table of accounts is full, the least recently used account is dropped for a new key.
+type responseRateLimiter struct {
+	cfg     config.RRL
+	exempt  []netip.Prefix
+	slipped atomic.Uint64
+
+	lock        sync.Mutex
+	accounts    *simplelru.LRU
+	lastCleanup time.Time
+}
+
+// newResponseRateLimiter returns nil if RRL is disabled
+func newResponseRateLimiter(cfg config.RRL) (*responseRateLimiter, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil //nolint:nilnil
+	}
+
+	exempt, err := cfg.ExemptPrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := simplelru.NewLRU(int(cfg.MaxTableSize), nil)
+	if err != nil {
+		return nil, fmt.Errorf("rrl.maxTableSize: %w", err)
+	}
+
+	return &responseRateLimiter{
+		cfg:         cfg,
+		exempt:      exempt,
+		accounts:    accounts,
+		lastCleanup: time.Now(),
+	}, nil
+}
+
+// wrap limits the responses of the server if it is an UDP server and returns the handler to use
+func (l *responseRateLimiter) wrap(srv *dns.Server, handler dns.HandlerFunc) dns.HandlerFunc {
+	if l == nil || srv.Net != "udp" {
+		return handler
+	}
+
+	return func(w dns.ResponseWriter, msg *dns.Msg) {
+		clientIP, _ := resolveClientIPAndProtocol(w.RemoteAddr())
+
+		ip, _ := netip.AddrFromSlice(clientIP)
+		ip = ip.Unmap()
+
+		// a valid DNS cookie proves that the client address isn't spoofed
+		if l.isExempt(ip) || hasValidCookie(w) {
+			handler(w, msg)
+
+			return
+		}
+
+		handler(&rrlWriter{ResponseWriter: w, l: l, client: ip, request: msg}, msg)
+	}
+}
+
+func (l *responseRateLimiter) isExempt(ip netip.Addr) bool {
+	for _, prefix := range l.exempt {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allow debits the account of the response and returns false if the response exceeds the rate
+func (l *responseRateLimiter) allow(client netip.Addr, response *dns.Msg, now time.Time) bool {
+	key, rate := l.key(client, response)
+	if rate == 0 {
+		return true
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if now.Sub(l.lastCleanup) > rrlCleanupPeriod {
+		l.cleanup(now)
+	}
+
+	var account *rrlAccount
+
+	if value, ok := l.accounts.Get(key); ok {
+		account = value.(*rrlAccount)
+	} else {
+		account = &rrlAccount{rate: float64(rate), balance: float64(rate), last: now}
+		l.accounts.Add(key, account)
+	}
+
+	return account.debit(l.cfg.Window.ToDuration(), now)
+}
+
+// key returns the key of the response and the rate of its class
+func (l *responseRateLimiter) key(client netip.Addr, response *dns.Msg) (rrlKey, uint) {
+	bits := int(l.cfg.IPv4PrefixLength)
+	if client.Is6() {
+		bits = int(l.cfg.IPv6PrefixLength)
+	}
+
+	prefix, _ := client.Prefix(bits)
+
+	switch response.Rcode {
+	case dns.RcodeSuccess:
+		key := rrlKey{prefix: prefix, class: rrlClassResponse}
+
+		if len(response.Question) > 0 {
+			key.name = strings.ToLower(response.Question[0].Name)
+			key.qType = response.Question[0].Qtype
+		}
+
+		return key, l.cfg.ResponsesPerSecond
+
+	case dns.RcodeNameError:
+		return rrlKey{prefix: prefix, class: rrlClassNXDomain}, l.cfg.NXDomainsPerSecond
+
+	default:
+		return rrlKey{prefix: prefix, class: rrlClassError}, l.cfg.ErrorsPerSecond
+	}
+}
+
+// cleanup removes the accounts which are completely credited again and thus equivalent to new ones
+func (l *responseRateLimiter) cleanup(now time.Time) {
+	for _, key := range l.accounts.Keys() {
+		value, ok := l.accounts.Peek(key)
+		if !ok {
+			continue
+		}
+
+		if account := value.(*rrlAccount); account.balance+now.Sub(account.last).Seconds()*account.rate >= account.rate {
+			l.accounts.Remove(key)
+		}
+	}
+
+	l.lastCleanup = now
+}
+
+func (a *rrlAccount) debit(window time.Duration, now time.Time) bool {
+	if now.After(a.last) {
+		a.balance = min(a.rate, a.balance+now.Sub(a.last).Seconds()*a.rate)
+		a.last = now
+	}
+
+	a.balance = max(a.balance-1, -a.rate*window.Seconds())
+
+	return a.balance >= 0
+}
+
+// rrlWriter applies the response rate limiting to the response
+type rrlWriter struct {
+	dns.ResponseWriter
+
+	l       *responseRateLimiter
+	client  netip.Addr
+	request *dns.Msg
+}
+
+func (w *rrlWriter) Unwrap() dns.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *rrlWriter) WriteMsg(msg *dns.Msg) error {
+	if w.l.allow(w.client, msg, time.Now()) {
+		return w.ResponseWriter.WriteMsg(msg)
+	}
+
+	evt.Bus().Publish(evt.RateLimited, rateLimitRRL)
+
+	if w.l.cfg.LogOnly {
+		logger().Debugf("RRL would limit response to %s for %s", w.client, util.QuestionToString(w.request.Question))
+
+		return w.ResponseWriter.WriteMsg(msg)
+	}
+
+	if slip := uint64(w.l.cfg.Slip); slip == 0 || w.l.slipped.Add(1)%slip != 0 {
+		return nil
+	}
+
+	resp := new(dns.Msg)
+	resp.SetReply(w.request)
+	resp.Truncated = true
+
+	return w.ResponseWriter.WriteMsg(resp)
+}
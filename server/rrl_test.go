@@ -0,0 +1,218 @@
+package server
+
+import (
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response rate limiting", func() {
+	var (
+		cfg     config.RRL
+		sut     *responseRateLimiter
+		srv     *dns.Server
+		rcode   int
+		handler dns.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = config.WithDefaults[config.RRL]()
+		Expect(err).Should(Succeed())
+
+		cfg.ResponsesPerSecond = 2
+		cfg.NXDomainsPerSecond = 2
+		cfg.ErrorsPerSecond = 2
+		cfg.Slip = 0
+
+		srv = &dns.Server{Net: "udp"}
+
+		rcode = dns.RcodeSuccess
+		handler = func(w dns.ResponseWriter, m *dns.Msg) {
+			resp := new(dns.Msg)
+			resp.SetRcode(m, rcode)
+			Expect(w.WriteMsg(resp)).Should(Succeed())
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = newResponseRateLimiter(cfg)
+		Expect(err).Should(Succeed())
+	})
+
+	query := func(ip, question string) *recordingWriter {
+		w := &recordingWriter{remote: &net.UDPAddr{IP: net.ParseIP(ip), Port: 53}}
+
+		sut.wrap(srv, handler)(w, util.NewMsgWithQuestion(question, dns.Type(dns.TypeA)))
+
+		return w
+	}
+
+	It("should drop identical responses exceeding the rate per client prefix", func() {
+		Expect(query("192.0.2.1", "example.com.").written).Should(HaveLen(1))
+		Expect(query("192.0.2.2", "EXAMPLE.com.").written).Should(HaveLen(1))
+		Expect(query("192.0.2.3", "example.com.").written).Should(BeEmpty())
+
+		By("other responses, other prefixes", func() {
+			Expect(query("192.0.2.1", "other.com.").written).Should(HaveLen(1))
+			Expect(query("198.51.100.1", "example.com.").written).Should(HaveLen(1))
+		})
+	})
+
+	It("should limit all NXDOMAIN responses of a prefix together", func() {
+		rcode = dns.RcodeNameError
+
+		Expect(query("192.0.2.1", "a.example.com.").written).Should(HaveLen(1))
+		Expect(query("192.0.2.1", "b.example.com.").written).Should(HaveLen(1))
+		Expect(query("192.0.2.1", "c.example.com.").written).Should(BeEmpty())
+	})
+
+	When("slip is set", func() {
+		BeforeEach(func() {
+			cfg.Slip = 2
+		})
+
+		It("should send every n-th limited response truncated", func() {
+			query("192.0.2.1", "example.com.")
+			query("192.0.2.1", "example.com.")
+
+			Expect(query("192.0.2.1", "example.com.").written).Should(BeEmpty())
+
+			w := query("192.0.2.1", "example.com.")
+			Expect(w.written).Should(HaveLen(1))
+			Expect(w.written[0].Truncated).Should(BeTrue())
+			Expect(w.written[0].Answer).Should(BeEmpty())
+		})
+	})
+
+	When("the client is exempt", func() {
+		BeforeEach(func() {
+			cfg.Exempt = []string{"192.0.2.0/24"}
+		})
+
+		It("should not limit", func() {
+			for range 10 {
+				Expect(query("192.0.2.1", "example.com.").written).Should(HaveLen(1))
+			}
+		})
+	})
+
+	When("the query has a valid DNS cookie", func() {
+		It("should not limit", func() {
+			w := &recordingWriter{remote: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 53}}
+			msg := util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA))
+			msg.SetEdns0(1232, false)
+
+			for range 10 {
+				sut.wrap(srv, handler)(&cookieWriter{ResponseWriter: w, request: msg, verified: true}, msg)
+			}
+
+			Expect(w.written).Should(HaveLen(10))
+		})
+	})
+
+	When("only logging", func() {
+		BeforeEach(func() {
+			cfg.LogOnly = true
+		})
+
+		It("should not limit", func() {
+			for range 10 {
+				Expect(query("192.0.2.1", "example.com.").written).Should(HaveLen(1))
+			}
+		})
+	})
+
+	When("the table is full", func() {
+		BeforeEach(func() {
+			cfg.MaxTableSize = 2
+		})
+
+		It("should still limit responses to a new prefix", func() {
+			query("192.0.2.1", "example.com.")
+			query("192.0.2.1", "other.com.")
+			Expect(sut.accounts.Len()).Should(Equal(2))
+
+			Expect(query("198.51.100.1", "example.com.").written).Should(HaveLen(1))
+			Expect(query("198.51.100.1", "example.com.").written).Should(HaveLen(1))
+			Expect(query("198.51.100.1", "example.com.").written).Should(BeEmpty())
+			Expect(sut.accounts.Len()).Should(Equal(2))
+		})
+
+		It("should drop the least recently used account", func() {
+			now := time.Now()
+			victim := netip.MustParseAddr("198.51.100.1")
+			response := util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA))
+
+			Expect(sut.allow(victim, response, now)).Should(BeTrue())
+			Expect(sut.allow(victim, response, now)).Should(BeTrue())
+
+			for i := range 256 {
+				Expect(sut.allow(netip.AddrFrom4([4]byte{10, 0, byte(i), 1}), response, now)).Should(BeTrue())
+				Expect(sut.allow(victim, response, now)).Should(BeFalse())
+			}
+
+			Expect(sut.accounts.Len()).Should(Equal(2))
+		})
+	})
+
+	When("the server is not an UDP server", func() {
+		BeforeEach(func() {
+			srv = &dns.Server{Net: "tcp"}
+		})
+
+		It("should not limit", func() {
+			for range 10 {
+				Expect(query("192.0.2.1", "example.com.").written).Should(HaveLen(1))
+			}
+		})
+	})
+
+	Describe("accounts", func() {
+		It("should be credited with the rate per second and keep the client limited for the window", func() {
+			now := time.Now()
+			client := netip.MustParseAddr("2001:db8::1")
+			response := util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA))
+
+			Expect(sut.allow(client, response, now)).Should(BeTrue())
+			Expect(sut.allow(client, response, now)).Should(BeTrue())
+			Expect(sut.allow(client, response, now)).Should(BeFalse())
+			Expect(sut.allow(client, response, now.Add(time.Second))).Should(BeTrue())
+
+			// flood: the balance drops to -rate * window
+			for range 100 {
+				sut.allow(client, response, now.Add(time.Second))
+			}
+
+			Expect(sut.allow(client, response, now.Add(10*time.Second))).Should(BeFalse())
+			Expect(sut.allow(client, response, now.Add(17*time.Second))).Should(BeTrue())
+
+			// the same /56 shares the account
+			Expect(sut.allow(netip.MustParseAddr("2001:db8:0:ff::1"), response, now.Add(17*time.Second))).
+				Should(BeFalse())
+			Expect(sut.allow(netip.MustParseAddr("2001:db8:0:100::1"), response, now.Add(17*time.Second))).
+				Should(BeTrue())
+		})
+
+		It("should be removed when they are credited completely", func() {
+			now := time.Now()
+			response := util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA))
+
+			sut.allow(netip.MustParseAddr("192.0.2.1"), response, now)
+			Expect(sut.accounts.Len()).Should(Equal(1))
+
+			sut.allow(netip.MustParseAddr("198.51.100.1"), response, now.Add(2*rrlCleanupPeriod))
+			Expect(sut.accounts.Len()).Should(Equal(1))
+		})
+	})
+})
@@ -10,16 +10,28 @@ import (
 	"runtime"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/0xERR0R/blocky/acme"
+	"github.com/0xERR0R/blocky/cachesync"
+	"github.com/0xERR0R/blocky/cluster"
 	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/dnsupdate"
+	"github.com/0xERR0R/blocky/events"
+	"github.com/0xERR0R/blocky/externaldns"
+	"github.com/0xERR0R/blocky/geoip"
 	"github.com/0xERR0R/blocky/log"
 	"github.com/0xERR0R/blocky/metrics"
 	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/nats"
 	"github.com/0xERR0R/blocky/redis"
 	"github.com/0xERR0R/blocky/resolver"
+	"github.com/0xERR0R/blocky/snapshot"
 
 	"github.com/0xERR0R/blocky/util"
+	"github.com/0xERR0R/blocky/watchdog"
+	"github.com/0xERR0R/blocky/webhook"
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-multierror"
 
@@ -40,7 +52,26 @@ type Server struct {
 	queryResolver resolver.ChainedResolver
 	cfg           *config.Config
 
-	servers map[net.Listener]*httpServer
+	servers map[net.Listener]listenerServer
+	sockets *sockets
+
+	http3Server *http3Server
+	http3Conns  []net.PacketConn
+
+	snapshots   *snapshot.Store
+	externalDNS *externaldns.Provider
+	dnsUpdates  *dnsupdate.Zones
+	cookies     *dnsCookies
+	rateLimiter *dnsRateLimiter
+	rrl         *responseRateLimiter
+	tcpConns    *tcpConnections
+
+	proxyProtocol *proxyProtocol
+	certManager   *acme.Manager
+	certStore     *certificateStore
+
+	syncClient    cachesync.Client
+	listRefreshes *listRefreshes
 }
 
 func logger() *logrus.Entry {
@@ -62,81 +93,179 @@ func tlsCipherSuites() []uint16 {
 
 type NewServerFunc func(address string) (*dns.Server, error)
 
-func retrieveCertificate(cfg *config.Config) (cert tls.Certificate, err error) {
-	if cfg.CertFile == "" && cfg.KeyFile == "" {
-		cert, err = util.TLSGenerateSelfSignedCert([]string{"blocky.invalid", "*"})
-		if err != nil {
-			return tls.Certificate{}, fmt.Errorf("unable to generate self-signed certificate: %w", err)
-		}
+func selfSignedCertificate() (tls.Certificate, error) {
+	cert, err := util.TLSGenerateSelfSignedCert([]string{"blocky.invalid", "*"})
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("unable to generate self-signed certificate: %w", err)
+	}
+
+	log.Log().Info("using self-signed certificate")
 
-		log.Log().Info("using self-signed certificate")
+	return cert, nil
+}
+
+// newTLSConfigs returns the TLS configs of the DoT and DoH listeners,
+// a self-signed certificate is used if `getCertificate` is nil
+func newTLSConfigs(
+	cfg *config.Config, getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error),
+) (dotCfg, dohCfg *tls.Config, err error) {
+	// #nosec G402 // See TLSVersion.validate
+	base := &tls.Config{
+		MinVersion:   uint16(cfg.MinTLSServeVer),
+		CipherSuites: tlsCipherSuites(),
+	}
+
+	if getCertificate != nil {
+		base.GetCertificate = getCertificate
 	} else {
-		cert, err = tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		cert, err := selfSignedCertificate()
 		if err != nil {
-			return tls.Certificate{}, fmt.Errorf("can't load certificate files: %w", err)
+			return nil, nil, fmt.Errorf("can't retrieve cert: %w", err)
 		}
-	}
 
-	return
-}
+		base.Certificates = []tls.Certificate{cert}
+	}
 
-func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
-	var cert tls.Certificate
+	dotCfg = base.Clone()
+	dotPolicy := cfg.TLS.ForDoT()
 
-	cert, err := retrieveCertificate(cfg)
-	if err != nil {
-		return nil, fmt.Errorf("can't retrieve cert: %w", err)
+	if err := dotPolicy.ApplyServer(dotCfg); err != nil {
+		return nil, nil, fmt.Errorf("can't apply DoT TLS policy: %w", err)
 	}
 
-	// #nosec G402 // See TLSVersion.validate
-	res := &tls.Config{
-		MinVersion:   uint16(cfg.MinTLSServeVer),
-		CipherSuites: tlsCipherSuites(),
-		Certificates: []tls.Certificate{cert},
+	dohCfg = base.Clone()
+	dohPolicy := cfg.TLS.ForDoH()
+
+	if err := dohPolicy.ApplyServer(dohCfg); err != nil {
+		return nil, nil, fmt.Errorf("can't apply DoH TLS policy: %w", err)
 	}
 
-	return res, nil
+	return dotCfg, dohCfg, nil
 }
 
 // NewServer creates new server instance with passed config
 //
 //nolint:funlen
 func NewServer(ctx context.Context, cfg *config.Config) (server *Server, err error) {
-	var tlsCfg *tls.Config
+	var dotTLSCfg, dohTLSCfg *tls.Config
+
+	certManager, err := acme.New(cfg.ACME)
+	if err != nil {
+		return nil, fmt.Errorf("can't create ACME certificate manager: %w", err)
+	}
 
-	if len(cfg.Ports.HTTPS) > 0 || len(cfg.Ports.TLS) > 0 {
-		tlsCfg, err = newTLSConfig(cfg)
+	var certStore *certificateStore
+
+	if len(cfg.Ports.HTTPS) > 0 || len(cfg.Ports.TLS) > 0 || len(cfg.Sinkhole.HTTPS) > 0 {
+		var getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+
+		switch {
+		case certManager != nil:
+			getCertificate = certManager.GetCertificate
+
+		case cfg.CertFile != "" || cfg.KeyFile != "":
+			certStore, err = newCertificateStore(ctx, cfg)
+			if err != nil {
+				return nil, err
+			}
+
+			getCertificate = certStore.GetCertificate
+		}
+
+		dotTLSCfg, dohTLSCfg, err = newTLSConfigs(cfg, getCertificate)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	dnsServers, err := createServers(cfg, tlsCfg)
+	sockets := newSockets(cfg.Ports)
+
+	dnsServers, err := createServers(cfg, dotTLSCfg, sockets)
 	if err != nil {
 		return nil, fmt.Errorf("server creation failed: %w", err)
 	}
 
-	httpListeners, httpsListeners, err := createHTTPListeners(cfg, tlsCfg)
+	proxyProtocol, err := newProxyProtocol(cfg.ProxyProtocol)
+	if err != nil {
+		return nil, err
+	}
+
+	httpListeners, httpsListeners, err := createHTTPListeners(cfg, dohTLSCfg, proxyProtocol, sockets)
+	if err != nil {
+		return nil, err
+	}
+
+	cookies, err := newDNSCookies(cfg.Cookies)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimiter, err := newDNSRateLimiter(cfg.RateLimit, cfg.EDE, cfg.Cookies)
+	if err != nil {
+		return nil, err
+	}
+
+	rrl, err := newResponseRateLimiter(cfg.RRL)
 	if err != nil {
 		return nil, err
 	}
 
-	metrics.RegisterEventListeners()
+	metrics.RegisterEventListeners(cfg.Prometheus)
+
+	if _, err := events.Start(ctx, cfg.Events); err != nil {
+		return nil, fmt.Errorf("can't start event publishing: %w", err)
+	}
+
+	if _, err := webhook.Start(ctx, cfg.Webhooks); err != nil {
+		return nil, fmt.Errorf("can't start webhook notifications: %w", err)
+	}
 
 	bootstrap, err := resolver.NewBootstrap(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	var redisClient *redis.Client
-	if cfg.Redis.IsEnabled() {
-		redisClient, err = redis.New(ctx, &cfg.Redis)
-		if err != nil && cfg.Redis.Required {
+	syncClient, err := newSyncClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// subscribed before the lists are loaded by the blocking resolver
+	listRefreshes, err := newListRefreshes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	geoIP, err := geoip.Open(ctx, &cfg.GeoIP)
+	if err != nil {
+		return nil, err
+	}
+
+	var customDNSSources []resolver.CustomDNSSource
+
+	var externalDNS *externaldns.Provider
+	if cfg.CustomDNS.ExternalDNS.IsEnabled() {
+		externalDNS = externaldns.NewProvider(cfg.CustomDNS.ExternalDNS, cfg.CustomDNS.CustomTTL)
+		customDNSSources = append(customDNSSources, externalDNS)
+	}
+
+	var dnsUpdates *dnsupdate.Zones
+	if cfg.CustomDNS.DynamicUpdates.IsEnabled() {
+		dnsUpdates, err = dnsupdate.NewZones(cfg.CustomDNS.DynamicUpdates)
+		if err != nil {
 			return nil, err
 		}
+
+		customDNSSources = append(customDNSSources, dnsUpdates)
+
+		// the servers accept updates and verify their signatures
+		for _, srv := range dnsServers {
+			srv.MsgAcceptFunc = dnsupdate.AcceptMsg
+			srv.TsigSecret = dnsUpdates.TsigSecrets()
+		}
 	}
 
-	queryResolver, queryError := createQueryResolver(ctx, cfg, bootstrap, redisClient)
+	queryResolver, queryError := createQueryResolver(ctx, cfg, bootstrap, syncClient, geoIP, customDNSSources...)
 	if queryError != nil {
 		return nil, queryError
 	}
@@ -146,7 +275,26 @@ func NewServer(ctx context.Context, cfg *config.Config) (server *Server, err err
 		queryResolver: queryResolver,
 		cfg:           cfg,
 
-		servers: make(map[net.Listener]*httpServer),
+		servers: make(map[net.Listener]listenerServer),
+		sockets: sockets,
+
+		externalDNS: externalDNS,
+		dnsUpdates:  dnsUpdates,
+		cookies:     cookies,
+		rateLimiter: rateLimiter,
+		rrl:         rrl,
+		tcpConns:    newTCPConnections(cfg.Ports),
+
+		proxyProtocol: proxyProtocol,
+		certManager:   certManager,
+		certStore:     certStore,
+
+		syncClient:    syncClient,
+		listRefreshes: listRefreshes,
+	}
+
+	if cfg.Snapshots.IsEnabled() {
+		server.snapshots = snapshot.NewStore(cfg.Snapshots)
 	}
 
 	server.printConfiguration()
@@ -160,9 +308,16 @@ func NewServer(ctx context.Context, cfg *config.Config) (server *Server, err err
 
 	httpRouter := createHTTPRouter(cfg, openAPIImpl)
 	server.registerDoHEndpoints(httpRouter)
+	server.registerExternalDNSEndpoints(httpRouter)
+	server.registerClusterEndpoints(httpRouter)
+
+	if err := server.registerHealthEndpoints(httpRouter); err != nil {
+		return nil, err
+	}
 
 	if len(cfg.Ports.HTTP) != 0 {
-		srv := newHTTPServer("http", httpRouter, cfg)
+		// the http-01 challenges are answered before any authentication
+		srv := newHTTPServer("http", certManager.HTTPHandler(httpRouter), cfg)
 
 		for _, l := range httpListeners {
 			server.servers[l] = srv
@@ -170,17 +325,51 @@ func NewServer(ctx context.Context, cfg *config.Config) (server *Server, err err
 	}
 
 	if len(cfg.Ports.HTTPS) != 0 {
-		srv := newHTTPServer("https", httpRouter, cfg)
+		var httpsHandler http.Handler = httpRouter
+
+		if cfg.Ports.HTTP3 {
+			server.http3Conns, err = newUDPListeners("http3", cfg.Ports.HTTPS, sockets)
+			if err != nil {
+				return nil, err
+			}
+
+			server.http3Server = newHTTP3Server(httpRouter, dohTLSCfg, cfg)
+			httpsHandler = server.http3Server.altSvcMiddleware(httpRouter)
+		}
+
+		srv := newHTTPServer("https", httpsHandler, cfg)
 
 		for _, l := range httpsListeners {
 			server.servers[l] = srv
 		}
 	}
 
+	if err := server.registerSinkhole(ctx, dohTLSCfg); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Ports.GRPC) != 0 {
+		grpcImpl, err := server.createGRPCInterfaceImpl()
+		if err != nil {
+			return nil, err
+		}
+
+		grpcListeners, err := newTCPListeners("grpc", cfg.Ports.GRPC, sockets)
+		if err != nil {
+			return nil, err
+		}
+
+		srv := newGRPCServer(grpcImpl, cfg.API)
+
+		for _, l := range grpcListeners {
+			server.servers[l] = srv
+		}
+	}
+
 	return server, err
 }
 
-func createServers(cfg *config.Config, tlsCfg *tls.Config) ([]*dns.Server, error) {
+func createServers(cfg *config.Config, tlsCfg *tls.Config, sockets *sockets) ([]*dns.Server, error) {
 	var dnsServers []*dns.Server
 
 	var err *multierror.Error
@@ -203,32 +392,44 @@ func createServers(cfg *config.Config, tlsCfg *tls.Config) ([]*dns.Server, error
 		addServers(createTCPServer, cfg.Ports.DNS),
 		addServers(func(address string) (*dns.Server, error) {
 			return createTLSServer(address, tlsCfg)
-		}, cfg.Ports.TLS))
+		}, cfg.Ports.TLS),
+		addServers(func(socketPath string) (*dns.Server, error) {
+			return createUnixServer(socketPath, sockets)
+		}, cfg.Ports.Unix))
 
 	return dnsServers, err.ErrorOrNil()
 }
 
 func createHTTPListeners(
-	cfg *config.Config, tlsCfg *tls.Config,
+	cfg *config.Config, tlsCfg *tls.Config, proxyProtocol *proxyProtocol, sockets *sockets,
 ) (httpListeners, httpsListeners []net.Listener, err error) {
-	httpListeners, err = newTCPListeners("http", cfg.Ports.HTTP)
+	httpListeners, err = newTCPListeners("http", cfg.Ports.HTTP, sockets)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	httpsListeners, err = newTLSListeners("https", cfg.Ports.HTTPS, tlsCfg)
+	for i, listener := range httpListeners {
+		httpListeners[i] = proxyProtocol.wrap(config.ProxyProtocolListenerHttp, listener)
+	}
+
+	httpsListeners, err = newTCPListeners("https", cfg.Ports.HTTPS, sockets)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	for i, listener := range httpsListeners {
+		// the header is sent before the TLS handshake
+		httpsListeners[i] = tls.NewListener(proxyProtocol.wrap(config.ProxyProtocolListenerHttps, listener), tlsCfg)
+	}
+
 	return httpListeners, httpsListeners, nil
 }
 
-func newTCPListeners(proto string, addresses config.ListenConfig) ([]net.Listener, error) {
+func newTCPListeners(proto string, addresses config.ListenConfig, sockets *sockets) ([]net.Listener, error) {
 	listeners := make([]net.Listener, 0, len(addresses))
 
 	for _, address := range addresses {
-		listener, err := net.Listen("tcp", address)
+		listener, err := sockets.listen("tcp", address)
 		if err != nil {
 			return nil, fmt.Errorf("start %s listener on %s failed: %w", proto, address, err)
 		}
@@ -239,17 +440,44 @@ func newTCPListeners(proto string, addresses config.ListenConfig) ([]net.Listene
 	return listeners, nil
 }
 
-func newTLSListeners(proto string, addresses config.ListenConfig, tlsCfg *tls.Config) ([]net.Listener, error) {
-	listeners, err := newTCPListeners(proto, addresses)
+// listenDNS creates the listener or UDP connection of a server
+func (s *Server) listenDNS(srv *dns.Server) error {
+	if srv.Listener != nil {
+		// already listening, e.g. on a unix socket
+		return nil
+	}
+
+	if !isStreamServer(srv) {
+		conn, err := s.sockets.listenPacket(srv.Addr)
+		if err != nil {
+			return fmt.Errorf("start %s listener on %s failed: %w", srv.Net, srv.Addr, err)
+		}
+
+		srv.PacketConn = conn
+
+		return nil
+	}
+
+	kind := config.ProxyProtocolListenerDns
+	if srv.Net == "tcp-tls" {
+		kind = config.ProxyProtocolListenerTls
+	}
+
+	listener, err := s.sockets.listen("tcp", srv.Addr)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("start %s listener on %s failed: %w", srv.Net, srv.Addr, err)
 	}
 
-	for i, inner := range listeners {
-		listeners[i] = tls.NewListener(inner, tlsCfg)
+	// the PROXY protocol header is sent before the TLS handshake
+	listener = s.proxyProtocol.wrap(kind, s.tcpConns.limit(listener))
+
+	if srv.Net == "tcp-tls" {
+		listener = tls.NewListener(listener, srv.TLSConfig)
 	}
 
-	return listeners, nil
+	srv.Listener = listener
+
+	return nil
 }
 
 func createTLSServer(address string, tlsCfg *tls.Config) (*dns.Server, error) {
@@ -287,18 +515,79 @@ func createUDPServer(address string) (*dns.Server, error) {
 	}, nil
 }
 
+func createUnixServer(socketPath string, sockets *sockets) (*dns.Server, error) {
+	listener, err := sockets.listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("start unix listener on %s failed: %w", socketPath, err)
+	}
+
+	return &dns.Server{
+		Listener: listener,
+		Net:      "unix",
+		Handler:  dns.NewServeMux(),
+		NotifyStartedFunc: func() {
+			logger().Infof("Unix socket server is up and running on %s", socketPath)
+		},
+	}, nil
+}
+
+// newSyncClient creates the client which synchronizes the instances, nil if no backend is configured or reachable
+func newSyncClient(ctx context.Context, cfg *config.Config) (cachesync.Client, error) {
+	switch {
+	case cfg.Redis.IsEnabled():
+		client, err := redis.New(ctx, &cfg.Redis)
+		if err != nil {
+			if cfg.Redis.Required {
+				return nil, err
+			}
+
+			logger().Warn("can't connect to redis, instances are not synchronized: ", err)
+
+			return nil, nil //nolint:nilnil
+		}
+
+		return client, nil
+
+	case cfg.NATS.IsEnabled():
+		client, err := nats.New(ctx, &cfg.NATS)
+		if err != nil {
+			if cfg.NATS.Required {
+				return nil, err
+			}
+
+			logger().Warn("can't connect to NATS, instances are not synchronized: ", err)
+
+			return nil, nil //nolint:nilnil
+		}
+
+		return client, nil
+
+	case cfg.Cluster.IsEnabled():
+		return cluster.New(ctx, &cfg.Cluster, cfg.Hash), nil
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
 func createQueryResolver(
 	ctx context.Context,
 	cfg *config.Config,
 	bootstrap *resolver.Bootstrap,
-	redisClient *redis.Client,
+	syncClient cachesync.Client,
+	geoIP *geoip.DB,
+	customDNSSources ...resolver.CustomDNSSource,
 ) (resolver.ChainedResolver, error) {
 	upstreamTree, utErr := resolver.NewUpstreamTreeResolver(ctx, cfg.Upstreams, bootstrap)
-	blocking, blErr := resolver.NewBlockingResolver(ctx, cfg.Blocking, redisClient, bootstrap)
+	blocking, blErr := resolver.NewBlockingResolver(ctx, cfg.Blocking, syncClient, bootstrap, geoIP)
 	clientNames, cnErr := resolver.NewClientNamesResolver(ctx, cfg.ClientLookup, cfg.Upstreams, bootstrap)
-	queryLogging, qlErr := resolver.NewQueryLoggingResolver(ctx, cfg.QueryLog)
+	queryLogging, qlErr := resolver.NewQueryLoggingResolver(ctx, cfg.QueryLog, geoIP)
 	condUpstream, cuErr := resolver.NewConditionalUpstreamResolver(ctx, cfg.Conditional, cfg.Upstreams, bootstrap)
 	hostsFile, hfErr := resolver.NewHostsFileResolver(ctx, cfg.HostsFile, bootstrap)
+	customDNS, cdErr := resolver.NewCustomDNSResolver(ctx, cfg.CustomDNS, customDNSSources...)
+	mirror, miErr := resolver.NewMirrorResolver(ctx, cfg.Mirror, cfg.Upstreams, bootstrap)
+	mdns, mdErr := resolver.NewMDNSResolver(cfg.MDNS)
+	statistics, stErr := resolver.NewStatsResolver(ctx, cfg.Stats, &cfg.Redis)
+	newDomains, ndErr := resolver.NewNewDomainsResolver(ctx, cfg.NewDomains, bootstrap)
 
 	err := multierror.Append(
 		multierror.Prefix(utErr, "upstream tree resolver: "),
@@ -307,25 +596,41 @@ func createQueryResolver(
 		multierror.Prefix(cnErr, "client names resolver: "),
 		multierror.Prefix(cuErr, "conditional upstream resolver: "),
 		multierror.Prefix(hfErr, "hosts file resolver: "),
+		multierror.Prefix(cdErr, "custom DNS resolver: "),
+		multierror.Prefix(miErr, "mirror resolver: "),
+		multierror.Prefix(mdErr, "mDNS resolver: "),
+		multierror.Prefix(stErr, "statistics resolver: "),
+		multierror.Prefix(ndErr, "new domains resolver: "),
 	).ErrorOrNil()
 	if err != nil {
 		return nil, err
 	}
 
 	r := resolver.Chain(
-		resolver.NewFilteringResolver(cfg.Filtering),
+		resolver.NewCompatibilityResolver(cfg.Compatibility),
 		resolver.NewFQDNOnlyResolver(cfg.FQDNOnly),
 		resolver.NewECSResolver(cfg.ECS),
 		clientNames,
+		// after the client names, which the client groups of the filtering match
+		resolver.NewFilteringResolver(cfg.Filtering),
 		resolver.NewEDEResolver(cfg.EDE),
 		queryLogging,
-		resolver.NewMetricsResolver(cfg.Prometheus),
-		resolver.NewRewriterResolver(cfg.CustomDNS.RewriterConfig, resolver.NewCustomDNSResolver(cfg.CustomDNS)),
+		resolver.NewMetricsResolver(cfg.Prometheus, geoIP),
+		statistics,
+		resolver.NewTunnelingResolver(ctx, cfg.Tunneling),
+		resolver.NewRewriterResolver(cfg.CustomDNS.RewriterConfig, customDNS),
 		hostsFile,
 		blocking,
-		resolver.NewCachingResolver(ctx, cfg.Caching, redisClient),
+		newDomains,
+		resolver.NewDNS64Resolver(cfg.DNS64),
+		resolver.NewResponseRewriteResolver(cfg.ResponseRewrite),
+		resolver.NewCachingResolver(ctx, cfg.Caching, syncClient),
+		resolver.NewTTLRewriteResolver(cfg.Caching.TTLRewrite),
 		resolver.NewRewriterResolver(cfg.Conditional.RewriterConfig, condUpstream),
+		mdns,
 		resolver.NewSpecialUseDomainNamesResolver(cfg.SUDN),
+		mirror,
+		resolver.NewRebindProtectionResolver(cfg.Filtering.RebindProtection),
 		upstreamTree,
 	)
 
@@ -333,11 +638,16 @@ func createQueryResolver(
 }
 
 func (s *Server) registerDNSHandlers(ctx context.Context) {
+	pipelining := newTCPPipelining(s.cfg.Ports.TCPPipelining, s.cfg.Ports.TCPMaxQueries)
+
 	for _, server := range s.dnsServers {
 		handler := server.Handler.(*dns.ServeMux)
-		handler.HandleFunc(".", func(w dns.ResponseWriter, m *dns.Msg) {
+		onRequest := func(w dns.ResponseWriter, m *dns.Msg) {
 			s.OnRequest(ctx, w, m)
-		})
+		}
+
+		handler.HandleFunc(".", pipelining.wrap(server,
+			s.tcpConns.wrap(server, s.cookies.wrap(s.rateLimiter.wrap(s.rrl.wrap(server, onRequest))))))
 		handler.HandleFunc("healthcheck.blocky", func(w dns.ResponseWriter, m *dns.Msg) {
 			s.OnHealthCheck(ctx, w, m)
 		})
@@ -347,17 +657,7 @@ func (s *Server) registerDNSHandlers(ctx context.Context) {
 func (s *Server) printConfiguration() {
 	logger().Info("current configuration:")
 
-	if s.cfg.Redis.IsEnabled() {
-		logger().Info("Redis:")
-		log.WithIndent(logger(), "  ", s.cfg.Redis.LogConfig)
-	}
-
-	resolver.ForEach(s.queryResolver, func(res resolver.Resolver) {
-		resolver.LogResolverConfig(res, logger())
-	})
-
-	logger().Info("listeners:")
-	log.WithIndent(logger(), "  ", s.cfg.Ports.LogConfig)
+	s.logConfiguration(logger())
 
 	logger().Info("runtime information:")
 
@@ -379,6 +679,104 @@ func (s *Server) printConfiguration() {
 	logger().Infof("    numGC =    %10v", m.NumGC)
 }
 
+// logConfiguration logs the configuration of all components, secrets are hidden
+func (s *Server) logConfiguration(logger *logrus.Entry) {
+	if s.cfg.Redis.IsEnabled() {
+		logger.Info("Redis:")
+		log.WithIndent(logger, "  ", s.cfg.Redis.LogConfig)
+	}
+
+	if s.cfg.NATS.IsEnabled() {
+		logger.Info("NATS:")
+		log.WithIndent(logger, "  ", s.cfg.NATS.LogConfig)
+	}
+
+	if s.cfg.Cluster.IsEnabled() {
+		logger.Info("cluster:")
+		log.WithIndent(logger, "  ", s.cfg.Cluster.LogConfig)
+	}
+
+	if s.cfg.Events.IsEnabled() {
+		logger.Info("events:")
+		log.WithIndent(logger, "  ", s.cfg.Events.LogConfig)
+	}
+
+	if s.cfg.Webhooks.IsEnabled() {
+		logger.Info("webhooks:")
+		log.WithIndent(logger, "  ", s.cfg.Webhooks.LogConfig)
+	}
+
+	if s.cfg.Sinkhole.IsEnabled() {
+		logger.Info("sinkhole:")
+		log.WithIndent(logger, "  ", s.cfg.Sinkhole.LogConfig)
+	}
+
+	if s.cfg.GeoIP.IsEnabled() {
+		logger.Info("geoIP:")
+		log.WithIndent(logger, "  ", s.cfg.GeoIP.LogConfig)
+	}
+
+	resolver.ForEach(s.queryResolver, func(res resolver.Resolver) {
+		resolver.LogResolverConfig(res, logger)
+	})
+
+	logger.Info("listeners:")
+	log.WithIndent(logger, "  ", s.cfg.Ports.LogConfig)
+
+	if s.cfg.TLS.IsEnabled() {
+		logger.Info("tls:")
+		log.WithIndent(logger, "  ", s.cfg.TLS.LogConfig)
+	}
+
+	if s.cfg.RateLimit.IsEnabled() {
+		logger.Info("rateLimit:")
+		log.WithIndent(logger, "  ", s.cfg.RateLimit.LogConfig)
+	}
+
+	if s.cfg.RRL.IsEnabled() {
+		logger.Info("rrl:")
+		log.WithIndent(logger, "  ", s.cfg.RRL.LogConfig)
+	}
+
+	if s.cfg.Cookies.IsEnabled() {
+		logger.Info("cookies:")
+		log.WithIndent(logger, "  ", s.cfg.Cookies.LogConfig)
+	}
+
+	if len(s.cfg.Ports.HTTP) > 0 || len(s.cfg.Ports.HTTPS) > 0 {
+		logger.Info("doh:")
+		log.WithIndent(logger, "  ", s.cfg.DoH.LogConfig)
+	}
+
+	if s.cfg.ProxyProtocol.IsEnabled() {
+		logger.Info("proxyProtocol:")
+		log.WithIndent(logger, "  ", s.cfg.ProxyProtocol.LogConfig)
+	}
+
+	if s.cfg.ACME.IsEnabled() {
+		logger.Info("acme:")
+		log.WithIndent(logger, "  ", s.cfg.ACME.LogConfig)
+	} else if s.certStore != nil {
+		logger.Info("certificate:")
+		log.WithIndent(logger, "  ", s.cfg.Certificate.LogConfig)
+	}
+
+	if s.cfg.API.IsEnabled() {
+		logger.Info("api:")
+		log.WithIndent(logger, "  ", s.cfg.API.LogConfig)
+	}
+
+	if s.cfg.Snapshots.IsEnabled() {
+		logger.Info("snapshots:")
+		log.WithIndent(logger, "  ", s.cfg.Snapshots.LogConfig)
+	}
+
+	if s.cfg.Watchdog.IsEnabled() {
+		logger.Info("watchdog:")
+		log.WithIndent(logger, "  ", s.cfg.Watchdog.LogConfig)
+	}
+}
+
 func toMB(b uint64) uint64 {
 	const bytesInKB = 1024
 
@@ -392,13 +790,21 @@ func (s *Server) Start(ctx context.Context, errCh chan<- error) {
 	for _, srv := range s.dnsServers {
 		srv := srv
 
+		if err := s.listenDNS(srv); err != nil {
+			errCh <- err
+
+			continue
+		}
+
 		go func() {
-			if err := srv.ListenAndServe(); err != nil {
+			if err := srv.ActivateAndServe(); err != nil {
 				errCh <- fmt.Errorf("start %s listener failed: %w", srv.Net, err)
 			}
 		}()
 	}
 
+	s.sockets.closeUnused()
+
 	for listener, srv := range s.servers {
 		listener, srv := listener, srv
 
@@ -412,20 +818,93 @@ func (s *Server) Start(ctx context.Context, errCh chan<- error) {
 		}()
 	}
 
+	for _, conn := range s.http3Conns {
+		conn := conn
+
+		go func() {
+			logger().Infof("%s server is up and running on addr/port %s", s.http3Server, conn.LocalAddr())
+
+			err := s.http3Server.Serve(ctx, conn)
+			if err != nil {
+				errCh <- fmt.Errorf("%s on %s: %w", s.http3Server, conn.LocalAddr(), err)
+			}
+		}()
+	}
+
 	registerPrintConfigurationTrigger(ctx, s)
+	registerSnapshots(ctx, s)
+
+	if s.cfg.Watchdog.IsEnabled() {
+		watchdog.New(s.cfg.Watchdog).Start(ctx)
+	}
+
+	if s.certManager != nil {
+		s.certManager.Start(ctx)
+
+		watchCertificateExpiry(ctx, s.cfg.Certificate, s.certManager.GetCertificate)
+	}
+
+	if s.certStore != nil {
+		s.certStore.Start(ctx)
+
+		watchCertificateExpiry(ctx, s.cfg.Certificate, s.certStore.GetCertificate)
+	}
 }
 
 // Stop stops the server
 func (s *Server) Stop(ctx context.Context) error {
 	logger().Info("Stopping server")
 
+	if timeout := s.cfg.Ports.DrainTimeout.ToDuration(); timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		errs *multierror.Error
+	)
+
+	// all listeners stop accepting at once and then finish their in-flight queries in parallel
+	shutdown := func(name string, fn func(ctx context.Context) error) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := fn(ctx); err != nil {
+				lock.Lock()
+				defer lock.Unlock()
+
+				errs = multierror.Append(errs, fmt.Errorf("stop %s listener failed: %w", name, err))
+			}
+		}()
+	}
+
 	for _, server := range s.dnsServers {
-		if err := server.ShutdownContext(ctx); err != nil {
-			return fmt.Errorf("stop %s listener failed: %w", server.Net, err)
+		shutdown(server.Net, server.ShutdownContext)
+	}
+
+	stopped := make(map[listenerServer]bool)
+
+	for _, srv := range s.servers {
+		if !stopped[srv] {
+			stopped[srv] = true
+
+			shutdown(srv.String(), srv.Shutdown)
 		}
 	}
 
-	return nil
+	if s.http3Server != nil {
+		shutdown(s.http3Server.String(), s.http3Server.inner.Shutdown)
+	}
+
+	wg.Wait()
+
+	return errs.ErrorOrNil()
 }
 
 func extractClientIDFromHost(hostName string) string {
@@ -460,6 +939,7 @@ func newRequest(
 		Protocol:        protocol,
 		Req:             request,
 		RequestTS:       time.Now(),
+		EDNS:            model.NewEDNS(request),
 	}
 
 	return ctx, &req
@@ -476,18 +956,55 @@ func newRequestFromDNS(ctx context.Context, rw dns.ResponseWriter, msg *dns.Msg)
 	}
 
 	var clientID string
-	if con, ok := rw.(dns.ConnectionStater); ok && con.ConnectionState() != nil {
-		clientID = extractClientIDFromHost(con.ConnectionState().ServerName)
+	if state := connectionState(rw); state != nil {
+		clientID = extractClientIDFromHost(state.ServerName)
 	}
 
 	return newRequest(ctx, clientIP, clientID, protocol, msg)
 }
 
+// unwrapWriter returns the writer wrapped by w, nil if w doesn't wrap another writer
+func unwrapWriter(w dns.ResponseWriter) dns.ResponseWriter {
+	if u, ok := w.(interface{ Unwrap() dns.ResponseWriter }); ok {
+		return u.Unwrap()
+	}
+
+	return nil
+}
+
+// tsigStatus returns the result of the verification of the TSIG signature of the message answered with the writer.
+// The connection's writer of a concurrently handled message already belongs to the next message: its request MAC
+// can't sign the response, so the status is an error.
+func tsigStatus(w dns.ResponseWriter) error {
+	for inner := w; inner != nil; inner = unwrapWriter(inner) {
+		if _, ok := inner.(*pipelinedWriter); ok {
+			return errConcurrentTSIG
+		}
+	}
+
+	return w.TsigStatus()
+}
+
+// connectionState returns the TLS state of the connection of the writer, nil if it isn't a TLS connection
+func connectionState(w dns.ResponseWriter) *tls.ConnectionState {
+	for ; w != nil; w = unwrapWriter(w) {
+		if con, ok := w.(dns.ConnectionStater); ok {
+			return con.ConnectionState()
+		}
+	}
+
+	return nil
+}
+
 func newRequestFromHTTP(ctx context.Context, req *http.Request, msg *dns.Msg) (context.Context, *model.Request) {
 	protocol := model.RequestProtocolTCP
 	clientIP := util.HTTPClientIP(req)
 
-	clientID := chi.URLParam(req, "clientID")
+	clientID, ok := req.Context().Value(dohClientIDKey{}).(string)
+	if !ok {
+		clientID = chi.URLParam(req, "clientID")
+	}
+
 	if clientID == "" {
 		clientID = extractClientIDFromHost(req.Host)
 	}
@@ -497,6 +1014,12 @@ func newRequestFromHTTP(ctx context.Context, req *http.Request, msg *dns.Msg) (c
 
 // OnRequest will be executed if a new DNS request is received
 func (s *Server) OnRequest(ctx context.Context, w dns.ResponseWriter, msg *dns.Msg) {
+	if msg.Opcode == dns.OpcodeUpdate && s.dnsUpdates != nil {
+		s.dnsUpdates.ServeUpdate(w, msg, tsigStatus(w))
+
+		return
+	}
+
 	ctx, request := newRequestFromDNS(ctx, w, msg)
 
 	s.handleReq(ctx, request, w)
@@ -599,6 +1122,9 @@ func resolveClientIPAndProtocol(addr net.Addr) (ip net.IP, protocol model.Reques
 		return a.IP, model.RequestProtocolUDP
 	case *net.TCPAddr:
 		return a.IP, model.RequestProtocolTCP
+	case *net.UnixAddr:
+		// local client without IP: handle it like a request from loopback
+		return net.IPv4(127, 0, 0, 1), model.RequestProtocolTCP //nolint:mnd
 	}
 
 	return nil, model.RequestProtocolUDP
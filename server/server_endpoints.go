@@ -3,16 +3,19 @@ package server
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/0xERR0R/blocky/metrics"
 	"github.com/0xERR0R/blocky/resolver"
 
 	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/cluster"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/docs"
 	"github.com/0xERR0R/blocky/log"
@@ -26,11 +29,12 @@ import (
 )
 
 const (
-	dohMessageLimit   = 512
 	contentTypeHeader = "content-type"
 	dnsContentType    = "application/dns-message"
 	htmlContentType   = "text/html; charset=UTF-8"
 	yamlContentType   = "text/yaml"
+
+	pathExternalDNS = "/api/externaldns"
 )
 
 func (s *Server) createOpenAPIInterfaceImpl() (impl api.StrictServerInterface, err error) {
@@ -44,16 +48,42 @@ func (s *Server) createOpenAPIInterfaceImpl() (impl api.StrictServerInterface, e
 		return nil, fmt.Errorf("no refresh API implementation found %w", err)
 	}
 
+	exporter, err := resolver.GetFromChainWithType[api.ListExporter](s.queryResolver)
+	if err != nil {
+		return nil, fmt.Errorf("no list export API implementation found %w", err)
+	}
+
 	cacheControl, err := resolver.GetFromChainWithType[api.CacheControl](s.queryResolver)
 	if err != nil {
 		return nil, fmt.Errorf("no cache API implementation found %w", err)
 	}
 
-	return api.NewOpenAPIInterfaceImpl(bControl, s, refresher, cacheControl), nil
+	upstreams, err := resolver.GetFromChainWithType[api.UpstreamStatusProvider](s.queryResolver)
+	if err != nil {
+		return nil, fmt.Errorf("no upstream status API implementation found %w", err)
+	}
+
+	queryLog, err := resolver.GetFromChainWithType[api.QueryLogProvider](s.queryResolver)
+	if err != nil {
+		return nil, fmt.Errorf("no query log API implementation found %w", err)
+	}
+
+	statistics, err := resolver.GetFromChainWithType[api.StatsProvider](s.queryResolver)
+	if err != nil {
+		return nil, fmt.Errorf("no statistics API implementation found %w", err)
+	}
+
+	return api.NewOpenAPIInterfaceImpl(
+		bControl, s, refresher, exporter, cacheControl, s, upstreams, s, queryLog, statistics,
+	), nil
 }
 
 func (s *Server) registerDoHEndpoints(router *chi.Mux) {
-	const pathDohQuery = "/dns-query"
+	if !s.cfg.DoH.IsEnabled() {
+		return
+	}
+
+	pathDohQuery := s.cfg.DoH.Path
 
 	router.Get(pathDohQuery, s.dohGetRequestHandler)
 	router.Get(pathDohQuery+"/", s.dohGetRequestHandler)
@@ -61,6 +91,41 @@ func (s *Server) registerDoHEndpoints(router *chi.Mux) {
 	router.Post(pathDohQuery, s.dohPostRequestHandler)
 	router.Post(pathDohQuery+"/", s.dohPostRequestHandler)
 	router.Post(pathDohQuery+"/{clientID}", s.dohPostRequestHandler)
+
+	// the requests of the additional endpoints use the client name of their path
+	for path, clientName := range s.cfg.DoH.Paths {
+		router.With(withDoHClientID(clientName)).Group(func(r chi.Router) {
+			r.Get(path, s.dohGetRequestHandler)
+			r.Get(path+"/", s.dohGetRequestHandler)
+			r.Post(path, s.dohPostRequestHandler)
+			r.Post(path+"/", s.dohPostRequestHandler)
+		})
+	}
+}
+
+type dohClientIDKey struct{}
+
+func withDoHClientID(clientID string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			ctx := context.WithValue(req.Context(), dohClientIDKey{}, clientID)
+
+			next.ServeHTTP(rw, req.WithContext(ctx))
+		})
+	}
+}
+
+// registerExternalDNSEndpoints registers the webhook provider API for Kubernetes ExternalDNS, if enabled
+func (s *Server) registerExternalDNSEndpoints(router *chi.Mux) {
+	if s.externalDNS != nil {
+		router.Mount(pathExternalDNS, s.externalDNS.Handler())
+	}
+}
+
+func (s *Server) registerClusterEndpoints(router *chi.Mux) {
+	if node, ok := s.syncClient.(*cluster.Node); ok {
+		router.Mount(cluster.PathPrefix, node.Handler())
+	}
 }
 
 func (s *Server) dohGetRequestHandler(rw http.ResponseWriter, req *http.Request) {
@@ -71,15 +136,19 @@ func (s *Server) dohGetRequestHandler(rw http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	rawMsg, err := base64.RawURLEncoding.DecodeString(dnsParam[0])
-	if err != nil {
-		http.Error(rw, "wrong message format", http.StatusBadRequest)
+	// the message is base64url encoded without padding, but some clients add it
+	encoded := strings.TrimRight(dnsParam[0], "=")
+
+	// check the size before decoding, to not waste work on oversized messages
+	if base64.RawURLEncoding.DecodedLen(len(encoded)) > int(s.cfg.DoH.MaxMessageSize) {
+		http.Error(rw, "URI Too Long", http.StatusRequestURITooLong)
 
 		return
 	}
 
-	if len(rawMsg) > dohMessageLimit {
-		http.Error(rw, "URI Too Long", http.StatusRequestURITooLong)
+	rawMsg, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		http.Error(rw, "wrong message format", http.StatusBadRequest)
 
 		return
 	}
@@ -95,14 +164,30 @@ func (s *Server) dohPostRequestHandler(rw http.ResponseWriter, req *http.Request
 		return
 	}
 
-	rawMsg, err := io.ReadAll(req.Body)
+	maxSize := int64(s.cfg.DoH.MaxMessageSize)
+
+	if req.ContentLength > maxSize {
+		http.Error(rw, "Payload Too Large", http.StatusRequestEntityTooLarge)
+
+		return
+	}
+
+	// read one byte more than allowed to detect oversized messages without content length
+	rawMsg, err := io.ReadAll(io.LimitReader(req.Body, maxSize+1))
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(rw, "Payload Too Large", http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
 		http.Error(rw, err.Error(), http.StatusBadRequest)
 
 		return
 	}
 
-	if len(rawMsg) > dohMessageLimit {
+	if int64(len(rawMsg)) > maxSize {
 		http.Error(rw, "Payload Too Large", http.StatusRequestEntityTooLarge)
 
 		return
@@ -137,6 +222,11 @@ func (r httpMsgWriter) WriteMsg(msg *dns.Msg) error {
 
 	r.rw.Header().Set("content-type", dnsContentType)
 
+	// https://www.rfc-editor.org/rfc/rfc8484#section-5.1
+	if maxAge, ok := dohMaxAge(msg); ok {
+		r.rw.Header().Set("cache-control", fmt.Sprintf("max-age=%d", maxAge))
+	}
+
 	// https://www.rfc-editor.org/rfc/rfc8484#section-4.2.1
 	r.rw.WriteHeader(http.StatusOK)
 
@@ -145,6 +235,32 @@ func (r httpMsgWriter) WriteMsg(msg *dns.Msg) error {
 	return err
 }
 
+// dohMaxAge returns the time the response can be cached by HTTP caches:
+// the lowest TTL of the answers or for negative responses the negative caching TTL of the SOA (RFC 2308)
+func dohMaxAge(msg *dns.Msg) (uint32, bool) {
+	if msg.Rcode != dns.RcodeSuccess && msg.Rcode != dns.RcodeNameError {
+		return 0, false
+	}
+
+	if len(msg.Answer) > 0 {
+		maxAge := msg.Answer[0].Header().Ttl
+
+		for _, rr := range msg.Answer[1:] {
+			maxAge = min(maxAge, rr.Header().Ttl)
+		}
+
+		return maxAge, true
+	}
+
+	for _, rr := range msg.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return min(soa.Hdr.Ttl, soa.Minttl), true
+		}
+	}
+
+	return 0, false
+}
+
 func (s *Server) Query(
 	ctx context.Context, serverHost string, clientIP net.IP, question string, qType dns.Type,
 ) (*model.Response, error) {
@@ -156,6 +272,32 @@ func (s *Server) Query(
 	return s.resolve(ctx, req)
 }
 
+// TraceQuery implements `api.Querier`.
+func (s *Server) TraceQuery(
+	ctx context.Context, serverHost string, clientIP net.IP, question string, qType dns.Type,
+) (*model.Response, *model.Trace, error) {
+	ctx, trace := resolver.WithTrace(ctx)
+
+	resp, err := s.Query(ctx, serverHost, clientIP, question, qType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := trace()
+
+	return resp, &result, nil
+}
+
+// ConfigHash implements `api.InfoProvider`.
+func (s *Server) ConfigHash() string {
+	return s.cfg.Hash
+}
+
+// Configuration implements `api.InfoProvider`.
+func (s *Server) Configuration() string {
+	return log.CaptureMessages(s.logConfiguration)
+}
+
 func createHTTPRouter(cfg *config.Config, openAPIImpl api.StrictServerInterface) *chi.Mux {
 	router := chi.NewRouter()
 
@@ -167,6 +309,8 @@ func createHTTPRouter(cfg *config.Config, openAPIImpl api.StrictServerInterface)
 
 	configureStaticAssetsHandler(router)
 
+	configureUIHandler(router)
+
 	configureRootHandler(cfg, router)
 
 	metrics.Start(router, cfg.Prometheus)
@@ -190,6 +334,16 @@ func configureStaticAssetsHandler(router *chi.Mux) {
 	router.Handle("/static/*", http.StripPrefix("/static/", fs))
 }
 
+func configureUIHandler(router *chi.Mux) {
+	files, err := web.UI()
+	util.FatalOnError("unable to load web UI files", err)
+
+	router.Get("/ui", func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, "/ui/", http.StatusMovedPermanently)
+	})
+	router.Handle("/ui/*", http.StripPrefix("/ui/", http.FileServer(http.FS(files))))
+}
+
 func configureRootHandler(cfg *config.Config, router *chi.Mux) {
 	router.Get("/", func(writer http.ResponseWriter, request *http.Request) {
 		writer.Header().Set(contentTypeHeader, htmlContentType)
@@ -216,6 +370,10 @@ func configureRootHandler(cfg *config.Config, router *chi.Mux) {
 		}
 
 		pd.Links = []HandlerLink{
+			{
+				URL:   "/ui/",
+				Title: "Web UI",
+			},
 			{
 				URL:   "/docs/openapi.yaml",
 				Title: "Rest API Documentation (OpenAPI)",
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DoH endpoints", func() {
+	Describe("request size limit", func() {
+		var router *chi.Mux
+
+		BeforeEach(func() {
+			cfg, err := config.WithDefaults[config.Config]()
+			Expect(err).Should(Succeed())
+
+			cfg.DoH.MaxMessageSize = 64
+
+			router = chi.NewRouter()
+			(&Server{cfg: &cfg}).registerDoHEndpoints(router)
+		})
+
+		serve := func(req *http.Request) int {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			return rec.Code
+		}
+
+		It("should reject larger GET messages before decoding them", func() {
+			param := base64.RawURLEncoding.EncodeToString(bytes.Repeat([]byte{0}, 65))
+
+			Expect(serve(httptest.NewRequest(http.MethodGet, "/dns-query?dns="+param, nil))).
+				Should(Equal(http.StatusRequestURITooLong))
+		})
+
+		It("should reject larger POST messages", func() {
+			req := httptest.NewRequest(http.MethodPost, "/dns-query", strings.NewReader(strings.Repeat("t", 65)))
+			req.Header.Set("Content-Type", dnsContentType)
+
+			Expect(serve(req)).Should(Equal(http.StatusRequestEntityTooLarge))
+
+			// without content length, the body is only read up to the limit
+			req = httptest.NewRequest(http.MethodPost, "/dns-query", strings.NewReader(strings.Repeat("t", 65)))
+			req.Header.Set("Content-Type", dnsContentType)
+			req.ContentLength = -1
+
+			Expect(serve(req)).Should(Equal(http.StatusRequestEntityTooLarge))
+		})
+	})
+
+	Describe("dohMaxAge", func() {
+		It("should use the lowest TTL of the answers", func() {
+			msg, err := util.NewMsgWithAnswer("example.com.", 300, dns.Type(dns.TypeA), "192.0.2.1")
+			Expect(err).Should(Succeed())
+
+			other, err := util.NewMsgWithAnswer("example.com.", 60, dns.Type(dns.TypeA), "192.0.2.2")
+			Expect(err).Should(Succeed())
+
+			msg.Answer = append(msg.Answer, other.Answer...)
+
+			maxAge, ok := dohMaxAge(msg)
+			Expect(ok).Should(BeTrue())
+			Expect(maxAge).Should(BeEquivalentTo(60))
+		})
+
+		It("should use the negative caching TTL of the SOA", func() {
+			msg := new(dns.Msg)
+			msg.Rcode = dns.RcodeNameError
+
+			soa, err := dns.NewRR("example.com. 3600 IN SOA ns.example.com. admin.example.com. 1 3600 600 86400 300")
+			Expect(err).Should(Succeed())
+
+			msg.Ns = []dns.RR{soa}
+
+			maxAge, ok := dohMaxAge(msg)
+			Expect(ok).Should(BeTrue())
+			Expect(maxAge).Should(BeEquivalentTo(300))
+		})
+
+		It("should not allow caching of errors and responses without TTL", func() {
+			msg := new(dns.Msg)
+
+			_, ok := dohMaxAge(msg)
+			Expect(ok).Should(BeFalse())
+
+			msg.Rcode = dns.RcodeServerFailure
+
+			_, ok = dohMaxAge(msg)
+			Expect(ok).Should(BeFalse())
+		})
+	})
+})
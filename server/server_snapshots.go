@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/resolver"
+	"github.com/0xERR0R/blocky/snapshot"
+)
+
+var errSnapshotsDisabled = errors.New("snapshots are disabled, configure snapshots.directory")
+
+// registerSnapshots takes a snapshot on start and every `snapshots.interval`
+func registerSnapshots(ctx context.Context, s *Server) {
+	if s.snapshots == nil {
+		return
+	}
+
+	s.logSnapshotError(s.takeSnapshot(snapshot.ReasonStart))
+
+	interval := s.cfg.Snapshots.Interval.ToDuration()
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.logSnapshotError(s.takeSnapshot(snapshot.ReasonScheduled))
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *Server) logSnapshotError(err error) {
+	if err != nil {
+		logger().WithError(err).Error("can't take snapshot")
+	}
+}
+
+// takeSnapshot stores the loaded configuration and the current runtime state
+func (s *Server) takeSnapshot(reason string) error {
+	current := snapshot.Snapshot{
+		Created:    time.Now(),
+		Reason:     reason,
+		ConfigHash: s.cfg.Hash,
+		Config:     string(s.cfg.Data),
+		Blocking:   snapshot.Blocking{Enabled: true},
+	}
+
+	if control, err := resolver.GetFromChainWithType[api.BlockingControl](s.queryResolver); err == nil {
+		status := control.BlockingStatus()
+
+		// a temporary disable is not worth restoring, it would not end anymore
+		if !status.Enabled && status.AutoEnableInSec == 0 {
+			current.Blocking = snapshot.Blocking{DisabledGroups: status.DisabledGroups}
+		}
+	}
+
+	err := s.snapshots.Save(&current)
+	if err != nil {
+		return err
+	}
+
+	logger().Debugf("took %s snapshot '%s'", reason, current.Name)
+
+	return nil
+}
+
+// Snapshots implements `api.SnapshotManager`.
+func (s *Server) Snapshots() ([]api.Snapshot, error) {
+	if s.snapshots == nil {
+		return []api.Snapshot{}, nil
+	}
+
+	snapshots, err := s.snapshots.List()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]api.Snapshot, 0, len(snapshots))
+
+	for _, snap := range snapshots {
+		res = append(res, api.Snapshot{
+			Name:       snap.Name,
+			Created:    snap.Created,
+			Reason:     snap.Reason,
+			ConfigHash: snap.ConfigHash,
+		})
+	}
+
+	return res, nil
+}
+
+// Rollback implements `api.SnapshotManager`.
+//
+// The runtime state is restored immediately, the configuration is written to the configuration file
+// and applied on the next start.
+func (s *Server) Rollback(ctx context.Context, name string) error {
+	if s.snapshots == nil {
+		return errSnapshotsDisabled
+	}
+
+	target, err := s.snapshots.Load(name)
+	if err != nil {
+		return err
+	}
+
+	// keep the current state to be able to undo the rollback
+	if err := s.takeSnapshot(snapshot.ReasonRollback); err != nil {
+		return fmt.Errorf("can't take snapshot before rollback: %w", err)
+	}
+
+	// the configuration file may have been changed since start
+	if hash, err := config.HashConfig(s.cfg.Path); err != nil || hash != target.ConfigHash {
+		if err := snapshot.RestoreConfig(target, s.cfg.Path); err != nil {
+			return err
+		}
+
+		logger().Warnf("restored configuration of snapshot '%s', restart blocky to apply it", name)
+	}
+
+	control, err := resolver.GetFromChainWithType[api.BlockingControl](s.queryResolver)
+	if err != nil {
+		return err
+	}
+
+	if target.Blocking.Enabled {
+		control.EnableBlocking(ctx)
+	} else if err := control.DisableBlocking(ctx, 0, target.Blocking.DisabledGroups); err != nil {
+		return fmt.Errorf("can't restore blocking state: %w", err)
+	}
+
+	logger().Infof("rolled back to snapshot '%s'", name)
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"io/fs"
+	"os"
+
+	"github.com/0xERR0R/blocky/api"
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+	"github.com/0xERR0R/blocky/resolver"
+	"github.com/0xERR0R/blocky/snapshot"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Snapshots", func() {
+	var (
+		server     *Server
+		cfg        *config.Config
+		configFile *TmpFile
+		control    api.BlockingControl
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		tmpDir := NewTmpFolder("snapshots")
+		configFile = tmpDir.CreateStringFile("config.yml", "current: config")
+
+		cfg = &config.Config{
+			Upstreams: config.Upstreams{
+				Groups: map[string][]config.Upstream{
+					"default": {config.Upstream{Net: config.NetProtocolTcpUdp, Host: "4.4.4.4", Port: 53}},
+				},
+			},
+			Blocking: config.Blocking{BlockType: "zeroIp"},
+			Snapshots: config.Snapshots{
+				Directory: tmpDir.JoinPath("snapshots"),
+			},
+			Hash: "current-hash",
+			Data: []byte("current: config"),
+			Path: configFile.Path,
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		server, err = NewServer(ctx, cfg)
+		Expect(err).Should(Succeed())
+
+		control, err = resolver.GetFromChainWithType[api.BlockingControl](server.queryResolver)
+		Expect(err).Should(Succeed())
+	})
+
+	It("should take a snapshot on start", func() {
+		registerSnapshots(ctx, server)
+
+		snapshots, err := server.Snapshots()
+		Expect(err).Should(Succeed())
+		Expect(snapshots).Should(HaveLen(1))
+		Expect(snapshots[0].Reason).Should(Equal(snapshot.ReasonStart))
+		Expect(snapshots[0].ConfigHash).Should(Equal("current-hash"))
+	})
+
+	Describe("Rollback", func() {
+		var target string
+
+		JustBeforeEach(func() {
+			Expect(server.takeSnapshot(snapshot.ReasonStart)).Should(Succeed())
+
+			snapshots, err := server.Snapshots()
+			Expect(err).Should(Succeed())
+
+			target = snapshots[0].Name
+		})
+
+		It("should restore the runtime state and configuration", func() {
+			Expect(control.DisableBlocking(ctx, 0, []string{})).Should(Succeed())
+			Expect(os.WriteFile(configFile.Path, []byte("broken: config"), 0o600)).Should(Succeed())
+
+			Expect(server.Rollback(ctx, target)).Should(Succeed())
+
+			Expect(control.BlockingStatus().Enabled).Should(BeTrue())
+			Expect(os.ReadFile(configFile.Path)).Should(BeEquivalentTo("current: config"))
+		})
+
+		It("should take a snapshot before, to undo the rollback", func() {
+			Expect(control.DisableBlocking(ctx, 0, []string{})).Should(Succeed())
+
+			Expect(server.Rollback(ctx, target)).Should(Succeed())
+
+			snapshots, err := server.Snapshots()
+			Expect(err).Should(Succeed())
+			Expect(snapshots).Should(ContainElement(HaveField("Reason", snapshot.ReasonRollback)))
+
+			var undo string
+
+			for _, s := range snapshots {
+				if s.Reason == snapshot.ReasonRollback {
+					undo = s.Name
+				}
+			}
+
+			Expect(server.Rollback(ctx, undo)).Should(Succeed())
+			Expect(control.BlockingStatus().Enabled).Should(BeFalse())
+		})
+
+		It("should fail for unknown snapshots", func() {
+			Expect(server.Rollback(ctx, "unknown")).Should(MatchError(fs.ErrNotExist))
+		})
+	})
+
+	When("snapshots are disabled", func() {
+		BeforeEach(func() {
+			cfg.Snapshots.Directory = ""
+		})
+
+		It("should not list or restore snapshots", func() {
+			registerSnapshots(ctx, server)
+
+			Expect(server.Snapshots()).Should(BeEmpty())
+			Expect(server.Rollback(ctx, "x")).Should(MatchError(errSnapshotsDisabled))
+		})
+	})
+})
@@ -3,15 +3,19 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/0xERR0R/blocky/acme"
+	"github.com/0xERR0R/blocky/api/admin"
 	"github.com/0xERR0R/blocky/config"
 	"github.com/0xERR0R/blocky/docs"
 	. "github.com/0xERR0R/blocky/helpertest"
@@ -24,6 +28,9 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go/http3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 const (
@@ -32,6 +39,7 @@ const (
 	dnsBasePort2  = 55000
 	httpsBasePort = 6000
 	tlsBasePort   = 8000
+	grpcBasePort  = 9000
 )
 
 var (
@@ -40,6 +48,7 @@ var (
 	err                                                          error
 	baseURL                                                      string
 	queryURL                                                     string
+	unixSocketPath                                               string
 	googleMockUpstream, fritzboxMockUpstream, clientMockUpstream *resolver.MockUDPUpstreamServer
 )
 
@@ -93,6 +102,7 @@ var _ = BeforeSuite(func() {
 	upstreamGoogle = googleMockUpstream.Start()
 
 	tmpDir := NewTmpFolder("server")
+	unixSocketPath = filepath.Join(tmpDir.Path, "dns.sock")
 	certPem := writeCertPem(tmpDir)
 	keyPem := writeKeyPem(tmpDir)
 	doubleclickFile := tmpDir.CreateStringFile("doubleclick.net.txt", "doubleclick.net", "doubleclick.net.cn")
@@ -107,6 +117,11 @@ var _ = BeforeSuite(func() {
 				"custom.lan": {&dns.A{A: net.ParseIP("192.168.178.55")}},
 				"lan.home":   {&dns.A{A: net.ParseIP("192.168.178.56")}},
 			},
+			ExternalDNS: config.ExternalDNS{Enable: true, Domains: []string{"k8s.lan"}},
+			DynamicUpdates: config.DynamicUpdates{
+				Zones: []string{"dyn.lan"},
+				Keys:  map[string]string{"dhcp-key": "c2VjcmV0LXNlY3JldC1zZWNyZXQ="},
+			},
 		},
 		Conditional: config.ConditionalUpstream{
 			Mapping: config.ConditionalUpstreamMapping{
@@ -144,6 +159,12 @@ var _ = BeforeSuite(func() {
 		},
 		ClientLookup: config.ClientLookup{
 			Upstream: upstreamClient,
+			MaxWait:  config.Duration(time.Second),
+		},
+		Filtering: config.Filtering{
+			ClientGroups: map[string]config.FilteringGroup{
+				"clNoMX": {QueryTypes: config.NewQTypeSet(MX)},
+			},
 		},
 
 		Ports: config.Ports{
@@ -151,9 +172,30 @@ var _ = BeforeSuite(func() {
 			TLS:   config.ListenConfig{GetHostPort("", tlsBasePort)},
 			HTTP:  config.ListenConfig{GetHostPort("", httpBasePort)},
 			HTTPS: config.ListenConfig{GetHostPort("", httpsBasePort)},
+			GRPC:  config.ListenConfig{GetHostPort("", grpcBasePort)},
+			Unix:  []string{unixSocketPath},
+			HTTP3: true,
+
+			TCPPipelining:  16,
+			TCPIdleTimeout: config.Duration(10 * time.Second),
 		},
 		CertFile: certPem.Path,
 		KeyFile:  keyPem.Path,
+		API: config.API{
+			Auth: config.APIAuth{
+				Tokens: []config.APIToken{{Name: "externaldns", Token: "externaldns-token", Role: config.APIRoleAdmin}},
+				Endpoints: config.APIEndpointRoles{
+					Read:    config.APIRoleNone,
+					Query:   config.APIRoleNone,
+					Control: config.APIRoleAdmin,
+				},
+			},
+		},
+		DoH: config.DoH{
+			Path:           "/dns-query",
+			Paths:          map[string]string{"/youtube-only": "clYoutubeOnly"},
+			MaxMessageSize: 512,
+		},
 		Prometheus: config.Metrics{
 			Enable: true,
 			Path:   "/metrics",
@@ -304,6 +346,19 @@ var _ = Describe("Running DNS server", func() {
 						))
 			})
 		})
+		Context("filtering of a client group", func() {
+			It("should filter the query types of the client's name", func() {
+				mockClientName.Store("clNoMX")
+
+				resp := requestServer(util.NewMsgWithQuestion("google.de.", MX))
+				Expect(resp.Rcode).Should(Equal(dns.RcodeSuccess))
+				Expect(resp.Answer).Should(BeEmpty())
+			})
+
+			It("should not filter the query types for other clients", func() {
+				Expect(requestServer(util.NewMsgWithQuestion("google.de.", MX)).Answer).ShouldNot(BeEmpty())
+			})
+		})
 		Context("block client with 2 groups", func() {
 			It("Query with should be blocked, domain is on black list", func() {
 				mockClientName.Store("clAdsAndYoutube")
@@ -365,6 +420,83 @@ var _ = Describe("Running DNS server", func() {
 			})
 		})
 	})
+	Describe("Unix socket endpoint", func() {
+		When("DNS request is sent via unix socket", func() {
+			It("should get a valid response", func() {
+				client := &dns.Client{Net: "unix"}
+
+				resp, _, err := client.Exchange(util.NewMsgWithQuestion("www.example.com.", A), unixSocketPath)
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(BeDNSRecord("www.example.com.", A, "123.124.122.122"))
+			})
+		})
+	})
+
+	Describe("HTTP/3 endpoint", func() {
+		var httpsQueryURL string
+
+		BeforeEach(func() {
+			httpsQueryURL = fmt.Sprintf("https://%s/dns-query", GetHostPort("localhost", httpsBasePort))
+		})
+
+		When("DoH request is performed via HTTP/3", func() {
+			It("should get a valid response", func() {
+				transport := &http3.RoundTripper{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+				}
+				DeferCleanup(transport.Close)
+
+				rawDNSMessage, err := util.NewMsgWithQuestion("www.example.com.", A).Pack()
+				Expect(err).Should(Succeed())
+
+				resp, err := (&http.Client{Transport: transport}).Post(httpsQueryURL,
+					"application/dns-message", bytes.NewReader(rawDNSMessage))
+				Expect(err).Should(Succeed())
+				DeferCleanup(resp.Body.Close)
+
+				Expect(resp).Should(HaveHTTPStatus(http.StatusOK))
+				Expect(resp.ProtoMajor).Should(Equal(3))
+
+				rawMsg, err := io.ReadAll(resp.Body)
+				Expect(err).Should(Succeed())
+
+				msg := new(dns.Msg)
+				Expect(msg.Unpack(rawMsg)).Should(Succeed())
+				Expect(msg.Answer).Should(BeDNSRecord("www.example.com.", A, "123.124.122.122"))
+			})
+		})
+
+		When("HTTPS request is performed via TCP", func() {
+			It("should advertise HTTP/3", func() {
+				client := &http.Client{Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+				}}
+
+				resp, err := client.Get(httpsQueryURL)
+				Expect(err).Should(Succeed())
+				DeferCleanup(resp.Body.Close)
+
+				_, port, err := net.SplitHostPort(GetHostPort("", httpsBasePort))
+				Expect(err).Should(Succeed())
+
+				Expect(resp.Header.Get("Alt-Svc")).Should(ContainSubstring(fmt.Sprintf(`h3=":%s"`, port)))
+			})
+		})
+	})
+
+	Describe("gRPC endpoint", func() {
+		It("should resolve queries", func() {
+			conn, err := grpc.NewClient(GetHostPort("localhost", grpcBasePort),
+				grpc.WithTransportCredentials(insecure.NewCredentials()))
+			Expect(err).Should(Succeed())
+			DeferCleanup(conn.Close)
+
+			resp, err := admin.NewAdminServiceClient(conn).Query(ctx, &admin.QueryRequest{Query: "google.de"})
+			Expect(err).Should(Succeed())
+			Expect(resp.GetResponse()).Should(Equal("A (123.124.122.122)"))
+		})
+	})
+
 	Describe("Root endpoint", func() {
 		When("Root URL is called", func() {
 			It("should return root page", func() {
@@ -378,6 +510,162 @@ var _ = Describe("Running DNS server", func() {
 			})
 		})
 	})
+	Describe("Web UI endpoint", func() {
+		When("UI URL is called", func() {
+			It("should return the UI page", func() {
+				resp, err := http.Get(baseURL + "ui")
+				Expect(err).Should(Succeed())
+				DeferCleanup(resp.Body.Close)
+
+				Expect(resp).Should(
+					SatisfyAll(
+						HaveHTTPStatus(http.StatusOK),
+						HaveHTTPHeaderWithValue("Content-type", "text/html; charset=utf-8"),
+						HaveHTTPBody(ContainSubstring("app.js")),
+					))
+				Expect(resp.Request.URL.Path).Should(Equal("/ui/"))
+			})
+		})
+	})
+	Describe("Configuration API endpoint", func() {
+		It("should return the configuration", func() {
+			resp, err := http.Get(baseURL + "api/config")
+			Expect(err).Should(Succeed())
+			DeferCleanup(resp.Body.Close)
+
+			Expect(resp).Should(
+				SatisfyAll(
+					HaveHTTPStatus(http.StatusOK),
+					HaveHTTPBody(SatisfyAll(
+						ContainSubstring("listeners:"),
+						ContainSubstring("-> blocking:"),
+						ContainSubstring("  DNS   = "),
+					)),
+				))
+		})
+	})
+	Describe("Recent queries API endpoint", func() {
+		It("should return the latest queries", func() {
+			Expect(requestServer(util.NewMsgWithQuestion("google.de.", A))).
+				Should(BeDNSRecord("google.de.", A, "123.124.122.122"))
+
+			resp, err := http.Get(baseURL + "api/queries/recent?limit=1")
+			Expect(err).Should(Succeed())
+			DeferCleanup(resp.Body.Close)
+
+			Expect(resp).Should(
+				SatisfyAll(
+					HaveHTTPStatus(http.StatusOK),
+					HaveHTTPHeaderWithValue("Content-type", "application/json"),
+					HaveHTTPBody(ContainSubstring(`"question":`)),
+				))
+		})
+	})
+	Describe("ExternalDNS webhook endpoints", func() {
+		When("ExternalDNS creates a record", func() {
+			It("should resolve it", func() {
+				req, err := http.NewRequest(http.MethodGet, baseURL+"api/externaldns", nil)
+				Expect(err).Should(Succeed())
+				req.Header.Set("Authorization", "Bearer externaldns-token")
+
+				resp, err := http.DefaultClient.Do(req)
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(
+					SatisfyAll(
+						HaveHTTPStatus(http.StatusOK),
+						HaveHTTPHeaderWithValue("Content-type", "application/external.dns.webhook+json;version=1"),
+					))
+				DeferCleanup(resp.Body.Close)
+
+				req, err = http.NewRequest(http.MethodPost, baseURL+"api/externaldns/records",
+					strings.NewReader(`{"create": [{"dnsName": "app.k8s.lan", "recordType": "A", "targets": ["10.0.0.1"]}]}`))
+				Expect(err).Should(Succeed())
+				req.Header.Set("Content-Type", "application/external.dns.webhook+json;version=1")
+				req.Header.Set("Authorization", "Bearer externaldns-token")
+
+				resp, err = http.DefaultClient.Do(req)
+				Expect(err).Should(Succeed())
+				Expect(resp).Should(HaveHTTPStatus(http.StatusNoContent))
+				DeferCleanup(resp.Body.Close)
+
+				Expect(requestServer(util.NewMsgWithQuestion("app.k8s.lan.", A))).
+					Should(BeDNSRecord("app.k8s.lan.", A, "10.0.0.1"))
+			})
+		})
+	})
+	Describe("Dynamic DNS updates", func() {
+		When("a signed update adds a record", func() {
+			It("should resolve it", func() {
+				msg := new(dns.Msg)
+				msg.SetUpdate("dyn.lan.")
+
+				rr, err := dns.NewRR("printer.dyn.lan. 300 IN A 192.168.178.60")
+				Expect(err).Should(Succeed())
+
+				msg.Insert([]dns.RR{rr})
+				msg.SetTsig("dhcp-key.", dns.HmacSHA256, 300, time.Now().Unix())
+
+				client := &dns.Client{TsigSecret: map[string]string{"dhcp-key.": "c2VjcmV0LXNlY3JldC1zZWNyZXQ="}}
+
+				resp, _, err := client.Exchange(msg, GetHostPort("", dnsBasePort))
+				Expect(err).Should(Succeed())
+				Expect(resp.Rcode).Should(Equal(dns.RcodeSuccess))
+
+				Expect(requestServer(util.NewMsgWithQuestion("printer.dyn.lan.", A))).
+					Should(BeDNSRecord("printer.dyn.lan.", A, "192.168.178.60"))
+			})
+		})
+
+		When("a forged update is pipelined with a query on a TCP connection", func() {
+			It("should reject it", func() {
+				conn, err := dns.Dial("tcp", GetHostPort("", dnsBasePort))
+				Expect(err).Should(Succeed())
+				DeferCleanup(conn.Close)
+
+				msg := new(dns.Msg)
+				msg.SetUpdate("dyn.lan.")
+
+				rr, err := dns.NewRR("forged.dyn.lan. 300 IN A 192.168.178.66")
+				Expect(err).Should(Succeed())
+
+				msg.Insert([]dns.RR{rr})
+				msg.SetTsig("dhcp-key.", dns.HmacSHA256, 300, time.Now().Unix())
+
+				// signed with another secret than the server's
+				conn.TsigSecret = map[string]string{"dhcp-key.": "d3Jvbmctc2VjcmV0"}
+				Expect(conn.WriteMsg(msg)).Should(Succeed())
+				Expect(conn.WriteMsg(util.NewMsgWithQuestion("custom.lan.", A))).Should(Succeed())
+
+				rcodes := map[uint16]int{}
+
+				for range 2 {
+					resp, err := conn.ReadMsg()
+					Expect(err).Should(Succeed())
+
+					rcodes[uint16(resp.Opcode)] = resp.Rcode
+				}
+
+				Expect(rcodes).Should(HaveKeyWithValue(uint16(dns.OpcodeUpdate), dns.RcodeNotAuth))
+
+				Expect(requestServer(util.NewMsgWithQuestion("forged.dyn.lan.", A))).
+					ShouldNot(BeDNSRecord("forged.dyn.lan.", A, "192.168.178.66"))
+			})
+		})
+
+		When("an update is not signed", func() {
+			It("should refuse it", func() {
+				msg := new(dns.Msg)
+				msg.SetUpdate("dyn.lan.")
+
+				rr, err := dns.NewRR("nas.dyn.lan. 300 IN A 192.168.178.61")
+				Expect(err).Should(Succeed())
+
+				msg.Insert([]dns.RR{rr})
+
+				Expect(requestServer(msg).Rcode).Should(Equal(dns.RcodeRefused))
+			})
+		})
+	})
 	Describe("Docs endpoints", func() {
 		When("OpenApi URL is called", func() {
 			It("should return openAPI definition file", func() {
@@ -414,6 +702,52 @@ var _ = Describe("Running DNS server", func() {
 					Expect(msg.Answer).Should(BeDNSRecord("www.example.com.", A, "123.124.122.122"))
 				})
 			})
+			When("the response is cacheable", func() {
+				It("should set the lowest TTL as max-age", func() {
+					resp, err := http.Get(queryURL + "?dns=AAABAAABAAAAAAAAA3d3dwdleGFtcGxlA2NvbQAAAQAB")
+					Expect(err).Should(Succeed())
+					DeferCleanup(resp.Body.Close)
+
+					// the TTL of the mock is 123, the response may come from the cache
+					Expect(resp).Should(HaveHTTPHeaderWithValue("Cache-Control", MatchRegexp(`^max-age=1[0-2][0-9]$`)))
+				})
+			})
+			When("the dns parameter is padded", func() {
+				It("should get a valid response", func() {
+					resp, err := http.Get(queryURL + "?dns=AAABAAABAAAAAAAAA3d3dwdleGFtcGxlA2NvbQAAAQAB==")
+					Expect(err).Should(Succeed())
+					DeferCleanup(resp.Body.Close)
+
+					Expect(resp).Should(HaveHTTPStatus(http.StatusOK))
+				})
+			})
+			When("a path mapped to a client name is used", func() {
+				It("should use the client groups of the client name", func() {
+					msg := util.NewMsgWithQuestion("youtube.com.", A)
+					rawMsg, err := msg.Pack()
+					Expect(err).Should(Succeed())
+
+					query := "?dns=" + base64.RawURLEncoding.EncodeToString(rawMsg)
+
+					for path, answer := range map[string]string{
+						"dns-query":    "123.124.122.122",
+						"youtube-only": "0.0.0.0",
+					} {
+						resp, err := http.Get(baseURL + path + query)
+						Expect(err).Should(Succeed())
+						DeferCleanup(resp.Body.Close)
+
+						Expect(resp).Should(HaveHTTPStatus(http.StatusOK))
+
+						body, err := io.ReadAll(resp.Body)
+						Expect(err).Should(Succeed())
+
+						res := new(dns.Msg)
+						Expect(res.Unpack(body)).Should(Succeed())
+						Expect(res.Answer).Should(BeDNSRecord("youtube.com.", A, answer), path)
+					}
+				})
+			})
 			When("Request does not contain a valid DNS message", func() {
 				It("should return 'Bad Request'", func() {
 					resp, err := http.Get(queryURL + "?dns=xxxx")
@@ -720,6 +1054,33 @@ var _ = Describe("Running DNS server", func() {
 				Expect(protocol).Should(Equal(model.RequestProtocolTCP))
 			})
 		})
+		Context("Unix address", func() {
+			It("should use the loopback address", func() {
+				ip, protocol := resolveClientIPAndProtocol(&net.UnixAddr{Name: "@", Net: "unix"})
+				Expect(ip.String()).Should(Equal("127.0.0.1"))
+				Expect(protocol).Should(Equal(model.RequestProtocolTCP))
+			})
+		})
+	})
+
+	Describe("EDNS of a request", func() {
+		It("should be nil without OPT record", func() {
+			_, req := newRequest(ctx, net.ParseIP("192.168.178.88"), "", model.RequestProtocolUDP,
+				util.NewMsgWithQuestion("example.com.", A))
+
+			Expect(req.EDNS).Should(BeNil())
+		})
+
+		It("should record the options as received from the client", func() {
+			msg := util.NewMsgWithQuestion("example.com.", A)
+			msg.SetEdns0(1232, true)
+			util.SetEdns0Option(msg, &dns.EDNS0_SUBNET{Code: dns.EDNS0SUBNET, Family: 1, Address: net.ParseIP("192.0.2.0")})
+			util.SetEdns0Option(msg, &dns.EDNS0_COOKIE{Code: dns.EDNS0COOKIE, Cookie: "0123456789abcdef"})
+
+			_, req := newRequest(ctx, net.ParseIP("192.168.178.88"), "", model.RequestProtocolUDP, msg)
+
+			Expect(req.EDNS).Should(Equal(&model.EDNS{UDPSize: 1232, DO: true, ECS: true, Cookie: true}))
+		})
 	})
 
 	Describe("self-signed certificate creation", func() {
@@ -744,9 +1105,64 @@ var _ = Describe("Running DNS server", func() {
 				HTTPS: []string{":0"},
 			}
 
-			sut, err := newTLSConfig(&cfg)
+			dot, doh, err := newTLSConfigs(&cfg, nil)
+			Expect(err).Should(Succeed())
+			Expect(dot.Certificates).ShouldNot(BeEmpty())
+			Expect(doh.Certificates).ShouldNot(BeEmpty())
+		})
+
+		It("should use the certificate of the ACME manager if enabled", func() {
+			cfg.ACME = config.ACME{Domains: []string{"dns.example.com"}, AcceptTOS: true, Storage: GinkgoT().TempDir()}
+
+			certManager, err := acme.New(cfg.ACME)
+			Expect(err).Should(Succeed())
+
+			dot, doh, err := newTLSConfigs(&cfg, certManager.GetCertificate)
+			Expect(err).Should(Succeed())
+			Expect(dot.Certificates).Should(BeEmpty())
+
+			cert, err := doh.GetCertificate(nil)
+			Expect(err).Should(Succeed())
+			Expect(cert.Leaf.DNSNames).Should(Equal([]string{"dns.example.com"}))
+		})
+	})
+
+	Describe("TLS policy", func() {
+		var cfg config.Config
+
+		BeforeEach(func() {
+			Expect(defaults.Set(&cfg)).Should(Succeed())
+		})
+
+		It("should apply global settings and per listener overrides", func() {
+			cfg.TLS = config.TLS{
+				TLSPolicy: config.TLSPolicy{
+					CipherSuites: []config.TLSCipherSuite{config.TLSCipherSuite(tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256)},
+					Curves:       []config.TLSCurve{config.TLSCurve(tls.X25519)},
+				},
+				DoT: &config.TLSPolicy{
+					MinVersion: config.TLSVersion13,
+					ClientAuth: config.TLSClientAuthRequire,
+				},
+			}
+
+			dot, doh, err := newTLSConfigs(&cfg, nil)
 			Expect(err).Should(Succeed())
-			Expect(sut.Certificates).ShouldNot(BeEmpty())
+
+			Expect(dot.MinVersion).Should(BeEquivalentTo(tls.VersionTLS13))
+			Expect(dot.ClientAuth).Should(Equal(tls.RequireAnyClientCert))
+			Expect(dot.CurvePreferences).Should(Equal([]tls.CurveID{tls.X25519}))
+
+			Expect(doh.MinVersion).Should(BeEquivalentTo(tls.VersionTLS12))
+			Expect(doh.ClientAuth).Should(Equal(tls.NoClientCert))
+			Expect(doh.CipherSuites).Should(Equal([]uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}))
+		})
+
+		It("should fail if the client CA file can't be loaded", func() {
+			cfg.TLS.DoH = &config.TLSPolicy{CAFile: "/does/not/exist"}
+
+			_, _, err := newTLSConfigs(&cfg, nil)
+			Expect(err).Should(HaveOccurred())
 		})
 	})
 })
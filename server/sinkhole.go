@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/0xERR0R/blocky/resolver"
+	"github.com/0xERR0R/blocky/sinkhole"
+)
+
+// registerSinkhole serves the block page on the sinkhole listeners
+func (s *Server) registerSinkhole(ctx context.Context, tlsCfg *tls.Config) error {
+	cfg := s.cfg.Sinkhole
+	if !cfg.IsEnabled() {
+		return nil
+	}
+
+	allower, err := resolver.GetFromChainWithType[sinkhole.DomainAllower](s.queryResolver)
+	if err != nil {
+		return fmt.Errorf("no blocking implementation found for the sinkhole: %w", err)
+	}
+
+	handler, err := sinkhole.New(ctx, cfg, s.cfg.Blocking.BlockTTL, allower)
+	if err != nil {
+		return err
+	}
+
+	// the sinkhole answers requests for any path of the blocked domains, the API middlewares don't apply
+	srv := newHTTPServer("sinkhole", handler, s.cfg)
+	srv.inner.Handler = handler
+
+	httpListeners, err := newTCPListeners("sinkhole http", cfg.HTTP, s.sockets)
+	if err != nil {
+		return err
+	}
+
+	httpsListeners, err := newTCPListeners("sinkhole https", cfg.HTTPS, s.sockets)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range httpListeners {
+		s.servers[l] = srv
+	}
+
+	for _, l := range httpsListeners {
+		s.servers[tls.NewListener(l, tlsCfg)] = srv
+	}
+
+	return nil
+}
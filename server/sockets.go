@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/0xERR0R/blocky/config"
+)
+
+// listenFdsStart is the first file descriptor passed by systemd, see sd_listen_fds(3)
+const listenFdsStart = 3
+
+// sockets creates the sockets of the listeners.
+// Sockets passed by systemd (socket activation) are used for the listeners with the same address, so systemd keeps
+// them open while blocky restarts. With `reusePort`, a new blocky process can bind the same addresses while the old one
+// is still running.
+type sockets struct {
+	reusePort bool
+
+	lock      sync.Mutex
+	inherited []*inheritedSocket
+}
+
+// inheritedSocket is a socket passed by systemd, either a stream listener or a packet connection
+type inheritedSocket struct {
+	listener   net.Listener
+	packetConn net.PacketConn
+}
+
+func newSockets(cfg config.Ports) *sockets {
+	return &sockets{
+		reusePort: cfg.ReusePort,
+		inherited: inheritSockets(),
+	}
+}
+
+// inheritSockets returns the sockets passed by systemd
+func inheritSockets() []*inheritedSocket {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil
+	}
+
+	// the sockets are meant for this process only, not for its children
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	res := make([]*inheritedSocket, 0, count)
+
+	for fd := listenFdsStart; fd < listenFdsStart+count; fd++ {
+		socket, err := newInheritedSocket(os.NewFile(uintptr(fd), fmt.Sprintf("LISTEN_FD_%d", fd)))
+		if err != nil {
+			logger().Warnf("ignoring socket %d passed by systemd: %v", fd, err)
+
+			continue
+		}
+
+		logger().Infof("using socket %s passed by systemd", socket.addr())
+
+		res = append(res, socket)
+	}
+
+	return res
+}
+
+func newInheritedSocket(file *os.File) (*inheritedSocket, error) {
+	// the listener and connection use a duplicate of the file descriptor
+	defer file.Close()
+
+	if listener, err := net.FileListener(file); err == nil {
+		return &inheritedSocket{listener: listener}, nil
+	}
+
+	conn, err := net.FilePacketConn(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inheritedSocket{packetConn: conn}, nil
+}
+
+func (s *inheritedSocket) addr() net.Addr {
+	if s.listener != nil {
+		return s.listener.Addr()
+	}
+
+	return s.packetConn.LocalAddr()
+}
+
+func (s *inheritedSocket) close() error {
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+
+	return s.packetConn.Close()
+}
+
+// listen returns a stream listener ("tcp" or "unix") on the address
+func (s *sockets) listen(network, address string) (net.Listener, error) {
+	if socket := s.take(network, address); socket != nil {
+		return socket.listener, nil
+	}
+
+	if network == "unix" {
+		// remove the socket of a previous run which was not shut down cleanly
+		if fi, err := os.Stat(address); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			if err := os.Remove(address); err != nil {
+				return nil, fmt.Errorf("can't remove stale unix socket %s: %w", address, err)
+			}
+		}
+	}
+
+	return s.listenConfig(network).Listen(context.Background(), network, address)
+}
+
+// listenPacket returns a UDP connection on the address
+func (s *sockets) listenPacket(address string) (net.PacketConn, error) {
+	if socket := s.take("udp", address); socket != nil {
+		return socket.packetConn, nil
+	}
+
+	return s.listenConfig("udp").ListenPacket(context.Background(), "udp", address)
+}
+
+func (s *sockets) listenConfig(network string) *net.ListenConfig {
+	var lc net.ListenConfig
+
+	if s.reusePort && network != "unix" {
+		lc.Control = reusePortControl
+	}
+
+	return &lc
+}
+
+// take returns the inherited socket of the address and removes it from the inherited sockets, nil if there is none
+func (s *sockets) take(network, address string) *inheritedSocket {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, socket := range s.inherited {
+		if isSocketOf(socket, network, address) {
+			s.inherited = slices.Delete(s.inherited, i, i+1)
+
+			return socket
+		}
+	}
+
+	return nil
+}
+
+// closeUnused closes the inherited sockets which are not used by any listener
+func (s *sockets) closeUnused() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, socket := range s.inherited {
+		logger().Warnf("closing socket %s passed by systemd, no listener is configured for it", socket.addr())
+
+		_ = socket.close()
+	}
+
+	s.inherited = nil
+}
+
+// isSocketOf returns true if the socket is bound to the configured address, an empty host matches all addresses
+func isSocketOf(socket *inheritedSocket, network, address string) bool {
+	var (
+		ip   net.IP
+		port int
+	)
+
+	switch addr := socket.addr().(type) {
+	case *net.UnixAddr:
+		return network == "unix" && socket.listener != nil && addr.Name == address
+	case *net.TCPAddr:
+		if network != "tcp" || socket.listener == nil {
+			return false
+		}
+
+		ip, port = addr.IP, addr.Port
+	case *net.UDPAddr:
+		if network != "udp" || socket.packetConn == nil {
+			return false
+		}
+
+		ip, port = addr.IP, addr.Port
+	default:
+		return false
+	}
+
+	host, configuredPort, err := net.SplitHostPort(address)
+	if err != nil || configuredPort != strconv.Itoa(port) {
+		return false
+	}
+
+	if host == "" {
+		return ip.IsUnspecified()
+	}
+
+	return net.ParseIP(host).Equal(ip)
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sockets", func() {
+	var sut *sockets
+
+	BeforeEach(func() {
+		sut = newSockets(config.Ports{})
+	})
+
+	port := func(addr net.Addr) string {
+		_, port, err := net.SplitHostPort(addr.String())
+		Expect(err).Should(Succeed())
+
+		return port
+	}
+
+	When("systemd doesn't pass sockets", func() {
+		It("should create new sockets", func() {
+			Expect(sut.inherited).Should(BeEmpty())
+
+			listener, err := sut.listen("tcp", "127.0.0.1:0")
+			Expect(err).Should(Succeed())
+			DeferCleanup(listener.Close)
+
+			conn, err := sut.listenPacket("127.0.0.1:0")
+			Expect(err).Should(Succeed())
+			DeferCleanup(conn.Close)
+		})
+
+		It("should ignore sockets of other processes", func() {
+			GinkgoT().Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+			GinkgoT().Setenv("LISTEN_FDS", "1")
+
+			Expect(inheritSockets()).Should(BeEmpty())
+		})
+	})
+
+	When("systemd passes sockets", func() {
+		var tcp, unix *inheritedSocket
+
+		inherit := func(listener interface{ File() (*os.File, error) }) *inheritedSocket {
+			file, err := listener.File()
+			Expect(err).Should(Succeed())
+
+			socket, err := newInheritedSocket(file)
+			Expect(err).Should(Succeed())
+			DeferCleanup(func() { _ = socket.close() })
+
+			return socket
+		}
+
+		BeforeEach(func() {
+			tcpListener, err := net.ListenTCP("tcp", &net.TCPAddr{})
+			Expect(err).Should(Succeed())
+			DeferCleanup(tcpListener.Close)
+
+			tcp = inherit(tcpListener)
+
+			socketPath := filepath.Join(NewTmpFolder("sockets").Path, "dns.sock")
+
+			unixListener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+			Expect(err).Should(Succeed())
+			DeferCleanup(unixListener.Close)
+
+			unix = inherit(unixListener)
+
+			sut.inherited = []*inheritedSocket{tcp, unix}
+		})
+
+		It("should use the socket with the same address", func() {
+			address := ":" + port(tcp.addr())
+
+			conn, err := sut.listenPacket(address)
+			Expect(err).Should(Succeed())
+			Expect(conn.Close()).Should(Succeed())
+
+			listener, err := sut.listen("tcp", address)
+			Expect(err).Should(Succeed())
+			Expect(listener).Should(BeIdenticalTo(tcp.listener))
+
+			listener, err = sut.listen("unix", unix.addr().String())
+			Expect(err).Should(Succeed())
+			Expect(listener).Should(BeIdenticalTo(unix.listener))
+
+			Expect(sut.inherited).Should(BeEmpty())
+		})
+
+		It("should close the unused sockets", func() {
+			sut.closeUnused()
+
+			Expect(sut.inherited).Should(BeEmpty())
+
+			_, err := tcp.listener.Accept()
+			Expect(err).Should(MatchError(net.ErrClosed))
+		})
+	})
+
+	Describe("isSocketOf", func() {
+		It("should match the configured address", func() {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).Should(Succeed())
+			DeferCleanup(conn.Close)
+
+			socket := &inheritedSocket{packetConn: conn}
+			p := port(conn.LocalAddr())
+
+			Expect(isSocketOf(socket, "udp", "127.0.0.1:"+p)).Should(BeTrue())
+			Expect(isSocketOf(socket, "udp", "[::ffff:127.0.0.1]:"+p)).Should(BeTrue())
+			Expect(isSocketOf(socket, "udp", ":"+p)).Should(BeFalse())
+			Expect(isSocketOf(socket, "udp", "127.0.0.2:"+p)).Should(BeFalse())
+			Expect(isSocketOf(socket, "tcp", "127.0.0.1:"+p)).Should(BeFalse())
+		})
+	})
+
+	Describe("reusePort", func() {
+		It("should allow multiple listeners on the same address", func() {
+			sut.reusePort = true
+
+			first, err := sut.listen("tcp", "127.0.0.1:0")
+			Expect(err).Should(Succeed())
+			DeferCleanup(first.Close)
+
+			second, err := sut.listen("tcp", first.Addr().String())
+			Expect(err).Should(Succeed())
+			DeferCleanup(second.Close)
+
+			conn, err := sut.listenPacket("127.0.0.1:0")
+			Expect(err).Should(Succeed())
+			DeferCleanup(conn.Close)
+
+			conn2, err := sut.listenPacket(conn.LocalAddr().String())
+			Expect(err).Should(Succeed())
+			DeferCleanup(conn2.Close)
+		})
+	})
+})
@@ -0,0 +1,24 @@
+//go:build !windows
+// +build !windows
+
+package server
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// reusePortControl sets SO_REUSEPORT, so multiple processes can bind the same address
+func reusePortControl(_, _ string, c syscall.RawConn) error {
+	var opErr error
+
+	err := c.Control(func(fd uintptr) {
+		opErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if err != nil {
+		return err
+	}
+
+	return opErr
+}
@@ -0,0 +1,10 @@
+package server
+
+import (
+	"errors"
+	"syscall"
+)
+
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return errors.New("ports.reusePort is not supported on Windows")
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+
+	"github.com/miekg/dns"
+)
+
+// unit of the timeout of the EDNS TCP keepalive option
+const tcpKeepaliveUnit = 100 * time.Millisecond
+
+// tcpConnections manages the connections of the TCP and DoT servers (RFC 7766, RFC 7828):
+// the number of open connections, the queries per connection and how long idle connections are kept open.
+type tcpConnections struct {
+	maxConnections uint
+	maxQueries     uint
+	idleTimeout    time.Duration
+	keepalive      bool
+
+	// shared by all listeners
+	slots chan struct{}
+}
+
+func newTCPConnections(cfg config.Ports) *tcpConnections {
+	c := &tcpConnections{
+		maxConnections: cfg.TCPMaxConnections,
+		maxQueries:     cfg.TCPMaxQueries,
+		idleTimeout:    cfg.TCPIdleTimeout.ToDuration(),
+		keepalive:      cfg.TCPKeepalive,
+	}
+
+	if c.maxConnections > 0 {
+		c.slots = make(chan struct{}, c.maxConnections)
+	}
+
+	return c
+}
+
+func isStreamServer(srv *dns.Server) bool {
+	return srv.Net == "tcp" || srv.Net == "tcp-tls"
+}
+
+// wrap applies the query limit and idle timeout to the server if it is a TCP or DoT server
+// and returns the handler to use
+func (c *tcpConnections) wrap(srv *dns.Server, handler dns.HandlerFunc) dns.HandlerFunc {
+	if !isStreamServer(srv) {
+		return handler
+	}
+
+	srv.MaxTCPQueries = -1
+	if c.maxQueries > 0 {
+		srv.MaxTCPQueries = int(c.maxQueries)
+	}
+
+	srv.IdleTimeout = func() time.Duration {
+		return c.idleTimeout
+	}
+
+	if !c.keepalive {
+		return handler
+	}
+
+	return func(w dns.ResponseWriter, msg *dns.Msg) {
+		if !hasTCPKeepalive(msg) {
+			handler(w, msg)
+
+			return
+		}
+
+		handler(&keepaliveWriter{ResponseWriter: w, timeout: c.keepaliveTimeout()}, msg)
+	}
+}
+
+func (c *tcpConnections) limited() bool {
+	return c.slots != nil
+}
+
+// limit returns the listener to use to limit the number of connections
+func (c *tcpConnections) limit(listener net.Listener) net.Listener {
+	if !c.limited() {
+		return listener
+	}
+
+	return &limitedListener{Listener: listener, slots: c.slots}
+}
+
+// keepaliveTimeout returns the idle timeout in units of the EDNS TCP keepalive option
+func (c *tcpConnections) keepaliveTimeout() uint16 {
+	return uint16(min(c.idleTimeout/tcpKeepaliveUnit, math.MaxUint16))
+}
+
+func hasTCPKeepalive(msg *dns.Msg) bool {
+	opt := msg.IsEdns0()
+	if opt == nil {
+		return false
+	}
+
+	for _, o := range opt.Option {
+		if o.Option() == dns.EDNS0TCPKEEPALIVE {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keepaliveWriter answers a query with the EDNS TCP keepalive option with the idle timeout of the server
+type keepaliveWriter struct {
+	dns.ResponseWriter
+
+	timeout uint16
+}
+
+func (w *keepaliveWriter) Unwrap() dns.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *keepaliveWriter) WriteMsg(msg *dns.Msg) error {
+	msg = msg.Copy()
+
+	opt := msg.IsEdns0()
+	if opt == nil {
+		msg.SetEdns0(maxUDPBufferSize, false)
+		opt = msg.IsEdns0()
+	}
+
+	options := opt.Option[:0]
+
+	for _, o := range opt.Option {
+		// the option of an upstream response is about another connection
+		if o.Option() != dns.EDNS0TCPKEEPALIVE {
+			options = append(options, o)
+		}
+	}
+
+	opt.Option = append(options, &dns.EDNS0_TCP_KEEPALIVE{Code: dns.EDNS0TCPKEEPALIVE, Timeout: w.timeout})
+
+	return w.ResponseWriter.WriteMsg(msg)
+}
+
+// limitedListener closes accepted connections exceeding the maximum number of open connections,
+// instead of letting them wait for a free slot, so clients can try another server or transport
+type limitedListener struct {
+	net.Listener
+
+	slots chan struct{}
+}
+
+func (l *limitedListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		select {
+		case l.slots <- struct{}{}:
+			return &limitedConn{Conn: conn, slots: l.slots}, nil
+		default:
+			logger().Debugf("closing TCP connection of %s: maximum number of connections reached", conn.RemoteAddr())
+
+			conn.Close()
+		}
+	}
+}
+
+// limitedConn frees its slot when it is closed
+type limitedConn struct {
+	net.Conn
+
+	slots chan struct{}
+	once  sync.Once
+}
+
+func (c *limitedConn) Close() error {
+	c.once.Do(func() {
+		<-c.slots
+	})
+
+	return c.Conn.Close()
+}
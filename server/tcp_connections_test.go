@@ -0,0 +1,174 @@
+package server
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TCP connections", func() {
+	var (
+		cfg  config.Ports
+		sut  *tcpConnections
+		srv  *dns.Server
+		addr string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		cfg, err = config.WithDefaults[config.Ports]()
+		Expect(err).Should(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		sut = newTCPConnections(cfg)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).Should(Succeed())
+
+		addr = listener.Addr().String()
+
+		srv = &dns.Server{Listener: sut.limit(listener), Net: "tcp", Handler: dns.NewServeMux()}
+
+		srv.Handler.(*dns.ServeMux).HandleFunc(".", sut.wrap(srv, func(w dns.ResponseWriter, m *dns.Msg) {
+			resp := new(dns.Msg)
+			resp.SetReply(m)
+
+			_ = w.WriteMsg(resp)
+		}))
+
+		go func() {
+			defer GinkgoRecover()
+
+			Expect(srv.ActivateAndServe()).Should(Succeed())
+		}()
+
+		DeferCleanup(srv.Shutdown)
+	})
+
+	dial := func() *dns.Conn {
+		conn, err := dns.Dial("tcp", addr)
+		Expect(err).Should(Succeed())
+		DeferCleanup(func() { _ = conn.Close() })
+
+		return conn
+	}
+
+	exchange := func(conn *dns.Conn, msg *dns.Msg) (*dns.Msg, error) {
+		if err := conn.WriteMsg(msg); err != nil {
+			return nil, err
+		}
+
+		return conn.ReadMsg()
+	}
+
+	keepaliveQuery := func() *dns.Msg {
+		msg := util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA))
+		msg.SetEdns0(dns.DefaultMsgSize, false)
+		opt := msg.IsEdns0()
+		opt.Option = append(opt.Option, &dns.EDNS0_TCP_KEEPALIVE{Code: dns.EDNS0TCPKEEPALIVE})
+
+		return msg
+	}
+
+	Describe("EDNS TCP keepalive", func() {
+		It("should answer with the idle timeout if the query has the option", func() {
+			resp, err := exchange(dial(), keepaliveQuery())
+			Expect(err).Should(Succeed())
+
+			// 8s in units of 100ms
+			Expect(resp.IsEdns0().Option).Should(ConsistOf(
+				And(BeAssignableToTypeOf(&dns.EDNS0_TCP_KEEPALIVE{}), HaveField("Timeout", uint16(80))),
+			))
+		})
+
+		It("should not add the option to other responses", func() {
+			resp, err := exchange(dial(), util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA)))
+			Expect(err).Should(Succeed())
+
+			Expect(resp.IsEdns0()).Should(BeNil())
+		})
+
+		When("it is disabled", func() {
+			BeforeEach(func() {
+				cfg.TCPKeepalive = false
+			})
+
+			It("should not answer with the option", func() {
+				resp, err := exchange(dial(), keepaliveQuery())
+				Expect(err).Should(Succeed())
+
+				Expect(resp.IsEdns0()).Should(BeNil())
+			})
+		})
+	})
+
+	When("the connection is idle", func() {
+		BeforeEach(func() {
+			cfg.TCPIdleTimeout = config.Duration(100 * time.Millisecond)
+		})
+
+		It("should be closed after the idle timeout", func() {
+			conn := dial()
+
+			_, err := exchange(conn, util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA)))
+			Expect(err).Should(Succeed())
+
+			start := time.Now()
+
+			_, err = conn.ReadMsg()
+			Expect(err).Should(MatchError(io.EOF))
+			Expect(time.Since(start)).Should(BeNumerically("<", time.Second))
+		})
+	})
+
+	When("the queries per connection are limited", func() {
+		BeforeEach(func() {
+			cfg.TCPMaxQueries = 2
+		})
+
+		It("should close the connection after the maximum number of queries", func() {
+			conn := dial()
+
+			for range 2 {
+				_, err := exchange(conn, util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA)))
+				Expect(err).Should(Succeed())
+			}
+
+			_, err := conn.ReadMsg()
+			Expect(err).Should(MatchError(io.EOF))
+		})
+	})
+
+	When("the connections are limited", func() {
+		BeforeEach(func() {
+			cfg.TCPMaxConnections = 1
+		})
+
+		It("should close further connections until a connection is closed", func() {
+			first := dial()
+
+			_, err := exchange(first, util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA)))
+			Expect(err).Should(Succeed())
+
+			_, err = exchange(dial(), util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA)))
+			Expect(err).Should(HaveOccurred())
+
+			Expect(first.Close()).Should(Succeed())
+
+			Eventually(func() error {
+				_, err := exchange(dial(), util.NewMsgWithQuestion("example.com.", dns.Type(dns.TypeA)))
+
+				return err
+			}).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/watchdog"
+
+	"github.com/miekg/dns"
+)
+
+var (
+	errTCPQueryLimit  = errors.New("maximum number of queries per connection reached")
+	errConcurrentTSIG = errors.New("TSIG state of a pipelined message is unknown")
+)
+
+// tcpPipelining processes multiple queries of a TCP/DoT connection concurrently and answers them out of order
+// (RFC 7766, section 6.2.1.1), instead of one after the other.
+//
+// The handler dispatches each query to its own goroutine. The reader of a connection waits for all in-flight queries
+// before it reports the end of the connection, so `dns.Server` does not close it before all queries are answered.
+//
+// `dns.Server` uses one writer per connection and resets its TSIG state for each message. Signed messages, updates
+// and zone transfers are therefore handled before the next message is read.
+type tcpPipelining struct {
+	maxInFlight uint
+	maxQueries  uint // 0 for no limit
+
+	conns sync.Map // connection key -> *pipelinedConn
+}
+
+type pipelinedConn struct {
+	slots    chan struct{} // limits the in-flight queries
+	inFlight sync.WaitGroup
+	queries  uint
+}
+
+// newTCPPipelining returns nil if `maxInFlight` disables pipelining
+func newTCPPipelining(maxInFlight, maxQueries uint) *tcpPipelining {
+	if maxInFlight <= 1 {
+		return nil
+	}
+
+	return &tcpPipelining{maxInFlight: maxInFlight, maxQueries: maxQueries}
+}
+
+// wrap enables pipelining for the server if it is a TCP or DoT server and returns the handler to use
+func (p *tcpPipelining) wrap(srv *dns.Server, handler dns.HandlerFunc) dns.HandlerFunc {
+	if p == nil || (srv.Net != "tcp" && srv.Net != "tcp-tls") {
+		return handler
+	}
+
+	// the limit is enforced by the reader, `dns.Server` would close the connection with queries in-flight
+	srv.MaxTCPQueries = -1
+	srv.DecorateReader = func(reader dns.Reader) dns.Reader {
+		return &pipelinedReader{Reader: reader, p: p}
+	}
+
+	return p.dispatch(handler)
+}
+
+func (p *tcpPipelining) dispatch(handler dns.HandlerFunc) dns.HandlerFunc {
+	return func(w dns.ResponseWriter, msg *dns.Msg) {
+		v, ok := p.conns.Load(connKey(w.LocalAddr(), w.RemoteAddr()))
+		if !ok {
+			handler(w, msg)
+
+			return
+		}
+
+		if isSequential(msg) {
+			handler(w, msg)
+
+			return
+		}
+
+		conn := v.(*pipelinedConn)
+
+		// the state of the connection's writer belongs to the next message while the query is handled
+		qw := &pipelinedWriter{ResponseWriter: w, tsigStatus: w.TsigStatus()}
+
+		// blocks reading the next query while the connection has `maxInFlight` queries
+		conn.slots <- struct{}{}
+		conn.inFlight.Add(1)
+
+		go func() {
+			defer watchdog.TrackWorker("tcp_pipelining")()
+			defer func() {
+				<-conn.slots
+				conn.inFlight.Done()
+			}()
+
+			// writes of `dns.ResponseWriter` are a single write to the connection, safe for concurrent use
+			handler(qw, msg)
+		}()
+	}
+}
+
+// isSequential returns true if the message must be handled before the next message of the connection is read
+func isSequential(msg *dns.Msg) bool {
+	if msg.IsTsig() != nil || msg.Opcode != dns.OpcodeQuery {
+		return true
+	}
+
+	for _, q := range msg.Question {
+		if q.Qtype == dns.TypeAXFR || q.Qtype == dns.TypeIXFR {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pipelinedWriter is the writer of a pipelined query, with the TSIG status captured when the query was read
+type pipelinedWriter struct {
+	dns.ResponseWriter
+
+	tsigStatus error
+}
+
+// TsigStatus implements `dns.ResponseWriter`.
+func (w *pipelinedWriter) TsigStatus() error {
+	return w.tsigStatus
+}
+
+func (w *pipelinedWriter) Unwrap() dns.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type pipelinedReader struct {
+	dns.Reader
+
+	p *tcpPipelining
+}
+
+// ReadTCP implements `dns.Reader`.
+func (r *pipelinedReader) ReadTCP(conn net.Conn, timeout time.Duration) ([]byte, error) {
+	key := connKey(conn.LocalAddr(), conn.RemoteAddr())
+
+	v, _ := r.p.conns.LoadOrStore(key, &pipelinedConn{slots: make(chan struct{}, r.p.maxInFlight)})
+	state := v.(*pipelinedConn)
+
+	var (
+		msg []byte
+		err error
+	)
+
+	if r.p.maxQueries == 0 || state.queries < r.p.maxQueries {
+		msg, err = r.Reader.ReadTCP(conn, timeout)
+	} else {
+		err = errTCPQueryLimit
+	}
+
+	if err != nil {
+		// the connection gets closed after this
+		state.inFlight.Wait()
+		r.p.conns.Delete(key)
+
+		return nil, err
+	}
+
+	state.queries++
+
+	return msg, nil
+}
+
+func connKey(local, remote net.Addr) string {
+	return local.String() + "|" + remote.String()
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// tsigWriter is a dns.ResponseWriter with a TSIG status
+type tsigWriter struct {
+	dns.ResponseWriter
+
+	status error
+}
+
+func (w *tsigWriter) TsigStatus() error {
+	return w.status
+}
+
+var _ = Describe("TCP pipelining", func() {
+	var (
+		pipelining *tcpPipelining
+		conn       *dns.Conn
+		tsigSecret map[string]string
+	)
+
+	const (
+		slowDelay  = 200 * time.Millisecond
+		maxQueries = 32
+	)
+
+	query := func(name string, id uint16) {
+		msg := new(dns.Msg)
+		msg.SetQuestion(name, dns.TypeA)
+		msg.Id = id
+
+		Expect(conn.WriteMsg(msg)).Should(Succeed())
+	}
+
+	readIDs := func(count int) []uint16 {
+		ids := make([]uint16, 0, count)
+
+		for range count {
+			msg, err := conn.ReadMsg()
+			Expect(err).Should(Succeed())
+
+			ids = append(ids, msg.Id)
+		}
+
+		return ids
+	}
+
+	BeforeEach(func() {
+		pipelining = newTCPPipelining(16, maxQueries)
+		tsigSecret = nil
+	})
+
+	JustBeforeEach(func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).Should(Succeed())
+
+		srv := &dns.Server{Listener: listener, Net: "tcp", Handler: dns.NewServeMux(), TsigSecret: tsigSecret}
+
+		srv.Handler.(*dns.ServeMux).HandleFunc(".", pipelining.wrap(srv, func(w dns.ResponseWriter, m *dns.Msg) {
+			if m.Question[0].Name == "slow." || m.IsTsig() != nil {
+				time.Sleep(slowDelay)
+			}
+
+			resp := new(dns.Msg)
+			resp.SetReply(m)
+
+			if w.TsigStatus() != nil {
+				resp.Rcode = dns.RcodeRefused
+			}
+
+			_ = w.WriteMsg(resp)
+		}))
+
+		go func() {
+			defer GinkgoRecover()
+
+			Expect(srv.ActivateAndServe()).Should(Succeed())
+		}()
+
+		DeferCleanup(srv.Shutdown)
+
+		conn, err = dns.Dial("tcp", listener.Addr().String())
+		Expect(err).Should(Succeed())
+		DeferCleanup(conn.Close)
+	})
+
+	It("should answer pipelined queries out of order", func() {
+		query("slow.", 1)
+		query("fast.", 2)
+
+		Expect(readIDs(2)).Should(Equal([]uint16{2, 1}))
+	})
+
+	It("should answer all queries before closing the connection", func() {
+		query("slow.", 1)
+		query("slow.", 2)
+
+		Expect(conn.Conn.(*net.TCPConn).CloseWrite()).Should(Succeed())
+
+		Expect(readIDs(2)).Should(ConsistOf(uint16(1), uint16(2)))
+	})
+
+	It("should close the connection after the maximum number of queries", func() {
+		for i := range maxQueries {
+			query("fast.", uint16(i))
+		}
+
+		Expect(readIDs(maxQueries)).Should(HaveLen(maxQueries))
+
+		_, err := conn.ReadMsg()
+		Expect(err).Should(MatchError(io.EOF))
+	})
+
+	When("a query is signed", func() {
+		BeforeEach(func() {
+			tsigSecret = map[string]string{"key.": "c2VjcmV0"}
+		})
+
+		It("should keep the TSIG status of the message until it is answered", func() {
+			signed := new(dns.Msg)
+			signed.SetQuestion("fast.", dns.TypeA)
+			signed.Id = 1
+			signed.SetTsig("key.", dns.HmacSHA256, 300, time.Now().Unix())
+
+			// signed with another secret than the server's
+			conn.TsigSecret = map[string]string{"key.": "b3RoZXI="}
+			Expect(conn.WriteMsg(signed)).Should(Succeed())
+
+			query("fast.", 2)
+
+			first, err := conn.ReadMsg()
+			Expect(err).Should(Succeed())
+			Expect(first.Id).Should(BeEquivalentTo(1))
+			Expect(first.Rcode).Should(Equal(dns.RcodeRefused))
+
+			second, err := conn.ReadMsg()
+			Expect(err).Should(Succeed())
+			Expect(second.Id).Should(BeEquivalentTo(2))
+			Expect(second.Rcode).Should(Equal(dns.RcodeSuccess))
+		})
+	})
+
+	Describe("tsigStatus", func() {
+		It("should not trust the status of pipelined messages", func() {
+			w := &pipelinedWriter{ResponseWriter: &recordingWriter{}}
+
+			Expect(tsigStatus(w)).Should(MatchError(errConcurrentTSIG))
+			Expect(tsigStatus(&cookieWriter{ResponseWriter: w})).Should(MatchError(errConcurrentTSIG))
+		})
+
+		It("should return the status of the writer of other messages", func() {
+			Expect(tsigStatus(&cookieWriter{ResponseWriter: &tsigWriter{status: dns.ErrSig}})).Should(MatchError(dns.ErrSig))
+			Expect(tsigStatus(&tsigWriter{})).Should(Succeed())
+		})
+	})
+
+	When("pipelining is disabled", func() {
+		BeforeEach(func() {
+			pipelining = newTCPPipelining(1, maxQueries)
+		})
+
+		It("should answer the queries in order", func() {
+			query("slow.", 1)
+			query("fast.", 2)
+
+			Expect(readIDs(2)).Should(Equal([]uint16{1, 2}))
+		})
+	})
+})
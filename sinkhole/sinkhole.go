@@ -0,0 +1,248 @@
+// Package sinkhole serves a block page for HTTP(S) requests to blocked domains which resolve to the blocking IP
+package sinkhole
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/cache/expirationcache"
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/0xERR0R/blocky/util"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// AllowPath is the path the button of the page posts to
+	AllowPath = "/.blocky/allow"
+
+	maxReasons = 10_000
+)
+
+//go:embed page.html
+var defaultPage string
+
+// DomainAllower allows blocked domains temporarily for a single client
+type DomainAllower interface {
+	AllowDomainForClient(ctx context.Context, clientIP net.IP, domain string, duration time.Duration) error
+}
+
+// Sinkhole is the HTTP handler of the sinkhole listeners
+type Sinkhole struct {
+	cfg      config.Sinkhole
+	blockTTL config.Duration
+	allower  DomainAllower
+	page     *template.Template
+
+	// reasons of the latest blocked queries by client IP and domain, and by domain only
+	reasons *expirationcache.ExpiringLRUCache[string]
+}
+
+// page is the data of the template
+type page struct {
+	Domain string
+	// Reason of the block, e.g. "BLOCKED (ads)", empty if unknown
+	Reason string
+	// Error of the allow button
+	Error   string
+	Allowed bool
+	// Path of the allow button
+	AllowPath string
+	// Duration of the allow button, empty if it is disabled
+	AllowDuration string
+	BlockTTL      string
+}
+
+func logger() *logrus.Entry {
+	return log.PrefixedLog("sinkhole")
+}
+
+// New creates the handler and records the reasons of blocked queries until ctx is done
+func New(ctx context.Context, cfg config.Sinkhole, blockTTL config.Duration, allower DomainAllower) (*Sinkhole, error) {
+	text := defaultPage
+
+	if cfg.Template != "" {
+		data, err := os.ReadFile(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("can't read the sinkhole template: %w", err)
+		}
+
+		text = string(data)
+	}
+
+	tmpl, err := template.New("sinkhole").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse the sinkhole template: %w", err)
+	}
+
+	s := &Sinkhole{
+		cfg:      cfg,
+		blockTTL: blockTTL,
+		allower:  allower,
+		page:     tmpl,
+		reasons:  expirationcache.NewCache[string](ctx, expirationcache.Options{MaxSize: maxReasons}),
+	}
+
+	if err := evt.Bus().Subscribe(evt.BlockingQueryBlocked, s.onBlocked); err != nil {
+		return nil, fmt.Errorf("can't subscribe to blocked queries: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		_ = evt.Bus().Unsubscribe(evt.BlockingQueryBlocked, s.onBlocked)
+	}()
+
+	return s, nil
+}
+
+func reasonKey(clientIP net.IP, domain string) string {
+	if clientIP == nil {
+		return domain
+	}
+
+	return clientIP.String() + " " + domain
+}
+
+func (s *Sinkhole) onBlocked(clientIP net.IP, _ []string, domain, reason string) {
+	// the client's resolver forgets the blocked answer after the block TTL, it won't connect later
+	ttl := max(s.blockTTL.ToDuration(), time.Minute)
+
+	s.reasons.Put(reasonKey(clientIP, domain), &reason, ttl)
+	s.reasons.Put(reasonKey(nil, domain), &reason, ttl)
+}
+
+// reason returns the reason of the latest block of the domain for the client,
+// or for any client if it is unknown
+func (s *Sinkhole) reason(clientIP net.IP, domain string) string {
+	if reason, _ := s.reasons.Get(reasonKey(clientIP, domain)); reason != nil {
+		return *reason
+	}
+
+	if reason, _ := s.reasons.Get(reasonKey(nil, domain)); reason != nil {
+		return *reason
+	}
+
+	return ""
+}
+
+// ServeHTTP serves the block page for requests of web pages and refuses all other requests
+func (s *Sinkhole) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	domain := requestDomain(r)
+
+	if r.URL.Path == AllowPath && r.Method == http.MethodPost {
+		s.allow(w, r, domain)
+
+		return
+	}
+
+	// images, scripts, etc. of blocked domains don't need the page
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	s.render(w, http.StatusForbidden, s.newPage(r, domain))
+}
+
+// allow allows the domain with the button of the page
+func (s *Sinkhole) allow(w http.ResponseWriter, r *http.Request, domain string) {
+	if !s.cfg.AllowDuration.IsAboveZero() {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	// only the page itself may allow the domain, other sites can't post to it
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || !strings.EqualFold(u.Host, r.Host) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+			return
+		}
+	}
+
+	ip := clientIP(r)
+	p := s.newPage(r, domain)
+
+	// only the client the domain was blocked for may allow it, so the button can't be used to allow arbitrary domains
+	if reason, _ := s.reasons.Get(reasonKey(ip, domain)); reason == nil {
+		p.Error = "the domain wasn't blocked for this client"
+
+		s.render(w, http.StatusForbidden, p)
+
+		return
+	}
+
+	err := s.allower.AllowDomainForClient(r.Context(), ip, domain, s.cfg.AllowDuration.ToDuration())
+	if err != nil {
+		p.Error = err.Error()
+
+		s.render(w, http.StatusBadRequest, p)
+
+		return
+	}
+
+	logger().Infof("client %s allowed '%s' for %s", ip, log.EscapeInput(domain), s.cfg.AllowDuration)
+
+	p.Allowed = true
+
+	s.render(w, http.StatusOK, p)
+}
+
+func (s *Sinkhole) newPage(r *http.Request, domain string) page {
+	p := page{
+		Domain:    domain,
+		Reason:    s.reason(clientIP(r), domain),
+		AllowPath: AllowPath,
+		BlockTTL:  s.blockTTL.String(),
+	}
+
+	if s.cfg.AllowDuration.IsAboveZero() {
+		p.AllowDuration = s.cfg.AllowDuration.String()
+	}
+
+	return p
+}
+
+func (s *Sinkhole) render(w http.ResponseWriter, status int, p page) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	util.LogOnErrorWithEntry(logger(), "can't render the page: ", s.page.Execute(w, p))
+}
+
+// requestDomain returns the requested domain, the server name of TLS requests without host header
+func requestDomain(r *http.Request) string {
+	host := r.Host
+	if host == "" && r.TLS != nil {
+		host = r.TLS.ServerName
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return util.ExtractDomainOnly(host)
+}
+
+// clientIP returns the IP of the connection, the sinkhole is never behind a proxy
+func clientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return net.ParseIP(r.RemoteAddr)
+	}
+
+	return net.ParseIP(host)
+}
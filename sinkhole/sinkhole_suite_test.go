@@ -0,0 +1,18 @@
+package sinkhole
+
+import (
+	"testing"
+
+	"github.com/0xERR0R/blocky/log"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func init() {
+	log.Silence()
+}
+
+func TestSinkhole(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sinkhole Suite")
+}
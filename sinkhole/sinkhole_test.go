@@ -0,0 +1,217 @@
+package sinkhole
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	. "github.com/0xERR0R/blocky/helpertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type allowerMock struct {
+	clientIP net.IP
+	domain   string
+	duration time.Duration
+	err      error
+}
+
+func (m *allowerMock) AllowDomainForClient(
+	_ context.Context, clientIP net.IP, domain string, duration time.Duration,
+) error {
+	m.clientIP = clientIP
+	m.domain = domain
+	m.duration = duration
+
+	return m.err
+}
+
+var _ = Describe("Sinkhole", func() {
+	var (
+		ctx     context.Context
+		cfg     config.Sinkhole
+		allower *allowerMock
+		sut     *Sinkhole
+	)
+
+	BeforeEach(func() {
+		var cancelFn context.CancelFunc
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		cfg = config.Sinkhole{HTTP: config.ListenConfig{":80"}}
+		allower = &allowerMock{}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = New(ctx, cfg, config.Duration(time.Hour), allower)
+		Expect(err).Should(Succeed())
+	})
+
+	serve := func(method, target string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.RemoteAddr = "192.168.178.20:12345"
+
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		rec := httptest.NewRecorder()
+		sut.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	html := http.Header{"Accept": {"text/html,application/xhtml+xml,*/*;q=0.8"}}
+
+	It("should serve the page with the reason of the block", func() {
+		evt.Bus().Publish(evt.BlockingQueryBlocked,
+			net.ParseIP("192.168.178.20"), []string{"laptop"}, "ads.example.com", "BLOCKED (ads)")
+		evt.Bus().Publish(evt.BlockingQueryBlocked,
+			net.ParseIP("192.168.178.21"), []string{"phone"}, "ads.example.com", "BLOCKED (ads,kids)")
+
+		rec := serve(http.MethodGet, "http://ads.example.com/banner?id=1", html)
+
+		Expect(rec.Code).Should(Equal(http.StatusForbidden))
+		Expect(rec.Header().Get("Content-Type")).Should(HavePrefix("text/html"))
+		Expect(rec.Body.String()).Should(SatisfyAll(
+			ContainSubstring("ads.example.com is blocked"),
+			ContainSubstring("BLOCKED (ads)<"),
+			Not(ContainSubstring("<form"))))
+	})
+
+	It("should use the latest reason of any client if the client's is unknown", func() {
+		evt.Bus().Publish(evt.BlockingQueryBlocked,
+			net.ParseIP("192.168.178.21"), []string{"phone"}, "tracker.example.com", "BLOCKED (trackers)")
+
+		Expect(serve(http.MethodGet, "http://tracker.example.com:8080/", html).Body.String()).
+			Should(ContainSubstring("BLOCKED (trackers)"))
+	})
+
+	It("should not serve the page for other resources", func() {
+		rec := serve(http.MethodGet, "http://ads.example.com/banner.png", http.Header{"Accept": {"image/*"}})
+
+		Expect(rec.Code).Should(Equal(http.StatusForbidden))
+		Expect(rec.Body.String()).Should(BeEmpty())
+	})
+
+	It("should escape the domain", func() {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+		req.Host = "<script>"
+		req.Header.Set("Accept", "text/html")
+
+		rec := httptest.NewRecorder()
+		sut.ServeHTTP(rec, req)
+
+		Expect(rec.Body.String()).ShouldNot(ContainSubstring("<script>"))
+	})
+
+	It("should not allow domains if the button is disabled", func() {
+		Expect(serve(http.MethodPost, "http://ads.example.com"+AllowPath, nil).Code).Should(Equal(http.StatusNotFound))
+		Expect(allower.domain).Should(BeEmpty())
+	})
+
+	When("the allow button is enabled", func() {
+		BeforeEach(func() {
+			cfg.AllowDuration = config.Duration(15 * time.Minute)
+		})
+
+		JustBeforeEach(func() {
+			evt.Bus().Publish(evt.BlockingQueryBlocked,
+				net.ParseIP("192.168.178.20"), []string{"laptop"}, "ads.example.com", "BLOCKED (ads)")
+		})
+
+		It("should show the button", func() {
+			Expect(serve(http.MethodGet, "http://ads.example.com/", html).Body.String()).Should(SatisfyAll(
+				ContainSubstring(`action="`+AllowPath+`"`),
+				ContainSubstring("Allow for 15 minutes")))
+		})
+
+		It("should allow the domain", func() {
+			rec := serve(http.MethodPost, "http://ads.example.com"+AllowPath, http.Header{
+				"Origin": {"http://ads.example.com"},
+			})
+
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(SatisfyAll(
+				ContainSubstring("ads.example.com is allowed for 15 minutes"),
+				ContainSubstring("up to 1 hour")))
+			Expect(allower.clientIP).Should(Equal(net.ParseIP("192.168.178.20")))
+			Expect(allower.domain).Should(Equal("ads.example.com"))
+			Expect(allower.duration).Should(Equal(15 * time.Minute))
+		})
+
+		It("should only allow domains blocked for the client", func() {
+			evt.Bus().Publish(evt.BlockingQueryBlocked,
+				net.ParseIP("192.168.178.21"), []string{"phone"}, "tracker.example.com", "BLOCKED (ads)")
+
+			for _, target := range []string{"http://tracker.example.com", "http://example.com", "http://com"} {
+				rec := serve(http.MethodPost, target+AllowPath, nil)
+
+				Expect(rec.Code).Should(Equal(http.StatusForbidden), target)
+				Expect(rec.Body.String()).Should(ContainSubstring("wasn&#39;t blocked for this client"))
+			}
+
+			Expect(allower.domain).Should(BeEmpty())
+		})
+
+		It("should reject requests of other sites", func() {
+			rec := serve(http.MethodPost, "http://ads.example.com"+AllowPath, http.Header{
+				"Origin": {"https://evil.example.org"},
+			})
+
+			Expect(rec.Code).Should(Equal(http.StatusForbidden))
+			Expect(allower.domain).Should(BeEmpty())
+		})
+
+		It("should show the error", func() {
+			allower.err = errors.New("domain is empty")
+
+			rec := serve(http.MethodPost, "http://ads.example.com"+AllowPath, nil)
+
+			Expect(rec.Code).Should(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).Should(ContainSubstring("domain is empty"))
+		})
+	})
+
+	Describe("template", func() {
+		var tmpDir *TmpFolder
+
+		BeforeEach(func() {
+			tmpDir = NewTmpFolder("sinkhole")
+			DeferCleanup(tmpDir.Clean)
+		})
+
+		It("should use the configured template", func() {
+			cfg.Template = tmpDir.CreateStringFile("page.html", "custom page of {{ .Domain }}").Path
+
+			s, err := New(ctx, cfg, config.Duration(time.Hour), allower)
+			Expect(err).Should(Succeed())
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://ads.example.com/", nil)
+			req.Header.Set("Accept", "text/html")
+			s.ServeHTTP(rec, req)
+
+			Expect(strings.TrimSpace(rec.Body.String())).Should(Equal("custom page of ads.example.com"))
+		})
+
+		It("should fail if the template is invalid", func() {
+			cfg.Template = tmpDir.CreateStringFile("page.html", "{{ .Domain").Path
+
+			_, err := New(ctx, cfg, config.Duration(time.Hour), allower)
+			Expect(err).Should(MatchError(ContainSubstring("can't parse the sinkhole template")))
+		})
+	})
+})
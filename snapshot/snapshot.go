@@ -0,0 +1,228 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/util"
+)
+
+const (
+	fileExtension  = ".json"
+	nameLayout     = "20060102-150405"
+	dirPermission  = 0o750
+	filePermission = 0o640
+
+	// ReasonStart is used for snapshots taken on start
+	ReasonStart = "start"
+	// ReasonScheduled is used for snapshots taken every `snapshots.interval`
+	ReasonScheduled = "scheduled"
+	// ReasonRollback is used for snapshots taken before a rollback, to be able to undo it
+	ReasonRollback = "rollback"
+)
+
+// Snapshot is the configuration and runtime state of blocky at a point in time
+type Snapshot struct {
+	Name       string    `json:"name"`
+	Created    time.Time `json:"created"`
+	Reason     string    `json:"reason"`
+	ConfigHash string    `json:"configHash"`
+	Config     string    `json:"config"`
+	Blocking   Blocking  `json:"blocking"`
+}
+
+// Blocking is the runtime state of blocking, it may differ from the configuration via API
+type Blocking struct {
+	Enabled        bool     `json:"enabled"`
+	DisabledGroups []string `json:"disabledGroups,omitempty"`
+}
+
+// Store stores snapshots as JSON files in a directory
+type Store struct {
+	cfg config.Snapshots
+
+	// serializes the saves, so they don't take the same name
+	lock sync.Mutex
+}
+
+// NewStore creates a store for the snapshots directory of the configuration
+func NewStore(cfg config.Snapshots) *Store {
+	return &Store{cfg: cfg}
+}
+
+// Save stores the snapshot, named after its creation time and reason, and removes the oldest ones beyond `keep`.
+// Snapshots with the same name get a counter appended, so they don't overwrite each other.
+func (s *Store) Save(snapshot *Snapshot) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := os.MkdirAll(s.cfg.Directory, dirPermission); err != nil {
+		return fmt.Errorf("can't create snapshot directory: %w", err)
+	}
+
+	name, err := s.unusedName(fmt.Sprintf("%s-%s", snapshot.Created.UTC().Format(nameLayout), snapshot.Reason))
+	if err != nil {
+		return err
+	}
+
+	snapshot.Name = name
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't encode snapshot: %w", err)
+	}
+
+	err = util.WriteFileAtomic(filepath.Join(s.cfg.Directory, snapshot.Name+fileExtension), data, filePermission)
+	if err != nil {
+		return fmt.Errorf("can't write snapshot: %w", err)
+	}
+
+	return s.prune()
+}
+
+// unusedName returns the name, with a counter appended if a snapshot with the name exists
+func (s *Store) unusedName(name string) (string, error) {
+	res := name
+
+	for i := 2; ; i++ {
+		_, err := os.Stat(filepath.Join(s.cfg.Directory, res+fileExtension))
+		if errors.Is(err, fs.ErrNotExist) {
+			return res, nil
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("can't check snapshot name: %w", err)
+		}
+
+		res = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// List returns all snapshots, newest first
+func (s *Store) List() ([]Snapshot, error) {
+	names, err := s.names()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Snapshot, 0, len(names))
+
+	for _, name := range names {
+		snapshot, err := s.Load(name)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, *snapshot)
+	}
+
+	return res, nil
+}
+
+// Load returns the snapshot with the given name, the error wraps `fs.ErrNotExist` if there is none
+func (s *Store) Load(name string) (*Snapshot, error) {
+	if name == "" || filepath.Base(name) != name || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid snapshot name '%s': %w", name, fs.ErrNotExist)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.cfg.Directory, name+fileExtension))
+	if err != nil {
+		return nil, fmt.Errorf("can't read snapshot '%s': %w", name, err)
+	}
+
+	var snapshot Snapshot
+
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("can't decode snapshot '%s': %w", name, err)
+	}
+
+	return &snapshot, nil
+}
+
+// RestoreConfig writes the configuration of the snapshot to path, which must be a single file without includes.
+// The snapshot contains the merged configuration, so it can't restore the included files.
+func RestoreConfig(snapshot *Snapshot, path string) error {
+	if path == "" {
+		return errors.New("blocky was started without a configuration file")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("can't restore configuration: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("can't restore configuration into directory '%s', only a single file is supported", path)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("can't restore configuration: %w", err)
+	}
+
+	if config.HasIncludes(current) {
+		return fmt.Errorf("can't restore configuration into '%s', it includes other files", path)
+	}
+
+	err = util.WriteFileAtomic(path, []byte(snapshot.Config), info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("can't restore configuration: %w", err)
+	}
+
+	return nil
+}
+
+// names returns the names of all snapshots, newest first
+func (s *Store) names() ([]string, error) {
+	entries, err := os.ReadDir(s.cfg.Directory)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("can't list snapshots: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), fileExtension)
+		if ok && entry.Type().IsRegular() && !strings.HasPrefix(name, ".") {
+			names = append(names, name)
+		}
+	}
+
+	// names start with the creation time
+	slices.Sort(names)
+	slices.Reverse(names)
+
+	return names, nil
+}
+
+func (s *Store) prune() error {
+	if s.cfg.Keep == 0 {
+		return nil
+	}
+
+	names, err := s.names()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names[min(uint(len(names)), s.cfg.Keep):] {
+		if err := os.Remove(filepath.Join(s.cfg.Directory, name+fileExtension)); err != nil {
+			return fmt.Errorf("can't remove old snapshot: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package snapshot
+
+import (
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Snapshot store", func() {
+	var (
+		sut       *Store
+		sutConfig config.Snapshots
+		tmpDir    *TmpFolder
+		created   time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		sutConfig, err = config.WithDefaults[config.Snapshots]()
+		Expect(err).Should(Succeed())
+
+		tmpDir = NewTmpFolder("snapshots")
+		sutConfig.Directory = tmpDir.JoinPath("snapshots")
+
+		created = time.Date(2024, 5, 1, 10, 20, 30, 0, time.UTC)
+	})
+
+	JustBeforeEach(func() {
+		sut = NewStore(sutConfig)
+	})
+
+	newSnapshot := func(created time.Time, reason string) *Snapshot {
+		return &Snapshot{
+			Created:    created,
+			Reason:     reason,
+			ConfigHash: "abc",
+			Config:     "upstreams: {}",
+			Blocking:   Blocking{DisabledGroups: []string{"ads"}},
+		}
+	}
+
+	Describe("Save", func() {
+		It("should store the snapshot named after its creation time and reason", func() {
+			snapshot := newSnapshot(created, ReasonStart)
+
+			Expect(sut.Save(snapshot)).Should(Succeed())
+			Expect(snapshot.Name).Should(Equal("20240501-102030-start"))
+
+			loaded, err := sut.Load(snapshot.Name)
+			Expect(err).Should(Succeed())
+			Expect(loaded.Created).Should(BeTemporally("==", created))
+			Expect(loaded).Should(Equal(&Snapshot{
+				Name:       "20240501-102030-start",
+				Created:    loaded.Created,
+				Reason:     ReasonStart,
+				ConfigHash: "abc",
+				Config:     "upstreams: {}",
+				Blocking:   Blocking{DisabledGroups: []string{"ads"}},
+			}))
+		})
+
+		It("should not overwrite a snapshot with the same name", func() {
+			first := newSnapshot(created, ReasonRollback)
+			Expect(sut.Save(first)).Should(Succeed())
+
+			second := newSnapshot(created, ReasonRollback)
+			second.ConfigHash = "def"
+			Expect(sut.Save(second)).Should(Succeed())
+
+			Expect(first.Name).Should(Equal("20240501-102030-rollback"))
+			Expect(second.Name).Should(Equal("20240501-102030-rollback-2"))
+
+			loaded, err := sut.Load(first.Name)
+			Expect(err).Should(Succeed())
+			Expect(loaded.ConfigHash).Should(Equal("abc"))
+
+			loaded, err = sut.Load(second.Name)
+			Expect(err).Should(Succeed())
+			Expect(loaded.ConfigHash).Should(Equal("def"))
+		})
+
+		When("there are more snapshots than configured to keep", func() {
+			BeforeEach(func() {
+				sutConfig.Keep = 2
+			})
+
+			It("should remove the oldest", func() {
+				for i := range 3 {
+					Expect(sut.Save(newSnapshot(created.Add(time.Duration(i)*time.Hour), ReasonScheduled))).Should(Succeed())
+				}
+
+				snapshots, err := sut.List()
+				Expect(err).Should(Succeed())
+				Expect(snapshots).Should(HaveLen(2))
+				Expect(snapshots[0].Name).Should(Equal("20240501-122030-scheduled"))
+				Expect(snapshots[1].Name).Should(Equal("20240501-112030-scheduled"))
+			})
+		})
+
+		When("keep is 0", func() {
+			BeforeEach(func() {
+				sutConfig.Keep = 0
+			})
+
+			It("should keep all", func() {
+				for i := range 10 {
+					Expect(sut.Save(newSnapshot(created.Add(time.Duration(i)*time.Hour), ReasonScheduled))).Should(Succeed())
+				}
+
+				Expect(sut.List()).Should(HaveLen(10))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		It("should be empty if the directory does not exist", func() {
+			Expect(sut.List()).Should(BeEmpty())
+		})
+
+		It("should ignore other files", func() {
+			Expect(sut.Save(newSnapshot(created, ReasonStart))).Should(Succeed())
+
+			Expect(os.Mkdir(tmpDir.JoinPath("snapshots/dir.json"), 0o700)).Should(Succeed())
+			Expect(os.WriteFile(tmpDir.JoinPath("snapshots/notes.txt"), []byte("x"), 0o600)).Should(Succeed())
+
+			Expect(sut.List()).Should(HaveLen(1))
+		})
+	})
+
+	Describe("Load", func() {
+		It("should fail for unknown snapshots", func() {
+			_, err := sut.Load("unknown")
+			Expect(err).Should(MatchError(fs.ErrNotExist))
+		})
+
+		It("should not allow paths", func() {
+			_, err := sut.Load("../snapshots/x")
+			Expect(err).Should(MatchError(fs.ErrNotExist))
+			Expect(err.Error()).Should(ContainSubstring("invalid snapshot name"))
+		})
+	})
+
+	Describe("RestoreConfig", func() {
+		It("should write the configuration to the file", func() {
+			file := tmpDir.CreateStringFile("config.yml", "other: config")
+			Expect(os.Chmod(file.Path, 0o600)).Should(Succeed())
+
+			Expect(RestoreConfig(newSnapshot(created, ReasonStart), file.Path)).Should(Succeed())
+
+			Expect(os.ReadFile(file.Path)).Should(BeEquivalentTo("upstreams: {}"))
+
+			info, err := os.Stat(file.Path)
+			Expect(err).Should(Succeed())
+			Expect(info.Mode().Perm()).Should(Equal(fs.FileMode(0o600)))
+		})
+
+		It("should not write into a directory", func() {
+			err := RestoreConfig(newSnapshot(created, ReasonStart), tmpDir.Path)
+			Expect(err).Should(MatchError(ContainSubstring("only a single file is supported")))
+		})
+
+		It("should not write into a file with includes", func() {
+			file := tmpDir.CreateStringFile("config.yml", "include: blocking.yml", "upstreams: {}")
+
+			err := RestoreConfig(newSnapshot(created, ReasonStart), file.Path)
+			Expect(err).Should(MatchError(ContainSubstring("it includes other files")))
+
+			Expect(os.ReadFile(file.Path)).Should(BeEquivalentTo("include: blocking.yml\nupstreams: {}"))
+		})
+
+		It("should fail without configuration file", func() {
+			Expect(RestoreConfig(newSnapshot(created, ReasonStart), "")).ShouldNot(Succeed())
+		})
+	})
+})
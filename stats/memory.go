@@ -0,0 +1,158 @@
+package stats
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+)
+
+// bucket counts the queries of one time bucket
+type bucket struct {
+	Counters
+
+	keys map[Kind]map[string]uint64
+}
+
+func newBucket() *bucket {
+	return &bucket{keys: map[Kind]map[string]uint64{
+		KindDomains:           {},
+		KindBlockedDomains:    {},
+		KindClients:           {},
+		KindBlockedCategories: {},
+	}}
+}
+
+// add counts the query, new keys are ignored if a kind already has maxKeys keys
+func (b *bucket) add(q *Query, maxKeys int) {
+	b.Total++
+
+	if q.Blocked {
+		b.Blocked++
+	}
+
+	if q.Cached {
+		b.Cached++
+	}
+
+	b.inc(KindDomains, q.Domain, maxKeys)
+	b.inc(KindClients, q.Client, maxKeys)
+
+	if q.Blocked {
+		b.inc(KindBlockedDomains, q.Domain, maxKeys)
+
+		for _, category := range q.Categories {
+			b.inc(KindBlockedCategories, category, maxKeys)
+		}
+	}
+}
+
+func (b *bucket) inc(kind Kind, key string, maxKeys int) {
+	if key == "" {
+		return
+	}
+
+	counts := b.keys[kind]
+
+	if _, ok := counts[key]; !ok && len(counts) >= maxKeys {
+		return
+	}
+
+	counts[key]++
+}
+
+// memoryStore keeps the time buckets in memory, they are lost on restart
+type memoryStore struct {
+	lock      sync.RWMutex
+	buckets   map[time.Time]*bucket
+	maxKeys   int
+	retention time.Duration
+}
+
+func newMemoryStore(cfg config.Stats) *memoryStore {
+	return &memoryStore{
+		buckets:   make(map[time.Time]*bucket),
+		maxKeys:   int(cfg.MaxKeys),
+		retention: cfg.Retention.ToDuration(),
+	}
+}
+
+func (s *memoryStore) add(t time.Time, q *Query) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	b, ok := s.buckets[t]
+	if !ok {
+		b = newBucket()
+		s.buckets[t] = b
+
+		s.prune(t)
+	}
+
+	b.add(q, s.maxKeys)
+}
+
+// prune removes the buckets older than the retention, relative to the newest bucket
+func (s *memoryStore) prune(newest time.Time) {
+	for t := range s.buckets {
+		if newest.Sub(t) > s.retention {
+			delete(s.buckets, t)
+		}
+	}
+}
+
+func (s *memoryStore) overview(_ context.Context, from time.Time) (Overview, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	res := Overview{From: from, Timeline: []TimeCounters{}}
+	clients := make(map[string]struct{})
+	domains := make(map[string]struct{})
+
+	for t, b := range s.buckets {
+		if t.Before(from) {
+			continue
+		}
+
+		res.Total += b.Total
+		res.Blocked += b.Blocked
+		res.Cached += b.Cached
+
+		res.Timeline = append(res.Timeline, TimeCounters{Counters: b.Counters, Time: t})
+
+		for client := range b.keys[KindClients] {
+			clients[client] = struct{}{}
+		}
+
+		for domain := range b.keys[KindDomains] {
+			domains[domain] = struct{}{}
+		}
+	}
+
+	res.Clients = len(clients)
+	res.Domains = len(domains)
+
+	sortTimeline(res.Timeline)
+
+	return res, nil
+}
+
+func (s *memoryStore) top(_ context.Context, from time.Time, kind Kind, limit int) ([]Count, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	counts := make(map[string]uint64)
+
+	for t, b := range s.buckets {
+		if t.Before(from) {
+			continue
+		}
+
+		for key, count := range b.keys[kind] {
+			counts[key] += count
+		}
+	}
+
+	return topCounts(counts, limit), nil
+}
@@ -0,0 +1,258 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	redisKeyPrefix     = "blocky:stats:"
+	redisFlushInterval = 5 * time.Second
+
+	fieldTotal   = "total"
+	fieldBlocked = "blocked"
+	fieldCached  = "cached"
+)
+
+// redisStore keeps the time buckets in redis, so they are shared between instances and survive restarts.
+// The queries are counted in memory and added to redis periodically.
+type redisStore struct {
+	client     *redis.Client
+	l          *logrus.Entry
+	maxKeys    int
+	resolution time.Duration
+	expiration time.Duration
+
+	lock    sync.Mutex
+	pending map[time.Time]*bucket
+}
+
+func newRedisStore(ctx context.Context, cfg config.Stats, client *redis.Client) *redisStore {
+	s := &redisStore{
+		client:     client,
+		l:          log.PrefixedLog("stats"),
+		maxKeys:    int(cfg.MaxKeys),
+		resolution: cfg.Resolution.ToDuration(),
+		expiration: cfg.Retention.ToDuration() + cfg.Resolution.ToDuration(),
+		pending:    make(map[time.Time]*bucket),
+	}
+
+	go s.flushPeriodically(ctx)
+
+	return s
+}
+
+func (s *redisStore) flushPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(redisFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.flush(ctx); err != nil {
+				s.l.Warn("can't write statistics to redis: ", err)
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *redisStore) add(t time.Time, q *Query) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	b, ok := s.pending[t]
+	if !ok {
+		b = newBucket()
+		s.pending[t] = b
+	}
+
+	b.add(q, s.maxKeys)
+}
+
+// flush adds the pending counters to redis
+func (s *redisStore) flush(ctx context.Context) error {
+	s.lock.Lock()
+	pending := s.pending
+	s.pending = make(map[time.Time]*bucket)
+	s.lock.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for t, b := range pending {
+			key := counterKey(t)
+
+			pipe.HIncrBy(ctx, key, fieldTotal, int64(b.Total))
+			pipe.HIncrBy(ctx, key, fieldBlocked, int64(b.Blocked))
+			pipe.HIncrBy(ctx, key, fieldCached, int64(b.Cached))
+			pipe.Expire(ctx, key, s.expiration)
+
+			for kind, counts := range b.keys {
+				if len(counts) == 0 {
+					continue
+				}
+
+				key := keysKey(t, kind)
+
+				for k, count := range counts {
+					pipe.ZIncrBy(ctx, key, float64(count), k)
+				}
+
+				pipe.Expire(ctx, key, s.expiration)
+			}
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (s *redisStore) overview(ctx context.Context, from time.Time) (Overview, error) {
+	if err := s.flush(ctx); err != nil {
+		return Overview{}, err
+	}
+
+	res := Overview{From: from, Timeline: []TimeCounters{}}
+	buckets := s.buckets(from)
+
+	cmds := make([]*redis.StringStringMapCmd, len(buckets))
+
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, t := range buckets {
+			cmds[i] = pipe.HGetAll(ctx, counterKey(t))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Overview{}, err
+	}
+
+	for i, cmd := range cmds {
+		values := cmd.Val()
+		if len(values) == 0 {
+			continue
+		}
+
+		c := Counters{
+			Total:   parseCount(values[fieldTotal]),
+			Blocked: parseCount(values[fieldBlocked]),
+			Cached:  parseCount(values[fieldCached]),
+		}
+
+		res.Total += c.Total
+		res.Blocked += c.Blocked
+		res.Cached += c.Cached
+
+		res.Timeline = append(res.Timeline, TimeCounters{Counters: c, Time: buckets[i]})
+	}
+
+	if res.Clients, err = s.distinct(ctx, buckets, KindClients); err != nil {
+		return Overview{}, err
+	}
+
+	if res.Domains, err = s.distinct(ctx, buckets, KindDomains); err != nil {
+		return Overview{}, err
+	}
+
+	return res, nil
+}
+
+func (s *redisStore) top(ctx context.Context, from time.Time, kind Kind, limit int) ([]Count, error) {
+	if err := s.flush(ctx); err != nil {
+		return nil, err
+	}
+
+	var cmd *redis.ZSliceCmd
+
+	err := s.union(ctx, s.buckets(from), kind, func(pipe redis.Pipeliner, key string) {
+		cmd = pipe.ZRevRangeWithScores(ctx, key, 0, int64(limit-1))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Count, 0, len(cmd.Val()))
+
+	for _, z := range cmd.Val() {
+		res = append(res, Count{Key: fmt.Sprint(z.Member), Count: uint64(z.Score)})
+	}
+
+	return res, nil
+}
+
+// distinct returns the number of distinct keys of the kind in the buckets
+func (s *redisStore) distinct(ctx context.Context, buckets []time.Time, kind Kind) (int, error) {
+	var cmd *redis.IntCmd
+
+	err := s.union(ctx, buckets, kind, func(pipe redis.Pipeliner, key string) {
+		cmd = pipe.ZCard(ctx, key)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(cmd.Val()), nil
+}
+
+// union adds the counts of the kind in the buckets to a temporary key and queries it with the read function
+func (s *redisStore) union(ctx context.Context, buckets []time.Time, kind Kind,
+	read func(pipe redis.Pipeliner, key string),
+) error {
+	keys := make([]string, len(buckets))
+	for i, t := range buckets {
+		keys[i] = keysKey(t, kind)
+	}
+
+	tmpKey := redisKeyPrefix + "tmp:" + uuid.NewString()
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, tmpKey, &redis.ZStore{Keys: keys})
+		read(pipe, tmpKey)
+		pipe.Del(ctx, tmpKey)
+
+		return nil
+	})
+
+	return err
+}
+
+// buckets returns the start times of all buckets from the given time until now
+func (s *redisStore) buckets(from time.Time) []time.Time {
+	var res []time.Time
+
+	for t := from; !t.After(time.Now()); t = t.Add(s.resolution) {
+		res = append(res, t)
+	}
+
+	return res
+}
+
+func counterKey(t time.Time) string {
+	return fmt.Sprintf("%s%d:counters", redisKeyPrefix, t.Unix())
+}
+
+func keysKey(t time.Time, kind Kind) string {
+	return fmt.Sprintf("%s%d:%s", redisKeyPrefix, t.Unix(), kind)
+}
+
+func parseCount(value string) uint64 {
+	res, _ := strconv.ParseUint(value, 10, 64)
+
+	return res
+}
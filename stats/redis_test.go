@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/alicebob/miniredis/v2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redis store", func() {
+	var (
+		redisServer *miniredis.Miniredis
+		redisCfg    *config.Redis
+		sut         *Collector
+	)
+
+	BeforeEach(func(ctx context.Context) {
+		var err error
+
+		redisServer, err = miniredis.Run()
+		Expect(err).Should(Succeed())
+		DeferCleanup(redisServer.Close)
+
+		redisCfg = &config.Redis{Address: redisServer.Addr()}
+
+		cfg := defaultConfig()
+		cfg.Redis = true
+
+		sut, err = New(ctx, cfg, redisCfg)
+		Expect(err).Should(Succeed())
+		Expect(sut.store).Should(BeAssignableToTypeOf(&redisStore{}))
+	})
+
+	itCountsQueries(func() *Collector { return sut })
+
+	It("should write the counters with expiration and remove temporary keys", func(ctx context.Context) {
+		now := time.Now()
+
+		sut.Add(&Query{Time: now, Client: "laptop", Domain: "ads.com", Blocked: true})
+
+		Expect(sut.store.(*redisStore).flush(ctx)).Should(Succeed())
+
+		bucket := now.Truncate(10 * time.Minute)
+
+		Expect(redisServer.HGet(counterKey(bucket), fieldBlocked)).Should(Equal("1"))
+		Expect(redisServer.TTL(counterKey(bucket))).Should(Equal(24*time.Hour + 10*time.Minute))
+		Expect(redisServer.ZScore(keysKey(bucket, KindBlockedDomains), "ads.com")).Should(Equal(1.0))
+		Expect(redisServer.TTL(keysKey(bucket, KindClients))).Should(BeNumerically(">", 0))
+
+		_, err := sut.Top(ctx, time.Hour, KindDomains, 10)
+		Expect(err).Should(Succeed())
+		Expect(redisServer.Keys()).ShouldNot(ContainElement(HavePrefix(redisKeyPrefix + "tmp:")))
+	})
+
+	It("should add the counters of all instances", func(ctx context.Context) {
+		cfg := defaultConfig()
+		cfg.Redis = true
+
+		other, err := New(ctx, cfg, redisCfg)
+		Expect(err).Should(Succeed())
+
+		now := time.Now()
+
+		sut.Add(&Query{Time: now, Client: "laptop", Domain: "example.com"})
+		other.Add(&Query{Time: now, Client: "phone", Domain: "example.com"})
+
+		Expect(other.store.(*redisStore).flush(ctx)).Should(Succeed())
+
+		Expect(sut.Top(ctx, time.Hour, KindDomains, 10)).Should(Equal([]Count{
+			{Key: "example.com", Count: 2},
+		}))
+	})
+
+	It("should fail if redis is not reachable", func(ctx context.Context) {
+		cfg := defaultConfig()
+		cfg.Redis = true
+
+		_, err := New(ctx, cfg, &config.Redis{Address: "127.0.0.1:0"})
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,185 @@
+// Package stats aggregates the queries to rolling statistics, e.g. for dashboards
+package stats
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/redis"
+)
+
+// ErrDisabled is returned by a nil collector
+var ErrDisabled = errors.New("statistics are disabled, configure stats.enable")
+
+// Kind of the counted keys
+type Kind string
+
+const (
+	KindDomains           Kind = "domains"
+	KindBlockedDomains    Kind = "blockedDomains"
+	KindClients           Kind = "clients"
+	KindBlockedCategories Kind = "blockedCategories"
+)
+
+// Query is a resolved query to count
+type Query struct {
+	Time       time.Time
+	Client     string
+	Domain     string
+	Blocked    bool
+	Cached     bool
+	Categories []string
+}
+
+// Counters are the number of queries of a period
+type Counters struct {
+	Total   uint64
+	Blocked uint64
+	Cached  uint64
+}
+
+// BlockedRatio is the share of blocked queries (0 - 1)
+func (c Counters) BlockedRatio() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+
+	return float64(c.Blocked) / float64(c.Total)
+}
+
+// TimeCounters are the counters of a time bucket
+type TimeCounters struct {
+	Counters
+
+	// Start of the time bucket
+	Time time.Time
+}
+
+// Overview summarizes the queries of a period
+type Overview struct {
+	Counters
+
+	// Start of the period, the start of the oldest time bucket
+	From time.Time
+	// Number of distinct clients and domains, up to the limit of keys per time bucket
+	Clients int
+	Domains int
+	// Counters per time bucket, oldest first
+	Timeline []TimeCounters
+}
+
+// Count is the number of queries of a domain or client
+type Count struct {
+	Key   string
+	Count uint64
+}
+
+// store keeps the counters per time bucket
+type store interface {
+	add(bucket time.Time, q *Query)
+	overview(ctx context.Context, from time.Time) (Overview, error)
+	top(ctx context.Context, from time.Time, kind Kind, limit int) ([]Count, error)
+}
+
+// Collector counts the queries in time buckets of `stats.resolution`
+type Collector struct {
+	cfg   config.Stats
+	store store
+}
+
+// New creates a collector, which is nil if the statistics are disabled
+func New(ctx context.Context, cfg config.Stats, redisCfg *config.Redis) (*Collector, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil //nolint:nilnil
+	}
+
+	var s store = newMemoryStore(cfg)
+
+	if cfg.Redis {
+		client, err := redis.Connect(ctx, redisCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		s = newRedisStore(ctx, cfg, client)
+	}
+
+	return &Collector{cfg: cfg, store: s}, nil
+}
+
+// Add counts the query
+func (c *Collector) Add(q *Query) {
+	if c == nil {
+		return
+	}
+
+	q.Domain = strings.TrimSuffix(strings.ToLower(q.Domain), ".")
+
+	c.store.add(c.bucket(q.Time), q)
+}
+
+// Overview returns the counters of the queries of the latest period
+func (c *Collector) Overview(ctx context.Context, period time.Duration) (Overview, error) {
+	if c == nil {
+		return Overview{}, ErrDisabled
+	}
+
+	return c.store.overview(ctx, c.from(period))
+}
+
+// Top returns the keys of the kind with the most queries of the latest period, most queries first
+func (c *Collector) Top(ctx context.Context, period time.Duration, kind Kind, limit int) ([]Count, error) {
+	if c == nil {
+		return nil, ErrDisabled
+	}
+
+	return c.store.top(ctx, c.from(period), kind, limit)
+}
+
+func (c *Collector) bucket(t time.Time) time.Time {
+	return t.Truncate(c.cfg.Resolution.ToDuration())
+}
+
+// from returns the start of the oldest time bucket of the period, which is at most the retention
+func (c *Collector) from(period time.Duration) time.Time {
+	period = min(period, c.cfg.Retention.ToDuration())
+
+	return c.bucket(time.Now().Add(-period))
+}
+
+func sortTimeline(timeline []TimeCounters) {
+	slices.SortFunc(timeline, func(a, b TimeCounters) int {
+		return a.Time.Compare(b.Time)
+	})
+}
+
+// topCounts returns the limit keys with the highest counts, highest first
+func topCounts(counts map[string]uint64, limit int) []Count {
+	res := make([]Count, 0, len(counts))
+
+	for key, count := range counts {
+		res = append(res, Count{Key: key, Count: count})
+	}
+
+	slices.SortFunc(res, func(a, b Count) int {
+		if a.Count != b.Count {
+			if a.Count > b.Count {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res
+}
@@ -0,0 +1,180 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/creasty/defaults"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func defaultConfig() config.Stats {
+	var cfg config.Stats
+	Expect(defaults.Set(&cfg)).Should(Succeed())
+
+	cfg.Enable = true
+
+	return cfg
+}
+
+// itCountsQueries describes the behavior of the collector, which is the same for all stores
+func itCountsQueries(sut func() *Collector) {
+	It("should summarize the queries", func(ctx context.Context) {
+		now := time.Now()
+
+		sut().Add(&Query{Time: now, Client: "laptop", Domain: "Example.com.", Cached: true})
+		sut().Add(&Query{Time: now, Client: "laptop", Domain: "ads.com", Blocked: true})
+		sut().Add(&Query{Time: now, Client: "phone", Domain: "example.com"})
+		sut().Add(&Query{Time: now, Client: "phone", Domain: "ads.com", Blocked: true})
+
+		overview, err := sut().Overview(ctx, time.Hour)
+		Expect(err).Should(Succeed())
+		Expect(overview.Counters).Should(Equal(Counters{Total: 4, Blocked: 2, Cached: 1}))
+		Expect(overview.BlockedRatio()).Should(Equal(0.5))
+		Expect(overview.Clients).Should(Equal(2))
+		Expect(overview.Domains).Should(Equal(2))
+		Expect(overview.Timeline).Should(HaveLen(1))
+		Expect(overview.Timeline[0].Time).Should(Equal(now.Truncate(10 * time.Minute)))
+		Expect(overview.Timeline[0].Total).Should(BeEquivalentTo(4))
+	})
+
+	It("should return the top keys", func(ctx context.Context) {
+		now := time.Now()
+
+		sut().Add(&Query{Time: now, Client: "laptop", Domain: "example.com"})
+		sut().Add(&Query{Time: now, Client: "laptop", Domain: "example.com"})
+		sut().Add(&Query{Time: now, Client: "laptop", Domain: "example.com"})
+		sut().Add(&Query{Time: now, Client: "laptop", Domain: "ads.com", Blocked: true})
+		sut().Add(&Query{Time: now, Client: "phone", Domain: "ads.com", Blocked: true})
+		sut().Add(&Query{Time: now, Client: "phone", Domain: "blocky.com"})
+
+		Expect(sut().Top(ctx, time.Hour, KindDomains, 2)).Should(Equal([]Count{
+			{Key: "example.com", Count: 3},
+			{Key: "ads.com", Count: 2},
+		}))
+		Expect(sut().Top(ctx, time.Hour, KindBlockedDomains, 10)).Should(Equal([]Count{
+			{Key: "ads.com", Count: 2},
+		}))
+		Expect(sut().Top(ctx, time.Hour, KindClients, 10)).Should(Equal([]Count{
+			{Key: "laptop", Count: 4},
+			{Key: "phone", Count: 2},
+		}))
+	})
+
+	It("should count the categories of the blocked queries", func(ctx context.Context) {
+		now := time.Now()
+
+		sut().Add(&Query{Time: now, Client: "laptop", Domain: "ads.com", Blocked: true, Categories: []string{"ads"}})
+		sut().Add(&Query{
+			Time: now, Client: "laptop", Domain: "tracker.com", Blocked: true, Categories: []string{"ads", "tracking"},
+		})
+		sut().Add(&Query{Time: now, Client: "laptop", Domain: "example.com", Categories: []string{"adult"}})
+
+		Expect(sut().Top(ctx, time.Hour, KindBlockedCategories, 10)).Should(Equal([]Count{
+			{Key: "ads", Count: 2},
+			{Key: "tracking", Count: 1},
+		}))
+	})
+
+	It("should only count the queries of the period", func(ctx context.Context) {
+		now := time.Now()
+
+		sut().Add(&Query{Time: now.Add(-3 * time.Hour), Client: "laptop", Domain: "old.com"})
+		sut().Add(&Query{Time: now, Client: "phone", Domain: "new.com"})
+
+		overview, err := sut().Overview(ctx, time.Hour)
+		Expect(err).Should(Succeed())
+		Expect(overview.Total).Should(BeEquivalentTo(1))
+		Expect(overview.Clients).Should(Equal(1))
+
+		Expect(sut().Top(ctx, time.Hour, KindDomains, 10)).Should(Equal([]Count{
+			{Key: "new.com", Count: 1},
+		}))
+
+		overview, err = sut().Overview(ctx, 24*time.Hour)
+		Expect(err).Should(Succeed())
+		Expect(overview.Total).Should(BeEquivalentTo(2))
+		Expect(overview.Timeline).Should(HaveLen(2))
+		Expect(overview.Timeline[0].Time).Should(BeTemporally("<", overview.Timeline[1].Time))
+	})
+}
+
+var _ = Describe("Collector", func() {
+	var (
+		cfg config.Stats
+		sut *Collector
+	)
+
+	BeforeEach(func() {
+		cfg = defaultConfig()
+	})
+
+	JustBeforeEach(func(ctx context.Context) {
+		var err error
+
+		sut, err = New(ctx, cfg, &config.Redis{})
+		Expect(err).Should(Succeed())
+	})
+
+	When("statistics are disabled", func() {
+		BeforeEach(func() {
+			cfg.Enable = false
+		})
+
+		It("should ignore queries and return an error", func(ctx context.Context) {
+			Expect(sut).Should(BeNil())
+
+			sut.Add(&Query{Time: time.Now(), Domain: "example.com"})
+
+			_, err := sut.Overview(ctx, time.Hour)
+			Expect(err).Should(MatchError(ErrDisabled))
+
+			_, err = sut.Top(ctx, time.Hour, KindDomains, 10)
+			Expect(err).Should(MatchError(ErrDisabled))
+		})
+	})
+
+	Describe("in memory", func() {
+		itCountsQueries(func() *Collector { return sut })
+
+		It("should limit the period to the retention", func(ctx context.Context) {
+			overview, err := sut.Overview(ctx, 7*24*time.Hour)
+			Expect(err).Should(Succeed())
+			Expect(overview.From).Should(BeTemporally("~", time.Now().Add(-24*time.Hour), 10*time.Minute))
+		})
+
+		It("should remove the buckets older than the retention", func() {
+			now := time.Now()
+
+			sut.Add(&Query{Time: now.Add(-48 * time.Hour), Domain: "old.com"})
+			sut.Add(&Query{Time: now, Domain: "new.com"})
+
+			Expect(sut.store.(*memoryStore).buckets).Should(HaveLen(1))
+		})
+
+		When("the number of keys is limited", func() {
+			BeforeEach(func() {
+				cfg.MaxKeys = 1
+			})
+
+			It("should count queries of new keys only in the counters", func(ctx context.Context) {
+				now := time.Now()
+
+				sut.Add(&Query{Time: now, Domain: "first.com"})
+				sut.Add(&Query{Time: now, Domain: "second.com"})
+				sut.Add(&Query{Time: now, Domain: "first.com"})
+
+				Expect(sut.Top(ctx, time.Hour, KindDomains, 10)).Should(Equal([]Count{
+					{Key: "first.com", Count: 2},
+				}))
+
+				overview, err := sut.Overview(ctx, time.Hour)
+				Expect(err).Should(Succeed())
+				Expect(overview.Total).Should(BeEquivalentTo(3))
+			})
+		})
+	})
+})
@@ -1,5 +1,11 @@
 package util
 
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
 //nolint:gochecknoglobals
 var (
 	// Version current version number
@@ -9,3 +15,47 @@ var (
 	// Architecture current CPU architecture
 	Architecture = "undefined"
 )
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Version      string   `json:"version"`
+	BuildTime    string   `json:"buildTime"`
+	Architecture string   `json:"architecture"`
+	GoVersion    string   `json:"goVersion"`
+	BuildTags    []string `json:"buildTags"`
+}
+
+// GetBuildInfo returns the build information of the running binary
+func GetBuildInfo() BuildInfo {
+	return BuildInfo{
+		Version:      Version,
+		BuildTime:    BuildTime,
+		Architecture: Architecture,
+		GoVersion:    runtime.Version(),
+		BuildTags:    buildTags(),
+	}
+}
+
+// buildTags returns the tags the binary was built with (`go build -tags`)
+func buildTags() []string {
+	tags := []string{}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return tags
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key != "-tags" {
+			continue
+		}
+
+		for _, tag := range strings.Split(setting.Value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync/atomic"
 
@@ -61,6 +62,18 @@ func AnswerToString(answer []dns.RR) string {
 	return Obfuscate(strings.Join(answers, ", "))
 }
 
+// AnswerIP returns the address of an A or AAAA record, nil for other records
+func AnswerIP(rr dns.RR) net.IP {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A
+	case *dns.AAAA:
+		return v.AAAA
+	}
+
+	return nil
+}
+
 // QuestionToString creates a user-friendly representation of a question
 func QuestionToString(questions []dns.Question) string {
 	result := make([]string, len(questions))
@@ -219,3 +232,30 @@ func ClientNameMatchesGroupName(group, clientName string) bool {
 
 	return match
 }
+
+// ClientMACMatchesGroupName checks if a group is the hardware address of a client or its OUI (vendor prefix),
+// for example "aa:bb:cc:dd:ee:ff" or "aa:bb:cc". Bytes can be separated by colons or hyphens.
+func ClientMACMatchesGroupName(group string, mac net.HardwareAddr) bool {
+	const (
+		ouiLen = 3
+		macLen = 6
+	)
+
+	parts := strings.Split(strings.ReplaceAll(group, "-", ":"), ":")
+	if (len(parts) != ouiLen && len(parts) != macLen) || len(mac) != macLen {
+		return false
+	}
+
+	for i, part := range parts {
+		if len(part) != 2 { //nolint:mnd
+			return false
+		}
+
+		b, err := strconv.ParseUint(part, 16, 8)
+		if err != nil || byte(b) != mac[i] {
+			return false
+		}
+	}
+
+	return true
+}
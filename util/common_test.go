@@ -33,6 +33,17 @@ var _ = Describe("Common function tests", func() {
 		})
 	})
 
+	Describe("Answer IP", func() {
+		It("should return the address of A and AAAA records", func() {
+			Expect(AnswerIP(&dns.A{A: net.ParseIP("127.0.0.1")})).Should(Equal(net.ParseIP("127.0.0.1")))
+			Expect(AnswerIP(&dns.AAAA{AAAA: net.IPv6loopback})).Should(Equal(net.IPv6loopback))
+		})
+
+		It("should return nil for other records", func() {
+			Expect(AnswerIP(&dns.CNAME{Target: "cname"})).Should(BeNil())
+		})
+	})
+
 	Describe("print question", func() {
 		When("question is provided", func() {
 			question := dns.Question{
@@ -235,4 +246,31 @@ var _ = Describe("Common function tests", func() {
 			Expect(c).Should(BeFalse())
 		})
 	})
+
+	Describe("Client MAC matches group name", func() {
+		mac := net.HardwareAddr{0xaa, 0xbb, 0xcc, 0x01, 0x02, 0x03}
+
+		It("should return true if the group is the MAC address", func() {
+			Expect(ClientMACMatchesGroupName("aa:bb:cc:01:02:03", mac)).Should(BeTrue())
+			Expect(ClientMACMatchesGroupName("AA-BB-CC-01-02-03", mac)).Should(BeTrue())
+		})
+		It("should return true if the group is the OUI of the MAC address", func() {
+			Expect(ClientMACMatchesGroupName("aa:bb:cc", mac)).Should(BeTrue())
+		})
+		It("should return false if the group is another MAC address or OUI", func() {
+			Expect(ClientMACMatchesGroupName("aa:bb:cc:01:02:04", mac)).Should(BeFalse())
+			Expect(ClientMACMatchesGroupName("aa:bb:cd", mac)).Should(BeFalse())
+		})
+		It("should return false if the group is no MAC address", func() {
+			Expect(ClientMACMatchesGroupName("aa:bb", mac)).Should(BeFalse())
+			Expect(ClientMACMatchesGroupName("aabb.cc01.0203", mac)).Should(BeFalse())
+			Expect(ClientMACMatchesGroupName("aa:bb:cc:1:2:3", mac)).Should(BeFalse())
+			Expect(ClientMACMatchesGroupName("xx:bb:cc", mac)).Should(BeFalse())
+			Expect(ClientMACMatchesGroupName("aa::bb:cc", mac)).Should(BeFalse())
+			Expect(ClientMACMatchesGroupName("default", mac)).Should(BeFalse())
+		})
+		It("should return false if the client MAC address is unknown", func() {
+			Expect(ClientMACMatchesGroupName("aa:bb:cc", nil)).Should(BeFalse())
+		})
+	})
 })
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File Util", func() {
+	Describe("WriteFileAtomic", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+		})
+
+		It("should replace the file without leaving temporary files", func() {
+			path := filepath.Join(dir, "key.pem")
+
+			Expect(os.WriteFile(path, []byte("old"), 0o644)).Should(Succeed())
+			Expect(WriteFileAtomic(path, []byte("new"), 0o600)).Should(Succeed())
+
+			Expect(os.ReadFile(path)).Should(BeEquivalentTo("new"))
+
+			info, err := os.Stat(path)
+			Expect(err).Should(Succeed())
+			Expect(info.Mode().Perm()).Should(Equal(os.FileMode(0o600)))
+
+			Expect(os.ReadDir(dir)).Should(HaveLen(1))
+		})
+
+		It("should fail if the directory doesn't exist", func() {
+			Expect(WriteFileAtomic(filepath.Join(dir, "missing", "key.pem"), nil, 0o600)).ShouldNot(Succeed())
+		})
+	})
+})
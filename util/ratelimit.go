@@ -0,0 +1,161 @@
+package util
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+// TokenBucket is a token bucket rate limiter which is safe for concurrent use.
+//
+// The bucket holds up to `burst` tokens and is refilled with `rate` tokens per second.
+type TokenBucket struct {
+	lock sync.Mutex
+
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a full bucket refilled with rate tokens per second.
+//
+// A burst lower than 1 is raised to 1, so at least a single request is always allowed.
+func NewTokenBucket(rate float64, burst uint) *TokenBucket {
+	b := math.Max(float64(burst), 1)
+
+	return &TokenBucket{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+	}
+}
+
+// Allow takes a token from the bucket and returns true if one was available.
+func (b *TokenBucket) Allow() bool {
+	return b.AllowAt(time.Now())
+}
+
+// AllowAt is like Allow but uses the given time instead of the current one.
+func (b *TokenBucket) AllowAt(now time.Time) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// RetryAfter returns the duration until the next token is available.
+func (b *TokenBucket) RetryAfter() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill(time.Now())
+
+	if b.tokens >= 1 || b.rate <= 0 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// isFull returns true if the bucket is completely refilled and thus equivalent to a new one.
+func (b *TokenBucket) isFull(now time.Time) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill(now)
+
+	return b.tokens >= b.burst
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+
+	b.last = now
+}
+
+// KeyedRateLimiter manages one TokenBucket per key (e.g. client IP).
+//
+// Buckets that are full again are dropped periodically to bound memory usage. If the limiter holds
+// the maximum number of buckets, the least recently used one is dropped for a new key.
+type KeyedRateLimiter struct {
+	lock sync.Mutex
+
+	rate          float64
+	burst         uint
+	buckets       *simplelru.LRU
+	lastCleanup   time.Time
+	cleanupPeriod time.Duration
+}
+
+// NewKeyedRateLimiter creates a limiter allowing rate requests per second and key with the given burst.
+func NewKeyedRateLimiter(rate float64, burst uint) *KeyedRateLimiter {
+	const defaultMaxKeys = 100_000
+
+	return newKeyedRateLimiter(rate, burst, defaultMaxKeys)
+}
+
+func newKeyedRateLimiter(rate float64, burst uint, maxKeys int) *KeyedRateLimiter {
+	const defaultCleanupPeriod = time.Minute
+
+	// can't fail as the size is positive
+	buckets, _ := simplelru.NewLRU(maxKeys, nil)
+
+	return &KeyedRateLimiter{
+		rate:          rate,
+		burst:         burst,
+		buckets:       buckets,
+		lastCleanup:   time.Now(),
+		cleanupPeriod: defaultCleanupPeriod,
+	}
+}
+
+// Allow returns true if a request for the given key is allowed.
+// If not, the second return value indicates when the key can retry.
+func (l *KeyedRateLimiter) Allow(key string) (bool, time.Duration) {
+	bucket := l.bucket(key)
+
+	if bucket.Allow() {
+		return true, 0
+	}
+
+	return false, bucket.RetryAfter()
+}
+
+func (l *KeyedRateLimiter) bucket(key string) *TokenBucket {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(l.lastCleanup) > l.cleanupPeriod {
+		for _, k := range l.buckets.Keys() {
+			if b, ok := l.buckets.Peek(k); ok && b.(*TokenBucket).isFull(now) {
+				l.buckets.Remove(k)
+			}
+		}
+
+		l.lastCleanup = now
+	}
+
+	if b, ok := l.buckets.Get(key); ok {
+		return b.(*TokenBucket)
+	}
+
+	bucket := NewTokenBucket(l.rate, l.burst)
+	l.buckets.Add(key, bucket)
+
+	return bucket
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rate limiting", func() {
+	Describe("TokenBucket", func() {
+		It("should allow requests up to the burst size", func() {
+			now := time.Now()
+			sut := NewTokenBucket(1, 3)
+
+			Expect(sut.AllowAt(now)).Should(BeTrue())
+			Expect(sut.AllowAt(now)).Should(BeTrue())
+			Expect(sut.AllowAt(now)).Should(BeTrue())
+			Expect(sut.AllowAt(now)).Should(BeFalse())
+		})
+
+		It("should refill tokens over time", func() {
+			now := time.Now()
+			sut := NewTokenBucket(2, 1)
+
+			Expect(sut.AllowAt(now)).Should(BeTrue())
+			Expect(sut.AllowAt(now)).Should(BeFalse())
+			Expect(sut.AllowAt(now.Add(200 * time.Millisecond))).Should(BeFalse())
+			Expect(sut.AllowAt(now.Add(500 * time.Millisecond))).Should(BeTrue())
+		})
+
+		It("should not exceed the burst size when refilling", func() {
+			now := time.Now()
+			sut := NewTokenBucket(10, 2)
+
+			Expect(sut.AllowAt(now)).Should(BeTrue())
+			Expect(sut.AllowAt(now.Add(time.Hour))).Should(BeTrue())
+			Expect(sut.AllowAt(now.Add(time.Hour))).Should(BeTrue())
+			Expect(sut.AllowAt(now.Add(time.Hour))).Should(BeFalse())
+		})
+
+		It("should allow at least one request if burst is 0", func() {
+			sut := NewTokenBucket(1, 0)
+
+			Expect(sut.Allow()).Should(BeTrue())
+			Expect(sut.Allow()).Should(BeFalse())
+			Expect(sut.RetryAfter()).Should(BeNumerically(">", 0))
+		})
+	})
+
+	Describe("KeyedRateLimiter", func() {
+		It("should limit each key separately", func() {
+			sut := NewKeyedRateLimiter(1, 1)
+
+			ok, _ := sut.Allow("a")
+			Expect(ok).Should(BeTrue())
+
+			ok, retryAfter := sut.Allow("a")
+			Expect(ok).Should(BeFalse())
+			Expect(retryAfter).Should(BeNumerically("~", time.Second, 100*time.Millisecond))
+
+			ok, _ = sut.Allow("b")
+			Expect(ok).Should(BeTrue())
+		})
+
+		It("should drop full buckets on cleanup", func() {
+			sut := NewKeyedRateLimiter(1000, 1)
+			sut.cleanupPeriod = 0
+
+			sut.Allow("a")
+			time.Sleep(5 * time.Millisecond)
+			sut.Allow("b")
+
+			Expect(sut.buckets.Keys()).Should(Equal([]any{"b"}))
+		})
+
+		It("should drop the least recently used bucket if the maximum number of keys is reached", func() {
+			sut := newKeyedRateLimiter(1, 1, 2)
+
+			sut.Allow("a")
+			sut.Allow("b")
+
+			ok, _ := sut.Allow("a")
+			Expect(ok).Should(BeFalse())
+
+			sut.Allow("c")
+
+			Expect(sut.buckets.Len()).Should(Equal(2))
+			Expect(sut.buckets.Keys()).Should(Equal([]any{"a", "c"}))
+
+			ok, _ = sut.Allow("a")
+			Expect(ok).Should(BeFalse())
+		})
+	})
+})
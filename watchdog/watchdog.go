@@ -0,0 +1,162 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/log"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ResourceGoroutines is the resource name of the goroutine count
+	ResourceGoroutines = "goroutines"
+	// ResourceOpenFDs is the resource name of the open file descriptor count
+	ResourceOpenFDs = "open_fds"
+	// ResourceWorkersPrefix prefixes the subsystem in the resource name of worker counts
+	ResourceWorkersPrefix = "workers:"
+)
+
+func logger() *logrus.Entry {
+	return log.PrefixedLog("watchdog")
+}
+
+// Watchdog samples the goroutine, open file descriptor and worker counts and raises an alarm
+// if one of them keeps growing
+type Watchdog struct {
+	cfg config.Watchdog
+
+	trends map[string]*trend
+
+	// sample returns the current count per resource
+	sample func() map[string]int64
+
+	// stopped is closed when the sampling of Start stopped
+	stopped chan struct{}
+}
+
+// trend of a resource since the last alarm
+type trend struct {
+	first, last int64
+	growing     uint // number of consecutive samples with a higher count
+}
+
+// New creates a new watchdog
+func New(cfg config.Watchdog) *Watchdog {
+	return &Watchdog{
+		cfg:     cfg,
+		trends:  make(map[string]*trend),
+		sample:  sample,
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start samples the counts every `watchdog.interval` until the context is done
+func (w *Watchdog) Start(ctx context.Context) {
+	go func() {
+		defer close(w.stopped)
+
+		ticker := time.NewTicker(w.cfg.Interval.ToDuration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.check()
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// check takes a sample and raises an alarm for each resource which grew for `watchdog.samples` samples
+func (w *Watchdog) check() {
+	counts := w.sample()
+
+	logger().WithFields(toFields(counts)).Trace("sampled counts")
+
+	for resource, count := range counts {
+		t, ok := w.trends[resource]
+		if !ok {
+			w.trends[resource] = &trend{first: count, last: count}
+
+			continue
+		}
+
+		if count <= t.last {
+			*t = trend{first: count, last: count}
+
+			continue
+		}
+
+		t.last = count
+		t.growing++
+
+		if t.growing >= w.cfg.Samples {
+			w.alarm(resource, t)
+
+			*t = trend{first: count, last: count}
+		}
+	}
+}
+
+func (w *Watchdog) alarm(resource string, t *trend) {
+	logger().Warnf(
+		"%s grew for %d samples in a row from %d to %d, this might be a leak",
+		resource, t.growing, t.first, t.last,
+	)
+
+	var stacks bytes.Buffer
+
+	if err := pprof.Lookup("goroutine").WriteTo(&stacks, 1); err == nil {
+		logger().Warnf("goroutine stacks:\n%s", stacks.String())
+	}
+
+	evt.Bus().Publish(evt.WatchdogAlarm, resource, t.last)
+}
+
+// sample returns the current counts of all resources
+func sample() map[string]int64 {
+	counts := map[string]int64{
+		ResourceGoroutines: int64(runtime.NumGoroutine()),
+	}
+
+	if fds, ok := openFDs(); ok {
+		counts[ResourceOpenFDs] = fds
+	}
+
+	for subsystem, cnt := range Workers() {
+		counts[ResourceWorkersPrefix+subsystem] = cnt
+	}
+
+	return counts
+}
+
+// openFDs returns the number of open file descriptors, false if the OS doesn't provide it
+func openFDs() (int64, bool) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+
+	return int64(len(entries)), true
+}
+
+func toFields(counts map[string]int64) logrus.Fields {
+	fields := make(logrus.Fields, len(counts))
+
+	for resource, count := range counts {
+		fields[resource] = count
+	}
+
+	return fields
+}
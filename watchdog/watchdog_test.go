@@ -0,0 +1,123 @@
+package watchdog
+
+import (
+	"context"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/evt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watchdog", func() {
+	var (
+		sut *Watchdog
+
+		counts map[string]int64
+		alarms map[string]int64
+	)
+
+	BeforeEach(func() {
+		sut = New(config.Watchdog{Enable: true, Interval: config.Duration(time.Minute), Samples: 3})
+
+		counts = map[string]int64{}
+		sut.sample = func() map[string]int64 { return counts }
+
+		alarms = map[string]int64{}
+		handler := func(resource string, count int64) {
+			alarms[resource] = count
+		}
+
+		Expect(Bus().Subscribe(WatchdogAlarm, handler)).Should(Succeed())
+		DeferCleanup(func() { _ = Bus().Unsubscribe(WatchdogAlarm, handler) })
+	})
+
+	checkWith := func(resource string, values ...int64) {
+		for _, v := range values {
+			counts[resource] = v
+
+			sut.check()
+		}
+	}
+
+	It("should raise an alarm if a count grows for the configured samples", func() {
+		checkWith(ResourceGoroutines, 10, 11, 12)
+		Expect(alarms).Should(BeEmpty())
+
+		checkWith(ResourceGoroutines, 13)
+		Expect(alarms).Should(HaveKeyWithValue(ResourceGoroutines, int64(13)))
+	})
+
+	It("should not raise an alarm if a count drops or stays the same", func() {
+		checkWith(ResourceOpenFDs, 10, 11, 12, 12, 13, 14, 10, 11, 12)
+
+		Expect(alarms).Should(BeEmpty())
+	})
+
+	It("should start over after an alarm", func() {
+		checkWith(ResourceGoroutines, 1, 2, 3, 4)
+		Expect(alarms).Should(HaveLen(1))
+
+		delete(alarms, ResourceGoroutines)
+
+		checkWith(ResourceGoroutines, 5, 6)
+		Expect(alarms).Should(BeEmpty())
+
+		checkWith(ResourceGoroutines, 7)
+		Expect(alarms).Should(HaveKeyWithValue(ResourceGoroutines, int64(7)))
+	})
+
+	It("should sample periodically", func() {
+		sut.cfg.Interval = config.Duration(10 * time.Millisecond)
+
+		sampled := make(chan struct{}, 1)
+		sut.sample = func() map[string]int64 {
+			select {
+			case sampled <- struct{}{}:
+			default:
+			}
+
+			return counts
+		}
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+		DeferCleanup(func() {
+			cancelFn()
+
+			// the next spec replaces the sample function and the counts
+			Eventually(sut.stopped).Should(BeClosed())
+		})
+
+		sut.Start(ctx)
+
+		Eventually(sampled).Should(Receive())
+	})
+
+	Describe("sample", func() {
+		It("should contain goroutines and workers", func() {
+			done := TrackWorker("sample_test")
+			DeferCleanup(done)
+
+			Expect(sample()).Should(And(
+				HaveKeyWithValue(ResourceGoroutines, BeNumerically(">", 0)),
+				HaveKeyWithValue(ResourceWorkersPrefix+"sample_test", int64(1)),
+			))
+		})
+	})
+
+	Describe("TrackWorker", func() {
+		It("should count running workers", func() {
+			done1 := TrackWorker("track_test")
+			done2 := TrackWorker("track_test")
+
+			Expect(Workers()).Should(HaveKeyWithValue("track_test", int64(2)))
+
+			done1()
+			done2()
+
+			Expect(Workers()).Should(HaveKeyWithValue("track_test", int64(0)))
+		})
+	})
+})
@@ -0,0 +1,32 @@
+package watchdog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+//nolint:gochecknoglobals
+var workers sync.Map // subsystem -> *atomic.Int64
+
+// TrackWorker counts a running worker of the subsystem until the returned function is called
+func TrackWorker(subsystem string) (done func()) {
+	v, _ := workers.LoadOrStore(subsystem, new(atomic.Int64))
+	cnt := v.(*atomic.Int64)
+
+	cnt.Add(1)
+
+	return func() { cnt.Add(-1) }
+}
+
+// Workers returns the number of running workers per subsystem
+func Workers() map[string]int64 {
+	res := make(map[string]int64)
+
+	workers.Range(func(key, value any) bool {
+		res[key.(string)] = value.(*atomic.Int64).Load()
+
+		return true
+	})
+
+	return res
+}
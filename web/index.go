@@ -13,6 +13,14 @@ var IndexTmpl string
 //go:embed all:static
 var static embed.FS
 
+//go:embed all:ui
+var ui embed.FS
+
 func Assets() (fs.FS, error) {
 	return fs.Sub(static, "static")
 }
+
+// UI returns the files of the web UI
+func UI() (fs.FS, error) {
+	return fs.Sub(ui, "ui")
+}
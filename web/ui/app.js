@@ -0,0 +1,324 @@
+"use strict";
+
+// the UI is served from /ui/, the API from /api/
+const api = "../api";
+
+const refreshInterval = 2000;
+const recentQueries = 1000;
+const renderDelay = 250;
+
+let queries = [];
+let queryStream = null;
+let renderTimer = null;
+
+async function request(path, options) {
+    const res = await fetch(api + path, options);
+    if (!res.ok) {
+        throw new Error(`${path}: ${res.status} ${await res.text()}`);
+    }
+
+    return res;
+}
+
+function showError(err) {
+    const el = document.getElementById("error");
+
+    el.textContent = err ? err.message : "";
+    el.hidden = !err;
+}
+
+// run calls fn and shows its error, if any
+async function run(fn) {
+    try {
+        await fn();
+        showError(null);
+    } catch (err) {
+        showError(err);
+    }
+}
+
+function setText(id, text) {
+    document.getElementById(id).textContent = text;
+}
+
+// row creates a table row, values are never interpreted as HTML
+function row(values, className) {
+    const tr = document.createElement("tr");
+
+    if (className) {
+        tr.className = className;
+    }
+
+    for (const value of values) {
+        const td = document.createElement("td");
+        td.textContent = value;
+        tr.appendChild(td);
+    }
+
+    return tr;
+}
+
+function percent(ratio) {
+    return `${(ratio * 100).toFixed(1)} %`;
+}
+
+function clientName(q) {
+    const names = q.clientNames.filter((n) => n && n !== "none");
+
+    return names.length > 0 ? names.join(", ") : q.clientIP;
+}
+
+async function loadBlockingStatus() {
+    const status = await (await request("/blocking/status")).json();
+
+    let text = status.enabled ? "enabled" : "disabled";
+
+    if (status.disabledGroups && status.disabledGroups.length > 0) {
+        text += ` (groups: ${status.disabledGroups.join(", ")})`;
+    }
+
+    if (status.autoEnableInSec) {
+        text += `, enabled again in ${status.autoEnableInSec}s`;
+    }
+
+    setText("blocking-status", text);
+}
+
+async function loadCacheStats() {
+    const stats = await (await request("/cache/stats")).json();
+
+    let hits = 0;
+    let lookups = 0;
+
+    for (const b of stats.buckets) {
+        hits += b.hits;
+        lookups += b.hits + b.misses;
+    }
+
+    setText("cache-entries", stats.entries);
+    setText("cache-memory", `${(stats.memoryBytes / 1024 / 1024).toFixed(1)} MB`);
+    setText("cache-hit-ratio", lookups > 0 ? percent(hits / lookups) : "-");
+}
+
+async function loadUpstreams() {
+    const upstreams = await (await request("/upstreams/status")).json();
+    const body = document.getElementById("upstreams");
+
+    body.replaceChildren(...upstreams.map((u) => row([
+        u.group,
+        u.upstream,
+        u.healthy ? "yes" : "no",
+        percent(u.errorRate),
+        `${u.averageLatencyMs.toFixed(1)} ms`,
+    ], u.healthy ? "" : "unhealthy")));
+}
+
+async function loadDashboard() {
+    await Promise.all([loadBlockingStatus(), loadCacheStats(), loadUpstreams()]);
+}
+
+function matchesFilter(q, filter) {
+    if (!filter) {
+        return true;
+    }
+
+    return [q.clientIP, ...q.clientNames, q.question, q.responseType]
+        .some((v) => v && v.toLowerCase().includes(filter));
+}
+
+function renderQueries() {
+    const filter = document.getElementById("queries-filter").value.trim().toLowerCase();
+    const body = document.getElementById("queries-list");
+
+    body.replaceChildren(...queries.filter((q) => matchesFilter(q, filter)).slice(0, 200).map((q) => row([
+        new Date(q.time).toLocaleTimeString(),
+        clientName(q),
+        q.question,
+        q.questionType,
+        q.responseType,
+        q.reason,
+        q.answer,
+        `${q.durationMs} ms`,
+    ], q.responseType)));
+}
+
+function renderClients() {
+    const clients = new Map();
+
+    for (const q of queries) {
+        const name = clientName(q);
+
+        let c = clients.get(name);
+        if (!c) {
+            c = {name: name, total: 0, blocked: 0, cached: 0, domains: new Map()};
+            clients.set(name, c);
+        }
+
+        c.total++;
+
+        if (q.responseType === "BLOCKED") {
+            c.blocked++;
+        } else if (q.responseType === "CACHED") {
+            c.cached++;
+        }
+
+        c.domains.set(q.question, (c.domains.get(q.question) || 0) + 1);
+    }
+
+    const body = document.getElementById("clients-list");
+
+    body.replaceChildren(...[...clients.values()].sort((a, b) => b.total - a.total).map((c) => {
+        const [topDomain, topCount] = [...c.domains.entries()].sort((a, b) => b[1] - a[1])[0];
+
+        return row([
+            c.name,
+            c.total,
+            `${c.blocked} (${percent(c.blocked / c.total)})`,
+            `${c.cached} (${percent(c.cached / c.total)})`,
+            `${topDomain} (${topCount})`,
+        ]);
+    }));
+}
+
+function render() {
+    renderTimer = null;
+
+    renderQueries();
+    renderClients();
+}
+
+// scheduleRender merges the rendering of queries arriving in quick succession
+function scheduleRender() {
+    if (!renderTimer) {
+        renderTimer = setTimeout(render, renderDelay);
+    }
+}
+
+function paused() {
+    return document.getElementById("queries-pause").checked;
+}
+
+async function loadQueries() {
+    if (paused()) {
+        return;
+    }
+
+    queries = await (await request(`/queries/recent?limit=${recentQueries}`)).json();
+
+    render();
+}
+
+// openQueryStream receives new queries as Server-Sent Events, the browser reconnects automatically
+function openQueryStream() {
+    if (queryStream) {
+        return;
+    }
+
+    queryStream = new EventSource(`${api}/queries/stream`);
+
+    queryStream.onmessage = (event) => {
+        if (paused()) {
+            return;
+        }
+
+        queries.unshift(JSON.parse(event.data));
+        queries.length = Math.min(queries.length, recentQueries);
+
+        scheduleRender();
+    };
+}
+
+function closeQueryStream() {
+    if (queryStream) {
+        queryStream.close();
+        queryStream = null;
+    }
+}
+
+async function loadLiveQueries() {
+    await loadQueries();
+    openQueryStream();
+}
+
+async function loadConfig() {
+    setText("config-text", await (await request("/config")).text());
+}
+
+async function loadVersion() {
+    const info = await (await request("/info")).json();
+
+    setText("version", `Version ${info.version}, built ${info.buildTime}`);
+}
+
+const views = {
+    dashboard: loadDashboard,
+    queries: loadLiveQueries,
+    clients: loadLiveQueries,
+    config: loadConfig,
+};
+
+// views updated by the query stream instead of polling
+const liveViews = ["queries", "clients"];
+
+let current = "dashboard";
+
+function show(view) {
+    current = views[view] ? view : "dashboard";
+
+    for (const section of document.querySelectorAll("main > section")) {
+        section.hidden = section.id !== current;
+    }
+
+    for (const link of document.querySelectorAll("nav a")) {
+        link.classList.toggle("active", link.dataset.view === current);
+    }
+
+    if (!liveViews.includes(current)) {
+        closeQueryStream();
+    }
+
+    run(views[current]);
+}
+
+function bindActions() {
+    document.getElementById("blocking-enable").addEventListener("click", () => run(async () => {
+        await request("/blocking/enable");
+        await loadBlockingStatus();
+    }));
+
+    document.getElementById("blocking-disable").addEventListener("click", () => run(async () => {
+        const duration = document.getElementById("blocking-duration").value;
+
+        await request("/blocking/disable" + (duration ? `?duration=${encodeURIComponent(duration)}` : ""));
+        await loadBlockingStatus();
+    }));
+
+    document.getElementById("lists-refresh").addEventListener("click", () => run(async () => {
+        setText("lists-status", "refreshing...");
+        await request("/lists/refresh", {method: "POST"});
+        setText("lists-status", `refreshed at ${new Date().toLocaleTimeString()}`);
+    }));
+
+    document.getElementById("cache-flush").addEventListener("click", () => run(async () => {
+        await request("/cache/flush", {method: "POST"});
+        await loadCacheStats();
+    }));
+
+    document.getElementById("queries-filter").addEventListener("input", renderQueries);
+
+    // fill the gap of queries missed while paused
+    document.getElementById("queries-pause").addEventListener("change", () => run(loadQueries));
+}
+
+window.addEventListener("hashchange", () => show(location.hash.substring(1)));
+
+bindActions();
+run(loadVersion);
+show(location.hash.substring(1));
+
+setInterval(() => {
+    // the configuration doesn't change while running
+    if (current !== "config" && !liveViews.includes(current)) {
+        run(views[current]);
+    }
+}, refreshInterval);
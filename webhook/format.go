@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/0xERR0R/blocky/config"
+)
+
+const contentTypeJSON = "application/json"
+
+// newRequest creates the request which delivers the notification in the format of the target
+func newRequest(ctx context.Context, target config.Webhook, n *Notification) (*http.Request, error) {
+	var (
+		body        []byte
+		contentType string
+		err         error
+	)
+
+	header := http.Header{}
+
+	switch target.Format {
+	case config.WebhookFormatJson:
+		body, err = json.Marshal(n)
+		contentType = contentTypeJSON
+
+	case config.WebhookFormatSlack:
+		body, err = json.Marshal(map[string]string{
+			"text": fmt.Sprintf("*%s*\n%s", n.title(), n.Message),
+		})
+		contentType = contentTypeJSON
+
+	case config.WebhookFormatDiscord:
+		body, err = json.Marshal(map[string]string{
+			"username": "blocky",
+			"content":  fmt.Sprintf("**%s**\n%s", n.title(), n.Message),
+		})
+		contentType = contentTypeJSON
+
+	case config.WebhookFormatNtfy:
+		body = []byte(n.Message)
+		contentType = "text/plain"
+
+		header.Set("Title", n.title())
+		header.Set("Tags", ntfyTag(n))
+
+		if target.Token != "" {
+			header.Set("Authorization", "Bearer "+target.Token)
+		}
+
+	case config.WebhookFormatPushover:
+		body = []byte(url.Values{
+			"token":   {target.Token},
+			"user":    {target.User},
+			"title":   {n.title()},
+			"message": {n.Message},
+		}.Encode())
+		contentType = "application/x-www-form-urlencoded"
+
+	default:
+		return nil, fmt.Errorf("unsupported webhook format: %s", target.Format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = header
+	req.Header.Set("Content-Type", contentType)
+
+	return req, nil
+}
+
+// ntfyTag returns the emoji tag shown in front of the title
+func ntfyTag(n *Notification) string {
+	if n.Event == config.WebhookEventUpstreamsRecovered {
+		return "white_check_mark"
+	}
+
+	return "warning"
+}
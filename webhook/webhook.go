@@ -0,0 +1,183 @@
+// Package webhook notifies about operational events like failed list downloads or unreachable upstreams
+// via webhooks (generic JSON, Slack, Discord, ntfy or Pushover)
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/log"
+	"github.com/sirupsen/logrus"
+)
+
+const sendTimeout = 10 * time.Second
+
+// Notification is a message about an operational event
+type Notification struct {
+	Event    config.WebhookEvent `json:"event"`
+	Title    string              `json:"title"`
+	Message  string              `json:"message"`
+	Subject  string              `json:"subject"`
+	Time     time.Time           `json:"time"`
+	Instance string              `json:"instance"`
+}
+
+// Notifier sends the notifications to the configured targets
+type Notifier struct {
+	cfg      config.Webhooks
+	client   *http.Client
+	instance string
+
+	lock     sync.Mutex
+	lastSent map[string]time.Time
+
+	ctx context.Context
+}
+
+func logger() *logrus.Entry {
+	return log.PrefixedLog("webhook")
+}
+
+// Start subscribes the operational events and notifies the targets until ctx is done,
+// returns nil if no target is configured
+func Start(ctx context.Context, cfg config.Webhooks) (*Notifier, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil //nolint:nilnil
+	}
+
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "blocky"
+	}
+
+	n := &Notifier{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: sendTimeout},
+		instance: instance,
+		lastSent: make(map[string]time.Time),
+		ctx:      ctx,
+	}
+
+	if err := n.subscribe(); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// subscribe registers the handlers of the operational events on the bus
+func (n *Notifier) subscribe() error {
+	err := evt.Bus().Subscribe(evt.CachingFailedDownloadChanged, func(link string) {
+		n.notify(config.WebhookEventListDownloadFailed, link,
+			"List download failed", fmt.Sprintf("Can't download list '%s'", link))
+	})
+	if err != nil {
+		return err
+	}
+
+	err = evt.Bus().Subscribe(evt.UpstreamGroupHealthChanged, func(group string, healthy bool) {
+		if healthy {
+			n.notify(config.WebhookEventUpstreamsRecovered, group,
+				"Upstreams recovered", fmt.Sprintf("Upstream group '%s' is reachable again", group))
+
+			return
+		}
+
+		n.notify(config.WebhookEventUpstreamsDown, group,
+			"All upstreams down", fmt.Sprintf("No upstream of group '%s' is healthy", group))
+	})
+	if err != nil {
+		return err
+	}
+
+	return evt.Bus().Subscribe(evt.CertificateExpiring, func(domains string, notAfter time.Time) {
+		n.notify(config.WebhookEventCertificateExpiring, domains,
+			"Certificate expiring",
+			fmt.Sprintf("Certificate for '%s' expires on %s", domains, notAfter.Format(time.RFC1123)))
+	})
+}
+
+// notify sends the notification to all targets of the event, unless the same event of the subject
+// was sent within the throttle period
+func (n *Notifier) notify(event config.WebhookEvent, subject, title, message string) {
+	if n.ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+
+	if !n.allow(event, subject, now) {
+		logger().Debugf("%s for '%s' throttled", event, subject)
+
+		return
+	}
+
+	notification := &Notification{
+		Event:    event,
+		Title:    title,
+		Message:  message,
+		Subject:  subject,
+		Time:     now,
+		Instance: n.instance,
+	}
+
+	for _, target := range n.cfg.Targets {
+		if slices.Contains(target.Events, event) {
+			go n.send(target, notification)
+		}
+	}
+}
+
+// allow returns false if the event of the subject was already sent within the throttle period
+func (n *Notifier) allow(event config.WebhookEvent, subject string, now time.Time) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	key := event.String() + ":" + subject
+
+	if last, ok := n.lastSent[key]; ok && now.Sub(last) < n.cfg.Throttle.ToDuration() {
+		return false
+	}
+
+	n.lastSent[key] = now
+
+	return true
+}
+
+func (n *Notifier) send(target config.Webhook, notification *Notification) {
+	req, err := newRequest(n.ctx, target, notification)
+	if err != nil {
+		logger().Errorf("can't create %s webhook request: %v", target.Format, err)
+
+		return
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		logger().Warnf("can't send %s webhook to %s: %v", target.Format, req.URL.Host, err)
+
+		return
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		logger().Warnf("%s webhook to %s failed with status %s", target.Format, req.URL.Host, resp.Status)
+
+		return
+	}
+
+	logger().Debugf("sent %s to %s webhook %s", notification.Event, target.Format, req.URL.Host)
+}
+
+// title returns the title of the notification including the instance
+func (n *Notification) title() string {
+	return fmt.Sprintf("blocky (%s): %s", n.Instance, n.Title)
+}
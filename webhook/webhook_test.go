@@ -0,0 +1,193 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notifier", func() {
+	var (
+		cfg      config.Webhooks
+		received chan *Notification
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		received = make(chan *Notification, 10)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var n Notification
+
+			Expect(json.NewDecoder(r.Body).Decode(&n)).Should(Succeed())
+
+			received <- &n
+		}))
+		DeferCleanup(server.Close)
+
+		cfg = config.Webhooks{
+			Targets: []config.Webhook{
+				{URL: server.URL, Format: config.WebhookFormatJson, Events: config.WebhookEventValues()},
+			},
+			Throttle: config.Duration(time.Hour),
+		}
+
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+	})
+
+	It("should be nil without targets", func() {
+		Expect(Start(ctx, config.Webhooks{})).Should(BeNil())
+	})
+
+	It("should notify about the operational events", func() {
+		_, err := Start(ctx, cfg)
+		Expect(err).Should(Succeed())
+
+		var n *Notification
+
+		evt.Bus().Publish(evt.CachingFailedDownloadChanged, "https://example.com/list.txt")
+
+		Eventually(received).Should(Receive(&n))
+		Expect(n.Event).Should(Equal(config.WebhookEventListDownloadFailed))
+		Expect(n.Subject).Should(Equal("https://example.com/list.txt"))
+		Expect(n.Message).Should(ContainSubstring("https://example.com/list.txt"))
+		Expect(n.Instance).ShouldNot(BeEmpty())
+
+		evt.Bus().Publish(evt.UpstreamGroupHealthChanged, "default", false)
+
+		Eventually(received).Should(Receive(&n))
+		Expect(n.Event).Should(Equal(config.WebhookEventUpstreamsDown))
+		Expect(n.Subject).Should(Equal("default"))
+
+		evt.Bus().Publish(evt.UpstreamGroupHealthChanged, "default", true)
+
+		Eventually(received).Should(Receive(&n))
+		Expect(n.Event).Should(Equal(config.WebhookEventUpstreamsRecovered))
+
+		evt.Bus().Publish(evt.CertificateExpiring, "blocky.example.com", time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC))
+
+		Eventually(received).Should(Receive(&n))
+		Expect(n.Event).Should(Equal(config.WebhookEventCertificateExpiring))
+		Expect(n.Message).Should(ContainSubstring("02 Jan 2030"))
+	})
+
+	It("should notify the targets of the event only", func() {
+		cfg.Targets[0].Events = []config.WebhookEvent{config.WebhookEventUpstreamsDown}
+
+		_, err := Start(ctx, cfg)
+		Expect(err).Should(Succeed())
+
+		evt.Bus().Publish(evt.CachingFailedDownloadChanged, "https://example.com/list.txt")
+
+		Consistently(received).ShouldNot(Receive())
+	})
+
+	It("should throttle repeated events of the same subject", func() {
+		_, err := Start(ctx, cfg)
+		Expect(err).Should(Succeed())
+
+		evt.Bus().Publish(evt.CachingFailedDownloadChanged, "https://example.com/a.txt")
+		evt.Bus().Publish(evt.CachingFailedDownloadChanged, "https://example.com/a.txt")
+		evt.Bus().Publish(evt.CachingFailedDownloadChanged, "https://example.com/b.txt")
+
+		Eventually(received).Should(HaveLen(2))
+		Consistently(received).Should(HaveLen(2))
+	})
+
+	It("should not notify when the context is done", func() {
+		_, err := Start(ctx, cfg)
+		Expect(err).Should(Succeed())
+
+		cancelFn()
+
+		evt.Bus().Publish(evt.UpstreamGroupHealthChanged, "default", false)
+
+		Consistently(received).ShouldNot(Receive())
+	})
+})
+
+var _ = Describe("Formats", func() {
+	notification := &Notification{
+		Event:    config.WebhookEventUpstreamsDown,
+		Title:    "All upstreams down",
+		Message:  "No upstream of group 'default' is healthy",
+		Subject:  "default",
+		Instance: "host",
+	}
+
+	request := func(target config.Webhook) (*http.Request, string) {
+		if target.URL == "" {
+			target.URL = "https://example.com/hook"
+		}
+
+		req, err := newRequest(context.Background(), target, notification)
+		Expect(err).Should(Succeed())
+
+		body, err := io.ReadAll(req.Body)
+		Expect(err).Should(Succeed())
+
+		Expect(req.Method).Should(Equal(http.MethodPost))
+
+		return req, string(body)
+	}
+
+	It("should post the notification as JSON", func() {
+		req, body := request(config.Webhook{Format: config.WebhookFormatJson})
+
+		Expect(req.Header.Get("Content-Type")).Should(Equal("application/json"))
+		Expect(body).Should(MatchJSON(`{
+			"event": "upstreamsDown",
+			"title": "All upstreams down",
+			"message": "No upstream of group 'default' is healthy",
+			"subject": "default",
+			"time": "0001-01-01T00:00:00Z",
+			"instance": "host"
+		}`))
+	})
+
+	It("should post a Slack message", func() {
+		_, body := request(config.Webhook{Format: config.WebhookFormatSlack})
+
+		Expect(body).Should(MatchJSON(`{
+			"text": "*blocky (host): All upstreams down*\nNo upstream of group 'default' is healthy"
+		}`))
+	})
+
+	It("should post a Discord message", func() {
+		_, body := request(config.Webhook{Format: config.WebhookFormatDiscord})
+
+		Expect(body).Should(MatchJSON(`{
+			"username": "blocky",
+			"content": "**blocky (host): All upstreams down**\nNo upstream of group 'default' is healthy"
+		}`))
+	})
+
+	It("should publish to a ntfy topic", func() {
+		req, body := request(config.Webhook{Format: config.WebhookFormatNtfy, Token: "tk_secret"})
+
+		Expect(body).Should(Equal("No upstream of group 'default' is healthy"))
+		Expect(req.Header.Get("Title")).Should(Equal("blocky (host): All upstreams down"))
+		Expect(req.Header.Get("Tags")).Should(Equal("warning"))
+		Expect(req.Header.Get("Authorization")).Should(Equal("Bearer tk_secret"))
+	})
+
+	It("should send a Pushover message", func() {
+		req, body := request(config.Webhook{Format: config.WebhookFormatPushover, Token: "app", User: "user"})
+
+		Expect(req.Header.Get("Content-Type")).Should(Equal("application/x-www-form-urlencoded"))
+		Expect(body).Should(ContainSubstring("token=app"))
+		Expect(body).Should(ContainSubstring("user=user"))
+		Expect(body).Should(ContainSubstring("title=blocky+%28host%29%3A+All+upstreams+down"))
+	})
+})
@@ -0,0 +1,195 @@
+// Package zonefiles loads the records of BIND zone files for the custom DNS resolver and reloads changed files
+package zonefiles
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/log"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
+)
+
+// zone is a loaded zone file
+type zone struct {
+	path    string
+	modTime time.Time
+	size    int64
+	records config.CustomDNSMapping
+}
+
+// Watcher holds the records of the zone files and reloads them if they change
+type Watcher struct {
+	cfg config.ZoneFiles
+
+	lock     sync.Mutex
+	zones    map[string]*zone
+	onChange func(config.CustomDNSMapping)
+}
+
+// NewWatcher loads the zone files and checks them for changes until the context is done
+func NewWatcher(ctx context.Context, cfg config.ZoneFiles) (*Watcher, error) {
+	w := &Watcher{
+		cfg:   cfg,
+		zones: make(map[string]*zone, len(cfg.Zones)),
+	}
+
+	for name, path := range cfg.Zones {
+		z, err := load(name, path)
+		if err != nil {
+			return nil, err
+		}
+
+		w.zones[name] = z
+	}
+
+	if cfg.CheckPeriod.IsAboveZero() {
+		go w.watch(ctx)
+	}
+
+	return w, nil
+}
+
+func logger() *logrus.Entry {
+	return log.PrefixedLog("zoneFiles")
+}
+
+// Name implements `resolver.CustomDNSSource`.
+func (w *Watcher) Name() string {
+	return "zone files"
+}
+
+// OnChange registers the function which receives the records of all zones after each reload
+func (w *Watcher) OnChange(onChange func(config.CustomDNSMapping)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.onChange = onChange
+
+	onChange(w.mapping())
+}
+
+func (w *Watcher) watch(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.CheckPeriod.ToDuration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.reload()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reload loads the changed files, a file which can't be loaded keeps its previous records
+func (w *Watcher) reload() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	changed := false
+
+	for name, old := range w.zones {
+		info, err := os.Stat(old.path)
+		if err != nil {
+			logger().WithError(err).Warnf("can't check zone file of %s", name)
+
+			continue
+		}
+
+		if info.ModTime().Equal(old.modTime) && info.Size() == old.size {
+			continue
+		}
+
+		z, err := load(name, old.path)
+		if err != nil {
+			logger().WithError(err).Errorf("can't reload zone %s, keeping the previous records", name)
+
+			// don't retry until the file changes again
+			old.modTime, old.size = info.ModTime(), info.Size()
+
+			continue
+		}
+
+		w.zones[name] = z
+		changed = true
+
+		logger().Infof("reloaded zone %s", name)
+	}
+
+	if changed && w.onChange != nil {
+		w.onChange(w.mapping())
+	}
+}
+
+// mapping returns the records of all zones, the lock must be held
+func (w *Watcher) mapping() config.CustomDNSMapping {
+	result := make(config.CustomDNSMapping)
+
+	names := maps.Keys(w.zones)
+	slices.Sort(names)
+
+	for _, name := range names {
+		for domain, entries := range w.zones[name].records {
+			result[domain] = append(result[domain], entries...)
+		}
+	}
+
+	return result
+}
+
+// load parses the zone file, the name of the zone is the origin of relative names
+func load(name, path string) (*zone, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open zone file of %s: %w", name, err)
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("can't open zone file of %s: %w", name, err)
+	}
+
+	origin := dns.Fqdn(name)
+	records := make(config.CustomDNSMapping)
+	hasSOA := false
+
+	parser := dns.NewZoneParser(file, origin, path)
+	parser.SetIncludeAllowed(true)
+
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		owner := strings.ToLower(rr.Header().Name)
+
+		if !dns.IsSubDomain(origin, owner) {
+			return nil, fmt.Errorf("zone file of %s: %s is not in the zone", name, rr.Header().Name)
+		}
+
+		if rr.Header().Rrtype == dns.TypeSOA {
+			hasSOA = true
+		}
+
+		domain := strings.TrimSuffix(owner, ".")
+		records[domain] = append(records[domain], rr)
+	}
+
+	if err := parser.Err(); err != nil {
+		return nil, fmt.Errorf("can't parse zone file of %s: %w", name, err)
+	}
+
+	if !hasSOA {
+		logger().Warnf("zone %s has no SOA record, queries for names without records are forwarded", name)
+	}
+
+	return &zone{path: path, modTime: info.ModTime(), size: info.Size(), records: records}, nil
+}
@@ -0,0 +1,150 @@
+package zonefiles
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	. "github.com/0xERR0R/blocky/helpertest"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		sut      *Watcher
+		cfg      config.ZoneFiles
+		zoneFile *TmpFile
+		mapping  config.CustomDNSMapping
+
+		ctx      context.Context
+		cancelFn context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		ctx, cancelFn = context.WithCancel(context.Background())
+		DeferCleanup(cancelFn)
+
+		zoneFile = NewTmpFolder("zonefiles").CreateStringFile("home.lan.zone",
+			"$TTL 300",
+			"@    IN SOA ns.home.lan. admin.home.lan. 1 3600 600 86400 60",
+			"ns   IN A   192.168.178.2",
+			"NAS  IN A   192.168.178.3",
+		)
+
+		cfg = config.ZoneFiles{
+			Zones: map[string]string{"home.lan": zoneFile.Path},
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+
+		sut, err = NewWatcher(ctx, cfg)
+		Expect(err).Should(Succeed())
+
+		sut.OnChange(func(m config.CustomDNSMapping) {
+			mapping = m
+		})
+	})
+
+	rewrite := func(lines ...string) {
+		content := ""
+		for _, line := range lines {
+			content += line + "\n"
+		}
+
+		Expect(os.WriteFile(zoneFile.Path, []byte(content), 0o600)).Should(Succeed())
+	}
+
+	rrString := func(domain string) []string {
+		result := make([]string, 0, len(mapping[domain]))
+		for _, rr := range mapping[domain] {
+			result = append(result, rr.String())
+		}
+
+		return result
+	}
+
+	It("should have a name", func() {
+		Expect(sut.Name()).Should(Equal("zone files"))
+	})
+
+	It("should load the records of the zone", func() {
+		Expect(mapping).Should(HaveLen(3))
+		Expect(mapping["home.lan"]).Should(ConsistOf(BeAssignableToTypeOf(&dns.SOA{})))
+		Expect(rrString("ns.home.lan")).Should(Equal([]string{"ns.home.lan.\t300\tIN\tA\t192.168.178.2"}))
+		// the names are case insensitive
+		Expect(mapping).Should(HaveKey("nas.home.lan"))
+	})
+
+	Describe("reload", func() {
+		It("should reload a changed file", func() {
+			rewrite(
+				"$TTL 300",
+				"@    IN SOA ns.home.lan. admin.home.lan. 2 3600 600 86400 60",
+				"ns   IN A   192.168.178.22",
+			)
+
+			sut.reload()
+
+			Expect(mapping).Should(HaveLen(2))
+			Expect(rrString("ns.home.lan")).Should(Equal([]string{"ns.home.lan.\t300\tIN\tA\t192.168.178.22"}))
+		})
+
+		It("should keep the records if the file is invalid", func() {
+			rewrite("ns IN A not-an-ip")
+
+			sut.reload()
+
+			Expect(mapping).Should(HaveLen(3))
+			Expect(rrString("ns.home.lan")).Should(Equal([]string{"ns.home.lan.\t300\tIN\tA\t192.168.178.2"}))
+		})
+	})
+
+	When("a check period is configured", func() {
+		BeforeEach(func() {
+			cfg.CheckPeriod = config.Duration(10 * time.Millisecond)
+		})
+
+		It("should reload the changed file", func() {
+			rewrite(
+				"$TTL 300",
+				"@    IN SOA ns.home.lan. admin.home.lan. 2 3600 600 86400 60",
+			)
+
+			Eventually(func() int {
+				sut.lock.Lock()
+				defer sut.lock.Unlock()
+
+				return len(mapping)
+			}).Should(Equal(1))
+		})
+	})
+
+	Describe("NewWatcher", func() {
+		It("should fail if a file does not exist", func() {
+			cfg.Zones["home.lan"] = "/does/not/exist"
+
+			_, err := NewWatcher(ctx, cfg)
+			Expect(err).Should(MatchError(ContainSubstring("can't open zone file of home.lan")))
+		})
+
+		It("should fail if a record is not in the zone", func() {
+			rewrite("www.example.com. 300 IN A 192.168.178.4")
+
+			_, err := NewWatcher(ctx, cfg)
+			Expect(err).Should(MatchError(ContainSubstring("www.example.com. is not in the zone")))
+		})
+
+		It("should fail if the file is invalid", func() {
+			rewrite("ns IN A not-an-ip")
+
+			_, err := NewWatcher(ctx, cfg)
+			Expect(err).Should(MatchError(ContainSubstring("can't parse zone file of home.lan")))
+		})
+	})
+})